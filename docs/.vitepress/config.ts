@@ -64,6 +64,7 @@ function sidebar(): DefaultTheme.Sidebar {
           { text: 'Quickstart', link: '/quickstart' },
           { text: 'Installation', link: '/installation' },
           { text: 'Project Structure', link: '/project-structure' },
+          { text: 'Embedding in Go', link: '/embedding' },
         ],
       },
       {
@@ -75,6 +76,8 @@ function sidebar(): DefaultTheme.Sidebar {
           { text: 'Dependencies', link: '/concepts/dependencies' },
           { text: 'Seeds', link: '/concepts/seeds' },
           { text: 'Configuration', link: '/concepts/configuration' },
+          { text: 'Workspaces', link: '/concepts/workspaces' },
+          { text: 'Selecting Models', link: '/concepts/selection' },
         ],
       },
       {
@@ -122,17 +125,34 @@ function sidebar(): DefaultTheme.Sidebar {
         text: 'CLI Reference',
         items: [
           { text: 'Overview', link: '/cli/' },
+          { text: 'audit', link: '/cli/audit' },
+          { text: 'backfill', link: '/cli/backfill' },
+          { text: 'clone', link: '/cli/clone' },
           { text: 'completion', link: '/cli/completion' },
+          { text: 'console', link: '/cli/console' },
           { text: 'dag', link: '/cli/dag' },
+          { text: 'diff', link: '/cli/diff' },
           { text: 'discover', link: '/cli/discover' },
           { text: 'docs', link: '/cli/docs' },
+          { text: 'export', link: '/cli/export' },
+          { text: 'fmt', link: '/cli/fmt' },
+          { text: 'freshness', link: '/cli/freshness' },
+          { text: 'import', link: '/cli/import' },
           { text: 'init', link: '/cli/init' },
           { text: 'lineage', link: '/cli/lineage' },
           { text: 'list', link: '/cli/list' },
           { text: 'lsp', link: '/cli/lsp' },
+          { text: 'parse', link: '/cli/parse' },
+          { text: 'preview', link: '/cli/preview' },
+          { text: 'refactor', link: '/cli/refactor' },
           { text: 'render', link: '/cli/render' },
           { text: 'run', link: '/cli/run' },
           { text: 'seed', link: '/cli/seed' },
+          { text: 'serve', link: '/cli/serve' },
+          { text: 'state', link: '/cli/state' },
+          { text: 'stats', link: '/cli/stats' },
+          { text: 'trace', link: '/cli/trace' },
+          { text: 'validate', link: '/cli/validate' },
           { text: 'version', link: '/cli/version' },
         ],
       },
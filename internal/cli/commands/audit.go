@@ -0,0 +1,313 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// AuditOptions holds options for the audit command.
+type AuditOptions struct {
+	CleanOrphans bool // Drop the orphaned relations found
+	Yes          bool // Drop them without asking
+}
+
+// NewAuditCommand creates the audit command.
+func NewAuditCommand() *cobra.Command {
+	opts := &AuditOptions{}
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Compare the models with the relations in the target database",
+		Long: `Compare the models of the project with the relations in the target database
+and report:
+
+  - missing relations: models whose table or view does not exist, either
+    never built or dropped outside LeapSQL since their last build
+  - orphaned relations: tables and views in the schemas models are built in
+    that no model, seed or source accounts for, such as the tables of
+    deleted or renamed models
+  - drift: relations whose columns or types differ from those the model's
+    SQL produces, e.g. after a model change that was not rebuilt or a
+    column added outside LeapSQL
+
+--clean-orphans drops the orphaned relations after asking for confirmation;
+--yes skips the question. Staging and temp tables of interrupted builds are
+not orphans: leapsql state cleanup drops them. The command fails if any
+issue remains.
+
+Output adapts to environment:
+  - Terminal: Styled, colored output
+  - Piped/Scripted: Markdown format (agent-friendly)
+
+Use --output to override: auto, text, markdown, json`,
+		Example: `  # Audit the target database
+  leapsql audit
+
+  # Drop the tables no model produces anymore
+  leapsql audit --clean-orphans
+
+  # Audit production in CI
+  leapsql audit --env prod --output json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runAudit(cmd, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.CleanOrphans, "clean-orphans", false, "Drop the orphaned relations, after confirmation")
+	cmd.Flags().BoolVarP(&opts.Yes, "yes", "y", false, "Drop orphaned relations without asking")
+
+	return cmd
+}
+
+type auditColumnOutput struct {
+	Column   string `json:"column"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+type auditDriftOutput struct {
+	Model    string              `json:"model"`
+	Relation string              `json:"relation"`
+	Columns  []auditColumnOutput `json:"columns"`
+}
+
+type auditMissingOutput struct {
+	Model    string `json:"model"`
+	Relation string `json:"relation"`
+	Built    bool   `json:"built"`
+}
+
+type auditOrphanOutput struct {
+	Relation string `json:"relation"`
+	Type     string `json:"type"`
+}
+
+type auditUncheckedOutput struct {
+	Model string `json:"model"`
+	Error string `json:"error"`
+}
+
+type auditOutput struct {
+	Missing   []auditMissingOutput   `json:"missing"`
+	Orphans   []auditOrphanOutput    `json:"orphans"`
+	Drift     []auditDriftOutput     `json:"drift"`
+	Unchecked []auditUncheckedOutput `json:"unchecked"`
+	Dropped   []string               `json:"dropped"`
+}
+
+func runAudit(cmd *cobra.Command, opts *AuditOptions) error {
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+	ctx := context.Background()
+
+	if opts.CleanOrphans && !opts.Yes && r.EffectiveMode() == output.ModeJSON {
+		return fmt.Errorf("--clean-orphans asks for confirmation; pass --yes with JSON output")
+	}
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	result, err := eng.Audit(ctx)
+	if err != nil {
+		return err
+	}
+
+	var dropped []string
+	if r.EffectiveMode() == output.ModeJSON {
+		if opts.CleanOrphans {
+			if dropped, err = dropOrphans(ctx, eng, result); err != nil {
+				return err
+			}
+		}
+		if err := r.JSON(auditJSON(result, dropped)); err != nil {
+			return err
+		}
+	} else {
+		if r.EffectiveMode() == output.ModeMarkdown {
+			auditMarkdown(r, result)
+		} else {
+			auditText(r, result)
+		}
+
+		if opts.CleanOrphans && len(result.Orphans) > 0 {
+			if opts.Yes || confirmDrop(r, cmd.InOrStdin(), len(result.Orphans)) {
+				dropped, err = dropOrphans(ctx, eng, result)
+				for _, relation := range dropped {
+					r.Success("Dropped " + relation)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if issues := result.Issues(); issues > 0 {
+		return fmt.Errorf("audit found %d issue(s)", issues)
+	}
+	return nil
+}
+
+// confirmDrop asks whether to drop the orphaned relations.
+func confirmDrop(r *output.Renderer, in io.Reader, count int) bool {
+	r.Println("")
+	r.Printf("Drop %d orphaned relation(s)? [y/N] ", count)
+	answers := bufio.NewScanner(in)
+	if !answers.Scan() {
+		r.Println("")
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(answers.Text()))
+	return answer == "y" || answer == "yes"
+}
+
+// dropOrphans drops the orphaned relations of an audit and removes the
+// dropped ones from it.
+func dropOrphans(ctx context.Context, eng *engine.Engine, result *engine.AuditResult) ([]string, error) {
+	dropped, err := eng.DropOrphans(ctx, result.Orphans)
+	result.Orphans = result.Orphans[len(dropped):]
+	return dropped, err
+}
+
+// relationKind returns "view" or "table".
+func relationKind(o engine.OrphanRelation) string {
+	if o.View {
+		return "view"
+	}
+	return "table"
+}
+
+// describeMissing explains why a model's relation is missing.
+func describeMissing(m engine.MissingRelation) string {
+	if m.Built {
+		return "dropped since its last build"
+	}
+	return "not built"
+}
+
+// describeColumnDrift describes a drifted column, e.g. "id: BIGINT, model
+// produces VARCHAR".
+func describeColumnDrift(c engine.ColumnDrift) string {
+	switch {
+	case c.Actual == "":
+		return fmt.Sprintf("%s: missing, model produces %s", c.Column, c.Expected)
+	case c.Expected == "":
+		return fmt.Sprintf("%s: %s, not produced by the model", c.Column, c.Actual)
+	default:
+		return fmt.Sprintf("%s: %s, model produces %s", c.Column, c.Actual, c.Expected)
+	}
+}
+
+// auditText outputs an audit in styled text format.
+func auditText(r *output.Renderer, result *engine.AuditResult) {
+	r.Header(1, "Relation Audit")
+	r.Println("")
+
+	if result.Issues() == 0 {
+		r.Success("Every model's relation exists and matches its SQL, and no orphaned relations were found")
+	}
+
+	for _, m := range result.Missing {
+		r.Error(fmt.Sprintf("%s: %s is missing (%s)", m.Model, m.Relation, describeMissing(m)))
+	}
+	for _, d := range result.Drift {
+		r.Warning(fmt.Sprintf("%s: %s has drifted", d.Model, d.Relation))
+		for _, c := range d.Columns {
+			r.Println("  - " + describeColumnDrift(c))
+		}
+	}
+	for _, o := range result.Orphans {
+		r.Warning(fmt.Sprintf("%s: orphaned %s, no model produces it", o.Relation(), relationKind(o)))
+	}
+	for _, u := range result.Unchecked {
+		r.Muted(fmt.Sprintf("%s: columns not compared: %v", u.Model, u.Err))
+	}
+
+	r.Println("")
+	r.Println(fmt.Sprintf("%d missing, %d drifted, %d orphaned", len(result.Missing), len(result.Drift), len(result.Orphans)))
+}
+
+// auditMarkdown outputs an audit in markdown format.
+func auditMarkdown(r *output.Renderer, result *engine.AuditResult) {
+	r.Println(output.FormatHeader(1, "Relation Audit"))
+	r.Println("")
+
+	if result.Issues() == 0 {
+		r.Println("Every model's relation exists and matches its SQL, and no orphaned relations were found.")
+	}
+
+	if len(result.Missing) > 0 {
+		r.Println(output.FormatHeader(2, "Missing"))
+		for _, m := range result.Missing {
+			r.Printf("- **%s** %s: %s\n", m.Model, m.Relation, describeMissing(m))
+		}
+		r.Println("")
+	}
+	if len(result.Drift) > 0 {
+		r.Println(output.FormatHeader(2, "Drift"))
+		for _, d := range result.Drift {
+			r.Printf("- **%s** %s\n", d.Model, d.Relation)
+			for _, c := range d.Columns {
+				r.Printf("  - %s\n", describeColumnDrift(c))
+			}
+		}
+		r.Println("")
+	}
+	if len(result.Orphans) > 0 {
+		r.Println(output.FormatHeader(2, "Orphans"))
+		for _, o := range result.Orphans {
+			r.Printf("- %s (%s)\n", o.Relation(), relationKind(o))
+		}
+		r.Println("")
+	}
+	if len(result.Unchecked) > 0 {
+		r.Println(output.FormatHeader(2, "Unchecked"))
+		for _, u := range result.Unchecked {
+			r.Printf("- %s: %v\n", u.Model, u.Err)
+		}
+		r.Println("")
+	}
+
+	r.Printf("**Missing:** %d, **Drifted:** %d, **Orphaned:** %d\n", len(result.Missing), len(result.Drift), len(result.Orphans))
+}
+
+// auditJSON builds the JSON output of an audit.
+func auditJSON(result *engine.AuditResult, dropped []string) auditOutput {
+	out := auditOutput{
+		Missing:   make([]auditMissingOutput, 0, len(result.Missing)),
+		Orphans:   make([]auditOrphanOutput, 0, len(result.Orphans)),
+		Drift:     make([]auditDriftOutput, 0, len(result.Drift)),
+		Unchecked: make([]auditUncheckedOutput, 0, len(result.Unchecked)),
+		Dropped:   nonNil(dropped),
+	}
+	for _, m := range result.Missing {
+		out.Missing = append(out.Missing, auditMissingOutput{Model: m.Model, Relation: m.Relation, Built: m.Built})
+	}
+	for _, o := range result.Orphans {
+		out.Orphans = append(out.Orphans, auditOrphanOutput{Relation: o.Relation(), Type: relationKind(o)})
+	}
+	for _, d := range result.Drift {
+		columns := make([]auditColumnOutput, 0, len(d.Columns))
+		for _, c := range d.Columns {
+			columns = append(columns, auditColumnOutput(c))
+		}
+		out.Drift = append(out.Drift, auditDriftOutput{Model: d.Model, Relation: d.Relation, Columns: columns})
+	}
+	for _, u := range result.Unchecked {
+		out.Unchecked = append(out.Unchecked, auditUncheckedOutput{Model: u.Model, Error: u.Err.Error()})
+	}
+	return out
+}
@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+// BackfillOptions holds options for the backfill command.
+type BackfillOptions struct {
+	Column string
+	Start  string
+	End    string
+	Chunk  string
+}
+
+// NewBackfillCommand creates the backfill command.
+func NewBackfillCommand() *cobra.Command {
+	opts := &BackfillOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "backfill <model>",
+		Short: "Rebuild an incremental model over a range of dates, chunk by chunk",
+		Long: `Rebuild the rows of an incremental model in a range of dates, one chunk
+of the range at a time.
+
+Each chunk deletes the model's rows whose --column falls in the chunk's dates
+and inserts the rows of the model's full query in them, in a transaction
+where the adapter supports one. Backfilling a range again replaces its rows
+instead of duplicating them. The range starts at --start and ends before
+--end; chunks are a day, a week or a month long. --column defaults to the
+partition column of models with partition_by.
+
+Each chunk is recorded in the state database as it commits. When a backfill
+fails or is interrupted, running the same backfill again resumes it: the
+chunks already built are skipped.
+
+Output adapts to environment:
+  - Terminal: Styled, colored output
+  - Piped/Scripted: Markdown format (agent-friendly)
+
+Use --output to override: auto, text, markdown, json`,
+		Example: `  # Backfill 2024 a day at a time
+  leapsql backfill marts.orders --column order_date --start 2024-01-01 --end 2025-01-01
+
+  # Backfill a quarter a month at a time
+  leapsql backfill marts.orders --column order_date --start 2024-01-01 --end 2024-04-01 --chunk month
+
+  # Backfill a model by its partition_by column
+  leapsql backfill marts.events --start 2024-01-01 --end 2024-02-01`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runBackfill(cmd, args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Column, "column", "", "Date column the range applies to (default: the model's partition_by column)")
+	cmd.Flags().StringVar(&opts.Start, "start", "", "First date of the range (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&opts.End, "end", "", "Date the range ends before (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&opts.Chunk, "chunk", string(core.BackfillChunkDay), "Length of the chunks: day, week or month")
+	_ = cmd.MarkFlagRequired("start")
+	_ = cmd.MarkFlagRequired("end")
+
+	return cmd
+}
+
+type backfillChunkOutput struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+	Rows  int64  `json:"rows"`
+}
+
+type backfillOutput struct {
+	Model   string                `json:"model"`
+	Column  string                `json:"column"`
+	Start   string                `json:"start"`
+	End     string                `json:"end"`
+	Chunk   string                `json:"chunk"`
+	ID      string                `json:"id"`
+	RunID   string                `json:"run_id"`
+	Status  string                `json:"status"`
+	Total   int                   `json:"total"`
+	Resumed int                   `json:"resumed"`
+	Built   []backfillChunkOutput `json:"built"`
+	Error   string                `json:"error,omitempty"`
+}
+
+func runBackfill(cmd *cobra.Command, modelPath string, opts *BackfillOptions) error {
+	start, err := time.Parse(time.DateOnly, opts.Start)
+	if err != nil {
+		return fmt.Errorf("invalid --start %q: expected YYYY-MM-DD", opts.Start)
+	}
+	end, err := time.Parse(time.DateOnly, opts.End)
+	if err != nil {
+		return fmt.Errorf("invalid --end %q: expected YYYY-MM-DD", opts.End)
+	}
+
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	cfg := cmdCtx.Cfg
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	// Interrupting a backfill stops it after the chunk being built rolls back
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	result, backfillErr := eng.Backfill(ctx, cfg.Environment, engine.BackfillOptions{
+		Model:  modelPath,
+		Column: opts.Column,
+		Start:  start,
+		End:    end,
+		Chunk:  core.BackfillChunkSize(opts.Chunk),
+	})
+	if result == nil {
+		return backfillErr
+	}
+
+	out := backfillOutput{
+		Model:   result.Backfill.ModelPath,
+		Column:  result.Backfill.Column,
+		Start:   result.Backfill.Start.Format(time.DateOnly),
+		End:     result.Backfill.End.Format(time.DateOnly),
+		Chunk:   string(result.Backfill.Chunk),
+		ID:      result.Backfill.ID,
+		RunID:   result.Run.ID,
+		Status:  string(result.Backfill.Status),
+		Total:   result.Total,
+		Resumed: result.Resumed,
+		Built:   make([]backfillChunkOutput, 0, len(result.Built)),
+		Error:   result.Backfill.Error,
+	}
+	for _, c := range result.Built {
+		out.Built = append(out.Built, backfillChunkOutput{Start: c.Start.Format(time.DateOnly), End: c.End.Format(time.DateOnly), Rows: c.RowsAffected})
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		if err := r.JSON(out); err != nil {
+			return err
+		}
+	case output.ModeMarkdown:
+		backfillMarkdown(r, out)
+	default:
+		backfillText(r, out)
+	}
+
+	return backfillErr
+}
+
+// backfillText outputs a backfill in styled text format.
+func backfillText(r *output.Renderer, out backfillOutput) {
+	r.Header(2, fmt.Sprintf("Backfill %s: %s to %s by %s", out.Model, out.Start, out.End, out.Chunk))
+
+	if out.Resumed > 0 {
+		r.Muted(fmt.Sprintf("Resumed: %d of %d chunk(s) already built", out.Resumed, out.Total))
+	}
+	for _, c := range out.Built {
+		r.StatusLine(c.Start, "success", fmt.Sprintf("%d rows", c.Rows))
+	}
+
+	r.Println("")
+	remaining := out.Total - out.Resumed - len(out.Built)
+	if out.Error != "" {
+		r.Error(out.Error)
+		r.Warning(fmt.Sprintf("%d chunk(s) left: run the same backfill again to resume", remaining))
+		return
+	}
+	r.Success(fmt.Sprintf("%d chunk(s) built", len(out.Built)))
+}
+
+// backfillMarkdown outputs a backfill in markdown format.
+func backfillMarkdown(r *output.Renderer, out backfillOutput) {
+	r.Println(output.FormatHeader(1, "Backfill "+out.Model))
+	r.Println("")
+	r.Println(output.FormatKeyValue("Range", fmt.Sprintf("%s >= %s AND %s < %s", out.Column, out.Start, out.Column, out.End)))
+	r.Println(output.FormatKeyValue("Chunk", out.Chunk))
+	r.Println(output.FormatKeyValue("Status", out.Status))
+	r.Println("")
+
+	if out.Resumed > 0 {
+		r.Printf("Resumed: %d of %d chunk(s) already built.\n\n", out.Resumed, out.Total)
+	}
+	for _, c := range out.Built {
+		r.Printf("- %s: %d rows\n", c.Start, c.Rows)
+	}
+	if len(out.Built) > 0 {
+		r.Println("")
+	}
+
+	if out.Error != "" {
+		r.Printf("**Error:** %s\n", out.Error)
+		r.Printf("**Chunks left:** %d\n", out.Total-out.Resumed-len(out.Built))
+		return
+	}
+	r.Printf("**Chunks built:** %d\n", len(out.Built))
+}
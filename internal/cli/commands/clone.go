@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// CloneOptions holds options for the clone command.
+type CloneOptions struct {
+	Select string
+	From   string
+}
+
+// NewCloneCommand creates the clone command.
+func NewCloneCommand() *cobra.Command {
+	opts := &CloneOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "clone",
+		Short: "Clone production tables into the target database",
+		Long: `Populate a development sandbox with the production tables of your models.
+
+Each selected model's table is copied from the --from environment's database
+into the current target, replacing the existing table. Adapters that support
+zero-copy clones clone without copying data; others copy the table with
+CREATE TABLE AS SELECT. Views are skipped: run rebuilds them from the cloned
+tables.
+
+By default all models are cloned. Use --select to clone specific models, by
+name or with a selector expression over frontmatter tags and groups.
+
+Output adapts to environment:
+  - Terminal: Styled, colored output
+  - Piped/Scripted: Markdown format (agent-friendly)
+
+Use --output to override: auto, text, markdown, json`,
+		Example: `  # Clone every model from prod into the dev database
+  leapsql clone
+
+  # Clone the staging models only
+  leapsql clone --select "staging.*"
+
+  # Clone from another environment
+  leapsql clone --from staging --env dev`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runClone(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Select, "select", "s", "", "Models to clone (name or selector expression)")
+	cmd.Flags().StringVar(&opts.From, "from", "prod", "Environment to clone from")
+
+	return cmd
+}
+
+type cloneModelOutput struct {
+	Model   string `json:"model"`
+	Method  string `json:"method,omitempty"`
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type cloneOutput struct {
+	From   string             `json:"from"`
+	Models []cloneModelOutput `json:"models"`
+	Failed int                `json:"failed"`
+}
+
+func runClone(cmd *cobra.Command, opts *CloneOptions) error {
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	cfg := cmdCtx.Cfg
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	ctx := context.Background()
+
+	database, ok := cfg.EnvironmentDatabase(opts.From)
+	if !ok {
+		return fmt.Errorf("cannot clone: environment %q has no target database in leapsql.yaml", opts.From)
+	}
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	var paths []string
+	if opts.Select != "" {
+		if paths, err = resolveSelection(eng, opts.Select); err != nil {
+			return err
+		}
+	} else {
+		paths = slices.Sorted(maps.Keys(eng.GetModels()))
+	}
+
+	results, err := eng.Clone(ctx, database, paths)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, res := range results {
+		if res.Error != nil {
+			failed++
+		}
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		if err := cloneJSON(r, opts.From, results, failed); err != nil {
+			return err
+		}
+	case output.ModeMarkdown:
+		cloneMarkdown(r, opts.From, results, failed)
+	default:
+		cloneText(r, opts.From, results, failed)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d model(s) failed to clone", failed)
+	}
+	return nil
+}
+
+// cloneText outputs clone results in styled text format.
+func cloneText(r *output.Renderer, from string, results []engine.CloneResult, failed int) {
+	r.Header(2, "Cloned from "+from)
+
+	for _, res := range results {
+		switch {
+		case res.Error != nil:
+			r.Error(fmt.Sprintf("%s: %v", res.Model, res.Error))
+		case res.Skipped != "":
+			r.Muted(fmt.Sprintf("%s skipped (%s)", res.Model, res.Skipped))
+		default:
+			r.StatusLine(res.Model, "success", res.Method)
+		}
+	}
+
+	r.Println("")
+	if failed > 0 {
+		r.Warning(fmt.Sprintf("%d model(s) failed to clone", failed))
+	}
+}
+
+// cloneMarkdown outputs clone results in markdown format.
+func cloneMarkdown(r *output.Renderer, from string, results []engine.CloneResult, failed int) {
+	r.Println(output.FormatHeader(1, "Clone"))
+	r.Println("")
+	r.Println(output.FormatKeyValue("From", from))
+	r.Println("")
+
+	for _, res := range results {
+		switch {
+		case res.Error != nil:
+			r.Printf("- %s: **failed** (%v)\n", res.Model, res.Error)
+		case res.Skipped != "":
+			r.Printf("- %s: skipped (%s)\n", res.Model, res.Skipped)
+		default:
+			r.Printf("- %s: %s\n", res.Model, res.Method)
+		}
+	}
+
+	r.Println("")
+	r.Printf("**Failed:** %d\n", failed)
+}
+
+// cloneJSON outputs clone results in JSON format.
+func cloneJSON(r *output.Renderer, from string, results []engine.CloneResult, failed int) error {
+	models := make([]cloneModelOutput, 0, len(results))
+	for _, res := range results {
+		m := cloneModelOutput{Model: res.Model, Method: res.Method, Skipped: res.Skipped}
+		if res.Error != nil {
+			m.Error = res.Error.Error()
+		}
+		models = append(models, m)
+	}
+
+	return r.JSON(cloneOutput{From: from, Models: models, Failed: failed})
+}
@@ -44,7 +44,7 @@ func TestNewRunCommand(t *testing.T) {
 	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
 
 	// Verify flags exist
-	flags := []string{"select", "downstream", "json"}
+	flags := []string{"select", "downstream", "json", "skip-fresh"}
 	for _, flag := range flags {
 		assert.NotNil(t, cmd.Flags().Lookup(flag), "flag %q should exist", flag)
 	}
@@ -61,3 +61,92 @@ func TestNewSeedCommand(t *testing.T) {
 	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
 	assert.NotEmpty(t, cmd.Example, "Example should not be empty")
 }
+
+func TestNewCloneCommand(t *testing.T) {
+	cmd := NewCloneCommand()
+
+	assert.Equal(t, "clone", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+
+	flags := []string{"select", "from"}
+	for _, flag := range flags {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), "flag %q should exist", flag)
+	}
+}
+
+func TestNewBackfillCommand(t *testing.T) {
+	cmd := NewBackfillCommand()
+
+	assert.Equal(t, "backfill <model>", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+	assert.NotEmpty(t, cmd.Example, "Example should not be empty")
+
+	flags := []string{"column", "start", "end", "chunk"}
+	for _, flag := range flags {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), "flag %q should exist", flag)
+	}
+}
+
+func TestNewDiffCommand(t *testing.T) {
+	cmd := NewDiffCommand()
+
+	assert.Equal(t, "diff <model>", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+
+	flags := []string{"target-a", "target-b", "sample"}
+	for _, flag := range flags {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), "flag %q should exist", flag)
+	}
+}
+
+func TestNewStateCommand(t *testing.T) {
+	cmd := NewStateCommand()
+
+	assert.Equal(t, "state", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+
+	costs, _, err := cmd.Find([]string{"costs"})
+	assert.NoError(t, err)
+	assert.Equal(t, "costs", costs.Use)
+
+	flags := []string{"by", "days", "interval", "all-envs"}
+	for _, flag := range flags {
+		assert.NotNil(t, costs.Flags().Lookup(flag), "flag %q should exist", flag)
+	}
+
+	cleanup, _, err := cmd.Find([]string{"cleanup"})
+	assert.NoError(t, err)
+	assert.Equal(t, "cleanup", cleanup.Use)
+	assert.NotNil(t, cleanup.Flags().Lookup("lock-timeout"))
+}
+
+func TestNewFreshnessCommand(t *testing.T) {
+	cmd := NewFreshnessCommand()
+
+	assert.Equal(t, "freshness", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+	assert.NotEmpty(t, cmd.Long, "Long should not be empty")
+	assert.NotEmpty(t, cmd.Example, "Example should not be empty")
+}
+
+func TestNewAuditCommand(t *testing.T) {
+	cmd := NewAuditCommand()
+
+	assert.Equal(t, "audit", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+	assert.NotEmpty(t, cmd.Example, "Example should not be empty")
+
+	flags := []string{"clean-orphans", "yes"}
+	for _, flag := range flags {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), "flag %q should exist", flag)
+	}
+}
+
+func TestNewConsoleCommand(t *testing.T) {
+	cmd := NewConsoleCommand()
+
+	assert.Equal(t, "console", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+	assert.NotEmpty(t, cmd.Example, "Example should not be empty")
+	assert.NotNil(t, cmd.Flags().Lookup("format"), "flag format should exist")
+}
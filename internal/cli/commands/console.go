@@ -0,0 +1,311 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+// ConsoleOptions holds options for the console command.
+type ConsoleOptions struct {
+	Format string
+}
+
+// NewConsoleCommand creates the console command.
+func NewConsoleCommand() *cobra.Command {
+	opts := &ConsoleOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "console",
+		Short: "Interactive SQL console with model context",
+		Long: `Start an interactive console with the project loaded.
+
+SQL typed in the console is rendered like a model before it runs against the
+target database, so {{ ref('model') }} resolves to the model's table. Dot
+commands preview a model's compiled SQL, list its columns and walk its
+lineage. Model and column names from the state store tab-complete.`,
+		Example: `  # Start the console
+  leapsql console
+
+  # Render results as JSON
+  leapsql console --format json
+
+  # In the console
+  leapsql> SELECT count(*) FROM {{ ref('stg_orders') }};
+  leapsql> .compile marts.revenue
+  leapsql> .lineage marts.revenue total_amount`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runConsole(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Format, "format", "f", "table", "Output format: table, json, csv, md")
+
+	return cmd
+}
+
+func runConsole(cmd *cobra.Command, opts *ConsoleOptions) error {
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+	if err := eng.EnsureConnected(cmd.Context()); err != nil {
+		return err
+	}
+
+	queryOpts := &QueryOptions{Format: opts.Format}
+	return replLoop(cmd, replConfig{
+		historyFile: filepath.Join(filepath.Dir(resolveStatePath(cmdCtx.Cfg)), "console_history"),
+		completer:   newConsoleCompleter(eng),
+		banner:      fmt.Sprintf("LeapSQL Console (%d models)", len(eng.GetModels())),
+		dotCommand: func(line string) bool {
+			return handleConsoleCommand(cmd, eng, line, queryOpts)
+		},
+		execute: func(query string) error {
+			rendered, err := eng.RenderSQL(query)
+			if err != nil {
+				return err
+			}
+			return executeOnTarget(cmd, eng, rendered, queryOpts)
+		},
+	})
+}
+
+// handleConsoleCommand handles a console dot-command.
+func handleConsoleCommand(cmd *cobra.Command, eng *engine.Engine, line string, opts *QueryOptions) bool {
+	parts := strings.Fields(line)
+	command := strings.ToLower(parts[0])
+	w := cmd.OutOrStdout()
+
+	var err error
+	switch command {
+	case ".quit", ".exit":
+		return true
+
+	case ".help":
+		printConsoleHelp(w)
+
+	case ".models":
+		paths := make([]string, 0, len(eng.GetModels()))
+		for path := range eng.GetModels() {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			_, _ = fmt.Fprintln(w, path)
+		}
+
+	case ".compile", ".columns", ".lineage":
+		if len(parts) < 2 {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Usage: %s <model>\n", command)
+			return true
+		}
+		switch command {
+		case ".compile":
+			err = consoleCompile(w, eng, parts[1])
+		case ".columns":
+			err = consoleColumns(cmd, eng, parts[1], opts.Format)
+		default:
+			column := ""
+			if len(parts) > 2 {
+				column = parts[2]
+			}
+			err = consoleLineage(w, eng, parts[1], column)
+		}
+
+	case ".clear":
+		_, _ = fmt.Fprint(w, "\033[H\033[2J")
+
+	default:
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Unknown command: %s (type .help for commands)\n", command)
+	}
+
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+	}
+	return true
+}
+
+// consoleCompile prints a model's compiled SQL.
+func consoleCompile(w io.Writer, eng *engine.Engine, modelPath string) error {
+	sql, err := eng.RenderModel(modelPath)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w, strings.TrimSpace(sql))
+	return nil
+}
+
+// consoleColumns renders a model's columns and their sources from the state
+// store.
+func consoleColumns(cmd *cobra.Command, eng *engine.Engine, modelPath, format string) error {
+	if _, ok := eng.GetModels()[modelPath]; !ok {
+		return fmt.Errorf("model not found: %s", modelPath)
+	}
+	columns, err := eng.GetStateStore().GetModelColumns(modelPath)
+	if err != nil {
+		return err
+	}
+
+	cols := []string{"column", "transform", "sources"}
+	results := make([]map[string]any, len(columns))
+	for i, c := range columns {
+		results[i] = map[string]any{
+			"column":    c.Name,
+			"transform": string(c.TransformType),
+			"sources":   strings.Join(formatColumnSources(c.Sources), ", "),
+		}
+	}
+	return renderRows(cmd.OutOrStdout(), cols, results, format)
+}
+
+// consoleLineage prints a model's direct upstream and downstream models, or
+// with a column, the upstream columns it is derived from.
+func consoleLineage(w io.Writer, eng *engine.Engine, modelPath, column string) error {
+	if _, ok := eng.GetModels()[modelPath]; !ok {
+		return fmt.Errorf("model not found: %s", modelPath)
+	}
+
+	if column == "" {
+		graph := eng.GetGraph()
+		for _, parent := range graph.GetParents(modelPath) {
+			_, _ = fmt.Fprintf(w, "  ← %s (%s)\n", parent, getNodeType(eng, parent))
+		}
+		_, _ = fmt.Fprintf(w, "  %s\n", modelPath)
+		for _, child := range graph.GetChildren(modelPath) {
+			_, _ = fmt.Fprintf(w, "  → %s\n", child)
+		}
+		return nil
+	}
+
+	columns, err := eng.GetStateStore().GetModelColumns(modelPath)
+	if err != nil {
+		return err
+	}
+	for _, c := range columns {
+		if !strings.EqualFold(c.Name, column) {
+			continue
+		}
+		sources := formatColumnSources(c.Sources)
+		if len(sources) == 0 {
+			_, _ = fmt.Fprintf(w, "  %s.%s has no upstream columns\n", modelPath, c.Name)
+			return nil
+		}
+		for _, source := range sources {
+			_, _ = fmt.Fprintf(w, "  ← %s\n", source)
+		}
+		_, _ = fmt.Fprintf(w, "  %s.%s\n", modelPath, c.Name)
+		return nil
+	}
+	return fmt.Errorf("column not found: %s.%s", modelPath, column)
+}
+
+func printConsoleHelp(w io.Writer) {
+	help := `
+Commands:
+  .help                      Show this help message
+  .models                    List models
+  .compile <model>           Show a model's compiled SQL
+  .columns <model>           Show a model's columns and their sources
+  .lineage <model> [column]  Show a model's parents and children, or a column's sources
+  .clear                     Clear the screen
+  .quit / .exit              Exit the console
+
+Tips:
+  - SQL statements must end with a semicolon (;)
+  - Use {{ ref('model') }} to query a model's table
+  - Tab completion works for model and column names
+`
+	_, _ = fmt.Fprintln(w, help)
+}
+
+// formatColumnSources formats the upstream columns of a column as
+// table.column, with the field path of struct or JSON references.
+func formatColumnSources(sources []core.SourceRef) []string {
+	formatted := make([]string, 0, len(sources))
+	for _, s := range sources {
+		name := s.Column
+		if s.Table != "" {
+			name = s.Table + "." + name
+		}
+		if s.Field != "" {
+			name += "." + s.Field
+		}
+		formatted = append(formatted, name)
+	}
+	return formatted
+}
+
+// consoleCompleter completes the word before the cursor with dot-commands
+// and the model and column names recorded in the state store.
+type consoleCompleter struct {
+	words []string
+}
+
+// newConsoleCompleter collects completions from the engine's state store.
+// Names that cannot be read are left out, as completion is not critical.
+func newConsoleCompleter(eng *engine.Engine) *consoleCompleter {
+	words := []string{".help", ".models", ".compile", ".columns", ".lineage", ".clear", ".quit", ".exit"}
+	store := eng.GetStateStore()
+	if models, err := store.ListModels(); err == nil {
+		for _, m := range models {
+			words = append(words, m.Path, m.Name)
+			columns, err := store.GetModelColumns(m.Path)
+			if err != nil {
+				continue
+			}
+			for _, c := range columns {
+				words = append(words, c.Name)
+			}
+		}
+	}
+	return newWordCompleter(words)
+}
+
+// newWordCompleter creates a completer over a set of words.
+func newWordCompleter(words []string) *consoleCompleter {
+	sort.Strings(words)
+	return &consoleCompleter{words: slices.Compact(words)}
+}
+
+// Do implements readline.AutoCompleter, returning the suffixes of the words
+// starting with the word before the cursor.
+func (c *consoleCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	start := pos
+	for start > 0 && isCompletionRune(line[start-1]) {
+		start--
+	}
+	prefix := string(line[start:pos])
+	if prefix == "" {
+		return nil, 0
+	}
+
+	var candidates [][]rune
+	for _, word := range c.words {
+		if len(word) > len(prefix) && strings.HasPrefix(word, prefix) {
+			candidates = append(candidates, []rune(word[len(prefix):]))
+		}
+	}
+	return candidates, len([]rune(prefix))
+}
+
+// isCompletionRune reports whether a rune can be part of a completed word:
+// identifiers, dotted model paths and dot-commands.
+func isCompletionRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleCompleter(t *testing.T) {
+	c := newWordCompleter([]string{".compile", ".columns", "staging.stg_orders", "stg_orders", "stg_order_items", "order_id", "order_id"})
+
+	tests := []struct {
+		name       string
+		line       string
+		want       []string
+		wantLength int
+	}{
+		{name: "dot-command", line: ".co", want: []string{"lumns", "mpile"}, wantLength: 3},
+		{name: "model name in ref", line: "SELECT * FROM {{ ref('stg_ord", want: []string{"er_items", "ers"}, wantLength: 7},
+		{name: "model path", line: "SELECT * FROM staging.s", want: []string{"tg_orders"}, wantLength: 9},
+		{name: "column after comma", line: "SELECT id,ord", want: []string{"er_id"}, wantLength: 3},
+		{name: "complete word", line: "SELECT order_id", wantLength: len("order_id")},
+		{name: "no word", line: "SELECT ", wantLength: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := []rune(tt.line)
+			candidates, length := c.Do(line, len(line))
+
+			var got []string
+			for _, cand := range candidates {
+				got = append(got, string(cand))
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantLength, length)
+		})
+	}
+}
+
+func TestFormatColumnSources(t *testing.T) {
+	sources := []core.SourceRef{
+		{Table: "staging.stg_orders", Column: "amount"},
+		{Column: "status"},
+		{Table: "raw_events", Column: "payload", Field: "user.id"},
+	}
+	assert.Equal(t, []string{"staging.stg_orders.amount", "status", "raw_events.payload.user.id"}, formatColumnSources(sources))
+}
+
+func TestConsoleClear(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&buf)
+
+	assert.True(t, handleConsoleCommand(cmd, nil, ".clear", &QueryOptions{}))
+	assert.Equal(t, "\033[H\033[2J", buf.String(), ".clear writes to the command's output")
+}
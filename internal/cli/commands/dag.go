@@ -20,6 +20,8 @@ type GraphQuerier interface {
 
 // NewDAGCommand creates the dag command.
 func NewDAGCommand() *cobra.Command {
+	var criticalPath bool
+
 	cmd := &cobra.Command{
 		Use:   "dag",
 		Short: "Show the dependency graph",
@@ -28,6 +30,12 @@ func NewDAGCommand() *cobra.Command {
 Models are grouped by execution level, showing which models can run
 in parallel and their dependency relationships.
 
+With --critical-path, shows the chain of dependent models with the longest
+expected runtime instead, using each model's average execution time over
+the last 10 runs. A run takes at least that long however many models run
+in parallel, so speeding up its slowest model (the bottleneck) shortens
+runs the most.
+
 Output adapts to environment:
   - Terminal: Styled output with colors
   - Piped/Scripted: Markdown format (agent-friendly)`,
@@ -37,16 +45,24 @@ Output adapts to environment:
   # Show DAG with verbose model info
   leapsql dag -v
 
+  # Show the critical path of a run
+  leapsql dag --critical-path
+
   # Output as JSON
   leapsql dag --output json
 
   # Output as Markdown
   leapsql dag --output markdown`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if criticalPath {
+				return runCriticalPath(cmd)
+			}
 			return runDAG(cmd)
 		},
 	}
 
+	cmd.Flags().BoolVar(&criticalPath, "critical-path", false, "Show the chain of models with the longest expected runtime")
+
 	return cmd
 }
 
@@ -73,19 +89,27 @@ func runDAG(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to get execution levels: %w", err)
 	}
 
+	// Files read by models through table functions such as read_parquet
+	files := make(map[string][]string)
+	for path, m := range eng.GetModels() {
+		if len(m.Files) > 0 {
+			files[path] = m.Files
+		}
+	}
+
 	effectiveMode := r.EffectiveMode()
 	switch effectiveMode {
 	case output.ModeJSON:
 		return dagJSON(r, graph, levels)
 	case output.ModeMarkdown:
-		return dagMarkdown(r, graph, levels)
+		return dagMarkdown(r, graph, levels, files)
 	default:
-		return dagText(r, graph, levels)
+		return dagText(r, graph, levels, files)
 	}
 }
 
 // dagText outputs DAG in styled text format.
-func dagText(r *output.Renderer, graph GraphQuerier, levels [][]string) error {
+func dagText(r *output.Renderer, graph GraphQuerier, levels [][]string, files map[string][]string) error {
 	styles := r.Styles()
 
 	r.Header(1, "Dependency Graph")
@@ -103,6 +127,9 @@ func dagText(r *output.Renderer, graph GraphQuerier, levels [][]string) error {
 			if len(children) > 0 {
 				r.Printf("    %s %s\n", styles.Muted.Render("used by:"), strings.Join(children, ", "))
 			}
+			if len(files[model]) > 0 {
+				r.Printf("    %s %s\n", styles.Muted.Render("reads files:"), strings.Join(files[model], ", "))
+			}
 		}
 		r.Println("")
 	}
@@ -113,7 +140,7 @@ func dagText(r *output.Renderer, graph GraphQuerier, levels [][]string) error {
 }
 
 // dagMarkdown outputs DAG in markdown format.
-func dagMarkdown(r *output.Renderer, graph GraphQuerier, levels [][]string) error {
+func dagMarkdown(r *output.Renderer, graph GraphQuerier, levels [][]string, files map[string][]string) error {
 	r.Println(output.FormatHeader(1, "Dependency Graph"))
 	r.Println("")
 
@@ -135,6 +162,9 @@ func dagMarkdown(r *output.Renderer, graph GraphQuerier, levels [][]string) erro
 			if len(children) > 0 {
 				r.Printf("  - used by: %s\n", strings.Join(children, ", "))
 			}
+			if len(files[model]) > 0 {
+				r.Printf("  - reads files: %s\n", strings.Join(files[model], ", "))
+			}
 		}
 		r.Println("")
 	}
@@ -175,3 +205,117 @@ func dagJSON(r *output.Renderer, graph GraphQuerier, levels [][]string) error {
 	enc.SetIndent("", "  ")
 	return enc.Encode(dagOutput)
 }
+
+type criticalPathStepOutput struct {
+	Model      string `json:"model"`
+	ExpectedMS int64  `json:"expected_ms"`
+	Builds     int    `json:"builds"`
+}
+
+type criticalPathOutput struct {
+	Steps      []criticalPathStepOutput `json:"steps"`
+	TotalMS    int64                    `json:"total_ms"`
+	Bottleneck string                   `json:"bottleneck,omitempty"`
+}
+
+func runCriticalPath(cmd *cobra.Command) error {
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	cp, err := eng.CriticalPath()
+	if err != nil {
+		return fmt.Errorf("failed to compute critical path: %w", err)
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		return criticalPathJSON(r, cp)
+	case output.ModeMarkdown:
+		criticalPathMarkdown(r, cp)
+	default:
+		criticalPathText(r, cp)
+	}
+	return nil
+}
+
+// criticalPathStep describes a step's expected runtime, e.g. "1.2s (avg of 10 builds)".
+func criticalPathStep(step engine.CriticalPathStep) string {
+	if step.Builds == 0 {
+		return "no recent builds"
+	}
+	return fmt.Sprintf("%s (avg of %d builds)", formatMS(step.ExpectedMS), step.Builds)
+}
+
+// criticalPathText outputs the critical path in styled text format.
+func criticalPathText(r *output.Renderer, cp *engine.CriticalPath) {
+	styles := r.Styles()
+
+	r.Header(1, "Critical Path")
+
+	for i, step := range cp.Steps {
+		line := fmt.Sprintf("  %d. %s  %s", i+1, styles.ModelPath.Render(step.Model), styles.Muted.Render(criticalPathStep(step)))
+		if step.Model == cp.Bottleneck {
+			line += "  " + styles.Warning.Render("bottleneck")
+		}
+		r.Println(line)
+	}
+	r.Println("")
+
+	if cp.Bottleneck == "" {
+		r.Println(styles.Muted.Render("No timing history yet: run models with 'leapsql run' to record their runtimes"))
+		return
+	}
+	r.Println(styles.Muted.Render(fmt.Sprintf("Expected runtime: %s over %d models", formatMS(cp.TotalMS), len(cp.Steps))))
+}
+
+// criticalPathMarkdown outputs the critical path in markdown format.
+func criticalPathMarkdown(r *output.Renderer, cp *engine.CriticalPath) {
+	r.Println(output.FormatHeader(1, "Critical Path"))
+	r.Println("")
+
+	for i, step := range cp.Steps {
+		line := fmt.Sprintf("%d. %s: %s", i+1, step.Model, criticalPathStep(step))
+		if step.Model == cp.Bottleneck {
+			line += " (bottleneck)"
+		}
+		r.Println(line)
+	}
+	r.Println("")
+
+	r.Println(output.FormatHeader(2, "Summary"))
+	r.Println(output.FormatKeyValue("Expected Runtime", formatMS(cp.TotalMS)))
+	r.Println(output.FormatKeyValue("Models", fmt.Sprintf("%d", len(cp.Steps))))
+	if cp.Bottleneck != "" {
+		r.Println(output.FormatKeyValue("Bottleneck", cp.Bottleneck))
+	}
+}
+
+// criticalPathJSON outputs the critical path in JSON format.
+func criticalPathJSON(r *output.Renderer, cp *engine.CriticalPath) error {
+	out := criticalPathOutput{
+		Steps:      make([]criticalPathStepOutput, 0, len(cp.Steps)),
+		TotalMS:    cp.TotalMS,
+		Bottleneck: cp.Bottleneck,
+	}
+	for _, step := range cp.Steps {
+		out.Steps = append(out.Steps, criticalPathStepOutput{
+			Model:      step.Model,
+			ExpectedMS: step.ExpectedMS,
+			Builds:     step.Builds,
+		})
+	}
+
+	enc := json.NewEncoder(r.Writer())
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,305 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/config"
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// DiffOptions holds options for the diff command.
+type DiffOptions struct {
+	TargetA string
+	TargetB string
+	Sample  int
+}
+
+// NewDiffCommand creates the diff command.
+func NewDiffCommand() *cobra.Command {
+	opts := &DiffOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "diff <model>",
+		Short: "Compare a model's data between two environments",
+		Long: `Compare a model's table in the databases of two environments.
+
+The report shows the row counts, per-column aggregates (nulls, distinct
+values, min, max and, for numeric columns, sum) and a sample of the rows
+that have no identical row in the other environment. Use it to check that a
+refactor leaves the data unchanged.
+
+Each environment's database is its target database in leapsql.yaml. Both
+databases must be DuckDB databases.
+
+Output adapts to environment:
+  - Terminal: Styled, colored output
+  - Piped/Scripted: Markdown format (agent-friendly)
+
+Use --output to override: auto, text, markdown, json`,
+		Example: `  # Compare a model between dev and prod
+  leapsql diff marts.customer_summary
+
+  # Compare two named environments
+  leapsql diff marts.customer_summary --target-a staging --target-b prod
+
+  # Sample more mismatched rows, as JSON
+  leapsql diff marts.customer_summary --sample 20 --output json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDiff(cmd, args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.TargetA, "target-a", config.DefaultEnv, "First environment to compare")
+	cmd.Flags().StringVar(&opts.TargetB, "target-b", "prod", "Second environment to compare")
+	cmd.Flags().IntVar(&opts.Sample, "sample", 5, "Maximum mismatched rows to show per environment")
+
+	return cmd
+}
+
+type diffStatsOutput struct {
+	Nulls    int64  `json:"nulls"`
+	Distinct int64  `json:"distinct"`
+	Min      string `json:"min"`
+	Max      string `json:"max"`
+	Sum      string `json:"sum,omitempty"`
+}
+
+type diffColumnOutput struct {
+	Name    string           `json:"name"`
+	TypeA   string           `json:"type_a,omitempty"`
+	TypeB   string           `json:"type_b,omitempty"`
+	A       *diffStatsOutput `json:"a,omitempty"`
+	B       *diffStatsOutput `json:"b,omitempty"`
+	Changed bool             `json:"changed"`
+}
+
+type diffOutput struct {
+	Model     string             `json:"model"`
+	TargetA   string             `json:"target_a"`
+	TargetB   string             `json:"target_b"`
+	Identical bool               `json:"identical"`
+	RowsA     int64              `json:"rows_a"`
+	RowsB     int64              `json:"rows_b"`
+	Columns   []diffColumnOutput `json:"columns"`
+	Compared  []string           `json:"compared"`
+	OnlyInA   int64              `json:"only_in_a"`
+	OnlyInB   int64              `json:"only_in_b"`
+	SampleA   [][]string         `json:"sample_a"`
+	SampleB   [][]string         `json:"sample_b"`
+}
+
+func runDiff(cmd *cobra.Command, modelPath string, opts *DiffOptions) error {
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	cfg := cmdCtx.Cfg
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	databaseA, ok := environmentDatabase(cfg, opts.TargetA)
+	if !ok {
+		return fmt.Errorf("cannot diff: environment %q has no target database in leapsql.yaml", opts.TargetA)
+	}
+	databaseB, ok := environmentDatabase(cfg, opts.TargetB)
+	if !ok {
+		return fmt.Errorf("cannot diff: environment %q has no target database in leapsql.yaml", opts.TargetB)
+	}
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	result, err := eng.Diff(context.Background(), modelPath, engine.DiffOptions{
+		DatabaseA:  databaseA,
+		DatabaseB:  databaseB,
+		SampleSize: opts.Sample,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		return diffJSON(r, opts, result)
+	case output.ModeMarkdown:
+		diffMarkdown(r, opts, result)
+	default:
+		diffText(r, opts, result)
+	}
+	return nil
+}
+
+// environmentDatabase returns the target database of env. The current
+// environment falls back to the active target when it has no environments entry.
+func environmentDatabase(cfg *config.Config, env string) (string, bool) {
+	if database, ok := cfg.EnvironmentDatabase(env); ok {
+		return database, true
+	}
+	if env != cfg.Environment {
+		return "", false
+	}
+	if cfg.Target != nil && cfg.Target.Database != "" {
+		return cfg.Target.Database, true
+	}
+	return cfg.DatabasePath, cfg.DatabasePath != ""
+}
+
+// diffText outputs a diff in styled text format.
+func diffText(r *output.Renderer, opts *DiffOptions, result *engine.DiffResult) {
+	r.Header(1, fmt.Sprintf("Diff: %s (%s vs %s)", result.Model, opts.TargetA, opts.TargetB))
+	r.Println("")
+
+	if result.Identical() {
+		r.Success(fmt.Sprintf("Identical: %d rows", result.RowsA))
+		return
+	}
+
+	r.Println(fmt.Sprintf("Rows: %d vs %d", result.RowsA, result.RowsB))
+	r.Println("")
+
+	r.Header(2, "Columns")
+	changed := 0
+	for _, col := range result.Columns {
+		if !col.Changed() {
+			continue
+		}
+		changed++
+		r.Warning(col.Name)
+		for _, line := range columnDiffLines(col, opts) {
+			r.Muted("  " + line)
+		}
+	}
+	if changed == 0 {
+		r.Muted("No column differences")
+	}
+	r.Println("")
+
+	r.Header(2, "Rows")
+	if len(result.Compared) == 0 {
+		r.Muted("No common columns to compare rows")
+		return
+	}
+	r.Println(fmt.Sprintf("Only in %s: %d", opts.TargetA, result.OnlyInA))
+	for _, row := range result.SampleA {
+		r.Muted("  " + strings.Join(row, ", "))
+	}
+	r.Println(fmt.Sprintf("Only in %s: %d", opts.TargetB, result.OnlyInB))
+	for _, row := range result.SampleB {
+		r.Muted("  " + strings.Join(row, ", "))
+	}
+}
+
+// diffMarkdown outputs a diff in markdown format.
+func diffMarkdown(r *output.Renderer, opts *DiffOptions, result *engine.DiffResult) {
+	r.Println(output.FormatHeader(1, "Diff: "+result.Model))
+	r.Println("")
+	r.Println(output.FormatKeyValue("Environments", opts.TargetA+" vs "+opts.TargetB))
+	r.Println(output.FormatKeyValue("Identical", fmt.Sprintf("%t", result.Identical())))
+	r.Println(output.FormatKeyValue("Rows", fmt.Sprintf("%d vs %d", result.RowsA, result.RowsB)))
+	r.Println("")
+
+	r.Println(output.FormatHeader(2, "Columns"))
+	r.Println("")
+	r.Printf("| Column | Changed | %s | %s |\n", opts.TargetA, opts.TargetB)
+	r.Println("|--------|---------|------|------|")
+	for _, col := range result.Columns {
+		r.Printf("| %s | %t | %s | %s |\n", col.Name, col.Changed(),
+			formatColumnStats(col.TypeA, col.A), formatColumnStats(col.TypeB, col.B))
+	}
+	r.Println("")
+
+	r.Println(output.FormatHeader(2, "Rows"))
+	r.Println("")
+	if len(result.Compared) == 0 {
+		r.Println("No common columns to compare rows.")
+		return
+	}
+	diffMarkdownSample(r, opts.TargetA, result.OnlyInA, result.Compared, result.SampleA)
+	diffMarkdownSample(r, opts.TargetB, result.OnlyInB, result.Compared, result.SampleB)
+}
+
+// diffMarkdownSample outputs the rows only found in one environment as a markdown table.
+func diffMarkdownSample(r *output.Renderer, env string, count int64, cols []string, sample [][]string) {
+	r.Println(output.FormatKeyValue("Only in "+env, fmt.Sprintf("%d", count)))
+	r.Println("")
+	if len(sample) == 0 {
+		return
+	}
+
+	seps := make([]string, len(cols))
+	for i := range seps {
+		seps[i] = "---"
+	}
+	r.Printf("| %s |\n", strings.Join(cols, " | "))
+	r.Printf("| %s |\n", strings.Join(seps, " | "))
+	for _, row := range sample {
+		r.Printf("| %s |\n", strings.Join(row, " | "))
+	}
+	r.Println("")
+}
+
+// diffJSON outputs a diff in JSON format.
+func diffJSON(r *output.Renderer, opts *DiffOptions, result *engine.DiffResult) error {
+	columns := make([]diffColumnOutput, 0, len(result.Columns))
+	for _, col := range result.Columns {
+		columns = append(columns, diffColumnOutput{
+			Name:    col.Name,
+			TypeA:   col.TypeA,
+			TypeB:   col.TypeB,
+			A:       toDiffStatsOutput(col.A),
+			B:       toDiffStatsOutput(col.B),
+			Changed: col.Changed(),
+		})
+	}
+
+	return r.JSON(diffOutput{
+		Model:     result.Model,
+		TargetA:   opts.TargetA,
+		TargetB:   opts.TargetB,
+		Identical: result.Identical(),
+		RowsA:     result.RowsA,
+		RowsB:     result.RowsB,
+		Columns:   columns,
+		Compared:  result.Compared,
+		OnlyInA:   result.OnlyInA,
+		OnlyInB:   result.OnlyInB,
+		SampleA:   result.SampleA,
+		SampleB:   result.SampleB,
+	})
+}
+
+func toDiffStatsOutput(stats *engine.ColumnStats) *diffStatsOutput {
+	if stats == nil {
+		return nil
+	}
+	out := diffStatsOutput(*stats)
+	return &out
+}
+
+// columnDiffLines describes how a column differs, one line per environment.
+func columnDiffLines(col engine.ColumnDiff, opts *DiffOptions) []string {
+	return []string{
+		fmt.Sprintf("%s: %s", opts.TargetA, formatColumnStats(col.TypeA, col.A)),
+		fmt.Sprintf("%s: %s", opts.TargetB, formatColumnStats(col.TypeB, col.B)),
+	}
+}
+
+// formatColumnStats formats a column's type and aggregates on one line.
+func formatColumnStats(typ string, stats *engine.ColumnStats) string {
+	if stats == nil {
+		return "missing"
+	}
+	s := fmt.Sprintf("%s nulls=%d distinct=%d min=%s max=%s", typ, stats.Nulls, stats.Distinct, stats.Min, stats.Max)
+	if stats.Sum != "" {
+		s += " sum=" + stats.Sum
+	}
+	return s
+}
@@ -99,6 +99,9 @@ func discoverText(r *output.Renderer, result *engine.DiscoveryResult, statePath
 			if result.ModelsDeleted > 0 {
 				r.Printf("  Deleted: %d\n", result.ModelsDeleted)
 			}
+			if result.ModelsDisabled > 0 {
+				r.Printf("  Disabled: %d\n", result.ModelsDisabled)
+			}
 			r.Println("")
 		}
 
@@ -0,0 +1,393 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/leapstack-labs/leapsql/internal/dag"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/internal/server"
+	"github.com/spf13/cobra"
+)
+
+// Export runners: how generated tasks run models.
+const (
+	exportRunnerCLI    = "cli"
+	exportRunnerDaemon = "daemon"
+)
+
+// Export task granularities.
+const (
+	exportByModel = "model"
+	exportByGroup = "group"
+)
+
+// ExportOptions holds options for the export commands.
+type ExportOptions struct {
+	Select    string // Selector expression limiting the exported models
+	GroupBy   string // Task granularity: model, group
+	Runner    string // How tasks run models: cli, daemon
+	Command   string // CLI invocation used by cli tasks
+	DaemonURL string // Daemon URL used by daemon tasks
+	Name      string // DAG ID (Airflow) or asset group (Dagster)
+	Schedule  string // Airflow schedule
+	File      string // Output file (stdout if empty)
+}
+
+// exportTask is one orchestrator task running a selection of models.
+type exportTask struct {
+	ID       string   // Task ID (Airflow) or asset name (Dagster)
+	Select   string   // Selector passed to leapsql run
+	Upstream []string // IDs of the tasks that must complete first
+}
+
+// exportData is the data of an export template.
+type exportData struct {
+	Project   string
+	DagID     string
+	GroupName string
+	Schedule  string
+	Runner    string
+	Command   string
+	Argv      []string
+	DaemonURL string
+	Tasks     []exportTask
+}
+
+// NewExportCommand creates the export command.
+func NewExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Generate orchestrator definitions from the project graph",
+		Long: `Generate pipeline definitions for workflow orchestrators from the project's
+model graph, so orchestration stays in sync with model dependencies.
+
+Each model becomes a task running "leapsql run --select <model>", wired to
+the tasks of the models it depends on. With --group-by group, models owned
+by a group run together in one task selecting "group:<name>"; models
+without a group keep their own task.
+
+Tasks run models with the CLI by default. With --runner daemon they call a
+"leapsql serve" daemon instead, at --daemon-url or $LEAPSQL_URL, sending
+$LEAPSQL_SERVE_TOKEN if set.
+
+Re-run the export when models or their dependencies change.`,
+	}
+
+	cmd.AddCommand(newExportAirflowCommand())
+	cmd.AddCommand(newExportDagsterCommand())
+
+	return cmd
+}
+
+func newExportAirflowCommand() *cobra.Command {
+	opts := &ExportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "airflow",
+		Short: "Generate an Airflow DAG running the project's models",
+		Long: `Generate an Airflow DAG with one task per model (or group), depending on the
+tasks of upstream models. CLI tasks use BashOperator, daemon tasks
+PythonOperator.`,
+		Example: `  # Write a DAG into the Airflow dags folder
+  leapsql export airflow --file dags/analytics.py
+
+  # One task per group, scheduled daily, running through the daemon
+  leapsql export airflow --group-by group --schedule @daily --runner daemon`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runExport(cmd, "airflow", opts)
+		},
+	}
+
+	addExportFlags(cmd, opts)
+	cmd.Flags().StringVar(&opts.Name, "dag-id", "", "DAG ID (default: project directory name)")
+	cmd.Flags().StringVar(&opts.Schedule, "schedule", "", "DAG schedule, e.g. @daily or a cron expression (default: none)")
+
+	return cmd
+}
+
+func newExportDagsterCommand() *cobra.Command {
+	opts := &ExportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "dagster",
+		Short: "Generate Dagster assets for the project's models",
+		Long: `Generate a Dagster code location with one asset per model (or group),
+depending on the assets of upstream models. Asset names are model paths
+with dots replaced by double underscores.`,
+		Example: `  # Write a Dagster definitions module
+  leapsql export dagster --file analytics_assets.py
+
+  # Assets per group, materialized through the daemon
+  leapsql export dagster --group-by group --runner daemon`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runExport(cmd, "dagster", opts)
+		},
+	}
+
+	addExportFlags(cmd, opts)
+	cmd.Flags().StringVar(&opts.Name, "group-name", "", "Dagster asset group (default: project directory name)")
+
+	return cmd
+}
+
+func addExportFlags(cmd *cobra.Command, opts *ExportOptions) {
+	cmd.Flags().StringVarP(&opts.Select, "select", "s", "", "Export only models matching a selector expression")
+	cmd.Flags().StringVar(&opts.GroupBy, "group-by", exportByModel, "Task granularity: model, group")
+	cmd.Flags().StringVar(&opts.Runner, "runner", exportRunnerCLI, "How tasks run models: cli, daemon")
+	cmd.Flags().StringVar(&opts.Command, "command", "leapsql", "CLI invocation of cli tasks, e.g. \"leapsql -C /opt/analytics\"")
+	cmd.Flags().StringVar(&opts.DaemonURL, "daemon-url", "http://"+server.DefaultAddr, "Daemon URL of daemon tasks")
+	cmd.Flags().StringVar(&opts.File, "file", "", "Write to a file instead of stdout")
+
+	_ = cmd.RegisterFlagCompletionFunc("group-by", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{exportByModel, exportByGroup}, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("runner", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{exportRunnerCLI, exportRunnerDaemon}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+func runExport(cmd *cobra.Command, format string, opts *ExportOptions) error {
+	if opts.GroupBy != exportByModel && opts.GroupBy != exportByGroup {
+		return fmt.Errorf("invalid --group-by %q: must be model or group", opts.GroupBy)
+	}
+	if opts.Runner != exportRunnerCLI && opts.Runner != exportRunnerDaemon {
+		return fmt.Errorf("invalid --runner %q: must be cli or daemon", opts.Runner)
+	}
+	argv := strings.Fields(opts.Command)
+	if opts.Runner == exportRunnerCLI && len(argv) == 0 {
+		return fmt.Errorf("--command must not be empty")
+	}
+
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	var selected []string
+	if opts.Select != "" {
+		if selected, err = resolveSelection(eng, opts.Select); err != nil {
+			return err
+		}
+	}
+
+	taskID := func(id string) string { return id }
+	if format == "dagster" {
+		taskID = dagsterAssetName
+	}
+	tasks, err := planExportTasks(eng, selected, opts.Select, opts.GroupBy, taskID)
+	if err != nil {
+		return err
+	}
+
+	project := "leapsql"
+	if root := cmdCtx.Cfg.ProjectRoot; root != "" {
+		project = filepath.Base(root)
+	}
+	name := opts.Name
+	if name == "" {
+		name = project
+	}
+
+	content, err := renderExport(format, exportData{
+		Project:   project,
+		DagID:     name,
+		GroupName: dagsterAssetName(name),
+		Schedule:  opts.Schedule,
+		Runner:    opts.Runner,
+		Command:   opts.Command,
+		Argv:      argv,
+		DaemonURL: opts.DaemonURL,
+		Tasks:     tasks,
+	})
+	if err != nil {
+		return err
+	}
+
+	if opts.File == "" {
+		_, err = fmt.Fprint(cmd.OutOrStdout(), content)
+		return err
+	}
+	if err := os.WriteFile(opts.File, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.File, err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %d tasks to %s\n", len(tasks), opts.File)
+	return nil
+}
+
+// planExportTasks returns the tasks running the selected models (all models if
+// selected is nil) in dependency order. A task depends on the tasks of its
+// models' nearest exported ancestors, so dependencies through models left out
+// of the export are kept.
+func planExportTasks(eng *engine.Engine, selected []string, selectExpr, groupBy string, taskID func(string) string) ([]exportTask, error) {
+	models := eng.GetModels()
+	exported := make(map[string]bool, len(models))
+	if selected == nil {
+		for path := range models {
+			exported[path] = true
+		}
+	} else {
+		for _, path := range selected {
+			exported[path] = true
+		}
+	}
+
+	// Assign models to tasks: their group's task, or their own
+	taskOf := make(map[string]string, len(exported))
+	selects := make(map[string]string)
+	for path := range exported {
+		key, sel := path, path
+		if m := models[path]; groupBy == exportByGroup && m.Group != "" {
+			key, sel = "group-"+m.Group, "group:"+m.Group
+			if selectExpr != "" {
+				sel = fmt.Sprintf("group:%s AND (%s)", m.Group, selectExpr)
+			}
+		}
+		taskOf[path] = key
+		selects[key] = sel
+	}
+
+	// Task IDs must stay distinct once adapted to the orchestrator
+	ids := make(map[string]string, len(selects))
+	owners := make(map[string]string, len(selects))
+	for key := range selects {
+		id := taskID(key)
+		if other, ok := owners[id]; ok {
+			return nil, fmt.Errorf("tasks %q and %q would share the ID %q", other, key, id)
+		}
+		owners[id] = key
+		ids[key] = id
+	}
+
+	graph := eng.GetGraph()
+	tasks := dag.NewGraph()
+	for key := range selects {
+		tasks.AddNode(key, nil)
+	}
+	edges := make(map[[2]string]bool)
+	for path := range exported {
+		for _, parent := range exportedAncestors(graph, path, exported) {
+			from, to := taskOf[parent], taskOf[path]
+			if from == to || edges[[2]string{from, to}] {
+				continue
+			}
+			edges[[2]string{from, to}] = true
+			if err := tasks.AddEdge(from, to); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sorted, err := tasks.TopologicalSort()
+	if err != nil {
+		return nil, fmt.Errorf("groups depend on each other, export with --group-by model: %w", err)
+	}
+
+	result := make([]exportTask, 0, len(sorted))
+	for _, node := range sorted {
+		upstream := make([]string, 0)
+		for _, parent := range tasks.GetParents(node.ID) {
+			upstream = append(upstream, ids[parent])
+		}
+		sort.Strings(upstream)
+		result = append(result, exportTask{ID: ids[node.ID], Select: selects[node.ID], Upstream: upstream})
+	}
+	return result, nil
+}
+
+// exportedAncestors returns the nearest exported ancestors of a model,
+// looking through ancestors that are not exported.
+func exportedAncestors(graph *dag.Graph, path string, exported map[string]bool) []string {
+	var result []string
+	seen := make(map[string]bool)
+
+	var walk func(id string)
+	walk = func(id string) {
+		for _, parent := range graph.GetParents(id) {
+			if seen[parent] {
+				continue
+			}
+			seen[parent] = true
+			if exported[parent] {
+				result = append(result, parent)
+			} else {
+				walk(parent)
+			}
+		}
+	}
+	walk(path)
+	return result
+}
+
+// dagsterInvalidChars matches characters not allowed in Dagster names.
+var dagsterInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// dagsterAssetName adapts a task key to a Dagster asset name:
+// "staging.orders" becomes "staging__orders".
+func dagsterAssetName(key string) string {
+	return dagsterInvalidChars.ReplaceAllString(strings.ReplaceAll(key, ".", "__"), "_")
+}
+
+// renderExport renders the export template of a format.
+func renderExport(format string, data exportData) (string, error) {
+	source, err := templateFS.ReadFile("templates/export/" + format + ".py.tmpl")
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(format).Funcs(template.FuncMap{
+		"py":         pyString,
+		"pyList":     pyList,
+		"shellQuote": shellQuote,
+	}).Parse(string(source))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// pyString returns s as a Python string literal.
+func pyString(s string) string {
+	// JSON string escapes are valid Python string escapes
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// pyList returns strs as a Python list literal.
+func pyList(strs []string) string {
+	quoted := make([]string, 0, len(strs))
+	for _, s := range strs {
+		quoted = append(quoted, pyString(s))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// shellSafe matches words that need no quoting in a POSIX shell.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_.:/-]+$`)
+
+// shellQuote quotes s as a single POSIX shell word.
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/leapstack-labs/leapsql/pkg/adapters/duckdb"
+)
+
+func TestNewExportCommand(t *testing.T) {
+	cmd := NewExportCommand()
+
+	assert.Equal(t, "export", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+
+	for _, name := range []string{"airflow", "dagster"} {
+		sub, _, err := cmd.Find([]string{name})
+		require.NoError(t, err)
+		assert.Equal(t, name, sub.Use)
+		assert.NotEmpty(t, sub.Example, "Example should not be empty")
+
+		for _, flag := range []string{"select", "group-by", "runner", "command", "daemon-url", "file"} {
+			assert.NotNil(t, sub.Flags().Lookup(flag), "%s: flag %q should exist", name, flag)
+		}
+	}
+}
+
+// newExportTestEngine discovers a project where finance models depend on a
+// staging model through a model left out of selections by tag.
+func newExportTestEngine(t *testing.T) *engine.Engine {
+	t.Helper()
+	tmpDir := t.TempDir()
+	modelsDir := filepath.Join(tmpDir, "models")
+
+	models := map[string]string{
+		"staging/stg_orders.sql":  "/*---\ntags: [core]\n---*/\nSELECT 1 AS id",
+		"staging/int_orders.sql":  "SELECT id FROM staging.stg_orders",
+		"finance/revenue.sql":     "/*---\ngroup: finance\ntags: [core]\n---*/\nSELECT id FROM staging.int_orders",
+		"finance/revenue_sum.sql": "/*---\ngroup: finance\ntags: [core]\n---*/\nSELECT COUNT(*) AS n FROM finance.revenue",
+	}
+	for name, content := range models {
+		path := filepath.Join(modelsDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	eng, err := engine.New(engine.Config{
+		ModelsDir: modelsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Groups:    []core.GroupConfig{{Name: "finance"}},
+		Target:    &starctx.TargetInfo{Type: "duckdb", Schema: "main"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = eng.Close() })
+
+	_, err = eng.Discover(engine.DiscoveryOptions{})
+	require.NoError(t, err)
+	return eng
+}
+
+func TestPlanExportTasks(t *testing.T) {
+	eng := newExportTestEngine(t)
+	identity := func(id string) string { return id }
+
+	t.Run("one task per model", func(t *testing.T) {
+		tasks, err := planExportTasks(eng, nil, "", exportByModel, identity)
+		require.NoError(t, err)
+		assert.Equal(t, []exportTask{
+			{ID: "staging.stg_orders", Select: "staging.stg_orders", Upstream: []string{}},
+			{ID: "staging.int_orders", Select: "staging.int_orders", Upstream: []string{"staging.stg_orders"}},
+			{ID: "finance.revenue", Select: "finance.revenue", Upstream: []string{"staging.int_orders"}},
+			{ID: "finance.revenue_sum", Select: "finance.revenue_sum", Upstream: []string{"finance.revenue"}},
+		}, tasks)
+	})
+
+	t.Run("one task per group", func(t *testing.T) {
+		tasks, err := planExportTasks(eng, nil, "", exportByGroup, dagsterAssetName)
+		require.NoError(t, err)
+		assert.Equal(t, []exportTask{
+			{ID: "staging__stg_orders", Select: "staging.stg_orders", Upstream: []string{}},
+			{ID: "staging__int_orders", Select: "staging.int_orders", Upstream: []string{"staging__stg_orders"}},
+			{ID: "group_finance", Select: "group:finance", Upstream: []string{"staging__int_orders"}},
+		}, tasks)
+	})
+
+	t.Run("keeps dependencies through unselected models", func(t *testing.T) {
+		selected, err := eng.SelectModels("tag:core")
+		require.NoError(t, err)
+
+		tasks, err := planExportTasks(eng, selected, "tag:core", exportByGroup, identity)
+		require.NoError(t, err)
+		assert.Equal(t, []exportTask{
+			{ID: "staging.stg_orders", Select: "staging.stg_orders", Upstream: []string{}},
+			{ID: "group-finance", Select: "group:finance AND (tag:core)", Upstream: []string{"staging.stg_orders"}},
+		}, tasks)
+	})
+}
+
+func TestRenderExport(t *testing.T) {
+	tasks := []exportTask{
+		{ID: "staging.orders", Select: "staging.orders", Upstream: []string{}},
+		{ID: "marts.revenue", Select: "marts.revenue", Upstream: []string{"staging.orders"}},
+	}
+
+	t.Run("airflow cli", func(t *testing.T) {
+		content, err := renderExport("airflow", exportData{
+			Project: "shop", DagID: "shop", Schedule: "@daily", Runner: exportRunnerCLI,
+			Command: "leapsql -C /opt/shop", Tasks: tasks,
+		})
+		require.NoError(t, err)
+		assert.Contains(t, content, `dag_id="shop"`)
+		assert.Contains(t, content, `schedule="@daily"`)
+		assert.Contains(t, content, `LEAPSQL = "leapsql -C /opt/shop"`)
+		assert.Contains(t, content, `bash_command=LEAPSQL + " run --select marts.revenue"`)
+		assert.Contains(t, content, `tasks["staging.orders"] >> tasks["marts.revenue"]`)
+		assert.NotContains(t, content, "PythonOperator")
+	})
+
+	t.Run("dagster daemon", func(t *testing.T) {
+		content, err := renderExport("dagster", exportData{
+			Project: "shop", GroupName: "shop", Runner: exportRunnerDaemon,
+			DaemonURL: "http://127.0.0.1:8766", Tasks: tasks,
+		})
+		require.NoError(t, err)
+		assert.Contains(t, content, `LEAPSQL_URL = os.environ.get("LEAPSQL_URL", "http://127.0.0.1:8766")`)
+		assert.Contains(t, content, `leapsql_asset("marts.revenue", "marts.revenue", ["staging.orders"])`)
+		assert.NotContains(t, content, "subprocess")
+	})
+}
+
+func TestDagsterAssetName(t *testing.T) {
+	assert.Equal(t, "staging__orders", dagsterAssetName("staging.orders"))
+	assert.Equal(t, "group_finance", dagsterAssetName("group-finance"))
+	assert.Equal(t, "core_staging__orders", dagsterAssetName("core/staging.orders"))
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"staging.orders", "staging.orders"},
+		{"group:finance", "group:finance"},
+		{"tag:a AND NOT tag:b", "'tag:a AND NOT tag:b'"},
+		{"it's", `'it'"'"'s'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.want, shellQuote(tt.input))
+		})
+	}
+}
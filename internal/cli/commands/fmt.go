@@ -0,0 +1,230 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/config"
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/format"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/spf13/cobra"
+)
+
+// errFmtCheck makes fmt --check exit non-zero when files need formatting.
+var errFmtCheck = errors.New("files need formatting")
+
+// FmtOptions holds options for the fmt command.
+type FmtOptions struct {
+	Path  string // Path filter for model files
+	Check bool   // List files needing formatting without writing them
+}
+
+// NewFmtCommand creates the fmt command.
+func NewFmtCommand() *cobra.Command {
+	opts := &FmtOptions{}
+	cmd := &cobra.Command{
+		Use:   "fmt [path]",
+		Short: "Format the SQL of model files",
+		Long: `Format the SQL of model files in place, in the model dialect of the
+project: one select item per line, clauses on lines of their own.
+Keywords, function names and unquoted identifiers are cased as the CV16
+lint rule is configured, keywords and function names upper case by
+default; quoted identifiers are kept as written. Frontmatter is kept as is, and template expressions
+({{ ... }}) as written. Comments move to the nearest line the formatter
+keeps: the end of the line before them or the line after them.
+
+With --check, no file is written: the files whose formatting differs are
+listed and the command exits non-zero if there are any, so a CI job can
+require formatted models. Formatting is idempotent, formatted SQL formats
+to itself, so a file fmt wrote always passes --check.
+
+Files that do not parse, such as models using template statements
+({* ... *}), are reported as skipped and left alone.
+
+Output adapts to environment:
+  - Terminal: Styled output with colors
+  - Piped/Scripted: Markdown format (agent-friendly)
+  - JSON: Machine-readable format`,
+		Example: `  # Format all models
+  leapsql fmt
+
+  # Format staging models
+  leapsql fmt ./models/staging
+
+  # Fail when a model is not formatted (CI)
+  leapsql fmt --check`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Path = args[0]
+			}
+			return runFmt(cmd, opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Check, "check", false, "List files needing formatting without writing them, exiting non-zero if any")
+
+	return cmd
+}
+
+// fmtCasing returns the casing fmt formats in: the policies the CV16 lint
+// rule is configured with, so formatted models pass it.
+func fmtCasing(cfg *config.Config) (format.Options, error) {
+	lintCfg := lint.NewConfig()
+	if cfg != nil {
+		if err := lintCfg.ApplyProject(cfg.Lint); err != nil {
+			return format.Options{}, fmt.Errorf("invalid lint config: %w", err)
+		}
+	}
+	opts := lintCfg.GetRuleOptions("CV16")
+	return format.Options{
+		Keywords:    core.Casing(lint.GetStringOption(opts, "keywords", string(core.CasingAny))),
+		Functions:   core.Casing(lint.GetStringOption(opts, "functions", string(core.CasingAny))),
+		Identifiers: core.Casing(lint.GetStringOption(opts, "identifiers", string(core.CasingAny))),
+	}, nil
+}
+
+// fmtOutput is the JSON output of fmt.
+type fmtOutput struct {
+	Check     bool         `json:"check"`
+	Changed   []string     `json:"changed"`
+	Unchanged int          `json:"unchanged"`
+	Skipped   []fmtSkipped `json:"skipped"`
+}
+
+// fmtSkipped is a file fmt could not format.
+type fmtSkipped struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+func runFmt(cmd *cobra.Command, opts *FmtOptions) error {
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+	d := eng.ModelDialect()
+	if d == nil {
+		return fmt.Errorf("dialect not available")
+	}
+	casing, err := fmtCasing(cmdCtx.Cfg)
+	if err != nil {
+		return err
+	}
+
+	var files []string
+	for _, m := range filterModelsByPath(eng.LintableModels(), opts.Path) {
+		if filepath.Ext(m.FilePath) == ".sql" {
+			files = append(files, m.FilePath)
+		}
+	}
+	sort.Strings(files)
+
+	out := fmtOutput{Check: opts.Check, Changed: []string{}, Skipped: []fmtSkipped{}}
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		formatted, err := engine.FormatModel(string(content), d, casing)
+		if err != nil {
+			out.Skipped = append(out.Skipped, fmtSkipped{Path: path, Reason: err.Error()})
+			continue
+		}
+		if formatted == string(content) {
+			out.Unchanged++
+			continue
+		}
+		out.Changed = append(out.Changed, path)
+		if opts.Check {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(formatted), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		if err := r.JSON(out); err != nil {
+			return err
+		}
+	case output.ModeMarkdown:
+		fmtMarkdown(r, out)
+	default:
+		fmtText(r, out)
+	}
+
+	if opts.Check && len(out.Changed) > 0 {
+		return errFmtCheck
+	}
+	return nil
+}
+
+// fmtText outputs the formatted files in styled text format.
+func fmtText(r *output.Renderer, out fmtOutput) {
+	for _, path := range out.Changed {
+		if out.Check {
+			r.Warning(path + ": needs formatting")
+		} else {
+			r.Success(path + ": formatted")
+		}
+	}
+	for _, s := range out.Skipped {
+		r.Muted(fmt.Sprintf("%s: skipped (%s)", s.Path, s.Reason))
+	}
+
+	switch {
+	case len(out.Changed) == 0:
+		r.Success(fmt.Sprintf("%d file(s) already formatted", out.Unchanged))
+	case out.Check:
+		r.Println(fmt.Sprintf("%d file(s) need formatting, %d already formatted", len(out.Changed), out.Unchanged))
+	default:
+		r.Println(fmt.Sprintf("%d file(s) formatted, %d already formatted", len(out.Changed), out.Unchanged))
+	}
+}
+
+// fmtMarkdown outputs the formatted files in markdown format.
+func fmtMarkdown(r *output.Renderer, out fmtOutput) {
+	title := "Formatted Files"
+	if out.Check {
+		title = "Files Needing Formatting"
+	}
+	r.Println(output.FormatHeader(1, title))
+	r.Println("")
+
+	if len(out.Changed) == 0 {
+		r.Println("None.")
+	}
+	for _, path := range out.Changed {
+		r.Printf("- %s\n", path)
+	}
+	if len(out.Skipped) > 0 {
+		r.Println("")
+		r.Println(output.FormatHeader(2, "Skipped"))
+		r.Println("")
+		for _, s := range out.Skipped {
+			r.Printf("- %s: %s\n", s.Path, s.Reason)
+		}
+	}
+	r.Println("")
+	r.Printf("%d file(s) already formatted.\n", out.Unchanged)
+}
@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/config"
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFmtCommand(t *testing.T) {
+	cmd := NewFmtCommand()
+
+	assert.Equal(t, "fmt [path]", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+	assert.NotEmpty(t, cmd.Example, "Example should not be empty")
+	assert.NotNil(t, cmd.Flags().Lookup("check"), "flag check should exist")
+}
+
+func TestFmtMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	r := output.NewRenderer(&buf, &bytes.Buffer{}, output.ModeMarkdown)
+
+	fmtMarkdown(r, fmtOutput{
+		Check:     true,
+		Changed:   []string{"models/orders.sql"},
+		Unchanged: 2,
+		Skipped:   []fmtSkipped{{Path: "models/dynamic.sql", Reason: "parse error"}},
+	})
+
+	out := buf.String()
+	assert.Contains(t, out, "# Files Needing Formatting")
+	assert.Contains(t, out, "- models/orders.sql\n")
+	assert.Contains(t, out, "- models/dynamic.sql: parse error\n")
+	assert.Contains(t, out, "2 file(s) already formatted.")
+}
+
+func TestFmtCasing(t *testing.T) {
+	casing, err := fmtCasing(nil)
+	require.NoError(t, err)
+	assert.Equal(t, format.Options{Keywords: core.CasingAny, Functions: core.CasingAny, Identifiers: core.CasingAny}, casing)
+
+	casing, err = fmtCasing(&config.Config{Lint: &core.LintConfig{Rules: map[string]core.RuleOptions{
+		"CV16": {"keywords": "lower", "identifiers": "snake_case"},
+	}}})
+	require.NoError(t, err)
+	assert.Equal(t, format.Options{Keywords: core.CasingLower, Functions: core.CasingAny, Identifiers: core.CasingSnake}, casing)
+
+	_, err = fmtCasing(&config.Config{Lint: &core.LintConfig{Rules: map[string]core.RuleOptions{
+		"CV16": {"keywords": "title"},
+	}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// NewFreshnessCommand creates the freshness command.
+func NewFreshnessCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "freshness",
+		Short: "Check how recently file sources were modified",
+		Long: `Check the freshness of the files models read with table functions such as
+read_parquet('s3://bucket/orders/*.parquet') or read_csv('data/refunds.csv').
+
+For each file source, the newest matching file's modification time is
+compared with the warn_after and error_after thresholds set for its path
+under file_sources in leapsql.yaml. A source that matches no files fails.
+The command fails if any source fails.
+
+Modification times are read with DuckDB, so this requires a DuckDB target.
+
+Output adapts to environment:
+  - Terminal: Styled output with colors
+  - Piped/Scripted: Markdown format (agent-friendly)`,
+		Example: `  # Check file source freshness
+  leapsql freshness
+
+  # Output as JSON
+  leapsql freshness --output json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runFreshness(cmd)
+		},
+	}
+
+	return cmd
+}
+
+type fileFreshnessOutput struct {
+	Path         string     `json:"path"`
+	Models       []string   `json:"models"`
+	Status       string     `json:"status"`
+	Reason       string     `json:"reason,omitempty"`
+	Files        int        `json:"files"`
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	AgeSeconds   int64      `json:"age_seconds,omitempty"`
+	WarnAfter    string     `json:"warn_after,omitempty"`
+	ErrorAfter   string     `json:"error_after,omitempty"`
+}
+
+func runFreshness(cmd *cobra.Command) error {
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	results, err := eng.CheckFileFreshness(context.Background())
+	if err != nil {
+		return err
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		out := make([]fileFreshnessOutput, 0, len(results))
+		for _, res := range results {
+			out = append(out, freshnessOutput(res))
+		}
+		if err := r.JSON(out); err != nil {
+			return err
+		}
+	case output.ModeMarkdown:
+		freshnessMarkdown(r, results)
+	default:
+		freshnessText(r, results)
+	}
+
+	failed := 0
+	for _, res := range results {
+		if res.Status == engine.FreshnessError {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d file source(s) failed the freshness check", failed)
+	}
+	return nil
+}
+
+func freshnessOutput(res engine.FileFreshness) fileFreshnessOutput {
+	out := fileFreshnessOutput{
+		Path:   res.Path,
+		Models: nonNil(res.Models),
+		Status: string(res.Status),
+		Reason: res.Reason,
+		Files:  res.Files,
+	}
+	if res.Files > 0 {
+		out.LastModified = &res.LastModified
+		out.AgeSeconds = int64(res.Age.Seconds())
+	}
+	if res.Config.WarnAfter > 0 {
+		out.WarnAfter = res.Config.WarnAfter.String()
+	}
+	if res.Config.ErrorAfter > 0 {
+		out.ErrorAfter = res.Config.ErrorAfter.String()
+	}
+	return out
+}
+
+// describeFreshness describes a file source's check, e.g. "3 files,
+// modified 2h ago (warn after 12h): older than 12h".
+func describeFreshness(res engine.FileFreshness) string {
+	if res.Files == 0 {
+		return res.Reason
+	}
+	desc := fmt.Sprintf("%d file(s), modified %s ago", res.Files, res.Age.Round(time.Second))
+	var thresholds []string
+	if res.Config.WarnAfter > 0 {
+		thresholds = append(thresholds, "warn after "+res.Config.WarnAfter.String())
+	}
+	if res.Config.ErrorAfter > 0 {
+		thresholds = append(thresholds, "error after "+res.Config.ErrorAfter.String())
+	}
+	if len(thresholds) > 0 {
+		desc += " (" + strings.Join(thresholds, ", ") + ")"
+	}
+	if res.Reason != "" {
+		desc += ": " + res.Reason
+	}
+	return desc
+}
+
+// freshnessText outputs freshness checks in styled text format.
+func freshnessText(r *output.Renderer, results []engine.FileFreshness) {
+	r.Header(1, "File Source Freshness")
+	r.Println("")
+
+	if len(results) == 0 {
+		r.Muted("No models read files with table functions")
+		return
+	}
+
+	for _, res := range results {
+		line := res.Path + ": " + describeFreshness(res)
+		switch res.Status {
+		case engine.FreshnessError:
+			r.Error(line)
+		case engine.FreshnessWarn:
+			r.Warning(line)
+		default:
+			r.Success(line)
+		}
+		if len(res.Models) > 0 {
+			r.Muted("  read by " + strings.Join(res.Models, ", "))
+		}
+	}
+}
+
+// freshnessMarkdown outputs freshness checks in markdown format.
+func freshnessMarkdown(r *output.Renderer, results []engine.FileFreshness) {
+	r.Println(output.FormatHeader(1, "File Source Freshness"))
+	r.Println("")
+
+	if len(results) == 0 {
+		r.Println("No models read files with table functions.")
+		return
+	}
+
+	for _, res := range results {
+		r.Printf("- **%s** %s: %s\n", res.Status, res.Path, describeFreshness(res))
+		if len(res.Models) > 0 {
+			r.Printf("  - read by: %s\n", strings.Join(res.Models, ", "))
+		}
+	}
+}
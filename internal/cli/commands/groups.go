@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// formatGroupContact describes who owns a group's models,
+// e.g. "finance (owner: finance-data, #finance-alerts)".
+func formatGroupContact(g core.GroupConfig) string {
+	if g.Name == "" {
+		return "no group"
+	}
+	var contacts []string
+	if g.Owner != "" {
+		contacts = append(contacts, "owner: "+g.Owner)
+	}
+	if g.SlackChannel != "" {
+		contacts = append(contacts, g.SlackChannel)
+	}
+	if len(contacts) == 0 {
+		return g.Name
+	}
+	return fmt.Sprintf("%s (%s)", g.Name, strings.Join(contacts, ", "))
+}
+
+// owningGroups buckets model paths by owning group.
+// Returns nil when the project declares no groups, so callers only
+// report ownership for projects that opted in.
+func owningGroups(eng *engine.Engine, paths []string) []engine.GroupModels {
+	if len(eng.GetGroups()) == 0 {
+		return nil
+	}
+	return eng.GroupByOwner(paths)
+}
+
+// failedModelPaths returns the paths of models that failed in a run.
+func failedModelPaths(eng *engine.Engine, runID string) []string {
+	store := eng.GetStateStore()
+	if store == nil {
+		return nil
+	}
+	modelRuns, err := store.GetModelRunsForRun(runID)
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, mr := range modelRuns {
+		if mr.Status != core.ModelRunStatusFailed {
+			continue
+		}
+		if model, err := store.GetModelByID(mr.ModelID); err == nil && model != nil {
+			paths = append(paths, model.Path)
+		}
+	}
+	return paths
+}
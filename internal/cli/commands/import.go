@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/dbt"
+	"github.com/spf13/cobra"
+)
+
+// NewImportCommand creates the import command.
+func NewImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Convert projects from other tools into LeapSQL projects",
+		Long: `Convert a project built with another tool into a LeapSQL project, reporting
+the features that need manual conversion.`,
+	}
+
+	cmd.AddCommand(newImportDbtCommand())
+	return cmd
+}
+
+func newImportDbtCommand() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "dbt <dbt-project> [directory]",
+		Short: "Convert a dbt project into a LeapSQL project",
+		Long: `Convert the dbt project in <dbt-project> into a LeapSQL project in [directory]
+(default: the current directory).
+
+Models keep their directory layout: models/staging/stg_orders.sql becomes
+the model staging.stg_orders. In each model:
+  - ref() and source() calls become table names
+  - config() calls and dbt_project.yml configs become frontmatter
+  - var() calls are inlined with the values from dbt_project.yml
+  - {% if is_incremental() %} blocks become "-- #if is_incremental" blocks
+  - Jinja comments become SQL comments
+
+Descriptions, column descriptions and the unique, not_null and
+accepted_values tests of schema YAML files are added to the model
+frontmatter. Seeds are copied to seeds/ and dbt groups are declared in
+leapsql.yaml.
+
+Everything else - macros, packages, hooks, snapshots, other tests and
+unsupported configs - is reported for manual conversion. Jinja that cannot
+be converted is kept in the model so it is easy to find.`,
+		Example: `  # Import into the current directory
+  leapsql import dbt ../jaffle_shop
+
+  # Import into a new directory
+  leapsql import dbt ../jaffle_shop jaffle_leapsql
+
+  # List the issues as JSON
+  leapsql import dbt ../jaffle_shop jaffle_leapsql -o json`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outDir := "."
+			if len(args) > 1 {
+				outDir = args[1]
+			}
+
+			cfg := getConfig()
+			r := output.NewRenderer(cmd.OutOrStdout(), cmd.ErrOrStderr(), output.Mode(cfg.OutputFormat))
+			return runImportDbt(r, args[0], outDir, force)
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing LeapSQL project")
+
+	return cmd
+}
+
+func runImportDbt(r *output.Renderer, dbtDir, outDir string, force bool) error {
+	result, err := dbt.Import(dbtDir, outDir, dbt.Options{Force: force})
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+
+	if r.EffectiveMode() == output.ModeJSON {
+		return r.JSON(result)
+	}
+
+	r.Header(2, "Files")
+	for _, f := range result.Files {
+		r.StatusLine(f, "success", "")
+	}
+
+	if len(result.Issues) > 0 {
+		r.Println("")
+		r.Header(2, "Needs manual conversion")
+		for _, issue := range result.Issues {
+			location := issue.File
+			if issue.Line > 0 {
+				location = fmt.Sprintf("%s:%d", issue.File, issue.Line)
+			}
+			r.Warning(fmt.Sprintf("%s: %s", location, issue.Message))
+		}
+	}
+
+	r.Println("")
+	r.Success(fmt.Sprintf("Imported dbt project %s: %d models, %d seeds", result.Project, len(result.Models), len(result.Seeds)))
+	if len(result.Issues) > 0 {
+		r.Muted(fmt.Sprintf("%d issue(s) need manual conversion", len(result.Issues)))
+	}
+	r.Println("")
+	r.Println("Next steps:")
+	r.Println("  1. Set the database target in leapsql.yaml")
+	r.Println("  2. Resolve the issues above")
+	r.Println("  3. Run 'leapsql lint' and 'leapsql run'")
+
+	return nil
+}
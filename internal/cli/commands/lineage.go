@@ -125,6 +125,17 @@ func lineageText(eng *engine.Engine, r *output.Renderer, modelPath string, upstr
 				styles.Success.Render("\u2192"), // right arrow
 				styles.ModelPath.Render(node))
 		}
+
+		// Route the impact to the groups that own affected models
+		if groups := owningGroups(eng, downstreamNodes); len(groups) > 0 {
+			r.Println("")
+			r.Println(styles.Header2.Render(fmt.Sprintf("Affected groups (%d):", len(groups))))
+			for _, g := range groups {
+				r.Printf("    %s %s\n",
+					styles.ModelPath.Render(formatGroupContact(g.Group)),
+					styles.Muted.Render(fmt.Sprintf("(%d models)", len(g.Models))))
+			}
+		}
 	}
 
 	return nil
@@ -162,11 +173,29 @@ func lineageMarkdown(eng *engine.Engine, r *output.Renderer, modelPath string, u
 		} else {
 			r.Println("*No downstream dependents*")
 		}
+
+		if groups := owningGroups(eng, downstreamNodes); len(groups) > 0 {
+			r.Println("")
+			r.Println(output.FormatHeader(2, fmt.Sprintf("Affected Groups (%d)", len(groups))))
+			items := make([]string, 0, len(groups))
+			for _, g := range groups {
+				items = append(items, fmt.Sprintf("%s: %s", formatGroupContact(g.Group), strings.Join(g.Models, ", ")))
+			}
+			r.Print(output.FormatList(items))
+		}
 	}
 
 	return nil
 }
 
+// lineageGroupJSON is a group that owns downstream models affected by a change.
+type lineageGroupJSON struct {
+	Name         string   `json:"name,omitempty"`
+	Owner        string   `json:"owner,omitempty"`
+	SlackChannel string   `json:"slack_channel,omitempty"`
+	Models       []string `json:"models"`
+}
+
 // lineageJSON outputs lineage in JSON format.
 func lineageJSON(eng *engine.Engine, r *output.Renderer, modelPath string, upstream, downstream bool, depth int) error {
 	models := eng.GetModels()
@@ -237,9 +266,22 @@ func lineageJSON(eng *engine.Engine, r *output.Renderer, modelPath string, upstr
 	lineageOutput.Stats.UpstreamCount = len(upstreamNodes)
 	lineageOutput.Stats.DownstreamCount = len(downstreamNodes)
 
+	result := struct {
+		output.LineageOutput
+		AffectedGroups []lineageGroupJSON `json:"affected_groups,omitempty"`
+	}{LineageOutput: lineageOutput}
+	for _, g := range owningGroups(eng, downstreamNodes) {
+		result.AffectedGroups = append(result.AffectedGroups, lineageGroupJSON{
+			Name:         g.Group.Name,
+			Owner:        g.Group.Owner,
+			SlackChannel: g.Group.SlackChannel,
+			Models:       g.Models,
+		})
+	}
+
 	enc := json.NewEncoder(r.Writer())
 	enc.SetIndent("", "  ")
-	return enc.Encode(lineageOutput)
+	return enc.Encode(result)
 }
 
 // getNodeType returns the type of a node (model, source, seed).
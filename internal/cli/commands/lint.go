@@ -1,34 +1,49 @@
 package commands
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/exec"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	"github.com/leapstack-labs/leapsql/internal/cli/config"
 	"github.com/leapstack-labs/leapsql/internal/cli/output"
 	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/internal/server"
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/lint"
 	"github.com/leapstack-labs/leapsql/pkg/lint/project"
 	_ "github.com/leapstack-labs/leapsql/pkg/lint/project/rules" // register project rules
 	_ "github.com/leapstack-labs/leapsql/pkg/lint/sql/rules"     // register SQL rules
-	"github.com/leapstack-labs/leapsql/pkg/parser"
 	"github.com/spf13/cobra"
 )
 
 // LintOptions holds options for the lint command.
 type LintOptions struct {
 	Path        string   // File or directory path
-	Format      string   // Output format: text, json
+	Select      string   // Selector expression (e.g., "tag:pii AND NOT tag:deprecated")
+	Format      string   // Output format: text, json, csv, github
 	Disable     []string // Rule IDs to disable
 	Severity    string   // Minimum severity: error, warning, info, hint
 	Rules       []string // Run only specific rules
 	SkipProject bool     // Skip project health linting
 	Verbose     bool     // Show rule documentation with violations
+	Fix         bool     // Apply safe fixes to model files
+	Unsafe      bool     // With Fix, also apply suggestions that may change semantics
+	FromState   bool     // Run project health rules on the state store without discovery
+	Staged      bool     // Only lint models whose files are staged in git
+	OutputFile  string   // Write the output to this file instead of stdout
+	NoDaemon    bool     // Lint in-process even when a daemon serves the project
 }
 
+// errLintIssues is returned when lint reports diagnostics, so the command
+// exits non-zero.
+var errLintIssues = errors.New("lint issues found")
+
 // NewLintCommand creates the lint command.
 func NewLintCommand() *cobra.Command {
 	opts := &LintOptions{}
@@ -40,6 +55,45 @@ func NewLintCommand() *cobra.Command {
 Runs SQLFluff-style lint rules against your SQL models and reports
 any violations found. Rules can be configured in leapsql.yaml.
 
+With --fix, safe fixes for the reported violations are written back to
+the model files. Suggestions, rewrites that may subtly change semantics
+such as ST07 (ON to USING), are only applied with --fix --unsafe; editors
+offer both as code actions. Models whose source differs from their
+rendered SQL (e.g. templated models) are not fixed.
+
+Rules run on the rendered SQL of models, but diagnostics point at the
+model file: a violation in the SQL a template expression or macro call
+expanded to is reported at the {{ ... }} that produced it.
+
+With --from-state, only project health rules run, on the models, lineage
+and dependencies recorded in the state database by the last discover or
+run. No model file is parsed, which makes it a fast check for CI jobs
+that already ran the pipeline.
+
+With --format json or csv, every diagnostic, SQL and project, is written as
+one record with its rule ID, severity, model, file, line and column,
+message, and a fingerprint. Fingerprints are stable across runs: they
+don't depend on line numbers, so dashboards can use them to deduplicate
+findings and track when each was introduced or fixed.
+
+With --format github, diagnostics are printed as GitHub Actions workflow
+commands (::error file=...,line=...::message), so a workflow step running
+lint shows violations inline on pull requests.
+
+While a daemon (leapsql serve) runs for the project, SQL rules run in the
+daemon, which has the models parsed already, with the lint config it loaded
+when it started. --fix and --no-daemon lint in-process.
+
+With --staged, only models whose .sql files are staged in git are linted,
+the fast path for a pre-commit hook. Unchanged models are loaded from the
+state database rather than re-parsed, and project health diagnostics are
+only reported on the staged models.
+
+Diagnostics are always ordered by file, line, column and rule ID, so the
+output of unchanged models is identical between runs. --output-file writes
+the output to a file atomically: readers such as CI caches see either the
+previous file or the complete new one.
+
 Output adapts to environment:
   - Terminal: Styled output with colors
   - Piped/Scripted: Markdown format
@@ -50,9 +104,18 @@ Output adapts to environment:
   # Lint specific path
   leapsql lint ./models/staging
 
+  # Lint models tagged pii
+  leapsql lint --select tag:pii
+
   # Output as JSON
   leapsql lint --format json
 
+  # Export diagnostics with fingerprints for a dashboard
+  leapsql lint --format csv --output-file lint.csv
+
+  # Annotate pull requests from a GitHub Actions workflow
+  leapsql lint --format github
+
   # Disable specific rules
   leapsql lint --disable AM01,ST01
 
@@ -60,7 +123,19 @@ Output adapts to environment:
   leapsql lint --severity error
 
   # Show rule documentation with violations
-  leapsql lint --verbose`,
+  leapsql lint --verbose
+
+  # Apply safe fixes to model files
+  leapsql lint --fix
+
+  # Also apply suggestions, rewrites that may subtly change semantics
+  leapsql lint --fix --unsafe --severity hint
+
+  # Run project health rules on the state of the last run
+  leapsql lint --from-state
+
+  # Lint the models staged for commit (pre-commit hook)
+  leapsql lint --staged`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) > 0 {
 				opts.Path = args[0]
@@ -69,17 +144,38 @@ Output adapts to environment:
 		},
 	}
 
-	cmd.Flags().StringVarP(&opts.Format, "format", "f", "", "Output format: text, json")
+	cmd.AddCommand(newLintExplainCommand())
+
+	cmd.Flags().StringVarP(&opts.Format, "format", "f", "", "Output format: text, json, csv, github")
+	cmd.Flags().StringVarP(&opts.Select, "select", "s", "", "Only lint models matching a selector expression")
 	cmd.Flags().StringSliceVar(&opts.Disable, "disable", nil, "Rule IDs to disable")
 	cmd.Flags().StringVar(&opts.Severity, "severity", "warning", "Minimum severity: error, warning, info, hint")
 	cmd.Flags().StringSliceVar(&opts.Rules, "rule", nil, "Run only specific rules")
 	cmd.Flags().BoolVar(&opts.SkipProject, "skip-project", false, "Skip project health linting")
 	cmd.Flags().BoolVarP(&opts.Verbose, "verbose", "v", false, "Show rule documentation with violations")
+	cmd.Flags().BoolVar(&opts.Fix, "fix", false, "Apply safe fixes for reported violations to model files")
+	cmd.Flags().BoolVar(&opts.Unsafe, "unsafe", false, "With --fix, also apply suggestions that may change semantics")
+	cmd.Flags().BoolVar(&opts.FromState, "from-state", false, "Run only project health rules, on the state database instead of discovering models")
+	cmd.Flags().BoolVar(&opts.Staged, "staged", false, "Only lint models whose files are staged in git")
+	cmd.Flags().StringVar(&opts.OutputFile, "output-file", "", "Write the output to a file instead of stdout")
+	cmd.Flags().BoolVar(&opts.NoDaemon, "no-daemon", false, "Lint in-process even when a daemon serves the project")
 
 	return cmd
 }
 
-func runLint(cmd *cobra.Command, opts *LintOptions) error {
+func runLint(cmd *cobra.Command, opts *LintOptions) (err error) {
+	if opts.Unsafe && !opts.Fix {
+		return fmt.Errorf("--unsafe requires --fix")
+	}
+	if opts.FromState && (opts.Fix || opts.Select != "" || opts.SkipProject || opts.Staged) {
+		return fmt.Errorf("--from-state cannot be combined with --fix, --select, --skip-project or --staged")
+	}
+	switch opts.Format {
+	case "", string(output.ModeText), string(output.ModeMarkdown), lintFormatJSON, lintFormatCSV, lintFormatGitHub:
+	default:
+		return fmt.Errorf("invalid --format %q: must be text, json, csv or github", opts.Format)
+	}
+
 	cmdCtx, cleanup, err := NewCommandContext(cmd)
 	if err != nil {
 		return err
@@ -90,74 +186,142 @@ func runLint(cmd *cobra.Command, opts *LintOptions) error {
 	cfg := cmdCtx.Cfg
 	r := cmdCtx.Renderer
 
-	// Override renderer if format flag is set
-	if opts.Format != "" {
-		r = output.NewRenderer(cmd.OutOrStdout(), cmd.ErrOrStderr(), output.Mode(opts.Format))
+	// With --output-file, the output is collected and written once linting is
+	// done, so the file is never left half-written. Files get markdown rather
+	// than styled text, like piped output.
+	out := cmd.OutOrStdout()
+	if opts.OutputFile != "" {
+		var buf bytes.Buffer
+		out = &buf
+		if opts.Format == "" {
+			mode := output.ModeMarkdown
+			if r.EffectiveMode() == output.ModeJSON {
+				mode = output.ModeJSON
+			}
+			r = output.NewRenderer(out, cmd.ErrOrStderr(), mode)
+		}
+		defer func() {
+			if err != nil && !errors.Is(err, errLintIssues) {
+				return
+			}
+			if werr := writeFileAtomic(opts.OutputFile, buf.Bytes()); werr != nil {
+				err = werr
+			}
+		}()
 	}
 
-	// Discover models
-	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
-		return fmt.Errorf("failed to discover models: %w", err)
+	// Override renderer if format flag is set; CSV and GitHub annotations are
+	// machine formats like JSON
+	if opts.Format == lintFormatCSV || opts.Format == lintFormatGitHub {
+		r = output.NewRenderer(out, cmd.ErrOrStderr(), output.ModeJSON)
+	} else if opts.Format != "" {
+		r = output.NewRenderer(out, cmd.ErrOrStderr(), output.Mode(opts.Format))
 	}
 
 	// Build lint config from CLI flags + project config
-	lintCfg := buildLintConfig(cfg, opts)
+	lintCfg, err := buildLintConfig(cfg, opts)
+	if err != nil {
+		return err
+	}
 
-	// Get dialect - it's set after engine initialization (before DB connection)
-	d := eng.GetDialect()
-	if d == nil {
-		return fmt.Errorf("dialect not available")
+	if opts.FromState {
+		return runLintFromState(r, eng, cfg, opts)
 	}
 
-	// Create analyzer with registry
-	analyzer := lint.NewAnalyzerWithRegistry(lintCfg, d.Name)
+	// Discover models
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
 
-	// Filter models by path if specified
-	models := filterModelsByPath(eng.GetModels(), opts.Path)
+	// Filter models by path and selector if specified
+	models := filterModelsByPath(eng.LintableModels(), opts.Path)
+	var selected map[string]bool
+	if opts.Select != "" {
+		paths, err := resolveSelection(eng, opts.Select)
+		if err != nil {
+			return err
+		}
+		selected = make(map[string]bool, len(paths))
+		for _, p := range paths {
+			selected[p] = true
+		}
+		models = filterModelsBySelection(models, selected)
+	}
+	if opts.Staged {
+		staged, err := stagedSQLFiles(cmd.Context(), cfg.ModelsDir)
+		if err != nil {
+			return err
+		}
+		models = filterModelsByFiles(models, staged)
+		if len(models) == 0 {
+			r.Success("No staged models to lint")
+			return nil
+		}
+		selected = make(map[string]bool, len(models))
+		for _, m := range models {
+			selected[m.Path] = true
+		}
+	}
 
-	// Analyze each model (SQL-level linting)
-	results := analyzeModels(models, analyzer, d, eng)
+	// Analyze each model (SQL-level linting), in the project's daemon if
+	// one is running. Fixes are computed in-process: the daemon doesn't
+	// return them.
+	var results []lintFileResult
+	if !opts.NoDaemon && !opts.Fix {
+		results, err = analyzeModelsWithDaemon(cmd.Context(), models, opts, resolveStatePath(cfg))
+		if err != nil {
+			cmdCtx.Logger.Debug("linting in-process", "reason", err)
+		}
+	}
+	if results == nil {
+		results, err = analyzeModels(cmd.Context(), models, lintCfg, eng)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Run project health linting
 	var projectResults []project.Diagnostic
 	if !opts.SkipProject && isProjectHealthEnabled(cfg) {
-		projectResults = runProjectHealthLinting(eng, cfg, opts)
+		projectResults = runProjectHealthLinting(buildProjectContext(eng, cfg), cfg, opts)
+		if selected != nil {
+			projectResults = filterProjectBySelection(projectResults, selected)
+		}
 	}
 
 	// Filter by severity threshold
 	results = filterBySeverity(results, opts.Severity)
 	projectResults = filterProjectBySeverity(projectResults, opts.Severity)
 
+	if opts.Fix {
+		results, err = applyLintFixes(r, results, opts.Unsafe)
+		if err != nil {
+			return err
+		}
+	}
+
+	if format := lintReportFormat(r, opts); format != "" {
+		return writeLintReport(r.Writer(), format, results, projectResults)
+	}
+
 	// Render output
 	hasIssues := renderLintResults(r, results, opts.Verbose)
 	hasProjectIssues := renderProjectHealthResults(r, projectResults, opts.Verbose)
 
 	// Exit with code 1 if issues found
 	if hasIssues || hasProjectIssues {
-		return fmt.Errorf("lint issues found")
+		return errLintIssues
 	}
 	return nil
 }
 
-func buildLintConfig(cfg *config.Config, opts *LintOptions) *lint.Config {
+func buildLintConfig(cfg *config.Config, opts *LintOptions) (*lint.Config, error) {
 	lintCfg := lint.NewConfig()
 
 	// Apply project config first (lower precedence)
-	if cfg != nil && cfg.Lint != nil {
-		projectLint := cfg.Lint
-		// Apply disabled rules from project config
-		for _, id := range projectLint.Disabled {
-			lintCfg.Disable(strings.TrimSpace(id))
-		}
-		// Apply severity overrides from project config
-		for id, sev := range projectLint.Severity {
-			if s, ok := core.ParseSeverity(sev); ok {
-				lintCfg.SetSeverity(id, s)
-			}
-		}
-		// Apply rule-specific options from project config
-		for id, ruleOpts := range projectLint.Rules {
-			lintCfg.SetRuleOptions(id, ruleOpts)
+	if cfg != nil {
+		if err := lintCfg.ApplyProject(cfg.Lint); err != nil {
+			return nil, fmt.Errorf("invalid lint config: %w", err)
 		}
 	}
 
@@ -179,13 +343,113 @@ func buildLintConfig(cfg *config.Config, opts *LintOptions) *lint.Config {
 		}
 	}
 
-	return lintCfg
+	return lintCfg, nil
+}
+
+// relatedLocation formats the location of a diagnostic's related span as
+// line:column, prefixed with its file when in another file.
+func relatedLocation(rel lint.RelatedInfo) string {
+	loc := fmt.Sprintf("%d:%d", rel.Pos.Line, rel.Pos.Column)
+	if rel.Pos.Line == 0 {
+		loc = "-"
+	}
+	if rel.FilePath != "" {
+		loc = rel.FilePath + ":" + loc
+	}
+	return loc
 }
 
 // lintFileResult holds lint results for a single file.
 type lintFileResult struct {
 	Path        string
+	Model       string // Model path
 	Diagnostics []lint.Diagnostic
+	SQL         string // Rendered SQL the fix edits refer to
+	Source      string // Content the diagnostic positions refer to
+}
+
+// filterModelsBySelection keeps the models whose paths are in selected.
+func filterModelsBySelection(models []*core.Model, selected map[string]bool) []*core.Model {
+	result := make([]*core.Model, 0, len(models))
+	for _, m := range models {
+		if selected[m.Path] {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// filterProjectBySelection keeps project diagnostics reported on selected models.
+// Project rules still analyze the whole DAG, so diagnostics on selected models
+// account for their unselected neighbors.
+func filterProjectBySelection(diags []project.Diagnostic, selected map[string]bool) []project.Diagnostic {
+	result := make([]project.Diagnostic, 0, len(diags))
+	for _, d := range diags {
+		if selected[d.Model] {
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// stagedSQLFiles returns the absolute paths of the .sql files staged in the
+// git repository containing dir. Deleted files are left out.
+func stagedSQLFiles(ctx context.Context, dir string) (map[string]bool, error) {
+	git := func(args ...string) ([]byte, error) {
+		cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+		}
+		return out, nil
+	}
+
+	top, err := git("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("--staged requires a git repository: %w", err)
+	}
+	root := strings.TrimSpace(string(top))
+
+	out, err := git("diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]bool)
+	for _, name := range strings.Split(string(out), "\x00") {
+		if !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		files[realPath(filepath.Join(root, filepath.FromSlash(name)))] = true
+	}
+	return files, nil
+}
+
+// filterModelsByFiles keeps the models whose file is one of files, keyed by
+// real path.
+func filterModelsByFiles(models []*core.Model, files map[string]bool) []*core.Model {
+	result := make([]*core.Model, 0, len(models))
+	for _, m := range models {
+		if files[realPath(m.FilePath)] {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// realPath returns the absolute path of path with symlinks resolved, or just
+// the absolute path if it can't be resolved.
+func realPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
 }
 
 func filterModelsByPath(models map[string]*core.Model, pathFilter string) []*core.Model {
@@ -217,37 +481,45 @@ func filterModelsByPath(models map[string]*core.Model, pathFilter string) []*cor
 	return result
 }
 
-func analyzeModels(models []*core.Model, analyzer *lint.Analyzer, d lint.DialectInfo, eng *engine.Engine) []lintFileResult {
-	var results []lintFileResult
-
-	for _, m := range models {
-		// Render and parse the model
-		rendered, err := eng.RenderModel(m.Path)
-		if err != nil {
-			continue // Skip models that fail to render
-		}
-
-		stmt, err := parser.ParseWithDialect(rendered, eng.GetDialect())
-		if err != nil {
-			continue // Skip models that fail to parse
-		}
-
-		// Analyze using registry rules
-		diags := analyzer.AnalyzeWithRegistryRules(stmt, d)
-		if len(diags) > 0 {
-			results = append(results, lintFileResult{
-				Path:        m.FilePath,
-				Diagnostics: diags,
-			})
-		}
+// analyzeModels lints the rendered SQL of models.
+func analyzeModels(ctx context.Context, models []*core.Model, lintCfg *lint.Config, eng *engine.Engine) ([]lintFileResult, error) {
+	linted, err := eng.LintModels(ctx, models, lintCfg)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]lintFileResult, 0, len(linted))
+	for _, l := range linted {
+		results = append(results, lintFileResult{Path: l.FilePath, Model: l.Model, Diagnostics: l.Diagnostics, SQL: l.SQL, Source: l.Source})
 	}
+	return results, nil
+}
 
-	// Sort results by path for consistent output
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Path < results[j].Path
-	})
+// analyzeModelsWithDaemon lints the rendered SQL of models in the daemon
+// serving the project whose state database is at statePath. It fails if no
+// daemon is running.
+func analyzeModelsWithDaemon(ctx context.Context, models []*core.Model, opts *LintOptions, statePath string) ([]lintFileResult, error) {
+	if len(models) == 0 {
+		return []lintFileResult{}, nil
+	}
+	client, err := server.Dial(ctx, server.SocketPath(statePath))
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
 
-	return results
+	paths := make([]string, len(models))
+	for i, m := range models {
+		paths[i] = m.Path
+	}
+	linted, err := client.Lint(ctx, server.LintRequest{Models: paths, Disable: opts.Disable, Rules: opts.Rules})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]lintFileResult, 0, len(linted))
+	for _, l := range linted {
+		results = append(results, lintFileResult{Path: l.FilePath, Model: l.Model, Diagnostics: l.Diagnostics, SQL: l.SQL, Source: l.Source})
+	}
+	return results, nil
 }
 
 func filterBySeverity(results []lintFileResult, severityThreshold string) []lintFileResult {
@@ -267,21 +539,96 @@ func filterBySeverity(results []lintFileResult, severityThreshold string) []lint
 		if len(diags) > 0 {
 			filtered = append(filtered, lintFileResult{
 				Path:        r.Path,
+				Model:       r.Model,
 				Diagnostics: diags,
+				SQL:         r.SQL,
+				Source:      r.Source,
 			})
 		}
 	}
 	return filtered
 }
 
+// applyLintFixes rewrites model files with the fixes of their diagnostics and
+// returns the diagnostics that remain. Suggestions are only applied when
+// unsafe is set. Fix positions refer to the rendered SQL, so files whose
+// source doesn't contain it verbatim (e.g. templated models) are left alone.
+func applyLintFixes(r *output.Renderer, results []lintFileResult, unsafe bool) ([]lintFileResult, error) {
+	var remaining []lintFileResult
+	fixedIssues, fixedFiles, skippedFiles := 0, 0, 0
+	for _, res := range results {
+		if res.SQL != "" && hasFixable(res.Diagnostics, unsafe) {
+			unfixed, ok, err := fixLintFile(res, unsafe)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				skippedFiles++
+			} else if n := len(res.Diagnostics) - len(unfixed); n > 0 {
+				fixedIssues += n
+				fixedFiles++
+				res.Diagnostics = unfixed
+			}
+		}
+		if len(res.Diagnostics) > 0 {
+			remaining = append(remaining, res)
+		}
+	}
+
+	if fixedIssues > 0 {
+		r.Success(fmt.Sprintf("Fixed %d issue(s) in %d file(s)", fixedIssues, fixedFiles))
+	}
+	if skippedFiles > 0 {
+		r.Warning(fmt.Sprintf("Skipped fixes in %d file(s) whose rendered SQL differs from the source", skippedFiles))
+	}
+	return remaining, nil
+}
+
+// fixLintFile applies the fixes of res to its file and returns the
+// diagnostics left unfixed. It reports false when the file's source doesn't
+// contain the rendered SQL.
+func fixLintFile(res lintFileResult, unsafe bool) ([]lint.Diagnostic, bool, error) {
+	info, err := os.Stat(res.Path)
+	if err != nil {
+		return nil, false, err
+	}
+	content, err := os.ReadFile(res.Path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", res.Path, err)
+	}
+	src := string(content)
+	idx := strings.Index(src, res.SQL)
+	if idx < 0 {
+		return nil, false, nil
+	}
+
+	fixedSQL, unfixed := lint.ApplyFixes(res.SQL, res.Diagnostics, unsafe)
+	if len(unfixed) == len(res.Diagnostics) {
+		return unfixed, true, nil
+	}
+	fixed := src[:idx] + fixedSQL + src[idx+len(res.SQL):]
+	if err := os.WriteFile(res.Path, []byte(fixed), info.Mode().Perm()); err != nil {
+		return nil, false, fmt.Errorf("failed to write %s: %w", res.Path, err)
+	}
+	return unfixed, true, nil
+}
+
+// hasFixable reports whether any diagnostic has a fix that may be applied.
+func hasFixable(diags []lint.Diagnostic, unsafe bool) bool {
+	for _, d := range diags {
+		if lint.IsFixable(d, unsafe) {
+			return true
+		}
+	}
+	return false
+}
+
 func renderLintResults(r *output.Renderer, results []lintFileResult, verbose bool) bool {
 	if len(results) == 0 {
 		r.Success("No lint issues found")
 		return false
 	}
 
-	mode := r.EffectiveMode()
-
 	// Calculate summary stats
 	summary := output.LintSummary{
 		FilesAnalyzed: len(results),
@@ -302,30 +649,6 @@ func renderLintResults(r *output.Renderer, results []lintFileResult, verbose boo
 		}
 	}
 
-	if mode == output.ModeJSON {
-		// JSON output
-		jsonOutput := output.LintOutput{
-			Summary: summary,
-		}
-		for _, res := range results {
-			fileResult := output.LintFileResult{
-				Path: res.Path,
-			}
-			for _, d := range res.Diagnostics {
-				fileResult.Diagnostics = append(fileResult.Diagnostics, output.LintDiagnostic{
-					RuleID:   d.RuleID,
-					Severity: d.Severity.String(),
-					Message:  d.Message,
-					Line:     d.Pos.Line,
-					Column:   d.Pos.Column,
-				})
-			}
-			jsonOutput.Files = append(jsonOutput.Files, fileResult)
-		}
-		_ = r.JSON(jsonOutput)
-		return true
-	}
-
 	// Text/Markdown output
 	for _, res := range results {
 		r.Println(r.Styles().ModelPath.Render(res.Path))
@@ -341,6 +664,9 @@ func renderLintResults(r *output.Renderer, results []lintFileResult, verbose boo
 				r.Styles().Bold.Render(d.RuleID),
 				d.Message,
 			)
+			for _, rel := range d.RelatedInfo {
+				r.Println(r.Styles().Muted.Render(fmt.Sprintf("         ↳ %s  %s", relatedLocation(rel), rel.Message)))
+			}
 
 			// Verbose mode: show rule documentation
 			if verbose {
@@ -352,6 +678,7 @@ func renderLintResults(r *output.Renderer, results []lintFileResult, verbose boo
 						r.Println(r.Styles().Muted.Render("       Fix: " + truncateOneLineVerbose(info.Fix, 70)))
 					}
 				}
+				r.Println(r.Styles().Muted.Render("       Docs: " + lint.BuildDocURL(d.RuleID)))
 				r.Println("")
 			}
 		}
@@ -402,10 +729,41 @@ func isProjectHealthEnabled(cfg *config.Config) bool {
 	return cfg.Lint.ProjectHealth.IsEnabled()
 }
 
-// runProjectHealthLinting runs project-level lint rules.
-func runProjectHealthLinting(eng *engine.Engine, cfg *config.Config, opts *LintOptions) []project.Diagnostic {
-	// Build project context from engine models
-	ctx := buildProjectContext(eng, cfg)
+// runLintFromState runs project health rules on the models recorded in the
+// state store, without discovering or parsing model files.
+func runLintFromState(r *output.Renderer, eng *engine.Engine, cfg *config.Config, opts *LintOptions) error {
+	store := eng.GetStateStore()
+	if store == nil {
+		return fmt.Errorf("no state database, run leapsql discover first")
+	}
+
+	ctx, err := project.NewContextFromStore(store, buildProjectHealthConfig(cfg))
+	if errors.Is(err, project.ErrNoModels) {
+		return fmt.Errorf("no models in the state database, run leapsql discover first")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load project from state: %w", err)
+	}
+	if seeds, err := eng.SeedFiles(); err == nil {
+		ctx.SetSeeds(seeds)
+	}
+
+	var projectResults []project.Diagnostic
+	if isProjectHealthEnabled(cfg) {
+		projectResults = filterProjectBySeverity(runProjectHealthLinting(ctx, cfg, opts), opts.Severity)
+	}
+
+	if format := lintReportFormat(r, opts); format != "" {
+		return writeLintReport(r.Writer(), format, nil, projectResults)
+	}
+	if renderProjectHealthResults(r, projectResults, opts.Verbose) {
+		return errLintIssues
+	}
+	return nil
+}
+
+// runProjectHealthLinting runs project-level lint rules on ctx.
+func runProjectHealthLinting(ctx *project.Context, cfg *config.Config, opts *LintOptions) []project.Diagnostic {
 	if ctx == nil {
 		return nil
 	}
@@ -429,18 +787,29 @@ func buildProjectContext(eng *engine.Engine, cfg *config.Config) *project.Contex
 	for path, m := range engineModels {
 		models[path] = &project.ModelInfo{
 			Path:         m.Path,
+			Project:      m.Project,
 			Name:         m.Name,
 			FilePath:     m.FilePath,
+			Relation:     eng.Relation(path),
 			Sources:      m.Sources,
 			Columns:      m.Columns, // No conversion needed - both use core.ColumnInfo
 			Materialized: m.Materialized,
 			Tags:         m.Tags,
 			Meta:         m.Meta,
+			Version:      m.Version,
+			Deprecated:   m.Deprecated,
+			Access:       m.Access,
+			Owner:        m.Owner,
+			Group:        m.Group,
+			PII:          m.PII,
 		}
 	}
 
+	// Build config
+	projectCfg := buildProjectHealthConfig(cfg)
+
 	// Infer model types
-	project.InferAndSetTypes(models)
+	project.InferAndSetTypes(models, projectCfg.Layers)
 
 	// Build parent/child relationships from the graph
 	graph := eng.GetGraph()
@@ -454,34 +823,52 @@ func buildProjectContext(eng *engine.Engine, cfg *config.Config) *project.Contex
 		}
 	}
 
-	// Build config
-	projectCfg := buildProjectHealthConfig(cfg)
-
 	// Use NewContextWithStore to enable schema drift detection (PL05)
 	store := eng.GetStateStore()
-	return project.NewContextWithStore(models, parents, children, projectCfg, store)
+	ctx := project.NewContextWithStore(models, parents, children, projectCfg, store)
+	if seeds, err := eng.SeedFiles(); err == nil {
+		ctx.SetSeeds(seeds)
+	}
+	return ctx
 }
 
 // buildProjectHealthConfig creates lint.ProjectHealthConfig from CLI config.
 func buildProjectHealthConfig(cfg *config.Config) lint.ProjectHealthConfig {
 	result := lint.DefaultProjectHealthConfig()
+	if cfg != nil {
+		result.Groups = lint.GroupsByName(cfg.Groups)
+		result.Layers = cfg.Layers
+	}
 
-	if cfg == nil || cfg.Lint == nil || cfg.Lint.ProjectHealth == nil {
+	if cfg == nil || cfg.Lint == nil {
 		return result
 	}
 
-	ph := cfg.Lint.ProjectHealth
-	if ph.Thresholds.ModelFanout > 0 {
-		result.ModelFanoutThreshold = ph.Thresholds.ModelFanout
-	}
-	if ph.Thresholds.TooManyJoins > 0 {
-		result.TooManyJoinsThreshold = ph.Thresholds.TooManyJoins
-	}
-	if ph.Thresholds.PassthroughColumns > 0 {
-		result.PassthroughColumnThreshold = ph.Thresholds.PassthroughColumns
+	if ph := cfg.Lint.ProjectHealth; ph != nil {
+		result.PIIApproved = ph.PIIApproved
+		if ph.Thresholds.ModelFanout > 0 {
+			result.ModelFanoutThreshold = ph.Thresholds.ModelFanout
+		}
+		if ph.Thresholds.TooManyJoins > 0 {
+			result.TooManyJoinsThreshold = ph.Thresholds.TooManyJoins
+		}
+		if ph.Thresholds.PassthroughColumns > 0 {
+			result.PassthroughColumnThreshold = ph.Thresholds.PassthroughColumns
+		}
+		if ph.Thresholds.StarlarkComplexity > 0 {
+			result.StarlarkComplexityThreshold = ph.Thresholds.StarlarkComplexity
+		}
 	}
-	if ph.Thresholds.StarlarkComplexity > 0 {
-		result.StarlarkComplexityThreshold = ph.Thresholds.StarlarkComplexity
+
+	// The threshold option of a rule overrides project_health.thresholds
+	for id, threshold := range map[string]*int{
+		"PM04": &result.ModelFanoutThreshold,
+		"PM05": &result.TooManyJoinsThreshold,
+		"PL01": &result.PassthroughColumnThreshold,
+	} {
+		if n := lint.GetIntOption(cfg.Lint.Rules[id], "threshold", 0); n > 0 {
+			*threshold = n
+		}
 	}
 
 	return result
@@ -517,6 +904,11 @@ func buildProjectAnalyzerConfig(cfg *config.Config, opts *LintOptions) *project.
 		}
 	}
 
+	// Model overrides were validated with the config
+	if cfg != nil && cfg.Lint != nil {
+		analyzerCfg.ModelOverrides, _ = lint.ParseModelOverrides(cfg.Lint.Overrides)
+	}
+
 	return analyzerCfg
 }
 
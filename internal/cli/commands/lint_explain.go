@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/pkg/dialect"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/spf13/cobra"
+)
+
+// LintExplainOptions holds options for the lint explain command.
+type LintExplainOptions struct {
+	SQL     string // SQL to run the rule against; read from the file or stdin if empty
+	Dialect string // SQL dialect; the dialect models are written in by default
+	Format  string // Output format: text, json
+}
+
+// lintExplainOutput is the JSON representation of a rule explanation.
+type lintExplainOutput struct {
+	Rule         string             `json:"rule"`
+	Dialect      string             `json:"dialect"`
+	Options      map[string]any     `json:"options,omitempty"`
+	Ran          bool               `json:"ran"`
+	Reason       string             `json:"reason"`
+	SyntaxErrors []lintExplainError `json:"syntax_errors,omitempty"`
+	Diagnostics  []lintExplainDiag  `json:"diagnostics"`
+	Nodes        []lintExplainNode  `json:"nodes"`
+}
+
+// lintExplainNode is an AST node in a rule explanation.
+type lintExplainNode struct {
+	Depth  int    `json:"depth"`
+	Type   string `json:"type"`
+	Detail string `json:"detail,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// lintExplainError is a syntax error in a rule explanation.
+type lintExplainError struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// lintExplainDiag is a diagnostic in a rule explanation, with its span.
+type lintExplainDiag struct {
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"end_line,omitempty"`
+	EndColumn int    `json:"end_column,omitempty"`
+	Fixes     int    `json:"fixes,omitempty"`
+}
+
+func newLintExplainCommand() *cobra.Command {
+	opts := &LintExplainOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "explain <rule> [file]",
+		Short: "Run one SQL rule against SQL and explain the result",
+		Long: `Run a single SQL lint rule against the given SQL and explain the result.
+
+Prints the nodes of the parsed statement, the options the rule ran with,
+why the rule did or didn't fire, and the span of each diagnostic. Use it
+to develop rules and to attach to rule bug reports.
+
+The SQL comes from --sql, the given file, or stdin. The rule runs with the
+options and severity set in leapsql.yaml, even if the config disables it.`,
+		Example: `  # Explain why AM06 fires on a query
+  leapsql lint explain AM06 --sql 'SELECT id FROM a JOIN b ON a.id = b.a_id'
+
+  # Explain a rule against a model file in another dialect
+  leapsql lint explain CV10 models/orders.sql --dialect snowflake
+
+  # Output as JSON for a bug report
+  leapsql lint explain ST06 query.sql --format json`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ""
+			if len(args) > 1 {
+				path = args[1]
+			}
+			return runLintExplain(cmd, args[0], path, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.SQL, "sql", "", "SQL to run the rule against")
+	cmd.Flags().StringVar(&opts.Dialect, "dialect", "", "SQL dialect (default: the dialect models are written in)")
+	cmd.Flags().StringVarP(&opts.Format, "format", "f", "", "Output format: text, json")
+
+	_ = cmd.RegisterFlagCompletionFunc("dialect", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return dialect.List(), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+func runLintExplain(cmd *cobra.Command, ruleID, path string, opts *LintExplainOptions) error {
+	src := opts.SQL
+	if src == "" {
+		var err error
+		if src, err = readTraceInput(cmd, path); err != nil {
+			return err
+		}
+	} else if path != "" {
+		return fmt.Errorf("--sql and a file are mutually exclusive")
+	}
+	if strings.TrimSpace(src) == "" {
+		return fmt.Errorf("no SQL provided")
+	}
+
+	cmdCtx := NewCommandContextWithoutEngine(cmd)
+	r := cmdCtx.Renderer
+
+	d, err := sqlDialect(cmdCtx.Cfg, opts.Dialect)
+	if err != nil {
+		return err
+	}
+
+	lintCfg, err := buildLintConfig(cmdCtx.Cfg, &LintOptions{})
+	if err != nil {
+		return err
+	}
+
+	ex, err := lintsql.NewAnalyzer(lintCfg, d.Name).Explain(strings.ToUpper(ruleID), src, d)
+	if err != nil {
+		return err
+	}
+
+	format := opts.Format
+	if format == "" && r.EffectiveMode() == output.ModeJSON {
+		format = "json"
+	}
+	switch format {
+	case "json":
+		return lintExplainJSON(r, ex)
+	case "", "text":
+		lintExplainText(r, ex, src)
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q: supported formats are text, json", format)
+	}
+}
+
+// lintExplainJSON outputs a rule explanation as JSON.
+func lintExplainJSON(r *output.Renderer, ex *lintsql.Explanation) error {
+	out := lintExplainOutput{
+		Rule:        ex.Rule.ID(),
+		Dialect:     ex.Dialect,
+		Options:     ex.Options,
+		Ran:         ex.Ran,
+		Reason:      ex.Reason,
+		Diagnostics: make([]lintExplainDiag, 0, len(ex.Diagnostics)),
+		Nodes:       make([]lintExplainNode, 0, len(ex.Nodes)),
+	}
+	for _, n := range ex.Nodes {
+		out.Nodes = append(out.Nodes, lintExplainNode{Depth: n.Depth, Type: n.Type, Detail: n.Detail, Line: n.Pos.Line, Column: n.Pos.Column})
+	}
+	for _, e := range ex.SyntaxErrors {
+		out.SyntaxErrors = append(out.SyntaxErrors, lintExplainError{Line: e.Pos.Line, Column: e.Pos.Column, Message: e.Message})
+	}
+	for _, d := range ex.Diagnostics {
+		out.Diagnostics = append(out.Diagnostics, lintExplainDiag{
+			Severity:  d.Severity.String(),
+			Message:   d.Message,
+			Line:      d.Pos.Line,
+			Column:    d.Pos.Column,
+			EndLine:   d.EndPos.Line,
+			EndColumn: d.EndPos.Column,
+			Fixes:     len(d.Fixes),
+		})
+	}
+	return r.JSON(out)
+}
+
+// lintExplainText outputs a rule explanation in styled text format.
+func lintExplainText(r *output.Renderer, ex *lintsql.Explanation, src string) {
+	styles := r.Styles()
+
+	r.Header(1, fmt.Sprintf("%s - %s", ex.Rule.ID(), ex.Rule.Name()))
+	r.Printf("  %s: %s\n", styles.Bold.Render("Dialect"), ex.Dialect)
+	if len(ex.Options) > 0 {
+		keys := make([]string, 0, len(ex.Options))
+		for k := range ex.Options {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = fmt.Sprintf("%s=%v", k, ex.Options[k])
+		}
+		r.Printf("  %s: %s\n", styles.Bold.Render("Options"), strings.Join(parts, ", "))
+	}
+	r.Println("")
+
+	if len(ex.SyntaxErrors) > 0 {
+		r.Println(styles.Header2.Render(fmt.Sprintf("Syntax errors (%d):", len(ex.SyntaxErrors))))
+		for _, e := range ex.SyntaxErrors {
+			r.Printf("  %s  %s\n", styles.Muted.Render(fmt.Sprintf("%-5s", explainLocation(e.Pos))), e.Message)
+		}
+		r.Println("")
+	}
+
+	r.Println(styles.Header2.Render(fmt.Sprintf("AST (%d nodes):", len(ex.Nodes))))
+	for _, n := range ex.Nodes {
+		line := strings.Repeat("  ", n.Depth+1) + n.Type
+		if n.Detail != "" {
+			line += " " + styles.ModelPath.Render(n.Detail)
+		}
+		if n.Pos.Line > 0 {
+			line += " " + styles.Muted.Render("@"+explainLocation(n.Pos))
+		}
+		r.Println(line)
+	}
+	r.Println("")
+
+	r.Println(styles.Header2.Render(fmt.Sprintf("Diagnostics (%d):", len(ex.Diagnostics))))
+	lines := strings.Split(src, "\n")
+	for _, d := range ex.Diagnostics {
+		span := explainLocation(d.Pos)
+		if d.EndPos.Line > 0 {
+			span += "-" + explainLocation(d.EndPos)
+		}
+		r.Printf("  %s  %s  %s\n", styles.Muted.Render(span), severityStyle(r, d.Severity), d.Message)
+		for _, l := range explainExcerpt(lines, d) {
+			r.Println(styles.Muted.Render("      " + l))
+		}
+	}
+	if len(ex.Diagnostics) > 0 {
+		r.Println("")
+	}
+
+	if ex.Ran && len(ex.Diagnostics) > 0 {
+		r.Warning(ex.Reason)
+	} else {
+		r.Println(styles.Muted.Render(ex.Reason))
+	}
+}
+
+// explainLocation formats a position as line:column, or "-" if unknown.
+func explainLocation(pos token.Position) string {
+	if pos.Line == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+// explainExcerpt returns the source line a diagnostic starts on, and a line
+// underlining its span on that line.
+func explainExcerpt(lines []string, d lint.Diagnostic) []string {
+	if d.Pos.Line < 1 || d.Pos.Line > len(lines) {
+		return nil
+	}
+	line := lines[d.Pos.Line-1]
+	start := max(d.Pos.Column-1, 0)
+	if start > len(line) {
+		return []string{line}
+	}
+	end := len(line)
+	if d.EndPos.Line == d.Pos.Line && d.EndPos.Column > d.Pos.Column {
+		end = min(d.EndPos.Column-1, len(line))
+	}
+	width := max(end-start, 1)
+	return []string{line, strings.Repeat(" ", start) + strings.Repeat("^", width)}
+}
@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+)
+
+// Lint report formats accepted by --format.
+const (
+	lintFormatJSON   = "json"
+	lintFormatCSV    = "csv"
+	lintFormatGitHub = "github"
+)
+
+// lintRecord is one diagnostic in a JSON or CSV lint report.
+type lintRecord struct {
+	RuleID      string `json:"rule_id"`
+	Severity    string `json:"severity"`
+	Model       string `json:"model,omitempty"`
+	FilePath    string `json:"file_path,omitempty"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	Message     string `json:"message"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// lintReport is the JSON lint report.
+type lintReport struct {
+	Summary     output.LintSummary `json:"summary"`
+	Diagnostics []lintRecord       `json:"diagnostics"`
+}
+
+// lintCSVHeader is the header row of the CSV lint report.
+var lintCSVHeader = []string{"rule_id", "severity", "model", "file_path", "line", "column", "message", "fingerprint"}
+
+// lintReportFormat returns the report format lint output is written in, or
+// "" for the styled text/markdown output.
+func lintReportFormat(r *output.Renderer, opts *LintOptions) string {
+	if opts.Format == lintFormatCSV || opts.Format == lintFormatGitHub {
+		return opts.Format
+	}
+	if r.EffectiveMode() == output.ModeJSON {
+		return lintFormatJSON
+	}
+	return ""
+}
+
+// writeLintReport writes SQL and project diagnostics as a JSON or CSV report,
+// or as GitHub Actions annotations.
+// It returns an error if there are any diagnostics, so lint exits non-zero.
+func writeLintReport(w io.Writer, format string, results []lintFileResult, projectResults []project.Diagnostic) error {
+	records := buildLintRecords(results, projectResults)
+
+	var err error
+	switch format {
+	case lintFormatCSV:
+		err = writeLintCSV(w, records)
+	case lintFormatGitHub:
+		err = writeLintGitHub(w, records)
+	default:
+		report := lintReport{
+			Summary:     lintReportSummary(results, records),
+			Diagnostics: records,
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write lint report: %w", err)
+	}
+
+	if len(records) > 0 {
+		return errLintIssues
+	}
+	return nil
+}
+
+// buildLintRecords flattens SQL and project diagnostics into report records.
+// Fingerprints are computed from the rule, model, message and the source
+// line a diagnostic points at, so they survive unrelated edits.
+func buildLintRecords(results []lintFileResult, projectResults []project.Diagnostic) []lintRecord {
+	fp := lint.NewFingerprinter()
+	records := make([]lintRecord, 0)
+
+	for _, res := range results {
+		for _, d := range res.Diagnostics {
+			records = append(records, lintRecord{
+				RuleID:      d.RuleID,
+				Severity:    d.Severity.String(),
+				Model:       res.Model,
+				FilePath:    res.Path,
+				Line:        d.Pos.Line,
+				Column:      d.Pos.Column,
+				Message:     d.Message,
+				Fingerprint: fp.Fingerprint(d.RuleID, res.Model, d.Message, lint.SourceLine(res.Source, d.Pos.Line)),
+			})
+		}
+	}
+
+	for _, d := range projectResults {
+		records = append(records, lintRecord{
+			RuleID:      d.RuleID,
+			Severity:    d.Severity.String(),
+			Model:       d.Model,
+			FilePath:    d.FilePath,
+			Message:     d.Message,
+			Fingerprint: fp.Fingerprint(d.RuleID, d.Model, d.Message, ""),
+		})
+	}
+
+	return records
+}
+
+// lintReportSummary counts the records of a report by severity.
+func lintReportSummary(results []lintFileResult, records []lintRecord) output.LintSummary {
+	summary := output.LintSummary{
+		FilesAnalyzed: len(results),
+		TotalIssues:   len(records),
+	}
+	for _, rec := range records {
+		switch rec.Severity {
+		case core.SeverityError.String():
+			summary.Errors++
+		case core.SeverityWarning.String():
+			summary.Warnings++
+		case core.SeverityInfo.String():
+			summary.Info++
+		case core.SeverityHint.String():
+			summary.Hints++
+		}
+	}
+	return summary
+}
+
+// writeLintCSV writes records as CSV with a header row.
+func writeLintCSV(w io.Writer, records []lintRecord) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(lintCSVHeader); err != nil {
+		return err
+	}
+	for _, rec := range records {
+		row := []string{
+			rec.RuleID,
+			rec.Severity,
+			rec.Model,
+			rec.FilePath,
+			strconv.Itoa(rec.Line),
+			strconv.Itoa(rec.Column),
+			rec.Message,
+			rec.Fingerprint,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeLintGitHub writes records as GitHub Actions workflow commands, which
+// GitHub shows as annotations on the lines of a pull request's diff.
+// Errors and warnings map to the same annotation levels, info and hints to
+// notices.
+func writeLintGitHub(w io.Writer, records []lintRecord) error {
+	for _, rec := range records {
+		level := "notice"
+		switch rec.Severity {
+		case core.SeverityError.String():
+			level = "error"
+		case core.SeverityWarning.String():
+			level = "warning"
+		}
+
+		var props []string
+		if rec.FilePath != "" {
+			props = append(props, "file="+githubEscapeProperty(rec.FilePath))
+			if rec.Line > 0 {
+				props = append(props, "line="+strconv.Itoa(rec.Line))
+				if rec.Column > 0 {
+					props = append(props, "col="+strconv.Itoa(rec.Column))
+				}
+			}
+		}
+		props = append(props, "title="+githubEscapeProperty(rec.RuleID))
+
+		message := rec.Message
+		if rec.FilePath == "" && rec.Model != "" {
+			message = rec.Model + ": " + message
+		}
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", level, strings.Join(props, ","), githubEscapeData(message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// githubEscapeData escapes the message of a workflow command.
+func githubEscapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubEscapeProperty escapes a property value of a workflow command.
+func githubEscapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// writeFileAtomic writes data to path through a temporary file in the same
+// directory that is renamed over path, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
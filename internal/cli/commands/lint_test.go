@@ -2,11 +2,20 @@ package commands
 
 import (
 	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/leapstack-labs/leapsql/internal/cli/config"
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+	"github.com/leapstack-labs/leapsql/pkg/token"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -19,16 +28,30 @@ func TestNewLintCommand(t *testing.T) {
 	assert.NotEmpty(t, cmd.Example, "Example should not be empty")
 
 	// Verify flags exist
-	flags := []string{"format", "disable", "severity", "rule"}
+	flags := []string{"format", "disable", "severity", "rule", "fix", "unsafe", "from-state"}
 	for _, flag := range flags {
 		assert.NotNil(t, cmd.Flags().Lookup(flag), "flag %q should exist", flag)
 	}
 }
 
+func TestRunLint_FromStateConflicts(t *testing.T) {
+	for _, opts := range []*LintOptions{
+		{FromState: true, Fix: true},
+		{FromState: true, Select: "tag:pii"},
+		{FromState: true, SkipProject: true},
+		{FromState: true, Staged: true},
+	} {
+		err := runLint(NewLintCommand(), opts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--from-state cannot be combined")
+	}
+}
+
 func TestBuildLintConfig(t *testing.T) {
 	t.Run("empty options", func(t *testing.T) {
 		opts := &LintOptions{}
-		cfg := buildLintConfig(nil, opts)
+		cfg, err := buildLintConfig(nil, opts)
+		require.NoError(t, err)
 
 		require.NotNil(t, cfg)
 		// No rules should be disabled
@@ -39,7 +62,8 @@ func TestBuildLintConfig(t *testing.T) {
 		opts := &LintOptions{
 			Disable: []string{"AM01", "ST01"},
 		}
-		cfg := buildLintConfig(nil, opts)
+		cfg, err := buildLintConfig(nil, opts)
+		require.NoError(t, err)
 
 		require.NotNil(t, cfg)
 		assert.True(t, cfg.IsDisabled("AM01"))
@@ -51,7 +75,8 @@ func TestBuildLintConfig(t *testing.T) {
 		opts := &LintOptions{
 			Rules: []string{"AM01", "AM02"},
 		}
-		cfg := buildLintConfig(nil, opts)
+		cfg, err := buildLintConfig(nil, opts)
+		require.NoError(t, err)
 
 		require.NotNil(t, cfg)
 		// AM01 and AM02 should be enabled
@@ -73,7 +98,8 @@ func TestBuildLintConfig(t *testing.T) {
 			},
 		}
 		opts := &LintOptions{}
-		cfg := buildLintConfig(projectCfg, opts)
+		cfg, err := buildLintConfig(projectCfg, opts)
+		require.NoError(t, err)
 
 		require.NotNil(t, cfg)
 		assert.True(t, cfg.IsDisabled("AM01"))
@@ -91,7 +117,8 @@ func TestBuildLintConfig(t *testing.T) {
 			},
 		}
 		opts := &LintOptions{}
-		cfg := buildLintConfig(projectCfg, opts)
+		cfg, err := buildLintConfig(projectCfg, opts)
+		require.NoError(t, err)
 
 		require.NotNil(t, cfg)
 		assert.Equal(t, core.SeverityError, cfg.GetSeverity("AM01", core.SeverityWarning))
@@ -109,7 +136,8 @@ func TestBuildLintConfig(t *testing.T) {
 			},
 		}
 		opts := &LintOptions{}
-		cfg := buildLintConfig(projectCfg, opts)
+		cfg, err := buildLintConfig(projectCfg, opts)
+		require.NoError(t, err)
 
 		require.NotNil(t, cfg)
 		al06Opts := cfg.GetRuleOptions("AL06")
@@ -118,6 +146,19 @@ func TestBuildLintConfig(t *testing.T) {
 		assert.Equal(t, 20, int(al06Opts["max_length"].(int)))
 	})
 
+	t.Run("project config invalid rule options", func(t *testing.T) {
+		projectCfg := &config.Config{
+			Lint: &core.LintConfig{
+				Rules: map[string]core.RuleOptions{
+					"AL06": {"min_length": "three"},
+				},
+			},
+		}
+		_, err := buildLintConfig(projectCfg, &LintOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "lint.rules.AL06: min_length: must be an integer")
+	})
+
 	t.Run("CLI overrides project config", func(t *testing.T) {
 		projectCfg := &config.Config{
 			Lint: &core.LintConfig{
@@ -127,7 +168,8 @@ func TestBuildLintConfig(t *testing.T) {
 		opts := &LintOptions{
 			Disable: []string{"AM02"}, // Additional disable via CLI
 		}
-		cfg := buildLintConfig(projectCfg, opts)
+		cfg, err := buildLintConfig(projectCfg, opts)
+		require.NoError(t, err)
 
 		require.NotNil(t, cfg)
 		// Both should be disabled
@@ -200,3 +242,278 @@ func TestSeverityStyle(t *testing.T) {
 		})
 	}
 }
+
+func TestRelatedLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		related  lint.RelatedInfo
+		expected string
+	}{
+		{"same file", lint.RelatedInfo{Pos: token.Position{Line: 3, Column: 8}}, "3:8"},
+		{"other file", lint.RelatedInfo{FilePath: "models/orders.sql", Pos: token.Position{Line: 1, Column: 15}}, "models/orders.sql:1:15"},
+		{"no position", lint.RelatedInfo{}, "-"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, relatedLocation(tc.related))
+		})
+	}
+}
+
+func TestApplyLintFixes(t *testing.T) {
+	const sql = "SELECT * FROM users usr JOIN orders ord ON usr.id = ord.id"
+	suggestion := lint.Diagnostic{
+		RuleID:   "ST07",
+		Severity: core.SeverityHint,
+		Fixes: []lint.Fix{{
+			Kind: lint.FixSuggestion,
+			TextEdits: []lint.TextEdit{{
+				Pos:     token.Position{Offset: 40},
+				EndPos:  token.Position{Offset: len(sql)},
+				NewText: "USING (id)",
+			}},
+		}},
+	}
+	unfixable := lint.Diagnostic{RuleID: "AM04", Severity: core.SeverityWarning}
+	r := output.NewRenderer(&bytes.Buffer{}, &bytes.Buffer{}, output.ModeText)
+
+	write := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "orders.sql")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	read := func(t *testing.T, path string) string {
+		t.Helper()
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	t.Run("suggestions need unsafe", func(t *testing.T) {
+		path := write(t, sql)
+		results := []lintFileResult{{Path: path, SQL: sql, Diagnostics: []lint.Diagnostic{suggestion}}}
+
+		remaining, err := applyLintFixes(r, results, false)
+		require.NoError(t, err)
+		assert.Equal(t, results, remaining)
+		assert.Equal(t, sql, read(t, path))
+	})
+
+	t.Run("unsafe applies suggestions around frontmatter", func(t *testing.T) {
+		const header = "/*---\nname: orders\n---*/\n"
+		path := write(t, header+sql+"\n")
+		results := []lintFileResult{{Path: path, SQL: sql, Diagnostics: []lint.Diagnostic{suggestion, unfixable}}}
+
+		remaining, err := applyLintFixes(r, results, true)
+		require.NoError(t, err)
+		require.Len(t, remaining, 1)
+		assert.Equal(t, []lint.Diagnostic{unfixable}, remaining[0].Diagnostics)
+		assert.Equal(t, header+"SELECT * FROM users usr JOIN orders ord USING (id)\n", read(t, path))
+	})
+
+	t.Run("templated source left alone", func(t *testing.T) {
+		const templated = "SELECT * FROM {{ ref('users') }} usr JOIN orders ord ON usr.id = ord.id"
+		path := write(t, templated)
+		results := []lintFileResult{{Path: path, SQL: sql, Diagnostics: []lint.Diagnostic{suggestion}}}
+
+		remaining, err := applyLintFixes(r, results, true)
+		require.NoError(t, err)
+		assert.Equal(t, results, remaining)
+		assert.Equal(t, templated, read(t, path))
+	})
+}
+
+func TestRunLint_InvalidFormat(t *testing.T) {
+	err := runLint(NewLintCommand(), &LintOptions{Format: "xml"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid --format "xml"`)
+}
+
+func TestBuildLintRecords(t *testing.T) {
+	results := []lintFileResult{{
+		Path:   "models/orders.sql",
+		Model:  "marts.orders",
+		Source: "SELECT *\nFROM   orders",
+		Diagnostics: []lint.Diagnostic{
+			{RuleID: "AM04", Severity: core.SeverityWarning, Message: "star", Pos: token.Position{Line: 1, Column: 8}},
+			{RuleID: "AM04", Severity: core.SeverityWarning, Message: "star", Pos: token.Position{Line: 1, Column: 8}},
+		},
+	}}
+	projectResults := []project.Diagnostic{
+		{RuleID: "PM01", Severity: core.SeverityInfo, Message: "root model", Model: "marts.orders", FilePath: "models/orders.sql"},
+	}
+
+	records := buildLintRecords(results, projectResults)
+	require.Len(t, records, 3)
+
+	assert.Equal(t, "AM04", records[0].RuleID)
+	assert.Equal(t, "warning", records[0].Severity)
+	assert.Equal(t, "marts.orders", records[0].Model)
+	assert.Equal(t, 1, records[0].Line)
+	assert.Equal(t, 8, records[0].Column)
+	assert.Len(t, records[0].Fingerprint, 32)
+	assert.NotEqual(t, records[0].Fingerprint, records[1].Fingerprint, "duplicates get distinct fingerprints")
+
+	assert.Equal(t, "PM01", records[2].RuleID)
+	assert.Equal(t, 0, records[2].Line)
+
+	// Moving the diagnostic to another line keeps its fingerprint
+	results[0].Source = "-- orders\nSELECT *\nFROM   orders"
+	for i := range results[0].Diagnostics {
+		results[0].Diagnostics[i].Pos.Line = 2
+	}
+	moved := buildLintRecords(results, projectResults)
+	assert.Equal(t, records[0].Fingerprint, moved[0].Fingerprint)
+	assert.Equal(t, records[2].Fingerprint, moved[2].Fingerprint)
+}
+
+func TestWriteLintReport(t *testing.T) {
+	results := []lintFileResult{{
+		Path:  "models/orders.sql",
+		Model: "marts.orders",
+		SQL:   "SELECT *, a FROM orders",
+		Diagnostics: []lint.Diagnostic{
+			{RuleID: "AM04", Severity: core.SeverityError, Message: "star, expanded", Pos: token.Position{Line: 1, Column: 8}},
+		},
+	}}
+
+	t.Run("csv", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := writeLintReport(&buf, lintFormatCSV, results, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "lint issues found")
+
+		rows, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 2)
+		assert.Equal(t, lintCSVHeader, rows[0])
+		assert.Equal(t, []string{"AM04", "error", "marts.orders", "models/orders.sql", "1", "8", "star, expanded"}, rows[1][:7])
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.Error(t, writeLintReport(&buf, lintFormatJSON, results, nil))
+
+		var report lintReport
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+		assert.Equal(t, 1, report.Summary.FilesAnalyzed)
+		assert.Equal(t, 1, report.Summary.Errors)
+		require.Len(t, report.Diagnostics, 1)
+		assert.Equal(t, "marts.orders", report.Diagnostics[0].Model)
+		assert.NotEmpty(t, report.Diagnostics[0].Fingerprint)
+	})
+
+	t.Run("no issues", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeLintReport(&buf, lintFormatCSV, nil, nil))
+		assert.Equal(t, "rule_id,severity,model,file_path,line,column,message,fingerprint\n", buf.String())
+	})
+}
+
+func TestWriteLintGitHub(t *testing.T) {
+	records := []lintRecord{
+		{RuleID: "AM04", Severity: "error", FilePath: "models/a,b.sql", Line: 3, Column: 8, Message: "100% star\nexpanded"},
+		{RuleID: "ST01", Severity: "warning", FilePath: "models/orders.sql", Line: 1, Column: 1, Message: "else null"},
+		{RuleID: "PM01", Severity: "info", Model: "marts.orders", Message: "root model"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeLintGitHub(&buf, records))
+
+	assert.Equal(t,
+		"::error file=models/a%2Cb.sql,line=3,col=8,title=AM04::100%25 star%0Aexpanded\n"+
+			"::warning file=models/orders.sql,line=1,col=1,title=ST01::else null\n"+
+			"::notice title=PM01::marts.orders: root model\n",
+		buf.String())
+}
+
+func TestStagedSQLFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+
+	modelsDir := filepath.Join(dir, "models")
+	require.NoError(t, os.MkdirAll(modelsDir, 0o755))
+	for _, name := range []string{"staged.sql", "unstaged.sql", "notes.md"} {
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte("SELECT 1"), 0o600))
+	}
+	git("add", "models/staged.sql", "models/notes.md")
+
+	files, err := stagedSQLFiles(context.Background(), modelsDir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{realPath(filepath.Join(modelsDir, "staged.sql")): true}, files)
+
+	models := []*core.Model{
+		{Path: "staged", FilePath: filepath.Join(modelsDir, "staged.sql")},
+		{Path: "unstaged", FilePath: filepath.Join(modelsDir, "unstaged.sql")},
+	}
+	filtered := filterModelsByFiles(models, files)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "staged", filtered[0].Path)
+}
+
+func TestStagedSQLFiles_NotARepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(t.TempDir()))
+
+	_, err := stagedSQLFiles(context.Background(), t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--staged requires a git repository")
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "lint.json")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0600))
+
+	require.NoError(t, writeFileAtomic(path, []byte("new")))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary file is renamed over the target")
+
+	err = writeFileAtomic(filepath.Join(dir, "missing", "lint.json"), []byte("new"))
+	require.Error(t, err)
+}
+
+func TestNewLintExplainCommand(t *testing.T) {
+	explainCmd, _, err := NewLintCommand().Find([]string{"explain"})
+	require.NoError(t, err)
+	assert.Equal(t, "explain <rule> [file]", explainCmd.Use)
+	assert.NotEmpty(t, explainCmd.Example, "Example should not be empty")
+
+	for _, flag := range []string{"sql", "dialect", "format"} {
+		assert.NotNil(t, explainCmd.Flags().Lookup(flag), "flag %q should exist", flag)
+	}
+}
+
+func TestExplainExcerpt(t *testing.T) {
+	lines := []string{"SELECT id", "FROM a JOIN b ON a.id = b.a_id"}
+
+	got := explainExcerpt(lines, lint.Diagnostic{
+		Pos:    token.Position{Line: 2, Column: 8},
+		EndPos: token.Position{Line: 2, Column: 14},
+	})
+	assert.Equal(t, []string{lines[1], "       ^^^^^^"}, got)
+
+	got = explainExcerpt(lines, lint.Diagnostic{Pos: token.Position{Line: 1, Column: 8}})
+	assert.Equal(t, []string{lines[0], "       ^^"}, got, "without an end, the span runs to the end of the line")
+
+	assert.Nil(t, explainExcerpt(lines, lint.Diagnostic{}))
+}
@@ -14,8 +14,16 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// ListOptions holds options for the list command.
+type ListOptions struct {
+	Group  string
+	Select string
+}
+
 // NewListCommand creates the list command.
 func NewListCommand() *cobra.Command {
+	opts := &ListOptions{}
+
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all models and their dependencies",
@@ -35,17 +43,26 @@ Use --output to override: auto, text, markdown, json`,
   # List models as Markdown (for agents/scripts)
   leapsql list --output markdown
 
+  # List models owned by the finance group
+  leapsql list --group finance
+
+  # List models tagged pii that are not deprecated
+  leapsql list --select "tag:pii AND NOT tag:deprecated"
+
   # List models with verbose output
   leapsql list -v`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return runList(cmd)
+			return runList(cmd, opts)
 		},
 	}
 
+	cmd.Flags().StringVar(&opts.Group, "group", "", "Only list models owned by this group")
+	cmd.Flags().StringVarP(&opts.Select, "select", "s", "", "Only list models matching a selector expression")
+
 	return cmd
 }
 
-func runList(cmd *cobra.Command) error {
+func runList(cmd *cobra.Command, opts *ListOptions) error {
 	cmdCtx, cleanup, err := NewCommandContext(cmd)
 	if err != nil {
 		return err
@@ -60,20 +77,51 @@ func runList(cmd *cobra.Command) error {
 		return fmt.Errorf("failed to discover models: %w", err)
 	}
 
+	models := eng.GetModels()
+	if opts.Group != "" {
+		if _, ok := eng.GetGroup(opts.Group); !ok {
+			return fmt.Errorf("unknown group %q: declare it under 'groups' in leapsql.yaml", opts.Group)
+		}
+		models = filterModelsByGroup(models, opts.Group)
+	}
+	if opts.Select != "" {
+		selected, err := resolveSelection(eng, opts.Select)
+		if err != nil {
+			return err
+		}
+		filtered := make(map[string]*core.Model, len(selected))
+		for _, path := range selected {
+			if m, ok := models[path]; ok {
+				filtered[path] = m
+			}
+		}
+		models = filtered
+	}
+
 	effectiveMode := r.EffectiveMode()
 	switch effectiveMode {
 	case output.ModeJSON:
-		return listJSON(eng, r)
+		return listJSON(eng, r, models)
 	case output.ModeMarkdown:
-		return listMarkdown(eng, r)
+		return listMarkdown(eng, r, models)
 	default:
-		return listText(eng, r)
+		return listText(eng, r, models)
 	}
 }
 
+// filterModelsByGroup returns the models owned by the given group.
+func filterModelsByGroup(models map[string]*core.Model, group string) map[string]*core.Model {
+	result := make(map[string]*core.Model)
+	for path, m := range models {
+		if m.Group == group {
+			result[path] = m
+		}
+	}
+	return result
+}
+
 // listText outputs models in styled text format.
-func listText(eng *engine.Engine, r *output.Renderer) error {
-	models := eng.GetModels()
+func listText(eng *engine.Engine, r *output.Renderer, models map[string]*core.Model) error {
 	graph := eng.GetGraph()
 
 	r.Header(1, fmt.Sprintf("Models (%d total)", len(models)))
@@ -98,8 +146,7 @@ func listText(eng *engine.Engine, r *output.Renderer) error {
 }
 
 // listMarkdown outputs models in markdown format.
-func listMarkdown(eng *engine.Engine, r *output.Renderer) error {
-	models := eng.GetModels()
+func listMarkdown(eng *engine.Engine, r *output.Renderer, models map[string]*core.Model) error {
 	graph := eng.GetGraph()
 	store := eng.GetStateStore()
 
@@ -122,6 +169,9 @@ func listMarkdown(eng *engine.Engine, r *output.Renderer) error {
 
 		r.Println(output.FormatKeyValue("Materialized", m.Materialized))
 		r.Println(output.FormatKeyValue("File", m.FilePath))
+		if m.Group != "" {
+			r.Println(output.FormatKeyValue("Group", m.Group))
+		}
 
 		deps := graph.GetParents(node.ID)
 		if len(deps) > 0 {
@@ -152,8 +202,7 @@ func listMarkdown(eng *engine.Engine, r *output.Renderer) error {
 }
 
 // listJSON outputs models and macros in JSON format.
-func listJSON(eng *engine.Engine, r *output.Renderer) error {
-	models := eng.GetModels()
+func listJSON(eng *engine.Engine, r *output.Renderer, models map[string]*core.Model) error {
 	graph := eng.GetGraph()
 	store := eng.GetStateStore()
 
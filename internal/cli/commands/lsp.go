@@ -17,7 +17,59 @@ func NewLSPCommand() *cobra.Command {
 
 The server communicates over stdin/stdout using JSON-RPC.
 The project root and state database are determined by the
-client's initialization request (rootUri parameter).`,
+client's initialization request (rootUri parameter).
+
+Diagnostics are pushed as documents change, or pulled by clients
+supporting LSP 3.17 pull diagnostics (textDocument/diagnostic and
+workspace/diagnostic). Either way, the results of project rules (PM, PS,
+PL) appear on the model files they concern, next to the syntax and SQL
+lint errors of each file.
+
+Inlay hints show each model's materialization and target schema above
+its query, and the type inferred for select items from casts, literals,
+documented function return types and CTEs. Turn either off with the
+inlayHints.materialization and inlayHints.columnTypes settings, sent as
+initialization options or in the "leapsql" section of the client
+configuration.
+
+Hovering a macro call in template code, such as finance.revenue in
+{{ finance.revenue('amount') }}, shows the function's signature and
+docstring, and go-to-definition jumps to its def in the .star file.
+Namespaces list their functions. Macros come from the discover index,
+and saving a .star file of the macros directory re-indexes it.
+
+Macro files (.star) get syntax and undefined-name diagnostics, completion
+of their functions and Starlark's builtins, and signature help, which
+also covers macro calls in template code. Macros only see Starlark's
+builtins: template globals such as ref, config and target must be passed
+in as arguments.
+
+Code lenses at the top of model files run the model, preview its first
+100 rows and show its lineage through the daemon serving the project
+(see leapsql serve). Results open as read-only Markdown documents
+(leapsql-run://, leapsql-preview:// and leapsql-lineage:// URIs) whose
+content clients read with a leapsql/virtualDocument request. A "Show
+compiled SQL" lens opens the model rendered by the daemon as a
+leapsql-compiled:// SQL document; it is compiled again whenever a model
+or macro file is saved, and clients are told to read it again with a
+leapsql/virtualDocumentChanged notification.
+
+A leapsql/preview request ({"model": ..., "limit": ...}, or a
+textDocument instead of the model) runs a model's query with a LIMIT
+through the daemon and returns its columns and rows as JSON, for clients
+to render as a table; truncated tells whether the model has more rows.
+Rows are capped at 10000, and $/cancelRequest cancels the query.
+
+Folding ranges cover the frontmatter, CTEs, subqueries, CASE expressions
+and select lists spanning several lines. Smart selection expands from the
+word at the cursor through its expression, select item, clause, subquery
+and CTE to the whole statement.
+
+Refactor code actions extract the subquery at the cursor into a CTE named
+after its alias, and the CTE at the cursor into a model of its own, next
+to the file and read with ref() (see leapsql refactor). Extracting a model
+needs a client able to create files through workspace edits; subqueries
+and CTEs holding template expressions are not offered.`,
 		Example: `  # Start LSP server (usually called by an IDE)
   leapsql lsp`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
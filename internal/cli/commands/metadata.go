@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/config"
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/internal/metadata"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+// MetadataPushOptions holds options for the metadata push command.
+type MetadataPushOptions struct {
+	Select   string // Selector expression limiting the models pushed
+	Sink     string // Only push to sinks of this type
+	URL      string // Catalog endpoint, overriding the configured one
+	Token    string // Access token, overriding the configured one
+	Platform string // Data platform of the datasets (default: the target type)
+	Env      string // Catalog environment of the datasets
+}
+
+// NewMetadataCommand creates the metadata command.
+func NewMetadataCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "metadata",
+		Short: "Push model metadata to data catalogs",
+		Long: `Keep data catalogs in sync with the project. Each model is pushed as a
+dataset with its description, schema, owner, tags, and the tables and
+columns it is computed from.
+
+Catalogs are configured as sinks under metadata.sinks in leapsql.yaml.
+With metadata.push_after_run, the models built by each successful
+"leapsql run" are pushed to every sink.`,
+	}
+
+	cmd.AddCommand(newMetadataPushCommand())
+
+	return cmd
+}
+
+func newMetadataPushCommand() *cobra.Command {
+	opts := &MetadataPushOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Push model metadata to the configured data catalogs",
+		Long: `Push the metadata of models to the sinks configured in leapsql.yaml, or
+to a catalog given with --sink and --url.
+
+Schemas have the column types of the models' tables when they are built,
+and the columns inferred from lineage otherwise. A model's owner is its
+owner frontmatter field, or the owner of its group.
+
+Sink types:
+  - datahub: DataHub, through the GMS REST API. Owners that are email
+    addresses become users, other owners groups.
+
+Output adapts to environment:
+  - Terminal: Styled output with colors
+  - Piped/Scripted: Markdown format (agent-friendly)`,
+		Example: `  # Push all models to the configured sinks
+  leapsql metadata push
+
+  # Push the marts to a DataHub instance
+  leapsql metadata push --sink datahub --url http://localhost:8080 --select "tag:mart"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runMetadataPush(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Select, "select", "s", "", "Only push models matching a selector expression")
+	cmd.Flags().StringVar(&opts.Sink, "sink", "", "Only push to sinks of this type, e.g. datahub")
+	cmd.Flags().StringVar(&opts.URL, "url", "", "Catalog endpoint (overrides the configured url; requires --sink)")
+	cmd.Flags().StringVar(&opts.Token, "token", "", "Access token (overrides the configured token)")
+	cmd.Flags().StringVar(&opts.Platform, "platform", "", "Data platform of the datasets (default: the target type)")
+	cmd.Flags().StringVar(&opts.Env, "env", "", "Catalog environment of the datasets (default: PROD)")
+
+	_ = cmd.RegisterFlagCompletionFunc("sink", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return metadata.ListSinks(), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+type metadataPushOutput struct {
+	Sink     string   `json:"sink"`
+	URL      string   `json:"url"`
+	Datasets []string `json:"datasets"`
+}
+
+func runMetadataPush(cmd *cobra.Command, opts *MetadataPushOptions) error {
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	sinks, err := metadataSinks(cmdCtx.Cfg, opts)
+	if err != nil {
+		return err
+	}
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	var paths []string
+	if opts.Select != "" {
+		if paths, err = resolveSelection(eng, opts.Select); err != nil {
+			return err
+		}
+	} else {
+		for path := range eng.GetModels() {
+			paths = append(paths, path)
+		}
+	}
+
+	results, err := pushMetadata(cmd.Context(), eng, sinks, paths)
+	if err != nil {
+		return err
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		return r.JSON(results)
+	case output.ModeMarkdown:
+		r.Println(output.FormatHeader(1, "Metadata Push"))
+		r.Println("")
+		for _, res := range results {
+			r.Printf("- **%s** (`%s`): %d dataset(s)\n", res.Sink, res.URL, len(res.Datasets))
+		}
+	default:
+		r.Header(1, "Metadata Push")
+		r.Println("")
+		for _, res := range results {
+			r.Success(fmt.Sprintf("%s (%s): %d dataset(s) pushed", res.Sink, res.URL, len(res.Datasets)))
+		}
+	}
+	return nil
+}
+
+// metadataSinks returns the sink configs to push to: the configured sinks,
+// limited to --sink and overridden by the other flags. --sink with --url
+// pushes to that catalog when no sink of the type is configured.
+func metadataSinks(cfg *config.Config, opts *MetadataPushOptions) ([]core.MetadataSinkConfig, error) {
+	var sinks []core.MetadataSinkConfig
+	if cfg.Metadata != nil {
+		for _, s := range cfg.Metadata.Sinks {
+			if opts.Sink == "" || s.Type == opts.Sink {
+				sinks = append(sinks, s)
+			}
+		}
+	}
+	if len(sinks) == 0 && opts.Sink != "" && opts.URL != "" {
+		sinks = append(sinks, core.MetadataSinkConfig{Type: opts.Sink})
+	}
+	if len(sinks) == 0 {
+		if opts.Sink != "" {
+			return nil, fmt.Errorf("no %s sink configured: add one to metadata.sinks in leapsql.yaml or pass --url", opts.Sink)
+		}
+		return nil, fmt.Errorf("no metadata sinks configured: add them to metadata.sinks in leapsql.yaml or pass --sink and --url")
+	}
+	if opts.URL != "" && opts.Sink == "" && len(sinks) > 1 {
+		return nil, fmt.Errorf("--url requires --sink when several sinks are configured")
+	}
+
+	for i := range sinks {
+		s := &sinks[i]
+		if opts.URL != "" {
+			s.URL = opts.URL
+		}
+		if opts.Token != "" {
+			s.Token = opts.Token
+		}
+		if opts.Platform != "" {
+			s.Platform = opts.Platform
+		}
+		if opts.Env != "" {
+			s.Env = opts.Env
+		}
+		if s.Platform == "" && cfg.Target != nil {
+			s.Platform = cfg.Target.Type
+		}
+	}
+	return sinks, nil
+}
+
+// pushMetadata pushes the catalog metadata of models to each sink.
+func pushMetadata(ctx context.Context, eng *engine.Engine, sinks []core.MetadataSinkConfig, paths []string) ([]metadataPushOutput, error) {
+	datasets, err := eng.CatalogDatasets(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(datasets))
+	for _, d := range datasets {
+		names = append(names, d.Name)
+	}
+
+	results := make([]metadataPushOutput, 0, len(sinks))
+	for _, cfg := range sinks {
+		sink, err := metadata.NewSink(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := sink.Push(ctx, datasets); err != nil {
+			return nil, err
+		}
+		results = append(results, metadataPushOutput{Sink: cfg.Type, URL: cfg.URL, Datasets: names})
+	}
+	return results, nil
+}
+
+// pushMetadataAfterRun pushes the models built by the latest run of an
+// environment to every configured sink, when metadata.push_after_run is
+// set. Failures are reported as warnings: the run itself succeeded.
+func pushMetadataAfterRun(eng *engine.Engine, r *output.Renderer, cfg *config.Config) {
+	if cfg.Metadata == nil || !cfg.Metadata.PushAfterRun || len(cfg.Metadata.Sinks) == 0 {
+		return
+	}
+	store := eng.GetStateStore()
+	if store == nil {
+		return
+	}
+	run, err := store.GetLatestRun(cfg.Environment)
+	if err != nil || run == nil || run.Status != core.RunStatusCompleted {
+		return
+	}
+	paths := builtModelPaths(eng, run.ID)
+	if len(paths) == 0 {
+		return
+	}
+
+	sinks, err := metadataSinks(cfg, &MetadataPushOptions{})
+	if err == nil {
+		_, err = pushMetadata(context.Background(), eng, sinks, paths)
+	}
+	if err != nil {
+		r.Warning(fmt.Sprintf("Metadata push failed: %v", err))
+	}
+}
+
+// builtModelPaths returns the paths of models a run built successfully.
+func builtModelPaths(eng *engine.Engine, runID string) []string {
+	store := eng.GetStateStore()
+	modelRuns, err := store.GetModelRunsForRun(runID)
+	if err != nil {
+		return nil
+	}
+
+	var paths []string
+	for _, mr := range modelRuns {
+		if mr.Status != core.ModelRunStatusSuccess {
+			continue
+		}
+		if model, err := store.GetModelByID(mr.ModelID); err == nil && model != nil {
+			if _, ok := eng.GetModels()[model.Path]; ok {
+				paths = append(paths, model.Path)
+			}
+		}
+	}
+	return paths
+}
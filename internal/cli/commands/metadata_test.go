@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/config"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMetadataCommand(t *testing.T) {
+	cmd := NewMetadataCommand()
+
+	assert.Equal(t, "metadata", cmd.Use)
+	push, _, err := cmd.Find([]string{"push"})
+	require.NoError(t, err)
+	assert.Equal(t, "push", push.Name())
+	for _, flag := range []string{"select", "sink", "url", "token", "platform", "env"} {
+		assert.NotNil(t, push.Flags().Lookup(flag), "flag %q should exist", flag)
+	}
+}
+
+func TestMetadataSinks(t *testing.T) {
+	cfg := &config.Config{
+		Target: &core.TargetConfig{Type: "duckdb"},
+		Metadata: &core.MetadataConfig{Sinks: []core.MetadataSinkConfig{
+			{Type: "datahub", URL: "http://datahub:8080", Env: "DEV"},
+		}},
+	}
+
+	t.Run("configured", func(t *testing.T) {
+		sinks, err := metadataSinks(cfg, &MetadataPushOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []core.MetadataSinkConfig{
+			{Type: "datahub", URL: "http://datahub:8080", Env: "DEV", Platform: "duckdb"},
+		}, sinks)
+	})
+
+	t.Run("flags override", func(t *testing.T) {
+		sinks, err := metadataSinks(cfg, &MetadataPushOptions{Sink: "datahub", URL: "http://other:8080", Platform: "motherduck"})
+		require.NoError(t, err)
+		assert.Equal(t, []core.MetadataSinkConfig{
+			{Type: "datahub", URL: "http://other:8080", Env: "DEV", Platform: "motherduck"},
+		}, sinks)
+		assert.Equal(t, "http://datahub:8080", cfg.Metadata.Sinks[0].URL, "config should not change")
+	})
+
+	t.Run("ad hoc sink", func(t *testing.T) {
+		sinks, err := metadataSinks(&config.Config{}, &MetadataPushOptions{Sink: "datahub", URL: "http://localhost:8080"})
+		require.NoError(t, err)
+		assert.Equal(t, []core.MetadataSinkConfig{{Type: "datahub", URL: "http://localhost:8080"}}, sinks)
+	})
+
+	t.Run("no sinks", func(t *testing.T) {
+		_, err := metadataSinks(&config.Config{}, &MetadataPushOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no metadata sinks configured")
+
+		_, err = metadataSinks(cfg, &MetadataPushOptions{Sink: "atlas"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no atlas sink configured")
+	})
+}
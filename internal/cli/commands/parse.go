@@ -0,0 +1,213 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/dialect"
+	"github.com/leapstack-labs/leapsql/pkg/parser"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/spf13/cobra"
+)
+
+// ParseOptions holds options for the parse command.
+type ParseOptions struct {
+	Dump    string // What to dump: ast, tokens
+	Dialect string // SQL dialect; the dialect models are written in by default
+	Format  string // Output format: text, json
+}
+
+// ParseASTOutput is the JSON representation of a parsed statement.
+type ParseASTOutput struct {
+	Dialect   string             `json:"dialect"`
+	Statement json.RawMessage    `json:"statement"` // Encoded with core.MarshalAST
+	Errors    []lintExplainError `json:"errors,omitempty"`
+}
+
+// ParseToken is the JSON representation of a lexed token.
+type ParseToken struct {
+	Type    string `json:"type"`
+	Literal string `json:"literal"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Offset  int    `json:"offset"`
+}
+
+// NewParseCommand creates the parse command.
+func NewParseCommand() *cobra.Command {
+	opts := &ParseOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "parse [file]",
+		Short: "Dump the tokens or AST of SQL for debugging",
+		Long: `Parse SQL and dump what the lexer and parser see.
+
+Use it to debug parse errors, to check how a dialect lexes its keywords
+and operators, and to attach to parser bug reports.
+
+Dumps:
+  - ast:    The parsed statement as a tree, with the source span of each node (default)
+  - tokens: The token stream, with the line and column of each token
+
+Statements with syntax errors are parsed as far as possible: the errors
+are listed before the partial tree.
+
+JSON output encodes the AST in the stable format of core.MarshalAST:
+each node is an object tagged with its "type" and carrying its "span".
+
+Reads from stdin when no file is given or the file is "-".`,
+		Example: `  # Dump the AST of a query
+  leapsql parse query.sql
+
+  # Dump the tokens of a query from stdin
+  echo "SELECT a FROM t QUALIFY a = 1" | leapsql parse --dump tokens
+
+  # Parse with another dialect
+  leapsql parse models/orders.sql --dialect snowflake
+
+  # Output the AST as JSON
+  leapsql parse query.sql --format json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return runParse(cmd, path, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Dump, "dump", "ast", "What to dump: ast, tokens")
+	cmd.Flags().StringVar(&opts.Dialect, "dialect", "", "SQL dialect (default: the dialect models are written in)")
+	cmd.Flags().StringVarP(&opts.Format, "format", "f", "", "Output format: text, json")
+
+	_ = cmd.RegisterFlagCompletionFunc("dump", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"ast", "tokens"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("dialect", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return dialect.List(), cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+func runParse(cmd *cobra.Command, path string, opts *ParseOptions) error {
+	src, err := readTraceInput(cmd, path)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(src) == "" {
+		return fmt.Errorf("no SQL provided")
+	}
+
+	cmdCtx := NewCommandContextWithoutEngine(cmd)
+	r := cmdCtx.Renderer
+
+	d, err := sqlDialect(cmdCtx.Cfg, opts.Dialect)
+	if err != nil {
+		return err
+	}
+
+	format := opts.Format
+	if format == "" {
+		if r.EffectiveMode() == output.ModeJSON {
+			format = "json"
+		} else {
+			format = "text"
+		}
+	}
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown format %q: supported formats are text, json", format)
+	}
+
+	switch opts.Dump {
+	case "tokens":
+		return parseTokens(r, src, d, format)
+	case "ast":
+		return parseAST(r, src, d, format)
+	default:
+		return fmt.Errorf("unknown dump %q: supported dumps are ast, tokens", opts.Dump)
+	}
+}
+
+// parseTokens outputs the tokens of src, lexed in dialect d.
+func parseTokens(r *output.Renderer, src string, d *core.Dialect, format string) error {
+	tokens := parser.TokenizeWithDialect(src, d)
+
+	if format == "json" {
+		out := make([]ParseToken, len(tokens))
+		for i, tok := range tokens {
+			out[i] = ParseToken{
+				Type:    tok.Type.String(),
+				Literal: tok.Literal,
+				Line:    tok.Pos.Line,
+				Column:  tok.Pos.Column,
+				Offset:  tok.Pos.Offset,
+			}
+		}
+		return r.JSON(out)
+	}
+
+	styles := r.Styles()
+	for _, tok := range tokens {
+		r.Printf("%s  %-12s %s\n",
+			styles.Muted.Render(fmt.Sprintf("%-7s", explainLocation(tok.Pos))),
+			tok.Type.String(),
+			styles.ModelPath.Render(fmt.Sprintf("%q", tok.Literal)))
+	}
+	return nil
+}
+
+// parseAST outputs the statement parsed from src in dialect d. Syntax errors
+// are listed, and fail the command, after the partial statement is output.
+func parseAST(r *output.Renderer, src string, d *core.Dialect, format string) error {
+	stmt, errs := parser.ParseTolerant(src, d)
+
+	syntaxErrs := make([]lintExplainError, 0, len(errs))
+	for _, err := range errs {
+		var pe *parser.ParseError
+		if errors.As(err, &pe) {
+			syntaxErrs = append(syntaxErrs, lintExplainError{Line: pe.Pos.Line, Column: pe.Pos.Column, Message: pe.Message})
+		} else {
+			syntaxErrs = append(syntaxErrs, lintExplainError{Message: err.Error()})
+		}
+	}
+
+	if format == "json" {
+		data, err := core.MarshalAST(stmt)
+		if err != nil {
+			return err
+		}
+		if err := r.JSON(ParseASTOutput{Dialect: d.Name, Statement: data, Errors: syntaxErrs}); err != nil {
+			return err
+		}
+	} else {
+		styles := r.Styles()
+		if len(syntaxErrs) > 0 {
+			r.Println(styles.Header2.Render(fmt.Sprintf("Syntax errors (%d):", len(syntaxErrs))))
+			for _, e := range syntaxErrs {
+				loc := explainLocation(token.Position{Line: e.Line, Column: e.Column})
+				r.Printf("  %s  %s\n", styles.Muted.Render(fmt.Sprintf("%-5s", loc)), e.Message)
+			}
+			r.Println("")
+		}
+
+		var sb strings.Builder
+		if err := parser.FprintAST(&sb, stmt); err != nil {
+			return err
+		}
+		r.Printf("%s", sb.String())
+	}
+
+	if len(syntaxErrs) > 0 {
+		return fmt.Errorf("%d syntax error(s)", len(syntaxErrs))
+	}
+	return nil
+}
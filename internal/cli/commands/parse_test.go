@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewParseCommand(t *testing.T) {
+	cmd := NewParseCommand()
+
+	assert.Equal(t, "parse [file]", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+	assert.NotEmpty(t, cmd.Example, "Example should not be empty")
+
+	flags := []string{"dump", "dialect", "format"}
+	for _, flag := range flags {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), "flag %q should exist", flag)
+	}
+	assert.Equal(t, "ast", cmd.Flags().Lookup("dump").DefValue)
+}
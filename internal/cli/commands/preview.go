@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// PreviewOptions holds options for the preview command.
+type PreviewOptions struct {
+	Limit  int
+	Format string
+}
+
+// PreviewOutput is the JSON representation of a model preview.
+type PreviewOutput struct {
+	Model   string   `json:"model"`
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+	// Truncated tells that the model's query has more rows than the limit
+	Truncated bool `json:"truncated"`
+}
+
+// NewPreviewCommand creates the preview command.
+func NewPreviewCommand() *cobra.Command {
+	opts := &PreviewOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "preview <model>",
+		Short: "Show the first rows of a model without building it",
+		Long: `Run a model's compiled query with a LIMIT against the target database and
+show its first rows, without building the model or recording a run.
+
+The tables the model reads must exist in the target: run its upstream
+models (or load its seeds) first. Use --target to preview against another
+environment than dev. Rows are capped at 10000 whatever the limit; when
+the query has more rows than shown, a note says so (or truncated is set
+in JSON output). Interrupting the command cancels the query.
+
+With --format json, the output is an object with the model, its columns
+and its rows as arrays of values, for editors to render as a table.
+Editors using leapsql lsp can request the same with leapsql/preview.`,
+		Example: `  # Show the first 100 rows of a model
+  leapsql preview staging.stg_customers
+
+  # Show 10 rows as JSON
+  leapsql preview staging.stg_customers --limit 10 --format json
+
+  # Preview against the staging environment
+  leapsql preview marts.revenue --target staging`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPreview(cmd, args[0], opts)
+		},
+	}
+
+	cmd.Flags().IntVarP(&opts.Limit, "limit", "n", 100, "Number of rows to show")
+	cmd.Flags().StringVarP(&opts.Format, "format", "f", "table", "Output format: table, json, csv, md")
+
+	return cmd
+}
+
+func runPreview(cmd *cobra.Command, modelPath string, opts *PreviewOptions) error {
+	if opts.Limit <= 0 {
+		return fmt.Errorf("invalid limit: %d", opts.Limit)
+	}
+
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	result, err := eng.PreviewModel(ctx, modelPath, opts.Limit)
+	if err != nil {
+		if ctx.Err() != nil {
+			return errors.New("preview cancelled")
+		}
+		return fmt.Errorf("failed to preview model: %w", err)
+	}
+
+	if opts.Format == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(PreviewOutput{Model: modelPath, Columns: result.Columns, Rows: result.Rows, Truncated: result.Truncated})
+	}
+
+	if err := renderRows(cmd.OutOrStdout(), result.Columns, rowMaps(result), opts.Format); err != nil {
+		return err
+	}
+	if result.Truncated {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "(first %d rows: the model has more)\n", len(result.Rows))
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	projectrules "github.com/leapstack-labs/leapsql/pkg/lint/project/rules"
+	"github.com/spf13/cobra"
+)
+
+// PruneOptions holds options for the prune command.
+type PruneOptions struct {
+	Select      string // Selector expression limiting the models pruned
+	Apply       bool   // Write the patches to the model files
+	Interactive bool   // Ask before writing each model's patch
+}
+
+// NewPruneCommand creates the prune command.
+func NewPruneCommand() *cobra.Command {
+	opts := &PruneOptions{}
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove columns no downstream model uses",
+		Long: `Find the columns no downstream model reads, the orphaned columns of lint
+rule PL02, and build a patch per model removing them from its SELECT list.
+
+By default the patches are only shown. --apply writes them to the model
+files, and --interactive asks for each model before writing its patch.
+Columns are left in place when they cannot be removed safely: columns from
+SELECT *, columns the query refers to by name, and every column of queries
+using DISTINCT, set operations or positional GROUP BY and ORDER BY.
+Models whose source differs from their rendered SQL (e.g. templated
+models) are not patched. Leaf models are never pruned: their columns are
+the project's output.
+
+Savings are estimated from each model's last successful run, taking every
+column to cost the same: values no longer written per run, and the share
+of bytes scanned and execution time of the removed columns.
+
+Output adapts to environment:
+  - Terminal: Styled output with colors
+  - Piped/Scripted: Markdown format (agent-friendly)`,
+		Example: `  # Show the columns that can be removed
+  leapsql prune
+
+  # Remove them from the model files
+  leapsql prune --apply
+
+  # Confirm each model's patch
+  leapsql prune --interactive
+
+  # Only prune staging models
+  leapsql prune --select "tag:staging"`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runPrune(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Select, "select", "s", "", "Only prune models matching a selector expression")
+	cmd.Flags().BoolVar(&opts.Apply, "apply", false, "Write the patches to the model files")
+	cmd.Flags().BoolVarP(&opts.Interactive, "interactive", "i", false, "Ask before writing each model's patch")
+
+	return cmd
+}
+
+type pruneOutput struct {
+	Model        string   `json:"model"`
+	FilePath     string   `json:"file_path"`
+	Columns      []string `json:"columns"`
+	Kept         []string `json:"kept"`
+	TotalColumns int      `json:"total_columns"`
+	Removed      []string `json:"removed"`
+	Applied      bool     `json:"applied"`
+	Values       int64    `json:"values_per_run"`
+	BytesScanned int64    `json:"bytes_scanned_per_run"`
+	ExecutionMS  int64    `json:"execution_ms_per_run"`
+}
+
+func runPrune(cmd *cobra.Command, opts *PruneOptions) error {
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	if opts.Interactive && r.EffectiveMode() == output.ModeJSON {
+		return fmt.Errorf("--interactive cannot be used with JSON output")
+	}
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	projectCtx := buildProjectContext(eng, cmdCtx.Cfg)
+	if projectCtx == nil {
+		return fmt.Errorf("no models found")
+	}
+	unused := projectrules.OrphanedColumns(projectCtx)
+
+	if opts.Select != "" {
+		paths, err := resolveSelection(eng, opts.Select)
+		if err != nil {
+			return err
+		}
+		selected := make(map[string][]string, len(paths))
+		for _, p := range paths {
+			if cols, ok := unused[p]; ok {
+				selected[p] = cols
+			}
+		}
+		unused = selected
+	}
+
+	prunes, err := eng.PlanColumnPrunes(cmd.Context(), unused)
+	if err != nil {
+		return err
+	}
+
+	applied := make(map[string]bool, len(prunes))
+	if opts.Apply || opts.Interactive {
+		applied, err = applyPrunes(r, cmd.InOrStdin(), prunes, opts.Interactive)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		out := make([]pruneOutput, 0, len(prunes))
+		for _, p := range prunes {
+			out = append(out, pruneOutput{
+				Model:        p.Model,
+				FilePath:     p.FilePath,
+				Columns:      nonNil(p.Columns),
+				Kept:         nonNil(p.Kept),
+				TotalColumns: p.TotalColumns,
+				Removed:      nonNil(removedText(p)),
+				Applied:      applied[p.Model],
+				Values:       p.Savings.Values,
+				BytesScanned: p.Savings.BytesScanned,
+				ExecutionMS:  p.Savings.ExecutionMS,
+			})
+		}
+		return r.JSON(out)
+	case output.ModeMarkdown:
+		pruneMarkdown(r, prunes, applied)
+	default:
+		pruneText(r, prunes, applied)
+	}
+	return nil
+}
+
+// applyPrunes writes the patches to the model files, asking first for each
+// one when interactive, and returns the models patched.
+func applyPrunes(r *output.Renderer, in io.Reader, prunes []engine.ColumnPrune, interactive bool) (map[string]bool, error) {
+	applied := make(map[string]bool, len(prunes))
+	answers := bufio.NewScanner(in)
+	skipped := 0
+	for _, p := range prunes {
+		if len(p.Columns) == 0 {
+			continue
+		}
+		if interactive {
+			r.Printf("Remove %s from %s? [y/N] ", strings.Join(p.Columns, ", "), p.Model)
+			if !answers.Scan() {
+				r.Println("")
+				break
+			}
+			if answer := strings.ToLower(strings.TrimSpace(answers.Text())); answer != "y" && answer != "yes" {
+				continue
+			}
+		}
+
+		res := lintFileResult{Path: p.FilePath, SQL: p.SQL, Diagnostics: []lint.Diagnostic{{Fixes: []lint.Fix{p.Fix}}}}
+		_, ok, err := fixLintFile(res, true)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			skipped++
+			continue
+		}
+		applied[p.Model] = true
+	}
+	if skipped > 0 {
+		r.Warning(fmt.Sprintf("Skipped %d model(s) whose rendered SQL differs from the source", skipped))
+	}
+	return applied, nil
+}
+
+// removedText returns the source text each edit of a patch removes, e.g.
+// "amount * 2 AS doubled".
+func removedText(p engine.ColumnPrune) []string {
+	var removed []string
+	for _, e := range p.Fix.TextEdits {
+		text := strings.Trim(p.SQL[e.Pos.Offset:e.EndPos.Offset], ", \t\r\n")
+		if text != "" {
+			removed = append(removed, text)
+		}
+	}
+	return removed
+}
+
+// describeSavings describes the estimated savings of a patch, e.g.
+// "saves ~12000 values, 1.2 MiB scanned, 150ms per run".
+func describeSavings(s engine.PruneSavings) string {
+	if s == (engine.PruneSavings{}) {
+		return "no run recorded to estimate savings"
+	}
+	parts := []string{fmt.Sprintf("~%d values", s.Values)}
+	if s.BytesScanned > 0 {
+		parts = append(parts, formatBytes(s.BytesScanned)+" scanned")
+	}
+	if s.ExecutionMS > 0 {
+		parts = append(parts, formatMS(s.ExecutionMS))
+	}
+	return "saves " + strings.Join(parts, ", ") + " per run"
+}
+
+// pruneTotals sums the columns removable and the savings of the patches.
+func pruneTotals(prunes []engine.ColumnPrune) (int, engine.PruneSavings) {
+	columns := 0
+	var total engine.PruneSavings
+	for _, p := range prunes {
+		columns += len(p.Columns)
+		total.Values += p.Savings.Values
+		total.BytesScanned += p.Savings.BytesScanned
+		total.ExecutionMS += p.Savings.ExecutionMS
+	}
+	return columns, total
+}
+
+// pruneText outputs the patches in styled text format.
+func pruneText(r *output.Renderer, prunes []engine.ColumnPrune, applied map[string]bool) {
+	r.Header(1, "Unused Columns")
+	r.Println("")
+
+	if len(prunes) == 0 {
+		r.Success("Every column is used downstream")
+		return
+	}
+
+	for _, p := range prunes {
+		switch {
+		case applied[p.Model]:
+			r.Success(fmt.Sprintf("%s: removed %d of %d column(s), %s", p.Model, len(p.Columns), p.TotalColumns, describeSavings(p.Savings)))
+		case len(p.Columns) > 0:
+			r.Warning(fmt.Sprintf("%s: %d of %d column(s) unused, %s", p.Model, len(p.Columns), p.TotalColumns, describeSavings(p.Savings)))
+		default:
+			r.Muted(p.Model + ": no unused column can be removed")
+		}
+		for _, text := range removedText(p) {
+			r.Println("  - " + text)
+		}
+		if len(p.Kept) > 0 {
+			r.Muted("  kept: " + strings.Join(p.Kept, ", "))
+		}
+	}
+
+	columns, total := pruneTotals(prunes)
+	r.Println("")
+	r.Println(fmt.Sprintf("%d column(s) removable in %d model(s), %s", columns, len(prunes), describeSavings(total)))
+}
+
+// pruneMarkdown outputs the patches in markdown format.
+func pruneMarkdown(r *output.Renderer, prunes []engine.ColumnPrune, applied map[string]bool) {
+	r.Println(output.FormatHeader(1, "Unused Columns"))
+	r.Println("")
+
+	if len(prunes) == 0 {
+		r.Println("Every column is used downstream.")
+		return
+	}
+
+	for _, p := range prunes {
+		status := "unused"
+		if applied[p.Model] {
+			status = "removed"
+		}
+		r.Printf("- **%s** %s: %s (%s)\n", p.Model, status, strings.Join(nonNil(p.Columns), ", "), describeSavings(p.Savings))
+		if len(p.Kept) > 0 {
+			r.Printf("  - kept: %s\n", strings.Join(p.Kept, ", "))
+		}
+	}
+
+	columns, total := pruneTotals(prunes)
+	r.Println("")
+	r.Printf("%d column(s) removable in %d model(s), %s.\n", columns, len(prunes), describeSavings(total))
+}
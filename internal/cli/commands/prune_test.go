@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPruneCommand(t *testing.T) {
+	cmd := NewPruneCommand()
+
+	assert.Equal(t, "prune", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+	assert.NotEmpty(t, cmd.Example, "Example should not be empty")
+	for _, flag := range []string{"select", "apply", "interactive"} {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), "flag %q should exist", flag)
+	}
+}
+
+func TestApplyPrunes(t *testing.T) {
+	const sql = "SELECT id, notes FROM orders"
+	r := output.NewRenderer(&bytes.Buffer{}, &bytes.Buffer{}, output.ModeText)
+
+	newPrune := func(t *testing.T, model string) engine.ColumnPrune {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), model+".sql")
+		require.NoError(t, os.WriteFile(path, []byte("/*---\nname: "+model+"\n---*/\n"+sql+"\n"), 0o600))
+		return engine.ColumnPrune{
+			Model:    model,
+			FilePath: path,
+			Columns:  []string{"notes"},
+			SQL:      sql,
+			Fix: lint.Fix{Kind: lint.FixSuggestion, TextEdits: []lint.TextEdit{{
+				Pos:    token.Position{Offset: 9},
+				EndPos: token.Position{Offset: 16},
+			}}},
+		}
+	}
+	read := func(t *testing.T, path string) string {
+		t.Helper()
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	t.Run("apply all", func(t *testing.T) {
+		p := newPrune(t, "orders")
+		applied, err := applyPrunes(r, strings.NewReader(""), []engine.ColumnPrune{p}, false)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"orders": true}, applied)
+		assert.Equal(t, "/*---\nname: orders\n---*/\nSELECT id FROM orders\n", read(t, p.FilePath))
+		assert.Equal(t, []string{"notes"}, removedText(p))
+	})
+
+	t.Run("interactive", func(t *testing.T) {
+		yes, no := newPrune(t, "orders"), newPrune(t, "refunds")
+		applied, err := applyPrunes(r, strings.NewReader("y\nn\n"), []engine.ColumnPrune{yes, no}, true)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"orders": true}, applied)
+		assert.Contains(t, read(t, yes.FilePath), "SELECT id FROM orders")
+		assert.Contains(t, read(t, no.FilePath), sql)
+	})
+}
+
+func TestDescribeSavings(t *testing.T) {
+	assert.Equal(t, "no run recorded to estimate savings", describeSavings(engine.PruneSavings{}))
+	assert.Equal(t, "saves ~2000 values, 1.5 KiB scanned, 250ms per run",
+		describeSavings(engine.PruneSavings{Values: 2000, BytesScanned: 1536, ExecutionMS: 250}))
+}
@@ -7,8 +7,10 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/leapstack-labs/leapsql/internal/cli/config"
+	"github.com/leapstack-labs/leapsql/internal/engine"
 	"github.com/spf13/cobra"
 
 	// sqlite driver for state database queries.
@@ -28,10 +30,19 @@ func openStateDBReadOnly(path string) (*sql.DB, error) {
 	return sql.Open("sqlite", path+"?mode=ro")
 }
 
+// Databases the query command can query.
+const (
+	queryDBState  = "state"
+	queryDBTarget = "target"
+)
+
 // QueryOptions holds options for the query command.
 type QueryOptions struct {
-	Format string
-	Input  string
+	Format   string
+	Input    string
+	DB       string
+	Cache    bool
+	CacheTTL time.Duration
 }
 
 // NewQueryCommand creates the query command.
@@ -40,13 +51,17 @@ func NewQueryCommand() *cobra.Command {
 
 	cmd := &cobra.Command{
 		Use:   "query [SQL]",
-		Short: "Query the state database",
+		Short: "Query the state or target database",
 		Long: `Query the LeapSQL state database directly.
 
 Execute SQL queries against the state database to inspect runs, models,
 dependencies, and other pipeline metadata. Supports multiple output formats
 for scripting and integration.
 
+With --db target, queries run against the active target's database instead,
+for quick checks of built models during development. Add --cache to reuse
+the results of a query against the same target until --cache-ttl expires.
+
 When invoked without arguments, enters interactive REPL mode.`,
 		Example: `  # Execute SQL directly
   leapsql query "SELECT * FROM v_runs"
@@ -62,6 +77,9 @@ When invoked without arguments, enters interactive REPL mode.`,
   
   # Output as JSON
   leapsql query "SELECT * FROM v_models" --format json
+
+  # Query the target database, caching results for 10 minutes
+  leapsql query --db target --cache --cache-ttl 10m "SELECT * FROM marts.revenue"
   
   # Interactive mode
   leapsql query`,
@@ -73,6 +91,9 @@ When invoked without arguments, enters interactive REPL mode.`,
 	// Flags
 	cmd.Flags().StringVarP(&opts.Format, "format", "f", "table", "Output format: table, json, csv, md")
 	cmd.Flags().StringVarP(&opts.Input, "input", "i", "", "Read SQL from file")
+	cmd.Flags().StringVar(&opts.DB, "db", queryDBState, "Database to query: state, target")
+	cmd.Flags().BoolVar(&opts.Cache, "cache", false, "Reuse cached results of target queries")
+	cmd.Flags().DurationVar(&opts.CacheTTL, "cache-ttl", time.Hour, "How long cached target query results are reused")
 
 	// Subcommands
 	cmd.AddCommand(newQueryTablesCommand(opts))
@@ -84,6 +105,14 @@ When invoked without arguments, enters interactive REPL mode.`,
 }
 
 func runQuery(cmd *cobra.Command, args []string, opts *QueryOptions) error {
+	switch opts.DB {
+	case queryDBState:
+	case queryDBTarget:
+		return runTargetQuery(cmd, args, opts)
+	default:
+		return fmt.Errorf("invalid --db %q: must be %s or %s", opts.DB, queryDBState, queryDBTarget)
+	}
+
 	// Get config for state path
 	cmdCtx := NewCommandContextWithoutEngine(cmd)
 	statePath := resolveStatePath(cmdCtx.Cfg)
@@ -93,32 +122,97 @@ func runQuery(cmd *cobra.Command, args []string, opts *QueryOptions) error {
 		return fmt.Errorf("state database not found at %s (run 'leapsql run' first)", statePath)
 	}
 
-	// Determine SQL source
-	var sqlQuery string
+	sqlQuery, ok, err := readQueryInput(args, opts)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// No input, TTY detected - enter REPL mode
+		return runQueryREPL(cmd, statePath, opts)
+	}
+
+	// Execute the query
+	return executeAndRender(cmd.Context(), cmd, statePath, sqlQuery, opts.Format)
+}
 
+// readQueryInput returns the SQL to execute from the arguments, the input
+// file or piped stdin. It returns false if there is none and stdin is a
+// terminal.
+func readQueryInput(args []string, opts *QueryOptions) (string, bool, error) {
 	switch {
 	case len(args) > 0:
-		sqlQuery = strings.Join(args, " ")
+		return strings.Join(args, " "), true, nil
 	case opts.Input != "":
 		content, err := os.ReadFile(opts.Input)
 		if err != nil {
-			return fmt.Errorf("failed to read file: %w", err)
+			return "", false, fmt.Errorf("failed to read file: %w", err)
 		}
-		sqlQuery = string(content)
+		return string(content), true, nil
 	case !isTerminal(os.Stdin):
 		// Read from stdin (piped input)
 		content, err := io.ReadAll(os.Stdin)
 		if err != nil {
-			return fmt.Errorf("failed to read stdin: %w", err)
+			return "", false, fmt.Errorf("failed to read stdin: %w", err)
 		}
-		sqlQuery = string(content)
+		return string(content), true, nil
 	default:
-		// No input, TTY detected - enter REPL mode
-		return runQueryREPL(cmd, statePath, opts)
+		return "", false, nil
 	}
+}
 
-	// Execute the query
-	return executeAndRender(cmd.Context(), cmd, statePath, sqlQuery, opts.Format)
+// runTargetQuery executes SQL against the active target's database.
+func runTargetQuery(cmd *cobra.Command, args []string, opts *QueryOptions) error {
+	sqlQuery, ok, err := readQueryInput(args, opts)
+	if err != nil {
+		return err
+	}
+
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if !ok {
+		return runTargetREPL(cmd, cmdCtx.Engine, cmdCtx.Cfg, opts)
+	}
+	return executeOnTarget(cmd, cmdCtx.Engine, sqlQuery, opts)
+}
+
+// executeOnTarget runs a query against the target and renders its results.
+// A note on stderr tells when results come from the cache.
+func executeOnTarget(cmd *cobra.Command, eng *engine.Engine, sqlQuery string, opts *QueryOptions) error {
+	var queryOpts engine.QueryOptions
+	if opts.Cache {
+		queryOpts.CacheTTL = opts.CacheTTL
+	}
+	result, err := eng.Query(cmd.Context(), sqlQuery, queryOpts)
+	if err != nil {
+		return fmt.Errorf("query failed: %w", err)
+	}
+
+	if err := renderRows(cmd.OutOrStdout(), result.Columns, rowMaps(result), opts.Format); err != nil {
+		return err
+	}
+
+	if !result.CachedAt.IsZero() {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "(cached result from %s)\n", result.CachedAt.Format(time.DateTime))
+	}
+	return nil
+}
+
+// rowMaps returns the rows of a query result keyed by column, as rendered
+// by renderRows.
+func rowMaps(result *engine.QueryResult) []map[string]any {
+	rows := make([]map[string]any, len(result.Rows))
+	for i, values := range result.Rows {
+		row := make(map[string]any, len(result.Columns))
+		for j, col := range result.Columns {
+			row[col] = values[j]
+		}
+		rows[i] = row
+	}
+	return rows
 }
 
 func executeAndRender(ctx context.Context, cmd *cobra.Command, statePath, sqlQuery, format string) error {
@@ -47,6 +47,11 @@ func renderResults(w io.Writer, rows *sql.Rows, format string) error {
 		return err
 	}
 
+	return renderRows(w, cols, results, format)
+}
+
+// renderRows renders collected query results in the given format.
+func renderRows(w io.Writer, cols []string, results []map[string]any, format string) error {
 	switch format {
 	case "json":
 		return renderJSON(w, results)
@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"github.com/chzyer/readline"
+	"github.com/leapstack-labs/leapsql/internal/cli/config"
+	"github.com/leapstack-labs/leapsql/internal/engine"
 	"github.com/spf13/cobra"
 )
 
@@ -29,11 +31,37 @@ func runQueryREPL(cmd *cobra.Command, statePath string, opts *QueryOptions) erro
 	// Get table names for completion
 	completer := newTableCompleter(ctx, db)
 
+	return replLoop(cmd, replConfig{
+		historyFile: historyFile,
+		completer:   completer,
+		banner:      fmt.Sprintf("LeapSQL Query REPL (state: %s)", statePath),
+		dotCommand: func(line string) bool {
+			return handleDotCommand(ctx, cmd, db, line, opts.Format)
+		},
+		execute: func(query string) error {
+			return executeAndRenderQuery(ctx, cmd, db, query, opts.Format)
+		},
+	})
+}
+
+// replConfig configures the REPL loop for a database.
+type replConfig struct {
+	historyFile string
+	completer   readline.AutoCompleter
+	banner      string
+	// dotCommand handles a dot-command, returning false if it is not one
+	dotCommand func(line string) bool
+	// execute runs a SQL statement and renders its results
+	execute func(query string) error
+}
+
+// replLoop reads SQL statements and dot-commands until .quit or EOF.
+func replLoop(cmd *cobra.Command, cfg replConfig) error {
 	// Configure readline
 	rl, err := readline.NewEx(&readline.Config{
 		Prompt:          "leapsql> ",
-		HistoryFile:     historyFile,
-		AutoComplete:    completer,
+		HistoryFile:     cfg.historyFile,
+		AutoComplete:    cfg.completer,
 		InterruptPrompt: "^C",
 		EOFPrompt:       ".quit",
 	})
@@ -43,7 +71,7 @@ func runQueryREPL(cmd *cobra.Command, statePath string, opts *QueryOptions) erro
 	defer func() { _ = rl.Close() }()
 
 	// Print welcome message
-	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "LeapSQL Query REPL (state: %s)\n", statePath)
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), cfg.banner)
 	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Type .help for commands, .quit to exit")
 	_, _ = fmt.Fprintln(cmd.OutOrStdout())
 
@@ -67,7 +95,7 @@ func runQueryREPL(cmd *cobra.Command, statePath string, opts *QueryOptions) erro
 
 		// Handle dot-commands
 		if strings.HasPrefix(line, ".") {
-			if handled := handleDotCommand(ctx, cmd, db, line, opts.Format); handled {
+			if handled := cfg.dotCommand(line); handled {
 				if line == ".quit" || line == ".exit" {
 					break
 				}
@@ -88,7 +116,7 @@ func runQueryREPL(cmd *cobra.Command, statePath string, opts *QueryOptions) erro
 		query := strings.TrimSuffix(multiLineBuffer.String(), ";")
 		multiLineBuffer.Reset()
 
-		if err := executeAndRenderQuery(ctx, cmd, db, query, opts.Format); err != nil {
+		if err := cfg.execute(query); err != nil {
 			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
 		}
 		_, _ = fmt.Fprintln(cmd.OutOrStdout())
@@ -143,7 +171,7 @@ func handleDotCommand(ctx context.Context, cmd *cobra.Command, db *sql.DB, line,
 		return true
 
 	case ".clear":
-		fmt.Print("\033[H\033[2J")
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), "\033[H\033[2J")
 		return true
 
 	default:
@@ -207,3 +235,114 @@ func newTableCompleter(ctx context.Context, db *sql.DB) *readline.PrefixComplete
 
 	return readline.NewPrefixCompleter(items...)
 }
+
+// targetTablesQuery lists the tables and views of the target database.
+const targetTablesQuery = `SELECT table_schema, table_name, table_type
+FROM information_schema.tables
+WHERE table_schema NOT IN ('information_schema', 'pg_catalog')
+ORDER BY table_schema, table_name`
+
+// runTargetREPL runs the REPL against the active target's database.
+func runTargetREPL(cmd *cobra.Command, eng *engine.Engine, cfg *config.Config, opts *QueryOptions) error {
+	ctx := cmd.Context()
+	if err := eng.EnsureConnected(ctx); err != nil {
+		return err
+	}
+
+	target := "default"
+	if cfg.Target != nil && cfg.Target.Type != "" {
+		target = cfg.Target.Type
+	}
+
+	return replLoop(cmd, replConfig{
+		historyFile: filepath.Join(filepath.Dir(resolveStatePath(cfg)), "query_history"),
+		completer:   newTargetCompleter(),
+		banner:      fmt.Sprintf("LeapSQL Query REPL (target: %s)", target),
+		dotCommand: func(line string) bool {
+			return handleTargetDotCommand(cmd, eng, line, opts)
+		},
+		execute: func(query string) error {
+			return executeOnTarget(cmd, eng, query, opts)
+		},
+	})
+}
+
+func handleTargetDotCommand(cmd *cobra.Command, eng *engine.Engine, line string, opts *QueryOptions) bool {
+	parts := strings.Fields(line)
+	command := strings.ToLower(parts[0])
+
+	switch command {
+	case ".quit", ".exit":
+		return true
+
+	case ".help":
+		printTargetREPLHelp(cmd.OutOrStdout())
+		return true
+
+	case ".tables":
+		tablesOpts := *opts
+		tablesOpts.Cache = false
+		if err := executeOnTarget(cmd, eng, targetTablesQuery, &tablesOpts); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+		}
+		return true
+
+	case ".schema":
+		if len(parts) < 2 {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Usage: .schema <table>")
+			return true
+		}
+		meta, err := eng.GetAdapter().GetTableMetadata(cmd.Context(), parts[1])
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+			return true
+		}
+		cols := []string{"name", "type", "nullable"}
+		results := make([]map[string]any, len(meta.Columns))
+		for i, c := range meta.Columns {
+			results[i] = map[string]any{"name": c.Name, "type": c.Type, "nullable": c.Nullable}
+		}
+		if err := renderRows(cmd.OutOrStdout(), cols, results, opts.Format); err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Error: %v\n", err)
+		}
+		return true
+
+	case ".clear":
+		_, _ = fmt.Fprint(cmd.OutOrStdout(), "\033[H\033[2J")
+		return true
+
+	default:
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Unknown command: %s (type .help for commands)\n", command)
+		return true
+	}
+}
+
+func printTargetREPLHelp(w io.Writer) {
+	help := `
+Commands:
+  .help           Show this help message
+  .tables         List tables and views in the target database
+  .schema <name>  Show columns of a table or view
+  .clear          Clear the screen
+  .quit / .exit   Exit the REPL
+
+Tips:
+  - SQL statements must end with a semicolon (;)
+  - Use arrow keys to navigate history
+  - Start with --cache to reuse results of repeated queries
+`
+	_, _ = fmt.Fprintln(w, help)
+}
+
+// newTargetCompleter creates a readline completer for the target REPL's
+// dot-commands.
+func newTargetCompleter() *readline.PrefixCompleter {
+	return readline.NewPrefixCompleter(
+		readline.PcItem(".help"),
+		readline.PcItem(".tables"),
+		readline.PcItem(".schema"),
+		readline.PcItem(".clear"),
+		readline.PcItem(".quit"),
+		readline.PcItem(".exit"),
+	)
+}
@@ -319,6 +319,35 @@ func TestNewQueryCommand(t *testing.T) {
 	assert.Contains(t, names, "views")
 	assert.Contains(t, names, "schema")
 	assert.Contains(t, names, "search")
+
+	// Target query flags
+	db := cmd.Flags().Lookup("db")
+	require.NotNil(t, db)
+	assert.Equal(t, "state", db.DefValue)
+	assert.NotNil(t, cmd.Flags().Lookup("cache"))
+	ttl := cmd.Flags().Lookup("cache-ttl")
+	require.NotNil(t, ttl)
+	assert.Equal(t, "1h0m0s", ttl.DefValue)
+}
+
+func TestQueryREPL_Clear(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetOut(&buf)
+
+	assert.True(t, handleTargetDotCommand(cmd, nil, ".clear", &QueryOptions{}))
+	assert.Equal(t, "\033[H\033[2J", buf.String(), ".clear writes to the command's output")
+
+	buf.Reset()
+	assert.True(t, handleDotCommand(context.Background(), cmd, nil, ".clear", "table"))
+	assert.Equal(t, "\033[H\033[2J", buf.String())
+}
+
+func TestQueryCommand_InvalidDB(t *testing.T) {
+	cmd := &cobra.Command{}
+	err := runQuery(cmd, []string{"SELECT 1"}, &QueryOptions{DB: "warehouse"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid --db "warehouse"`)
 }
 
 func TestQueryCommand_NoDB(t *testing.T) {
@@ -0,0 +1,487 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/spf13/cobra"
+)
+
+// RenameColumnOptions holds options for the refactor rename-column command.
+type RenameColumnOptions struct {
+	Apply bool // Write the patches to the model files
+}
+
+// ExtractOptions holds options for the refactor extract-cte and
+// extract-model commands.
+type ExtractOptions struct {
+	Apply bool // Write the patch to the model file, and the new model
+}
+
+// NewRefactorCommand creates the refactor command.
+func NewRefactorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refactor",
+		Short: "Rewrite models across the DAG",
+		Long: `Refactor models with patches spanning the models that depend on them.
+
+Use the subcommands to plan a refactoring: patches are shown as a preview
+until --apply writes them to the model files.`,
+	}
+
+	cmd.AddCommand(newRenameColumnCommand())
+	cmd.AddCommand(newExtractCTECommand())
+	cmd.AddCommand(newExtractModelCommand())
+
+	return cmd
+}
+
+func newRenameColumnCommand() *cobra.Command {
+	opts := &RenameColumnOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "rename-column <model.column> <new_name>",
+		Short: "Rename a model column and the references to it downstream",
+		Long: `Rename a column of a model and rewrite the models reading it.
+
+The select item defining the column is renamed: its alias is replaced, or
+a column selected without one is given one. In the models reading the
+model directly, references to the column are renamed wherever they are
+qualified by the model's table or alias: select lists, WHERE, GROUP BY,
+join keys and so on. Unqualified references are renamed too, unless the
+model reads another model with the same column. Downstream models keep
+their own output columns: a reference selected without an alias is given
+the old name as alias, so nothing further downstream changes.
+
+By default the patches are only shown, as a diff of each model's SQL.
+--apply writes them to the model files. What cannot be rewritten is
+reported instead: columns coming from SELECT *, JOIN ... USING, and
+models whose source differs from their rendered SQL (e.g. templated
+models) are left for you to edit.
+
+Output adapts to environment:
+  - Terminal: Styled output with colors
+  - Piped/Scripted: Markdown format (agent-friendly)`,
+		Example: `  # Preview renaming a column
+  leapsql refactor rename-column staging.stg_orders.amount amount_usd
+
+  # Write the patches to the model files
+  leapsql refactor rename-column staging.stg_orders.amount amount_usd --apply`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRenameColumn(cmd, args[0], args[1], opts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Apply, "apply", false, "Write the patches to the model files")
+
+	return cmd
+}
+
+type renamePatchOutput struct {
+	Model    string   `json:"model"`
+	FilePath string   `json:"file_path"`
+	Diff     []string `json:"diff"`
+	Notes    []string `json:"notes"`
+	Applied  bool     `json:"applied"`
+}
+
+type renameColumnOutput struct {
+	Model   string              `json:"model"`
+	Column  string              `json:"column"`
+	NewName string              `json:"new_name"`
+	Patches []renamePatchOutput `json:"patches"`
+}
+
+func runRenameColumn(cmd *cobra.Command, target, name string, opts *RenameColumnOptions) error {
+	i := strings.LastIndex(target, ".")
+	if i <= 0 || i == len(target)-1 {
+		return fmt.Errorf("invalid column %q: use <model>.<column>, e.g. staging.stg_orders.amount", target)
+	}
+	modelPath, column := target[:i], target[i+1:]
+
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+	rename, err := eng.PlanColumnRename(modelPath, column, name)
+	if err != nil {
+		return err
+	}
+
+	applied := make(map[string]bool, len(rename.Patches))
+	if opts.Apply {
+		skipped := 0
+		for _, p := range rename.Patches {
+			if len(p.Fix.TextEdits) == 0 {
+				continue
+			}
+			res := lintFileResult{Path: p.FilePath, SQL: p.SQL, Diagnostics: []lint.Diagnostic{{Fixes: []lint.Fix{p.Fix}}}}
+			_, ok, err := fixLintFile(res, true)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				skipped++
+				continue
+			}
+			applied[p.Model] = true
+		}
+		if skipped > 0 {
+			r.Warning(fmt.Sprintf("Skipped %d model(s) whose rendered SQL differs from the source", skipped))
+		}
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		out := renameColumnOutput{Model: rename.Model, Column: rename.Column, NewName: rename.NewName, Patches: []renamePatchOutput{}}
+		for _, p := range rename.Patches {
+			out.Patches = append(out.Patches, renamePatchOutput{
+				Model:    p.Model,
+				FilePath: p.FilePath,
+				Diff:     nonNil(patchDiff(p.SQL, p.Fix)),
+				Notes:    nonNil(p.Notes),
+				Applied:  applied[p.Model],
+			})
+		}
+		return r.JSON(out)
+	case output.ModeMarkdown:
+		renameColumnMarkdown(r, rename, applied, !opts.Apply)
+	default:
+		renameColumnText(r, rename, applied, !opts.Apply)
+	}
+	return nil
+}
+
+// patchDiff returns the lines of sql a fix changes, as "-" lines removed
+// and "+" lines added with their line number, e.g. "-3: GROUP BY amount".
+// Lines are matched along their longest common subsequence.
+func patchDiff(sql string, fix lint.Fix) []string {
+	if len(fix.TextEdits) == 0 {
+		return nil
+	}
+	patched, _ := lint.ApplyFixes(sql, []lint.Diagnostic{{Fixes: []lint.Fix{fix}}}, true)
+	before, after := strings.Split(sql, "\n"), strings.Split(patched, "\n")
+
+	// common[i][j] is the length of the longest common subsequence of
+	// before[i:] and after[j:]
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			i++
+			j++
+		case j == len(after) || (i < len(before) && common[i+1][j] >= common[i][j+1]):
+			diff = append(diff, fmt.Sprintf("-%d: %s", i+1, before[i]))
+			i++
+		default:
+			diff = append(diff, fmt.Sprintf("+%d: %s", j+1, after[j]))
+			j++
+		}
+	}
+	return diff
+}
+
+// renameColumnText outputs the patches in styled text format.
+func renameColumnText(r *output.Renderer, rename *engine.ColumnRename, applied map[string]bool, dryRun bool) {
+	r.Header(1, fmt.Sprintf("Rename %s.%s to %s", rename.Model, rename.Column, rename.NewName))
+	r.Println("")
+
+	for _, p := range rename.Patches {
+		switch {
+		case applied[p.Model]:
+			r.Success(fmt.Sprintf("%s: patched %s", p.Model, p.FilePath))
+		case len(p.Fix.TextEdits) > 0:
+			r.Println(fmt.Sprintf("%s (%s)", p.Model, p.FilePath))
+		default:
+			r.Muted(p.Model + ": nothing to rename")
+		}
+		for _, line := range patchDiff(p.SQL, p.Fix) {
+			r.Println("  " + line)
+		}
+		for _, note := range p.Notes {
+			r.Warning("  " + note)
+		}
+	}
+
+	if dryRun {
+		r.Println("")
+		r.Muted("Dry run: use --apply to write the patches")
+	}
+}
+
+// renameColumnMarkdown outputs the patches in markdown format.
+func renameColumnMarkdown(r *output.Renderer, rename *engine.ColumnRename, applied map[string]bool, dryRun bool) {
+	r.Println(output.FormatHeader(1, fmt.Sprintf("Rename %s.%s to %s", rename.Model, rename.Column, rename.NewName)))
+
+	for _, p := range rename.Patches {
+		status := "patch"
+		if applied[p.Model] {
+			status = "patched"
+		}
+		r.Println("")
+		r.Printf("- **%s** (`%s`) %s\n", p.Model, p.FilePath, status)
+		if diff := patchDiff(p.SQL, p.Fix); len(diff) > 0 {
+			r.Println("")
+			r.Println("```diff")
+			for _, line := range diff {
+				r.Println(line)
+			}
+			r.Println("```")
+		}
+		for _, note := range p.Notes {
+			r.Printf("  - note: %s\n", note)
+		}
+	}
+
+	if dryRun {
+		r.Println("")
+		r.Println("Dry run: use --apply to write the patches.")
+	}
+}
+
+func newExtractCTECommand() *cobra.Command {
+	opts := &ExtractOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "extract-cte <model> <subquery_alias> <cte_name>",
+		Short: "Turn a subquery of a model into a named CTE",
+		Long: `Move a subquery of a model's FROM clause into a CTE.
+
+The subquery is named by its alias. It becomes a CTE named <cte_name>,
+read under the same alias where the subquery was, so the model's output
+doesn't change. The CTE is added before the CTE holding the subquery, after
+the last CTE otherwise, or in a new WITH clause. The subquery's text is
+moved as is, reindented.
+
+By default the patch is only shown, as a diff of the model's SQL. --apply
+writes it to the model file. Models whose source differs from their
+rendered SQL (e.g. templated models) are left for you to edit.
+
+Output adapts to environment:
+  - Terminal: Styled output with colors
+  - Piped/Scripted: Markdown format (agent-friendly)`,
+		Example: `  # Preview extracting the subquery aliased "totals"
+  leapsql refactor extract-cte marts.customer_orders totals order_totals
+
+  # Write the patch to the model file
+  leapsql refactor extract-cte marts.customer_orders totals order_totals --apply`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			title := fmt.Sprintf("Extract subquery %s of %s into CTE %s", args[1], args[0], args[2])
+			return runExtract(cmd, title, opts, func(eng *engine.Engine) (*engine.Extraction, error) {
+				return eng.PlanExtractCTE(args[0], args[1], args[2])
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Apply, "apply", false, "Write the patch to the model file")
+
+	return cmd
+}
+
+func newExtractModelCommand() *cobra.Command {
+	opts := &ExtractOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "extract-model <model> <cte> [new_model_name]",
+		Short: "Turn a CTE of a model into a model of its own",
+		Long: `Move a CTE of a model into a new model, read with ref().
+
+The new model is created next to the model, named after the CTE unless
+[new_model_name] is given. Its SQL is the CTE's query, formatted. The CTE
+is removed from the model's WITH clause, and the model reads the new model
+with {{ ref('<new_model_name>') }} wherever it read the CTE, aliased by the
+CTE's name. A CTE reading other CTEs of the model cannot be extracted:
+extract those first.
+
+By default the patch and the new model are only shown. --apply writes the
+patch to the model file and creates the new model's file. Models whose
+source differs from their rendered SQL (e.g. templated models) are left
+for you to edit.
+
+Output adapts to environment:
+  - Terminal: Styled output with colors
+  - Piped/Scripted: Markdown format (agent-friendly)`,
+		Example: `  # Preview extracting the CTE "paid_orders" into a model
+  leapsql refactor extract-model marts.customer_orders paid_orders
+
+  # Name the new model and write the files
+  leapsql refactor extract-model marts.customer_orders paid_orders int_paid_orders --apply`,
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[1]
+			if len(args) == 3 {
+				name = args[2]
+			}
+			title := fmt.Sprintf("Extract CTE %s of %s into model %s", args[1], args[0], name)
+			return runExtract(cmd, title, opts, func(eng *engine.Engine) (*engine.Extraction, error) {
+				return eng.PlanExtractModel(args[0], args[1], name)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.Apply, "apply", false, "Write the patch to the model file and create the new model")
+
+	return cmd
+}
+
+type extractOutput struct {
+	Model       string   `json:"model"`
+	FilePath    string   `json:"file_path"`
+	Diff        []string `json:"diff"`
+	NewModel    string   `json:"new_model,omitempty"`
+	NewFilePath string   `json:"new_file_path,omitempty"`
+	NewSQL      string   `json:"new_sql,omitempty"`
+	Applied     bool     `json:"applied"`
+}
+
+func runExtract(cmd *cobra.Command, title string, opts *ExtractOptions, plan func(*engine.Engine) (*engine.Extraction, error)) error {
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+	ext, err := plan(eng)
+	if err != nil {
+		return err
+	}
+
+	applied := false
+	if opts.Apply {
+		res := lintFileResult{Path: ext.FilePath, SQL: ext.SQL, Diagnostics: []lint.Diagnostic{{Fixes: []lint.Fix{ext.Fix}}}}
+		_, ok, err := fixLintFile(res, true)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("cannot patch %s: its rendered SQL differs from the source", ext.FilePath)
+		}
+		if ext.NewFilePath != "" {
+			if err := os.WriteFile(ext.NewFilePath, []byte(ext.NewSQL), 0600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", ext.NewFilePath, err)
+			}
+		}
+		applied = true
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		return r.JSON(extractOutput{
+			Model:       ext.Model,
+			FilePath:    ext.FilePath,
+			Diff:        nonNil(patchDiff(ext.SQL, ext.Fix)),
+			NewModel:    ext.NewModel,
+			NewFilePath: ext.NewFilePath,
+			NewSQL:      ext.NewSQL,
+			Applied:     applied,
+		})
+	case output.ModeMarkdown:
+		extractMarkdown(r, title, ext, applied)
+	default:
+		extractText(r, title, ext, applied)
+	}
+	return nil
+}
+
+// extractText outputs an extraction in styled text format.
+func extractText(r *output.Renderer, title string, ext *engine.Extraction, applied bool) {
+	r.Header(1, title)
+	r.Println("")
+
+	if applied {
+		r.Success(fmt.Sprintf("%s: patched %s", ext.Model, ext.FilePath))
+	} else {
+		r.Println(fmt.Sprintf("%s (%s)", ext.Model, ext.FilePath))
+	}
+	for _, line := range patchDiff(ext.SQL, ext.Fix) {
+		r.Println("  " + line)
+	}
+
+	if ext.NewFilePath != "" {
+		r.Println("")
+		if applied {
+			r.Success(fmt.Sprintf("%s: created %s", ext.NewModel, ext.NewFilePath))
+		} else {
+			r.Println(fmt.Sprintf("%s (%s)", ext.NewModel, ext.NewFilePath))
+		}
+		for _, line := range strings.Split(strings.TrimRight(ext.NewSQL, "\n"), "\n") {
+			r.Println("  " + line)
+		}
+	}
+
+	if !applied {
+		r.Println("")
+		r.Muted("Dry run: use --apply to write the patch")
+	}
+}
+
+// extractMarkdown outputs an extraction in markdown format.
+func extractMarkdown(r *output.Renderer, title string, ext *engine.Extraction, applied bool) {
+	r.Println(output.FormatHeader(1, title))
+
+	status := "patch"
+	if applied {
+		status = "patched"
+	}
+	r.Println("")
+	r.Printf("- **%s** (`%s`) %s\n", ext.Model, ext.FilePath, status)
+	r.Println("")
+	r.Println("```diff")
+	for _, line := range patchDiff(ext.SQL, ext.Fix) {
+		r.Println(line)
+	}
+	r.Println("```")
+
+	if ext.NewFilePath != "" {
+		status = "new model"
+		if applied {
+			status = "created"
+		}
+		r.Println("")
+		r.Printf("- **%s** (`%s`) %s\n", ext.NewModel, ext.NewFilePath, status)
+		r.Println("")
+		r.Println("```sql")
+		r.Println(strings.TrimRight(ext.NewSQL, "\n"))
+		r.Println("```")
+	}
+
+	if !applied {
+		r.Println("")
+		r.Println("Dry run: use --apply to write the patch.")
+	}
+}
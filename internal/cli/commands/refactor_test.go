@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRefactorCommand(t *testing.T) {
+	cmd := NewRefactorCommand()
+
+	assert.Equal(t, "refactor", cmd.Use)
+	rename, _, err := cmd.Find([]string{"rename-column"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, rename.Example, "Example should not be empty")
+	assert.NotNil(t, rename.Flags().Lookup("apply"), "flag apply should exist")
+
+	for _, name := range []string{"extract-cte", "extract-model"} {
+		sub, _, err := cmd.Find([]string{name})
+		assert.NoError(t, err)
+		assert.Equal(t, name, sub.Name())
+		assert.NotNil(t, sub.Flags().Lookup("apply"), "flag apply should exist")
+	}
+}
+
+func TestPatchDiff(t *testing.T) {
+	const sql = "SELECT id, amount\nFROM orders\nGROUP BY amount"
+	fix := lint.Fix{TextEdits: []lint.TextEdit{
+		{Pos: token.Position{Offset: 11}, EndPos: token.Position{Offset: 17}, NewText: "amount_usd AS amount"},
+		{Pos: token.Position{Offset: 39}, EndPos: token.Position{Offset: 45}, NewText: "amount_usd"},
+	}}
+	assert.Equal(t, []string{
+		"-1: SELECT id, amount",
+		"+1: SELECT id, amount_usd AS amount",
+		"-3: GROUP BY amount",
+		"+3: GROUP BY amount_usd",
+	}, patchDiff(sql, fix))
+
+	// Lines added and removed
+	fix = lint.Fix{TextEdits: []lint.TextEdit{
+		{Pos: token.Position{Offset: 0}, EndPos: token.Position{Offset: 0}, NewText: "WITH o AS (SELECT 1)\n"},
+		{Pos: token.Position{Offset: 29}, EndPos: token.Position{Offset: 45}, NewText: ""},
+	}}
+	assert.Equal(t, []string{
+		"+1: WITH o AS (SELECT 1)",
+		"-3: GROUP BY amount",
+	}, patchDiff(sql, fix))
+
+	assert.Empty(t, patchDiff(sql, lint.Fix{}))
+}
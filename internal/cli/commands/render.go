@@ -1,9 +1,17 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/leapstack-labs/leapsql/internal/cli/output"
 	"github.com/leapstack-labs/leapsql/internal/engine"
 	"github.com/spf13/cobra"
@@ -11,13 +19,29 @@ import (
 
 // NewRenderCommand creates the render command.
 func NewRenderCommand() *cobra.Command {
+	var dialectName string
+	var watch bool
+
 	cmd := &cobra.Command{
-		Use:   "render <model>",
-		Short: "Render SQL for a model with templates expanded",
+		Use:     "render <model>",
+		Aliases: []string{"compile"},
+		Short:   "Render SQL for a model with templates expanded",
 		Long: `Render the final SQL for a model with all templates and macros expanded.
 
 This is useful for debugging template issues and seeing the exact SQL
-that will be executed.
+that will be executed, including the model's row filter (frontmatter
+where) in the current environment.
+
+Models written in the project's dialect (dialect in leapsql.yaml) are
+transpiled to the target's dialect. Use --dialect to render them for
+another dialect, e.g. to check that models developed on DuckDB compile
+for Snowflake.
+
+With --watch, the model is rendered again whenever a model or macro file
+changes, until interrupted: keep it open next to the editor to see the
+compiled SQL follow the source. Render errors are reported without
+stopping the watch. Editors using leapsql lsp can show the same compiled
+SQL as a virtual document (see leapsql lsp).
 
 Output adapts to environment:
   - Terminal: Plain SQL (suitable for syntax highlighting)
@@ -28,6 +52,12 @@ Output adapts to environment:
   # Render and save to file
   leapsql render staging.stg_customers > rendered.sql
 
+  # Render for Snowflake
+  leapsql render staging.stg_customers --dialect snowflake
+
+  # Render again on every change to models and macros
+  leapsql compile staging.stg_customers --watch
+
   # Render as JSON
   leapsql render staging.stg_customers --output json
 
@@ -35,14 +65,17 @@ Output adapts to environment:
   leapsql render staging.stg_customers --output markdown`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRender(cmd, args[0])
+			return runRender(cmd, args[0], dialectName, watch)
 		},
 	}
 
+	cmd.Flags().StringVar(&dialectName, "dialect", "", "Render for this SQL dialect instead of the target's (e.g. snowflake)")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Render again whenever a model or macro file changes")
+
 	return cmd
 }
 
-func runRender(cmd *cobra.Command, modelPath string) error {
+func runRender(cmd *cobra.Command, modelPath, dialectName string, watch bool) error {
 	cmdCtx, cleanup, err := NewCommandContext(cmd)
 	if err != nil {
 		return err
@@ -52,15 +85,100 @@ func runRender(cmd *cobra.Command, modelPath string) error {
 	eng := cmdCtx.Engine
 	r := cmdCtx.Renderer
 
-	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
-		return fmt.Errorf("failed to discover models: %w", err)
+	render := func() error {
+		if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+			return fmt.Errorf("failed to discover models: %w", err)
+		}
+
+		var sql string
+		var err error
+		if dialectName != "" {
+			sql, err = eng.RenderModelForDialect(modelPath, dialectName)
+		} else {
+			sql, err = eng.RenderModel(modelPath)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to render model: %w", err)
+		}
+		return printRendered(r, modelPath, sql)
 	}
+	if !watch {
+		return render()
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return watchRender(ctx, r, []string{cmdCtx.Cfg.ModelsDir, cmdCtx.Cfg.MacrosDir}, render)
+}
 
-	sql, err := eng.RenderModel(modelPath)
+// watchRender renders once, then again whenever a .sql or .star file of the
+// directories changes, until ctx is done. Changes are debounced, so saving
+// several files renders once.
+func watchRender(ctx context.Context, r *output.Renderer, dirs []string, render func() error) error {
+	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		return fmt.Errorf("failed to render model: %w", err)
+		return fmt.Errorf("failed to watch files: %w", err)
 	}
+	defer func() { _ = watcher.Close() }()
+
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return watcher.Add(path)
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to watch %s: %w", dir, err)
+		}
+	}
+
+	if err := render(); err != nil {
+		r.Error(err.Error())
+	}
+
+	changed := make(chan string, 1)
+	var debounce *time.Timer
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event := <-watcher.Events:
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			if ext := filepath.Ext(event.Name); ext != ".sql" && ext != ".star" {
+				continue
+			}
+			if debounce != nil {
+				debounce.Stop()
+			}
+			name := event.Name
+			debounce = time.AfterFunc(100*time.Millisecond, func() {
+				select {
+				case changed <- name:
+				default:
+				}
+			})
+
+		case name := <-changed:
+			r.Muted(fmt.Sprintf("-- %s changed", name))
+			if err := render(); err != nil {
+				r.Error(err.Error())
+			}
+
+		case err := <-watcher.Errors:
+			r.Warning(fmt.Sprintf("watch error: %v", err))
+		}
+	}
+}
 
+// printRendered prints a model's rendered SQL in the output mode.
+func printRendered(r *output.Renderer, modelPath, sql string) error {
 	effectiveMode := r.EffectiveMode()
 	switch effectiveMode {
 	case output.ModeJSON:
@@ -250,7 +250,7 @@ func listRulesMarkdown(r *output.Renderer, rules []core.RuleInfo, verbose bool)
 			r.Println("")
 		}
 
-		r.Printf("- **%s** - %s (`%s`)\n", rule.ID, rule.Name, rule.DefaultSeverity.String())
+		r.Printf("- [**%s**](%s) - %s (`%s`)\n", rule.ID, lint.BuildDocURL(rule.ID), rule.Name, rule.DefaultSeverity.String())
 		if verbose {
 			r.Println("  " + rule.Description)
 			if rule.Rationale != "" {
@@ -338,7 +338,16 @@ func showRuleText(r *output.Renderer, rule *core.RuleInfo) error {
 		r.Println("")
 	}
 
-	if len(rule.ConfigKeys) > 0 {
+	if len(rule.Options) > 0 {
+		r.Println(styles.Bold.Render("Options"))
+		for _, o := range rule.Options {
+			r.Printf("  %s %s\n", styles.Bold.Render(o.Name), styles.Muted.Render("("+describeRuleOption(o)+")"))
+			if o.Description != "" {
+				r.Println("    " + o.Description)
+			}
+		}
+		r.Println("")
+	} else if len(rule.ConfigKeys) > 0 {
 		r.Println(styles.Bold.Render("Configuration"))
 		r.Printf("  Options: %s\n", strings.Join(rule.ConfigKeys, ", "))
 		r.Println("")
@@ -357,6 +366,7 @@ func showRuleMarkdown(r *output.Renderer, rule *core.RuleInfo) error {
 	r.Printf("**Type:** %s | **Group:** %s | **Severity:** `%s`\n\n", rule.Type, rule.Group, rule.DefaultSeverity.String())
 	r.Println(rule.Description)
 	r.Println("")
+	r.Printf("Documentation: %s\n\n", lint.BuildDocURL(rule.ID))
 
 	if rule.Rationale != "" {
 		r.Println("## Why This Matters")
@@ -390,7 +400,18 @@ func showRuleMarkdown(r *output.Renderer, rule *core.RuleInfo) error {
 		r.Println("")
 	}
 
-	if len(rule.ConfigKeys) > 0 {
+	if len(rule.Options) > 0 {
+		r.Println("## Options")
+		r.Println("")
+		for _, o := range rule.Options {
+			r.Printf("- `%s` (%s)", o.Name, describeRuleOption(o))
+			if o.Description != "" {
+				r.Printf(": %s", o.Description)
+			}
+			r.Println("")
+		}
+		r.Println("")
+	} else if len(rule.ConfigKeys) > 0 {
 		r.Println("## Configuration")
 		r.Println("")
 		r.Printf("Options: `%s`\n", strings.Join(rule.ConfigKeys, "`, `"))
@@ -409,6 +430,32 @@ func showRuleJSON(r *output.Renderer, rule *core.RuleInfo) error {
 
 // Helper functions
 
+// describeRuleOption describes an option's type, constraints and default,
+// e.g. "int, at least 1, default 30".
+func describeRuleOption(o core.RuleOption) string {
+	parts := []string{string(o.Type)}
+	if o.Type == "" {
+		parts[0] = "any"
+	}
+	if o.Min != 0 {
+		parts = append(parts, fmt.Sprintf("at least %d", o.Min))
+	}
+	if o.Max != 0 {
+		parts = append(parts, fmt.Sprintf("at most %d", o.Max))
+	}
+	if len(o.Enum) > 0 {
+		parts = append(parts, "one of "+strings.Join(o.Enum, ", "))
+	}
+	switch d := o.Default.(type) {
+	case nil:
+	case []string:
+		parts = append(parts, "default ["+strings.Join(d, ", ")+"]")
+	default:
+		parts = append(parts, fmt.Sprintf("default %v", d))
+	}
+	return strings.Join(parts, ", ")
+}
+
 func getSeverityStyle(styles *output.Styles, sev core.Severity) lipgloss.Style {
 	switch sev {
 	case core.SeverityError:
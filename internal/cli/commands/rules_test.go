@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -243,3 +244,47 @@ func TestRulesCommand_SingleRuleMarkdown(t *testing.T) {
 	output := buf.String()
 	assert.True(t, strings.HasPrefix(output, "# AM01"))
 }
+
+func TestRulesCommand_OptionsJSON(t *testing.T) {
+	cmd := NewRulesCommand()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"AL06", "--format", "json"})
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	var result struct {
+		Options []struct {
+			Name    string `json:"name"`
+			Type    string `json:"type"`
+			Default any    `json:"default"`
+			Min     int    `json:"min"`
+		} `json:"options"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	require.Len(t, result.Options, 2)
+	assert.Equal(t, "min_length", result.Options[0].Name)
+	assert.Equal(t, "int", result.Options[0].Type)
+	assert.InDelta(t, 1, result.Options[0].Default, 0)
+	assert.Equal(t, 1, result.Options[0].Min)
+}
+
+func TestDescribeRuleOption(t *testing.T) {
+	tests := []struct {
+		name     string
+		option   core.RuleOption
+		expected string
+	}{
+		{"int with bounds", core.RuleOption{Type: core.OptionInt, Default: 30, Min: 1, Max: 100}, "int, at least 1, at most 100, default 30"},
+		{"string enum", core.RuleOption{Type: core.OptionString, Enum: []string{"upper", "lower"}}, "string, one of upper, lower"},
+		{"list default", core.RuleOption{Type: core.OptionStringList, Default: []string{"DROP", "DELETE"}}, "string_list, default [DROP, DELETE]"},
+		{"untyped", core.RuleOption{}, "any"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, describeRuleOption(tc.option))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -15,9 +16,21 @@ import (
 
 // RunOptions holds options for the run command.
 type RunOptions struct {
-	Select     string
-	Downstream bool
-	JSONOutput bool
+	Select      string
+	Downstream  bool
+	JSONOutput  bool
+	Defer       bool
+	DeferEnv    string
+	DeferState  string
+	Constraints string
+	NoLock      bool
+	LockTimeout time.Duration
+	FullRefresh bool
+	NoCache     bool
+	SkipFresh   bool
+	Sample      int
+	Explain     bool
+	Threads     int
 }
 
 // NewRunCommand creates the run command.
@@ -29,9 +42,74 @@ func NewRunCommand() *cobra.Command {
 		Short: "Run all models or specific models",
 		Long: `Execute SQL models in dependency order.
 
-By default, runs all discovered models. Use --select to run specific models.
+By default, runs all discovered models. Use --select to run specific models,
+by name or with a selector expression over frontmatter tags and groups
+(e.g. "tag:pii AND NOT tag:deprecated").
 Use --downstream to also run models that depend on the selected models.
 
+Selectors can also pick models by run state: result:error selects the models
+that failed in the environment's latest run and state:modified the models
+changed since their last successful build. A trailing + adds downstream
+models, so "result:error+ state:modified+" retries a failed CI run in one
+invocation.
+
+With --defer, upstream models that are not selected and have not been built
+in the current database are read from production instead: the --defer-env
+environment's database, for models its state records as built. This lets
+you build one model without rebuilding its parents.
+
+The not_null and unique tests declared in model frontmatter are checked after
+each table or incremental model is built. With --constraints enforce they are
+added to the table as constraints where the adapter supports it, so later
+writes that violate them fail too; --constraints off skips them.
+
+Models whose compiled SQL, upstream builds, seeds and sources are unchanged
+since their last successful build in the same database are skipped as cache
+hits. Source changes are known for the raw tables listed under table_sources
+in leapsql.yaml, from their loaded_at column, and for files read on DuckDB;
+models reading other raw tables always run. Use --no-cache to rebuild every
+table and view without a full refresh of incremental models.
+
+With --skip-fresh, models whose inputs are also unchanged are skipped as
+fresh, incremental models included: the raw tables listed under
+table_sources in leapsql.yaml have no rows loaded since the model's last
+build, and the files it reads were not modified since. Models reading other
+raw tables are never skipped as fresh. The first run with --skip-fresh
+records when each source last changed.
+
+Use --threads N to build up to N models at once. A model starts once the
+models it depends on are built, and waits for the target's
+max_concurrent_queries and the limit of its resource_class in leapsql.yaml.
+
+A run stops at the first model that fails: no other model starts, and the
+models already building finish. The models it did not build are listed after
+the run with the reason they were skipped: downstream of the failed model, or
+not run because the run stopped.
+
+With --full-refresh, models are rebuilt from scratch: incremental models
+replace their tables with their full query instead of merging new rows, and
+the build cache is ignored, e.g. after source data changed outside LeapSQL.
+Set full_refresh: false in a model's frontmatter to protect a large table from
+full refreshes, or full_refresh: true to always rebuild it.
+
+Use --sample N to build at most N rows per model, so development runs are
+quick on small data; set sample under an environment in leapsql.yaml to
+sample every run in it. Set sample in a model's frontmatter to override the
+number of rows, or sample: 0 to always build it in full.
+
+Use --explain to capture the query plan of each model built with EXPLAIN, or
+set explain: true under an environment in leapsql.yaml. Models whose plan has
+new full scans, broadcast joins or nested loop joins compared to the previous
+run that captured it are reported after the run.
+
+A run locks the state database, so a second run started meanwhile fails
+instead of corrupting the run history. Use --lock-timeout to wait for the
+other run to finish, or --no-lock when runs are already serialized (e.g. by
+a scheduler). Locks left by crashed runs on the same host are taken over.
+
+With metadata.push_after_run in leapsql.yaml, the models a successful run
+built are pushed to the configured data catalogs (see leapsql metadata).
+
 Output adapts to environment:
   - Terminal: Animated progress with spinner
   - Piped/Scripted: Static progress messages`,
@@ -41,9 +119,42 @@ Output adapts to environment:
   # Run specific models
   leapsql run --select staging.stg_customers,staging.stg_orders
 
+  # Run models tagged pii, except deprecated ones
+  leapsql run --select "tag:pii AND NOT tag:deprecated"
+
   # Run a model and its downstream dependents
   leapsql run --select staging.stg_customers --downstream
 
+  # Retry failed and changed models, and everything downstream of them
+  leapsql run --select "result:error+ state:modified+"
+
+  # Build one mart, reading its unbuilt parents from production
+  leapsql run --select marts.customer_summary --defer --defer-state prod-state.db
+
+  # Add declared tests to the built tables as constraints
+  leapsql run --constraints enforce
+
+  # Rebuild incremental models from scratch, ignoring the build cache
+  leapsql run --full-refresh
+
+  # Rebuild every table and view, merging new rows into incremental models
+  leapsql run --no-cache
+
+  # Skip models whose sources have not changed since their last build
+  leapsql run --skip-fresh
+
+  # Build up to 8 independent models at once
+  leapsql run --threads 8
+
+  # Build at most 1000 rows per model
+  leapsql run --sample 1000
+
+  # Capture query plans and report plan regressions
+  leapsql run --explain
+
+  # Wait up to 10 minutes for a concurrent run to finish
+  leapsql run --lock-timeout 10m
+
   # Run with JSON output for CI/CD integration
   leapsql run --json`,
 		Aliases: []string{"build"},
@@ -52,9 +163,21 @@ Output adapts to environment:
 		},
 	}
 
-	cmd.Flags().StringVarP(&opts.Select, "select", "s", "", "Comma-separated list of models to run")
+	cmd.Flags().StringVarP(&opts.Select, "select", "s", "", "Models to run: comma-separated names or a selector expression")
 	cmd.Flags().BoolVar(&opts.Downstream, "downstream", false, "Include downstream dependents when using --select")
 	cmd.Flags().BoolVar(&opts.JSONOutput, "json", false, "Output as JSON lines for progress tracking")
+	cmd.Flags().BoolVar(&opts.Defer, "defer", false, "Read unbuilt upstream models from production (requires --select)")
+	cmd.Flags().StringVar(&opts.DeferEnv, "defer-env", "prod", "Environment to defer to")
+	cmd.Flags().StringVar(&opts.DeferState, "defer-state", "", "State database recording production runs for --defer (default: project state)")
+	cmd.Flags().StringVar(&opts.Constraints, "constraints", engine.ConstraintModeAssert, "How to check not_null and unique tests: assert, enforce, off")
+	cmd.Flags().BoolVar(&opts.FullRefresh, "full-refresh", false, "Rebuild models from scratch, replacing incremental tables and ignoring the build cache")
+	cmd.Flags().BoolVar(&opts.NoCache, "no-cache", false, "Ignore the build cache, rebuilding every table and view but not incremental models from scratch")
+	cmd.Flags().BoolVar(&opts.SkipFresh, "skip-fresh", false, "Skip models whose inputs and sources are unchanged since their last build")
+	cmd.Flags().IntVar(&opts.Sample, "sample", 0, "Build at most N rows per model (default: the environment's sample setting; 0 builds in full)")
+	cmd.Flags().BoolVar(&opts.Explain, "explain", false, "Capture the query plan of each model and report plan regressions (default: the environment's explain setting)")
+	cmd.Flags().IntVar(&opts.Threads, "threads", 1, "Number of models to build at once")
+	cmd.Flags().BoolVar(&opts.NoLock, "no-lock", false, "Do not lock the state database during the run")
+	cmd.Flags().DurationVar(&opts.LockTimeout, "lock-timeout", 0, "How long to wait for a concurrent run to release the state lock")
 
 	return cmd
 }
@@ -94,14 +217,75 @@ func runRun(cmd *cobra.Command, opts *RunOptions) error {
 		return fmt.Errorf("failed to discover models: %w", err)
 	}
 
+	var selected []string
+	if opts.Select != "" {
+		if selected, err = resolveSelection(eng, opts.Select); err != nil {
+			return err
+		}
+	}
+
+	if err := eng.SetConstraintMode(opts.Constraints); err != nil {
+		return err
+	}
+	eng.SetLock(engine.LockConfig{Disabled: opts.NoLock, Timeout: opts.LockTimeout})
+	eng.SetFullRefresh(opts.FullRefresh)
+	eng.SetNoCache(opts.NoCache)
+	eng.SetSkipFresh(opts.SkipFresh)
+	if opts.Threads < 1 {
+		return fmt.Errorf("invalid threads %d: must be at least 1", opts.Threads)
+	}
+	eng.SetThreads(opts.Threads)
+
+	sample := cfg.Sample
+	if cmd.Flags().Changed("sample") {
+		sample = opts.Sample
+	}
+	if sample < 0 {
+		return fmt.Errorf("invalid sample %d: must be a number of rows, or 0 to build in full", sample)
+	}
+	eng.SetSample(sample)
+	if sample > 0 && !opts.JSONOutput {
+		r.Muted(fmt.Sprintf("Sampling: building at most %d rows per model", sample))
+	}
+
+	explain := cfg.Explain
+	if cmd.Flags().Changed("explain") {
+		explain = opts.Explain
+	}
+	eng.SetExplain(explain)
+
+	if opts.Defer {
+		if selected == nil {
+			return fmt.Errorf("--defer requires --select")
+		}
+		database, ok := cfg.EnvironmentDatabase(opts.DeferEnv)
+		if !ok {
+			return fmt.Errorf("cannot defer: environment %q has no target database in leapsql.yaml", opts.DeferEnv)
+		}
+		eng.SetDefer(&engine.DeferConfig{
+			Environment: opts.DeferEnv,
+			StatePath:   opts.DeferState,
+			Database:    database,
+		})
+	}
+
 	if opts.JSONOutput {
-		return runWithJSON(eng, r, cfg.Environment, opts.Select, opts.Downstream)
+		err = runWithJSON(eng, r, cfg.Environment, selected, opts.Downstream)
+	} else {
+		err = runWithRenderer(eng, r, cfg.Environment, selected, opts.Downstream, startTime)
+	}
+	if err == nil {
+		pushMetadataAfterRun(eng, r, cfg)
+	}
+	if errors.Is(err, core.ErrStateLocked) {
+		return fmt.Errorf("%w; wait for it with --lock-timeout or skip locking with --no-lock", err)
 	}
-	return runWithRenderer(eng, r, cfg.Environment, opts.Select, opts.Downstream, startTime)
+	return err
 }
 
 // runWithRenderer executes models with adaptive output.
-func runWithRenderer(eng *engine.Engine, r *output.Renderer, envName string, selectModels string, downstream bool, startTime time.Time) error {
+// A nil selection runs all models.
+func runWithRenderer(eng *engine.Engine, r *output.Renderer, envName string, modelsToRun []string, downstream bool, startTime time.Time) error {
 	ctx := context.Background()
 	models := eng.GetModels()
 
@@ -114,21 +298,11 @@ func runWithRenderer(eng *engine.Engine, r *output.Renderer, envName string, sel
 		r.Printf("Found %d models\n", len(models))
 	}
 
-	// Determine models to run
-	var modelsToRun []string
-	if selectModels != "" {
-		selected := strings.Split(selectModels, ",")
-		for i := range selected {
-			selected[i] = strings.TrimSpace(selected[i])
-		}
-		modelsToRun = selected
-	}
-
 	// Create progress tracker for TTY mode
 	var progress *output.Progress
 	if r.IsTTY() && effectiveMode == output.ModeText {
 		total := len(models)
-		if selectModels != "" {
+		if modelsToRun != nil {
 			total = len(modelsToRun)
 		}
 		progress = r.NewProgress(total, "Running models")
@@ -138,7 +312,7 @@ func runWithRenderer(eng *engine.Engine, r *output.Renderer, envName string, sel
 	// Run models
 	var result *core.Run
 	var runErr error
-	if selectModels != "" {
+	if modelsToRun != nil {
 		downstreamStr := ""
 		if downstream {
 			downstreamStr = " (+ downstream)"
@@ -149,6 +323,14 @@ func runWithRenderer(eng *engine.Engine, r *output.Renderer, envName string, sel
 			r.Printf("Running %d selected models%s...\n", len(modelsToRun), downstreamStr)
 		}
 		result, runErr = eng.RunSelected(ctx, envName, modelsToRun, downstream)
+
+		if deferred := eng.DeferredModels(); len(deferred) > 0 {
+			if effectiveMode == output.ModeMarkdown {
+				r.Println(output.FormatKeyValue("Deferred", strings.Join(deferred, ", ")))
+			} else {
+				r.Muted(fmt.Sprintf("Deferred to production: %s", strings.Join(deferred, ", ")))
+			}
+		}
 	} else {
 		if effectiveMode == output.ModeMarkdown {
 			r.Println(output.FormatKeyValue("Mode", "All models"))
@@ -182,6 +364,28 @@ func runWithRenderer(eng *engine.Engine, r *output.Renderer, envName string, sel
 				r.Error(result.Error)
 			}
 		}
+
+		reportSkips(eng, r, result.ID)
+		reportPlanRegressions(eng, r, result.ID)
+		reportAnomalies(eng, r, result.ID)
+
+		// Route failures to the groups that own the failed models
+		if result.Status == core.RunStatusFailed {
+			groups := owningGroups(eng, failedModelPaths(eng, result.ID))
+			if effectiveMode == output.ModeMarkdown && len(groups) > 0 {
+				r.Println("")
+				r.Println(output.FormatHeader(2, "Notify"))
+				items := make([]string, 0, len(groups))
+				for _, g := range groups {
+					items = append(items, fmt.Sprintf("%s: %s", formatGroupContact(g.Group), strings.Join(g.Models, ", ")))
+				}
+				r.Print(output.FormatList(items))
+			} else {
+				for _, g := range groups {
+					r.Warning(fmt.Sprintf("Notify %s: %s", formatGroupContact(g.Group), strings.Join(g.Models, ", ")))
+				}
+			}
+		}
 	}
 
 	elapsed := time.Since(startTime)
@@ -194,19 +398,133 @@ func runWithRenderer(eng *engine.Engine, r *output.Renderer, envName string, sel
 	return runErr
 }
 
+// reportSkips lists the models a run skipped because of a failure or
+// cancellation, with the reason each was skipped. Cache hits and fresh models
+// are not listed.
+func reportSkips(eng *engine.Engine, r *output.Renderer, runID string) {
+	store := eng.GetStateStore()
+	if store == nil {
+		return
+	}
+	modelRuns, err := store.GetModelRunsWithModelInfo(runID)
+	if err != nil {
+		return
+	}
+
+	var items []string
+	for _, mr := range modelRuns {
+		if mr.Status != core.ModelRunStatusSkipped || mr.SkipReason == core.SkipReasonCacheHit || mr.SkipReason == core.SkipReasonFresh {
+			continue
+		}
+		items = append(items, fmt.Sprintf("%s: %s", mr.ModelPath, skipDescription(mr.SkipReason, mr.SkippedBy)))
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	if r.EffectiveMode() == output.ModeMarkdown {
+		r.Println("")
+		r.Println(output.FormatHeader(2, "Skipped"))
+		r.Print(output.FormatList(items))
+		return
+	}
+	for _, item := range items {
+		r.Muted("Skipped " + item)
+	}
+}
+
+// skipDescription describes why a model run was skipped.
+func skipDescription(reason core.SkipReason, skippedBy string) string {
+	switch reason {
+	case core.SkipReasonUpstreamFailed:
+		return "upstream model " + skippedBy + " failed"
+	case core.SkipReasonRunStopped:
+		return "run stopped after " + skippedBy + " failed"
+	case core.SkipReasonGroupTimeout:
+		return "group max_runtime exceeded after " + skippedBy
+	case core.SkipReasonRenderFailed:
+		return "other models failed to render"
+	case core.SkipReasonCancelled:
+		return "run cancelled"
+	case core.SkipReasonInterrupted:
+		return "run interrupted"
+	case core.SkipReasonCacheHit:
+		return "unchanged since its last build"
+	case core.SkipReasonFresh:
+		return "sources unchanged since its last build"
+	default:
+		return "skipped"
+	}
+}
+
+// reportPlanRegressions warns about the models whose query plans captured in
+// a run regressed since the previous run.
+func reportPlanRegressions(eng *engine.Engine, r *output.Renderer, runID string) {
+	regressions, err := eng.PlanRegressions(runID)
+	if err != nil || len(regressions) == 0 {
+		return
+	}
+
+	items := make([]string, 0, len(regressions))
+	for _, reg := range regressions {
+		changes := make([]string, len(reg.Changes))
+		for i, c := range reg.Changes {
+			changes[i] = c.String()
+		}
+		items = append(items, fmt.Sprintf("%s: %s (since run %s)", reg.Model, strings.Join(changes, ", "), reg.PreviousRun))
+	}
+
+	if r.EffectiveMode() == output.ModeMarkdown {
+		r.Println("")
+		r.Println(output.FormatHeader(2, "Plan Regressions"))
+		r.Print(output.FormatList(items))
+		return
+	}
+	for _, item := range items {
+		r.Warning("Plan regression in " + item)
+	}
+}
+
+// reportAnomalies warns about the model outputs of a run that deviate from
+// previous runs beyond their anomaly tests. Anomalies that failed their model
+// are already reported as errors.
+func reportAnomalies(eng *engine.Engine, r *output.Renderer, runID string) {
+	anomalies, err := eng.Anomalies(runID)
+	if err != nil {
+		return
+	}
+
+	var items []string
+	for _, a := range anomalies {
+		if !a.Fail {
+			items = append(items, a.String())
+		}
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	if r.EffectiveMode() == output.ModeMarkdown {
+		r.Println("")
+		r.Println(output.FormatHeader(2, "Anomalies"))
+		r.Print(output.FormatList(items))
+		return
+	}
+	for _, item := range items {
+		r.Warning("Anomaly: " + item)
+	}
+}
+
 // runWithJSON executes models with JSON lines output.
-func runWithJSON(eng *engine.Engine, r *output.Renderer, envName string, selectModels string, downstream bool) error {
+// A nil selection runs all models.
+func runWithJSON(eng *engine.Engine, r *output.Renderer, envName string, selected []string, downstream bool) error {
 	ctx := context.Background()
 	graph := eng.GetGraph()
 	store := eng.GetStateStore()
 
 	// Determine which models to run
 	var modelPaths []string
-	if selectModels != "" {
-		selected := strings.Split(selectModels, ",")
-		for i := range selected {
-			selected[i] = strings.TrimSpace(selected[i])
-		}
+	if selected != nil {
 		if downstream {
 			modelPaths = graph.GetAffectedNodes(selected)
 		} else {
@@ -236,11 +554,7 @@ func runWithJSON(eng *engine.Engine, r *output.Renderer, envName string, selectM
 	// Execute the run
 	var result *core.Run
 	var runErr error
-	if selectModels != "" {
-		selected := strings.Split(selectModels, ",")
-		for i := range selected {
-			selected[i] = strings.TrimSpace(selected[i])
-		}
+	if selected != nil {
 		result, runErr = eng.RunSelected(ctx, envName, selected, downstream)
 	} else {
 		result, runErr = eng.Run(ctx, envName)
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSchemaCommand(t *testing.T) {
+	cmd := NewSchemaCommand()
+
+	assert.Equal(t, "schema", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+	assert.NotEmpty(t, cmd.Example, "Example should not be empty")
+	for _, flag := range []string{"select", "format", "out", "registry"} {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), "flag %q should exist", flag)
+	}
+	assert.Equal(t, engine.SchemaFormatJSON, cmd.Flags().Lookup("format").DefValue)
+}
+
+func TestSchemaFileName(t *testing.T) {
+	tests := []struct {
+		path   string
+		format string
+		want   string
+	}{
+		{"staging.orders", engine.SchemaFormatJSON, "staging.orders.schema.json"},
+		{"staging.orders", engine.SchemaFormatAvro, "staging.orders.avsc"},
+		{"core/dim_users", engine.SchemaFormatAvro, filepath.Join("core", "dim_users.avsc")},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, schemaFileName(tt.path, tt.format))
+	}
+}
+
+func TestPublishSchema(t *testing.T) {
+	var gotPath, gotType string
+	var gotBody map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/subjects/rejected-value/versions" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error_code":409,"message":"incompatible schema"}`))
+			return
+		}
+		gotPath = r.URL.Path
+		gotType = r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_, _ = w.Write([]byte(`{"id":42}`))
+	}))
+	defer srv.Close()
+
+	id, err := publishSchema(context.Background(), srv.URL+"/", "marts.orders-value", engine.SchemaFormatAvro, []byte(`{"type":"record"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 42, id)
+	assert.Equal(t, "/subjects/marts.orders-value/versions", gotPath)
+	assert.Equal(t, "application/vnd.schemaregistry.v1+json", gotType)
+	assert.Equal(t, map[string]string{"schemaType": "AVRO", "schema": `{"type":"record"}`}, gotBody)
+
+	_, err = publishSchema(context.Background(), srv.URL, "rejected-value", engine.SchemaFormatJSON, []byte(`{}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "incompatible schema")
+}
@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/internal/engine"
+)
+
+// resolveSelection evaluates a --select expression against the discovered
+// models, e.g. "staging.stg_users,staging.stg_orders" or
+// "tag:pii AND NOT tag:deprecated". It is an error for a selector to match
+// no models, since that is almost always a mistake.
+func resolveSelection(eng *engine.Engine, expr string) ([]string, error) {
+	selected, err := eng.SelectModels(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --select: %w", err)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no models match --select %q", expr)
+	}
+	return selected, nil
+}
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/config"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/internal/server"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/spf13/cobra"
+)
+
+// ServeOptions holds options for the serve command.
+type ServeOptions struct {
+	Addr     string
+	Token    string
+	NoSocket bool
+}
+
+// NewServeCommand creates the serve command.
+func NewServeCommand() *cobra.Command {
+	opts := &ServeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run LeapSQL as a long-running HTTP daemon",
+		Long: `Start a daemon exposing compile, lint, run, lineage and catalog endpoints
+over HTTP, so orchestrators and web UIs can drive LeapSQL without starting
+the CLI for every call.
+
+The daemon keeps the project loaded and rediscovers models on each request,
+so edits to model files are picked up. Requests are served one at a time.
+
+The daemon also listens on a Unix socket next to the state database
+(.leapsql/daemon.sock). While it runs, lint and the language server of the
+same project connect to it and reuse its discovered models and caches
+instead of loading the project themselves. Socket requests need no token:
+only the socket's owner can connect.
+
+Endpoints (JSON):
+  GET  /healthz                    Liveness check
+  GET  /v1/catalog                 Models with dependencies and columns
+  GET  /v1/models/{model}/compile  Rendered SQL of a model
+  GET  /v1/models/{model}/lineage  Upstream, downstream and column lineage
+  GET  /v1/models/{model}/preview  First rows of a model's query, without
+                                   building it (?limit=100)
+  GET  /v1/lint                    SQL lint diagnostics of all models, or
+                                   those named by ?model= (repeatable), with
+                                   ?disable= and ?rule= like lint's flags
+  POST /v1/runs                    Run models, streaming newline-delimited
+                                   JSON events; body: {"select": "...",
+                                   "downstream": true, "full_refresh": true}
+  GET  /v1/runs                    Latest runs with durations (?limit=20)
+  GET  /v1/runs/{id}               A run and its model runs
+  GET  /v1/runs/{id}/models/{model}/logs
+                                   Log records of a model during a run
+  GET  /v1/dag                     Models in execution levels with their
+                                   statuses in a run (?run=, default latest)
+  GET  /v1/events                  Events of all runs as newline-delimited
+                                   JSON, until the client disconnects
+
+The web UI at / follows runs live, shows the DAG with model statuses, each
+model's logs and the durations of past runs. Logs are kept in memory for the
+latest 20 runs executed by the daemon.
+
+When a token is set, /v1 endpoints require an "Authorization: Bearer <token>"
+header; the web UI asks for it.`,
+		Example: `  # Serve on the default address (127.0.0.1:8766)
+  leapsql serve
+
+  # Accept remote connections, requiring a token
+  LEAPSQL_SERVE_TOKEN=secret leapsql serve --addr 0.0.0.0:8766
+
+  # Run models tagged daily and follow the run
+  curl -N -X POST localhost:8766/v1/runs -d '{"select": "tag:daily"}'`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runServe(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.Addr, "addr", server.DefaultAddr, "Address to listen on")
+	cmd.Flags().StringVar(&opts.Token, "token", "", "Bearer token required by /v1 endpoints (default: $LEAPSQL_SERVE_TOKEN)")
+	cmd.Flags().BoolVar(&opts.NoSocket, "no-socket", false, "Do not listen on the project socket for the CLI and language server")
+
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, opts *ServeOptions) error {
+	cfg := getConfig()
+	logger := config.GetLogger(cmd.Context())
+
+	if err := lint.NewConfig().ApplyProject(cfg.Lint); err != nil {
+		return fmt.Errorf("invalid lint config: %w", err)
+	}
+
+	token := opts.Token
+	if token == "" {
+		token = os.Getenv("LEAPSQL_SERVE_TOKEN")
+	}
+
+	// Capture the engine's logs per model for the web UI
+	logs := server.NewLogCapture(logger.Handler())
+	eng, err := createEngine(cfg, slog.New(logs))
+	if err != nil {
+		return fmt.Errorf("failed to create engine: %w", err)
+	}
+	defer func() { _ = eng.Close() }()
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("discover failed: %w", err)
+	}
+
+	socket := ""
+	if !opts.NoSocket {
+		socket = server.SocketPath(resolveStatePath(cfg))
+	}
+
+	srv := server.New(server.Config{
+		Engine:      eng,
+		Addr:        opts.Addr,
+		Environment: cfg.Environment,
+		Lint:        cfg.Lint,
+		Token:       token,
+		Logs:        logs,
+		Logger:      logger,
+		Socket:      socket,
+	})
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Serving %s on http://%s\n", cfg.Environment, opts.Addr)
+	fmt.Fprintf(cmd.OutOrStdout(), "Open http://%s in a browser to monitor runs\n", opts.Addr)
+	fmt.Fprintln(cmd.OutOrStdout(), "Press Ctrl+C to stop")
+
+	// Shut down gracefully when stopped, recording in-flight runs as cancelled
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return srv.Serve(ctx)
+}
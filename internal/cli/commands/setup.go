@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/leapstack-labs/leapsql/internal/engine"
 	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
 	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/dialect"
 	"github.com/spf13/cobra"
 )
 
@@ -94,6 +96,26 @@ func getConfig() *config.Config {
 	}
 }
 
+// sqlDialect returns the dialect with the given name or, if name is empty,
+// the dialect models are written in: the project's dialect, else the
+// target's, else DuckDB.
+func sqlDialect(cfg *config.Config, name string) (*core.Dialect, error) {
+	if name == "" && cfg != nil {
+		name = cfg.Dialect
+		if name == "" && cfg.Target != nil {
+			name = cfg.Target.Type
+		}
+	}
+	if name == "" {
+		name = "duckdb"
+	}
+	d, ok := dialect.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown dialect %q: supported dialects are %v", name, dialect.List())
+	}
+	return d, nil
+}
+
 func getEnvOrDefault(key, defaultVal string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -139,8 +161,33 @@ func createEngine(cfg *config.Config, logger *slog.Logger) (*engine.Engine, erro
 		Environment:   cfg.Environment,
 		Target:        targetInfo,
 		AdapterConfig: adapterConfig,
+		Groups:        cfg.Groups,
+		Layers:        cfg.Layers,
+		FileSources:   cfg.FileSources,
+		TableSources:  cfg.TableSources,
+		QueryComment:  cfg.QueryComment,
+		Dialect:       cfg.Dialect,
 		Logger:        logger,
 	}
+	if cfg.Target != nil {
+		engineCfg.Transaction = cfg.Target.Transaction
+		engineCfg.Masking = cfg.Target.Masking
+		engineCfg.MaxConcurrentQueries = cfg.Target.MaxConcurrentQueries
+		engineCfg.ResourceClasses = cfg.Target.ResourceClasses
+	}
+	if cfg.ProjectRoot != "" {
+		engineCfg.ProjectName = filepath.Base(cfg.ProjectRoot)
+	}
+
+	if cfg.Workspace != nil {
+		for _, p := range cfg.Workspace.Projects {
+			modelsDir, _, _, err := intconfig.ProjectDirs(p)
+			if err != nil {
+				return nil, err
+			}
+			engineCfg.Projects = append(engineCfg.Projects, engine.Project{Name: p.Name, ModelsDir: modelsDir})
+		}
+	}
 
 	return engine.New(engineCfg)
 }
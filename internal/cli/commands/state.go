@@ -0,0 +1,329 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// StateCostsOptions holds options for the state costs command.
+type StateCostsOptions struct {
+	By       string
+	Days     int
+	Interval string
+	AllEnvs  bool
+}
+
+// NewStateCommand creates the state command.
+func NewStateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Report on the run history in the state database",
+		Long: `Inspect the run history recorded in the state database.
+
+Use the subcommands to aggregate what past runs recorded, such as the
+warehouse cost of each model, or to clean up after interrupted runs.`,
+	}
+
+	cmd.AddCommand(newStateCostsCommand())
+	cmd.AddCommand(newStateCleanupCommand())
+
+	return cmd
+}
+
+func newStateCostsCommand() *cobra.Command {
+	opts := &StateCostsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "costs",
+		Short: "Report warehouse costs of model runs",
+		Long: `Aggregate the warehouse cost recorded for model runs by model, tag or owner.
+
+Costs are recorded during run by adapters that report them: bytes scanned
+and compute time (slot-ms) of the statements that build each model. Models
+built with other adapters report execution time only.
+
+A model with several tags counts toward each of its tags. Use --interval
+to split the report into daily, weekly or monthly periods.
+
+Output adapts to environment:
+  - Terminal: Styled, colored output
+  - Piped/Scripted: Markdown format (agent-friendly)
+
+Use --output to override: auto, text, markdown, json`,
+		Example: `  # Costs per model over the last 30 days
+  leapsql state costs
+
+  # Costs per owner, week by week
+  leapsql state costs --by owner --interval week
+
+  # Costs per tag over the last 7 days, as JSON
+  leapsql state costs --by tag --days 7 --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runStateCosts(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.By, "by", engine.CostByModel, "Group costs by: model, tag, owner")
+	cmd.Flags().IntVar(&opts.Days, "days", 30, "Include runs from the last N days (0 for all runs)")
+	cmd.Flags().StringVar(&opts.Interval, "interval", "", "Split costs into periods: day, week, month")
+	cmd.Flags().BoolVar(&opts.AllEnvs, "all-envs", false, "Include runs in every environment")
+
+	_ = cmd.RegisterFlagCompletionFunc("by", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{engine.CostByModel, engine.CostByTag, engine.CostByOwner}, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("interval", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{engine.CostIntervalDay, engine.CostIntervalWeek, engine.CostIntervalMonth}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+func newStateCleanupCommand() *cobra.Command {
+	var lockTimeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "Clean up after runs interrupted by a crash",
+		Long: `Clean up the state and target databases after runs interrupted by a crash.
+
+Cleanup takes the run lock, so it waits for or fails on a run in progress.
+While holding it:
+  - Runs still recorded as running are orphaned: their process exited
+    without completing them. They are marked as failed, along with their
+    unfinished model runs. The next run does this too.
+  - Model runs whose run or model no longer exists are deleted.
+  - Temp tables left by interrupted incremental builds and staging tables
+    left by interrupted table builds are dropped.
+
+Output adapts to environment:
+  - Terminal: Styled, colored output
+  - Piped/Scripted: Markdown format (agent-friendly)
+
+Use --output to override: auto, text, markdown, json`,
+		Example: `  # Clean up after a crashed run
+  leapsql state cleanup
+
+  # Wait for a run in progress to finish first
+  leapsql state cleanup --lock-timeout 10m`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runStateCleanup(cmd, lockTimeout)
+		},
+	}
+
+	cmd.Flags().DurationVar(&lockTimeout, "lock-timeout", 0, "How long to wait for a run in progress to release the state lock")
+
+	return cmd
+}
+
+type stateCleanupOutput struct {
+	InterruptedRuns []string `json:"interrupted_runs"`
+	PrunedModelRuns int64    `json:"pruned_model_runs"`
+	DroppedTables   []string `json:"dropped_tables"`
+}
+
+func runStateCleanup(cmd *cobra.Command, lockTimeout time.Duration) error {
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	// Discovery finds the models whose temp and staging tables may be left behind
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	eng.SetLock(engine.LockConfig{Timeout: lockTimeout})
+	result, err := eng.Cleanup(context.Background())
+	if err != nil {
+		return err
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		return r.JSON(stateCleanupOutput{
+			InterruptedRuns: nonNil(result.InterruptedRuns),
+			PrunedModelRuns: result.PrunedModelRuns,
+			DroppedTables:   nonNil(result.DroppedTables),
+		})
+	case output.ModeMarkdown:
+		r.Println(output.FormatHeader(1, "State Cleanup"))
+		r.Println("")
+		r.Println(output.FormatKeyValue("Interrupted runs", fmt.Sprintf("%d", len(result.InterruptedRuns))))
+		r.Print(output.FormatList(result.InterruptedRuns))
+		r.Println(output.FormatKeyValue("Pruned model runs", fmt.Sprintf("%d", result.PrunedModelRuns)))
+		r.Println(output.FormatKeyValue("Dropped tables", fmt.Sprintf("%d", len(result.DroppedTables))))
+		r.Print(output.FormatList(result.DroppedTables))
+	default:
+		r.Header(1, "State Cleanup")
+		r.Println("")
+		for _, id := range result.InterruptedRuns {
+			r.Warning("Marked interrupted run " + id + " as failed")
+		}
+		if result.PrunedModelRuns > 0 {
+			r.Println(fmt.Sprintf("Pruned %d orphaned model run(s)", result.PrunedModelRuns))
+		}
+		for _, table := range result.DroppedTables {
+			r.Println("Dropped " + table)
+		}
+		if len(result.InterruptedRuns) == 0 && result.PrunedModelRuns == 0 && len(result.DroppedTables) == 0 {
+			r.Success("Nothing to clean up")
+		}
+	}
+	return nil
+}
+
+// nonNil returns s, or an empty slice if s is nil, so it encodes as a JSON array.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+type costSummaryOutput struct {
+	Period       string `json:"period,omitempty"`
+	Key          string `json:"key"`
+	ModelRuns    int    `json:"model_runs"`
+	BytesScanned int64  `json:"bytes_scanned"`
+	SlotMS       int64  `json:"slot_ms"`
+	ExecutionMS  int64  `json:"execution_ms"`
+}
+
+type stateCostsOutput struct {
+	By          string              `json:"by"`
+	Environment string              `json:"environment,omitempty"`
+	Since       *time.Time          `json:"since,omitempty"`
+	Interval    string              `json:"interval,omitempty"`
+	Costs       []costSummaryOutput `json:"costs"`
+}
+
+func runStateCosts(cmd *cobra.Command, opts *StateCostsOptions) error {
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	reportOpts := engine.CostReportOptions{By: opts.By, Interval: opts.Interval}
+	if !opts.AllEnvs {
+		reportOpts.Environment = cmdCtx.Cfg.Environment
+	}
+	if opts.Days > 0 {
+		reportOpts.Since = time.Now().AddDate(0, 0, -opts.Days)
+	}
+
+	report, err := eng.CostReport(reportOpts)
+	if err != nil {
+		return err
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		return stateCostsJSON(r, reportOpts, report)
+	case output.ModeMarkdown:
+		stateCostsMarkdown(r, reportOpts, report)
+	default:
+		stateCostsText(r, reportOpts, report)
+	}
+	return nil
+}
+
+// stateCostsText outputs a cost report in styled text format.
+func stateCostsText(r *output.Renderer, opts engine.CostReportOptions, report []engine.CostSummary) {
+	r.Header(1, "Costs by "+opts.By)
+	r.Println("")
+
+	if len(report) == 0 {
+		r.Muted("No model runs recorded")
+		return
+	}
+
+	period := ""
+	for i, s := range report {
+		if s.Period != period || i == 0 {
+			if s.Period != "" {
+				if i > 0 {
+					r.Println("")
+				}
+				r.Header(2, s.Period)
+			}
+			period = s.Period
+		}
+		r.Println(fmt.Sprintf("%-40s %10s scanned  %10s slot  %10s exec  (%d runs)",
+			s.Key, formatBytes(s.BytesScanned), formatMS(s.SlotMS), formatMS(s.ExecutionMS), s.ModelRuns))
+	}
+}
+
+// stateCostsMarkdown outputs a cost report in markdown format.
+func stateCostsMarkdown(r *output.Renderer, opts engine.CostReportOptions, report []engine.CostSummary) {
+	r.Println(output.FormatHeader(1, "Costs by "+opts.By))
+	r.Println("")
+
+	if len(report) == 0 {
+		r.Println("No model runs recorded.")
+		return
+	}
+
+	r.Printf("| Period | %s | Runs | Bytes Scanned | Slot | Execution |\n", opts.By)
+	r.Println("|--------|------|------|---------------|------|-----------|")
+	for _, s := range report {
+		period := s.Period
+		if period == "" {
+			period = "-"
+		}
+		r.Printf("| %s | %s | %d | %s | %s | %s |\n", period, s.Key, s.ModelRuns,
+			formatBytes(s.BytesScanned), formatMS(s.SlotMS), formatMS(s.ExecutionMS))
+	}
+}
+
+// stateCostsJSON outputs a cost report in JSON format.
+func stateCostsJSON(r *output.Renderer, opts engine.CostReportOptions, report []engine.CostSummary) error {
+	costs := make([]costSummaryOutput, 0, len(report))
+	for _, s := range report {
+		costs = append(costs, costSummaryOutput(s))
+	}
+
+	out := stateCostsOutput{
+		By:          opts.By,
+		Environment: opts.Environment,
+		Interval:    opts.Interval,
+		Costs:       costs,
+	}
+	if !opts.Since.IsZero() {
+		out.Since = &opts.Since
+	}
+	return r.JSON(out)
+}
+
+// formatBytes formats a byte count with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// formatMS formats a duration in milliseconds.
+func formatMS(ms int64) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
@@ -0,0 +1,370 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/spf13/cobra"
+)
+
+// StatsOptions holds options for the stats command.
+type StatsOptions struct {
+	Path   string // Only measure models under this path
+	Select string // Selector expression (e.g., "tag:finance")
+	Sort   string // Metric to sort models by, highest first
+	Limit  int    // Show only the first N models (0 for all)
+}
+
+// statsSortKeys are the metrics stats can sort by.
+var statsSortKeys = []string{"expressions", "joins", "ctes", "subqueries", "depth", "tables", "columns", "model"}
+
+// statsCounts is the JSON representation of a model's metric counts.
+type statsCounts struct {
+	Joins        int `json:"joins"`
+	CTEs         int `json:"ctes"`
+	Subqueries   int `json:"subqueries"`
+	NestingDepth int `json:"nesting_depth"`
+	SetOps       int `json:"set_ops"`
+	Expressions  int `json:"expressions"`
+	Tables       int `json:"tables"`
+	Columns      int `json:"columns"`
+}
+
+// statsMeans is the JSON representation of the mean metric counts.
+type statsMeans struct {
+	Joins        float64 `json:"joins"`
+	CTEs         float64 `json:"ctes"`
+	Subqueries   float64 `json:"subqueries"`
+	NestingDepth float64 `json:"nesting_depth"`
+	SetOps       float64 `json:"set_ops"`
+	Expressions  float64 `json:"expressions"`
+	Tables       float64 `json:"tables"`
+	Columns      float64 `json:"columns"`
+}
+
+// statsModelOutput is the JSON representation of a model's metrics.
+type statsModelOutput struct {
+	Model    string `json:"model"`
+	FilePath string `json:"file_path"`
+	statsCounts
+	TableNames  []string `json:"table_names"`
+	ColumnNames []string `json:"column_names"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// statsSummaryOutput aggregates the metrics of the measured models.
+type statsSummaryOutput struct {
+	Models int         `json:"models"`
+	Failed int         `json:"failed"`
+	Max    statsCounts `json:"max"`
+	Mean   statsMeans  `json:"mean"`
+}
+
+type statsOutput struct {
+	Sort    string             `json:"sort"`
+	Models  []statsModelOutput `json:"models"`
+	Summary statsSummaryOutput `json:"summary"`
+}
+
+// NewStatsCommand creates the stats command.
+func NewStatsCommand() *cobra.Command {
+	opts := &StatsOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "stats [path]",
+		Short: "Report the complexity of model SQL",
+		Long: `Measure the complexity of the SQL of each model and aggregate it.
+
+Metrics are computed from the rendered SQL of each model, counting its
+CTEs and subqueries:
+  - joins:       JOIN clauses, comma joins included
+  - ctes:        Common table expressions
+  - subqueries:  Queries nested in the statement, other than CTEs
+  - depth:       Deepest nesting of queries (1 for a flat SELECT)
+  - set ops:     UNION, INTERSECT and EXCEPT operations
+  - expressions: Expression nodes, e.g. 3 for a = 1
+  - tables:      Distinct tables read, excluding CTEs
+  - columns:     Distinct column references
+
+Use it to find the models to refactor first and to set complexity
+budgets. Models whose SQL fails to render or parse are listed as failed.
+
+Output adapts to environment:
+  - Terminal: Styled, colored output
+  - Piped/Scripted: Markdown format (agent-friendly)
+
+Use --output to override: auto, text, markdown, json`,
+		Example: `  # Report the complexity of all models
+  leapsql stats
+
+  # The 10 models with the most joins
+  leapsql stats --sort joins --limit 10
+
+  # Models under a directory, as JSON
+  leapsql stats models/marts --output json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				opts.Path = args[0]
+			}
+			return runStats(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Select, "select", "s", "", "Only measure models matching a selector expression")
+	cmd.Flags().StringVar(&opts.Sort, "sort", "expressions", "Sort models by: expressions, joins, ctes, subqueries, depth, tables, columns, model")
+	cmd.Flags().IntVar(&opts.Limit, "limit", 0, "Show only the first N models (0 for all)")
+
+	_ = cmd.RegisterFlagCompletionFunc("sort", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return statsSortKeys, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+func runStats(cmd *cobra.Command, opts *StatsOptions) error {
+	if !slices.Contains(statsSortKeys, opts.Sort) {
+		return fmt.Errorf("invalid sort %q: must be one of: %v", opts.Sort, statsSortKeys)
+	}
+	if opts.Limit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+
+	cmdCtx, cleanup, err := NewCommandContext(cmd)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	eng := cmdCtx.Engine
+	r := cmdCtx.Renderer
+
+	if _, err := eng.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+
+	models := filterModelsByPath(eng.GetModels(), opts.Path)
+	if opts.Select != "" {
+		paths, err := resolveSelection(eng, opts.Select)
+		if err != nil {
+			return err
+		}
+		selected := make(map[string]bool, len(paths))
+		for _, p := range paths {
+			selected[p] = true
+		}
+		models = filterModelsBySelection(models, selected)
+	}
+
+	stats, err := eng.MeasureModels(cmd.Context(), models)
+	if err != nil {
+		return err
+	}
+
+	summary := summarizeStats(stats)
+	sortStats(stats, opts.Sort)
+	if opts.Limit > 0 && len(stats) > opts.Limit {
+		stats = stats[:opts.Limit]
+	}
+
+	switch r.EffectiveMode() {
+	case output.ModeJSON:
+		return statsJSON(r, opts, stats, summary)
+	case output.ModeMarkdown:
+		statsMarkdown(r, stats, summary)
+	default:
+		statsText(r, stats, summary)
+	}
+	return nil
+}
+
+// countsOf returns the metric counts of a model.
+func countsOf(s engine.ModelStats) statsCounts {
+	m := s.Metrics
+	return statsCounts{
+		Joins:        m.Joins,
+		CTEs:         m.CTEs,
+		Subqueries:   m.Subqueries,
+		NestingDepth: m.NestingDepth,
+		SetOps:       m.SetOps,
+		Expressions:  m.Expressions,
+		Tables:       len(m.Tables),
+		Columns:      len(m.Columns),
+	}
+}
+
+// sortStats sorts stats by the given metric, highest first, then by model.
+// Failed models go last.
+func sortStats(stats []engine.ModelStats, by string) {
+	key := func(s engine.ModelStats) int {
+		c := countsOf(s)
+		switch by {
+		case "joins":
+			return c.Joins
+		case "ctes":
+			return c.CTEs
+		case "subqueries":
+			return c.Subqueries
+		case "depth":
+			return c.NestingDepth
+		case "tables":
+			return c.Tables
+		case "columns":
+			return c.Columns
+		case "model":
+			return 0
+		}
+		return c.Expressions
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if (a.Err == nil) != (b.Err == nil) {
+			return a.Err == nil
+		}
+		if ka, kb := key(a), key(b); ka != kb {
+			return ka > kb
+		}
+		return a.Model < b.Model
+	})
+}
+
+// summarizeStats aggregates the metrics of the models that were measured.
+func summarizeStats(stats []engine.ModelStats) statsSummaryOutput {
+	var sum statsCounts
+	summary := statsSummaryOutput{}
+	for _, s := range stats {
+		if s.Err != nil {
+			summary.Failed++
+			continue
+		}
+		summary.Models++
+		c := countsOf(s)
+		sum.Joins += c.Joins
+		sum.CTEs += c.CTEs
+		sum.Subqueries += c.Subqueries
+		sum.NestingDepth += c.NestingDepth
+		sum.SetOps += c.SetOps
+		sum.Expressions += c.Expressions
+		sum.Tables += c.Tables
+		sum.Columns += c.Columns
+		summary.Max = statsCounts{
+			Joins:        max(summary.Max.Joins, c.Joins),
+			CTEs:         max(summary.Max.CTEs, c.CTEs),
+			Subqueries:   max(summary.Max.Subqueries, c.Subqueries),
+			NestingDepth: max(summary.Max.NestingDepth, c.NestingDepth),
+			SetOps:       max(summary.Max.SetOps, c.SetOps),
+			Expressions:  max(summary.Max.Expressions, c.Expressions),
+			Tables:       max(summary.Max.Tables, c.Tables),
+			Columns:      max(summary.Max.Columns, c.Columns),
+		}
+	}
+	if summary.Models > 0 {
+		mean := func(total int) float64 {
+			return math.Round(float64(total)/float64(summary.Models)*10) / 10
+		}
+		summary.Mean = statsMeans{
+			Joins:        mean(sum.Joins),
+			CTEs:         mean(sum.CTEs),
+			Subqueries:   mean(sum.Subqueries),
+			NestingDepth: mean(sum.NestingDepth),
+			SetOps:       mean(sum.SetOps),
+			Expressions:  mean(sum.Expressions),
+			Tables:       mean(sum.Tables),
+			Columns:      mean(sum.Columns),
+		}
+	}
+	return summary
+}
+
+// statsText outputs model metrics in styled text format.
+func statsText(r *output.Renderer, stats []engine.ModelStats, summary statsSummaryOutput) {
+	styles := r.Styles()
+
+	r.Header(1, "Model Complexity")
+	r.Println("")
+
+	if len(stats) == 0 {
+		r.Muted("No models found")
+		return
+	}
+
+	r.Println(styles.Muted.Render(fmt.Sprintf("%-40s %5s %5s %5s %5s %5s %6s %6s %7s",
+		"MODEL", "JOINS", "CTES", "SUBQ", "DEPTH", "SETOP", "EXPRS", "TABLES", "COLUMNS")))
+	for _, s := range stats {
+		if s.Err != nil {
+			r.Printf("%s %s\n", fmt.Sprintf("%-40s", s.Model), styles.Error.Render(s.Err.Error()))
+			continue
+		}
+		c := countsOf(s)
+		r.Printf("%s %5d %5d %5d %5d %5d %6d %6d %7d\n", styles.ModelPath.Render(fmt.Sprintf("%-40s", s.Model)),
+			c.Joins, c.CTEs, c.Subqueries, c.NestingDepth, c.SetOps, c.Expressions, c.Tables, c.Columns)
+	}
+
+	r.Println("")
+	m, x := summary.Mean, summary.Max
+	r.Printf("%s %5.1f %5.1f %5.1f %5.1f %5.1f %6.1f %6.1f %7.1f\n", styles.Bold.Render(fmt.Sprintf("%-40s", "mean")),
+		m.Joins, m.CTEs, m.Subqueries, m.NestingDepth, m.SetOps, m.Expressions, m.Tables, m.Columns)
+	r.Printf("%s %5d %5d %5d %5d %5d %6d %6d %7d\n", styles.Bold.Render(fmt.Sprintf("%-40s", "max")),
+		x.Joins, x.CTEs, x.Subqueries, x.NestingDepth, x.SetOps, x.Expressions, x.Tables, x.Columns)
+
+	r.Println("")
+	line := fmt.Sprintf("%d model(s) measured", summary.Models)
+	if summary.Failed > 0 {
+		line += fmt.Sprintf(", %d failed", summary.Failed)
+	}
+	r.Muted(line)
+}
+
+// statsMarkdown outputs model metrics in markdown format.
+func statsMarkdown(r *output.Renderer, stats []engine.ModelStats, summary statsSummaryOutput) {
+	r.Println(output.FormatHeader(1, "Model Complexity"))
+	r.Println("")
+
+	if len(stats) == 0 {
+		r.Println("No models found.")
+		return
+	}
+
+	r.Println("| Model | Joins | CTEs | Subqueries | Depth | Set Ops | Expressions | Tables | Columns |")
+	r.Println("|-------|-------|------|------------|-------|---------|-------------|--------|---------|")
+	for _, s := range stats {
+		if s.Err != nil {
+			r.Printf("| %s | %s | | | | | | | |\n", s.Model, s.Err.Error())
+			continue
+		}
+		c := countsOf(s)
+		r.Printf("| %s | %d | %d | %d | %d | %d | %d | %d | %d |\n", s.Model,
+			c.Joins, c.CTEs, c.Subqueries, c.NestingDepth, c.SetOps, c.Expressions, c.Tables, c.Columns)
+	}
+	m, x := summary.Mean, summary.Max
+	r.Printf("| **mean** | %.1f | %.1f | %.1f | %.1f | %.1f | %.1f | %.1f | %.1f |\n",
+		m.Joins, m.CTEs, m.Subqueries, m.NestingDepth, m.SetOps, m.Expressions, m.Tables, m.Columns)
+	r.Printf("| **max** | %d | %d | %d | %d | %d | %d | %d | %d |\n",
+		x.Joins, x.CTEs, x.Subqueries, x.NestingDepth, x.SetOps, x.Expressions, x.Tables, x.Columns)
+	r.Println("")
+	r.Println(output.FormatKeyValue("Models measured", fmt.Sprintf("%d", summary.Models)))
+	r.Println(output.FormatKeyValue("Failed", fmt.Sprintf("%d", summary.Failed)))
+}
+
+// statsJSON outputs model metrics in JSON format.
+func statsJSON(r *output.Renderer, opts *StatsOptions, stats []engine.ModelStats, summary statsSummaryOutput) error {
+	models := make([]statsModelOutput, 0, len(stats))
+	for _, s := range stats {
+		m := statsModelOutput{
+			Model:       s.Model,
+			FilePath:    s.FilePath,
+			statsCounts: countsOf(s),
+			TableNames:  nonNil(s.Metrics.Tables),
+			ColumnNames: nonNil(s.Metrics.Columns),
+		}
+		if s.Err != nil {
+			m.Error = s.Err.Error()
+		}
+		models = append(models, m)
+	}
+	return r.JSON(statsOutput{Sort: opts.Sort, Models: models, Summary: summary})
+}
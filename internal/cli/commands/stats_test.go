@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewStatsCommand(t *testing.T) {
+	cmd := NewStatsCommand()
+
+	assert.Equal(t, "stats [path]", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+	assert.NotEmpty(t, cmd.Example, "Example should not be empty")
+
+	flags := []string{"select", "sort", "limit"}
+	for _, flag := range flags {
+		assert.NotNil(t, cmd.Flags().Lookup(flag), "flag %q should exist", flag)
+	}
+}
+
+func testModelStats() []engine.ModelStats {
+	return []engine.ModelStats{
+		{Model: "a", Metrics: lintsql.Metrics{Joins: 1, Expressions: 10, NestingDepth: 1, Tables: []string{"x"}}},
+		{Model: "b", Err: errors.New("failed to parse")},
+		{Model: "c", Metrics: lintsql.Metrics{Joins: 3, Expressions: 4, NestingDepth: 2, Tables: []string{"x", "y"}}},
+		{Model: "d", Metrics: lintsql.Metrics{Joins: 1, Expressions: 4, NestingDepth: 1}},
+	}
+}
+
+func TestSortStats(t *testing.T) {
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		{"expressions", []string{"a", "c", "d", "b"}},
+		{"joins", []string{"c", "a", "d", "b"}},
+		{"tables", []string{"c", "a", "d", "b"}},
+		{"model", []string{"a", "c", "d", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			stats := testModelStats()
+			sortStats(stats, tt.by)
+
+			got := make([]string, len(stats))
+			for i, s := range stats {
+				got[i] = s.Model
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSummarizeStats(t *testing.T) {
+	summary := summarizeStats(testModelStats())
+
+	assert.Equal(t, 3, summary.Models)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, 3, summary.Max.Joins)
+	assert.Equal(t, 10, summary.Max.Expressions)
+	assert.Equal(t, 2, summary.Max.Tables)
+	assert.InDelta(t, 1.7, summary.Mean.Joins, 0.001)
+	assert.InDelta(t, 6.0, summary.Mean.Expressions, 0.001)
+	assert.InDelta(t, 1.3, summary.Mean.NestingDepth, 0.001)
+}
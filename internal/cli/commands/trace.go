@@ -0,0 +1,400 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
+	"github.com/spf13/cobra"
+)
+
+// TraceOptions holds options for the trace sql command.
+type TraceOptions struct {
+	Format   string // Output format: text, json, dot
+	NoSchema bool   // Skip loading model schemas from the state catalog
+}
+
+// TraceOutput is the JSON representation of ad-hoc lineage.
+type TraceOutput struct {
+	Sources        []string      `json:"sources"`
+	Columns        []TraceColumn `json:"columns"`
+	UsesSelectStar bool          `json:"uses_select_star"`
+	Warnings       []string      `json:"warnings,omitempty"`
+}
+
+// TraceColumn is the JSON representation of a single output column.
+type TraceColumn struct {
+	Name      string        `json:"name"`
+	Transform string        `json:"transform"`
+	Function  string        `json:"function,omitempty"`
+	Sources   []TraceSource `json:"sources"`
+}
+
+// TraceSource is a single upstream column reference.
+type TraceSource struct {
+	Table  string `json:"table,omitempty"`
+	Column string `json:"column"`
+	Field  string `json:"field,omitempty"`
+}
+
+// NewTraceCommand creates the trace command.
+func NewTraceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trace",
+		Short: "Trace lineage for ad-hoc SQL",
+		Long: `Trace table and column lineage outside of the project DAG.
+
+Use the subcommands to inspect queries that are not models, such as
+analyst scratch queries or SQL copied from a BI tool.`,
+	}
+
+	cmd.AddCommand(newTraceSQLCommand())
+
+	return cmd
+}
+
+func newTraceSQLCommand() *cobra.Command {
+	opts := &TraceOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "sql [file]",
+		Short: "Extract lineage from a SQL file or stdin",
+		Long: `Extract table and column lineage from an arbitrary SELECT statement.
+
+The SQL is parsed with the dialect models are written in. Column lists for
+models known to the state catalog are used to expand SELECT * and to
+resolve unqualified columns. The catalog is read as of the last discover or
+run, and the state database is opened read-only.
+
+Reads from stdin when no file is given or the file is "-".
+
+Output formats:
+  - text: Human-readable column listing (default)
+  - json: Machine-readable lineage
+  - dot:  Graphviz digraph of column-level edges`,
+		Example: `  # Trace a query file
+  leapsql trace sql query.sql
+
+  # Trace from stdin
+  echo "SELECT id, name FROM staging.stg_customers" | leapsql trace sql
+
+  # Render column lineage with Graphviz
+  leapsql trace sql query.sql --format dot | dot -Tsvg > lineage.svg
+
+  # Output as JSON
+  leapsql trace sql query.sql --format json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ""
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return runTraceSQL(cmd, path, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.Format, "format", "f", "", "Output format: text, json, dot")
+	cmd.Flags().BoolVar(&opts.NoSchema, "no-schema", false, "Do not load model schemas from the state catalog")
+
+	_ = cmd.RegisterFlagCompletionFunc("format", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"text", "json", "dot"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+func runTraceSQL(cmd *cobra.Command, path string, opts *TraceOptions) error {
+	sqlStr, err := readTraceInput(cmd, path)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(sqlStr) == "" {
+		return fmt.Errorf("no SQL provided")
+	}
+
+	// Tracing ad-hoc SQL only reads the state catalog, so it needs no engine
+	cmdCtx := NewCommandContextWithoutEngine(cmd)
+	r := cmdCtx.Renderer
+
+	d, err := sqlDialect(cmdCtx.Cfg, "")
+	if err != nil {
+		return err
+	}
+
+	var schema lineage.Schema
+	if !opts.NoSchema {
+		schema, err = loadTraceSchema(resolveStatePath(cmdCtx.Cfg))
+		if err != nil {
+			return fmt.Errorf("failed to load catalog schemas: %w", err)
+		}
+	}
+
+	result, err := lineage.ExtractLineageWithOptions(sqlStr, lineage.ExtractLineageOptions{
+		Dialect: d,
+		Schema:  schema,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to extract lineage: %w", err)
+	}
+
+	format := opts.Format
+	if format == "" {
+		if r.EffectiveMode() == output.ModeJSON {
+			format = "json"
+		} else {
+			format = "text"
+		}
+	}
+
+	switch format {
+	case "json":
+		return traceJSON(r, result)
+	case "dot":
+		return traceDOT(r, result)
+	case "text":
+		return traceText(r, result)
+	default:
+		return fmt.Errorf("unknown format %q: supported formats are text, json, dot", format)
+	}
+}
+
+// readTraceInput reads SQL from the given file, or stdin when path is empty or "-".
+func readTraceInput(cmd *cobra.Command, path string) (string, error) {
+	if path == "" || path == "-" {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return string(data), nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is user-provided CLI input
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(data), nil
+}
+
+// loadTraceSchema builds a lineage.Schema from the column catalog of the state
+// database, which is opened read-only. Each model is registered under both its
+// path (schema.name) and its bare name so qualified and unqualified references
+// resolve. Without a state database the schema is empty.
+func loadTraceSchema(statePath string) (lineage.Schema, error) {
+	if _, err := os.Stat(statePath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	db, err := openStateDBReadOnly(statePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+
+	rows, err := db.Query(`SELECT m.path, m.name, c.column_name
+FROM model_columns c JOIN models m ON m.path = c.model_path
+ORDER BY m.path, c.column_index`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	schema := make(lineage.Schema)
+	names := make(map[string]string)
+	for rows.Next() {
+		var path, name, column string
+		if err := rows.Scan(&path, &name, &column); err != nil {
+			return nil, err
+		}
+		schema[path] = append(schema[path], column)
+		names[path] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(names)) {
+		name := names[path]
+		if _, exists := schema[name]; name != "" && !exists {
+			schema[name] = schema[path]
+		}
+	}
+	return schema, nil
+}
+
+// traceText outputs lineage in styled text format.
+func traceText(r *output.Renderer, result *lineage.ModelLineage) error {
+	styles := r.Styles()
+
+	r.Header(1, "SQL Lineage")
+
+	r.Println(styles.Header2.Render(fmt.Sprintf("Sources (%d):", len(result.Sources))))
+	for _, src := range result.Sources {
+		r.Printf("    %s %s\n", styles.Dependency.Render("←"), styles.ModelPath.Render(src))
+	}
+	r.Println("")
+
+	r.Println(styles.Header2.Render(fmt.Sprintf("Columns (%d):", len(result.Columns))))
+	for _, col := range result.Columns {
+		transform := traceTransformLabel(col.Transform)
+		if col.Function != "" {
+			transform += ":" + col.Function
+		}
+		r.Printf("  %s %s\n", styles.ModelPath.Render(col.Name), styles.Muted.Render("("+transform+")"))
+		for _, src := range col.Sources {
+			r.Printf("    %s %s\n", styles.Dependency.Render("←"), formatTraceSource(src))
+		}
+	}
+
+	if result.UsesSelectStar {
+		r.Println("")
+		r.Println(styles.Muted.Render("Query uses SELECT *; columns are expanded from the catalog where known."))
+	}
+
+	for _, w := range result.Warnings {
+		r.Warning(w)
+	}
+
+	return nil
+}
+
+// traceJSON outputs lineage in JSON format.
+func traceJSON(r *output.Renderer, result *lineage.ModelLineage) error {
+	out := TraceOutput{
+		Sources:        result.Sources,
+		Columns:        make([]TraceColumn, 0, len(result.Columns)),
+		UsesSelectStar: result.UsesSelectStar,
+		Warnings:       result.Warnings,
+	}
+	if out.Sources == nil {
+		out.Sources = []string{}
+	}
+
+	for _, col := range result.Columns {
+		tc := TraceColumn{
+			Name:      col.Name,
+			Transform: traceTransformLabel(col.Transform),
+			Function:  col.Function,
+			Sources:   make([]TraceSource, 0, len(col.Sources)),
+		}
+		for _, src := range col.Sources {
+			tc.Sources = append(tc.Sources, TraceSource{Table: src.Table, Column: src.Column, Field: src.Field})
+		}
+		out.Columns = append(out.Columns, tc)
+	}
+
+	enc := json.NewEncoder(r.Writer())
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// traceDOT outputs column lineage as a Graphviz digraph.
+// Source tables become clusters; the query output is a single record node.
+func traceDOT(r *output.Renderer, result *lineage.ModelLineage) error {
+	tables := make(map[string]map[string]struct{})
+	for _, col := range result.Columns {
+		for _, src := range col.Sources {
+			if tables[src.Table] == nil {
+				tables[src.Table] = make(map[string]struct{})
+			}
+			tables[src.Table][traceSourceColumn(src)] = struct{}{}
+		}
+	}
+
+	tableNames := make([]string, 0, len(tables))
+	for t := range tables {
+		tableNames = append(tableNames, t)
+	}
+	sort.Strings(tableNames)
+
+	var sb strings.Builder
+	sb.WriteString("digraph lineage {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	for i, table := range tableNames {
+		label := table
+		if label == "" {
+			label = "(unresolved)"
+		}
+		fmt.Fprintf(&sb, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&sb, "    label=%s;\n", dotQuote(label))
+		cols := make([]string, 0, len(tables[table]))
+		for c := range tables[table] {
+			cols = append(cols, c)
+		}
+		sort.Strings(cols)
+		for _, c := range cols {
+			fmt.Fprintf(&sb, "    %s [label=%s];\n", dotQuote(dotNodeID(table, c)), dotQuote(c))
+		}
+		sb.WriteString("  }\n")
+	}
+
+	sb.WriteString("  subgraph cluster_output {\n")
+	sb.WriteString("    label=\"output\";\n")
+	for _, col := range result.Columns {
+		fmt.Fprintf(&sb, "    %s [label=%s];\n", dotQuote(dotNodeID("output", col.Name)), dotQuote(col.Name))
+	}
+	sb.WriteString("  }\n")
+
+	for _, col := range result.Columns {
+		for _, src := range col.Sources {
+			edgeLabel := ""
+			if col.Function != "" {
+				edgeLabel = fmt.Sprintf(" [label=%s]", dotQuote(col.Function))
+			}
+			fmt.Fprintf(&sb, "  %s -> %s%s;\n",
+				dotQuote(dotNodeID(src.Table, traceSourceColumn(src))),
+				dotQuote(dotNodeID("output", col.Name)),
+				edgeLabel)
+		}
+	}
+
+	sb.WriteString("}\n")
+	r.Print(sb.String())
+	return nil
+}
+
+// traceTransformLabel returns a display label for a transform type.
+func traceTransformLabel(t core.TransformType) string {
+	if t == core.TransformDirect {
+		return "direct"
+	}
+	return "expression"
+}
+
+// formatTraceSource formats a source reference as table.column[.field].
+func formatTraceSource(src core.SourceRef) string {
+	if src.Table == "" {
+		return traceSourceColumn(src)
+	}
+	return src.Table + "." + traceSourceColumn(src)
+}
+
+// traceSourceColumn returns the source column including any nested field path.
+func traceSourceColumn(src core.SourceRef) string {
+	if src.Field == "" {
+		return src.Column
+	}
+	if strings.HasPrefix(src.Field, "[") {
+		return src.Column + src.Field
+	}
+	return src.Column + "." + src.Field
+}
+
+// dotNodeID builds a stable node identifier for a table column.
+func dotNodeID(table, column string) string {
+	return table + ":" + column
+}
+
+// dotQuote quotes a string for use as a DOT identifier or label.
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`) + `"`
+}
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/config"
+	"github.com/leapstack-labs/leapsql/internal/state"
+	"github.com/leapstack-labs/leapsql/internal/testutil"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTraceCommand(t *testing.T) {
+	cmd := NewTraceCommand()
+
+	assert.Equal(t, "trace", cmd.Use)
+	assert.NotEmpty(t, cmd.Short, "Short should not be empty")
+
+	sqlCmd, _, err := cmd.Find([]string{"sql"})
+	require.NoError(t, err)
+	assert.Equal(t, "sql [file]", sqlCmd.Use)
+	assert.NotEmpty(t, sqlCmd.Example, "Example should not be empty")
+
+	flags := []string{"format", "no-schema"}
+	for _, flag := range flags {
+		assert.NotNil(t, sqlCmd.Flags().Lookup(flag), "flag %q should exist", flag)
+	}
+}
+
+func TestDotQuote(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain", "users", `"users"`},
+		{"quote", `a"b`, `"a\"b"`},
+		{"backslash", `a\b`, `"a\\b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dotQuote(tt.input))
+		})
+	}
+}
+
+func TestFormatTraceSource(t *testing.T) {
+	tests := []struct {
+		name string
+		src  core.SourceRef
+		want string
+	}{
+		{"column", core.SourceRef{Table: "users", Column: "id"}, "users.id"},
+		{"unqualified", core.SourceRef{Column: "id"}, "id"},
+		{"struct field", core.SourceRef{Table: "users", Column: "address", Field: "geo.lat"}, "users.address.geo.lat"},
+		{"array element", core.SourceRef{Table: "events", Column: "payload", Field: "[0]"}, "events.payload[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, formatTraceSource(tt.src))
+		})
+	}
+}
+
+func TestRunTraceSQL(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "state.db")
+	store := state.NewSQLiteStore(testutil.NewTestLogger(t))
+	require.NoError(t, store.Open(statePath))
+	require.NoError(t, store.InitSchema())
+	require.NoError(t, store.RegisterModel(&core.PersistedModel{
+		Model:       &core.Model{Path: "staging.stg_customers", Name: "stg_customers", Materialized: "table"},
+		ContentHash: "hash",
+	}))
+	require.NoError(t, store.SaveModelColumns("staging.stg_customers", []core.ColumnInfo{
+		{Name: "customer_id", Index: 0},
+		{Name: "customer_name", Index: 1},
+	}))
+	require.NoError(t, store.Close())
+	before, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+
+	config.ResetConfig()
+	t.Setenv("LEAPSQL_STATE_PATH", statePath)
+
+	out := new(bytes.Buffer)
+	cmd := &cobra.Command{}
+	cmd.SetOut(out)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetIn(strings.NewReader("SELECT c.*, c.customer_id + 1 AS next_id FROM stg_customers c"))
+
+	require.NoError(t, runTraceSQL(cmd, "", &TraceOptions{Format: "json"}))
+
+	var got TraceOutput
+	require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+	assert.Equal(t, []string{"stg_customers"}, got.Sources)
+	assert.True(t, got.UsesSelectStar)
+
+	// SELECT * is expanded from the catalog
+	require.Len(t, got.Columns, 3)
+	assert.Equal(t, "customer_id", got.Columns[0].Name)
+	assert.Equal(t, []TraceSource{{Table: "stg_customers", Column: "customer_id"}}, got.Columns[0].Sources)
+	assert.Equal(t, "customer_name", got.Columns[1].Name)
+	assert.Equal(t, TraceColumn{
+		Name:      "next_id",
+		Transform: "expression",
+		Sources:   []TraceSource{{Table: "stg_customers", Column: "customer_id"}},
+	}, got.Columns[2])
+
+	// The state database is only read
+	after, err := os.ReadFile(statePath)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+}
@@ -78,9 +78,15 @@ func runUI(cmd *cobra.Command, opts *UIOptions) error {
 		watch = opts.Watch
 	}
 
+	// Watch the whole workspace when serving multiple projects
+	modelsDir := cfg.ModelsDir
+	if cfg.Workspace != nil {
+		modelsDir = cfg.ProjectRoot
+	}
+
 	// Validate models directory exists
-	if _, err := os.Stat(cfg.ModelsDir); os.IsNotExist(err) {
-		return fmt.Errorf("models directory does not exist: %s", cfg.ModelsDir)
+	if _, err := os.Stat(modelsDir); os.IsNotExist(err) {
+		return fmt.Errorf("models directory does not exist: %s", modelsDir)
 	}
 
 	// Create engine for state access and discover
@@ -107,7 +113,7 @@ func runUI(cmd *cobra.Command, opts *UIOptions) error {
 		Watch:         watch,
 		SessionSecret: generateSessionSecret(),
 		Logger:        logger,
-		ModelsDir:     cfg.ModelsDir,
+		ModelsDir:     modelsDir,
 	}
 
 	server := ui.NewServer(serverCfg)
@@ -0,0 +1,265 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/config"
+	"github.com/leapstack-labs/leapsql/internal/cli/output"
+	intconfig "github.com/leapstack-labs/leapsql/internal/config"
+	"github.com/leapstack-labs/leapsql/internal/loader"
+	"github.com/leapstack-labs/leapsql/internal/template"
+	"github.com/leapstack-labs/leapsql/pkg/yamlschema"
+	"github.com/spf13/cobra"
+)
+
+// NewValidateCommand creates the validate command.
+func NewValidateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check config files and model frontmatter without touching the database",
+		Long: `Check the project's files for mistakes without connecting to the database.
+
+Validate checks:
+  - leapsql.yaml and leapsql.workspace.yaml: unknown fields, values of the
+    wrong type, invalid values and invalid targets
+  - Model frontmatter: unknown fields, values of the wrong type and invalid
+    values such as an unknown materialization
+  - Model templates: {{ }} and {* *} syntax
+
+Every problem is reported with its file, line and column. The command exits
+with an error if any problem is found, so it can run in CI or a pre-commit
+hook.`,
+		Example: `  # Validate the project
+  leapsql validate
+
+  # Report problems as JSON
+  leapsql validate -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// The root command skips loading the config for validate, so that
+			// config errors are reported with their position instead
+			flags := cmd.Root().PersistentFlags()
+			cfgFile, _ := flags.GetString("config")
+			target, _ := flags.GetString("target")
+			cfg, loadErr := config.LoadConfigWithTarget(cfgFile, target, flags)
+
+			format, _ := flags.GetString("output")
+			if cfg != nil {
+				format = cfg.OutputFormat
+			}
+			r := output.NewRenderer(cmd.OutOrStdout(), cmd.ErrOrStderr(), output.Mode(format))
+
+			result := validateProject(config.GetConfigFileUsed(), cfg, loadErr)
+			return renderValidate(r, result)
+		},
+	}
+
+	return cmd
+}
+
+// ValidateIssue is a problem found by the validate command.
+type ValidateIssue struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`   // 1-based, 0 if unknown
+	Column  int    `json:"column,omitempty"` // 1-based, 0 if unknown
+	Message string `json:"message"`
+}
+
+func (i ValidateIssue) String() string {
+	switch {
+	case i.Line > 0 && i.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", i.File, i.Line, i.Column, i.Message)
+	case i.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", i.File, i.Line, i.Message)
+	default:
+		return fmt.Sprintf("%s: %s", i.File, i.Message)
+	}
+}
+
+// ValidateOutput is the JSON output of the validate command.
+type ValidateOutput struct {
+	Files  int             `json:"files"` // Number of files checked
+	Issues []ValidateIssue `json:"issues"`
+}
+
+// validateProject checks the config file, the workspace file and every model
+// of the project. cfg is nil if the config failed to load with loadErr.
+func validateProject(configFile string, cfg *config.Config, loadErr error) *ValidateOutput {
+	result := &ValidateOutput{Issues: []ValidateIssue{}}
+
+	projectRoot := "."
+	if configFile != "" {
+		projectRoot = filepath.Dir(configFile)
+		result.Files++
+		errs, err := config.ValidateFile(configFile)
+		result.addSchemaIssues(configFile, errs, err)
+	}
+	if cfg != nil {
+		projectRoot = cfg.ProjectRoot
+	}
+	workspaceFile := filepath.Join(projectRoot, intconfig.WorkspaceFileName)
+	if _, err := os.Stat(workspaceFile); err == nil {
+		result.Files++
+		errs, err := config.ValidateWorkspaceFile(workspaceFile)
+		result.addSchemaIssues(workspaceFile, errs, err)
+	}
+
+	if loadErr != nil {
+		// Report load errors not already explained by a schema error
+		if len(result.Issues) == 0 {
+			file := configFile
+			if file == "" {
+				file = "leapsql.yaml"
+			}
+			result.Issues = append(result.Issues, ValidateIssue{File: displayPath(file), Message: loadErr.Error()})
+		}
+		return result
+	}
+
+	modelsDirs := []string{cfg.ModelsDir}
+	if cfg.Workspace != nil {
+		modelsDirs = modelsDirs[:0]
+		for _, p := range cfg.Workspace.Projects {
+			for _, name := range []string{"leapsql.yaml", "leapsql.yml"} {
+				path := filepath.Join(p.Path, name)
+				if _, err := os.Stat(path); err == nil {
+					result.Files++
+					errs, err := config.ValidateFile(path)
+					result.addSchemaIssues(path, errs, err)
+					break
+				}
+			}
+
+			modelsDir, _, _, err := intconfig.ProjectDirs(p)
+			if err != nil {
+				result.Issues = append(result.Issues, ValidateIssue{File: displayPath(p.Path), Message: err.Error()})
+				continue
+			}
+			modelsDirs = append(modelsDirs, modelsDir)
+		}
+	}
+
+	for _, dir := range modelsDirs {
+		result.validateModels(dir)
+	}
+	return result
+}
+
+func (o *ValidateOutput) addSchemaIssues(file string, errs []*yamlschema.Error, err error) {
+	if err != nil {
+		o.Issues = append(o.Issues, ValidateIssue{File: displayPath(file), Message: err.Error()})
+		return
+	}
+	for _, e := range errs {
+		o.Issues = append(o.Issues, ValidateIssue{File: displayPath(file), Line: e.Line, Column: e.Column, Message: e.Message})
+	}
+}
+
+// validateModels checks the frontmatter and template syntax of every model
+// in dir.
+func (o *ValidateOutput) validateModels(dir string) {
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".sql") || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+
+		content, err := os.ReadFile(path) //nolint:gosec // G304: path comes from filepath.WalkDir within the models directory
+		if err != nil {
+			return err
+		}
+		o.Files++
+		o.validateModel(displayPath(path), string(content))
+		return nil
+	})
+	if err != nil {
+		o.Issues = append(o.Issues, ValidateIssue{File: displayPath(dir), Message: err.Error()})
+	}
+}
+
+func (o *ValidateOutput) validateModel(file, content string) {
+	for _, err := range loader.ValidateFrontmatter(content) {
+		issue := ValidateIssue{File: file, Message: err.Error()}
+		var parseErr *loader.FrontmatterParseError
+		var unknownErr *loader.UnknownFieldError
+		switch {
+		case errors.As(err, &parseErr):
+			issue.Line, issue.Column, issue.Message = parseErr.Line, parseErr.Column, parseErr.Message
+		case errors.As(err, &unknownErr):
+			issue.Line, issue.Column = unknownErr.Line, unknownErr.Column
+		}
+		o.Issues = append(o.Issues, issue)
+	}
+
+	if _, err := template.ParseString(blankFrontmatter(content), ""); err != nil {
+		issue := ValidateIssue{File: file, Message: err.Error()}
+		var te template.Error
+		if errors.As(err, &te) {
+			pos := te.Position()
+			issue.Line, issue.Column = pos.Line, pos.Column
+			issue.Message = strings.TrimPrefix(err.Error(), fmt.Sprintf("%d:%d: ", pos.Line, pos.Column))
+		}
+		o.Issues = append(o.Issues, issue)
+	}
+}
+
+// blankFrontmatter replaces a model's frontmatter with whitespace, so that
+// template error positions match the file.
+func blankFrontmatter(content string) string {
+	if !strings.HasPrefix(strings.TrimSpace(content), "/*---") {
+		return content
+	}
+	end := strings.Index(content, "---*/")
+	if end < 0 {
+		return content
+	}
+	end += len("---*/")
+	lineStart := strings.LastIndex(content[:end], "\n") + 1
+	return strings.Repeat("\n", strings.Count(content[:end], "\n")) + strings.Repeat(" ", end-lineStart) + content[end:]
+}
+
+// displayPath returns path relative to the working directory when it is
+// inside it.
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(cwd, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+func renderValidate(r *output.Renderer, result *ValidateOutput) error {
+	if r.EffectiveMode() == output.ModeJSON {
+		if err := r.JSON(result); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range result.Issues {
+			r.Println(issue.String())
+		}
+		if len(result.Issues) == 0 {
+			r.Success(fmt.Sprintf("Validated %d files, no problems found", result.Files))
+		} else {
+			r.Println("")
+			r.Muted(fmt.Sprintf("Validated %d files, found %d problem(s)", result.Files, len(result.Issues)))
+		}
+	}
+
+	if len(result.Issues) > 0 {
+		return fmt.Errorf("validation failed")
+	}
+	return nil
+}
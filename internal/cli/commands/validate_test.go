@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leapstack-labs/leapsql/internal/cli/config"
+)
+
+func TestNewValidateCommand(t *testing.T) {
+	cmd := NewValidateCommand()
+	assert.Equal(t, "validate", cmd.Use)
+	assert.NotEmpty(t, cmd.Short)
+	assert.Error(t, cmd.Args(cmd, []string{"extra"}))
+}
+
+// writeFiles writes files relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+}
+
+func TestValidateProject(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"leapsql.yaml": "models_dir: models\nmodels: x\n",
+		"models/staging/orders.sql": `/*---
+materialized: table
+---*/
+SELECT * FROM raw_orders`,
+		"models/staging/customers.sql": `/*---
+name: customers
+materialised: view
+tags: pii
+---*/
+SELECT * FROM raw_customers`,
+		"models/marts/revenue.sql": `/*---
+materialized: table
+---*/
+SELECT {{ sum( FROM orders`,
+		"models/notes.txt": "not a model",
+	})
+
+	configFile := filepath.Join(dir, "leapsql.yaml")
+	cfg := &config.Config{ProjectRoot: dir, ModelsDir: filepath.Join(dir, "models")}
+
+	result := validateProject(configFile, cfg, nil)
+	assert.Equal(t, 4, result.Files)
+
+	var got []string
+	for _, issue := range result.Issues {
+		rel, err := filepath.Rel(dir, issue.File)
+		require.NoError(t, err)
+		issue.File = rel
+		got = append(got, issue.String())
+	}
+	assert.Equal(t, []string{
+		`leapsql.yaml:2:1: unknown field "models", expected one of: database, dialect, environment, environments, file_sources, groups, layers, lint, macros_dir, metadata, models_dir, output, query_comment, secrets, seeds_dir, state_path, table_sources, target, ui, verbose`,
+		"models/marts/revenue.sql:4:8: unclosed expression: missing '}}'",
+		`models/staging/customers.sql:3:1: unknown field "materialised" in frontmatter, use "meta" field for custom fields`,
+		"models/staging/customers.sql:4:7: tags: expected a list, got a string",
+	}, got)
+}
+
+func TestValidateProject_ConfigLoadError(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"leapsql.yaml": "target:\n  type: oracle\n",
+	})
+
+	result := validateProject(filepath.Join(dir, "leapsql.yaml"), nil, errors.New(`invalid target configuration: unknown adapter type "oracle"`))
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, `invalid target configuration: unknown adapter type "oracle"`, result.Issues[0].Message)
+	assert.Zero(t, result.Issues[0].Line)
+}
+
+func TestBlankFrontmatter(t *testing.T) {
+	content := "/*---\nname: a\n---*/ SELECT {{ x }}"
+	assert.Equal(t, "\n\n      SELECT {{ x }}", blankFrontmatter(content))
+	assert.Equal(t, "SELECT 1", blankFrontmatter("SELECT 1"))
+}
@@ -5,9 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	intconfig "github.com/leapstack-labs/leapsql/internal/config"
 	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/redact"
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -49,6 +51,54 @@ func TestTargetConfig_Validate(t *testing.T) {
 			wantErr:   false,
 			errSubstr: "",
 		},
+		{
+			name:      "valid transaction policy",
+			target:    core.TargetConfig{Type: "duckdb", Transaction: core.TransactionNever},
+			wantErr:   false,
+			errSubstr: "",
+		},
+		{
+			name:      "invalid transaction policy",
+			target:    core.TargetConfig{Type: "duckdb", Transaction: "sometimes"},
+			wantErr:   true,
+			errSubstr: `invalid target transaction "sometimes"`,
+		},
+		{
+			name:      "valid masking mode",
+			target:    core.TargetConfig{Type: "duckdb", Masking: &core.MaskingConfig{Mode: core.MaskingHash}},
+			wantErr:   false,
+			errSubstr: "",
+		},
+		{
+			name:      "invalid masking mode",
+			target:    core.TargetConfig{Type: "duckdb", Masking: &core.MaskingConfig{Mode: "redact"}},
+			wantErr:   true,
+			errSubstr: `invalid target masking mode "redact"`,
+		},
+		{
+			name:      "masking policy mode without policy",
+			target:    core.TargetConfig{Type: "duckdb", Masking: &core.MaskingConfig{Mode: core.MaskingPolicy}},
+			wantErr:   true,
+			errSubstr: "requires masking.policy",
+		},
+		{
+			name:      "valid concurrency limits",
+			target:    core.TargetConfig{Type: "duckdb", MaxConcurrentQueries: 4, ResourceClasses: map[string]int{"heavy": 1}},
+			wantErr:   false,
+			errSubstr: "",
+		},
+		{
+			name:      "negative max_concurrent_queries",
+			target:    core.TargetConfig{Type: "duckdb", MaxConcurrentQueries: -1},
+			wantErr:   true,
+			errSubstr: "invalid target max_concurrent_queries -1",
+		},
+		{
+			name:      "resource class without slots",
+			target:    core.TargetConfig{Type: "duckdb", ResourceClasses: map[string]int{"heavy": 0}},
+			wantErr:   true,
+			errSubstr: `invalid target resource class "heavy" limit 0`,
+		},
 		{
 			name:      "unknown type mysql",
 			target:    core.TargetConfig{Type: "mysql"},
@@ -225,8 +275,11 @@ func TestMergeTargetConfig(t *testing.T) {
 			Host:     "localhost",
 		}
 		override := &core.TargetConfig{
-			Database: "override.db",
-			Schema:   "custom",
+			Database:             "override.db",
+			Schema:               "custom",
+			Transaction:          core.TransactionNever,
+			Masking:              &core.MaskingConfig{Mode: core.MaskingHash},
+			MaxConcurrentQueries: 2,
 		}
 
 		result := MergeTargetConfig(base, override)
@@ -235,6 +288,9 @@ func TestMergeTargetConfig(t *testing.T) {
 		assert.Equal(t, "override.db", result.Database, "Database should be from override")
 		assert.Equal(t, "custom", result.Schema, "Schema should be from override")
 		assert.Equal(t, "localhost", result.Host, "Host should be inherited from base")
+		assert.Equal(t, core.TransactionNever, result.Transaction, "Transaction should be from override")
+		assert.Equal(t, core.MaskingHash, result.Masking.Mode, "Masking should be from override")
+		assert.Equal(t, 2, result.MaxConcurrentQueries, "MaxConcurrentQueries should be from override")
 	})
 
 	t.Run("options are merged", func(t *testing.T) {
@@ -394,6 +450,110 @@ func TestConfig_Validate(t *testing.T) {
 		require.Error(t, err, "expected error for empty models_dir")
 		assert.Contains(t, err.Error(), "models_dir is required")
 	})
+
+	t.Run("duplicate group", func(t *testing.T) {
+		cfg := &Config{ModelsDir: "models", Groups: []core.GroupConfig{{Name: "finance"}, {Name: "finance"}}}
+		err := cfg.Validate()
+		require.Error(t, err, "expected error for duplicate group")
+		assert.Contains(t, err.Error(), `duplicate group name "finance"`)
+	})
+
+	t.Run("unnamed group", func(t *testing.T) {
+		cfg := &Config{ModelsDir: "models", Groups: []core.GroupConfig{{Owner: "alice"}}}
+		err := cfg.Validate()
+		require.Error(t, err, "expected error for unnamed group")
+		assert.Contains(t, err.Error(), "name is required")
+	})
+
+	t.Run("negative max_runtime", func(t *testing.T) {
+		cfg := &Config{ModelsDir: "models", Groups: []core.GroupConfig{{Name: "finance", MaxRuntime: -time.Minute}}}
+		err := cfg.Validate()
+		require.Error(t, err, "expected error for negative max_runtime")
+		assert.Contains(t, err.Error(), "max_runtime must not be negative")
+	})
+
+	t.Run("layers", func(t *testing.T) {
+		cfg := &Config{ModelsDir: "models", Layers: []core.LayerConfig{
+			{Name: "bronze", DependsOn: []string{"source"}},
+			{Name: "silver", DependsOn: []string{"bronze"}},
+		}}
+		assert.NoError(t, cfg.Validate())
+
+		for _, tt := range []struct {
+			layers  []core.LayerConfig
+			wantErr string
+		}{
+			{[]core.LayerConfig{{Paths: []string{"bronze"}}}, "layers[0]: name is required"},
+			{[]core.LayerConfig{{Name: "bronze"}, {Name: "bronze"}}, `layers[1]: duplicate layer name "bronze"`},
+			{[]core.LayerConfig{{Name: "source"}}, `layers[0]: "source" is a reserved layer name`},
+			{[]core.LayerConfig{{Name: "silver", DependsOn: []string{"bronz"}}}, `layers[0]: depends_on references unknown layer "bronz"`},
+		} {
+			cfg := &Config{ModelsDir: "models", Layers: tt.layers}
+			err := cfg.Validate()
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		}
+	})
+
+	t.Run("lint overrides", func(t *testing.T) {
+		cfg := &Config{ModelsDir: "models", Lint: &core.LintConfig{Overrides: []core.LintOverride{
+			{Models: []string{"tag:tier1"}, WarningsAsErrors: true},
+		}}}
+		assert.NoError(t, cfg.Validate())
+
+		cfg.Lint.Overrides = []core.LintOverride{{Models: []string{"tier1"}}}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `lint.overrides[0]: invalid model selector "tier1"`)
+	})
+
+	t.Run("lint profile", func(t *testing.T) {
+		cfg := &Config{ModelsDir: "models", Lint: &core.LintConfig{Profile: "strict"}}
+		assert.NoError(t, cfg.Validate())
+
+		cfg.Lint.Profile = "lenient"
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Equal(t, `lint.profile: unknown profile "lenient": must be one of [dbt-style minimal sqlfluff-default strict]`, err.Error())
+	})
+
+	t.Run("metadata sinks", func(t *testing.T) {
+		cfg := &Config{ModelsDir: "models", Metadata: &core.MetadataConfig{Sinks: []core.MetadataSinkConfig{
+			{Type: "datahub", URL: "http://localhost:8080"},
+		}}}
+		assert.NoError(t, cfg.Validate())
+
+		cfg.Metadata.Sinks = []core.MetadataSinkConfig{{Type: "atlas", URL: "http://localhost:21000"}}
+		err := cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `metadata.sinks[0]: unknown type "atlas"`)
+
+		cfg.Metadata.Sinks = []core.MetadataSinkConfig{{Type: "datahub"}}
+		err = cfg.Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "metadata.sinks[0]: url is required")
+	})
+
+	t.Run("file source without path", func(t *testing.T) {
+		cfg := &Config{ModelsDir: "models", FileSources: []core.FileSourceConfig{{WarnAfter: time.Hour}}}
+		err := cfg.Validate()
+		require.Error(t, err, "expected error for file source without path")
+		assert.Contains(t, err.Error(), "file_sources[0]: path is required")
+	})
+
+	t.Run("negative file source threshold", func(t *testing.T) {
+		cfg := &Config{ModelsDir: "models", FileSources: []core.FileSourceConfig{{Path: "data/*.csv", ErrorAfter: -time.Hour}}}
+		err := cfg.Validate()
+		require.Error(t, err, "expected error for negative error_after")
+		assert.Contains(t, err.Error(), "warn_after and error_after must not be negative")
+	})
+
+	t.Run("table source without loaded_at", func(t *testing.T) {
+		cfg := &Config{ModelsDir: "models", TableSources: []core.TableSourceConfig{{Table: "raw.orders"}}}
+		err := cfg.Validate()
+		require.Error(t, err, "expected error for table source without loaded_at")
+		assert.Contains(t, err.Error(), "table_sources[0]: table and loaded_at are required")
+	})
 }
 
 // TestLoadConfigWithTarget_FlagPrecedence tests that flags override env vars and config file.
@@ -701,3 +861,395 @@ target:
 		assert.Equal(t, project1, cfg.ProjectRoot)
 	})
 }
+
+func TestLoadConfigWithTarget_Groups(t *testing.T) {
+	ResetConfig()
+
+	tmpDir := t.TempDir()
+	content := `groups:
+  - name: finance
+    owner: finance-data
+    slack_channel: "#finance-alerts"
+    max_runtime: 30m
+  - name: growth
+    owner: growth-team
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "leapsql.yaml"), []byte(content), 0600))
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("project-dir", "", "")
+	require.NoError(t, flags.Set("project-dir", tmpDir))
+
+	cfg, err := LoadConfigWithTarget("", "", flags)
+	require.NoError(t, err)
+
+	assert.Equal(t, []core.GroupConfig{
+		{Name: "finance", Owner: "finance-data", SlackChannel: "#finance-alerts", MaxRuntime: 30 * time.Minute},
+		{Name: "growth", Owner: "growth-team"},
+	}, cfg.Groups)
+}
+
+func TestLoadConfigWithTarget_FileSources(t *testing.T) {
+	ResetConfig()
+
+	tmpDir := t.TempDir()
+	content := `file_sources:
+  - path: s3://lake/orders/*.parquet
+    warn_after: 12h
+    error_after: 24h
+  - path: data/refunds.csv
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "leapsql.yaml"), []byte(content), 0600))
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("project-dir", "", "")
+	require.NoError(t, flags.Set("project-dir", tmpDir))
+
+	cfg, err := LoadConfigWithTarget("", "", flags)
+	require.NoError(t, err)
+
+	assert.Equal(t, []core.FileSourceConfig{
+		{Path: "s3://lake/orders/*.parquet", WarnAfter: 12 * time.Hour, ErrorAfter: 24 * time.Hour},
+		{Path: "data/refunds.csv"},
+	}, cfg.FileSources)
+}
+
+func TestLoadConfigWithTarget_Sample(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `target:
+  type: duckdb
+environments:
+  dev:
+    sample: 1000
+  prod:
+    target:
+      database: prod.duckdb
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "leapsql.yaml"), []byte(content), 0600))
+
+	for env, want := range map[string]int{"dev": 1000, "prod": 0} {
+		t.Run(env, func(t *testing.T) {
+			ResetConfig()
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.String("project-dir", "", "")
+			require.NoError(t, flags.Set("project-dir", tmpDir))
+
+			cfg, err := LoadConfigWithTarget("", env, flags)
+			require.NoError(t, err)
+			assert.Equal(t, want, cfg.Sample)
+		})
+	}
+}
+
+func TestLoadConfigWithTarget_Explain(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `target:
+  type: duckdb
+environments:
+  dev:
+    explain: true
+  prod:
+    target:
+      database: prod.duckdb
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "leapsql.yaml"), []byte(content), 0600))
+
+	for env, want := range map[string]bool{"dev": true, "prod": false} {
+		t.Run(env, func(t *testing.T) {
+			ResetConfig()
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.String("project-dir", "", "")
+			require.NoError(t, flags.Set("project-dir", tmpDir))
+
+			cfg, err := LoadConfigWithTarget("", env, flags)
+			require.NoError(t, err)
+			assert.Equal(t, want, cfg.Explain)
+		})
+	}
+}
+
+func TestLoadConfigWithTarget_Secrets(t *testing.T) {
+	t.Setenv("LEAPSQL_TEST_PG_USER", "analyst")
+	t.Setenv("LEAPSQL_TEST_PG_HOST", "db.internal")
+
+	tests := []struct {
+		name    string
+		config  string
+		want    core.TargetConfig
+		wantErr string
+		masked  []string
+	}{
+		{
+			name: "env_var",
+			config: `target:
+  type: postgres
+  host: ${LEAPSQL_TEST_PG_HOST}
+  user: "{{ env_var('LEAPSQL_TEST_PG_USER') }}"
+  password: env_var("LEAPSQL_TEST_PG_PASSWORD", "fallback-pw")
+  database: analytics
+`,
+			want: core.TargetConfig{
+				Type: "postgres", Host: "db.internal", Port: 5432, User: "analyst",
+				Password: "fallback-pw", Database: "analytics", Schema: "public",
+			},
+			masked: []string{"fallback-pw"},
+		},
+		{
+			name: "secret from command",
+			config: `secrets:
+  command: ["echo", "s3cr3t-{name}"]
+target:
+  type: postgres
+  host: localhost
+  user: app
+  password: secret('pg')
+  database: analytics
+  options:
+    sslmode: require
+    sslpassword: secret('ssl')
+`,
+			want: core.TargetConfig{
+				Type: "postgres", Host: "localhost", Port: 5432, User: "app",
+				Password: "s3cr3t-pg", Database: "analytics", Schema: "public",
+				Options: map[string]string{"sslmode": "require", "sslpassword": "s3cr3t-ssl"},
+			},
+			masked: []string{"s3cr3t-pg", "s3cr3t-ssl"},
+		},
+		{
+			name: "unset env_var",
+			config: `target:
+  type: postgres
+  host: localhost
+  password: env_var('LEAPSQL_TEST_UNSET')
+`,
+			wantErr: "environment variable LEAPSQL_TEST_UNSET is not set",
+		},
+		{
+			name: "secret without command",
+			config: `target:
+  type: postgres
+  host: localhost
+  password: secret('pg')
+`,
+			wantErr: "no secret manager configured: set secrets.command in leapsql.yaml",
+		},
+		{
+			name: "failing command",
+			config: `secrets:
+  command: ["sh", "-c", "echo 'access denied' >&2; exit 3"]
+target:
+  type: postgres
+  host: localhost
+  password: secret('pg')
+`,
+			wantErr: "access denied",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ResetConfig()
+			t.Cleanup(redact.Reset)
+
+			tmpDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "leapsql.yaml"), []byte(tt.config), 0600))
+
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.String("project-dir", "", "")
+			require.NoError(t, flags.Set("project-dir", tmpDir))
+
+			cfg, err := LoadConfigWithTarget("", "", flags)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *cfg.Target)
+			for _, secret := range tt.masked {
+				assert.Equal(t, "pw="+redact.Mask, redact.String("pw="+secret))
+			}
+		})
+	}
+}
+
+func TestLoadConfigWithTarget_MetadataSecrets(t *testing.T) {
+	ResetConfig()
+	t.Cleanup(redact.Reset)
+	t.Setenv("LEAPSQL_TEST_DATAHUB_URL", "http://datahub:8080")
+
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "leapsql.yaml"), []byte(`secrets:
+  command: ["echo", "tok-{name}"]
+metadata:
+  push_after_run: true
+  sinks:
+    - type: datahub
+      url: ${LEAPSQL_TEST_DATAHUB_URL}
+      token: secret('datahub')
+`), 0600))
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("project-dir", "", "")
+	require.NoError(t, flags.Set("project-dir", tmpDir))
+
+	cfg, err := LoadConfigWithTarget("", "", flags)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.Metadata)
+	assert.True(t, cfg.Metadata.PushAfterRun)
+	assert.Equal(t, []core.MetadataSinkConfig{
+		{Type: "datahub", URL: "http://datahub:8080", Token: "tok-datahub"},
+	}, cfg.Metadata.Sinks)
+	assert.Equal(t, "token="+redact.Mask, redact.String("token=tok-datahub"))
+}
+
+func TestLoadConfigWithTarget_Workspace(t *testing.T) {
+	t.Run("loads workspace projects", func(t *testing.T) {
+		ResetConfig()
+
+		tmpDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "core"), 0750))
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "projects", "billing"), 0750))
+
+		wsContent := `projects:
+  - name: core
+  - name: billing
+    path: projects/billing
+`
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, intconfig.WorkspaceFileName), []byte(wsContent), 0600))
+
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.String("project-dir", "", "")
+		require.NoError(t, flags.Set("project-dir", tmpDir))
+
+		cfg, err := LoadConfigWithTarget("", "", flags)
+		require.NoError(t, err)
+		require.NotNil(t, cfg.Workspace)
+
+		assert.Equal(t, []core.WorkspaceProject{
+			{Name: "core", Path: filepath.Join(tmpDir, "core")},
+			{Name: "billing", Path: filepath.Join(tmpDir, "projects", "billing")},
+		}, cfg.Workspace.Projects)
+	})
+
+	t.Run("no workspace file", func(t *testing.T) {
+		ResetConfig()
+
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.String("project-dir", "", "")
+		require.NoError(t, flags.Set("project-dir", t.TempDir()))
+
+		cfg, err := LoadConfigWithTarget("", "", flags)
+		require.NoError(t, err)
+		assert.Nil(t, cfg.Workspace)
+	})
+
+	t.Run("rejects invalid project names", func(t *testing.T) {
+		ResetConfig()
+
+		tmpDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "core-data"), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, intconfig.WorkspaceFileName),
+			[]byte("projects:\n  - name: core-data\n"), 0600))
+
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.String("project-dir", "", "")
+		require.NoError(t, flags.Set("project-dir", tmpDir))
+
+		_, err := LoadConfigWithTarget("", "", flags)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `invalid project name "core-data"`)
+	})
+}
+
+func TestConfig_EnvironmentDatabase(t *testing.T) {
+	require.NoError(t, os.Setenv("TEST_PROD_DB", "/data/prod.duckdb"))
+	defer func() { _ = os.Unsetenv("TEST_PROD_DB") }()
+
+	cfg := &Config{
+		Environments: map[string]EnvConfig{
+			"prod":    {Target: &core.TargetConfig{Database: "${TEST_PROD_DB}"}},
+			"staging": {DatabasePath: "staging.duckdb"},
+			"ci":      {Target: &core.TargetConfig{Schema: "ci"}},
+		},
+	}
+
+	tests := []struct {
+		env    string
+		want   string
+		wantOK bool
+	}{
+		{env: "prod", want: "/data/prod.duckdb", wantOK: true},
+		{env: "staging", want: "staging.duckdb", wantOK: true},
+		{env: "ci"},
+		{env: "qa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			got, ok := cfg.EnvironmentDatabase(tt.env)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	t.Run("repository configs are valid", func(t *testing.T) {
+		paths, err := filepath.Glob("../../../testdata/leapsql.yaml")
+		require.NoError(t, err)
+		more, err := filepath.Glob("../commands/templates/*/leapsql.yaml")
+		require.NoError(t, err)
+		paths = append(paths, more...)
+		require.NotEmpty(t, paths)
+
+		for _, path := range paths {
+			errs, err := ValidateFile(path)
+			require.NoError(t, err)
+			assert.Empty(t, errs, path)
+		}
+	})
+
+	t.Run("problems are reported with positions", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "leapsql.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`models_dir: models
+modles_dir: other
+output: html
+target:
+  type: duckdb
+  port: "5432"
+  params:
+    threads: 4
+lint:
+  severity:
+    AM01: fatal
+environments:
+  prod:
+    target: [duckdb]
+`), 0600))
+
+		errs, err := ValidateFile(path)
+		require.NoError(t, err)
+
+		var got []string
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		assert.Equal(t, []string{
+			`2:1: unknown field "modles_dir", expected one of: database, dialect, environment, environments, file_sources, groups, layers, lint, macros_dir, metadata, models_dir, output, query_comment, secrets, seeds_dir, state_path, table_sources, target, ui, verbose`,
+			`3:9: invalid output value: "html", must be one of: auto, text, markdown, json`,
+			`11:11: invalid lint.severity.AM01 value: "fatal", must be one of: error, warning, info, hint`,
+			`14:13: environments.prod.target: expected a mapping, got a list`,
+		}, got)
+	})
+
+	t.Run("workspace file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), intconfig.WorkspaceFileName)
+		require.NoError(t, os.WriteFile(path, []byte("projects:\n  - name: core\n    dir: core\n"), 0600))
+
+		errs, err := ValidateWorkspaceFile(path)
+		require.NoError(t, err)
+		require.Len(t, errs, 1)
+		assert.Equal(t, `3:5: unknown field "dir" in projects[0], expected one of: name, path`, errs[0].Error())
+	})
+}
@@ -337,6 +337,8 @@ func LoadConfigWithTarget(cfgFile string, targetOverride string, flags *pflag.Fl
 			if envCfg.Target != nil {
 				cfg.Target = MergeTargetConfig(cfg.Target, envCfg.Target)
 			}
+			cfg.Sample = envCfg.Sample
+			cfg.Explain = envCfg.Explain
 		}
 	}
 
@@ -351,8 +353,13 @@ func LoadConfigWithTarget(cfgFile string, targetOverride string, flags *pflag.Fl
 	// Apply defaults based on target type
 	intconfig.ApplyTargetDefaults(cfg.Target)
 
-	// Expand environment variables in target
-	expandTargetEnvVars(cfg.Target)
+	// Resolve env_var(), secret() and ${VAR} references in target
+	if err := intconfig.ResolveSecrets(cfg.Target, cfg.Secrets); err != nil {
+		return nil, fmt.Errorf("invalid target configuration: %w", err)
+	}
+	if err := intconfig.ResolveMetadataSecrets(cfg.Metadata, cfg.Secrets); err != nil {
+		return nil, fmt.Errorf("invalid metadata configuration: %w", err)
+	}
 
 	// For backward compatibility: sync DatabasePath with Target.Database
 	// If --database flag was explicitly set, it takes precedence over config file
@@ -370,6 +377,17 @@ func LoadConfigWithTarget(cfgFile string, targetOverride string, flags *pflag.Fl
 		return nil, fmt.Errorf("invalid target configuration: %w", err)
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Load workspace file, if the project root is a multi-project workspace
+	ws, err := intconfig.LoadWorkspace(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Workspace = ws
+
 	// Store config for access by commands
 	currentConfig = &cfg
 
@@ -417,18 +435,6 @@ func expandEnvVars(s string) string {
 	})
 }
 
-// expandTargetEnvVars expands environment variables in sensitive target fields.
-func expandTargetEnvVars(t *core.TargetConfig) {
-	if t == nil {
-		return
-	}
-	t.Password = expandEnvVars(t.Password)
-	t.User = expandEnvVars(t.User)
-	t.Host = expandEnvVars(t.Host)
-	t.Database = expandEnvVars(t.Database)
-	t.Account = expandEnvVars(t.Account)
-}
-
 // MergeTargetConfig merges two target configs, with override taking precedence.
 func MergeTargetConfig(base, override *core.TargetConfig) *core.TargetConfig {
 	if base == nil {
@@ -440,18 +446,22 @@ func MergeTargetConfig(base, override *core.TargetConfig) *core.TargetConfig {
 
 	// Start with a copy of base
 	merged := &core.TargetConfig{
-		Type:      base.Type,
-		Database:  base.Database,
-		Host:      base.Host,
-		Port:      base.Port,
-		User:      base.User,
-		Password:  base.Password,
-		Schema:    base.Schema,
-		Account:   base.Account,
-		Warehouse: base.Warehouse,
-		Role:      base.Role,
-		Options:   make(map[string]string),
-		Params:    make(map[string]any),
+		Type:                 base.Type,
+		Database:             base.Database,
+		Host:                 base.Host,
+		Port:                 base.Port,
+		User:                 base.User,
+		Password:             base.Password,
+		Schema:               base.Schema,
+		Account:              base.Account,
+		Warehouse:            base.Warehouse,
+		Role:                 base.Role,
+		Transaction:          base.Transaction,
+		Masking:              base.Masking,
+		MaxConcurrentQueries: base.MaxConcurrentQueries,
+		ResourceClasses:      base.ResourceClasses,
+		Options:              make(map[string]string),
+		Params:               make(map[string]any),
 	}
 
 	// Copy base options
@@ -495,6 +505,18 @@ func MergeTargetConfig(base, override *core.TargetConfig) *core.TargetConfig {
 	if override.Role != "" {
 		merged.Role = override.Role
 	}
+	if override.Transaction != "" {
+		merged.Transaction = override.Transaction
+	}
+	if override.Masking != nil {
+		merged.Masking = override.Masking
+	}
+	if override.MaxConcurrentQueries != 0 {
+		merged.MaxConcurrentQueries = override.MaxConcurrentQueries
+	}
+	if override.ResourceClasses != nil {
+		merged.ResourceClasses = override.ResourceClasses
+	}
 
 	// Merge options
 	for k, v := range override.Options {
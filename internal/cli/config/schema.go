@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/yamlschema"
+)
+
+// configSchema is the schema of leapsql.yaml. koanf decodes config files
+// weakly typed, so values such as port: "5432" are accepted. The name and
+// version fields of older config files are still accepted, and ignored.
+var configSchema = yamlschema.For(Config{}, "koanf").WeaklyTyped().
+	Ignore("name", "version").
+	Enum("output", "auto", "text", "markdown", "json").
+	Enum("target.transaction", "auto", "always", "never").
+	Enum("environments.*.target.transaction", "auto", "always", "never").
+	Enum("target.masking.mode", "none", "hash", "null", "policy").
+	Enum("environments.*.target.masking.mode", "none", "hash", "null", "policy").
+	Enum("lint.profile", lint.ListProfiles()...).
+	Enum("lint.severity.*", "error", "warning", "info", "hint").
+	Enum("lint.project_health.rules.*", "off", "info", "warning", "error")
+
+// workspaceSchema is the schema of leapsql.workspace.yaml.
+var workspaceSchema = yamlschema.For(core.WorkspaceConfig{}, "koanf").WeaklyTyped()
+
+// ValidateFile checks a project config file against the config schema and
+// returns every unknown field, value of the wrong type and invalid enum value.
+// It does not check that directories exist or that the target is reachable.
+func ValidateFile(path string) ([]*yamlschema.Error, error) {
+	return validateFile(path, configSchema)
+}
+
+// ValidateWorkspaceFile checks a workspace file against the workspace schema.
+func ValidateWorkspaceFile(path string) ([]*yamlschema.Error, error) {
+	return validateFile(path, workspaceSchema)
+}
+
+func validateFile(path string, schema *yamlschema.Schema) ([]*yamlschema.Error, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is the project's config file
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return schema.Validate(data), nil
+}
@@ -45,19 +45,39 @@ func (c *Config) GetUIConfig() *UIConfig {
 
 // Config holds all CLI configuration options.
 type Config struct {
-	ProjectRoot  string               `koanf:"-"` // Computed project root, not from config file
-	ModelsDir    string               `koanf:"models_dir"`
-	SeedsDir     string               `koanf:"seeds_dir"`
-	MacrosDir    string               `koanf:"macros_dir"`
-	DatabasePath string               `koanf:"database"` // Deprecated: use Target.Database
-	StatePath    string               `koanf:"state_path"`
-	Environment  string               `koanf:"environment"`
-	Verbose      bool                 `koanf:"verbose"`
-	OutputFormat string               `koanf:"output"`
-	Target       *core.TargetConfig   `koanf:"target"`
-	Lint         *core.LintConfig     `koanf:"lint"`
-	UI           *UIConfig            `koanf:"ui"`
-	Environments map[string]EnvConfig `koanf:"environments"`
+	ProjectRoot  string                   `koanf:"-"` // Computed project root, not from config file
+	ModelsDir    string                   `koanf:"models_dir"`
+	SeedsDir     string                   `koanf:"seeds_dir"`
+	MacrosDir    string                   `koanf:"macros_dir"`
+	Dialect      string                   `koanf:"dialect"`  // SQL dialect models are written in (default: the target's)
+	DatabasePath string                   `koanf:"database"` // Deprecated: use Target.Database
+	StatePath    string                   `koanf:"state_path"`
+	Environment  string                   `koanf:"environment"`
+	Verbose      bool                     `koanf:"verbose"`
+	OutputFormat string                   `koanf:"output"`
+	Target       *core.TargetConfig       `koanf:"target"`
+	Lint         *core.LintConfig         `koanf:"lint"`
+	UI           *UIConfig                `koanf:"ui"`
+	Environments map[string]EnvConfig     `koanf:"environments"`
+	Groups       []core.GroupConfig       `koanf:"groups"`
+	Layers       []core.LayerConfig       `koanf:"layers"`        // Model layers for project lint (default: staging, intermediate, marts)
+	FileSources  []core.FileSourceConfig  `koanf:"file_sources"`  // Freshness of files models read with read_parquet etc.
+	TableSources []core.TableSourceConfig `koanf:"table_sources"` // Columns telling when raw tables were loaded, for run --skip-fresh
+	QueryComment string                   `koanf:"query_comment"` // Template of the comment prefixed to executed SQL ("off" to disable)
+	Secrets      *core.SecretsConfig      `koanf:"secrets"`       // Secret manager for secret() references in targets
+	Metadata     *core.MetadataConfig     `koanf:"metadata"`      // Data catalogs model metadata is pushed to
+
+	// Sample is the number of rows runs limit each model to, from the
+	// selected environment's sample setting (0 builds models in full).
+	Sample int `koanf:"-"`
+
+	// Explain reports whether runs capture the query plan of each model,
+	// from the selected environment's explain setting.
+	Explain bool `koanf:"-"`
+
+	// Workspace lists the member projects when the project root holds a
+	// leapsql.workspace.yaml; nil for a standalone project.
+	Workspace *core.WorkspaceConfig `koanf:"-"`
 }
 
 // EnvConfig holds environment-specific configuration overrides.
@@ -67,6 +87,27 @@ type EnvConfig struct {
 	SeedsDir     string             `koanf:"seeds_dir"`
 	MacrosDir    string             `koanf:"macros_dir"`
 	Target       *core.TargetConfig `koanf:"target"`
+	Sample       int                `koanf:"sample"`  // Rows runs limit each model to (0 builds in full)
+	Explain      bool               `koanf:"explain"` // Capture the query plan of each model built
+}
+
+// EnvironmentDatabase returns the target database configured for the named
+// environment, with environment variables expanded. It reports false if the
+// environment does not exist or sets no database.
+func (c *Config) EnvironmentDatabase(env string) (string, bool) {
+	envCfg, ok := c.Environments[env]
+	if !ok {
+		return "", false
+	}
+
+	database := envCfg.DatabasePath
+	if envCfg.Target != nil && envCfg.Target.Database != "" {
+		database = envCfg.Target.Database
+	}
+	if database == "" {
+		return "", false
+	}
+	return expandEnvVars(database), true
 }
 
 // CLI-specific default configuration values.
@@ -5,6 +5,9 @@ import (
 	"os"
 
 	intconfig "github.com/leapstack-labs/leapsql/internal/config"
+	"github.com/leapstack-labs/leapsql/internal/metadata"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
 )
 
 // DefaultSchemaForType returns the default schema for a database type.
@@ -19,11 +22,92 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("models_dir is required")
 	}
 
+	seenGroups := make(map[string]bool, len(c.Groups))
+	for i, g := range c.Groups {
+		if g.Name == "" {
+			return fmt.Errorf("groups[%d]: name is required", i)
+		}
+		if seenGroups[g.Name] {
+			return fmt.Errorf("groups[%d]: duplicate group name %q", i, g.Name)
+		}
+		seenGroups[g.Name] = true
+		if g.MaxRuntime < 0 {
+			return fmt.Errorf("groups[%d]: max_runtime must not be negative", i)
+		}
+	}
+
+	if err := validateLayers(c.Layers); err != nil {
+		return err
+	}
+
+	if c.Lint != nil {
+		if c.Lint.Profile != "" {
+			if _, ok := lint.GetProfile(c.Lint.Profile); !ok {
+				return fmt.Errorf("lint.profile: unknown profile %q: must be one of %v", c.Lint.Profile, lint.ListProfiles())
+			}
+		}
+		if _, err := lint.ParseModelOverrides(c.Lint.Overrides); err != nil {
+			return err
+		}
+	}
+
+	for i, f := range c.FileSources {
+		if f.Path == "" {
+			return fmt.Errorf("file_sources[%d]: path is required", i)
+		}
+		if f.WarnAfter < 0 || f.ErrorAfter < 0 {
+			return fmt.Errorf("file_sources[%d]: warn_after and error_after must not be negative", i)
+		}
+	}
+
+	for i, t := range c.TableSources {
+		if t.Table == "" || t.LoadedAt == "" {
+			return fmt.Errorf("table_sources[%d]: table and loaded_at are required", i)
+		}
+	}
+
+	if c.Metadata != nil {
+		for i, s := range c.Metadata.Sinks {
+			if !metadata.IsRegistered(s.Type) {
+				return fmt.Errorf("metadata.sinks[%d]: unknown type %q: must be one of %v", i, s.Type, metadata.ListSinks())
+			}
+			if s.URL == "" {
+				return fmt.Errorf("metadata.sinks[%d]: url is required", i)
+			}
+		}
+	}
+
 	// Only validate directory existence if we're running a command that needs it
 	// This allows help commands to work without a valid directory
 	return nil
 }
 
+// validateLayers checks that layers are named uniquely and only depend on
+// declared layers or raw sources.
+func validateLayers(layers []core.LayerConfig) error {
+	seen := make(map[string]bool, len(layers))
+	for i, l := range layers {
+		if l.Name == "" {
+			return fmt.Errorf("layers[%d]: name is required", i)
+		}
+		if l.Name == core.SourceLayer || l.Name == string(core.ModelTypeOther) {
+			return fmt.Errorf("layers[%d]: %q is a reserved layer name", i, l.Name)
+		}
+		if seen[l.Name] {
+			return fmt.Errorf("layers[%d]: duplicate layer name %q", i, l.Name)
+		}
+		seen[l.Name] = true
+	}
+	for i, l := range layers {
+		for _, d := range l.DependsOn {
+			if d != core.SourceLayer && !seen[d] {
+				return fmt.Errorf("layers[%d]: depends_on references unknown layer %q", i, d)
+			}
+		}
+	}
+	return nil
+}
+
 // ValidateDirectories checks if required directories exist.
 func (c *Config) ValidateDirectories() error {
 	if _, err := os.Stat(c.ModelsDir); os.IsNotExist(err) {
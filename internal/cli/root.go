@@ -15,6 +15,7 @@ import (
 	"github.com/leapstack-labs/leapsql/internal/engine"
 	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
 	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/redact"
 	"github.com/spf13/cobra"
 )
 
@@ -52,6 +53,10 @@ then execute them in the correct order with state tracking and lineage.`,
 			if cmd.Name() == "help" || cmd.Name() == "completion" || cmd.Name() == "__complete" {
 				return nil
 			}
+			// validate loads the config itself to report config errors with their position
+			if cmd.Name() == "validate" {
+				return nil
+			}
 
 			// Load configuration with optional target override and CLI flags
 			var err error
@@ -75,8 +80,9 @@ then execute them in the correct order with state tracking and lineage.`,
 				level = slog.LevelDebug
 			}
 
-			// Create logger - always writes to stderr (data goes to stdout)
-			handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})
+			// Create logger - always writes to stderr (data goes to stdout),
+			// with resolved credentials masked
+			handler := redact.NewHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
 			logger := slog.New(handler)
 			ctx = context.WithValue(ctx, config.LoggerKey(), logger)
 			cmd.SetContext(ctx)
@@ -129,17 +135,37 @@ Built with Go and DuckDB
 	rootCmd.AddCommand(commands.NewRunCommand())
 	rootCmd.AddCommand(commands.NewListCommand())
 	rootCmd.AddCommand(commands.NewLineageCommand())
+	rootCmd.AddCommand(commands.NewTraceCommand())
 	rootCmd.AddCommand(commands.NewRenderCommand())
+	rootCmd.AddCommand(commands.NewPreviewCommand())
+	rootCmd.AddCommand(commands.NewParseCommand())
 	rootCmd.AddCommand(commands.NewSeedCommand())
+	rootCmd.AddCommand(commands.NewCloneCommand())
+	rootCmd.AddCommand(commands.NewBackfillCommand())
+	rootCmd.AddCommand(commands.NewDiffCommand())
+	rootCmd.AddCommand(commands.NewStateCommand())
+	rootCmd.AddCommand(commands.NewStatsCommand())
 	rootCmd.AddCommand(commands.NewDAGCommand())
+	rootCmd.AddCommand(commands.NewFreshnessCommand())
+	rootCmd.AddCommand(commands.NewAuditCommand())
 	rootCmd.AddCommand(commands.NewDiscoverCommand())
 	rootCmd.AddCommand(commands.NewLSPCommand())
 	rootCmd.AddCommand(commands.NewInitCommand())
 	rootCmd.AddCommand(commands.NewLintCommand())
+	rootCmd.AddCommand(commands.NewFmtCommand())
+	rootCmd.AddCommand(commands.NewPruneCommand())
+	rootCmd.AddCommand(commands.NewRefactorCommand())
 	rootCmd.AddCommand(commands.NewRulesCommand())
 	rootCmd.AddCommand(commands.NewDoctorCommand())
 	rootCmd.AddCommand(commands.NewQueryCommand())
+	rootCmd.AddCommand(commands.NewConsoleCommand())
 	rootCmd.AddCommand(commands.NewUICommand())
+	rootCmd.AddCommand(commands.NewServeCommand())
+	rootCmd.AddCommand(commands.NewExportCommand())
+	rootCmd.AddCommand(commands.NewSchemaCommand())
+	rootCmd.AddCommand(commands.NewMetadataCommand())
+	rootCmd.AddCommand(commands.NewImportCommand())
+	rootCmd.AddCommand(commands.NewValidateCommand())
 	rootCmd.AddCommand(NewCompletionCommand())
 
 	return rootCmd
@@ -149,7 +175,7 @@ Built with Go and DuckDB
 func Execute() error {
 	rootCmd := NewRootCmd()
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %s\n", redact.String(err.Error()))
 		return err
 	}
 	return nil
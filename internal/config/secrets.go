@@ -0,0 +1,234 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/redact"
+)
+
+// secretCommandTimeout bounds how long the secret manager command may run.
+const secretCommandTimeout = 30 * time.Second
+
+var (
+	// env_var('NAME'), env_var('NAME', 'default') and secret('name'), with
+	// single or double quotes, optionally wrapped in {{ }}
+	secretRefPattern = regexp.MustCompile(`(?:\{\{\s*)?\b(env_var|secret)\(\s*(?:'([^']*)'|"([^"]*)")\s*(?:,\s*(?:'([^']*)'|"([^"]*)")\s*)?\)(?:\s*\}\})?`)
+	// ${VAR}
+	envVarPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+	// Option and param keys whose values are always redacted
+	sensitiveKeyPattern = regexp.MustCompile(`(?i)password|secret|token|private_key|credential`)
+)
+
+// ResolveSecrets resolves the references in the string fields, options and
+// params of a target, so that config files never store plaintext credentials:
+//
+//   - env_var('NAME') is the value of an environment variable; it is an error
+//     if the variable is unset, unless a default is given as in
+//     env_var('NAME', 'default')
+//   - secret('name') is fetched from the secret manager configured in secrets
+//   - ${NAME} is the value of an environment variable, left as is if unset
+//
+// Values fetched with secret() and the values of the password field and of
+// password-like options and params are registered with package redact, so
+// they are masked in logs and artifacts.
+func ResolveSecrets(t *core.TargetConfig, secrets *core.SecretsConfig) error {
+	if t == nil {
+		return nil
+	}
+	r := &secretResolver{secrets: secrets, fetched: make(map[string]string)}
+
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"database", &t.Database},
+		{"host", &t.Host},
+		{"user", &t.User},
+		{"password", &t.Password},
+		{"schema", &t.Schema},
+		{"account", &t.Account},
+		{"warehouse", &t.Warehouse},
+		{"role", &t.Role},
+	}
+	for _, f := range fields {
+		resolved, err := r.resolve("target."+f.name, *f.value)
+		if err != nil {
+			return err
+		}
+		*f.value = resolved
+	}
+	redact.Add(t.Password)
+
+	for key, value := range t.Options {
+		resolved, err := r.resolve("target.options."+key, value)
+		if err != nil {
+			return err
+		}
+		t.Options[key] = resolved
+		if sensitiveKeyPattern.MatchString(key) {
+			redact.Add(resolved)
+		}
+	}
+
+	for key, value := range t.Params {
+		resolved, err := r.resolveAny("target.params."+key, value, sensitiveKeyPattern.MatchString(key))
+		if err != nil {
+			return err
+		}
+		t.Params[key] = resolved
+	}
+	return nil
+}
+
+// ResolveMetadataSecrets resolves the references in the URLs and tokens of
+// metadata sinks, like ResolveSecrets. Tokens are registered with package
+// redact.
+func ResolveMetadataSecrets(m *core.MetadataConfig, secrets *core.SecretsConfig) error {
+	if m == nil {
+		return nil
+	}
+	r := &secretResolver{secrets: secrets, fetched: make(map[string]string)}
+	for i := range m.Sinks {
+		sink := &m.Sinks[i]
+		url, err := r.resolve(fmt.Sprintf("metadata.sinks[%d].url", i), sink.URL)
+		if err != nil {
+			return err
+		}
+		token, err := r.resolve(fmt.Sprintf("metadata.sinks[%d].token", i), sink.Token)
+		if err != nil {
+			return err
+		}
+		sink.URL, sink.Token = url, token
+		redact.Add(token)
+	}
+	return nil
+}
+
+// secretResolver resolves the references of one config.
+type secretResolver struct {
+	secrets *core.SecretsConfig
+	fetched map[string]string // Secrets already fetched, by name
+}
+
+// resolveAny resolves the strings in a param value, descending into maps and
+// lists. sensitive reports whether the value is under a password-like key.
+func (r *secretResolver) resolveAny(path string, value any, sensitive bool) (any, error) {
+	switch v := value.(type) {
+	case string:
+		resolved, err := r.resolve(path, v)
+		if err != nil {
+			return nil, err
+		}
+		if sensitive {
+			redact.Add(resolved)
+		}
+		return resolved, nil
+	case map[string]any:
+		for key, item := range v {
+			resolved, err := r.resolveAny(path+"."+key, item, sensitive || sensitiveKeyPattern.MatchString(key))
+			if err != nil {
+				return nil, err
+			}
+			v[key] = resolved
+		}
+		return v, nil
+	case []any:
+		for i, item := range v {
+			resolved, err := r.resolveAny(fmt.Sprintf("%s[%d]", path, i), item, sensitive)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+// resolve resolves the references in the value of a field, named by its
+// path in the config file (e.g. target.password).
+func (r *secretResolver) resolve(field, value string) (string, error) {
+	var resolveErr error
+	resolved := secretRefPattern.ReplaceAllStringFunc(value, func(match string) string {
+		if resolveErr != nil {
+			return match
+		}
+		m := secretRefPattern.FindStringSubmatch(match)
+		fn, arg := m[1], m[2]+m[3]
+		def, hasDefault := m[4]+m[5], m[4] != "" || m[5] != "" || strings.Contains(match, ",")
+
+		switch fn {
+		case "env_var":
+			if v, ok := os.LookupEnv(arg); ok {
+				return v
+			}
+			if hasDefault {
+				return def
+			}
+			resolveErr = fmt.Errorf("%s: env_var('%s'): environment variable %s is not set", field, arg, arg)
+			return match
+		default:
+			v, err := r.fetch(arg)
+			if err != nil {
+				resolveErr = fmt.Errorf("%s: secret('%s'): %w", field, arg, err)
+				return match
+			}
+			return v
+		}
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+
+	return envVarPattern.ReplaceAllStringFunc(resolved, func(match string) string {
+		if v := os.Getenv(match[2 : len(match)-1]); v != "" {
+			return v
+		}
+		return match
+	}), nil
+}
+
+// fetch returns a secret from the secret manager, running its command once
+// per secret name.
+func (r *secretResolver) fetch(name string) (string, error) {
+	if v, ok := r.fetched[name]; ok {
+		return v, nil
+	}
+	if r.secrets == nil || len(r.secrets.Command) == 0 {
+		return "", fmt.Errorf("no secret manager configured: set secrets.command in %s", ConfigFileName)
+	}
+
+	args := make([]string, len(r.secrets.Command))
+	for i, arg := range r.secrets.Command {
+		args[i] = strings.ReplaceAll(arg, "{name}", name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec // G204: the command comes from the project config
+	cmd.Env = append(os.Environ(), "LEAPSQL_SECRET_NAME="+name)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s failed: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("%s failed: %w", args[0], err)
+	}
+
+	secret := strings.TrimSuffix(strings.TrimSuffix(stdout.String(), "\n"), "\r")
+	redact.Add(secret)
+	r.fetched[name] = secret
+	return secret, nil
+}
@@ -43,5 +43,27 @@ func ValidateTarget(t *core.TargetConfig) error {
 		}
 	}
 
+	if !t.Transaction.IsValid() {
+		return fmt.Errorf("invalid target transaction %q: must be auto, always or never", t.Transaction)
+	}
+
+	if m := t.Masking; m != nil {
+		if !m.Mode.IsValid() {
+			return fmt.Errorf("invalid target masking mode %q: must be none, hash, null or policy", m.Mode)
+		}
+		if m.Mode == core.MaskingPolicy && m.Policy == "" {
+			return fmt.Errorf("target masking mode policy requires masking.policy")
+		}
+	}
+
+	if t.MaxConcurrentQueries < 0 {
+		return fmt.Errorf("invalid target max_concurrent_queries %d: must be a number of queries, or 0 for no limit", t.MaxConcurrentQueries)
+	}
+	for class, limit := range t.ResourceClasses {
+		if limit < 1 {
+			return fmt.Errorf("invalid target resource class %q limit %d: must be at least 1", class, limit)
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// WorkspaceFileName is the name of the workspace file.
+// It lives next to the workspace's leapsql.yaml and lists the member projects.
+const WorkspaceFileName = "leapsql.workspace.yaml"
+
+// projectNamePattern restricts project names to identifiers usable in ref() and model IDs.
+var projectNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LoadWorkspace loads the workspace file from the given directory.
+// Project paths are resolved relative to dir.
+// Returns nil, nil if no workspace file is found (not an error condition).
+func LoadWorkspace(dir string) (*core.WorkspaceConfig, error) {
+	path := filepath.Join(dir, WorkspaceFileName)
+	if _, err := os.Stat(path); err != nil {
+		return nil, nil //nolint:nilerr // Missing workspace file means single-project mode
+	}
+
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), yaml.Parser()); err != nil {
+		return nil, fmt.Errorf("error reading workspace file %s: %w", path, err)
+	}
+
+	var ws core.WorkspaceConfig
+	if err := k.Unmarshal("", &ws); err != nil {
+		return nil, fmt.Errorf("unable to decode workspace file %s: %w", path, err)
+	}
+
+	for i := range ws.Projects {
+		p := &ws.Projects[i]
+		if p.Path == "" {
+			p.Path = p.Name
+		}
+		if !filepath.IsAbs(p.Path) {
+			p.Path = filepath.Join(dir, p.Path)
+		}
+	}
+
+	if err := ValidateWorkspace(&ws); err != nil {
+		return nil, fmt.Errorf("invalid workspace file %s: %w", path, err)
+	}
+
+	return &ws, nil
+}
+
+// ValidateWorkspace checks that project names are unique identifiers
+// and that every project directory exists.
+func ValidateWorkspace(ws *core.WorkspaceConfig) error {
+	if len(ws.Projects) == 0 {
+		return fmt.Errorf("workspace must list at least one project")
+	}
+
+	seen := make(map[string]bool, len(ws.Projects))
+	for _, p := range ws.Projects {
+		if !projectNamePattern.MatchString(p.Name) {
+			return fmt.Errorf("invalid project name %q: use letters, digits, and underscores", p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("duplicate project name %q", p.Name)
+		}
+		seen[p.Name] = true
+
+		info, err := os.Stat(p.Path)
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("project %q: directory %s not found", p.Name, p.Path)
+		}
+	}
+
+	return nil
+}
+
+// ProjectDirs returns the models, seeds, and macros directories of a workspace project.
+// A leapsql.yaml in the project directory may override the default directory names.
+func ProjectDirs(p core.WorkspaceProject) (modelsDir, seedsDir, macrosDir string, err error) {
+	cfg, err := LoadFromDir(p.Path)
+	if err != nil {
+		return "", "", "", fmt.Errorf("project %q: %w", p.Name, err)
+	}
+	if cfg == nil {
+		cfg = &core.ProjectConfig{}
+		ApplyDefaults(cfg)
+	}
+
+	resolve := func(dir string) string {
+		if filepath.IsAbs(dir) {
+			return dir
+		}
+		return filepath.Join(p.Path, dir)
+	}
+
+	return resolve(cfg.ModelsDir), resolve(cfg.SeedsDir), resolve(cfg.MacrosDir), nil
+}
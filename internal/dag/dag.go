@@ -204,6 +204,62 @@ func (g *Graph) TopologicalSort() ([]*Node, error) {
 	return result, nil
 }
 
+// ScheduleOrder returns nodes in a topological order that, among the nodes
+// whose dependencies come earlier, puts first the one heading the heaviest
+// remaining chain: its weight plus the heaviest chain of its descendants.
+// Starting long chains first keeps them from finishing last when nodes are
+// built in parallel. Nodes missing from weights weigh 0; ties go to the
+// alphabetically first node.
+// Returns an error if the graph contains a cycle.
+func (g *Graph) ScheduleOrder(weights map[string]int64) ([]*Node, error) {
+	sorted, err := g.TopologicalSort()
+	if err != nil {
+		return nil, err
+	}
+
+	// Heaviest chain starting at each node, children first
+	rank := make(map[string]int64, len(sorted))
+	for i := len(sorted) - 1; i >= 0; i-- {
+		id := sorted[i].ID
+		var heaviest int64
+		for _, childID := range g.edges[id] {
+			heaviest = max(heaviest, rank[childID])
+		}
+		rank[id] = weights[id] + heaviest
+	}
+
+	waiting := make(map[string]int, len(sorted)) // Node -> parents not yet scheduled
+	var ready []string
+	for _, node := range sorted {
+		waiting[node.ID] = len(g.parents[node.ID])
+		if waiting[node.ID] == 0 {
+			ready = append(ready, node.ID)
+		}
+	}
+
+	result := make([]*Node, 0, len(sorted))
+	for len(ready) > 0 {
+		next := 0
+		for i, id := range ready {
+			if rank[id] > rank[ready[next]] || (rank[id] == rank[ready[next]] && id < ready[next]) {
+				next = i
+			}
+		}
+		id := ready[next]
+		ready = append(ready[:next], ready[next+1:]...)
+		result = append(result, g.nodes[id])
+
+		for _, childID := range g.edges[id] {
+			waiting[childID]--
+			if waiting[childID] == 0 {
+				ready = append(ready, childID)
+			}
+		}
+	}
+
+	return result, nil
+}
+
 // GetExecutionLevels returns nodes grouped by execution level.
 // Nodes at level N can be executed in parallel after level N-1 completes.
 // Level 0 contains nodes with no dependencies.
@@ -322,6 +378,101 @@ func (g *Graph) GetUpstreamNodes(id string) []string {
 	return result
 }
 
+// GetAncestors returns the nodes upstream of a node up to a number of edges
+// away (all of them if depth is 0 or less), sorted.
+func (g *Graph) GetAncestors(id string, depth int) []string {
+	return g.walk(id, depth, g.parents)
+}
+
+// GetDescendants returns the nodes downstream of a node up to a number of
+// edges away (all of them if depth is 0 or less), sorted.
+func (g *Graph) GetDescendants(id string, depth int) []string {
+	return g.walk(id, depth, g.edges)
+}
+
+// walk collects the nodes reachable from a node through the given adjacency,
+// breadth first, so each node is reached at its shortest distance.
+func (g *Graph) walk(id string, depth int, next map[string][]string) []string {
+	seen := map[string]bool{id: true}
+	var result []string
+	frontier := []string{id}
+	for d := 1; len(frontier) > 0 && (depth <= 0 || d <= depth); d++ {
+		var following []string
+		for _, nodeID := range frontier {
+			for _, n := range next[nodeID] {
+				if !seen[n] {
+					seen[n] = true
+					result = append(result, n)
+					following = append(following, n)
+				}
+			}
+		}
+		frontier = following
+	}
+	sort.Strings(result)
+	return result
+}
+
+// IsReachable returns true if to is downstream of from, i.e. to depends on
+// from directly or transitively.
+func (g *Graph) IsReachable(from, to string) bool {
+	return contains(g.GetDescendants(from, 0), to)
+}
+
+// CriticalPath returns the chain of dependent nodes with the highest total
+// weight, from a root to the node that finishes last, and that total. It is
+// the shortest a run can take however many nodes run in parallel, so it
+// shows where speeding nodes up shortens the run. Nodes missing from
+// weights weigh 0. Ties go to the longer chain, then to the alphabetically
+// first node.
+// Returns an error if the graph contains a cycle.
+func (g *Graph) CriticalPath(weights map[string]int64) ([]string, int64, error) {
+	nodes, err := g.TopologicalSort()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(nodes) == 0 {
+		return nil, 0, nil
+	}
+
+	total := make(map[string]int64, len(nodes)) // Heaviest chain ending at the node
+	length := make(map[string]int, len(nodes))  // Number of nodes in that chain
+	prev := make(map[string]string, len(nodes))
+	heavier := func(a, b string) bool {
+		if total[a] != total[b] {
+			return total[a] > total[b]
+		}
+		if length[a] != length[b] {
+			return length[a] > length[b]
+		}
+		return a < b
+	}
+
+	var last string
+	for _, node := range nodes {
+		id := node.ID
+		for _, parentID := range g.parents[id] {
+			if p, ok := prev[id]; !ok || heavier(parentID, p) {
+				prev[id] = parentID
+			}
+		}
+		total[id], length[id] = weights[id], 1
+		if parentID, ok := prev[id]; ok {
+			total[id] += total[parentID]
+			length[id] += length[parentID]
+		}
+		if last == "" || heavier(id, last) {
+			last = id
+		}
+	}
+
+	path := []string{last}
+	for id, ok := prev[last]; ok; id, ok = prev[id] {
+		path = append([]string{id}, path...)
+	}
+	return path, total[last], nil
+}
+
 // GetRoots returns nodes with no parents (no dependencies).
 func (g *Graph) GetRoots() []string {
 	var roots []string
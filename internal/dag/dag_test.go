@@ -1,6 +1,7 @@
 package dag
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -267,6 +268,60 @@ func TestGraph_TopologicalSort(t *testing.T) {
 	}
 }
 
+func TestGraph_ScheduleOrder(t *testing.T) {
+	g := NewGraph()
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		g.AddNode(id, nil)
+	}
+
+	// a -> b; c -> d -> e
+	_ = g.AddEdge("a", "b")
+	_ = g.AddEdge("c", "d")
+	_ = g.AddEdge("d", "e")
+
+	tests := []struct {
+		name    string
+		weights map[string]int64
+		want    []string
+	}{
+		{
+			name: "no weights",
+			want: []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name:    "longest chain first",
+			weights: map[string]int64{"a": 10, "b": 10, "c": 5, "d": 5, "e": 20},
+			want:    []string{"c", "d", "a", "e", "b"},
+		},
+		{
+			name:    "slow node gets ahead once ready",
+			weights: map[string]int64{"a": 1, "b": 100, "c": 5, "d": 5, "e": 5},
+			want:    []string{"a", "b", "c", "d", "e"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes, err := g.ScheduleOrder(tt.weights)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got []string
+			for _, n := range nodes {
+				got = append(got, n.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	_ = g.AddEdge("e", "c")
+	if _, err := g.ScheduleOrder(nil); err == nil {
+		t.Error("expected error for cyclic graph")
+	}
+}
+
 func TestGraph_GetExecutionLevels(t *testing.T) {
 	g := NewGraph()
 	g.AddNode("raw1", nil)
@@ -355,6 +410,128 @@ func TestGraph_GetUpstreamNodes(t *testing.T) {
 	}
 }
 
+func TestGraph_GetAncestorsAndDescendants(t *testing.T) {
+	g := NewGraph()
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		g.AddNode(id, nil)
+	}
+
+	// a -> b -> c -> d, and a -> d directly; e is independent
+	_ = g.AddEdge("a", "b")
+	_ = g.AddEdge("b", "c")
+	_ = g.AddEdge("c", "d")
+	_ = g.AddEdge("a", "d")
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"ancestors unlimited", g.GetAncestors("d", 0), []string{"a", "b", "c"}},
+		{"ancestors depth 1", g.GetAncestors("d", 1), []string{"a", "c"}},
+		{"ancestors depth 2", g.GetAncestors("d", 2), []string{"a", "b", "c"}},
+		{"ancestors of root", g.GetAncestors("a", 0), nil},
+		{"descendants unlimited", g.GetDescendants("a", 0), []string{"b", "c", "d"}},
+		{"descendants depth 1", g.GetDescendants("a", 1), []string{"b", "d"}},
+		{"descendants of leaf", g.GetDescendants("e", 0), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, tt.got)
+			}
+		})
+	}
+
+	if !g.IsReachable("a", "c") {
+		t.Error("expected c to be reachable from a")
+	}
+	if g.IsReachable("c", "a") {
+		t.Error("expected a not to be reachable from c")
+	}
+	if g.IsReachable("a", "e") {
+		t.Error("expected e not to be reachable from a")
+	}
+}
+
+func TestGraph_CriticalPath(t *testing.T) {
+	g := NewGraph()
+	for _, id := range []string{"raw", "stg_orders", "stg_users", "fct_orders", "report"} {
+		g.AddNode(id, nil)
+	}
+
+	// raw feeds both staging models, which both feed fct_orders
+	_ = g.AddEdge("raw", "stg_orders")
+	_ = g.AddEdge("raw", "stg_users")
+	_ = g.AddEdge("stg_orders", "fct_orders")
+	_ = g.AddEdge("stg_users", "fct_orders")
+	_ = g.AddEdge("fct_orders", "report")
+
+	tests := []struct {
+		name      string
+		weights   map[string]int64
+		wantPath  []string
+		wantTotal int64
+	}{
+		{
+			name:      "slowest branch",
+			weights:   map[string]int64{"raw": 10, "stg_orders": 500, "stg_users": 20, "fct_orders": 100, "report": 5},
+			wantPath:  []string{"raw", "stg_orders", "fct_orders", "report"},
+			wantTotal: 615,
+		},
+		{
+			name:      "other branch",
+			weights:   map[string]int64{"raw": 10, "stg_orders": 20, "stg_users": 500, "fct_orders": 100, "report": 5},
+			wantPath:  []string{"raw", "stg_users", "fct_orders", "report"},
+			wantTotal: 615,
+		},
+		{
+			name:      "missing weights count as zero",
+			weights:   map[string]int64{"stg_users": 30},
+			wantPath:  []string{"raw", "stg_users", "fct_orders", "report"},
+			wantTotal: 30,
+		},
+		{
+			name:      "ties go to the longer chain, then the first node",
+			weights:   nil,
+			wantPath:  []string{"raw", "stg_orders", "fct_orders", "report"},
+			wantTotal: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, total, err := g.CriticalPath(tt.weights)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(path, tt.wantPath) {
+				t.Errorf("expected path %v, got %v", tt.wantPath, path)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("expected total %d, got %d", tt.wantTotal, total)
+			}
+		})
+	}
+
+	// An independent node heavier than the whole chain is the critical path
+	g.AddNode("backfill", nil)
+	path, total, err := g.CriticalPath(map[string]int64{"backfill": 1000, "raw": 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(path, []string{"backfill"}) || total != 1000 {
+		t.Errorf("expected [backfill] with total 1000, got %v with total %d", path, total)
+	}
+
+	// Cycles are rejected
+	_ = g.AddEdge("report", "raw")
+	if _, _, err := g.CriticalPath(nil); err == nil {
+		t.Error("expected error for cyclic graph")
+	}
+}
+
 func TestGraph_GetRootsAndLeaves(t *testing.T) {
 	tests := []struct {
 		name       string
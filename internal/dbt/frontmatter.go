@@ -0,0 +1,243 @@
+package dbt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// frontmatter is the LeapSQL frontmatter written for an imported model.
+type frontmatter struct {
+	Description  string         `yaml:"description,omitempty"`
+	Materialized string         `yaml:"materialized"`
+	UniqueKey    string         `yaml:"unique_key,omitempty"`
+	Group        string         `yaml:"group,omitempty"`
+	Access       string         `yaml:"access,omitempty"`
+	Tags         []string       `yaml:"tags,omitempty,flow"`
+	Enabled      *bool          `yaml:"enabled,omitempty"`
+	FullRefresh  *bool          `yaml:"full_refresh,omitempty"`
+	PartitionBy  *partitionBy   `yaml:"partition_by,omitempty"`
+	Tests        []testEntry    `yaml:"tests,omitempty"`
+	Meta         map[string]any `yaml:"meta,omitempty"`
+}
+
+// testEntry is one entry of the frontmatter tests list.
+type testEntry struct {
+	Unique         []string        `yaml:"unique,omitempty,flow"`
+	NotNull        []string        `yaml:"not_null,omitempty,flow"`
+	AcceptedValues *acceptedValues `yaml:"accepted_values,omitempty"`
+}
+
+type partitionBy struct {
+	Column string `yaml:"column"`
+	Grain  string `yaml:"grain"`
+}
+
+type acceptedValues struct {
+	Column string   `yaml:"column"`
+	Values []string `yaml:"values,flow"`
+}
+
+// ignoredConfigs only affect dbt's documentation or are defaults.
+var ignoredConfigs = map[string]bool{
+	"docs": true, "persist_docs": true, "description": true,
+}
+
+// frontmatter builds a model's frontmatter from the configs of
+// dbt_project.yml, its properties file and its config() calls, in order of
+// increasing precedence, and from the tests and descriptions of its
+// properties.
+func (im *importer) frontmatter(m dbtModel, inline map[string]any) *frontmatter {
+	fm := &frontmatter{}
+	props, hasProps := im.props[m.Name]
+	propsFile := im.propsFiles[m.Name]
+
+	im.applyConfig(fm, projectConfig(im.p, m.Dir), ProjectFile)
+	if hasProps {
+		fm.Description = props.Description
+		fm.Group = props.Group
+		fm.Access = props.Access
+		im.applyConfig(fm, props.Config, propsFile)
+	}
+	im.applyConfig(fm, inline, m.File)
+
+	if fm.Materialized == "" {
+		fm.Materialized = "view" // dbt's default
+	}
+	if !hasProps {
+		return fm
+	}
+
+	if strings.Contains(fm.Description, "doc(") {
+		im.report(propsFile, 0, "doc() blocks in the description of %s are not converted", m.Name)
+	}
+	if len(props.Versions) > 0 {
+		im.report(propsFile, 0, "versions of %s are not converted; only the model file is imported", m.Name)
+	}
+
+	var notNull []string
+	descriptions := make(map[string]any)
+	for _, col := range props.Columns {
+		if col.Description != "" {
+			descriptions[col.Name] = col.Description
+		}
+		for _, test := range append(col.Tests, col.DataTests...) {
+			name, args := testNameArgs(test)
+			switch name {
+			case "unique":
+				fm.Tests = append(fm.Tests, testEntry{Unique: []string{col.Name}})
+			case "not_null":
+				notNull = append(notNull, col.Name)
+			case "accepted_values":
+				values := toStrings(args["values"])
+				if len(values) == 0 {
+					im.report(propsFile, 0, "accepted_values test on %s.%s has no values", m.Name, col.Name)
+					continue
+				}
+				fm.Tests = append(fm.Tests, testEntry{AcceptedValues: &acceptedValues{Column: col.Name, Values: values}})
+			default:
+				im.report(propsFile, 0, "%s test on %s.%s not converted", name, m.Name, col.Name)
+			}
+		}
+	}
+	if len(notNull) > 0 {
+		fm.Tests = append(fm.Tests, testEntry{NotNull: notNull})
+	}
+
+	for _, test := range append(props.Tests, props.DataTests...) {
+		name, args := testNameArgs(test)
+		columns := toStrings(args["combination_of_columns"])
+		if strings.HasSuffix(name, "unique_combination_of_columns") && len(columns) > 0 {
+			fm.Tests = append(fm.Tests, testEntry{Unique: columns})
+			continue
+		}
+		im.report(propsFile, 0, "%s test on %s not converted", name, m.Name)
+	}
+
+	if len(descriptions) > 0 {
+		if fm.Meta == nil {
+			fm.Meta = make(map[string]any)
+		}
+		fm.Meta["column_descriptions"] = descriptions
+	}
+	return fm
+}
+
+// applyConfig applies dbt model configs set in file to the frontmatter,
+// reporting the configs LeapSQL does not support.
+func (im *importer) applyConfig(fm *frontmatter, cfg map[string]any, file string) {
+	keys := make([]string, 0, len(cfg))
+	for key := range cfg {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := cfg[key]
+		switch strings.ReplaceAll(key, "-", "_") {
+		case "materialized":
+			fm.Materialized = im.materialization(fmt.Sprint(value), file)
+		case "unique_key":
+			keys := toStrings(value)
+			switch len(keys) {
+			case 1:
+				fm.UniqueKey = keys[0]
+			default:
+				im.report(file, 0, "composite unique_key %v not converted; LeapSQL merges on a single column", keys)
+			}
+		case "tags":
+			fm.Tags = appendUnique(fm.Tags, toStrings(value)...)
+		case "enabled":
+			if b, ok := value.(bool); ok {
+				fm.Enabled = &b
+			}
+		case "full_refresh":
+			if b, ok := value.(bool); ok {
+				fm.FullRefresh = &b
+			}
+		case "partition_by":
+			fm.PartitionBy = im.partitionBy(value, file)
+		case "meta":
+			if meta, ok := value.(map[string]any); ok {
+				if fm.Meta == nil {
+					fm.Meta = make(map[string]any)
+				}
+				for k, v := range meta {
+					fm.Meta[k] = v
+				}
+			}
+		case "group":
+			fm.Group = fmt.Sprint(value)
+		case "access":
+			fm.Access = fmt.Sprint(value)
+		case "schema", "database", "alias":
+			im.report(file, 0, "%s config not converted; LeapSQL names tables after the model's directory", key)
+		case "pre_hook", "post_hook":
+			im.report(file, 0, "%s not converted; hooks are not supported", key)
+		case "incremental_strategy":
+			if s := fmt.Sprint(value); s != "merge" && s != "delete+insert" && s != "append" && s != "insert_overwrite" {
+				im.report(file, 0, "incremental_strategy %s not converted; LeapSQL merges on unique_key, overwrites partition_by partitions or appends", s)
+			}
+		case "on_schema_change":
+			if s := fmt.Sprint(value); s != "ignore" {
+				im.report(file, 0, "on_schema_change %s not converted", s)
+			}
+		default:
+			if !ignoredConfigs[key] {
+				im.report(file, 0, "%s config not converted", key)
+			}
+		}
+	}
+}
+
+// partitionBy converts a dbt partition_by config: a field truncated to a
+// granularity, as in BigQuery. Partitions by a list of columns are reported.
+func (im *importer) partitionBy(value any, file string) *partitionBy {
+	cfg, ok := value.(map[string]any)
+	if !ok || cfg["field"] == nil {
+		im.report(file, 0, "partition_by %v not converted; LeapSQL partitions by a date column", value)
+		return nil
+	}
+	p := &partitionBy{Column: fmt.Sprint(cfg["field"])}
+	if g, ok := cfg["granularity"]; ok {
+		switch grain := strings.ToLower(fmt.Sprint(g)); grain {
+		case "hour", "day", "month", "year":
+			p.Grain = grain
+		default:
+			im.report(file, 0, "partition_by granularity %s not converted; partitioned by day", grain)
+		}
+	}
+	return p
+}
+
+// materialization maps a dbt materialization to a LeapSQL one.
+func (im *importer) materialization(m, file string) string {
+	switch m {
+	case "table", "view", "incremental":
+		return m
+	case "ephemeral":
+		im.report(file, 0, "ephemeral models are imported as views")
+	default:
+		im.report(file, 0, "%s materialization not supported; imported as a view", m)
+	}
+	return "view"
+}
+
+// testNameArgs returns the name and arguments of a test entry, given as a
+// name or as a map from the name to its arguments. Arguments nested under
+// "arguments" (dbt 1.10+) are flattened.
+func testNameArgs(test any) (string, map[string]any) {
+	switch t := test.(type) {
+	case string:
+		return t, nil
+	case map[string]any:
+		for name, v := range t {
+			args, _ := v.(map[string]any)
+			if nested, ok := args["arguments"].(map[string]any); ok {
+				args = nested
+			}
+			return name, args
+		}
+	}
+	return fmt.Sprint(test), nil
+}
@@ -0,0 +1,409 @@
+package dbt
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is the name of the LeapSQL project file written by Import.
+const ConfigFile = "leapsql.yaml"
+
+// Options configures an import.
+type Options struct {
+	Force bool // Overwrite an existing LeapSQL project in the output directory
+}
+
+// Issue is a dbt feature that was not converted and needs manual work.
+type Issue struct {
+	File    string `json:"file"`           // Path relative to the dbt project
+	Line    int    `json:"line,omitempty"` // 0 when not tied to a line
+	Message string `json:"message"`
+}
+
+// Result summarizes an import.
+type Result struct {
+	Project string   `json:"project"` // dbt project name
+	Models  []string `json:"models"`  // Model paths written
+	Seeds   []string `json:"seeds"`   // Seed tables copied
+	Files   []string `json:"files"`   // Files written, relative to the output directory
+	Issues  []Issue  `json:"issues"`
+}
+
+// dbtModel is a SQL model found in a model path.
+type dbtModel struct {
+	Name string // dbt model name (file name)
+	File string // Slash-separated path relative to the dbt project
+	Dir  string // Slash-separated directory relative to its model path
+	Path string // LeapSQL model path, e.g. staging.stg_orders
+}
+
+// importer holds the state of one import.
+type importer struct {
+	dbtDir string
+	outDir string
+	p      *project
+	result *Result
+	seen   map[Issue]bool
+
+	models     []dbtModel
+	seeds      map[string]string // Seed table -> file relative to the dbt project
+	props      map[string]modelProperties
+	propsFiles map[string]string // Model name -> properties file
+	sources    map[[2]string]string
+	groups     []groupProperties
+}
+
+// Import converts the dbt project in dbtDir into a LeapSQL project in outDir.
+// Models keep their directory layout, so a dbt model models/staging/stg_orders.sql
+// becomes the LeapSQL model staging.stg_orders. Features without a LeapSQL
+// equivalent are listed in the result's issues.
+func Import(dbtDir, outDir string, opts Options) (*Result, error) {
+	p, err := readProject(dbtDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filepath.Join(outDir, ConfigFile)); err == nil && !opts.Force {
+		return nil, fmt.Errorf("%s already exists in %s. Use --force to overwrite", ConfigFile, outDir)
+	}
+
+	im := &importer{
+		dbtDir:     dbtDir,
+		outDir:     outDir,
+		p:          p,
+		result:     &Result{Project: p.Name, Models: []string{}, Seeds: []string{}, Files: []string{}, Issues: []Issue{}},
+		seen:       make(map[Issue]bool),
+		seeds:      make(map[string]string),
+		props:      make(map[string]modelProperties),
+		propsFiles: make(map[string]string),
+		sources:    make(map[[2]string]string),
+	}
+
+	steps := []func() error{
+		im.scanModels,
+		im.scanSeeds,
+		im.writeModels,
+		im.writeSeeds,
+		im.writeConfig,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return nil, err
+		}
+	}
+	im.reportUnsupported()
+
+	sort.SliceStable(im.result.Issues, func(i, j int) bool {
+		a, b := im.result.Issues[i], im.result.Issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return im.result, nil
+}
+
+// report records an issue once.
+func (im *importer) report(file string, line int, format string, args ...any) {
+	issue := Issue{File: file, Line: line, Message: fmt.Sprintf(format, args...)}
+	if im.seen[issue] {
+		return
+	}
+	im.seen[issue] = true
+	im.result.Issues = append(im.result.Issues, issue)
+}
+
+// walk calls fn for the files under the project-relative directories dirs,
+// with slash-separated paths relative to the dbt project and to the directory.
+func (im *importer) walk(dirs []string, fn func(file, rel string) error) error {
+	for _, dir := range dirs {
+		root := filepath.Join(im.dbtDir, dir)
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			file, _ := filepath.Rel(im.dbtDir, p)
+			rel, _ := filepath.Rel(root, p)
+			return fn(filepath.ToSlash(file), filepath.ToSlash(rel))
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// scanModels finds the SQL models and properties files in the model paths.
+func (im *importer) scanModels() error {
+	paths := make(map[string]string) // LeapSQL model path -> file
+	return im.walk(im.p.ModelPaths, func(file, rel string) error {
+		switch path.Ext(rel) {
+		case ".sql":
+			name := strings.TrimSuffix(path.Base(rel), ".sql")
+			dir := path.Dir(rel)
+			if dir == "." {
+				dir = ""
+			}
+			modelPath := strings.ReplaceAll(strings.TrimSuffix(rel, ".sql"), "/", ".")
+			if other, ok := paths[modelPath]; ok {
+				im.report(file, 0, "model not converted: %s is also imported as %s", other, modelPath)
+				return nil
+			}
+			paths[modelPath] = file
+			im.models = append(im.models, dbtModel{Name: name, File: file, Dir: dir, Path: modelPath})
+		case ".yml", ".yaml":
+			return im.readProperties(file)
+		case ".py":
+			im.report(file, 0, "Python models are not supported; rewrite the model in SQL")
+		}
+		return nil
+	})
+}
+
+// scanSeeds finds the CSV seeds and properties files in the seed paths.
+// LeapSQL loads seeds from a single directory, so nested seeds are flattened.
+func (im *importer) scanSeeds() error {
+	err := im.walk(im.p.SeedPaths, func(file, rel string) error {
+		switch path.Ext(rel) {
+		case ".csv":
+			table := strings.TrimSuffix(path.Base(rel), ".csv")
+			if other, ok := im.seeds[table]; ok {
+				im.report(file, 0, "seed not copied: %s also loads table %s", other, table)
+				return nil
+			}
+			im.seeds[table] = file
+		case ".yml", ".yaml":
+			return im.readProperties(file)
+		}
+		return nil
+	})
+	if len(im.p.Seeds) > 0 {
+		im.report(ProjectFile, 0, "seed configs are not converted; LeapSQL infers seed column types")
+	}
+	return err
+}
+
+// readProperties reads a properties file, keeping model properties, sources
+// and groups and reporting the rest.
+func (im *importer) readProperties(file string) error {
+	props, err := readProperties(filepath.Join(im.dbtDir, file))
+	if err != nil {
+		return err
+	}
+
+	for _, m := range props.Models {
+		im.props[m.Name] = m
+		im.propsFiles[m.Name] = file
+	}
+	for _, src := range props.Sources {
+		schema := src.Schema
+		if schema == "" {
+			schema = src.Name
+		}
+		if src.Database != "" {
+			schema = src.Database + "." + schema
+		}
+		for _, t := range src.Tables {
+			identifier := t.Identifier
+			if identifier == "" {
+				identifier = t.Name
+			}
+			im.sources[[2]string{src.Name, t.Name}] = schema + "." + identifier
+		}
+	}
+	im.groups = append(im.groups, props.Groups...)
+
+	unsupported := []struct {
+		count int
+		kind  string
+	}{
+		{len(props.Seeds), "seed properties"},
+		{len(props.Snapshots), "snapshots"},
+		{len(props.Exposures), "exposures"},
+		{len(props.Metrics), "metrics"},
+		{len(props.SemanticModels), "semantic models"},
+		{len(props.UnitTests), "unit tests"},
+	}
+	for _, u := range unsupported {
+		if u.count > 0 {
+			im.report(file, 0, "%s not converted (%d)", u.kind, u.count)
+		}
+	}
+	return nil
+}
+
+// writeModels converts each model and writes it with its frontmatter.
+func (im *importer) writeModels() error {
+	r := &resolver{tables: make(map[string]string), sources: im.sources, vars: im.p.Vars}
+	for table := range im.seeds {
+		r.tables[table] = table
+	}
+	for _, m := range im.models {
+		r.tables[m.Name] = m.Path
+	}
+
+	sort.Slice(im.models, func(i, j int) bool { return im.models[i].Path < im.models[j].Path })
+	for _, m := range im.models {
+		content, err := os.ReadFile(filepath.Join(im.dbtDir, m.File)) //nolint:gosec // G304: path comes from walking the dbt project
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", m.File, err)
+		}
+
+		converted := r.convertSQL(string(content), m.Path)
+		for _, issue := range converted.Issues {
+			im.report(m.File, issue.Line, "%s", issue.Message)
+		}
+
+		fm := im.frontmatter(m, converted.Config)
+		header, err := marshalYAML(fm)
+		if err != nil {
+			return fmt.Errorf("failed to write frontmatter of %s: %w", m.File, err)
+		}
+
+		out := path.Join("models", m.Dir, m.Name+".sql")
+		if err := im.writeFile(out, []byte("/*---\n"+header+"---*/\n"+converted.SQL)); err != nil {
+			return err
+		}
+		im.result.Models = append(im.result.Models, m.Path)
+	}
+	return nil
+}
+
+// writeSeeds copies the seeds into the seeds directory.
+func (im *importer) writeSeeds() error {
+	tables := make([]string, 0, len(im.seeds))
+	for table := range im.seeds {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		content, err := os.ReadFile(filepath.Join(im.dbtDir, im.seeds[table])) //nolint:gosec // G304: path comes from walking the dbt project
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", im.seeds[table], err)
+		}
+		if err := im.writeFile("seeds/"+table+".csv", content); err != nil {
+			return err
+		}
+		im.result.Seeds = append(im.result.Seeds, table)
+	}
+	return nil
+}
+
+// writeConfig writes leapsql.yaml and creates the project directories.
+func (im *importer) writeConfig() error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# LeapSQL Configuration\n# Imported from dbt project %q by: leapsql import dbt\n\n", im.p.Name)
+	b.WriteString("models_dir: models\nseeds_dir: seeds\nmacros_dir: macros\nstate_path: .leapsql/state.db\nenvironment: dev\n\n")
+	if im.p.Profile != "" {
+		fmt.Fprintf(&b, "# Database target (dbt profile %q is not converted)\n", im.p.Profile)
+	} else {
+		b.WriteString("# Database target\n")
+	}
+	fmt.Fprintf(&b, "target:\n  type: duckdb\n  database: %s.duckdb\n  schema: main\n", im.p.Name)
+
+	if len(im.groups) > 0 {
+		b.WriteString("\ngroups:\n")
+		for _, g := range im.groups {
+			owner := g.Owner.Name
+			if owner == "" {
+				owner = g.Owner.Email
+			}
+			fmt.Fprintf(&b, "  - name: %s\n", g.Name)
+			if owner != "" {
+				fmt.Fprintf(&b, "    owner: %s\n", owner)
+			}
+		}
+	}
+
+	for _, dir := range []string{"models", "seeds", "macros"} {
+		if err := os.MkdirAll(filepath.Join(im.outDir, dir), 0750); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+	return im.writeFile(ConfigFile, []byte(b.String()))
+}
+
+// reportUnsupported reports the project features the importer skips.
+func (im *importer) reportUnsupported() {
+	if im.p.Profile != "" {
+		im.report(ProjectFile, 0, "connection profile %q not converted; set the target in %s", im.p.Profile, ConfigFile)
+	}
+	if im.p.OnRunStart != nil || im.p.OnRunEnd != nil {
+		im.report(ProjectFile, 0, "on-run-start and on-run-end hooks are not supported")
+	}
+	for _, name := range []string{"packages.yml", "dependencies.yml"} {
+		if _, err := os.Stat(filepath.Join(im.dbtDir, name)); err == nil {
+			im.report(name, 0, "packages are not installed; rewrite the package macros models call as Starlark macros")
+		}
+	}
+
+	_ = im.walk(im.p.MacroPaths, func(file, _ string) error {
+		if path.Ext(file) != ".sql" {
+			return nil
+		}
+		content, err := os.ReadFile(filepath.Join(im.dbtDir, file)) //nolint:gosec // G304: path comes from walking the dbt project
+		if err != nil {
+			return nil
+		}
+		for _, name := range macroNames(string(content)) {
+			im.report(file, 0, "macro %s not converted; rewrite it as a Starlark macro in macros/", name)
+		}
+		return nil
+	})
+
+	skipped := []struct {
+		dirs    []string
+		message string
+	}{
+		{im.p.SnapshotPaths, "snapshots are not supported"},
+		{im.p.TestPaths, "singular tests are not supported; add column tests to the model frontmatter"},
+		{im.p.AnalysisPaths, "analyses are not converted"},
+	}
+	for _, s := range skipped {
+		_ = im.walk(s.dirs, func(file, _ string) error {
+			if path.Ext(file) == ".sql" {
+				im.report(file, 0, "%s", s.message)
+			}
+			return nil
+		})
+	}
+}
+
+// writeFile writes a file relative to the output directory.
+func (im *importer) writeFile(rel string, content []byte) error {
+	target := filepath.Join(im.outDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", rel, err)
+	}
+	if err := os.WriteFile(target, content, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", rel, err)
+	}
+	im.result.Files = append(im.result.Files, rel)
+	return nil
+}
+
+// marshalYAML encodes v with the two-space indent used by model frontmatter.
+func marshalYAML(v any) (string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,214 @@
+package dbt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leapstack-labs/leapsql/internal/config"
+	"github.com/leapstack-labs/leapsql/internal/loader"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// writeDbtProject writes a small dbt project with staging and mart models,
+// a source, a seed, a macro and a snapshot.
+func writeDbtProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"dbt_project.yml": `name: shop
+profile: shop
+vars:
+  min_amount: 10
+models:
+  shop:
+    +materialized: view
+    marts:
+      +materialized: table
+      +tags: [marts]
+      +schema: analytics
+`,
+		"models/staging/sources.yml": `version: 2
+sources:
+  - name: raw
+    schema: raw_data
+    tables:
+      - name: payments
+`,
+		"models/staging/stg_orders.sql": `{{ config(tags=['orders']) }}
+select id, amount from {{ ref('raw_orders') }}
+where amount >= {{ var('min_amount') }}
+`,
+		"models/staging/stg_payments.sql": `select id, paid from {{ source('raw', 'payments') }}`,
+		"models/marts/orders.sql": `{{ config(materialized='incremental', unique_key='id') }}
+select o.id, o.amount, p.paid
+from {{ ref('stg_orders') }} o
+join {{ ref('stg_payments') }} p on p.id = o.id
+{% if is_incremental() %}
+where o.id > (select max(id) from {{ this }})
+{% endif %}
+`,
+		"models/marts/schema.yml": `version: 2
+groups:
+  - name: finance
+    owner:
+      email: finance@example.com
+models:
+  - name: orders
+    description: One row per order
+    group: finance
+    columns:
+      - name: id
+        description: Order ID
+        data_tests: [unique, not_null]
+      - name: paid
+        data_tests:
+          - accepted_values:
+              arguments:
+                values: [true, false]
+          - relationships:
+              to: ref('stg_payments')
+              field: id
+`,
+		"seeds/raw/raw_orders.csv": "id,amount\n1,10\n",
+		"macros/cents.sql":         "{% macro cents(x) %}{{ x }} * 100{% endmacro %}\n",
+		"snapshots/orders.sql":     "{% snapshot orders_snapshot %}select 1{% endsnapshot %}\n",
+		"models/marts/forecast.py": "def model(dbt, session): pass\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+	return dir
+}
+
+func TestImport(t *testing.T) {
+	dbtDir := writeDbtProject(t)
+	outDir := t.TempDir()
+
+	result, err := Import(dbtDir, outDir, Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "shop", result.Project)
+	assert.Equal(t, []string{"marts.orders", "staging.stg_orders", "staging.stg_payments"}, result.Models)
+	assert.Equal(t, []string{"raw_orders"}, result.Seeds)
+	assert.Contains(t, result.Files, "seeds/raw_orders.csv")
+
+	t.Run("models load with their frontmatter", func(t *testing.T) {
+		l := loader.NewLoader(filepath.Join(outDir, "models"), nil)
+
+		orders, err := l.ParseFile(filepath.Join(outDir, "models/marts/orders.sql"))
+		require.NoError(t, err)
+		assert.Equal(t, "marts.orders", orders.Path)
+		assert.Equal(t, "incremental", orders.Materialized)
+		assert.Equal(t, "id", orders.UniqueKey)
+		assert.Equal(t, "finance", orders.Group)
+		assert.Equal(t, []string{"marts"}, orders.Tags)
+		assert.Equal(t, []core.TestConfig{
+			{Unique: []string{"id"}},
+			{AcceptedValues: &core.AcceptedValuesConfig{Column: "paid", Values: []string{"true", "false"}}},
+			{NotNull: []string{"id"}},
+		}, orders.Tests)
+		assert.Equal(t, map[string]any{"id": "Order ID"}, orders.Meta["column_descriptions"])
+		assert.Contains(t, orders.SQL, "from staging.stg_orders o\njoin staging.stg_payments p")
+		require.Len(t, orders.Conditionals, 1)
+		assert.Equal(t, "is_incremental", orders.Conditionals[0].Condition)
+		assert.Equal(t, "where o.id > (select max(id) from {{ this }})\n", orders.Conditionals[0].Content)
+
+		content, err := os.ReadFile(filepath.Join(outDir, "models/marts/orders.sql"))
+		require.NoError(t, err)
+		fm, err := loader.ExtractFrontmatter(string(content))
+		require.NoError(t, err)
+		assert.Equal(t, "One row per order", fm.Config.Description)
+
+		stg, err := l.ParseFile(filepath.Join(outDir, "models/staging/stg_orders.sql"))
+		require.NoError(t, err)
+		assert.Equal(t, "view", stg.Materialized)
+		assert.Equal(t, []string{"orders"}, stg.Tags)
+		assert.Equal(t, "select id, amount from raw_orders\nwhere amount >= 10", stg.SQL)
+
+		payments, err := l.ParseFile(filepath.Join(outDir, "models/staging/stg_payments.sql"))
+		require.NoError(t, err)
+		assert.Equal(t, "select id, paid from raw_data.payments", payments.SQL)
+	})
+
+	t.Run("project config loads", func(t *testing.T) {
+		cfg, err := config.LoadFromDir(outDir)
+		require.NoError(t, err)
+		assert.Equal(t, "models", cfg.ModelsDir)
+		assert.Equal(t, []core.GroupConfig{{Name: "finance", Owner: "finance@example.com"}}, cfg.Groups)
+	})
+
+	t.Run("unconverted features are reported", func(t *testing.T) {
+		var messages []string
+		for _, issue := range result.Issues {
+			messages = append(messages, issue.File+": "+issue.Message)
+		}
+		assert.Equal(t, []string{
+			`dbt_project.yml: schema config not converted; LeapSQL names tables after the model's directory`,
+			`dbt_project.yml: connection profile "shop" not converted; set the target in leapsql.yaml`,
+			"macros/cents.sql: macro cents not converted; rewrite it as a Starlark macro in macros/",
+			"models/marts/forecast.py: Python models are not supported; rewrite the model in SQL",
+			"models/marts/schema.yml: relationships test on orders.paid not converted",
+			"snapshots/orders.sql: snapshots are not supported",
+		}, messages)
+	})
+}
+
+func TestImport_PartitionBy(t *testing.T) {
+	dbtDir := t.TempDir()
+	files := map[string]string{
+		"dbt_project.yml": "name: shop\n",
+		"models/events.sql": `{{ config(materialized='incremental', incremental_strategy='insert_overwrite',
+    partition_by={'field': 'event_date', 'data_type': 'date', 'granularity': 'month'}) }}
+select id, event_date from raw_events
+`,
+		"models/clicks.sql": `{{ config(materialized='table', partition_by=['region']) }}
+select id, region from raw_clicks
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dbtDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+	outDir := t.TempDir()
+
+	result, err := Import(dbtDir, outDir, Options{})
+	require.NoError(t, err)
+
+	l := loader.NewLoader(filepath.Join(outDir, "models"), nil)
+	events, err := l.ParseFile(filepath.Join(outDir, "models/events.sql"))
+	require.NoError(t, err)
+	assert.Equal(t, &core.PartitionSpec{Column: "event_date", Grain: core.PartitionGrainMonth}, events.PartitionBy)
+
+	var messages []string
+	for _, issue := range result.Issues {
+		messages = append(messages, issue.File+": "+issue.Message)
+	}
+	assert.Contains(t, messages, "models/clicks.sql: partition_by [region] not converted; LeapSQL partitions by a date column")
+}
+
+func TestImport_ExistingProject(t *testing.T) {
+	dbtDir := writeDbtProject(t)
+	outDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outDir, ConfigFile), []byte("models_dir: models\n"), 0600))
+
+	_, err := Import(dbtDir, outDir, Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+
+	_, err = Import(dbtDir, outDir, Options{Force: true})
+	require.NoError(t, err)
+}
+
+func TestImport_NotDbtProject(t *testing.T) {
+	_, err := Import(t.TempDir(), t.TempDir(), Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dbt_project.yml not found")
+}
@@ -0,0 +1,307 @@
+package dbt
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// thisPlaceholder is replaced by the model's table inside is_incremental
+// blocks when an incremental model runs.
+const thisPlaceholder = "{{ this }}"
+
+var (
+	// {{ expr }}, {% stmt %} and {# comment #}, with optional whitespace control
+	jinjaTagPattern = regexp.MustCompile(`(?s)\{\{-?(.*?)-?\}\}|\{%-?(.*?)-?%\}|\{#(.*?)#\}`)
+	// {% if is_incremental() %}
+	incrementalIfPattern = regexp.MustCompile(`^if\s+is_incremental\(\s*\)$`)
+	// Position prefix of starlark syntax and resolve errors
+	exprPositionPattern = regexp.MustCompile(`(?m)^model\.sql:\d+:\d+: `)
+	// {% macro name(...) %}
+	macroPattern = regexp.MustCompile(`\{%-?\s*macro\s+(\w+)`)
+)
+
+// resolver resolves the dbt functions called from model SQL.
+type resolver struct {
+	tables  map[string]string    // dbt model or seed name -> LeapSQL table
+	sources map[[2]string]string // (source, table) -> relation
+	vars    map[string]any       // Project vars from dbt_project.yml
+}
+
+// lineIssue is a construct of one model that could not be converted.
+type lineIssue struct {
+	Line    int
+	Message string
+}
+
+// convertedSQL is a model's SQL with its Jinja converted to LeapSQL.
+type convertedSQL struct {
+	SQL    string
+	Config map[string]any // Arguments of config() calls
+	Issues []lineIssue
+}
+
+// sqlConverter converts the Jinja of one model.
+type sqlConverter struct {
+	r           *resolver
+	table       string // LeapSQL table of the model, for {{ this }}
+	incremental bool   // Inside an is_incremental() block
+	config      map[string]any
+	globals     starlark.StringDict
+}
+
+// convertSQL converts the Jinja in a dbt model to LeapSQL: refs and sources
+// become table names, config() calls are collected, vars are inlined and
+// is_incremental() blocks become "-- #if is_incremental" conditionals.
+// Constructs that cannot be converted are kept verbatim and reported.
+func (r *resolver) convertSQL(content, table string) *convertedSQL {
+	c := &sqlConverter{r: r, table: table, config: make(map[string]any)}
+	c.globals = c.predeclared()
+
+	var (
+		b        strings.Builder
+		issues   []lineIssue
+		ifBlocks []bool // Open if blocks; true for is_incremental()
+		endifAt  = -1   // Output offset after the last is_incremental block
+		endifLn  int
+	)
+	report := func(line int, format string, args ...any) {
+		issues = append(issues, lineIssue{Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	last := 0
+	for _, loc := range jinjaTagPattern.FindAllStringSubmatchIndex(content, -1) {
+		b.WriteString(content[last:loc[0]])
+		last = loc[1]
+		raw := content[loc[0]:loc[1]]
+		line := 1 + strings.Count(content[:loc[0]], "\n")
+
+		switch {
+		case loc[2] >= 0: // {{ expr }}
+			expr := strings.TrimSpace(content[loc[2]:loc[3]])
+			out, isConfig, err := c.eval(expr)
+			if err != nil {
+				report(line, "%s not converted: %v", raw, err)
+				b.WriteString(raw)
+				continue
+			}
+			if isConfig {
+				last = skipLineEnd(content, last)
+			}
+			b.WriteString(out)
+
+		case loc[4] >= 0: // {% stmt %}
+			stmt := strings.Join(strings.Fields(content[loc[4]:loc[5]]), " ")
+			switch {
+			case incrementalIfPattern.MatchString(stmt):
+				ifBlocks = append(ifBlocks, true)
+				c.incremental = true
+				last = writeDirective(&b, content, last, "-- #if is_incremental")
+			case stmt == "endif" && len(ifBlocks) > 0:
+				isIncremental := ifBlocks[len(ifBlocks)-1]
+				ifBlocks = ifBlocks[:len(ifBlocks)-1]
+				if !isIncremental {
+					b.WriteString(raw)
+					continue
+				}
+				c.incremental = false
+				last = writeDirective(&b, content, last, "-- #endif")
+				endifAt, endifLn = b.Len(), line
+			case strings.HasPrefix(stmt, "if "):
+				ifBlocks = append(ifBlocks, false)
+				report(line, "%s not converted: only {%% if is_incremental() %%} blocks are supported", raw)
+				b.WriteString(raw)
+			default:
+				if c.incremental && (stmt == "else" || strings.HasPrefix(stmt, "elif ")) {
+					report(line, "%s not converted: is_incremental() blocks cannot have an else branch", raw)
+				} else if stmt != "endif" {
+					report(line, "%s not converted: Jinja statements have no LeapSQL equivalent", raw)
+				}
+				b.WriteString(raw)
+			}
+
+		default: // {# comment #}
+			b.WriteString("/*" + content[loc[6]:loc[7]] + "*/")
+		}
+	}
+	b.WriteString(content[last:])
+
+	sql := b.String()
+	if endifAt >= 0 && strings.TrimSpace(sql[endifAt:]) != "" {
+		report(endifLn, "is_incremental() block is followed by more SQL; LeapSQL appends the block to the end of the query")
+	}
+
+	return &convertedSQL{
+		SQL:    strings.TrimSpace(sql) + "\n",
+		Config: c.config,
+		Issues: issues,
+	}
+}
+
+// skipLineEnd returns the offset after the line break following pos, if only
+// whitespace separates them, so removed tags do not leave blank lines.
+func skipLineEnd(content string, pos int) int {
+	rest := content[pos:]
+	trimmed := strings.TrimLeft(rest, " \t\r")
+	if strings.HasPrefix(trimmed, "\n") {
+		return pos + len(rest) - len(trimmed) + 1
+	}
+	return pos
+}
+
+// writeDirective writes a conditional directive on its own line, as the
+// loader only recognizes directives in line comments. It returns the offset
+// to continue copying content from.
+func writeDirective(b *strings.Builder, content string, pos int, directive string) int {
+	written := strings.TrimRight(b.String(), " \t")
+	b.Reset()
+	b.WriteString(written)
+	if written != "" && !strings.HasSuffix(written, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString(directive)
+
+	next := skipLineEnd(content, pos)
+	b.WriteString("\n")
+	if next == pos {
+		// More content followed on the tag's line; keep it on the next line
+		return pos + len(content[pos:]) - len(strings.TrimLeft(content[pos:], " \t"))
+	}
+	return next
+}
+
+// eval evaluates a Jinja expression. It reports whether the expression was a
+// config() call, whose output is empty.
+func (c *sqlConverter) eval(expr string) (out string, isConfig bool, err error) {
+	if expr == "this" {
+		return c.this(), false, nil
+	}
+
+	thread := &starlark.Thread{Name: "dbt"}
+	result, err := starlark.Eval(thread, "model.sql", expr, c.globals) //nolint:staticcheck // SA1019: matches the template evaluator
+	if err != nil {
+		var evalErr *starlark.EvalError
+		if errors.As(err, &evalErr) {
+			return "", false, errors.New(evalErr.Msg)
+		}
+		return "", false, errors.New(exprPositionPattern.ReplaceAllString(err.Error(), ""))
+	}
+
+	isConfig = strings.HasPrefix(expr, "config(")
+	switch v := result.(type) {
+	case starlark.String:
+		return string(v), isConfig, nil
+	case starlark.NoneType:
+		return "", isConfig, nil
+	default:
+		return v.String(), isConfig, nil
+	}
+}
+
+// this returns the value of {{ this }}: a placeholder inside is_incremental
+// blocks, which the engine fills in, and the model's table elsewhere.
+func (c *sqlConverter) this() string {
+	if c.incremental {
+		return thisPlaceholder
+	}
+	return c.table
+}
+
+// predeclared returns the dbt context available to expressions.
+func (c *sqlConverter) predeclared() starlark.StringDict {
+	return starlark.StringDict{
+		"ref":            starlark.NewBuiltin("ref", c.ref),
+		"source":         starlark.NewBuiltin("source", c.source),
+		"var":            starlark.NewBuiltin("var", c.variable),
+		"config":         starlark.NewBuiltin("config", c.configure),
+		"is_incremental": starlark.NewBuiltin("is_incremental", isIncremental),
+		"target": starlarkstruct.FromStringDict(starlark.String("target"), starlark.StringDict{
+			"name":     starlark.String("{{ env }}"),
+			"type":     starlark.String("{{ target.type }}"),
+			"schema":   starlark.String("{{ target.schema }}"),
+			"database": starlark.String("{{ target.database }}"),
+		}),
+		"true":  starlark.True,
+		"false": starlark.False,
+		"none":  starlark.None,
+	}
+}
+
+// ref resolves ref('model') and ref('package', 'model') to a table.
+func (c *sqlConverter) ref(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, fmt.Errorf("%s: expected a model name", b.Name())
+	}
+	name, ok := starlark.AsString(args[len(args)-1])
+	if !ok {
+		return nil, fmt.Errorf("%s: model name must be a string", b.Name())
+	}
+	table, ok := c.r.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("model or seed %q is not part of the project", name)
+	}
+	return starlark.String(table), nil
+}
+
+// source resolves source('source', 'table') to the declared relation.
+func (c *sqlConverter) source(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var sourceName, tableName string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "source_name", &sourceName, "table_name", &tableName); err != nil {
+		return nil, err
+	}
+	relation, ok := c.r.sources[[2]string{sourceName, tableName}]
+	if !ok {
+		return nil, fmt.Errorf("source %s.%s is not declared", sourceName, tableName)
+	}
+	return starlark.String(relation), nil
+}
+
+// variable inlines var('name', default) with the project's value.
+func (c *sqlConverter) variable(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var def starlark.Value
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "default?", &def); err != nil {
+		return nil, err
+	}
+	value, ok := c.r.vars[name]
+	if !ok {
+		if def == nil {
+			return nil, fmt.Errorf("var %q has no value in %s", name, ProjectFile)
+		}
+		return def, nil
+	}
+	return starctx.GoToStarlark(value)
+}
+
+// configure records the arguments of config() for the model's frontmatter.
+func (c *sqlConverter) configure(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if len(args) > 0 {
+		return nil, fmt.Errorf("%s: expected keyword arguments", b.Name())
+	}
+	for _, kv := range kwargs {
+		value, err := starctx.ToGo(kv[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", b.Name(), kv[0], err)
+		}
+		mergeConfig(c.config, string(kv[0].(starlark.String)), value)
+	}
+	return starlark.String(""), nil
+}
+
+func isIncremental(_ *starlark.Thread, _ *starlark.Builtin, _ starlark.Tuple, _ []starlark.Tuple) (starlark.Value, error) {
+	return nil, fmt.Errorf("is_incremental() is only supported in {%% if is_incremental() %%} blocks")
+}
+
+// macroNames returns the names of the macros defined in a dbt macro file.
+func macroNames(content string) []string {
+	var names []string
+	for _, m := range macroPattern.FindAllStringSubmatch(content, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
@@ -0,0 +1,112 @@
+package dbt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertSQL(t *testing.T) {
+	r := &resolver{
+		tables:  map[string]string{"stg_orders": "staging.stg_orders", "raw_orders": "raw_orders"},
+		sources: map[[2]string]string{{"raw", "payments"}: "raw_data.payments"},
+		vars:    map[string]any{"min_amount": 10, "region": "eu"},
+	}
+
+	tests := []struct {
+		name       string
+		content    string
+		wantSQL    string
+		wantConfig map[string]any
+		wantIssues []int // Lines of the reported issues
+	}{
+		{
+			name:       "refs and sources",
+			content:    "select * from {{ ref('stg_orders') }} o join {{ source('raw', 'payments') }} p using (id)",
+			wantSQL:    "select * from staging.stg_orders o join raw_data.payments p using (id)\n",
+			wantConfig: map[string]any{},
+		},
+		{
+			name:       "package refs and double quotes",
+			content:    `select * from {{ ref("shop", "raw_orders") }}`,
+			wantSQL:    "select * from raw_orders\n",
+			wantConfig: map[string]any{},
+		},
+		{
+			name:       "config is collected and removed",
+			content:    "{{ config(materialized='table', tags=['a', 'b'], enabled=true) }}\nselect 1",
+			wantSQL:    "select 1\n",
+			wantConfig: map[string]any{"materialized": "table", "tags": []string{"a", "b"}, "enabled": true},
+		},
+		{
+			name:       "vars are inlined",
+			content:    "select * from t where amount >= {{ var('min_amount') }} and region = '{{ var(\"region\") }}' and n < {{ var('limit', 5) }}",
+			wantSQL:    "select * from t where amount >= 10 and region = 'eu' and n < 5\n",
+			wantConfig: map[string]any{},
+		},
+		{
+			name:       "target",
+			content:    "select '{{ target.name }}' as env from {{ target.schema }}.t",
+			wantSQL:    "select '{{ env }}' as env from {{ target.schema }}.t\n",
+			wantConfig: map[string]any{},
+		},
+		{
+			name:       "comments",
+			content:    "{# raw data #}\nselect 1",
+			wantSQL:    "/* raw data */\nselect 1\n",
+			wantConfig: map[string]any{},
+		},
+		{
+			name:       "incremental block",
+			content:    "select * from {{ this }}\n{% if is_incremental() %}\n  where id > (select max(id) from {{ this }})\n{% endif %}\n",
+			wantSQL:    "select * from staging.model\n-- #if is_incremental\n  where id > (select max(id) from {{ this }})\n-- #endif\n",
+			wantConfig: map[string]any{},
+		},
+		{
+			name:       "inline incremental block",
+			content:    "select * from t {%- if is_incremental() %} where id > 1 {% endif -%}",
+			wantSQL:    "select * from t\n-- #if is_incremental\nwhere id > 1\n-- #endif\n",
+			wantConfig: map[string]any{},
+		},
+		{
+			name:       "incremental block before more SQL",
+			content:    "select * from t\n{% if is_incremental() %}\nwhere id > 1\n{% endif %}\nlimit 10",
+			wantSQL:    "select * from t\n-- #if is_incremental\nwhere id > 1\n-- #endif\nlimit 10\n",
+			wantConfig: map[string]any{},
+			wantIssues: []int{4},
+		},
+		{
+			name:       "unsupported constructs are kept",
+			content:    "select\n{{ dbt_utils.star(ref('stg_orders')) }},\n{% if target.name == 'prod' %}1{% endif %}\nfrom {{ ref('missing') }}",
+			wantSQL:    "select\n{{ dbt_utils.star(ref('stg_orders')) }},\n{% if target.name == 'prod' %}1{% endif %}\nfrom {{ ref('missing') }}\n",
+			wantConfig: map[string]any{},
+			wantIssues: []int{2, 3, 4},
+		},
+		{
+			name:       "else branch in incremental block",
+			content:    "select 1\n{% if is_incremental() %}\nwhere a\n{% else %}\nwhere b\n{% endif %}",
+			wantSQL:    "select 1\n-- #if is_incremental\nwhere a\n{% else %}\nwhere b\n-- #endif\n",
+			wantConfig: map[string]any{},
+			wantIssues: []int{4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.convertSQL(tt.content, "staging.model")
+			assert.Equal(t, tt.wantSQL, got.SQL)
+			assert.Equal(t, tt.wantConfig, got.Config)
+
+			var lines []int
+			for _, issue := range got.Issues {
+				lines = append(lines, issue.Line)
+			}
+			assert.Equal(t, tt.wantIssues, lines)
+		})
+	}
+}
+
+func TestMacroNames(t *testing.T) {
+	content := "{% macro cents(x) %}{{ x }} * 100{% endmacro %}\n\n{%- macro dollars(x) -%}{{ x }} / 100{%- endmacro %}"
+	assert.Equal(t, []string{"cents", "dollars"}, macroNames(content))
+}
@@ -0,0 +1,236 @@
+// Package dbt converts dbt projects into LeapSQL projects.
+//
+// The importer reads dbt_project.yml, the SQL models with their Jinja refs,
+// sources and configs, the schema YAML files with descriptions and tests, and
+// the seeds. Features LeapSQL has no equivalent for are reported as issues
+// instead of being dropped silently.
+package dbt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile is the name of the dbt project file.
+const ProjectFile = "dbt_project.yml"
+
+// project is the subset of dbt_project.yml the importer understands.
+type project struct {
+	Name          string         `yaml:"name"`
+	Profile       string         `yaml:"profile"`
+	ModelPaths    []string       `yaml:"model-paths"`
+	SourcePaths   []string       `yaml:"source-paths"` // Pre-1.0 name of model-paths
+	SeedPaths     []string       `yaml:"seed-paths"`
+	DataPaths     []string       `yaml:"data-paths"` // Pre-1.0 name of seed-paths
+	MacroPaths    []string       `yaml:"macro-paths"`
+	SnapshotPaths []string       `yaml:"snapshot-paths"`
+	TestPaths     []string       `yaml:"test-paths"`
+	AnalysisPaths []string       `yaml:"analysis-paths"`
+	Vars          map[string]any `yaml:"vars"`
+	Models        map[string]any `yaml:"models"`
+	Seeds         map[string]any `yaml:"seeds"`
+	OnRunStart    any            `yaml:"on-run-start"`
+	OnRunEnd      any            `yaml:"on-run-end"`
+}
+
+// readProject reads dbt_project.yml from dir, filling in dbt's default paths.
+func readProject(dir string) (*project, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ProjectFile)) //nolint:gosec // G304: user-provided project directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s not found in %s", ProjectFile, dir)
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", ProjectFile, err)
+	}
+
+	var p project
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ProjectFile, err)
+	}
+	if p.Name == "" {
+		return nil, fmt.Errorf("%s has no project name", ProjectFile)
+	}
+
+	p.ModelPaths = firstNonEmpty(p.ModelPaths, p.SourcePaths, []string{"models"})
+	p.SeedPaths = firstNonEmpty(p.SeedPaths, p.DataPaths, []string{"seeds"})
+	p.MacroPaths = firstNonEmpty(p.MacroPaths, []string{"macros"})
+	p.SnapshotPaths = firstNonEmpty(p.SnapshotPaths, []string{"snapshots"})
+	p.TestPaths = firstNonEmpty(p.TestPaths, []string{"tests"})
+	p.AnalysisPaths = firstNonEmpty(p.AnalysisPaths, []string{"analyses"})
+	return &p, nil
+}
+
+func firstNonEmpty(lists ...[]string) []string {
+	for _, l := range lists {
+		if len(l) > 0 {
+			return l
+		}
+	}
+	return nil
+}
+
+// properties is a dbt properties file (schema.yml).
+type properties struct {
+	Models         []modelProperties  `yaml:"models"`
+	Sources        []sourceProperties `yaml:"sources"`
+	Seeds          []yaml.Node        `yaml:"seeds"`
+	Groups         []groupProperties  `yaml:"groups"`
+	Snapshots      []yaml.Node        `yaml:"snapshots"`
+	Exposures      []yaml.Node        `yaml:"exposures"`
+	Metrics        []yaml.Node        `yaml:"metrics"`
+	SemanticModels []yaml.Node        `yaml:"semantic_models"`
+	Macros         []yaml.Node        `yaml:"macros"`
+	UnitTests      []yaml.Node        `yaml:"unit_tests"`
+}
+
+// modelProperties documents and tests one model.
+type modelProperties struct {
+	Name        string             `yaml:"name"`
+	Description string             `yaml:"description"`
+	Config      map[string]any     `yaml:"config"`
+	Columns     []columnProperties `yaml:"columns"`
+	Tests       []any              `yaml:"tests"`
+	DataTests   []any              `yaml:"data_tests"`
+	Versions    []yaml.Node        `yaml:"versions"`
+	Access      string             `yaml:"access"`
+	Group       string             `yaml:"group"`
+}
+
+// columnProperties documents and tests one column.
+type columnProperties struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Tests       []any  `yaml:"tests"`
+	DataTests   []any  `yaml:"data_tests"`
+}
+
+// sourceProperties declares tables loaded outside dbt.
+type sourceProperties struct {
+	Name     string `yaml:"name"`
+	Database string `yaml:"database"`
+	Schema   string `yaml:"schema"`
+	Tables   []struct {
+		Name       string `yaml:"name"`
+		Identifier string `yaml:"identifier"`
+	} `yaml:"tables"`
+}
+
+// groupProperties declares a dbt group and its owner.
+type groupProperties struct {
+	Name  string `yaml:"name"`
+	Owner struct {
+		Name  string `yaml:"name"`
+		Email string `yaml:"email"`
+	} `yaml:"owner"`
+}
+
+// readProperties reads a properties file.
+func readProperties(path string) (*properties, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path comes from walking the dbt project
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var props properties
+	if err := yaml.Unmarshal(data, &props); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &props, nil
+}
+
+// configKeys are the model configs that may appear without a "+" prefix in
+// dbt_project.yml. Other unprefixed keys name model directories.
+var configKeys = map[string]bool{
+	"materialized": true, "unique_key": true, "schema": true, "database": true,
+	"alias": true, "tags": true, "enabled": true, "full_refresh": true,
+	"meta": true, "docs": true, "group": true, "access": true, "grants": true,
+	"persist_docs": true, "pre-hook": true, "post-hook": true, "pre_hook": true,
+	"post_hook": true, "incremental_strategy": true, "on_schema_change": true,
+	"contract": true, "partition_by": true,
+}
+
+// projectConfig returns the configs dbt_project.yml applies to a model in dir,
+// a slash-separated directory relative to its model path. Deeper directories
+// override shallower ones, except tags which accumulate.
+func projectConfig(p *project, dir string) map[string]any {
+	cfg := make(map[string]any)
+	level := p.Models
+	mergeLevel(cfg, level)
+
+	scope, ok := level[p.Name].(map[string]any)
+	if !ok {
+		return cfg
+	}
+	mergeLevel(cfg, scope)
+
+	if dir == "" {
+		return cfg
+	}
+	for _, part := range strings.Split(dir, "/") {
+		next, ok := scope[part].(map[string]any)
+		if !ok {
+			break
+		}
+		mergeLevel(cfg, next)
+		scope = next
+	}
+	return cfg
+}
+
+// mergeLevel merges the configs set on one level of the models tree into cfg.
+func mergeLevel(cfg, level map[string]any) {
+	for key, value := range level {
+		name := strings.TrimPrefix(key, "+")
+		if name == key && !configKeys[key] {
+			continue
+		}
+		if name == key {
+			if _, isDir := value.(map[string]any); isDir && key != "meta" && key != "docs" {
+				continue
+			}
+		}
+		mergeConfig(cfg, name, value)
+	}
+}
+
+// mergeConfig sets one config value, accumulating tags.
+func mergeConfig(cfg map[string]any, name string, value any) {
+	if name == "tags" {
+		cfg["tags"] = appendUnique(toStrings(cfg["tags"]), toStrings(value)...)
+		return
+	}
+	cfg[name] = value
+}
+
+// toStrings converts a string or list config value to a string slice.
+func toStrings(v any) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			out = append(out, fmt.Sprint(item))
+		}
+		return out
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		if !slices.Contains(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,274 @@
+package engine
+
+// anomalies.go - Anomaly tests comparing model outputs with previous runs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// minZScoreHistory is the number of previous runs a z-score needs to be
+// meaningful.
+const minZScoreHistory = 3
+
+// Anomaly is a metric of a model's output in a run that deviates from its
+// values in previous runs beyond an anomaly test's thresholds.
+type Anomaly struct {
+	Model   string
+	Metric  string // core.MetricRowCount or core.MetricNullRate
+	Column  string // Column of a null rate (empty for row counts)
+	Value   float64
+	Reasons []string // Thresholds the value exceeds
+	Fail    bool     // The anomaly failed the model
+}
+
+// String describes the anomaly, e.g. "row count of staging.orders is 150:
+// changed by -85.0% since the previous run (max 50.0%)".
+func (a Anomaly) String() string {
+	if a.Metric == core.MetricNullRate {
+		return fmt.Sprintf("null rate of %s.%s is %s: %s", a.Model, a.Column, formatRate(a.Value), strings.Join(a.Reasons, "; "))
+	}
+	return fmt.Sprintf("row count of %s is %.0f: %s", a.Model, a.Value, strings.Join(a.Reasons, "; "))
+}
+
+// Anomalies returns the anomalies of the model outputs measured in a run,
+// compared with the runs before it.
+func (e *Engine) Anomalies(runID string) ([]Anomaly, error) {
+	metrics, err := e.store.GetModelMetricsForRun(runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metrics of run %s: %w", runID, err)
+	}
+
+	var anomalies []Anomaly
+	for _, metric := range metrics {
+		m, ok := e.models[metric.ModelPath]
+		if !ok {
+			continue
+		}
+		cfg := driftConfig(m, metric.Metric)
+		if cfg == nil {
+			continue
+		}
+		anomaly, err := e.metricAnomaly(metric, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if anomaly != nil {
+			anomalies = append(anomalies, *anomaly)
+		}
+	}
+	return anomalies, nil
+}
+
+// driftConfig returns a model's anomaly test of a metric (nil if none).
+func driftConfig(m *core.Model, metric string) *core.DriftConfig {
+	for _, test := range m.Tests {
+		switch {
+		case metric == core.MetricRowCount && test.RowCountDrift != nil:
+			return test.RowCountDrift
+		case metric == core.MetricNullRate && test.NullRateDrift != nil:
+			return test.NullRateDrift
+		}
+	}
+	return nil
+}
+
+// checkAnomalies records the row count and null rates of a built model with
+// anomaly tests, and fails if they deviate from previous runs beyond a test
+// set to fail. Views, external models and builds limited to a sample
+// or a row filter are not measured, since they are not comparable with full
+// builds.
+func (e *Engine) checkAnomalies(ctx context.Context, runID string, m *core.Model) error {
+	rowCount, nullRate := driftConfig(m, core.MetricRowCount), driftConfig(m, core.MetricNullRate)
+	if rowCount == nil && nullRate == nil {
+		return nil
+	}
+	if m.Materialized == "view" || m.External != nil || e.sampleRows(m) > 0 || strings.TrimSpace(m.Where) != "" {
+		return nil
+	}
+
+	metrics, err := e.measureModel(ctx, runID, m, nullRate)
+	if err != nil {
+		return fmt.Errorf("anomaly test on %s failed: %w", m.Path, err)
+	}
+	if err := e.store.SaveModelMetrics(metrics); err != nil {
+		return fmt.Errorf("failed to record metrics of %s: %w", m.Path, err)
+	}
+
+	var errs []error
+	for _, metric := range metrics {
+		cfg := driftConfig(m, metric.Metric)
+		if cfg == nil || !cfg.Fail {
+			continue
+		}
+		anomaly, err := e.metricAnomaly(metric, cfg)
+		if err != nil {
+			return err
+		}
+		if anomaly != nil {
+			errs = append(errs, fmt.Errorf("anomaly test failed: %s", anomaly))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// measureModel queries the row count of a model's table and, with a null rate
+// test, the null rates of its columns.
+func (e *Engine) measureModel(ctx context.Context, runID string, m *core.Model, nullRate *core.DriftConfig) ([]*core.ModelMetric, error) {
+	var columns []string
+	if nullRate != nil {
+		columns = nullRate.Columns
+		if len(columns) == 0 {
+			cols, err := e.store.GetModelColumns(m.Path)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range cols {
+				columns = append(columns, c.Name)
+			}
+		}
+	}
+
+	exprs := []string{"COUNT(*)"}
+	for _, c := range columns {
+		exprs = append(exprs, fmt.Sprintf("COUNT(%s)", c))
+	}
+	rows, err := e.db.Query(ctx, fmt.Sprintf("SELECT %s FROM %s", strings.Join(exprs, ", "), e.tableName(m.Path)))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	counts := make([]int64, len(exprs))
+	dest := make([]any, len(exprs))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	if rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	total := counts[0]
+	metrics := []*core.ModelMetric{{RunID: runID, ModelPath: m.Path, Metric: core.MetricRowCount, Value: float64(total)}}
+	for i, c := range columns {
+		rate := 0.0
+		if total > 0 {
+			rate = float64(total-counts[i+1]) / float64(total)
+		}
+		metrics = append(metrics, &core.ModelMetric{RunID: runID, ModelPath: m.Path, Metric: core.MetricNullRate, Column: c, Value: rate})
+	}
+	return metrics, nil
+}
+
+// metricAnomaly compares a metric recorded in a run with its values in the
+// previous runs. It returns nil if the metric is within the test's thresholds.
+func (e *Engine) metricAnomaly(metric *core.ModelMetric, cfg *core.DriftConfig) (*Anomaly, error) {
+	window := cfg.Window
+	if window <= 0 {
+		window = core.DefaultDriftWindow
+	}
+	recorded, err := e.store.GetModelMetrics(metric.ModelPath, metric.Metric, metric.Column, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metrics of %s: %w", metric.ModelPath, err)
+	}
+
+	// Values recorded before the run, newest first
+	var history []float64
+	for i, r := range recorded {
+		if r.RunID == metric.RunID {
+			for _, prev := range recorded[i+1:] {
+				history = append(history, prev.Value)
+			}
+			break
+		}
+	}
+	if len(history) > window {
+		history = history[:window]
+	}
+
+	reasons := driftReasons(metric.Value, history, cfg, metric.Metric == core.MetricRowCount)
+	if len(reasons) == 0 {
+		return nil, nil
+	}
+	return &Anomaly{
+		Model:   metric.ModelPath,
+		Metric:  metric.Metric,
+		Column:  metric.Column,
+		Value:   metric.Value,
+		Reasons: reasons,
+		Fail:    cfg.Fail,
+	}, nil
+}
+
+// driftReasons returns the thresholds of a test that a value exceeds, given
+// the values of the previous runs, newest first. With relative, changes are
+// measured as fractions of the previous value (row counts); otherwise as
+// differences (null rates).
+func driftReasons(value float64, history []float64, cfg *core.DriftConfig, relative bool) []string {
+	if len(history) == 0 {
+		return nil
+	}
+	format := formatRate
+	if relative {
+		format = func(v float64) string { return fmt.Sprintf("%.0f", v) }
+	}
+
+	var reasons []string
+	if cfg.MaxChange > 0 {
+		prev := history[0]
+		switch {
+		case relative && prev == 0:
+			if value != 0 {
+				reasons = append(reasons, fmt.Sprintf("was %s in the previous run", format(prev)))
+			}
+		case relative:
+			if change := (value - prev) / prev; math.Abs(change) > cfg.MaxChange {
+				reasons = append(reasons, fmt.Sprintf("changed by %+.1f%% since the previous run (max %.1f%%)", change*100, cfg.MaxChange*100))
+			}
+		default:
+			if change := value - prev; math.Abs(change) > cfg.MaxChange {
+				reasons = append(reasons, fmt.Sprintf("changed by %+.1f points since the previous run (max %.1f)", change*100, cfg.MaxChange*100))
+			}
+		}
+	}
+
+	if cfg.ZScore > 0 && len(history) >= minZScoreHistory {
+		var mean float64
+		for _, v := range history {
+			mean += v
+		}
+		mean /= float64(len(history))
+		var variance float64
+		for _, v := range history {
+			variance += (v - mean) * (v - mean)
+		}
+		stddev := math.Sqrt(variance / float64(len(history)))
+
+		switch {
+		case stddev == 0:
+			if value != mean {
+				reasons = append(reasons, fmt.Sprintf("was %s in each of the previous %d runs", format(mean), len(history)))
+			}
+		default:
+			if z := (value - mean) / stddev; math.Abs(z) > cfg.ZScore {
+				reasons = append(reasons, fmt.Sprintf("z-score %.1f over the previous %d runs (max %.1f)", z, len(history), cfg.ZScore))
+			}
+		}
+	}
+	return reasons
+}
+
+// formatRate formats a null rate as a percentage.
+func formatRate(rate float64) string {
+	return fmt.Sprintf("%.1f%%", rate*100)
+}
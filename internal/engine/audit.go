@@ -0,0 +1,324 @@
+package engine
+
+// audit.go - Auditing the relations of models in the target database
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// AuditResult compares the models of the project with the relations of the
+// target database.
+type AuditResult struct {
+	// Missing are the models whose relation does not exist
+	Missing []MissingRelation
+	// Orphans are the relations in the schemas models are built in that no
+	// model, seed or source accounts for
+	Orphans []OrphanRelation
+	// Drift are the models whose relation has other columns or types than
+	// their SQL produces
+	Drift []RelationDrift
+	// Unchecked are the models whose columns could not be compared, e.g.
+	// because a relation they read is missing
+	Unchecked []UncheckedModel
+}
+
+// Issues returns the number of missing, orphaned and drifted relations.
+func (r *AuditResult) Issues() int {
+	return len(r.Missing) + len(r.Orphans) + len(r.Drift)
+}
+
+// MissingRelation is a model whose relation does not exist.
+type MissingRelation struct {
+	// Model is the model path
+	Model string
+	// Relation is the table name the model is built as
+	Relation string
+	// Built is true when the state records a build of the model in the
+	// target: its relation was dropped outside LeapSQL
+	Built bool
+}
+
+// OrphanRelation is a relation no model, seed or source accounts for, such
+// as the table of a deleted or renamed model.
+type OrphanRelation struct {
+	// Schema is the schema of the relation
+	Schema string
+	// Name is the name of the relation
+	Name string
+	// View is true for views, false for tables
+	View bool
+}
+
+// Relation returns the schema-qualified name of the relation.
+func (o OrphanRelation) Relation() string {
+	return o.Schema + "." + o.Name
+}
+
+// RelationDrift lists the columns of a model's relation that differ from
+// those its SQL produces.
+type RelationDrift struct {
+	// Model is the model path
+	Model string
+	// Relation is the table name the model is built as
+	Relation string
+	// Columns are the drifted columns, in the order of the model's SQL
+	// followed by the columns only the relation has
+	Columns []ColumnDrift
+}
+
+// ColumnDrift is a column whose type differs between a model's SQL and its relation.
+type ColumnDrift struct {
+	// Column is the column name
+	Column string
+	// Expected is the type the model's SQL produces ("" if it no longer outputs the column)
+	Expected string
+	// Actual is the type of the relation's column ("" if the relation lacks it)
+	Actual string
+}
+
+// UncheckedModel is a model whose columns could not be compared.
+type UncheckedModel struct {
+	// Model is the model path
+	Model string
+	// Err is why the columns could not be compared
+	Err error
+}
+
+// Audit compares the models of the project with the relations of the target
+// database. It reports the models whose relation is missing, the relations
+// left in the schemas of models that no model produces anymore, and the
+// models whose relation has drifted from the columns and types their SQL
+// produces as it would be built, which is their contract until they are
+// rebuilt. External models are only checked for their relation.
+func (e *Engine) Audit(ctx context.Context) (*AuditResult, error) {
+	if err := e.ensureDBConnected(ctx); err != nil {
+		return nil, err
+	}
+
+	result := &AuditResult{}
+	target := e.cacheTarget()
+	for _, path := range slices.Sorted(maps.Keys(e.models)) {
+		m := e.models[path]
+		relation := e.tableName(path)
+
+		if _, err := e.db.GetTableMetadata(ctx, relation); err != nil {
+			result.Missing = append(result.Missing, MissingRelation{Model: path, Relation: relation, Built: e.builtInTarget(target, path)})
+			continue
+		}
+		if m.External != nil {
+			continue
+		}
+
+		columns, err := e.driftedColumns(ctx, m, relation)
+		if err != nil {
+			result.Unchecked = append(result.Unchecked, UncheckedModel{Model: path, Err: err})
+			continue
+		}
+		if len(columns) > 0 {
+			result.Drift = append(result.Drift, RelationDrift{Model: path, Relation: relation, Columns: columns})
+		}
+	}
+
+	orphans, err := e.orphanRelations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relations: %w", err)
+	}
+	result.Orphans = orphans
+	return result, nil
+}
+
+// DropOrphans drops orphaned relations found by Audit and returns the
+// relations dropped. It stops at the first relation that fails to drop.
+func (e *Engine) DropOrphans(ctx context.Context, orphans []OrphanRelation) ([]string, error) {
+	if err := e.ensureDBConnected(ctx); err != nil {
+		return nil, err
+	}
+
+	var dropped []string
+	for _, o := range orphans {
+		kind := "TABLE"
+		if o.View {
+			kind = "VIEW"
+		}
+		name := e.dialect.QuoteIdentifier(o.Schema) + "." + e.dialect.QuoteIdentifier(o.Name)
+		if err := e.db.Exec(ctx, fmt.Sprintf("DROP %s IF EXISTS %s", kind, name)); err != nil {
+			return dropped, fmt.Errorf("failed to drop %s: %w", o.Relation(), err)
+		}
+		dropped = append(dropped, o.Relation())
+	}
+	return dropped, nil
+}
+
+// builtInTarget reports whether the state records a build of a model in target.
+func (e *Engine) builtInTarget(target, path string) bool {
+	if target == "" {
+		return false
+	}
+	model, err := e.store.GetModelByPath(path)
+	if err != nil || model == nil {
+		return false
+	}
+	build, err := e.store.GetModelBuild(model.ID, target)
+	return err == nil && build != nil
+}
+
+// driftedColumns compares the columns and types of a model's relation with
+// those of its SQL, with the masking and audit columns its build adds.
+func (e *Engine) driftedColumns(ctx context.Context, m *core.Model, relation string) ([]ColumnDrift, error) {
+	model, err := e.store.GetModelByPath(m.Path)
+	if err != nil {
+		model = nil
+	}
+	sql, err := e.buildSQL(m, model)
+	if err != nil {
+		return nil, err
+	}
+	sql = e.withMasking(m, sql)
+	if m.Materialized != core.MaterializationView {
+		sql = withAuditColumns(m, model, sql, "")
+	}
+	sql = strings.TrimSuffix(strings.TrimSpace(sql), ";")
+
+	expected, err := e.queryColumns(ctx, fmt.Sprintf("SELECT * FROM (\n%s\n) AS leapsql_audit LIMIT 0", sql))
+	if err != nil {
+		return nil, err
+	}
+	actual, err := e.queryColumns(ctx, fmt.Sprintf("SELECT * FROM %s LIMIT 0", relation))
+	if err != nil {
+		return nil, err
+	}
+
+	actualTypes := make(map[string]string, len(actual))
+	for _, col := range actual {
+		actualTypes[strings.ToLower(col.name)] = col.typ
+	}
+
+	var drift []ColumnDrift
+	seen := make(map[string]bool, len(expected))
+	for _, col := range expected {
+		key := strings.ToLower(col.name)
+		seen[key] = true
+		if typ, ok := actualTypes[key]; !ok || !strings.EqualFold(typ, col.typ) {
+			drift = append(drift, ColumnDrift{Column: col.name, Expected: col.typ, Actual: typ})
+		}
+	}
+	for _, col := range actual {
+		if !seen[strings.ToLower(col.name)] {
+			drift = append(drift, ColumnDrift{Column: col.name, Actual: col.typ})
+		}
+	}
+	return drift, nil
+}
+
+// queryColumns returns the columns of a query's result and their types.
+func (e *Engine) queryColumns(ctx context.Context, query string) ([]relationColumn, error) {
+	rows, err := e.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]relationColumn, len(types))
+	for i, t := range types {
+		cols[i] = relationColumn{name: t.Name(), typ: t.DatabaseTypeName()}
+	}
+	return cols, nil
+}
+
+// orphanRelations lists the relations in the schemas of models built in the
+// target database that are not the relation of a model, a seed, or a source
+// models read. Staging and temp tables of interrupted builds are left to
+// Cleanup.
+func (e *Engine) orphanRelations(ctx context.Context) ([]OrphanRelation, error) {
+	currentSchema, err := e.queryString(ctx, "SELECT current_schema()")
+	if err != nil {
+		return nil, err
+	}
+	qualify := func(table string) string {
+		if !strings.Contains(table, ".") {
+			table = currentSchema + "." + table
+		}
+		return strings.ToLower(table)
+	}
+
+	known := make(map[string]bool)
+	schemas := make(map[string]bool)
+	for path, m := range e.models {
+		relation := e.tableName(path)
+		for _, table := range []string{relation, stagingTable(relation), incrementalTempTable(relation)} {
+			known[qualify(table)] = true
+		}
+		if m.Database == "" {
+			schemas[tableSchema(qualify(relation))] = true
+		}
+
+		_, sources := e.registry.ResolveDependenciesFrom(m.Project, m.Sources)
+		for _, source := range sources {
+			known[qualify(source)] = true
+		}
+	}
+	for path := range e.disabled {
+		known[qualify(pathToTableName(path))] = true
+	}
+	seeds, _ := e.SeedFiles()
+	for table := range seeds {
+		known[qualify(table)] = true
+	}
+	if len(schemas) == 0 {
+		return nil, nil
+	}
+
+	quoted := make([]string, 0, len(schemas))
+	for _, schema := range slices.Sorted(maps.Keys(schemas)) {
+		quoted = append(quoted, "'"+strings.ReplaceAll(schema, "'", "''")+"'")
+	}
+	rows, err := e.db.Query(ctx, fmt.Sprintf(`SELECT table_schema, table_name, table_type FROM information_schema.tables
+WHERE table_catalog = current_database() AND lower(table_schema) IN (%s)
+ORDER BY table_schema, table_name`, strings.Join(quoted, ", ")))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var orphans []OrphanRelation
+	for rows.Next() {
+		var o OrphanRelation
+		var tableType string
+		if err := rows.Scan(&o.Schema, &o.Name, &tableType); err != nil {
+			return nil, err
+		}
+		if known[strings.ToLower(o.Relation())] {
+			continue
+		}
+		o.View = tableType == "VIEW"
+		orphans = append(orphans, o)
+	}
+	return orphans, rows.Err()
+}
+
+// queryString runs a query returning a single string.
+func (e *Engine) queryString(ctx context.Context, query string) (string, error) {
+	rows, err := e.db.Query(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var s string
+	if rows.Next() {
+		if err := rows.Scan(&s); err != nil {
+			return "", err
+		}
+	}
+	return s, rows.Err()
+}
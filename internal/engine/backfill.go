@@ -0,0 +1,293 @@
+package engine
+
+// backfill.go - Checkpointed backfills of incremental models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/adapter"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// BackfillOptions configures a backfill.
+type BackfillOptions struct {
+	Model  string                 // Path of the incremental model to backfill
+	Column string                 // Date column the range applies to (the partition column if empty)
+	Start  time.Time              // First date of the range
+	End    time.Time              // Date the range ends before
+	Chunk  core.BackfillChunkSize // Length of the chunks (day if empty)
+}
+
+// BackfillResult describes the outcome of a backfill.
+type BackfillResult struct {
+	// Backfill is the backfill, resumed or started
+	Backfill *core.Backfill
+	// Run is the run that built the chunks
+	Run *core.Run
+	// Built are the chunks built by the run
+	Built []*core.BackfillChunk
+	// Resumed is the number of chunks skipped because an earlier, interrupted
+	// backfill over the same range completed them
+	Resumed int
+	// Total is the number of chunks of the range
+	Total int
+}
+
+// Backfill rebuilds the rows of an incremental model in a range of dates, one
+// chunk at a time. Each chunk deletes the model's rows in its dates and
+// inserts the rows of the model's full query in them, in a transaction where
+// the adapter supports one, so building a chunk again replaces its rows
+// rather than duplicating them. Chunks are recorded in the state store as
+// they complete: when a backfill over the same model, column, range and chunk
+// size was interrupted, Backfill resumes it, skipping its completed chunks.
+func (e *Engine) Backfill(ctx context.Context, env string, opts BackfillOptions) (*BackfillResult, error) {
+	m, ok := e.models[opts.Model]
+	if !ok {
+		return nil, fmt.Errorf("model not found: %s", opts.Model)
+	}
+	if m.Materialized != "incremental" {
+		return nil, fmt.Errorf("model %s is materialized as %s: only incremental models can be backfilled", m.Path, m.Materialized)
+	}
+	if strings.TrimSpace(opts.Column) == "" && m.PartitionBy != nil {
+		opts.Column = m.PartitionBy.Column
+	}
+	if strings.TrimSpace(opts.Column) == "" {
+		return nil, fmt.Errorf("backfill of %s needs a date column: the model has no partition_by", m.Path)
+	}
+	if !opts.Start.Before(opts.End) {
+		return nil, fmt.Errorf("backfill range is empty: %s is not before %s", opts.Start.Format(time.DateOnly), opts.End.Format(time.DateOnly))
+	}
+	switch opts.Chunk {
+	case "":
+		opts.Chunk = core.BackfillChunkDay
+	case core.BackfillChunkDay, core.BackfillChunkWeek, core.BackfillChunkMonth:
+	default:
+		return nil, fmt.Errorf("unknown backfill chunk %q: expected day, week or month", opts.Chunk)
+	}
+	if err := e.checkMasking(m); err != nil {
+		return nil, err
+	}
+
+	if err := e.ensureDBConnected(ctx); err != nil {
+		return nil, err
+	}
+
+	unlock, _, err := e.lockRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	persisted, err := e.store.GetModelByPath(m.Path)
+	if err != nil || persisted == nil {
+		return nil, fmt.Errorf("model not found in store: %s", m.Path)
+	}
+	sql, err := e.buildSQL(m, persisted)
+	if err != nil {
+		return nil, err
+	}
+	pre, post, err := e.buildStatements(m)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resume an interrupted backfill over the same range
+	backfill, err := e.store.GetUnfinishedBackfill(m.Path, opts.Column, opts.Start, opts.End, opts.Chunk)
+	if err != nil {
+		return nil, err
+	}
+	if backfill == nil {
+		backfill = &core.Backfill{ModelPath: m.Path, Column: opts.Column, Start: opts.Start, End: opts.End, Chunk: opts.Chunk}
+		if err := e.store.CreateBackfill(backfill); err != nil {
+			return nil, err
+		}
+	} else {
+		e.logger.Info("resuming backfill", "backfill", backfill.ID, "model", m.Path)
+	}
+	completed, err := e.store.GetBackfillChunks(backfill.ID)
+	if err != nil {
+		return nil, err
+	}
+	done := make(map[time.Time]bool, len(completed))
+	for _, c := range completed {
+		done[c.Start] = true
+	}
+
+	run, err := e.store.CreateRun(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create run: %w", err)
+	}
+	modelRun := &core.ModelRun{
+		RunID:       run.ID,
+		ModelID:     persisted.ID,
+		Status:      core.ModelRunStatusRunning,
+		RowFilter:   backfillFilter(opts.Column, opts.Start, opts.End),
+		ContentHash: persisted.ContentHash,
+	}
+	if err := e.store.RecordModelRun(modelRun); err != nil {
+		_ = e.store.CompleteRun(run.ID, core.RunStatusFailed, err.Error())
+		return nil, fmt.Errorf("failed to record model run: %w", err)
+	}
+
+	result := &BackfillResult{Backfill: backfill, Run: run}
+	build := &modelBuild{mode: core.BuildModeBackfill}
+	modelCtx := withModelBuild(e.withQueryComment(ctx, run.ID, m.Path), build)
+	start := time.Now()
+	chunks := backfillChunks(backfill)
+	result.Total = len(chunks)
+	var rows int64
+	var backfillErr error
+	for _, chunk := range chunks {
+		if done[chunk.Start] {
+			result.Resumed++
+			continue
+		}
+
+		// Stop between chunks when the backfill is cancelled
+		if backfillErr = ctx.Err(); backfillErr != nil {
+			break
+		}
+
+		chunk.RunID = run.ID
+		chunk.RowsAffected, backfillErr = e.backfillChunk(modelCtx, run.ID, m, persisted, sql, pre, post, opts.Column, chunk.Start, chunk.End)
+		if backfillErr != nil {
+			backfillErr = fmt.Errorf("chunk %s: %w", chunk.Start.Format(time.DateOnly), backfillErr)
+			break
+		}
+		if backfillErr = e.store.RecordBackfillChunk(chunk); backfillErr != nil {
+			break
+		}
+		e.logger.Debug("backfill chunk built", "model", m.Path, "chunk", chunk.Start.Format(time.DateOnly), "rows", chunk.RowsAffected)
+		result.Built = append(result.Built, chunk)
+		rows += chunk.RowsAffected
+	}
+	if backfillErr == nil {
+		if err := e.applyMaskingPolicy(modelCtx, m); err != nil {
+			backfillErr = err
+		} else if err := e.checkPartition(modelCtx, m); err != nil {
+			backfillErr = err
+		} else if err := e.checkConstraints(modelCtx, m); err != nil {
+			backfillErr = err
+		}
+	}
+	executionMS := time.Since(start).Milliseconds()
+	e.recordBuildCost(modelRun, build)
+	e.recordBuildMode(modelRun, build)
+
+	switch {
+	case backfillErr != nil:
+		status := core.RunStatusFailed
+		if errors.Is(backfillErr, context.Canceled) || errors.Is(backfillErr, context.DeadlineExceeded) {
+			status = core.RunStatusCancelled
+		}
+		e.logger.Info("backfill stopped", "backfill", backfill.ID, "error", backfillErr.Error())
+		_ = e.store.UpdateModelRun(modelRun.ID, core.ModelRunStatusFailed, rows, backfillErr.Error(), 0, executionMS)
+		_ = e.store.CompleteRun(run.ID, status, backfillErr.Error())
+		_ = e.store.CompleteBackfill(backfill.ID, core.BackfillStatusFailed, backfillErr.Error())
+	default:
+		e.logger.Info("backfill completed", "backfill", backfill.ID, "chunks", len(result.Built))
+		_ = e.store.UpdateModelRun(modelRun.ID, core.ModelRunStatusSuccess, rows, "", 0, executionMS)
+		_ = e.store.CompleteRun(run.ID, core.RunStatusCompleted, "")
+		_ = e.store.CompleteBackfill(backfill.ID, core.BackfillStatusCompleted, "")
+	}
+
+	if refreshed, err := e.store.GetBackfill(backfill.ID); err == nil {
+		result.Backfill = refreshed
+	}
+	if refreshed, err := e.store.GetRun(run.ID); err == nil {
+		result.Run = refreshed
+	}
+	return result, backfillErr
+}
+
+// backfillChunk builds the rows of an incremental model in the dates from
+// (inclusive) to (exclusive) between the model's pre and post statements,
+// creating the model's table from them if it does not exist. It returns the
+// number of rows of the chunk.
+func (e *Engine) backfillChunk(ctx context.Context, runID string, m *core.Model, model *core.PersistedModel, sql string, pre, post []string, column string, from, to time.Time) (int64, error) {
+	tableName := e.tableName(m.Path)
+	filter := backfillFilter(column, from, to)
+	chunkSQL := withAuditColumns(m, model, e.withSample(m, e.withMasking(m, withRowFilter(sql, filter))), runID)
+
+	// Chunks count against the target's and the model's resource class limits
+	release, err := e.acquireBuildSlots(ctx, e.cacheTarget(), m)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	var rowsAffected int64
+	build := func(ctx context.Context) error {
+		for i, stmt := range pre {
+			if err := e.execMeasured(ctx, stmt); err != nil {
+				return fmt.Errorf("pre statement %d of %s: %w", i+1, m.Path, err)
+			}
+		}
+
+		if _, err := e.db.GetTableMetadata(ctx, tableName); err != nil {
+			// The first chunk of a model without a table creates it
+			count, err := e.executeTable(ctx, m.Path, chunkSQL)
+			if err != nil {
+				return err
+			}
+			rowsAffected = count
+		} else {
+			if err := e.execMeasured(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", tableName, filter)); err != nil {
+				return fmt.Errorf("failed to delete rows of %s: %w", tableName, err)
+			}
+			if err := e.execMeasured(ctx, fmt.Sprintf("INSERT INTO %s %s", tableName, chunkSQL)); err != nil {
+				return fmt.Errorf("failed to insert rows of %s: %w", tableName, err)
+			}
+			count, err := e.countRows(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", tableName, filter))
+			if err != nil {
+				return fmt.Errorf("failed to count rows of %s: %w", tableName, err)
+			}
+			rowsAffected = count
+		}
+
+		for i, stmt := range post {
+			if err := e.execMeasured(ctx, stmt); err != nil {
+				return fmt.Errorf("post statement %d of %s: %w", i+1, m.Path, err)
+			}
+		}
+		return nil
+	}
+
+	// Deleting and inserting a chunk's rows is two statements, so the
+	// transaction policy's auto commits each chunk in a transaction
+	policy := e.transactionPolicy(m)
+	tx, ok := e.db.(adapter.Transactor)
+	if !ok && policy == core.TransactionAlways {
+		return 0, fmt.Errorf("model %s has transaction: always, but the %s adapter does not support transactions", m.Path, e.dbConfig.Type)
+	}
+	if ok && policy != core.TransactionNever {
+		err = tx.Transaction(ctx, build)
+	} else {
+		err = build(ctx)
+	}
+	return rowsAffected, err
+}
+
+// backfillChunks returns the chunks of a backfill's range, in date order. The
+// last chunk ends with the range, so it may be shorter than the others.
+func backfillChunks(backfill *core.Backfill) []*core.BackfillChunk {
+	var chunks []*core.BackfillChunk
+	for from := backfill.Start; from.Before(backfill.End); from = backfill.Chunk.Next(from) {
+		to := backfill.Chunk.Next(from)
+		if to.After(backfill.End) {
+			to = backfill.End
+		}
+		chunks = append(chunks, &core.BackfillChunk{BackfillID: backfill.ID, Start: from, End: to})
+	}
+	return chunks
+}
+
+// backfillFilter returns the condition selecting the rows of a backfill range
+// or chunk.
+func backfillFilter(column string, from, to time.Time) string {
+	return fmt.Sprintf("%s >= '%s' AND %s < '%s'", column, from.Format(time.DateOnly), column, to.Format(time.DateOnly))
+}
@@ -22,9 +22,33 @@ type RenderResult struct {
 // RenderModel renders the SQL for a model with all templates expanded.
 // This is the public API for SQL rendering.
 func (e *Engine) RenderModel(modelPath string) (string, error) {
+	m, model, err := e.renderTarget(modelPath)
+	if err != nil {
+		return "", err
+	}
+	return e.buildSQL(m, model)
+}
+
+// RenderSQL renders the templates of ad-hoc SQL, such as a query typed in
+// the console, as if it were a model of the root project: ref() resolves to
+// the referenced model's table.
+func (e *Engine) RenderSQL(sql string) (string, error) {
+	m := &core.Model{Path: "console", Name: "console", FilePath: "<console>", SQL: sql}
+	rendered, err := template.RenderString(sql, m.FilePath, e.createExecutionContext(m))
+	if err != nil {
+		return "", fmt.Errorf("render: %w", err)
+	}
+	return rendered, nil
+}
+
+// renderTarget looks up a model to render, enabled or not.
+func (e *Engine) renderTarget(modelPath string) (*core.Model, *core.PersistedModel, error) {
 	m, ok := e.models[modelPath]
 	if !ok {
-		return "", fmt.Errorf("model not found: %s", modelPath)
+		m, ok = e.disabled[modelPath]
+	}
+	if !ok {
+		return nil, nil, fmt.Errorf("model not found: %s", modelPath)
 	}
 
 	model, err := e.store.GetModelByPath(modelPath)
@@ -34,8 +58,7 @@ func (e *Engine) RenderModel(modelPath string) (string, error) {
 			Model: &core.Model{Path: modelPath, Name: m.Name},
 		}
 	}
-
-	return e.buildSQL(m, model)
+	return m, model, nil
 }
 
 // RenderModelTimed renders a model and returns timing information.
@@ -51,22 +74,84 @@ func (e *Engine) RenderModelTimed(modelPath string) RenderResult {
 	}
 }
 
-// buildSQL prepares the SQL for execution using template rendering.
-// Returns an error if template rendering fails - no silent fallback.
+// buildSQL prepares the SQL for execution using template rendering, with the
+// model's row filter applied, transpiled to the target's dialect. Returns an
+// error if template rendering fails - no silent fallback.
 func (e *Engine) buildSQL(m *core.Model, model *core.PersistedModel) (string, error) {
+	sql, err := e.filteredSQL(m)
+	if err != nil {
+		return "", err
+	}
+	return e.transpileSQL(m, sql, e.dialect)
+}
+
+// filteredSQL renders a model's SQL with its row filter applied.
+func (e *Engine) filteredSQL(m *core.Model) (string, error) {
+	rendered, err := e.renderSQL(m)
+	if err != nil || strings.TrimSpace(m.Where) == "" {
+		return rendered, err
+	}
+
+	where, err := template.RenderString(m.Where, m.FilePath, e.createExecutionContext(m))
+	if err != nil {
+		return "", fmt.Errorf("render %s: where: %w", m.Path, err)
+	}
+	return withRowFilter(rendered, where), nil
+}
+
+// renderSQL renders the templates of a model's SQL.
+func (e *Engine) renderSQL(m *core.Model) (string, error) {
+	rendered, _, err := e.renderSQLWithSourceMap(m)
+	return rendered, err
+}
+
+// renderSQLWithSourceMap renders the templates of a model's SQL, along with
+// the map of the rendered SQL back to the model's SQL.
+func (e *Engine) renderSQLWithSourceMap(m *core.Model) (string, *template.SourceMap, error) {
 	// Create execution context for this model
 	ctx := e.createExecutionContext(m)
 
 	// Render the template
-	rendered, err := template.RenderString(m.SQL, m.FilePath, ctx)
+	rendered, sm, err := template.RenderStringWithSourceMap(m.SQL, m.FilePath, ctx)
 	if err != nil {
 		e.logger.Error("template render failed",
 			"model", m.Path,
 			"file", m.FilePath,
 			"error", err)
-		return "", fmt.Errorf("render %s: %w", m.Path, err)
+		return "", nil, fmt.Errorf("render %s: %w", m.Path, err)
 	}
+	return rendered, sm, nil
+}
+
+// withRowFilter wraps a model's rendered SQL so it only returns the rows
+// matching a row filter. The filter is applied to the model's output, so it
+// refers to the model's columns.
+func withRowFilter(sql, where string) string {
+	sql = strings.TrimSuffix(strings.TrimSpace(sql), ";")
+	return fmt.Sprintf("SELECT * FROM (\n%s\n) AS leapsql_filtered WHERE %s", sql, strings.TrimSpace(where))
+}
 
+// buildStatements renders the statements run before and after a model's
+// build.
+func (e *Engine) buildStatements(m *core.Model) (pre, post []string, err error) {
+	if pre, err = e.renderStatements(m, m.PreStatements); err != nil {
+		return nil, nil, err
+	}
+	if post, err = e.renderStatements(m, m.PostStatements); err != nil {
+		return nil, nil, err
+	}
+	return pre, post, nil
+}
+
+func (e *Engine) renderStatements(m *core.Model, statements []string) ([]string, error) {
+	rendered := make([]string, len(statements))
+	for i, stmt := range statements {
+		sql, err := template.RenderString(stmt, m.FilePath, e.createExecutionContext(m))
+		if err != nil {
+			return nil, fmt.Errorf("render %s: %w", m.Path, err)
+		}
+		rendered[i] = sql
+	}
 	return rendered, nil
 }
 
@@ -85,8 +170,9 @@ func (e *Engine) createExecutionContext(m *core.Model) *starctx.ExecutionContext
 
 	// Build this info
 	thisInfo := &starctx.ThisInfo{
-		Name:   m.Name,
-		Schema: e.getModelSchema(m),
+		Name:     m.Name,
+		Schema:   e.getModelSchema(m),
+		Database: m.Database,
 	}
 
 	// Create context with macros
@@ -96,21 +182,58 @@ func (e *Engine) createExecutionContext(m *core.Model) *starctx.ExecutionContext
 		e.target,
 		thisInfo,
 		starctx.WithMacroProvider(e.macroRegistry),
+		starctx.WithRefResolver(func(project, model string, version int) (string, error) {
+			return e.resolveRef(m.Project, project, model, version)
+		}),
 	)
 
 	return ctx
 }
 
-// getModelSchema extracts the schema from a model path.
+// resolveRef resolves a ref() call in a model of project from to the referenced
+// model's table name. Without a project, models from the same project are preferred.
+func (e *Engine) resolveRef(from, project, model string, version int) (string, error) {
+	name := core.VersionedName(model, version)
+
+	var path string
+	var ok bool
+	if project == "" {
+		path, ok = e.registry.ResolveFrom(from, name)
+	} else {
+		path, ok = e.registry.ResolveRef(project, name)
+	}
+	if !ok {
+		return "", fmt.Errorf("%s: model not found", formatRef(project, model, version))
+	}
+	return e.tableName(path), nil
+}
+
+// getModelSchema returns the schema a model is built in, not qualified by
+// its database. Models with unqualified table names are built in the target
+// schema.
 func (e *Engine) getModelSchema(m *core.Model) string {
-	// If schema is explicitly set, use it
+	table := e.tableName(m.Path)
+	if m.Database != "" {
+		table = strings.TrimPrefix(table, m.Database+".")
+	}
+	if schema := tableSchema(table); schema != "" {
+		return schema
+	}
+	if e.target == nil {
+		return ""
+	}
+	return e.target.Schema
+}
+
+// customSchema returns the schema a model asks for: its frontmatter schema,
+// or else the directory of its path (e.g., "staging.customers" -> "staging").
+// It is "" for top-level models without a schema.
+func customSchema(m *core.Model) string {
 	if m.Schema != "" {
 		return m.Schema
 	}
-	// Otherwise derive from path (e.g., "staging.customers" -> "staging")
-	parts := strings.Split(m.Path, ".")
-	if len(parts) > 1 {
+	if parts := strings.Split(pathToTableName(m.Path), "."); len(parts) > 1 {
 		return parts[0]
 	}
-	return e.target.Schema // Default to target schema
+	return ""
 }
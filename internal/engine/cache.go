@@ -0,0 +1,200 @@
+package engine
+
+// cache.go - Skipping models whose build inputs are unchanged since their last build
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// SetFullRefresh sets whether runs rebuild models from scratch: incremental
+// models replace their tables instead of merging new rows, and the build cache
+// is ignored. Models set full_refresh in frontmatter to override it.
+func (e *Engine) SetFullRefresh(fullRefresh bool) {
+	e.fullRefresh = fullRefresh
+}
+
+// SetNoCache sets whether runs ignore the build cache, rebuilding every table
+// and view. Unlike a full refresh, incremental models still merge new rows.
+func (e *Engine) SetNoCache(noCache bool) {
+	e.noCache = noCache
+}
+
+// modelFullRefresh reports whether a model is rebuilt from scratch, applying
+// its frontmatter full_refresh override to the run's setting.
+func (e *Engine) modelFullRefresh(m *core.Model) bool {
+	if m.FullRefresh != nil {
+		return *m.FullRefresh
+	}
+	return e.fullRefresh
+}
+
+// cacheTarget identifies the database models are built in, so builds in one
+// target are never reused for another. It returns "" for in-memory databases,
+// whose tables do not outlive the engine, which disables the build cache.
+func (e *Engine) cacheTarget() string {
+	cfg := e.dbConfig
+	switch {
+	case cfg.Host != "":
+		return fmt.Sprintf("%s://%s:%d/%s/%s", cfg.Type, cfg.Host, cfg.Port, cfg.Database, cfg.Schema)
+	case cfg.Path != "" && cfg.Path != ":memory:":
+		path, err := filepath.Abs(cfg.Path)
+		if err != nil {
+			path = cfg.Path
+		}
+		return fmt.Sprintf("%s://%s/%s", cfg.Type, path, cfg.Schema)
+	default:
+		return ""
+	}
+}
+
+// buildHash hashes everything a model's build depends on: the target, the
+// compiled SQL, materialization and sample, the builds of its upstream models,
+// the contents of the seeds it reads and when the raw tables and files it
+// reads last changed. builtBy maps upstream model paths to the run that last
+// built them.
+func (e *Engine) buildHash(target string, p preparedModel, builtBy map[string]string, snapshots []core.SourceSnapshot) string {
+	h := sha256.New()
+	m := p.model
+
+	fmt.Fprintf(h, "target\x00%s\x00", target)
+	fmt.Fprintf(h, "sql\x00%s\x00", p.sql)
+	for _, stmt := range p.pre {
+		fmt.Fprintf(h, "pre\x00%s\x00", stmt)
+	}
+	for _, stmt := range p.post {
+		fmt.Fprintf(h, "post\x00%s\x00", stmt)
+	}
+	fmt.Fprintf(h, "materialized\x00%s\x00%s\x00%s\x00", m.Materialized, m.UniqueKey, strconv.FormatBool(m.AuditColumns))
+	if m.PartitionBy != nil {
+		fmt.Fprintf(h, "partition\x00%s\x00", m.PartitionBy.Expr())
+	}
+	if m.Retention != nil {
+		fmt.Fprintf(h, "retention\x00%s\x00%s\x00", m.Retention.Column, m.Retention.Keep)
+	}
+	fmt.Fprintf(h, "sample\x00%d\x00", e.sampleRows(m))
+	if len(m.PII) > 0 {
+		fmt.Fprintf(h, "masking\x00%s\x00%s\x00%s\x00", e.masking.Mode, e.masking.Policy, strings.Join(m.PII, ","))
+	}
+
+	parents := e.graph.GetParents(m.Path)
+	sort.Strings(parents)
+	for _, parent := range parents {
+		fmt.Fprintf(h, "parent\x00%s\x00%s\x00", parent, builtBy[parent])
+	}
+
+	sources := append([]string(nil), m.Sources...)
+	sort.Strings(sources)
+	for _, source := range sources {
+		if hash := e.seedHash(source); hash != "" {
+			fmt.Fprintf(h, "seed\x00%s\x00%s\x00", source, hash)
+		}
+	}
+
+	snapshots = slices.Clone(snapshots)
+	slices.SortFunc(snapshots, func(a, b core.SourceSnapshot) int { return strings.Compare(a.Source, b.Source) })
+	for _, s := range snapshots {
+		fmt.Fprintf(h, "source\x00%s\x00%s\x00", s.Source, s.ChangedAt.UTC().Format(time.RFC3339Nano))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// seedHash returns the content hash of the seed loaded as a table, or "" if
+// the table is not a seed.
+func (e *Engine) seedHash(table string) string {
+	if e.seedsDir == "" || strings.ContainsAny(table, `/\`) {
+		return ""
+	}
+	f, err := os.Open(filepath.Join(e.seedsDir, table+".csv")) //nolint:gosec // Seed path is derived from the project seeds directory
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// upstreamBuild returns the run that last built an upstream model in the
+// target, or "" if it has not been built.
+func (e *Engine) upstreamBuild(target, modelPath string) string {
+	persisted, err := e.store.GetModelByPath(modelPath)
+	if err != nil || persisted == nil {
+		return ""
+	}
+	build, err := e.store.GetModelBuild(persisted.ID, target)
+	if err != nil || build == nil {
+		return ""
+	}
+	return build.RunID
+}
+
+// cachedBuild returns the previous build of a model if it can be reused: its
+// build hash is unchanged and its relation still exists. Incremental models
+// are never reused, since each run appends new data to them, and neither are
+// external models, since their commands may read data LeapSQL does not track,
+// nor models reading sources whose changes are unknown.
+func (e *Engine) cachedBuild(ctx context.Context, target, hash string, p preparedModel, unknown []string) *core.ModelBuild {
+	if target == "" || e.noCache || len(unknown) > 0 || e.modelFullRefresh(p.model) || p.model.Materialized == "incremental" || p.model.External != nil {
+		return nil
+	}
+	// Production data read through deferral may have changed since the build
+	for _, parent := range e.graph.GetParents(p.model.Path) {
+		if slices.Contains(e.deferred, parent) {
+			return nil
+		}
+	}
+
+	build, err := e.store.GetModelBuild(p.persisted.ID, target)
+	if err != nil || build == nil || build.BuildHash != hash {
+		return nil
+	}
+
+	if _, err := e.db.GetTableMetadata(ctx, e.tableName(p.model.Path)); err != nil {
+		return nil
+	}
+	return build
+}
+
+// recordBuild saves a successful build for the build cache.
+func (e *Engine) recordBuild(target, hash, runID string, p preparedModel) {
+	if target == "" {
+		return
+	}
+	err := e.store.SaveModelBuild(&core.ModelBuild{
+		ModelID:   p.persisted.ID,
+		Target:    target,
+		BuildHash: hash,
+		RunID:     runID,
+	})
+	if err != nil {
+		e.logger.Debug("failed to record model build", "model", p.model.Path, "error", err)
+	}
+}
+
+// forgetBuild removes the build record of a model that failed to build, since
+// its relation may be gone or partially written.
+func (e *Engine) forgetBuild(target string, p preparedModel) {
+	if target == "" {
+		return
+	}
+	if err := e.store.DeleteModelBuild(p.persisted.ID, target); err != nil {
+		e.logger.Debug("failed to delete model build", "model", p.model.Path, "error", err)
+	}
+}
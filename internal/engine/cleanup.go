@@ -0,0 +1,131 @@
+package engine
+
+// cleanup.go - Recovering from runs interrupted by a crash
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// interruptedError is recorded on runs and model runs left running by a
+// process that exited before completing them.
+const interruptedError = "interrupted: the run's process exited before it completed"
+
+// CleanupResult describes what Cleanup removed.
+type CleanupResult struct {
+	// InterruptedRuns are the IDs of orphaned runs marked as failed
+	InterruptedRuns []string
+	// PrunedModelRuns counts the deleted model runs whose run or model no longer exists
+	PrunedModelRuns int64
+	// DroppedTables are the temp and staging tables of interrupted builds that were dropped
+	DroppedTables []string
+}
+
+// Cleanup recovers from runs interrupted by a crash: it marks orphaned runs
+// as failed, deletes model runs whose run or model no longer exists and drops
+// the temp tables left by interrupted incremental builds and the staging
+// tables left by interrupted table builds. It holds the run
+// lock, so it never touches a run in progress.
+func (e *Engine) Cleanup(ctx context.Context) (*CleanupResult, error) {
+	if _, ok := e.store.(core.Locker); !ok || e.lock.Disabled {
+		return nil, fmt.Errorf("cleanup requires state locking to tell orphaned runs from runs in progress")
+	}
+
+	unlock, orphaned, err := e.lockRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	result := &CleanupResult{InterruptedRuns: orphaned}
+
+	if result.PrunedModelRuns, err = e.store.DeleteOrphanedModelRuns(); err != nil {
+		return nil, fmt.Errorf("failed to prune model runs: %w", err)
+	}
+
+	if err := e.ensureDBConnected(ctx); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(e.models))
+	for path := range e.models {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		m := e.models[path]
+		tableName := e.tableName(path)
+
+		var leftovers []string
+		if m.Materialized != core.MaterializationView {
+			leftovers = append(leftovers, stagingTable(tableName))
+		}
+		if m.Materialized == core.MaterializationIncremental && (m.UniqueKey != "" || m.PartitionBy != nil) {
+			leftovers = append(leftovers, incrementalTempTable(tableName))
+		}
+
+		for _, table := range leftovers {
+			if _, err := e.db.GetTableMetadata(ctx, table); err != nil {
+				continue // Not left behind
+			}
+			if err := e.db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", table)); err != nil {
+				return nil, fmt.Errorf("failed to drop %s: %w", table, err)
+			}
+			result.DroppedTables = append(result.DroppedTables, table)
+		}
+	}
+
+	return result, nil
+}
+
+// recoverOrphanedRuns marks the runs still recorded as running as failed,
+// along with their unfinished model runs. It must only be called while
+// holding the run lock: a live run would hold it, so these runs' processes
+// exited without completing them. It returns the IDs of the recovered runs.
+func (e *Engine) recoverOrphanedRuns() ([]string, error) {
+	runs, err := e.store.ListRuns(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	var recovered []string
+	for _, run := range runs {
+		if run.Status != core.RunStatusRunning {
+			continue
+		}
+
+		modelRuns, err := e.store.GetModelRunsForRun(run.ID)
+		if err != nil {
+			return recovered, fmt.Errorf("failed to get model runs for run %s: %w", run.ID, err)
+		}
+		for _, mr := range modelRuns {
+			status := core.ModelRunStatusFailed
+			switch mr.Status {
+			case core.ModelRunStatusPending:
+				status = core.ModelRunStatusSkipped
+			case core.ModelRunStatusRunning:
+			default:
+				continue
+			}
+			if err := e.store.UpdateModelRun(mr.ID, status, 0, interruptedError, mr.RenderMS, mr.ExecutionMS); err != nil {
+				return recovered, fmt.Errorf("failed to update model run %s: %w", mr.ID, err)
+			}
+			if status == core.ModelRunStatusSkipped {
+				if err := e.store.UpdateModelRunSkip(mr.ID, core.SkipReasonInterrupted, ""); err != nil {
+					return recovered, fmt.Errorf("failed to update model run %s: %w", mr.ID, err)
+				}
+			}
+		}
+
+		if err := e.store.CompleteRun(run.ID, core.RunStatusFailed, interruptedError); err != nil {
+			return recovered, fmt.Errorf("failed to complete run %s: %w", run.ID, err)
+		}
+		e.logger.Warn("marked orphaned run as failed", "run_id", run.ID, "started_at", run.StartedAt)
+		recovered = append(recovered, run.ID)
+	}
+	return recovered, nil
+}
@@ -0,0 +1,104 @@
+package engine
+
+// clone.go - Cloning production tables into the target database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/leapstack-labs/leapsql/pkg/adapter"
+)
+
+// Clone methods.
+const (
+	// CloneMethodClone is a clone made by the adapter without copying data.
+	CloneMethodClone = "clone"
+	// CloneMethodCopy is a copy made with CREATE TABLE AS SELECT.
+	CloneMethodCopy = "copy"
+)
+
+// CloneResult describes the outcome of cloning one model.
+type CloneResult struct {
+	// Model is the model path
+	Model string
+	// Method is how the table was cloned (CloneMethodClone or CloneMethodCopy), empty if skipped or failed
+	Method string
+	// Skipped explains why the model was not cloned (empty if it was attempted)
+	Skipped string
+	// Error is the clone error, if any
+	Error error
+}
+
+// Clone copies the production tables of the given models into the target
+// database, replacing existing tables. Adapters that implement adapter.Cloner
+// clone without copying data; others copy with CREATE TABLE AS SELECT.
+// Views are skipped: run rebuilds them from the cloned tables.
+func (e *Engine) Clone(ctx context.Context, database string, modelPaths []string) ([]CloneResult, error) {
+	if err := e.ensureDBConnected(ctx); err != nil {
+		return nil, err
+	}
+
+	detach, err := e.attachProduction(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+	defer detach()
+
+	paths := append([]string(nil), modelPaths...)
+	sort.Strings(paths)
+
+	cloner, canClone := e.db.(adapter.Cloner)
+
+	results := make([]CloneResult, 0, len(paths))
+	for _, path := range paths {
+		m, ok := e.models[path]
+		if !ok {
+			results = append(results, CloneResult{Model: path, Error: fmt.Errorf("model not found: %s", path)})
+			continue
+		}
+		if m.Materialized == "view" {
+			results = append(results, CloneResult{Model: path, Skipped: "view"})
+			continue
+		}
+		if m.Database != "" {
+			// Tables of attached databases are not part of the production database file
+			results = append(results, CloneResult{Model: path, Skipped: "attached database " + m.Database})
+			continue
+		}
+
+		target := e.tableName(path)
+		source := productionCatalog + "." + e.defaultTableName(path)
+
+		if schema := tableSchema(target); schema != "" {
+			_ = e.db.Exec(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schema))
+		}
+
+		result := CloneResult{Model: path}
+		if canClone {
+			result.Method = CloneMethodClone
+			result.Error = cloner.CloneTable(ctx, source, target)
+		} else {
+			result.Method = CloneMethodCopy
+			result.Error = e.copyTable(ctx, source, target)
+		}
+		if result.Error != nil {
+			result.Method = ""
+		}
+
+		e.logger.Debug("cloned model", "model", path, "method", result.Method, "error", result.Error)
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// copyTable replaces target with a copy of the source table.
+func (e *Engine) copyTable(ctx context.Context, source, target string) error {
+	_ = e.db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", target))
+
+	if err := e.db.Exec(ctx, fmt.Sprintf("CREATE TABLE %s AS SELECT * FROM %s", target, source)); err != nil {
+		return fmt.Errorf("failed to copy %s: %w", source, err)
+	}
+	return nil
+}
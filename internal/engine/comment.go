@@ -0,0 +1,94 @@
+package engine
+
+// comment.go - Attributing executed SQL to runs and models with query comments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"text/template"
+
+	"github.com/leapstack-labs/leapsql/pkg/adapter"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// DefaultQueryComment is the query comment template used when none is
+// configured. It renders all fields as JSON, e.g.
+// {"app":"leapsql","project":"jaffle","model":"staging.orders",...}
+const DefaultQueryComment = "{{ json . }}"
+
+// QueryCommentOff disables query comments.
+const QueryCommentOff = "off"
+
+// QueryCommentData holds the fields available to query comment templates.
+type QueryCommentData struct {
+	App         string `json:"app"`
+	Project     string `json:"project,omitempty"`
+	Model       string `json:"model,omitempty"`
+	RunID       string `json:"run_id,omitempty"`
+	User        string `json:"user,omitempty"`
+	Environment string `json:"environment"`
+	Target      string `json:"target"`
+}
+
+// parseQueryComment parses a query comment template. It returns nil if
+// query comments are off.
+func parseQueryComment(text string) (*template.Template, error) {
+	switch strings.TrimSpace(text) {
+	case QueryCommentOff:
+		return nil, nil
+	case "":
+		text = DefaultQueryComment
+	}
+
+	tmpl, err := template.New("query_comment").Funcs(template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query_comment template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// withQueryComment returns a context whose statements carry the query comment
+// of a run and model. Either may be empty for statements outside a model build.
+func (e *Engine) withQueryComment(ctx context.Context, runID, modelPath string) context.Context {
+	if e.queryComment == nil {
+		return ctx
+	}
+
+	project, path := core.SplitModelID(modelPath)
+	if project == "" {
+		project = e.projectName
+	}
+
+	var sb strings.Builder
+	err := e.queryComment.Execute(&sb, QueryCommentData{
+		App:         "leapsql",
+		Project:     project,
+		Model:       path,
+		RunID:       runID,
+		User:        e.user,
+		Environment: e.environment,
+		Target:      e.dbConfig.Type,
+	})
+	if err != nil {
+		e.logger.Debug("failed to render query comment", "model", modelPath, "error", err)
+		return ctx
+	}
+	return adapter.WithQueryComment(ctx, sb.String())
+}
+
+// currentUser returns the name of the user running LeapSQL.
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
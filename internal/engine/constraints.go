@@ -0,0 +1,123 @@
+package engine
+
+// constraints.go - Checking declared not_null and unique tests on built tables
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/adapter"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// Constraint modes select how the not_null and unique tests declared in a
+// model's frontmatter are checked after the model is built.
+const (
+	// ConstraintModeAssert checks the tests with queries after each build (default).
+	ConstraintModeAssert = "assert"
+	// ConstraintModeEnforce adds the tests as constraints to the built table, so
+	// later writes that violate them fail too. Adapters that cannot add
+	// constraints fall back to assertions.
+	ConstraintModeEnforce = "enforce"
+	// ConstraintModeOff does not check the tests.
+	ConstraintModeOff = "off"
+)
+
+// SetConstraintMode selects how declared tests are checked in subsequent runs.
+func (e *Engine) SetConstraintMode(mode string) error {
+	switch mode {
+	case ConstraintModeAssert, ConstraintModeEnforce, ConstraintModeOff:
+		e.constraintMode = mode
+		return nil
+	}
+	return fmt.Errorf("invalid constraint mode %q: must be one of: %s, %s, %s",
+		mode, ConstraintModeAssert, ConstraintModeEnforce, ConstraintModeOff)
+}
+
+// checkConstraints checks the not_null and unique tests of a built model.
+// Views are not checked.
+func (e *Engine) checkConstraints(ctx context.Context, m *core.Model) error {
+	if m.Materialized == "view" || e.constraintMode == ConstraintModeOff {
+		return nil
+	}
+
+	var notNull []string
+	var unique [][]string
+	for _, test := range m.Tests {
+		notNull = append(notNull, test.NotNull...)
+		if len(test.Unique) > 0 {
+			unique = append(unique, test.Unique)
+		}
+	}
+	if len(notNull) == 0 && len(unique) == 0 {
+		return nil
+	}
+
+	tableName := e.tableName(m.Path)
+
+	if constrainer, ok := e.db.(adapter.Constrainer); ok && e.constraintMode == ConstraintModeEnforce {
+		return enforceConstraints(ctx, constrainer, tableName, notNull, unique)
+	}
+	return e.assertConstraints(ctx, tableName, notNull, unique)
+}
+
+// enforceConstraints adds the tests as constraints to the table.
+func enforceConstraints(ctx context.Context, constrainer adapter.Constrainer, tableName string, notNull []string, unique [][]string) error {
+	for _, column := range notNull {
+		if err := constrainer.SetNotNull(ctx, tableName, column); err != nil {
+			return fmt.Errorf("not_null constraint on %s.%s failed: %w", tableName, column, err)
+		}
+	}
+	for _, columns := range unique {
+		if err := constrainer.AddUnique(ctx, tableName, columns); err != nil {
+			return fmt.Errorf("unique constraint on %s (%s) failed: %w", tableName, strings.Join(columns, ", "), err)
+		}
+	}
+	return nil
+}
+
+// assertConstraints queries the table for rows that violate the tests.
+func (e *Engine) assertConstraints(ctx context.Context, tableName string, notNull []string, unique [][]string) error {
+	var errs []error
+	for _, column := range notNull {
+		count, err := e.countRows(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s IS NULL", tableName, column))
+		if err != nil {
+			return fmt.Errorf("not_null test on %s.%s failed: %w", tableName, column, err)
+		}
+		if count > 0 {
+			errs = append(errs, fmt.Errorf("not_null test failed: %s.%s has %d null rows", tableName, column, count))
+		}
+	}
+	for _, columns := range unique {
+		cols := strings.Join(columns, ", ")
+		count, err := e.countRows(ctx, fmt.Sprintf(
+			"SELECT COUNT(*) FROM (SELECT %s FROM %s GROUP BY %s HAVING COUNT(*) > 1) AS duplicates",
+			cols, tableName, cols))
+		if err != nil {
+			return fmt.Errorf("unique test on %s (%s) failed: %w", tableName, cols, err)
+		}
+		if count > 0 {
+			errs = append(errs, fmt.Errorf("unique test failed: %s has %d duplicate values of (%s)", tableName, count, cols))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// countRows runs a query returning a single count.
+func (e *Engine) countRows(ctx context.Context, query string) (int64, error) {
+	rows, err := e.db.Query(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var count int64
+	if rows.Next() {
+		if err := rows.Scan(&count); err != nil {
+			return 0, err
+		}
+	}
+	return count, rows.Err()
+}
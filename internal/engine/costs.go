@@ -0,0 +1,181 @@
+package engine
+
+// costs.go - Aggregating the recorded warehouse cost of model runs
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// Cost report groupings.
+const (
+	CostByModel = "model"
+	CostByTag   = "tag"
+	CostByOwner = "owner"
+)
+
+// Cost report intervals. An empty interval aggregates the whole report period.
+const (
+	CostIntervalDay   = "day"
+	CostIntervalWeek  = "week"
+	CostIntervalMonth = "month"
+)
+
+// Keys of models without tags or owner in cost reports.
+const (
+	costKeyUntagged = "(untagged)"
+	costKeyNoOwner  = "(no owner)"
+)
+
+// CostReportOptions configures a cost report.
+type CostReportOptions struct {
+	// By groups the costs by model, tag or owner (default model)
+	By string
+	// Since excludes runs started before this time (zero includes all runs)
+	Since time.Time
+	// Environment only includes runs in this environment (empty includes all)
+	Environment string
+	// Interval splits the costs into day, week or month periods (empty for none)
+	Interval string
+}
+
+// CostSummary is the aggregated cost of the model runs of one key in one period.
+type CostSummary struct {
+	// Period is the start date of the period (empty without an interval)
+	Period string
+	// Key is the model path, tag or owner
+	Key          string
+	ModelRuns    int
+	BytesScanned int64
+	SlotMS       int64
+	ExecutionMS  int64
+}
+
+// CostReport aggregates the warehouse cost recorded for model runs by model,
+// tag or owner. A model run with several tags counts toward each of them.
+// Summaries are ordered by period, then by bytes scanned, highest first.
+func (e *Engine) CostReport(opts CostReportOptions) ([]CostSummary, error) {
+	if opts.By == "" {
+		opts.By = CostByModel
+	}
+	switch opts.By {
+	case CostByModel, CostByTag, CostByOwner:
+	default:
+		return nil, fmt.Errorf("invalid cost grouping %q: must be one of: %s, %s, %s",
+			opts.By, CostByModel, CostByTag, CostByOwner)
+	}
+	switch opts.Interval {
+	case "", CostIntervalDay, CostIntervalWeek, CostIntervalMonth:
+	default:
+		return nil, fmt.Errorf("invalid cost interval %q: must be one of: %s, %s, %s",
+			opts.Interval, CostIntervalDay, CostIntervalWeek, CostIntervalMonth)
+	}
+
+	runs, err := e.store.ListRuns(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	models := make(map[string]*core.PersistedModel)
+	summaries := make(map[[2]string]*CostSummary)
+
+	for _, run := range runs {
+		if opts.Environment != "" && run.Environment != opts.Environment {
+			continue
+		}
+		if !opts.Since.IsZero() && run.StartedAt.Before(opts.Since) {
+			continue
+		}
+
+		modelRuns, err := e.store.GetModelRunsForRun(run.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get model runs for run %s: %w", run.ID, err)
+		}
+
+		period := costPeriod(run.StartedAt, opts.Interval)
+		for _, mr := range modelRuns {
+			if mr.Status == core.ModelRunStatusSkipped || mr.Status == core.ModelRunStatusPending {
+				continue
+			}
+
+			model, ok := models[mr.ModelID]
+			if !ok {
+				// Models deleted since the run are reported under their ID
+				model, _ = e.store.GetModelByID(mr.ModelID)
+				models[mr.ModelID] = model
+			}
+
+			for _, key := range costKeys(model, mr.ModelID, opts.By) {
+				id := [2]string{period, key}
+				s, ok := summaries[id]
+				if !ok {
+					s = &CostSummary{Period: period, Key: key}
+					summaries[id] = s
+				}
+				s.ModelRuns++
+				s.BytesScanned += mr.BytesScanned
+				s.SlotMS += mr.SlotMS
+				s.ExecutionMS += mr.ExecutionMS
+			}
+		}
+	}
+
+	report := make([]CostSummary, 0, len(summaries))
+	for _, s := range summaries {
+		report = append(report, *s)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		if a.BytesScanned != b.BytesScanned {
+			return a.BytesScanned > b.BytesScanned
+		}
+		if a.SlotMS != b.SlotMS {
+			return a.SlotMS > b.SlotMS
+		}
+		return a.Key < b.Key
+	})
+	return report, nil
+}
+
+// costKeys returns the keys a model run's cost is grouped under.
+func costKeys(model *core.PersistedModel, modelID, by string) []string {
+	if model == nil || model.Model == nil {
+		return []string{modelID}
+	}
+	switch by {
+	case CostByTag:
+		if len(model.Tags) == 0 {
+			return []string{costKeyUntagged}
+		}
+		return model.Tags
+	case CostByOwner:
+		if model.Owner == "" {
+			return []string{costKeyNoOwner}
+		}
+		return []string{model.Owner}
+	default:
+		return []string{model.Path}
+	}
+}
+
+// costPeriod returns the start date of the interval containing t.
+func costPeriod(t time.Time, interval string) string {
+	t = t.UTC()
+	switch interval {
+	case CostIntervalDay:
+	case CostIntervalWeek:
+		// Weeks start on Monday
+		t = t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	case CostIntervalMonth:
+		t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
@@ -0,0 +1,106 @@
+package engine
+
+// critical_path.go - Expected model runtimes, the critical path and scheduling by them
+
+import (
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/internal/dag"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// runtimeHistory is the number of recent runs whose builds of a model are
+// averaged into its expected runtime.
+const runtimeHistory = 10
+
+// CriticalPathStep is a model on the critical path.
+type CriticalPathStep struct {
+	Model string
+	// ExpectedMS is the model's average execution time in recent runs
+	ExpectedMS int64
+	// Builds is the number of recent builds averaged (0 if never built)
+	Builds int
+}
+
+// CriticalPath is the chain of dependent models with the longest expected
+// runtime: however many models run in parallel, a run takes at least
+// TotalMS. Speeding up models on it shortens runs; speeding up others does not.
+type CriticalPath struct {
+	Steps   []CriticalPathStep
+	TotalMS int64
+	// Bottleneck is the step with the longest expected runtime (empty without history)
+	Bottleneck string
+}
+
+// ExpectedRuntimes returns the average execution time in milliseconds of the
+// successful builds of each model in the last runs, and how many builds each
+// average covers. Models not built in those runs are missing.
+func (e *Engine) ExpectedRuntimes() (map[string]int64, map[string]int, error) {
+	runs, err := e.store.ListRuns(runtimeHistory)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+
+	totals := make(map[string]int64)
+	builds := make(map[string]int)
+	for _, run := range runs {
+		modelRuns, err := e.store.GetModelRunsWithModelInfo(run.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get model runs for run %s: %w", run.ID, err)
+		}
+		for _, mr := range modelRuns {
+			if mr.Status != core.ModelRunStatusSuccess {
+				continue
+			}
+			totals[mr.ModelPath] += mr.ExecutionMS
+			builds[mr.ModelPath]++
+		}
+	}
+
+	expected := make(map[string]int64, len(totals))
+	for path, total := range totals {
+		expected[path] = total / int64(builds[path])
+	}
+	return expected, builds, nil
+}
+
+// CriticalPath returns the models' critical path, weighted by their expected
+// runtimes. Models never built in recent runs count as instant.
+func (e *Engine) CriticalPath() (*CriticalPath, error) {
+	expected, builds, err := e.ExpectedRuntimes()
+	if err != nil {
+		return nil, err
+	}
+
+	path, total, err := e.graph.CriticalPath(expected)
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &CriticalPath{TotalMS: total}
+	var slowest int64
+	for _, model := range path {
+		step := CriticalPathStep{Model: model, ExpectedMS: expected[model], Builds: builds[model]}
+		if step.ExpectedMS > slowest {
+			slowest = step.ExpectedMS
+			cp.Bottleneck = model
+		}
+		cp.Steps = append(cp.Steps, step)
+	}
+	return cp, nil
+}
+
+// scheduleOrder returns the order to build a graph's models in. With timing
+// history, models heading the longest expected chains go first among those
+// whose dependencies are built; otherwise models are built in topological
+// order.
+func (e *Engine) scheduleOrder(g *dag.Graph) ([]*dag.Node, error) {
+	expected, _, err := e.ExpectedRuntimes()
+	if err != nil {
+		e.logger.Debug("failed to get expected runtimes", "error", err)
+	}
+	if len(expected) == 0 {
+		return g.TopologicalSort()
+	}
+	return g.ScheduleOrder(expected)
+}
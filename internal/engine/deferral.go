@@ -0,0 +1,197 @@
+package engine
+
+// deferral.go - Deferring unbuilt upstream models to production
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/state"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// productionCatalog is the name the production database is attached under.
+const productionCatalog = "leapsql_prod"
+
+// DeferConfig points selected runs at a production environment. Upstream models
+// that are not part of the run and have not been built in the target database
+// are read from the production database instead of being rebuilt.
+type DeferConfig struct {
+	// Environment is the production environment whose runs are looked up in the state
+	Environment string
+	// StatePath is the state database recording production runs (empty uses the engine's state)
+	StatePath string
+	// Database is the production DuckDB database deferred models are read from
+	Database string
+}
+
+// SetDefer enables deferral to production for subsequent RunSelected calls.
+// Pass nil to disable deferral.
+func (e *Engine) SetDefer(cfg *DeferConfig) {
+	e.deferTo = cfg
+}
+
+// DeferredModels returns the models the last RunSelected read from production.
+func (e *Engine) DeferredModels() []string {
+	return e.deferred
+}
+
+// deferUpstream makes the parents of the affected models that are missing from
+// the target database readable from production. Each deferred model gets a
+// view in the target database over its production table. The returned cleanup
+// function drops the views and detaches the production database.
+func (e *Engine) deferUpstream(ctx context.Context, affected []string) ([]string, func(), error) {
+	noop := func() {}
+
+	// Parents outside the run that have not been built in the target database
+	inRun := make(map[string]bool, len(affected))
+	for _, path := range affected {
+		inRun[path] = true
+	}
+	seen := make(map[string]bool)
+	var missing []string
+	for _, path := range affected {
+		for _, parent := range e.graph.GetParents(path) {
+			if inRun[parent] || seen[parent] {
+				continue
+			}
+			seen[parent] = true
+			if m, ok := e.models[parent]; ok && m.Database != "" {
+				continue // Attached databases are shared, not deferred
+			}
+			if _, err := e.db.GetTableMetadata(ctx, e.tableName(parent)); err != nil {
+				missing = append(missing, parent)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil, noop, nil
+	}
+	sort.Strings(missing)
+
+	if err := e.checkBuiltInProduction(missing); err != nil {
+		return nil, noop, err
+	}
+
+	detach, err := e.attachProduction(ctx, e.deferTo.Database)
+	if err != nil {
+		return nil, noop, err
+	}
+
+	var views []string
+	cleanup := func() {
+		for _, view := range views {
+			_ = e.db.Exec(ctx, fmt.Sprintf("DROP VIEW IF EXISTS %s", view))
+		}
+		detach()
+	}
+
+	for _, path := range missing {
+		tableName := e.tableName(path)
+		if schema := tableSchema(tableName); schema != "" {
+			_ = e.db.Exec(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schema))
+		}
+
+		createSQL := fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s.%s", tableName, productionCatalog, e.defaultTableName(path))
+		if err := e.db.Exec(ctx, createSQL); err != nil {
+			cleanup()
+			return nil, noop, fmt.Errorf("failed to defer %s to production: %w", path, err)
+		}
+		views = append(views, tableName)
+
+		e.logger.Debug("deferred model to production", "model", path, "environment", e.deferTo.Environment)
+	}
+
+	return missing, cleanup, nil
+}
+
+// attachProduction attaches the production database read-only under
+// productionCatalog. The returned function detaches it.
+func (e *Engine) attachProduction(ctx context.Context, database string) (func(), error) {
+	if e.dbConfig.Type != "duckdb" {
+		return nil, fmt.Errorf("reading from production requires a duckdb target, got %q", e.dbConfig.Type)
+	}
+	if database == "" {
+		return nil, fmt.Errorf("no production database configured")
+	}
+
+	detach, err := e.attachDatabase(ctx, database, productionCatalog)
+	if err != nil {
+		return nil, fmt.Errorf("failed to attach production database: %w", err)
+	}
+	return detach, nil
+}
+
+// attachDatabase attaches a DuckDB database read-only under catalog. The
+// returned function detaches it.
+func (e *Engine) attachDatabase(ctx context.Context, database, catalog string) (func(), error) {
+	attach := fmt.Sprintf("ATTACH IF NOT EXISTS '%s' AS %s (READ_ONLY)",
+		strings.ReplaceAll(database, "'", "''"), catalog)
+	if err := e.db.Exec(ctx, attach); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_ = e.db.Exec(ctx, fmt.Sprintf("DETACH DATABASE IF EXISTS %s", catalog))
+	}, nil
+}
+
+// checkBuiltInProduction returns an error unless every model has been built
+// successfully in the production environment.
+func (e *Engine) checkBuiltInProduction(paths []string) error {
+	store := e.store
+	if e.deferTo.StatePath != "" {
+		prodStore := state.NewSQLiteStore(e.logger)
+		if err := prodStore.Open(e.deferTo.StatePath); err != nil {
+			return fmt.Errorf("failed to open production state: %w", err)
+		}
+		defer func() { _ = prodStore.Close() }()
+		store = prodStore
+	}
+
+	built, err := builtModels(store, e.deferTo.Environment)
+	if err != nil {
+		return fmt.Errorf("failed to read production state: %w", err)
+	}
+
+	var unbuilt []string
+	for _, path := range paths {
+		model, err := store.GetModelByPath(path)
+		if err != nil || model == nil || !built[model.ID] {
+			unbuilt = append(unbuilt, path)
+		}
+	}
+	if len(unbuilt) > 0 {
+		return fmt.Errorf("cannot defer to environment %q: not built in production: %s",
+			e.deferTo.Environment, strings.Join(unbuilt, ", "))
+	}
+	return nil
+}
+
+// builtModels returns the IDs of the models with a successful model run in env.
+// An empty env matches runs in every environment.
+func builtModels(store core.Store, env string) (map[string]bool, error) {
+	runs, err := store.ListRuns(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	built := make(map[string]bool)
+	for _, run := range runs {
+		if env != "" && run.Environment != env {
+			continue
+		}
+		modelRuns, err := store.GetModelRunsForRun(run.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, mr := range modelRuns {
+			if mr.Status == core.ModelRunStatusSuccess {
+				built[mr.ModelID] = true
+			}
+		}
+	}
+	return built, nil
+}
@@ -0,0 +1,361 @@
+package engine
+
+// diff.go - Comparing a model's data between two databases
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Catalogs the diffed databases are attached under.
+const (
+	diffCatalogA = "leapsql_diff_a"
+	diffCatalogB = "leapsql_diff_b"
+)
+
+// defaultDiffSampleSize is the number of mismatched rows sampled per side.
+const defaultDiffSampleSize = 5
+
+// DiffOptions configures a data diff.
+type DiffOptions struct {
+	// DatabaseA is the first DuckDB database (e.g., dev)
+	DatabaseA string
+	// DatabaseB is the second DuckDB database (e.g., prod)
+	DatabaseB string
+	// SampleSize is the maximum number of mismatched rows sampled per side (default 5)
+	SampleSize int
+}
+
+// ColumnStats holds the aggregates of one column.
+type ColumnStats struct {
+	Nulls    int64
+	Distinct int64
+	Min      string
+	Max      string
+	Sum      string // Numeric columns only
+}
+
+// ColumnDiff compares one column between the two databases.
+type ColumnDiff struct {
+	Name string
+	// TypeA and TypeB are the column types (empty if the column is missing)
+	TypeA string
+	TypeB string
+	// A and B are the column aggregates (nil if the column is missing)
+	A *ColumnStats
+	B *ColumnStats
+}
+
+// Changed reports whether the column differs between the two databases.
+func (c ColumnDiff) Changed() bool {
+	if c.A == nil || c.B == nil {
+		return true
+	}
+	return c.TypeA != c.TypeB || *c.A != *c.B
+}
+
+// DiffResult is the comparison of a model's table in two databases.
+type DiffResult struct {
+	Model string
+	RowsA int64
+	RowsB int64
+	// Columns are the columns of both tables, in A's order followed by columns only in B
+	Columns []ColumnDiff
+	// Compared are the columns present in both tables, used to match rows
+	Compared []string
+	// OnlyInA and OnlyInB count the rows with no identical row on the other side
+	OnlyInA int64
+	OnlyInB int64
+	// SampleA and SampleB are sampled rows with no match, as values of Compared
+	SampleA [][]string
+	SampleB [][]string
+}
+
+// Identical reports whether both tables hold the same columns and rows.
+func (r *DiffResult) Identical() bool {
+	if r.RowsA != r.RowsB || r.OnlyInA != 0 || r.OnlyInB != 0 {
+		return false
+	}
+	for _, col := range r.Columns {
+		if col.Changed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff compares a model's table in two databases: row counts, per-column
+// aggregates and a sample of the rows that have no identical row on the other
+// side. Either database may be the engine's own target database.
+func (e *Engine) Diff(ctx context.Context, modelPath string, opts DiffOptions) (*DiffResult, error) {
+	if e.dbConfig.Type != "duckdb" {
+		return nil, fmt.Errorf("diffing requires a duckdb target, got %q", e.dbConfig.Type)
+	}
+	if opts.DatabaseA == "" || opts.DatabaseB == "" {
+		return nil, fmt.Errorf("two databases are required to diff")
+	}
+	if sameDatabase(opts.DatabaseA, opts.DatabaseB) {
+		return nil, fmt.Errorf("cannot diff a database with itself: %s", opts.DatabaseA)
+	}
+	if _, ok := e.models[modelPath]; !ok {
+		return nil, fmt.Errorf("model not found: %s", modelPath)
+	}
+	if opts.SampleSize <= 0 {
+		opts.SampleSize = defaultDiffSampleSize
+	}
+
+	if err := e.ensureDBConnected(ctx); err != nil {
+		return nil, err
+	}
+
+	table := e.tableName(modelPath)
+
+	relA, detachA, err := e.diffRelation(ctx, opts.DatabaseA, diffCatalogA, table)
+	if err != nil {
+		return nil, err
+	}
+	defer detachA()
+
+	relB, detachB, err := e.diffRelation(ctx, opts.DatabaseB, diffCatalogB, table)
+	if err != nil {
+		return nil, err
+	}
+	defer detachB()
+
+	colsA, err := e.describeRelation(ctx, relA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from %s: %w", table, opts.DatabaseA, err)
+	}
+	colsB, err := e.describeRelation(ctx, relB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from %s: %w", table, opts.DatabaseB, err)
+	}
+
+	result := &DiffResult{Model: modelPath}
+
+	rowsA, statsA, err := e.columnStats(ctx, relA, colsA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate %s: %w", opts.DatabaseA, err)
+	}
+	rowsB, statsB, err := e.columnStats(ctx, relB, colsB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate %s: %w", opts.DatabaseB, err)
+	}
+	result.RowsA, result.RowsB = rowsA, rowsB
+
+	typesB := make(map[string]string, len(colsB))
+	for _, col := range colsB {
+		typesB[col.name] = col.typ
+	}
+	typesA := make(map[string]bool, len(colsA))
+	for _, col := range colsA {
+		typesA[col.name] = true
+		diff := ColumnDiff{Name: col.name, TypeA: col.typ, A: statsA[col.name]}
+		if typ, ok := typesB[col.name]; ok {
+			diff.TypeB = typ
+			diff.B = statsB[col.name]
+			result.Compared = append(result.Compared, col.name)
+		}
+		result.Columns = append(result.Columns, diff)
+	}
+	for _, col := range colsB {
+		if !typesA[col.name] {
+			result.Columns = append(result.Columns, ColumnDiff{Name: col.name, TypeB: col.typ, B: statsB[col.name]})
+		}
+	}
+
+	if len(result.Compared) == 0 {
+		return result, nil
+	}
+
+	if result.OnlyInA, result.SampleA, err = e.unmatchedRows(ctx, relA, relB, result.Compared, opts.SampleSize); err != nil {
+		return nil, fmt.Errorf("failed to compare rows: %w", err)
+	}
+	if result.OnlyInB, result.SampleB, err = e.unmatchedRows(ctx, relB, relA, result.Compared, opts.SampleSize); err != nil {
+		return nil, fmt.Errorf("failed to compare rows: %w", err)
+	}
+
+	return result, nil
+}
+
+// diffRelation returns the qualified name of table in database. The engine's
+// own database is read directly; any other database is attached under catalog.
+func (e *Engine) diffRelation(ctx context.Context, database, catalog, table string) (string, func(), error) {
+	if sameDatabase(database, e.dbConfig.Path) {
+		return table, func() {}, nil
+	}
+
+	detach, err := e.attachDatabase(ctx, database, catalog)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to attach %s: %w", database, err)
+	}
+	return catalog + "." + table, detach, nil
+}
+
+// sameDatabase reports whether two database paths refer to the same file.
+func sameDatabase(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return absA == absB
+}
+
+// relationColumn is a column name and type read with DESCRIBE.
+type relationColumn struct {
+	name string
+	typ  string
+}
+
+// describeRelation returns the columns of a relation.
+func (e *Engine) describeRelation(ctx context.Context, relation string) ([]relationColumn, error) {
+	rows, err := e.db.Query(ctx, fmt.Sprintf("SELECT column_name, column_type FROM (DESCRIBE SELECT * FROM %s)", relation))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var cols []relationColumn
+	for rows.Next() {
+		var col relationColumn
+		if err := rows.Scan(&col.name, &col.typ); err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	return cols, rows.Err()
+}
+
+// columnStats returns the row count of a relation and the aggregates of its columns.
+func (e *Engine) columnStats(ctx context.Context, relation string, cols []relationColumn) (int64, map[string]*ColumnStats, error) {
+	exprs := []string{"COUNT(*)"}
+	for _, col := range cols {
+		q := e.dialect.QuoteIdentifier(col.name)
+		sum := "NULL"
+		if isNumericType(col.typ) {
+			sum = fmt.Sprintf("CAST(SUM(%s) AS VARCHAR)", q)
+		}
+		exprs = append(exprs,
+			fmt.Sprintf("COUNT(*) - COUNT(%s)", q),
+			fmt.Sprintf("COUNT(DISTINCT %s)", q),
+			fmt.Sprintf("CAST(MIN(%s) AS VARCHAR)", q),
+			fmt.Sprintf("CAST(MAX(%s) AS VARCHAR)", q),
+			sum,
+		)
+	}
+
+	rows, err := e.db.Query(ctx, fmt.Sprintf("SELECT %s FROM %s", strings.Join(exprs, ", "), relation))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var count int64
+	type scanned struct {
+		nulls, distinct int64
+		min, max, sum   sql.NullString
+	}
+	values := make([]scanned, len(cols))
+	dest := []any{&count}
+	for i := range values {
+		v := &values[i]
+		dest = append(dest, &v.nulls, &v.distinct, &v.min, &v.max, &v.sum)
+	}
+
+	if !rows.Next() {
+		return 0, nil, fmt.Errorf("no aggregate row returned")
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, nil, err
+	}
+
+	stats := make(map[string]*ColumnStats, len(cols))
+	for i, col := range cols {
+		v := values[i]
+		stats[col.name] = &ColumnStats{
+			Nulls:    v.nulls,
+			Distinct: v.distinct,
+			Min:      v.min.String,
+			Max:      v.max.String,
+			Sum:      v.sum.String,
+		}
+	}
+	return count, stats, rows.Err()
+}
+
+// unmatchedRows counts the rows of from that have no identical row in other,
+// comparing the given columns, and samples up to limit of them.
+func (e *Engine) unmatchedRows(ctx context.Context, from, other string, cols []string, limit int) (int64, [][]string, error) {
+	quoted := make([]string, len(cols))
+	casts := make([]string, len(cols))
+	for i, col := range cols {
+		quoted[i] = e.dialect.QuoteIdentifier(col)
+		casts[i] = fmt.Sprintf("CAST(%s AS VARCHAR)", quoted[i])
+	}
+	colList := strings.Join(quoted, ", ")
+	except := fmt.Sprintf("SELECT %s FROM %s EXCEPT ALL SELECT %s FROM %s", colList, from, colList, other)
+
+	rows, err := e.db.Query(ctx, fmt.Sprintf("SELECT COUNT(*) FROM (%s)", except))
+	if err != nil {
+		return 0, nil, err
+	}
+	var count int64
+	if rows.Next() {
+		err = rows.Scan(&count)
+	}
+	_ = rows.Close()
+	if err != nil {
+		return 0, nil, err
+	}
+	if count == 0 {
+		return 0, nil, nil
+	}
+
+	rows, err = e.db.Query(ctx, fmt.Sprintf("SELECT %s FROM (%s) ORDER BY ALL LIMIT %d",
+		strings.Join(casts, ", "), except, limit))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var sample [][]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(cols))
+		dest := make([]any, len(cols))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return 0, nil, err
+		}
+		row := make([]string, len(cols))
+		for i, v := range values {
+			if v.Valid {
+				row[i] = v.String
+			} else {
+				row[i] = "NULL"
+			}
+		}
+		sample = append(sample, row)
+	}
+	return count, sample, rows.Err()
+}
+
+// isNumericType reports whether a DuckDB column type can be summed.
+func isNumericType(typ string) bool {
+	base, _, _ := strings.Cut(strings.ToUpper(typ), "(")
+	switch base {
+	case "TINYINT", "SMALLINT", "INTEGER", "BIGINT", "HUGEINT",
+		"UTINYINT", "USMALLINT", "UINTEGER", "UBIGINT", "UHUGEINT",
+		"FLOAT", "DOUBLE", "REAL", "DECIMAL", "NUMERIC":
+		return true
+	}
+	return false
+}
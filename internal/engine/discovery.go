@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
@@ -32,6 +34,8 @@ type DiscoveryResult struct {
 	ModelsChanged int
 	ModelsSkipped int
 	ModelsDeleted int
+	// ModelsDisabled counts models excluded from the DAG by enabled: false
+	ModelsDisabled int
 
 	// Macros
 	MacrosTotal   int
@@ -100,6 +104,9 @@ func (e *Engine) Discover(opts DiscoveryOptions) (*DiscoveryResult, error) {
 		return result, fmt.Errorf("graph construction failed: %w", err)
 	}
 
+	// 4b. Report ref('project', 'model') calls that match no model
+	e.validateRefs(result)
+
 	// 5. Persist dependencies to SQLite
 	if err := e.persistDependencies(); err != nil {
 		return result, fmt.Errorf("dependency persistence failed: %w", err)
@@ -248,36 +255,98 @@ func (e *Engine) saveMacroToStore(parsed *macro.ParsedNamespace, absPath, hash s
 	return e.store.SetContentHash(absPath, hash, "macro")
 }
 
-// discoverModels scans and indexes model files incrementally.
-func (e *Engine) discoverModels(opts DiscoveryOptions, result *DiscoveryResult) error {
-	modelsDir := e.modelsDir
-	if opts.ModelsDir != "" {
-		modelsDir = opts.ModelsDir
-	}
+// modelRoot is a models directory scanned during discovery.
+type modelRoot struct {
+	project string // Workspace project name (empty outside a workspace)
+	dir     string // Absolute models directory
+}
 
-	if modelsDir == "" {
-		return nil
+// modelRoots returns the models directories to scan: one per workspace
+// project, or the single models directory of a standalone project.
+func (e *Engine) modelRoots(opts DiscoveryOptions) ([]modelRoot, error) {
+	var roots []modelRoot
+
+	if len(e.projects) > 0 && opts.ModelsDir == "" {
+		for _, p := range e.projects {
+			roots = append(roots, modelRoot{project: p.Name, dir: p.ModelsDir})
+		}
+	} else {
+		modelsDir := e.modelsDir
+		if opts.ModelsDir != "" {
+			modelsDir = opts.ModelsDir
+		}
+		if modelsDir == "" {
+			return nil, nil
+		}
+		roots = append(roots, modelRoot{dir: modelsDir})
 	}
 
-	// Ensure modelsDir is absolute for consistent path resolution
-	absModelsDir, absErr := filepath.Abs(modelsDir)
-	if absErr != nil {
-		return fmt.Errorf("failed to resolve models directory: %w", absErr)
+	// Ensure directories are absolute for consistent path resolution
+	for i := range roots {
+		absDir, err := filepath.Abs(roots[i].dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve models directory: %w", err)
+		}
+		roots[i].dir = absDir
 	}
 
-	e.logger.Debug("discovering models", "models_dir", absModelsDir)
+	return roots, nil
+}
+
+// newModelScanner creates a scanner for a models directory.
+func (e *Engine) newModelScanner(root modelRoot) *loader.Scanner {
+	scanner := loader.NewScanner(root.dir, e.ModelDialect())
+	scanner.GetLoader().LineageExtractor = NewLineageExtractor()
+	scanner.GetLoader().Project = root.project
+	scanner.GetLoader().Environment = e.environment
+	return scanner
+}
+
+// discoverModels scans and indexes model files incrementally.
+func (e *Engine) discoverModels(opts DiscoveryOptions, result *DiscoveryResult) error {
+	roots, err := e.modelRoots(opts)
+	if err != nil {
+		return err
+	}
+	if len(roots) == 0 {
+		return nil
+	}
 
 	// Clear in-memory state for fresh build
 	e.models = make(map[string]*core.Model)
+	e.disabled = make(map[string]*core.Model)
 	e.registry = registry.NewModelRegistry()
 
 	// Track which files we've seen
 	seenFiles := make(map[string]bool)
 
-	scanner := loader.NewScanner(absModelsDir, e.dialect)
-	scanner.GetLoader().LineageExtractor = NewLineageExtractor()
+	for _, root := range roots {
+		if err := e.discoverModelsIn(root, opts, result, seenFiles); err != nil {
+			return err
+		}
+	}
 
-	err := filepath.Walk(absModelsDir, func(path string, info os.FileInfo, walkErr error) error {
+	// Remove deleted models from SQLite
+	result.ModelsDeleted = e.cleanupDeletedModels(seenFiles)
+
+	// Name the tables of models after the schemas generate_schema_name gives them
+	e.resolveRelations(result)
+
+	// Workspace projects share one database, so their tables must not collide
+	if len(roots) > 1 {
+		e.validateTableNames(result)
+	}
+
+	return nil
+}
+
+// discoverModelsIn scans and indexes the model files of one models directory.
+func (e *Engine) discoverModelsIn(root modelRoot, opts DiscoveryOptions, result *DiscoveryResult, seenFiles map[string]bool) error {
+	e.logger.Debug("discovering models", "models_dir", root.dir, "project", root.project)
+
+	scanner := e.newModelScanner(root)
+
+	return filepath.Walk(root.dir, func(path string, info os.FileInfo, walkErr error) error {
 		if walkErr != nil || info.IsDir() || !strings.HasSuffix(info.Name(), ".sql") {
 			return nil //nolint:nilerr // Skip directories and non-.sql files
 		}
@@ -294,9 +363,18 @@ func (e *Engine) discoverModels(opts DiscoveryOptions, result *DiscoveryResult)
 			// Try to load from SQLite
 			storedModel, err := e.store.GetModelByFilePath(absPath)
 			if err == nil && storedModel != nil {
-				modelConfig = e.reconstructModelConfig(storedModel, absPath, content)
+				modelConfig = e.reconstructModelConfig(scanner, absPath, content)
 				e.logger.Debug("skipping unchanged model", "path", absPath)
 				result.ModelsSkipped++
+
+				// Environment overrides may resolve differently than when the model was stored
+				if modelConfig != nil && !modelConfig.Disabled && environmentConfigChanged(storedModel.Model, modelConfig) {
+					if err := e.saveModelToStore(modelConfig, absPath, newHash); err != nil {
+						result.Errors = append(result.Errors, DiscoveryError{
+							Path: absPath, Type: "save", Message: err.Error(),
+						})
+					}
+				}
 			}
 		}
 
@@ -315,43 +393,75 @@ func (e *Engine) discoverModels(opts DiscoveryOptions, result *DiscoveryResult)
 			e.logger.Debug("parsed model", "path", absPath, "model_name", modelConfig.Name)
 
 			// Save to SQLite
-			if err := e.saveModelToStore(modelConfig, absPath, newHash); err != nil {
-				result.Errors = append(result.Errors, DiscoveryError{
-					Path: absPath, Type: "save", Message: err.Error(),
-				})
+			if !modelConfig.Disabled {
+				if err := e.saveModelToStore(modelConfig, absPath, newHash); err != nil {
+					result.Errors = append(result.Errors, DiscoveryError{
+						Path: absPath, Type: "save", Message: err.Error(),
+					})
+				}
 			}
 
 			result.ModelsChanged++
 		}
 
+		// Disabled models stay out of the registry, the DAG, and the state store
+		if modelConfig.Disabled {
+			e.logger.Debug("model disabled", "path", absPath, "environment", e.environment)
+			_ = e.store.DeleteModelByFilePath(absPath)
+			_ = e.store.DeleteContentHash(absPath)
+			e.disabled[modelConfig.Path] = modelConfig
+			result.ModelsDisabled++
+			return nil
+		}
+
 		// Register in memory
 		e.registry.Register(modelConfig)
 		e.models[modelConfig.Path] = modelConfig
 
 		return nil
 	})
+}
 
-	if err != nil {
-		return err
+// validateTableNames reports workspace models from different projects
+// that would materialize to the same table.
+func (e *Engine) validateTableNames(result *DiscoveryResult) {
+	byTable := make(map[string][]*core.Model)
+	for _, m := range e.models {
+		table := e.tableName(m.Path)
+		byTable[table] = append(byTable[table], m)
 	}
 
-	// Remove deleted models from SQLite
-	result.ModelsDeleted = e.cleanupDeletedModels(seenFiles)
+	for table, models := range byTable {
+		if len(models) < 2 {
+			continue
+		}
+		ids := make([]string, 0, len(models))
+		for _, m := range models {
+			ids = append(ids, m.Path)
+		}
+		sort.Strings(ids)
+		for _, m := range models {
+			result.Errors = append(result.Errors, DiscoveryError{
+				Path:    m.FilePath,
+				Type:    "validation",
+				Message: fmt.Sprintf("table %s is defined by multiple projects: %s", table, strings.Join(ids, ", ")),
+			})
+		}
+	}
+}
 
-	return nil
+// environmentConfigChanged reports whether settings that frontmatter config
+// overrides differ between a stored model and its re-parsed form.
+func environmentConfigChanged(stored, parsed *core.Model) bool {
+	return stored.Materialized != parsed.Materialized ||
+		stored.UniqueKey != parsed.UniqueKey ||
+		stored.Schema != parsed.Schema
 }
 
 // reconstructModelConfig creates a ModelConfig from stored state and file content.
-func (e *Engine) reconstructModelConfig(_ *core.PersistedModel, filePath string, content []byte) *core.Model {
+func (e *Engine) reconstructModelConfig(scanner *loader.Scanner, filePath string, content []byte) *core.Model {
 	// We need to re-parse the file to get the full SQL and sources
 	// But we can skip the full parse validation since we know it was valid before
-	// Use absolute path for consistent model path computation
-	absModelsDir, err := filepath.Abs(e.modelsDir)
-	if err != nil {
-		return nil
-	}
-	scanner := loader.NewScanner(absModelsDir, e.dialect)
-	scanner.GetLoader().LineageExtractor = NewLineageExtractor()
 	config, parseErr := scanner.ParseContent(filePath, content)
 	if parseErr != nil {
 		// If parsing fails now, return nil to trigger full re-parse
@@ -365,11 +475,16 @@ func (e *Engine) saveModelToStore(m *core.Model, absPath, hash string) error {
 	model := &core.PersistedModel{
 		Model: &core.Model{
 			Path:           m.Path,
+			Project:        m.Project,
 			Name:           m.Name,
 			Materialized:   m.Materialized,
 			UniqueKey:      m.UniqueKey,
 			FilePath:       absPath,
 			Owner:          m.Owner,
+			Group:          m.Group,
+			PII:            m.PII,
+			Sources:        m.Sources,
+			PartitionBy:    m.PartitionBy,
 			Schema:         m.Schema,
 			Tags:           m.Tags,
 			Meta:           m.Meta,
@@ -377,6 +492,9 @@ func (e *Engine) saveModelToStore(m *core.Model, absPath, hash string) error {
 			SQL:            m.SQL,
 			RawContent:     m.RawContent,
 			Description:    m.Description,
+			Version:        m.Version,
+			Deprecated:     m.Deprecated,
+			Access:         m.Access,
 		},
 		ContentHash: computeHash(m.RawContent),
 	}
@@ -423,8 +541,14 @@ func (e *Engine) validateSeeds(opts DiscoveryOptions, result *DiscoveryResult) {
 		return
 	}
 
-	// Get all external sources (tables not mapped to models)
+	// Get all external sources (tables not mapped to models), except files
+	// read by table functions, which are not seeds
 	externalSources := e.registry.GetExternalSources()
+	for _, m := range e.models {
+		for _, path := range m.Files {
+			delete(externalSources, path)
+		}
+	}
 
 	for tableName := range externalSources {
 		// Check if seed file exists
@@ -454,8 +578,9 @@ func (e *Engine) buildGraph() error {
 		} else {
 			tableSources = m.Imports
 		}
+		tableSources = append(slices.Clone(tableSources), m.Refs...)
 
-		dependencies, _ := e.registry.ResolveDependencies(tableSources)
+		dependencies, _ := e.registry.ResolveDependenciesFrom(m.Project, tableSources)
 
 		for _, dep := range dependencies {
 			if dep == m.Path {
@@ -477,6 +602,48 @@ func (e *Engine) buildGraph() error {
 	return nil
 }
 
+// validateRefs reports ref() calls that do not resolve to a model.
+func (e *Engine) validateRefs(result *DiscoveryResult) {
+	for _, m := range e.models {
+		for _, ref := range m.Refs {
+			project, name := core.SplitModelID(ref)
+
+			var ok bool
+			if project == "" {
+				_, ok = e.registry.ResolveFrom(m.Project, name)
+			} else {
+				_, ok = e.registry.ResolveRef(project, name)
+			}
+			if !ok {
+				model, version := core.SplitVersionedName(name)
+				reason := "model not found"
+				if e.isDisabled(project, m.Project, model) {
+					reason = fmt.Sprintf("model is disabled in environment %q", e.environment)
+				}
+				result.Errors = append(result.Errors, DiscoveryError{
+					Path:    m.FilePath,
+					Type:    "validation",
+					Message: formatRef(project, model, version) + ": " + reason,
+				})
+			}
+		}
+	}
+}
+
+// isDisabled reports whether a ref() to name resolves to a disabled model.
+// The ref's project defaults to the referencing model's project.
+func (e *Engine) isDisabled(project, fromProject, name string) bool {
+	if project == "" {
+		project = fromProject
+	}
+	for _, m := range e.disabled {
+		if m.Project == project && m.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // persistDependencies saves the dependency graph to SQLite.
 func (e *Engine) persistDependencies() error {
 	for modelPath, m := range e.models {
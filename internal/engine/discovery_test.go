@@ -1,8 +1,10 @@
 package engine
 
 import (
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/leapstack-labs/leapsql/internal/testutil"
@@ -276,6 +278,242 @@ SELECT 1`), 0600)
 	assert.GreaterOrEqual(t, len(models), 1, "Expected at least 1 valid model in memory")
 }
 
+// TestDiscoverModels_Workspace tests discovery across workspace projects.
+func TestDiscoverModels_Workspace(t *testing.T) {
+	tmpDir := t.TempDir()
+	coreDir := filepath.Join(tmpDir, "core", "models")
+	billingDir := filepath.Join(tmpDir, "billing", "models")
+	require.NoError(t, os.MkdirAll(filepath.Join(coreDir, "staging"), 0750))
+	require.NoError(t, os.MkdirAll(filepath.Join(billingDir, "marts"), 0750))
+
+	require.NoError(t, os.WriteFile(filepath.Join(coreDir, "staging", "stg_customers.sql"),
+		[]byte(`SELECT id, name FROM raw_customers`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(billingDir, "marts", "invoices.sql"),
+		[]byte(`SELECT c.id, c.name FROM {{ ref('core', 'stg_customers') }} c`), 0600))
+
+	eng, err := New(Config{
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+		Projects: []Project{
+			{Name: "core", ModelsDir: coreDir},
+			{Name: "billing", ModelsDir: billingDir},
+		},
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = eng.Close() }()
+
+	result, err := eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	assert.False(t, result.HasErrors(), "unexpected errors: %v", result.Errors)
+
+	models := eng.GetModels()
+	require.Contains(t, models, "core/staging.stg_customers")
+	require.Contains(t, models, "billing/marts.invoices")
+	assert.Equal(t, "billing", models["billing/marts.invoices"].Project)
+
+	// The cross-project ref is an edge in the DAG
+	assert.Equal(t, []string{"core/staging.stg_customers"}, eng.GetGraph().GetParents("billing/marts.invoices"))
+}
+
+// TestDiscoverModels_WorkspaceValidation tests workspace-specific discovery errors.
+func TestDiscoverModels_WorkspaceValidation(t *testing.T) {
+	tmpDir := t.TempDir()
+	coreDir := filepath.Join(tmpDir, "core")
+	billingDir := filepath.Join(tmpDir, "billing")
+	require.NoError(t, os.MkdirAll(filepath.Join(coreDir, "staging"), 0750))
+	require.NoError(t, os.MkdirAll(filepath.Join(billingDir, "staging"), 0750))
+
+	// Both projects define staging.customers, which share one table
+	require.NoError(t, os.WriteFile(filepath.Join(coreDir, "staging", "customers.sql"),
+		[]byte(`SELECT 1 AS id`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(billingDir, "staging", "customers.sql"),
+		[]byte(`SELECT 2 AS id`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(billingDir, "orders.sql"),
+		[]byte(`SELECT * FROM {{ ref('core', 'missing') }}`), 0600))
+
+	eng, err := New(Config{
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+		Projects: []Project{
+			{Name: "core", ModelsDir: coreDir},
+			{Name: "billing", ModelsDir: billingDir},
+		},
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = eng.Close() }()
+
+	result, err := eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	var messages []string
+	for _, e := range result.Errors {
+		assert.Equal(t, "validation", e.Type)
+		messages = append(messages, e.Message)
+	}
+	assert.Contains(t, messages, "table staging.customers is defined by multiple projects: billing/staging.customers, core/staging.customers")
+	assert.Contains(t, messages, `ref("core", "missing"): model not found`)
+}
+
+// TestDiscoverModels_Versions tests dependency resolution for versioned models.
+func TestDiscoverModels_Versions(t *testing.T) {
+	tmpDir := t.TempDir()
+	modelsDir := filepath.Join(tmpDir, "models")
+	require.NoError(t, os.MkdirAll(filepath.Join(modelsDir, "marts"), 0750))
+
+	files := map[string]string{
+		"marts/dim_users_v1.sql": `/*---
+name: dim_users
+version: 1
+deprecated:
+  since: "2024-06-01"
+---*/
+SELECT 1 AS id`,
+		"marts/dim_users_v2.sql": `/*---
+name: dim_users
+version: 2
+---*/
+SELECT 1 AS id, 'a' AS name`,
+		"marts/fct_pinned.sql": `SELECT id FROM {{ ref('dim_users', v=1) }}`,
+		"marts/fct_latest.sql": `SELECT id, name FROM dim_users`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+
+	eng, err := New(Config{
+		ModelsDir: modelsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = eng.Close() }()
+
+	result, err := eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	assert.False(t, result.HasErrors(), "unexpected errors: %v", result.Errors)
+
+	assert.Equal(t, []string{"marts.dim_users_v1"}, eng.GetGraph().GetParents("marts.fct_pinned"))
+	assert.Equal(t, []string{"marts.dim_users_v2"}, eng.GetGraph().GetParents("marts.fct_latest"))
+
+	sql, err := eng.RenderModel("marts.fct_pinned")
+	require.NoError(t, err)
+	assert.Contains(t, sql, "marts.dim_users_v1")
+}
+
+func TestDiscoverModels_Enabled(t *testing.T) {
+	files := map[string]string{
+		"staging/stg_orders.sql": `SELECT 1 AS id`,
+		"staging/stg_legacy.sql": `/*---
+enabled: false
+---*/
+SELECT 1 AS id`,
+		"marts/fct_orders.sql": `/*---
+config:
+  prod:
+    materialized: incremental
+    unique_key: id
+---*/
+SELECT id FROM stg_orders`,
+		"marts/fct_preview.sql": `/*---
+config:
+  prod:
+    enabled: false
+---*/
+SELECT id FROM stg_orders`,
+	}
+
+	tests := []struct {
+		environment  string
+		models       []string
+		disabled     []string
+		materialized string
+	}{
+		{
+			environment:  "dev",
+			models:       []string{"marts.fct_orders", "marts.fct_preview", "staging.stg_orders"},
+			disabled:     []string{"staging.stg_legacy"},
+			materialized: "table",
+		},
+		{
+			environment:  "prod",
+			models:       []string{"marts.fct_orders", "staging.stg_orders"},
+			disabled:     []string{"marts.fct_preview", "staging.stg_legacy"},
+			materialized: "incremental",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.environment, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			modelsDir := filepath.Join(tmpDir, "models")
+			for _, dir := range []string{"staging", "marts"} {
+				require.NoError(t, os.MkdirAll(filepath.Join(modelsDir, dir), 0750))
+			}
+			for name, content := range files {
+				require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+			}
+
+			eng, err := New(Config{
+				ModelsDir:   modelsDir,
+				StatePath:   filepath.Join(tmpDir, "state.db"),
+				Environment: tt.environment,
+				Target:      defaultTestTarget(),
+				Logger:      testutil.NewTestLogger(t),
+			})
+			require.NoError(t, err, "New() failed")
+			defer func() { _ = eng.Close() }()
+
+			result, err := eng.Discover(DiscoveryOptions{})
+			require.NoError(t, err, "Discover() failed")
+			assert.False(t, result.HasErrors(), "unexpected errors: %v", result.Errors)
+			assert.Equal(t, len(tt.disabled), result.ModelsDisabled)
+
+			assert.ElementsMatch(t, tt.models, slices.Collect(maps.Keys(eng.GetModels())))
+			assert.ElementsMatch(t, tt.disabled, slices.Collect(maps.Keys(eng.GetDisabledModels())))
+			for _, path := range tt.disabled {
+				_, inGraph := eng.GetGraph().GetNode(path)
+				assert.False(t, inGraph, "disabled model %s should not be in the DAG", path)
+
+				_, err := eng.RenderModel(path)
+				assert.NoError(t, err, "disabled model %s should still render for linting", path)
+			}
+
+			assert.Equal(t, tt.materialized, eng.GetModels()["marts.fct_orders"].Materialized)
+		})
+	}
+}
+
+func TestDiscoverModels_RefToDisabledModel(t *testing.T) {
+	tmpDir := t.TempDir()
+	modelsDir := filepath.Join(tmpDir, "models")
+	require.NoError(t, os.MkdirAll(modelsDir, 0750))
+
+	files := map[string]string{
+		"stg_legacy.sql": "/*---\nenabled: false\n---*/\nSELECT 1 AS id",
+		"fct_legacy.sql": "SELECT id FROM {{ ref('stg_legacy') }}",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+
+	eng, err := New(Config{
+		ModelsDir: modelsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = eng.Close() }()
+
+	result, err := eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, `model is disabled in environment "dev"`)
+}
+
 // TestDiscoverMacros_IncrementalSkip tests incremental macro discovery.
 func TestDiscoverMacros_IncrementalSkip(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"os"
 	"sync"
+	"text/template"
 
 	"github.com/leapstack-labs/leapsql/internal/dag"
 	"github.com/leapstack-labs/leapsql/internal/macro"
@@ -37,6 +38,9 @@ type Engine struct {
 
 	// SQL dialect for the connected adapter (set after connection)
 	dialect *core.Dialect
+	// SQL dialect models are written in, when it differs from the target's
+	// (nil for the target's). Models are transpiled to the target's dialect.
+	modelDialect *core.Dialect
 
 	// Structured logger
 	logger *slog.Logger
@@ -46,13 +50,66 @@ type Engine struct {
 	modelsDir     string
 	seedsDir      string
 	macrosDir     string
+	projects      []Project
+	groups        []core.GroupConfig
+	layers        []core.LayerConfig
+	fileSources   []core.FileSourceConfig
+	tableSources  []core.TableSourceConfig
 	environment   string
 	target        *starctx.TargetInfo
 	graph         *dag.Graph
 	models        map[string]*core.Model
+	disabled      map[string]*core.Model // Models with enabled: false, kept out of the DAG
+	relations     map[string]string      // Table names generate_schema_name gave models, by path
 	registry      *registry.ModelRegistry
 	macroRegistry *macro.Registry
 
+	// How declared not_null and unique tests are checked (ConstraintMode*)
+	constraintMode string
+
+	// Default transaction policy of model builds (empty for the adapter's)
+	transaction core.TransactionPolicy
+
+	// How the columns models tag as PII are masked on the target
+	masking core.MaskingConfig
+
+	// Limits on the model builds running at once against the target
+	// (see limits.go)
+	maxQueries     int
+	resourceLimits map[string]int
+	// Semaphores of in-memory targets, which no other engine shares
+	localSlots semaphores
+
+	// Models built at once by runs (see SetThreads)
+	threads int
+
+	// Query comment prefixed to executed SQL (nil if off)
+	queryComment *template.Template
+	projectName  string
+	user         string
+
+	// State lock acquired by runs
+	lock LockConfig
+
+	// Rebuild models from scratch (see SetFullRefresh)
+	fullRefresh bool
+
+	// Ignore the build cache (see SetNoCache)
+	noCache bool
+
+	// Skip models whose sources are unchanged (see SetSkipFresh)
+	skipFresh bool
+
+	// Rows each model is limited to (see SetSample; 0 builds in full)
+	sample int
+
+	// Capture the query plan of each model built (see SetExplain)
+	explain bool
+
+	// Deferral to production for selected runs (optional)
+	deferTo  *DeferConfig
+	deferred []string // Models the last selected run read from production
+
 	// Observer for run lifecycle events (optional)
 	observer   RunObserver
 	observerMu sync.RWMutex
@@ -66,6 +123,39 @@ type Config struct {
 	SeedsDir string
 	// MacrosDir is the path to the macros directory (optional)
 	MacrosDir string
+	// Projects lists workspace projects (optional). When set, models are
+	// discovered from each project's models directory instead of ModelsDir
+	// and their paths are namespaced by project name.
+	Projects []Project
+	// Groups declares the groups that own models (optional)
+	Groups []core.GroupConfig
+	// Layers declares the model layers (optional, nil for the default layers)
+	Layers []core.LayerConfig
+	// FileSources sets the freshness thresholds of files models read (optional)
+	FileSources []core.FileSourceConfig
+	// TableSources sets how to tell when raw tables last changed (optional)
+	TableSources []core.TableSourceConfig
+	// ProjectName identifies the project in query comments (optional)
+	ProjectName string
+	// QueryComment is the text/template of the comment prefixed to executed
+	// SQL (empty for DefaultQueryComment, QueryCommentOff to disable)
+	QueryComment string
+	// Transaction is the default transaction policy of model builds (empty
+	// for the adapter's default). Models override it in frontmatter.
+	Transaction core.TransactionPolicy
+	// Masking configures how the columns models tag as PII are masked on
+	// the target (nil builds them unchanged)
+	Masking *core.MaskingConfig
+	// MaxConcurrentQueries caps the model builds running against the target
+	// at once, across every engine of the process (0 for no limit)
+	MaxConcurrentQueries int
+	// ResourceClasses caps the builds running at once of the models of each
+	// resource class (optional)
+	ResourceClasses map[string]int
+	// Dialect is the SQL dialect models are written in (empty for the
+	// target's). Models are transpiled to the target's dialect, so the same
+	// models build on targets of different types.
+	Dialect string
 	// StatePath is the path to the SQLite state database
 	StatePath string
 	// Environment is the current environment (dev, staging, prod)
@@ -83,6 +173,14 @@ type Config struct {
 	DatabasePath string
 }
 
+// Project is a workspace project whose models are discovered by the engine.
+type Project struct {
+	// Name namespaces the project's model paths (e.g., "core/staging.orders")
+	Name string
+	// ModelsDir is the path to the project's models directory
+	ModelsDir string
+}
+
 // New creates a new engine with lazy database connection.
 // The database adapter is only connected when Run() or LoadSeeds() is called.
 func New(cfg Config) (*Engine, error) {
@@ -128,6 +226,35 @@ func New(cfg Config) (*Engine, error) {
 		env = "dev"
 	}
 
+	queryComment, err := parseQueryComment(cfg.QueryComment)
+	if err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+
+	if !cfg.Transaction.IsValid() {
+		_ = store.Close()
+		return nil, fmt.Errorf("invalid transaction policy %q: must be auto, always or never", cfg.Transaction)
+	}
+
+	var masking core.MaskingConfig
+	if cfg.Masking != nil {
+		masking = *cfg.Masking
+	}
+	if !masking.Mode.IsValid() {
+		_ = store.Close()
+		return nil, fmt.Errorf("invalid masking mode %q: must be none, hash, null or policy", masking.Mode)
+	}
+	if masking.Mode == core.MaskingPolicy && masking.Policy == "" {
+		_ = store.Close()
+		return nil, fmt.Errorf("masking mode policy requires a masking policy")
+	}
+
+	if err := validateLimits(cfg.MaxConcurrentQueries, cfg.ResourceClasses); err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+
 	// Require explicit target or adapter configuration
 	if cfg.Target == nil && cfg.AdapterConfig == nil {
 		_ = store.Close()
@@ -166,23 +293,46 @@ func New(cfg Config) (*Engine, error) {
 		return nil, fmt.Errorf("unknown adapter type %q: supported types are 'duckdb', 'postgres'", dbConfig.Type)
 	}
 
+	var modelDialect *core.Dialect
+	if cfg.Dialect != "" {
+		if modelDialect, ok = dialect.Get(cfg.Dialect); !ok {
+			_ = store.Close()
+			return nil, fmt.Errorf("unknown dialect %q: supported dialects are %v", cfg.Dialect, dialect.List())
+		}
+	}
+
 	return &Engine{
-		db:            nil, // Lazy
-		dbConfig:      dbConfig,
-		dbConnected:   false,
-		dialect:       d,
-		logger:        logger,
-		store:         store,
-		statePath:     cfg.StatePath,
-		modelsDir:     cfg.ModelsDir,
-		seedsDir:      cfg.SeedsDir,
-		macrosDir:     cfg.MacrosDir,
-		environment:   env,
-		target:        target,
-		graph:         dag.NewGraph(),
-		models:        make(map[string]*core.Model),
-		registry:      registry.NewModelRegistry(),
-		macroRegistry: macroRegistry,
+		db:             nil, // Lazy
+		dbConfig:       dbConfig,
+		dbConnected:    false,
+		dialect:        d,
+		modelDialect:   modelDialect,
+		constraintMode: ConstraintModeAssert,
+		transaction:    cfg.Transaction,
+		masking:        masking,
+		maxQueries:     cfg.MaxConcurrentQueries,
+		resourceLimits: cfg.ResourceClasses,
+		queryComment:   queryComment,
+		projectName:    cfg.ProjectName,
+		user:           currentUser(),
+		logger:         logger,
+		store:          store,
+		statePath:      cfg.StatePath,
+		modelsDir:      cfg.ModelsDir,
+		seedsDir:       cfg.SeedsDir,
+		macrosDir:      cfg.MacrosDir,
+		projects:       cfg.Projects,
+		groups:         cfg.Groups,
+		layers:         cfg.Layers,
+		fileSources:    cfg.FileSources,
+		tableSources:   cfg.TableSources,
+		environment:    env,
+		target:         target,
+		graph:          dag.NewGraph(),
+		models:         make(map[string]*core.Model),
+		disabled:       make(map[string]*core.Model),
+		registry:       registry.NewModelRegistry(),
+		macroRegistry:  macroRegistry,
 	}, nil
 }
 
@@ -268,6 +418,12 @@ func (e *Engine) GetModels() map[string]*core.Model {
 	return e.models
 }
 
+// GetDisabledModels returns the discovered models that are disabled in the
+// current environment. They are not part of the DAG but can still be linted.
+func (e *Engine) GetDisabledModels() map[string]*core.Model {
+	return e.disabled
+}
+
 // GetStateStore returns the state store.
 func (e *Engine) GetStateStore() core.Store {
 	return e.store
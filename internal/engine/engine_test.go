@@ -3,19 +3,33 @@ package engine
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/leapstack-labs/leapsql/internal/metadata"
 	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
 	"github.com/leapstack-labs/leapsql/internal/testutil"
+	"github.com/leapstack-labs/leapsql/pkg/adapter"
 	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/dialect"
+	"github.com/leapstack-labs/leapsql/pkg/format"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/leapstack-labs/leapsql/pkg/transpile"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	// Import adapter packages to ensure adapters are registered via init()
 	_ "github.com/leapstack-labs/leapsql/pkg/adapters/duckdb"
 	_ "github.com/leapstack-labs/leapsql/pkg/adapters/postgres"
+	_ "github.com/leapstack-labs/leapsql/pkg/lint/sql/rules" // register SQL rules
 )
 
 // defaultTestTarget returns the default DuckDB target for tests.
@@ -159,6 +173,7 @@ func TestPathToTableName(t *testing.T) {
 		{"staging.customers", "staging.customers"},
 		{"marts.summary", "marts.summary"},
 		{"simple", "simple"},
+		{"billing/staging.invoices", "staging.invoices"},
 	}
 
 	for _, tc := range tests {
@@ -304,63 +319,3430 @@ SELECT id, name FROM users
 	assert.Equal(t, 2, count, "active_users should have 2 rows")
 }
 
+func TestEngine_RunSelectedDefer(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.Remove(filepath.Join(modelsDir, "active_users.sql")))
+	for _, dir := range []string{"staging", "marts"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(modelsDir, dir), 0750))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "staging", "stg_users.sql"),
+		[]byte("SELECT id, name FROM users"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "marts", "dim_users.sql"),
+		[]byte("SELECT id, upper(name) AS name FROM staging.stg_users"), 0600))
+
+	prodDatabase := filepath.Join(tmpDir, "prod.duckdb")
+	prodStatePath := filepath.Join(tmpDir, "prod-state.db")
+	ctx := testContext()
+
+	// Build everything in production
+	prod, err := New(Config{
+		ModelsDir:    modelsDir,
+		SeedsDir:     seedsDir,
+		DatabasePath: prodDatabase,
+		StatePath:    prodStatePath,
+		Target:       defaultTestTarget(),
+		Logger:       testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	require.NoError(t, prod.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = prod.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	_, err = prod.Run(ctx, "prod")
+	require.NoError(t, err, "Run() failed")
+	require.NoError(t, prod.Close())
+
+	// Build only the mart in dev, without seeds or the staging model
+	dev, err := New(Config{
+		ModelsDir:    modelsDir,
+		DatabasePath: filepath.Join(tmpDir, "dev.duckdb"),
+		StatePath:    filepath.Join(tmpDir, "state.db"),
+		Target:       defaultTestTarget(),
+		Logger:       testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = dev.Close() }()
+	_, err = dev.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	selected := []string{"marts.dim_users"}
+
+	// Nothing has been built in the staging environment
+	dev.SetDefer(&DeferConfig{Environment: "staging", StatePath: prodStatePath, Database: prodDatabase})
+	_, err = dev.RunSelected(ctx, "dev", selected, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `not built in production: staging.stg_users`)
+
+	dev.SetDefer(&DeferConfig{Environment: "prod", StatePath: prodStatePath, Database: prodDatabase})
+	run, err := dev.RunSelected(ctx, "dev", selected, false)
+	require.NoError(t, err, "RunSelected() failed")
+	assert.Equal(t, core.RunStatusCompleted, run.Status, "Run status should be completed. Error: %s", run.Error)
+	assert.Equal(t, []string{"staging.stg_users"}, dev.DeferredModels())
+
+	// The mart was built from production data
+	rows, err := dev.db.Query(ctx, "SELECT COUNT(*) FROM marts.dim_users")
+	require.NoError(t, err, "Query marts.dim_users failed")
+	var count int
+	if rows.Next() {
+		_ = rows.Scan(&count)
+	}
+	_ = rows.Close()
+	assert.Equal(t, 2, count, "marts.dim_users should have 2 rows")
+
+	// The deferred model is not left behind in the dev database
+	_, err = dev.db.GetTableMetadata(ctx, "staging.stg_users")
+	assert.Error(t, err, "deferred model should not exist in the dev database after the run")
+}
+
+func TestEngine_Clone(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.Remove(filepath.Join(modelsDir, "active_users.sql")))
+	for _, dir := range []string{"staging", "marts"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(modelsDir, dir), 0750))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "staging", "stg_users.sql"),
+		[]byte("SELECT id, name FROM users"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "marts", "users_view.sql"),
+		[]byte("/*---\nmaterialized: view\n---*/\nSELECT id FROM staging.stg_users"), 0600))
+
+	prodDatabase := filepath.Join(tmpDir, "prod.duckdb")
+	ctx := testContext()
+
+	prod, err := New(Config{
+		ModelsDir:    modelsDir,
+		SeedsDir:     seedsDir,
+		DatabasePath: prodDatabase,
+		StatePath:    filepath.Join(tmpDir, "prod-state.db"),
+		Target:       defaultTestTarget(),
+		Logger:       testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	require.NoError(t, prod.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = prod.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	_, err = prod.Run(ctx, "prod")
+	require.NoError(t, err, "Run() failed")
+	require.NoError(t, prod.Close())
+
+	dev, err := New(Config{
+		ModelsDir:    modelsDir,
+		DatabasePath: filepath.Join(tmpDir, "dev.duckdb"),
+		StatePath:    filepath.Join(tmpDir, "state.db"),
+		Target:       defaultTestTarget(),
+		Logger:       testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = dev.Close() }()
+	_, err = dev.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	results, err := dev.Clone(ctx, prodDatabase, []string{"staging.stg_users", "marts.users_view"})
+	require.NoError(t, err, "Clone() failed")
+	require.Len(t, results, 2)
+
+	assert.Equal(t, CloneResult{Model: "marts.users_view", Skipped: "view"}, results[0])
+	assert.Equal(t, CloneResult{Model: "staging.stg_users", Method: CloneMethodCopy}, results[1])
+
+	rows, err := dev.db.Query(ctx, "SELECT COUNT(*) FROM staging.stg_users")
+	require.NoError(t, err, "Query staging.stg_users failed")
+	var count int
+	if rows.Next() {
+		_ = rows.Scan(&count)
+	}
+	_ = rows.Close()
+	assert.Equal(t, 2, count, "staging.stg_users should have 2 rows")
+
+	// Cloning again replaces the table
+	results, err = dev.Clone(ctx, prodDatabase, []string{"staging.stg_users"})
+	require.NoError(t, err, "Clone() failed")
+	assert.NoError(t, results[0].Error)
+}
+
+func TestEngine_Diff(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	devSeedsDir := filepath.Join(tmpDir, "dev-seeds")
+	require.NoError(t, os.MkdirAll(devSeedsDir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(devSeedsDir, "users.csv"),
+		[]byte("id,name,email\n1,Alice,alice@example.com\n3,Carol,carol@example.com\n"), 0600))
+
+	ctx := testContext()
+	build := func(name, seeds string) string {
+		database := filepath.Join(tmpDir, name+".duckdb")
+		eng, err := New(Config{
+			ModelsDir:    modelsDir,
+			SeedsDir:     seeds,
+			DatabasePath: database,
+			StatePath:    filepath.Join(tmpDir, name+"-state.db"),
+			Target:       defaultTestTarget(),
+			Logger:       testutil.NewTestLogger(t),
+		})
+		require.NoError(t, err, "New() failed")
+		require.NoError(t, eng.LoadSeeds(ctx), "LoadSeeds() failed")
+		_, err = eng.Discover(DiscoveryOptions{})
+		require.NoError(t, err, "Discover() failed")
+		_, err = eng.Run(ctx, name)
+		require.NoError(t, err, "Run() failed")
+		require.NoError(t, eng.Close())
+		return database
+	}
+	prodDatabase := build("prod", seedsDir)
+	devDatabase := build("dev", devSeedsDir)
+
+	dev, err := New(Config{
+		ModelsDir:    modelsDir,
+		DatabasePath: devDatabase,
+		StatePath:    filepath.Join(tmpDir, "dev-state.db"),
+		Target:       defaultTestTarget(),
+		Logger:       testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = dev.Close() }()
+	_, err = dev.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	result, err := dev.Diff(ctx, "active_users", DiffOptions{DatabaseA: devDatabase, DatabaseB: prodDatabase})
+	require.NoError(t, err, "Diff() failed")
+
+	assert.False(t, result.Identical())
+	assert.Equal(t, int64(2), result.RowsA)
+	assert.Equal(t, int64(2), result.RowsB)
+	assert.Equal(t, []string{"id", "name", "email"}, result.Compared)
+	assert.Equal(t, int64(1), result.OnlyInA)
+	assert.Equal(t, int64(1), result.OnlyInB)
+	assert.Equal(t, [][]string{{"3", "Carol", "carol@example.com"}}, result.SampleA)
+	assert.Equal(t, [][]string{{"2", "Bob", "bob@example.com"}}, result.SampleB)
+
+	require.Len(t, result.Columns, 3)
+	id := result.Columns[0]
+	assert.True(t, id.Changed(), "id should differ")
+	assert.Equal(t, "3", id.A.Max)
+	assert.Equal(t, "2", id.B.Max)
+	assert.Equal(t, "4", id.A.Sum)
+	assert.Equal(t, "3", id.B.Sum)
+
+	_, err = dev.Diff(ctx, "active_users", DiffOptions{DatabaseA: prodDatabase, DatabaseB: prodDatabase})
+	assert.ErrorContains(t, err, "cannot diff a database with itself")
+}
+
+func TestEngine_RunConstraints(t *testing.T) {
+	const model = `/*---
+materialized: table
+tests:
+  - unique: [id]
+  - not_null: [id, email]
+---*/
+SELECT id, email FROM users`
+
+	tests := []struct {
+		name    string
+		mode    string
+		seed    string
+		wantErr []string
+	}{
+		{
+			name: "assert passes",
+			mode: ConstraintModeAssert,
+			seed: "id,email\n1,a@example.com\n2,b@example.com\n",
+		},
+		{
+			name: "assert fails",
+			mode: ConstraintModeAssert,
+			seed: "id,email\n1,a@example.com\n1,\n",
+			wantErr: []string{
+				"not_null test failed: users_out.email has 1 null rows",
+				"unique test failed: users_out has 1 duplicate values of (id)",
+			},
+		},
+		{
+			name:    "enforce fails",
+			mode:    ConstraintModeEnforce,
+			seed:    "id,email\n1,a@example.com\n2,\n",
+			wantErr: []string{"not_null constraint on users_out.email failed"},
+		},
+		{
+			name: "off ignores tests",
+			mode: ConstraintModeOff,
+			seed: "id,email\n1,a@example.com\n1,\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			modelsDir := filepath.Join(tmpDir, "models")
+			seedsDir := filepath.Join(tmpDir, "seeds")
+			require.NoError(t, os.MkdirAll(modelsDir, 0750))
+			require.NoError(t, os.MkdirAll(seedsDir, 0750))
+			require.NoError(t, os.WriteFile(filepath.Join(seedsDir, "users.csv"), []byte(tt.seed), 0600))
+			require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "users_out.sql"), []byte(model), 0600))
+
+			engine, err := New(Config{
+				ModelsDir: modelsDir,
+				SeedsDir:  seedsDir,
+				StatePath: filepath.Join(tmpDir, "state.db"),
+				Target:    defaultTestTarget(),
+				Logger:    testutil.NewTestLogger(t),
+			})
+			require.NoError(t, err, "New() failed")
+			defer func() { _ = engine.Close() }()
+			require.NoError(t, engine.SetConstraintMode(tt.mode))
+
+			ctx := testContext()
+			require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+			_, err = engine.Discover(DiscoveryOptions{})
+			require.NoError(t, err, "Discover() failed")
+
+			_, err = engine.Run(ctx, "test")
+			if len(tt.wantErr) == 0 {
+				require.NoError(t, err, "Run() failed")
+				return
+			}
+			require.Error(t, err)
+			for _, want := range tt.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
+
+func TestEngine_RunConstraintsEnforced(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "active_users.sql"), []byte(`/*---
+materialized: table
+tests:
+  - unique: [id]
+  - not_null: [email]
+---*/
+SELECT id, name, email FROM users`), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+	require.NoError(t, engine.SetConstraintMode(ConstraintModeEnforce))
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	_, err = engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+
+	// The constraints reject later writes
+	assert.Error(t, engine.db.Exec(ctx, "INSERT INTO active_users VALUES (3, 'Carol', NULL)"))
+	assert.Error(t, engine.db.Exec(ctx, "INSERT INTO active_users VALUES (1, 'Alice', 'alice2@example.com')"))
+
+	assert.Error(t, engine.SetConstraintMode("strict"))
+}
+
+func TestEngine_RunAuditColumns(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "active_users.sql"),
+		[]byte("/*---\naudit_columns: true\n---*/\nSELECT id, name FROM users;"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_events.sql"),
+		[]byte("/*---\nmaterialized: incremental\naudit_columns: true\n---*/\nSELECT id FROM users"), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	// The second run appends to the incremental model
+	var runIDs []string
+	for range 2 {
+		run, err := engine.Run(ctx, "test")
+		require.NoError(t, err, "Run() failed")
+		runIDs = append(runIDs, run.ID)
+	}
+
+	persisted, err := engine.store.GetModelByPath("active_users")
+	require.NoError(t, err)
+
+	for table, wantRunIDs := range map[string][]string{
+		"active_users": {runIDs[1], runIDs[1]},
+		"user_events":  {runIDs[0], runIDs[0], runIDs[1], runIDs[1]},
+	} {
+		meta, err := engine.db.GetTableMetadata(ctx, table)
+		require.NoError(t, err)
+		var columns []string
+		for _, col := range meta.Columns {
+			columns = append(columns, col.Name)
+		}
+		assert.Equal(t, core.AuditColumnNames, columns[len(columns)-3:], "%s audit columns", table)
+
+		rows, err := engine.db.Query(ctx, fmt.Sprintf("SELECT %s, %s, %s IS NOT NULL FROM %s ORDER BY 1, id",
+			core.AuditColumnRunID, core.AuditColumnModelHash, core.AuditColumnLoadedAt, table))
+		require.NoError(t, err)
+		var gotRunIDs []string
+		for rows.Next() {
+			var runID, hash string
+			var loaded bool
+			require.NoError(t, rows.Scan(&runID, &hash, &loaded))
+			gotRunIDs = append(gotRunIDs, runID)
+			assert.NotEmpty(t, hash)
+			assert.True(t, loaded)
+			if table == "active_users" {
+				assert.Equal(t, persisted.ContentHash, hash)
+			}
+		}
+		_ = rows.Close()
+		slices.Sort(wantRunIDs)
+		assert.Equal(t, wantRunIDs, gotRunIDs, "%s run IDs", table)
+	}
+}
+
+func TestEngine_RunStatements(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_domains.sql"), []byte(`CREATE TEMP MACRO domain(email) AS split_part(email, '@', 2);
+SELECT id, domain(email) AS domain FROM users;
+CREATE OR REPLACE TABLE user_domains_audit AS SELECT COUNT(*) AS n FROM {{ this.name }};
+`), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	_, err = engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+
+	count, err := engine.countRows(ctx, "SELECT COUNT(*) FROM user_domains WHERE domain = 'example.com'")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	count, err = engine.countRows(ctx, "SELECT n FROM user_domains_audit")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// A failing post statement rolls back the model's build
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_names.sql"),
+		[]byte("SELECT id, name FROM users;\nINSERT INTO missing_table VALUES (1);"), 0600))
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	_, err = engine.RunSelected(ctx, "test", []string{"user_names"}, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "post statement 1 of user_names")
+	_, err = engine.db.GetTableMetadata(ctx, "user_names")
+	assert.Error(t, err, "user_names should not exist after a rollback")
+}
+
+func TestEngine_RunTransactionPolicy(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	// A failing post statement does not roll back a build outside a transaction
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_names.sql"),
+		[]byte("/*---\ntransaction: never\n---*/\nSELECT id, name FROM users;\nINSERT INTO missing_table VALUES (1);"), 0600))
+
+	newEngine := func(policy core.TransactionPolicy) (*Engine, error) {
+		return New(Config{
+			ModelsDir:   modelsDir,
+			SeedsDir:    seedsDir,
+			StatePath:   filepath.Join(tmpDir, "state.db"),
+			Target:      defaultTestTarget(),
+			Transaction: policy,
+			Logger:      testutil.NewTestLogger(t),
+		})
+	}
+
+	_, err := newEngine("sometimes")
+	require.ErrorContains(t, err, `invalid transaction policy "sometimes"`)
+
+	engine, err := newEngine("")
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	_, err = engine.RunSelected(ctx, "test", []string{"user_names"}, false)
+	require.ErrorContains(t, err, "post statement 1 of user_names")
+	count, err := engine.countRows(ctx, "SELECT COUNT(*) FROM user_names")
+	require.NoError(t, err, "user_names should be kept without a transaction")
+	assert.Equal(t, int64(2), count)
+
+	// The model's policy overrides the target's, which overrides the adapter's
+	tests := []struct {
+		name   string
+		target core.TransactionPolicy
+		model  core.TransactionPolicy
+		want   core.TransactionPolicy
+	}{
+		{"adapter default", "", "", core.TransactionAuto},
+		{"target", core.TransactionNever, "", core.TransactionNever},
+		{"model", core.TransactionNever, core.TransactionAlways, core.TransactionAlways},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine.transaction = tt.target
+			assert.Equal(t, tt.want, engine.transactionPolicy(&core.Model{Transaction: tt.model}))
+		})
+	}
+}
+
+func TestEngine_RunConcurrencyLimits(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_names.sql"),
+		[]byte("/*---\nresource_class: heavy\n---*/\nSELECT id, name FROM active_users"), 0600))
+
+	newEngine := func(maxQueries int, classes map[string]int) (*Engine, error) {
+		return New(Config{
+			ModelsDir:            modelsDir,
+			SeedsDir:             seedsDir,
+			StatePath:            filepath.Join(tmpDir, "state.db"),
+			DatabasePath:         filepath.Join(tmpDir, "warehouse.duckdb"),
+			Target:               defaultTestTarget(),
+			MaxConcurrentQueries: maxQueries,
+			ResourceClasses:      classes,
+			Logger:               testutil.NewTestLogger(t),
+		})
+	}
+
+	_, err := newEngine(-1, nil)
+	require.ErrorContains(t, err, "invalid max_concurrent_queries -1")
+	_, err = newEngine(0, map[string]int{"heavy": 0})
+	require.ErrorContains(t, err, `invalid resource class "heavy" limit 0`)
+
+	engine, err := newEngine(1, map[string]int{"heavy": 1})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	// Each build releases its slots, so a run within a limit of one query
+	// builds every model
+	ctx, cancel := context.WithTimeout(testContext(), 30*time.Second)
+	defer cancel()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	_, err = engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+
+	// The slots are shared by the engines building against the same target
+	target := engine.cacheTarget()
+	require.NotEmpty(t, target)
+	other := &Engine{maxQueries: 2, resourceLimits: map[string]int{"heavy": 1}, logger: testutil.NewTestLogger(t)}
+	first := &Engine{maxQueries: 2, resourceLimits: map[string]int{"heavy": 1}, logger: testutil.NewTestLogger(t)}
+	heavy := &core.Model{Path: "user_names", ResourceClass: "heavy"}
+	light := &core.Model{Path: "active_users"}
+	blocked := func(e *Engine, target string, m *core.Model) bool {
+		waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		release, err := e.acquireBuildSlots(waitCtx, target, m)
+		if err != nil {
+			return true
+		}
+		release()
+		return false
+	}
+
+	releaseHeavy, err := first.acquireBuildSlots(ctx, target, heavy)
+	require.NoError(t, err)
+	assert.True(t, blocked(other, target, heavy), "the heavy class allows one build at a time")
+	releaseLight, err := other.acquireBuildSlots(ctx, target, light)
+	require.NoError(t, err, "a light build fits within max_concurrent_queries")
+	assert.True(t, blocked(other, target, light), "the target allows two builds at a time")
+
+	// An engine with a lower limit counts the builds of the others
+	strict := &Engine{maxQueries: 1, logger: testutil.NewTestLogger(t)}
+	assert.True(t, blocked(strict, target, light), "two builds are running, above a limit of one")
+
+	releaseHeavy()
+	assert.False(t, blocked(other, target, heavy), "a released slot is free again")
+	releaseLight()
+	assert.False(t, blocked(strict, target, light))
+
+	// In-memory targets are not shared, so only an engine's own builds count
+	// against their limits
+	releaseHeavy, err = first.acquireBuildSlots(ctx, "", heavy)
+	require.NoError(t, err)
+	assert.True(t, blocked(first, "", heavy), "the engine's in-memory target allows one heavy build")
+	assert.False(t, blocked(other, "", heavy), "other engines' in-memory targets are their own")
+	releaseHeavy()
+}
+
+// slowAdapter delays the statements of an adapter, counting the most it
+// executes at once.
+type slowAdapter struct {
+	adapter.Adapter
+	delay   time.Duration
+	mu      sync.Mutex
+	running int
+	most    int
+}
+
+func (a *slowAdapter) Exec(ctx context.Context, sql string) error {
+	a.mu.Lock()
+	a.running++
+	a.most = max(a.most, a.running)
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		a.running--
+		a.mu.Unlock()
+	}()
+	time.Sleep(a.delay)
+	return a.Adapter.Exec(ctx, sql)
+}
+
+func TestEngine_RunThreads(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	writeModel := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+	writeModel("user_ids.sql", "SELECT id FROM active_users")
+	writeModel("user_names.sql", "SELECT id, name FROM active_users")
+	writeModel("user_emails.sql", "SELECT id, email FROM active_users")
+	writeModel("user_domains.sql", "SELECT DISTINCT split_part(email, '@', 2) AS domain FROM user_emails")
+	writeModel("user_profiles.sql", "SELECT n.id, n.name, e.email FROM user_names n JOIN user_emails e ON n.id = e.id")
+
+	newEngine := func(maxQueries int) *Engine {
+		t.Helper()
+		engine, err := New(Config{
+			ModelsDir:            modelsDir,
+			SeedsDir:             seedsDir,
+			StatePath:            filepath.Join(tmpDir, "state.db"),
+			Target:               defaultTestTarget(),
+			MaxConcurrentQueries: maxQueries,
+			Logger:               testutil.NewTestLogger(t),
+		})
+		require.NoError(t, err, "New() failed")
+		t.Cleanup(func() { _ = engine.Close() })
+		engine.SetThreads(4)
+
+		ctx := testContext()
+		require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+		_, err = engine.Discover(DiscoveryOptions{})
+		require.NoError(t, err, "Discover() failed")
+		return engine
+	}
+	statuses := func(engine *Engine, run *core.Run) map[string]core.ModelRunStatus {
+		t.Helper()
+		modelRuns, err := engine.store.GetModelRunsWithModelInfo(run.ID)
+		require.NoError(t, err)
+		result := make(map[string]core.ModelRunStatus)
+		for _, mr := range modelRuns {
+			result[mr.ModelPath] = mr.Status
+		}
+		return result
+	}
+
+	// Independent models build at once, within the target's limit, and a
+	// model builds after the models it depends on
+	engine := newEngine(2)
+	slow := &slowAdapter{Adapter: engine.db, delay: 20 * time.Millisecond}
+	engine.db = slow
+	run, err := engine.Run(testContext(), "test")
+	require.NoError(t, err, "Run() failed")
+	assert.Equal(t, 2, slow.most, "two of the models after active_users build at once")
+	for path, status := range statuses(engine, run) {
+		assert.Equal(t, core.ModelRunStatusSuccess, status, path)
+	}
+	n, err := engine.countRows(testContext(), "SELECT COUNT(*) FROM user_profiles")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	// The models running when a model fails are built, and the models
+	// downstream of it are skipped
+	writeModel("user_names.sql", "SELECT id, no_such_column FROM active_users")
+	engine = newEngine(0)
+	run, err = engine.Run(testContext(), "test")
+	require.ErrorContains(t, err, "no_such_column")
+	got := statuses(engine, run)
+	assert.Equal(t, core.ModelRunStatusSuccess, got["user_ids"], "user_ids started with user_names")
+	assert.Equal(t, core.ModelRunStatusSuccess, got["user_emails"], "user_emails started with user_names")
+	assert.Equal(t, core.ModelRunStatusFailed, got["user_names"])
+	assert.Equal(t, core.ModelRunStatusSkipped, got["user_profiles"])
+}
+
+func TestEngine_RunSample(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	models := map[string]string{
+		"user_emails.sql": "/*---\nmaterialized: view\n---*/\nSELECT id, email FROM active_users;",
+		"all_users.sql":   "/*---\nsample: 0\n---*/\nSELECT id FROM users",
+		"user_events.sql": "/*---\nmaterialized: incremental\n---*/\nSELECT id FROM users",
+	}
+	for name, content := range models {
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	counts := func() map[string]int64 {
+		t.Helper()
+		_, err := engine.Run(ctx, "test")
+		require.NoError(t, err, "Run() failed")
+		result := make(map[string]int64)
+		for _, table := range []string{"active_users", "user_emails", "all_users", "user_events"} {
+			count, err := engine.countRows(ctx, "SELECT COUNT(*) FROM "+table)
+			require.NoError(t, err)
+			result[table] = count
+		}
+		return result
+	}
+
+	// Models are limited to the sample, except those with sample: 0; each
+	// incremental batch is sampled too
+	engine.SetSample(1)
+	assert.Equal(t, map[string]int64{"active_users": 1, "user_emails": 1, "all_users": 2, "user_events": 1}, counts())
+	assert.Equal(t, map[string]int64{"active_users": 1, "user_emails": 1, "all_users": 2, "user_events": 2}, counts())
+
+	engine.SetSample(0)
+	assert.Equal(t, map[string]int64{"active_users": 2, "user_emails": 2, "all_users": 2, "user_events": 4}, counts())
+}
+
+func TestEngine_RunExplain(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	modelPath := filepath.Join(modelsDir, "user_ids.sql")
+	require.NoError(t, os.WriteFile(modelPath, []byte("SELECT id FROM users"), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+
+	run := func() *core.Run {
+		t.Helper()
+		_, err := engine.Discover(DiscoveryOptions{})
+		require.NoError(t, err, "Discover() failed")
+		result, err := engine.Run(ctx, "test")
+		require.NoError(t, err, "Run() failed")
+		return result
+	}
+
+	// Plans are only captured when enabled
+	first := run()
+	plans, err := engine.store.GetModelPlansForRun(first.ID)
+	require.NoError(t, err)
+	assert.Empty(t, plans)
+
+	engine.SetExplain(true)
+	second := run()
+	plans, err = engine.store.GetModelPlansForRun(second.ID)
+	require.NoError(t, err)
+	require.Len(t, plans, 2)
+	assert.Contains(t, plans[0].Plan+plans[1].Plan, "SEQ_SCAN")
+
+	regressions, err := engine.PlanRegressions(second.ID)
+	require.NoError(t, err)
+	assert.Empty(t, regressions, "no previous plan to compare with")
+
+	// Scanning users twice and joining without a key regresses the plan
+	require.NoError(t, os.WriteFile(modelPath, []byte("SELECT a.id FROM users a CROSS JOIN users b"), 0600))
+	third := run()
+	regressions, err = engine.PlanRegressions(third.ID)
+	require.NoError(t, err)
+	require.Len(t, regressions, 1)
+	assert.Equal(t, "user_ids", regressions[0].Model)
+	assert.Equal(t, second.ID, regressions[0].PreviousRun)
+	assert.Contains(t, regressions[0].Changes, core.PlanChange{Operator: "full scans", Before: 1, After: 2})
+}
+
+func TestEngine_RunAnomalies(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	modelPath := filepath.Join(modelsDir, "user_names.sql")
+	writeModel := func(query string) {
+		t.Helper()
+		content := `/*---
+tests:
+  - row_count_drift:
+      max_change: 0.4
+  - null_rate_drift:
+      columns: [name]
+      max_change: 0.1
+      severity: error
+---*/
+
+` + query
+		require.NoError(t, os.WriteFile(modelPath, []byte(content), 0600))
+	}
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+
+	run := func(query string) *core.Run {
+		t.Helper()
+		writeModel(query)
+		_, err := engine.Discover(DiscoveryOptions{})
+		require.NoError(t, err, "Discover() failed")
+		result, _ := engine.Run(ctx, "test")
+		require.NotNil(t, result, "Run() returned no run")
+		return result
+	}
+
+	// The first run has nothing to compare with
+	first := run("SELECT id, name FROM users")
+	assert.Equal(t, core.RunStatusCompleted, first.Status)
+	metrics, err := engine.store.GetModelMetricsForRun(first.ID)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, core.MetricRowCount, metrics[0].Metric)
+	assert.InDelta(t, 2, metrics[0].Value, 0)
+	assert.Equal(t, "name", metrics[1].Column)
+	assert.InDelta(t, 0, metrics[1].Value, 0)
+	anomalies, err := engine.Anomalies(first.ID)
+	require.NoError(t, err)
+	assert.Empty(t, anomalies)
+
+	// Halving the row count is reported, without failing the run
+	second := run("SELECT id, name FROM users WHERE id = 1")
+	assert.Equal(t, core.RunStatusCompleted, second.Status)
+	anomalies, err = engine.Anomalies(second.ID)
+	require.NoError(t, err)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, "row count of user_names is 1: changed by -50.0% since the previous run (max 40.0%)", anomalies[0].String())
+	assert.False(t, anomalies[0].Fail)
+
+	// A null rate test with error severity fails the model
+	third := run("SELECT id, CASE WHEN id = 1 THEN name END AS name FROM users")
+	assert.Equal(t, core.RunStatusFailed, third.Status)
+	anomalies, err = engine.Anomalies(third.ID)
+	require.NoError(t, err)
+	require.Len(t, anomalies, 2)
+	assert.Equal(t, "null rate of user_names.name is 50.0%: changed by +50.0 points since the previous run (max 10.0)", anomalies[1].String())
+	assert.True(t, anomalies[1].Fail)
+}
+
+func TestEngine_RunMasking(t *testing.T) {
+	tests := []struct {
+		name      string
+		masking   *core.MaskingConfig
+		model     string
+		wantEmail any
+		wantErr   string
+	}{
+		{
+			name:      "unmasked",
+			model:     "SELECT id, name, email FROM users",
+			wantEmail: "alice@example.com",
+		},
+		{
+			name:      "hash",
+			masking:   &core.MaskingConfig{Mode: core.MaskingHash},
+			model:     "SELECT id, name, email FROM users",
+			wantEmail: "c160f8cc69a4f0bf2b0362752353d060",
+		},
+		{
+			name:      "null",
+			masking:   &core.MaskingConfig{Mode: core.MaskingNull},
+			model:     "SELECT id, name, email FROM users",
+			wantEmail: nil,
+		},
+		{
+			name:    "select star",
+			masking: &core.MaskingConfig{Mode: core.MaskingHash},
+			model:   "SELECT * FROM users",
+			wantErr: "columns are unknown",
+		},
+		{
+			name:    "policy unsupported by the adapter",
+			masking: &core.MaskingConfig{Mode: core.MaskingPolicy, Policy: "governance.mask_pii"},
+			model:   "SELECT id, name, email FROM users",
+			wantErr: "does not support masking policies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+			models := map[string]string{
+				"active_users.sql": "/*---\npii: [email]\n---*/\n" + tt.model,
+				"user_emails.sql":  "/*---\nmaterialized: view\n---*/\nSELECT id, email FROM active_users",
+			}
+			for name, content := range models {
+				require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+			}
+
+			engine, err := New(Config{
+				ModelsDir: modelsDir,
+				SeedsDir:  seedsDir,
+				StatePath: filepath.Join(tmpDir, "state.db"),
+				Target:    defaultTestTarget(),
+				Masking:   tt.masking,
+				Logger:    testutil.NewTestLogger(t),
+			})
+			require.NoError(t, err, "New() failed")
+			defer func() { _ = engine.Close() }()
+
+			ctx := testContext()
+			require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+			_, err = engine.Discover(DiscoveryOptions{})
+			require.NoError(t, err, "Discover() failed")
+
+			_, err = engine.Run(ctx, "test")
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err, "Run() failed")
+
+			// Downstream models read the masked values
+			for _, table := range []string{"active_users", "user_emails"} {
+				rows, err := engine.db.Query(ctx, "SELECT email FROM "+table+" WHERE id = 1")
+				require.NoError(t, err)
+				require.True(t, rows.Next())
+				var email any
+				require.NoError(t, rows.Scan(&email))
+				_ = rows.Close()
+				assert.Equal(t, tt.wantEmail, email, table)
+			}
+		})
+	}
+}
+
+func TestEngine_RunRowFilter(t *testing.T) {
+	tests := []struct {
+		environment string
+		wantRows    int64
+		wantFilter  string
+	}{
+		{environment: "dev", wantRows: 1, wantFilter: "id < {{ 1 + 1 }}"},
+		{environment: "prod", wantRows: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.environment, func(t *testing.T) {
+			tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+			model := "/*---\nconfig:\n  dev:\n    where: \"id < {{ 1 + 1 }}\"\n---*/\nSELECT id, name FROM users;"
+			require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "active_users.sql"), []byte(model), 0600))
+
+			engine, err := New(Config{
+				ModelsDir:   modelsDir,
+				SeedsDir:    seedsDir,
+				StatePath:   filepath.Join(tmpDir, "state.db"),
+				Environment: tt.environment,
+				Target:      defaultTestTarget(),
+				Logger:      testutil.NewTestLogger(t),
+			})
+			require.NoError(t, err, "New() failed")
+			defer func() { _ = engine.Close() }()
+
+			ctx := testContext()
+			require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+			_, err = engine.Discover(DiscoveryOptions{})
+			require.NoError(t, err, "Discover() failed")
+
+			// The filter is part of the compiled SQL
+			sql, err := engine.RenderModel("active_users")
+			require.NoError(t, err)
+			if tt.wantFilter != "" {
+				assert.Contains(t, sql, "AS leapsql_filtered WHERE id < 2")
+			} else {
+				assert.NotContains(t, sql, "leapsql_filtered")
+			}
+
+			run, err := engine.Run(ctx, tt.environment)
+			require.NoError(t, err, "Run() failed")
+			count, err := engine.countRows(ctx, "SELECT COUNT(*) FROM active_users")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRows, count)
+
+			// Model runs record the declared filter
+			modelRuns, err := engine.store.GetModelRunsForRun(run.ID)
+			require.NoError(t, err)
+			require.Len(t, modelRuns, 1)
+			assert.Equal(t, tt.wantFilter, modelRuns[0].RowFilter)
+		})
+	}
+}
+
+func TestEngine_RunTranspile(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	// Written for Snowflake, built on DuckDB
+	model := "SELECT id, nvl(name, 'none') AS name, startswith(name, 'A') AS a_name FROM users"
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "named_users.sql"), []byte(model), 0600))
+
+	engine, err := New(Config{
+		ModelsDir:   modelsDir,
+		SeedsDir:    seedsDir,
+		StatePath:   filepath.Join(tmpDir, "state.db"),
+		Dialect:     "snowflake",
+		Environment: "dev",
+		Target:      defaultTestTarget(),
+		Logger:      testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	sql, err := engine.RenderModel("named_users")
+	require.NoError(t, err)
+	assert.Contains(t, sql, "IFNULL(name, 'none')")
+	assert.Contains(t, sql, "STARTS_WITH(name, 'A')")
+
+	sql, err = engine.RenderModelForDialect("named_users", "postgres")
+	require.NoError(t, err)
+	assert.Contains(t, sql, "COALESCE(name, 'none')")
+
+	_, err = engine.Run(ctx, "dev")
+	require.NoError(t, err, "Run() failed")
+	count, err := engine.countRows(ctx, "SELECT COUNT(*) FROM named_users WHERE name <> 'none'")
+	require.NoError(t, err)
+	assert.Positive(t, count)
+}
+
+func TestTranspileSQL(t *testing.T) {
+	duckdb, _ := dialect.Get("duckdb")
+	snowflake, _ := dialect.Get("snowflake")
+
+	// Same dialect: unchanged
+	sql := "select ifnull(a, 0) from t"
+	got, err := TranspileSQL(sql, duckdb, duckdb)
+	require.NoError(t, err)
+	assert.Equal(t, sql, got)
+
+	got, err = TranspileSQL(sql, duckdb, snowflake)
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT\n  IFNULL(a, 0)\nFROM t\n", got)
+
+	// Syntax the target dialect lacks
+	_, err = TranspileSQL("SELECT [1, 2] AS l FROM t", duckdb, snowflake)
+	var unsupported *transpile.UnsupportedError
+	require.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, "snowflake", unsupported.Dialect)
+}
+
+func TestFormatModel(t *testing.T) {
+	duckdb, _ := dialect.Get("duckdb")
+
+	content := "/*---\nname: orders\n---*/\n-- Paid orders\nselect id, amount from {{ ref('raw_orders') }} where amount > 0;\n"
+	got, err := FormatModel(content, duckdb, format.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "/*---\nname: orders\n---*/\n\n-- Paid orders\nSELECT\n  id,\n  amount\nFROM {{ ref('raw_orders') }}\nWHERE\n  amount > 0\n", got)
+
+	// Formatted models are left as they are
+	again, err := FormatModel(got, duckdb, format.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, got, again)
+
+	// Models without SQL and with template statements
+	external := "/*---\nmaterialized: external\n---*/\n"
+	got, err = FormatModel(external, duckdb, format.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, external, got)
+	_, err = FormatModel("{* if true: *}SELECT 1{* endif *}", duckdb, format.Options{})
+	assert.Error(t, err)
+
+	// Casing policies leave quoted identifiers alone
+	got, err = FormatModel(`SELECT COUNT(*) AS "OrderCount", CUSTOMER_ID, OrderDate FROM ORDERS GROUP BY ALL`, duckdb,
+		format.Options{Keywords: core.CasingLower, Functions: core.CasingLower, Identifiers: core.CasingSnake})
+	require.NoError(t, err)
+	assert.Equal(t, "select\n  count(*) as \"OrderCount\",\n  customer_id,\n  OrderDate\nfrom orders\ngroup by all\n", got)
+}
+
+func TestEngine_RunExternal(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	files := map[string]string{
+		// SQL -> external -> SQL
+		"user_scores.sql": `/*---
+materialized: external
+external:
+  command: ["sh", "score.sh"]
+  env:
+    BONUS: "10"
+  inputs: [active_users]
+  columns: [id, score]
+---*/`,
+		"score.sh": `awk -F, -v bonus="$BONUS" 'NR == 1 { print "id,score"; next } { print $1 "," $1 * 100 + bonus }' \
+  "$LEAPSQL_INPUT_DIR/active_users.csv" > "$LEAPSQL_OUTPUT"`,
+		"top_users.sql": "SELECT id FROM user_scores WHERE score > 150",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	result, err := engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	require.Empty(t, result.Errors)
+
+	assert.Equal(t, []string{"active_users"}, engine.graph.GetParents("user_scores"))
+	assert.Equal(t, []string{"user_scores"}, engine.graph.GetParents("top_users"))
+
+	run, err := engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+	modelRuns, err := engine.store.GetModelRunsForRun(run.ID)
+	require.NoError(t, err)
+	require.Len(t, modelRuns, 3)
+
+	count, err := engine.countRows(ctx, "SELECT SUM(score) FROM user_scores")
+	require.NoError(t, err)
+	assert.Equal(t, int64(320), count)
+	count, err = engine.countRows(ctx, "SELECT COUNT(*) FROM top_users")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// A command that fails, or produces nothing, fails the model, even with
+	// the table of a previous build in place
+	for script, wantErr := range map[string]string{
+		"echo 'model not trained' >&2; exit 2": "model not trained",
+		"true":                                 "command wrote neither $LEAPSQL_OUTPUT nor table user_scores",
+	} {
+		require.NoError(t, engine.db.Exec(ctx, "CREATE OR REPLACE TABLE user_scores AS SELECT 1 AS id, 100 AS score"))
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "score.sh"), []byte(script), 0600))
+		_, err = engine.RunSelected(ctx, "test", []string{"user_scores"}, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), wantErr)
+	}
+}
+
+func TestEngine_RunSwapsTables(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	viewPath := filepath.Join(modelsDir, "user_names.sql")
+	require.NoError(t, os.WriteFile(viewPath, []byte("/*---\nmaterialized: view\n---*/\nSELECT name FROM active_users"), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	_, err = engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+
+	// The table is built under a staging name and swapped in
+	_, err = engine.db.GetTableMetadata(ctx, "active_users__staging")
+	assert.Error(t, err, "staging table should be swapped in")
+	count, err := engine.countRows(ctx, "SELECT COUNT(*) FROM active_users")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// A view is replaced even when its columns change
+	require.NoError(t, os.WriteFile(viewPath, []byte("/*---\nmaterialized: view\n---*/\nSELECT id, name FROM active_users"), 0600))
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	_, err = engine.RunSelected(ctx, "test", []string{"user_names"}, false)
+	require.NoError(t, err)
+	meta, err := engine.db.GetTableMetadata(ctx, "user_names")
+	require.NoError(t, err)
+	assert.Len(t, meta.Columns, 2)
+
+	// A failed build leaves the old table in place
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "active_users.sql"),
+		[]byte("SELECT id, name FROM missing_table"), 0600))
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	_, err = engine.RunSelected(ctx, "test", []string{"active_users"}, false)
+	require.Error(t, err)
+	count, err = engine.countRows(ctx, "SELECT COUNT(*) FROM active_users")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	_, err = engine.db.GetTableMetadata(ctx, "active_users__staging")
+	assert.Error(t, err, "staging table of a failed build should be dropped")
+}
+
+func TestEngine_GroupByOwner(t *testing.T) {
+	tmpDir := t.TempDir()
+	finance := core.GroupConfig{Name: "finance", Owner: "finance-data", SlackChannel: "#finance-alerts"}
+	growth := core.GroupConfig{Name: "growth", Owner: "growth-team"}
+
+	engine, err := New(Config{
+		ModelsDir: filepath.Join(tmpDir, "models"),
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Groups:    []core.GroupConfig{finance, growth},
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	engine.models = map[string]*core.Model{
+		"marts.fct_revenue":  {Path: "marts.fct_revenue", Group: "finance"},
+		"marts.fct_invoices": {Path: "marts.fct_invoices", Group: "finance"},
+		"marts.fct_signups":  {Path: "marts.fct_signups", Group: "growth"},
+		"marts.fct_misc":     {Path: "marts.fct_misc"},
+		"marts.fct_typo":     {Path: "marts.fct_typo", Group: "fnance"},
+	}
+
+	got := engine.GroupByOwner([]string{
+		"marts.fct_signups", "marts.fct_revenue", "marts.fct_typo",
+		"marts.fct_invoices", "marts.fct_misc", "raw_orders",
+	})
+
+	assert.Equal(t, []GroupModels{
+		{Group: finance, Models: []string{"marts.fct_invoices", "marts.fct_revenue"}},
+		{Group: growth, Models: []string{"marts.fct_signups"}},
+		{Models: []string{"marts.fct_misc", "marts.fct_typo"}},
+	}, got)
+
+	_, ok := engine.GetGroup("finance")
+	assert.True(t, ok)
+	_, ok = engine.GetGroup("fnance")
+	assert.False(t, ok)
+}
+
+func TestEngine_SelectModels(t *testing.T) {
+	tmpDir := t.TempDir()
+	engine, err := New(Config{
+		ModelsDir: filepath.Join(tmpDir, "models"),
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	engine.models = map[string]*core.Model{
+		"staging.stg_users": {Path: "staging.stg_users", Name: "stg_users", Tags: []string{"pii"}},
+		"marts.dim_users":   {Path: "marts.dim_users", Name: "dim_users", Tags: []string{"pii", "deprecated"}},
+		"marts.fct_orders":  {Path: "marts.fct_orders", Name: "fct_orders"},
+	}
+
+	selected, err := engine.SelectModels("tag:pii AND NOT tag:deprecated")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"staging.stg_users"}, selected)
+
+	selected, err = engine.SelectModels("fct_orders, marts.dim_users")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"marts.dim_users", "marts.fct_orders"}, selected)
+
+	_, err = engine.SelectModels("tag:pi")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown tag "pi"`)
+}
+
+func TestEngine_SelectModels_RunState(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	writeModel := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+	writeModel("user_emails.sql", "SELECT id, email FROM active_users")
+	writeModel("user_names.sql", "SELECT id, name FROM users")
+
+	engine, err := New(Config{
+		ModelsDir:   modelsDir,
+		SeedsDir:    seedsDir,
+		StatePath:   filepath.Join(tmpDir, "state.db"),
+		Environment: "test",
+		Target:      defaultTestTarget(),
+		Logger:      testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	discover := func() {
+		t.Helper()
+		_, err := engine.Discover(DiscoveryOptions{})
+		require.NoError(t, err, "Discover() failed")
+	}
+
+	// Before any run, every model is modified
+	discover()
+	selected, err := engine.SelectModels("state:modified")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"active_users", "user_emails", "user_names"}, selected)
+
+	result, err := engine.Run(ctx, "test")
+	require.NoError(t, err)
+	assert.Equal(t, core.RunStatusCompleted, result.Status)
+	selected, err = engine.SelectModels("result:error+ state:modified+")
+	require.NoError(t, err)
+	assert.Empty(t, selected)
+
+	// user_emails fails and stays modified until it builds
+	writeModel("user_emails.sql", "SELECT id, no_such_column FROM active_users")
+	discover()
+	result, _ = engine.Run(ctx, "test")
+	require.NotNil(t, result)
+	assert.Equal(t, core.RunStatusFailed, result.Status)
+	selected, err = engine.SelectModels("result:error+ state:modified+")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_emails"}, selected)
+
+	// Editing a model that built marks it modified
+	writeModel("user_names.sql", "SELECT id, name AS user_name FROM users")
+	discover()
+	selected, err = engine.SelectModels("result:error+ state:modified+")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_emails", "user_names"}, selected)
+
+	// Retrying the selection clears it
+	writeModel("user_emails.sql", "SELECT id, email FROM active_users")
+	discover()
+	result, err = engine.RunSelected(ctx, "test", selected, false)
+	require.NoError(t, err)
+	assert.Equal(t, core.RunStatusCompleted, result.Status)
+	selected, err = engine.SelectModels("result:error+ state:modified+")
+	require.NoError(t, err)
+	assert.Empty(t, selected)
+	selected, err = engine.SelectModels("+user_emails")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"active_users", "user_emails"}, selected)
+}
+
 func TestNew_MissingTargetConfig(t *testing.T) {
 	tmpDir := t.TempDir()
 	statePath := filepath.Join(tmpDir, "state.db")
-	modelsDir := filepath.Join(tmpDir, "models")
-	require.NoError(t, os.MkdirAll(modelsDir, 0750))
+	modelsDir := filepath.Join(tmpDir, "models")
+	require.NoError(t, os.MkdirAll(modelsDir, 0750))
+
+	cfg := Config{
+		ModelsDir: modelsDir,
+		StatePath: statePath,
+		// No Target or AdapterConfig
+		Logger: testutil.NewTestLogger(t),
+	}
+
+	_, err := New(cfg)
+	require.Error(t, err, "New() should fail without target config")
+	assert.Contains(t, err.Error(), "target configuration required")
+}
+
+func TestNew_EmptyAdapterType(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.db")
+	modelsDir := filepath.Join(tmpDir, "models")
+	require.NoError(t, os.MkdirAll(modelsDir, 0750))
+
+	cfg := Config{
+		ModelsDir: modelsDir,
+		StatePath: statePath,
+		Target: &starctx.TargetInfo{
+			Type:   "", // Empty type
+			Schema: "main",
+		},
+		Logger: testutil.NewTestLogger(t),
+	}
+
+	_, err := New(cfg)
+	require.Error(t, err, "New() should fail with empty adapter type")
+	assert.Contains(t, err.Error(), "adapter type required")
+}
+
+func TestNew_UnknownAdapterType(t *testing.T) {
+	tmpDir := t.TempDir()
+	statePath := filepath.Join(tmpDir, "state.db")
+	modelsDir := filepath.Join(tmpDir, "models")
+	require.NoError(t, os.MkdirAll(modelsDir, 0750))
+
+	cfg := Config{
+		ModelsDir: modelsDir,
+		StatePath: statePath,
+		Target: &starctx.TargetInfo{
+			Type:   "unknown_db",
+			Schema: "main",
+		},
+		Logger: testutil.NewTestLogger(t),
+	}
+
+	_, err := New(cfg)
+	require.Error(t, err, "New() should fail with unknown adapter type")
+	assert.Contains(t, err.Error(), "unknown adapter type")
+	assert.Contains(t, err.Error(), "unknown_db")
+}
+
+func TestNew_UnknownDialect(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	_, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Dialect:   "oracle",
+		Target:    defaultTestTarget(),
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown dialect "oracle"`)
+}
+
+func TestEngine_CostReport(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "active_users.sql"),
+		[]byte("/*---\nowner: data-team\ntags: [finance, daily]\n---*/\nSELECT id, name FROM users"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_count.sql"),
+		[]byte("/*---\nmaterialized: view\n---*/\nSELECT COUNT(*) AS n FROM active_users"), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	run, err := engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+
+	// The table's build statement is measured; the view's is not
+	modelRuns, err := engine.store.GetModelRunsForRun(run.ID)
+	require.NoError(t, err)
+	require.Len(t, modelRuns, 2)
+	scanned := make(map[string]int64)
+	for _, mr := range modelRuns {
+		m, err := engine.store.GetModelByID(mr.ModelID)
+		require.NoError(t, err)
+		scanned[m.Path] = mr.BytesScanned
+	}
+	assert.Positive(t, scanned["active_users"], "table bytes scanned")
+	assert.Zero(t, scanned["user_count"], "view bytes scanned")
+
+	tests := []struct {
+		name     string
+		opts     CostReportOptions
+		wantKeys []string
+	}{
+		{"by model", CostReportOptions{}, []string{"active_users", "user_count"}},
+		{"by tag", CostReportOptions{By: CostByTag}, []string{"daily", "finance", "(untagged)"}},
+		{"by owner", CostReportOptions{By: CostByOwner}, []string{"data-team", "(no owner)"}},
+		{"other environment", CostReportOptions{Environment: "prod"}, nil},
+		{"before since", CostReportOptions{Since: time.Now().Add(time.Hour)}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := engine.CostReport(tt.opts)
+			require.NoError(t, err)
+
+			var keys []string
+			for _, s := range report {
+				keys = append(keys, s.Key)
+				assert.Equal(t, 1, s.ModelRuns, s.Key)
+				assert.Empty(t, s.Period, s.Key)
+			}
+			assert.Equal(t, tt.wantKeys, keys)
+		})
+	}
+
+	report, err := engine.CostReport(CostReportOptions{Interval: CostIntervalMonth})
+	require.NoError(t, err)
+	require.NotEmpty(t, report)
+	assert.Equal(t, run.StartedAt.UTC().Format("2006-01")+"-01", report[0].Period)
+
+	_, err = engine.CostReport(CostReportOptions{By: "team"})
+	assert.ErrorContains(t, err, "invalid cost grouping")
+}
+
+func TestEngine_QueryComment(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		model    string
+		want     string
+	}{
+		{
+			name:  "default",
+			model: "staging.orders",
+			want:  `{"app":"leapsql","project":"jaffle","model":"staging.orders","run_id":"run-1","user":"alice","environment":"dev","target":"duckdb"}`,
+		},
+		{
+			name:     "custom template",
+			template: "leapsql model={{ .Model }} run={{ .RunID }}",
+			model:    "staging.orders",
+			want:     "leapsql model=staging.orders run=run-1",
+		},
+		{
+			name:     "workspace model",
+			template: "{{ .Project }}:{{ .Model }}",
+			model:    "marketing/marts.campaigns",
+			want:     "marketing:marts.campaigns",
+		},
+		{
+			name:     "off",
+			template: QueryCommentOff,
+			model:    "staging.orders",
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+			engine, err := New(Config{
+				ModelsDir:    modelsDir,
+				SeedsDir:     seedsDir,
+				StatePath:    filepath.Join(tmpDir, "state.db"),
+				Target:       defaultTestTarget(),
+				ProjectName:  "jaffle",
+				QueryComment: tt.template,
+				Logger:       testutil.NewTestLogger(t),
+			})
+			require.NoError(t, err, "New() failed")
+			defer func() { _ = engine.Close() }()
+			engine.user = "alice"
+
+			ctx := engine.withQueryComment(testContext(), "run-1", tt.model)
+			assert.Equal(t, tt.want, adapter.QueryComment(ctx))
+		})
+	}
+
+	tmpDir, modelsDir, _, _ := createTestProject(t)
+	_, err := New(Config{
+		ModelsDir:    modelsDir,
+		StatePath:    filepath.Join(tmpDir, "state.db"),
+		Target:       defaultTestTarget(),
+		QueryComment: "{{ .Model",
+	})
+	assert.ErrorContains(t, err, "invalid query_comment template")
+}
+
+func TestEngine_RunLock(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	statePath := filepath.Join(tmpDir, "state.db")
+
+	newEngine := func() *Engine {
+		engine, err := New(Config{
+			ModelsDir: modelsDir,
+			SeedsDir:  seedsDir,
+			StatePath: statePath,
+			Target:    defaultTestTarget(),
+			Logger:    testutil.NewTestLogger(t),
+		})
+		require.NoError(t, err, "New() failed")
+		t.Cleanup(func() { _ = engine.Close() })
+
+		require.NoError(t, engine.LoadSeeds(testContext()), "LoadSeeds() failed")
+		_, err = engine.Discover(DiscoveryOptions{})
+		require.NoError(t, err, "Discover() failed")
+		return engine
+	}
+
+	holder, other := newEngine(), newEngine()
+	ctx := testContext()
+
+	unlock, _, err := holder.lockRuns(ctx)
+	require.NoError(t, err)
+
+	_, err = other.Run(ctx, "test")
+	require.ErrorIs(t, err, core.ErrStateLocked)
+
+	other.SetLock(LockConfig{Disabled: true})
+	_, err = other.Run(ctx, "test")
+	require.NoError(t, err, "Run() without locking failed")
+
+	unlock()
+	other.SetLock(LockConfig{})
+	_, err = other.Run(ctx, "test")
+	require.NoError(t, err, "Run() after the lock was released failed")
+}
+
+func TestEngine_Cleanup(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_events.sql"),
+		[]byte("/*---\nmaterialized: incremental\nunique_key: id\n---*/\nSELECT id FROM users"), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	// A crashed run: still running, with a model running and one pending
+	crashed, err := engine.store.CreateRun("test")
+	require.NoError(t, err)
+	var modelRunIDs []string
+	for _, path := range []string{"user_events", "active_users"} {
+		persisted, err := engine.store.GetModelByPath(path)
+		require.NoError(t, err)
+		status := core.ModelRunStatusPending
+		if path == "user_events" {
+			status = core.ModelRunStatusRunning
+		}
+		mr := &core.ModelRun{RunID: crashed.ID, ModelID: persisted.ID, Status: status}
+		require.NoError(t, engine.store.RecordModelRun(mr))
+		modelRunIDs = append(modelRunIDs, mr.ID)
+	}
+	require.NoError(t, engine.ensureDBConnected(ctx))
+	require.NoError(t, engine.db.Exec(ctx, "CREATE TABLE user_events_temp AS SELECT 1 AS id"))
+	require.NoError(t, engine.db.Exec(ctx, "CREATE TABLE active_users__staging AS SELECT 1 AS id"))
+
+	result, err := engine.Cleanup(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{crashed.ID}, result.InterruptedRuns)
+	assert.Equal(t, []string{"active_users__staging", "user_events_temp"}, result.DroppedTables)
+
+	run, err := engine.store.GetRun(crashed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, core.RunStatusFailed, run.Status)
+	assert.Contains(t, run.Error, "interrupted")
+
+	modelRuns, err := engine.store.GetModelRunsForRun(crashed.ID)
+	require.NoError(t, err)
+	statuses := make(map[string]core.ModelRunStatus)
+	for _, mr := range modelRuns {
+		statuses[mr.ID] = mr.Status
+		if mr.ID == modelRunIDs[1] {
+			assert.Equal(t, core.SkipReasonInterrupted, mr.SkipReason)
+		}
+	}
+	assert.Equal(t, core.ModelRunStatusFailed, statuses[modelRunIDs[0]])
+	assert.Equal(t, core.ModelRunStatusSkipped, statuses[modelRunIDs[1]])
+
+	// A second cleanup finds nothing left
+	result, err = engine.Cleanup(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, result.InterruptedRuns)
+	assert.Empty(t, result.DroppedTables)
+	assert.Zero(t, result.PrunedModelRuns)
+
+	// Without locking, orphaned runs cannot be told from runs in progress
+	engine.SetLock(LockConfig{Disabled: true})
+	_, err = engine.Cleanup(ctx)
+	assert.ErrorContains(t, err, "requires state locking")
+}
+
+func TestEngine_RunRecoversOrphanedRuns(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	crashed, err := engine.store.CreateRun("test")
+	require.NoError(t, err)
+
+	_, err = engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+
+	run, err := engine.store.GetRun(crashed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, core.RunStatusFailed, run.Status, "orphaned run should be marked failed")
+}
+
+func TestEngine_RunBuildCache(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	models := map[string]string{
+		"user_count.sql":  "/*---\nmaterialized: table\n---*/\nSELECT count(*) AS n FROM active_users",
+		"user_events.sql": "/*---\nmaterialized: incremental\n---*/\nSELECT id FROM users",
+		"event_count.sql": "/*---\nmaterialized: table\n---*/\nSELECT count(*) AS n FROM user_events",
+	}
+	for name, content := range models {
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+
+	engine, err := New(Config{
+		ModelsDir:    modelsDir,
+		SeedsDir:     seedsDir,
+		StatePath:    filepath.Join(tmpDir, "state.db"),
+		DatabasePath: filepath.Join(tmpDir, "warehouse.duckdb"),
+		Target:       defaultTestTarget(),
+		Logger:       testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+
+	// run loads seeds, runs all models and returns the status of each model
+	messages := make(map[string]string)
+	run := func() map[string]core.ModelRunStatus {
+		t.Helper()
+		require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+		_, err := engine.Discover(DiscoveryOptions{})
+		require.NoError(t, err, "Discover() failed")
+		result, err := engine.Run(ctx, "test")
+		require.NoError(t, err, "Run() failed")
+
+		modelRuns, err := engine.store.GetModelRunsWithModelInfo(result.ID)
+		require.NoError(t, err)
+		statuses := make(map[string]core.ModelRunStatus)
+		for _, mr := range modelRuns {
+			statuses[mr.ModelPath] = mr.Status
+			messages[mr.ModelPath] = mr.Error
+		}
+		return statuses
+	}
+
+	all := func(status core.ModelRunStatus) map[string]core.ModelRunStatus {
+		return map[string]core.ModelRunStatus{
+			"active_users": status, "user_count": status, "user_events": core.ModelRunStatusSuccess, "event_count": core.ModelRunStatusSuccess,
+		}
+	}
+
+	assert.Equal(t, all(core.ModelRunStatusSuccess), run(), "first run builds every model")
+
+	// Incremental models always run, so their downstream models rebuild too
+	assert.Equal(t, all(core.ModelRunStatusSkipped), run(), "unchanged models are cache hits")
+	assert.Contains(t, messages["active_users"], "cache hit: unchanged since run")
+
+	// Changing a seed rebuilds the models reading it and their dependents
+	require.NoError(t, os.WriteFile(filepath.Join(seedsDir, "users.csv"),
+		[]byte("id,name,email\n1,Alice,alice@example.com\n"), 0600))
+	assert.Equal(t, all(core.ModelRunStatusSuccess), run(), "seed change invalidates the cache")
+	assert.Equal(t, all(core.ModelRunStatusSkipped), run())
+
+	// Changing a model's SQL rebuilds it and its dependents only
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_count.sql"),
+		[]byte("/*---\nmaterialized: table\n---*/\nSELECT count(*) AS users FROM active_users"), 0600))
+	statuses := run()
+	assert.Equal(t, core.ModelRunStatusSkipped, statuses["active_users"])
+	assert.Equal(t, core.ModelRunStatusSuccess, statuses["user_count"])
+
+	// A dropped relation is rebuilt
+	require.NoError(t, engine.db.Exec(ctx, "DROP TABLE active_users"))
+	statuses = run()
+	assert.Equal(t, core.ModelRunStatusSuccess, statuses["active_users"])
+	assert.Equal(t, core.ModelRunStatusSuccess, statuses["user_count"])
+
+	// Full refresh ignores the cache
+	engine.SetFullRefresh(true)
+	assert.Equal(t, all(core.ModelRunStatusSuccess), run(), "full refresh rebuilds every model")
+}
+
+func TestEngine_RunBuildCacheRawSources(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	models := map[string]string{
+		"order_count.sql":  "/*---\nmaterialized: table\n---*/\nSELECT count(*) AS n FROM raw_orders",
+		"refund_count.sql": "/*---\nmaterialized: table\n---*/\nSELECT count(*) AS n FROM raw_refunds",
+		"order_events.sql": "/*---\nmaterialized: incremental\n---*/\nSELECT id FROM raw_orders",
+	}
+	for name, content := range models {
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+
+	engine, err := New(Config{
+		ModelsDir:    modelsDir,
+		SeedsDir:     seedsDir,
+		StatePath:    filepath.Join(tmpDir, "state.db"),
+		DatabasePath: filepath.Join(tmpDir, "warehouse.duckdb"),
+		Target:       defaultTestTarget(),
+		TableSources: []core.TableSourceConfig{{Table: "raw_orders", LoadedAt: "loaded_at"}},
+		Logger:       testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.EnsureConnected(ctx))
+	require.NoError(t, engine.db.Exec(ctx, "CREATE TABLE raw_orders AS SELECT 1 AS id, TIMESTAMP '2024-01-01 06:00:00' AS loaded_at"))
+	require.NoError(t, engine.db.Exec(ctx, "CREATE TABLE raw_refunds AS SELECT 1 AS id"))
+
+	// run runs all models and returns the model runs by model
+	run := func() map[string]*core.ModelRunWithInfo {
+		t.Helper()
+		require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+		_, err := engine.Discover(DiscoveryOptions{})
+		require.NoError(t, err, "Discover() failed")
+		result, err := engine.Run(ctx, "test")
+		require.NoError(t, err, "Run() failed")
+
+		modelRuns, err := engine.store.GetModelRunsWithModelInfo(result.ID)
+		require.NoError(t, err)
+		byModel := make(map[string]*core.ModelRunWithInfo)
+		for _, mr := range modelRuns {
+			byModel[mr.ModelPath] = mr
+		}
+		return byModel
+	}
+
+	// count returns the row count a model's table holds
+	count := func(table string) int64 {
+		t.Helper()
+		rows, err := engine.db.Query(ctx, "SELECT n FROM "+table)
+		require.NoError(t, err)
+		defer func() { _ = rows.Close() }()
+		var n int64
+		require.True(t, rows.Next())
+		require.NoError(t, rows.Scan(&n))
+		return n
+	}
+
+	run()
+
+	// Models reading raw tables whose changes are unknown always rebuild
+	second := run()
+	assert.Equal(t, core.SkipReasonCacheHit, second["order_count"].SkipReason)
+	assert.Equal(t, core.ModelRunStatusSuccess, second["refund_count"].Status)
+
+	require.NoError(t, engine.db.Exec(ctx, "INSERT INTO raw_refunds VALUES (2)"))
+	assert.Equal(t, core.ModelRunStatusSuccess, run()["refund_count"].Status)
+	assert.Equal(t, int64(2), count("refund_count"))
+
+	// Rows loaded into a table_sources table invalidate the cache
+	require.NoError(t, engine.db.Exec(ctx, "INSERT INTO raw_orders VALUES (2, TIMESTAMP '2024-01-02 06:00:00')"))
+	assert.Equal(t, core.ModelRunStatusSuccess, run()["order_count"].Status, "raw table change invalidates the cache")
+	assert.Equal(t, int64(2), count("order_count"))
+	assert.Equal(t, core.SkipReasonCacheHit, run()["order_count"].SkipReason)
+
+	// Without the cache tables rebuild, while incremental models still merge
+	engine.SetNoCache(true)
+	noCache := run()
+	assert.Equal(t, core.ModelRunStatusSuccess, noCache["order_count"].Status, "no cache rebuilds cached models")
+	assert.Equal(t, core.BuildModeFull, noCache["order_count"].BuildMode)
+	assert.Equal(t, core.BuildModeIncremental, noCache["order_events"].BuildMode)
+}
+
+func TestEngine_RunSkipFresh(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	models := map[string]string{
+		"order_events.sql":  "/*---\nmaterialized: incremental\n---*/\nSELECT id, loaded_at FROM raw_orders",
+		"refund_events.sql": "/*---\nmaterialized: incremental\n---*/\nSELECT id FROM raw_refunds",
+		"user_events.sql":   "/*---\nmaterialized: incremental\n---*/\nSELECT id FROM users",
+	}
+	for name, content := range models {
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+
+	engine, err := New(Config{
+		ModelsDir:    modelsDir,
+		SeedsDir:     seedsDir,
+		StatePath:    filepath.Join(tmpDir, "state.db"),
+		DatabasePath: filepath.Join(tmpDir, "warehouse.duckdb"),
+		Target:       defaultTestTarget(),
+		TableSources: []core.TableSourceConfig{{Table: "raw_orders", LoadedAt: "loaded_at"}},
+		Logger:       testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+	engine.SetSkipFresh(true)
+
+	ctx := testContext()
+	require.NoError(t, engine.EnsureConnected(ctx))
+	require.NoError(t, engine.db.Exec(ctx, "CREATE TABLE raw_orders AS SELECT 1 AS id, TIMESTAMP '2024-01-01 06:00:00' AS loaded_at"))
+	require.NoError(t, engine.db.Exec(ctx, "CREATE TABLE raw_refunds AS SELECT 1 AS id"))
+
+	// run loads seeds, runs all models and returns the model runs by model
+	run := func() map[string]*core.ModelRunWithInfo {
+		t.Helper()
+		require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+		_, err := engine.Discover(DiscoveryOptions{})
+		require.NoError(t, err, "Discover() failed")
+		result, err := engine.Run(ctx, "test")
+		require.NoError(t, err, "Run() failed")
+
+		modelRuns, err := engine.store.GetModelRunsWithModelInfo(result.ID)
+		require.NoError(t, err)
+		byModel := make(map[string]*core.ModelRunWithInfo)
+		for _, mr := range modelRuns {
+			byModel[mr.ModelPath] = mr
+		}
+		return byModel
+	}
+
+	first := run()
+	assert.Equal(t, core.ModelRunStatusSuccess, first["order_events"].Status, "first run builds every model")
+	assert.Equal(t, core.ModelRunStatusSuccess, first["user_events"].Status)
+
+	// Incremental models whose sources are unchanged are skipped as fresh,
+	// unless they read raw tables whose changes are unknown
+	second := run()
+	assert.Equal(t, core.ModelRunStatusSkipped, second["order_events"].Status)
+	assert.Equal(t, core.SkipReasonFresh, second["order_events"].SkipReason)
+	assert.Contains(t, second["order_events"].Error, "fresh: sources unchanged since run")
+	assert.Equal(t, core.SkipReasonFresh, second["user_events"].SkipReason)
+	assert.Equal(t, core.ModelRunStatusSuccess, second["refund_events"].Status)
+	assert.Equal(t, core.SkipReasonCacheHit, second["active_users"].SkipReason)
+
+	// Rows loaded since the last build rebuild the models reading them
+	require.NoError(t, engine.db.Exec(ctx, "INSERT INTO raw_orders VALUES (2, TIMESTAMP '2024-01-02 06:00:00')"))
+	third := run()
+	assert.Equal(t, core.ModelRunStatusSuccess, third["order_events"].Status)
+	assert.Equal(t, core.SkipReasonFresh, third["user_events"].SkipReason)
+	assert.Equal(t, core.SkipReasonFresh, run()["order_events"].SkipReason)
+
+	// Without --skip-fresh incremental models always run
+	engine.SetSkipFresh(false)
+	assert.Equal(t, core.ModelRunStatusSuccess, run()["order_events"].Status)
+}
+
+func TestEngine_RunFullRefresh(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	models := map[string]string{
+		"user_events.sql":   "/*---\nmaterialized: incremental\n---*/\nSELECT id FROM users",
+		"user_archive.sql":  "/*---\nmaterialized: incremental\nfull_refresh: false\n---*/\nSELECT id FROM users",
+		"user_snapshot.sql": "/*---\nmaterialized: incremental\nfull_refresh: true\n---*/\nSELECT id FROM users",
+	}
+	for name, content := range models {
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	type result struct {
+		mode core.BuildMode
+		rows int64
+	}
+
+	// run runs all models and returns the build mode and row count of each
+	// incremental model
+	run := func() map[string]result {
+		t.Helper()
+		r, err := engine.Run(ctx, "test")
+		require.NoError(t, err, "Run() failed")
+
+		modelRuns, err := engine.store.GetModelRunsWithModelInfo(r.ID)
+		require.NoError(t, err)
+		results := make(map[string]result)
+		for _, mr := range modelRuns {
+			if mr.ModelPath == "active_users" {
+				continue
+			}
+			rows, err := engine.db.Query(ctx, "SELECT COUNT(*) FROM "+mr.ModelPath)
+			require.NoError(t, err)
+			var count int64
+			require.True(t, rows.Next())
+			require.NoError(t, rows.Scan(&count))
+			_ = rows.Close()
+			results[mr.ModelPath] = result{mode: mr.BuildMode, rows: count}
+		}
+		return results
+	}
+
+	assert.Equal(t, map[string]result{
+		"user_events":   {core.BuildModeFull, 2},
+		"user_archive":  {core.BuildModeFull, 2},
+		"user_snapshot": {core.BuildModeFullRefresh, 2},
+	}, run(), "first run")
+
+	assert.Equal(t, map[string]result{
+		"user_events":   {core.BuildModeIncremental, 4},
+		"user_archive":  {core.BuildModeIncremental, 4},
+		"user_snapshot": {core.BuildModeFullRefresh, 2},
+	}, run(), "incremental run")
+
+	engine.SetFullRefresh(true)
+	assert.Equal(t, map[string]result{
+		"user_events":   {core.BuildModeFullRefresh, 2},
+		"user_archive":  {core.BuildModeIncremental, 6},
+		"user_snapshot": {core.BuildModeFullRefresh, 2},
+	}, run(), "full refresh rebuilds incremental models, except protected ones")
+}
+
+func TestEngine_Backfill(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(seedsDir, "orders.csv"),
+		[]byte("id,order_date\n1,2024-01-01\n2,2024-01-01\n3,2024-01-02\n4,2024-01-03\n5,2024-01-05\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "order_events.sql"),
+		[]byte("/*---\nmaterialized: incremental\n---*/\nSELECT id, order_date FROM orders"), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	opts := BackfillOptions{Model: "order_events", Column: "order_date", Start: day(1), End: day(4)}
+	count := func() int64 {
+		t.Helper()
+		n, err := engine.countRows(ctx, "SELECT COUNT(*) FROM order_events")
+		require.NoError(t, err)
+		return n
+	}
+
+	// The first chunk creates the table
+	result, err := engine.Backfill(ctx, "test", opts)
+	require.NoError(t, err, "Backfill() failed")
+	assert.Equal(t, core.BackfillStatusCompleted, result.Backfill.Status)
+	assert.Equal(t, core.RunStatusCompleted, result.Run.Status)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 0, result.Resumed)
+	require.Len(t, result.Built, 3)
+	assert.Equal(t, []int64{2, 1, 1}, []int64{result.Built[0].RowsAffected, result.Built[1].RowsAffected, result.Built[2].RowsAffected})
+	assert.Equal(t, int64(4), count())
+
+	modelRuns, err := engine.store.GetModelRunsWithModelInfo(result.Run.ID)
+	require.NoError(t, err)
+	require.Len(t, modelRuns, 1)
+	assert.Equal(t, core.BuildModeBackfill, modelRuns[0].BuildMode)
+	assert.Equal(t, "order_date >= '2024-01-01' AND order_date < '2024-01-04'", modelRuns[0].RowFilter)
+
+	// Backfilling a range again replaces its rows
+	result, err = engine.Backfill(ctx, "test", BackfillOptions{Model: "order_events", Column: "order_date", Start: day(1), End: day(8), Chunk: core.BackfillChunkWeek})
+	require.NoError(t, err, "Backfill() failed")
+	assert.Equal(t, 1, result.Total)
+	assert.Equal(t, int64(5), count())
+
+	// An interrupted backfill resumes after its completed chunks
+	interrupted := &core.Backfill{ModelPath: "order_events", Column: "order_date", Start: day(1), End: day(4), Chunk: core.BackfillChunkDay}
+	require.NoError(t, engine.store.CreateBackfill(interrupted))
+	require.NoError(t, engine.store.RecordBackfillChunk(&core.BackfillChunk{BackfillID: interrupted.ID, Start: day(1), End: day(2), RunID: result.Run.ID}))
+	require.NoError(t, engine.store.CompleteBackfill(interrupted.ID, core.BackfillStatusFailed, "interrupted"))
+
+	result, err = engine.Backfill(ctx, "test", opts)
+	require.NoError(t, err, "Backfill() failed")
+	assert.Equal(t, interrupted.ID, result.Backfill.ID)
+	assert.Equal(t, core.BackfillStatusCompleted, result.Backfill.Status)
+	assert.Equal(t, 1, result.Resumed)
+	require.Len(t, result.Built, 2)
+	assert.Equal(t, day(2), result.Built[0].Start)
+	assert.Equal(t, int64(5), count())
+
+	// Only incremental models are backfilled
+	_, err = engine.Backfill(ctx, "test", BackfillOptions{Model: "active_users", Column: "id", Start: day(1), End: day(4)})
+	require.ErrorContains(t, err, "only incremental models")
+	_, err = engine.Backfill(ctx, "test", BackfillOptions{Model: "order_events", Column: "order_date", Start: day(4), End: day(1)})
+	require.ErrorContains(t, err, "range is empty")
+}
+
+func TestEngine_PartitionedIncremental(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(seedsDir, "orders.csv"),
+		[]byte("id,order_date\n1,2024-01-01\n2,2024-01-01\n3,2024-01-02\n4,2024-01-05\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "order_events.sql"),
+		[]byte("/*---\nmaterialized: incremental\npartition_by:\n  column: order_date\n---*/\nSELECT id, order_date FROM orders"), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	persisted, err := engine.store.GetModelByPath("order_events")
+	require.NoError(t, err)
+	assert.Equal(t, &core.PartitionSpec{Column: "order_date", Grain: core.PartitionGrainDay}, persisted.PartitionBy)
+
+	_, err = engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+
+	// Late rows for the latest partition and rows of a new one: the latest
+	// partition is overwritten rather than appended to
+	require.NoError(t, os.WriteFile(filepath.Join(seedsDir, "orders.csv"),
+		[]byte("id,order_date\n1,2024-01-01\n2,2024-01-01\n3,2024-01-02\n4,2024-01-05\n5,2024-01-05\n6,2024-01-06\n"), 0600))
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	run, err := engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+
+	modelRuns, err := engine.store.GetModelRunsWithModelInfo(run.ID)
+	require.NoError(t, err)
+	var modelRun *core.ModelRunWithInfo
+	for _, mr := range modelRuns {
+		if mr.ModelPath == "order_events" {
+			modelRun = mr
+		}
+	}
+	require.NotNil(t, modelRun)
+	assert.Equal(t, core.BuildModeIncremental, modelRun.BuildMode)
+	assert.Equal(t, int64(3), modelRun.RowsAffected)
+
+	n, err := engine.countRows(ctx, "SELECT COUNT(*) FROM order_events")
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), n)
+	n, err = engine.countRows(ctx, "SELECT COUNT(DISTINCT id) FROM order_events")
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), n)
+
+	// The partition column must be a column of the built table
+	m := *engine.models["order_events"]
+	m.PartitionBy = &core.PartitionSpec{Column: "shipped_at", Grain: core.PartitionGrainDay}
+	require.ErrorContains(t, engine.checkPartition(ctx, &m), "has no column shipped_at")
+}
+
+func TestEngine_Retention(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+
+	// An old row, a row before the cutoff in the partition the cutoff falls
+	// in, and a recent row
+	cutoff := core.RetentionPeriod{Count: 90, Unit: core.RetentionDay}.Cutoff(time.Now())
+	partitionStart := time.Date(cutoff.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, os.WriteFile(filepath.Join(seedsDir, "events.csv"), []byte(fmt.Sprintf(
+		"id,event_date\n1,2020-06-01\n2,%s\n3,%s\n", partitionStart.Format(time.DateOnly), time.Now().Format(time.DateOnly))), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "event_log.sql"),
+		[]byte("/*---\nmaterialized: incremental\npartition_by:\n  column: event_date\n  grain: year\nretention:\n  keep: 90d\n---*/\nSELECT id, event_date FROM events"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "recent_events.sql"),
+		[]byte("/*---\nretention:\n  column: event_date\n  keep: 90d\n---*/\nSELECT id, event_date FROM events"), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	_, err = engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+
+	// Partitioned by the retention column: whole partitions are deleted
+	n, err := engine.countRows(ctx, "SELECT COUNT(*) FROM event_log WHERE id IN (2, 3)")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	n, err = engine.countRows(ctx, "SELECT COUNT(*) FROM event_log")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	// Unpartitioned: every row before the cutoff is deleted
+	n, err = engine.countRows(ctx, "SELECT COUNT(*) FROM recent_events")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestEngine_RetentionCacheHit(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(seedsDir, "events.csv"), []byte(fmt.Sprintf(
+		"id,event_date\n1,2020-06-01\n2,%s\n", time.Now().Format(time.DateOnly))), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "recent_events.sql"),
+		[]byte("/*---\nretention:\n  column: event_date\n  keep: 90d\n---*/\nSELECT id, event_date FROM events"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "recent_event_ids.sql"),
+		[]byte("/*---\nmaterialized: table\n---*/\nSELECT id FROM recent_events"), 0600))
+
+	engine, err := New(Config{
+		ModelsDir:    modelsDir,
+		SeedsDir:     seedsDir,
+		StatePath:    filepath.Join(tmpDir, "state.db"),
+		DatabasePath: filepath.Join(tmpDir, "warehouse.duckdb"),
+		Target:       defaultTestTarget(),
+		Logger:       testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	_, err = engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+
+	// A row that aged past the retention period since the model's build
+	require.NoError(t, engine.db.Exec(ctx, "INSERT INTO recent_events VALUES (3, DATE '2021-01-01')"))
+
+	skipReasons := func() map[string]core.SkipReason {
+		t.Helper()
+		result, err := engine.Run(ctx, "test")
+		require.NoError(t, err, "Run() failed")
+		modelRuns, err := engine.store.GetModelRunsWithModelInfo(result.ID)
+		require.NoError(t, err)
+		reasons := make(map[string]core.SkipReason)
+		for _, mr := range modelRuns {
+			reasons[mr.ModelPath] = mr.SkipReason
+		}
+		return reasons
+	}
+	reasons := skipReasons()
+	assert.Equal(t, core.SkipReasonCacheHit, reasons["recent_events"], "recent_events is unchanged")
+	assert.Empty(t, reasons["recent_event_ids"], "the rows retention deleted rebuild the downstream model")
+
+	// Retention still deletes the expired rows of the unchanged model
+	for _, table := range []string{"recent_events", "recent_event_ids"} {
+		n, err := engine.countRows(ctx, "SELECT COUNT(*) FROM "+table)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n, table)
+	}
+
+	// Without rows to delete, both models are unchanged
+	reasons = skipReasons()
+	assert.Equal(t, core.SkipReasonCacheHit, reasons["recent_events"])
+	assert.Equal(t, core.SkipReasonCacheHit, reasons["recent_event_ids"])
+}
+
+// cancelObserver cancels the run context once a model has succeeded.
+type cancelObserver struct {
+	cancel context.CancelFunc
+}
+
+func (o *cancelObserver) OnRunStarted(*core.Run) {}
+
+func (o *cancelObserver) OnModelRunUpdated(_ string, modelRun *core.ModelRun) {
+	if modelRun.Status == core.ModelRunStatusSuccess {
+		o.cancel()
+	}
+}
+
+func (o *cancelObserver) OnRunCompleted(*core.Run) {}
+
+func TestEngine_RunCancelled(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_names.sql"),
+		[]byte("/*---\nmaterialized: table\n---*/\nSELECT name FROM active_users"), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx, cancel := context.WithCancel(testContext())
+	defer cancel()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	engine.SetRunObserver(&cancelObserver{cancel: cancel})
+
+	run, err := engine.Run(ctx, "test")
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotNil(t, run)
+
+	stored, err := engine.store.GetRun(run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, core.RunStatusCancelled, stored.Status)
+
+	modelRuns, err := engine.store.GetModelRunsWithModelInfo(run.ID)
+	require.NoError(t, err)
+	statuses := make(map[string]core.ModelRunStatus)
+	for _, mr := range modelRuns {
+		statuses[mr.ModelPath] = mr.Status
+	}
+	assert.Equal(t, map[string]core.ModelRunStatus{
+		"active_users": core.ModelRunStatusSuccess,
+		"user_names":   core.ModelRunStatusSkipped,
+	}, statuses)
+	for _, mr := range modelRuns {
+		if mr.ModelPath == "user_names" {
+			assert.Equal(t, core.SkipReasonCancelled, mr.SkipReason)
+		}
+	}
+}
+
+func TestEngine_RunSkipReasons(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	writeModel := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+	writeModel("active_users.sql", "SELECT id, no_such_column FROM users")
+	writeModel("user_emails.sql", "SELECT id, email FROM active_users")
+	writeModel("user_domains.sql", "SELECT DISTINCT split_part(email, '@', 2) AS domain FROM user_emails")
+	writeModel("user_names.sql", "SELECT id, name FROM users")
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	run, err := engine.Run(ctx, "test")
+	require.Error(t, err)
+	require.NotNil(t, run)
+
+	modelRuns, err := engine.store.GetModelRunsWithModelInfo(run.ID)
+	require.NoError(t, err)
+	byPath := make(map[string]*core.ModelRunWithInfo)
+	for _, mr := range modelRuns {
+		byPath[mr.ModelPath] = mr
+	}
+
+	require.Contains(t, byPath, "active_users")
+	assert.Equal(t, core.ModelRunStatusFailed, byPath["active_users"].Status)
+	assert.Empty(t, byPath["active_users"].SkipReason)
+
+	// Downstream models record the upstream model that failed
+	for _, path := range []string{"user_emails", "user_domains"} {
+		require.Contains(t, byPath, path)
+		assert.Equal(t, core.ModelRunStatusSkipped, byPath[path].Status)
+		assert.Equal(t, core.SkipReasonUpstreamFailed, byPath[path].SkipReason, path)
+		assert.Equal(t, "active_users", byPath[path].SkippedBy, path)
+		assert.Equal(t, "skipped: upstream model active_users failed", byPath[path].Error, path)
+	}
 
-	cfg := Config{
+	// An independent model is built, or skipped because the run stopped
+	require.Contains(t, byPath, "user_names")
+	if names := byPath["user_names"]; names.Status == core.ModelRunStatusSkipped {
+		assert.Equal(t, core.SkipReasonRunStopped, names.SkipReason)
+		assert.Equal(t, "active_users", names.SkippedBy)
+	} else {
+		assert.Equal(t, core.ModelRunStatusSuccess, names.Status)
+	}
+}
+
+func TestEngine_RunGroupMaxRuntime(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	writeModel := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+	writeModel("slow_totals.sql", "/*---\ngroup: finance\n---*/\nSELECT sum(a.range * b.range) AS total FROM range(1000000) a, range(1000000) b")
+	writeModel("slow_report.sql", "SELECT total FROM slow_totals")
+	writeModel("user_names.sql", "SELECT id, name FROM users")
+
+	engine, err := New(Config{
 		ModelsDir: modelsDir,
-		StatePath: statePath,
-		// No Target or AdapterConfig
-		Logger: testutil.NewTestLogger(t),
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Groups:    []core.GroupConfig{{Name: "finance", MaxRuntime: 200 * time.Millisecond}},
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	run, err := engine.Run(ctx, "test")
+	require.Error(t, err)
+	require.NotNil(t, run)
+	assert.ErrorContains(t, err, "model slow_totals timed out: group finance exceeded its max_runtime of 200ms")
+
+	run, err = engine.store.GetRun(run.ID)
+	require.NoError(t, err)
+	assert.Equal(t, core.RunStatusFailed, run.Status, "a group timeout fails the run rather than cancelling it")
+
+	modelRuns, err := engine.store.GetModelRunsWithModelInfo(run.ID)
+	require.NoError(t, err)
+	byPath := make(map[string]*core.ModelRunWithInfo)
+	for _, mr := range modelRuns {
+		byPath[mr.ModelPath] = mr
 	}
 
-	_, err := New(cfg)
-	require.Error(t, err, "New() should fail without target config")
-	assert.Contains(t, err.Error(), "target configuration required")
+	require.Contains(t, byPath, "slow_totals")
+	assert.Equal(t, core.ModelRunStatusFailed, byPath["slow_totals"].Status)
+	assert.Contains(t, byPath["slow_totals"].Error, "timed out: group finance exceeded its max_runtime of 200ms")
+
+	// Models downstream of the timed out group are skipped
+	require.Contains(t, byPath, "slow_report")
+	assert.Equal(t, core.ModelRunStatusSkipped, byPath["slow_report"].Status)
+	assert.Equal(t, core.SkipReasonGroupTimeout, byPath["slow_report"].SkipReason)
+	assert.Equal(t, "slow_totals", byPath["slow_report"].SkippedBy)
+
+	// Models outside the group are still built
+	require.Contains(t, byPath, "user_names")
+	assert.Equal(t, core.ModelRunStatusSuccess, byPath["user_names"].Status)
 }
 
-func TestNew_EmptyAdapterType(t *testing.T) {
-	tmpDir := t.TempDir()
-	statePath := filepath.Join(tmpDir, "state.db")
-	modelsDir := filepath.Join(tmpDir, "models")
-	require.NoError(t, os.MkdirAll(modelsDir, 0750))
+func TestEngine_CriticalPath(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	writeModel := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+	writeModel("active_users.sql", "SELECT id, name, email FROM users")
+	writeModel("user_emails.sql", "SELECT id, email FROM active_users")
+	writeModel("user_names.sql", "SELECT id, name FROM users")
 
-	cfg := Config{
+	engine, err := New(Config{
 		ModelsDir: modelsDir,
-		StatePath: statePath,
-		Target: &starctx.TargetInfo{
-			Type:   "", // Empty type
-			Schema: "main",
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	// Without history every model counts as instant
+	cp, err := engine.CriticalPath()
+	require.NoError(t, err)
+	assert.Zero(t, cp.TotalMS)
+	assert.Empty(t, cp.Bottleneck)
+
+	// Record the execution times of two runs
+	timings := []map[string]int64{
+		{"active_users": 100, "user_emails": 300, "user_names": 250},
+		{"active_users": 300, "user_emails": 500, "user_names": 250},
+	}
+	for _, times := range timings {
+		run, err := engine.Run(ctx, "test")
+		require.NoError(t, err, "Run() failed")
+		modelRuns, err := engine.store.GetModelRunsWithModelInfo(run.ID)
+		require.NoError(t, err)
+		for _, mr := range modelRuns {
+			require.NoError(t, engine.store.UpdateModelRun(mr.ID, mr.Status, mr.RowsAffected, "", mr.RenderMS, times[mr.ModelPath]))
+		}
+	}
+
+	expected, builds, err := engine.ExpectedRuntimes()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"active_users": 200, "user_emails": 400, "user_names": 250}, expected)
+	assert.Equal(t, 2, builds["user_emails"])
+
+	cp, err = engine.CriticalPath()
+	require.NoError(t, err)
+	var models []string
+	for _, step := range cp.Steps {
+		models = append(models, step.Model)
+	}
+	assert.Equal(t, []string{"active_users", "user_emails"}, models)
+	assert.Equal(t, int64(600), cp.TotalMS)
+	assert.Equal(t, "user_emails", cp.Bottleneck)
+	assert.Equal(t, CriticalPathStep{Model: "user_emails", ExpectedMS: 400, Builds: 2}, cp.Steps[1])
+}
+
+func TestEngine_ScheduleOrder(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	writeModel := func(name, content string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(content), 0600))
+	}
+	writeModel("active_users.sql", "SELECT id, name, email FROM users")
+	writeModel("user_emails.sql", "SELECT id, email FROM active_users")
+	writeModel("user_names.sql", "SELECT id, name FROM users")
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	order := func() []string {
+		t.Helper()
+		nodes, err := engine.scheduleOrder(engine.graph)
+		require.NoError(t, err)
+		var paths []string
+		for _, n := range nodes {
+			if _, ok := engine.models[n.ID]; ok {
+				paths = append(paths, n.ID)
+			}
+		}
+		return paths
+	}
+
+	// Without history models are built in topological order
+	assert.Equal(t, []string{"active_users", "user_emails", "user_names"}, order())
+
+	// With history the slowest chain starts first
+	run, err := engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+	times := map[string]int64{"active_users": 10, "user_emails": 20, "user_names": 1000}
+	modelRuns, err := engine.store.GetModelRunsWithModelInfo(run.ID)
+	require.NoError(t, err)
+	for _, mr := range modelRuns {
+		require.NoError(t, engine.store.UpdateModelRun(mr.ID, mr.Status, mr.RowsAffected, "", mr.RenderMS, times[mr.ModelPath]))
+	}
+
+	assert.Equal(t, []string{"user_names", "active_users", "user_emails"}, order())
+}
+
+func TestEngine_FileSources(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	filesDir := filepath.Join(tmpDir, "files")
+	require.NoError(t, os.MkdirAll(filesDir, 0750))
+	writeFile := func(name string, age time.Duration) string {
+		t.Helper()
+		path := filepath.Join(filesDir, name)
+		require.NoError(t, os.WriteFile(path, []byte("id,amount\n1,10\n2,20\n"), 0600))
+		modified := time.Now().Add(-age)
+		require.NoError(t, os.Chtimes(path, modified, modified))
+		return filepath.ToSlash(path)
+	}
+	orders := writeFile("orders.csv", time.Minute)
+	refunds := writeFile("refunds.csv", 3*time.Hour)
+	missing := filepath.ToSlash(filepath.Join(filesDir, "missing.csv"))
+
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "orders.sql"),
+		[]byte(fmt.Sprintf("SELECT id, amount FROM read_csv('%s')", orders)), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "refunds.sql"),
+		[]byte(fmt.Sprintf("SELECT r.id, r.amount FROM read_csv('%s') AS r JOIN orders o ON o.id = r.id", refunds)), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		FileSources: []core.FileSourceConfig{
+			{Path: orders, WarnAfter: time.Hour},
+			{Path: refunds, WarnAfter: time.Hour, ErrorAfter: 2 * time.Hour},
+			{Path: missing},
 		},
 		Logger: testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	result, err := engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	assert.NotContains(t, result.SeedsMissing, orders, "files are not seeds")
+
+	// Files are sources of the models that read them; models still depend on models
+	assert.Equal(t, []string{orders}, engine.models["orders"].Files)
+	assert.Equal(t, []string{"orders"}, engine.graph.GetParents("refunds"))
+
+	sources := engine.FileSources()
+	require.Len(t, sources, 3)
+	assert.Equal(t, missing, sources[0].Path)
+	assert.Empty(t, sources[0].Models)
+	assert.Equal(t, orders, sources[1].Path)
+	assert.Equal(t, []string{"orders"}, sources[1].Models)
+
+	// Models reading files build
+	_, err = engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+
+	checks, err := engine.CheckFileFreshness(ctx)
+	require.NoError(t, err)
+	status := make(map[string]FileFreshness)
+	for _, c := range checks {
+		status[c.Path] = c
 	}
 
-	_, err := New(cfg)
-	require.Error(t, err, "New() should fail with empty adapter type")
-	assert.Contains(t, err.Error(), "adapter type required")
+	assert.Equal(t, FreshnessPass, status[orders].Status)
+	assert.Equal(t, 1, status[orders].Files)
+	assert.Less(t, status[orders].Age, time.Hour)
+
+	assert.Equal(t, FreshnessError, status[refunds].Status)
+	assert.Equal(t, "older than 2h0m0s", status[refunds].Reason)
+
+	assert.Equal(t, FreshnessError, status[missing].Status)
+	assert.Equal(t, "no files match", status[missing].Reason)
 }
 
-func TestNew_UnknownAdapterType(t *testing.T) {
-	tmpDir := t.TempDir()
-	statePath := filepath.Join(tmpDir, "state.db")
-	modelsDir := filepath.Join(tmpDir, "models")
-	require.NoError(t, os.MkdirAll(modelsDir, 0750))
+func TestEngine_AttachedDatabase(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	lake := filepath.Join(tmpDir, "lake.duckdb")
 
-	cfg := Config{
+	stagingDir := filepath.Join(modelsDir, "staging")
+	require.NoError(t, os.MkdirAll(stagingDir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(stagingDir, "stg_users.sql"), []byte(`/*---
+database: lake
+---*/
+SELECT id, name FROM users
+`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_names.sql"),
+		[]byte("SELECT u.name FROM {{ ref('stg_users') }} u JOIN lake.staging.stg_users s ON s.id = u.id"), 0600))
+
+	engine, err := New(Config{
 		ModelsDir: modelsDir,
-		StatePath: statePath,
-		Target: &starctx.TargetInfo{
-			Type:   "unknown_db",
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		AdapterConfig: &core.AdapterConfig{
+			Type:   "duckdb",
 			Schema: "main",
+			Params: map[string]any{"attach": []any{map[string]any{"path": lake}}},
 		},
 		Logger: testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	// Refs and catalog-qualified references resolve to the model in the attached database
+	assert.Equal(t, "lake", engine.models["staging.stg_users"].Database)
+	assert.Equal(t, []string{"staging.stg_users"}, engine.graph.GetParents("user_names"))
+	sql, err := engine.RenderModel("user_names")
+	require.NoError(t, err)
+	assert.Contains(t, sql, "FROM lake.staging.stg_users u")
+
+	_, err = engine.Run(ctx, "test")
+	require.NoError(t, err, "Run() failed")
+
+	// The model is built in the attached database, not the target database
+	_, err = engine.db.GetTableMetadata(ctx, "lake.staging.stg_users")
+	require.NoError(t, err)
+	_, err = engine.db.GetTableMetadata(ctx, "staging.stg_users")
+	assert.Error(t, err)
+	meta, err := engine.db.GetTableMetadata(ctx, "user_names")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), meta.RowCount)
+}
+
+func TestEngine_Query(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	statePath := filepath.Join(tmpDir, "state.db")
+
+	engine, err := New(Config{
+		ModelsDir:    modelsDir,
+		SeedsDir:     seedsDir,
+		DatabasePath: filepath.Join(tmpDir, "dev.duckdb"),
+		StatePath:    statePath,
+		Target:       defaultTestTarget(),
+		Logger:       testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+
+	query := "SELECT id, name FROM users ORDER BY id LIMIT 2;"
+	result, err := engine.Query(ctx, query, QueryOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name"}, result.Columns)
+	assert.Len(t, result.Rows, 2)
+	assert.True(t, result.CachedAt.IsZero())
+	assert.NoDirExists(t, filepath.Join(tmpDir, queryCacheDir), "uncached queries are not written to the cache")
+
+	// The first cached query is read from the target, the next one from the cache
+	opts := QueryOptions{CacheTTL: time.Hour}
+	result, err = engine.Query(ctx, query, opts)
+	require.NoError(t, err)
+	assert.True(t, result.CachedAt.IsZero())
+
+	require.NoError(t, engine.GetAdapter().Exec(ctx, "DELETE FROM users"))
+	cached, err := engine.Query(ctx, query, opts)
+	require.NoError(t, err)
+	assert.False(t, cached.CachedAt.IsZero())
+	assert.Equal(t, result.Columns, cached.Columns)
+	require.Len(t, cached.Rows, 2)
+	assert.Equal(t, json.Number(fmt.Sprint(result.Rows[0][0])), cached.Rows[0][0])
+	assert.Equal(t, result.Rows[0][1], cached.Rows[0][1])
+
+	// Expired results are queried again
+	time.Sleep(10 * time.Millisecond)
+	result, err = engine.Query(ctx, query, QueryOptions{CacheTTL: time.Millisecond})
+	require.NoError(t, err)
+	assert.True(t, result.CachedAt.IsZero())
+	assert.Empty(t, result.Rows)
+}
+
+func TestEngine_PreviewModel(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(modelsDir, "staging"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "staging", "stg_users.sql"),
+		[]byte("SELECT id, name FROM users ORDER BY id;"), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, engine.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	result, err := engine.PreviewModel(ctx, "staging.stg_users", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name"}, result.Columns)
+	assert.Len(t, result.Rows, 1)
+	assert.True(t, result.Truncated)
+
+	result, err = engine.PreviewModel(ctx, "staging.stg_users", MaxPreviewRows+1)
+	require.NoError(t, err)
+	assert.Len(t, result.Rows, 2)
+	assert.False(t, result.Truncated)
+
+	// Cancelled previews fail
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = engine.PreviewModel(cancelled, "staging.stg_users", 1)
+	assert.Error(t, err)
+
+	// Previewing builds nothing
+	_, err = engine.Query(ctx, "SELECT * FROM staging.stg_users", QueryOptions{})
+	assert.Error(t, err)
+
+	_, err = engine.PreviewModel(ctx, "staging.missing", 1)
+	assert.Error(t, err)
+}
+
+func TestEngine_RenderSQL(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.MkdirAll(filepath.Join(modelsDir, "staging"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "staging", "stg_users.sql"),
+		[]byte("SELECT id, name FROM users"), 0600))
+
+	engine, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = engine.Close() }()
+
+	_, err = engine.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	sql, err := engine.RenderSQL("SELECT count(*) FROM {{ ref('stg_users') }}")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT count(*) FROM staging.stg_users", sql)
+
+	_, err = engine.RenderSQL("SELECT * FROM {{ ref('missing') }}")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "missing")
+}
+
+func TestEngine_PlanColumnPrunes(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	eng, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = eng.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, eng.LoadSeeds(ctx), "LoadSeeds() failed")
+	_, err = eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	_, err = eng.Run(ctx, "dev")
+	require.NoError(t, err, "Run() failed")
+
+	prunes, err := eng.PlanColumnPrunes(ctx, map[string][]string{
+		"active_users": {"email", "missing"},
+		"unknown":      {"id"},
+	})
+	require.NoError(t, err)
+	require.Len(t, prunes, 1)
+
+	p := prunes[0]
+	assert.Equal(t, "active_users", p.Model)
+	assert.Equal(t, []string{"email"}, p.Columns)
+	assert.Equal(t, []string{"missing"}, p.Kept)
+	assert.Equal(t, 3, p.TotalColumns)
+	assert.Equal(t, int64(2), p.Savings.Values, "2 rows of one column")
+
+	pruned, _ := lint.ApplyFixes(p.SQL, []lint.Diagnostic{{Fixes: []lint.Fix{p.Fix}}}, true)
+	assert.Contains(t, pruned, "SELECT id, name FROM users")
+}
+
+func TestEngine_PlanColumnRename(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	for name, sql := range map[string]string{
+		"user_emails.sql":  "SELECT au.id, au.email FROM active_users au WHERE au.email IS NOT NULL",
+		"email_counts.sql": "SELECT email, COUNT(*) AS n FROM active_users GROUP BY email",
+		"user_ids.sql":     "SELECT id FROM active_users",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, name), []byte(sql), 0600))
 	}
+	eng, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = eng.Close() }()
+	_, err = eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
 
-	_, err := New(cfg)
-	require.Error(t, err, "New() should fail with unknown adapter type")
-	assert.Contains(t, err.Error(), "unknown adapter type")
-	assert.Contains(t, err.Error(), "unknown_db")
+	rename, err := eng.PlanColumnRename("active_users", "email", "email_address")
+	require.NoError(t, err)
+
+	patched := make(map[string]string)
+	var models []string
+	for _, p := range rename.Patches {
+		models = append(models, p.Model)
+		patched[p.Model], _ = lint.ApplyFixes(p.SQL, []lint.Diagnostic{{Fixes: []lint.Fix{p.Fix}}}, true)
+	}
+	assert.Equal(t, []string{"active_users", "email_counts", "user_emails"}, models, "models not using the column are not patched")
+	assert.Contains(t, patched["active_users"], "SELECT id, name, email AS email_address FROM users")
+	assert.Equal(t, "SELECT email_address AS email, COUNT(*) AS n FROM active_users GROUP BY email_address", patched["email_counts"])
+	assert.Equal(t, "SELECT au.id, au.email_address AS email FROM active_users au WHERE au.email_address IS NOT NULL", patched["user_emails"])
+
+	_, err = eng.PlanColumnRename("active_users", "email", "name")
+	assert.ErrorContains(t, err, "already has a column name")
+	_, err = eng.PlanColumnRename("active_users", "missing", "other")
+	assert.ErrorContains(t, err, "has no column missing")
+	_, err = eng.PlanColumnRename("active_users", "email", "e-mail")
+	assert.ErrorContains(t, err, "invalid column name")
+}
+
+func TestEngine_PlanExtract(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	modelFile := filepath.Join(modelsDir, "email_ids.sql")
+	sql := "WITH emails AS (\n    SELECT id, email\n    FROM active_users\n    WHERE email IS NOT NULL\n)\nSELECT e.id FROM (SELECT id FROM emails) e\n"
+	require.NoError(t, os.WriteFile(modelFile, []byte(sql), 0600))
+	eng, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = eng.Close() }()
+	_, err = eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	ext, err := eng.PlanExtractCTE("email_ids", "e", "ids")
+	require.NoError(t, err)
+	patched, _ := lint.ApplyFixes(ext.SQL, []lint.Diagnostic{{Fixes: []lint.Fix{ext.Fix}}}, true)
+	assert.Contains(t, patched, "),\nids AS (\n    SELECT id FROM emails\n)\nSELECT e.id FROM ids AS e")
+	_, err = eng.PlanExtractCTE("email_ids", "missing", "ids")
+	assert.ErrorContains(t, err, "no subquery aliased missing (subqueries: e)")
+	_, err = eng.PlanExtractCTE("email_ids", "e", "emails")
+	assert.ErrorContains(t, err, "emails already names a table or CTE")
+
+	ext, err = eng.PlanExtractModel("email_ids", "emails", "user_emails")
+	require.NoError(t, err)
+	assert.Equal(t, "user_emails", ext.NewModel)
+	assert.Equal(t, filepath.Join(modelsDir, "user_emails.sql"), ext.NewFilePath)
+	assert.Contains(t, ext.NewSQL, "active_users")
+	patched, _ = lint.ApplyFixes(ext.SQL, []lint.Diagnostic{{Fixes: []lint.Fix{ext.Fix}}}, true)
+	assert.Equal(t, "SELECT e.id FROM (SELECT id FROM {{ ref('user_emails') }} AS emails) e", patched)
+	_, err = eng.PlanExtractModel("email_ids", "emails", "active_users")
+	assert.ErrorContains(t, err, "already named active_users")
+
+	// The patched model reads the new one
+	require.NoError(t, os.WriteFile(modelFile, []byte(patched), 0600))
+	require.NoError(t, os.WriteFile(ext.NewFilePath, []byte(ext.NewSQL), 0600))
+	_, err = eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+	assert.Contains(t, eng.graph.GetParents("email_ids"), "user_emails")
+	assert.Contains(t, eng.graph.GetParents("user_emails"), "active_users")
+}
+
+func TestEngine_OutputSchema(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_status.sql"), []byte(`/*---
+name: user_status
+description: Status of each user
+materialized: table
+tests:
+  - not_null: [id]
+  - accepted_values:
+      column: status
+      values: [active, churned]
+---*/
+
+SELECT id, 'active' AS status FROM active_users
+`), 0600))
+
+	eng, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = eng.Close() }()
+
+	ctx := testContext()
+	_, err = eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	_, err = eng.OutputSchema(ctx, "unknown")
+	require.Error(t, err)
+
+	t.Run("not built", func(t *testing.T) {
+		s, err := eng.OutputSchema(ctx, "user_status")
+		require.NoError(t, err)
+		assert.True(t, s.Inferred)
+		assert.Equal(t, []ColumnSchema{
+			{Name: "id"},
+			{Name: "status", Nullable: true, Values: []string{"active", "churned"}},
+		}, s.Columns)
+	})
+
+	t.Run("built", func(t *testing.T) {
+		require.NoError(t, eng.LoadSeeds(ctx), "LoadSeeds() failed")
+		_, err = eng.Run(ctx, "dev")
+		require.NoError(t, err, "Run() failed")
+
+		s, err := eng.OutputSchema(ctx, "user_status")
+		require.NoError(t, err)
+		assert.False(t, s.Inferred)
+		assert.Equal(t, "Status of each user", s.Description)
+		require.Len(t, s.Columns, 2)
+		assert.Equal(t, ColumnSchema{Name: "id", Type: "BIGINT"}, s.Columns[0])
+		assert.Equal(t, "VARCHAR", s.Columns[1].Type)
+		assert.True(t, s.Columns[1].Nullable)
+	})
+}
+
+func TestOutputSchema_Encode(t *testing.T) {
+	s := &OutputSchema{
+		Model: "marts.orders",
+		Name:  "orders",
+		Columns: []ColumnSchema{
+			{Name: "id", Type: "BIGINT"},
+			{Name: "amount", Type: "DECIMAL(10,2)", Nullable: true},
+			{Name: "ordered_at", Type: "TIMESTAMP WITH TIME ZONE", Nullable: true},
+			{Name: "order-status", Type: "VARCHAR", Values: []string{"open", "shipped"}},
+			{Name: "tags", Type: "VARCHAR[]", Nullable: true},
+			{Name: "notes", Nullable: true},
+		},
+	}
+
+	t.Run("json schema", func(t *testing.T) {
+		doc, err := s.Encode(SchemaFormatJSON)
+		require.NoError(t, err)
+		assert.Equal(t, "marts.orders", doc["title"])
+		assert.Equal(t, []string{"id", "order-status"}, doc["required"])
+		assert.Equal(t, map[string]any{
+			"id":           map[string]any{"type": "integer"},
+			"amount":       map[string]any{"type": []string{"number", "null"}},
+			"ordered_at":   map[string]any{"type": []string{"string", "null"}, "format": "date-time"},
+			"order-status": map[string]any{"type": "string", "enum": []any{"open", "shipped"}},
+			"tags":         map[string]any{"type": []string{"array", "null"}, "items": map[string]any{"type": "string"}},
+			"notes":        map[string]any{},
+		}, doc["properties"])
+	})
+
+	t.Run("avro", func(t *testing.T) {
+		doc, err := s.Encode(SchemaFormatAvro)
+		require.NoError(t, err)
+		assert.Equal(t, "record", doc["type"])
+		assert.Equal(t, "orders", doc["name"])
+		assert.Equal(t, "marts", doc["namespace"])
+		assert.Equal(t, []any{
+			map[string]any{"name": "id", "type": "long"},
+			map[string]any{"name": "amount", "type": []any{"null", map[string]any{"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}}, "default": nil},
+			map[string]any{"name": "ordered_at", "type": []any{"null", map[string]any{"type": "long", "logicalType": "timestamp-micros"}}, "default": nil},
+			map[string]any{"name": "order_status", "type": "string", "aliases": []string{"order-status"}, "doc": "One of: open, shipped"},
+			map[string]any{"name": "tags", "type": []any{"null", map[string]any{"type": "array", "items": "string"}}, "default": nil},
+			map[string]any{"name": "notes", "type": []any{"null", "string"}, "default": nil},
+		}, doc["fields"])
+	})
+
+	_, err := s.Encode("protobuf")
+	assert.Error(t, err)
+}
+
+func TestEngine_CatalogDatasets(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_emails.sql"), []byte(`/*---
+name: user_emails
+description: Email of each active user
+materialized: view
+group: crm
+tags: [pii]
+---*/
+
+SELECT id, max(email) AS email FROM active_users GROUP BY id
+`), 0600))
+
+	eng, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Groups:    []core.GroupConfig{{Name: "crm", Owner: "growth"}},
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err, "New() failed")
+	defer func() { _ = eng.Close() }()
+
+	ctx := testContext()
+	_, err = eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err, "Discover() failed")
+
+	_, err = eng.CatalogDatasets(ctx, []string{"unknown"})
+	require.Error(t, err)
+
+	datasets, err := eng.CatalogDatasets(ctx, []string{"user_emails", "active_users"})
+	require.NoError(t, err)
+	require.Len(t, datasets, 2)
+
+	users := datasets[0]
+	assert.Equal(t, "active_users", users.Model)
+	assert.Equal(t, []string{"users"}, users.Upstreams)
+	assert.Empty(t, users.Owner)
+
+	emails := datasets[1]
+	assert.Equal(t, "user_emails", emails.Name)
+	assert.Equal(t, "Email of each active user", emails.Description)
+	assert.Equal(t, "view", emails.Materialized)
+	assert.Equal(t, "growth", emails.Owner)
+	assert.Equal(t, []string{"pii"}, emails.Tags)
+	assert.Equal(t, []string{"active_users"}, emails.Upstreams)
+	assert.Equal(t, []metadata.Field{{Name: "id", Nullable: true}, {Name: "email", Nullable: true}}, emails.Fields)
+	assert.Equal(t, []metadata.ColumnLineage{
+		{Column: "id", Sources: []metadata.ColumnRef{{Table: "active_users", Column: "id"}}},
+		{Column: "email", Sources: []metadata.ColumnRef{{Table: "active_users", Column: "email"}}, Transform: "MAX"},
+	}, emails.ColumnLineage)
+}
+
+func TestLintModels_UpstreamColumns(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, macrosDir := createTestProject(t)
+	stagingDir := filepath.Join(modelsDir, "staging")
+	require.NoError(t, os.MkdirAll(stagingDir, 0750))
+
+	files := map[string]string{
+		filepath.Join(stagingDir, "customers.sql"): "SELECT id, name FROM raw_customers\n",
+		filepath.Join(stagingDir, "orders.sql"):    "SELECT id, customer_id, amount FROM raw_orders\n",
+		filepath.Join(modelsDir, "customer_orders.sql"): `SELECT id, name, amount
+FROM staging.customers c
+JOIN staging.orders o ON c.id = o.customer_id
+`,
+	}
+	for path, content := range files {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	eng, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		MacrosDir: macrosDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err)
+	defer func() { _ = eng.Close() }()
+
+	_, err = eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err)
+
+	model, ok := eng.GetModels()["customer_orders"]
+	require.True(t, ok)
+
+	cfg := lint.NewConfig()
+	results, err := eng.LintModels(testContext(), []*core.Model{model}, cfg)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	var am06 []string
+	for _, d := range results[0].Diagnostics {
+		if d.RuleID == "AM06" {
+			am06 = append(am06, d.Message)
+		}
+	}
+	// Only id exists in both upstream models
+	assert.Equal(t, []string{"Column 'id' is ambiguous: it exists in 'c' and 'o'"}, am06)
+}
+
+func TestLintModels_SecondaryDialects(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, macrosDir := createTestProject(t)
+
+	files := map[string]string{
+		filepath.Join(modelsDir, "portable.sql"): "SELECT id, name FROM raw_customers\n",
+		filepath.Join(modelsDir, "all_people.sql"): `SELECT id, name FROM raw_customers
+UNION ALL BY NAME
+SELECT name, id FROM raw_employees
+`,
+	}
+	for path, content := range files {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	eng, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		MacrosDir: macrosDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err)
+	defer func() { _ = eng.Close() }()
+
+	_, err = eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err)
+
+	models := []*core.Model{eng.GetModels()["portable"], eng.GetModels()["all_people"]}
+
+	cfg := lint.NewConfig()
+	require.NoError(t, cfg.ApplyProject(&core.LintConfig{Dialects: []string{"duckdb", "Snowflake"}}))
+	results, err := eng.LintModels(testContext(), models, cfg)
+	require.NoError(t, err)
+
+	var port []string
+	for _, res := range results {
+		for _, d := range res.Diagnostics {
+			if d.RuleID == PortabilityRuleID {
+				assert.Equal(t, "all_people", res.Model)
+				assert.Equal(t, core.SeverityWarning, d.Severity)
+				port = append(port, d.Message)
+			}
+		}
+	}
+	assert.Equal(t, []string{"Not supported by snowflake: UNION ALL BY NAME"}, port)
+
+	// Disabling the rule skips the check
+	cfg.Disable(PortabilityRuleID)
+	results, err = eng.LintModels(testContext(), models, cfg)
+	require.NoError(t, err)
+	for _, res := range results {
+		for _, d := range res.Diagnostics {
+			assert.NotEqual(t, PortabilityRuleID, d.RuleID)
+		}
+	}
+
+	cfg = lint.NewConfig()
+	cfg.Dialects = []string{"nope"}
+	_, err = eng.LintModels(testContext(), models, cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `lint.dialects: unknown dialect "nope"`)
+}
+
+func TestMeasureModels(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, macrosDir := createTestProject(t)
+
+	files := map[string]string{
+		filepath.Join(modelsDir, "orders.sql"): "SELECT id, customer_id FROM raw_orders\n",
+		filepath.Join(modelsDir, "customer_orders.sql"): `WITH recent AS (SELECT * FROM {{ ref('orders') }})
+SELECT c.id, COUNT(*) AS orders
+FROM raw_customers c
+JOIN recent r ON c.id = r.customer_id
+GROUP BY c.id
+`,
+		filepath.Join(modelsDir, "broken.sql"): "SELECT FROM WHERE\n",
+	}
+	for path, content := range files {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	eng, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		MacrosDir: macrosDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err)
+	defer func() { _ = eng.Close() }()
+
+	_, _ = eng.Discover(DiscoveryOptions{})
+
+	var models []*core.Model
+	for _, path := range []string{"orders", "customer_orders", "broken"} {
+		m, ok := eng.GetModels()[path]
+		require.True(t, ok, "model %s should be discovered", path)
+		models = append(models, m)
+	}
+
+	stats, err := eng.MeasureModels(testContext(), models)
+	require.NoError(t, err)
+	require.Len(t, stats, 3)
+
+	assert.Equal(t, "broken", stats[0].Model)
+	assert.Error(t, stats[0].Err)
+
+	assert.Equal(t, "customer_orders", stats[1].Model)
+	require.NoError(t, stats[1].Err)
+	assert.Equal(t, 1, stats[1].Metrics.Joins)
+	assert.Equal(t, 1, stats[1].Metrics.CTEs)
+	assert.Equal(t, 2, stats[1].Metrics.NestingDepth)
+	assert.Equal(t, []string{"orders", "raw_customers"}, stats[1].Metrics.Tables)
+
+	assert.Equal(t, "orders", stats[2].Model)
+	assert.Equal(t, []string{"customer_id", "id"}, stats[2].Metrics.Columns)
+}
+
+func TestLintModels_TemplatePositions(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, macrosDir := createTestProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(macrosDir, "utils.star"), []byte("def nulls():\n    return \"(1, NULL)\"\n"), 0600))
+	content := "/*---\nmaterialized: table\n---*/\n\nSELECT name\nFROM users\nWHERE id NOT IN {{ utils.nulls() }}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "filtered.sql"), []byte(content), 0600))
+
+	eng, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		MacrosDir: macrosDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err)
+	defer func() { _ = eng.Close() }()
+
+	_, err = eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err)
+
+	results, err := eng.LintModels(testContext(), []*core.Model{eng.GetModels()["filtered"]}, lint.NewConfig())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "SELECT name\nFROM users\nWHERE id NOT IN (1, NULL)", results[0].SQL)
+	assert.Equal(t, content, results[0].Source)
+
+	// The NOT IN found in the rendered SQL covers the macro call of the file
+	var am12 []lint.Diagnostic
+	for _, d := range results[0].Diagnostics {
+		if d.RuleID == "AM12" {
+			am12 = append(am12, d)
+		}
+	}
+	require.Len(t, am12, 1)
+	assert.Equal(t, token.Position{Line: 7, Column: 7, Offset: strings.Index(content, "id NOT IN")}, am12[0].Pos)
+	assert.Equal(t, "id NOT IN {{ utils.nulls() }}", content[am12[0].Pos.Offset:am12[0].EndPos.Offset])
+	assert.Equal(t, 36, am12[0].EndPos.Column)
+}
+
+func TestEngine_CustomSchemas(t *testing.T) {
+	newEngine := func(t *testing.T, hook string) *Engine {
+		t.Helper()
+		tmpDir, modelsDir, seedsDir, macrosDir := createTestProject(t)
+		stagingDir := filepath.Join(modelsDir, "staging")
+		require.NoError(t, os.MkdirAll(stagingDir, 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(stagingDir, "stg_users.sql"), []byte("SELECT id, name FROM users\n"), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(stagingDir, "stg_emails.sql"), []byte(`/*---
+schema: analytics
+---*/
+SELECT id, email FROM users
+`), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_emails.sql"),
+			[]byte("SELECT u.name, e.email FROM {{ ref('stg_users') }} u JOIN {{ ref('stg_emails') }} e ON e.id = u.id"), 0600))
+		if hook != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(macrosDir, "naming.star"), []byte(hook), 0600))
+		}
+
+		eng, err := New(Config{
+			ModelsDir:   modelsDir,
+			SeedsDir:    seedsDir,
+			MacrosDir:   macrosDir,
+			StatePath:   filepath.Join(tmpDir, "state.db"),
+			Environment: "dev",
+			Target:      defaultTestTarget(),
+			Logger:      testutil.NewTestLogger(t),
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = eng.Close() })
+
+		require.NoError(t, eng.LoadSeeds(testContext()))
+		result, err := eng.Discover(DiscoveryOptions{})
+		require.NoError(t, err)
+		require.False(t, result.HasErrors(), "discovery errors: %v", result.Errors)
+		return eng
+	}
+
+	t.Run("frontmatter schema", func(t *testing.T) {
+		eng := newEngine(t, "")
+
+		assert.Equal(t, "staging.stg_users", eng.Relation("staging.stg_users"))
+		assert.Equal(t, "analytics.stg_emails", eng.Relation("staging.stg_emails"))
+		sql, err := eng.RenderModel("user_emails")
+		require.NoError(t, err)
+		assert.Contains(t, sql, "JOIN analytics.stg_emails e")
+
+		_, err = eng.Run(testContext(), "test")
+		require.NoError(t, err)
+		_, err = eng.db.GetTableMetadata(testContext(), "analytics.stg_emails")
+		require.NoError(t, err)
+		meta, err := eng.db.GetTableMetadata(testContext(), "user_emails")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), meta.RowCount)
+	})
+
+	t.Run("generate_schema_name", func(t *testing.T) {
+		eng := newEngine(t, `
+def generate_schema_name(custom_schema, target, env):
+    if env == "prod" or custom_schema == None:
+        return custom_schema
+    return "dev_" + custom_schema
+`)
+
+		assert.Equal(t, "dev_staging.stg_users", eng.Relation("staging.stg_users"))
+		assert.Equal(t, "dev_analytics.stg_emails", eng.Relation("staging.stg_emails"))
+		assert.Equal(t, "user_emails", eng.Relation("user_emails"))
+		assert.Equal(t, "dev_staging", eng.getModelSchema(eng.models["staging.stg_users"]))
+		sql, err := eng.RenderModel("user_emails")
+		require.NoError(t, err)
+		assert.Contains(t, sql, "FROM dev_staging.stg_users u JOIN dev_analytics.stg_emails e")
+
+		_, err = eng.Run(testContext(), "test")
+		require.NoError(t, err)
+		_, err = eng.db.GetTableMetadata(testContext(), "dev_staging.stg_users")
+		require.NoError(t, err)
+		_, err = eng.db.GetTableMetadata(testContext(), "staging.stg_users")
+		assert.Error(t, err)
+	})
+}
+
+func TestEngine_Audit(t *testing.T) {
+	tmpDir, modelsDir, seedsDir, _ := createTestProject(t)
+	stagingDir := filepath.Join(modelsDir, "staging")
+	require.NoError(t, os.MkdirAll(stagingDir, 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(stagingDir, "stg_users.sql"), []byte("SELECT id, name FROM users\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "user_names.sql"), []byte(`/*---
+materialized: view
+---*/
+SELECT name FROM {{ ref('stg_users') }}
+`), 0600))
+
+	eng, err := New(Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    defaultTestTarget(),
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err)
+	defer func() { _ = eng.Close() }()
+
+	ctx := testContext()
+	require.NoError(t, eng.LoadSeeds(ctx))
+	_, err = eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err)
+	_, err = eng.Run(ctx, "test")
+	require.NoError(t, err)
+
+	result, err := eng.Audit(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, result.Issues(), "a fresh build has no issues: %+v", result)
+	assert.Empty(t, result.Unchecked)
+
+	// A renamed model leaves its table behind, a new model is not built yet,
+	// a model's SQL changes a column's type and a column is added to a table
+	// outside LeapSQL
+	require.NoError(t, eng.db.Exec(ctx, "CREATE TABLE staging.stg_people AS SELECT 1 AS id"))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "active_users.sql"),
+		[]byte("SELECT CAST(id AS VARCHAR) AS id, name, email FROM users\n"), 0600))
+	require.NoError(t, eng.db.Exec(ctx, "ALTER TABLE staging.stg_users ADD COLUMN age INTEGER"))
+	require.NoError(t, os.WriteFile(filepath.Join(modelsDir, "new_users.sql"), []byte("SELECT id FROM users\n"), 0600))
+	_, err = eng.Discover(DiscoveryOptions{})
+	require.NoError(t, err)
+
+	result, err = eng.Audit(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []MissingRelation{{Model: "new_users", Relation: "new_users"}}, result.Missing)
+	assert.Equal(t, []OrphanRelation{{Schema: "staging", Name: "stg_people"}}, result.Orphans)
+	assert.Equal(t, []RelationDrift{{
+		Model:    "active_users",
+		Relation: "active_users",
+		Columns:  []ColumnDrift{{Column: "id", Expected: "VARCHAR", Actual: "BIGINT"}},
+	}, {
+		Model:    "staging.stg_users",
+		Relation: "staging.stg_users",
+		Columns:  []ColumnDrift{{Column: "age", Actual: "INTEGER"}},
+	}}, result.Drift)
+
+	dropped, err := eng.DropOrphans(ctx, result.Orphans)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"staging.stg_people"}, dropped)
+	_, err = eng.db.GetTableMetadata(ctx, "staging.stg_people")
+	assert.Error(t, err)
 }
@@ -0,0 +1,124 @@
+package engine
+
+// explain.go - Capturing model query plans and detecting plan regressions
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// SetExplain sets whether runs capture the query plan of each model they
+// build with EXPLAIN, so plan changes between runs can be reported (see
+// PlanRegressions).
+func (e *Engine) SetExplain(explain bool) {
+	e.explain = explain
+}
+
+// PlanRegression is a model whose query plan has more costly operators than
+// the plan captured in the previous run that built it.
+type PlanRegression struct {
+	Model       string
+	PreviousRun string
+	Changes     []core.PlanChange
+}
+
+// PlanRegressions compares the query plans captured in a run with each
+// model's previous plan, returning the models with new full scans, broadcast
+// joins or nested loop joins.
+func (e *Engine) PlanRegressions(runID string) ([]PlanRegression, error) {
+	plans, err := e.store.GetModelPlansForRun(runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get plans of run %s: %w", runID, err)
+	}
+
+	var regressions []PlanRegression
+	for _, plan := range plans {
+		history, err := e.store.GetModelPlans(plan.ModelPath, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get plans of %s: %w", plan.ModelPath, err)
+		}
+		previous := previousPlan(history, runID)
+		if previous == nil {
+			continue
+		}
+		if changes := core.ComparePlans(previous.Plan, plan.Plan); len(changes) > 0 {
+			regressions = append(regressions, PlanRegression{
+				Model:       plan.ModelPath,
+				PreviousRun: previous.RunID,
+				Changes:     changes,
+			})
+		}
+	}
+	return regressions, nil
+}
+
+// previousPlan returns the plan captured before the one of a run in a
+// model's plans, newest first.
+func previousPlan(history []*core.ModelPlan, runID string) *core.ModelPlan {
+	for i, p := range history {
+		if p.RunID == runID {
+			if i+1 < len(history) {
+				return history[i+1]
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// capturePlan saves the query plan of the model just built, when the run
+// captures plans. Failing to explain a query does not fail the build.
+func (e *Engine) capturePlan(ctx context.Context, runID string, p preparedModel) {
+	if !e.explain || p.model.External != nil {
+		return
+	}
+	plan, err := e.explainQuery(ctx, p.sql)
+	if err != nil {
+		e.logger.Debug("failed to explain model query", "model", p.model.Path, "error", err)
+		return
+	}
+	if err := e.store.SaveModelPlan(&core.ModelPlan{RunID: runID, ModelPath: p.model.Path, Plan: plan}); err != nil {
+		e.logger.Debug("failed to record model plan", "model", p.model.Path, "error", err)
+	}
+}
+
+// explainQuery returns the EXPLAIN output of a query as text: one line per
+// row, with its columns separated by tabs.
+func (e *Engine) explainQuery(ctx context.Context, query string) (string, error) {
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+	rows, err := e.db.Query(ctx, "EXPLAIN "+query)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = rows.Close() }()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	values := make([]sql.NullString, len(cols))
+	dest := make([]any, len(cols))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var lines []string
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		fields := make([]string, len(values))
+		for i, v := range values {
+			fields[i] = v.String
+		}
+		lines = append(lines, strings.Join(fields, "\t"))
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,214 @@
+package engine
+
+// external.go - Execution of external models, whose tables are built by a command
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/adapter"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// maxCommandOutput bounds how much of a failed command's output is included
+// in its error.
+const maxCommandOutput = 2000
+
+// executeExternal builds an external model by running its command.
+//
+// The command's inputs are exported to CSV files in the directory named by
+// LEAPSQL_INPUT_DIR, one file per input named after it (e.g., stg_orders.csv).
+// The command either writes the model's rows as a CSV file with a header to
+// the path in LEAPSQL_OUTPUT, which LeapSQL then loads into the model's
+// table, or writes the table named by LEAPSQL_TABLE to the database itself.
+// The table of the previous build is dropped before the command runs, so a
+// command that writes neither fails instead of leaving that table in place.
+func (e *Engine) executeExternal(ctx context.Context, runID string, m *core.Model) (int64, error) {
+	if m.External == nil || len(m.External.Command) == 0 {
+		return 0, fmt.Errorf("external model %s has no external.command", m.Path)
+	}
+	tableName := e.tableName(m.Path)
+
+	workDir, err := os.MkdirTemp("", "leapsql-external-")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+
+	inputDir := filepath.Join(workDir, "inputs")
+	if err := os.Mkdir(inputDir, 0750); err != nil {
+		return 0, fmt.Errorf("failed to create input directory: %w", err)
+	}
+	for _, input := range m.External.Inputs {
+		table := input
+		if path, ok := e.registry.ResolveFrom(m.Project, input); ok {
+			table = e.tableName(path)
+		}
+		file := filepath.Join(inputDir, strings.ReplaceAll(input, "/", "_")+".csv")
+		if err := e.exportCSV(ctx, table, file); err != nil {
+			return 0, fmt.Errorf("failed to export input %s: %w", input, err)
+		}
+	}
+
+	if err := e.db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)); err != nil {
+		return 0, fmt.Errorf("failed to drop previous table of %s: %w", m.Path, err)
+	}
+
+	outputPath := filepath.Join(workDir, "output.csv")
+	if err := e.runExternalCommand(ctx, runID, m, tableName, inputDir, outputPath); err != nil {
+		return 0, err
+	}
+
+	if _, err := os.Stat(outputPath); err == nil {
+		if schema := tableSchema(tableName); schema != "" {
+			_ = e.db.Exec(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schema))
+		}
+		if err := e.loadTable(ctx, tableName, outputPath); err != nil {
+			return 0, fmt.Errorf("failed to load output of %s: %w", m.Path, err)
+		}
+	} else if _, err := e.db.GetTableMetadata(ctx, tableName); err != nil {
+		return 0, fmt.Errorf("external model %s: command wrote neither $LEAPSQL_OUTPUT nor table %s", m.Path, tableName)
+	}
+
+	count, _ := e.countRows(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName))
+	return count, nil
+}
+
+// loadTable replaces a table with the rows of a CSV file, through a staging
+// table for adapters that implement adapter.Swapper.
+func (e *Engine) loadTable(ctx context.Context, tableName, path string) error {
+	swapper, ok := e.db.(adapter.Swapper)
+	if !ok {
+		_ = e.db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName))
+		return e.db.LoadCSV(ctx, tableName, path)
+	}
+
+	staging := stagingTable(tableName)
+	_ = e.db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", staging))
+	err := e.db.LoadCSV(ctx, staging, path)
+	if err == nil {
+		err = swapper.SwapTable(ctx, staging, tableName)
+	}
+	if err != nil {
+		_ = e.db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", staging))
+	}
+	return err
+}
+
+// runExternalCommand runs an external model's command in the model file's
+// directory.
+func (e *Engine) runExternalCommand(ctx context.Context, runID string, m *core.Model, tableName, inputDir, outputPath string) error {
+	args := m.External.Command
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec // G204: the command comes from the model's frontmatter
+	cmd.Dir = filepath.Dir(m.FilePath)
+
+	cmd.Env = os.Environ()
+	for key, value := range m.External.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Env = append(cmd.Env,
+		"LEAPSQL_MODEL="+m.Path,
+		"LEAPSQL_TABLE="+tableName,
+		"LEAPSQL_INPUT_DIR="+inputDir,
+		"LEAPSQL_OUTPUT="+outputPath,
+		"LEAPSQL_RUN_ID="+runID,
+		"LEAPSQL_ENVIRONMENT="+e.environment,
+	)
+	if e.target != nil {
+		cmd.Env = append(cmd.Env, "LEAPSQL_TARGET_TYPE="+e.target.Type)
+	}
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	e.logger.Debug("running external model command", "model", m.Path, "command", strings.Join(args, " "))
+	start := time.Now()
+	err := cmd.Run()
+	e.logger.Debug("external model command finished", "model", m.Path,
+		"duration_ms", time.Since(start).Milliseconds(), "output", out.String())
+	if err == nil {
+		return nil
+	}
+
+	msg := strings.TrimSpace(out.String())
+	if len(msg) > maxCommandOutput {
+		msg = "..." + msg[len(msg)-maxCommandOutput:]
+	}
+	if msg != "" {
+		return fmt.Errorf("external model %s: %s failed: %w\n%s", m.Path, args[0], err, msg)
+	}
+	return fmt.Errorf("external model %s: %s failed: %w", m.Path, args[0], err)
+}
+
+// exportCSV writes the rows of a table to a CSV file with a header. NULLs
+// are written as empty fields.
+func (e *Engine) exportCSV(ctx context.Context, table, path string) (err error) {
+	rows, err := e.db.Query(ctx, fmt.Sprintf("SELECT * FROM %s", table))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path) //nolint:gosec // G304: path is in a directory created by executeExternal
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, f.Close()) }()
+
+	w := csv.NewWriter(f)
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	record := make([]string, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		for i, v := range values {
+			record[i] = formatCSVValue(v)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// formatCSVValue formats a scanned value as a CSV field.
+func formatCSVValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
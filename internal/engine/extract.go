@@ -0,0 +1,123 @@
+package engine
+
+// extract.go - Patches extracting subqueries into CTEs and CTEs into models
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/parser"
+)
+
+// Extraction is the patch of a model extracting one of its subqueries into
+// a CTE, or one of its CTEs into a model of its own.
+type Extraction struct {
+	// Model is the path of the patched model
+	Model string
+	// FilePath is the path of the model's SQL file
+	FilePath string
+	// SQL is the rendered SQL the fix applies to
+	SQL string
+	// Fix extracts the subquery or the CTE in SQL
+	Fix lint.Fix
+	// NewModel is the path of the model a CTE is extracted into, empty when
+	// a subquery is extracted
+	NewModel string
+	// NewFilePath is the path of the new model's SQL file
+	NewFilePath string
+	// NewSQL is the new model's SQL
+	NewSQL string
+}
+
+// PlanExtractCTE builds the patch moving the subquery of a model aliased
+// alias into a CTE named name, read where the subquery was.
+func (e *Engine) PlanExtractCTE(model, alias, name string) (*Extraction, error) {
+	d := e.ModelDialect()
+	if d == nil {
+		return nil, fmt.Errorf("dialect not available")
+	}
+	if !isPlainIdentifier(name) {
+		return nil, fmt.Errorf("invalid CTE name %q: use letters, digits and underscores", name)
+	}
+	m, ok := e.models[model]
+	if !ok {
+		return nil, fmt.Errorf("model not found: %s", model)
+	}
+	rendered, stmt, err := e.parseRendered(m, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	for _, sub := range lintsql.Subqueries(stmt) {
+		if !strings.EqualFold(sub.Alias, alias) {
+			matches = append(matches, sub.Alias)
+			continue
+		}
+		fix, err := lintsql.ExtractCTE(stmt, rendered, parser.TokenizeWithDialect(rendered, d), sub, name)
+		if err != nil {
+			return nil, fmt.Errorf("cannot extract %s in %s: %w", alias, model, err)
+		}
+		return &Extraction{Model: model, FilePath: m.FilePath, SQL: rendered, Fix: fix}, nil
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("model %s has no subquery in a FROM clause", model)
+	}
+	return nil, fmt.Errorf("model %s has no subquery aliased %s (subqueries: %s)", model, alias, strings.Join(matches, ", "))
+}
+
+// PlanExtractModel builds the patch moving the CTE of a model named cte into
+// a new model named name, in the model's directory. The model reads the new
+// model with ref() where it read the CTE. The new model's SQL is the CTE's
+// query, formatted.
+func (e *Engine) PlanExtractModel(model, cte, name string) (*Extraction, error) {
+	d := e.ModelDialect()
+	if d == nil {
+		return nil, fmt.Errorf("dialect not available")
+	}
+	if !isPlainIdentifier(name) {
+		return nil, fmt.Errorf("invalid model name %q: use letters, digits and underscores", name)
+	}
+	m, ok := e.models[model]
+	if !ok {
+		return nil, fmt.Errorf("model not found: %s", model)
+	}
+	if path, ok := e.registry.ResolveFrom(m.Project, name); ok {
+		return nil, fmt.Errorf("model %s is already named %s", path, name)
+	}
+	newFile := filepath.Join(filepath.Dir(m.FilePath), name+".sql")
+	if _, err := os.Stat(newFile); err == nil {
+		return nil, fmt.Errorf("file %s already exists", newFile)
+	}
+
+	rendered, stmt, err := e.parseRendered(m, d)
+	if err != nil {
+		return nil, err
+	}
+	fix, query, err := lintsql.ExtractModel(stmt, rendered, parser.TokenizeWithDialect(rendered, d), cte, fmt.Sprintf("{{ ref('%s') }}", name))
+	if err != nil {
+		return nil, fmt.Errorf("cannot extract %s in %s: %w", cte, model, err)
+	}
+	newSQL, err := FormatSQL(query, d)
+	if err != nil {
+		newSQL = query
+	}
+
+	newModel := name
+	if i := strings.LastIndex(model, "."); i >= 0 {
+		newModel = model[:i+1] + name
+	}
+	return &Extraction{
+		Model:       model,
+		FilePath:    m.FilePath,
+		SQL:         rendered,
+		Fix:         fix,
+		NewModel:    newModel,
+		NewFilePath: newFile,
+		NewSQL:      strings.TrimRight(newSQL, "\n") + "\n",
+	}, nil
+}
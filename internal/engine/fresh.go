@@ -0,0 +1,150 @@
+package engine
+
+// fresh.go - Skipping models whose sources are unchanged since their last build
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// SetSkipFresh sets whether runs skip models whose inputs are unchanged since
+// their last successful build, including the raw tables and files they read.
+// Unlike cache hits, incremental models are skipped too.
+func (e *Engine) SetSkipFresh(skipFresh bool) {
+	e.skipFresh = skipFresh
+}
+
+// sourceSnapshots takes a snapshot of when each raw table and file a model
+// reads last changed: the latest loaded_at value of tables configured in
+// table_sources, and the modification time of the newest matching file of
+// file sources. Seeds are left out, since the build hash covers their
+// contents. It also returns the sources whose changes cannot be told: other
+// raw tables, and files read on targets other than DuckDB.
+func (e *Engine) sourceSnapshots(ctx context.Context, m *core.Model) ([]core.SourceSnapshot, []string) {
+	var snapshots []core.SourceSnapshot
+	var unknown []string
+	_, sources := e.registry.ResolveDependenciesFrom(m.Project, m.Sources)
+	for _, source := range sources {
+		if e.seedHash(source) != "" {
+			continue
+		}
+
+		var changedAt time.Time
+		var err error
+		if slices.Contains(m.Files, source) {
+			changedAt, err = e.fileChangedAt(ctx, source)
+		} else {
+			changedAt, err = e.tableChangedAt(ctx, source)
+		}
+		if err != nil {
+			e.logger.Debug("source changes unknown", "model", m.Path, "source", source, "error", err)
+			unknown = append(unknown, source)
+			continue
+		}
+		snapshots = append(snapshots, core.SourceSnapshot{Source: source, ChangedAt: changedAt})
+	}
+	return snapshots, unknown
+}
+
+// fileChangedAt returns the modification time of the newest file matching a
+// file source.
+func (e *Engine) fileChangedAt(ctx context.Context, path string) (time.Time, error) {
+	if e.dialect == nil || e.dialect.Name != "duckdb" {
+		return time.Time{}, fmt.Errorf("file modification times require a DuckDB target")
+	}
+	result := FileFreshness{FileSource: FileSource{Path: path}}
+	if err := e.statFiles(ctx, &result); err != nil {
+		return time.Time{}, err
+	}
+	if result.Files == 0 {
+		return time.Time{}, fmt.Errorf("no files match")
+	}
+	return result.LastModified, nil
+}
+
+// tableChangedAt returns the latest loaded_at value of a raw table configured
+// in table_sources.
+func (e *Engine) tableChangedAt(ctx context.Context, table string) (time.Time, error) {
+	i := slices.IndexFunc(e.tableSources, func(t core.TableSourceConfig) bool { return t.Table == table })
+	if i < 0 {
+		return time.Time{}, fmt.Errorf("no loaded_at column: not in table_sources")
+	}
+
+	rows, err := e.db.Query(ctx, fmt.Sprintf("SELECT MAX(%s) FROM %s", e.tableSources[i].LoadedAt, table))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var loadedAt sql.NullTime
+	if rows.Next() {
+		if err := rows.Scan(&loadedAt); err != nil {
+			return time.Time{}, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return time.Time{}, err
+	}
+	if !loadedAt.Valid {
+		return time.Time{}, fmt.Errorf("table has no rows")
+	}
+	return loadedAt.Time, nil
+}
+
+// freshBuild returns the previous build of a model if a run with --skip-fresh
+// can skip it: its build hash is unchanged, its relation still exists, and
+// none of the sources it reads changed since the snapshot taken before that
+// build. External models are never skipped, since their commands may read
+// data LeapSQL does not track, and neither are models reading sources whose
+// changes are unknown.
+func (e *Engine) freshBuild(ctx context.Context, target, hash string, p preparedModel, snapshots []core.SourceSnapshot, unknown []string) *core.ModelBuild {
+	if !e.skipFresh || target == "" || len(unknown) > 0 || e.modelFullRefresh(p.model) || p.model.External != nil {
+		return nil
+	}
+	for _, parent := range e.graph.GetParents(p.model.Path) {
+		if slices.Contains(e.deferred, parent) {
+			return nil
+		}
+	}
+
+	build, err := e.store.GetModelBuild(p.persisted.ID, target)
+	if err != nil || build == nil || build.BuildHash != hash {
+		return nil
+	}
+
+	previous, err := e.store.GetSourceSnapshots(p.model.Path, target)
+	if err != nil || len(previous) != len(snapshots) {
+		return nil
+	}
+	changedAt := make(map[string]time.Time, len(previous))
+	for _, s := range previous {
+		changedAt[s.Source] = s.ChangedAt
+	}
+	for _, s := range snapshots {
+		last, ok := changedAt[s.Source]
+		if !ok || s.ChangedAt.After(last) {
+			return nil
+		}
+	}
+
+	if _, err := e.db.GetTableMetadata(ctx, e.tableName(p.model.Path)); err != nil {
+		return nil
+	}
+	return build
+}
+
+// recordSourceSnapshots saves the source snapshots taken before a successful
+// build, for the next run with --skip-fresh.
+func (e *Engine) recordSourceSnapshots(target string, p preparedModel, snapshots []core.SourceSnapshot) {
+	if !e.skipFresh || target == "" {
+		return
+	}
+	if err := e.store.SaveSourceSnapshots(p.model.Path, target, snapshots); err != nil {
+		e.logger.Debug("failed to record source snapshots", "model", p.model.Path, "error", err)
+	}
+}
@@ -0,0 +1,144 @@
+package engine
+
+// freshness.go - Files models read with table functions and their freshness
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// FreshnessStatus is the outcome of a file source's freshness check.
+type FreshnessStatus string
+
+// Freshness statuses.
+const (
+	FreshnessPass  FreshnessStatus = "pass"
+	FreshnessWarn  FreshnessStatus = "warn"
+	FreshnessError FreshnessStatus = "error"
+)
+
+// FileSource is a file, URL or glob that models read with a table function,
+// such as read_parquet('s3://bucket/orders/*.parquet').
+type FileSource struct {
+	Path string
+	// Models are the models reading the source, sorted
+	Models []string
+	// Config holds the source's freshness thresholds (zero if not configured)
+	Config core.FileSourceConfig
+}
+
+// FileFreshness is the result of a file source's freshness check.
+type FileFreshness struct {
+	FileSource
+	// Files is the number of files matching the path
+	Files int
+	// LastModified is the modification time of the newest matching file
+	LastModified time.Time
+	// Age is the time since LastModified when checked
+	Age    time.Duration
+	Status FreshnessStatus
+	// Reason explains a warn or error status
+	Reason string
+}
+
+// FileSources returns the file sources read by the discovered models and
+// those configured in file_sources, sorted by path.
+func (e *Engine) FileSources() []FileSource {
+	sources := make(map[string]*FileSource)
+	source := func(path string) *FileSource {
+		if s, ok := sources[path]; ok {
+			return s
+		}
+		s := &FileSource{Path: path}
+		sources[path] = s
+		return s
+	}
+
+	for _, m := range e.models {
+		for _, path := range m.Files {
+			s := source(path)
+			s.Models = append(s.Models, m.Path)
+		}
+	}
+	for _, cfg := range e.fileSources {
+		source(cfg.Path).Config = cfg
+	}
+
+	result := make([]FileSource, 0, len(sources))
+	for _, s := range sources {
+		sort.Strings(s.Models)
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result
+}
+
+// CheckFileFreshness checks the age of the newest file matching each file
+// source against its warn_after and error_after thresholds. A source that
+// matches no files fails the check. Modification times are read by DuckDB
+// (read_blob), so remote paths need the same extensions and credentials as
+// the models reading them.
+func (e *Engine) CheckFileFreshness(ctx context.Context) ([]FileFreshness, error) {
+	if err := e.ensureDBConnected(ctx); err != nil {
+		return nil, err
+	}
+	if e.dialect == nil || e.dialect.Name != "duckdb" {
+		return nil, fmt.Errorf("file source freshness checks require a DuckDB target")
+	}
+
+	now := time.Now()
+	var results []FileFreshness
+	for _, source := range e.FileSources() {
+		result := FileFreshness{FileSource: source}
+		if err := e.statFiles(ctx, &result); err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", source.Path, err)
+		}
+		if result.Files > 0 {
+			result.Age = now.Sub(result.LastModified)
+		}
+		result.Status, result.Reason = freshnessStatus(result)
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// statFiles reads the number of files matching a source and the newest
+// modification time among them.
+func (e *Engine) statFiles(ctx context.Context, result *FileFreshness) error {
+	query := fmt.Sprintf("SELECT count(*), max(last_modified) FROM read_blob('%s')",
+		strings.ReplaceAll(result.Path, "'", "''"))
+	rows, err := e.db.Query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var lastModified sql.NullTime
+	if rows.Next() {
+		if err := rows.Scan(&result.Files, &lastModified); err != nil {
+			return err
+		}
+	}
+	result.LastModified = lastModified.Time
+	return rows.Err()
+}
+
+// freshnessStatus compares a source's age with its thresholds.
+func freshnessStatus(r FileFreshness) (FreshnessStatus, string) {
+	switch {
+	case r.Files == 0:
+		return FreshnessError, "no files match"
+	case r.Config.ErrorAfter > 0 && r.Age > r.Config.ErrorAfter:
+		return FreshnessError, fmt.Sprintf("older than %s", r.Config.ErrorAfter)
+	case r.Config.WarnAfter > 0 && r.Age > r.Config.WarnAfter:
+		return FreshnessWarn, fmt.Sprintf("older than %s", r.Config.WarnAfter)
+	default:
+		return FreshnessPass, ""
+	}
+}
@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"sort"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// GroupModels lists the models owned by one group.
+type GroupModels struct {
+	// Group is the owning group; Name is empty for models without a declared group
+	Group core.GroupConfig
+	// Models are the model paths, sorted
+	Models []string
+}
+
+// GetGroups returns the groups declared in config.
+func (e *Engine) GetGroups() []core.GroupConfig {
+	return e.groups
+}
+
+// GetLayers returns the model layers declared in config, or nil if the
+// project uses the default layers.
+func (e *Engine) GetLayers() []core.LayerConfig {
+	return e.layers
+}
+
+// GetGroup returns the declared group with the given name.
+func (e *Engine) GetGroup(name string) (core.GroupConfig, bool) {
+	for _, g := range e.groups {
+		if g.Name == name {
+			return g, true
+		}
+	}
+	return core.GroupConfig{}, false
+}
+
+// GroupByOwner buckets model paths by owning group, so impact reports and
+// failure notifications can be routed to the teams responsible.
+// Groups appear in declaration order; models without a declared group are
+// collected last under an unnamed group. Paths that are not models (e.g.
+// sources) are skipped.
+func (e *Engine) GroupByOwner(paths []string) []GroupModels {
+	byGroup := make(map[string][]string)
+	for _, path := range paths {
+		m, ok := e.models[path]
+		if !ok {
+			continue
+		}
+		name := m.Group
+		if _, declared := e.GetGroup(name); !declared {
+			name = ""
+		}
+		byGroup[name] = append(byGroup[name], path)
+	}
+
+	var result []GroupModels
+	for _, g := range e.groups {
+		if models, ok := byGroup[g.Name]; ok {
+			sort.Strings(models)
+			result = append(result, GroupModels{Group: g, Models: models})
+		}
+	}
+	if models, ok := byGroup[""]; ok {
+		sort.Strings(models)
+		result = append(result, GroupModels{Models: models})
+	}
+	return result
+}
@@ -1,9 +1,91 @@
 package engine
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
 // helpers.go - Utility functions for the engine package
 
 // pathToTableName converts a model path to a SQL table name.
+// Workspace project namespaces are not part of the table name.
 // e.g., "staging.customers" -> "staging.customers"
+// e.g., "core/staging.customers" -> "staging.customers"
 func pathToTableName(path string) string {
-	return path
+	_, tablePath := core.SplitModelID(path)
+	return tablePath
+}
+
+// tableName returns the table name of a model: the table name of its path,
+// or its schema and name when its frontmatter sets a schema, qualified by the
+// model's database when it is built in an attached database. Names given by
+// a generate_schema_name hook take precedence (see resolveRelations).
+// e.g., "staging.customers" -> "staging.customers"
+// e.g., "staging.customers" with schema analytics -> "analytics.customers"
+// e.g., "staging.customers" with database lake -> "lake.staging.customers"
+func (e *Engine) tableName(path string) string {
+	if table, ok := e.relations[path]; ok {
+		return table
+	}
+	return e.defaultTableName(path)
+}
+
+// defaultTableName returns the table name of a model regardless of the
+// generate_schema_name hook. Production tables are read by these names,
+// since the hook usually keeps them for production.
+func (e *Engine) defaultTableName(path string) string {
+	table := pathToTableName(path)
+	m, ok := e.models[path]
+	if !ok {
+		return table
+	}
+	if m.Schema != "" {
+		table = m.Schema + "." + unqualifiedName(table)
+	}
+	return e.qualifyDatabase(m, table)
+}
+
+// qualifyDatabase qualifies the table name of a model built in an attached
+// database by the database. Unqualified names get the target's schema.
+func (e *Engine) qualifyDatabase(m *core.Model, table string) string {
+	if m.Database == "" {
+		return table
+	}
+	if !strings.Contains(table, ".") && e.target != nil && e.target.Schema != "" {
+		table = e.target.Schema + "." + table
+	}
+	return m.Database + "." + table
+}
+
+// unqualifiedName returns the last part of a table name.
+// e.g., "lake.staging.customers" -> "customers"
+func unqualifiedName(table string) string {
+	return table[strings.LastIndex(table, ".")+1:]
+}
+
+// tableSchema returns the schema part of a table name, qualified by its
+// catalog if any, or "" for an unqualified name.
+// e.g., "lake.staging.customers" -> "lake.staging"
+func tableSchema(table string) string {
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		return table[:i]
+	}
+	return ""
+}
+
+// formatRef formats a ref() call for messages.
+// e.g., formatRef("core", "dim_users", 2) -> ref("core", "dim_users", v=2)
+func formatRef(project, model string, version int) string {
+	var args []string
+	if project != "" {
+		args = append(args, strconv.Quote(project))
+	}
+	args = append(args, strconv.Quote(model))
+	if version > 0 {
+		args = append(args, fmt.Sprintf("v=%d", version))
+	}
+	return "ref(" + strings.Join(args, ", ") + ")"
 }
@@ -0,0 +1,153 @@
+package engine
+
+// limits.go - Concurrent model builds and their per-target and per-resource-class limits
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// SetThreads sets how many models runs build at once (1 or less builds them
+// one at a time). A model starts once the models it depends on are built, and
+// its build waits for the target's max_concurrent_queries and the limit of its
+// resource class.
+func (e *Engine) SetThreads(threads int) {
+	e.threads = threads
+}
+
+// buildSlots holds the semaphores counting the model builds running at once,
+// keyed by target and resource class. They are shared by every engine of the
+// process, so projects built concurrently against the same warehouse, e.g.
+// by an application embedding LeapSQL, stay within its limits together.
+var buildSlots semaphores
+
+// semaphores holds build semaphores by key.
+type semaphores struct {
+	mu         sync.Mutex
+	semaphores map[string]*buildSemaphore
+}
+
+// buildSemaphore counts the builds running against a target or one of its
+// resource classes. Each engine waits until the count is below its own limit,
+// so engines configured with different limits for the same target never run
+// more builds together than the largest of them allows.
+type buildSemaphore struct {
+	mu      sync.Mutex
+	running int
+	freed   chan struct{} // Closed when a build releases its slot
+}
+
+// get returns the semaphore of a target, or of one of its resource classes.
+func (s *semaphores) get(target, class string) *buildSemaphore {
+	key := target + "\x00" + class
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.semaphores == nil {
+		s.semaphores = make(map[string]*buildSemaphore)
+	}
+	sem, ok := s.semaphores[key]
+	if !ok {
+		sem = &buildSemaphore{freed: make(chan struct{})}
+		s.semaphores[key] = sem
+	}
+	return sem
+}
+
+// tryAcquire takes a slot if fewer than limit builds are running, and returns
+// the channel closed when a slot is next released if not.
+func (s *buildSemaphore) tryAcquire(limit int) (bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running < limit {
+		s.running++
+		return true, nil
+	}
+	return false, s.freed
+}
+
+// acquire waits until fewer than limit builds are running and takes a slot.
+func (s *buildSemaphore) acquire(ctx context.Context, limit int) error {
+	for {
+		ok, freed := s.tryAcquire(limit)
+		if ok {
+			return nil
+		}
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release frees a slot and wakes the builds waiting for one.
+func (s *buildSemaphore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+	close(s.freed)
+	s.freed = make(chan struct{})
+}
+
+// buildSlot is a semaphore a build takes a slot of, with the limit of the
+// engine taking it.
+type buildSlot struct {
+	semaphore *buildSemaphore
+	limit     int
+}
+
+// validateLimits checks a target's max_concurrent_queries and resource_classes.
+func validateLimits(maxQueries int, classes map[string]int) error {
+	if maxQueries < 0 {
+		return fmt.Errorf("invalid max_concurrent_queries %d: must be a number of queries, or 0 for no limit", maxQueries)
+	}
+	for class, limit := range classes {
+		if limit < 1 {
+			return fmt.Errorf("invalid resource class %q limit %d: must be at least 1", class, limit)
+		}
+	}
+	return nil
+}
+
+// acquireBuildSlots waits until a model's build fits within the target's
+// max_concurrent_queries and the limit of the model's resource class, and
+// returns the function releasing its slots. Models of resource classes the
+// target does not limit only wait for max_concurrent_queries. Targets
+// without a persistent database (an in-memory DuckDB) are not shared by
+// engines, so only the builds of this engine count against their limits.
+func (e *Engine) acquireBuildSlots(ctx context.Context, target string, m *core.Model) (func(), error) {
+	slots := &buildSlots
+	if target == "" {
+		slots = &e.localSlots
+	}
+
+	// The resource class slot is taken first, so a build waiting for its
+	// class does not hold one of the target's slots
+	var held []buildSlot
+	if limit, ok := e.resourceLimits[m.ResourceClass]; ok && m.ResourceClass != "" {
+		held = append(held, buildSlot{slots.get(target, m.ResourceClass), limit})
+	}
+	if e.maxQueries > 0 {
+		held = append(held, buildSlot{slots.get(target, ""), e.maxQueries})
+	}
+
+	release := func(held []buildSlot) {
+		for _, slot := range held {
+			slot.semaphore.release()
+		}
+	}
+	for i, slot := range held {
+		if ok, _ := slot.semaphore.tryAcquire(slot.limit); ok {
+			continue
+		}
+		e.logger.Debug("waiting for a build slot", "model", m.Path, "resource_class", m.ResourceClass)
+		if err := slot.semaphore.acquire(ctx, slot.limit); err != nil {
+			release(held[:i])
+			return nil, err
+		}
+	}
+	return func() { release(held) }, nil
+}
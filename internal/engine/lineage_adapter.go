@@ -1,17 +1,17 @@
 package engine
 
 import (
-	"github.com/leapstack-labs/leapsql/internal/lineage"
 	"github.com/leapstack-labs/leapsql/internal/loader"
 	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
 )
 
-// lineageExtractorAdapter adapts internal/lineage to loader.LineageExtractor.
+// lineageExtractorAdapter adapts pkg/lineage to loader.LineageExtractor.
 // This allows the loader package to remain decoupled from the lineage package
 // while still being able to extract lineage information when wired through engine.
 type lineageExtractorAdapter struct{}
 
-// NewLineageExtractor creates a new LineageExtractor that uses the internal/lineage package.
+// NewLineageExtractor creates a new LineageExtractor that uses the pkg/lineage package.
 func NewLineageExtractor() loader.LineageExtractor {
 	return &lineageExtractorAdapter{}
 }
@@ -39,6 +39,7 @@ func (a *lineageExtractorAdapter) Extract(sql string, d *core.Dialect) (*loader.
 
 	return &loader.LineageResult{
 		Sources:        result.Sources,
+		Files:          result.Files,
 		Columns:        columns,
 		UsesSelectStar: result.UsesSelectStar,
 	}, nil
@@ -0,0 +1,211 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/template"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/dialect"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/leapstack-labs/leapsql/pkg/transpile"
+)
+
+// PortabilityRuleID is the rule ID of the diagnostics LintModels reports for
+// constructs a secondary dialect of the lint config has no equivalent for.
+const PortabilityRuleID = "PORT"
+
+// ModelLint holds the lint diagnostics of one model.
+type ModelLint struct {
+	// Model is the model path
+	Model string
+	// FilePath is the path of the model's SQL file
+	FilePath string
+	// Diagnostics are the rule violations found in the model's rendered SQL,
+	// positioned in Source
+	Diagnostics []lint.Diagnostic
+	// SQL is the rendered SQL that fix edits refer to
+	SQL string
+	// Source is the content diagnostic positions refer to: the model's file,
+	// or SQL when the model's SQL can't be located in it
+	Source string
+}
+
+// LintableModels returns all discovered models, including models disabled in
+// the current environment: they are excluded from the DAG but still linted.
+func (e *Engine) LintableModels() map[string]*core.Model {
+	if len(e.disabled) == 0 {
+		return e.models
+	}
+
+	models := make(map[string]*core.Model, len(e.models)+len(e.disabled))
+	maps.Copy(models, e.models)
+	maps.Copy(models, e.disabled)
+	return models
+}
+
+// LintModels analyzes the rendered SQL of models with the SQL rules enabled
+// in cfg, applying its model overrides to each model's diagnostics. Models
+// are analyzed as one batch, in parallel, with the output columns of the
+// models they read from. Only models with diagnostics are
+// returned, sorted by file path.
+// Models are also transpiled for each of the config's secondary dialects,
+// reporting the constructs a dialect has no equivalent for as PORT
+// diagnostics.
+// Models that fail to render or parse are skipped: their errors are reported
+// by discovery and runs.
+// Diagnostic positions are mapped from the rendered SQL back to the model's
+// file, so they point at the template the user wrote: a finding in the
+// output of a macro call points at the call.
+func (e *Engine) LintModels(ctx context.Context, models []*core.Model, cfg *lint.Config) ([]ModelLint, error) {
+	d := e.ModelDialect()
+	if d == nil {
+		return nil, fmt.Errorf("dialect not available")
+	}
+	secondary, err := secondaryDialects(cfg, d)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]lintsql.Source, 0, len(models))
+	sourceMaps := make([]*template.SourceMap, 0, len(models))
+	linted := make([]*core.Model, 0, len(models))
+	for _, m := range models {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Lint the model's own SQL, without its row filter
+		rendered, sm, err := e.renderSQLWithSourceMap(m)
+		if err != nil {
+			continue
+		}
+		sources = append(sources, lintsql.Source{
+			Path:  m.Path,
+			SQL:   rendered,
+			Model: &lint.ModelInfo{Path: m.Path, FilePath: m.FilePath, Tags: m.Tags, Owner: m.Owner, Group: m.Group},
+		})
+		sourceMaps = append(sourceMaps, sm)
+		linted = append(linted, m)
+	}
+
+	analyzer := lintsql.NewAnalyzer(cfg, d.Name)
+	analyzer.SetSchema(e.lintSchema())
+	analyzed, err := analyzer.AnalyzeProject(ctx, sources, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []ModelLint
+	for i, res := range analyzed {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if res.SyntaxErrors {
+			continue
+		}
+		diagnostics := res.Diagnostics
+		if len(secondary) > 0 && !cfg.IsDisabled(PortabilityRuleID) {
+			diagnostics = append(diagnostics, portabilityDiagnostics(cfg, sources[i], d, secondary)...)
+			lint.SortDiagnostics(diagnostics)
+		}
+		if len(diagnostics) == 0 {
+			continue
+		}
+		m := linted[i]
+		source := mapToFile(diagnostics, m, sources[i].SQL, sourceMaps[i])
+		results = append(results, ModelLint{Model: m.Path, FilePath: m.FilePath, Diagnostics: diagnostics, SQL: sources[i].SQL, Source: source})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].FilePath != results[j].FilePath {
+			return results[i].FilePath < results[j].FilePath
+		}
+		return results[i].Model < results[j].Model
+	})
+	return results, nil
+}
+
+// mapToFile moves the positions of diagnostics found in the rendered SQL of
+// a model to its file, through the source map of its templates. It returns
+// the content positions refer to: the file, or the rendered SQL when the
+// model's SQL can't be located in the file.
+func mapToFile(diagnostics []lint.Diagnostic, m *core.Model, rendered string, sm *template.SourceMap) string {
+	base := strings.Index(m.RawContent, m.SQL)
+	if m.SQL == "" || base < 0 || sm == nil {
+		return rendered
+	}
+	lint.MapRanges(diagnostics, func(pos, end token.Position) (token.Position, token.Position) {
+		start, stop := sm.Range(pos.OffsetIn(rendered), end.OffsetIn(rendered))
+		return token.PositionAt(m.RawContent, base+start), token.PositionAt(m.RawContent, base+stop)
+	})
+	return m.RawContent
+}
+
+// secondaryDialects returns the secondary dialects of a lint config, leaving
+// out the dialect models are written in. It fails for unknown dialects.
+func secondaryDialects(cfg *lint.Config, primary *core.Dialect) ([]*core.Dialect, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	var dialects []*core.Dialect
+	for _, name := range cfg.Dialects {
+		if name == primary.Name {
+			continue
+		}
+		d, ok := dialect.Get(name)
+		if !ok {
+			return nil, fmt.Errorf("lint.dialects: unknown dialect %q: supported dialects are %v", name, dialect.List())
+		}
+		dialects = append(dialects, d)
+	}
+	return dialects, nil
+}
+
+// portabilityDiagnostics transpiles the SQL of a source written in dialect
+// from for each secondary dialect, reporting one diagnostic for each
+// construct a dialect has no equivalent for. The constructs belong to the
+// whole model, so diagnostics are reported at its start.
+func portabilityDiagnostics(cfg *lint.Config, src lintsql.Source, from *core.Dialect, secondary []*core.Dialect) []lint.Diagnostic {
+	var diagnostics []lint.Diagnostic
+	for _, to := range secondary {
+		var unsupported *transpile.UnsupportedError
+		if _, err := TranspileSQL(src.SQL, from, to); !errors.As(err, &unsupported) {
+			continue
+		}
+		for _, construct := range unsupported.Constructs {
+			diagnostics = append(diagnostics, lint.Diagnostic{
+				RuleID:   PortabilityRuleID,
+				Severity: cfg.GetModelSeverity(PortabilityRuleID, core.SeverityWarning, src.Model),
+				Message:  "Not supported by " + to.Name + ": " + construct,
+				Pos:      token.Position{Line: 1, Column: 1},
+			})
+		}
+	}
+	return diagnostics
+}
+
+// lintSchema returns the output columns of the models whose columns are
+// known from lineage, keyed by table name, so SQL rules can resolve the
+// columns a model reads from upstream models. Models using SELECT * are left
+// out, as their lineage may not list every column.
+func (e *Engine) lintSchema() lint.Schema {
+	schema := make(lint.Schema)
+	for path, m := range e.LintableModels() {
+		if m.UsesSelectStar || len(m.Columns) == 0 {
+			continue
+		}
+		cols := make([]string, len(m.Columns))
+		for i, c := range m.Columns {
+			cols[i] = c.Name
+		}
+		schema[e.tableName(path)] = cols
+	}
+	return schema
+}
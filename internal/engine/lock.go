@@ -0,0 +1,51 @@
+package engine
+
+// lock.go - Serializing runs of processes sharing a state store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// LockConfig configures the state lock acquired by runs.
+type LockConfig struct {
+	// Disabled skips locking, e.g. when a scheduler already serializes runs
+	Disabled bool
+	// Timeout is how long to wait for another run to release the lock (0 fails immediately)
+	Timeout time.Duration
+}
+
+// SetLock configures the state lock acquired by subsequent runs.
+func (e *Engine) SetLock(cfg LockConfig) {
+	e.lock = cfg
+}
+
+// lockRuns acquires the run lock of the state store, if it supports locking,
+// and marks the runs orphaned by crashed processes as failed. It returns a
+// function releasing the lock and the IDs of the orphaned runs.
+func (e *Engine) lockRuns(ctx context.Context) (func(), []string, error) {
+	locker, ok := e.store.(core.Locker)
+	if !ok || e.lock.Disabled {
+		return func() {}, nil, nil
+	}
+
+	release, err := locker.LockRuns(ctx, e.lock.Timeout)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock state: %w", err)
+	}
+	unlock := func() {
+		if err := release(); err != nil {
+			e.logger.Warn("failed to release state lock", "error", err)
+		}
+	}
+
+	orphaned, err := e.recoverOrphanedRuns()
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return unlock, orphaned, nil
+}
@@ -0,0 +1,116 @@
+package engine
+
+// masking.go - Masking of the columns models tag as PII, per target
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/adapter"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// masksInSQL reports whether a model's PII columns are masked by rewriting
+// its SQL, in the hash and null masking modes.
+func (e *Engine) masksInSQL(m *core.Model) bool {
+	if len(m.PII) == 0 {
+		return false
+	}
+	return e.masking.Mode == core.MaskingHash || e.masking.Mode == core.MaskingNull
+}
+
+// checkMasking reports whether a model's PII columns can be masked on the
+// target, before the model is built. In hash and null mode the model's columns are listed from its
+// lineage, so they must be known and include every PII column; in policy mode
+// the adapter must implement adapter.Masker.
+func (e *Engine) checkMasking(m *core.Model) error {
+	if len(m.PII) == 0 {
+		return nil
+	}
+	switch e.masking.Mode {
+	case core.MaskingHash, core.MaskingNull:
+		if m.External != nil {
+			return fmt.Errorf("%s: PII columns of external models can only be masked in policy mode", m.Path)
+		}
+		if m.UsesSelectStar || len(m.Columns) == 0 {
+			return fmt.Errorf("%s: cannot mask PII columns of a model whose columns are unknown; select its columns explicitly instead of *", m.Path)
+		}
+		for _, pii := range m.PII {
+			if !hasColumn(m.Columns, pii) {
+				return fmt.Errorf("%s: PII column %s is not a column of the model", m.Path, pii)
+			}
+		}
+	case core.MaskingPolicy:
+		if _, ok := e.db.(adapter.Masker); !ok {
+			return fmt.Errorf("%s: target %s does not support masking policies", m.Path, e.dbConfig.Type)
+		}
+	}
+	return nil
+}
+
+// withMasking wraps a model's SQL so it returns its PII columns hashed or
+// NULL, according to the masking mode. Hashed values are the MD5 of the value
+// as text, so they can still be joined and counted; NULL keeps the column's
+// type. The SQL is returned unchanged when the model is not masked in SQL.
+func (e *Engine) withMasking(m *core.Model, sql string) string {
+	if !e.masksInSQL(m) {
+		return sql
+	}
+
+	columns := make([]string, len(m.Columns))
+	for i, col := range m.Columns {
+		name := e.dialect.QuoteIdentifierIfNeeded(col.Name)
+		switch {
+		case !containsFold(m.PII, col.Name):
+			columns[i] = name
+		case e.masking.Mode == core.MaskingHash:
+			columns[i] = fmt.Sprintf("md5(CAST(%s AS VARCHAR)) AS %s", name, name)
+		default:
+			columns[i] = fmt.Sprintf("CASE WHEN FALSE THEN %s END AS %s", name, name)
+		}
+	}
+	sql = strings.TrimSuffix(strings.TrimSpace(sql), ";")
+	return fmt.Sprintf("SELECT %s FROM (\n%s\n) AS leapsql_masked", strings.Join(columns, ", "), sql)
+}
+
+// applyMaskingPolicy attaches the target's masking policy to the PII columns
+// of a built model, in policy mode.
+func (e *Engine) applyMaskingPolicy(ctx context.Context, m *core.Model) error {
+	if len(m.PII) == 0 || e.masking.Mode != core.MaskingPolicy {
+		return nil
+	}
+	masker, ok := e.db.(adapter.Masker)
+	if !ok {
+		return fmt.Errorf("target %s does not support masking policies", e.dbConfig.Type)
+	}
+
+	tableName := e.tableName(m.Path)
+	for _, column := range m.PII {
+		if err := masker.SetMaskingPolicy(ctx, tableName, column, e.masking.Policy); err != nil {
+			return fmt.Errorf("failed to set masking policy on %s.%s: %w", tableName, column, err)
+		}
+	}
+	return nil
+}
+
+// hasColumn reports whether columns include a column named name, ignoring
+// case.
+func hasColumn(columns []core.ColumnInfo, name string) bool {
+	for _, col := range columns {
+		if strings.EqualFold(col.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether names contains name, ignoring case.
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -7,29 +7,47 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/leapstack-labs/leapsql/pkg/adapter"
 	"github.com/leapstack-labs/leapsql/pkg/core"
 )
 
-// executeTable creates or replaces a table.
+// executeTable creates or replaces a table. Adapters that implement
+// adapter.Swapper build the table under a staging name and swap it in, so
+// readers never see the table missing or partially built, and a failed build
+// leaves the old table in place.
 func (e *Engine) executeTable(ctx context.Context, path, sql string) (int64, error) {
-	tableName := pathToTableName(path)
-
-	// Drop existing table
-	_ = e.db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName))
+	tableName := e.tableName(path)
 
 	// Create schema if needed
-	parts := strings.Split(path, ".")
-	if len(parts) > 1 {
-		schema := parts[0]
+	if schema := tableSchema(tableName); schema != "" {
 		_ = e.db.Exec(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schema))
 	}
 
+	swapper, canSwap := e.db.(adapter.Swapper)
+	buildTable := tableName
+	if canSwap {
+		buildTable = stagingTable(tableName)
+	}
+
+	// Drop existing table
+	_ = e.db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", buildTable))
+
 	// Create new table
-	createSQL := fmt.Sprintf("CREATE TABLE %s AS %s", tableName, sql)
-	if err := e.db.Exec(ctx, createSQL); err != nil {
+	createSQL := fmt.Sprintf("CREATE TABLE %s AS %s", buildTable, sql)
+	if err := e.execMeasured(ctx, createSQL); err != nil {
+		if canSwap {
+			_ = e.db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", buildTable))
+		}
 		return 0, fmt.Errorf("failed to create table %s: %w", tableName, err)
 	}
 
+	if canSwap {
+		if err := swapper.SwapTable(ctx, buildTable, tableName); err != nil {
+			_ = e.db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", buildTable))
+			return 0, fmt.Errorf("failed to swap in table %s: %w", tableName, err)
+		}
+	}
+
 	// Get row count
 	rows, err := e.db.Query(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName))
 	if err != nil {
@@ -45,20 +63,26 @@ func (e *Engine) executeTable(ctx context.Context, path, sql string) (int64, err
 	return count, nil
 }
 
-// executeView creates or replaces a view.
+// executeView creates or replaces a view, atomically for adapters that
+// implement adapter.Swapper.
 func (e *Engine) executeView(ctx context.Context, path, sql string) (int64, error) {
-	tableName := pathToTableName(path)
-
-	// Drop existing view
-	_ = e.db.Exec(ctx, fmt.Sprintf("DROP VIEW IF EXISTS %s", tableName))
+	tableName := e.tableName(path)
 
 	// Create schema if needed
-	parts := strings.Split(path, ".")
-	if len(parts) > 1 {
-		schema := parts[0]
+	if schema := tableSchema(tableName); schema != "" {
 		_ = e.db.Exec(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", schema))
 	}
 
+	if swapper, ok := e.db.(adapter.Swapper); ok {
+		if err := swapper.ReplaceView(ctx, tableName, sql); err != nil {
+			return 0, fmt.Errorf("failed to create view %s: %w", tableName, err)
+		}
+		return 0, nil
+	}
+
+	// Drop existing view
+	_ = e.db.Exec(ctx, fmt.Sprintf("DROP VIEW IF EXISTS %s", tableName))
+
 	// Create new view
 	createSQL := fmt.Sprintf("CREATE VIEW %s AS %s", tableName, sql)
 	if err := e.db.Exec(ctx, createSQL); err != nil {
@@ -68,21 +92,27 @@ func (e *Engine) executeView(ctx context.Context, path, sql string) (int64, erro
 	return 0, nil // Views don't affect rows
 }
 
-// executeIncremental handles incremental model execution.
-func (e *Engine) executeIncremental(ctx context.Context, m *core.Model, _ *core.PersistedModel, sql string) (int64, error) {
-	tableName := pathToTableName(m.Path)
+// executeIncremental handles incremental model execution. With fullRefresh,
+// an existing table is replaced by a build from the model's full query.
+func (e *Engine) executeIncremental(ctx context.Context, m *core.Model, model *core.PersistedModel, sql, runID string, fullRefresh bool) (int64, error) {
+	tableName := e.tableName(m.Path)
 
 	// Check if table exists
 	_, err := e.db.GetTableMetadata(ctx, tableName)
 	tableExists := err == nil
 
-	if !tableExists {
-		// First run - create table with full data
-		return e.executeTable(ctx, m.Path, sql)
+	if !tableExists || fullRefresh {
+		// First run or full refresh - create table with full data
+		if tableExists {
+			e.logger.Debug("rebuilding incremental model", "model", m.Path)
+		}
+		return e.executeTable(ctx, m.Path, withAuditColumns(m, model, e.withSample(m, e.withMasking(m, sql)), runID))
 	}
+	modelBuildFrom(ctx).setMode(core.BuildModeIncremental)
 
 	// Table exists - check if we have incremental SQL
 	incrementalSQL := sql
+	conditional := false
 	if len(m.Conditionals) > 0 {
 		// Apply incremental conditional
 		for _, cond := range m.Conditionals {
@@ -91,43 +121,43 @@ func (e *Engine) executeIncremental(ctx context.Context, m *core.Model, _ *core.
 				condContent := cond.Content
 				condContent = strings.ReplaceAll(condContent, "{{ this }}", tableName)
 				incrementalSQL = sql + "\n" + condContent
+				conditional = true
 				break
 			}
 		}
 	}
+	// Partitioned models without their own conditional rebuild the latest
+	// partition onwards
+	if m.PartitionBy != nil && !conditional {
+		incrementalSQL = withRowFilter(sql, partitionPredicate(m.PartitionBy, tableName))
+	}
+	incrementalSQL = withAuditColumns(m, model, e.withSample(m, e.withMasking(m, incrementalSQL)), runID)
 
 	// Insert new rows using unique key for deduplication
 	if m.UniqueKey != "" {
 		// Merge/upsert pattern
-		tempTable := tableName + "_temp"
+		tempTable := incrementalTempTable(tableName)
 
 		// Create temp table with new data
 		_ = e.db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", tempTable))
 		createTempSQL := fmt.Sprintf("CREATE TABLE %s AS %s", tempTable, incrementalSQL)
-		if err := e.db.Exec(ctx, createTempSQL); err != nil {
+		if err := e.execMeasured(ctx, createTempSQL); err != nil {
 			return 0, fmt.Errorf("failed to create temp table: %w", err)
 		}
 
 		// Delete matching rows from target
 		deleteSQL := fmt.Sprintf("DELETE FROM %s WHERE %s IN (SELECT %s FROM %s)",
 			tableName, m.UniqueKey, m.UniqueKey, tempTable)
-		_ = e.db.Exec(ctx, deleteSQL)
+		_ = e.execMeasured(ctx, deleteSQL)
 
 		// Insert all rows from temp
 		insertSQL := fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", tableName, tempTable)
-		if err := e.db.Exec(ctx, insertSQL); err != nil {
+		if err := e.execMeasured(ctx, insertSQL); err != nil {
 			return 0, fmt.Errorf("failed to insert incremental rows: %w", err)
 		}
 
 		// Get count from temp table
-		rows, err := e.db.Query(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", tempTable))
-		var count int64
-		if err == nil {
-			defer func() { _ = rows.Close() }()
-			if rows.Next() {
-				_ = rows.Scan(&count)
-			}
-		}
+		count, _ := e.countRows(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", tempTable))
 
 		// Clean up temp table
 		_ = e.db.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", tempTable))
@@ -135,11 +165,59 @@ func (e *Engine) executeIncremental(ctx context.Context, m *core.Model, _ *core.
 		return count, nil
 	}
 
-	// No unique key - simple append
+	// No unique key - partitioned models overwrite the partitions they build
+	if m.PartitionBy != nil {
+		return e.overwritePartitions(ctx, m.PartitionBy, tableName, incrementalSQL)
+	}
+
+	// No unique key or partitions - simple append
 	insertSQL := fmt.Sprintf("INSERT INTO %s %s", tableName, incrementalSQL)
-	if err := e.db.Exec(ctx, insertSQL); err != nil {
+	if err := e.execMeasured(ctx, insertSQL); err != nil {
 		return 0, fmt.Errorf("failed to insert rows: %w", err)
 	}
 
 	return 0, nil
 }
+
+// incrementalTempTable returns the table holding an incremental model's new
+// rows while they are merged into the target table.
+func incrementalTempTable(tableName string) string {
+	return tableName + "_temp"
+}
+
+// stagingTable returns the table a model is built into before it is swapped
+// in to replace the model's table.
+func stagingTable(tableName string) string {
+	return tableName + "__staging"
+}
+
+// execMeasured executes a statement of a model build. When the adapter reports
+// costs, the statement's cost is added to the cost of the model being built.
+func (e *Engine) execMeasured(ctx context.Context, sql string) error {
+	reporter, ok := e.db.(adapter.CostReporter)
+	if !ok {
+		return e.db.Exec(ctx, sql)
+	}
+
+	cost, err := reporter.ExecWithCost(ctx, sql)
+	modelBuildFrom(ctx).addCost(cost)
+	return err
+}
+
+// withAuditColumns wraps a model's SQL so its rows carry the audit columns
+// (core.AuditColumnNames). The SQL is returned unchanged for models without
+// audit_columns.
+func withAuditColumns(m *core.Model, model *core.PersistedModel, sql, runID string) string {
+	if !m.AuditColumns {
+		return sql
+	}
+
+	var contentHash string
+	if model != nil {
+		contentHash = model.ContentHash
+	}
+	sql = strings.TrimSuffix(strings.TrimSpace(sql), ";")
+
+	return fmt.Sprintf("SELECT *, CURRENT_TIMESTAMP AS %s, '%s' AS %s, '%s' AS %s FROM (\n%s\n) AS leapsql_model",
+		core.AuditColumnLoadedAt, runID, core.AuditColumnRunID, contentHash, core.AuditColumnModelHash, sql)
+}
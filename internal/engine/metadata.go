@@ -0,0 +1,87 @@
+package engine
+
+// metadata.go - Catalog metadata of models for data catalog sinks
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/metadata"
+)
+
+// CatalogDatasets returns the catalog metadata of models, sorted by model
+// path: the tables they are built as with their output schema (see
+// OutputSchema), ownership, tags, and table and column lineage. Models whose
+// columns are unknown get no fields. The owner of a model without one is the
+// owner of its group.
+func (e *Engine) CatalogDatasets(ctx context.Context, paths []string) ([]metadata.Dataset, error) {
+	sorted := slices.Clone(paths)
+	sort.Strings(sorted)
+
+	datasets := make([]metadata.Dataset, 0, len(sorted))
+	for _, path := range sorted {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		m, ok := e.models[path]
+		if !ok {
+			return nil, fmt.Errorf("model not found: %s", path)
+		}
+
+		d := metadata.Dataset{
+			Model:        m.Path,
+			Name:         e.tableName(path),
+			Description:  m.Description,
+			Materialized: m.Materialized,
+			Owner:        m.Owner,
+			PartitionBy:  m.PartitionBy,
+			Tags:         m.Tags,
+		}
+		if d.Owner == "" {
+			if g, ok := e.GetGroup(m.Group); ok {
+				d.Owner = g.Owner
+			}
+		}
+
+		if s, err := e.OutputSchema(ctx, path); err == nil {
+			for _, col := range s.Columns {
+				d.Fields = append(d.Fields, metadata.Field{Name: col.Name, Type: col.Type, Nullable: col.Nullable})
+			}
+		}
+
+		for _, parent := range e.graph.GetParents(path) {
+			d.Upstreams = append(d.Upstreams, e.tableName(parent))
+		}
+		_, sources := e.registry.ResolveDependenciesFrom(m.Project, m.Sources)
+		for _, source := range sources {
+			if !slices.Contains(m.Files, source) {
+				d.Upstreams = append(d.Upstreams, source)
+			}
+		}
+		sort.Strings(d.Upstreams)
+
+		for _, col := range m.Columns {
+			cl := metadata.ColumnLineage{Column: col.Name, Transform: strings.ToUpper(col.Function)}
+			if cl.Transform == "" && col.TransformType != "" {
+				cl.Transform = string(col.TransformType)
+			}
+			for _, src := range col.Sources {
+				if src.Table == "" || slices.Contains(m.Files, src.Table) {
+					continue
+				}
+				table := src.Table
+				if modelPath, ok := e.registry.ResolveFrom(m.Project, src.Table); ok {
+					table = e.tableName(modelPath)
+				}
+				cl.Sources = append(cl.Sources, metadata.ColumnRef{Table: table, Column: src.Column})
+			}
+			d.ColumnLineage = append(d.ColumnLineage, cl)
+		}
+
+		datasets = append(datasets, d)
+	}
+	return datasets, nil
+}
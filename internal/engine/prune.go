@@ -0,0 +1,132 @@
+package engine
+
+// prune.go - Patches removing the columns no downstream model reads
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/parser"
+)
+
+// ColumnPrune is the patch removing the unused columns of one model.
+type ColumnPrune struct {
+	// Model is the model path
+	Model string
+	// FilePath is the path of the model's SQL file
+	FilePath string
+	// Columns are the unused columns the patch removes
+	Columns []string
+	// Kept are the unused columns that cannot be removed from the SQL, e.g.
+	// because they come from SELECT * or the query refers to them
+	Kept []string
+	// TotalColumns is the number of columns of the model
+	TotalColumns int
+	// SQL is the rendered SQL the fix applies to
+	SQL string
+	// Fix removes Columns from SQL
+	Fix lint.Fix
+	// Savings estimates what the patch saves per run
+	Savings PruneSavings
+}
+
+// PruneSavings estimates what removing columns saves per run of a model,
+// from its last successful run, taking every column to cost the same.
+type PruneSavings struct {
+	// Values is the number of values no longer written: rows times columns
+	Values int64
+	// BytesScanned is the share of the bytes read by the model's queries
+	BytesScanned int64
+	// ExecutionMS is the share of the model's execution time
+	ExecutionMS int64
+}
+
+// PlanColumnPrunes builds the patches removing the unused columns of
+// models, keyed by model path (see projectrules.OrphanedColumns). Models that
+// fail to render or parse are skipped. Patches are sorted by model path;
+// models none of whose unused columns can be removed get a patch with no
+// edits listing them as kept.
+func (e *Engine) PlanColumnPrunes(ctx context.Context, unused map[string][]string) ([]ColumnPrune, error) {
+	d := e.ModelDialect()
+	if d == nil {
+		return nil, fmt.Errorf("dialect not available")
+	}
+
+	var prunes []ColumnPrune
+	for path, columns := range unused {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		m, ok := e.models[path]
+		if !ok {
+			continue
+		}
+
+		rendered, err := e.renderSQL(m)
+		if err != nil {
+			continue
+		}
+		stmt, err := parser.ParseWithDialect(rendered, d)
+		if err != nil {
+			continue
+		}
+
+		fix, removed := lintsql.RemoveColumns(stmt, columns)
+		prune := ColumnPrune{
+			Model:        m.Path,
+			FilePath:     m.FilePath,
+			Columns:      removed,
+			Kept:         withoutColumns(columns, removed),
+			TotalColumns: len(m.Columns),
+			SQL:          rendered,
+			Fix:          fix,
+		}
+		prune.Savings = e.pruneSavings(path, len(removed), prune.TotalColumns)
+		prunes = append(prunes, prune)
+	}
+
+	sort.Slice(prunes, func(i, j int) bool {
+		return prunes[i].Model < prunes[j].Model
+	})
+	return prunes, nil
+}
+
+// pruneSavings estimates the savings of removing n of a model's total
+// columns from its last successful run.
+func (e *Engine) pruneSavings(path string, n, total int) PruneSavings {
+	if n == 0 || total == 0 || e.store == nil {
+		return PruneSavings{}
+	}
+	persisted, err := e.store.GetModelByPath(path)
+	if err != nil || persisted == nil {
+		return PruneSavings{}
+	}
+	last, err := e.store.GetLatestSuccessfulModelRun(persisted.ID)
+	if err != nil || last == nil {
+		return PruneSavings{}
+	}
+	return PruneSavings{
+		Values:       last.RowsAffected * int64(n),
+		BytesScanned: last.BytesScanned * int64(n) / int64(total),
+		ExecutionMS:  last.ExecutionMS * int64(n) / int64(total),
+	}
+}
+
+// withoutColumns returns the columns not in removed, ignoring case.
+func withoutColumns(columns, removed []string) []string {
+	drop := make(map[string]bool, len(removed))
+	for _, c := range removed {
+		drop[strings.ToLower(c)] = true
+	}
+	var kept []string
+	for _, c := range columns {
+		if !drop[strings.ToLower(c)] {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,155 @@
+package engine
+
+// query.go - Ad-hoc queries against the target database with cached results
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// queryCacheDir is the directory, next to the state database, holding the
+// cached results of ad-hoc queries.
+const queryCacheDir = "query_cache"
+
+// QueryOptions configures an ad-hoc query.
+type QueryOptions struct {
+	// CacheTTL is how long results are reused for the same query against the
+	// same target. Zero disables the cache.
+	CacheTTL time.Duration
+}
+
+// QueryResult is the result of an ad-hoc query.
+type QueryResult struct {
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+	// CachedAt is when a result read from the cache was queried (zero for
+	// results queried now)
+	CachedAt time.Time `json:"cached_at"`
+	// Truncated tells that a preview's query returned more rows than its
+	// limit
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Query runs an ad-hoc query against the target database. With a cache TTL,
+// results are cached next to the state database, keyed by the target and the
+// query, and reused until they expire. Queries against in-memory databases
+// are never cached.
+func (e *Engine) Query(ctx context.Context, query string, opts QueryOptions) (*QueryResult, error) {
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+
+	var cachePath string
+	if target := e.cacheTarget(); opts.CacheTTL > 0 && target != "" && e.statePath != "" {
+		sum := sha256.Sum256([]byte(target + "\x00" + query))
+		cachePath = filepath.Join(filepath.Dir(e.statePath), queryCacheDir, hex.EncodeToString(sum[:])+".json")
+		if result := readQueryCache(cachePath, opts.CacheTTL); result != nil {
+			return result, nil
+		}
+	}
+
+	if err := e.ensureDBConnected(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := e.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &QueryResult{Columns: cols, Rows: [][]any{}}
+	for rows.Next() {
+		values := make([]any, len(cols))
+		dest := make([]any, len(cols))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		for i, v := range values {
+			if b, ok := v.([]byte); ok {
+				values[i] = string(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if cachePath != "" {
+		if err := writeQueryCache(cachePath, result); err != nil {
+			e.logger.Debug("failed to cache query result", "error", err)
+		}
+	}
+	return result, nil
+}
+
+// MaxPreviewRows caps the rows a model preview returns, whatever its limit.
+const MaxPreviewRows = 10000
+
+// PreviewModel runs a model's rendered query against the target database
+// without building the model, returning at most limit rows (capped at
+// MaxPreviewRows). The result is marked truncated if the query has more
+// rows. The tables the model reads must exist; cancelling ctx cancels the
+// query.
+func (e *Engine) PreviewModel(ctx context.Context, path string, limit int) (*QueryResult, error) {
+	sql, err := e.RenderModel(path)
+	if err != nil {
+		return nil, err
+	}
+	limit = min(max(limit, 1), MaxPreviewRows)
+
+	// One more row than the limit tells whether there are more
+	sql = strings.TrimSuffix(strings.TrimSpace(sql), ";")
+	result, err := e.Query(ctx, fmt.Sprintf("SELECT * FROM (\n%s\n) AS preview LIMIT %d", sql, limit+1), QueryOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Rows) > limit {
+		result.Rows = result.Rows[:limit]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+// readQueryCache returns a cached query result, or nil if there is none or it
+// is older than ttl. Numbers are read as json.Number to keep integers exact.
+func readQueryCache(path string, ttl time.Duration) *QueryResult {
+	data, err := os.ReadFile(path) //nolint:gosec // path is derived from a hash
+	if err != nil {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var result QueryResult
+	if err := dec.Decode(&result); err != nil || time.Since(result.CachedAt) > ttl {
+		return nil
+	}
+	return &result
+}
+
+// writeQueryCache saves a query result, stamped with the current time.
+func writeQueryCache(path string, result *QueryResult) error {
+	cached := *result
+	cached.CachedAt = time.Now()
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return fmt.Errorf("failed to encode query result: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
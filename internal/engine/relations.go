@@ -0,0 +1,60 @@
+package engine
+
+// relations.go - Schema names generated by the generate_schema_name hook
+
+import (
+	"fmt"
+
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+)
+
+// Relation returns the table name a model is built as on the target, e.g.
+// "analytics.customers" for a model in staging/ with schema: analytics.
+func (e *Engine) Relation(path string) string {
+	return e.tableName(path)
+}
+
+// resolveRelations names the tables of the discovered models after the
+// schemas a generate_schema_name macro generates for them, if a macro defines
+// one. The hook is called with each model's custom schema (see customSchema),
+// the target and the environment; models it fails for keep their default
+// table names and are reported.
+func (e *Engine) resolveRelations(result *DiscoveryResult) {
+	e.relations = nil
+	if e.macroRegistry == nil {
+		return
+	}
+
+	target := e.target
+	if target == nil {
+		target = &starctx.TargetInfo{Type: e.dbConfig.Type, Schema: e.dbConfig.Schema, Database: e.dbConfig.Database}
+	}
+	namer, err := e.macroRegistry.SchemaNamer(target.ToStarlark(), e.environment)
+	if err != nil {
+		result.Errors = append(result.Errors, DiscoveryError{Path: e.macrosDir, Type: "validation", Message: err.Error()})
+		return
+	}
+	if namer == nil {
+		return
+	}
+
+	relations := make(map[string]string, len(e.models))
+	for path, m := range e.models {
+		schema, err := namer.SchemaName(customSchema(m))
+		if err != nil {
+			result.Errors = append(result.Errors, DiscoveryError{
+				Path:    m.FilePath,
+				Type:    "validation",
+				Message: fmt.Sprintf("model %s: %v", path, err),
+			})
+			continue
+		}
+
+		table := unqualifiedName(pathToTableName(path))
+		if schema != "" {
+			table = schema + "." + table
+		}
+		relations[path] = e.qualifyDatabase(m, table)
+	}
+	e.relations = relations
+}
@@ -0,0 +1,161 @@
+package engine
+
+// rename.go - Patches renaming a model column and its downstream references
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/parser"
+)
+
+// ColumnRename is the patch set renaming a column of a model and the
+// references to it in the models reading the model.
+type ColumnRename struct {
+	// Model is the path of the model whose column is renamed
+	Model string
+	// Column is the column's current name
+	Column string
+	// NewName is the column's new name
+	NewName string
+	// Patches are the patches of the model, then of the models reading it,
+	// sorted by model path
+	Patches []RenamePatch
+}
+
+// RenamePatch is the patch of one model of a column rename.
+type RenamePatch struct {
+	// Model is the model path
+	Model string
+	// FilePath is the path of the model's SQL file
+	FilePath string
+	// SQL is the rendered SQL the fix applies to
+	SQL string
+	// Fix renames the column, or the references to it, in SQL
+	Fix lint.Fix
+	// Notes are the references the patch leaves in place, and why
+	Notes []string
+}
+
+// PlanColumnRename builds the patches renaming a model's column to name:
+// the select item defining it, and the references to it in the models
+// reading the model directly. Those keep their own output columns: a
+// reference selected without an alias is given the column's old name as
+// alias. Unqualified references are only renamed in models reading no other
+// model with the column. Models that fail to render or parse get a patch
+// with no edits saying so.
+func (e *Engine) PlanColumnRename(model, column, name string) (*ColumnRename, error) {
+	d := e.ModelDialect()
+	if d == nil {
+		return nil, fmt.Errorf("dialect not available")
+	}
+	if !isPlainIdentifier(name) {
+		return nil, fmt.Errorf("invalid column name %q: use letters, digits and underscores", name)
+	}
+	m, ok := e.models[model]
+	if !ok {
+		return nil, fmt.Errorf("model not found: %s", model)
+	}
+	if len(m.Columns) > 0 && !hasColumn(m.Columns, column) {
+		return nil, fmt.Errorf("model %s has no column %s", model, column)
+	}
+	if hasColumn(m.Columns, name) {
+		return nil, fmt.Errorf("model %s already has a column %s", model, name)
+	}
+
+	rendered, stmt, err := e.parseRendered(m, d)
+	if err != nil {
+		return nil, err
+	}
+	fix, err := lintsql.RenameOutputColumn(stmt, rendered, parser.TokenizeWithDialect(rendered, d), column, name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot rename %s in %s: %w", column, model, err)
+	}
+	rename := &ColumnRename{
+		Model:   model,
+		Column:  column,
+		NewName: name,
+		Patches: []RenamePatch{{Model: model, FilePath: m.FilePath, SQL: rendered, Fix: fix}},
+	}
+
+	children := e.graph.GetChildren(model)
+	sort.Strings(children)
+	for _, child := range children {
+		cm, ok := e.models[child]
+		if !ok {
+			continue
+		}
+		patch := RenamePatch{Model: child, FilePath: cm.FilePath}
+		rendered, stmt, err := e.parseRendered(cm, d)
+		if err != nil {
+			patch.Notes = []string{fmt.Sprintf("not patched: %v", err)}
+			rename.Patches = append(rename.Patches, patch)
+			continue
+		}
+
+		// Unqualified references are ambiguous if another model read has
+		// the column
+		unqualified := true
+		for _, parent := range e.graph.GetParents(child) {
+			if pm, ok := e.models[parent]; ok && parent != model && hasColumn(pm.Columns, column) {
+				unqualified = false
+			}
+		}
+		isTable := func(t *core.TableName) bool {
+			path, ok := e.registry.ResolveFrom(cm.Project, tableNameOf(t))
+			return ok && path == model
+		}
+
+		patch.SQL = rendered
+		patch.Fix, patch.Notes = lintsql.RenameColumnRefs(stmt, rendered, parser.TokenizeWithDialect(rendered, d), isTable, column, name, unqualified)
+		if len(patch.Fix.TextEdits) > 0 || len(patch.Notes) > 0 {
+			rename.Patches = append(rename.Patches, patch)
+		}
+	}
+	return rename, nil
+}
+
+// parseRendered renders a model's SQL and parses it.
+func (e *Engine) parseRendered(m *core.Model, d *core.Dialect) (string, *core.SelectStmt, error) {
+	rendered, err := e.renderSQL(m)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to render %s: %w", m.Path, err)
+	}
+	stmt, err := parser.ParseWithDialect(rendered, d)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse %s: %w", m.Path, err)
+	}
+	return rendered, stmt, nil
+}
+
+// tableNameOf returns the qualified name of a table reference.
+func tableNameOf(t *core.TableName) string {
+	var parts []string
+	for _, p := range []string{t.Catalog, t.Schema, t.Name} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// isPlainIdentifier reports whether name is an identifier that needs no
+// quoting.
+func isPlainIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package engine
+
+// retention.go - Deleting rows older than the retention period of models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// retentionPredicate returns the condition selecting the rows a retention
+// policy deletes: rows whose column is before the cutoff. When the policy's
+// column is the model's partition column, only partitions wholly before the
+// cutoff are deleted, so a partition is never left partly filled.
+func retentionPredicate(m *core.Model, cutoff time.Time) string {
+	bound := "TIMESTAMP '" + cutoff.UTC().Format("2006-01-02 15:04:05") + "'"
+	if p := m.PartitionBy; p != nil && strings.EqualFold(p.Column, m.Retention.Column) {
+		return fmt.Sprintf("%s < date_trunc('%s', %s)", p.Expr(), p.Grain, bound)
+	}
+	return fmt.Sprintf("%s < %s", m.Retention.Column, bound)
+}
+
+// applyRetention deletes the rows of a built model's table that are older
+// than its retention period, logs how many it reclaimed and returns their
+// number. Models are partitioned by their partition_by expression rather
+// than by the warehouse, so old partitions are dropped by deleting their
+// rows, which every adapter supports; no adapter drops warehouse partitions
+// (e.g. ALTER TABLE ... DROP PARTITION) instead.
+func (e *Engine) applyRetention(ctx context.Context, m *core.Model) (int64, error) {
+	if m.Retention == nil || m.Materialized == core.MaterializationView || m.Materialized == core.MaterializationExternal {
+		return 0, nil
+	}
+
+	tableName := e.tableName(m.Path)
+	predicate := retentionPredicate(m, m.Retention.Keep.Cutoff(time.Now()))
+	count, err := e.countRows(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", tableName, predicate))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count expired rows of %s: %w", tableName, err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	if err := e.execMeasured(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", tableName, predicate)); err != nil {
+		return 0, fmt.Errorf("failed to delete expired rows of %s: %w", tableName, err)
+	}
+	e.logger.Info("retention deleted expired rows", "model", m.Path, "rows", count, "keep", m.Retention.Keep.String())
+	return count, nil
+}
+
+// retainUnchanged applies the retention of a model skipped because its inputs
+// are unchanged: rows still age past its retention period between builds. The
+// model's table is as its last build left it, so a failure is logged rather
+// than failing the run. It reports whether rows were deleted, in which case
+// the run changed the table and the models downstream of it must rebuild.
+func (e *Engine) retainUnchanged(ctx context.Context, runID, target string, m *core.Model) bool {
+	if m.Retention == nil {
+		return false
+	}
+	release, err := e.acquireBuildSlots(ctx, target, m)
+	if err != nil {
+		return false
+	}
+	defer release()
+	deleted, err := e.applyRetention(e.withQueryComment(ctx, runID, m.Path), m)
+	if err != nil {
+		e.logger.Warn("retention failed", "model", m.Path, "error", err)
+	}
+	return deleted > 0
+}
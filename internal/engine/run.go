@@ -6,9 +6,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/leapstack-labs/leapsql/internal/dag"
+	"github.com/leapstack-labs/leapsql/pkg/adapter"
 	"github.com/leapstack-labs/leapsql/pkg/core"
 )
 
@@ -18,6 +21,8 @@ type preparedModel struct {
 	persisted *core.PersistedModel
 	modelRun  *core.ModelRun
 	sql       string
+	pre       []string // Rendered statements run before the model's build
+	post      []string // Rendered statements run after the model's build
 	renderMS  int64
 }
 
@@ -32,6 +37,12 @@ func (e *Engine) Run(ctx context.Context, env string) (*core.Run, error) {
 		return nil, err
 	}
 
+	unlock, _, err := e.lockRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	// Create a new run
 	run, err := e.store.CreateRun(env)
 	if err != nil {
@@ -45,8 +56,8 @@ func (e *Engine) Run(ctx context.Context, env string) (*core.Run, error) {
 		observer.OnRunStarted(run)
 	}
 
-	// Get topological order
-	sorted, err := e.graph.TopologicalSort()
+	// Get build order
+	sorted, err := e.scheduleOrder(e.graph)
 	if err != nil {
 		_ = e.store.CompleteRun(run.ID, core.RunStatusFailed, fmt.Sprintf("dependency sort failed: %v", err))
 		return run, err
@@ -59,10 +70,7 @@ func (e *Engine) Run(ctx context.Context, env string) (*core.Run, error) {
 
 	if len(renderErrors) > 0 {
 		// Mark prepared models as skipped
-		for _, p := range prepared {
-			_ = e.store.UpdateModelRun(p.modelRun.ID, core.ModelRunStatusSkipped, 0,
-				"run aborted: other models failed to render", p.renderMS, 0)
-		}
+		e.skipModels(run.ID, prepared, core.SkipReasonRenderFailed, "", "run aborted: other models failed to render")
 
 		errMsg := fmt.Sprintf("%d model(s) failed to render", len(renderErrors))
 		_ = e.store.CompleteRun(run.ID, core.RunStatusFailed, errMsg)
@@ -78,10 +86,14 @@ func (e *Engine) Run(ctx context.Context, env string) (*core.Run, error) {
 	runErr := e.executeModels(ctx, run.ID, prepared)
 
 	// Complete run
-	if runErr != nil {
+	switch {
+	case errors.Is(runErr, context.Canceled) || errors.Is(runErr, context.DeadlineExceeded):
+		e.logger.Info("run cancelled", "run_id", run.ID, "error", runErr.Error())
+		_ = e.store.CompleteRun(run.ID, core.RunStatusCancelled, runErr.Error())
+	case runErr != nil:
 		e.logger.Info("run failed", "run_id", run.ID, "error", runErr.Error())
 		_ = e.store.CompleteRun(run.ID, core.RunStatusFailed, runErr.Error())
-	} else {
+	default:
 		e.logger.Info("run completed", "run_id", run.ID)
 		_ = e.store.CompleteRun(run.ID, core.RunStatusCompleted, "")
 		_ = e.store.DeleteOldSnapshots(5)
@@ -99,7 +111,8 @@ func (e *Engine) Run(ctx context.Context, env string) (*core.Run, error) {
 
 // RunSelected executes only the specified models and their downstream dependents.
 // Uses a two-phase approach: validate all templates, then execute.
-// Upstream dependencies must already exist in the database.
+// Upstream dependencies must already exist in the database, unless deferral
+// is enabled (see SetDefer) and they have been built in production.
 func (e *Engine) RunSelected(ctx context.Context, env string, modelPaths []string, includeDownstream bool) (*core.Run, error) {
 	e.logger.Info("starting selected run", "environment", env, "models", modelPaths, "include_downstream", includeDownstream)
 
@@ -108,6 +121,12 @@ func (e *Engine) RunSelected(ctx context.Context, env string, modelPaths []strin
 		return nil, err
 	}
 
+	unlock, _, err := e.lockRuns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
 	var affected []string
 	if includeDownstream {
 		// Get affected nodes (selected + downstream)
@@ -117,6 +136,20 @@ func (e *Engine) RunSelected(ctx context.Context, env string, modelPaths []strin
 		affected = modelPaths
 	}
 
+	// Read unbuilt upstream models from production
+	e.deferred = nil
+	if e.deferTo != nil {
+		deferred, cleanup, err := e.deferUpstream(ctx, affected)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		e.deferred = deferred
+		if len(deferred) > 0 {
+			e.logger.Info("deferring upstream models to production", "environment", e.deferTo.Environment, "models", deferred)
+		}
+	}
+
 	// Create subgraph with affected nodes
 	subgraph := e.graph.Subgraph(affected)
 
@@ -133,8 +166,8 @@ func (e *Engine) RunSelected(ctx context.Context, env string, modelPaths []strin
 		observer.OnRunStarted(run)
 	}
 
-	// Get topological order of subgraph
-	sorted, err := subgraph.TopologicalSort()
+	// Get build order of subgraph
+	sorted, err := e.scheduleOrder(subgraph)
 	if err != nil {
 		_ = e.store.CompleteRun(run.ID, core.RunStatusFailed, fmt.Sprintf("dependency sort failed: %v", err))
 		return run, err
@@ -147,10 +180,7 @@ func (e *Engine) RunSelected(ctx context.Context, env string, modelPaths []strin
 
 	if len(renderErrors) > 0 {
 		// Mark prepared models as skipped
-		for _, p := range prepared {
-			_ = e.store.UpdateModelRun(p.modelRun.ID, core.ModelRunStatusSkipped, 0,
-				"run aborted: other models failed to render", p.renderMS, 0)
-		}
+		e.skipModels(run.ID, prepared, core.SkipReasonRenderFailed, "", "run aborted: other models failed to render")
 
 		errMsg := fmt.Sprintf("%d model(s) failed to render", len(renderErrors))
 		_ = e.store.CompleteRun(run.ID, core.RunStatusFailed, errMsg)
@@ -166,10 +196,14 @@ func (e *Engine) RunSelected(ctx context.Context, env string, modelPaths []strin
 	runErr := e.executeModels(ctx, run.ID, prepared)
 
 	// Complete run
-	if runErr != nil {
+	switch {
+	case errors.Is(runErr, context.Canceled) || errors.Is(runErr, context.DeadlineExceeded):
+		e.logger.Info("run cancelled", "run_id", run.ID, "error", runErr.Error())
+		_ = e.store.CompleteRun(run.ID, core.RunStatusCancelled, runErr.Error())
+	case runErr != nil:
 		e.logger.Info("run failed", "run_id", run.ID, "error", runErr.Error())
 		_ = e.store.CompleteRun(run.ID, core.RunStatusFailed, runErr.Error())
-	} else {
+	default:
 		e.logger.Info("run completed", "run_id", run.ID)
 		_ = e.store.CompleteRun(run.ID, core.RunStatusCompleted, "")
 		_ = e.store.DeleteOldSnapshots(5)
@@ -211,9 +245,11 @@ func (e *Engine) validateAndPrepareModels(runID string, sorted []*dag.Node) ([]p
 
 		// Create pending ModelRun
 		modelRun := &core.ModelRun{
-			RunID:   runID,
-			ModelID: persisted.ID,
-			Status:  core.ModelRunStatusPending,
+			RunID:       runID,
+			ModelID:     persisted.ID,
+			Status:      core.ModelRunStatusPending,
+			RowFilter:   strings.TrimSpace(m.Where),
+			ContentHash: persisted.ContentHash,
 		}
 		if err := e.store.RecordModelRun(modelRun); err != nil {
 			renderErrors = append(renderErrors, fmt.Errorf("%s: failed to record model run: %w", m.Path, err))
@@ -223,6 +259,10 @@ func (e *Engine) validateAndPrepareModels(runID string, sorted []*dag.Node) ([]p
 		// Render template with timing
 		start := time.Now()
 		sql, err := e.buildSQL(m, persisted)
+		var pre, post []string
+		if err == nil {
+			pre, post, err = e.buildStatements(m)
+		}
 		renderMS := time.Since(start).Milliseconds()
 
 		if err != nil {
@@ -238,6 +278,8 @@ func (e *Engine) validateAndPrepareModels(runID string, sorted []*dag.Node) ([]p
 			persisted: persisted,
 			modelRun:  modelRun,
 			sql:       sql,
+			pre:       pre,
+			post:      post,
 			renderMS:  renderMS,
 		})
 	}
@@ -245,27 +287,155 @@ func (e *Engine) validateAndPrepareModels(runID string, sorted []*dag.Node) ([]p
 	return prepared, renderErrors
 }
 
-// executeModels executes all prepared models in order.
+// executeModels builds the prepared models, which are in dependency order,
+// up to the engine's threads at a time (see SetThreads). A model starts once
+// the models it depends on in the run are built or skipped; with one thread,
+// the models build in order. After a model fails no other model starts, and
+// the run stops once the builds running finish.
 func (e *Engine) executeModels(ctx context.Context, runID string, prepared []preparedModel) error {
 	observer := e.getObserver()
-
+	target := e.cacheTarget()
+	threads := max(e.threads, 1)
+	builtBy := make(map[string]string) // Model path -> run that built it
+	budgets := e.newGroupBudgets()
+	var timeouts []error // Builds stopped by their group's max_runtime
+	var failed []string  // Models whose failure stopped the run
+	var failures []error
+	var cancelled error
+
+	position := make(map[string]int, len(prepared))
 	for i, p := range prepared {
-		// Update to running
-		_ = e.store.UpdateModelRun(p.modelRun.ID, core.ModelRunStatusRunning, 0, "", p.renderMS, 0)
+		position[p.model.Path] = i
+	}
+	started := make([]bool, len(prepared))
+	settled := make([]bool, len(prepared)) // Built, failed or skipped
+	hashes := make([]string, len(prepared))
+	snapshots := make([][]core.SourceSnapshot, len(prepared))
+
+	// ready reports whether the models a model depends on in the run are settled
+	ready := func(i int) bool {
+		for _, parent := range e.graph.GetParents(prepared[i].model.Path) {
+			if j, ok := position[parent]; ok && !settled[j] {
+				return false
+			}
+		}
+		return true
+	}
 
-		// Notify observer of status change
-		if observer != nil {
-			p.modelRun.Status = core.ModelRunStatusRunning
-			observer.OnModelRunUpdated(runID, p.modelRun)
+	// Each build sends when it starts and when it ends
+	results := make(chan buildResult, 2*threads)
+	running := 0
+	for {
+		for i := 0; i < len(prepared) && running < threads && len(failed) == 0 && cancelled == nil; i++ {
+			if started[i] || !ready(i) {
+				continue
+			}
+			p := prepared[i]
+
+			// Stop starting models when the run is cancelled
+			if err := ctx.Err(); err != nil {
+				cancelled = err
+				break
+			}
+			started[i] = true
+
+			// Skip the rest of a group that exceeded its max_runtime, and the
+			// models downstream of it
+			if group, ok := budgets.exceeded(p.model); ok {
+				budgets.block(group, e.graph.GetAffectedNodes([]string{p.model.Path}))
+				e.skipModels(runID, []preparedModel{p}, core.SkipReasonGroupTimeout, budgets.tripped[group], budgets.message(group))
+				settled[i] = true
+				continue
+			}
+
+			var unknown []string
+			if target != "" {
+				for _, parent := range e.graph.GetParents(p.model.Path) {
+					if _, ok := builtBy[parent]; !ok {
+						builtBy[parent] = e.upstreamBuild(target, parent)
+					}
+				}
+				snapshots[i], unknown = e.sourceSnapshots(ctx, p.model)
+				hashes[i] = e.buildHash(target, p, builtBy, snapshots[i])
+			}
+
+			// Skip models whose build inputs are unchanged
+			if build := e.cachedBuild(ctx, target, hashes[i], p, unknown); build != nil {
+				msg := "cache hit: unchanged since run " + build.RunID
+				e.logger.Debug("model cache hit", "model", p.model.Path, "built_by", build.RunID)
+				e.skipModels(runID, []preparedModel{p}, core.SkipReasonCacheHit, "", msg)
+				builtBy[p.model.Path] = build.RunID
+				if e.retainUnchanged(ctx, runID, target, p.model) {
+					// Record the deletion as a build of this run, so the
+					// models downstream of the table rebuild
+					e.recordBuild(target, hashes[i], runID, p)
+					builtBy[p.model.Path] = runID
+				}
+				settled[i] = true
+				continue
+			}
+
+			// Skip models whose inputs and sources are unchanged (--skip-fresh)
+			if build := e.freshBuild(ctx, target, hashes[i], p, snapshots[i], unknown); build != nil {
+				msg := "fresh: sources unchanged since run " + build.RunID
+				e.logger.Debug("model fresh", "model", p.model.Path, "built_by", build.RunID)
+				e.skipModels(runID, []preparedModel{p}, core.SkipReasonFresh, "", msg)
+				builtBy[p.model.Path] = build.RunID
+				if e.retainUnchanged(ctx, runID, target, p.model) {
+					// Record the deletion as a build of this run, so the
+					// models downstream of the table rebuild
+					e.recordBuild(target, hashes[i], runID, p)
+					builtBy[p.model.Path] = runID
+				}
+				settled[i] = true
+				continue
+			}
+
+			timeout, limited := budgets.timeout(p.model)
+			running++
+			go e.buildModel(ctx, runID, target, i, p, timeout, limited, results)
+		}
+		if running == 0 {
+			break
 		}
 
-		// Execute
-		start := time.Now()
-		rowsAffected, err := e.executeModelWithSQL(ctx, p.model, p.persisted, p.sql)
-		executionMS := time.Since(start).Milliseconds()
+		r := <-results
+		p := prepared[r.index]
+		if r.started {
+			// Update to running
+			_ = e.store.UpdateModelRun(p.modelRun.ID, core.ModelRunStatusRunning, 0, "", p.renderMS, 0)
+
+			// Notify observer of status change
+			if observer != nil {
+				p.modelRun.Status = core.ModelRunStatusRunning
+				observer.OnModelRunUpdated(runID, p.modelRun)
+			}
+			continue
+		}
+		running--
+		settled[r.index] = true
+
+		// The run was cancelled while the build waited for its slots
+		if r.waitErr != nil {
+			e.skipModels(runID, []preparedModel{p}, core.SkipReasonCancelled, "", "skipped: run cancelled")
+			cancelled = r.waitErr
+			continue
+		}
+
+		budgets.spend(p.model, r.elapsed)
+		executionMS := r.elapsed.Milliseconds()
+		e.recordBuildCost(p.modelRun, r.build)
+		e.recordBuildMode(p.modelRun, r.build)
+
+		err := r.err
+		if r.timedOut {
+			// Not wrapped, so the run is recorded as failed rather than cancelled
+			err = fmt.Errorf("timed out: group %s exceeded its max_runtime of %s (%v)", p.model.Group, budgets.limits[p.model.Group], err)
+		}
 
 		if err != nil {
 			e.logger.Debug("model execution failed", "model", p.model.Path, "error", err)
+			e.forgetBuild(target, p)
 			_ = e.store.UpdateModelRun(p.modelRun.ID, core.ModelRunStatusFailed, 0, err.Error(), p.renderMS, executionMS)
 
 			// Notify observer of failure
@@ -276,53 +446,299 @@ func (e *Engine) executeModels(ctx context.Context, runID string, prepared []pre
 				observer.OnModelRunUpdated(runID, p.modelRun)
 			}
 
-			// Mark remaining models as skipped
-			for j := i + 1; j < len(prepared); j++ {
-				skipErr := fmt.Sprintf("skipped: upstream model %s failed", p.model.Path)
-				_ = e.store.UpdateModelRun(prepared[j].modelRun.ID, core.ModelRunStatusSkipped, 0,
-					skipErr, prepared[j].renderMS, 0)
-
-				// Notify observer of skipped model
-				if observer != nil {
-					prepared[j].modelRun.Status = core.ModelRunStatusSkipped
-					prepared[j].modelRun.Error = skipErr
-					observer.OnModelRunUpdated(runID, prepared[j].modelRun)
-				}
+			// A timeout only stops the model's group and its downstream
+			// models; the run fails once the other models are built
+			if r.timedOut {
+				budgets.block(p.model.Group, e.graph.GetAffectedNodes([]string{p.model.Path}))
+				timeouts = append(timeouts, fmt.Errorf("model %s %w", p.model.Path, err))
+				continue
 			}
 
-			return err
+			failed = append(failed, p.model.Path)
+			failures = append(failures, err)
+			continue
 		}
 
-		e.logger.Debug("model executed", "model", p.model.Path, "rows", rowsAffected, "exec_ms", executionMS)
-		_ = e.store.UpdateModelRun(p.modelRun.ID, core.ModelRunStatusSuccess, rowsAffected, "", p.renderMS, executionMS)
+		e.logger.Debug("model executed", "model", p.model.Path, "rows", r.rows, "exec_ms", executionMS)
+		_ = e.store.UpdateModelRun(p.modelRun.ID, core.ModelRunStatusSuccess, r.rows, "", p.renderMS, executionMS)
 		e.saveModelSnapshot(runID, p.model, p.persisted)
+		e.recordBuild(target, hashes[r.index], runID, p)
+		e.recordSourceSnapshots(target, p, snapshots[r.index])
+		builtBy[p.model.Path] = runID
 
 		// Notify observer of success
 		if observer != nil {
 			p.modelRun.Status = core.ModelRunStatusSuccess
-			p.modelRun.RowsAffected = rowsAffected
+			p.modelRun.RowsAffected = r.rows
 			p.modelRun.ExecutionMS = executionMS
 			observer.OnModelRunUpdated(runID, p.modelRun)
 		}
 	}
 
-	return nil
+	// Mark the models that did not start as skipped: the failed models'
+	// downstream models cannot run, and the run stops before the others
+	var rest []preparedModel
+	for i, p := range prepared {
+		if !started[i] {
+			rest = append(rest, p)
+		}
+	}
+	if len(failed) > 0 {
+		downstream := make(map[string]string) // Model path -> failed model upstream of it
+		for _, path := range failed {
+			for _, affected := range e.graph.GetAffectedNodes([]string{path}) {
+				if _, ok := downstream[affected]; !ok {
+					downstream[affected] = path
+				}
+			}
+		}
+		for _, p := range rest {
+			if upstream, ok := downstream[p.model.Path]; ok {
+				e.skipModels(runID, []preparedModel{p}, core.SkipReasonUpstreamFailed, upstream,
+					fmt.Sprintf("skipped: upstream model %s failed", upstream))
+			} else {
+				e.skipModels(runID, []preparedModel{p}, core.SkipReasonRunStopped, failed[0],
+					fmt.Sprintf("skipped: run stopped after model %s failed", failed[0]))
+			}
+		}
+	} else if cancelled != nil {
+		e.skipModels(runID, rest, core.SkipReasonCancelled, "", "skipped: run cancelled")
+		failures = append(failures, cancelled)
+	}
+
+	return errors.Join(append(timeouts, failures...)...)
+}
+
+// buildResult is sent by a model build when it starts, and when it ends.
+type buildResult struct {
+	index    int
+	started  bool
+	build    *modelBuild
+	rows     int64
+	elapsed  time.Duration
+	timedOut bool  // Stopped by its group's max_runtime
+	waitErr  error // The run was cancelled while it waited for its slots
+	err      error
+}
+
+// buildModel builds a prepared model once it gets slots of the target's and
+// its resource class limits, in at most timeout when limited by its group's
+// max_runtime, and sends its result.
+func (e *Engine) buildModel(ctx context.Context, runID, target string, index int, p preparedModel, timeout time.Duration, limited bool, results chan<- buildResult) {
+	// Wait for the target's and the model's resource class limits
+	releaseSlots, err := e.acquireBuildSlots(ctx, target, p.model)
+	if err != nil {
+		results <- buildResult{index: index, waitErr: err}
+		return
+	}
+	results <- buildResult{index: index, started: true}
+
+	fullRefresh := e.modelFullRefresh(p.model)
+	r := buildResult{index: index, build: &modelBuild{mode: core.BuildModeFull}}
+	if fullRefresh {
+		r.build.mode = core.BuildModeFullRefresh
+	}
+	start := time.Now()
+	modelCtx := withModelBuild(e.withQueryComment(ctx, runID, p.model.Path), r.build)
+	buildCtx, cancel := modelCtx, context.CancelFunc(func() {})
+	if limited {
+		buildCtx, cancel = context.WithTimeout(modelCtx, timeout)
+	}
+	r.rows, r.err = e.executeModelStatements(buildCtx, runID, p, fullRefresh)
+	r.timedOut = r.err != nil && errors.Is(buildCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+	cancel()
+	r.elapsed = time.Since(start)
+	if r.err == nil {
+		e.capturePlan(modelCtx, runID, p)
+	}
+	releaseSlots()
+	results <- r
+}
+
+// skipModels marks models that will not run as skipped, recording why and,
+// for skips caused by a failure, the model that failed.
+func (e *Engine) skipModels(runID string, models []preparedModel, reason core.SkipReason, skippedBy, msg string) {
+	observer := e.getObserver()
+	for _, p := range models {
+		_ = e.store.UpdateModelRun(p.modelRun.ID, core.ModelRunStatusSkipped, 0, msg, p.renderMS, 0)
+		_ = e.store.UpdateModelRunSkip(p.modelRun.ID, reason, skippedBy)
+
+		// Notify observer of skipped model
+		if observer != nil {
+			p.modelRun.Status = core.ModelRunStatusSkipped
+			p.modelRun.Error = msg
+			p.modelRun.SkipReason = reason
+			p.modelRun.SkippedBy = skippedBy
+			observer.OnModelRunUpdated(runID, p.modelRun)
+		}
+	}
+}
+
+// modelBuild is the state of a model build its statements add to: how the
+// model is built and the warehouse cost of its statements. Builds running at
+// once each carry their own in their context (see withModelBuild).
+type modelBuild struct {
+	mu   sync.Mutex
+	mode core.BuildMode
+	cost core.QueryCost
+}
+
+// modelBuildKey is the context key of the build a statement belongs to.
+type modelBuildKey struct{}
+
+// withModelBuild returns a context whose statements belong to a build.
+func withModelBuild(ctx context.Context, b *modelBuild) context.Context {
+	return context.WithValue(ctx, modelBuildKey{}, b)
+}
+
+// modelBuildFrom returns the build a context's statements belong to, or nil
+// for statements outside a model build.
+func modelBuildFrom(ctx context.Context) *modelBuild {
+	b, _ := ctx.Value(modelBuildKey{}).(*modelBuild)
+	return b
+}
+
+// setMode records how the model is built.
+func (b *modelBuild) setMode(mode core.BuildMode) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mode = mode
+}
+
+// addCost adds the cost of a statement to the build's.
+func (b *modelBuild) addCost(cost core.QueryCost) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cost = b.cost.Add(cost)
+}
+
+// recordBuildCost saves the warehouse cost of a model build, if the adapter
+// reported one.
+func (e *Engine) recordBuildCost(modelRun *core.ModelRun, b *modelBuild) {
+	if b.cost == (core.QueryCost{}) {
+		return
+	}
+	modelRun.BytesScanned = b.cost.BytesScanned
+	modelRun.SlotMS = b.cost.SlotMS
+	if err := e.store.UpdateModelRunCost(modelRun.ID, b.cost); err != nil {
+		e.logger.Debug("failed to record model run cost", "model_run", modelRun.ID, "error", err)
+	}
+}
+
+// recordBuildMode saves how a model was built.
+func (e *Engine) recordBuildMode(modelRun *core.ModelRun, b *modelBuild) {
+	modelRun.BuildMode = b.mode
+	if err := e.store.UpdateModelRunBuildMode(modelRun.ID, b.mode); err != nil {
+		e.logger.Debug("failed to record model run build mode", "model_run", modelRun.ID, "error", err)
+	}
+}
+
+// executeModelStatements builds a prepared model between its pre and post
+// statements. Whether the statements and the build run in a single
+// transaction, so a failure leaves no partial changes, follows the model's
+// transaction policy (see transactionPolicy).
+func (e *Engine) executeModelStatements(ctx context.Context, runID string, p preparedModel, fullRefresh bool) (int64, error) {
+	var rowsAffected int64
+	run := func(ctx context.Context) error {
+		for i, stmt := range p.pre {
+			if err := e.execMeasured(ctx, stmt); err != nil {
+				return fmt.Errorf("pre statement %d of %s: %w", i+1, p.model.Path, err)
+			}
+		}
+		var err error
+		rowsAffected, err = e.executeModelWithSQL(ctx, runID, p.model, p.persisted, p.sql, fullRefresh)
+		if err != nil {
+			return err
+		}
+		for i, stmt := range p.post {
+			if err := e.execMeasured(ctx, stmt); err != nil {
+				return fmt.Errorf("post statement %d of %s: %w", i+1, p.model.Path, err)
+			}
+		}
+		return nil
+	}
+
+	policy := e.transactionPolicy(p.model)
+	hasStatements := len(p.pre) > 0 || len(p.post) > 0
+	if policy == core.TransactionNever || (policy == core.TransactionAuto && !hasStatements) {
+		return rowsAffected, run(ctx)
+	}
+
+	tx, ok := e.db.(adapter.Transactor)
+	if !ok {
+		if policy == core.TransactionAlways {
+			return 0, fmt.Errorf("model %s has transaction: always, but the %s adapter does not support transactions", p.model.Path, e.dbConfig.Type)
+		}
+		return rowsAffected, run(ctx)
+	}
+	return rowsAffected, tx.Transaction(ctx, run)
 }
 
-// executeModelWithSQL executes a model with pre-rendered SQL.
-func (e *Engine) executeModelWithSQL(ctx context.Context, m *core.Model, model *core.PersistedModel, sql string) (int64, error) {
+// transactionPolicy returns the transaction policy of a model's build: the
+// model's own, else the target's, else auto for adapters whose DDL is
+// transactional and never for others.
+func (e *Engine) transactionPolicy(m *core.Model) core.TransactionPolicy {
+	if m.Transaction != "" {
+		return m.Transaction
+	}
+	if e.transaction != "" {
+		return e.transaction
+	}
+	if tx, ok := e.db.(adapter.Transactor); ok && tx.TransactionalDDL() {
+		return core.TransactionAuto
+	}
+	return core.TransactionNever
+}
+
+// executeModelWithSQL executes a model with pre-rendered SQL. With fullRefresh,
+// incremental models rebuild their tables instead of merging new rows.
+func (e *Engine) executeModelWithSQL(ctx context.Context, runID string, m *core.Model, model *core.PersistedModel, sql string, fullRefresh bool) (int64, error) {
 	e.logger.Debug("executing model", "model_path", m.Path, "materialization", m.Materialized)
 
+	if err := e.checkMasking(m); err != nil {
+		return 0, err
+	}
+
+	var rowsAffected int64
+	var err error
 	switch m.Materialized {
 	case "table":
-		return e.executeTable(ctx, m.Path, sql)
+		rowsAffected, err = e.executeTable(ctx, m.Path, withAuditColumns(m, model, e.withSample(m, e.withMasking(m, sql)), runID))
 	case "view":
-		return e.executeView(ctx, m.Path, sql)
+		rowsAffected, err = e.executeView(ctx, m.Path, e.withSample(m, e.withMasking(m, sql)))
 	case "incremental":
-		return e.executeIncremental(ctx, m, model, sql)
+		rowsAffected, err = e.executeIncremental(ctx, m, model, sql, runID, fullRefresh)
+	case core.MaterializationExternal:
+		rowsAffected, err = e.executeExternal(ctx, runID, m)
 	default:
 		return 0, fmt.Errorf("unknown materialization: %s", m.Materialized)
 	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := e.applyMaskingPolicy(ctx, m); err != nil {
+		return 0, err
+	}
+	if err := e.checkPartition(ctx, m); err != nil {
+		return 0, err
+	}
+	if err := e.checkConstraints(ctx, m); err != nil {
+		return 0, err
+	}
+	if err := e.checkAnomalies(ctx, runID, m); err != nil {
+		return 0, err
+	}
+	if _, err := e.applyRetention(ctx, m); err != nil {
+		return 0, err
+	}
+	return rowsAffected, nil
 }
 
 // saveModelSnapshot saves column snapshots for models that use SELECT *.
@@ -0,0 +1,40 @@
+package engine
+
+// sample.go - Sampled builds, limiting models to a number of rows
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// SetSample sets the number of rows runs limit each model to, so development
+// builds run quickly on small data (0 builds models in full). Models set
+// sample in frontmatter to override it.
+func (e *Engine) SetSample(rows int) {
+	e.sample = rows
+}
+
+// sampleRows returns the number of rows a model is limited to, or 0 if it is
+// built in full. Models are only sampled in sampled runs.
+func (e *Engine) sampleRows(m *core.Model) int {
+	if e.sample <= 0 || m.External != nil {
+		return 0
+	}
+	if m.Sample != nil {
+		return *m.Sample
+	}
+	return e.sample
+}
+
+// withSample wraps a model's SQL so it returns at most the model's sample
+// rows. The SQL is returned unchanged for models built in full.
+func (e *Engine) withSample(m *core.Model, sql string) string {
+	rows := e.sampleRows(m)
+	if rows == 0 {
+		return sql
+	}
+	sql = strings.TrimSuffix(strings.TrimSpace(sql), ";")
+	return fmt.Sprintf("SELECT * FROM (\n%s\n) AS leapsql_sample LIMIT %d", sql, rows)
+}
@@ -0,0 +1,264 @@
+package engine
+
+// schemas.go - Machine-readable schemas (JSON Schema, Avro) of model outputs
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// Schema formats supported by OutputSchema.Encode.
+const (
+	SchemaFormatJSON = "json-schema"
+	SchemaFormatAvro = "avro"
+)
+
+// OutputSchema describes the columns a model outputs.
+type OutputSchema struct {
+	// Model is the model path
+	Model string
+	// Name is the model name
+	Name string
+	// Description is the model description
+	Description string
+	// Columns are the output columns, in order
+	Columns []ColumnSchema
+	// Inferred is true when the columns come from lineage because the model
+	// is not built: they have no types
+	Inferred bool
+}
+
+// ColumnSchema describes an output column of a model.
+type ColumnSchema struct {
+	// Name is the column name
+	Name string
+	// Type is the database type, e.g. "BIGINT" (empty if inferred)
+	Type string
+	// Nullable is false when the column has a not_null test
+	Nullable bool
+	// Values are the values of an accepted_values test, if any
+	Values []string
+}
+
+// OutputSchema returns the schema of a model's output: the columns and
+// types of its built table, or the columns inferred from lineage when the
+// model is not built. Tests of the model are the contract of its columns:
+// not_null makes a column required and accepted_values enumerates its values.
+func (e *Engine) OutputSchema(ctx context.Context, path string) (*OutputSchema, error) {
+	m, ok := e.models[path]
+	if !ok {
+		return nil, fmt.Errorf("model not found: %s", path)
+	}
+
+	s := &OutputSchema{Model: m.Path, Name: m.Name, Description: m.Description}
+	if err := e.ensureDBConnected(ctx); err != nil {
+		return nil, err
+	}
+	if meta, err := e.db.GetTableMetadata(ctx, e.tableName(path)); err == nil {
+		for _, col := range meta.Columns {
+			s.Columns = append(s.Columns, ColumnSchema{Name: col.Name, Type: col.Type, Nullable: col.Nullable})
+		}
+	} else {
+		s.Inferred = true
+		for _, col := range m.Columns {
+			s.Columns = append(s.Columns, ColumnSchema{Name: col.Name, Nullable: true})
+		}
+	}
+	if len(s.Columns) == 0 {
+		return nil, fmt.Errorf("model %s is not built and its columns cannot be inferred (e.g. SELECT *)", path)
+	}
+
+	for i := range s.Columns {
+		col := &s.Columns[i]
+		for _, test := range m.Tests {
+			if containsFold(test.NotNull, col.Name) {
+				col.Nullable = false
+			}
+			if av := test.AcceptedValues; av != nil && strings.EqualFold(av.Column, col.Name) {
+				col.Values = av.Values
+			}
+		}
+	}
+	return s, nil
+}
+
+// Encode returns the schema in a format: SchemaFormatJSON or SchemaFormatAvro.
+func (s *OutputSchema) Encode(format string) (map[string]any, error) {
+	switch format {
+	case SchemaFormatJSON:
+		return s.JSONSchema(), nil
+	case SchemaFormatAvro:
+		return s.AvroSchema(), nil
+	}
+	return nil, fmt.Errorf("invalid schema format %q: must be %s or %s", format, SchemaFormatJSON, SchemaFormatAvro)
+}
+
+// JSONSchema returns the schema as a JSON Schema (draft 2020-12) of one row.
+func (s *OutputSchema) JSONSchema() map[string]any {
+	properties := make(map[string]any, len(s.Columns))
+	required := []string{}
+	for _, col := range s.Columns {
+		prop := jsonSchemaType(col.Type)
+		if col.Nullable {
+			if typ, ok := prop["type"].(string); ok {
+				prop["type"] = []string{typ, "null"}
+			}
+		} else {
+			required = append(required, col.Name)
+		}
+		if len(col.Values) > 0 {
+			values := make([]any, 0, len(col.Values)+1)
+			for _, v := range col.Values {
+				values = append(values, v)
+			}
+			if col.Nullable {
+				values = append(values, nil)
+			}
+			prop["enum"] = values
+		}
+		properties[col.Name] = prop
+	}
+
+	schema := map[string]any{
+		"$schema":    "https://json-schema.org/draft/2020-12/schema",
+		"title":      s.Model,
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+	if s.Description != "" {
+		schema["description"] = s.Description
+	}
+	return schema
+}
+
+// jsonSchemaType returns the JSON Schema of a database type. Unknown and
+// inferred types accept any value.
+func jsonSchemaType(typ string) map[string]any {
+	typ = strings.ToUpper(strings.TrimSpace(typ))
+	if inner, ok := strings.CutSuffix(typ, "[]"); ok {
+		return map[string]any{"type": "array", "items": jsonSchemaType(inner)}
+	}
+	base, _, _ := strings.Cut(typ, "(")
+	switch base {
+	case "":
+		return map[string]any{}
+	case "BOOLEAN", "BOOL":
+		return map[string]any{"type": "boolean"}
+	case "TINYINT", "SMALLINT", "INTEGER", "INT", "BIGINT", "HUGEINT",
+		"UTINYINT", "USMALLINT", "UINTEGER", "UBIGINT", "UHUGEINT":
+		return map[string]any{"type": "integer"}
+	case "FLOAT", "DOUBLE", "REAL", "DECIMAL", "NUMERIC", "DOUBLE PRECISION":
+		return map[string]any{"type": "number"}
+	case "DATE":
+		return map[string]any{"type": "string", "format": "date"}
+	case "TIME":
+		return map[string]any{"type": "string", "format": "time"}
+	case "UUID":
+		return map[string]any{"type": "string", "format": "uuid"}
+	case "STRUCT", "MAP":
+		return map[string]any{"type": "object"}
+	case "JSON":
+		return map[string]any{}
+	}
+	if strings.HasPrefix(base, "TIMESTAMP") {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	return map[string]any{"type": "string"}
+}
+
+// AvroSchema returns the schema as an Avro record of one row. Names that are
+// not valid Avro names are converted, e.g. "order-id" -> "order_id".
+func (s *OutputSchema) AvroSchema() map[string]any {
+	fields := make([]any, 0, len(s.Columns))
+	for _, col := range s.Columns {
+		field := map[string]any{"name": avroName(col.Name), "type": avroType(col.Type)}
+		if col.Nullable {
+			field["type"] = []any{"null", field["type"]}
+			field["default"] = nil
+		}
+		if field["name"] != col.Name {
+			field["aliases"] = []string{col.Name}
+		}
+		if len(col.Values) > 0 {
+			field["doc"] = "One of: " + strings.Join(col.Values, ", ")
+		}
+		fields = append(fields, field)
+	}
+
+	schema := map[string]any{
+		"type":   "record",
+		"name":   avroName(s.Name),
+		"fields": fields,
+	}
+	if _, table := core.SplitModelID(s.Model); strings.Contains(table, ".") {
+		namespace := table[:strings.LastIndex(table, ".")]
+		parts := strings.Split(namespace, ".")
+		for i, p := range parts {
+			parts[i] = avroName(p)
+		}
+		schema["namespace"] = strings.Join(parts, ".")
+	}
+	if s.Description != "" {
+		schema["doc"] = s.Description
+	}
+	return schema
+}
+
+// avroType returns the Avro type of a database type. Unknown and inferred
+// types are strings.
+func avroType(typ string) any {
+	typ = strings.ToUpper(strings.TrimSpace(typ))
+	if inner, ok := strings.CutSuffix(typ, "[]"); ok {
+		return map[string]any{"type": "array", "items": avroType(inner)}
+	}
+	base, args, _ := strings.Cut(typ, "(")
+	switch base {
+	case "BOOLEAN", "BOOL":
+		return "boolean"
+	case "TINYINT", "SMALLINT", "INTEGER", "INT", "UTINYINT", "USMALLINT":
+		return "int"
+	case "BIGINT", "UINTEGER":
+		return "long"
+	case "FLOAT", "REAL":
+		return "float"
+	case "DOUBLE", "DOUBLE PRECISION":
+		return "double"
+	case "DECIMAL", "NUMERIC":
+		precision, scale := 18, 3
+		if p, s, ok := strings.Cut(strings.TrimSuffix(args, ")"), ","); ok {
+			precision, _ = strconv.Atoi(strings.TrimSpace(p))
+			scale, _ = strconv.Atoi(strings.TrimSpace(s))
+		}
+		return map[string]any{"type": "bytes", "logicalType": "decimal", "precision": precision, "scale": scale}
+	case "DATE":
+		return map[string]any{"type": "int", "logicalType": "date"}
+	case "TIME":
+		return map[string]any{"type": "long", "logicalType": "time-micros"}
+	case "UUID":
+		return map[string]any{"type": "string", "logicalType": "uuid"}
+	case "BLOB", "BYTEA", "VARBINARY":
+		return "bytes"
+	}
+	if strings.HasPrefix(base, "TIMESTAMP") {
+		return map[string]any{"type": "long", "logicalType": "timestamp-micros"}
+	}
+	return "string"
+}
+
+var avroInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// avroName converts a name to a valid Avro name: letters, digits and
+// underscores, not starting with a digit.
+func avroName(name string) string {
+	name = avroInvalidChars.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
@@ -5,11 +5,18 @@ package engine
 import (
 	"context"
 	"fmt"
-	"os"
 	"path/filepath"
-	"strings"
+	"sort"
+
+	"github.com/leapstack-labs/leapsql/internal/loader"
 )
 
+// SeedFiles returns the CSV files of the seeds directory, keyed by the table
+// each is loaded into.
+func (e *Engine) SeedFiles() (map[string]string, error) {
+	return loader.FindSeeds(e.seedsDir)
+}
+
 // LoadSeeds loads all CSV files from the seeds directory into the database.
 func (e *Engine) LoadSeeds(ctx context.Context) error {
 	if e.seedsDir == "" {
@@ -22,27 +29,27 @@ func (e *Engine) LoadSeeds(ctx context.Context) error {
 	if err := e.ensureDBConnected(ctx); err != nil {
 		return err
 	}
+	ctx = e.withQueryComment(ctx, "", "")
 
-	entries, err := os.ReadDir(e.seedsDir)
+	seeds, err := loader.FindSeeds(e.seedsDir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil // No seeds directory is OK
-		}
 		return fmt.Errorf("failed to read seeds directory: %w", err)
 	}
 
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".csv") {
-			continue
-		}
+	// Load in a stable order
+	tables := make([]string, 0, len(seeds))
+	for table := range seeds {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
 
-		tableName := strings.TrimSuffix(entry.Name(), ".csv")
-		csvPath := filepath.Join(e.seedsDir, entry.Name())
+	for _, tableName := range tables {
+		csvPath := seeds[tableName]
 
 		e.logger.Debug("loading seed file", "table", tableName, "path", csvPath)
 
 		if err := e.db.LoadCSV(ctx, tableName, csvPath); err != nil {
-			return fmt.Errorf("failed to load seed %s: %w", entry.Name(), err)
+			return fmt.Errorf("failed to load seed %s: %w", filepath.Base(csvPath), err)
 		}
 	}
 
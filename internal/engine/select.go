@@ -0,0 +1,70 @@
+package engine
+
+import (
+	"github.com/leapstack-labs/leapsql/internal/selector"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// SelectModels returns the sorted paths of the discovered models matched by
+// a selector expression (e.g., "tag:pii AND NOT tag:deprecated").
+// Models, tags, and groups the expression references must exist. result:
+// terms match the statuses of the environment's latest run, and state:modified
+// the models whose content changed since their last successful build.
+func (e *Engine) SelectModels(expr string) ([]string, error) {
+	sel, err := selector.Parse(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	models := make([]*core.Model, 0, len(e.models))
+	for _, m := range e.models {
+		models = append(models, m)
+	}
+
+	if err := selector.Validate(sel, models); err != nil {
+		return nil, err
+	}
+	return selector.Select(sel, e.selectionContext(models)), nil
+}
+
+// selectionContext returns the dependencies, previous run results and
+// modified models of the project for selector evaluation.
+func (e *Engine) selectionContext(models []*core.Model) *selector.Context {
+	c := &selector.Context{
+		Models:   models,
+		Parents:  make(map[string][]string, len(models)),
+		Results:  make(map[string]core.ModelRunStatus),
+		Modified: make(map[string]bool),
+	}
+
+	for _, m := range models {
+		for _, parent := range e.graph.GetParents(m.Path) {
+			if _, ok := e.models[parent]; ok {
+				c.Parents[m.Path] = append(c.Parents[m.Path], parent)
+			}
+		}
+	}
+
+	if run, err := e.store.GetLatestRun(e.environment); err == nil && run != nil {
+		modelRuns, err := e.store.GetModelRunsWithModelInfo(run.ID)
+		if err != nil {
+			e.logger.Debug("failed to get previous run results", "run", run.ID, "error", err)
+		}
+		for _, mr := range modelRuns {
+			c.Results[mr.ModelPath] = mr.Status
+		}
+	}
+
+	for _, m := range models {
+		persisted, err := e.store.GetModelByPath(m.Path)
+		if err != nil || persisted == nil {
+			c.Modified[m.Path] = true
+			continue
+		}
+		last, err := e.store.GetLatestSuccessfulModelRun(persisted.ID)
+		if err != nil || last == nil || last.ContentHash != persisted.ContentHash {
+			c.Modified[m.Path] = true
+		}
+	}
+	return c
+}
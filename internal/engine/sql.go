@@ -1,19 +1,88 @@
 // Package engine provides the SQL orchestration layer.
-// This file contains the FormatSQL function which combines parsing and formatting.
+// This file contains the FormatSQL, FormatModel and TranspileSQL functions
+// which combine parsing and formatting.
 package engine
 
 import (
+	"strings"
+
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/format"
 	"github.com/leapstack-labs/leapsql/pkg/parser"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/leapstack-labs/leapsql/pkg/transpile"
 )
 
 // FormatSQL parses and formats SQL in one step.
 // This is an orchestration function that combines parser and formatter.
 func FormatSQL(sql string, d *core.Dialect) (string, error) {
+	return formatSQL(sql, d, format.Options{})
+}
+
+// formatSQL formats SQL in the casing of opts. Identifiers quoted in sql
+// stay quoted and as written.
+func formatSQL(sql string, d *core.Dialect, opts format.Options) (string, error) {
 	stmt, comments, err := parser.ParseWithDialectAndComments(sql, d)
 	if err != nil {
 		return "", err
 	}
-	return format.WithComments(stmt, comments, d), nil
+	opts.Quoted = make(map[string]bool)
+	for _, tok := range parser.TokenizeWithDialect(sql, d) {
+		if tok.Type == token.IDENT && tok.Pos.Offset < len(sql) && sql[tok.Pos.Offset] == '"' {
+			opts.Quoted[tok.Literal] = true
+		}
+	}
+	return format.WithOptions(stmt, comments, d, opts), nil
+}
+
+// FormatModel formats the SQL of a model file's content in the casing of
+// opts. The frontmatter is kept as is, followed by a blank line and the
+// formatted SQL. Content without SQL is returned unchanged. Template
+// expressions are kept as written, but SQL with template statements
+// ({* ... *}) does not parse.
+func FormatModel(content string, d *core.Dialect, opts format.Options) (string, error) {
+	head, sql := "", content
+	if strings.HasPrefix(strings.TrimSpace(content), "/*---") {
+		if end := strings.Index(content, "---*/"); end >= 0 {
+			end += len("---*/")
+			head, sql = strings.TrimSpace(content[:end])+"\n\n", content[end:]
+		}
+	}
+	if strings.TrimSpace(sql) == "" {
+		return content, nil
+	}
+	formatted, err := formatSQL(sql, d, opts)
+	if err != nil {
+		return "", err
+	}
+	return head + formatted, nil
+}
+
+// TranspileSQL parses SQL written in dialect from, rewrites it for dialect to
+// and formats it. SQL is returned unchanged when both dialects are the same.
+// Constructs the target dialect lacks are reported in a
+// *transpile.UnsupportedError.
+func TranspileSQL(sql string, from, to *core.Dialect) (string, error) {
+	if from == nil || to == nil {
+		return "", core.ErrDialectRequired
+	}
+	if from.Name == to.Name {
+		return sql, nil
+	}
+
+	stmt, comments, err := parser.ParseWithDialectAndComments(sql, from)
+	if err != nil {
+		return "", err
+	}
+	if err := transpile.Rewrite(stmt, from, to); err != nil {
+		return "", err
+	}
+
+	out := format.WithComments(stmt, comments, to)
+	// Syntax the target dialect lacks (lambdas, star modifiers, PIVOT, ...)
+	// does not parse with it
+	if _, err := parser.ParseWithDialect(out, to); err != nil {
+		return "", &transpile.UnsupportedError{Dialect: to.Name, Constructs: []string{err.Error()}}
+	}
+	return out, nil
 }
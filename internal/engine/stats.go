@@ -0,0 +1,55 @@
+package engine
+
+// stats.go - Complexity metrics of model SQL
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/parser"
+)
+
+// ModelStats is the complexity of a model's SQL.
+type ModelStats struct {
+	Model    string
+	FilePath string
+	Metrics  lintsql.Metrics
+	// Err is why the model couldn't be measured: its SQL failed to render
+	// or parse. Metrics are zero then.
+	Err error
+}
+
+// MeasureModels computes the complexity metrics of the rendered SQL of
+// models, without their row filters. Stats are sorted by model path.
+func (e *Engine) MeasureModels(ctx context.Context, models []*core.Model) ([]ModelStats, error) {
+	d := e.ModelDialect()
+	if d == nil {
+		return nil, fmt.Errorf("dialect not available")
+	}
+
+	stats := make([]ModelStats, 0, len(models))
+	for _, m := range models {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		s := ModelStats{Model: m.Path, FilePath: m.FilePath}
+		rendered, err := e.renderSQL(m)
+		if err != nil {
+			s.Err = fmt.Errorf("failed to render: %w", err)
+		} else if stmt, err := parser.ParseWithDialect(rendered, d); err != nil {
+			s.Err = fmt.Errorf("failed to parse: %w", err)
+		} else {
+			s.Metrics = lintsql.Measure(stmt, d)
+		}
+		stats = append(stats, s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Model < stats[j].Model
+	})
+	return stats, nil
+}
@@ -0,0 +1,85 @@
+package engine
+
+// timeouts.go - Per-group max_runtime limits on model builds
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// groupBudgets tracks the time the models of groups with a max_runtime spend
+// building during a run. Once a group exceeds its max_runtime, its remaining
+// models and the models downstream of them are skipped, while the rest of the
+// run continues.
+type groupBudgets struct {
+	limits  map[string]time.Duration
+	spent   map[string]time.Duration
+	tripped map[string]string // Group -> model after which it exceeded its max_runtime
+	blocked map[string]string // Model path -> group whose timeout skips it
+}
+
+func (e *Engine) newGroupBudgets() *groupBudgets {
+	b := &groupBudgets{
+		limits:  make(map[string]time.Duration),
+		spent:   make(map[string]time.Duration),
+		tripped: make(map[string]string),
+		blocked: make(map[string]string),
+	}
+	for _, g := range e.groups {
+		if g.MaxRuntime > 0 {
+			b.limits[g.Name] = g.MaxRuntime
+		}
+	}
+	return b
+}
+
+// timeout returns the time left in the max_runtime of a model's group, and
+// false for models of groups without one. Models of a group building at once
+// may each take the time left; their time adds up once they are built.
+func (b *groupBudgets) timeout(m *core.Model) (time.Duration, bool) {
+	limit, ok := b.limits[m.Group]
+	if !ok {
+		return 0, false
+	}
+	return limit - b.spent[m.Group], true
+}
+
+// spend adds the build time of a model to its group, tripping the group once
+// it reaches the max_runtime.
+func (b *groupBudgets) spend(m *core.Model, elapsed time.Duration) {
+	limit, ok := b.limits[m.Group]
+	if !ok {
+		return
+	}
+	b.spent[m.Group] += elapsed
+	if b.spent[m.Group] >= limit && b.tripped[m.Group] == "" {
+		b.tripped[m.Group] = m.Path
+	}
+}
+
+// exceeded returns the group whose timeout skips a model: its own group, or
+// the group of a timed out or skipped model upstream of it.
+func (b *groupBudgets) exceeded(m *core.Model) (string, bool) {
+	if _, ok := b.tripped[m.Group]; ok {
+		return m.Group, true
+	}
+	group, ok := b.blocked[m.Path]
+	return group, ok
+}
+
+// block skips models because of a group's timeout.
+func (b *groupBudgets) block(group string, paths []string) {
+	for _, path := range paths {
+		if _, ok := b.blocked[path]; !ok {
+			b.blocked[path] = group
+		}
+	}
+}
+
+// message describes why a group's timeout skips a model.
+func (b *groupBudgets) message(group string) string {
+	return fmt.Sprintf("skipped: group %s exceeded its max_runtime of %s after model %s",
+		group, b.limits[group], b.tripped[group])
+}
@@ -0,0 +1,58 @@
+package engine
+
+// transpile.go - Rendering models written in the project's dialect for the target's
+
+import (
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/dialect"
+
+	// Register every dialect models can be written in or rendered for
+	_ "github.com/leapstack-labs/leapsql/pkg/dialects/databricks"
+	_ "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	_ "github.com/leapstack-labs/leapsql/pkg/dialects/postgres"
+	_ "github.com/leapstack-labs/leapsql/pkg/dialects/snowflake"
+)
+
+// ModelDialect returns the SQL dialect models are written in: the project's
+// dialect if one is configured, otherwise the target's.
+func (e *Engine) ModelDialect() *core.Dialect {
+	if e.modelDialect != nil {
+		return e.modelDialect
+	}
+	return e.dialect
+}
+
+// RenderModelForDialect renders the SQL of a model for the named dialect
+// instead of the target's, e.g. to check that a model written for DuckDB in
+// development also compiles for Snowflake in production.
+func (e *Engine) RenderModelForDialect(modelPath, name string) (string, error) {
+	d, ok := dialect.Get(name)
+	if !ok {
+		return "", fmt.Errorf("unknown dialect %q: supported dialects are %v", name, dialect.List())
+	}
+	m, _, err := e.renderTarget(modelPath)
+	if err != nil {
+		return "", err
+	}
+	sql, err := e.filteredSQL(m)
+	if err != nil {
+		return "", err
+	}
+	return e.transpileSQL(m, sql, d)
+}
+
+// transpileSQL renders a model's SQL, written in the model dialect, for
+// dialect to. It is returned unchanged if both are the same.
+func (e *Engine) transpileSQL(m *core.Model, sql string, to *core.Dialect) (string, error) {
+	from := e.ModelDialect()
+	if from == nil || to == nil || from.Name == to.Name {
+		return sql, nil
+	}
+	transpiled, err := TranspileSQL(sql, from, to)
+	if err != nil {
+		return "", fmt.Errorf("transpile %s from %s to %s: %w", m.Path, from.Name, to.Name, err)
+	}
+	return transpiled, nil
+}
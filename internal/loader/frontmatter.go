@@ -4,24 +4,96 @@ package loader
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/yamlschema"
 	"gopkg.in/yaml.v3"
 )
 
 // FrontmatterConfig represents parsed YAML frontmatter.
 // Unknown fields cause parse errors (use Meta for extensions).
 type FrontmatterConfig struct {
-	Name         string            `yaml:"name"`
-	Description  string            `yaml:"description"`
-	Materialized string            `yaml:"materialized"` // table, view, incremental
-	UniqueKey    string            `yaml:"unique_key"`
-	Owner        string            `yaml:"owner"`
-	Schema       string            `yaml:"schema"`
-	Tags         []string          `yaml:"tags"`
-	Tests        []core.TestConfig `yaml:"tests"`
-	Meta         map[string]any    `yaml:"meta"` // Extension point for custom fields
+	Name         string                 `yaml:"name"`
+	Description  string                 `yaml:"description"`
+	Materialized string                 `yaml:"materialized"` // table, view, incremental, external
+	UniqueKey    string                 `yaml:"unique_key"`
+	Owner        string                 `yaml:"owner"`
+	Group        string                 `yaml:"group"`
+	Schema       string                 `yaml:"schema"`
+	Database     string                 `yaml:"database"` // Attached database (catalog) the model is built in
+	Tags         []string               `yaml:"tags"`
+	Tests        []core.TestConfig      `yaml:"tests"`
+	Meta         map[string]any         `yaml:"meta"` // Extension point for custom fields
+	Version      int                    `yaml:"version"`
+	Deprecated   *core.Deprecation      `yaml:"deprecated"`
+	Access       core.Access            `yaml:"access"`  // public, protected, private
+	Enabled      *bool                  `yaml:"enabled"` // nil means enabled
+	AuditColumns bool                   `yaml:"audit_columns"`
+	FullRefresh  *bool                  `yaml:"full_refresh"` // nil follows run --full-refresh
+	Transaction  core.TransactionPolicy `yaml:"transaction"`  // auto, always, never
+	Sample       *int                   `yaml:"sample"`       // nil follows the run's sample
+	PII          []string               `yaml:"pii"`          // Output columns holding personal data
+	Where        string                 `yaml:"where"`        // Row filter applied to the model's query
+	// PartitionBy partitions the model's table by a date column
+	PartitionBy *core.PartitionSpec `yaml:"partition_by"`
+	// Retention deletes rows older than a period after each build
+	Retention *core.RetentionPolicy `yaml:"retention"`
+	// ResourceClass is the target resource class limiting how many of the
+	// model's builds run at once
+	ResourceClass string `yaml:"resource_class"`
+	// External configures the command building an external model
+	External *core.ExternalConfig `yaml:"external"`
+	// Config holds per-environment overrides, keyed by environment name
+	Config map[string]EnvironmentConfig `yaml:"config"`
+}
+
+// EnvironmentConfig overrides frontmatter settings in one environment.
+// Empty fields leave the model's setting unchanged.
+type EnvironmentConfig struct {
+	Enabled      *bool  `yaml:"enabled"`
+	Materialized string `yaml:"materialized"`
+	UniqueKey    string `yaml:"unique_key"`
+	Schema       string `yaml:"schema"`
+	Database     string `yaml:"database"`
+	Where        string `yaml:"where"`
+}
+
+// ForEnvironment returns the config with the overrides for env applied.
+// The receiver is not modified.
+func (c *FrontmatterConfig) ForEnvironment(env string) *FrontmatterConfig {
+	override, ok := c.Config[env]
+	if !ok {
+		return c
+	}
+
+	resolved := *c
+	if override.Enabled != nil {
+		resolved.Enabled = override.Enabled
+	}
+	if override.Materialized != "" {
+		resolved.Materialized = override.Materialized
+	}
+	if override.UniqueKey != "" {
+		resolved.UniqueKey = override.UniqueKey
+	}
+	if override.Schema != "" {
+		resolved.Schema = override.Schema
+	}
+	if override.Database != "" {
+		resolved.Database = override.Database
+	}
+	if override.Where != "" {
+		resolved.Where = override.Where
+	}
+	return &resolved
+}
+
+// IsEnabled reports whether the model is enabled. Models are enabled unless
+// they set enabled: false.
+func (c *FrontmatterConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
 }
 
 // FrontmatterResult holds the result of frontmatter extraction.
@@ -36,7 +108,9 @@ type FrontmatterResult struct {
 var frontmatterPattern = regexp.MustCompile(`(?s)^\s*/\*---\s*\n(.*?)\s*---\*/`)
 
 // ExtractFrontmatter extracts YAML frontmatter from SQL content.
-// Returns the parsed config, remaining SQL, and any error.
+// Returns the parsed config, remaining SQL, and any error. When the
+// frontmatter has several problems, the first one is returned; use
+// ValidateFrontmatter to get them all.
 func ExtractFrontmatter(content string) (*FrontmatterResult, error) {
 	result := &FrontmatterResult{
 		Config:  &FrontmatterConfig{},
@@ -44,33 +118,93 @@ func ExtractFrontmatter(content string) (*FrontmatterResult, error) {
 		HasYAML: false,
 	}
 
-	matches := frontmatterPattern.FindStringSubmatch(content)
-	if len(matches) < 2 {
+	loc := frontmatterPattern.FindStringSubmatchIndex(content)
+	if loc == nil {
 		// No frontmatter found, return content as-is
 		return result, nil
 	}
 
 	result.HasYAML = true
-	yamlContent := matches[1]
 
 	// Remove the frontmatter block from SQL
 	result.SQL = strings.TrimSpace(frontmatterPattern.ReplaceAllString(content, ""))
 
-	// Parse YAML with strict mode to reject unknown fields
-	config, err := parseFrontmatterYAML(yamlContent)
-	if err != nil {
-		return nil, err
+	config, errs := parseFrontmatter(content, loc)
+	if len(errs) > 0 {
+		return nil, errs[0]
 	}
 
 	result.Config = config
 	return result, nil
 }
 
+// ValidateFrontmatter checks the frontmatter of SQL content against the
+// frontmatter schema and returns every problem found: unknown fields, values
+// of the wrong type and invalid enum values. Errors carry the line and column
+// in content. Content without frontmatter is valid.
+func ValidateFrontmatter(content string) []error {
+	loc := frontmatterPattern.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return nil
+	}
+	_, errs := parseFrontmatter(content, loc)
+	return errs
+}
+
+// frontmatterSchema is the schema of the frontmatter YAML.
+var frontmatterSchema = yamlschema.For(frontmatterConfigYAML{}, "yaml").
+	Enum("materialized", "table", "view", "incremental", "external").
+	Enum("access", "public", "protected", "private").
+	Enum("transaction", "auto", "always", "never").
+	Enum("partition_by.grain", "hour", "day", "month", "year").
+	Enum("config.*.materialized", "table", "view", "incremental").
+	Enum("tests.*.row_count_drift.severity", "warning", "error").
+	Enum("tests.*.null_rate_drift.severity", "warning", "error")
+
+// parseFrontmatter validates and parses the frontmatter matched at loc.
+func parseFrontmatter(content string, loc []int) (*FrontmatterConfig, []error) {
+	yamlContent := content[loc[2]:loc[3]]
+	lineOffset := strings.Count(content[:loc[2]], "\n")
+
+	var errs []error
+	for _, e := range frontmatterSchema.Validate([]byte(yamlContent)) {
+		line := e.Line
+		if line > 0 {
+			line += lineOffset
+		}
+		if e.Field != "" && e.Path == "" {
+			errs = append(errs, &UnknownFieldError{Field: e.Field, Line: line, Column: e.Column})
+			continue
+		}
+		errs = append(errs, &FrontmatterParseError{Line: line, Column: e.Column, Message: e.Message})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	config, err := parseFrontmatterYAML(yamlContent)
+	if err != nil {
+		return nil, []error{err}
+	}
+	return config, nil
+}
+
 // testConfigYAML is an internal type for YAML unmarshaling with correct tags.
 type testConfigYAML struct {
 	Unique         []string                  `yaml:"unique,omitempty"`
 	NotNull        []string                  `yaml:"not_null,omitempty"`
 	AcceptedValues *acceptedValuesConfigYAML `yaml:"accepted_values,omitempty"`
+	RowCountDrift  *driftConfigYAML          `yaml:"row_count_drift,omitempty"`
+	NullRateDrift  *driftConfigYAML          `yaml:"null_rate_drift,omitempty"`
+}
+
+// driftConfigYAML is an internal type for YAML unmarshaling.
+type driftConfigYAML struct {
+	Columns   []string `yaml:"columns"`
+	MaxChange float64  `yaml:"max_change"`
+	ZScore    float64  `yaml:"z_score"`
+	Window    int      `yaml:"window"`
+	Severity  string   `yaml:"severity"`
 }
 
 // acceptedValuesConfigYAML is an internal type for YAML unmarshaling.
@@ -79,82 +213,183 @@ type acceptedValuesConfigYAML struct {
 	Values []string `yaml:"values"`
 }
 
+// deprecationYAML is an internal type for YAML unmarshaling.
+type deprecationYAML struct {
+	Since       string `yaml:"since"`
+	Replacement string `yaml:"replacement"`
+}
+
+// externalConfigYAML is an internal type for YAML unmarshaling.
+type externalConfigYAML struct {
+	Command []string          `yaml:"command"`
+	Env     map[string]string `yaml:"env"`
+	Inputs  []string          `yaml:"inputs"`
+	Columns []string          `yaml:"columns"`
+}
+
+// partitionSpecYAML is an internal type for YAML unmarshaling.
+type partitionSpecYAML struct {
+	Column string `yaml:"column"`
+	Grain  string `yaml:"grain"`
+}
+
+// retentionPolicyYAML is an internal type for YAML unmarshaling.
+type retentionPolicyYAML struct {
+	Column string `yaml:"column"`
+	Keep   string `yaml:"keep"`
+}
+
+// environmentConfigYAML is an internal type for YAML unmarshaling.
+type environmentConfigYAML struct {
+	Enabled      *bool  `yaml:"enabled"`
+	Materialized string `yaml:"materialized"`
+	UniqueKey    string `yaml:"unique_key"`
+	Schema       string `yaml:"schema"`
+	Database     string `yaml:"database"`
+	Where        string `yaml:"where"`
+}
+
 // frontmatterConfigYAML is an internal type for YAML unmarshaling.
 type frontmatterConfigYAML struct {
-	Name         string           `yaml:"name"`
-	Description  string           `yaml:"description"`
-	Materialized string           `yaml:"materialized"`
-	UniqueKey    string           `yaml:"unique_key"`
-	Owner        string           `yaml:"owner"`
-	Schema       string           `yaml:"schema"`
-	Tags         []string         `yaml:"tags"`
-	Tests        []testConfigYAML `yaml:"tests"`
-	Meta         map[string]any   `yaml:"meta"`
-}
-
-// parseFrontmatterYAML parses YAML content with strict field validation.
+	Name          string                           `yaml:"name"`
+	Description   string                           `yaml:"description"`
+	Materialized  string                           `yaml:"materialized"`
+	UniqueKey     string                           `yaml:"unique_key"`
+	Owner         string                           `yaml:"owner"`
+	Group         string                           `yaml:"group"`
+	Schema        string                           `yaml:"schema"`
+	Database      string                           `yaml:"database"`
+	Tags          []string                         `yaml:"tags"`
+	Tests         []testConfigYAML                 `yaml:"tests"`
+	Meta          map[string]any                   `yaml:"meta"`
+	Version       int                              `yaml:"version"`
+	Deprecated    *deprecationYAML                 `yaml:"deprecated"`
+	Access        string                           `yaml:"access"`
+	Enabled       *bool                            `yaml:"enabled"`
+	AuditColumns  bool                             `yaml:"audit_columns"`
+	FullRefresh   *bool                            `yaml:"full_refresh"`
+	Transaction   string                           `yaml:"transaction"`
+	Sample        *int                             `yaml:"sample"`
+	PII           []string                         `yaml:"pii"`
+	Where         string                           `yaml:"where"`
+	PartitionBy   *partitionSpecYAML               `yaml:"partition_by"`
+	Retention     *retentionPolicyYAML             `yaml:"retention"`
+	ResourceClass string                           `yaml:"resource_class"`
+	External      *externalConfigYAML              `yaml:"external"`
+	Config        map[string]environmentConfigYAML `yaml:"config"`
+}
+
+// parseFrontmatterYAML parses YAML content that passed schema validation.
 func parseFrontmatterYAML(yamlContent string) (*FrontmatterConfig, error) {
-	// First, decode into a map to check for unknown fields
-	var rawMap map[string]any
-	if err := yaml.Unmarshal([]byte(yamlContent), &rawMap); err != nil {
+	var yamlConfig frontmatterConfigYAML
+	if err := yaml.Unmarshal([]byte(yamlContent), &yamlConfig); err != nil {
 		return nil, &FrontmatterParseError{
-			Message: fmt.Sprintf("invalid YAML: %v", err),
+			Message: fmt.Sprintf("failed to parse frontmatter: %v", err),
 		}
 	}
 
-	// Check for unknown fields
-	knownFields := map[string]bool{
-		"name":         true,
-		"description":  true,
-		"materialized": true,
-		"unique_key":   true,
-		"owner":        true,
-		"schema":       true,
-		"tags":         true,
-		"tests":        true,
-		"meta":         true,
+	if yamlConfig.Version < 0 {
+		return nil, &FrontmatterParseError{
+			Message: fmt.Sprintf("invalid version: %d, must be a positive integer", yamlConfig.Version),
+		}
 	}
 
-	for field := range rawMap {
-		if !knownFields[field] {
-			return nil, &UnknownFieldError{
-				Field: field,
-			}
+	if yamlConfig.Sample != nil && *yamlConfig.Sample < 0 {
+		return nil, &FrontmatterParseError{
+			Message: fmt.Sprintf("invalid sample: %d, must be a number of rows, or 0 to build in full", *yamlConfig.Sample),
 		}
 	}
 
-	// Now decode into the internal YAML struct
-	var yamlConfig frontmatterConfigYAML
-	if err := yaml.Unmarshal([]byte(yamlContent), &yamlConfig); err != nil {
+	// Convert to FrontmatterConfig with core types
+	config := &FrontmatterConfig{
+		Name:          yamlConfig.Name,
+		Description:   yamlConfig.Description,
+		Materialized:  yamlConfig.Materialized,
+		UniqueKey:     yamlConfig.UniqueKey,
+		Owner:         yamlConfig.Owner,
+		Group:         yamlConfig.Group,
+		Schema:        yamlConfig.Schema,
+		Database:      yamlConfig.Database,
+		Tags:          yamlConfig.Tags,
+		Meta:          yamlConfig.Meta,
+		Version:       yamlConfig.Version,
+		Access:        core.Access(yamlConfig.Access),
+		Enabled:       yamlConfig.Enabled,
+		AuditColumns:  yamlConfig.AuditColumns,
+		FullRefresh:   yamlConfig.FullRefresh,
+		Transaction:   core.TransactionPolicy(yamlConfig.Transaction),
+		Sample:        yamlConfig.Sample,
+		PII:           yamlConfig.PII,
+		Where:         yamlConfig.Where,
+		ResourceClass: yamlConfig.ResourceClass,
+	}
+
+	if yamlConfig.External != nil {
+		if len(yamlConfig.External.Command) == 0 {
+			return nil, &FrontmatterParseError{Message: "external.command is required"}
+		}
+		if config.Materialized == "" {
+			config.Materialized = core.MaterializationExternal
+		}
+		config.External = (*core.ExternalConfig)(yamlConfig.External)
+	}
+	if config.Materialized == core.MaterializationExternal && config.External == nil {
+		return nil, &FrontmatterParseError{Message: "materialized: external requires an external.command"}
+	}
+	if config.External != nil && config.Materialized != core.MaterializationExternal {
 		return nil, &FrontmatterParseError{
-			Message: fmt.Sprintf("failed to parse frontmatter: %v", err),
+			Message: fmt.Sprintf("external is only valid for external models, not materialized: %s", config.Materialized),
 		}
 	}
 
-	// Validate materialized value if present
-	if yamlConfig.Materialized != "" {
-		validMaterialized := map[string]bool{
-			"table":       true,
-			"view":        true,
-			"incremental": true,
+	if p := yamlConfig.PartitionBy; p != nil {
+		if strings.TrimSpace(p.Column) == "" {
+			return nil, &FrontmatterParseError{Message: "partition_by.column is required"}
 		}
-		if !validMaterialized[yamlConfig.Materialized] {
+		if config.Materialized == "view" || config.Materialized == core.MaterializationExternal {
 			return nil, &FrontmatterParseError{
-				Message: fmt.Sprintf("invalid materialized value: %q, must be one of: table, view, incremental", yamlConfig.Materialized),
+				Message: fmt.Sprintf("partition_by is only valid for tables and incremental models, not materialized: %s", config.Materialized),
 			}
 		}
+		config.PartitionBy = &core.PartitionSpec{Column: p.Column, Grain: core.PartitionGrain(p.Grain)}
+		if config.PartitionBy.Grain == "" {
+			config.PartitionBy.Grain = core.PartitionGrainDay
+		}
 	}
 
-	// Convert to FrontmatterConfig with core types
-	config := &FrontmatterConfig{
-		Name:         yamlConfig.Name,
-		Description:  yamlConfig.Description,
-		Materialized: yamlConfig.Materialized,
-		UniqueKey:    yamlConfig.UniqueKey,
-		Owner:        yamlConfig.Owner,
-		Schema:       yamlConfig.Schema,
-		Tags:         yamlConfig.Tags,
-		Meta:         yamlConfig.Meta,
+	if r := yamlConfig.Retention; r != nil {
+		if config.Materialized == "view" || config.Materialized == core.MaterializationExternal {
+			return nil, &FrontmatterParseError{
+				Message: fmt.Sprintf("retention is only valid for tables and incremental models, not materialized: %s", config.Materialized),
+			}
+		}
+		column := strings.TrimSpace(r.Column)
+		if column == "" && config.PartitionBy != nil {
+			column = config.PartitionBy.Column
+		}
+		if column == "" {
+			return nil, &FrontmatterParseError{Message: "retention.column is required unless the model sets partition_by"}
+		}
+		keep, err := core.ParseRetentionPeriod(r.Keep)
+		if err != nil {
+			return nil, &FrontmatterParseError{Message: "retention.keep: " + err.Error()}
+		}
+		config.Retention = &core.RetentionPolicy{Column: column, Keep: keep}
+	}
+
+	// Convert per-environment overrides
+	for env, envConfig := range yamlConfig.Config {
+		if config.Config == nil {
+			config.Config = make(map[string]EnvironmentConfig, len(yamlConfig.Config))
+		}
+		config.Config[env] = EnvironmentConfig(envConfig)
+	}
+
+	if yamlConfig.Deprecated != nil {
+		config.Deprecated = &core.Deprecation{
+			Since:       yamlConfig.Deprecated.Since,
+			Replacement: yamlConfig.Deprecated.Replacement,
+		}
 	}
 
 	// Convert tests
@@ -169,12 +404,39 @@ func parseFrontmatterYAML(yamlContent string) (*FrontmatterConfig, error) {
 				Values: t.AcceptedValues.Values,
 			}
 		}
+		var err error
+		if test.RowCountDrift, err = convertDriftConfig("row_count_drift", t.RowCountDrift); err != nil {
+			return nil, err
+		}
+		if test.NullRateDrift, err = convertDriftConfig("null_rate_drift", t.NullRateDrift); err != nil {
+			return nil, err
+		}
 		config.Tests = append(config.Tests, test)
 	}
 
 	return config, nil
 }
 
+// convertDriftConfig converts and validates an anomaly test.
+func convertDriftConfig(name string, t *driftConfigYAML) (*core.DriftConfig, error) {
+	if t == nil {
+		return nil, nil
+	}
+	if t.MaxChange < 0 || t.ZScore < 0 || t.Window < 0 {
+		return nil, &FrontmatterParseError{Message: name + ": max_change, z_score and window must not be negative"}
+	}
+	if t.MaxChange == 0 && t.ZScore == 0 {
+		return nil, &FrontmatterParseError{Message: name + " requires max_change or z_score"}
+	}
+	return &core.DriftConfig{
+		Columns:   t.Columns,
+		MaxChange: t.MaxChange,
+		ZScore:    t.ZScore,
+		Window:    t.Window,
+		Fail:      t.Severity == "error",
+	}, nil
+}
+
 // ApplyDefaults applies default values to a FrontmatterConfig based on file context.
 func (c *FrontmatterConfig) ApplyDefaults(filename string, dirPath string) {
 	// Default name from filename (without .sql extension)
@@ -196,14 +458,15 @@ func (c *FrontmatterConfig) ApplyDefaults(filename string, dirPath string) {
 // FrontmatterParseError represents a frontmatter parsing error.
 type FrontmatterParseError struct {
 	File    string
-	Line    int
+	Line    int // 1-based line in the model file, 0 if unknown
+	Column  int // 1-based column, 0 if unknown
 	Message string
 }
 
 func (e *FrontmatterParseError) Error() string {
 	if e.File != "" {
 		if e.Line > 0 {
-			return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+			return fmt.Sprintf("%s:%s: %s", e.File, position(e.Line, e.Column), e.Message)
 		}
 		return fmt.Sprintf("%s: %s", e.File, e.Message)
 	}
@@ -212,14 +475,27 @@ func (e *FrontmatterParseError) Error() string {
 
 // UnknownFieldError represents an error for unknown frontmatter fields.
 type UnknownFieldError struct {
-	File  string
-	Field string
+	File   string
+	Field  string
+	Line   int // 1-based line in the model file, 0 if unknown
+	Column int // 1-based column, 0 if unknown
 }
 
 func (e *UnknownFieldError) Error() string {
 	msg := fmt.Sprintf("unknown field %q in frontmatter, use \"meta\" field for custom fields", e.Field)
 	if e.File != "" {
+		if e.Line > 0 {
+			return fmt.Sprintf("%s:%s: %s", e.File, position(e.Line, e.Column), msg)
+		}
 		return fmt.Sprintf("%s: %s", e.File, msg)
 	}
 	return msg
 }
+
+// position formats a line and optional column as "line" or "line:column".
+func position(line, column int) string {
+	if column > 0 {
+		return fmt.Sprintf("%d:%d", line, column)
+	}
+	return strconv.Itoa(line)
+}
@@ -2,7 +2,11 @@ package loader
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
 )
 
 func TestExtractFrontmatter_ValidBasic(t *testing.T) {
@@ -147,6 +151,75 @@ SELECT * FROM raw_orders`
 	}
 }
 
+func TestExtractFrontmatter_DriftTests(t *testing.T) {
+	content := `/*---
+name: orders
+tests:
+  - row_count_drift:
+      max_change: 0.5
+      z_score: 3
+      severity: error
+  - null_rate_drift:
+      columns: [customer_id]
+      max_change: 0.1
+      window: 5
+---*/
+
+SELECT * FROM raw_orders`
+
+	result, err := ExtractFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Config.Tests) != 2 {
+		t.Fatalf("expected 2 test configs, got %d", len(result.Config.Tests))
+	}
+
+	rc := result.Config.Tests[0].RowCountDrift
+	if rc == nil {
+		t.Fatal("expected row_count_drift test, got nil")
+	}
+	if rc.MaxChange != 0.5 || rc.ZScore != 3 || !rc.Fail {
+		t.Errorf("unexpected row_count_drift config: %+v", rc)
+	}
+
+	nr := result.Config.Tests[1].NullRateDrift
+	if nr == nil {
+		t.Fatal("expected null_rate_drift test, got nil")
+	}
+	if len(nr.Columns) != 1 || nr.Columns[0] != "customer_id" || nr.MaxChange != 0.1 || nr.Window != 5 || nr.Fail {
+		t.Errorf("unexpected null_rate_drift config: %+v", nr)
+	}
+}
+
+func TestExtractFrontmatter_InvalidDriftTests(t *testing.T) {
+	tests := []struct {
+		name    string
+		test    string
+		wantMsg string
+	}{
+		{"no threshold", "row_count_drift:\n      window: 5", "requires max_change or z_score"},
+		{"negative threshold", "null_rate_drift:\n      z_score: -1", "must not be negative"},
+		{"invalid severity", "row_count_drift:\n      max_change: 0.5\n      severity: fatal", "severity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "/*---\ntests:\n  - " + tt.test + "\n---*/\n\nSELECT 1"
+
+			_, err := ExtractFrontmatter(content)
+			var parseErr *FrontmatterParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected FrontmatterParseError, got %T: %v", err, err)
+			}
+			if !strings.Contains(parseErr.Message, tt.wantMsg) {
+				t.Errorf("expected error containing %q, got %q", tt.wantMsg, parseErr.Message)
+			}
+		})
+	}
+}
+
 func TestExtractFrontmatter_NoFrontmatter(t *testing.T) {
 	content := `SELECT * FROM orders WHERE amount > 100`
 
@@ -231,6 +304,197 @@ SELECT 1`
 	}
 }
 
+func TestExtractFrontmatter_VersionAndDeprecation(t *testing.T) {
+	content := `/*---
+name: dim_users
+version: 1
+deprecated:
+  since: "2024-06-01"
+  replacement: dim_users@v2
+---*/
+
+SELECT id FROM users`
+
+	result, err := ExtractFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg := result.Config
+
+	if cfg.Version != 1 {
+		t.Errorf("expected version 1, got %d", cfg.Version)
+	}
+
+	if cfg.Deprecated == nil {
+		t.Fatal("expected deprecation to be set")
+	}
+
+	if cfg.Deprecated.Since != "2024-06-01" {
+		t.Errorf("expected since '2024-06-01', got %q", cfg.Deprecated.Since)
+	}
+
+	if cfg.Deprecated.Replacement != "dim_users@v2" {
+		t.Errorf("expected replacement 'dim_users@v2', got %q", cfg.Deprecated.Replacement)
+	}
+}
+
+func TestExtractFrontmatter_InvalidVersion(t *testing.T) {
+	content := `/*---
+name: dim_users
+version: -1
+---*/
+
+SELECT 1`
+
+	_, err := ExtractFrontmatter(content)
+	if err == nil {
+		t.Fatal("expected error for negative version")
+	}
+
+	var parseErr *FrontmatterParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected FrontmatterParseError, got %T: %v", err, err)
+	}
+}
+
+func TestExtractFrontmatter_Group(t *testing.T) {
+	content := `/*---
+owner: alice
+group: finance
+---*/
+
+SELECT 1`
+
+	result, err := ExtractFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Config.Group != "finance" {
+		t.Errorf("expected group 'finance', got %q", result.Config.Group)
+	}
+
+	if result.Config.Owner != "alice" {
+		t.Errorf("expected owner 'alice', got %q", result.Config.Owner)
+	}
+}
+
+func TestExtractFrontmatter_Access(t *testing.T) {
+	tests := []struct {
+		access  string
+		want    core.Access
+		wantErr bool
+	}{
+		{access: "public", want: core.AccessPublic},
+		{access: "protected", want: core.AccessProtected},
+		{access: "private", want: core.AccessPrivate},
+		{access: "internal", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.access, func(t *testing.T) {
+			content := "/*---\naccess: " + tt.access + "\n---*/\n\nSELECT 1"
+
+			result, err := ExtractFrontmatter(content)
+			if tt.wantErr {
+				var parseErr *FrontmatterParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("expected FrontmatterParseError, got %T: %v", err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Config.Access != tt.want {
+				t.Errorf("expected access %q, got %q", tt.want, result.Config.Access)
+			}
+		})
+	}
+}
+
+func TestExtractFrontmatter_EnvironmentConfig(t *testing.T) {
+	content := `/*---
+materialized: table
+enabled: false
+config:
+  prod:
+    enabled: true
+    materialized: incremental
+    unique_key: id
+  staging:
+    schema: scratch
+  dev:
+    where: created_at > current_date - 30
+---*/
+
+SELECT 1`
+
+	result, err := ExtractFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		env          string
+		enabled      bool
+		materialized string
+		uniqueKey    string
+		schema       string
+		where        string
+	}{
+		{env: "dev", enabled: false, materialized: "table", where: "created_at > current_date - 30"},
+		{env: "prod", enabled: true, materialized: "incremental", uniqueKey: "id"},
+		{env: "staging", enabled: false, materialized: "table", schema: "scratch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			fc := result.Config.ForEnvironment(tt.env)
+			if fc.IsEnabled() != tt.enabled {
+				t.Errorf("expected enabled %v, got %v", tt.enabled, fc.IsEnabled())
+			}
+			if fc.Materialized != tt.materialized {
+				t.Errorf("expected materialized %q, got %q", tt.materialized, fc.Materialized)
+			}
+			if fc.UniqueKey != tt.uniqueKey {
+				t.Errorf("expected unique_key %q, got %q", tt.uniqueKey, fc.UniqueKey)
+			}
+			if fc.Schema != tt.schema {
+				t.Errorf("expected schema %q, got %q", tt.schema, fc.Schema)
+			}
+			if fc.Where != tt.where {
+				t.Errorf("expected where %q, got %q", tt.where, fc.Where)
+			}
+		})
+	}
+
+	if result.Config.Materialized != "table" {
+		t.Errorf("ForEnvironment modified the base config: materialized %q", result.Config.Materialized)
+	}
+}
+
+func TestExtractFrontmatter_EnvironmentConfigInvalidMaterialized(t *testing.T) {
+	content := `/*---
+config:
+  prod:
+    materialized: snapshot
+---*/
+
+SELECT 1`
+
+	_, err := ExtractFrontmatter(content)
+	var parseErr *FrontmatterParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected FrontmatterParseError, got %T: %v", err, err)
+	}
+	if !strings.Contains(parseErr.Message, "config.prod") {
+		t.Errorf("expected error to name config.prod, got %q", parseErr.Message)
+	}
+}
+
 func TestExtractFrontmatter_InvalidYAML(t *testing.T) {
 	content := `/*---
 name: test_model
@@ -293,6 +557,132 @@ SELECT 1`
 	}
 }
 
+func TestExtractFrontmatter_Transaction(t *testing.T) {
+	content := `/*---
+materialized: incremental
+transaction: always
+---*/
+
+SELECT 1`
+
+	result, err := ExtractFrontmatter(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Config.Transaction != core.TransactionAlways {
+		t.Errorf("expected transaction 'always', got %q", result.Config.Transaction)
+	}
+
+	_, err = ExtractFrontmatter("/*---\ntransaction: sometimes\n---*/\nSELECT 1")
+	if err == nil || !strings.Contains(err.Error(), "auto, always, never") {
+		t.Errorf("expected invalid transaction error, got %v", err)
+	}
+}
+
+func TestExtractFrontmatter_Sample(t *testing.T) {
+	result, err := ExtractFrontmatter("/*---\nsample: 0\n---*/\nSELECT 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Config.Sample == nil || *result.Config.Sample != 0 {
+		t.Errorf("expected sample 0, got %v", result.Config.Sample)
+	}
+
+	_, err = ExtractFrontmatter("/*---\nsample: -5\n---*/\nSELECT 1")
+	if err == nil || !strings.Contains(err.Error(), "invalid sample: -5") {
+		t.Errorf("expected invalid sample error, got %v", err)
+	}
+}
+
+func TestExtractFrontmatter_ResourceClass(t *testing.T) {
+	result, err := ExtractFrontmatter("/*---\nresource_class: heavy\n---*/\nSELECT 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Config.ResourceClass != "heavy" {
+		t.Errorf("expected resource_class heavy, got %q", result.Config.ResourceClass)
+	}
+}
+
+func TestExtractFrontmatter_PII(t *testing.T) {
+	result, err := ExtractFrontmatter("/*---\npii: [email, phone]\n---*/\nSELECT id, email, phone FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if pii := result.Config.PII; len(pii) != 2 || pii[0] != "email" || pii[1] != "phone" {
+		t.Errorf("expected pii [email phone], got %v", result.Config.PII)
+	}
+}
+
+func TestExtractFrontmatter_PartitionBy(t *testing.T) {
+	result, err := ExtractFrontmatter("/*---\nmaterialized: incremental\npartition_by:\n  column: order_date\n---*/\nSELECT 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := core.PartitionSpec{Column: "order_date", Grain: core.PartitionGrainDay}
+	if p := result.Config.PartitionBy; p == nil || *p != want {
+		t.Errorf("expected partition_by %v, got %v", want, result.Config.PartitionBy)
+	}
+
+	tests := []struct {
+		content string
+		wantErr string
+	}{
+		{"/*---\npartition_by:\n  column: order_date\n  grain: week\n---*/\nSELECT 1", "hour, day, month, year"},
+		{"/*---\npartition_by:\n  grain: month\n---*/\nSELECT 1", "partition_by.column is required"},
+		{"/*---\nmaterialized: view\npartition_by:\n  column: order_date\n---*/\nSELECT 1", "only valid for tables and incremental models"},
+	}
+	for _, tt := range tests {
+		_, err := ExtractFrontmatter(tt.content)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+		}
+	}
+}
+
+func TestExtractFrontmatter_Retention(t *testing.T) {
+	result, err := ExtractFrontmatter("/*---\nmaterialized: incremental\nretention:\n  column: loaded_at\n  keep: 90d\n---*/\nSELECT 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := core.RetentionPolicy{Column: "loaded_at", Keep: core.RetentionPeriod{Count: 90, Unit: core.RetentionDay}}
+	if r := result.Config.Retention; r == nil || *r != want {
+		t.Errorf("expected retention %v, got %v", want, result.Config.Retention)
+	}
+
+	// The column defaults to the partition column
+	result, err = ExtractFrontmatter("/*---\npartition_by:\n  column: order_date\n  grain: month\nretention:\n  keep: 6mo\n---*/\nSELECT 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want = core.RetentionPolicy{Column: "order_date", Keep: core.RetentionPeriod{Count: 6, Unit: core.RetentionMonth}}
+	if r := result.Config.Retention; r == nil || *r != want {
+		t.Errorf("expected retention %v, got %v", want, result.Config.Retention)
+	}
+
+	tests := []struct {
+		content string
+		wantErr string
+	}{
+		{"/*---\nretention:\n  keep: 90d\n---*/\nSELECT 1", "retention.column is required"},
+		{"/*---\nretention:\n  column: loaded_at\n  keep: 90 days\n---*/\nSELECT 1", "unit must be one of h, d, w, mo, y"},
+		{"/*---\nretention:\n  column: loaded_at\n---*/\nSELECT 1", "must be a positive number of units"},
+		{"/*---\nmaterialized: view\nretention:\n  column: loaded_at\n  keep: 90d\n---*/\nSELECT 1", "only valid for tables and incremental models"},
+	}
+	for _, tt := range tests {
+		_, err := ExtractFrontmatter(tt.content)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+		}
+	}
+}
+
 func TestApplyDefaults(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -370,6 +760,11 @@ func TestFrontmatterParseError_Error(t *testing.T) {
 			err:  FrontmatterParseError{File: "models/orders.sql", Line: 5, Message: "invalid YAML"},
 			want: "models/orders.sql:5: invalid YAML",
 		},
+		{
+			name: "with file, line and column",
+			err:  FrontmatterParseError{File: "models/orders.sql", Line: 5, Column: 3, Message: "invalid YAML"},
+			want: "models/orders.sql:5:3: invalid YAML",
+		},
 		{
 			name: "with file only",
 			err:  FrontmatterParseError{File: "models/orders.sql", Message: "invalid YAML"},
@@ -402,6 +797,11 @@ func TestUnknownFieldError_Error(t *testing.T) {
 			err:  UnknownFieldError{File: "models/orders.sql", Field: "custom"},
 			want: `models/orders.sql: unknown field "custom" in frontmatter, use "meta" field for custom fields`,
 		},
+		{
+			name: "with file and position",
+			err:  UnknownFieldError{File: "models/orders.sql", Field: "custom", Line: 3, Column: 1},
+			want: `models/orders.sql:3:1: unknown field "custom" in frontmatter, use "meta" field for custom fields`,
+		},
 		{
 			name: "without file",
 			err:  UnknownFieldError{Field: "custom"},
@@ -473,3 +873,74 @@ SELECT * FROM aggregated`
 		t.Error("expected SQL to contain the full query")
 	}
 }
+
+func TestValidateFrontmatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string // "line:column: message" of each error
+	}{
+		{
+			name:    "valid",
+			content: "/*---\nname: orders\nmaterialized: view\ntags: [a]\n---*/\nSELECT 1",
+		},
+		{
+			name:    "no frontmatter",
+			content: "SELECT 1",
+		},
+		{
+			name:    "all problems are reported",
+			content: "\n/*---\nname: orders\ncolour: red\nmaterialized: snapshot\ntags: a\n---*/\nSELECT 1",
+			want: []string{
+				`4:1: unknown field "colour" in frontmatter, use "meta" field for custom fields`,
+				`5:15: invalid materialized value: "snapshot", must be one of: table, view, incremental, external`,
+				`6:7: tags: expected a list, got a string`,
+			},
+		},
+		{
+			name:    "nested fields",
+			content: "/*---\ntests:\n  - unique: [id]\n    not_nul: [id]\nconfig:\n  prod:\n    materialized: snapshot\nenabled: maybe\n---*/\nSELECT 1",
+			want: []string{
+				`4:5: unknown field "not_nul" in tests[0], expected one of: accepted_values, not_null, null_rate_drift, row_count_drift, unique`,
+				`7:19: invalid config.prod.materialized value: "snapshot", must be one of: table, view, incremental`,
+				`8:10: enabled: expected a boolean, got a string`,
+			},
+		},
+		{
+			name:    "YAML 1.1 booleans and integral floats decode",
+			content: "/*---\nenabled: yes\nfull_refresh: off\nversion: 2.0\n---*/\nSELECT 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range ValidateFrontmatter(tt.content) {
+				switch e := err.(type) {
+				case *UnknownFieldError:
+					got = append(got, fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Error()))
+				case *FrontmatterParseError:
+					got = append(got, fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message))
+				default:
+					t.Fatalf("unexpected error type %T: %v", err, err)
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("ValidateFrontmatter() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestExtractFrontmatter_ErrorPosition(t *testing.T) {
+	content := "/*---\nname: orders\nmaterialized: snapshot\n---*/\nSELECT 1"
+
+	_, err := ExtractFrontmatter(content)
+	var parseErr *FrontmatterParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected FrontmatterParseError, got %T: %v", err, err)
+	}
+	if parseErr.Line != 3 || parseErr.Column != 15 {
+		t.Errorf("position = %d:%d, want 3:15", parseErr.Line, parseErr.Column)
+	}
+}
@@ -6,9 +6,9 @@ import (
 	"testing"
 
 	"github.com/leapstack-labs/leapsql/internal/dag"
-	"github.com/leapstack-labs/leapsql/internal/lineage"
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/dialect"
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
 
 	// Import duckdb dialect so it registers itself
 	_ "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
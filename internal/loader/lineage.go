@@ -4,7 +4,7 @@ package loader
 import "github.com/leapstack-labs/leapsql/pkg/core"
 
 // LineageExtractor extracts table/column lineage from SQL.
-// This interface allows the loader to be decoupled from the internal/lineage package.
+// This interface allows the loader to be decoupled from the pkg/lineage package.
 // Implementations are wired in internal/engine.
 type LineageExtractor interface {
 	// Extract extracts lineage information from the given SQL.
@@ -17,6 +17,9 @@ type LineageResult struct {
 	// Sources contains all source table names (deduplicated, sorted)
 	Sources []string
 
+	// Files contains the sources that are files read by table functions
+	Files []string
+
 	// Columns contains lineage information for each output column
 	Columns []core.ColumnInfo
 
@@ -11,6 +11,8 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/leapstack-labs/leapsql/pkg/core"
@@ -25,6 +27,10 @@ type Loader struct {
 	// LineageExtractor extracts table/column lineage from SQL (optional)
 	// If nil, lineage extraction will be skipped.
 	LineageExtractor LineageExtractor
+	// Project namespaces model paths when loading a workspace project (optional)
+	Project string
+	// Environment selects the frontmatter config overrides to apply (optional)
+	Environment string
 }
 
 // NewLoader creates a new loader with the given base directory and dialect.
@@ -45,6 +51,10 @@ var (
 	endifPattern = regexp.MustCompile(`--\s*#endif`)
 	// Key-value patterns for config
 	kvPattern = regexp.MustCompile(`(\w+)\s*=\s*'([^']*)'`)
+	// {{ ref('model') }}, {{ ref('project', 'model') }}, {{ ref('model', v=2) }}
+	refPattern = regexp.MustCompile(`\{\{\s*ref\(([^)]*)\)\s*\}\}`)
+	// A single ref() argument: a quoted name or a v=N version pin
+	refArgPattern = regexp.MustCompile(`^\s*(?:['"]([^'"]+)['"]|v\s*=\s*(\d+))\s*$`)
 )
 
 // ParseFile parses a single SQL model file.
@@ -72,6 +82,7 @@ func (p *Loader) ParseContent(filePath string, content string) (*core.Model, err
 	// Derive name and path from file path
 	model.Name = strings.TrimSuffix(filepath.Base(filePath), ".sql")
 	model.Path = p.filePathToModelPath(filePath)
+	model.Project = p.Project
 
 	// Try to extract YAML frontmatter first (new preferred format)
 	frontmatter, err := ExtractFrontmatter(content)
@@ -85,7 +96,7 @@ func (p *Loader) ParseContent(filePath string, content string) (*core.Model, err
 
 	// Apply frontmatter config if present
 	if frontmatter.HasYAML && frontmatter.Config != nil {
-		fc := frontmatter.Config
+		fc := frontmatter.Config.ForEnvironment(p.Environment)
 		if fc.Name != "" {
 			model.Name = fc.Name
 		}
@@ -95,10 +106,13 @@ func (p *Loader) ParseContent(filePath string, content string) (*core.Model, err
 		if fc.UniqueKey != "" {
 			model.UniqueKey = fc.UniqueKey
 		}
+		model.Description = fc.Description
 		model.Owner = fc.Owner
+		model.Group = fc.Group
 		if fc.Schema != "" {
 			model.Schema = fc.Schema
 		}
+		model.Database = fc.Database
 		if len(fc.Tags) > 0 {
 			model.Tags = fc.Tags
 		}
@@ -108,6 +122,22 @@ func (p *Loader) ParseContent(filePath string, content string) (*core.Model, err
 		if len(fc.Tests) > 0 {
 			model.Tests = fc.Tests
 		}
+		model.Version = fc.Version
+		model.Deprecated = fc.Deprecated
+		model.Access = fc.Access
+		model.Disabled = !fc.IsEnabled()
+		model.AuditColumns = fc.AuditColumns
+		model.FullRefresh = fc.FullRefresh
+		model.Transaction = fc.Transaction
+		model.Sample = fc.Sample
+		model.ResourceClass = fc.ResourceClass
+		model.PII = fc.PII
+		model.Where = fc.Where
+		model.PartitionBy = fc.PartitionBy
+		model.Retention = fc.Retention
+		if fc.Materialized == core.MaterializationExternal {
+			model.External = fc.External
+		}
 	}
 
 	// Continue parsing legacy pragmas from the SQL content
@@ -170,14 +200,55 @@ func (p *Loader) ParseContent(filePath string, content string) (*core.Model, err
 
 	model.SQL = strings.TrimSpace(strings.Join(sqlLines, "\n"))
 
+	// Setup and teardown statements around the model's query
+	if pre, query, post := splitModelSQL(model.SQL); len(pre)+len(post) > 0 {
+		model.SQL = query
+		model.PreStatements = pre
+		model.PostStatements = post
+	}
+
+	// External models are built by a command: their declared inputs and
+	// columns stand in for the lineage of SQL
+	if model.External != nil {
+		if model.SQL != "" {
+			return nil, fmt.Errorf("external model %s has SQL; external models are built by external.command only", model.Path)
+		}
+		model.Sources = model.External.Inputs
+		for i, name := range model.External.Columns {
+			model.Columns = append(model.Columns, core.ColumnInfo{Name: name, Index: i, TransformType: core.TransformGenerated})
+		}
+		return model, nil
+	}
+
+	// Explicit model references: {{ ref('project', 'model', v=2) }}
+	statements := slices.Concat(model.PreStatements, []string{model.SQL}, model.PostStatements)
+	for _, matches := range refPattern.FindAllStringSubmatch(strings.Join(statements, "\n"), -1) {
+		id, ok := parseRef(matches[1])
+		if ok && !slices.Contains(model.Refs, id) {
+			model.Refs = append(model.Refs, id)
+		}
+	}
+
 	// Auto-detect table sources and column lineage using the lineage extractor
 	// Only if dialect and lineage extractor are available
 	if model.SQL != "" && p.Dialect != nil && p.LineageExtractor != nil {
-		result, err := p.extractLineage(model.SQL)
+		// ref() calls become quoted model IDs so lineage can parse the SQL
+		// and its sources resolve to the referenced models
+		lineageSQL := refPattern.ReplaceAllStringFunc(model.SQL, func(call string) string {
+			if id, ok := parseRef(refPattern.FindStringSubmatch(call)[1]); ok {
+				return `"` + id + `"`
+			}
+			return call
+		})
+		result, err := p.extractLineage(lineageSQL)
 		if err == nil {
 			model.Sources = result.Sources
+			model.Files = result.Files
 			model.Columns = result.Columns
 			model.UsesSelectStar = result.UsesSelectStar
+			if model.AuditColumns && model.Materialized != "view" {
+				model.Columns = appendAuditColumns(model.Columns)
+			}
 		}
 		// If lineage extraction fails, we continue without sources/columns
 		// The model may have syntax errors or use unsupported SQL features
@@ -186,9 +257,23 @@ func (p *Loader) ParseContent(filePath string, content string) (*core.Model, err
 	return model, nil
 }
 
+// appendAuditColumns adds the audit columns to a model's column lineage,
+// marked as generated since they have no source in the model's SQL.
+func appendAuditColumns(columns []core.ColumnInfo) []core.ColumnInfo {
+	for _, name := range core.AuditColumnNames {
+		columns = append(columns, core.ColumnInfo{
+			Name:          name,
+			Index:         len(columns),
+			TransformType: core.TransformGenerated,
+		})
+	}
+	return columns
+}
+
 // lineageResult holds both table sources and column lineage information.
 type lineageResult struct {
 	Sources        []string
+	Files          []string
 	Columns        []core.ColumnInfo
 	UsesSelectStar bool
 }
@@ -209,6 +294,7 @@ func (p *Loader) extractLineage(sql string) (*lineageResult, error) {
 
 	return &lineageResult{
 		Sources:        result.Sources,
+		Files:          result.Files,
 		Columns:        result.Columns,
 		UsesSelectStar: result.UsesSelectStar,
 	}, nil
@@ -232,13 +318,41 @@ func (p *Loader) parseConfig(configStr string, model *core.Model) {
 	}
 }
 
+// parseRef converts the arguments of a ref() call to the referenced model ID.
+// e.g., "'core', 'dim_users', v=2" -> "core/dim_users@v2"
+func parseRef(args string) (string, bool) {
+	var names []string
+	version := 0
+	for _, arg := range strings.Split(args, ",") {
+		matches := refArgPattern.FindStringSubmatch(arg)
+		switch {
+		case matches == nil:
+			return "", false
+		case matches[2] != "":
+			version, _ = strconv.Atoi(matches[2])
+		default:
+			names = append(names, matches[1])
+		}
+	}
+
+	switch len(names) {
+	case 1:
+		return core.VersionedName(names[0], version), true
+	case 2:
+		return core.ModelID(names[0], core.VersionedName(names[1], version)), true
+	default:
+		return "", false
+	}
+}
+
 // filePathToModelPath converts a file path to a model path.
 // e.g., "/base/staging/customers.sql" -> "staging.customers"
+// With a Project set, the path is namespaced: "core/staging.customers".
 func (p *Loader) filePathToModelPath(filePath string) string {
 	relPath, err := filepath.Rel(p.BaseDir, filePath)
 	if err != nil {
 		// Fallback to just the filename
-		return strings.TrimSuffix(filepath.Base(filePath), ".sql")
+		return core.ModelID(p.Project, strings.TrimSuffix(filepath.Base(filePath), ".sql"))
 	}
 
 	// Remove .sql extension
@@ -246,7 +360,7 @@ func (p *Loader) filePathToModelPath(filePath string) string {
 
 	// Convert path separators to dots
 	parts := strings.Split(relPath, string(filepath.Separator))
-	return strings.Join(parts, ".")
+	return core.ModelID(p.Project, strings.Join(parts, "."))
 }
 
 // Scanner scans a directory for SQL model files.
@@ -5,9 +5,9 @@ import (
 	"path/filepath"
 	"testing"
 
-	"github.com/leapstack-labs/leapsql/internal/lineage"
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/dialect"
+	"github.com/leapstack-labs/leapsql/pkg/lineage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -23,7 +23,7 @@ func testDialect(t *testing.T) *core.Dialect {
 	return d
 }
 
-// testLineageExtractor implements LineageExtractor for tests using internal/lineage.
+// testLineageExtractor implements LineageExtractor for tests using pkg/lineage.
 type testLineageExtractor struct{}
 
 func (e *testLineageExtractor) Extract(sql string, d *core.Dialect) (*LineageResult, error) {
@@ -284,24 +284,160 @@ func TestParser_filePathToModelPath(t *testing.T) {
 	tests := []struct {
 		name     string
 		baseDir  string
+		project  string
 		filePath string
 		expected string
 	}{
-		{"staging model", "/models", "/models/staging/users.sql", "staging.users"},
-		{"nested marts model", "/models", "/models/marts/core/orders.sql", "marts.core.orders"},
-		{"root model", "/models", "/models/users.sql", "users"},
-		{"different base", "/app/models", "/app/models/staging/customers.sql", "staging.customers"},
+		{"staging model", "/models", "", "/models/staging/users.sql", "staging.users"},
+		{"nested marts model", "/models", "", "/models/marts/core/orders.sql", "marts.core.orders"},
+		{"root model", "/models", "", "/models/users.sql", "users"},
+		{"different base", "/app/models", "", "/app/models/staging/customers.sql", "staging.customers"},
+		{"workspace project", "/models", "app", "/models/staging/users.sql", "app/staging.users"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			p := NewLoader(tt.baseDir, nil) // dialect not needed for path conversion
+			p.Project = tt.project
 			result := p.filePathToModelPath(tt.filePath)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
+func TestParser_ParseContent_Refs(t *testing.T) {
+	tests := []struct {
+		name        string
+		sql         string
+		wantRefs    []string
+		wantSources []string
+	}{
+		{
+			name:        "single ref",
+			sql:         `SELECT id FROM {{ ref('core', 'stg_customers') }}`,
+			wantRefs:    []string{"core/stg_customers"},
+			wantSources: []string{"core/stg_customers"},
+		},
+		{
+			name: "repeated ref with double quotes",
+			sql: `SELECT a.id FROM {{ ref("core", "stg_orders") }} a
+JOIN {{ ref('core', 'stg_orders') }} b ON a.id = b.id
+JOIN raw_payments p ON a.id = p.order_id`,
+			wantRefs:    []string{"core/stg_orders"},
+			wantSources: []string{"core/stg_orders", "raw_payments"},
+		},
+		{
+			name:        "no refs",
+			sql:         `SELECT id FROM raw_customers`,
+			wantSources: []string{"raw_customers"},
+		},
+		{
+			name:        "model ref",
+			sql:         `SELECT id FROM {{ ref('dim_users') }}`,
+			wantRefs:    []string{"dim_users"},
+			wantSources: []string{"dim_users"},
+		},
+		{
+			name:        "pinned versions",
+			sql:         `SELECT a.id FROM {{ ref('dim_users', v=1) }} a JOIN {{ ref('core', 'dim_users', v = 2) }} b ON a.id = b.id`,
+			wantRefs:    []string{"dim_users@v1", "core/dim_users@v2"},
+			wantSources: []string{"dim_users@v1", "core/dim_users@v2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testLoader(t, "/models")
+			p.Project = "billing"
+			model, err := p.ParseContent("/models/invoices.sql", tt.sql)
+			require.NoError(t, err)
+
+			assert.Equal(t, "billing/invoices", model.Path)
+			assert.Equal(t, "billing", model.Project)
+			assert.Equal(t, tt.wantRefs, model.Refs)
+			assert.ElementsMatch(t, tt.wantSources, model.Sources)
+		})
+	}
+}
+
+func TestParser_ParseContent_Statements(t *testing.T) {
+	content := `/*---
+materialized: table
+---*/
+SET threads = 4;
+CREATE TEMP MACRO cents(x) AS x * 100;
+
+SELECT o.id, cents(o.amount) AS amount_cents
+FROM raw_orders o
+JOIN {{ ref('customers') }} c ON c.id = o.customer_id;
+
+DELETE FROM {{ this }} WHERE id IN (SELECT id FROM {{ ref('refunds') }});
+`
+	model, err := testLoader(t, "/models").ParseContent("/models/orders.sql", content)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"SET threads = 4", "CREATE TEMP MACRO cents(x) AS x * 100"}, model.PreStatements)
+	assert.Equal(t, "SELECT o.id, cents(o.amount) AS amount_cents\nFROM raw_orders o\nJOIN {{ ref('customers') }} c ON c.id = o.customer_id", model.SQL)
+	assert.Equal(t, []string{"DELETE FROM {{ this }} WHERE id IN (SELECT id FROM {{ ref('refunds') }})"}, model.PostStatements)
+
+	// Lineage comes from the query, dependencies from every statement
+	assert.ElementsMatch(t, []string{"raw_orders", "customers"}, model.Sources)
+	assert.Equal(t, []string{"customers", "refunds"}, model.Refs)
+	require.Len(t, model.Columns, 2)
+	assert.Equal(t, "amount_cents", model.Columns[1].Name)
+}
+
+func TestParser_ParseContent_External(t *testing.T) {
+	content := `/*---
+external:
+  command: [python, score.py]
+  env:
+    MODEL_VERSION: "3"
+  inputs: [stg_users]
+  columns: [user_id, score]
+---*/
+`
+	model, err := testLoader(t, "/models").ParseContent("/models/ml/user_scores.sql", content)
+	require.NoError(t, err)
+
+	assert.Equal(t, core.MaterializationExternal, model.Materialized)
+	require.NotNil(t, model.External)
+	assert.Equal(t, []string{"python", "score.py"}, model.External.Command)
+	assert.Equal(t, map[string]string{"MODEL_VERSION": "3"}, model.External.Env)
+	assert.Equal(t, []string{"stg_users"}, model.Sources)
+	require.Len(t, model.Columns, 2)
+	assert.Equal(t, "score", model.Columns[1].Name)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "missing command",
+			content: "/*---\nmaterialized: external\n---*/\n",
+			wantErr: "materialized: external requires an external.command",
+		},
+		{
+			name:    "external block on a table",
+			content: "/*---\nmaterialized: table\nexternal:\n  command: [./build.sh]\n---*/\nSELECT 1",
+			wantErr: "external is only valid for external models",
+		},
+		{
+			name:    "SQL in an external model",
+			content: "/*---\nexternal:\n  command: [./build.sh]\n---*/\nSELECT 1",
+			wantErr: "external models are built by external.command only",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := testLoader(t, "/models").ParseContent("/models/bad.sql", tt.content)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
 func TestScanner_ScanDir(t *testing.T) {
 	// Create temp directory with test models
 	tmpDir, err := os.MkdirTemp("", "parser-test")
@@ -401,3 +537,90 @@ func TestParser_ParseContent_ColumnLineage(t *testing.T) {
 	assert.Equal(t, core.TransformExpression, col.TransformType)
 	assert.Equal(t, "sum", col.Function)
 }
+
+func TestParser_ParseContent_AuditColumns(t *testing.T) {
+	tests := []struct {
+		name        string
+		frontmatter string
+		wantColumns []string
+	}{
+		{
+			name:        "table",
+			frontmatter: "audit_columns: true",
+			wantColumns: []string{"id", "amount", core.AuditColumnLoadedAt, core.AuditColumnRunID, core.AuditColumnModelHash},
+		},
+		{
+			name:        "disabled",
+			frontmatter: "audit_columns: false",
+			wantColumns: []string{"id", "amount"},
+		},
+		{
+			name:        "view",
+			frontmatter: "audit_columns: true\nmaterialized: view",
+			wantColumns: []string{"id", "amount"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testLoader(t, "/models")
+			content := "/*---\n" + tt.frontmatter + "\n---*/\nSELECT id, amount FROM orders"
+
+			model, err := p.ParseContent("/models/orders.sql", content)
+			require.NoError(t, err)
+
+			var names []string
+			for i, col := range model.Columns {
+				names = append(names, col.Name)
+				assert.Equal(t, i, col.Index)
+				if i >= 2 {
+					assert.Equal(t, core.TransformGenerated, col.TransformType, "%s should be generated", col.Name)
+					assert.Empty(t, col.Sources)
+				}
+			}
+			assert.Equal(t, tt.wantColumns, names)
+		})
+	}
+}
+
+func TestParser_ParseContent_FullRefresh(t *testing.T) {
+	tests := []struct {
+		name        string
+		frontmatter string
+		wantSet     bool
+		want        bool
+	}{
+		{
+			name:        "unset follows the run",
+			frontmatter: "materialized: incremental",
+		},
+		{
+			name:        "protected",
+			frontmatter: "materialized: incremental\nfull_refresh: false",
+			wantSet:     true,
+			want:        false,
+		},
+		{
+			name:        "always",
+			frontmatter: "materialized: incremental\nfull_refresh: true",
+			wantSet:     true,
+			want:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testLoader(t, "/models")
+			content := "/*---\n" + tt.frontmatter + "\n---*/\nSELECT id FROM orders"
+
+			model, err := p.ParseContent("/models/orders.sql", content)
+			require.NoError(t, err)
+			if !tt.wantSet {
+				assert.Nil(t, model.FullRefresh)
+				return
+			}
+			require.NotNil(t, model.FullRefresh)
+			assert.Equal(t, tt.want, *model.FullRefresh)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FindSeeds lists the seed files of a seeds directory: CSV files, each loaded
+// into a table named after the file. It returns the file paths keyed by table
+// name, and no seeds if the directory does not exist.
+func FindSeeds(dir string) (map[string]string, error) {
+	seeds := make(map[string]string)
+	if dir == "" {
+		return seeds, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return seeds, nil
+		}
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".csv") {
+			continue
+		}
+		seeds[strings.TrimSuffix(entry.Name(), ".csv")] = filepath.Join(dir, entry.Name())
+	}
+	return seeds, nil
+}
@@ -0,0 +1,29 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindSeeds(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"countries.csv", "currencies.csv", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("id\n1\n"), 0o600))
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "archive.csv"), 0o750))
+
+	seeds, err := FindSeeds(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"countries":  filepath.Join(dir, "countries.csv"),
+		"currencies": filepath.Join(dir, "currencies.csv"),
+	}, seeds)
+
+	seeds, err = FindSeeds(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, seeds)
+}
@@ -0,0 +1,151 @@
+package loader
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SplitStatements splits SQL into its statements at top-level semicolons.
+// Semicolons in string literals, quoted identifiers, comments, dollar-quoted
+// bodies ($$ ... $$, $fn$ ... $fn$) and template blocks ({{ }}, {* *}) do not
+// split. Statements are trimmed and empty statements are dropped.
+func SplitStatements(sql string) []string {
+	var statements []string
+	start := 0
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(sql, i, c)
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			i = skipTo(sql, i, "\n")
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			i = skipTo(sql, i+2, "*/")
+		case c == '{' && strings.HasPrefix(sql[i:], "{{"):
+			i = skipTo(sql, i+2, "}}")
+		case c == '{' && strings.HasPrefix(sql[i:], "{*"):
+			i = skipTo(sql, i+2, "*}")
+		case c == '$':
+			if tag, ok := dollarTag(sql[i:]); ok {
+				i = skipTo(sql, i+len(tag), tag)
+			}
+		case c == ';':
+			statements = appendStatement(statements, sql[start:i])
+			start = i + 1
+		}
+	}
+	return appendStatement(statements, sql[start:])
+}
+
+// appendStatement appends a statement unless it is empty or only comments.
+func appendStatement(statements []string, stmt string) []string {
+	stmt = strings.TrimSpace(stmt)
+	if stripLeadingComments(stmt) == "" {
+		return statements
+	}
+	return append(statements, stmt)
+}
+
+// skipQuoted returns the index of the quote closing the quoted text starting
+// at i. A doubled quote is an escaped quote.
+func skipQuoted(sql string, i int, quote byte) int {
+	for j := i + 1; j < len(sql); j++ {
+		if sql[j] != quote {
+			continue
+		}
+		if j+1 < len(sql) && sql[j+1] == quote {
+			j++
+			continue
+		}
+		return j
+	}
+	return len(sql)
+}
+
+// skipTo returns the index of the last byte of the first end after i, or the
+// end of sql if there is none.
+func skipTo(sql string, i int, end string) int {
+	if j := strings.Index(sql[i:], end); j >= 0 {
+		return i + j + len(end) - 1
+	}
+	return len(sql)
+}
+
+// dollarTag returns the dollar-quote tag ($$ or $tag$) at the start of s.
+func dollarTag(s string) (string, bool) {
+	for j := 1; j < len(s); j++ {
+		switch c := rune(s[j]); {
+		case c == '$':
+			return s[:j+1], true
+		case c != '_' && !unicode.IsLetter(c) && !(j > 1 && unicode.IsDigit(c)):
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// stripLeadingComments removes the comments and whitespace at the start of a
+// statement.
+func stripLeadingComments(stmt string) string {
+	for {
+		stmt = strings.TrimSpace(stmt)
+		switch {
+		case strings.HasPrefix(stmt, "--"):
+			i := strings.Index(stmt, "\n")
+			if i < 0 {
+				return ""
+			}
+			stmt = stmt[i+1:]
+		case strings.HasPrefix(stmt, "/*"):
+			i := strings.Index(stmt, "*/")
+			if i < 0 {
+				return ""
+			}
+			stmt = stmt[i+2:]
+		default:
+			return stmt
+		}
+	}
+}
+
+// isQuery reports whether a statement is a query: a SELECT, WITH, FROM
+// (DuckDB) or VALUES statement, or a parenthesized query.
+func isQuery(stmt string) bool {
+	stmt = stripLeadingComments(stmt)
+	if strings.HasPrefix(stmt, "(") {
+		return true
+	}
+	end := strings.IndexFunc(stmt, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end < 0 {
+		end = len(stmt)
+	}
+	switch strings.ToUpper(stmt[:end]) {
+	case "SELECT", "WITH", "FROM", "VALUES":
+		return true
+	}
+	return false
+}
+
+// splitModelSQL splits a model's SQL into the statements run before its
+// query, the query and the statements run after it. The query is the last
+// query statement, or the last statement if none is a query.
+func splitModelSQL(sql string) (pre []string, query string, post []string) {
+	statements := SplitStatements(sql)
+	if len(statements) == 0 {
+		return nil, "", nil
+	}
+
+	main := len(statements) - 1
+	for i := len(statements) - 1; i >= 0; i-- {
+		if isQuery(statements[i]) {
+			main = i
+			break
+		}
+	}
+	if main > 0 {
+		pre = statements[:main]
+	}
+	if main < len(statements)-1 {
+		post = statements[main+1:]
+	}
+	return pre, statements[main], post
+}
@@ -0,0 +1,106 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "single statement",
+			sql:  "SELECT 1",
+			want: []string{"SELECT 1"},
+		},
+		{
+			name: "trailing semicolon",
+			sql:  "SELECT 1;\n",
+			want: []string{"SELECT 1"},
+		},
+		{
+			name: "several statements",
+			sql:  "SET threads = 4;\nCREATE TEMP MACRO add(a, b) AS a + b;\nSELECT add(1, 2)",
+			want: []string{"SET threads = 4", "CREATE TEMP MACRO add(a, b) AS a + b", "SELECT add(1, 2)"},
+		},
+		{
+			name: "semicolons in strings and identifiers",
+			sql:  `SELECT 'a;b', 'it''s;', "x;y" FROM t; SELECT 2`,
+			want: []string{`SELECT 'a;b', 'it''s;', "x;y" FROM t`, "SELECT 2"},
+		},
+		{
+			name: "semicolons in comments",
+			sql:  "-- setup; then query\nSET x = 1; /* ; */ SELECT 1",
+			want: []string{"-- setup; then query\nSET x = 1", "/* ; */ SELECT 1"},
+		},
+		{
+			name: "semicolons in dollar-quoted bodies",
+			sql:  "CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql; SELECT f() + $1",
+			want: []string{"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql", "SELECT f() + $1"},
+		},
+		{
+			name: "semicolons in template blocks",
+			sql:  "{* x = ';' *}SELECT '{{ x }}', {{ \";\" }}",
+			want: []string{"{* x = ';' *}SELECT '{{ x }}', {{ \";\" }}"},
+		},
+		{
+			name: "empty and comment-only statements are dropped",
+			sql:  ";; SELECT 1; -- done\n",
+			want: []string{"SELECT 1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SplitStatements(tt.sql))
+		})
+	}
+}
+
+func TestSplitModelSQL(t *testing.T) {
+	tests := []struct {
+		name      string
+		sql       string
+		wantPre   []string
+		wantQuery string
+		wantPost  []string
+	}{
+		{
+			name:      "query only",
+			sql:       "SELECT 1",
+			wantQuery: "SELECT 1",
+		},
+		{
+			name:      "pre and post statements",
+			sql:       "SET threads = 4;\n-- the model\nWITH a AS (SELECT 1) SELECT * FROM a;\nANALYZE {{ this }}",
+			wantPre:   []string{"SET threads = 4"},
+			wantQuery: "-- the model\nWITH a AS (SELECT 1) SELECT * FROM a",
+			wantPost:  []string{"ANALYZE {{ this }}"},
+		},
+		{
+			name:      "last query wins",
+			sql:       "CREATE TEMP TABLE t AS SELECT 1 AS n; SELECT 2; FROM t",
+			wantPre:   []string{"CREATE TEMP TABLE t AS SELECT 1 AS n", "SELECT 2"},
+			wantQuery: "FROM t",
+		},
+		{
+			name:      "no query",
+			sql:       "SET x = 1; PIVOT t ON c",
+			wantPre:   []string{"SET x = 1"},
+			wantQuery: "PIVOT t ON c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pre, query, post := splitModelSQL(tt.sql)
+			assert.Equal(t, tt.wantPre, pre)
+			assert.Equal(t, tt.wantQuery, query)
+			assert.Equal(t, tt.wantPost, post)
+		})
+	}
+}
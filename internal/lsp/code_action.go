@@ -85,12 +85,14 @@ func (s *Server) getCodeActions(params CodeActionParams) []CodeAction {
 			// when onlyQuickFix is true
 			_ = onlyQuickFix
 
-			// Convert lint.Fix to LSP CodeAction
+			// Convert lint.Fix to LSP CodeAction. Suggestions may change
+			// semantics, so they are never marked as preferred and editors
+			// won't apply them on their own.
 			action := CodeAction{
 				Title:       fix.Description,
 				Kind:        CodeActionKindQuickFix,
 				Diagnostics: []Diagnostic{diag},
-				IsPreferred: len(fixes) == 1, // Single fix is preferred
+				IsPreferred: len(fixes) == 1 && fix.Kind == lint.FixSafe, // Single safe fix is preferred
 				Edit: &WorkspaceEdit{
 					Changes: map[string][]TextEdit{
 						params.TextDocument.URI: convertTextEdits(fix.TextEdits),
@@ -102,9 +104,27 @@ func (s *Server) getCodeActions(params CodeActionParams) []CodeAction {
 		}
 	}
 
+	if wantsRefactors(params.Context.Only) {
+		actions = append(actions, s.extractActions(params)...)
+	}
+
 	return actions
 }
 
+// wantsRefactors reports whether a client asking for code actions of the
+// kinds only wants extract refactorings.
+func wantsRefactors(only []CodeActionKind) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, kind := range only {
+		if kind == CodeActionKindRefactor || kind == CodeActionKindRefactorExtract {
+			return true
+		}
+	}
+	return false
+}
+
 // convertTextEdits converts lint.TextEdit to LSP TextEdit.
 func convertTextEdits(edits []lint.TextEdit) []TextEdit {
 	result := make([]TextEdit, len(edits))
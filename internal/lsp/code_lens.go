@@ -0,0 +1,267 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/server"
+)
+
+// Commands run by the code lenses of model files. They take the model's path
+// as argument and are executed by the daemon serving the project.
+const (
+	CommandRunModel     = "leapsql.runModel"
+	CommandPreviewModel = "leapsql.previewModel"
+	CommandShowLineage  = "leapsql.showLineage"
+	CommandShowCompiled = "leapsql.showCompiled"
+)
+
+// commands are the commands the server executes.
+var commands = []string{CommandRunModel, CommandPreviewModel, CommandShowLineage, CommandShowCompiled}
+
+// compiledScheme is the URI scheme of the virtual documents holding the
+// compiled SQL of models, kept up to date as their files are saved.
+const compiledScheme = "leapsql-compiled://"
+
+// previewRows is the number of rows the preview code lens shows.
+const previewRows = 100
+
+// errNoDaemon is returned by commands when no daemon serves the project.
+var errNoDaemon = errors.New("no daemon serves the project: start one with 'leapsql serve'")
+
+// getCodeLenses returns the code lenses at the top of a model file: run the
+// model, preview its rows, show its lineage and show its compiled SQL.
+func (s *Server) getCodeLenses(params CodeLensParams) []CodeLens {
+	ctx := s.buildProjectContext()
+	if ctx == nil {
+		return nil
+	}
+	models := modelsInFile(ctx, URIToPath(params.TextDocument.URI))
+	slices.Sort(models)
+
+	var lenses []CodeLens
+	for _, model := range models {
+		for _, lens := range []struct{ title, command string }{
+			{"Run model", CommandRunModel},
+			{fmt.Sprintf("Preview %d rows", previewRows), CommandPreviewModel},
+			{"Show lineage", CommandShowLineage},
+			{"Show compiled SQL", CommandShowCompiled},
+		} {
+			lenses = append(lenses, CodeLens{
+				Command: &Command{Title: lens.title, Command: lens.command, Arguments: []any{model}},
+			})
+		}
+	}
+	return lenses
+}
+
+// connectDaemon returns the client of the daemon serving the project,
+// connecting to it if it started after the server.
+func (s *Server) connectDaemon() (*server.Client, error) {
+	if s.daemon != nil {
+		return s.daemon, nil
+	}
+	socket := server.SocketPath(filepath.Join(s.projectRoot, ".leapsql", "state.db"))
+	client, err := server.Dial(context.Background(), socket)
+	if err != nil {
+		return nil, errNoDaemon
+	}
+	s.daemon = client
+	s.logger.Info("Connected to daemon", "socket", socket)
+	return client, nil
+}
+
+// executeCommand executes a command with the daemon and stores its result as
+// a virtual document, returning the document's URI.
+func (s *Server) executeCommand(ctx context.Context, daemon *server.Client, params ExecuteCommandParams) (string, error) {
+	if !slices.Contains(commands, params.Command) {
+		return "", fmt.Errorf("unknown command: %s", params.Command)
+	}
+	var model string
+	if len(params.Arguments) != 1 || json.Unmarshal(params.Arguments[0], &model) != nil || model == "" {
+		return "", fmt.Errorf("%s takes the path of a model", params.Command)
+	}
+
+	var uri, content string
+	switch params.Command {
+	case CommandRunModel:
+		run, err := daemon.Run(ctx, server.RunRequest{Select: model})
+		if err != nil {
+			return "", err
+		}
+		uri, content = "leapsql-run://"+model, formatRun(model, run)
+	case CommandPreviewModel:
+		result, err := daemon.Preview(ctx, model, previewRows)
+		if err != nil {
+			return "", err
+		}
+		uri, content = "leapsql-preview://"+model, formatPreview(model, result.Columns, result.Rows)
+	case CommandShowLineage:
+		lineage, err := daemon.Lineage(ctx, model)
+		if err != nil {
+			return "", err
+		}
+		uri, content = "leapsql-lineage://"+model, formatLineage(lineage)
+	case CommandShowCompiled:
+		sql, err := daemon.Compile(ctx, model)
+		if err != nil {
+			return "", err
+		}
+		uri, content = compiledScheme+model, sql
+	}
+
+	s.virtualDocsMu.Lock()
+	s.virtualDocs[uri] = content
+	s.virtualDocsMu.Unlock()
+	return uri, nil
+}
+
+// getVirtualDocument returns a virtual document opened by a command, or nil
+// if there is none with the URI.
+func (s *Server) getVirtualDocument(params VirtualDocumentParams) *VirtualDocument {
+	s.virtualDocsMu.RLock()
+	defer s.virtualDocsMu.RUnlock()
+	content, ok := s.virtualDocs[params.URI]
+	if !ok {
+		return nil
+	}
+	languageID := "markdown"
+	if strings.HasPrefix(params.URI, compiledScheme) {
+		languageID = "sql"
+	}
+	return &VirtualDocument{URI: params.URI, LanguageID: languageID, Content: content}
+}
+
+// refreshCompiledDocuments compiles the models whose compiled SQL is open
+// again, after a model or macro file was saved. Clients are sent a
+// leapsql/virtualDocumentChanged notification for each document whose
+// content changed, and read it again. Models that fail to compile show the
+// error instead.
+func (s *Server) refreshCompiledDocuments(ctx context.Context, daemon *server.Client) {
+	s.virtualDocsMu.RLock()
+	var uris []string
+	for uri := range s.virtualDocs {
+		if strings.HasPrefix(uri, compiledScheme) {
+			uris = append(uris, uri)
+		}
+	}
+	s.virtualDocsMu.RUnlock()
+	slices.Sort(uris)
+
+	for _, uri := range uris {
+		content, err := daemon.Compile(ctx, strings.TrimPrefix(uri, compiledScheme))
+		if err != nil {
+			content = "-- Failed to compile: " + strings.ReplaceAll(err.Error(), "\n", "\n-- ") + "\n"
+		}
+
+		s.virtualDocsMu.Lock()
+		changed := s.virtualDocs[uri] != content
+		s.virtualDocs[uri] = content
+		s.virtualDocsMu.Unlock()
+		if changed {
+			s.sendNotification("leapsql/virtualDocumentChanged", &VirtualDocumentParams{URI: uri})
+		}
+	}
+}
+
+// formatRun formats the outcome of a model's run as Markdown.
+func formatRun(model string, run *server.RunResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Run of %s\n\n", model)
+	fmt.Fprintf(&b, "Run %s %s in %d ms.\n", run.ID, run.Status, run.DurationMS)
+	if run.Error != "" {
+		fmt.Fprintf(&b, "\nError: %s\n", run.Error)
+	}
+	if len(run.Models) > 0 {
+		rows := make([][]string, 0, len(run.Models))
+		for _, mr := range run.Models {
+			rows = append(rows, []string{mr.Model, mr.Status, fmt.Sprint(mr.RowsAffected), fmt.Sprintf("%d ms", mr.ExecutionMS), mr.Error})
+		}
+		b.WriteString("\n")
+		writeMarkdownTable(&b, []string{"Model", "Status", "Rows", "Time", "Error"}, rows)
+	}
+	return b.String()
+}
+
+// formatPreview formats the rows of a model preview as a Markdown table.
+func formatPreview(model string, columns []string, values [][]any) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Preview of %s\n\n", model)
+	fmt.Fprintf(&b, "First %d rows of the model's query (%d returned).\n\n", previewRows, len(values))
+	rows := make([][]string, 0, len(values))
+	for _, row := range values {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			if v == nil {
+				cells[i] = "NULL"
+			} else {
+				cells[i] = fmt.Sprint(v)
+			}
+		}
+		rows = append(rows, cells)
+	}
+	writeMarkdownTable(&b, columns, rows)
+	return b.String()
+}
+
+// formatLineage formats the lineage of a model as Markdown.
+func formatLineage(lineage *server.Lineage) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Lineage of %s\n", lineage.Model)
+	for _, section := range []struct {
+		title  string
+		models []string
+	}{
+		{"Upstream", lineage.Upstream},
+		{"Downstream", lineage.Downstream},
+	} {
+		fmt.Fprintf(&b, "\n## %s\n\n", section.title)
+		if len(section.models) == 0 {
+			b.WriteString("None.\n")
+		}
+		for _, m := range section.models {
+			fmt.Fprintf(&b, "- %s\n", m)
+		}
+	}
+
+	if len(lineage.Columns) > 0 {
+		b.WriteString("\n## Columns\n\n")
+		rows := make([][]string, 0, len(lineage.Columns))
+		for _, col := range lineage.Columns {
+			sources := make([]string, 0, len(col.Sources))
+			for _, src := range col.Sources {
+				sources = append(sources, src.Table+"."+src.Column)
+			}
+			transform := col.Transform
+			if col.Function != "" {
+				transform = strings.TrimSpace(transform + " " + col.Function)
+			}
+			rows = append(rows, []string{col.Name, strings.Join(sources, ", "), transform})
+		}
+		writeMarkdownTable(&b, []string{"Column", "Sources", "Transform"}, rows)
+	}
+	return b.String()
+}
+
+// writeMarkdownTable writes a Markdown table, escaping pipes and line breaks
+// in cells.
+func writeMarkdownTable(b *strings.Builder, header []string, rows [][]string) {
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, c := range cells {
+			b.WriteString(" " + escape.Replace(c) + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(header)
+	b.WriteString("|" + strings.Repeat("---|", len(header)) + "\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+}
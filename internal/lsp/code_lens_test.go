@@ -0,0 +1,170 @@
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/internal/server"
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+	"github.com/leapstack-labs/leapsql/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDaemonServer initializes a server for a project with a seed and a
+// staging model, served by a daemon. It returns the server and the model
+// file's URI.
+func newDaemonServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"seeds/raw_customers.csv":      "id,name\n1,alice\n2,bob\n",
+		"models/staging/customers.sql": "SELECT id, name FROM raw_customers\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".leapsql"), 0750))
+
+	statePath := filepath.Join(root, ".leapsql", "state.db")
+	eng, err := engine.New(engine.Config{
+		ModelsDir: filepath.Join(root, "models"),
+		SeedsDir:  filepath.Join(root, "seeds"),
+		StatePath: statePath,
+		Target:    &starctx.TargetInfo{Type: "duckdb", Schema: "main"},
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = eng.Close() })
+	_, err = eng.Discover(engine.DiscoveryOptions{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	daemon := server.New(server.Config{Engine: eng, Addr: "127.0.0.1:0", Environment: "test", Socket: server.SocketPath(statePath)})
+	served := make(chan error, 1)
+	go func() { served <- daemon.Serve(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-served
+	})
+	require.Eventually(t, func() bool {
+		client, err := server.Dial(context.Background(), server.SocketPath(statePath))
+		if err == nil {
+			client.Close()
+		}
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	var out bytes.Buffer
+	lsp := NewServerWithLogger(strings.NewReader(""), &out, testutil.NewTestLogger(t))
+	params, err := json.Marshal(map[string]any{"rootUri": PathToURI(root)})
+	require.NoError(t, err)
+	id := json.RawMessage("1")
+	require.NoError(t, lsp.handleInitialize(&JSONRPCMessage{ID: &id, Method: "initialize", Params: params}))
+	t.Cleanup(func() { _ = lsp.store.Close() })
+	require.NotNil(t, lsp.daemon, "the server connects to the daemon")
+	return lsp, PathToURI(filepath.Join(root, "models", "staging", "customers.sql"))
+}
+
+// executeModelCommand executes a command on a model and returns the content
+// of the virtual document holding its result.
+func executeModelCommand(t *testing.T, s *Server, command, model string) string {
+	t.Helper()
+	arg, err := json.Marshal(model)
+	require.NoError(t, err)
+	uri, err := s.executeCommand(context.Background(), s.daemon, ExecuteCommandParams{Command: command, Arguments: []json.RawMessage{arg}})
+	require.NoError(t, err)
+	doc := s.getVirtualDocument(VirtualDocumentParams{URI: uri})
+	require.NotNil(t, doc, "no virtual document at %s", uri)
+	assert.Equal(t, "markdown", doc.LanguageID)
+	return doc.Content
+}
+
+func TestServer_CodeLenses(t *testing.T) {
+	s, uri := newDaemonServer(t)
+
+	lenses := s.getCodeLenses(CodeLensParams{TextDocument: TextDocumentIdentifier{URI: uri}})
+	require.Len(t, lenses, 4)
+	var titles []string
+	for _, lens := range lenses {
+		titles = append(titles, lens.Command.Title)
+		assert.Equal(t, []any{"staging.customers"}, lens.Command.Arguments)
+		assert.Equal(t, Range{}, lens.Range, "lenses are at the top of the file")
+	}
+	assert.Equal(t, []string{"Run model", "Preview 100 rows", "Show lineage", "Show compiled SQL"}, titles)
+
+	assert.Empty(t, s.getCodeLenses(CodeLensParams{TextDocument: TextDocumentIdentifier{URI: "file:///elsewhere.sql"}}))
+}
+
+func TestServer_ExecuteCommand(t *testing.T) {
+	s, _ := newDaemonServer(t)
+
+	run := executeModelCommand(t, s, CommandRunModel, "staging.customers")
+	assert.Contains(t, run, "# Run of staging.customers")
+	assert.Contains(t, run, "| staging.customers | success | 2 |")
+
+	preview := executeModelCommand(t, s, CommandPreviewModel, "staging.customers")
+	assert.Contains(t, preview, "| id | name |")
+	assert.Contains(t, preview, "| 1 | alice |")
+
+	lineage := executeModelCommand(t, s, CommandShowLineage, "staging.customers")
+	assert.Contains(t, lineage, "# Lineage of staging.customers")
+	assert.Contains(t, lineage, "| name | raw_customers.name |")
+
+	// Unknown commands, models and virtual documents fail
+	_, err := s.executeCommand(context.Background(), s.daemon, ExecuteCommandParams{Command: "leapsql.unknown"})
+	assert.ErrorContains(t, err, "unknown command")
+	arg, _ := json.Marshal("staging.missing")
+	_, err = s.executeCommand(context.Background(), s.daemon, ExecuteCommandParams{Command: CommandShowLineage, Arguments: []json.RawMessage{arg}})
+	assert.ErrorContains(t, err, "model not found")
+	assert.Nil(t, s.getVirtualDocument(VirtualDocumentParams{URI: "leapsql-run://staging.missing"}))
+}
+
+func TestServer_CompiledDocument(t *testing.T) {
+	s, uri := newDaemonServer(t)
+	var out bytes.Buffer
+	s.writer = &out
+
+	arg, err := json.Marshal("staging.customers")
+	require.NoError(t, err)
+	compiled, err := s.executeCommand(context.Background(), s.daemon, ExecuteCommandParams{Command: CommandShowCompiled, Arguments: []json.RawMessage{arg}})
+	require.NoError(t, err)
+	assert.Equal(t, "leapsql-compiled://staging.customers", compiled)
+	doc := s.getVirtualDocument(VirtualDocumentParams{URI: compiled})
+	require.NotNil(t, doc)
+	assert.Equal(t, "sql", doc.LanguageID)
+	assert.Equal(t, "SELECT id, name FROM raw_customers", doc.Content)
+
+	// Saving the model compiles it again and tells the client
+	require.NoError(t, os.WriteFile(URIToPath(uri), []byte("SELECT id FROM raw_customers WHERE {{ 1 + 1 }} > 1\n"), 0600))
+	s.refreshCompiledDocuments(context.Background(), s.daemon)
+	assert.Equal(t, "SELECT id FROM raw_customers WHERE 2 > 1", s.getVirtualDocument(VirtualDocumentParams{URI: compiled}).Content)
+	assert.Contains(t, out.String(), `"method":"leapsql/virtualDocumentChanged","params":{"uri":"leapsql-compiled://staging.customers"}`)
+
+	// Unchanged documents send no notification
+	out.Reset()
+	s.refreshCompiledDocuments(context.Background(), s.daemon)
+	assert.Empty(t, out.String())
+
+	// Compile errors replace the SQL
+	require.NoError(t, os.WriteFile(URIToPath(uri), []byte("SELECT {{ missing }} FROM raw_customers\n"), 0600))
+	s.refreshCompiledDocuments(context.Background(), s.daemon)
+	assert.True(t, strings.HasPrefix(s.getVirtualDocument(VirtualDocumentParams{URI: compiled}).Content, "-- Failed to compile: "))
+}
+
+func TestServer_ExecuteCommand_NoDaemon(t *testing.T) {
+	s := NewServerWithLogger(strings.NewReader(""), &bytes.Buffer{}, testutil.NewTestLogger(t))
+	s.projectRoot = t.TempDir()
+
+	_, err := s.connectDaemon()
+	assert.ErrorIs(t, err, errNoDaemon)
+}
@@ -26,8 +26,7 @@ const (
 	ContextConfigAccess // Inside config. or config["
 )
 
-// builtinGlobals are the reserved Starlark globals with documentation.
-// Note: LeapSQL auto-detects dependencies from SQL - no ref() function needed.
+// builtinGlobals are the globals of templates with documentation.
 var builtinGlobals = []CompletionItem{
 	{
 		Label:         "config",
@@ -53,6 +52,12 @@ var builtinGlobals = []CompletionItem{
 		Detail:        "struct",
 		Documentation: "Current model information. Fields: this.name, this.schema, this.database",
 	},
+	{
+		Label:         "ref",
+		Kind:          CompletionItemKindFunction,
+		Detail:        "ref(model, v=0)",
+		Documentation: "Table name of a model: ref('model'), ref('project', 'model') for a model of another workspace project, or ref('model', v=2) to pin a version.",
+	},
 }
 
 // getSQLKeywordCompletions returns keyword completions from the dialect.
@@ -158,6 +163,9 @@ func (s *Server) getCompletions(params CompletionParams) []CompletionItem {
 	if doc == nil {
 		return nil
 	}
+	if isStarlarkURI(doc.URI) {
+		return starlarkCompletions(doc, params.Position)
+	}
 
 	ctx, extra := s.detectContext(doc, params.Position)
 	prefix := s.extractPrefix(doc, params.Position)
@@ -375,6 +383,9 @@ func (s *Server) getHover(params HoverParams) *Hover {
 	if doc == nil {
 		return nil
 	}
+	if isStarlarkURI(doc.URI) {
+		return starlarkHover(doc, params.Position)
+	}
 
 	word, _ := doc.GetWordAtPosition(params.Position)
 	if word == "" {
@@ -393,32 +404,10 @@ func (s *Server) getHover(params HoverParams) *Hover {
 		}
 	}
 
-	// Check for macro patterns
-	before := doc.GetTextBefore(params.Position)
-	if inTemplateExpr(before) {
-		// Try to find namespace.function pattern
-		exprContent := extractTemplateExprContent(before) + word
-		macroPattern := regexp.MustCompile(`(\w+)\.(\w+)$`)
-		if match := macroPattern.FindStringSubmatch(exprContent); match != nil {
-			namespace := match[1]
-			funcName := match[2]
-
-			if s.store != nil {
-				fn, _ := s.store.GetMacroFunction(namespace, funcName)
-				if fn != nil {
-					content := fmt.Sprintf("```\n%s.%s(%s)\n```", namespace, fn.Name, strings.Join(fn.Args, ", "))
-					if fn.Docstring != "" {
-						content += "\n\n" + fn.Docstring
-					}
-
-					return &Hover{
-						Contents: MarkupContent{
-							Kind:  MarkupKindMarkdown,
-							Value: content,
-						},
-					}
-				}
-			}
+	// Check for macro namespaces and functions
+	if ref, ok := macroRefAt(doc, params.Position); ok && s.store != nil {
+		if hover := s.macroHover(ref); hover != nil {
+			return hover
 		}
 	}
 
@@ -469,43 +458,9 @@ func (s *Server) getDefinition(params DefinitionParams) *Location {
 		return nil
 	}
 
-	word, _ := doc.GetWordAtPosition(params.Position)
-	if word == "" {
-		return nil
-	}
-
-	before := doc.GetTextBefore(params.Position)
-
-	// Check if inside template expression
-	if inTemplateExpr(before) && s.store != nil {
-		// Try to find namespace.function pattern
-		exprContent := extractTemplateExprContent(before) + word
-		macroPattern := regexp.MustCompile(`(\w+)\.(\w+)$`)
-		if match := macroPattern.FindStringSubmatch(exprContent); match != nil {
-			namespace := match[1]
-			funcName := match[2]
-
-			// Get namespace info
-			ns, _ := s.store.GetMacroNamespace(namespace)
-			if ns == nil {
-				return nil
-			}
-
-			// Get function info for line number
-			fn, _ := s.store.GetMacroFunction(namespace, funcName)
-			line := 0
-			if fn != nil {
-				line = fn.Line - 1 // Convert to 0-based
-			}
-
-			return &Location{
-				URI: PathToURI(ns.FilePath),
-				Range: Range{
-					Start: Position{Line: uint32(line), Character: 0}, //nolint:gosec // G115: line is always non-negative from AST
-					End:   Position{Line: uint32(line), Character: 0}, //nolint:gosec // G115: line is always non-negative from AST
-				},
-			}
-		}
+	// Check for macro namespaces and functions
+	if ref, ok := macroRefAt(doc, params.Position); ok && s.store != nil {
+		return s.macroDefinition(ref)
 	}
 
 	return nil
@@ -1,9 +1,14 @@
 package lsp
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/leapstack-labs/leapsql/internal/loader"
@@ -14,70 +19,91 @@ import (
 	"github.com/leapstack-labs/leapsql/pkg/lint/project"
 	_ "github.com/leapstack-labs/leapsql/pkg/lint/sql/rules" // Register SQLFluff-style lint rules
 	pkgparser "github.com/leapstack-labs/leapsql/pkg/parser"
+	"github.com/leapstack-labs/leapsql/pkg/token"
 )
 
 // publishDiagnostics parses the document and publishes any errors.
-// Uses the shared provider for caching to avoid redundant parsing.
+// Uses the shared provider for caching to avoid redundant parsing. Clients
+// pulling diagnostics get none pushed.
 func (s *Server) publishDiagnostics(uri string) {
+	if s.pullDiagnostics {
+		return
+	}
 	doc := s.documents.Get(uri)
 	if doc == nil {
 		return
 	}
 
+	// Only process model and macro files
 	var diagnostics []Diagnostic
+	if hasFileDiagnostics(uri) {
+		diagnostics = s.fileDiagnostics(doc, true)
+	}
 
-	// Only process SQL files
-	if !strings.HasSuffix(uri, ".sql") {
-		s.sendNotification("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
-			URI:         uri,
-			Diagnostics: diagnostics,
-		})
-		return
+	s.sendNotification("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+// hasFileDiagnostics reports whether a file has diagnostics of its own
+// content: models and macro files.
+func hasFileDiagnostics(path string) bool {
+	return strings.HasSuffix(path, ".sql") || isStarlarkURI(path)
+}
+
+// fileDiagnostics returns the diagnostics of a file's content. Those of a
+// SQL file are its syntax and lint errors and, if the store is available,
+// unknown macro references; those of a macro file are the errors the macro
+// loader reports. Open documents are parsed through the provider's cache;
+// files read from disk are parsed directly, as the cache is keyed by editor
+// versions.
+func (s *Server) fileDiagnostics(doc *Document, open bool) []Diagnostic {
+	if isStarlarkURI(doc.URI) {
+		return starlarkDiagnostics(doc)
 	}
 
-	// Use provider for cached parsing (single parse instead of 3x)
-	if s.provider != nil {
-		parsed := s.provider.GetOrParse(uri, doc.Content, doc.Version)
-		diagnostics = s.getDiagnosticsFromParsed(uri, parsed, doc)
-	} else {
+	var diagnostics []Diagnostic
+	switch {
+	case s.provider != nil && open:
+		diagnostics = s.getDiagnosticsFromParsed(doc.URI, s.provider.GetOrParse(doc.URI, doc.Content, doc.Version), doc)
+	case s.provider != nil:
+		diagnostics = s.getDiagnosticsFromParsed(doc.URI, provider.Parse(doc.Content, doc.URI, doc.Version, s.dialect), doc)
+	default:
 		// Fallback to direct parsing if provider not initialized
 		diagnostics = s.getDiagnosticsLegacy(doc)
 	}
 
-	// Validate macro references (if store available)
 	if s.store != nil {
-		macroDiags := s.validateMacroReferences(doc)
-		diagnostics = append(diagnostics, macroDiags...)
+		diagnostics = append(diagnostics, s.validateMacroReferences(doc)...)
 	}
-
-	s.sendNotification("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
-		URI:         uri,
-		Diagnostics: diagnostics,
-	})
+	return diagnostics
 }
 
 // getDiagnosticsFromParsed extracts diagnostics from a cached ParsedDocument.
+// Template, SQL and lint positions, found past the frontmatter and in SQL
+// with templates replaced, are mapped back to the document.
 func (s *Server) getDiagnosticsFromParsed(uri string, parsed *provider.ParsedDocument, _ *Document) []Diagnostic {
 	var diagnostics []Diagnostic
 
 	// 1. Frontmatter errors
 	if parsed.FrontmatterError != nil {
-		diagnostics = append(diagnostics, s.frontmatterErrorToDiagnostic(parsed.FrontmatterError)...)
+		diagnostics = append(diagnostics, s.frontmatterErrorsToDiagnostics(parsed.Content, parsed.FrontmatterError)...)
 	}
 
 	// 2. Template errors
 	if parsed.TemplateError != nil {
-		diagnostics = append(diagnostics, s.templateErrorToDiagnostic(parsed.TemplateError)...)
+		diagnostics = append(diagnostics, s.templateErrorToDiagnostic(parsed.TemplateError, parsed.Content, parsed.FrontmatterEnd)...)
 	}
 
 	// 3. SQL parse errors
 	if parsed.SQLError != nil {
-		diagnostics = append(diagnostics, s.sqlErrorToDiagnostic(parsed.SQLError)...)
+		diagnostics = append(diagnostics, s.sqlErrorToDiagnostic(parsed.SQLError, parsed)...)
 	}
 
 	// 4. Run lint rules if SQL parsed successfully
 	if parsed.SQL != nil {
-		lintDiags := s.runLinter(uri, parsed.SQL)
+		lintDiags := s.runLinter(uri, parsed.SQL, parsed)
 		diagnostics = append(diagnostics, lintDiags...)
 	}
 
@@ -103,6 +129,22 @@ func (s *Server) getDiagnosticsLegacy(doc *Document) []Diagnostic {
 	return diagnostics
 }
 
+// frontmatterErrorsToDiagnostics converts every frontmatter problem in content
+// to LSP diagnostics. err is the error returned when parsing the frontmatter,
+// used if validation does not report more detail.
+func (s *Server) frontmatterErrorsToDiagnostics(content string, err error) []Diagnostic {
+	errs := loader.ValidateFrontmatter(content)
+	if len(errs) == 0 {
+		errs = []error{err}
+	}
+
+	diagnostics := make([]Diagnostic, 0, len(errs))
+	for _, err := range errs {
+		diagnostics = append(diagnostics, s.frontmatterErrorToDiagnostic(err)...)
+	}
+	return diagnostics
+}
+
 // frontmatterErrorToDiagnostic converts a frontmatter error to LSP diagnostic.
 func (s *Server) frontmatterErrorToDiagnostic(err error) []Diagnostic {
 	var pos Position
@@ -113,11 +155,11 @@ func (s *Server) frontmatterErrorToDiagnostic(err error) []Diagnostic {
 
 	switch {
 	case errors.As(err, &parseErr):
-		pos = Position{Line: uint32(parseErr.Line), Character: 0} //nolint:gosec // G115: line is always non-negative from parser
+		pos = frontmatterPosition(parseErr.Line, parseErr.Column)
 		msg = parseErr.Message
 	case errors.As(err, &unknownErr):
 		msg = fmt.Sprintf("Unknown frontmatter field: %s", unknownErr.Field)
-		pos = Position{Line: 0, Character: 0}
+		pos = frontmatterPosition(unknownErr.Line, unknownErr.Column)
 	default:
 		msg = err.Error()
 		pos = Position{Line: 0, Character: 0}
@@ -135,19 +177,24 @@ func (s *Server) frontmatterErrorToDiagnostic(err error) []Diagnostic {
 	}}
 }
 
-// templateErrorToDiagnostic converts a template error to LSP diagnostic.
-func (s *Server) templateErrorToDiagnostic(err error) []Diagnostic {
+// frontmatterPosition converts a 1-based frontmatter error position to an
+// LSP position. Unknown positions map to the start of the document.
+func frontmatterPosition(line, column int) Position {
 	var pos Position
-	var msg string
-
-	var te template.Error
-	if errors.As(err, &te) {
-		tpos := te.Position()
-		pos = Position{Line: uint32(tpos.Line - 1), Character: uint32(tpos.Column - 1)} //nolint:gosec // G115: line/column are always non-negative
-		msg = err.Error()
-	} else {
-		msg = err.Error()
+	if line > 0 {
+		pos.Line = uint32(line - 1) //nolint:gosec // G115: line is positive
 	}
+	if column > 0 {
+		pos.Character = uint32(column - 1) //nolint:gosec // G115: column is positive
+	}
+	return pos
+}
+
+// templateErrorToDiagnostic converts a template error to LSP diagnostic. The
+// template starts at offset base of content, after the frontmatter.
+func (s *Server) templateErrorToDiagnostic(err error, content string, base int) []Diagnostic {
+	pos := templateErrorPosition(err, content, base)
+	msg := err.Error()
 
 	return []Diagnostic{{
 		Range: Range{
@@ -161,15 +208,33 @@ func (s *Server) templateErrorToDiagnostic(err error) []Diagnostic {
 	}}
 }
 
-// sqlErrorToDiagnostic converts a SQL parse error to LSP diagnostic.
-func (s *Server) sqlErrorToDiagnostic(err error) []Diagnostic {
+// templateErrorPosition returns the document position of a template error,
+// for a template starting at offset base of content.
+func templateErrorPosition(err error, content string, base int) Position {
+	var te template.Error
+	if !errors.As(err, &te) {
+		return Position{}
+	}
+	return lspPosition(token.PositionAt(content, base+te.Position().Offset))
+}
+
+// sqlErrorToDiagnostic converts a SQL parse error of a parsed document to
+// LSP diagnostic. An error in the placeholder of a template expression
+// covers the expression.
+func (s *Server) sqlErrorToDiagnostic(err error, parsed *provider.ParsedDocument) []Diagnostic {
 	var pe *pkgparser.ParseError
 	if errors.As(err, &pe) {
+		offset := pe.Pos.OffsetIn(parsed.SQLContent)
+		start, end := parsed.DocumentRange(offset, offset)
+		rng := Range{
+			Start: lspPosition(token.PositionAt(parsed.Content, start)),
+			End:   lspPosition(token.PositionAt(parsed.Content, end)),
+		}
+		if end == start {
+			rng.End = Position{Line: rng.Start.Line, Character: rng.Start.Character + 11}
+		}
 		return []Diagnostic{{
-			Range: Range{
-				Start: Position{Line: uint32(pe.Pos.Line - 1), Character: uint32(pe.Pos.Column - 1)},  //nolint:gosec // G115: line/column are always non-negative
-				End:   Position{Line: uint32(pe.Pos.Line - 1), Character: uint32(pe.Pos.Column + 10)}, //nolint:gosec // G115: line/column are always non-negative
-			},
+			Range:    rng,
 			Severity: DiagnosticSeverityError,
 			Code:     "E003",
 			Source:   "leapsql",
@@ -189,45 +254,13 @@ func (s *Server) sqlErrorToDiagnostic(err error) []Diagnostic {
 	}}
 }
 
-// validateFrontmatter checks YAML frontmatter syntax.
+// validateFrontmatter checks YAML frontmatter syntax and schema.
 func (s *Server) validateFrontmatter(doc *Document) []Diagnostic {
-	var diagnostics []Diagnostic
-
 	_, err := loader.ExtractFrontmatter(doc.Content)
-	if err != nil {
-		// Try to get position from error
-		var pos Position
-		var msg string
-
-		var parseErr *loader.FrontmatterParseError
-		var unknownErr *loader.UnknownFieldError
-
-		switch {
-		case errors.As(err, &parseErr):
-			pos = Position{Line: uint32(parseErr.Line), Character: 0} //nolint:gosec // G115: line is always non-negative from parser
-			msg = parseErr.Message
-		case errors.As(err, &unknownErr):
-			// Unknown field - try to find it in the content
-			msg = fmt.Sprintf("Unknown frontmatter field: %s", unknownErr.Field)
-			pos = Position{Line: 0, Character: 0}
-		default:
-			msg = err.Error()
-			pos = Position{Line: 0, Character: 0}
-		}
-
-		diagnostics = append(diagnostics, Diagnostic{
-			Range: Range{
-				Start: pos,
-				End:   Position{Line: pos.Line, Character: 1000},
-			},
-			Severity: DiagnosticSeverityError,
-			Code:     "E001",
-			Source:   "leapsql",
-			Message:  msg,
-		})
+	if err == nil {
+		return nil
 	}
-
-	return diagnostics
+	return s.frontmatterErrorsToDiagnostics(doc.Content, err)
 }
 
 // validateTemplate checks template syntax ({{ }} and {* *}).
@@ -242,18 +275,8 @@ func (s *Server) validateTemplate(doc *Document) []Diagnostic {
 
 	_, err := template.ParseString(content, "")
 	if err != nil {
-		var pos Position
-		var msg string
-
-		// Check if the error implements the template.Error interface
-		var te template.Error
-		if errors.As(err, &te) {
-			tpos := te.Position()
-			pos = Position{Line: uint32(tpos.Line - 1), Character: uint32(tpos.Column - 1)} //nolint:gosec // G115: line/column are always non-negative
-			msg = err.Error()
-		} else {
-			msg = err.Error()
-		}
+		pos := templateErrorPosition(err, doc.Content, len(doc.Content)-len(content))
+		msg := err.Error()
 
 		diagnostics = append(diagnostics, Diagnostic{
 			Range: Range{
@@ -311,7 +334,7 @@ func (s *Server) validateSQL(doc *Document) []Diagnostic {
 
 	// Run lint rules if statement parsed successfully (even if there were parser warnings)
 	if stmt != nil {
-		lintDiags := s.runLinter(doc.URI, stmt)
+		lintDiags := s.runLinter(doc.URI, stmt, nil)
 		diagnostics = append(diagnostics, lintDiags...)
 	}
 
@@ -472,41 +495,40 @@ func levenshtein(s1, s2 string) int {
 	return matrix[len(s1)][len(s2)]
 }
 
-// runLinter runs lint rules against a parsed SQL statement.
-func (s *Server) runLinter(uri string, stmt *core.SelectStmt) []Diagnostic {
+// runLinter runs lint rules against a parsed SQL statement. With the parsed
+// document the statement comes from, positions and fixes are mapped back to
+// the document.
+func (s *Server) runLinter(uri string, stmt *core.SelectStmt, parsed *provider.ParsedDocument) []Diagnostic {
 	// Use analyzer with registry to get SQLFluff-style rules in addition to dialect rules
 	analyzer := lint.NewAnalyzerWithRegistry(lint.NewConfig(), s.dialect.GetName())
 	lintDiags := analyzer.Analyze(stmt, s.dialect)
+	if parsed != nil {
+		mapToDocument(lintDiags, parsed)
+	}
 
 	// Convert lint.Diagnostic to LSP Diagnostic
 	var result []Diagnostic
 	for _, d := range lintDiags {
-		// Determine end position - use EndPos if available, otherwise estimate
-		endLine := d.EndPos.Line
-		endCol := d.EndPos.Column
-		if endLine == 0 && endCol == 0 {
-			// Fallback: estimate end position
-			endLine = d.Pos.Line
-			endCol = d.Pos.Column + 10
-		}
-
 		diag := Diagnostic{
-			Range: Range{
-				Start: Position{
-					Line:      uint32(max(0, d.Pos.Line-1)),   //nolint:gosec // G115: line is always non-negative
-					Character: uint32(max(0, d.Pos.Column-1)), //nolint:gosec // G115: column is always non-negative
-				},
-				End: Position{
-					Line:      uint32(max(0, endLine-1)), //nolint:gosec // G115: line is always non-negative
-					Character: uint32(max(0, endCol-1)),  //nolint:gosec // G115: column is always non-negative
-				},
-			},
+			Range:    lintRange(d.Pos, d.EndPos),
 			Severity: toLSPSeverity(d.Severity),
 			Code:     d.RuleID,
 			Source:   "leapsql-lint",
 			Message:  d.Message,
 		}
 
+		// Map secondary locations, in this document unless they name a file
+		for _, rel := range d.RelatedInfo {
+			relURI := uri
+			if rel.FilePath != "" {
+				relURI = PathToURI(rel.FilePath)
+			}
+			diag.RelatedInformation = append(diag.RelatedInformation, DiagnosticRelatedInformation{
+				Location: Location{URI: relURI, Range: lintRange(rel.Pos, rel.EndPos)},
+				Message:  rel.Message,
+			})
+		}
+
 		// Add documentation URL if available
 		if d.DocumentationURL != "" {
 			diag.CodeDescription = &CodeDescription{Href: d.DocumentationURL}
@@ -524,6 +546,60 @@ func (s *Server) runLinter(uri string, stmt *core.SelectStmt) []Diagnostic {
 	return result
 }
 
+// mapToDocument moves the positions of lint diagnostics found in the SQL of
+// a parsed document to the document, along with the edits of their fixes.
+// Fixes editing a template expression are dropped: they would rewrite the
+// template rather than the SQL it stands for.
+func mapToDocument(diags []lint.Diagnostic, parsed *provider.ParsedDocument) {
+	toDocument := func(pos, end token.Position) (token.Position, token.Position) {
+		start, stop := parsed.DocumentRange(pos.OffsetIn(parsed.SQLContent), end.OffsetIn(parsed.SQLContent))
+		return token.PositionAt(parsed.Content, start), token.PositionAt(parsed.Content, stop)
+	}
+	lint.MapRanges(diags, toDocument)
+
+	for i := range diags {
+		fixes := diags[i].Fixes[:0]
+	fixLoop:
+		for _, fix := range diags[i].Fixes {
+			edits := make([]lint.TextEdit, len(fix.TextEdits))
+			for j, edit := range fix.TextEdits {
+				if parsed.HasTemplate(edit.Pos.OffsetIn(parsed.SQLContent), edit.EndPos.OffsetIn(parsed.SQLContent)) {
+					continue fixLoop
+				}
+				edit.Pos, edit.EndPos = toDocument(edit.Pos, edit.EndPos)
+				edits[j] = edit
+			}
+			fix.TextEdits = edits
+			fixes = append(fixes, fix)
+		}
+		diags[i].Fixes = fixes
+		diags[i].AutoFixable = diags[i].AutoFixable && len(fixes) > 0
+	}
+}
+
+// lspPosition converts a 1-based position to an LSP position.
+func lspPosition(pos token.Position) Position {
+	return Position{
+		Line:      uint32(max(0, pos.Line-1)),   //nolint:gosec // G115: line is always non-negative
+		Character: uint32(max(0, pos.Column-1)), //nolint:gosec // G115: column is always non-negative
+	}
+}
+
+// lintRange converts the 1-based start and end positions of a lint finding to
+// an LSP range. Without an end position, the range spans 10 characters.
+func lintRange(pos, end token.Position) Range {
+	endLine, endCol := end.Line, end.Column
+	if endLine == 0 && endCol == 0 {
+		// Fallback: estimate end position
+		endLine = pos.Line
+		endCol = pos.Column + 10
+	}
+	return Range{
+		Start: lspPosition(pos),
+		End:   lspPosition(token.Position{Line: endLine, Column: endCol}),
+	}
+}
+
 // toLSPSeverity converts core.Severity to LSP DiagnosticSeverity.
 func toLSPSeverity(s core.Severity) DiagnosticSeverity {
 	switch s {
@@ -541,7 +617,8 @@ func toLSPSeverity(s core.Severity) DiagnosticSeverity {
 }
 
 // runProjectHealthDiagnostics runs project-level lint rules and returns diagnostics grouped by file.
-func (s *Server) runProjectHealthDiagnostics() map[string][]Diagnostic {
+// When changedFile names a model file, only the rules its models may affect are re-evaluated.
+func (s *Server) runProjectHealthDiagnostics(changedFile string) map[string][]Diagnostic {
 	// Use provider's cached project context if available
 	var ctx *project.Context
 	if s.provider != nil {
@@ -553,7 +630,12 @@ func (s *Server) runProjectHealthDiagnostics() map[string][]Diagnostic {
 		return nil
 	}
 
-	diags := s.projectAnalyzer.Analyze(ctx)
+	var diags []project.Diagnostic
+	if changed := modelsInFile(ctx, changedFile); len(changed) > 0 {
+		diags = s.projectAnalyzer.AnalyzeChanged(ctx, changed)
+	} else {
+		diags = s.projectAnalyzer.Analyze(ctx)
+	}
 	if len(diags) == 0 {
 		return nil
 	}
@@ -577,12 +659,31 @@ func (s *Server) runProjectHealthDiagnostics() map[string][]Diagnostic {
 			Source:   "leapsql-project-health",
 			Message:  d.Message,
 		}
+		if d.DocumentationURL != "" {
+			lspDiag.CodeDescription = &CodeDescription{Href: d.DocumentationURL}
+		} else {
+			lspDiag.CodeDescription = &CodeDescription{Href: lint.BuildDocURL(d.RuleID)}
+		}
 		byFile[filePath] = append(byFile[filePath], lspDiag)
 	}
 
 	return byFile
 }
 
+// modelsInFile returns the paths of the models defined in a file.
+func modelsInFile(ctx *project.Context, filePath string) []string {
+	if filePath == "" {
+		return nil
+	}
+	var paths []string
+	for path, m := range ctx.Models() {
+		if m.FilePath == filePath {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
 // projectSeverityToLSP converts core.Severity to LSP DiagnosticSeverity.
 func projectSeverityToLSP(s core.Severity) DiagnosticSeverity {
 	switch s {
@@ -600,8 +701,17 @@ func projectSeverityToLSP(s core.Severity) DiagnosticSeverity {
 }
 
 // publishProjectHealthDiagnostics runs project health analysis and publishes diagnostics.
-func (s *Server) publishProjectHealthDiagnostics() {
-	diagsByFile := s.runProjectHealthDiagnostics()
+// A non-empty changedFile limits the analysis to what a change to that file may affect.
+// Clients pulling diagnostics are asked to pull them again instead.
+func (s *Server) publishProjectHealthDiagnostics(changedFile string) {
+	if s.pullDiagnostics {
+		if s.diagnosticRefresh {
+			s.sendRequest("workspace/diagnostic/refresh", nil)
+		}
+		return
+	}
+
+	diagsByFile := s.runProjectHealthDiagnostics(changedFile)
 	if diagsByFile == nil {
 		return
 	}
@@ -632,23 +742,10 @@ func (s *Server) publishDiagnosticsWithProjectHealth(uri string, projectDiags []
 		return
 	}
 
+	// Only process model and macro files for file-level diagnostics
 	var diagnostics []Diagnostic
-
-	// Only process SQL files for file-level diagnostics
-	if strings.HasSuffix(uri, ".sql") {
-		// Use provider for cached parsing (single parse instead of 3x)
-		if s.provider != nil {
-			parsed := s.provider.GetOrParse(uri, doc.Content, doc.Version)
-			diagnostics = s.getDiagnosticsFromParsed(uri, parsed, doc)
-		} else {
-			diagnostics = s.getDiagnosticsLegacy(doc)
-		}
-
-		// Validate macro references
-		if s.store != nil {
-			macroDiags := s.validateMacroReferences(doc)
-			diagnostics = append(diagnostics, macroDiags...)
-		}
+	if hasFileDiagnostics(uri) {
+		diagnostics = s.fileDiagnostics(doc, true)
 	}
 
 	// Add project health diagnostics
@@ -659,3 +756,121 @@ func (s *Server) publishDiagnosticsWithProjectHealth(uri string, projectDiags []
 		Diagnostics: diagnostics,
 	})
 }
+
+// getDocumentDiagnostics answers a textDocument/diagnostic request: the
+// diagnostics of a SQL file, with the project rule results on its models.
+// Documents not open are read from disk. The report is unchanged if the
+// diagnostics match the client's previous result.
+func (s *Server) getDocumentDiagnostics(params DocumentDiagnosticParams) any {
+	path := URIToPath(params.TextDocument.URI)
+	doc, open := s.diskOrOpenDocument(params.TextDocument.URI)
+
+	var diagnostics []Diagnostic
+	if doc != nil && hasFileDiagnostics(path) {
+		diagnostics = s.fileDiagnostics(doc, open)
+	}
+	diagnostics = append(diagnostics, s.runProjectHealthDiagnostics("")[path]...)
+
+	id := diagnosticsResultID(diagnostics)
+	if id == params.PreviousResultID {
+		return UnchangedDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindUnchanged, ResultID: id}
+	}
+	return FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, ResultID: id, Items: nonNilDiagnostics(diagnostics)}
+}
+
+// getWorkspaceDiagnostics answers a workspace/diagnostic request: the
+// diagnostics of every model file of the project and every open SQL
+// document, reported unchanged where they match the client's previous
+// results. Project rules are evaluated once for the workspace.
+func (s *Server) getWorkspaceDiagnostics(params WorkspaceDiagnosticParams) WorkspaceDiagnosticReport {
+	previous := make(map[string]string, len(params.PreviousResultIDs))
+	for _, p := range params.PreviousResultIDs {
+		previous[p.URI] = p.Value
+	}
+
+	projectDiags := s.runProjectHealthDiagnostics("")
+	paths := make(map[string]bool)
+	for path := range projectDiags {
+		paths[path] = true
+	}
+	if ctx := s.buildProjectContext(); ctx != nil {
+		for _, m := range ctx.Models() {
+			if m.FilePath != "" {
+				paths[m.FilePath] = true
+			}
+		}
+	}
+	for _, uri := range s.documents.List() {
+		if hasFileDiagnostics(uri) {
+			paths[URIToPath(uri)] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	report := WorkspaceDiagnosticReport{Items: make([]any, 0, len(sorted))}
+	for _, path := range sorted {
+		uri := PathToURI(path)
+		doc, open := s.diskOrOpenDocument(uri)
+
+		var diagnostics []Diagnostic
+		if doc != nil && hasFileDiagnostics(path) {
+			diagnostics = s.fileDiagnostics(doc, open)
+		}
+		diagnostics = append(diagnostics, projectDiags[path]...)
+
+		var version *int
+		if open {
+			version = &doc.Version
+		}
+		id := diagnosticsResultID(diagnostics)
+		if id == previous[uri] {
+			report.Items = append(report.Items, WorkspaceUnchangedDocumentDiagnosticReport{
+				UnchangedDocumentDiagnosticReport: UnchangedDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindUnchanged, ResultID: id},
+				URI:                               uri,
+				Version:                           version,
+			})
+			continue
+		}
+		report.Items = append(report.Items, WorkspaceFullDocumentDiagnosticReport{
+			FullDocumentDiagnosticReport: FullDocumentDiagnosticReport{Kind: DocumentDiagnosticReportKindFull, ResultID: id, Items: nonNilDiagnostics(diagnostics)},
+			URI:                          uri,
+			Version:                      version,
+		})
+	}
+	return report
+}
+
+// diskOrOpenDocument returns the open document of a URI, or its content on
+// disk with open false. It returns nil if the file can't be read.
+func (s *Server) diskOrOpenDocument(uri string) (*Document, bool) {
+	if doc := s.documents.Get(uri); doc != nil {
+		return doc, true
+	}
+	content, err := os.ReadFile(URIToPath(uri))
+	if err != nil {
+		return nil, false
+	}
+	return &Document{URI: uri, Content: string(content), Lines: computeLineOffsets(string(content))}, false
+}
+
+// diagnosticsResultID identifies a set of diagnostics, so clients pulling
+// diagnostics they already have get an unchanged report.
+func diagnosticsResultID(diagnostics []Diagnostic) string {
+	data, _ := json.Marshal(nonNilDiagnostics(diagnostics))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// nonNilDiagnostics returns diagnostics, or an empty slice if nil, so it
+// encodes as [].
+func nonNilDiagnostics(diagnostics []Diagnostic) []Diagnostic {
+	if diagnostics == nil {
+		return []Diagnostic{}
+	}
+	return diagnostics
+}
@@ -1,10 +1,20 @@
 package lsp
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+	"github.com/leapstack-labs/leapsql/internal/testutil"
+	_ "github.com/leapstack-labs/leapsql/pkg/adapters/duckdb"
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/dialect"
 	_ "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb" // Register DuckDB dialect
@@ -163,6 +173,17 @@ SELECT * FROM users`,
 			expectErrors:  true,
 			expectedCount: 1,
 		},
+		{
+			name: "schema errors",
+			content: `/*---
+name: test
+colour: red
+materialized: snapshot
+---*/
+SELECT * FROM users`,
+			expectErrors:  true,
+			expectedCount: 2,
+		},
 		{
 			name:         "no frontmatter",
 			content:      "SELECT * FROM users",
@@ -347,7 +368,7 @@ func TestServer_RunProjectHealthDiagnostics_NoStore(t *testing.T) {
 	}
 
 	// Should return nil when store is unavailable
-	result := server.runProjectHealthDiagnostics()
+	result := server.runProjectHealthDiagnostics("")
 	assert.Nil(t, result, "expected nil when store is unavailable")
 }
 
@@ -363,3 +384,205 @@ func TestServer_BuildProjectContext_NoStore(t *testing.T) {
 	ctx := server.buildProjectContext()
 	assert.Nil(t, ctx, "expected nil when store is unavailable")
 }
+
+func TestServer_RunLinter_RelatedInformation(t *testing.T) {
+	duckdbDialect, _ := dialect.Get("duckdb")
+	server := &Server{
+		documents: NewDocumentStore(),
+		dialect:   duckdbDialect,
+	}
+
+	doc := &Document{
+		URI:     "file:///project/models/orders.sql",
+		Content: "SELECT id, name FROM users\nUNION ALL\nSELECT id FROM customers",
+	}
+	var am04 *Diagnostic
+	for _, d := range server.validateSQL(doc) {
+		if d.Code == "AM04" {
+			am04 = &d
+		}
+	}
+	require.NotNil(t, am04, "expected AM04 diagnostic")
+
+	assert.Equal(t, Range{Start: Position{Line: 2, Character: 7}, End: Position{Line: 2, Character: 9}}, am04.Range)
+	require.Len(t, am04.RelatedInformation, 1)
+	related := am04.RelatedInformation[0]
+	assert.Equal(t, doc.URI, related.Location.URI)
+	assert.Equal(t, Range{Start: Position{Line: 0, Character: 7}, End: Position{Line: 0, Character: 15}}, related.Location.Range)
+	assert.Equal(t, "First query selects 2 columns", related.Message)
+
+	require.NotNil(t, am04.CodeDescription)
+	assert.Equal(t, "https://leapstack-labs.github.io/leapsql/linting/rules/am04", am04.CodeDescription.Href)
+}
+
+func TestServer_CodeActions_Suggestion(t *testing.T) {
+	duckdbDialect, _ := dialect.Get("duckdb")
+	server := &Server{
+		documents: NewDocumentStore(),
+		dialect:   duckdbDialect,
+	}
+
+	doc := &Document{
+		URI:     "file:///project/models/user_orders.sql",
+		Content: "SELECT *\nFROM users usr\nJOIN orders ord ON usr.id = ord.id",
+	}
+	var st07 *Diagnostic
+	for _, d := range server.validateSQL(doc) {
+		if d.Code == "ST07" {
+			st07 = &d
+		}
+	}
+	require.NotNil(t, st07, "expected ST07 diagnostic")
+
+	actions := server.getCodeActions(CodeActionParams{
+		TextDocument: TextDocumentIdentifier{URI: doc.URI},
+		Context:      CodeActionContext{Diagnostics: []Diagnostic{*st07}},
+	})
+	require.Len(t, actions, 1)
+	assert.Equal(t, "Replace ON with USING (id)", actions[0].Title)
+	assert.False(t, actions[0].IsPreferred, "suggestions are never preferred")
+	assert.Equal(t, []TextEdit{{
+		Range:   Range{Start: Position{Line: 2, Character: 16}, End: Position{Line: 2, Character: 34}},
+		NewText: "USING (id)",
+	}}, actions[0].Edit.Changes[doc.URI])
+}
+
+// newPullServer initializes a server for a project with a staging model
+// missing its stg_ prefix, discovered into the state database, as a client
+// pulling diagnostics. It returns the server and the model file's URI.
+func newPullServer(t *testing.T) (*Server, *bytes.Buffer, string) {
+	t.Helper()
+	root := t.TempDir()
+	modelPath := filepath.Join(root, "models", "staging", "customers.sql")
+	require.NoError(t, os.MkdirAll(filepath.Dir(modelPath), 0750))
+	require.NoError(t, os.WriteFile(modelPath, []byte("SELECT id, name FROM raw_customers\n"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".leapsql"), 0750))
+
+	eng, err := engine.New(engine.Config{
+		ModelsDir: filepath.Join(root, "models"),
+		StatePath: filepath.Join(root, ".leapsql", "state.db"),
+		Target:    &starctx.TargetInfo{Type: "duckdb", Schema: "main"},
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err)
+	_, err = eng.Discover(engine.DiscoveryOptions{})
+	require.NoError(t, err)
+	require.NoError(t, eng.Close())
+
+	var out bytes.Buffer
+	server := NewServerWithLogger(strings.NewReader(""), &out, testutil.NewTestLogger(t))
+	params, err := json.Marshal(map[string]any{
+		"rootUri": PathToURI(root),
+		"capabilities": map[string]any{
+			"textDocument": map[string]any{"diagnostic": map[string]any{}},
+		},
+	})
+	require.NoError(t, err)
+	id := json.RawMessage("1")
+	require.NoError(t, server.handleInitialize(&JSONRPCMessage{ID: &id, Method: "initialize", Params: params}))
+	t.Cleanup(func() { _ = server.store.Close() })
+	require.True(t, server.pullDiagnostics)
+	out.Reset()
+	return server, &out, PathToURI(modelPath)
+}
+
+func TestServer_DocumentDiagnostics(t *testing.T) {
+	server, out, uri := newPullServer(t)
+
+	// Project rule results appear on the model file, read from disk
+	report, ok := server.getDocumentDiagnostics(DocumentDiagnosticParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+	}).(FullDocumentDiagnosticReport)
+	require.True(t, ok, "expected a full report")
+	var codes []string
+	for _, d := range report.Items {
+		codes = append(codes, d.Code)
+	}
+	assert.Contains(t, codes, "PS01")
+	assert.NotEmpty(t, report.ResultID)
+
+	// Pulling again with the same result ID reports no change
+	unchanged, ok := server.getDocumentDiagnostics(DocumentDiagnosticParams{
+		TextDocument:     TextDocumentIdentifier{URI: uri},
+		PreviousResultID: report.ResultID,
+	}).(UnchangedDocumentDiagnosticReport)
+	require.True(t, ok, "expected an unchanged report")
+	assert.Equal(t, report.ResultID, unchanged.ResultID)
+
+	// Editing the open document changes the result; nothing is pushed
+	server.documents.Open(uri, "SELECT id, name FROM raw_customers WHERE name = NULL\n", 2)
+	server.publishDiagnostics(uri)
+	assert.Empty(t, out.String())
+	report, ok = server.getDocumentDiagnostics(DocumentDiagnosticParams{
+		TextDocument:     TextDocumentIdentifier{URI: uri},
+		PreviousResultID: unchanged.ResultID,
+	}).(FullDocumentDiagnosticReport)
+	require.True(t, ok, "expected a full report")
+	codes = nil
+	for _, d := range report.Items {
+		codes = append(codes, d.Code)
+	}
+	assert.Contains(t, codes, "CV05")
+	assert.Contains(t, codes, "PS01")
+}
+
+func TestServer_WorkspaceDiagnostics(t *testing.T) {
+	server, _, uri := newPullServer(t)
+
+	report := server.getWorkspaceDiagnostics(WorkspaceDiagnosticParams{})
+	require.Len(t, report.Items, 1)
+	full, ok := report.Items[0].(WorkspaceFullDocumentDiagnosticReport)
+	require.True(t, ok, "expected a full report")
+	assert.Equal(t, uri, full.URI)
+	assert.Nil(t, full.Version, "files not open have no version")
+	require.NotEmpty(t, full.Items)
+
+	report = server.getWorkspaceDiagnostics(WorkspaceDiagnosticParams{
+		PreviousResultIDs: []PreviousResultID{{URI: uri, Value: full.ResultID}},
+	})
+	require.Len(t, report.Items, 1)
+	unchanged, ok := report.Items[0].(WorkspaceUnchangedDocumentDiagnosticReport)
+	require.True(t, ok, "expected an unchanged report")
+	assert.Equal(t, uri, unchanged.URI)
+	assert.Equal(t, DocumentDiagnosticReportKindUnchanged, unchanged.Kind)
+}
+
+func TestServer_DiagnosticPositions(t *testing.T) {
+	server := newHintServer(t)
+	version := 0
+	diagnostics := func(content string) map[string]Diagnostic {
+		uri := "file:///models/user_orders.sql"
+		version++
+		server.documents.Open(uri, content, version)
+		byCode := make(map[string]Diagnostic)
+		for _, d := range server.fileDiagnostics(server.documents.Get(uri), true) {
+			byCode[d.Code] = d
+		}
+		return byCode
+	}
+	frontmatter := "/*---\nmaterialized: view\n---*/\n"
+
+	// Lint findings and their fixes point past the frontmatter and templates
+	diags := diagnostics(frontmatter + "SELECT {{ utils.columns() }}\nFROM users usr\nJOIN orders ord ON usr.id = ord.id")
+	require.Contains(t, diags, "ST07")
+	assert.Equal(t, Range{Start: Position{Line: 5}, End: Position{Line: 5, Character: 34}}, diags["ST07"].Range)
+	actions := server.getCodeActions(CodeActionParams{
+		TextDocument: TextDocumentIdentifier{URI: "file:///models/user_orders.sql"},
+		Context:      CodeActionContext{Diagnostics: []Diagnostic{diags["ST07"]}},
+	})
+	require.Len(t, actions, 1)
+	assert.Equal(t, []TextEdit{{
+		Range:   Range{Start: Position{Line: 5, Character: 16}, End: Position{Line: 5, Character: 34}},
+		NewText: "USING (id)",
+	}}, actions[0].Edit.Changes["file:///models/user_orders.sql"])
+
+	// Syntax errors
+	diags = diagnostics(frontmatter + "SELECT {{ utils.columns() }}\nFROM users WHERE )")
+	require.Contains(t, diags, "E003")
+	assert.Equal(t, uint32(4), diags["E003"].Range.Start.Line)
+
+	// Template errors
+	diags = diagnostics(frontmatter + "SELECT id,\n    {{ utils.columns(\nFROM users")
+	require.Contains(t, diags, "E002")
+	assert.Equal(t, Position{Line: 4, Character: 4}, diags["E002"].Range.Start)
+}
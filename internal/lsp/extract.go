@@ -0,0 +1,142 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/internal/provider"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/parser"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+// extractActions returns the extract refactorings at the start of a code
+// action's range in a model file: the innermost subquery of a FROM clause
+// holding it into a CTE named after its alias, and the CTE holding it into
+// a model named after the CTE, for clients able to create files. Subqueries
+// and CTEs holding template expressions are left alone: the refactorings
+// are built on the SQL the templates stand for.
+func (s *Server) extractActions(params CodeActionParams) []CodeAction {
+	doc := s.documents.Get(params.TextDocument.URI)
+	if doc == nil || isStarlarkURI(doc.URI) || s.dialect == nil {
+		return nil
+	}
+	var parsed *provider.ParsedDocument
+	if s.provider != nil {
+		parsed = s.provider.GetOrParse(doc.URI, doc.Content, doc.Version)
+	} else {
+		parsed = provider.Parse(doc.Content, doc.URI, doc.Version, s.dialect)
+	}
+	if parsed.SQL == nil || parsed.SQLError != nil {
+		return nil
+	}
+
+	offset := doc.PositionToOffset(params.Range.Start)
+	holds := func(span token.Span) bool {
+		start, end := parsed.DocumentRange(span.Start.Offset, span.End.Offset)
+		return start <= offset && offset < end && !parsed.HasTemplate(span.Start.Offset, span.End.Offset)
+	}
+	tokens := parser.TokenizeWithDialect(parsed.SQLContent, s.dialect)
+
+	var actions []CodeAction
+	var sub *core.DerivedTable
+	for _, d := range lintsql.Subqueries(parsed.SQL) {
+		if holds(d.Span) {
+			sub = d
+		}
+	}
+	if sub != nil {
+		name := sub.Alias
+		if name == "" {
+			name = "subquery"
+		}
+		if fix, err := lintsql.ExtractCTE(parsed.SQL, parsed.SQLContent, tokens, sub, name); err == nil {
+			actions = append(actions, CodeAction{
+				Title: fmt.Sprintf("Extract subquery into CTE %s", name),
+				Kind:  CodeActionKindRefactorExtract,
+				Edit: &WorkspaceEdit{
+					Changes: map[string][]TextEdit{doc.URI: documentEdits(doc, parsed, fix.TextEdits)},
+				},
+			})
+		}
+	}
+
+	if s.createFiles && parsed.SQL.With != nil {
+		for _, c := range parsed.SQL.With.CTEs {
+			if holds(c.Span) {
+				if action, ok := s.extractModelAction(doc, parsed, tokens, c.Name); ok {
+					actions = append(actions, action)
+				}
+			}
+		}
+	}
+	return actions
+}
+
+// extractModelAction returns the refactoring extracting a CTE of a model
+// file into a new model named after it, next to the file. There is none if
+// the name is not a plain identifier or already taken, or the CTE cannot
+// be extracted.
+func (s *Server) extractModelAction(doc *Document, parsed *provider.ParsedDocument, tokens []token.Token, name string) (CodeAction, bool) {
+	plain := name != "" && strings.IndexFunc(name, func(r rune) bool {
+		return r != '_' && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9')
+	}) < 0
+	s.cacheMu.RLock()
+	taken := s.modelNameCache[name]
+	s.cacheMu.RUnlock()
+	path := filepath.Join(filepath.Dir(URIToPath(doc.URI)), name+".sql")
+	if _, err := os.Stat(path); !plain || taken || err == nil {
+		return CodeAction{}, false
+	}
+
+	fix, query, err := lintsql.ExtractModel(parsed.SQL, parsed.SQLContent, tokens, name, fmt.Sprintf("{{ ref('%s') }}", name))
+	if err != nil {
+		return CodeAction{}, false
+	}
+	newSQL, err := engine.FormatSQL(query, s.dialect)
+	if err != nil {
+		newSQL = query
+	}
+
+	uri := PathToURI(path)
+	version := doc.Version
+	return CodeAction{
+		Title: fmt.Sprintf("Extract CTE %s into a model", name),
+		Kind:  CodeActionKindRefactorExtract,
+		Edit: &WorkspaceEdit{DocumentChanges: []any{
+			CreateFile{Kind: "create", URI: uri},
+			TextDocumentEdit{
+				TextDocument: OptionalVersionedTextDocumentIdentifier{URI: uri},
+				Edits:        []TextEdit{{NewText: strings.TrimRight(newSQL, "\n") + "\n"}},
+			},
+			TextDocumentEdit{
+				TextDocument: OptionalVersionedTextDocumentIdentifier{URI: doc.URI, Version: &version},
+				Edits:        documentEdits(doc, parsed, fix.TextEdits),
+			},
+		}},
+	}, true
+}
+
+// documentEdits converts the edits of a fix built on the SQL of a parsed
+// document to edits of the document.
+func documentEdits(doc *Document, parsed *provider.ParsedDocument, edits []lint.TextEdit) []TextEdit {
+	result := make([]TextEdit, len(edits))
+	for i, edit := range edits {
+		start, end := parsed.DocumentRange(edit.Pos.Offset, edit.EndPos.Offset)
+		if edit.EndPos.Offset == edit.Pos.Offset {
+			// An insertion next to a template expression stays one
+			start = parsed.DocumentOffset(edit.Pos.Offset)
+			end = start
+		}
+		result[i] = TextEdit{
+			Range:   Range{Start: doc.OffsetToPosition(start), End: doc.OffsetToPosition(end)},
+			NewText: edit.NewText,
+		}
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package lsp
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/dialect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// applyEdits applies LSP text edits to a document's content.
+func applyEdits(doc *Document, edits []TextEdit) string {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return doc.PositionToOffset(edits[i].Range.Start) > doc.PositionToOffset(edits[j].Range.Start)
+	})
+	content := doc.Content
+	for _, e := range edits {
+		start, end := doc.PositionToOffset(e.Range.Start), doc.PositionToOffset(e.Range.End)
+		content = content[:start] + e.NewText + content[end:]
+	}
+	return content
+}
+
+func TestServer_ExtractActions(t *testing.T) {
+	duckdbDialect, _ := dialect.Get("duckdb")
+	server := &Server{
+		documents:      NewDocumentStore(),
+		dialect:        duckdbDialect,
+		modelNameCache: map[string]bool{},
+		createFiles:    true,
+	}
+	dir := t.TempDir()
+	uri := PathToURI(filepath.Join(dir, "orders.sql"))
+	content := "/*---\nname: orders\n---*/\nWITH paid AS (\n    SELECT id FROM raw_orders WHERE amount > 0\n)\nSELECT o.id, {{ 1 }} AS one\nFROM (SELECT id FROM paid) AS o\n"
+	server.documents.Open(uri, content, 3)
+	doc := server.documents.Get(uri)
+
+	actionsAt := func(text string, only ...CodeActionKind) []CodeAction {
+		return server.getCodeActions(CodeActionParams{
+			TextDocument: TextDocumentIdentifier{URI: uri},
+			Range:        Range{Start: doc.OffsetToPosition(strings.Index(content, text))},
+			Context:      CodeActionContext{Only: only},
+		})
+	}
+
+	// Subquery into CTE, past a template expression
+	actions := actionsAt("FROM paid")
+	require.Len(t, actions, 1)
+	assert.Equal(t, "Extract subquery into CTE o", actions[0].Title)
+	assert.Equal(t, CodeActionKindRefactorExtract, actions[0].Kind)
+	assert.Equal(t,
+		"/*---\nname: orders\n---*/\nWITH paid AS (\n    SELECT id FROM raw_orders WHERE amount > 0\n),\no AS (\n    SELECT id FROM paid\n)\nSELECT o.id, {{ 1 }} AS one\nFROM o\n",
+		applyEdits(doc, actions[0].Edit.Changes[uri]))
+
+	// CTE into model
+	actions = actionsAt("paid AS")
+	require.Len(t, actions, 1)
+	assert.Equal(t, "Extract CTE paid into a model", actions[0].Title)
+	changes := actions[0].Edit.DocumentChanges
+	require.Len(t, changes, 3)
+	newURI := PathToURI(filepath.Join(dir, "paid.sql"))
+	assert.Equal(t, CreateFile{Kind: "create", URI: newURI}, changes[0])
+	newModel, ok := changes[1].(TextDocumentEdit)
+	require.True(t, ok)
+	assert.Equal(t, newURI, newModel.TextDocument.URI)
+	assert.Contains(t, newModel.Edits[0].NewText, "raw_orders")
+	edit, ok := changes[2].(TextDocumentEdit)
+	require.True(t, ok)
+	require.NotNil(t, edit.TextDocument.Version)
+	assert.Equal(t, 3, *edit.TextDocument.Version)
+	assert.Equal(t,
+		"/*---\nname: orders\n---*/\nSELECT o.id, {{ 1 }} AS one\nFROM (SELECT id FROM {{ ref('paid') }} AS paid) AS o\n",
+		applyEdits(doc, edit.Edits))
+
+	// Taken names, clients unable to create files and quickfix requests
+	server.modelNameCache["paid"] = true
+	assert.Empty(t, actionsAt("paid AS"))
+	delete(server.modelNameCache, "paid")
+	server.createFiles = false
+	assert.Empty(t, actionsAt("paid AS"))
+	assert.Empty(t, actionsAt("FROM paid", CodeActionKindQuickFix))
+	assert.Empty(t, actionsAt("SELECT o.id"))
+}
@@ -0,0 +1,270 @@
+package lsp
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+// getFoldingRanges returns the folding ranges of a document: its frontmatter,
+// and the CTEs, subqueries, CASE expressions and select lists of its query
+// that span several lines.
+func (s *Server) getFoldingRanges(params FoldingRangeParams) []FoldingRange {
+	doc := s.documents.Get(params.TextDocument.URI)
+	if doc == nil || s.provider == nil {
+		return nil
+	}
+	parsed := s.provider.GetOrParse(doc.URI, doc.Content, doc.Version)
+
+	// Folds starting on the same line collapse to the outermost
+	ends := make(map[uint32]uint32)
+	addFold := func(start, end int) {
+		startLine := doc.OffsetToPosition(start).Line
+		endPos := doc.OffsetToPosition(end)
+		endLine := endPos.Line
+		// Keep the end line visible when more code follows on it, e.g.
+		// the next CTE after "),"
+		line := doc.GetLine(int(endPos.Line))
+		if strings.Trim(strings.TrimSpace(line[min(int(endPos.Character), len(line)):]), ",;") != "" {
+			endLine--
+		}
+		if endLine > startLine && endLine > ends[startLine] {
+			ends[startLine] = endLine
+		}
+	}
+
+	var ranges []FoldingRange
+	if parsed.FrontmatterEnd > 0 {
+		if start := strings.Index(doc.Content, "/*---"); start >= 0 && start < parsed.FrontmatterEnd {
+			startLine, endLine := doc.OffsetToPosition(start).Line, doc.OffsetToPosition(parsed.FrontmatterEnd-1).Line
+			if endLine > startLine {
+				ranges = append(ranges, FoldingRange{StartLine: startLine, EndLine: endLine, Kind: FoldingRangeKindComment})
+			}
+		}
+	}
+	if parsed.SQL != nil {
+		for _, r := range collectRegions(parsed.SQL) {
+			if r.fold {
+				addFold(parsed.DocumentOffset(r.span.Start.Offset), parsed.DocumentOffset(r.span.End.Offset))
+			}
+		}
+	}
+	for start, end := range ends {
+		ranges = append(ranges, FoldingRange{StartLine: start, EndLine: end})
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].StartLine < ranges[j].StartLine })
+	return ranges
+}
+
+// getSelectionRanges returns, for each position, the ranges a smart
+// selection expands through: the word at the position, then the select
+// items, expressions, clauses, subqueries and CTEs containing it, from the
+// innermost out, and last the whole statement.
+func (s *Server) getSelectionRanges(params SelectionRangeParams) []SelectionRange {
+	doc := s.documents.Get(params.TextDocument.URI)
+	if doc == nil || s.provider == nil {
+		return nil
+	}
+	parsed := s.provider.GetOrParse(doc.URI, doc.Content, doc.Version)
+
+	// The spans of the query in the document, the whole statement included
+	type docRange struct{ start, end int }
+	var spans []docRange
+	if parsed.SQL != nil {
+		for _, r := range collectRegions(parsed.SQL) {
+			spans = append(spans, docRange{parsed.DocumentOffset(r.span.Start.Offset), parsed.DocumentOffset(r.span.End.Offset)})
+		}
+		trimmed := strings.TrimSpace(parsed.SQLContent)
+		start := strings.Index(parsed.SQLContent, trimmed)
+		spans = append(spans, docRange{parsed.DocumentOffset(start), parsed.DocumentOffset(start + len(trimmed))})
+	}
+
+	result := make([]SelectionRange, 0, len(params.Positions))
+	for _, pos := range params.Positions {
+		offset := doc.PositionToOffset(pos)
+		var containing []docRange
+		for _, r := range spans {
+			if r.start <= offset && offset <= r.end {
+				containing = append(containing, r)
+			}
+		}
+		// Outermost first, so the chain is built from the outside in
+		sort.SliceStable(containing, func(i, j int) bool {
+			return containing[i].end-containing[i].start > containing[j].end-containing[j].start
+		})
+
+		var sel *SelectionRange
+		prev := docRange{-1, -1}
+		for _, r := range containing {
+			if r != prev {
+				sel = &SelectionRange{Range: Range{Start: doc.OffsetToPosition(r.start), End: doc.OffsetToPosition(r.end)}, Parent: sel}
+				prev = r
+			}
+		}
+		if word, rng := doc.GetWordAtPosition(pos); word != "" && (sel == nil || rng != sel.Range) {
+			sel = &SelectionRange{Range: rng, Parent: sel}
+		}
+		if sel == nil {
+			sel = &SelectionRange{Range: Range{Start: pos, End: pos}}
+		}
+		result = append(result, *sel)
+	}
+	return result
+}
+
+// sqlRegion is a region of a query's structure, in offsets of the SQL the
+// parser saw: a clause, a select item, a subquery or an expression with a
+// source span. Regions that fold are those worth collapsing in the editor.
+type sqlRegion struct {
+	span token.Span
+	fold bool
+}
+
+// collectRegions returns the regions of a statement, outer regions before
+// the regions they contain.
+func collectRegions(stmt *core.SelectStmt) []sqlRegion {
+	c := &regionCollector{}
+	c.walkStmt(stmt, false)
+	return c.regions
+}
+
+// regionCollector walks a statement collecting the regions of the nodes
+// that have source spans.
+type regionCollector struct {
+	regions []sqlRegion
+}
+
+func (c *regionCollector) add(span token.Span, fold bool) {
+	if span.Start.IsValid() && span.End.Offset > span.Start.Offset {
+		c.regions = append(c.regions, sqlRegion{span: span, fold: fold})
+	}
+}
+
+// walkStmt walks a statement. The select cores of nested statements, such as
+// subqueries, fold; those of the top-level statement span the whole query.
+func (c *regionCollector) walkStmt(stmt *core.SelectStmt, nested bool) {
+	if stmt == nil {
+		return
+	}
+	if stmt.With != nil {
+		c.add(stmt.With.Span, false)
+		for _, cte := range stmt.With.CTEs {
+			c.add(cte.Span, true)
+			c.walkStmt(cte.Select, true)
+		}
+	}
+	for body := stmt.Body; body != nil; body = body.Right {
+		c.walkCore(body.Left, nested)
+	}
+}
+
+func (c *regionCollector) walkCore(sc *core.SelectCore, nested bool) {
+	if sc == nil {
+		return
+	}
+	c.add(sc.Span, nested)
+	c.add(sc.ColumnsSpan, true)
+	for _, item := range sc.Columns {
+		c.add(item.Span, false)
+		c.walkExpr(item.Expr)
+	}
+	if sc.From != nil {
+		c.walkTableRef(sc.From.Source)
+		for _, join := range sc.From.Joins {
+			c.add(join.Span, false)
+			c.walkTableRef(join.Right)
+			c.add(join.ConditionSpan, false)
+			c.walkExpr(join.Condition)
+		}
+	}
+	c.walkExpr(sc.Where)
+	for _, e := range sc.GroupBy {
+		c.walkExpr(e)
+	}
+	c.walkExpr(sc.Having)
+	c.walkExpr(sc.Qualify)
+	for _, item := range sc.OrderBy {
+		c.walkExpr(item.Expr)
+	}
+}
+
+func (c *regionCollector) walkTableRef(ref core.TableRef) {
+	switch t := ref.(type) {
+	case *core.TableName:
+		c.add(t.Span, false)
+	case *core.DerivedTable:
+		c.add(t.Span, true)
+		c.walkStmt(t.Select, true)
+	case *core.LateralTable:
+		c.add(t.Span, true)
+		c.walkStmt(t.Select, true)
+	case *core.TableFunction:
+		for _, arg := range t.Args {
+			c.walkExpr(arg)
+		}
+	}
+}
+
+func (c *regionCollector) walkExpr(expr core.Expr) {
+	switch e := expr.(type) {
+	case *core.BinaryExpr:
+		c.walkExpr(e.Left)
+		c.walkExpr(e.Right)
+	case *core.UnaryExpr:
+		c.walkExpr(e.Expr)
+	case *core.ParenExpr:
+		c.walkExpr(e.Expr)
+	case *core.FuncCall:
+		for _, arg := range e.Args {
+			c.walkExpr(arg)
+		}
+		c.walkExpr(e.Filter)
+	case *core.CaseExpr:
+		c.add(e.Span, true)
+		c.walkExpr(e.Operand)
+		for _, when := range e.Whens {
+			c.add(when.ConditionSpan, false)
+			c.walkExpr(when.Condition)
+			c.walkExpr(when.Result)
+		}
+		c.walkExpr(e.Else)
+	case *core.CastExpr:
+		c.walkExpr(e.Expr)
+	case *core.InExpr:
+		c.add(e.Span, e.Query != nil)
+		c.walkExpr(e.Expr)
+		for _, v := range e.Values {
+			c.walkExpr(v)
+		}
+		c.walkStmt(e.Query, true)
+	case *core.BetweenExpr:
+		c.walkExpr(e.Expr)
+		c.walkExpr(e.Low)
+		c.walkExpr(e.High)
+	case *core.IsNullExpr:
+		c.walkExpr(e.Expr)
+	case *core.IsBoolExpr:
+		c.walkExpr(e.Expr)
+	case *core.LikeExpr:
+		c.walkExpr(e.Expr)
+		c.walkExpr(e.Pattern)
+	case *core.SubqueryExpr:
+		c.walkStmt(e.Select, true)
+	case *core.ExistsExpr:
+		c.walkStmt(e.Select, true)
+	case *core.LambdaExpr:
+		c.walkExpr(e.Body)
+	case *core.ListLiteral:
+		for _, el := range e.Elements {
+			c.walkExpr(el)
+		}
+	case *core.StructLiteral:
+		for _, f := range e.Fields {
+			c.walkExpr(f.Value)
+		}
+	case *core.IndexExpr:
+		c.walkExpr(e.Expr)
+	}
+}
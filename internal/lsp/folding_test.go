@@ -0,0 +1,93 @@
+package lsp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const foldingModel = `/*---
+materialized: view
+---*/
+
+WITH totals AS (
+    SELECT customer_id, sum(amount) AS amount
+    FROM {{ ref('orders') }}
+    GROUP BY customer_id
+), big AS (
+    SELECT * FROM totals WHERE amount > 100
+)
+SELECT
+    t.customer_id,
+    CASE
+        WHEN t.amount > 1000 THEN 'gold'
+        ELSE 'silver'
+    END AS tier
+FROM big t
+WHERE t.customer_id IN (
+    SELECT id FROM customers
+)
+`
+
+func TestServer_FoldingRanges(t *testing.T) {
+	server := newHintServer(t)
+	uri := "file:///models/marts/tiers.sql"
+	server.documents.Open(uri, foldingModel, 1)
+
+	ranges := server.getFoldingRanges(FoldingRangeParams{TextDocument: TextDocumentIdentifier{URI: uri}})
+	assert.Equal(t, []FoldingRange{
+		{StartLine: 0, EndLine: 2, Kind: FoldingRangeKindComment}, // Frontmatter
+		{StartLine: 4, EndLine: 7},                                // totals, ending before the next CTE
+		{StartLine: 5, EndLine: 7},                                // The select of totals
+		{StartLine: 8, EndLine: 10},                               // big
+		{StartLine: 12, EndLine: 16},                              // Select list
+		{StartLine: 13, EndLine: 15},                              // CASE, ending before its alias
+		{StartLine: 18, EndLine: 20},                              // IN subquery
+	}, ranges)
+
+	assert.Nil(t, server.getFoldingRanges(FoldingRangeParams{TextDocument: TextDocumentIdentifier{URI: "file:///missing.sql"}}))
+}
+
+func TestServer_SelectionRanges(t *testing.T) {
+	server := newHintServer(t)
+	uri := "file:///models/marts/tiers.sql"
+	server.documents.Open(uri, foldingModel, 1)
+	doc := server.documents.Get(uri)
+
+	// From "gold" in the CASE expression out to the whole statement, and
+	// from a template expression out to its CTE
+	ranges := server.getSelectionRanges(SelectionRangeParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Positions:    []Position{{Line: 14, Character: 36}, {Line: 6, Character: 13}},
+	})
+	require.Len(t, ranges, 2)
+	selected := func(sel *SelectionRange) []string {
+		var texts []string
+		for ; sel != nil; sel = sel.Parent {
+			texts = append(texts, doc.GetTextInRange(sel.Range))
+		}
+		return texts
+	}
+	caseExpr := "CASE\n        WHEN t.amount > 1000 THEN 'gold'\n        ELSE 'silver'\n    END"
+	statement := foldingModel[len("/*---\nmaterialized: view\n---*/\n\n") : len(foldingModel)-1]
+	assert.Equal(t, []string{
+		"gold",
+		caseExpr,
+		caseExpr + " AS tier",
+		"t.customer_id,\n    " + caseExpr + " AS tier",
+		statement[strings.Index(statement, "SELECT\n    t.customer_id"):],
+		statement,
+	}, selected(&ranges[0]))
+
+	totals := "SELECT customer_id, sum(amount) AS amount\n    FROM {{ ref('orders') }}\n    GROUP BY customer_id"
+	assert.Equal(t, []string{
+		"ref",
+		"{{ ref('orders') }}",
+		totals,
+		"totals AS (\n    " + totals + "\n)",
+		statement[:strings.Index(statement, "\nSELECT\n    t.customer_id")],
+		statement,
+	}, selected(&ranges[1]))
+}
@@ -0,0 +1,286 @@
+package lsp
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/leapstack-labs/leapsql/internal/provider"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+// getInlayHints returns the inlay hints of a document within the requested
+// range: a header hint with the model's materialization and target schema,
+// and the inferred type after each select item whose type is known.
+func (s *Server) getInlayHints(params InlayHintParams) []InlayHint {
+	doc := s.documents.Get(params.TextDocument.URI)
+	if doc == nil || s.provider == nil {
+		return nil
+	}
+	parsed := s.provider.GetOrParse(doc.URI, doc.Content, doc.Version)
+
+	var hints []InlayHint
+	if s.materializationHints {
+		hints = append(hints, s.materializationHint(doc, parsed))
+	}
+	if s.columnTypeHints && parsed.SQL != nil {
+		hints = append(hints, s.columnTypeHintsOf(doc, parsed)...)
+	}
+
+	var inRange []InlayHint
+	for _, h := range hints {
+		if !positionBefore(h.Position, params.Range.Start) && !positionBefore(params.Range.End, h.Position) {
+			inRange = append(inRange, h)
+		}
+	}
+	return inRange
+}
+
+// materializationHint returns the header hint of a model, before its query:
+// how it is materialized and the schema it is built in. Models default to
+// tables in the schema of their path, or the target's schema.
+func (s *Server) materializationHint(doc *Document, parsed *provider.ParsedDocument) InlayHint {
+	materialized, schema := "table", ""
+	if fm := parsed.Frontmatter; fm != nil && fm.Config != nil {
+		if fm.Config.Materialized != "" {
+			materialized = fm.Config.Materialized
+		}
+		schema = fm.Config.Schema
+	}
+	if schema == "" {
+		schema = s.modelSchema(URIToPath(doc.URI))
+	}
+
+	label := "materialized: " + materialized
+	if schema != "" {
+		label += " · schema: " + schema
+	}
+
+	// The hint precedes the first non-blank character after the frontmatter
+	offset := parsed.FrontmatterEnd
+	if i := strings.IndexFunc(doc.Content[min(offset, len(doc.Content)):], func(r rune) bool { return !unicode.IsSpace(r) }); i >= 0 {
+		offset += i
+	}
+	return InlayHint{
+		Position:     doc.OffsetToPosition(offset),
+		Label:        label,
+		PaddingRight: true,
+	}
+}
+
+// modelSchema returns the schema the model of a file is built in, from its
+// path in the project, e.g. "staging" for staging.customers, or the target's
+// schema when the file's model has none or is not discovered.
+func (s *Server) modelSchema(filePath string) string {
+	ctx := s.buildProjectContext()
+	if ctx != nil {
+		for _, path := range modelsInFile(ctx, filePath) {
+			_, table := core.SplitModelID(path)
+			if i := strings.LastIndex(table, "."); i >= 0 {
+				return table[:i]
+			}
+		}
+	}
+	return s.targetSchema
+}
+
+// columnTypeHintsOf returns a type hint after each select item of the
+// document's query and CTEs whose type can be inferred.
+func (s *Server) columnTypeHintsOf(doc *Document, parsed *provider.ParsedDocument) []InlayHint {
+	inf := &typeInferrer{dialect: s.dialect, ctes: make(map[string]*core.SelectStmt)}
+	if parsed.SQL.With != nil {
+		for _, cte := range parsed.SQL.With.CTEs {
+			inf.ctes[strings.ToLower(cte.Name)] = cte.Select
+		}
+	}
+
+	var hints []InlayHint
+	addHints := func(stmt *core.SelectStmt) {
+		for body := stmt.Body; body != nil; body = body.Right {
+			if body.Left == nil {
+				continue
+			}
+			for _, item := range body.Left.Columns {
+				if item.Star || item.TableStar != "" || !item.Span.End.IsValid() {
+					continue
+				}
+				typ := inf.typeOf(item.Expr, body.Left, 0)
+				if typ == "" {
+					continue
+				}
+				hints = append(hints, InlayHint{
+					Position: doc.OffsetToPosition(parsed.DocumentOffset(item.Span.End.Offset)),
+					Label:    ": " + typ,
+					Kind:     InlayHintKindType,
+				})
+			}
+		}
+	}
+	if parsed.SQL.With != nil {
+		for _, cte := range parsed.SQL.With.CTEs {
+			if cte.Select != nil {
+				addHints(cte.Select)
+			}
+		}
+	}
+	addHints(parsed.SQL)
+	return hints
+}
+
+// maxInferenceDepth bounds how many CTEs and derived tables type inference
+// follows column references through.
+const maxInferenceDepth = 8
+
+// typeInferrer infers the types of expressions from literals, casts, the
+// return types the dialect documents for functions, and the select items of
+// the CTEs and derived tables columns are read from.
+type typeInferrer struct {
+	dialect *core.Dialect
+	ctes    map[string]*core.SelectStmt // By lowercase name
+}
+
+var signatureReturnRe = regexp.MustCompile(`->\s*(.+)$`)
+
+// typeOf returns the type expr evaluates to in the select core it belongs
+// to, or "" if it cannot be inferred.
+func (inf *typeInferrer) typeOf(expr core.Expr, scope *core.SelectCore, depth int) string {
+	switch e := expr.(type) {
+	case *core.ParenExpr:
+		return inf.typeOf(e.Expr, scope, depth)
+	case *core.Literal:
+		switch e.Type {
+		case core.LiteralNumber:
+			if strings.ContainsAny(e.Value, ".eE") {
+				return "DECIMAL"
+			}
+			return "INTEGER"
+		case core.LiteralString:
+			return "VARCHAR"
+		case core.LiteralBool:
+			return "BOOLEAN"
+		}
+	case *core.CastExpr:
+		return strings.ToUpper(strings.TrimSpace(e.TypeName))
+	case *core.FuncCall:
+		return inf.functionType(e.Name)
+	case *core.ColumnRef:
+		return inf.columnType(e, scope, depth)
+	case *core.CaseExpr:
+		typ := ""
+		for i, when := range e.Whens {
+			t := inf.typeOf(when.Result, scope, depth)
+			if t == "" || (i > 0 && t != typ) {
+				return ""
+			}
+			typ = t
+		}
+		if e.Else != nil && inf.typeOf(e.Else, scope, depth) != typ {
+			return ""
+		}
+		return typ
+	case *core.BinaryExpr:
+		switch e.Op {
+		case token.DPIPE:
+			return "VARCHAR"
+		case token.EQ, token.NE, token.LT, token.GT, token.LE, token.GE, token.AND, token.OR:
+			return "BOOLEAN"
+		}
+	case *core.InExpr, *core.BetweenExpr, *core.IsNullExpr, *core.IsBoolExpr, *core.LikeExpr, *core.ExistsExpr:
+		return "BOOLEAN"
+	}
+	return ""
+}
+
+// functionType returns the type a function returns, as documented by the
+// dialect: its return type, or the return type all its signatures share.
+// Generic return types are unknown.
+func (inf *typeInferrer) functionType(name string) string {
+	if inf.dialect == nil {
+		return ""
+	}
+	doc, ok := inf.dialect.GetDoc(name)
+	if !ok {
+		return ""
+	}
+	typ := doc.ReturnType
+	for i, sig := range doc.Signatures {
+		m := signatureReturnRe.FindStringSubmatch(sig)
+		if m == nil {
+			return ""
+		}
+		t := strings.TrimSpace(m[1])
+		if i > 0 && t != typ {
+			return ""
+		}
+		typ = t
+	}
+	if strings.Contains(typ, "ANY") || strings.Contains(typ, `"`) {
+		return ""
+	}
+	return typ
+}
+
+// columnType returns the type of a column read from a CTE or a derived
+// table: the type of the select item that outputs it. Unqualified columns are
+// resolved only when the select core reads a single table.
+func (inf *typeInferrer) columnType(ref *core.ColumnRef, scope *core.SelectCore, depth int) string {
+	if scope == nil || scope.From == nil || len(ref.Fields) > 0 || depth >= maxInferenceDepth {
+		return ""
+	}
+	if ref.Table == "" && len(scope.From.Joins) > 0 {
+		return ""
+	}
+
+	sources := []core.TableRef{scope.From.Source}
+	for _, join := range scope.From.Joins {
+		sources = append(sources, join.Right)
+	}
+	for _, src := range sources {
+		var stmt *core.SelectStmt
+		switch t := src.(type) {
+		case *core.TableName:
+			if ref.Table != "" && !strings.EqualFold(ref.Table, t.Alias) && !(t.Alias == "" && strings.EqualFold(ref.Table, t.Name)) {
+				continue
+			}
+			if t.Schema == "" {
+				stmt = inf.ctes[strings.ToLower(t.Name)]
+			}
+		case *core.DerivedTable:
+			if ref.Table != "" && !strings.EqualFold(ref.Table, t.Alias) {
+				continue
+			}
+			stmt = t.Select
+		}
+		if stmt == nil || stmt.Body == nil || stmt.Body.Left == nil {
+			return ""
+		}
+		return inf.outputType(stmt.Body.Left, ref.Column, depth+1)
+	}
+	return ""
+}
+
+// outputType returns the type of the column a select core outputs under
+// name, or "" if it outputs none or its type is unknown.
+func (inf *typeInferrer) outputType(sc *core.SelectCore, name string, depth int) string {
+	for _, item := range sc.Columns {
+		if item.Star || item.TableStar != "" {
+			continue
+		}
+		outName := item.Alias
+		if outName == "" {
+			if col, ok := item.Expr.(*core.ColumnRef); ok {
+				outName = col.Column
+			}
+		}
+		if strings.EqualFold(outName, name) {
+			return inf.typeOf(item.Expr, sc, depth)
+		}
+	}
+	return ""
+}
+
+// positionBefore reports whether a comes before b.
+func positionBefore(a, b Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Character < b.Character)
+}
@@ -0,0 +1,115 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/provider"
+	"github.com/leapstack-labs/leapsql/internal/testutil"
+	"github.com/leapstack-labs/leapsql/pkg/dialect"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHintServer returns a server for the duckdb dialect, without a project,
+// with both kinds of inlay hints shown.
+func newHintServer(t *testing.T) *Server {
+	t.Helper()
+	d, _ := dialect.Get("duckdb")
+	server := NewServerWithLogger(nil, nil, testutil.NewTestLogger(t))
+	server.dialect = d
+	server.provider = provider.New(nil, d, lint.DefaultProjectHealthConfig(), server.logger)
+	return server
+}
+
+// wholeDocument is a range covering any document of the tests.
+var wholeDocument = Range{End: Position{Line: 100}}
+
+func TestServer_InlayHints(t *testing.T) {
+	server := newHintServer(t)
+	uri := "file:///models/marts/orders.sql"
+	content := `/*---
+materialized: view
+schema: analytics
+---*/
+
+WITH totals AS (
+    SELECT customer_id, CAST(amount AS decimal(10, 2)) AS amount
+    FROM raw_orders
+)
+SELECT
+    t.customer_id,
+    t.amount,
+    count(*) AS orders,
+    'web' AS channel,
+    amount > 100 AS is_large
+FROM totals t
+`
+	server.documents.Open(uri, content, 1)
+
+	hints := server.getInlayHints(InlayHintParams{TextDocument: TextDocumentIdentifier{URI: uri}, Range: wholeDocument})
+	assert.Equal(t, []InlayHint{
+		// The header hint precedes the query
+		{Position: Position{Line: 5}, Label: "materialized: view · schema: analytics", PaddingRight: true},
+		// Type hints follow select items, aliases included, and types are
+		// read through CTEs
+		{Position: Position{Line: 6, Character: 64}, Label: ": DECIMAL(10, 2)", Kind: InlayHintKindType},
+		{Position: Position{Line: 11, Character: 12}, Label: ": DECIMAL(10, 2)", Kind: InlayHintKindType},
+		{Position: Position{Line: 12, Character: 22}, Label: ": BIGINT", Kind: InlayHintKindType},
+		{Position: Position{Line: 13, Character: 20}, Label: ": VARCHAR", Kind: InlayHintKindType},
+		{Position: Position{Line: 14, Character: 28}, Label: ": BOOLEAN", Kind: InlayHintKindType},
+	}, hints)
+
+	// Hints outside the requested range are left out
+	hints = server.getInlayHints(InlayHintParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Range:        Range{Start: Position{Line: 12}, End: Position{Line: 13}},
+	})
+	require.Len(t, hints, 1)
+	assert.Equal(t, ": BIGINT", hints[0].Label)
+}
+
+func TestServer_InlayHints_Templates(t *testing.T) {
+	server := newHintServer(t)
+	uri := "file:///models/orders.sql"
+	server.documents.Open(uri, "SELECT {{ utils.amount() }} AS amount, 1 AS one\nFROM orders\n", 1)
+
+	// Items after a template expression are hinted where the user wrote them
+	hints := server.getInlayHints(InlayHintParams{TextDocument: TextDocumentIdentifier{URI: uri}, Range: wholeDocument})
+	assert.Equal(t, []InlayHint{
+		{Position: Position{}, Label: "materialized: table", PaddingRight: true},
+		{Position: Position{Character: 47}, Label: ": INTEGER", Kind: InlayHintKindType},
+	}, hints)
+}
+
+func TestServer_InlayHints_ModelSchema(t *testing.T) {
+	server, _, uri := newPullServer(t)
+	server.documents.Open(uri, "SELECT id, name FROM raw_customers\n", 1)
+
+	hints := server.getInlayHints(InlayHintParams{TextDocument: TextDocumentIdentifier{URI: uri}, Range: wholeDocument})
+	require.Len(t, hints, 1)
+	assert.Equal(t, "materialized: table · schema: staging", hints[0].Label)
+}
+
+func TestServer_InlayHints_Configuration(t *testing.T) {
+	server := newHintServer(t)
+	uri := "file:///models/orders.sql"
+	server.documents.Open(uri, "SELECT 1 AS one\n", 1)
+
+	params, err := json.Marshal(map[string]any{
+		"settings": map[string]any{
+			"leapsql": map[string]any{"inlayHints": map[string]any{"materialization": false}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, server.handleDidChangeConfiguration(&JSONRPCMessage{Method: "workspace/didChangeConfiguration", Params: params}))
+
+	hints := server.getInlayHints(InlayHintParams{TextDocument: TextDocumentIdentifier{URI: uri}, Range: wholeDocument})
+	require.Len(t, hints, 1)
+	assert.Equal(t, ": INTEGER", hints[0].Label)
+
+	off := false
+	server.applySettings(&Settings{InlayHints: &InlayHintSettings{ColumnTypes: &off}})
+	assert.Empty(t, server.getInlayHints(InlayHintParams{TextDocument: TextDocumentIdentifier{URI: uri}, Range: wholeDocument}))
+}
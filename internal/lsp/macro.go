@@ -0,0 +1,211 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/config"
+	"github.com/leapstack-labs/leapsql/internal/macro"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// macroRef is a reference to a macro namespace, or to one of its functions,
+// in the template code of a model.
+type macroRef struct {
+	Namespace string
+	Function  string // Empty for a reference to the namespace itself
+}
+
+// macroRefAt returns the macro reference named by the word at a position of
+// a document, within a template expression or statement: the function in
+// {{ finance.revenue('amount') }} when the word is revenue, or the
+// namespace when it is finance. It reports false for words that are not
+// part of a namespace.function path.
+func macroRefAt(doc *Document, pos Position) (macroRef, bool) {
+	word, rng := doc.GetWordAtPosition(pos)
+	if word == "" {
+		return macroRef{}, false
+	}
+	start, end := doc.PositionToOffset(rng.Start), doc.PositionToOffset(rng.End)
+	before, after := doc.Content[:start], doc.Content[end:]
+	if !inTemplateCode(before) {
+		return macroRef{}, false
+	}
+
+	// The word is a function: its namespace precedes it
+	if strings.HasSuffix(before, ".") {
+		ns := extractIdentifierBefore(before, len(before)-1)
+		if ns == "" || strings.HasSuffix(before[:len(before)-1-len(ns)], ".") {
+			return macroRef{}, false
+		}
+		return macroRef{Namespace: ns, Function: word}, true
+	}
+	// The word is a namespace: a function follows it
+	if strings.HasPrefix(after, ".") && len(after) > 1 && isIdentChar(after[1]) {
+		return macroRef{Namespace: word}, true
+	}
+	return macroRef{}, false
+}
+
+// inTemplateCode reports whether the text before a position ends inside a
+// template expression {{ ... }} or statement {* ... *}.
+func inTemplateCode(before string) bool {
+	return inTemplateExpr(before) || strings.LastIndex(before, "{*") > strings.LastIndex(before, "*}")
+}
+
+// macroHover returns the hover of a macro reference from the discover index:
+// a function's signature and docstring, or the functions of a namespace,
+// with the file defining them.
+func (s *Server) macroHover(ref macroRef) *Hover {
+	ns, _ := s.store.GetMacroNamespace(ref.Namespace)
+	if ns == nil {
+		return nil
+	}
+
+	var content strings.Builder
+	if ref.Function != "" {
+		fn, _ := s.store.GetMacroFunction(ref.Namespace, ref.Function)
+		if fn == nil {
+			return nil
+		}
+		fmt.Fprintf(&content, "```\n%s.%s(%s)\n```", ns.Name, fn.Name, strings.Join(fn.Args, ", "))
+		if doc := cleanDocstring(fn.Docstring); doc != "" {
+			content.WriteString("\n\n" + doc)
+		}
+	} else {
+		fmt.Fprintf(&content, "**%s** (macro namespace)\n", ns.Name)
+		functions, _ := s.store.GetMacroFunctions(ns.Name)
+		for _, fn := range functions {
+			fmt.Fprintf(&content, "\n- `%s(%s)`", fn.Name, strings.Join(fn.Args, ", "))
+			if summary, _, _ := strings.Cut(fn.Docstring, "\n"); summary != "" {
+				content.WriteString(": " + summary)
+			}
+		}
+	}
+
+	fmt.Fprintf(&content, "\n\n*Defined in %s", s.relativePath(ns.FilePath))
+	if ns.Package != "" {
+		fmt.Fprintf(&content, " (package %s)", ns.Package)
+	}
+	content.WriteString("*")
+
+	return &Hover{
+		Contents: MarkupContent{
+			Kind:  MarkupKindMarkdown,
+			Value: content.String(),
+		},
+	}
+}
+
+// cleanDocstring removes the indentation docstrings share after their first
+// line, which Markdown would render as a code block.
+func cleanDocstring(doc string) string {
+	lines := strings.Split(strings.TrimSpace(doc), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != "" {
+			if n := len(line) - len(trimmed); indent < 0 || n < indent {
+				indent = n
+			}
+		}
+	}
+	for i := 1; i < len(lines); i++ {
+		lines[i] = lines[i][min(max(indent, 0), len(lines[i])):]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// macroDefinition returns the location defining a macro reference from the
+// discover index: the line of a function's def, or the top of a namespace's
+// file. Unknown functions of a known namespace resolve to its file.
+func (s *Server) macroDefinition(ref macroRef) *Location {
+	ns, _ := s.store.GetMacroNamespace(ref.Namespace)
+	if ns == nil {
+		return nil
+	}
+
+	line := 0
+	if ref.Function != "" {
+		if fn, _ := s.store.GetMacroFunction(ref.Namespace, ref.Function); fn != nil && fn.Line > 0 {
+			line = fn.Line - 1 // Convert to 0-based
+		}
+	}
+	pos := Position{Line: uint32(line)} //nolint:gosec // G115: line is always non-negative
+	return &Location{
+		URI:   PathToURI(ns.FilePath),
+		Range: Range{Start: pos, End: pos},
+	}
+}
+
+// relativePath returns a path relative to the project root, or the path
+// itself when it lies outside the project.
+func (s *Server) relativePath(path string) string {
+	if s.projectRoot != "" {
+		if rel, err := filepath.Rel(s.projectRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return path
+}
+
+// macrosDir returns the absolute path of the project's macros directory.
+func (s *Server) macrosDir() string {
+	dir := config.DefaultMacrosDir
+	if cfg, err := config.LoadFromDir(s.projectRoot); err == nil && cfg != nil && cfg.MacrosDir != "" {
+		dir = cfg.MacrosDir
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(s.projectRoot, dir)
+	}
+	return dir
+}
+
+// reindexMacroFile re-parses a saved macro file of the project into the
+// state database, so hovers, definitions and diagnostics see its functions
+// before the next discover. Files outside the macros directory are skipped.
+func (s *Server) reindexMacroFile(path string) {
+	if rel, err := filepath.Rel(s.macrosDir(), path); err != nil || strings.HasPrefix(rel, "..") {
+		return
+	}
+
+	content, err := os.ReadFile(path) //nolint:gosec // G304: path is a saved file of the project's macros directory
+	if err != nil {
+		s.logger.Warn("Failed to read macro file", "path", path, "error", err)
+		return
+	}
+	parsed, err := macro.ParseStarlarkFile(path, content)
+	if err != nil {
+		s.logger.Warn("Failed to parse macro file", "path", path, "error", err)
+		return
+	}
+
+	ns := &core.MacroNamespace{Name: parsed.Name, FilePath: path}
+	if existing, _ := s.store.GetMacroNamespace(parsed.Name); existing != nil {
+		ns.Package = existing.Package
+	}
+	funcs := make([]*core.MacroFunction, 0, len(parsed.Functions))
+	for _, f := range parsed.Functions {
+		funcs = append(funcs, &core.MacroFunction{
+			Namespace: parsed.Name,
+			Name:      f.Name,
+			Args:      f.Args,
+			Docstring: f.Docstring,
+			Line:      f.Line,
+		})
+	}
+	if err := s.store.SaveMacroNamespace(ns, funcs); err != nil {
+		s.logger.Warn("Failed to re-index macro file", "path", path, "error", err)
+		return
+	}
+
+	s.macroNamespaceCache[ns.Name] = true
+	s.logger.Info("Re-indexed macro file", "path", path, "namespace", ns.Name, "functions", len(funcs))
+	// References to the namespace in open models may resolve now
+	for _, uri := range s.documents.List() {
+		if strings.HasSuffix(uri, ".sql") {
+			s.publishDiagnostics(uri)
+		}
+	}
+}
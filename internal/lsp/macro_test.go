@@ -0,0 +1,148 @@
+package lsp
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+	"github.com/leapstack-labs/leapsql/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const financeMacros = `def _rate(currency):
+    return "1.0"
+
+def revenue(column, currency=None):
+    """Sums a column of amounts.
+
+    Amounts are converted to the currency when one is given."""
+    return "sum(" + column + ")"
+
+def margin(revenue, cost):
+    return revenue + " - " + cost
+`
+
+// newMacroServer initializes a server for a project with a finance macro
+// namespace. It returns the server and the path of the macro file.
+func newMacroServer(t *testing.T) (*Server, string) {
+	t.Helper()
+	root := t.TempDir()
+	macroPath := filepath.Join(root, "macros", "finance.star")
+	require.NoError(t, os.MkdirAll(filepath.Dir(macroPath), 0750))
+	require.NoError(t, os.WriteFile(macroPath, []byte(financeMacros), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "models"), 0750))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".leapsql"), 0750))
+
+	eng, err := engine.New(engine.Config{
+		ModelsDir: filepath.Join(root, "models"),
+		MacrosDir: filepath.Join(root, "macros"),
+		StatePath: filepath.Join(root, ".leapsql", "state.db"),
+		Target:    &starctx.TargetInfo{Type: "duckdb", Schema: "main"},
+		Logger:    testutil.NewTestLogger(t),
+	})
+	require.NoError(t, err)
+	_, err = eng.Discover(engine.DiscoveryOptions{})
+	require.NoError(t, err)
+	require.NoError(t, eng.Close())
+
+	server := NewServerWithLogger(strings.NewReader(""), &bytes.Buffer{}, testutil.NewTestLogger(t))
+	params, err := json.Marshal(map[string]any{"rootUri": PathToURI(root)})
+	require.NoError(t, err)
+	id := json.RawMessage("1")
+	require.NoError(t, server.handleInitialize(&JSONRPCMessage{ID: &id, Method: "initialize", Params: params}))
+	t.Cleanup(func() { _ = server.store.Close() })
+	require.NotNil(t, server.store)
+	return server, macroPath
+}
+
+func TestMacroRefAt(t *testing.T) {
+	store := NewDocumentStore()
+	store.Open("file:///m.sql", "SELECT {{ finance.revenue('amount') }}, finance.revenue\n{* if utils.flag() *}x{* endif *}", 1)
+	doc := store.Get("file:///m.sql")
+
+	tests := []struct {
+		name string
+		pos  Position
+		want macroRef
+		ok   bool
+	}{
+		{"function start", Position{Character: 18}, macroRef{Namespace: "finance", Function: "revenue"}, true},
+		{"function middle", Position{Character: 22}, macroRef{Namespace: "finance", Function: "revenue"}, true},
+		{"namespace", Position{Character: 12}, macroRef{Namespace: "finance"}, true},
+		{"argument", Position{Character: 29}, macroRef{}, false},
+		{"outside templates", Position{Character: 50}, macroRef{}, false},
+		{"template statement", Position{Line: 1, Character: 13}, macroRef{Namespace: "utils", Function: "flag"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := macroRefAt(doc, tt.pos)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestServer_MacroHover(t *testing.T) {
+	server, macroPath := newMacroServer(t)
+	uri := "file:///models/orders.sql"
+	server.documents.Open(uri, "SELECT {{ finance.revenue('amount') }} AS revenue FROM orders", 1)
+
+	hover := server.getHover(HoverParams{TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{Character: 22}}})
+	require.NotNil(t, hover)
+	assert.Equal(t, "```\nfinance.revenue(column, currency=None)\n```\n\n"+
+		"Sums a column of amounts.\n\nAmounts are converted to the currency when one is given.\n\n"+
+		"*Defined in "+filepath.Join("macros", "finance.star")+"*", hover.Contents.Value)
+
+	// The namespace lists its public functions
+	hover = server.getHover(HoverParams{TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{Character: 12}}})
+	require.NotNil(t, hover)
+	assert.Contains(t, hover.Contents.Value, "**finance** (macro namespace)")
+	assert.Contains(t, hover.Contents.Value, "- `revenue(column, currency=None)`: Sums a column of amounts.")
+	assert.Contains(t, hover.Contents.Value, "- `margin(revenue, cost)`")
+	assert.NotContains(t, hover.Contents.Value, "_rate")
+
+	// Definitions jump to the def line, or the top of the namespace's file
+	def := server.getDefinition(DefinitionParams{TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{Character: 22}}})
+	require.NotNil(t, def)
+	assert.Equal(t, PathToURI(macroPath), def.URI)
+	assert.Equal(t, uint32(3), def.Range.Start.Line)
+	def = server.getDefinition(DefinitionParams{TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{Character: 12}}})
+	require.NotNil(t, def)
+	assert.Equal(t, Range{}, def.Range)
+
+	// The column alias is not a macro
+	assert.Nil(t, server.getDefinition(DefinitionParams{TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{Character: 43}}}))
+}
+
+func TestServer_ReindexMacroFile(t *testing.T) {
+	server, macroPath := newMacroServer(t)
+	uri := "file:///models/orders.sql"
+	server.documents.Open(uri, "SELECT {{ finance.tax('amount') }} AS tax FROM orders", 1)
+	params := DefinitionParams{TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{Character: 19}}}
+	assert.Nil(t, server.getHover(HoverParams(params)))
+
+	// Saving the macro file indexes its new function
+	require.NoError(t, os.WriteFile(macroPath, []byte(financeMacros+"\ndef tax(column):\n    return column\n"), 0600))
+	server.reindexMacroFile(macroPath)
+
+	hover := server.getHover(HoverParams(params))
+	require.NotNil(t, hover)
+	assert.Contains(t, hover.Contents.Value, "finance.tax(column)")
+	def := server.getDefinition(params)
+	require.NotNil(t, def)
+	assert.Equal(t, uint32(12), def.Range.Start.Line)
+
+	// Star files outside the macros directory are not indexed
+	other := filepath.Join(filepath.Dir(filepath.Dir(macroPath)), "scripts", "build.star")
+	require.NoError(t, os.MkdirAll(filepath.Dir(other), 0750))
+	require.NoError(t, os.WriteFile(other, []byte("def build():\n    pass\n"), 0600))
+	server.reindexMacroFile(other)
+	ns, _ := server.store.GetMacroNamespace("build")
+	assert.Nil(t, ns)
+}
@@ -0,0 +1,74 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/leapstack-labs/leapsql/internal/server"
+)
+
+// errRequestCancelled is the JSON-RPC error code of requests the client
+// cancelled.
+const errRequestCancelled = -32800
+
+// preview runs a model's query with the daemon, without building the model,
+// and returns its first rows. Cancelling ctx cancels the query.
+func (s *Server) preview(ctx context.Context, daemon *server.Client, params PreviewParams) (*PreviewResult, error) {
+	if params.Limit < 0 {
+		return nil, fmt.Errorf("invalid limit: %d", params.Limit)
+	}
+	limit := params.Limit
+	if limit == 0 {
+		limit = previewRows
+	}
+
+	model := params.Model
+	if model == "" && params.TextDocument != nil {
+		if pctx := s.buildProjectContext(); pctx != nil {
+			models := modelsInFile(pctx, URIToPath(params.TextDocument.URI))
+			slices.Sort(models)
+			if len(models) > 0 {
+				model = models[0]
+			}
+		}
+		if model == "" {
+			return nil, fmt.Errorf("no model in %s", params.TextDocument.URI)
+		}
+	}
+	if model == "" {
+		return nil, errors.New("leapsql/preview takes a model or a document")
+	}
+
+	result, err := daemon.Preview(ctx, model, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &PreviewResult{Model: model, Columns: result.Columns, Rows: result.Rows, Truncated: result.Truncated}, nil
+}
+
+// startRequest registers a request running in the background and returns
+// its context, cancelled when the client cancels the request, and the
+// function to call when it completes.
+func (s *Server) startRequest(id string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.requestsMu.Lock()
+	s.requests[id] = cancel
+	s.requestsMu.Unlock()
+	return ctx, func() {
+		s.requestsMu.Lock()
+		delete(s.requests, id)
+		s.requestsMu.Unlock()
+		cancel()
+	}
+}
+
+// cancelRequest cancels a request running in the background, if any.
+func (s *Server) cancelRequest(id string) {
+	s.requestsMu.Lock()
+	defer s.requestsMu.Unlock()
+	if cancel, ok := s.requests[id]; ok {
+		cancel()
+	}
+}
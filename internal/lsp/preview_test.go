@@ -0,0 +1,56 @@
+package lsp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/internal/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Preview(t *testing.T) {
+	s, uri := newDaemonServer(t)
+	ctx := context.Background()
+
+	// Running the model loads the seed it reads
+	_, err := s.daemon.Run(ctx, server.RunRequest{Select: "staging.customers"})
+	require.NoError(t, err)
+
+	result, err := s.preview(ctx, s.daemon, PreviewParams{Model: "staging.customers"})
+	require.NoError(t, err)
+	assert.Equal(t, &PreviewResult{
+		Model:   "staging.customers",
+		Columns: []string{"id", "name"},
+		Rows:    [][]any{{float64(1), "alice"}, {float64(2), "bob"}},
+	}, result)
+
+	// The model of a document, capped rows
+	result, err = s.preview(ctx, s.daemon, PreviewParams{TextDocument: &TextDocumentIdentifier{URI: uri}, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "staging.customers", result.Model)
+	assert.Len(t, result.Rows, 1)
+	assert.True(t, result.Truncated)
+
+	_, err = s.preview(ctx, s.daemon, PreviewParams{Model: "staging.customers", Limit: -1})
+	assert.ErrorContains(t, err, "invalid limit")
+	_, err = s.preview(ctx, s.daemon, PreviewParams{TextDocument: &TextDocumentIdentifier{URI: "file:///elsewhere.sql"}})
+	assert.ErrorContains(t, err, "no model in")
+	_, err = s.preview(ctx, s.daemon, PreviewParams{})
+	assert.Error(t, err)
+}
+
+func TestServer_CancelRequest(t *testing.T) {
+	s, _ := newDaemonServer(t)
+
+	ctx, done := s.startRequest("7")
+	s.cancelRequest("8")
+	require.NoError(t, ctx.Err())
+	s.cancelRequest("7")
+	require.Error(t, ctx.Err())
+	done()
+	assert.Empty(t, s.requests)
+
+	_, err := s.preview(ctx, s.daemon, PreviewParams{Model: "staging.customers"})
+	assert.Error(t, err, "cancelled previews fail")
+}
@@ -1,6 +1,8 @@
 // Package lsp implements a Language Server Protocol server for LeapSQL.
 package lsp
 
+import "encoding/json"
+
 // LSP Protocol Types
 // Based on LSP specification: https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/
 
@@ -67,8 +69,44 @@ type InitializeParams struct {
 					SnippetSupport bool `json:"snippetSupport"`
 				} `json:"completionItem"`
 			} `json:"completion"`
+			// Diagnostic is set by clients pulling diagnostics (LSP 3.17)
+			Diagnostic *struct {
+				DynamicRegistration bool `json:"dynamicRegistration"`
+			} `json:"diagnostic"`
 		} `json:"textDocument"`
+		Workspace struct {
+			WorkspaceEdit struct {
+				DocumentChanges    bool     `json:"documentChanges"`
+				ResourceOperations []string `json:"resourceOperations"`
+			} `json:"workspaceEdit"`
+			Diagnostics struct {
+				RefreshSupport bool `json:"refreshSupport"`
+			} `json:"diagnostics"`
+			InlayHint struct {
+				RefreshSupport bool `json:"refreshSupport"`
+			} `json:"inlayHint"`
+		} `json:"workspace"`
+		Window struct {
+			ShowDocument struct {
+				Support bool `json:"support"`
+			} `json:"showDocument"`
+		} `json:"window"`
 	} `json:"capabilities"`
+	InitializationOptions *Settings `json:"initializationOptions"`
+}
+
+// Settings is the client configuration of the server, sent as
+// initialization options and in the "leapsql" section of
+// workspace/didChangeConfiguration.
+type Settings struct {
+	InlayHints *InlayHintSettings `json:"inlayHints"`
+}
+
+// InlayHintSettings toggles the kinds of inlay hints. Unset fields leave the
+// kind unchanged; both kinds are shown by default.
+type InlayHintSettings struct {
+	ColumnTypes     *bool `json:"columnTypes"`
+	Materialization *bool `json:"materialization"`
 }
 
 // InitializeResult is the response to initialize request.
@@ -85,6 +123,13 @@ type ServerCapabilities struct {
 	ReferencesProvider         bool                     `json:"referencesProvider,omitempty"`
 	DocumentFormattingProvider bool                     `json:"documentFormattingProvider,omitempty"`
 	CodeActionProvider         *CodeActionOptions       `json:"codeActionProvider,omitempty"`
+	DiagnosticProvider         *DiagnosticOptions       `json:"diagnosticProvider,omitempty"`
+	InlayHintProvider          bool                     `json:"inlayHintProvider,omitempty"`
+	CodeLensProvider           *CodeLensOptions         `json:"codeLensProvider,omitempty"`
+	ExecuteCommandProvider     *ExecuteCommandOptions   `json:"executeCommandProvider,omitempty"`
+	FoldingRangeProvider       bool                     `json:"foldingRangeProvider,omitempty"`
+	SelectionRangeProvider     bool                     `json:"selectionRangeProvider,omitempty"`
+	SignatureHelpProvider      *SignatureHelpOptions    `json:"signatureHelpProvider,omitempty"`
 }
 
 // TextDocumentSyncKind defines how the client syncs document changes.
@@ -136,6 +181,15 @@ type Diagnostic struct {
 	CodeDescription *CodeDescription   `json:"codeDescription,omitempty"`
 	Source          string             `json:"source,omitempty"`
 	Message         string             `json:"message"`
+
+	RelatedInformation []DiagnosticRelatedInformation `json:"relatedInformation,omitempty"`
+}
+
+// DiagnosticRelatedInformation is a secondary location of a diagnostic, such
+// as the other half of a conflict.
+type DiagnosticRelatedInformation struct {
+	Location Location `json:"location"`
+	Message  string   `json:"message"`
 }
 
 // CodeDescription provides a URL with more information about a diagnostic code.
@@ -149,6 +203,80 @@ type PublishDiagnosticsParams struct {
 	Diagnostics []Diagnostic `json:"diagnostics"`
 }
 
+// DiagnosticOptions are options for the pull diagnostics provider.
+type DiagnosticOptions struct {
+	// InterFileDependencies is set when a change to one file may change the
+	// diagnostics of others, as project rules do
+	InterFileDependencies bool `json:"interFileDependencies"`
+	WorkspaceDiagnostics  bool `json:"workspaceDiagnostics"`
+}
+
+// DocumentDiagnosticParams are the parameters of a textDocument/diagnostic
+// request.
+type DocumentDiagnosticParams struct {
+	TextDocument     TextDocumentIdentifier `json:"textDocument"`
+	PreviousResultID string                 `json:"previousResultId,omitempty"`
+}
+
+// DocumentDiagnosticReportKind tells full diagnostic reports from reports
+// that the diagnostics did not change.
+type DocumentDiagnosticReportKind string
+
+// DocumentDiagnosticReportKind constants.
+const (
+	DocumentDiagnosticReportKindFull      DocumentDiagnosticReportKind = "full"
+	DocumentDiagnosticReportKindUnchanged DocumentDiagnosticReportKind = "unchanged"
+)
+
+// FullDocumentDiagnosticReport lists all diagnostics of a document.
+type FullDocumentDiagnosticReport struct {
+	Kind     DocumentDiagnosticReportKind `json:"kind"`
+	ResultID string                       `json:"resultId,omitempty"`
+	Items    []Diagnostic                 `json:"items"`
+}
+
+// UnchangedDocumentDiagnosticReport reports that the diagnostics of a
+// document are those of the client's previous result.
+type UnchangedDocumentDiagnosticReport struct {
+	Kind     DocumentDiagnosticReportKind `json:"kind"`
+	ResultID string                       `json:"resultId"`
+}
+
+// PreviousResultID is the result ID a client holds for a document.
+type PreviousResultID struct {
+	URI   string `json:"uri"`
+	Value string `json:"value"`
+}
+
+// WorkspaceDiagnosticParams are the parameters of a workspace/diagnostic
+// request.
+type WorkspaceDiagnosticParams struct {
+	PreviousResultIDs []PreviousResultID `json:"previousResultIds"`
+}
+
+// WorkspaceFullDocumentDiagnosticReport lists all diagnostics of a document
+// of the workspace. Version is nil for documents not open in the client.
+type WorkspaceFullDocumentDiagnosticReport struct {
+	FullDocumentDiagnosticReport
+	URI     string `json:"uri"`
+	Version *int   `json:"version"`
+}
+
+// WorkspaceUnchangedDocumentDiagnosticReport reports that the diagnostics of
+// a document of the workspace did not change.
+type WorkspaceUnchangedDocumentDiagnosticReport struct {
+	UnchangedDocumentDiagnosticReport
+	URI     string `json:"uri"`
+	Version *int   `json:"version"`
+}
+
+// WorkspaceDiagnosticReport is the response to a workspace/diagnostic
+// request. Items are WorkspaceFullDocumentDiagnosticReport or
+// WorkspaceUnchangedDocumentDiagnosticReport values.
+type WorkspaceDiagnosticReport struct {
+	Items []any `json:"items"`
+}
+
 // --- Completion ---
 
 // CompletionParams for completion requests.
@@ -291,6 +419,13 @@ type DidSaveTextDocumentParams struct {
 	Text         string                 `json:"text,omitempty"`
 }
 
+// DidChangeConfigurationParams for workspace/didChangeConfiguration notification.
+type DidChangeConfigurationParams struct {
+	Settings struct {
+		LeapSQL *Settings `json:"leapsql"`
+	} `json:"settings"`
+}
+
 // --- Messages ---
 
 // ShowMessageParams for window/showMessage notification.
@@ -354,6 +489,29 @@ type CodeAction struct {
 // WorkspaceEdit represents changes to many resources managed in the workspace.
 type WorkspaceEdit struct {
 	Changes map[string][]TextEdit `json:"changes,omitempty"`
+	// DocumentChanges are CreateFile and TextDocumentEdit operations,
+	// applied in order, for clients supporting them
+	DocumentChanges []any `json:"documentChanges,omitempty"`
+}
+
+// CreateFile is a workspace edit operation creating a file.
+type CreateFile struct {
+	Kind string `json:"kind"` // Always "create"
+	URI  string `json:"uri"`
+}
+
+// TextDocumentEdit is a workspace edit operation editing a version of a
+// document.
+type TextDocumentEdit struct {
+	TextDocument OptionalVersionedTextDocumentIdentifier `json:"textDocument"`
+	Edits        []TextEdit                              `json:"edits"`
+}
+
+// OptionalVersionedTextDocumentIdentifier identifies a version of a text
+// document, or any version when Version is nil, e.g. for created files.
+type OptionalVersionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version *int   `json:"version"`
 }
 
 // Command represents a reference to a command.
@@ -367,3 +525,176 @@ type Command struct {
 type CodeActionOptions struct {
 	CodeActionKinds []CodeActionKind `json:"codeActionKinds,omitempty"`
 }
+
+// --- Inlay Hints ---
+
+// InlayHintParams is the request parameters for textDocument/inlayHint.
+type InlayHintParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+}
+
+// InlayHintKind defines the kind of an inlay hint.
+type InlayHintKind int
+
+// InlayHintKind constants.
+const (
+	InlayHintKindType      InlayHintKind = 1
+	InlayHintKindParameter InlayHintKind = 2
+)
+
+// InlayHint is an inline annotation rendered in the editor.
+type InlayHint struct {
+	Position     Position      `json:"position"`
+	Label        string        `json:"label"`
+	Kind         InlayHintKind `json:"kind,omitempty"`
+	Tooltip      string        `json:"tooltip,omitempty"`
+	PaddingLeft  bool          `json:"paddingLeft,omitempty"`
+	PaddingRight bool          `json:"paddingRight,omitempty"`
+}
+
+// --- Signature Help ---
+
+// SignatureHelpOptions are options for the signature help provider.
+type SignatureHelpOptions struct {
+	TriggerCharacters   []string `json:"triggerCharacters,omitempty"`
+	RetriggerCharacters []string `json:"retriggerCharacters,omitempty"`
+}
+
+// SignatureHelpParams is the request parameters for textDocument/signatureHelp.
+type SignatureHelpParams struct {
+	TextDocumentPositionParams
+}
+
+// SignatureHelp describes the signature of the function called at a
+// position, and the argument being written.
+type SignatureHelp struct {
+	Signatures      []SignatureInformation `json:"signatures"`
+	ActiveSignature uint32                 `json:"activeSignature"`
+	ActiveParameter uint32                 `json:"activeParameter"`
+}
+
+// SignatureInformation is the signature of a callable.
+type SignatureInformation struct {
+	Label         string                 `json:"label"`
+	Documentation *MarkupContent         `json:"documentation,omitempty"`
+	Parameters    []ParameterInformation `json:"parameters,omitempty"`
+}
+
+// ParameterInformation is a parameter of a signature. Its label is a
+// substring of the signature's label.
+type ParameterInformation struct {
+	Label string `json:"label"`
+}
+
+// --- Folding and Selection Ranges ---
+
+// FoldingRangeParams is the request parameters for textDocument/foldingRange.
+type FoldingRangeParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// FoldingRangeKind classifies a folding range.
+type FoldingRangeKind string
+
+// FoldingRangeKind constants.
+const (
+	FoldingRangeKindComment FoldingRangeKind = "comment"
+	FoldingRangeKindRegion  FoldingRangeKind = "region"
+)
+
+// FoldingRange is a range of lines the editor can fold. Folding a range
+// keeps its first line visible.
+type FoldingRange struct {
+	StartLine uint32           `json:"startLine"`
+	EndLine   uint32           `json:"endLine"`
+	Kind      FoldingRangeKind `json:"kind,omitempty"`
+}
+
+// SelectionRangeParams is the request parameters for
+// textDocument/selectionRange.
+type SelectionRangeParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Positions    []Position             `json:"positions"`
+}
+
+// SelectionRange is a range to select, contained in its parent: expanding the
+// selection moves to the parent.
+type SelectionRange struct {
+	Range  Range           `json:"range"`
+	Parent *SelectionRange `json:"parent,omitempty"`
+}
+
+// --- Code Lenses and Commands ---
+
+// CodeLensOptions are options for the code lens provider.
+type CodeLensOptions struct {
+	ResolveProvider bool `json:"resolveProvider,omitempty"`
+}
+
+// CodeLensParams is the request parameters for textDocument/codeLens.
+type CodeLensParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+}
+
+// CodeLens is a command shown inline with the source.
+type CodeLens struct {
+	Range   Range    `json:"range"`
+	Command *Command `json:"command,omitempty"`
+}
+
+// ExecuteCommandOptions lists the commands the server executes.
+type ExecuteCommandOptions struct {
+	Commands []string `json:"commands"`
+}
+
+// ExecuteCommandParams is the request parameters for workspace/executeCommand.
+type ExecuteCommandParams struct {
+	Command   string            `json:"command"`
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+}
+
+// ShowDocumentParams is the request parameters for window/showDocument.
+type ShowDocumentParams struct {
+	URI       string `json:"uri"`
+	TakeFocus bool   `json:"takeFocus,omitempty"`
+}
+
+// VirtualDocumentParams is the request parameters for leapsql/virtualDocument.
+type VirtualDocumentParams struct {
+	URI string `json:"uri"`
+}
+
+// VirtualDocument is a read-only document the server generates, such as the
+// results of a command. Clients open it by URI and read its content with a
+// leapsql/virtualDocument request, again when the server sends a
+// leapsql/virtualDocumentChanged notification with its URI.
+type VirtualDocument struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Content    string `json:"content"`
+}
+
+// PreviewParams is the request parameters for leapsql/preview. The model
+// is given by path, or as the model of a document.
+type PreviewParams struct {
+	Model        string                  `json:"model,omitempty"`
+	TextDocument *TextDocumentIdentifier `json:"textDocument,omitempty"`
+	// Limit is the number of rows returned (100 if 0, capped by the daemon)
+	Limit int `json:"limit,omitempty"`
+}
+
+// PreviewResult is the rows of a model preview, for clients to render as a
+// table.
+type PreviewResult struct {
+	Model   string   `json:"model"`
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+	// Truncated tells that the model's query has more rows than the limit
+	Truncated bool `json:"truncated"`
+}
+
+// CancelParams is the notification parameters for $/cancelRequest.
+type CancelParams struct {
+	ID json.RawMessage `json:"id"`
+}
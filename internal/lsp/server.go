@@ -2,6 +2,7 @@ package lsp
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,12 +10,15 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/leapstack-labs/leapsql/internal/config"
 	"github.com/leapstack-labs/leapsql/internal/provider"
+	"github.com/leapstack-labs/leapsql/internal/server"
 	"github.com/leapstack-labs/leapsql/internal/state"
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/dialect"
@@ -23,7 +27,10 @@ import (
 	_ "github.com/leapstack-labs/leapsql/pkg/lint/project/rules" // Register project rules
 
 	// Import dialect implementations so they register themselves
+	_ "github.com/leapstack-labs/leapsql/pkg/dialects/databricks"
 	_ "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	_ "github.com/leapstack-labs/leapsql/pkg/dialects/postgres"
+	_ "github.com/leapstack-labs/leapsql/pkg/dialects/snowflake"
 )
 
 // Server implements the Language Server Protocol for LeapSQL.
@@ -41,6 +48,10 @@ type Server struct {
 	// State store (may be nil if discover not run)
 	store core.Store
 
+	// Daemon serving the project, if one was running at initialization
+	// (may be nil)
+	daemon *server.Client
+
 	// Memory caches for fast lookups
 	macroNamespaceCache map[string]bool
 	modelNameCache      map[string]bool
@@ -54,10 +65,41 @@ type Server struct {
 	projectAnalyzer *project.Analyzer
 	projectConfig   lint.ProjectHealthConfig
 
+	// Diagnostics mode: clients supporting LSP 3.17 pull diagnostics and get
+	// none pushed; those supporting refresh are asked to pull again when
+	// project rule results may have changed
+	pullDiagnostics   bool
+	diagnosticRefresh bool
+
+	// Inlay hints shown, as configured by the client; clients supporting
+	// refresh are asked for hints again when the configuration changes
+	columnTypeHints      bool
+	materializationHints bool
+	inlayHintRefresh     bool
+
+	// Schema of the project's target, where models outside a schema are built
+	targetSchema string
+
+	// Results of the commands code lenses run, by virtual document URI;
+	// clients supporting showDocument are asked to open them
+	virtualDocs   map[string]string
+	virtualDocsMu sync.RWMutex
+	showDocument  bool
+
+	// Whether the client applies workspace edits creating files, which
+	// extracting a CTE into a model needs
+	createFiles bool
+
+	// Cancel functions of the requests running in the background, by ID,
+	// called when the client cancels them
+	requests   map[string]context.CancelFunc
+	requestsMu sync.Mutex
+
 	// I/O
-	reader  *bufio.Reader
-	writer  io.Writer
-	writeMu sync.Mutex
+	reader        *bufio.Reader
+	writer        io.Writer
+	writeMu       sync.Mutex
+	nextRequestID atomic.Int64 // ID of the next request sent to the client
 
 	// Logging
 	logger *slog.Logger
@@ -78,14 +120,18 @@ func NewServerWithLogger(reader io.Reader, writer io.Writer, logger *slog.Logger
 		logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
 	}
 	return &Server{
-		documents:           NewDocumentStore(),
-		reader:              bufio.NewReader(reader),
-		writer:              writer,
-		logger:              logger,
-		macroNamespaceCache: make(map[string]bool),
-		modelNameCache:      make(map[string]bool),
-		projectAnalyzer:     project.NewAnalyzer(nil),
-		projectConfig:       lint.DefaultProjectHealthConfig(),
+		documents:            NewDocumentStore(),
+		reader:               bufio.NewReader(reader),
+		writer:               writer,
+		logger:               logger,
+		macroNamespaceCache:  make(map[string]bool),
+		modelNameCache:       make(map[string]bool),
+		projectAnalyzer:      project.NewAnalyzer(nil),
+		projectConfig:        lint.DefaultProjectHealthConfig(),
+		columnTypeHints:      true,
+		materializationHints: true,
+		virtualDocs:          make(map[string]string),
+		requests:             make(map[string]context.CancelFunc),
 	}
 }
 
@@ -197,6 +243,24 @@ func (s *Server) sendResponse(id *json.RawMessage, result any, err *JSONRPCError
 	s.writeMessage(&msg)
 }
 
+// sendRequest sends a JSON-RPC request to the client. Its response is
+// ignored.
+func (s *Server) sendRequest(method string, params any) {
+	id := json.RawMessage(strconv.FormatInt(s.nextRequestID.Add(1), 10))
+	msg := JSONRPCMessage{
+		JSONRPC: "2.0",
+		ID:      &id,
+		Method:  method,
+	}
+
+	if params != nil {
+		paramsBytes, _ := json.Marshal(params)
+		msg.Params = paramsBytes
+	}
+
+	s.writeMessage(&msg)
+}
+
 // sendNotification sends a JSON-RPC notification (no ID).
 func (s *Server) sendNotification(method string, params any) {
 	msg := JSONRPCMessage{
@@ -230,6 +294,11 @@ func (s *Server) writeMessage(msg *JSONRPCMessage) {
 
 // handleMessage dispatches a message to the appropriate handler.
 func (s *Server) handleMessage(msg *JSONRPCMessage) error {
+	// Responses to the server's requests have no method
+	if msg.Method == "" {
+		return nil
+	}
+
 	s.logger.Info("Received", "method", msg.Method)
 
 	switch msg.Method {
@@ -257,6 +326,30 @@ func (s *Server) handleMessage(msg *JSONRPCMessage) error {
 		return s.handleDefinition(msg)
 	case "textDocument/codeAction":
 		return s.handleCodeAction(msg)
+	case "textDocument/diagnostic":
+		return s.handleDocumentDiagnostic(msg)
+	case "workspace/diagnostic":
+		return s.handleWorkspaceDiagnostic(msg)
+	case "textDocument/inlayHint":
+		return s.handleInlayHint(msg)
+	case "workspace/didChangeConfiguration":
+		return s.handleDidChangeConfiguration(msg)
+	case "textDocument/codeLens":
+		return s.handleCodeLens(msg)
+	case "workspace/executeCommand":
+		return s.handleExecuteCommand(msg)
+	case "leapsql/virtualDocument":
+		return s.handleVirtualDocument(msg)
+	case "leapsql/preview":
+		return s.handlePreview(msg)
+	case "$/cancelRequest":
+		return s.handleCancelRequest(msg)
+	case "textDocument/foldingRange":
+		return s.handleFoldingRange(msg)
+	case "textDocument/selectionRange":
+		return s.handleSelectionRange(msg)
+	case "textDocument/signatureHelp":
+		return s.handleSignatureHelp(msg)
 	default:
 		if msg.ID != nil {
 			// Unknown method with ID - respond with method not found
@@ -281,8 +374,22 @@ func (s *Server) handleInitialize(msg *JSONRPCMessage) error {
 	s.projectRoot = URIToPath(params.RootURI)
 	s.logger.Info("Project root", "path", s.projectRoot)
 
-	// Try to open SQLite database
+	s.pullDiagnostics = params.Capabilities.TextDocument.Diagnostic != nil
+	s.diagnosticRefresh = params.Capabilities.Workspace.Diagnostics.RefreshSupport
+	s.inlayHintRefresh = params.Capabilities.Workspace.InlayHint.RefreshSupport
+	s.showDocument = params.Capabilities.Window.ShowDocument.Support
+	s.createFiles = params.Capabilities.Workspace.WorkspaceEdit.DocumentChanges &&
+		slices.Contains(params.Capabilities.Workspace.WorkspaceEdit.ResourceOperations, "create")
+	s.applySettings(params.InitializationOptions)
+
+	// Connect to the project's daemon, which keeps the models discovered
 	dbPath := filepath.Join(s.projectRoot, ".leapsql", "state.db")
+	if client, err := server.Dial(context.Background(), server.SocketPath(dbPath)); err == nil {
+		s.daemon = client
+		s.logger.Info("Connected to daemon", "socket", server.SocketPath(dbPath))
+	}
+
+	// Try to open SQLite database
 	store := state.NewSQLiteStore(s.logger)
 	if err := store.Open(dbPath); err != nil {
 		s.logger.Info("SQLite database not found", "path", dbPath, "error", err)
@@ -292,8 +399,9 @@ func (s *Server) handleInitialize(msg *JSONRPCMessage) error {
 		s.loadCaches()
 	}
 
-	// Load dialect from project config
+	// Load dialect and model groups from project config
 	s.loadDialectFromConfig()
+	s.loadGroupsFromConfig()
 
 	// Initialize the shared provider for parsing and context
 	s.provider = provider.New(s.store, s.dialect, s.projectConfig, s.logger)
@@ -313,7 +421,22 @@ func (s *Server) handleInitialize(msg *JSONRPCMessage) error {
 			HoverProvider:      true,
 			DefinitionProvider: true,
 			CodeActionProvider: &CodeActionOptions{
-				CodeActionKinds: []CodeActionKind{CodeActionKindQuickFix},
+				CodeActionKinds: []CodeActionKind{CodeActionKindQuickFix, CodeActionKindRefactorExtract},
+			},
+			DiagnosticProvider: &DiagnosticOptions{
+				InterFileDependencies: true,
+				WorkspaceDiagnostics:  true,
+			},
+			InlayHintProvider: true,
+			CodeLensProvider:  &CodeLensOptions{},
+			ExecuteCommandProvider: &ExecuteCommandOptions{
+				Commands: commands,
+			},
+			FoldingRangeProvider:   true,
+			SelectionRangeProvider: true,
+			SignatureHelpProvider: &SignatureHelpOptions{
+				TriggerCharacters:   []string{"(", ","},
+				RetriggerCharacters: []string{")"},
 			},
 		},
 	}
@@ -334,7 +457,7 @@ func (s *Server) handleInitialized(_ *JSONRPCMessage) error {
 		})
 	} else {
 		// Run project health diagnostics on startup if store is available
-		s.publishProjectHealthDiagnostics()
+		s.publishProjectHealthDiagnostics("")
 	}
 
 	// Show info if using default ANSI dialect
@@ -356,6 +479,9 @@ func (s *Server) handleShutdown(msg *JSONRPCMessage) error {
 	if s.store != nil {
 		_ = s.store.Close()
 	}
+	if s.daemon != nil {
+		s.daemon.Close()
+	}
 
 	s.sendResponse(msg.ID, nil, nil)
 	s.logger.Info("Server shutdown")
@@ -394,7 +520,10 @@ func (s *Server) handleDidClose(msg *JSONRPCMessage) error {
 	s.documents.Close(params.TextDocument.URI)
 	s.logger.Info("Closed", "uri", params.TextDocument.URI)
 
-	// Clear diagnostics
+	// Clear diagnostics; clients pulling them manage their own
+	if s.pullDiagnostics {
+		return nil
+	}
 	s.sendNotification("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
 		URI:         params.TextDocument.URI,
 		Diagnostics: []Diagnostic{},
@@ -436,9 +565,19 @@ func (s *Server) handleDidSave(msg *JSONRPCMessage) error {
 	}
 
 	// If it's a .sql file, re-run project health diagnostics
-	// Project health rules may be affected by model changes
+	// Project health rules may be affected by model changes. The daemon
+	// rediscovers the saved model into the state database they read.
 	if strings.HasSuffix(path, ".sql") && s.store != nil {
-		s.publishProjectHealthDiagnostics()
+		if s.daemon != nil {
+			s.loadCaches()
+			s.provider.InvalidateProjectContext()
+		}
+		s.publishProjectHealthDiagnostics(path)
+	}
+
+	// Open compiled SQL follows saved models and macros
+	if s.daemon != nil && (strings.HasSuffix(path, ".sql") || strings.HasSuffix(path, ".star")) {
+		go s.refreshCompiledDocuments(context.Background(), s.daemon)
 	}
 
 	return nil
@@ -482,8 +621,164 @@ func (s *Server) handleDefinition(msg *JSONRPCMessage) error {
 	return nil
 }
 
+func (s *Server) handleDocumentDiagnostic(msg *JSONRPCMessage) error {
+	var params DocumentDiagnosticParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32602, Message: err.Error()})
+		return err
+	}
+
+	s.sendResponse(msg.ID, s.getDocumentDiagnostics(params), nil)
+	return nil
+}
+
+func (s *Server) handleWorkspaceDiagnostic(msg *JSONRPCMessage) error {
+	var params WorkspaceDiagnosticParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32602, Message: err.Error()})
+		return err
+	}
+
+	s.sendResponse(msg.ID, s.getWorkspaceDiagnostics(params), nil)
+	return nil
+}
+
+func (s *Server) handleInlayHint(msg *JSONRPCMessage) error {
+	var params InlayHintParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32602, Message: err.Error()})
+		return err
+	}
+
+	s.sendResponse(msg.ID, s.getInlayHints(params), nil)
+	return nil
+}
+
+func (s *Server) handleDidChangeConfiguration(msg *JSONRPCMessage) error {
+	var params DidChangeConfigurationParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return err
+	}
+
+	s.applySettings(params.Settings.LeapSQL)
+	if s.inlayHintRefresh {
+		s.sendRequest("workspace/inlayHint/refresh", nil)
+	}
+	return nil
+}
+
+func (s *Server) handleCodeLens(msg *JSONRPCMessage) error {
+	var params CodeLensParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32602, Message: err.Error()})
+		return err
+	}
+
+	s.sendResponse(msg.ID, s.getCodeLenses(params), nil)
+	return nil
+}
+
+// handleExecuteCommand executes a command with the daemon in the background,
+// as runs may take long, and responds with the URI of the virtual document
+// holding its result.
+func (s *Server) handleExecuteCommand(msg *JSONRPCMessage) error {
+	var params ExecuteCommandParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32602, Message: err.Error()})
+		return err
+	}
+
+	daemon, err := s.connectDaemon()
+	if err != nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32603, Message: err.Error()})
+		return nil
+	}
+	go func() {
+		uri, err := s.executeCommand(context.Background(), daemon, params)
+		if err != nil {
+			s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32603, Message: err.Error()})
+			return
+		}
+		if s.showDocument {
+			s.sendRequest("window/showDocument", &ShowDocumentParams{URI: uri, TakeFocus: true})
+		}
+		s.sendResponse(msg.ID, &VirtualDocumentParams{URI: uri}, nil)
+	}()
+	return nil
+}
+
+func (s *Server) handleVirtualDocument(msg *JSONRPCMessage) error {
+	var params VirtualDocumentParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32602, Message: err.Error()})
+		return err
+	}
+
+	doc := s.getVirtualDocument(params)
+	if doc == nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32602, Message: "unknown virtual document: " + params.URI})
+		return nil
+	}
+	s.sendResponse(msg.ID, doc, nil)
+	return nil
+}
+
+func (s *Server) handlePreview(msg *JSONRPCMessage) error {
+	if msg.ID == nil {
+		return nil
+	}
+	var params PreviewParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32602, Message: err.Error()})
+		return err
+	}
+
+	daemon, err := s.connectDaemon()
+	if err != nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32603, Message: err.Error()})
+		return nil
+	}
+	ctx, done := s.startRequest(string(*msg.ID))
+	go func() {
+		defer done()
+		result, err := s.preview(ctx, daemon, params)
+		switch {
+		case ctx.Err() != nil:
+			s.sendResponse(msg.ID, nil, &JSONRPCError{Code: errRequestCancelled, Message: "preview cancelled"})
+		case err != nil:
+			s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32603, Message: err.Error()})
+		default:
+			s.sendResponse(msg.ID, result, nil)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) handleCancelRequest(msg *JSONRPCMessage) error {
+	var params CancelParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return err
+	}
+	s.cancelRequest(string(params.ID))
+	return nil
+}
+
 // --- Helper methods ---
 
+// applySettings applies the client configuration. Nil settings, or unset
+// fields, leave the current configuration unchanged.
+func (s *Server) applySettings(settings *Settings) {
+	if settings == nil || settings.InlayHints == nil {
+		return
+	}
+	if v := settings.InlayHints.ColumnTypes; v != nil {
+		s.columnTypeHints = *v
+	}
+	if v := settings.InlayHints.Materialization; v != nil {
+		s.materializationHints = *v
+	}
+}
+
 // loadCaches loads macro and model names into memory for fast lookups.
 func (s *Server) loadCaches() {
 	s.cacheMu.Lock()
@@ -496,38 +791,66 @@ func (s *Server) loadCaches() {
 		s.macroNamespaceCache[ns.Name] = true
 	}
 
-	// Load model names
-	models, _ := s.store.ListModels()
+	// Load model names, from the daemon if one serves the project: it
+	// rediscovers models, so models not yet recorded in the state database
+	// are included
 	s.modelNameCache = make(map[string]bool)
-	for _, m := range models {
-		s.modelNameCache[m.Name] = true
-		s.modelNameCache[m.Path] = true
+	if catalog, ok := s.daemonCatalog(); ok {
+		for _, m := range catalog {
+			s.modelNameCache[m.Name] = true
+			s.modelNameCache[m.Path] = true
+		}
+	} else {
+		models, _ := s.store.ListModels()
+		for _, m := range models {
+			s.modelNameCache[m.Name] = true
+			s.modelNameCache[m.Path] = true
+		}
 	}
 
 	s.logger.Info("Loaded caches", "macro_namespaces", len(s.macroNamespaceCache), "model_refs", len(s.modelNameCache))
 }
 
-// reindexMacroFile re-parses and stores a macro file.
-func (s *Server) reindexMacroFile(path string) {
-	// This would call macro.ParseStarlarkFile and store in SQLite
-	// For now, just log
-	s.logger.Info("TODO: Re-index macro file", "path", path)
+// daemonCatalog returns the models of the daemon's catalog, or false if no
+// daemon serves the project or it failed to answer.
+func (s *Server) daemonCatalog() ([]server.CatalogModel, bool) {
+	if s.daemon == nil {
+		return nil, false
+	}
+	catalog, err := s.daemon.Catalog(context.Background())
+	if err != nil {
+		s.logger.Warn("Daemon catalog unavailable, reading the state database", "error", err)
+		return nil, false
+	}
+	return catalog, true
 }
 
-// loadDialectFromConfig loads the dialect from the project's leapsql.yaml config.
-// Defaults to ANSI if no config or target is specified.
+// loadDialectFromConfig loads the dialect models are written in from the
+// project's leapsql.yaml config: its dialect, or its target's type, along
+// with the target's schema. Defaults to ANSI if no config or target is
+// specified.
 func (s *Server) loadDialectFromConfig() {
 	// Try to load from config
 	if s.projectRoot != "" {
 		cfg, err := config.LoadFromDir(s.projectRoot)
-		if err == nil && cfg != nil && cfg.Target != nil && cfg.Target.Type != "" {
-			if d, ok := dialect.Get(cfg.Target.Type); ok {
+		name := ""
+		if err == nil && cfg != nil {
+			name = cfg.Dialect
+			if cfg.Target != nil {
+				if name == "" {
+					name = cfg.Target.Type
+				}
+				s.targetSchema = cfg.Target.Schema
+			}
+		}
+		if name != "" {
+			if d, ok := dialect.Get(name); ok {
 				s.dialect = d
 				s.dialectFromConfig = true
-				s.logger.Info("Loaded dialect from project config", "dialect", cfg.Target.Type)
+				s.logger.Info("Loaded dialect from project config", "dialect", name)
 				return
 			}
-			s.logger.Warn("Unknown dialect type in project config", "type", cfg.Target.Type)
+			s.logger.Warn("Unknown dialect type in project config", "type", name)
 		}
 	}
 
@@ -537,6 +860,24 @@ func (s *Server) loadDialectFromConfig() {
 	s.logger.Info("No target configured, defaulting to DuckDB dialect")
 }
 
+// loadGroupsFromConfig loads the model groups declared in the project's
+// leapsql.yaml so ownership rules (PM10, PM11) see the same groups as the CLI,
+// along with the models approved to expose PII (PL06) and the model layers.
+func (s *Server) loadGroupsFromConfig() {
+	if s.projectRoot == "" {
+		return
+	}
+	cfg, err := config.LoadFromDir(s.projectRoot)
+	if err != nil || cfg == nil {
+		return
+	}
+	s.projectConfig.Groups = lint.GroupsByName(cfg.Groups)
+	s.projectConfig.Layers = cfg.Layers
+	if cfg.Lint != nil && cfg.Lint.ProjectHealth != nil {
+		s.projectConfig.PIIApproved = cfg.Lint.ProjectHealth.PIIApproved
+	}
+}
+
 // buildProjectContext delegates to the provider for project context.
 // Falls back to building directly from store if provider is not available.
 func (s *Server) buildProjectContext() *project.Context {
@@ -546,3 +887,36 @@ func (s *Server) buildProjectContext() *project.Context {
 	// Fallback: should not happen after initialization
 	return nil
 }
+
+func (s *Server) handleFoldingRange(msg *JSONRPCMessage) error {
+	var params FoldingRangeParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32602, Message: err.Error()})
+		return err
+	}
+
+	s.sendResponse(msg.ID, s.getFoldingRanges(params), nil)
+	return nil
+}
+
+func (s *Server) handleSelectionRange(msg *JSONRPCMessage) error {
+	var params SelectionRangeParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32602, Message: err.Error()})
+		return err
+	}
+
+	s.sendResponse(msg.ID, s.getSelectionRanges(params), nil)
+	return nil
+}
+
+func (s *Server) handleSignatureHelp(msg *JSONRPCMessage) error {
+	var params SignatureHelpParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		s.sendResponse(msg.ID, nil, &JSONRPCError{Code: -32602, Message: err.Error()})
+		return err
+	}
+
+	s.sendResponse(msg.ID, s.getSignatureHelp(params), nil)
+	return nil
+}
@@ -0,0 +1,333 @@
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/internal/macro"
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// Macro files are Starlark modules loaded without predeclared globals: they
+// see Starlark's builtins and their own definitions, but not the template
+// globals (config, env, target, this, ref), which exist only when templates
+// are rendered.
+
+// isStarlarkURI reports whether a document is a Starlark macro file.
+func isStarlarkURI(uri string) bool {
+	return strings.HasSuffix(uri, ".star")
+}
+
+// starlarkBuiltin documents a builtin of Starlark's universe.
+type starlarkBuiltin struct {
+	signature string
+	doc       string
+}
+
+// starlarkBuiltins documents the builtins of Starlark's universe by name.
+var starlarkBuiltins = map[string]starlarkBuiltin{
+	"abs":       {"abs(x)", "Absolute value of a number."},
+	"all":       {"all(x)", "Whether every element of an iterable is true."},
+	"any":       {"any(x)", "Whether some element of an iterable is true."},
+	"bool":      {"bool(x=False)", "Truth value of x."},
+	"bytes":     {"bytes(x)", "Bytes of a string, or of an iterable of ints."},
+	"chr":       {"chr(i)", "String of the Unicode code point i."},
+	"dict":      {"dict(pairs=[], **kwargs)", "New dictionary of key/value pairs and keyword arguments."},
+	"dir":       {"dir(x)", "Sorted names of the attributes of x."},
+	"enumerate": {"enumerate(x, start=0)", "List of (index, element) pairs of an iterable."},
+	"fail":      {"fail(*args, sep=\" \")", "Stops execution with an error; the macro call fails with the arguments as message."},
+	"float":     {"float(x)", "Floating-point number of a number or string."},
+	"getattr":   {"getattr(x, name, default)", "Attribute name of x, or default if x has none."},
+	"hasattr":   {"hasattr(x, name)", "Whether x has the attribute name."},
+	"hash":      {"hash(x)", "Hash of a string or bytes."},
+	"int":       {"int(x, base=10)", "Integer of a number or string."},
+	"len":       {"len(x)", "Number of elements of a string, list, tuple or dict."},
+	"list":      {"list(x=[])", "New list of the elements of an iterable."},
+	"max":       {"max(*args, key=None)", "Greatest of the arguments, or of the elements of a single iterable."},
+	"min":       {"min(*args, key=None)", "Least of the arguments, or of the elements of a single iterable."},
+	"ord":       {"ord(s)", "Unicode code point of a one-character string."},
+	"print":     {"print(*args, sep=\" \")", "Prints the arguments."},
+	"range":     {"range(start, stop, step=1)", "Sequence of integers from start to stop; range(n) counts from 0 to n."},
+	"repr":      {"repr(x)", "String representation of x, as Starlark source."},
+	"reversed":  {"reversed(x)", "New list of the elements of an iterable, in reverse order."},
+	"set":       {"set(x=[])", "New set of the elements of an iterable."},
+	"sorted":    {"sorted(x, key=None, reverse=False)", "New sorted list of the elements of an iterable."},
+	"str":       {"str(x)", "String of x."},
+	"tuple":     {"tuple(x=())", "New tuple of the elements of an iterable."},
+	"type":      {"type(x)", "Name of the type of x."},
+	"zip":       {"zip(*args)", "List of tuples of the elements of the iterables at the same index."},
+}
+
+// starlarkKeywords are the keywords completed in macro files. load is left
+// out: macro files cannot load other modules.
+var starlarkKeywords = []string{
+	"and", "break", "continue", "def", "elif", "else", "for", "if", "in",
+	"lambda", "not", "or", "pass", "return",
+}
+
+// identifierPattern matches names valid as macro namespaces.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// starlarkDiagnostics returns the errors the macro loader reports for a
+// macro file: syntax errors, names that do not resolve, such as template
+// globals used in macros, and file names that are not valid namespaces.
+func starlarkDiagnostics(doc *Document) []Diagnostic {
+	var diagnostics []Diagnostic
+	namespace := strings.TrimSuffix(filepath.Base(URIToPath(doc.URI)), ".star")
+	switch {
+	case !identifierPattern.MatchString(namespace):
+		diagnostics = append(diagnostics, starlarkDiagnostic(doc, syntax.Position{}, fmt.Sprintf("%q is not a valid macro namespace: name the file like an identifier", namespace)))
+	case slices.Contains(macro.ReservedNamespaces, namespace):
+		diagnostics = append(diagnostics, starlarkDiagnostic(doc, syntax.Position{}, fmt.Sprintf("%q is a reserved namespace: rename the file", namespace)))
+	}
+
+	f, err := syntax.Parse(URIToPath(doc.URI), doc.Content, 0) //nolint:staticcheck // SA1019: the macro loader parses with the legacy options
+	if err != nil {
+		var syntaxErr syntax.Error
+		if errors.As(err, &syntaxErr) {
+			return append(diagnostics, starlarkDiagnostic(doc, syntaxErr.Pos, syntaxErr.Msg))
+		}
+		return append(diagnostics, starlarkDiagnostic(doc, syntax.Position{}, err.Error()))
+	}
+
+	var resolveErrs resolve.ErrorList
+	if errors.As(resolve.File(f, func(string) bool { return false }, starlark.Universe.Has), &resolveErrs) {
+		for _, e := range resolveErrs {
+			msg := e.Msg
+			if name, ok := strings.CutPrefix(msg, "undefined: "); ok && isTemplateGlobal(name) {
+				msg += " (template globals are not available in macro files: pass the value as an argument)"
+			}
+			diagnostics = append(diagnostics, starlarkDiagnostic(doc, e.Pos, msg))
+		}
+	}
+	return diagnostics
+}
+
+// starlarkDiagnostic returns an error at a position of a macro file,
+// covering the word there.
+func starlarkDiagnostic(doc *Document, pos syntax.Position, msg string) Diagnostic {
+	start := Position{}
+	if pos.IsValid() {
+		start = Position{Line: uint32(pos.Line - 1), Character: uint32(pos.Col - 1)} //nolint:gosec // G115: positions are 1-based
+	}
+	rng := Range{Start: start, End: start}
+	if word, wordRange := doc.GetWordAtPosition(start); word != "" && wordRange.Start == start {
+		rng = wordRange
+	}
+	return Diagnostic{
+		Range:    rng,
+		Severity: DiagnosticSeverityError,
+		Source:   "leapsql",
+		Message:  msg,
+	}
+}
+
+// isTemplateGlobal reports whether a name is a global of templates.
+func isTemplateGlobal(name string) bool {
+	for _, builtin := range builtinGlobals {
+		if builtin.Label == name {
+			return true
+		}
+	}
+	return false
+}
+
+// starlarkDef is a function defined in a macro file.
+type starlarkDef struct {
+	Name   string
+	Params []string
+}
+
+// Signature returns the def's signature, e.g. "revenue(column, currency=None)".
+func (d starlarkDef) Signature() string {
+	return d.Name + "(" + strings.Join(d.Params, ", ") + ")"
+}
+
+var starlarkDefPattern = regexp.MustCompile(`(?m)^def\s+(\w+)\s*\(([^)]*)\)`)
+
+// starlarkDefs returns the functions defined at the top level of a macro
+// file. They are matched in the source, so files being edited that do not
+// parse still have them.
+func starlarkDefs(content string) []starlarkDef {
+	var defs []starlarkDef
+	for _, m := range starlarkDefPattern.FindAllStringSubmatch(content, -1) {
+		def := starlarkDef{Name: m[1]}
+		for _, param := range strings.Split(m[2], ",") {
+			if param = strings.Join(strings.Fields(param), " "); param != "" {
+				def.Params = append(def.Params, param)
+			}
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// starlarkCompletions returns the completions at a position of a macro file:
+// keywords, Starlark's builtins and the functions the file defines.
+// Attributes after a dot are not completed.
+func starlarkCompletions(doc *Document, pos Position) []CompletionItem {
+	before := doc.GetTextBefore(pos)
+	prefix := extractIdentifierBefore(before, len(before))
+	if strings.HasSuffix(before[:len(before)-len(prefix)], ".") {
+		return nil
+	}
+
+	var items []CompletionItem
+	add := func(item CompletionItem) {
+		if strings.HasPrefix(item.Label, prefix) {
+			items = append(items, item)
+		}
+	}
+	for _, def := range starlarkDefs(doc.Content) {
+		add(CompletionItem{Label: def.Name, Kind: CompletionItemKindFunction, Detail: def.Signature()})
+	}
+	names := make([]string, 0, len(starlark.Universe))
+	for name := range starlark.Universe {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if builtin, ok := starlarkBuiltins[name]; ok {
+			add(CompletionItem{Label: name, Kind: CompletionItemKindFunction, Detail: builtin.signature, Documentation: builtin.doc})
+		} else {
+			add(CompletionItem{Label: name, Kind: CompletionItemKindConstant})
+		}
+	}
+	for _, kw := range starlarkKeywords {
+		add(CompletionItem{Label: kw, Kind: CompletionItemKindKeyword})
+	}
+	return items
+}
+
+// starlarkHover returns the signature of the builtin or function of the file
+// at a position of a macro file.
+func starlarkHover(doc *Document, pos Position) *Hover {
+	word, _ := doc.GetWordAtPosition(pos)
+	if word == "" {
+		return nil
+	}
+	for _, def := range starlarkDefs(doc.Content) {
+		if def.Name == word {
+			return &Hover{Contents: MarkupContent{Kind: MarkupKindMarkdown, Value: "```\n" + def.Signature() + "\n```"}}
+		}
+	}
+	if builtin, ok := starlarkBuiltins[word]; ok {
+		return &Hover{Contents: MarkupContent{Kind: MarkupKindMarkdown, Value: "```\n" + builtin.signature + "\n```\n\n" + builtin.doc}}
+	}
+	return nil
+}
+
+// getSignatureHelp returns the signature of the function called at a
+// position: a builtin or a function of the file in macro files, or a macro
+// function in the template code of models.
+func (s *Server) getSignatureHelp(params SignatureHelpParams) *SignatureHelp {
+	doc := s.documents.Get(params.TextDocument.URI)
+	if doc == nil {
+		return nil
+	}
+	before := doc.GetTextBefore(params.Position)
+
+	code := before
+	if !isStarlarkURI(doc.URI) {
+		if !inTemplateCode(before) || s.store == nil {
+			return nil
+		}
+		// Calls are looked up within the template code only
+		code = before[max(strings.LastIndex(before, "{{"), strings.LastIndex(before, "{*"))+2:]
+	}
+	name, arg, ok := enclosingCall(code)
+	if !ok {
+		return nil
+	}
+
+	var label, documentation string
+	var parameters []string
+	if isStarlarkURI(doc.URI) {
+		for _, def := range starlarkDefs(doc.Content) {
+			if def.Name == name {
+				label, parameters = def.Signature(), def.Params
+			}
+		}
+		if builtin, ok := starlarkBuiltins[name]; ok && label == "" {
+			label, documentation = builtin.signature, builtin.doc
+			parameters = signatureParams(builtin.signature)
+		}
+	} else if namespace, function, dotted := strings.Cut(name, "."); dotted {
+		if fn, _ := s.store.GetMacroFunction(namespace, function); fn != nil {
+			label = fmt.Sprintf("%s.%s(%s)", namespace, fn.Name, strings.Join(fn.Args, ", "))
+			documentation, parameters = cleanDocstring(fn.Docstring), fn.Args
+		}
+	}
+	if label == "" {
+		return nil
+	}
+
+	sig := SignatureInformation{Label: label}
+	if documentation != "" {
+		sig.Documentation = &MarkupContent{Kind: MarkupKindMarkdown, Value: documentation}
+	}
+	for _, p := range parameters {
+		sig.Parameters = append(sig.Parameters, ParameterInformation{Label: p})
+	}
+	help := &SignatureHelp{Signatures: []SignatureInformation{sig}}
+	if len(parameters) > 0 {
+		// Extra arguments go to a trailing *args
+		help.ActiveParameter = uint32(min(arg, len(parameters)-1)) //nolint:gosec // G115: arg is non-negative
+	}
+	return help
+}
+
+// enclosingCall returns the name of the innermost call whose arguments
+// contain the end of code, e.g. "utils.pad" for `utils.pad(x, `, and the
+// index of the argument being written. Parentheses grouping expressions,
+// and lists and dicts within the arguments, are skipped.
+func enclosingCall(code string) (name string, arg int, ok bool) {
+	depth := 0
+	for i := len(code) - 1; i >= 0; i-- {
+		switch code[i] {
+		case ')', ']', '}':
+			depth++
+		case '[', '{':
+			if depth == 0 {
+				arg = 0 // The commas seen were inside a literal
+				continue
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				arg++
+			}
+		case '(':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			start := i
+			for start > 0 && (isIdentChar(code[start-1]) || code[start-1] == '.') {
+				start--
+			}
+			if name := strings.Trim(code[start:i], "."); name != "" {
+				return name, arg, true
+			}
+			arg = 0 // A grouping parenthesis
+		}
+	}
+	return "", 0, false
+}
+
+// signatureParams returns the parameters of a signature such as
+// "enumerate(x, start=0)".
+func signatureParams(signature string) []string {
+	open, end := strings.Index(signature, "("), strings.LastIndex(signature, ")")
+	if open < 0 || end <= open+1 {
+		return nil
+	}
+	return strings.Split(signature[open+1:end], ", ")
+}
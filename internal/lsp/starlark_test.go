@@ -0,0 +1,159 @@
+package lsp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openDocument opens a document in a new store and returns it.
+func openDocument(uri, content string) *Document {
+	store := NewDocumentStore()
+	store.Open(uri, content, 1)
+	return store.Get(uri)
+}
+
+func TestStarlarkDiagnostics(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		content string
+		want    []Diagnostic
+	}{
+		{
+			name:    "valid",
+			uri:     "file:///macros/utils.star",
+			content: "def pad(col, width=10):\n    return \"lpad({}, {})\".format(col, width)\n",
+		},
+		{
+			name:    "template global",
+			uri:     "file:///macros/utils.star",
+			content: "def schema():\n    return target.schema\n",
+			want: []Diagnostic{{
+				Range:    Range{Start: Position{Line: 1, Character: 11}, End: Position{Line: 1, Character: 17}},
+				Severity: DiagnosticSeverityError,
+				Source:   "leapsql",
+				Message:  "undefined: target (template globals are not available in macro files: pass the value as an argument)",
+			}},
+		},
+		{
+			name:    "syntax error",
+			uri:     "file:///macros/utils.star",
+			content: "def pad(col:\n    return col\n",
+			want: []Diagnostic{{
+				Range:    Range{Start: Position{Line: 0, Character: 12}, End: Position{Line: 0, Character: 12}},
+				Severity: DiagnosticSeverityError,
+				Source:   "leapsql",
+				Message:  "got ':', want ')'",
+			}},
+		},
+		{
+			name:    "reserved namespace",
+			uri:     "file:///macros/config.star",
+			content: "def x():\n    return 1\n",
+			want: []Diagnostic{{
+				Range:    Range{Start: Position{}, End: Position{Character: 3}},
+				Severity: DiagnosticSeverityError,
+				Source:   "leapsql",
+				Message:  `"config" is a reserved namespace: rename the file`,
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, starlarkDiagnostics(openDocument(tt.uri, tt.content)))
+		})
+	}
+}
+
+func TestServer_StarlarkDocumentDiagnostics(t *testing.T) {
+	server, _ := newMacroServer(t)
+	uri := "file:///macros/dates.star"
+	server.documents.Open(uri, "def today():\n    return env\n", 1)
+
+	report, ok := server.getDocumentDiagnostics(DocumentDiagnosticParams{TextDocument: TextDocumentIdentifier{URI: uri}}).(FullDocumentDiagnosticReport)
+	require.True(t, ok, "expected a full report")
+	require.Len(t, report.Items, 1)
+	assert.Contains(t, report.Items[0].Message, "undefined: env")
+}
+
+func TestStarlarkCompletions(t *testing.T) {
+	doc := openDocument("file:///macros/utils.star", "def pad(col, width=10):\n    return col\n\ndef wrap(x):\n    return pa\n")
+
+	labels := func(items []CompletionItem) []string {
+		var out []string
+		for _, item := range items {
+			out = append(out, item.Label)
+		}
+		return out
+	}
+	items := starlarkCompletions(doc, Position{Line: 4, Character: 13})
+	assert.Equal(t, []string{"pad", "pass"}, labels(items))
+	assert.Equal(t, "pad(col, width=10)", items[0].Detail)
+
+	// Builtins of Starlark's universe, not the template globals
+	assert.Contains(t, labels(starlarkCompletions(doc, Position{Line: 1, Character: 4})), "len")
+	assert.NotContains(t, labels(starlarkCompletions(doc, Position{Line: 1, Character: 4})), "config")
+
+	// Attributes are not completed
+	doc = openDocument("file:///macros/utils.star", "x = \"a\".st")
+	assert.Empty(t, starlarkCompletions(doc, Position{Character: 11}))
+}
+
+func TestEnclosingCall(t *testing.T) {
+	tests := []struct {
+		code string
+		name string
+		arg  int
+		ok   bool
+	}{
+		{"pad(", "pad", 0, true},
+		{"pad(col, ", "pad", 1, true},
+		{"utils.pad(x, len(y), ", "utils.pad", 2, true},
+		{"pad(x, [1, 2, ", "pad", 1, true},
+		{"pad(x, (1 + ", "pad", 1, true},
+		{"pad(x) + 1", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			name, arg, ok := enclosingCall(tt.code)
+			assert.Equal(t, tt.name, name)
+			assert.Equal(t, tt.arg, arg)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestServer_SignatureHelp(t *testing.T) {
+	server, _ := newMacroServer(t)
+	at := func(uri string, pos Position) *SignatureHelp {
+		return server.getSignatureHelp(SignatureHelpParams{TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: pos}})
+	}
+
+	// Functions of the macro file and builtins
+	star := "file:///macros/utils.star"
+	server.documents.Open(star, "def pad(col, width=10):\n    return col\n\nx = pad('a', \ny = sorted(", 1)
+	help := at(star, Position{Line: 3, Character: 13})
+	require.NotNil(t, help)
+	assert.Equal(t, "pad(col, width=10)", help.Signatures[0].Label)
+	assert.Equal(t, []ParameterInformation{{Label: "col"}, {Label: "width=10"}}, help.Signatures[0].Parameters)
+	assert.Equal(t, uint32(1), help.ActiveParameter)
+	help = at(star, Position{Line: 4, Character: 11})
+	require.NotNil(t, help)
+	assert.Equal(t, "sorted(x, key=None, reverse=False)", help.Signatures[0].Label)
+	assert.Equal(t, "New sorted list of the elements of an iterable.", help.Signatures[0].Documentation.Value)
+
+	// Macro calls in the template code of models
+	sql := "file:///models/orders.sql"
+	server.documents.Open(sql, "SELECT {{ finance.revenue('amount', ", 1)
+	help = at(sql, Position{Character: 36})
+	require.NotNil(t, help)
+	assert.Equal(t, "finance.revenue(column, currency=None)", help.Signatures[0].Label)
+	assert.Equal(t, uint32(1), help.ActiveParameter)
+	assert.Contains(t, help.Signatures[0].Documentation.Value, "Sums a column of amounts.")
+
+	// SQL calls are not macros
+	server.documents.Open(sql, "SELECT coalesce(a, ", 1)
+	assert.Nil(t, at(sql, Position{Character: 19}))
+}
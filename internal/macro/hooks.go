@@ -0,0 +1,69 @@
+package macro
+
+import (
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+// SchemaNameHook is the name of the macro function that generates the schema
+// names of models, e.g. to give each developer their own schemas.
+const SchemaNameHook = "generate_schema_name"
+
+// SchemaNamer calls a project's generate_schema_name hook.
+type SchemaNamer struct {
+	fn     starlark.Callable
+	target starlark.Value
+	env    string
+}
+
+// SchemaNamer returns the generate_schema_name hook of the registered macros,
+// bound to the target and environment it is called with, or nil if no macro
+// defines it. Defining the hook in more than one macro file is an error.
+func (r *Registry) SchemaNamer(target starlark.Value, env string) (*SchemaNamer, error) {
+	var fn starlark.Callable
+	var files []string
+	for _, namespace := range r.Namespaces() {
+		module := r.modules[namespace]
+		if hook, ok := module.Exports[SchemaNameHook].(starlark.Callable); ok {
+			fn = hook
+			files = append(files, namespace+".star")
+		}
+	}
+	if len(files) > 1 {
+		return nil, fmt.Errorf("%s is defined by multiple macro files: %s", SchemaNameHook, strings.Join(files, ", "))
+	}
+	if fn == nil {
+		return nil, nil
+	}
+	if target == nil {
+		target = starlark.None
+	}
+	return &SchemaNamer{fn: fn, target: target, env: env}, nil
+}
+
+// SchemaName calls generate_schema_name(custom_schema, target, env) with the
+// custom schema of a model ("" is passed as None) and returns the schema the
+// model is built in. The hook returns None, or "", for the target's default schema.
+func (n *SchemaNamer) SchemaName(custom string) (string, error) {
+	var customValue starlark.Value = starlark.None
+	if custom != "" {
+		customValue = starlark.String(custom)
+	}
+
+	thread := &starlark.Thread{Name: SchemaNameHook}
+	result, err := starlark.Call(thread, n.fn, starlark.Tuple{customValue, n.target, starlark.String(n.env)}, nil)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", SchemaNameHook, err)
+	}
+
+	switch v := result.(type) {
+	case starlark.NoneType:
+		return "", nil
+	case starlark.String:
+		return string(v), nil
+	default:
+		return "", fmt.Errorf("%s: returned %s, want a string or None", SchemaNameHook, result.Type())
+	}
+}
@@ -0,0 +1,96 @@
+package macro
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+func writeMacro(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+}
+
+func TestRegistry_SchemaNamer(t *testing.T) {
+	dir := t.TempDir()
+	writeMacro(t, dir, "naming.star", `
+def generate_schema_name(custom_schema, target, env):
+    if env == "prod":
+        return custom_schema
+    if custom_schema == None:
+        return target.schema
+    return target.schema + "_" + custom_schema
+`)
+
+	registry, err := LoadAndRegister(dir)
+	require.NoError(t, err)
+
+	target := starlarkstruct.FromStringDict(starlark.String("target"), starlark.StringDict{
+		"schema": starlark.String("dev_alice"),
+	})
+
+	namer, err := registry.SchemaNamer(target, "dev")
+	require.NoError(t, err)
+	require.NotNil(t, namer)
+
+	schema, err := namer.SchemaName("staging")
+	require.NoError(t, err)
+	assert.Equal(t, "dev_alice_staging", schema)
+
+	schema, err = namer.SchemaName("")
+	require.NoError(t, err)
+	assert.Equal(t, "dev_alice", schema)
+
+	// None in production: the target's default schema
+	namer, err = registry.SchemaNamer(target, "prod")
+	require.NoError(t, err)
+	schema, err = namer.SchemaName("")
+	require.NoError(t, err)
+	assert.Empty(t, schema)
+}
+
+func TestRegistry_SchemaNamer_Undefined(t *testing.T) {
+	dir := t.TempDir()
+	writeMacro(t, dir, "utils.star", "def double(x):\n    return x * 2\n")
+
+	registry, err := LoadAndRegister(dir)
+	require.NoError(t, err)
+
+	namer, err := registry.SchemaNamer(nil, "dev")
+	require.NoError(t, err)
+	assert.Nil(t, namer)
+}
+
+func TestRegistry_SchemaNamer_Errors(t *testing.T) {
+	t.Run("defined twice", func(t *testing.T) {
+		dir := t.TempDir()
+		writeMacro(t, dir, "a.star", "def generate_schema_name(custom_schema, target, env):\n    return custom_schema\n")
+		writeMacro(t, dir, "b.star", "def generate_schema_name(custom_schema, target, env):\n    return custom_schema\n")
+
+		registry, err := LoadAndRegister(dir)
+		require.NoError(t, err)
+
+		_, err = registry.SchemaNamer(nil, "dev")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "defined by multiple macro files: a.star, b.star")
+	})
+
+	t.Run("wrong return type", func(t *testing.T) {
+		dir := t.TempDir()
+		writeMacro(t, dir, "naming.star", "def generate_schema_name(custom_schema, target, env):\n    return 1\n")
+
+		registry, err := LoadAndRegister(dir)
+		require.NoError(t, err)
+
+		namer, err := registry.SchemaNamer(nil, "dev")
+		require.NoError(t, err)
+		_, err = namer.SchemaName("staging")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "returned int, want a string or None")
+	})
+}
@@ -0,0 +1,286 @@
+package metadata
+
+// datahub.go - DataHub sink, emitting metadata change proposals to the
+// DataHub REST API (GMS)
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// SinkDataHub is the catalog type of DataHub.
+const SinkDataHub = "datahub"
+
+// DefaultDataHubEnv is the DataHub environment (fabric) of datasets when the
+// sink sets none.
+const DefaultDataHubEnv = "PROD"
+
+// dataHubTimeout bounds each request to DataHub.
+const dataHubTimeout = 30 * time.Second
+
+func init() {
+	Register(SinkDataHub, NewDataHubSink)
+}
+
+// DataHubSink pushes datasets to DataHub through its REST emitter endpoint.
+// Each dataset is upserted with its properties, schema, ownership, tags and
+// upstream lineage, including column lineage.
+type DataHubSink struct {
+	url      string
+	token    string
+	platform string
+	env      string
+	client   *http.Client
+}
+
+// NewDataHubSink creates a DataHub sink. cfg.URL is the GMS URL, e.g.
+// http://localhost:8080; cfg.Platform is required.
+func NewDataHubSink(cfg core.MetadataSinkConfig) (Sink, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("datahub sink: url is required")
+	}
+	if cfg.Platform == "" {
+		return nil, fmt.Errorf("datahub sink: platform is required")
+	}
+	env := cfg.Env
+	if env == "" {
+		env = DefaultDataHubEnv
+	}
+	return &DataHubSink{
+		url:      strings.TrimSuffix(cfg.URL, "/"),
+		token:    cfg.Token,
+		platform: cfg.Platform,
+		env:      env,
+		client:   &http.Client{Timeout: dataHubTimeout},
+	}, nil
+}
+
+// Push upserts the aspects of each dataset, stopping at the first failure.
+func (s *DataHubSink) Push(ctx context.Context, datasets []Dataset) error {
+	for _, d := range datasets {
+		urn := s.datasetURN(d.Name)
+		for _, aspect := range s.aspects(d) {
+			if err := s.emit(ctx, urn, aspect.name, aspect.value); err != nil {
+				return fmt.Errorf("failed to push %s of %s to DataHub: %w", aspect.name, d.Model, err)
+			}
+		}
+	}
+	return nil
+}
+
+type dataHubAspect struct {
+	name  string
+	value map[string]any
+}
+
+// aspects returns the DataHub aspects describing a dataset.
+func (s *DataHubSink) aspects(d Dataset) []dataHubAspect {
+	urn := s.datasetURN(d.Name)
+
+	custom := map[string]string{
+		"leapsql_model": d.Model,
+		"materialized":  d.Materialized,
+	}
+	if d.PartitionBy != nil {
+		custom["partition_by"] = d.PartitionBy.String()
+	}
+	properties := map[string]any{
+		"name":             d.Name,
+		"customProperties": custom,
+	}
+	if d.Description != "" {
+		properties["description"] = d.Description
+	}
+	subType := "Table"
+	if d.Materialized == "view" {
+		subType = "View"
+	}
+	aspects := []dataHubAspect{
+		{"datasetProperties", properties},
+		{"subTypes", map[string]any{"typeNames": []string{subType}}},
+	}
+
+	if len(d.Fields) > 0 {
+		fields := make([]map[string]any, 0, len(d.Fields))
+		for _, f := range d.Fields {
+			fields = append(fields, map[string]any{
+				"fieldPath":      f.Name,
+				"nativeDataType": f.Type,
+				"type":           map[string]any{"type": map[string]any{dataHubFieldType(f.Type): map[string]any{}}},
+				"nullable":       f.Nullable,
+			})
+		}
+		aspects = append(aspects, dataHubAspect{"schemaMetadata", map[string]any{
+			"schemaName":     d.Name,
+			"platform":       s.platformURN(),
+			"version":        0,
+			"hash":           "",
+			"platformSchema": map[string]any{"com.linkedin.schema.OtherSchema": map[string]any{"rawSchema": ""}},
+			"fields":         fields,
+		}})
+	}
+
+	if d.Owner != "" {
+		aspects = append(aspects, dataHubAspect{"ownership", map[string]any{
+			"owners": []map[string]any{{"owner": dataHubOwnerURN(d.Owner), "type": "TECHNICAL_OWNER"}},
+		}})
+	}
+
+	if len(d.Tags) > 0 {
+		tags := make([]map[string]any, 0, len(d.Tags))
+		for _, tag := range d.Tags {
+			tags = append(tags, map[string]any{"tag": "urn:li:tag:" + tag})
+		}
+		aspects = append(aspects, dataHubAspect{"globalTags", map[string]any{"tags": tags}})
+	}
+
+	if len(d.Upstreams) > 0 {
+		upstreams := make([]map[string]any, 0, len(d.Upstreams))
+		for _, table := range d.Upstreams {
+			upstreams = append(upstreams, map[string]any{
+				"dataset":    s.datasetURN(table),
+				"type":       "TRANSFORMED",
+				"auditStamp": map[string]any{"time": 0, "actor": "urn:li:corpuser:unknown"},
+			})
+		}
+		lineage := map[string]any{"upstreams": upstreams}
+
+		var columns []map[string]any
+		for _, cl := range d.ColumnLineage {
+			if len(cl.Sources) == 0 {
+				continue
+			}
+			sources := make([]string, 0, len(cl.Sources))
+			for _, src := range cl.Sources {
+				sources = append(sources, fieldURN(s.datasetURN(src.Table), src.Column))
+			}
+			column := map[string]any{
+				"upstreamType":   "FIELD_SET",
+				"upstreams":      sources,
+				"downstreamType": "FIELD",
+				"downstreams":    []string{fieldURN(urn, cl.Column)},
+			}
+			if cl.Transform != "" {
+				column["transformOperation"] = cl.Transform
+			}
+			columns = append(columns, column)
+		}
+		if len(columns) > 0 {
+			lineage["fineGrainedLineages"] = columns
+		}
+		aspects = append(aspects, dataHubAspect{"upstreamLineage", lineage})
+	}
+
+	return aspects
+}
+
+// emit upserts one aspect of a dataset with an ingestProposal request.
+func (s *DataHubSink) emit(ctx context.Context, urn, aspectName string, aspect map[string]any) error {
+	value, err := json.Marshal(aspect)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]any{
+		"proposal": map[string]any{
+			"entityType": "dataset",
+			"entityUrn":  urn,
+			"changeType": "UPSERT",
+			"aspectName": aspectName,
+			"aspect":     map[string]any{"value": string(value), "contentType": "application/json"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+"/aspects?action=ingestProposal", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-RestLi-Protocol-Version", "2.0.0")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		return fmt.Errorf("DataHub returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func (s *DataHubSink) platformURN() string {
+	return "urn:li:dataPlatform:" + s.platform
+}
+
+// datasetURN returns the URN of the dataset of a table, e.g.
+// urn:li:dataset:(urn:li:dataPlatform:duckdb,staging.orders,PROD).
+func (s *DataHubSink) datasetURN(table string) string {
+	return fmt.Sprintf("urn:li:dataset:(%s,%s,%s)", s.platformURN(), table, s.env)
+}
+
+// fieldURN returns the URN of a column of a dataset.
+func fieldURN(datasetURN, column string) string {
+	return fmt.Sprintf("urn:li:schemaField:(%s,%s)", datasetURN, column)
+}
+
+// dataHubOwnerURN returns the URN of an owner: owners that are URNs are kept,
+// email addresses are users and other names are groups (teams).
+func dataHubOwnerURN(owner string) string {
+	switch {
+	case strings.HasPrefix(owner, "urn:li:"):
+		return owner
+	case strings.Contains(owner, "@"):
+		return "urn:li:corpuser:" + owner
+	default:
+		return "urn:li:corpGroup:" + owner
+	}
+}
+
+// dataHubFieldType returns the DataHub schema field type of a database type.
+func dataHubFieldType(typ string) string {
+	typ = strings.ToUpper(strings.TrimSpace(typ))
+	if strings.HasSuffix(typ, "[]") || strings.HasPrefix(typ, "LIST") {
+		return "com.linkedin.schema.ArrayType"
+	}
+	base, _, _ := strings.Cut(typ, "(")
+	switch base {
+	case "":
+		return "com.linkedin.schema.NullType"
+	case "BOOLEAN", "BOOL":
+		return "com.linkedin.schema.BooleanType"
+	case "TINYINT", "SMALLINT", "INTEGER", "INT", "BIGINT", "HUGEINT",
+		"UTINYINT", "USMALLINT", "UINTEGER", "UBIGINT", "UHUGEINT",
+		"FLOAT", "DOUBLE", "REAL", "DECIMAL", "NUMERIC", "DOUBLE PRECISION":
+		return "com.linkedin.schema.NumberType"
+	case "DATE":
+		return "com.linkedin.schema.DateType"
+	case "TIME":
+		return "com.linkedin.schema.TimeType"
+	case "BLOB", "BYTEA", "VARBINARY":
+		return "com.linkedin.schema.BytesType"
+	case "STRUCT":
+		return "com.linkedin.schema.RecordType"
+	case "MAP":
+		return "com.linkedin.schema.MapType"
+	}
+	if strings.HasPrefix(base, "TIMESTAMP") {
+		return "com.linkedin.schema.TimeType"
+	}
+	return "com.linkedin.schema.StringType"
+}
@@ -0,0 +1,139 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type proposal struct {
+	EntityURN  string `json:"entityUrn"`
+	ChangeType string `json:"changeType"`
+	AspectName string `json:"aspectName"`
+	Aspect     struct {
+		Value string `json:"value"`
+	} `json:"aspect"`
+}
+
+func TestNewSink(t *testing.T) {
+	_, err := NewSink(core.MetadataSinkConfig{Type: "amundsen"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown metadata sink type "amundsen"`)
+
+	_, err = NewSink(core.MetadataSinkConfig{Type: SinkDataHub, Platform: "duckdb"})
+	assert.EqualError(t, err, "datahub sink: url is required")
+
+	sink, err := NewSink(core.MetadataSinkConfig{Type: SinkDataHub, URL: "http://gms:8080/", Platform: "duckdb"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://gms:8080", sink.(*DataHubSink).url)
+	assert.Equal(t, DefaultDataHubEnv, sink.(*DataHubSink).env)
+	assert.True(t, IsRegistered(SinkDataHub))
+}
+
+func TestDataHubSink_Push(t *testing.T) {
+	var auth string
+	aspects := make(map[string]map[string]any)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/aspects", r.URL.Path)
+		assert.Equal(t, "ingestProposal", r.URL.Query().Get("action"))
+		auth = r.Header.Get("Authorization")
+
+		var body struct {
+			Proposal proposal `json:"proposal"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		p := body.Proposal
+		assert.Equal(t, "urn:li:dataset:(urn:li:dataPlatform:duckdb,marts.revenue,DEV)", p.EntityURN)
+		assert.Equal(t, "UPSERT", p.ChangeType)
+		var value map[string]any
+		require.NoError(t, json.Unmarshal([]byte(p.Aspect.Value), &value))
+		aspects[p.AspectName] = value
+	}))
+	defer srv.Close()
+
+	sink, err := NewDataHubSink(core.MetadataSinkConfig{URL: srv.URL, Token: "tok", Platform: "duckdb", Env: "DEV"})
+	require.NoError(t, err)
+	err = sink.Push(context.Background(), []Dataset{{
+		Model:        "marts.revenue",
+		Name:         "marts.revenue",
+		Description:  "Revenue per customer",
+		Materialized: "table",
+		Owner:        "finance",
+		PartitionBy:  &core.PartitionSpec{Column: "order_date", Grain: core.PartitionGrainMonth},
+		Tags:         []string{"mart"},
+		Fields: []Field{
+			{Name: "customer_id", Type: "BIGINT"},
+			{Name: "revenue", Type: "DECIMAL(18,2)", Nullable: true},
+		},
+		Upstreams: []string{"staging.orders"},
+		ColumnLineage: []ColumnLineage{
+			{Column: "customer_id", Sources: []ColumnRef{{Table: "staging.orders", Column: "customer_id"}}},
+			{Column: "revenue", Sources: []ColumnRef{{Table: "staging.orders", Column: "amount"}}, Transform: "SUM"},
+		},
+	}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer tok", auth)
+	assert.ElementsMatch(t, []string{"datasetProperties", "subTypes", "schemaMetadata", "ownership", "globalTags", "upstreamLineage"}, keys(aspects))
+	assert.Equal(t, "Revenue per customer", aspects["datasetProperties"]["description"])
+	assert.Equal(t, map[string]any{"leapsql_model": "marts.revenue", "materialized": "table", "partition_by": "order_date (month)"}, aspects["datasetProperties"]["customProperties"])
+	assert.Equal(t, []any{"Table"}, aspects["subTypes"]["typeNames"])
+	assert.Equal(t, []any{map[string]any{"owner": "urn:li:corpGroup:finance", "type": "TECHNICAL_OWNER"}}, aspects["ownership"]["owners"])
+	assert.Equal(t, []any{map[string]any{"tag": "urn:li:tag:mart"}}, aspects["globalTags"]["tags"])
+
+	fields := aspects["schemaMetadata"]["fields"].([]any)
+	require.Len(t, fields, 2)
+	assert.Equal(t, map[string]any{
+		"fieldPath":      "revenue",
+		"nativeDataType": "DECIMAL(18,2)",
+		"type":           map[string]any{"type": map[string]any{"com.linkedin.schema.NumberType": map[string]any{}}},
+		"nullable":       true,
+	}, fields[1])
+
+	lineage := aspects["upstreamLineage"]
+	upstream := lineage["upstreams"].([]any)[0].(map[string]any)
+	assert.Equal(t, "urn:li:dataset:(urn:li:dataPlatform:duckdb,staging.orders,DEV)", upstream["dataset"])
+	columns := lineage["fineGrainedLineages"].([]any)
+	require.Len(t, columns, 2)
+	assert.Equal(t, map[string]any{
+		"upstreamType":       "FIELD_SET",
+		"upstreams":          []any{"urn:li:schemaField:(urn:li:dataset:(urn:li:dataPlatform:duckdb,staging.orders,DEV),amount)"},
+		"downstreamType":     "FIELD",
+		"downstreams":        []any{"urn:li:schemaField:(urn:li:dataset:(urn:li:dataPlatform:duckdb,marts.revenue,DEV),revenue)"},
+		"transformOperation": "SUM",
+	}, columns[1])
+}
+
+func TestDataHubSink_PushError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"message":"Unauthorized"}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	sink, err := NewDataHubSink(core.MetadataSinkConfig{URL: srv.URL, Platform: "duckdb"})
+	require.NoError(t, err)
+	err = sink.Push(context.Background(), []Dataset{{Model: "orders", Name: "orders"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to push datasetProperties of orders to DataHub")
+	assert.Contains(t, err.Error(), "Unauthorized")
+}
+
+func TestDataHubOwnerURN(t *testing.T) {
+	assert.Equal(t, "urn:li:corpGroup:data-platform", dataHubOwnerURN("data-platform"))
+	assert.Equal(t, "urn:li:corpuser:ana@example.com", dataHubOwnerURN("ana@example.com"))
+	assert.Equal(t, "urn:li:corpuser:ana", dataHubOwnerURN("urn:li:corpuser:ana"))
+}
+
+func keys(m map[string]map[string]any) []string {
+	var result []string
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
@@ -0,0 +1,122 @@
+// Package metadata pushes model metadata to data catalogs, so the catalog
+// stays in sync with the project: one dataset per model with its schema,
+// ownership, tags, and table and column lineage.
+//
+// Catalogs are pushed to through a Sink. Sink implementations register a
+// factory under their catalog type in init(), like adapters do.
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// Dataset is the catalog metadata of one model.
+type Dataset struct {
+	// Model is the model path
+	Model string
+	// Name is the table the model is built as, e.g. "staging.orders"
+	Name string
+	// Description is the model description
+	Description string
+	// Materialized is how the model is stored: table, view, incremental...
+	Materialized string
+	// Owner is the team or person responsible for the model (empty if none)
+	Owner string
+	// PartitionBy is the partition column and grain of the table (nil if
+	// the table is not partitioned)
+	PartitionBy *core.PartitionSpec
+	// Tags are the model tags
+	Tags []string
+	// Fields are the output columns, in order (nil if unknown)
+	Fields []Field
+	// Upstreams are the tables the model reads: the tables of the models it
+	// depends on and raw source tables
+	Upstreams []string
+	// ColumnLineage lists where each output column comes from
+	ColumnLineage []ColumnLineage
+}
+
+// Field is an output column of a dataset.
+type Field struct {
+	// Name is the column name
+	Name string
+	// Type is the database type, e.g. "BIGINT" (empty if unknown)
+	Type string
+	// Nullable is false when the column has a not_null test
+	Nullable bool
+}
+
+// ColumnLineage lists the upstream columns an output column is computed from.
+type ColumnLineage struct {
+	// Column is the output column
+	Column string
+	// Sources are the upstream columns
+	Sources []ColumnRef
+	// Transform is the function computing the column, e.g. "SUM", or "EXPR"
+	// for other expressions (empty for a column copied as is)
+	Transform string
+}
+
+// ColumnRef is a column of an upstream table.
+type ColumnRef struct {
+	Table  string
+	Column string
+}
+
+// Sink pushes datasets to a data catalog.
+type Sink interface {
+	// Push creates or updates the datasets in the catalog
+	Push(ctx context.Context, datasets []Dataset) error
+}
+
+// SinkFactory creates a sink from its configuration.
+type SinkFactory func(cfg core.MetadataSinkConfig) (Sink, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]SinkFactory)
+)
+
+// Register adds a sink factory for a catalog type.
+// Called by sink implementations in their init() functions.
+func Register(sinkType string, factory SinkFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[sinkType] = factory
+}
+
+// NewSink creates the sink of a configured catalog.
+func NewSink(cfg core.MetadataSinkConfig) (Sink, error) {
+	registryMu.RLock()
+	factory, ok := registry[cfg.Type]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown metadata sink type %q: must be one of %v", cfg.Type, ListSinks())
+	}
+	return factory(cfg)
+}
+
+// ListSinks returns the registered catalog types (sorted).
+func ListSinks() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsRegistered reports whether a catalog type has a registered sink.
+func IsRegistered(sinkType string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	_, ok := registry[sinkType]
+	return ok
+}
@@ -27,6 +27,7 @@ type ParsedDocument struct {
 	Template      *template.Template
 	TemplateError error
 	SQLContent    string // Content with templates replaced
+	sqlMap        []sqlSegment
 
 	// SQL parsing result
 	SQL      *core.SelectStmt
@@ -66,7 +67,7 @@ func Parse(content string, uri string, version int, d *core.Dialect) *ParsedDocu
 	doc.TemplateError = err
 
 	// Phase 3: Extract and parse SQL
-	doc.SQLContent = extractSQL(content, doc.FrontmatterEnd)
+	doc.SQLContent, doc.sqlMap = mapSQL(content, doc.FrontmatterEnd)
 
 	if strings.TrimSpace(doc.SQLContent) != "" && d != nil {
 		stmt, err := pkgparser.ParseWithDialect(doc.SQLContent, d)
@@ -77,28 +78,113 @@ func Parse(content string, uri string, version int, d *core.Dialect) *ParsedDocu
 	return doc
 }
 
+// templatePattern matches template expressions {{ expr }}, replaced by a
+// placeholder in the SQL, and template statements {* ... *}, removed.
+var templatePattern = regexp.MustCompile(`\{\{[^}]+\}\}|\{\*[^*]*\*\}`)
+
+// exprPlaceholder replaces template expressions in the SQL.
+const exprPlaceholder = "__EXPR__"
+
+// sqlSegment maps a run of SQLContent to the content it comes from: text
+// copied as is, or the placeholder of a template expression.
+type sqlSegment struct {
+	sqlStart, sqlLen int
+	docStart, docLen int
+	expr             bool
+}
+
 // extractSQL extracts SQL content from a model file, handling frontmatter and templates.
 func extractSQL(content string, frontmatterEnd int) string {
+	sql, _ := mapSQL(content, frontmatterEnd)
+	return sql
+}
+
+// mapSQL extracts the SQL of a model file like extractSQL, along with the
+// segments mapping it back to the file's content.
+func mapSQL(content string, frontmatterEnd int) (string, []sqlSegment) {
 	// Skip frontmatter
+	base := 0
 	if frontmatterEnd > 0 && frontmatterEnd < len(content) {
-		content = content[frontmatterEnd:]
-	} else {
+		base = frontmatterEnd
+	} else if idx := strings.Index(content, "/*---"); idx != -1 {
 		// Try to detect frontmatter if frontmatterEnd wasn't provided
-		if idx := strings.Index(content, "/*---"); idx != -1 {
-			if endIdx := strings.Index(content, "---*/"); endIdx != -1 {
-				content = content[endIdx+5:]
-			}
+		if endIdx := strings.Index(content, "---*/"); endIdx != -1 {
+			base = endIdx + 5
 		}
 	}
 
-	// Replace template expressions with placeholders
-	// {{ expr }} -> __EXPR__
-	content = regexp.MustCompile(`\{\{[^}]+\}\}`).ReplaceAllString(content, "__EXPR__")
+	var sql strings.Builder
+	var segments []sqlSegment
+	copyText := func(start, end int) {
+		if end > start {
+			segments = append(segments, sqlSegment{sqlStart: sql.Len(), sqlLen: end - start, docStart: start, docLen: end - start})
+			sql.WriteString(content[start:end])
+		}
+	}
+	pos := base
+	for _, m := range templatePattern.FindAllStringIndex(content[base:], -1) {
+		start, end := base+m[0], base+m[1]
+		copyText(pos, start)
+		if strings.HasPrefix(content[start:], "{{") {
+			segments = append(segments, sqlSegment{sqlStart: sql.Len(), sqlLen: len(exprPlaceholder), docStart: start, docLen: end - start, expr: true})
+			sql.WriteString(exprPlaceholder)
+		}
+		pos = end
+	}
+	copyText(pos, len(content))
+	return sql.String(), segments
+}
 
-	// Remove template statements {* ... *}
-	content = regexp.MustCompile(`\{\*[^*]*\*\}`).ReplaceAllString(content, "")
+// DocumentOffset returns the offset in Content of an offset in SQLContent,
+// so positions the parser reports point at the text the user wrote. Offsets
+// in the placeholder of a template expression map into the expression.
+func (d *ParsedDocument) DocumentOffset(sqlOffset int) int {
+	for _, seg := range d.sqlMap {
+		if sqlOffset < seg.sqlStart+seg.sqlLen {
+			return seg.docStart + min(max(sqlOffset-seg.sqlStart, 0), seg.docLen)
+		}
+	}
+	if n := len(d.sqlMap); n > 0 {
+		last := d.sqlMap[n-1]
+		return last.docStart + last.docLen
+	}
+	return min(d.FrontmatterEnd+sqlOffset, len(d.Content))
+}
+
+// DocumentRange returns the range in Content of a range in SQLContent. A
+// range reaching into the placeholder of a template expression covers the
+// whole expression.
+func (d *ParsedDocument) DocumentRange(sqlStart, sqlEnd int) (int, int) {
+	start, end := d.DocumentOffset(sqlStart), d.DocumentOffset(sqlEnd)
+	if seg, ok := d.segmentAt(sqlStart); ok && seg.expr {
+		start = seg.docStart
+	}
+	// Ends are exclusive: the end of a placeholder belongs to it
+	if seg, ok := d.segmentAt(max(sqlEnd-1, sqlStart)); ok && seg.expr {
+		end = seg.docStart + seg.docLen
+	}
+	return start, max(end, start)
+}
 
-	return content
+// HasTemplate reports whether a range of SQLContent overlaps the placeholder
+// of a template expression.
+func (d *ParsedDocument) HasTemplate(sqlStart, sqlEnd int) bool {
+	for _, seg := range d.sqlMap {
+		if seg.expr && sqlStart < seg.sqlStart+seg.sqlLen && seg.sqlStart < max(sqlEnd, sqlStart+1) {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentAt returns the segment holding an offset of SQLContent.
+func (d *ParsedDocument) segmentAt(sqlOffset int) (sqlSegment, bool) {
+	for _, seg := range d.sqlMap {
+		if sqlOffset >= seg.sqlStart && sqlOffset < seg.sqlStart+seg.sqlLen {
+			return seg, true
+		}
+	}
+	return sqlSegment{}, false
 }
 
 // HasFrontmatterError returns true if frontmatter parsing failed.
@@ -4,6 +4,7 @@
 package provider
 
 import (
+	"errors"
 	"log/slog"
 	"sync"
 	"time"
@@ -132,7 +133,7 @@ func (p *Provider) InvalidateProjectContext() {
 }
 
 // buildProjectContext constructs the project context from the store.
-// Uses batch queries for performance (~5 queries instead of ~500 for 100 models).
+// Returns nil if the store has no models or cannot be read.
 func (p *Provider) buildProjectContext() *project.Context {
 	if p.store == nil {
 		return nil
@@ -140,96 +141,19 @@ func (p *Provider) buildProjectContext() *project.Context {
 
 	startTime := time.Now()
 
-	// Get all models in one query
-	storeModels, err := p.store.ListModels()
-	if err != nil || len(storeModels) == 0 {
-		return nil
-	}
-
-	// Build model ID to path mapping for dependency resolution
-	modelIDToPath := make(map[string]string, len(storeModels))
-	for _, m := range storeModels {
-		modelIDToPath[m.ID] = m.Path
-	}
-
-	// Batch fetch all columns (1 query instead of N)
-	allColumns, err := p.store.BatchGetAllColumns()
-	if err != nil {
-		p.logger.Warn("Failed to batch get columns, falling back to per-model queries", "error", err)
-		allColumns = nil
-	}
-
-	// Batch fetch all dependencies (1 query instead of N)
-	allDeps, err := p.store.BatchGetAllDependencies()
-	if err != nil {
-		p.logger.Warn("Failed to batch get dependencies", "error", err)
-		allDeps = make(map[string][]string)
-	}
-
-	// Batch fetch all dependents (1 query instead of N)
-	allDependents, err := p.store.BatchGetAllDependents()
+	ctx, err := project.NewContextFromStore(p.store, p.config)
 	if err != nil {
-		p.logger.Warn("Failed to batch get dependents", "error", err)
-		allDependents = make(map[string][]string)
-	}
-
-	// Build the context maps
-	models := make(map[string]*project.ModelInfo, len(storeModels))
-	parents := make(map[string][]string)
-	children := make(map[string][]string)
-
-	// Process each model
-	for _, m := range storeModels {
-		// Get columns from batch result or fallback to individual query
-		var columns []core.ColumnInfo
-		if allColumns != nil {
-			columns = allColumns[m.Path]
-		} else {
-			// Fallback to individual query
-			columns, _ = p.store.GetModelColumns(m.Path)
+		if !errors.Is(err, project.ErrNoModels) {
+			p.logger.Warn("Failed to build project context", "error", err)
 		}
-
-		// Get dependencies from batch result (convert IDs to paths)
-		deps := allDeps[m.ID]
-		var parentPaths []string
-		for _, depID := range deps {
-			if path, ok := modelIDToPath[depID]; ok {
-				parentPaths = append(parentPaths, path)
-			}
-		}
-
-		// Get dependents from batch result (convert IDs to paths)
-		dependents := allDependents[m.ID]
-		var childPaths []string
-		for _, depID := range dependents {
-			if path, ok := modelIDToPath[depID]; ok {
-				childPaths = append(childPaths, path)
-			}
-		}
-
-		models[m.Path] = &project.ModelInfo{
-			Path:           m.Path,
-			Name:           m.Name,
-			FilePath:       m.FilePath,
-			Columns:        columns,
-			Materialized:   m.Materialized,
-			Tags:           m.Tags,
-			Meta:           m.Meta,
-			UsesSelectStar: m.UsesSelectStar,
-		}
-		parents[m.Path] = parentPaths
-		children[m.Path] = childPaths
+		return nil
 	}
 
-	// Infer model types
-	project.InferAndSetTypes(models)
-
 	p.logger.Debug("Built project context",
-		"models", len(models),
+		"models", len(ctx.GetModels()),
 		"duration", time.Since(startTime))
 
-	// Use NewContextWithStore to enable schema drift detection (PL05)
-	return project.NewContextWithStore(models, parents, children, p.config, p.store)
+	return ctx
 }
 
 // Store returns the underlying state store.
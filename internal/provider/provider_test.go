@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -154,3 +155,44 @@ func TestExtractSQL_WithTemplates(t *testing.T) {
 	assert.NotContains(t, result, "{{")
 	assert.NotContains(t, result, "{*")
 }
+
+func TestParsedDocument_DocumentOffset(t *testing.T) {
+	content := "/*---\nname: test\n---*/\nSELECT {{ utils.amount() }} AS amount, {* if x *}id\nFROM users"
+	doc := Parse(content, "test.sql", 1, nil)
+	sql := doc.SQLContent
+
+	// Text before, between and after templates maps to itself
+	for _, word := range []string{"SELECT", "AS amount", "id\n", "users"} {
+		assert.Equal(t, strings.Index(content, word), doc.DocumentOffset(strings.Index(sql, word)), word)
+	}
+
+	// Placeholders map into their template expression
+	placeholder := strings.Index(sql, "__EXPR__")
+	assert.Equal(t, strings.Index(content, "{{"), doc.DocumentOffset(placeholder))
+
+	// The end of the SQL maps to the end of the content
+	assert.Equal(t, len(content), doc.DocumentOffset(len(sql)))
+}
+
+func TestParsedDocument_DocumentRange(t *testing.T) {
+	content := "/*---\nname: test\n---*/\nSELECT {{ utils.amount() }} AS amount\nFROM users"
+	doc := Parse(content, "test.sql", 1, nil)
+	sql := doc.SQLContent
+	text := func(sqlText string) string {
+		i := strings.Index(sql, sqlText)
+		start, end := doc.DocumentRange(i, i+len(sqlText))
+		return content[start:end]
+	}
+
+	// Ranges reaching into a placeholder cover its template expression
+	assert.Equal(t, "FROM users", text("FROM users"))
+	assert.Equal(t, "{{ utils.amount() }}", text("EXPR"))
+	assert.Equal(t, "SELECT {{ utils.amount() }}", text("SELECT __EX"))
+	assert.Equal(t, "{{ utils.amount() }} AS amount", text("__EXPR__ AS amount"))
+
+	placeholder := strings.Index(sql, "__EXPR__")
+	assert.True(t, doc.HasTemplate(placeholder-2, placeholder+1))
+	assert.True(t, doc.HasTemplate(placeholder+2, placeholder+2), "insertions in a placeholder")
+	assert.False(t, doc.HasTemplate(0, placeholder))
+	assert.False(t, doc.HasTemplate(placeholder+len("__EXPR__"), len(sql)))
+}
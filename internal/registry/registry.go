@@ -18,7 +18,8 @@ type ModelRegistry struct {
 	byPath map[string]*core.Model
 
 	// byName maps unqualified model names to paths: "stg_customers" → "staging.stg_customers"
-	// Note: if multiple models have the same name, the last registered wins
+	// Note: if multiple models have the same name, the last registered wins,
+	// except that versions of a model resolve to the latest version
 	byName map[string]string
 
 	// byTable maps qualified table names to model paths
@@ -29,6 +30,9 @@ type ModelRegistry struct {
 
 	// externalSources tracks known external sources (raw tables)
 	externalSources map[string]struct{}
+
+	// databases tracks the attached databases models are built in: "lake"
+	databases map[string]struct{}
 }
 
 // NewModelRegistry creates a new empty registry.
@@ -38,6 +42,7 @@ func NewModelRegistry() *ModelRegistry {
 		byName:          make(map[string]string),
 		byTable:         make(map[string]string),
 		externalSources: make(map[string]struct{}),
+		databases:       make(map[string]struct{}),
 	}
 }
 
@@ -51,23 +56,57 @@ func (r *ModelRegistry) Register(model *core.Model) {
 	r.byPath[model.Path] = model
 
 	// Register by model name (unqualified)
-	r.byName[model.Name] = model.Path
+	r.setLatest(r.byName, model.Name, model)
 
 	// Register by path as table name
 	r.byTable[model.Path] = model.Path
 
+	// Workspace models are also reachable by their physical table path and,
+	// within their project, by name: "core/stg_customers" → "core/staging.stg_customers"
+	project, tablePath := core.SplitModelID(model.Path)
+	if project != "" {
+		r.byTable[tablePath] = model.Path
+		r.setLatest(r.byTable, core.ModelID(project, model.Name), model)
+	}
+
+	// Versioned models are also reachable by a pinned version: "dim_users@v2"
+	if model.Version > 0 {
+		versioned := core.VersionedName(model.Name, model.Version)
+		r.byTable[versioned] = model.Path
+		if project != "" {
+			r.byTable[core.ModelID(project, versioned)] = model.Path
+		}
+	}
+
+	// Models built in an attached database are reachable by catalog-qualified
+	// names: "lake.staging.stg_customers", "lake.stg_customers"
+	if model.Database != "" {
+		r.databases[model.Database] = struct{}{}
+		r.byTable[model.Database+"."+tablePath] = model.Path
+		r.setLatest(r.byTable, model.Database+"."+model.Name, model)
+	}
+
 	// If the path contains a dot (e.g., "staging.stg_customers"),
 	// also register without the first component to support schema-qualified references
-	if parts := strings.SplitN(model.Path, ".", 2); len(parts) == 2 {
+	if parts := strings.SplitN(tablePath, ".", 2); len(parts) == 2 {
 		// Allow lookup by just the model name: "stg_customers"
 		r.byTable[parts[1]] = model.Path
 
 		// Also allow schema-qualified lookups with different schema prefixes
 		// e.g., "public.stg_customers" → "staging.stg_customers"
-		r.byTable[model.Name] = model.Path
+		r.setLatest(r.byTable, model.Name, model)
 	}
 }
 
+// setLatest maps key to the model unless it already maps to a newer version of the same model.
+// Must be called with the write lock held.
+func (r *ModelRegistry) setLatest(index map[string]string, key string, model *core.Model) {
+	if prev, ok := r.byPath[index[key]]; ok && prev.Name == model.Name && prev.Version > model.Version {
+		return
+	}
+	index[key] = model.Path
+}
+
 // RegisterExternalSource marks a table name as an external source (not a model).
 func (r *ModelRegistry) RegisterExternalSource(tableName string) {
 	r.mu.Lock()
@@ -96,7 +135,16 @@ func (r *ModelRegistry) Resolve(tableName string) (modelPath string, isModel boo
 		return path, true
 	}
 
-	// 4. Handle qualified names: try extracting just the table name
+	// 4. Names qualified by the attached database of models only resolve to
+	// models built in it: "lake.main.stg_customers" -> "lake.stg_customers"
+	if parts := strings.Split(tableName, "."); len(parts) == 3 {
+		if _, ok := r.databases[parts[0]]; ok {
+			path, ok := r.byTable[parts[0]+"."+parts[2]]
+			return path, ok
+		}
+	}
+
+	// 5. Handle qualified names: try extracting just the table name
 	if parts := strings.Split(tableName, "."); len(parts) > 1 {
 		justName := parts[len(parts)-1]
 
@@ -114,6 +162,45 @@ func (r *ModelRegistry) Resolve(tableName string) (modelPath string, isModel boo
 	return "", false
 }
 
+// ResolveFrom resolves a table name referenced from a model in the given project.
+// Models in the same project take precedence over equally named models in other
+// workspace projects. An empty project behaves like Resolve.
+func (r *ModelRegistry) ResolveFrom(project, tableName string) (modelPath string, isModel bool) {
+	if project != "" && !strings.Contains(tableName, core.ModelIDSeparator) {
+		if path, ok := r.ResolveRef(project, tableName); ok {
+			return path, true
+		}
+		if parts := strings.Split(tableName, "."); len(parts) > 1 {
+			if path, ok := r.ResolveRef(project, parts[len(parts)-1]); ok {
+				return path, true
+			}
+		}
+	}
+	return r.Resolve(tableName)
+}
+
+// ResolveRef resolves ref('project', 'model') to a model path.
+// The model may be given by name ("stg_orders"), versioned name ("stg_orders@v2"),
+// or path ("staging.stg_orders") and must belong to the named project.
+// An empty project resolves the model across all models like Resolve.
+func (r *ModelRegistry) ResolveRef(project, model string) (modelPath string, isModel bool) {
+	if project == "" {
+		return r.Resolve(model)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id := core.ModelID(project, model)
+	if _, ok := r.byPath[id]; ok {
+		return id, true
+	}
+	if path, ok := r.byTable[id]; ok {
+		return path, true
+	}
+	return "", false
+}
+
 // IsExternalSource returns true if the table name is known to be an external source.
 func (r *ModelRegistry) IsExternalSource(tableName string) bool {
 	r.mu.RLock()
@@ -154,11 +241,17 @@ func (r *ModelRegistry) Count() int {
 //   - dependencies: tables that are known models (deduplicated by model path)
 //   - externalSources: tables that are not models (raw/source tables, deduplicated)
 func (r *ModelRegistry) ResolveDependencies(tableNames []string) (dependencies []string, externalSources []string) {
+	return r.ResolveDependenciesFrom("", tableNames)
+}
+
+// ResolveDependenciesFrom is like ResolveDependencies for a model in the given
+// workspace project, preferring models from the same project (see ResolveFrom).
+func (r *ModelRegistry) ResolveDependenciesFrom(project string, tableNames []string) (dependencies []string, externalSources []string) {
 	seenDeps := make(map[string]struct{})
 	seenExternal := make(map[string]struct{})
 
 	for _, tableName := range tableNames {
-		if modelPath, isModel := r.Resolve(tableName); isModel {
+		if modelPath, isModel := r.ResolveFrom(project, tableName); isModel {
 			// Deduplicate by resolved model path
 			if _, ok := seenDeps[modelPath]; !ok {
 				seenDeps[modelPath] = struct{}{}
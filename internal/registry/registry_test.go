@@ -116,6 +116,102 @@ func TestModelRegistry_ResolveDependencies(t *testing.T) {
 	assert.Contains(t, external, "raw_orders", "expected raw_orders in external sources")
 }
 
+func TestModelRegistry_Workspace(t *testing.T) {
+	r := NewModelRegistry()
+
+	r.Register(&core.Model{Path: "core/staging.stg_customers", Name: "stg_customers", Project: "core"})
+	r.Register(&core.Model{Path: "billing/staging.stg_customers", Name: "stg_customers", Project: "billing"})
+	r.Register(&core.Model{Path: "billing/marts.invoices", Name: "invoices", Project: "billing"})
+
+	tests := []struct {
+		name      string
+		project   string
+		tableName string
+		wantPath  string
+		wantFound bool
+	}{
+		{"same project by name", "billing", "stg_customers", "billing/staging.stg_customers", true},
+		{"same project by path", "core", "staging.stg_customers", "core/staging.stg_customers", true},
+		{"namespaced ID", "billing", "core/stg_customers", "core/staging.stg_customers", true},
+		{"other project by table name", "core", "marts.invoices", "billing/marts.invoices", true},
+		{"unknown table", "core", "raw_orders", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPath, gotFound := r.ResolveFrom(tt.project, tt.tableName)
+			assert.Equal(t, tt.wantFound, gotFound)
+			assert.Equal(t, tt.wantPath, gotPath)
+		})
+	}
+
+	path, ok := r.ResolveRef("core", "stg_customers")
+	assert.True(t, ok, "expected ref to resolve")
+	assert.Equal(t, "core/staging.stg_customers", path)
+
+	_, ok = r.ResolveRef("core", "invoices")
+	assert.False(t, ok, "ref must not resolve to a model in another project")
+}
+
+func TestModelRegistry_Databases(t *testing.T) {
+	r := NewModelRegistry()
+
+	r.Register(&core.Model{Path: "staging.stg_orders", Name: "stg_orders", Database: "lake"})
+	r.Register(&core.Model{Path: "marts.revenue", Name: "revenue"})
+
+	tests := []struct {
+		name      string
+		tableName string
+		wantPath  string
+		wantFound bool
+	}{
+		{"three-part name", "lake.staging.stg_orders", "staging.stg_orders", true},
+		{"other schema in the database", "lake.main.stg_orders", "staging.stg_orders", true},
+		{"schema-qualified", "staging.stg_orders", "staging.stg_orders", true},
+		{"name", "stg_orders", "staging.stg_orders", true},
+		{"unknown table in the database", "lake.raw.revenue", "", false},
+		{"model of the target database", "dev.marts.revenue", "marts.revenue", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPath, gotFound := r.Resolve(tt.tableName)
+			assert.Equal(t, tt.wantFound, gotFound)
+			assert.Equal(t, tt.wantPath, gotPath)
+		})
+	}
+}
+
+func TestModelRegistry_Versions(t *testing.T) {
+	r := NewModelRegistry()
+
+	// Register out of order: the latest version must win regardless
+	r.Register(&core.Model{Path: "marts.dim_users_v10", Name: "dim_users", Version: 10})
+	r.Register(&core.Model{Path: "marts.dim_users_v2", Name: "dim_users", Version: 2})
+	r.Register(&core.Model{Path: "marts.dim_users_v1", Name: "dim_users", Version: 1})
+
+	tests := []struct {
+		name      string
+		tableName string
+		wantPath  string
+		wantFound bool
+	}{
+		{"unversioned name resolves to latest", "dim_users", "marts.dim_users_v10", true},
+		{"qualified name resolves to latest", "analytics.dim_users", "marts.dim_users_v10", true},
+		{"pinned version", "dim_users@v2", "marts.dim_users_v2", true},
+		{"version table name", "marts.dim_users_v1", "marts.dim_users_v1", true},
+		{"unknown version", "dim_users@v3", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotPath, gotFound := r.Resolve(tt.tableName)
+			assert.Equal(t, tt.wantFound, gotFound)
+			assert.Equal(t, tt.wantPath, gotPath)
+		})
+	}
+}
+
 func TestModelRegistry_ExternalSources(t *testing.T) {
 	r := NewModelRegistry()
 
@@ -0,0 +1,461 @@
+// Package selector parses and evaluates model selection expressions.
+//
+// A selector picks models by name, frontmatter metadata and run state:
+//
+//	staging.stg_users                   a model, by path or name
+//	tag:pii                             models tagged "pii"
+//	group:finance                       models owned by the "finance" group
+//	state:modified                      models changed since their last successful build
+//	result:error                        models that failed in the previous run
+//	stg_users+, +fct_x                  a term with its downstream / upstream models
+//	tag:pii AND NOT tag:deprecated      boolean combinations
+//	(tag:daily OR tag:hourly), fct_x    commas are shorthand for OR
+//	result:error+ state:modified+       and so is whitespace between terms
+//
+// NOT binds tighter than AND, which binds tighter than OR. Keywords are
+// case-insensitive. The same selector syntax is shared by run, lint, list
+// and the UI.
+package selector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// Expr is a parsed selection expression.
+type Expr interface {
+	// String returns the expression in canonical form.
+	String() string
+	// eval returns the paths of the models selected in c.
+	eval(c *Context) map[string]bool
+}
+
+// Context is the project a selector is evaluated against. Parents, Results
+// and Modified may be nil, in which case graph operators add no models and
+// state: and result: terms match none.
+type Context struct {
+	Models []*core.Model
+	// Parents maps model paths to the paths of the models they depend on
+	Parents map[string][]string
+	// Results maps model paths to their status in the previous run
+	Results map[string]core.ModelRunStatus
+	// Modified holds the paths of models changed since their last successful build
+	Modified map[string]bool
+}
+
+// Term kinds.
+const (
+	kindModel  = "model"
+	kindTag    = "tag"
+	kindGroup  = "group"
+	kindState  = "state"
+	kindResult = "result"
+)
+
+// resultStatuses maps result: values to model run statuses.
+var resultStatuses = map[string]core.ModelRunStatus{
+	"error":   core.ModelRunStatusFailed,
+	"success": core.ModelRunStatusSuccess,
+	"skipped": core.ModelRunStatusSkipped,
+}
+
+// term matches a single model, tag, group, state or result, optionally
+// extended to its upstream (+term) or downstream (term+) models.
+type term struct {
+	kind       string
+	value      string
+	upstream   bool
+	downstream bool
+}
+
+func (t *term) match(c *Context, m *core.Model) bool {
+	switch t.kind {
+	case kindTag:
+		for _, tag := range m.Tags {
+			if tag == t.value {
+				return true
+			}
+		}
+		return false
+	case kindGroup:
+		return m.Group == t.value
+	case kindState:
+		return c.Modified[m.Path]
+	case kindResult:
+		status, ok := c.Results[m.Path]
+		return ok && status == resultStatuses[t.value]
+	default:
+		return m.Path == t.value || m.Name == t.value
+	}
+}
+
+func (t *term) eval(c *Context) map[string]bool {
+	selected := make(map[string]bool)
+	for _, m := range c.Models {
+		if t.match(c, m) {
+			selected[m.Path] = true
+		}
+	}
+	if t.upstream {
+		walk(selected, c.Parents)
+	}
+	if t.downstream {
+		children := make(map[string][]string)
+		for child, parents := range c.Parents {
+			for _, parent := range parents {
+				children[parent] = append(children[parent], child)
+			}
+		}
+		walk(selected, children)
+	}
+	return selected
+}
+
+func (t *term) String() string {
+	s := t.kind + ":" + t.value
+	if t.kind == kindModel {
+		s = t.value
+	}
+	if t.upstream {
+		s = "+" + s
+	}
+	if t.downstream {
+		s += "+"
+	}
+	return s
+}
+
+// walk adds to selected every model reachable from it through edges.
+func walk(selected map[string]bool, edges map[string][]string) {
+	queue := make([]string, 0, len(selected))
+	for path := range selected {
+		queue = append(queue, path)
+	}
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[path] {
+			if !selected[next] {
+				selected[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+}
+
+type notExpr struct{ x Expr }
+
+func (e *notExpr) eval(c *Context) map[string]bool {
+	excluded := e.x.eval(c)
+	selected := make(map[string]bool)
+	for _, m := range c.Models {
+		if !excluded[m.Path] {
+			selected[m.Path] = true
+		}
+	}
+	return selected
+}
+
+func (e *notExpr) String() string { return "NOT " + e.x.String() }
+
+type andExpr struct{ left, right Expr }
+
+func (e *andExpr) eval(c *Context) map[string]bool {
+	left, right := e.left.eval(c), e.right.eval(c)
+	selected := make(map[string]bool)
+	for path := range left {
+		if right[path] {
+			selected[path] = true
+		}
+	}
+	return selected
+}
+
+func (e *andExpr) String() string { return "(" + e.left.String() + " AND " + e.right.String() + ")" }
+
+type orExpr struct{ left, right Expr }
+
+func (e *orExpr) eval(c *Context) map[string]bool {
+	selected := e.left.eval(c)
+	for path := range e.right.eval(c) {
+		selected[path] = true
+	}
+	return selected
+}
+
+func (e *orExpr) String() string { return "(" + e.left.String() + " OR " + e.right.String() + ")" }
+
+// Parse parses a selection expression.
+func Parse(input string) (Expr, error) {
+	p := &parser{tokens: tokenize(input)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty selector")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q in selector %q: expected AND, OR or ','", tok, input)
+	}
+	return expr, nil
+}
+
+// Select returns the sorted paths of the models in c matched by expr.
+func Select(expr Expr, c *Context) []string {
+	selected := expr.eval(c)
+	var paths []string
+	for _, m := range c.Models {
+		if selected[m.Path] {
+			paths = append(paths, m.Path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Validate checks that every model, tag, and group referenced by expr exists
+// in at least one model, so that typos fail loudly instead of selecting nothing.
+func Validate(expr Expr, models []*core.Model) error {
+	known := map[string]map[string]bool{
+		kindModel: {},
+		kindTag:   {},
+		kindGroup: {},
+	}
+	for _, m := range models {
+		known[kindModel][m.Path] = true
+		known[kindModel][m.Name] = true
+		for _, tag := range m.Tags {
+			known[kindTag][tag] = true
+		}
+		if m.Group != "" {
+			known[kindGroup][m.Group] = true
+		}
+	}
+
+	for _, t := range terms(expr) {
+		if t.kind == kindState || t.kind == kindResult || known[t.kind][t.value] {
+			continue
+		}
+		msg := fmt.Sprintf("unknown %s %q in selector", t.kind, t.value)
+		if suggestion := suggest(t.value, known[t.kind]); suggestion != "" {
+			msg += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		return fmt.Errorf("%s", msg)
+	}
+	return nil
+}
+
+// terms returns the terms referenced by expr, in order of appearance.
+func terms(expr Expr) []*term {
+	switch e := expr.(type) {
+	case *term:
+		return []*term{e}
+	case *notExpr:
+		return terms(e.x)
+	case *andExpr:
+		return append(terms(e.left), terms(e.right)...)
+	case *orExpr:
+		return append(terms(e.left), terms(e.right)...)
+	}
+	return nil
+}
+
+// tokenize splits input into words, parentheses, and commas.
+func tokenize(input string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '(' || r == ')' || r == ',':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// parser is a recursive descent parser over selector tokens.
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	return p.tokens[p.pos], true
+}
+
+// accept consumes the next token if it matches keyword (case-insensitively).
+func (p *parser) accept(keyword string) bool {
+	if tok, ok := p.peek(); ok && strings.EqualFold(tok, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// juxtaposed reports whether the next token starts another operand, which
+// is combined with the previous one by OR as in "a b".
+func (p *parser) juxtaposed() bool {
+	tok, ok := p.peek()
+	if !ok {
+		return false
+	}
+	switch {
+	case tok == ")" || tok == ",":
+		return false
+	case strings.EqualFold(tok, "AND") || strings.EqualFold(tok, "OR"):
+		return false
+	}
+	return true
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("OR") || p.accept(",") || p.juxtaposed() {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andExpr{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.accept("NOT") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of selector")
+	}
+
+	if tok == "(" {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ')' in selector")
+		}
+		return expr, nil
+	}
+
+	switch {
+	case tok == ")" || tok == ",":
+		return nil, fmt.Errorf("unexpected %q in selector", tok)
+	case strings.EqualFold(tok, "AND") || strings.EqualFold(tok, "OR"):
+		return nil, fmt.Errorf("unexpected %s in selector: missing operand", strings.ToUpper(tok))
+	}
+	p.pos++
+
+	t := &term{}
+	tok, t.upstream = strings.CutPrefix(tok, "+")
+	tok, t.downstream = strings.CutSuffix(tok, "+")
+	if tok == "" {
+		return nil, fmt.Errorf("missing model for '+' in selector")
+	}
+
+	kind, value, found := strings.Cut(tok, ":")
+	if !found {
+		t.kind, t.value = kindModel, tok
+		return t, nil
+	}
+	switch kind {
+	case kindTag, kindGroup, kindState, kindResult:
+	default:
+		return nil, fmt.Errorf("unknown selector method %q: expected tag, group, state or result", kind)
+	}
+	if value == "" {
+		return nil, fmt.Errorf("missing value for %s: in selector", kind)
+	}
+	switch {
+	case kind == kindState && value != "modified":
+		return nil, fmt.Errorf("unknown state %q in selector: expected modified", value)
+	case kind == kindResult && resultStatuses[value] == "":
+		return nil, fmt.Errorf("unknown result %q in selector: expected error, success or skipped", value)
+	}
+	t.kind, t.value = kind, value
+	return t, nil
+}
+
+// suggest returns the known value closest to value, if it is close enough
+// to likely be a typo.
+func suggest(value string, known map[string]bool) string {
+	best, bestDist := "", 3
+	for candidate := range known {
+		dist := levenshtein(strings.ToLower(value), strings.ToLower(candidate))
+		if dist < bestDist || (dist == bestDist && candidate < best) {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best
+}
+
+// levenshtein calculates the Levenshtein distance between two strings.
+func levenshtein(s1, s2 string) int {
+	prev := make([]int, len(s2)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s1); i++ {
+		curr := make([]int, len(s2)+1)
+		curr[0] = i
+		for j := 1; j <= len(s2); j++ {
+			cost := 1
+			if s1[i-1] == s2[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(s2)]
+}
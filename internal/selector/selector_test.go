@@ -0,0 +1,176 @@
+package selector
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testModels() []*core.Model {
+	return []*core.Model{
+		{Path: "staging.stg_users", Name: "stg_users", Tags: []string{"pii", "daily"}},
+		{Path: "staging.stg_orders", Name: "stg_orders", Tags: []string{"daily"}},
+		{Path: "marts.dim_users", Name: "dim_users", Tags: []string{"pii", "deprecated"}, Group: "growth"},
+		{Path: "marts.fct_revenue", Name: "fct_revenue", Tags: []string{"hourly"}, Group: "finance"},
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"stg_users", "stg_users"},
+		{"tag:pii", "tag:pii"},
+		{"group:finance", "group:finance"},
+		{"tag:pii AND NOT tag:deprecated", "(tag:pii AND NOT tag:deprecated)"},
+		{"tag:pii and not tag:deprecated", "(tag:pii AND NOT tag:deprecated)"},
+		{"a OR b AND c", "(a OR (b AND c))"},
+		{"(a OR b) AND c", "((a OR b) AND c)"},
+		{"a,b", "(a OR b)"},
+		{"a, b", "(a OR b)"},
+		{"NOT NOT a", "NOT NOT a"},
+		{"stg_users+", "stg_users+"},
+		{"+tag:pii+", "+tag:pii+"},
+		{"a b", "(a OR b)"},
+		{"result:error+ state:modified+", "(result:error+ OR state:modified+)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, expr.String())
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{"", "empty selector"},
+		{"tag:", "missing value for tag"},
+		{"owner:alice", `unknown selector method "owner"`},
+		{"(tag:pii", "missing ')'"},
+		{"tag:pii)", `unexpected ")"`},
+		{"tag:pii AND", "unexpected end of selector"},
+		{"AND tag:pii", "missing operand"},
+		{"+", "missing model for '+'"},
+		{"state:new", `unknown state "new"`},
+		{"result:fail", `unknown result "fail"`},
+		{"a,,b", `unexpected ","`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"stg_users", []string{"staging.stg_users"}},
+		{"marts.dim_users", []string{"marts.dim_users"}},
+		{"tag:pii", []string{"marts.dim_users", "staging.stg_users"}},
+		{"tag:pii AND NOT tag:deprecated", []string{"staging.stg_users"}},
+		{"tag:daily OR group:finance", []string{"marts.fct_revenue", "staging.stg_orders", "staging.stg_users"}},
+		{"NOT tag:daily AND NOT group:finance", []string{"marts.dim_users"}},
+		{"stg_orders, fct_revenue", []string{"marts.fct_revenue", "staging.stg_orders"}},
+		{"tag:pii AND tag:hourly", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Select(expr, &Context{Models: testModels()}))
+		})
+	}
+}
+
+func TestSelect_GraphAndState(t *testing.T) {
+	// stg_users -> dim_users -> fct_revenue, stg_orders -> fct_revenue
+	c := &Context{
+		Models: testModels(),
+		Parents: map[string][]string{
+			"marts.dim_users":   {"staging.stg_users"},
+			"marts.fct_revenue": {"marts.dim_users", "staging.stg_orders"},
+		},
+		Results: map[string]core.ModelRunStatus{
+			"staging.stg_users":  core.ModelRunStatusSuccess,
+			"staging.stg_orders": core.ModelRunStatusFailed,
+			"marts.fct_revenue":  core.ModelRunStatusSkipped,
+		},
+		Modified: map[string]bool{"marts.dim_users": true},
+	}
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"stg_users+", []string{"marts.dim_users", "marts.fct_revenue", "staging.stg_users"}},
+		{"+dim_users", []string{"marts.dim_users", "staging.stg_users"}},
+		{"+dim_users+", []string{"marts.dim_users", "marts.fct_revenue", "staging.stg_users"}},
+		{"tag:daily+ AND NOT tag:daily", []string{"marts.dim_users", "marts.fct_revenue"}},
+		{"result:error", []string{"staging.stg_orders"}},
+		{"result:skipped", []string{"marts.fct_revenue"}},
+		{"result:success", []string{"staging.stg_users"}},
+		{"state:modified", []string{"marts.dim_users"}},
+		{"result:error+ state:modified+", []string{"marts.dim_users", "marts.fct_revenue", "staging.stg_orders"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, Select(expr, c))
+		})
+	}
+
+	// Without run state, state: and result: match nothing
+	expr, err := Parse("result:error, state:modified")
+	require.NoError(t, err)
+	assert.Empty(t, Select(expr, &Context{Models: testModels()}))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{input: "tag:pii AND NOT tag:deprecated AND group:growth"},
+		{input: "stg_users, marts.fct_revenue"},
+		{input: "result:error+ state:modified+"},
+		{input: "+stg_users+"},
+		{input: "tag:pi", wantErr: `unknown tag "pi" in selector, did you mean "pii"?`},
+		{input: "tag:pii AND NOT tag:depracated", wantErr: `unknown tag "depracated" in selector, did you mean "deprecated"?`},
+		{input: "tag:nightly", wantErr: `unknown tag "nightly" in selector`},
+		{input: "group:fnance", wantErr: `unknown group "fnance" in selector, did you mean "finance"?`},
+		{input: "stg_user", wantErr: `unknown model "stg_user" in selector, did you mean "stg_users"?`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			require.NoError(t, err)
+
+			err = Validate(expr, testModels())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+// socketName is the file name of the daemon's socket, next to the state
+// database.
+const socketName = "daemon.sock"
+
+// dialTimeout bounds how long Dial waits for a daemon to answer, so clients
+// fall back to loading the project themselves without a noticeable delay.
+const dialTimeout = time.Second
+
+// SocketPath returns the path of the socket the daemon of a project listens
+// on, next to its state database: one daemon serves each state database.
+func SocketPath(statePath string) string {
+	return filepath.Join(filepath.Dir(statePath), socketName)
+}
+
+// Client calls a daemon over its socket.
+type Client struct {
+	http *http.Client
+}
+
+// Dial connects to the daemon listening on socket. It fails if no daemon
+// answers, in which case callers load the project themselves.
+func Dial(ctx context.Context, socket string) (*Client, error) {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	c := &Client{http: &http.Client{Transport: transport}}
+
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	var health map[string]string
+	if err := c.get(ctx, "/healthz", nil, &health); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("no daemon listening on %s: %w", socket, err)
+	}
+	return c, nil
+}
+
+// Close closes the client's idle connections.
+func (c *Client) Close() {
+	c.http.CloseIdleConnections()
+}
+
+// CatalogModel is a model of the daemon's catalog.
+type CatalogModel struct {
+	Path         string   `json:"path"`
+	Name         string   `json:"name"`
+	Materialized string   `json:"materialized"`
+	FilePath     string   `json:"file_path"`
+	DependsOn    []string `json:"depends_on"`
+}
+
+// Catalog returns the models of the daemon's project, rediscovered for the
+// call, sorted by path.
+func (c *Client) Catalog(ctx context.Context) ([]CatalogModel, error) {
+	var catalog struct {
+		Models []CatalogModel `json:"models"`
+	}
+	if err := c.get(ctx, "/v1/catalog", nil, &catalog); err != nil {
+		return nil, err
+	}
+	return catalog.Models, nil
+}
+
+// LintRequest selects the models and rules of a lint call.
+type LintRequest struct {
+	Models  []string // Paths of the models to lint (empty = all)
+	Disable []string // Rule IDs to disable
+	Rules   []string // Run only these rule IDs (empty = all)
+}
+
+// Lint lints models with the daemon's engine and project lint config, like
+// Engine.LintModels. Diagnostics have no fixes.
+func (c *Client) Lint(ctx context.Context, req LintRequest) ([]engine.ModelLint, error) {
+	query := url.Values{}
+	query["model"] = req.Models
+	query["disable"] = req.Disable
+	query["rule"] = req.Rules
+
+	var out struct {
+		Results []lintOutput `json:"results"`
+	}
+	if err := c.get(ctx, "/v1/lint", query, &out); err != nil {
+		return nil, err
+	}
+
+	results := make([]engine.ModelLint, 0, len(out.Results))
+	for _, res := range out.Results {
+		diags := make([]lint.Diagnostic, 0, len(res.Diagnostics))
+		for _, d := range res.Diagnostics {
+			sev, _ := core.ParseSeverity(d.Severity)
+			diag := lint.Diagnostic{
+				RuleID:   d.RuleID,
+				Severity: sev,
+				Message:  d.Message,
+				Pos:      token.Position{Line: d.Line, Column: d.Column},
+				EndPos:   token.Position{Line: d.EndLine, Column: d.EndColumn},
+			}
+			for _, rel := range d.Related {
+				diag.RelatedInfo = append(diag.RelatedInfo, lint.RelatedInfo{
+					FilePath: rel.FilePath,
+					Pos:      token.Position{Line: rel.Line, Column: rel.Column},
+					Message:  rel.Message,
+				})
+			}
+			diags = append(diags, diag)
+		}
+		results = append(results, engine.ModelLint{Model: res.Model, FilePath: res.FilePath, Diagnostics: diags, SQL: res.SQL, Source: res.Source})
+	}
+	return results, nil
+}
+
+// Lineage is the lineage of a model.
+type Lineage struct {
+	Model      string          `json:"model"`
+	Upstream   []string        `json:"upstream"`
+	Downstream []string        `json:"downstream"`
+	Columns    []LineageColumn `json:"columns"`
+}
+
+// LineageColumn is an output column of a model and the columns it comes
+// from.
+type LineageColumn struct {
+	Name      string          `json:"name"`
+	Transform string          `json:"transform,omitempty"`
+	Function  string          `json:"function,omitempty"`
+	Sources   []LineageSource `json:"sources"`
+}
+
+// LineageSource is a column of a table a model column comes from.
+type LineageSource struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+}
+
+// Lineage returns the upstream and downstream models of a model and the
+// lineage of its columns.
+func (c *Client) Lineage(ctx context.Context, model string) (*Lineage, error) {
+	var lineage Lineage
+	if err := c.get(ctx, "/v1/models/"+url.PathEscape(model)+"/lineage", nil, &lineage); err != nil {
+		return nil, err
+	}
+	return &lineage, nil
+}
+
+// Compile returns a model's SQL with its templates and macros rendered,
+// from its file as last saved.
+func (c *Client) Compile(ctx context.Context, model string) (string, error) {
+	var out struct {
+		SQL string `json:"sql"`
+	}
+	if err := c.get(ctx, "/v1/models/"+url.PathEscape(model)+"/compile", nil, &out); err != nil {
+		return "", err
+	}
+	return out.SQL, nil
+}
+
+// Preview runs a model's query without building it and returns at most
+// limit rows (the daemon's default if 0, capped at engine.MaxPreviewRows).
+// Cancelling ctx cancels the query.
+func (c *Client) Preview(ctx context.Context, model string, limit int) (*engine.QueryResult, error) {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var out previewOutput
+	if err := c.get(ctx, "/v1/models/"+url.PathEscape(model)+"/preview", query, &out); err != nil {
+		return nil, err
+	}
+	return &engine.QueryResult{Columns: out.Columns, Rows: out.Rows, Truncated: out.Truncated}, nil
+}
+
+// RunRequest selects the models of a run.
+type RunRequest struct {
+	Select      string // Selector of the models to run (empty = all)
+	Downstream  bool   // Also run the models downstream of the selected ones
+	FullRefresh bool   // Rebuild incremental models from scratch
+}
+
+// RunResult is the outcome of a run executed by the daemon.
+type RunResult struct {
+	ID         string
+	Status     string
+	DurationMS int64
+	Models     []ModelRunResult
+	// Error is the error the run failed with, if any
+	Error string
+}
+
+// ModelRunResult is the outcome of a model in a run.
+type ModelRunResult struct {
+	Model        string
+	Status       string
+	RowsAffected int64
+	ExecutionMS  int64
+	Error        string
+}
+
+// Run runs models with the daemon's engine and waits for the run to
+// complete. Runs that fail are returned with their error; an error is only
+// returned if the run could not be executed or followed.
+func (c *Client) Run(ctx context.Context, req RunRequest) (*RunResult, error) {
+	body, err := json.Marshal(runRequest{Select: req.Select, Downstream: req.Downstream, FullRefresh: req.FullRefresh})
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://daemon/v1/runs", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+
+	// Model runs are sent when they start and again when they complete
+	result := &RunResult{}
+	modelRuns := make(map[string]int)
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var ev runEvent
+		if err := dec.Decode(&ev); err != nil {
+			if errors.Is(err, io.EOF) {
+				return result, nil
+			}
+			return nil, fmt.Errorf("failed to read run events: %w", err)
+		}
+		switch {
+		case ev.Run != nil:
+			result.ID, result.Status, result.DurationMS = ev.Run.ID, ev.Run.Status, ev.Run.DurationMS
+			if ev.Run.Error != "" {
+				result.Error = ev.Run.Error
+			}
+		case ev.ModelRun != nil:
+			mr := ev.ModelRun
+			out := ModelRunResult{
+				Model:        mr.Model,
+				Status:       mr.Status,
+				RowsAffected: mr.RowsAffected,
+				ExecutionMS:  mr.ExecutionMS,
+				Error:        mr.Error,
+			}
+			if i, ok := modelRuns[mr.ID]; ok {
+				result.Models[i] = out
+			} else {
+				modelRuns[mr.ID] = len(result.Models)
+				result.Models = append(result.Models, out)
+			}
+		case ev.Event == "error":
+			result.Error = ev.Error
+		}
+	}
+}
+
+// get decodes the JSON response of a GET request to the daemon, returning
+// the error the daemon reports for unsuccessful requests.
+func (c *Client) get(ctx context.Context, path string, query url.Values, v any) error {
+	u := url.URL{Scheme: "http", Host: "daemon", Path: path, RawQuery: query.Encode()}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// responseError returns the error the daemon reports in an unsuccessful
+// response.
+func responseError(resp *http.Response) error {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error == "" {
+		return fmt.Errorf("daemon returned %s", resp.Status)
+	}
+	return fmt.Errorf("daemon: %s", body.Error)
+}
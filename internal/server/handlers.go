@@ -0,0 +1,710 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+)
+
+// runOutput is the JSON representation of a run.
+type runOutput struct {
+	ID          string     `json:"id"`
+	Environment string     `json:"environment"`
+	Status      string     `json:"status"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	DurationMS  int64      `json:"duration_ms,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// modelRunOutput is the JSON representation of a model run.
+type modelRunOutput struct {
+	ID           string     `json:"id"`
+	RunID        string     `json:"run_id"`
+	Model        string     `json:"model"`
+	Status       string     `json:"status"`
+	RowsAffected int64      `json:"rows_affected"`
+	StartedAt    time.Time  `json:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	RenderMS     int64      `json:"render_ms"`
+	ExecutionMS  int64      `json:"execution_ms"`
+	BuildMode    string     `json:"build_mode,omitempty"`
+	RowFilter    string     `json:"row_filter,omitempty"`
+}
+
+// runEvent is one line of a streamed run.
+type runEvent struct {
+	Event    string          `json:"event"` // run_started, model_run, run_completed or error
+	Run      *runOutput      `json:"run,omitempty"`
+	ModelRun *modelRunOutput `json:"model_run,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// sourceOutput is the JSON representation of a column lineage source.
+type sourceOutput struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+}
+
+// columnOutput is the JSON representation of a model column.
+type columnOutput struct {
+	Name      string         `json:"name"`
+	Transform string         `json:"transform,omitempty"`
+	Function  string         `json:"function,omitempty"`
+	Sources   []sourceOutput `json:"sources"`
+}
+
+// modelOutput is the JSON representation of a catalog model.
+type modelOutput struct {
+	Path         string           `json:"path"`
+	Name         string           `json:"name"`
+	Materialized string           `json:"materialized"`
+	Schema       string           `json:"schema,omitempty"`
+	Owner        string           `json:"owner,omitempty"`
+	Group        string           `json:"group,omitempty"`
+	Description  string           `json:"description,omitempty"`
+	PartitionBy  *partitionOutput `json:"partition_by,omitempty"`
+	Tags         []string         `json:"tags"`
+	FilePath     string           `json:"file_path"`
+	DependsOn    []string         `json:"depends_on"`
+	Columns      []columnOutput   `json:"columns"`
+}
+
+// partitionOutput is the JSON representation of a model's partition_by.
+type partitionOutput struct {
+	Column string `json:"column"`
+	Grain  string `json:"grain"`
+}
+
+// lineageOutput is the JSON representation of a model's lineage.
+type lineageOutput struct {
+	Model      string         `json:"model"`
+	Upstream   []string       `json:"upstream"`
+	Downstream []string       `json:"downstream"`
+	Columns    []columnOutput `json:"columns"`
+}
+
+// diagnosticOutput is the JSON representation of a lint diagnostic.
+type diagnosticOutput struct {
+	RuleID   string `json:"rule_id"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	// EndLine and EndColumn end the diagnostic's range, if it has one
+	EndLine   int `json:"end_line,omitempty"`
+	EndColumn int `json:"end_column,omitempty"`
+	// Related are secondary locations of the diagnostic
+	Related []relatedOutput `json:"related,omitempty"`
+}
+
+// relatedOutput is the JSON representation of a related location of a
+// diagnostic. FilePath is empty for the diagnostic's own file.
+type relatedOutput struct {
+	FilePath string `json:"file_path,omitempty"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Message  string `json:"message"`
+}
+
+// lintOutput is the JSON representation of a model's lint diagnostics.
+type lintOutput struct {
+	Model       string             `json:"model"`
+	FilePath    string             `json:"file_path"`
+	Diagnostics []diagnosticOutput `json:"diagnostics"`
+	// SQL is the rendered SQL fix edits refer to
+	SQL string `json:"sql"`
+	// Source is the content diagnostic positions refer to
+	Source string `json:"source"`
+}
+
+// dagNodeOutput is the JSON representation of a DAG node, with the status of
+// its model run in the run the DAG is shown for.
+type dagNodeOutput struct {
+	Path         string `json:"path"`
+	Materialized string `json:"materialized,omitempty"`
+	Level        int    `json:"level"`
+	Status       string `json:"status,omitempty"`
+	ExecutionMS  int64  `json:"execution_ms,omitempty"`
+}
+
+// dagEdgeOutput is the JSON representation of a DAG edge.
+type dagEdgeOutput struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// dagOutput is the JSON representation of the DAG.
+type dagOutput struct {
+	Run   string          `json:"run,omitempty"`
+	Nodes []dagNodeOutput `json:"nodes"`
+	Edges []dagEdgeOutput `json:"edges"`
+}
+
+// defaultRunsLimit is the number of runs listed when no limit is given.
+const defaultRunsLimit = 20
+
+// defaultPreviewLimit is the number of rows previewed when no limit is given.
+const defaultPreviewLimit = 100
+
+// previewOutput is the JSON representation of the rows of a model preview.
+type previewOutput struct {
+	Model   string   `json:"model"`
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+	// Truncated tells that the model's query has more rows than the limit
+	Truncated bool `json:"truncated"`
+}
+
+// runRequest is the body of a run request.
+type runRequest struct {
+	Select      string `json:"select"`
+	Downstream  bool   `json:"downstream"`
+	FullRefresh bool   `json:"full_refresh"`
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleCatalog(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.discover(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	graph := s.engine.GetGraph()
+	models := make([]modelOutput, 0, len(s.engine.GetModels()))
+	for _, m := range s.engine.GetModels() {
+		dependsOn := graph.GetParents(m.Path)
+		sort.Strings(dependsOn)
+		models = append(models, modelOutput{
+			Path:         m.Path,
+			Name:         m.Name,
+			Materialized: m.Materialized,
+			Schema:       m.Schema,
+			Owner:        m.Owner,
+			Group:        m.Group,
+			Description:  m.Description,
+			PartitionBy:  toPartitionOutput(m.PartitionBy),
+			Tags:         nonNil(m.Tags),
+			FilePath:     m.FilePath,
+			DependsOn:    nonNil(dependsOn),
+			Columns:      toColumnOutputs(m.Columns),
+		})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Path < models[j].Path })
+
+	writeJSON(w, http.StatusOK, map[string]any{"models": models})
+}
+
+// handleDAG returns the DAG in execution levels, with the statuses of the
+// models in a run: the run query parameter, or the latest run.
+func (s *Server) handleDAG(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.discover(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	graph := s.engine.GetGraph()
+	levels, err := graph.GetExecutionLevels()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	store := s.engine.GetStateStore()
+	runID := r.URL.Query().Get("run")
+	if runID == "" {
+		if runs, err := store.ListRuns(1); err == nil && len(runs) > 0 {
+			runID = runs[0].ID
+		}
+	}
+	modelRuns := make(map[string]*core.ModelRunWithInfo)
+	if runID != "" {
+		runs, err := store.GetModelRunsWithModelInfo(runID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		for _, mr := range runs {
+			modelRuns[mr.ModelPath] = mr
+		}
+	}
+
+	out := dagOutput{Run: runID, Nodes: []dagNodeOutput{}, Edges: []dagEdgeOutput{}}
+	for level, paths := range levels {
+		sort.Strings(paths)
+		for _, path := range paths {
+			node := dagNodeOutput{Path: path, Level: level}
+			if m, ok := s.engine.GetModels()[path]; ok {
+				node.Materialized = m.Materialized
+			}
+			if mr, ok := modelRuns[path]; ok {
+				node.Status = string(mr.Status)
+				node.ExecutionMS = mr.ExecutionMS
+			}
+			out.Nodes = append(out.Nodes, node)
+
+			parents := graph.GetParents(path)
+			sort.Strings(parents)
+			for _, parent := range parents {
+				out.Edges = append(out.Edges, dagEdgeOutput{From: parent, To: path})
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleCompile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.discover(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	m, ok := s.model(w, r)
+	if !ok {
+		return
+	}
+
+	sql, err := s.engine.RenderModel(m.Path)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"model": m.Path, "sql": sql})
+}
+
+func (s *Server) handleLineage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.discover(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	m, ok := s.model(w, r)
+	if !ok {
+		return
+	}
+
+	graph := s.engine.GetGraph()
+	downstream := make([]string, 0)
+	for _, id := range graph.GetAffectedNodes([]string{m.Path}) {
+		if id != m.Path {
+			downstream = append(downstream, id)
+		}
+	}
+	writeJSON(w, http.StatusOK, lineageOutput{
+		Model:      m.Path,
+		Upstream:   nonNil(graph.GetUpstreamNodes(m.Path)),
+		Downstream: downstream,
+		Columns:    toColumnOutputs(m.Columns),
+	})
+}
+
+// handlePreview runs a model's query without building it and returns its
+// first rows: the limit query parameter, or 100, capped at
+// engine.MaxPreviewRows. The query is cancelled if the client goes away.
+func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
+	limit := defaultPreviewLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %s", v))
+			return
+		}
+		limit = n
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.discover(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	m, ok := s.model(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := s.engine.PreviewModel(r.Context(), m.Path, limit)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, previewOutput{Model: m.Path, Columns: result.Columns, Rows: result.Rows, Truncated: result.Truncated})
+}
+
+// relatedOutputs converts the related locations of a diagnostic.
+func relatedOutputs(related []lint.RelatedInfo) []relatedOutput {
+	out := make([]relatedOutput, 0, len(related))
+	for _, r := range related {
+		out = append(out, relatedOutput{FilePath: r.FilePath, Line: r.Pos.Line, Column: r.Pos.Column, Message: r.Message})
+	}
+	return out
+}
+
+// handleLint lints the models named by the model query parameters, or all
+// models. Like the lint command's flags, disable parameters disable rules
+// and rule parameters run only the rules they name.
+func (s *Server) handleLint(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.discover(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	query := r.URL.Query()
+	lintable := s.engine.LintableModels()
+	models := make([]*core.Model, 0, len(lintable))
+	if paths := query["model"]; len(paths) > 0 {
+		for _, path := range paths {
+			m, ok := lintable[path]
+			if !ok {
+				writeError(w, http.StatusNotFound, fmt.Errorf("model not found: %s", path))
+				return
+			}
+			models = append(models, m)
+		}
+	} else {
+		for _, m := range lintable {
+			models = append(models, m)
+		}
+	}
+
+	lintCfg := lint.NewConfig()
+	if err := lintCfg.ApplyProject(s.lint); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("invalid lint config: %w", err))
+		return
+	}
+	for _, id := range query["disable"] {
+		lintCfg.Disable(strings.TrimSpace(id))
+	}
+	if rules := query["rule"]; len(rules) > 0 {
+		for i := range rules {
+			rules[i] = strings.TrimSpace(rules[i])
+		}
+		for _, rule := range lint.GetAllSQLRules() {
+			if !slices.Contains(rules, rule.ID()) {
+				lintCfg.Disable(rule.ID())
+			}
+		}
+	}
+
+	linted, err := s.engine.LintModels(r.Context(), models, lintCfg)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	results := make([]lintOutput, 0, len(linted))
+	for _, l := range linted {
+		diags := make([]diagnosticOutput, 0, len(l.Diagnostics))
+		for _, d := range l.Diagnostics {
+			diags = append(diags, diagnosticOutput{
+				RuleID:    d.RuleID,
+				Severity:  d.Severity.String(),
+				Message:   d.Message,
+				Line:      d.Pos.Line,
+				Column:    d.Pos.Column,
+				EndLine:   d.EndPos.Line,
+				EndColumn: d.EndPos.Column,
+				Related:   relatedOutputs(d.RelatedInfo),
+			})
+		}
+		results = append(results, lintOutput{Model: l.Model, FilePath: l.FilePath, Diagnostics: diags, SQL: l.SQL, Source: l.Source})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"results": results})
+}
+
+// handleRun runs models and streams the run's events as newline-delimited
+// JSON while it executes. The stream ends with a run_completed event, followed
+// by an error event if the run did not succeed. Disconnecting cancels the run.
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	var req runRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid run request: %w", err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx := r.Context()
+	if err := s.engine.LoadSeeds(ctx); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to load seeds: %w", err))
+		return
+	}
+	if err := s.discover(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	var selected []string
+	if req.Select != "" {
+		var err error
+		selected, err = s.engine.SelectModels(req.Select)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid select: %w", err))
+			return
+		}
+		if len(selected) == 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("no models match select %q", req.Select))
+			return
+		}
+	}
+	s.engine.SetFullRefresh(req.FullRefresh)
+
+	stream := &eventStream{w: w}
+	detach := s.events.attach(stream.write)
+	var err error
+	if selected != nil {
+		_, err = s.engine.RunSelected(ctx, s.env, selected, req.Downstream)
+	} else {
+		_, err = s.engine.Run(ctx, s.env)
+	}
+	detach()
+
+	switch {
+	case err == nil:
+	case !stream.started:
+		// The run was never recorded, e.g. the state lock is held
+		status := http.StatusInternalServerError
+		if errors.Is(err, core.ErrStateLocked) {
+			status = http.StatusConflict
+		}
+		writeError(w, status, err)
+	default:
+		stream.write(runEvent{Event: "error", Error: err.Error()})
+	}
+}
+
+// handleEvents streams the events of every run the daemon executes as
+// newline-delimited JSON, until the client disconnects. Events are dropped
+// for clients that do not keep up.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	events := make(chan runEvent, 256)
+	detach := s.events.attach(func(ev runEvent) {
+		select {
+		case events <- ev:
+		default:
+		}
+	})
+	defer detach()
+
+	stream := &eventStream{w: w}
+	stream.start()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-events:
+			stream.write(ev)
+		}
+	}
+}
+
+// handleListRuns returns the latest runs, newest first. The limit query
+// parameter sets how many (default 20).
+func (s *Server) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	limit := defaultRunsLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %s", v))
+			return
+		}
+		limit = n
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs, err := s.engine.GetStateStore().ListRuns(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := make([]*runOutput, 0, len(runs))
+	for _, run := range runs {
+		out = append(out, toRunOutput(run))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"runs": out})
+}
+
+func (s *Server) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	store := s.engine.GetStateStore()
+	run, err := store.GetRun(chi.URLParam(r, "id"))
+	if err != nil || run == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("run not found: %s", chi.URLParam(r, "id")))
+		return
+	}
+	modelRuns, err := store.GetModelRunsWithModelInfo(run.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	models := make([]*modelRunOutput, 0, len(modelRuns))
+	for _, mr := range modelRuns {
+		models = append(models, toModelRunOutput(&mr.ModelRun, mr.ModelPath))
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"run": toRunOutput(run), "models": models})
+}
+
+// handleModelLogs returns the log records of a model captured during a run.
+// Logs are only kept in memory for the latest runs executed by the daemon.
+func (s *Server) handleModelLogs(w http.ResponseWriter, r *http.Request) {
+	runID, model := chi.URLParam(r, "id"), chi.URLParam(r, "model")
+	logs := []logLine{}
+	if s.logs != nil {
+		logs = s.logs.modelLogs(runID, model)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"run": runID, "model": model, "logs": logs})
+}
+
+// discover rediscovers the project's models, picking up edited files.
+func (s *Server) discover() error {
+	if _, err := s.engine.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+	return nil
+}
+
+// model returns the model named by the request's model parameter, writing a
+// not found response if there is none.
+func (s *Server) model(w http.ResponseWriter, r *http.Request) (*core.Model, bool) {
+	path := chi.URLParam(r, "model")
+	m, ok := s.engine.GetModels()[path]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("model not found: %s", path))
+	}
+	return m, ok
+}
+
+// eventStream writes run events as newline-delimited JSON, flushing each.
+type eventStream struct {
+	w       http.ResponseWriter
+	started bool
+}
+
+// start writes the stream's headers, if not written yet.
+func (s *eventStream) start() {
+	if s.started {
+		return
+	}
+	s.w.Header().Set("Content-Type", "application/x-ndjson")
+	s.w.WriteHeader(http.StatusOK)
+	_ = http.NewResponseController(s.w).Flush()
+	s.started = true
+}
+
+func (s *eventStream) write(ev runEvent) {
+	s.start()
+	// Write errors mean the client is gone; the request context cancels the run
+	_ = json.NewEncoder(s.w).Encode(ev)
+	_ = http.NewResponseController(s.w).Flush()
+}
+
+func toRunOutput(run *core.Run) *runOutput {
+	out := &runOutput{
+		ID:          run.ID,
+		Environment: run.Environment,
+		Status:      string(run.Status),
+		StartedAt:   run.StartedAt,
+		CompletedAt: run.CompletedAt,
+		Error:       run.Error,
+	}
+	if run.CompletedAt != nil {
+		out.DurationMS = run.CompletedAt.Sub(run.StartedAt).Milliseconds()
+	}
+	return out
+}
+
+func toModelRunOutput(mr *core.ModelRun, model string) *modelRunOutput {
+	return &modelRunOutput{
+		ID:           mr.ID,
+		RunID:        mr.RunID,
+		Model:        model,
+		Status:       string(mr.Status),
+		RowsAffected: mr.RowsAffected,
+		StartedAt:    mr.StartedAt,
+		CompletedAt:  mr.CompletedAt,
+		Error:        mr.Error,
+		RenderMS:     mr.RenderMS,
+		ExecutionMS:  mr.ExecutionMS,
+		BuildMode:    string(mr.BuildMode),
+		RowFilter:    mr.RowFilter,
+	}
+}
+
+// toPartitionOutput returns the JSON representation of a partition spec, nil
+// for models that are not partitioned.
+func toPartitionOutput(p *core.PartitionSpec) *partitionOutput {
+	if p == nil {
+		return nil
+	}
+	return &partitionOutput{Column: p.Column, Grain: string(p.Grain)}
+}
+
+func toColumnOutputs(cols []core.ColumnInfo) []columnOutput {
+	out := make([]columnOutput, 0, len(cols))
+	for _, c := range cols {
+		sources := make([]sourceOutput, 0, len(c.Sources))
+		for _, src := range c.Sources {
+			sources = append(sources, sourceOutput{Table: src.Table, Column: src.Column})
+		}
+		out = append(out, columnOutput{
+			Name:      c.Name,
+			Transform: string(c.TransformType),
+			Function:  c.Function,
+			Sources:   sources,
+		})
+	}
+	return out
+}
+
+// nonNil returns s, or an empty slice if s is nil, so it encodes as [].
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
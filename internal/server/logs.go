@@ -0,0 +1,135 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Limits of the logs kept by a LogCapture.
+const (
+	maxLogRuns      = 20   // Runs whose logs are kept, oldest dropped first
+	maxLogsPerModel = 1000 // Records kept per model and run
+)
+
+// LogCapture is a slog.Handler keeping the log records of each model during
+// the daemon's runs, so the UI can show per-model logs. Records are attributed
+// to the model in their "model" or "model_path" attribute. All records are
+// passed on to the wrapped handler, which decides what it prints.
+type LogCapture struct {
+	next  slog.Handler
+	attrs []slog.Attr
+	logs  *runLogs
+}
+
+// NewLogCapture creates a handler capturing model logs and passing all records
+// on to next.
+func NewLogCapture(next slog.Handler) *LogCapture {
+	return &LogCapture{next: next, logs: &runLogs{models: make(map[string]map[string][]logLine)}}
+}
+
+// logLine is the JSON representation of a captured log record.
+type logLine struct {
+	Time    time.Time         `json:"time"`
+	Level   string            `json:"level"`
+	Message string            `json:"message"`
+	Attrs   map[string]string `json:"attrs,omitempty"`
+}
+
+// runLogs holds the captured records of the latest runs, by run and model.
+type runLogs struct {
+	mu      sync.Mutex
+	current string   // Run being captured ("" between runs)
+	runs    []string // Runs with logs, oldest first
+	models  map[string]map[string][]logLine
+}
+
+// Enabled reports true for all levels: debug records of models are captured
+// even when the wrapped handler does not print them.
+func (h *LogCapture) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle captures a record of a model and passes it on to the wrapped handler.
+func (h *LogCapture) Handle(ctx context.Context, r slog.Record) error {
+	h.capture(r)
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a handler adding attrs to the records it handles.
+func (h *LogCapture) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogCapture{next: h.next.WithAttrs(attrs), attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...), logs: h.logs}
+}
+
+// WithGroup returns a handler qualifying the attributes of its records with
+// a group.
+func (h *LogCapture) WithGroup(name string) slog.Handler {
+	return &LogCapture{next: h.next.WithGroup(name), attrs: h.attrs, logs: h.logs}
+}
+
+func (h *LogCapture) capture(r slog.Record) {
+	attrs := make(map[string]string)
+	for _, a := range h.attrs {
+		attrs[a.Key] = a.Value.String()
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.String()
+		return true
+	})
+
+	model := attrs["model"]
+	if model == "" {
+		model = attrs["model_path"]
+	}
+	if model == "" {
+		return
+	}
+	delete(attrs, "model")
+	delete(attrs, "model_path")
+
+	h.logs.add(model, logLine{Time: r.Time, Level: r.Level.String(), Message: r.Message, Attrs: attrs})
+}
+
+// startRun attributes the records captured from now on to a run, or to no
+// run if runID is empty.
+func (h *LogCapture) startRun(runID string) {
+	l := h.logs
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.current = runID
+	if runID == "" {
+		return
+	}
+	l.runs = append(l.runs, runID)
+	l.models[runID] = make(map[string][]logLine)
+	if len(l.runs) > maxLogRuns {
+		delete(l.models, l.runs[0])
+		l.runs = l.runs[1:]
+	}
+}
+
+// modelLogs returns the records of a model captured during a run.
+func (h *LogCapture) modelLogs(runID, model string) []logLine {
+	l := h.logs
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]logLine{}, l.models[runID][model]...)
+}
+
+func (l *runLogs) add(model string, line logLine) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.current == "" {
+		return
+	}
+	lines := l.models[l.current]
+	if len(lines[model]) < maxLogsPerModel {
+		lines[model] = append(lines[model], line)
+	}
+}
@@ -0,0 +1,296 @@
+// Package server implements the LeapSQL daemon: a long-running HTTP server
+// exposing compile, lint, run, lineage and catalog endpoints. Orchestrators
+// and web UIs drive a warm engine through it instead of starting the CLI for
+// every call. The daemon also hosts a web UI monitoring runs, built on the
+// same endpoints.
+//
+// The daemon also serves the same endpoints on a Unix socket next to the
+// project's state database. The CLI and the language server connect to it
+// through a Client, reusing its discovered models, parse caches and state
+// handle instead of loading the project on every start.
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultAddr is the address the daemon listens on by default. It only
+// accepts local connections: expose it deliberately, with a token.
+const DefaultAddr = "127.0.0.1:8766"
+
+// Config holds configuration for the daemon.
+type Config struct {
+	// Engine serves the requests. The server takes over its run observer.
+	Engine *engine.Engine
+	// Addr is the address to listen on (default: DefaultAddr)
+	Addr string
+	// Environment is the environment runs are recorded in
+	Environment string
+	// Lint is the lint section of the project config (optional)
+	Lint *core.LintConfig
+	// Token is the bearer token clients must send (optional, no auth if empty)
+	Token string
+	// Logs captures the engine's log records per model for the run logs
+	// endpoint (optional, no logs if nil). The engine must log through it.
+	Logs *LogCapture
+	// Logger is the structured logger (optional, uses discard if nil)
+	Logger *slog.Logger
+	// Socket is the path of a Unix socket the daemon also listens on, for
+	// the CLI and language server of the same project (optional, see
+	// SocketPath). Requests over the socket need no token: only the user
+	// owning it can connect.
+	Socket string
+}
+
+// Server is the LeapSQL daemon. The engine is not safe for concurrent use,
+// so requests using it are served one at a time; a run holds the engine until
+// it completes.
+type Server struct {
+	mu     sync.Mutex // Serializes engine access
+	engine *engine.Engine
+	addr   string
+	socket string
+	env    string
+	lint   *core.LintConfig
+	token  string
+	logger *slog.Logger
+	events *runEvents
+	logs   *LogCapture
+}
+
+// New creates a daemon serving the engine's project.
+func New(cfg Config) *Server {
+	addr := cfg.Addr
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	events := &runEvents{store: cfg.Engine.GetStateStore(), logs: cfg.Logs}
+	cfg.Engine.SetRunObserver(events)
+
+	return &Server{
+		engine: cfg.Engine,
+		addr:   addr,
+		socket: cfg.Socket,
+		env:    cfg.Environment,
+		lint:   cfg.Lint,
+		token:  cfg.Token,
+		logger: logger,
+		events: events,
+		logs:   cfg.Logs,
+	}
+}
+
+// Handler returns the daemon's HTTP handler.
+func (s *Server) Handler() http.Handler {
+	r := chi.NewMux()
+	r.Use(middleware.Recoverer)
+
+	r.Get("/", s.handleUI)
+	r.Get("/healthz", s.handleHealth)
+	r.Route("/v1", func(r chi.Router) {
+		r.Use(s.authenticate)
+		r.Get("/catalog", s.handleCatalog)
+		r.Get("/dag", s.handleDAG)
+		r.Get("/models/{model}/compile", s.handleCompile)
+		r.Get("/models/{model}/lineage", s.handleLineage)
+		r.Get("/models/{model}/preview", s.handlePreview)
+		r.Get("/lint", s.handleLint)
+		r.Get("/events", s.handleEvents)
+		r.Get("/runs", s.handleListRuns)
+		r.Post("/runs", s.handleRun)
+		r.Get("/runs/{id}", s.handleGetRun)
+		r.Get("/runs/{id}/models/{model}/logs", s.handleModelLogs)
+	})
+	return r
+}
+
+// Serve starts the daemon and blocks until the context is cancelled.
+func (s *Server) Serve(ctx context.Context) error {
+	s.logger.Info("starting daemon", "addr", s.addr)
+
+	eg, egctx := errgroup.WithContext(ctx)
+
+	srv := &http.Server{
+		Addr:    s.addr,
+		Handler: s.Handler(),
+		BaseContext: func(_ net.Listener) context.Context {
+			return egctx
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	eg.Go(func() error {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			return fmt.Errorf("server error: %w", err)
+		}
+		return nil
+	})
+
+	// Local clients connect over the socket; their connections are marked
+	// so they skip token authentication
+	var socketSrv *http.Server
+	if s.socket != "" {
+		ln, err := listenSocket(ctx, s.socket)
+		if err != nil {
+			return err
+		}
+		s.logger.Info("listening on socket", "path", s.socket)
+		socketSrv = &http.Server{
+			Handler: s.Handler(),
+			BaseContext: func(_ net.Listener) context.Context {
+				return egctx
+			},
+			ConnContext: func(ctx context.Context, _ net.Conn) context.Context {
+				return context.WithValue(ctx, socketConnKey{}, true)
+			},
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		eg.Go(func() error {
+			defer func() { _ = os.Remove(s.socket) }()
+			if err := socketSrv.Serve(ln); err != nil && err != http.ErrServerClosed {
+				return fmt.Errorf("socket server error: %w", err)
+			}
+			return nil
+		})
+	}
+
+	// Graceful shutdown: in-flight runs see their request context cancelled
+	// and are recorded as cancelled
+	eg.Go(func() error {
+		<-egctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		s.logger.Debug("shutting down daemon...")
+		if socketSrv != nil {
+			_ = socketSrv.Shutdown(shutdownCtx)
+		}
+		return srv.Shutdown(shutdownCtx)
+	})
+
+	return eg.Wait()
+}
+
+// socketConnKey marks the contexts of requests received over the socket.
+type socketConnKey struct{}
+
+// listenSocket listens on a Unix socket only its owner can connect to. A
+// socket left behind by a daemon that did not shut down is replaced; one a
+// running daemon answers on is not.
+func listenSocket(ctx context.Context, path string) (net.Listener, error) {
+	if client, err := Dial(ctx, path); err == nil {
+		client.Close()
+		return nil, fmt.Errorf("a daemon is already listening on %s", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on socket: %w", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	return ln, nil
+}
+
+// authenticate rejects requests without the configured bearer token, except
+// those received over the socket.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" && r.Context().Value(socketConnKey{}) == nil {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+				writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// runEvents implements engine.RunObserver, forwarding run events to the
+// request streaming the current run and to clients following all runs.
+type runEvents struct {
+	store  core.Store
+	logs   *LogCapture
+	mu     sync.Mutex
+	nextID int
+	sinks  map[int]func(runEvent)
+}
+
+// attach forwards events to sink until the returned function is called.
+func (e *runEvents) attach(sink func(runEvent)) func() {
+	e.mu.Lock()
+	if e.sinks == nil {
+		e.sinks = make(map[int]func(runEvent))
+	}
+	id := e.nextID
+	e.nextID++
+	e.sinks[id] = sink
+	e.mu.Unlock()
+	return func() {
+		e.mu.Lock()
+		delete(e.sinks, id)
+		e.mu.Unlock()
+	}
+}
+
+func (e *runEvents) send(ev runEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, sink := range e.sinks {
+		sink(ev)
+	}
+}
+
+// OnRunStarted is called when a run starts.
+func (e *runEvents) OnRunStarted(run *core.Run) {
+	if e.logs != nil {
+		e.logs.startRun(run.ID)
+	}
+	e.send(runEvent{Event: "run_started", Run: toRunOutput(run)})
+}
+
+// OnModelRunUpdated is called when a model run status changes.
+func (e *runEvents) OnModelRunUpdated(_ string, modelRun *core.ModelRun) {
+	path := modelRun.ModelID
+	if m, err := e.store.GetModelByID(modelRun.ModelID); err == nil && m != nil {
+		path = m.Path
+	}
+	e.send(runEvent{Event: "model_run", ModelRun: toModelRunOutput(modelRun, path)})
+}
+
+// OnRunCompleted is called when a run completes.
+func (e *runEvents) OnRunCompleted(run *core.Run) {
+	if e.logs != nil {
+		e.logs.startRun("")
+	}
+	e.send(runEvent{Event: "run_completed", Run: toRunOutput(run)})
+}
@@ -0,0 +1,394 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+	"github.com/leapstack-labs/leapsql/internal/testutil"
+	_ "github.com/leapstack-labs/leapsql/pkg/adapters/duckdb"
+	_ "github.com/leapstack-labs/leapsql/pkg/lint/sql/rules"
+)
+
+// newTestServer serves a project with a seed and two chained models.
+func newTestServer(t *testing.T, token string) *httptest.Server {
+	t.Helper()
+	eng, logs := newTestEngine(t)
+	srv := httptest.NewServer(New(Config{Engine: eng, Environment: "test", Token: token, Logs: logs}).Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestEngine creates the engine of a project with a seed and two chained
+// models.
+func newTestEngine(t *testing.T) (*engine.Engine, *LogCapture) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	modelsDir := filepath.Join(tmpDir, "models")
+	seedsDir := filepath.Join(tmpDir, "seeds")
+
+	files := map[string]string{
+		"seeds/users.csv":              "id,name\n1,alice\n2,bob\n",
+		"models/staging/stg_users.sql": "/*---\nmaterialized: table\ntags: [daily]\n---*/\nSELECT id, name FROM users",
+		"models/marts/user_names.sql":  "/*---\nmaterialized: table\n---*/\nSELECT name FROM staging.stg_users WHERE name = NULL",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	logs := NewLogCapture(testutil.NewTestLogger(t).Handler())
+	eng, err := engine.New(engine.Config{
+		ModelsDir: modelsDir,
+		SeedsDir:  seedsDir,
+		StatePath: filepath.Join(tmpDir, "state.db"),
+		Target:    &starctx.TargetInfo{Type: "duckdb", Schema: "main"},
+		Logger:    slog.New(logs),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = eng.Close() })
+	return eng, logs
+}
+
+// getJSON decodes the JSON response of a GET request.
+func getJSON(t *testing.T, url string, v any) int {
+	t.Helper()
+	resp, err := http.Get(url) //nolint:gosec,noctx // test server URL
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	return resp.StatusCode
+}
+
+func TestServer_Catalog(t *testing.T) {
+	srv := newTestServer(t, "")
+
+	var catalog struct {
+		Models []modelOutput `json:"models"`
+	}
+	require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/v1/catalog", &catalog))
+	require.Len(t, catalog.Models, 2)
+
+	marts := catalog.Models[0]
+	assert.Equal(t, "marts.user_names", marts.Path)
+	assert.Equal(t, "table", marts.Materialized)
+	assert.Equal(t, []string{"staging.stg_users"}, marts.DependsOn)
+	require.Len(t, marts.Columns, 1)
+	assert.Equal(t, "name", marts.Columns[0].Name)
+	assert.Equal(t, []string{"daily"}, catalog.Models[1].Tags)
+}
+
+func TestServer_Compile(t *testing.T) {
+	srv := newTestServer(t, "")
+
+	var compiled map[string]string
+	require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/v1/models/staging.stg_users/compile", &compiled))
+	assert.Equal(t, "staging.stg_users", compiled["model"])
+	assert.Contains(t, compiled["sql"], "FROM users")
+
+	var missing map[string]string
+	assert.Equal(t, http.StatusNotFound, getJSON(t, srv.URL+"/v1/models/staging.missing/compile", &missing))
+	assert.Contains(t, missing["error"], "model not found")
+}
+
+func TestServer_Lineage(t *testing.T) {
+	srv := newTestServer(t, "")
+
+	var lineage lineageOutput
+	require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/v1/models/staging.stg_users/lineage", &lineage))
+	assert.Equal(t, []string{"marts.user_names"}, lineage.Downstream)
+	assert.Len(t, lineage.Columns, 2)
+}
+
+func TestServer_Preview(t *testing.T) {
+	srv := newTestServer(t, "")
+
+	// Seeds are not loaded: the model's query fails
+	var failed map[string]string
+	assert.Equal(t, http.StatusUnprocessableEntity, getJSON(t, srv.URL+"/v1/models/staging.stg_users/preview", &failed))
+	assert.Contains(t, failed["error"], "users")
+
+	var invalid map[string]string
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, srv.URL+"/v1/models/staging.stg_users/preview?limit=0", &invalid))
+	assert.Contains(t, invalid["error"], "invalid limit")
+
+	var missing map[string]string
+	assert.Equal(t, http.StatusNotFound, getJSON(t, srv.URL+"/v1/models/staging.missing/preview", &missing))
+}
+
+func TestServer_Lint(t *testing.T) {
+	srv := newTestServer(t, "")
+
+	var lint struct {
+		Results []lintOutput `json:"results"`
+	}
+	require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/v1/lint", &lint))
+	require.Len(t, lint.Results, 1)
+	assert.Equal(t, "marts.user_names", lint.Results[0].Model)
+
+	var rules []string
+	for _, d := range lint.Results[0].Diagnostics {
+		rules = append(rules, d.RuleID)
+	}
+	assert.Contains(t, rules, "CV05")
+}
+
+func TestServer_Run(t *testing.T) {
+	srv := newTestServer(t, "")
+
+	t.Run("streams run events", func(t *testing.T) {
+		resp, err := http.Post(srv.URL+"/v1/runs", "application/json", strings.NewReader(`{"select": "tag:daily", "downstream": true}`)) //nolint:noctx // test
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+		var events []runEvent
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var ev runEvent
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev))
+			events = append(events, ev)
+		}
+		require.NotEmpty(t, events)
+
+		assert.Equal(t, "run_started", events[0].Event)
+		last := events[len(events)-1]
+		assert.Equal(t, "run_completed", last.Event)
+		assert.Equal(t, "completed", last.Run.Status)
+		assert.Equal(t, "test", last.Run.Environment)
+
+		succeeded := make(map[string]bool)
+		for _, ev := range events {
+			if ev.Event == "model_run" && ev.ModelRun.Status == "success" {
+				succeeded[ev.ModelRun.Model] = true
+			}
+		}
+		assert.Equal(t, map[string]bool{"staging.stg_users": true, "marts.user_names": true}, succeeded)
+
+		var run struct {
+			Run    runOutput        `json:"run"`
+			Models []modelRunOutput `json:"models"`
+		}
+		require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/v1/runs/"+last.Run.ID, &run))
+		assert.Equal(t, "completed", run.Run.Status)
+		assert.Len(t, run.Models, 2)
+	})
+
+	t.Run("rejects invalid selectors", func(t *testing.T) {
+		resp, err := http.Post(srv.URL+"/v1/runs", "application/json", strings.NewReader(`{"select": "tag:missing"}`)) //nolint:noctx // test
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("unknown run", func(t *testing.T) {
+		var body map[string]string
+		assert.Equal(t, http.StatusNotFound, getJSON(t, srv.URL+"/v1/runs/missing", &body))
+	})
+}
+
+func TestServer_Token(t *testing.T) {
+	srv := newTestServer(t, "secret")
+
+	var health map[string]string
+	assert.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/healthz", &health), "health checks need no token")
+
+	var body map[string]string
+	assert.Equal(t, http.StatusUnauthorized, getJSON(t, srv.URL+"/v1/catalog", &body))
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/v1/catalog", nil) //nolint:noctx // test
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServer_UI(t *testing.T) {
+	srv := newTestServer(t, "secret")
+
+	resp, err := http.Get(srv.URL + "/") //nolint:gosec,noctx // test server URL
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode, "the page needs no token")
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), "<title>LeapSQL</title>")
+}
+
+func TestServer_Monitoring(t *testing.T) {
+	srv := newTestServer(t, "")
+
+	// Follow all runs before starting one
+	events, err := http.Get(srv.URL + "/v1/events") //nolint:gosec,noctx // test server URL
+	require.NoError(t, err)
+	defer func() { _ = events.Body.Close() }()
+	require.Equal(t, http.StatusOK, events.StatusCode)
+	assert.Equal(t, "application/x-ndjson", events.Header.Get("Content-Type"))
+
+	resp, err := http.Post(srv.URL+"/v1/runs", "application/json", strings.NewReader(`{}`)) //nolint:noctx // test
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	var followed []string
+	var runID string
+	scanner := bufio.NewScanner(events.Body)
+	for scanner.Scan() {
+		var ev runEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &ev))
+		followed = append(followed, ev.Event)
+		if ev.Event == "run_completed" {
+			runID = ev.Run.ID
+			break
+		}
+	}
+	require.NotEmpty(t, runID)
+	assert.Equal(t, "run_started", followed[0])
+	assert.Contains(t, followed, "model_run")
+
+	t.Run("runs", func(t *testing.T) {
+		var body struct {
+			Runs []runOutput `json:"runs"`
+		}
+		require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/v1/runs?limit=5", &body))
+		require.Len(t, body.Runs, 1)
+		assert.Equal(t, runID, body.Runs[0].ID)
+		assert.Equal(t, "completed", body.Runs[0].Status)
+		require.NotNil(t, body.Runs[0].CompletedAt)
+
+		var bad map[string]string
+		assert.Equal(t, http.StatusBadRequest, getJSON(t, srv.URL+"/v1/runs?limit=none", &bad))
+	})
+
+	t.Run("dag with statuses", func(t *testing.T) {
+		var dag dagOutput
+		require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/v1/dag", &dag))
+		assert.Equal(t, runID, dag.Run, "statuses default to the latest run")
+		require.Len(t, dag.Nodes, 2)
+		assert.Equal(t, dagNodeOutput{Path: "staging.stg_users", Materialized: "table", Level: 0, Status: "success", ExecutionMS: dag.Nodes[0].ExecutionMS}, dag.Nodes[0])
+		assert.Equal(t, "marts.user_names", dag.Nodes[1].Path)
+		assert.Equal(t, 1, dag.Nodes[1].Level)
+		assert.Equal(t, []dagEdgeOutput{{From: "staging.stg_users", To: "marts.user_names"}}, dag.Edges)
+
+		var unknown dagOutput
+		require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/v1/dag?run=missing", &unknown))
+		require.Len(t, unknown.Nodes, 2)
+		assert.Empty(t, unknown.Nodes[0].Status)
+	})
+
+	t.Run("model logs", func(t *testing.T) {
+		var body struct {
+			Logs []logLine `json:"logs"`
+		}
+		require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/v1/runs/"+runID+"/models/staging.stg_users/logs", &body))
+		var messages []string
+		for _, l := range body.Logs {
+			messages = append(messages, l.Message)
+		}
+		assert.Contains(t, messages, "model executed")
+
+		require.Equal(t, http.StatusOK, getJSON(t, srv.URL+"/v1/runs/missing/models/staging.stg_users/logs", &body))
+		assert.Empty(t, body.Logs)
+	})
+}
+
+func TestServer_Socket(t *testing.T) {
+	eng, logs := newTestEngine(t)
+	socket := SocketPath(filepath.Join(t.TempDir(), "state.db"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := New(Config{Engine: eng, Addr: "127.0.0.1:0", Environment: "test", Token: "secret", Logs: logs, Socket: socket})
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx) }()
+
+	var client *Client
+	require.Eventually(t, func() bool {
+		var err error
+		client, err = Dial(context.Background(), socket)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	defer client.Close()
+
+	// Socket clients need no token
+	catalog, err := client.Catalog(context.Background())
+	require.NoError(t, err)
+	require.Len(t, catalog, 2)
+	assert.Equal(t, "marts.user_names", catalog[0].Path)
+	assert.Equal(t, []string{"staging.stg_users"}, catalog[0].DependsOn)
+
+	results, err := client.Lint(context.Background(), LintRequest{Models: []string{"marts.user_names"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "marts.user_names", results[0].Model)
+	assert.Contains(t, results[0].SQL, "FROM staging.stg_users")
+	var rules []string
+	for _, d := range results[0].Diagnostics {
+		rules = append(rules, d.RuleID)
+	}
+	assert.Contains(t, rules, "CV05")
+
+	results, err = client.Lint(context.Background(), LintRequest{Models: []string{"marts.user_names"}, Disable: rules})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	_, err = client.Lint(context.Background(), LintRequest{Models: []string{"marts.missing"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "model not found: marts.missing")
+
+	lineage, err := client.Lineage(context.Background(), "staging.stg_users")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"marts.user_names"}, lineage.Downstream)
+	require.Len(t, lineage.Columns, 2)
+	assert.Equal(t, "id", lineage.Columns[0].Name)
+
+	run, err := client.Run(context.Background(), RunRequest{Select: "staging.stg_users"})
+	require.NoError(t, err)
+	assert.Equal(t, "completed", run.Status)
+	assert.Empty(t, run.Error)
+	require.Len(t, run.Models, 1)
+	assert.Equal(t, "staging.stg_users", run.Models[0].Model)
+	assert.Equal(t, "success", run.Models[0].Status)
+
+	sql, err := client.Compile(context.Background(), "staging.stg_users")
+	require.NoError(t, err)
+	assert.Contains(t, sql, "FROM users")
+
+	preview, err := client.Preview(context.Background(), "staging.stg_users", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"id", "name"}, preview.Columns)
+	assert.Len(t, preview.Rows, 1)
+	assert.True(t, preview.Truncated)
+
+	// A second daemon can't take over the socket
+	_, err = listenSocket(context.Background(), socket)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "a daemon is already listening")
+
+	cancel()
+	require.NoError(t, <-served)
+	_, err = os.Stat(socket)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = Dial(context.Background(), socket)
+	assert.Error(t, err)
+}
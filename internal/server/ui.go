@@ -0,0 +1,19 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// uiPage is the run monitoring web UI: a single page using the /v1 endpoints.
+//
+//go:embed ui/index.html
+var uiPage []byte
+
+// handleUI serves the web UI. The page holds no project data, so it needs no
+// token; it asks for one when the /v1 endpoints require it.
+func (s *Server) handleUI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(uiPage)
+}
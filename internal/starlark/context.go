@@ -8,7 +8,8 @@ import (
 )
 
 // ExecutionContext provides all globals and state for Starlark template execution.
-// Note: No ref() function - dependencies are extracted by lineage parser from SQL AST.
+// Note: Dependencies are extracted by the lineage parser from the SQL AST.
+// ref() is only needed to pin a model version or to cross workspace projects.
 type ExecutionContext struct {
 	// Config dict containing parsed YAML frontmatter
 	// Accessible as: config["materialized"], config["owner"], etc.
@@ -30,6 +31,10 @@ type ExecutionContext struct {
 	// Each key is a namespace (e.g., "datetime") with a struct of functions
 	Macros starlark.StringDict
 
+	// Ref resolves ref('model') and ref('project', 'model') to a table name (optional)
+	// If nil, ref() is not available in templates.
+	Ref RefResolver
+
 	// globals is the combined set of all globals for execution
 	globals starlark.StringDict
 
@@ -56,6 +61,9 @@ func (ctx *ExecutionContext) buildGlobals() {
 	defer ctx.mu.Unlock()
 
 	ctx.globals = Predeclared(ctx.Config, ctx.Env, ctx.Target, ctx.This)
+	if ctx.Ref != nil {
+		ctx.globals["ref"] = refBuiltin(ctx.Ref)
+	}
 
 	// Add macros
 	for name, macro := range ctx.Macros {
@@ -76,6 +84,7 @@ func (ctx *ExecutionContext) AddMacros(macros starlark.StringDict) error {
 	builtins := map[string]bool{
 		"config": true,
 		"env":    true,
+		"ref":    true,
 		"target": true,
 		"this":   true,
 	}
@@ -195,6 +204,45 @@ func WithMacros(macros starlark.StringDict) ContextOption {
 	}
 }
 
+// RefResolver resolves a referenced model to its table name.
+// The project is empty for ref('model') and the version is 0 unless pinned with v=N.
+// This keeps the starlark package decoupled from the model registry;
+// implementations are wired in internal/engine.
+type RefResolver func(project, model string, version int) (string, error)
+
+// WithRefResolver makes ref() available in templates.
+func WithRefResolver(resolve RefResolver) ContextOption {
+	return func(ctx *ExecutionContext) {
+		ctx.Ref = resolve
+	}
+}
+
+// refBuiltin wraps a RefResolver as the ref() template builtin.
+func refBuiltin(resolve RefResolver) *starlark.Builtin {
+	return starlark.NewBuiltin("ref", func(_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var project, model string
+		var version int
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "project?", &project, "model?", &model, "v?", &version); err != nil {
+			return nil, err
+		}
+		// ref('model') names the model in its only argument
+		if model == "" {
+			project, model = "", project
+		}
+		if model == "" {
+			return nil, fmt.Errorf("%s: missing argument for model", fn.Name())
+		}
+		if version < 0 {
+			return nil, fmt.Errorf("%s: v must be a positive version, got %d", fn.Name(), version)
+		}
+		table, err := resolve(project, model, version)
+		if err != nil {
+			return nil, err
+		}
+		return starlark.String(table), nil
+	})
+}
+
 // MacroProvider provides macros as a Starlark dictionary.
 // This interface allows the starlark package to be decoupled from the macro package.
 // Implementations (like macro.Registry) are wired in internal/engine.
@@ -1,6 +1,7 @@
 package starlark
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/leapstack-labs/leapsql/internal/macro"
@@ -282,3 +283,55 @@ func TestNewContext_WithMacroProvider_Nil(t *testing.T) {
 	_, ok = globals["env"]
 	assert.True(t, ok, "env not found")
 }
+
+func TestNewContext_WithRefResolver(t *testing.T) {
+	resolve := func(project, model string, version int) (string, error) {
+		switch {
+		case project == "core" && model == "stg_orders" && version == 0:
+			return "staging.stg_orders", nil
+		case project == "" && model == "dim_users" && version == 0:
+			return "marts.dim_users_v3", nil
+		case project == "" && model == "dim_users" && version == 2:
+			return "marts.dim_users_v2", nil
+		}
+		return "", fmt.Errorf("unknown model %s/%s v%d", project, model, version)
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    string
+		wantErr string
+	}{
+		{name: "resolves model", expr: `ref('core', 'stg_orders')`, want: "staging.stg_orders"},
+		{name: "keyword arguments", expr: `ref(project='core', model='stg_orders')`, want: "staging.stg_orders"},
+		{name: "unknown model", expr: `ref('core', 'missing')`, wantErr: "unknown model core/missing"},
+		{name: "model only", expr: `ref('dim_users')`, want: "marts.dim_users_v3"},
+		{name: "model keyword", expr: `ref(model='dim_users')`, want: "marts.dim_users_v3"},
+		{name: "pinned version", expr: `ref('dim_users', v=2)`, want: "marts.dim_users_v2"},
+		{name: "negative version", expr: `ref('dim_users', v=-1)`, wantErr: "v must be a positive version"},
+		{name: "missing argument", expr: `ref()`, wantErr: "missing argument for model"},
+	}
+
+	ctx := NewContext(starlark.NewDict(0), "dev", nil, nil, WithRefResolver(resolve))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ctx.EvalExprString(tt.expr, "test.sql", 1)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewContext_WithoutRefResolver(t *testing.T) {
+	ctx := NewContext(starlark.NewDict(0), "dev", nil, nil)
+
+	_, ok := ctx.Globals()["ref"]
+	assert.False(t, ok, "ref should only be available with a resolver")
+}
@@ -20,8 +20,9 @@ type TargetInfo struct {
 // ThisInfo contains current model information.
 // Exposed as the "this" global in Starlark execution.
 type ThisInfo struct {
-	Name   string // Current model name
-	Schema string // Current model schema
+	Name     string // Current model name
+	Schema   string // Current model schema
+	Database string // Attached database the model is built in (empty for the target database)
 }
 
 // ToStarlark converts TargetInfo to a Starlark struct value.
@@ -36,8 +37,9 @@ func (t *TargetInfo) ToStarlark() starlark.Value {
 // ToStarlark converts ThisInfo to a Starlark struct value.
 func (t *ThisInfo) ToStarlark() starlark.Value {
 	return starlarkstruct.FromStringDict(starlark.String("this"), starlark.StringDict{
-		"name":   starlark.String(t.Name),
-		"schema": starlark.String(t.Schema),
+		"name":     starlark.String(t.Name),
+		"schema":   starlark.String(t.Schema),
+		"database": starlark.String(t.Database),
 	})
 }
 
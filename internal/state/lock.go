@@ -0,0 +1,174 @@
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// lockPollInterval is how often a waiting run retries the lock.
+const lockPollInterval = 100 * time.Millisecond
+
+// lockHolder identifies the process holding the run lock. It is written to
+// the lock file so waiting runs can report it and detect stale locks.
+type lockHolder struct {
+	PID        int       `json:"pid"`
+	Hostname   string    `json:"hostname"`
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+// stale reports whether the holder process has exited without releasing the
+// lock. Only holders on this host can be checked.
+func (h *lockHolder) stale() bool {
+	hostname, _ := os.Hostname()
+	return h.Hostname == hostname && h.PID > 0 && !processAlive(h.PID)
+}
+
+// LockRuns acquires the run lock with a lock file next to the state database.
+// Locks left by processes that exited without releasing them are taken over.
+// In-memory databases are never shared, so they are not locked.
+func (s *SQLiteStore) LockRuns(ctx context.Context, timeout time.Duration) (func() error, error) {
+	if s.path == "" || s.path == ":memory:" {
+		return func() error { return nil }, nil
+	}
+
+	lockPath := s.path + ".lock"
+	deadline := time.Now().Add(timeout)
+
+	for {
+		err := createLockFile(lockPath)
+		if err == nil {
+			s.logger.Debug("acquired run lock", slog.String("path", lockPath))
+			return func() error { return releaseLockFile(lockPath) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		// The holder may still be writing the file; an unreadable lock is held
+		holder, err := readLockFile(lockPath)
+		if err == nil && holder.stale() {
+			tookOver, err := takeOverLockFile(lockPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to take over stale lock file: %w", err)
+			}
+			if tookOver {
+				s.logger.Warn("took over stale run lock", slog.Int("pid", holder.PID), slog.Time("acquired_at", holder.AcquiredAt))
+				return func() error { return releaseLockFile(lockPath) }, nil
+			}
+			continue
+		}
+
+		if !time.Now().Before(deadline) {
+			if holder == nil {
+				return nil, fmt.Errorf("%w (lock file %s)", core.ErrStateLocked, lockPath)
+			}
+			return nil, fmt.Errorf("%w: pid %d on %s since %s (lock file %s)", core.ErrStateLocked,
+				holder.PID, holder.Hostname, holder.AcquiredAt.Format(time.RFC3339), lockPath)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// createLockFile creates the lock file, failing with fs.ErrExist if it is held.
+func createLockFile(path string) error {
+	return writeLockFile(path, os.O_EXCL)
+}
+
+// writeLockFile writes a lock file held by this process. With os.O_EXCL it
+// fails with fs.ErrExist if the file exists; without, it replaces it.
+func writeLockFile(path string, flag int) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|flag, 0600) //nolint:gosec // Path is derived from the state database path
+	if err != nil {
+		return err
+	}
+
+	hostname, _ := os.Hostname()
+	err = json.NewEncoder(f).Encode(lockHolder{PID: os.Getpid(), Hostname: hostname, AcquiredAt: time.Now()})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// takeOverLockFile replaces a stale lock file with one held by this process
+// and reports whether it did. Takeovers are serialized by an OS file lock on
+// a guard file next to the lock file, which the OS releases if the process
+// dies, and the holder is checked again under it: a lock file another run
+// created or took over since it was found stale is left alone. The new lock
+// file is renamed over the stale one, so the lock path never goes missing
+// and runs creating it in the meantime keep failing.
+func takeOverLockFile(lockPath string) (bool, error) {
+	guard, err := os.OpenFile(lockPath+".guard", os.O_RDWR|os.O_CREATE, 0600) //nolint:gosec // Path is derived from the state database path
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = guard.Close() }()
+
+	if err := lockGuard(guard); err != nil {
+		return false, err
+	}
+	defer func() { _ = unlockGuard(guard) }()
+
+	holder, err := readLockFile(lockPath)
+	if err != nil || !holder.stale() {
+		return false, nil
+	}
+
+	tmpPath := fmt.Sprintf("%s.%d.tmp", lockPath, os.Getpid())
+	if err := writeLockFile(tmpPath, 0); err != nil {
+		return false, err
+	}
+	if err := os.Rename(tmpPath, lockPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return false, err
+	}
+	return true, nil
+}
+
+// readLockFile reads the holder of a lock file.
+func readLockFile(path string) (*lockHolder, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path is derived from the state database path
+	if err != nil {
+		return nil, err
+	}
+	var holder lockHolder
+	if err := json.Unmarshal(data, &holder); err != nil {
+		return nil, err
+	}
+	return &holder, nil
+}
+
+// releaseLockFile removes the lock file if this process still holds it.
+func releaseLockFile(path string) error {
+	holder, err := readLockFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read lock file: %w", err)
+	}
+	if holder.PID != os.Getpid() {
+		return fmt.Errorf("lock file %s is held by pid %d", path, holder.PID)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package state
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/internal/testutil"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFileStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store := NewSQLiteStore(testutil.NewTestLogger(t))
+	require.NoError(t, store.Open(filepath.Join(t.TempDir(), "state.db")))
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func writeLockHolder(t *testing.T, path string, holder lockHolder) {
+	t.Helper()
+	data, err := json.Marshal(holder)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+}
+
+func TestSQLiteStore_LockRuns(t *testing.T) {
+	ctx := context.Background()
+	store := setupFileStore(t)
+	lockPath := store.path + ".lock"
+
+	release, err := store.LockRuns(ctx, 0)
+	require.NoError(t, err)
+	assert.FileExists(t, lockPath)
+
+	// A second run fails while the lock is held
+	_, err = store.LockRuns(ctx, 0)
+	require.ErrorIs(t, err, core.ErrStateLocked)
+	assert.Contains(t, err.Error(), "pid")
+
+	require.NoError(t, release())
+	assert.NoFileExists(t, lockPath)
+
+	release, err = store.LockRuns(ctx, 0)
+	require.NoError(t, err)
+	require.NoError(t, release())
+}
+
+func TestSQLiteStore_LockRunsWait(t *testing.T) {
+	ctx := context.Background()
+	store := setupFileStore(t)
+
+	release, err := store.LockRuns(ctx, 0)
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		_ = release()
+	}()
+
+	start := time.Now()
+	release, err = store.LockRuns(ctx, 5*time.Second)
+	require.NoError(t, err, "waiting run should acquire the released lock")
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	require.NoError(t, release())
+
+	// Cancelling the context stops waiting
+	release, err = store.LockRuns(ctx, 0)
+	require.NoError(t, err)
+	defer func() { _ = release() }()
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = store.LockRuns(cancelled, time.Minute)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSQLiteStore_LockRunsStale(t *testing.T) {
+	hostname, _ := os.Hostname()
+
+	tests := []struct {
+		name       string
+		holder     lockHolder
+		wantLocked bool
+	}{
+		{
+			name:       "exited process on this host",
+			holder:     lockHolder{PID: math.MaxInt32, Hostname: hostname},
+			wantLocked: false,
+		},
+		{
+			name:       "running process on this host",
+			holder:     lockHolder{PID: os.Getppid(), Hostname: hostname},
+			wantLocked: true,
+		},
+		{
+			name:       "process on another host",
+			holder:     lockHolder{PID: math.MaxInt32, Hostname: hostname + "-other"},
+			wantLocked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := setupFileStore(t)
+			tt.holder.AcquiredAt = time.Now().Add(-time.Hour)
+			writeLockHolder(t, store.path+".lock", tt.holder)
+
+			release, err := store.LockRuns(context.Background(), 0)
+			if tt.wantLocked {
+				assert.ErrorIs(t, err, core.ErrStateLocked)
+				return
+			}
+			require.NoError(t, err, "stale lock should be taken over")
+			require.NoError(t, release())
+		})
+	}
+}
+
+// lockHelperEnv names the state database a helper process contends for.
+const lockHelperEnv = "LEAPSQL_TEST_LOCK_HELPER"
+
+// TestLockRunsHelper is run by TestSQLiteStore_LockRunsStaleContention in
+// separate processes. It tries to take the lock once, reports the outcome on
+// stdout and holds the lock until stdin closes.
+func TestLockRunsHelper(t *testing.T) {
+	path := os.Getenv(lockHelperEnv)
+	if path == "" {
+		t.Skip("helper process for TestSQLiteStore_LockRunsStaleContention")
+	}
+
+	store := NewSQLiteStore(testutil.NewTestLogger(t))
+	require.NoError(t, store.Open(path))
+	defer func() { _ = store.Close() }()
+
+	release, err := store.LockRuns(context.Background(), 0)
+	switch {
+	case err == nil:
+		fmt.Println("acquired")
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		require.NoError(t, release())
+	case errors.Is(err, core.ErrStateLocked):
+		fmt.Println("locked")
+	default:
+		fmt.Println("error:", err)
+	}
+}
+
+func TestSQLiteStore_LockRunsStaleContention(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts processes")
+	}
+
+	hostname, _ := os.Hostname()
+	path := filepath.Join(t.TempDir(), "state.db")
+	writeLockHolder(t, path+".lock", lockHolder{PID: math.MaxInt32, Hostname: hostname, AcquiredAt: time.Now().Add(-time.Hour)})
+
+	// Processes finding the same stale lock must not both take it over
+	const processes = 8
+	stdins := make([]io.WriteCloser, 0, processes)
+	outputs := make([]*bufio.Reader, 0, processes)
+	for range processes {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestLockRunsHelper$") //nolint:gosec // Re-runs the test binary
+		cmd.Env = append(os.Environ(), lockHelperEnv+"="+path)
+		stdin, err := cmd.StdinPipe()
+		require.NoError(t, err)
+		stdout, err := cmd.StdoutPipe()
+		require.NoError(t, err)
+		require.NoError(t, cmd.Start())
+		t.Cleanup(func() { _ = cmd.Wait() })
+		t.Cleanup(func() { _ = stdin.Close() })
+		stdins = append(stdins, stdin)
+		outputs = append(outputs, bufio.NewReader(stdout))
+	}
+
+	acquired := 0
+	for _, out := range outputs {
+		line, err := out.ReadString('\n')
+		require.NoError(t, err)
+		switch line = strings.TrimSpace(line); line {
+		case "acquired":
+			acquired++
+		case "locked":
+		default:
+			t.Fatalf("helper process: %s", line)
+		}
+	}
+	assert.Equal(t, 1, acquired, "exactly one process should take over the stale lock")
+
+	for _, stdin := range stdins {
+		require.NoError(t, stdin.Close())
+	}
+}
+
+func TestSQLiteStore_LockRunsMemory(t *testing.T) {
+	store := setupTestStore(t)
+	defer func() { _ = store.Close() }()
+
+	// In-memory stores are never shared, so concurrent locks succeed
+	release1, err := store.LockRuns(context.Background(), 0)
+	require.NoError(t, err)
+	release2, err := store.LockRuns(context.Background(), 0)
+	require.NoError(t, err)
+	assert.NoError(t, release1())
+	assert.NoError(t, release2())
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package state
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// processAlive reports whether a process with the given PID exists.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// lockGuard takes an exclusive OS lock on a file, waiting for it.
+func lockGuard(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX) //nolint:gosec // File descriptors fit in an int
+}
+
+// unlockGuard releases the OS lock taken by lockGuard.
+func unlockGuard(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN) //nolint:gosec // File descriptors fit in an int
+}
@@ -0,0 +1,29 @@
+//go:build windows
+
+package state
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// processAlive reports whether a process with the given PID exists.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
+
+// lockGuard takes an exclusive OS lock on a file, waiting for it.
+func lockGuard(f *os.File) error {
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, new(windows.Overlapped))
+}
+
+// unlockGuard releases the OS lock taken by lockGuard.
+func unlockGuard(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, new(windows.Overlapped))
+}
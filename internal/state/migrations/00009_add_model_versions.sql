@@ -0,0 +1,8 @@
+-- +goose Up
+-- Add version and deprecation metadata from model frontmatter
+ALTER TABLE models ADD COLUMN version INTEGER DEFAULT 0;
+ALTER TABLE models ADD COLUMN deprecation TEXT;
+
+-- +goose Down
+ALTER TABLE models DROP COLUMN deprecation;
+ALTER TABLE models DROP COLUMN version;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Add access level from model frontmatter
+ALTER TABLE models ADD COLUMN access TEXT DEFAULT '';
+
+-- +goose Down
+ALTER TABLE models DROP COLUMN access;
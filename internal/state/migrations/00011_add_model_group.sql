@@ -0,0 +1,6 @@
+-- +goose Up
+-- Add owning group from model frontmatter
+ALTER TABLE models ADD COLUMN group_name TEXT DEFAULT '';
+
+-- +goose Down
+ALTER TABLE models DROP COLUMN group_name;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Add warehouse cost columns, set when the adapter reports query costs
+ALTER TABLE model_runs ADD COLUMN bytes_scanned INTEGER;
+ALTER TABLE model_runs ADD COLUMN slot_ms INTEGER;
+
+-- +goose Down
+ALTER TABLE model_runs DROP COLUMN slot_ms;
+ALTER TABLE model_runs DROP COLUMN bytes_scanned;
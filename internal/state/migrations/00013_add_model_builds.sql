@@ -0,0 +1,13 @@
+-- +goose Up
+-- Track the last successful build of each model per target for the build cache
+CREATE TABLE IF NOT EXISTS model_builds (
+    model_id TEXT NOT NULL,
+    target TEXT NOT NULL,
+    build_hash TEXT NOT NULL,
+    run_id TEXT NOT NULL,
+    built_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (model_id, target)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS model_builds;
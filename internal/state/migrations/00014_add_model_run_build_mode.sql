@@ -0,0 +1,6 @@
+-- +goose Up
+-- Record how each model run built its relation: full, incremental or full_refresh
+ALTER TABLE model_runs ADD COLUMN build_mode TEXT;
+
+-- +goose Down
+ALTER TABLE model_runs DROP COLUMN build_mode;
@@ -0,0 +1,153 @@
+-- +goose Up
+-- Allow external models, built by a command instead of SQL. SQLite cannot
+-- alter a CHECK constraint, so the models table is rebuilt, keeping rowids
+-- for the full-text index.
+-- The views over models must not be checked while the table is swapped
+PRAGMA legacy_alter_table = ON;
+
+CREATE TABLE models_new (
+    id TEXT PRIMARY KEY,
+    path TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    materialized TEXT NOT NULL DEFAULT 'table',
+    unique_key TEXT,
+    content_hash TEXT NOT NULL,
+    file_path TEXT,
+    owner TEXT,
+    schema_name TEXT,
+    tags TEXT,
+    tests TEXT,
+    meta TEXT,
+    uses_select_star INTEGER DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sql_content TEXT DEFAULT '',
+    raw_content TEXT DEFAULT '',
+    description TEXT DEFAULT '',
+    version INTEGER DEFAULT 0,
+    deprecation TEXT,
+    access TEXT DEFAULT '',
+    group_name TEXT DEFAULT '',
+
+    CHECK (materialized IN ('table', 'view', 'incremental', 'external'))
+);
+
+INSERT INTO models_new (rowid, id, path, name, materialized, unique_key, content_hash, file_path, owner, schema_name, tags, tests, meta, uses_select_star, created_at, updated_at, sql_content, raw_content, description, version, deprecation, access, group_name)
+SELECT rowid, id, path, name, materialized, unique_key, content_hash, file_path, owner, schema_name, tags, tests, meta, uses_select_star, created_at, updated_at, sql_content, raw_content, description, version, deprecation, access, group_name FROM models;
+
+DROP TABLE models;
+ALTER TABLE models_new RENAME TO models;
+
+PRAGMA legacy_alter_table = OFF;
+
+CREATE INDEX IF NOT EXISTS idx_models_path ON models(path);
+CREATE INDEX IF NOT EXISTS idx_models_name ON models(name);
+CREATE INDEX IF NOT EXISTS idx_models_file_path ON models(file_path);
+
+-- +goose StatementBegin
+CREATE TRIGGER models_updated_at
+    AFTER UPDATE ON models
+    FOR EACH ROW
+BEGIN
+    UPDATE models SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER models_fts_insert AFTER INSERT ON models BEGIN
+    INSERT INTO models_fts(rowid, name, path, description, sql_content)
+    VALUES (new.rowid, new.name, new.path, new.description, new.sql_content);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER models_fts_delete AFTER DELETE ON models BEGIN
+    INSERT INTO models_fts(models_fts, rowid, name, path, description, sql_content)
+    VALUES('delete', old.rowid, old.name, old.path, old.description, old.sql_content);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER models_fts_update AFTER UPDATE ON models BEGIN
+    INSERT INTO models_fts(models_fts, rowid, name, path, description, sql_content)
+    VALUES('delete', old.rowid, old.name, old.path, old.description, old.sql_content);
+    INSERT INTO models_fts(rowid, name, path, description, sql_content)
+    VALUES (new.rowid, new.name, new.path, new.description, new.sql_content);
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DELETE FROM models WHERE materialized = 'external';
+-- The views over models must not be checked while the table is swapped
+PRAGMA legacy_alter_table = ON;
+
+CREATE TABLE models_new (
+    id TEXT PRIMARY KEY,
+    path TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    materialized TEXT NOT NULL DEFAULT 'table',
+    unique_key TEXT,
+    content_hash TEXT NOT NULL,
+    file_path TEXT,
+    owner TEXT,
+    schema_name TEXT,
+    tags TEXT,
+    tests TEXT,
+    meta TEXT,
+    uses_select_star INTEGER DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sql_content TEXT DEFAULT '',
+    raw_content TEXT DEFAULT '',
+    description TEXT DEFAULT '',
+    version INTEGER DEFAULT 0,
+    deprecation TEXT,
+    access TEXT DEFAULT '',
+    group_name TEXT DEFAULT '',
+
+    CHECK (materialized IN ('table', 'view', 'incremental'))
+);
+
+INSERT INTO models_new (rowid, id, path, name, materialized, unique_key, content_hash, file_path, owner, schema_name, tags, tests, meta, uses_select_star, created_at, updated_at, sql_content, raw_content, description, version, deprecation, access, group_name)
+SELECT rowid, id, path, name, materialized, unique_key, content_hash, file_path, owner, schema_name, tags, tests, meta, uses_select_star, created_at, updated_at, sql_content, raw_content, description, version, deprecation, access, group_name FROM models;
+
+DROP TABLE models;
+ALTER TABLE models_new RENAME TO models;
+
+PRAGMA legacy_alter_table = OFF;
+
+CREATE INDEX IF NOT EXISTS idx_models_path ON models(path);
+CREATE INDEX IF NOT EXISTS idx_models_name ON models(name);
+CREATE INDEX IF NOT EXISTS idx_models_file_path ON models(file_path);
+
+-- +goose StatementBegin
+CREATE TRIGGER models_updated_at
+    AFTER UPDATE ON models
+    FOR EACH ROW
+BEGIN
+    UPDATE models SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER models_fts_insert AFTER INSERT ON models BEGIN
+    INSERT INTO models_fts(rowid, name, path, description, sql_content)
+    VALUES (new.rowid, new.name, new.path, new.description, new.sql_content);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER models_fts_delete AFTER DELETE ON models BEGIN
+    INSERT INTO models_fts(models_fts, rowid, name, path, description, sql_content)
+    VALUES('delete', old.rowid, old.name, old.path, old.description, old.sql_content);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER models_fts_update AFTER UPDATE ON models BEGIN
+    INSERT INTO models_fts(models_fts, rowid, name, path, description, sql_content)
+    VALUES('delete', old.rowid, old.name, old.path, old.description, old.sql_content);
+    INSERT INTO models_fts(rowid, name, path, description, sql_content)
+    VALUES (new.rowid, new.name, new.path, new.description, new.sql_content);
+END;
+-- +goose StatementEnd
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Add PII columns from model frontmatter
+ALTER TABLE models ADD COLUMN pii TEXT;
+
+-- +goose Down
+ALTER TABLE models DROP COLUMN pii;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Record the row filter applied to each model run's query
+ALTER TABLE model_runs ADD COLUMN row_filter TEXT;
+
+-- +goose Down
+ALTER TABLE model_runs DROP COLUMN row_filter;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Store the query plan of each model build captured with EXPLAIN
+CREATE TABLE IF NOT EXISTS model_plans (
+    model_path TEXT NOT NULL,
+    run_id TEXT NOT NULL,
+    plan TEXT NOT NULL,
+    captured_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (model_path, run_id),
+    FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_model_plans_run_id ON model_plans(run_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_model_plans_run_id;
+DROP TABLE IF EXISTS model_plans;
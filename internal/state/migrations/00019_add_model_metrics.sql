@@ -0,0 +1,18 @@
+-- +goose Up
+-- Record per-run row counts and null rates of models with anomaly tests
+CREATE TABLE IF NOT EXISTS model_metrics (
+    run_id TEXT NOT NULL,
+    model_path TEXT NOT NULL,
+    metric TEXT NOT NULL,
+    column_name TEXT NOT NULL DEFAULT '',
+    value REAL NOT NULL,
+    recorded_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (run_id, model_path, metric, column_name),
+    FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_model_metrics_model ON model_metrics(model_path, metric, column_name);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_model_metrics_model;
+DROP TABLE IF EXISTS model_metrics;
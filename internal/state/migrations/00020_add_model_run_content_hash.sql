@@ -0,0 +1,6 @@
+-- +goose Up
+-- Record the content hash of each model run's model, for state:modified selection
+ALTER TABLE model_runs ADD COLUMN content_hash TEXT;
+
+-- +goose Down
+ALTER TABLE model_runs DROP COLUMN content_hash;
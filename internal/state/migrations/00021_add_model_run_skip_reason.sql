@@ -0,0 +1,8 @@
+-- +goose Up
+-- Record why each skipped model run was skipped, and which model caused it
+ALTER TABLE model_runs ADD COLUMN skip_reason TEXT;
+ALTER TABLE model_runs ADD COLUMN skipped_by TEXT;
+
+-- +goose Down
+ALTER TABLE model_runs DROP COLUMN skipped_by;
+ALTER TABLE model_runs DROP COLUMN skip_reason;
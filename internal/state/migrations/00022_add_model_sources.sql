@@ -0,0 +1,6 @@
+-- +goose Up
+-- Record the tables each model references so project lint can run from state
+ALTER TABLE models ADD COLUMN sources TEXT;
+
+-- +goose Down
+ALTER TABLE models DROP COLUMN sources;
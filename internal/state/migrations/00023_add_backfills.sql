@@ -0,0 +1,33 @@
+-- +goose Up
+-- Record backfills of incremental models and the chunks they completed, so
+-- an interrupted backfill resumes after its completed chunks
+CREATE TABLE IF NOT EXISTS backfills (
+    id TEXT PRIMARY KEY,
+    model_path TEXT NOT NULL,
+    column_name TEXT NOT NULL,
+    range_start TEXT NOT NULL,
+    range_end TEXT NOT NULL,
+    chunk TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running',
+    error TEXT,
+    started_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_backfills_model ON backfills(model_path, status);
+
+CREATE TABLE IF NOT EXISTS backfill_chunks (
+    backfill_id TEXT NOT NULL,
+    chunk_start TEXT NOT NULL,
+    chunk_end TEXT NOT NULL,
+    run_id TEXT NOT NULL,
+    rows_affected INTEGER NOT NULL DEFAULT 0,
+    completed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (backfill_id, chunk_start),
+    FOREIGN KEY (backfill_id) REFERENCES backfills(id) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS backfill_chunks;
+DROP INDEX IF EXISTS idx_backfills_model;
+DROP TABLE IF EXISTS backfills;
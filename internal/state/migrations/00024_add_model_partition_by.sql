@@ -0,0 +1,58 @@
+-- +goose Up
+-- Record the partition column and grain of models with partition_by, and
+-- expose them to the docs
+ALTER TABLE models ADD COLUMN partition_by TEXT;
+
+DROP VIEW IF EXISTS v_models;
+CREATE VIEW v_models AS
+SELECT
+    id,
+    path,
+    name,
+    CASE WHEN instr(path, '.') > 0
+         THEN substr(path, 1, instr(path, '.') - 1)
+         ELSE 'default' END AS folder,
+    materialized,
+    unique_key,
+    sql_content,
+    raw_content,
+    file_path,
+    description,
+    owner,
+    schema_name,
+    tags,
+    tests,
+    meta,
+    uses_select_star,
+    partition_by,
+    created_at,
+    updated_at
+FROM models;
+
+-- +goose Down
+DROP VIEW IF EXISTS v_models;
+CREATE VIEW v_models AS
+SELECT
+    id,
+    path,
+    name,
+    CASE WHEN instr(path, '.') > 0
+         THEN substr(path, 1, instr(path, '.') - 1)
+         ELSE 'default' END AS folder,
+    materialized,
+    unique_key,
+    sql_content,
+    raw_content,
+    file_path,
+    description,
+    owner,
+    schema_name,
+    tags,
+    tests,
+    meta,
+    uses_select_star,
+    created_at,
+    updated_at
+FROM models;
+
+ALTER TABLE models DROP COLUMN partition_by;
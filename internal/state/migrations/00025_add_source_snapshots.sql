@@ -0,0 +1,13 @@
+-- +goose Up
+-- Record when the sources each model reads last changed, as seen before its
+-- last build, so runs with --skip-fresh skip models whose sources are unchanged
+CREATE TABLE IF NOT EXISTS source_snapshots (
+    model_path TEXT NOT NULL,
+    target TEXT NOT NULL,                -- Database the model was built in
+    source TEXT NOT NULL,                -- Table or file path the model reads
+    changed_at DATETIME NOT NULL,        -- Latest loaded_at or file modification time
+    PRIMARY KEY (model_path, target, source)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS source_snapshots;
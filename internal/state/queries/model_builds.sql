@@ -0,0 +1,15 @@
+-- name: GetModelBuild :one
+SELECT model_id, target, build_hash, run_id, built_at
+FROM model_builds
+WHERE model_id = ? AND target = ?;
+
+-- name: SaveModelBuild :exec
+INSERT INTO model_builds (model_id, target, build_hash, run_id, built_at)
+VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT(model_id, target) DO UPDATE SET
+    build_hash = excluded.build_hash,
+    run_id = excluded.run_id,
+    built_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteModelBuild :exec
+DELETE FROM model_builds WHERE model_id = ? AND target = ?;
@@ -1,33 +1,61 @@
 -- name: RecordModelRun :exec
-INSERT INTO model_runs (id, run_id, model_id, status, rows_affected, started_at, error, render_ms, execution_ms)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+INSERT INTO model_runs (id, run_id, model_id, status, rows_affected, started_at, error, render_ms, execution_ms, row_filter, content_hash)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
 
 -- name: UpdateModelRun :exec
 UPDATE model_runs
 SET status = ?, rows_affected = ?, completed_at = ?, error = ?, render_ms = ?, execution_ms = ?
 WHERE id = ?;
 
+-- name: UpdateModelRunCost :exec
+UPDATE model_runs
+SET bytes_scanned = ?, slot_ms = ?
+WHERE id = ?;
+
+-- name: UpdateModelRunBuildMode :exec
+UPDATE model_runs
+SET build_mode = ?
+WHERE id = ?;
+
+-- name: UpdateModelRunSkip :exec
+UPDATE model_runs
+SET skip_reason = ?, skipped_by = ?
+WHERE id = ?;
+
+-- name: DeleteOrphanedModelRuns :execrows
+DELETE FROM model_runs
+WHERE run_id NOT IN (SELECT id FROM runs)
+   OR model_id NOT IN (SELECT id FROM models);
+
 -- name: GetModelRunStartedAt :one
 SELECT started_at FROM model_runs WHERE id = ?;
 
 -- name: GetModelRunsForRun :many
-SELECT id, run_id, model_id, status, rows_affected, started_at, completed_at, error, render_ms, execution_ms
+SELECT id, run_id, model_id, status, rows_affected, started_at, completed_at, error, render_ms, execution_ms, bytes_scanned, slot_ms, build_mode, row_filter, content_hash, skip_reason, skipped_by
 FROM model_runs
 WHERE run_id = ?
 ORDER BY started_at;
 
 -- name: GetLatestModelRun :one
-SELECT id, run_id, model_id, status, rows_affected, started_at, completed_at, error, render_ms, execution_ms
+SELECT id, run_id, model_id, status, rows_affected, started_at, completed_at, error, render_ms, execution_ms, bytes_scanned, slot_ms, build_mode, row_filter, content_hash, skip_reason, skipped_by
 FROM model_runs
 WHERE model_id = ?
 ORDER BY started_at DESC
 LIMIT 1;
 
+-- name: GetLatestSuccessfulModelRun :one
+SELECT id, run_id, model_id, status, rows_affected, started_at, completed_at, error, render_ms, execution_ms, bytes_scanned, slot_ms, build_mode, row_filter, content_hash, skip_reason, skipped_by
+FROM model_runs
+WHERE model_id = ? AND status = 'success'
+ORDER BY started_at DESC
+LIMIT 1;
+
 -- name: GetModelRunsWithModelInfo :many
 SELECT
     mr.id, mr.run_id, mr.model_id, mr.status,
     mr.rows_affected, mr.started_at, mr.completed_at,
-    mr.error, mr.render_ms, mr.execution_ms,
+    mr.error, mr.render_ms, mr.execution_ms, mr.build_mode, mr.row_filter, mr.content_hash,
+    mr.skip_reason, mr.skipped_by,
     m.path as model_path, m.name as model_name
 FROM model_runs mr
 JOIN models m ON mr.model_id = m.id
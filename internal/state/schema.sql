@@ -38,10 +38,18 @@ CREATE TABLE IF NOT EXISTS models (
     sql_content TEXT DEFAULT '',    -- Rendered SQL (macros expanded)
     raw_content TEXT DEFAULT '',    -- Original file content
     description TEXT DEFAULT '',    -- Model description
+    -- Versioning fields from frontmatter
+    version INTEGER DEFAULT 0,      -- Model version (0 = unversioned)
+    deprecation TEXT,               -- JSON object: {"Since": "...", "Replacement": "..."}
+    access TEXT DEFAULT '',         -- public, protected, private ('' = protected)
+    group_name TEXT DEFAULT '',     -- Owning group from frontmatter
+    pii TEXT,                       -- JSON array of PII columns: ["email", "phone"]
+    sources TEXT,                   -- JSON array of referenced tables: ["raw.orders"]
+    partition_by TEXT,              -- JSON object: {"Column": "order_date", "Grain": "day"}
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     
-    CHECK (materialized IN ('table', 'view', 'incremental'))
+    CHECK (materialized IN ('table', 'view', 'incremental', 'external'))
 );
 
 CREATE INDEX IF NOT EXISTS idx_models_path ON models(path);
@@ -60,6 +68,13 @@ CREATE TABLE IF NOT EXISTS model_runs (
     error TEXT,
     render_ms INTEGER DEFAULT 0,
     execution_ms INTEGER DEFAULT 0,
+    bytes_scanned INTEGER,
+    slot_ms INTEGER,
+    build_mode TEXT, -- full, incremental, full_refresh (NULL if not built)
+    row_filter TEXT, -- Row filter applied to the model's query (NULL if none)
+    content_hash TEXT, -- Content hash of the model when it ran
+    skip_reason TEXT, -- Why the model was skipped (NULL if it was not)
+    skipped_by TEXT, -- Path of the model whose failure caused the skip
     
     FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE,
     FOREIGN KEY (model_id) REFERENCES models(id) ON DELETE CASCADE,
@@ -175,6 +190,17 @@ CREATE TABLE IF NOT EXISTS file_hashes (
 
 CREATE INDEX IF NOT EXISTS idx_file_hashes_type ON file_hashes(file_type);
 
+-- model_builds: last successful build of each model per target
+-- Used by the build cache to skip models whose build inputs are unchanged
+CREATE TABLE IF NOT EXISTS model_builds (
+    model_id TEXT NOT NULL,
+    target TEXT NOT NULL,
+    build_hash TEXT NOT NULL,  -- Hash of compiled SQL, upstream builds and target
+    run_id TEXT NOT NULL,      -- Run that built the model
+    built_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (model_id, target)
+);
+
 -- column_snapshots: store known-good column state after successful runs
 -- Used by schema drift detection to compare current vs. last-known state
 CREATE TABLE IF NOT EXISTS column_snapshots (
@@ -192,6 +218,73 @@ CREATE INDEX IF NOT EXISTS idx_snapshots_model ON column_snapshots(model_path);
 CREATE INDEX IF NOT EXISTS idx_snapshots_source ON column_snapshots(source_table);
 CREATE INDEX IF NOT EXISTS idx_snapshots_run_id ON column_snapshots(run_id);
 
+-- model_plans: query plans of model builds captured with EXPLAIN
+-- Used by plan regression detection to compare a model's plan between runs
+CREATE TABLE IF NOT EXISTS model_plans (
+    model_path TEXT NOT NULL,
+    run_id TEXT NOT NULL,
+    plan TEXT NOT NULL,  -- EXPLAIN output
+    captured_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (model_path, run_id),
+    FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_model_plans_run_id ON model_plans(run_id);
+
+-- model_metrics: per-run row counts and null rates of models with anomaly tests
+-- Used by anomaly tests to compare a model's output with previous runs
+CREATE TABLE IF NOT EXISTS model_metrics (
+    run_id TEXT NOT NULL,
+    model_path TEXT NOT NULL,
+    metric TEXT NOT NULL,                -- row_count, null_rate
+    column_name TEXT NOT NULL DEFAULT '', -- Column of a null rate ('' for row counts)
+    value REAL NOT NULL,
+    recorded_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (run_id, model_path, metric, column_name),
+    FOREIGN KEY (run_id) REFERENCES runs(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_model_metrics_model ON model_metrics(model_path, metric, column_name);
+
+-- backfills: backfills of incremental models over a range of dates
+-- Used by backfill to resume an interrupted backfill after its completed chunks
+CREATE TABLE IF NOT EXISTS backfills (
+    id TEXT PRIMARY KEY,
+    model_path TEXT NOT NULL,
+    column_name TEXT NOT NULL,           -- Date column the range applies to
+    range_start TEXT NOT NULL,           -- First date of the range (YYYY-MM-DD)
+    range_end TEXT NOT NULL,             -- Date the range ends before (YYYY-MM-DD)
+    chunk TEXT NOT NULL,                 -- day, week, month
+    status TEXT NOT NULL DEFAULT 'running', -- running, completed, failed
+    error TEXT,
+    started_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_backfills_model ON backfills(model_path, status);
+
+-- backfill_chunks: the chunks of backfills committed so far
+CREATE TABLE IF NOT EXISTS backfill_chunks (
+    backfill_id TEXT NOT NULL,
+    chunk_start TEXT NOT NULL,           -- First date of the chunk (YYYY-MM-DD)
+    chunk_end TEXT NOT NULL,             -- Date the chunk ends before (YYYY-MM-DD)
+    run_id TEXT NOT NULL,                -- Run that built the chunk
+    rows_affected INTEGER NOT NULL DEFAULT 0,
+    completed_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (backfill_id, chunk_start),
+    FOREIGN KEY (backfill_id) REFERENCES backfills(id) ON DELETE CASCADE
+);
+
+-- source_snapshots: when the sources of a model last changed, as seen before its last build
+-- Used by run --skip-fresh to skip models whose sources are unchanged
+CREATE TABLE IF NOT EXISTS source_snapshots (
+    model_path TEXT NOT NULL,
+    target TEXT NOT NULL,                -- Database the model was built in
+    source TEXT NOT NULL,                -- Table or file path the model reads
+    changed_at DATETIME NOT NULL,        -- Latest loaded_at or file modification time
+    PRIMARY KEY (model_path, target, source)
+);
+
 -- project_meta: key-value store for project-level metadata
 CREATE TABLE IF NOT EXISTS project_meta (
     key TEXT PRIMARY KEY,
@@ -199,7 +292,7 @@ CREATE TABLE IF NOT EXISTS project_meta (
 );
 
 -- ============================================================================
--- Views for Docs (defined in migration 00004)
+-- Views for Docs (defined in migration 00004, v_models updated in 00024)
 -- These views are used by the docs system for frontend consumption
 -- ============================================================================
 
@@ -224,6 +317,7 @@ SELECT
     tests,
     meta,
     uses_select_star,
+    partition_by,
     created_at,
     updated_at
 FROM models;
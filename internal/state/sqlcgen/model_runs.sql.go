@@ -10,8 +10,22 @@ import (
 	"time"
 )
 
+const deleteOrphanedModelRuns = `-- name: DeleteOrphanedModelRuns :execrows
+DELETE FROM model_runs
+WHERE run_id NOT IN (SELECT id FROM runs)
+   OR model_id NOT IN (SELECT id FROM models)
+`
+
+func (q *Queries) DeleteOrphanedModelRuns(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOrphanedModelRuns)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getLatestModelRun = `-- name: GetLatestModelRun :one
-SELECT id, run_id, model_id, status, rows_affected, started_at, completed_at, error, render_ms, execution_ms
+SELECT id, run_id, model_id, status, rows_affected, started_at, completed_at, error, render_ms, execution_ms, bytes_scanned, slot_ms, build_mode, row_filter, content_hash, skip_reason, skipped_by
 FROM model_runs
 WHERE model_id = ?
 ORDER BY started_at DESC
@@ -32,6 +46,46 @@ func (q *Queries) GetLatestModelRun(ctx context.Context, modelID string) (ModelR
 		&i.Error,
 		&i.RenderMs,
 		&i.ExecutionMs,
+		&i.BytesScanned,
+		&i.SlotMs,
+		&i.BuildMode,
+		&i.RowFilter,
+		&i.ContentHash,
+		&i.SkipReason,
+		&i.SkippedBy,
+	)
+	return i, err
+}
+
+const getLatestSuccessfulModelRun = `-- name: GetLatestSuccessfulModelRun :one
+SELECT id, run_id, model_id, status, rows_affected, started_at, completed_at, error, render_ms, execution_ms, bytes_scanned, slot_ms, build_mode, row_filter, content_hash, skip_reason, skipped_by
+FROM model_runs
+WHERE model_id = ? AND status = 'success'
+ORDER BY started_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestSuccessfulModelRun(ctx context.Context, modelID string) (ModelRun, error) {
+	row := q.db.QueryRowContext(ctx, getLatestSuccessfulModelRun, modelID)
+	var i ModelRun
+	err := row.Scan(
+		&i.ID,
+		&i.RunID,
+		&i.ModelID,
+		&i.Status,
+		&i.RowsAffected,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.Error,
+		&i.RenderMs,
+		&i.ExecutionMs,
+		&i.BytesScanned,
+		&i.SlotMs,
+		&i.BuildMode,
+		&i.RowFilter,
+		&i.ContentHash,
+		&i.SkipReason,
+		&i.SkippedBy,
 	)
 	return i, err
 }
@@ -48,7 +102,7 @@ func (q *Queries) GetModelRunStartedAt(ctx context.Context, id string) (time.Tim
 }
 
 const getModelRunsForRun = `-- name: GetModelRunsForRun :many
-SELECT id, run_id, model_id, status, rows_affected, started_at, completed_at, error, render_ms, execution_ms
+SELECT id, run_id, model_id, status, rows_affected, started_at, completed_at, error, render_ms, execution_ms, bytes_scanned, slot_ms, build_mode, row_filter, content_hash, skip_reason, skipped_by
 FROM model_runs
 WHERE run_id = ?
 ORDER BY started_at
@@ -74,6 +128,13 @@ func (q *Queries) GetModelRunsForRun(ctx context.Context, runID string) ([]Model
 			&i.Error,
 			&i.RenderMs,
 			&i.ExecutionMs,
+			&i.BytesScanned,
+			&i.SlotMs,
+			&i.BuildMode,
+			&i.RowFilter,
+			&i.ContentHash,
+			&i.SkipReason,
+			&i.SkippedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -92,7 +153,8 @@ const getModelRunsWithModelInfo = `-- name: GetModelRunsWithModelInfo :many
 SELECT
     mr.id, mr.run_id, mr.model_id, mr.status,
     mr.rows_affected, mr.started_at, mr.completed_at,
-    mr.error, mr.render_ms, mr.execution_ms,
+    mr.error, mr.render_ms, mr.execution_ms, mr.build_mode, mr.row_filter, mr.content_hash,
+    mr.skip_reason, mr.skipped_by,
     m.path as model_path, m.name as model_name
 FROM model_runs mr
 JOIN models m ON mr.model_id = m.id
@@ -111,6 +173,11 @@ type GetModelRunsWithModelInfoRow struct {
 	Error        *string    `json:"error"`
 	RenderMs     *int64     `json:"render_ms"`
 	ExecutionMs  *int64     `json:"execution_ms"`
+	BuildMode    *string    `json:"build_mode"`
+	RowFilter    *string    `json:"row_filter"`
+	ContentHash  *string    `json:"content_hash"`
+	SkipReason   *string    `json:"skip_reason"`
+	SkippedBy    *string    `json:"skipped_by"`
 	ModelPath    string     `json:"model_path"`
 	ModelName    string     `json:"model_name"`
 }
@@ -135,6 +202,11 @@ func (q *Queries) GetModelRunsWithModelInfo(ctx context.Context, runID string) (
 			&i.Error,
 			&i.RenderMs,
 			&i.ExecutionMs,
+			&i.BuildMode,
+			&i.RowFilter,
+			&i.ContentHash,
+			&i.SkipReason,
+			&i.SkippedBy,
 			&i.ModelPath,
 			&i.ModelName,
 		); err != nil {
@@ -152,8 +224,8 @@ func (q *Queries) GetModelRunsWithModelInfo(ctx context.Context, runID string) (
 }
 
 const recordModelRun = `-- name: RecordModelRun :exec
-INSERT INTO model_runs (id, run_id, model_id, status, rows_affected, started_at, error, render_ms, execution_ms)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+INSERT INTO model_runs (id, run_id, model_id, status, rows_affected, started_at, error, render_ms, execution_ms, row_filter, content_hash)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type RecordModelRunParams struct {
@@ -166,6 +238,8 @@ type RecordModelRunParams struct {
 	Error        *string   `json:"error"`
 	RenderMs     *int64    `json:"render_ms"`
 	ExecutionMs  *int64    `json:"execution_ms"`
+	RowFilter    *string   `json:"row_filter"`
+	ContentHash  *string   `json:"content_hash"`
 }
 
 func (q *Queries) RecordModelRun(ctx context.Context, arg RecordModelRunParams) error {
@@ -179,6 +253,8 @@ func (q *Queries) RecordModelRun(ctx context.Context, arg RecordModelRunParams)
 		arg.Error,
 		arg.RenderMs,
 		arg.ExecutionMs,
+		arg.RowFilter,
+		arg.ContentHash,
 	)
 	return err
 }
@@ -211,3 +287,53 @@ func (q *Queries) UpdateModelRun(ctx context.Context, arg UpdateModelRunParams)
 	)
 	return err
 }
+
+const updateModelRunBuildMode = `-- name: UpdateModelRunBuildMode :exec
+UPDATE model_runs
+SET build_mode = ?
+WHERE id = ?
+`
+
+type UpdateModelRunBuildModeParams struct {
+	BuildMode *string `json:"build_mode"`
+	ID        string  `json:"id"`
+}
+
+func (q *Queries) UpdateModelRunBuildMode(ctx context.Context, arg UpdateModelRunBuildModeParams) error {
+	_, err := q.db.ExecContext(ctx, updateModelRunBuildMode, arg.BuildMode, arg.ID)
+	return err
+}
+
+const updateModelRunSkip = `-- name: UpdateModelRunSkip :exec
+UPDATE model_runs
+SET skip_reason = ?, skipped_by = ?
+WHERE id = ?
+`
+
+type UpdateModelRunSkipParams struct {
+	SkipReason *string `json:"skip_reason"`
+	SkippedBy  *string `json:"skipped_by"`
+	ID         string  `json:"id"`
+}
+
+func (q *Queries) UpdateModelRunSkip(ctx context.Context, arg UpdateModelRunSkipParams) error {
+	_, err := q.db.ExecContext(ctx, updateModelRunSkip, arg.SkipReason, arg.SkippedBy, arg.ID)
+	return err
+}
+
+const updateModelRunCost = `-- name: UpdateModelRunCost :exec
+UPDATE model_runs
+SET bytes_scanned = ?, slot_ms = ?
+WHERE id = ?
+`
+
+type UpdateModelRunCostParams struct {
+	BytesScanned *int64 `json:"bytes_scanned"`
+	SlotMs       *int64 `json:"slot_ms"`
+	ID           string `json:"id"`
+}
+
+func (q *Queries) UpdateModelRunCost(ctx context.Context, arg UpdateModelRunCostParams) error {
+	_, err := q.db.ExecContext(ctx, updateModelRunCost, arg.BytesScanned, arg.SlotMs, arg.ID)
+	return err
+}
@@ -76,10 +76,25 @@ type Model struct {
 	SqlContent     *string   `json:"sql_content"`
 	RawContent     *string   `json:"raw_content"`
 	Description    *string   `json:"description"`
+	Version        *int64    `json:"version"`
+	Deprecation    *string   `json:"deprecation"`
+	Access         *string   `json:"access"`
+	GroupName      *string   `json:"group_name"`
+	Pii            *string   `json:"pii"`
+	Sources        *string   `json:"sources"`
+	PartitionBy    *string   `json:"partition_by"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
 
+type ModelBuild struct {
+	ModelID   string    `json:"model_id"`
+	Target    string    `json:"target"`
+	BuildHash string    `json:"build_hash"`
+	RunID     string    `json:"run_id"`
+	BuiltAt   time.Time `json:"built_at"`
+}
+
 type ModelColumn struct {
 	ModelPath     string  `json:"model_path"`
 	ColumnName    string  `json:"column_name"`
@@ -99,6 +114,13 @@ type ModelRun struct {
 	Error        *string    `json:"error"`
 	RenderMs     *int64     `json:"render_ms"`
 	ExecutionMs  *int64     `json:"execution_ms"`
+	BytesScanned *int64     `json:"bytes_scanned"`
+	SlotMs       *int64     `json:"slot_ms"`
+	BuildMode    *string    `json:"build_mode"`
+	RowFilter    *string    `json:"row_filter"`
+	ContentHash  *string    `json:"content_hash"`
+	SkipReason   *string    `json:"skip_reason"`
+	SkippedBy    *string    `json:"skipped_by"`
 }
 
 type ModelsFt struct {
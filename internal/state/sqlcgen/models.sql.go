@@ -21,7 +21,7 @@ func (q *Queries) DeleteModelByFilePath(ctx context.Context, filePath *string) e
 
 const getModelByFilePath = `-- name: GetModelByFilePath :one
 SELECT id, path, name, materialized, unique_key, content_hash, file_path,
-    owner, schema_name, tags, tests, meta, uses_select_star, sql_content, raw_content, description, created_at, updated_at
+    owner, schema_name, tags, tests, meta, uses_select_star, sql_content, raw_content, description, version, deprecation, access, group_name, pii, sources, partition_by, created_at, updated_at
 FROM models
 WHERE file_path = ?
 `
@@ -46,6 +46,13 @@ func (q *Queries) GetModelByFilePath(ctx context.Context, filePath *string) (Mod
 		&i.SqlContent,
 		&i.RawContent,
 		&i.Description,
+		&i.Version,
+		&i.Deprecation,
+		&i.Access,
+		&i.GroupName,
+		&i.Pii,
+		&i.Sources,
+		&i.PartitionBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -54,7 +61,7 @@ func (q *Queries) GetModelByFilePath(ctx context.Context, filePath *string) (Mod
 
 const getModelByID = `-- name: GetModelByID :one
 SELECT id, path, name, materialized, unique_key, content_hash, file_path,
-    owner, schema_name, tags, tests, meta, uses_select_star, sql_content, raw_content, description, created_at, updated_at
+    owner, schema_name, tags, tests, meta, uses_select_star, sql_content, raw_content, description, version, deprecation, access, group_name, pii, sources, partition_by, created_at, updated_at
 FROM models
 WHERE id = ?
 `
@@ -79,6 +86,13 @@ func (q *Queries) GetModelByID(ctx context.Context, id string) (Model, error) {
 		&i.SqlContent,
 		&i.RawContent,
 		&i.Description,
+		&i.Version,
+		&i.Deprecation,
+		&i.Access,
+		&i.GroupName,
+		&i.Pii,
+		&i.Sources,
+		&i.PartitionBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -87,7 +101,7 @@ func (q *Queries) GetModelByID(ctx context.Context, id string) (Model, error) {
 
 const getModelByPath = `-- name: GetModelByPath :one
 SELECT id, path, name, materialized, unique_key, content_hash, file_path,
-    owner, schema_name, tags, tests, meta, uses_select_star, sql_content, raw_content, description, created_at, updated_at
+    owner, schema_name, tags, tests, meta, uses_select_star, sql_content, raw_content, description, version, deprecation, access, group_name, pii, sources, partition_by, created_at, updated_at
 FROM models
 WHERE path = ?
 `
@@ -112,6 +126,13 @@ func (q *Queries) GetModelByPath(ctx context.Context, path string) (Model, error
 		&i.SqlContent,
 		&i.RawContent,
 		&i.Description,
+		&i.Version,
+		&i.Deprecation,
+		&i.Access,
+		&i.GroupName,
+		&i.Pii,
+		&i.Sources,
+		&i.PartitionBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -120,8 +141,8 @@ func (q *Queries) GetModelByPath(ctx context.Context, path string) (Model, error
 
 const insertModel = `-- name: InsertModel :exec
 INSERT INTO models (id, path, name, materialized, unique_key, content_hash, file_path,
-    owner, schema_name, tags, tests, meta, uses_select_star, sql_content, raw_content, description, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    owner, schema_name, tags, tests, meta, uses_select_star, sql_content, raw_content, description, version, deprecation, access, group_name, pii, sources, partition_by, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 `
 
 type InsertModelParams struct {
@@ -141,6 +162,13 @@ type InsertModelParams struct {
 	SqlContent     *string   `json:"sql_content"`
 	RawContent     *string   `json:"raw_content"`
 	Description    *string   `json:"description"`
+	Version        *int64    `json:"version"`
+	Deprecation    *string   `json:"deprecation"`
+	Access         *string   `json:"access"`
+	GroupName      *string   `json:"group_name"`
+	Pii            *string   `json:"pii"`
+	Sources        *string   `json:"sources"`
+	PartitionBy    *string   `json:"partition_by"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
@@ -163,6 +191,13 @@ func (q *Queries) InsertModel(ctx context.Context, arg InsertModelParams) error
 		arg.SqlContent,
 		arg.RawContent,
 		arg.Description,
+		arg.Version,
+		arg.Deprecation,
+		arg.Access,
+		arg.GroupName,
+		arg.Pii,
+		arg.Sources,
+		arg.PartitionBy,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 	)
@@ -198,7 +233,7 @@ func (q *Queries) ListModelFilePaths(ctx context.Context) ([]*string, error) {
 
 const listModels = `-- name: ListModels :many
 SELECT id, path, name, materialized, unique_key, content_hash, file_path,
-    owner, schema_name, tags, tests, meta, uses_select_star, sql_content, raw_content, description, created_at, updated_at
+    owner, schema_name, tags, tests, meta, uses_select_star, sql_content, raw_content, description, version, deprecation, access, group_name, pii, sources, partition_by, created_at, updated_at
 FROM models
 ORDER BY path
 `
@@ -229,6 +264,13 @@ func (q *Queries) ListModels(ctx context.Context) ([]Model, error) {
 			&i.SqlContent,
 			&i.RawContent,
 			&i.Description,
+			&i.Version,
+			&i.Deprecation,
+			&i.Access,
+			&i.GroupName,
+			&i.Pii,
+			&i.Sources,
+			&i.PartitionBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -249,7 +291,7 @@ const updateModel = `-- name: UpdateModel :exec
 UPDATE models
 SET name = ?, materialized = ?, unique_key = ?, content_hash = ?, file_path = ?,
     owner = ?, schema_name = ?, tags = ?, tests = ?, meta = ?, uses_select_star = ?, 
-    sql_content = ?, raw_content = ?, description = ?, updated_at = ?
+    sql_content = ?, raw_content = ?, description = ?, version = ?, deprecation = ?, access = ?, group_name = ?, pii = ?, sources = ?, partition_by = ?, updated_at = ?
 WHERE id = ?
 `
 
@@ -268,6 +310,13 @@ type UpdateModelParams struct {
 	SqlContent     *string   `json:"sql_content"`
 	RawContent     *string   `json:"raw_content"`
 	Description    *string   `json:"description"`
+	Version        *int64    `json:"version"`
+	Deprecation    *string   `json:"deprecation"`
+	Access         *string   `json:"access"`
+	GroupName      *string   `json:"group_name"`
+	Pii            *string   `json:"pii"`
+	Sources        *string   `json:"sources"`
+	PartitionBy    *string   `json:"partition_by"`
 	UpdatedAt      time.Time `json:"updated_at"`
 	ID             string    `json:"id"`
 }
@@ -288,6 +337,13 @@ func (q *Queries) UpdateModel(ctx context.Context, arg UpdateModelParams) error
 		arg.SqlContent,
 		arg.RawContent,
 		arg.Description,
+		arg.Version,
+		arg.Deprecation,
+		arg.Access,
+		arg.GroupName,
+		arg.Pii,
+		arg.Sources,
+		arg.PartitionBy,
 		arg.UpdatedAt,
 		arg.ID,
 	)
@@ -49,6 +49,11 @@ func (s *SQLiteStore) Open(path string) error {
 		return fmt.Errorf("failed to open sqlite database: %w", err)
 	}
 
+	// SQLite allows one writer at a time, and each connection to an in-memory
+	// database opens a database of its own, so runs building models at once
+	// share a single connection
+	db.SetMaxOpenConns(1)
+
 	// Test connection
 	if err := db.PingContext(context.Background()); err != nil {
 		_ = db.Close()
@@ -92,8 +97,11 @@ func (s *SQLiteStore) DB() *sql.DB {
 	return s.db
 }
 
-// Ensure SQLiteStore implements Store interface
-var _ core.Store = (*SQLiteStore)(nil)
+// Ensure SQLiteStore implements Store and Locker interfaces
+var (
+	_ core.Store  = (*SQLiteStore)(nil)
+	_ core.Locker = (*SQLiteStore)(nil)
+)
 
 // --- Helper functions ---
 
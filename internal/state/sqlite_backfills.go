@@ -0,0 +1,180 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// backfillDate is the layout of the dates of backfill ranges and chunks.
+const backfillDate = "2006-01-02"
+
+// CreateBackfill records a new running backfill, setting its ID and start
+// time.
+func (s *SQLiteStore) CreateBackfill(backfill *core.Backfill) error {
+	if s.db == nil {
+		return fmt.Errorf("database not open")
+	}
+
+	backfill.ID = generateID()
+	backfill.Status = core.BackfillStatusRunning
+	backfill.StartedAt = time.Now().UTC()
+
+	_, err := s.db.ExecContext(context.Background(), `
+		INSERT INTO backfills (id, model_path, column_name, range_start, range_end, chunk, status, started_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, backfill.ID, backfill.ModelPath, backfill.Column, backfill.Start.Format(backfillDate),
+		backfill.End.Format(backfillDate), string(backfill.Chunk), string(backfill.Status), backfill.StartedAt)
+	if err != nil {
+		return fmt.Errorf("create backfill of %s: %w", backfill.ModelPath, err)
+	}
+	return nil
+}
+
+// GetBackfill retrieves a backfill by ID.
+func (s *SQLiteStore) GetBackfill(id string) (*core.Backfill, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not open")
+	}
+
+	backfill, err := s.queryBackfill(`
+		SELECT id, model_path, column_name, range_start, range_end, chunk, status, error, started_at, completed_at
+		FROM backfills WHERE id = ?
+	`, id)
+	if err == nil && backfill == nil {
+		return nil, fmt.Errorf("backfill not found: %s", id)
+	}
+	return backfill, err
+}
+
+// GetUnfinishedBackfill returns the latest backfill of a model over the same
+// column, range and chunk size that did not complete, the one a new backfill
+// resumes. It returns nil if there is none.
+func (s *SQLiteStore) GetUnfinishedBackfill(modelPath, column string, start, end time.Time, chunk core.BackfillChunkSize) (*core.Backfill, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not open")
+	}
+
+	return s.queryBackfill(`
+		SELECT id, model_path, column_name, range_start, range_end, chunk, status, error, started_at, completed_at
+		FROM backfills
+		WHERE model_path = ? AND column_name = ? AND range_start = ? AND range_end = ? AND chunk = ? AND status != ?
+		ORDER BY started_at DESC, rowid DESC
+		LIMIT 1
+	`, modelPath, column, start.Format(backfillDate), end.Format(backfillDate), string(chunk), string(core.BackfillStatusCompleted))
+}
+
+// CompleteBackfill marks a backfill as finished with the given status. A
+// failed backfill is resumed by the next backfill over the same range.
+func (s *SQLiteStore) CompleteBackfill(id string, status core.BackfillStatus, errMsg string) error {
+	if s.db == nil {
+		return fmt.Errorf("database not open")
+	}
+
+	var errValue sql.NullString
+	if errMsg != "" {
+		errValue = sql.NullString{String: errMsg, Valid: true}
+	}
+	_, err := s.db.ExecContext(context.Background(), `
+		UPDATE backfills SET status = ?, error = ?, completed_at = ? WHERE id = ?
+	`, string(status), errValue, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("complete backfill %s: %w", id, err)
+	}
+	return nil
+}
+
+// RecordBackfillChunk records a completed chunk of a backfill, replacing an
+// earlier record of the same chunk.
+func (s *SQLiteStore) RecordBackfillChunk(chunk *core.BackfillChunk) error {
+	if s.db == nil {
+		return fmt.Errorf("database not open")
+	}
+
+	chunk.CompletedAt = time.Now().UTC()
+	_, err := s.db.ExecContext(context.Background(), `
+		INSERT OR REPLACE INTO backfill_chunks
+		(backfill_id, chunk_start, chunk_end, run_id, rows_affected, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, chunk.BackfillID, chunk.Start.Format(backfillDate), chunk.End.Format(backfillDate),
+		chunk.RunID, chunk.RowsAffected, chunk.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("record chunk %s of backfill %s: %w", chunk.Start.Format(backfillDate), chunk.BackfillID, err)
+	}
+	return nil
+}
+
+// GetBackfillChunks returns the completed chunks of a backfill, in date
+// order.
+func (s *SQLiteStore) GetBackfillChunks(backfillID string) ([]*core.BackfillChunk, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not open")
+	}
+
+	rows, err := s.db.QueryContext(context.Background(), `
+		SELECT backfill_id, chunk_start, chunk_end, run_id, rows_affected, completed_at
+		FROM backfill_chunks WHERE backfill_id = ?
+		ORDER BY chunk_start
+	`, backfillID)
+	if err != nil {
+		return nil, fmt.Errorf("query backfill chunks: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var chunks []*core.BackfillChunk
+	for rows.Next() {
+		c := &core.BackfillChunk{}
+		var start, end string
+		if err := rows.Scan(&c.BackfillID, &start, &end, &c.RunID, &c.RowsAffected, &c.CompletedAt); err != nil {
+			return nil, fmt.Errorf("scan backfill chunk: %w", err)
+		}
+		if c.Start, err = time.Parse(backfillDate, start); err != nil {
+			return nil, fmt.Errorf("parse chunk start: %w", err)
+		}
+		if c.End, err = time.Parse(backfillDate, end); err != nil {
+			return nil, fmt.Errorf("parse chunk end: %w", err)
+		}
+		chunks = append(chunks, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return chunks, nil
+}
+
+// queryBackfill returns the backfill a query selects, or nil if it selects
+// none.
+func (s *SQLiteStore) queryBackfill(query string, args ...any) (*core.Backfill, error) {
+	b := &core.Backfill{}
+	var start, end, chunk, status string
+	var errMsg sql.NullString
+	var completedAt sql.NullTime
+	err := s.db.QueryRowContext(context.Background(), query, args...).Scan(
+		&b.ID, &b.ModelPath, &b.Column, &start, &end, &chunk, &status, &errMsg, &b.StartedAt, &completedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query backfill: %w", err)
+	}
+
+	if b.Start, err = time.Parse(backfillDate, start); err != nil {
+		return nil, fmt.Errorf("parse backfill start: %w", err)
+	}
+	if b.End, err = time.Parse(backfillDate, end); err != nil {
+		return nil, fmt.Errorf("parse backfill end: %w", err)
+	}
+	b.Chunk = core.BackfillChunkSize(chunk)
+	b.Status = core.BackfillStatus(status)
+	b.Error = errMsg.String
+	if completedAt.Valid {
+		b.CompletedAt = &completedAt.Time
+	}
+	return b, nil
+}
@@ -0,0 +1,72 @@
+package state
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/internal/state/sqlcgen"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// GetModelBuild retrieves the last successful build of a model in a target.
+// It returns nil if the model has not been built in the target.
+func (s *SQLiteStore) GetModelBuild(modelID, target string) (*core.ModelBuild, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not opened")
+	}
+
+	row, err := s.queries.GetModelBuild(ctx(), sqlcgen.GetModelBuildParams{
+		ModelID: modelID,
+		Target:  target,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get model build: %w", err)
+	}
+
+	return &core.ModelBuild{
+		ModelID:   row.ModelID,
+		Target:    row.Target,
+		BuildHash: row.BuildHash,
+		RunID:     row.RunID,
+		BuiltAt:   row.BuiltAt,
+	}, nil
+}
+
+// SaveModelBuild records a successful build, replacing the previous build of
+// the model in the same target.
+func (s *SQLiteStore) SaveModelBuild(build *core.ModelBuild) error {
+	if s.db == nil {
+		return fmt.Errorf("database not opened")
+	}
+
+	err := s.queries.SaveModelBuild(ctx(), sqlcgen.SaveModelBuildParams{
+		ModelID:   build.ModelID,
+		Target:    build.Target,
+		BuildHash: build.BuildHash,
+		RunID:     build.RunID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save model build: %w", err)
+	}
+	return nil
+}
+
+// DeleteModelBuild removes the build record of a model in a target, so its
+// next run rebuilds it.
+func (s *SQLiteStore) DeleteModelBuild(modelID, target string) error {
+	if s.db == nil {
+		return fmt.Errorf("database not opened")
+	}
+
+	if err := s.queries.DeleteModelBuild(ctx(), sqlcgen.DeleteModelBuildParams{
+		ModelID: modelID,
+		Target:  target,
+	}); err != nil {
+		return fmt.Errorf("failed to delete model build: %w", err)
+	}
+	return nil
+}
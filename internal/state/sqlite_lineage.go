@@ -45,11 +45,18 @@ func (s *SQLiteStore) SaveModelColumns(modelPath string, columns []core.ColumnIn
 			return fmt.Errorf("failed to insert column %s: %w", col.Name, err)
 		}
 
-		// Insert source lineage for this column
+		// Insert source lineage for this column. The catalog tracks lineage at
+		// column granularity, so struct/JSON fields of one column collapse to a single row.
+		seen := make(map[core.SourceRef]struct{}, len(col.Sources))
 		for _, src := range col.Sources {
 			if src.Table == "" && src.Column == "" {
 				continue // Skip empty sources
 			}
+			key := core.SourceRef{Table: src.Table, Column: src.Column}
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
 			if err := qtx.InsertColumnLineage(ctx(), sqlcgen.InsertColumnLineageParams{
 				ModelPath:    modelPath,
 				ColumnName:   col.Name,
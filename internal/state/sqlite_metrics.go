@@ -0,0 +1,101 @@
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// SaveModelMetrics stores the metrics of model builds, replacing metrics
+// already recorded for the same run, model, metric and column.
+// This is used by anomaly tests to compare a model's output between runs.
+func (s *SQLiteStore) SaveModelMetrics(metrics []*core.ModelMetric) error {
+	if s.db == nil {
+		return fmt.Errorf("database not open")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT OR REPLACE INTO model_metrics
+		(run_id, model_path, metric, column_name, value)
+		VALUES (?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return fmt.Errorf("prepare statement: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, m := range metrics {
+		if _, err := stmt.ExecContext(ctx, m.RunID, m.ModelPath, m.Metric, m.Column, m.Value); err != nil {
+			return fmt.Errorf("insert %s metric for %s: %w", m.Metric, m.ModelPath, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetModelMetrics returns the most recent values of a model's metric, newest
+// first. Column is empty for row counts. A limit of 0 or less returns all of
+// them.
+func (s *SQLiteStore) GetModelMetrics(modelPath, metric, column string, limit int) ([]*core.ModelMetric, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not open")
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+
+	return s.queryModelMetrics(`
+		SELECT run_id, model_path, metric, column_name, value, recorded_at FROM model_metrics
+		WHERE model_path = ? AND metric = ? AND column_name = ?
+		ORDER BY recorded_at DESC, rowid DESC
+		LIMIT ?
+	`, modelPath, metric, column, limit)
+}
+
+// GetModelMetricsForRun returns the metrics recorded during a run.
+func (s *SQLiteStore) GetModelMetricsForRun(runID string) ([]*core.ModelMetric, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not open")
+	}
+
+	return s.queryModelMetrics(`
+		SELECT run_id, model_path, metric, column_name, value, recorded_at FROM model_metrics
+		WHERE run_id = ?
+		ORDER BY rowid
+	`, runID)
+}
+
+func (s *SQLiteStore) queryModelMetrics(query string, args ...any) ([]*core.ModelMetric, error) {
+	rows, err := s.db.QueryContext(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query metrics: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var metrics []*core.ModelMetric
+	for rows.Next() {
+		m := &core.ModelMetric{}
+		if err := rows.Scan(&m.RunID, &m.ModelPath, &m.Metric, &m.Column, &m.Value, &m.RecordedAt); err != nil {
+			return nil, fmt.Errorf("scan metric: %w", err)
+		}
+		metrics = append(metrics, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return metrics, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/leapstack-labs/leapsql/internal/state/sqlcgen"
 	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/redact"
 )
 
 // RegisterModel registers a new model or updates an existing one.
@@ -30,6 +31,17 @@ func (s *SQLiteStore) RegisterModel(model *core.PersistedModel) error {
 	tagsJSON := serializeJSONPtr(model.Tags)
 	testsJSON := serializeJSONPtr(model.Tests)
 	metaJSON := serializeJSONPtr(model.Meta)
+	piiJSON := serializeJSONPtr(model.PII)
+	sourcesJSON := serializeJSONPtr(model.Sources)
+	var deprecationJSON *string
+	if model.Deprecated != nil {
+		deprecationJSON = serializeJSONPtr(model.Deprecated)
+	}
+	var partitionByJSON *string
+	if model.PartitionBy != nil {
+		partitionByJSON = serializeJSONPtr(model.PartitionBy)
+	}
+	version := int64(model.Version)
 
 	// Convert bool to int64 for SQLite
 	var usesSelectStar *int64
@@ -70,6 +82,13 @@ func (s *SQLiteStore) RegisterModel(model *core.PersistedModel) error {
 			SqlContent:     nullableString(model.SQL),
 			RawContent:     nullableString(model.RawContent),
 			Description:    nullableString(model.Description),
+			Version:        &version,
+			Deprecation:    deprecationJSON,
+			Access:         nullableString(string(model.Access)),
+			GroupName:      nullableString(model.Group),
+			Pii:            piiJSON,
+			Sources:        sourcesJSON,
+			PartitionBy:    partitionByJSON,
 			UpdatedAt:      model.UpdatedAt,
 			ID:             model.ID,
 		})
@@ -99,6 +118,13 @@ func (s *SQLiteStore) RegisterModel(model *core.PersistedModel) error {
 		SqlContent:     nullableString(model.SQL),
 		RawContent:     nullableString(model.RawContent),
 		Description:    nullableString(model.Description),
+		Version:        &version,
+		Deprecation:    deprecationJSON,
+		Access:         nullableString(string(model.Access)),
+		GroupName:      nullableString(model.Group),
+		Pii:            piiJSON,
+		Sources:        sourcesJSON,
+		PartitionBy:    partitionByJSON,
 		CreatedAt:      model.CreatedAt,
 		UpdatedAt:      model.UpdatedAt,
 	})
@@ -264,7 +290,8 @@ func (s *SQLiteStore) RecordModelRun(modelRun *core.ModelRun) error {
 
 	var errorPtr *string
 	if modelRun.Error != "" {
-		errorPtr = &modelRun.Error
+		errMsg := redact.String(modelRun.Error)
+		errorPtr = &errMsg
 	}
 
 	return s.queries.RecordModelRun(ctx(), sqlcgen.RecordModelRunParams{
@@ -277,6 +304,8 @@ func (s *SQLiteStore) RecordModelRun(modelRun *core.ModelRun) error {
 		Error:        errorPtr,
 		RenderMs:     &modelRun.RenderMS,
 		ExecutionMs:  &modelRun.ExecutionMS,
+		RowFilter:    nullableString(modelRun.RowFilter),
+		ContentHash:  nullableString(modelRun.ContentHash),
 	})
 }
 
@@ -289,6 +318,7 @@ func (s *SQLiteStore) UpdateModelRun(id string, status core.ModelRunStatus, rows
 	now := time.Now().UTC()
 	var errorPtr *string
 	if errMsg != "" {
+		errMsg = redact.String(errMsg)
 		errorPtr = &errMsg
 	}
 
@@ -303,6 +333,56 @@ func (s *SQLiteStore) UpdateModelRun(id string, status core.ModelRunStatus, rows
 	})
 }
 
+// UpdateModelRunCost records the warehouse cost of a model run.
+func (s *SQLiteStore) UpdateModelRunCost(id string, cost core.QueryCost) error {
+	if s.db == nil {
+		return fmt.Errorf("database not opened")
+	}
+
+	return s.queries.UpdateModelRunCost(ctx(), sqlcgen.UpdateModelRunCostParams{
+		BytesScanned: &cost.BytesScanned,
+		SlotMs:       &cost.SlotMS,
+		ID:           id,
+	})
+}
+
+// UpdateModelRunBuildMode records how a model run built the model.
+func (s *SQLiteStore) UpdateModelRunBuildMode(id string, mode core.BuildMode) error {
+	if s.db == nil {
+		return fmt.Errorf("database not opened")
+	}
+
+	buildMode := string(mode)
+	return s.queries.UpdateModelRunBuildMode(ctx(), sqlcgen.UpdateModelRunBuildModeParams{
+		BuildMode: &buildMode,
+		ID:        id,
+	})
+}
+
+// UpdateModelRunSkip records why a skipped model run was skipped and, for
+// skips caused by a failure, the path of the model that failed.
+func (s *SQLiteStore) UpdateModelRunSkip(id string, reason core.SkipReason, skippedBy string) error {
+	if s.db == nil {
+		return fmt.Errorf("database not opened")
+	}
+
+	skipReason := string(reason)
+	return s.queries.UpdateModelRunSkip(ctx(), sqlcgen.UpdateModelRunSkipParams{
+		SkipReason: &skipReason,
+		SkippedBy:  nullableString(skippedBy),
+		ID:         id,
+	})
+}
+
+// DeleteOrphanedModelRuns deletes model runs whose run or model no longer exists.
+func (s *SQLiteStore) DeleteOrphanedModelRuns() (int64, error) {
+	if s.db == nil {
+		return 0, fmt.Errorf("database not opened")
+	}
+
+	return s.queries.DeleteOrphanedModelRuns(ctx())
+}
+
 // GetModelRunsForRun retrieves all model runs for a given pipeline run.
 func (s *SQLiteStore) GetModelRunsForRun(runID string) ([]*core.ModelRun, error) {
 	if s.db == nil {
@@ -339,6 +419,24 @@ func (s *SQLiteStore) GetLatestModelRun(modelID string) (*core.ModelRun, error)
 	return convertModelRun(row), nil
 }
 
+// GetLatestSuccessfulModelRun retrieves the most recent successful model run
+// for a model, or nil if it never built successfully.
+func (s *SQLiteStore) GetLatestSuccessfulModelRun(modelID string) (*core.ModelRun, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not opened")
+	}
+
+	row, err := s.queries.GetLatestSuccessfulModelRun(ctx(), modelID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil // No successful runs found
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest successful model run: %w", err)
+	}
+
+	return convertModelRun(row), nil
+}
+
 // GetModelRunsWithModelInfo retrieves all model runs for a run with model path and name.
 func (s *SQLiteStore) GetModelRunsWithModelInfo(runID string) ([]*core.ModelRunWithInfo, error) {
 	if s.db == nil {
@@ -377,6 +475,21 @@ func (s *SQLiteStore) GetModelRunsWithModelInfo(runID string) ([]*core.ModelRunW
 		if row.ExecutionMs != nil {
 			mr.ExecutionMS = *row.ExecutionMs
 		}
+		if row.BuildMode != nil {
+			mr.BuildMode = core.BuildMode(*row.BuildMode)
+		}
+		if row.RowFilter != nil {
+			mr.RowFilter = *row.RowFilter
+		}
+		if row.ContentHash != nil {
+			mr.ContentHash = *row.ContentHash
+		}
+		if row.SkipReason != nil {
+			mr.SkipReason = core.SkipReason(*row.SkipReason)
+		}
+		if row.SkippedBy != nil {
+			mr.SkippedBy = *row.SkippedBy
+		}
 
 		result = append(result, mr)
 	}
@@ -414,6 +527,15 @@ func convertModel(row sqlcgen.Model) (*core.PersistedModel, error) {
 	if row.Description != nil {
 		coreModel.Description = *row.Description
 	}
+	if row.Version != nil {
+		coreModel.Version = int(*row.Version)
+	}
+	if row.Access != nil {
+		coreModel.Access = core.Access(*row.Access)
+	}
+	if row.GroupName != nil {
+		coreModel.Group = *row.GroupName
+	}
 	// Convert int64 to bool for UsesSelectStar
 	if row.UsesSelectStar != nil && *row.UsesSelectStar == 1 {
 		coreModel.UsesSelectStar = true
@@ -429,6 +551,18 @@ func convertModel(row sqlcgen.Model) (*core.PersistedModel, error) {
 	if err := deserializeJSON(row.Meta, &coreModel.Meta); err != nil {
 		return nil, fmt.Errorf("failed to deserialize meta: %w", err)
 	}
+	if err := deserializeJSON(row.Pii, &coreModel.PII); err != nil {
+		return nil, fmt.Errorf("failed to deserialize pii: %w", err)
+	}
+	if err := deserializeJSON(row.Sources, &coreModel.Sources); err != nil {
+		return nil, fmt.Errorf("failed to deserialize sources: %w", err)
+	}
+	if err := deserializeJSON(row.PartitionBy, &coreModel.PartitionBy); err != nil {
+		return nil, fmt.Errorf("failed to deserialize partition_by: %w", err)
+	}
+	if err := deserializeJSON(row.Deprecation, &coreModel.Deprecated); err != nil {
+		return nil, fmt.Errorf("failed to deserialize deprecation: %w", err)
+	}
 
 	// Create the PersistedModel with embedded Model
 	model := &core.PersistedModel{
@@ -465,6 +599,27 @@ func convertModelRun(row sqlcgen.ModelRun) *core.ModelRun {
 	if row.ExecutionMs != nil {
 		mr.ExecutionMS = *row.ExecutionMs
 	}
+	if row.BytesScanned != nil {
+		mr.BytesScanned = *row.BytesScanned
+	}
+	if row.SlotMs != nil {
+		mr.SlotMS = *row.SlotMs
+	}
+	if row.BuildMode != nil {
+		mr.BuildMode = core.BuildMode(*row.BuildMode)
+	}
+	if row.RowFilter != nil {
+		mr.RowFilter = *row.RowFilter
+	}
+	if row.ContentHash != nil {
+		mr.ContentHash = *row.ContentHash
+	}
+	if row.SkipReason != nil {
+		mr.SkipReason = core.SkipReason(*row.SkipReason)
+	}
+	if row.SkippedBy != nil {
+		mr.SkippedBy = *row.SkippedBy
+	}
 
 	return mr
 }
@@ -0,0 +1,80 @@
+package state
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// SaveModelPlan stores the query plan of a model build.
+// This is used by the PL07 plan regression rule and run reports to detect
+// plan changes between runs.
+func (s *SQLiteStore) SaveModelPlan(plan *core.ModelPlan) error {
+	if s.db == nil {
+		return fmt.Errorf("database not open")
+	}
+
+	_, err := s.db.ExecContext(context.Background(), `
+		INSERT OR REPLACE INTO model_plans (model_path, run_id, plan)
+		VALUES (?, ?, ?)
+	`, plan.ModelPath, plan.RunID, plan.Plan)
+	if err != nil {
+		return fmt.Errorf("save plan for %s: %w", plan.ModelPath, err)
+	}
+	return nil
+}
+
+// GetModelPlans returns the most recent query plans of a model, newest first.
+// A limit of 0 or less returns all of them.
+func (s *SQLiteStore) GetModelPlans(modelPath string, limit int) ([]*core.ModelPlan, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not open")
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+
+	return s.queryModelPlans(`
+		SELECT run_id, model_path, plan, captured_at FROM model_plans
+		WHERE model_path = ?
+		ORDER BY captured_at DESC, rowid DESC
+		LIMIT ?
+	`, modelPath, limit)
+}
+
+// GetModelPlansForRun returns the query plans captured during a run.
+func (s *SQLiteStore) GetModelPlansForRun(runID string) ([]*core.ModelPlan, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not open")
+	}
+
+	return s.queryModelPlans(`
+		SELECT run_id, model_path, plan, captured_at FROM model_plans
+		WHERE run_id = ?
+		ORDER BY rowid
+	`, runID)
+}
+
+func (s *SQLiteStore) queryModelPlans(query string, args ...any) ([]*core.ModelPlan, error) {
+	rows, err := s.db.QueryContext(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query plans: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var plans []*core.ModelPlan
+	for rows.Next() {
+		p := &core.ModelPlan{}
+		if err := rows.Scan(&p.RunID, &p.ModelPath, &p.Plan, &p.CapturedAt); err != nil {
+			return nil, fmt.Errorf("scan plan: %w", err)
+		}
+		plans = append(plans, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return plans, nil
+}
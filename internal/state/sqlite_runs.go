@@ -9,6 +9,7 @@ import (
 
 	"github.com/leapstack-labs/leapsql/internal/state/sqlcgen"
 	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/redact"
 )
 
 // CreateRun creates a new pipeline run.
@@ -61,6 +62,7 @@ func (s *SQLiteStore) CompleteRun(id string, status core.RunStatus, errMsg strin
 	now := time.Now().UTC()
 	var errorPtr *string
 	if errMsg != "" {
+		errMsg = redact.String(errMsg)
 		errorPtr = &errMsg
 	}
 
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
 )
 
 // SaveColumnSnapshot stores the known-good column state after a successful run.
@@ -120,3 +122,70 @@ func (s *SQLiteStore) DeleteOldSnapshots(keepRuns int) error {
 
 	return nil
 }
+
+// SaveSourceSnapshots replaces the source snapshots of a model built in a
+// target with those taken before its latest build.
+func (s *SQLiteStore) SaveSourceSnapshots(modelPath, target string, snapshots []core.SourceSnapshot) error {
+	if s.db == nil {
+		return fmt.Errorf("database not open")
+	}
+
+	ctx := context.Background()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM source_snapshots WHERE model_path = ? AND target = ?
+	`, modelPath, target); err != nil {
+		return fmt.Errorf("delete source snapshots of %s: %w", modelPath, err)
+	}
+	for _, snapshot := range snapshots {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO source_snapshots (model_path, target, source, changed_at)
+			VALUES (?, ?, ?, ?)
+		`, modelPath, target, snapshot.Source, snapshot.ChangedAt.UTC()); err != nil {
+			return fmt.Errorf("insert source snapshot %s of %s: %w", snapshot.Source, modelPath, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetSourceSnapshots returns the source snapshots of a model built in a
+// target, sorted by source.
+func (s *SQLiteStore) GetSourceSnapshots(modelPath, target string) ([]core.SourceSnapshot, error) {
+	if s.db == nil {
+		return nil, fmt.Errorf("database not open")
+	}
+
+	rows, err := s.db.QueryContext(context.Background(), `
+		SELECT source, changed_at FROM source_snapshots
+		WHERE model_path = ? AND target = ?
+		ORDER BY source
+	`, modelPath, target)
+	if err != nil {
+		return nil, fmt.Errorf("query source snapshots: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var snapshots []core.SourceSnapshot
+	for rows.Next() {
+		var snapshot core.SourceSnapshot
+		if err := rows.Scan(&snapshot.Source, &snapshot.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scan source snapshot: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return snapshots, nil
+}
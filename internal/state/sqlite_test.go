@@ -2,16 +2,19 @@ package state
 
 import (
 	"context"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/leapstack-labs/leapsql/internal/testutil"
 	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/redact"
+	"github.com/pressly/goose/v3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-
 func setupTestStore(t *testing.T) *SQLiteStore {
 	t.Helper()
 	store := NewSQLiteStore(testutil.NewTestLogger(t))
@@ -69,6 +72,35 @@ func TestSQLiteStore_InitSchema(t *testing.T) {
 	}
 }
 
+func TestSQLiteStore_MigrateExternalModels(t *testing.T) {
+	store := NewSQLiteStore(testutil.NewTestLogger(t))
+	require.NoError(t, store.Open(filepath.Join(t.TempDir(), "state.db")))
+	defer func() { _ = store.Close() }()
+
+	// A model registered before external models were allowed survives the
+	// rebuild of the models table, along with its search index entry
+	goose.SetBaseFS(migrations)
+	require.NoError(t, goose.SetDialect("sqlite"))
+	require.NoError(t, goose.UpTo(store.db, "migrations", 14))
+	insert := `INSERT INTO models (id, path, name, materialized, content_hash, description) VALUES (?, ?, ?, ?, ?, ?)`
+	_, err := store.db.Exec(insert, "m1", "staging.orders", "orders", "table", "hash1", "Cleaned orders")
+	require.NoError(t, err)
+	_, err = store.db.Exec(insert, "m2", "ml.scores", "scores", "external", "hash2", "")
+	assert.Error(t, err)
+
+	require.NoError(t, store.InitSchema())
+	require.NoError(t, store.RegisterModel(newTestModel("ml.scores", "scores", "external", "hash2")))
+
+	scores, err := store.GetModelByPath("ml.scores")
+	require.NoError(t, err)
+	assert.Equal(t, "external", scores.Materialized)
+
+	results, err := store.SearchModels("cleaned")
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "staging.orders", results[0].Path)
+}
+
 // --- Run lifecycle tests ---
 
 func TestSQLiteStore_RunLifecycle(t *testing.T) {
@@ -145,6 +177,22 @@ func TestSQLiteStore_RunLifecycle(t *testing.T) {
 				assert.Equal(t, "something went wrong", retrieved.Error)
 			},
 		},
+		{
+			name: "complete run with error masks secrets",
+			setup: func(t *testing.T, store *SQLiteStore) *core.Run {
+				redact.Add("hunter22")
+				t.Cleanup(redact.Reset)
+				run, _ := store.CreateRun("dev")
+				return run
+			},
+			operation: func(t *testing.T, store *SQLiteStore, run *core.Run) {
+				require.NoError(t, store.CompleteRun(run.ID, core.RunStatusFailed, "connect postgres://app:hunter22@db: refused"))
+			},
+			verify: func(t *testing.T, store *SQLiteStore, run *core.Run) {
+				retrieved, _ := store.GetRun(run.ID)
+				assert.Equal(t, "connect postgres://app:****@db: refused", retrieved.Error)
+			},
+		},
 		{
 			name: "get latest run",
 			setup: func(_ *testing.T, store *SQLiteStore) *core.Run {
@@ -193,6 +241,40 @@ func TestSQLiteStore_RunLifecycle(t *testing.T) {
 
 // --- Model operations tests ---
 
+func TestSQLiteStore_ConcurrentWrites(t *testing.T) {
+	for _, path := range []string{":memory:", filepath.Join(t.TempDir(), "state.db")} {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			store := NewSQLiteStore(testutil.NewTestLogger(t))
+			require.NoError(t, store.Open(path))
+			require.NoError(t, store.InitSchema())
+			defer func() { _ = store.Close() }()
+
+			// Runs building models at once write to the store together
+			runs := make([]*core.Run, 64)
+			errs := make([]error, len(runs))
+			var wg sync.WaitGroup
+			for i := range runs {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					runs[i], errs[i] = store.CreateRun("dev")
+					if errs[i] == nil {
+						errs[i] = store.CompleteRun(runs[i].ID, core.RunStatusCompleted, "")
+					}
+				}()
+			}
+			wg.Wait()
+
+			for i, run := range runs {
+				require.NoError(t, errs[i])
+				stored, err := store.GetRun(run.ID)
+				require.NoError(t, err)
+				assert.Equal(t, core.RunStatusCompleted, stored.Status)
+			}
+		})
+	}
+}
+
 func TestSQLiteStore_ModelOperations(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -477,7 +559,8 @@ func TestSQLiteStore_ListModels_WithFrontmatterFields(t *testing.T) {
 	models := []*core.PersistedModel{
 		newTestModelFull(&core.Model{
 			Path: "models.list_a", Name: "list_a", Materialized: "table",
-			Owner: "team-a", Tags: []string{"tag-a"},
+			Owner: "team-a", Group: "finance", Tags: []string{"tag-a"}, PII: []string{"email"},
+			Sources: []string{"raw.a", "raw.b"}, PartitionBy: &core.PartitionSpec{Column: "created_at", Grain: core.PartitionGrainMonth},
 		}, "1"),
 		newTestModelFull(&core.Model{
 			Path: "models.list_b", Name: "list_b", Materialized: "table",
@@ -494,10 +577,51 @@ func TestSQLiteStore_ListModels_WithFrontmatterFields(t *testing.T) {
 	require.Len(t, list, 2)
 
 	assert.Equal(t, "team-a", list[0].Owner)
+	assert.Equal(t, "finance", list[0].Group)
+	assert.Equal(t, []string{"email"}, list[0].PII)
+	assert.Equal(t, []string{"raw.a", "raw.b"}, list[0].Sources)
+	assert.Equal(t, &core.PartitionSpec{Column: "created_at", Grain: core.PartitionGrainMonth}, list[0].PartitionBy)
+	assert.Nil(t, list[1].PartitionBy)
 	assert.Equal(t, []string{"tag-a"}, list[0].Tags)
 	assert.Equal(t, "team-b", list[1].Owner)
 }
 
+func TestSQLiteStore_ModelVersioning(t *testing.T) {
+	store := setupTestStore(t)
+	defer func() { _ = store.Close() }()
+
+	deprecated := newTestModelFull(&core.Model{
+		Path: "marts.dim_users_v1", Name: "dim_users", Materialized: "table",
+		Version:    1,
+		Deprecated: &core.Deprecation{Since: "2024-06-01", Replacement: "dim_users@v2"},
+		Access:     core.AccessPrivate,
+	}, "1")
+	current := newTestModelFull(&core.Model{
+		Path: "marts.dim_users_v2", Name: "dim_users", Materialized: "table",
+		Version: 2,
+	}, "2")
+	require.NoError(t, store.RegisterModel(deprecated))
+	require.NoError(t, store.RegisterModel(current))
+
+	got, err := store.GetModelByPath("marts.dim_users_v1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.Version)
+	assert.Equal(t, &core.Deprecation{Since: "2024-06-01", Replacement: "dim_users@v2"}, got.Deprecated)
+	assert.Equal(t, core.AccessPrivate, got.Access)
+
+	got, err = store.GetModelByPath("marts.dim_users_v2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Version)
+	assert.Nil(t, got.Deprecated)
+
+	// Undeprecating a model clears the stored notice
+	deprecated.Deprecated = nil
+	require.NoError(t, store.RegisterModel(deprecated))
+	got, err = store.GetModelByPath("marts.dim_users_v1")
+	require.NoError(t, err)
+	assert.Nil(t, got.Deprecated)
+}
+
 // --- Model run tests ---
 
 func TestSQLiteStore_ModelRun(t *testing.T) {
@@ -557,6 +681,130 @@ func TestSQLiteStore_ModelRun(t *testing.T) {
 				assert.Positive(t, runs[0].ExecutionMS)
 			},
 		},
+		{
+			name: "delete orphaned model runs",
+			setup: func(t *testing.T, store *SQLiteStore) (*core.Run, *core.PersistedModel) {
+				run, _ := store.CreateRun("test")
+				model := newTestModelFull(&core.Model{Path: "models.test", Name: "test", FilePath: "/models/test.sql"}, "hash")
+				require.NoError(t, store.RegisterModel(model))
+				return run, model
+			},
+			operation: func(t *testing.T, store *SQLiteStore, run *core.Run, model *core.PersistedModel) *core.ModelRun {
+				modelRun := &core.ModelRun{
+					RunID:   run.ID,
+					ModelID: model.ID,
+					Status:  core.ModelRunStatusSuccess,
+				}
+				require.NoError(t, store.RecordModelRun(modelRun))
+				require.NoError(t, store.DeleteModelByFilePath("/models/test.sql"))
+				_, err := store.DeleteOrphanedModelRuns()
+				require.NoError(t, err)
+				return modelRun
+			},
+			verify: func(t *testing.T, store *SQLiteStore, run *core.Run, _ *core.ModelRun) {
+				runs, err := store.GetModelRunsForRun(run.ID)
+				require.NoError(t, err)
+				assert.Empty(t, runs)
+
+				pruned, err := store.DeleteOrphanedModelRuns()
+				require.NoError(t, err)
+				assert.Zero(t, pruned)
+			},
+		},
+		{
+			name: "update model run cost",
+			setup: func(t *testing.T, store *SQLiteStore) (*core.Run, *core.PersistedModel) {
+				run, _ := store.CreateRun("test")
+				model := newTestModel("models.test", "test", "table", "hash")
+				require.NoError(t, store.RegisterModel(model))
+				return run, model
+			},
+			operation: func(t *testing.T, store *SQLiteStore, run *core.Run, model *core.PersistedModel) *core.ModelRun {
+				modelRun := &core.ModelRun{
+					RunID:   run.ID,
+					ModelID: model.ID,
+					Status:  core.ModelRunStatusRunning,
+				}
+				require.NoError(t, store.RecordModelRun(modelRun))
+				require.NoError(t, store.UpdateModelRunCost(modelRun.ID, core.QueryCost{BytesScanned: 2048, SlotMS: 15}))
+				return modelRun
+			},
+			verify: func(t *testing.T, store *SQLiteStore, run *core.Run, modelRun *core.ModelRun) {
+				runs, _ := store.GetModelRunsForRun(run.ID)
+				require.Len(t, runs, 1)
+				assert.Equal(t, int64(2048), runs[0].BytesScanned)
+				assert.Equal(t, int64(15), runs[0].SlotMS)
+
+				latest, err := store.GetLatestModelRun(modelRun.ModelID)
+				require.NoError(t, err)
+				assert.Equal(t, int64(2048), latest.BytesScanned)
+			},
+		},
+		{
+			name: "update model run build mode",
+			setup: func(t *testing.T, store *SQLiteStore) (*core.Run, *core.PersistedModel) {
+				run, _ := store.CreateRun("test")
+				model := newTestModel("models.test", "test", "incremental", "hash")
+				require.NoError(t, store.RegisterModel(model))
+				return run, model
+			},
+			operation: func(t *testing.T, store *SQLiteStore, run *core.Run, model *core.PersistedModel) *core.ModelRun {
+				modelRun := &core.ModelRun{
+					RunID:   run.ID,
+					ModelID: model.ID,
+					Status:  core.ModelRunStatusRunning,
+				}
+				require.NoError(t, store.RecordModelRun(modelRun))
+				runs, _ := store.GetModelRunsForRun(run.ID)
+				require.Len(t, runs, 1)
+				assert.Empty(t, runs[0].BuildMode, "build mode is empty until the model is built")
+
+				require.NoError(t, store.UpdateModelRunBuildMode(modelRun.ID, core.BuildModeFullRefresh))
+				return modelRun
+			},
+			verify: func(t *testing.T, store *SQLiteStore, run *core.Run, modelRun *core.ModelRun) {
+				runs, _ := store.GetModelRunsForRun(run.ID)
+				require.Len(t, runs, 1)
+				assert.Equal(t, core.BuildModeFullRefresh, runs[0].BuildMode)
+
+				withInfo, err := store.GetModelRunsWithModelInfo(run.ID)
+				require.NoError(t, err)
+				require.Len(t, withInfo, 1)
+				assert.Equal(t, core.BuildModeFullRefresh, withInfo[0].BuildMode)
+			},
+		},
+		{
+			name: "update model run skip reason",
+			setup: func(t *testing.T, store *SQLiteStore) (*core.Run, *core.PersistedModel) {
+				run, _ := store.CreateRun("test")
+				model := newTestModel("models.test", "test", "", "hash")
+				require.NoError(t, store.RegisterModel(model))
+				return run, model
+			},
+			operation: func(t *testing.T, store *SQLiteStore, run *core.Run, model *core.PersistedModel) *core.ModelRun {
+				modelRun := &core.ModelRun{
+					RunID:   run.ID,
+					ModelID: model.ID,
+					Status:  core.ModelRunStatusPending,
+				}
+				require.NoError(t, store.RecordModelRun(modelRun))
+				require.NoError(t, store.UpdateModelRun(modelRun.ID, core.ModelRunStatusSkipped, 0, "skipped: upstream model models.parent failed", 0, 0))
+				require.NoError(t, store.UpdateModelRunSkip(modelRun.ID, core.SkipReasonUpstreamFailed, "models.parent"))
+				return modelRun
+			},
+			verify: func(t *testing.T, store *SQLiteStore, run *core.Run, modelRun *core.ModelRun) {
+				runs, _ := store.GetModelRunsForRun(run.ID)
+				require.Len(t, runs, 1)
+				assert.Equal(t, core.SkipReasonUpstreamFailed, runs[0].SkipReason)
+				assert.Equal(t, "models.parent", runs[0].SkippedBy)
+
+				withInfo, err := store.GetModelRunsWithModelInfo(run.ID)
+				require.NoError(t, err)
+				require.Len(t, withInfo, 1)
+				assert.Equal(t, core.SkipReasonUpstreamFailed, withInfo[0].SkipReason)
+				assert.Equal(t, "models.parent", withInfo[0].SkippedBy)
+			},
+		},
 		{
 			name: "get latest model run",
 			setup: func(t *testing.T, store *SQLiteStore) (*core.Run, *core.PersistedModel) {
@@ -754,6 +1002,268 @@ func TestSQLiteStore_Environment(t *testing.T) {
 	}
 }
 
+func TestSQLiteStore_ModelBuild(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation func(t *testing.T, store *SQLiteStore)
+	}{
+		{
+			name: "get model build not found",
+			operation: func(t *testing.T, store *SQLiteStore) {
+				build, err := store.GetModelBuild("model-1", "duckdb:///warehouse.duckdb/main")
+				require.NoError(t, err)
+				assert.Nil(t, build)
+			},
+		},
+		{
+			name: "save and get model build",
+			operation: func(t *testing.T, store *SQLiteStore) {
+				require.NoError(t, store.SaveModelBuild(&core.ModelBuild{
+					ModelID: "model-1", Target: "dev", BuildHash: "abc", RunID: "run-1",
+				}))
+
+				build, err := store.GetModelBuild("model-1", "dev")
+				require.NoError(t, err)
+				require.NotNil(t, build)
+				assert.Equal(t, "abc", build.BuildHash)
+				assert.Equal(t, "run-1", build.RunID)
+				assert.False(t, build.BuiltAt.IsZero())
+
+				// Builds are tracked per target
+				build, err = store.GetModelBuild("model-1", "prod")
+				require.NoError(t, err)
+				assert.Nil(t, build)
+			},
+		},
+		{
+			name: "save model build replaces previous build",
+			operation: func(t *testing.T, store *SQLiteStore) {
+				require.NoError(t, store.SaveModelBuild(&core.ModelBuild{
+					ModelID: "model-1", Target: "dev", BuildHash: "abc", RunID: "run-1",
+				}))
+				require.NoError(t, store.SaveModelBuild(&core.ModelBuild{
+					ModelID: "model-1", Target: "dev", BuildHash: "def", RunID: "run-2",
+				}))
+
+				build, err := store.GetModelBuild("model-1", "dev")
+				require.NoError(t, err)
+				assert.Equal(t, "def", build.BuildHash)
+				assert.Equal(t, "run-2", build.RunID)
+			},
+		},
+		{
+			name: "delete model build",
+			operation: func(t *testing.T, store *SQLiteStore) {
+				require.NoError(t, store.SaveModelBuild(&core.ModelBuild{
+					ModelID: "model-1", Target: "dev", BuildHash: "abc", RunID: "run-1",
+				}))
+				require.NoError(t, store.DeleteModelBuild("model-1", "dev"))
+
+				build, err := store.GetModelBuild("model-1", "dev")
+				require.NoError(t, err)
+				assert.Nil(t, build)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := setupTestStore(t)
+			defer func() { _ = store.Close() }()
+			tt.operation(t, store)
+		})
+	}
+}
+
+func TestSQLiteStore_ModelPlans(t *testing.T) {
+	store := setupTestStore(t)
+	defer func() { _ = store.Close() }()
+
+	run1, err := store.CreateRun("dev")
+	require.NoError(t, err)
+	run2, err := store.CreateRun("dev")
+	require.NoError(t, err)
+
+	require.NoError(t, store.SaveModelPlan(&core.ModelPlan{RunID: run1.ID, ModelPath: "staging.orders", Plan: "SEQ_SCAN orders"}))
+	require.NoError(t, store.SaveModelPlan(&core.ModelPlan{RunID: run1.ID, ModelPath: "marts.revenue", Plan: "HASH_GROUP_BY"}))
+	require.NoError(t, store.SaveModelPlan(&core.ModelPlan{RunID: run2.ID, ModelPath: "staging.orders", Plan: "SEQ_SCAN orders\nSEQ_SCAN customers"}))
+
+	// Plans of a model, newest first
+	plans, err := store.GetModelPlans("staging.orders", 0)
+	require.NoError(t, err)
+	require.Len(t, plans, 2)
+	assert.Equal(t, run2.ID, plans[0].RunID)
+	assert.Equal(t, "SEQ_SCAN orders\nSEQ_SCAN customers", plans[0].Plan)
+	assert.Equal(t, run1.ID, plans[1].RunID)
+	assert.False(t, plans[0].CapturedAt.IsZero())
+
+	plans, err = store.GetModelPlans("staging.orders", 1)
+	require.NoError(t, err)
+	require.Len(t, plans, 1)
+	assert.Equal(t, run2.ID, plans[0].RunID)
+
+	// Plans captured during a run
+	plans, err = store.GetModelPlansForRun(run1.ID)
+	require.NoError(t, err)
+	require.Len(t, plans, 2)
+	assert.Equal(t, "staging.orders", plans[0].ModelPath)
+	assert.Equal(t, "marts.revenue", plans[1].ModelPath)
+
+	plans, err = store.GetModelPlans("staging.unknown", 0)
+	require.NoError(t, err)
+	assert.Empty(t, plans)
+}
+
+func TestSQLiteStore_ModelMetrics(t *testing.T) {
+	store := setupTestStore(t)
+	defer func() { _ = store.Close() }()
+
+	run1, err := store.CreateRun("dev")
+	require.NoError(t, err)
+	run2, err := store.CreateRun("dev")
+	require.NoError(t, err)
+
+	require.NoError(t, store.SaveModelMetrics([]*core.ModelMetric{
+		{RunID: run1.ID, ModelPath: "staging.orders", Metric: core.MetricRowCount, Value: 100},
+		{RunID: run1.ID, ModelPath: "staging.orders", Metric: core.MetricNullRate, Column: "customer_id", Value: 0.1},
+	}))
+	require.NoError(t, store.SaveModelMetrics([]*core.ModelMetric{
+		{RunID: run2.ID, ModelPath: "staging.orders", Metric: core.MetricRowCount, Value: 120},
+	}))
+
+	// Values of a metric, newest first
+	metrics, err := store.GetModelMetrics("staging.orders", core.MetricRowCount, "", 0)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, run2.ID, metrics[0].RunID)
+	assert.InDelta(t, 120, metrics[0].Value, 0)
+	assert.Equal(t, run1.ID, metrics[1].RunID)
+	assert.False(t, metrics[0].RecordedAt.IsZero())
+
+	metrics, err = store.GetModelMetrics("staging.orders", core.MetricRowCount, "", 1)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, run2.ID, metrics[0].RunID)
+
+	metrics, err = store.GetModelMetrics("staging.orders", core.MetricNullRate, "customer_id", 0)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.InDelta(t, 0.1, metrics[0].Value, 0)
+
+	// Saving a metric again replaces it
+	require.NoError(t, store.SaveModelMetrics([]*core.ModelMetric{
+		{RunID: run2.ID, ModelPath: "staging.orders", Metric: core.MetricRowCount, Value: 130},
+	}))
+
+	// Metrics recorded during a run
+	metrics, err = store.GetModelMetricsForRun(run2.ID)
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.InDelta(t, 130, metrics[0].Value, 0)
+
+	metrics, err = store.GetModelMetricsForRun(run1.ID)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, core.MetricRowCount, metrics[0].Metric)
+	assert.Equal(t, "customer_id", metrics[1].Column)
+}
+
+func TestSQLiteStore_Backfills(t *testing.T) {
+	store := setupTestStore(t)
+	defer func() { _ = store.Close() }()
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	run, err := store.CreateRun("dev")
+	require.NoError(t, err)
+
+	none, err := store.GetUnfinishedBackfill("orders", "order_date", day(1), day(8), core.BackfillChunkDay)
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	backfill := &core.Backfill{ModelPath: "orders", Column: "order_date", Start: day(1), End: day(8), Chunk: core.BackfillChunkDay}
+	require.NoError(t, store.CreateBackfill(backfill))
+	assert.NotEmpty(t, backfill.ID)
+	assert.Equal(t, core.BackfillStatusRunning, backfill.Status)
+
+	require.NoError(t, store.RecordBackfillChunk(&core.BackfillChunk{BackfillID: backfill.ID, Start: day(2), End: day(3), RunID: run.ID, RowsAffected: 5}))
+	require.NoError(t, store.RecordBackfillChunk(&core.BackfillChunk{BackfillID: backfill.ID, Start: day(1), End: day(2), RunID: run.ID, RowsAffected: 3}))
+
+	chunks, err := store.GetBackfillChunks(backfill.ID)
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, day(1), chunks[0].Start)
+	assert.Equal(t, day(2), chunks[0].End)
+	assert.Equal(t, int64(3), chunks[0].RowsAffected)
+	assert.Equal(t, run.ID, chunks[1].RunID)
+
+	// A failed backfill is resumed by the next backfill over the same range
+	require.NoError(t, store.CompleteBackfill(backfill.ID, core.BackfillStatusFailed, "chunk 2024-01-03: boom"))
+	unfinished, err := store.GetUnfinishedBackfill("orders", "order_date", day(1), day(8), core.BackfillChunkDay)
+	require.NoError(t, err)
+	require.NotNil(t, unfinished)
+	assert.Equal(t, backfill.ID, unfinished.ID)
+	assert.Equal(t, core.BackfillStatusFailed, unfinished.Status)
+	assert.Equal(t, "chunk 2024-01-03: boom", unfinished.Error)
+	assert.Equal(t, day(1), unfinished.Start)
+	assert.Equal(t, core.BackfillChunkDay, unfinished.Chunk)
+
+	other, err := store.GetUnfinishedBackfill("orders", "order_date", day(1), day(8), core.BackfillChunkWeek)
+	require.NoError(t, err)
+	assert.Nil(t, other, "backfills in other chunks are not resumed")
+
+	// A completed backfill is not
+	require.NoError(t, store.CompleteBackfill(backfill.ID, core.BackfillStatusCompleted, ""))
+	unfinished, err = store.GetUnfinishedBackfill("orders", "order_date", day(1), day(8), core.BackfillChunkDay)
+	require.NoError(t, err)
+	assert.Nil(t, unfinished)
+
+	completed, err := store.GetBackfill(backfill.ID)
+	require.NoError(t, err)
+	assert.Equal(t, core.BackfillStatusCompleted, completed.Status)
+	assert.Empty(t, completed.Error)
+	assert.NotNil(t, completed.CompletedAt)
+
+	_, err = store.GetBackfill("missing")
+	assert.Error(t, err)
+}
+
+func TestSQLiteStore_SourceSnapshots(t *testing.T) {
+	store := setupTestStore(t)
+	defer func() { _ = store.Close() }()
+
+	loaded := time.Date(2024, 1, 1, 6, 30, 0, 0, time.UTC)
+	snapshots, err := store.GetSourceSnapshots("marts.orders", "duckdb://prod.db/")
+	require.NoError(t, err)
+	assert.Empty(t, snapshots)
+
+	require.NoError(t, store.SaveSourceSnapshots("marts.orders", "duckdb://prod.db/", []core.SourceSnapshot{
+		{Source: "raw.orders", ChangedAt: loaded},
+		{Source: "data/refunds.csv", ChangedAt: loaded.Add(-time.Hour)},
+	}))
+	require.NoError(t, store.SaveSourceSnapshots("marts.orders", "duckdb://dev.db/", []core.SourceSnapshot{
+		{Source: "raw.orders", ChangedAt: loaded.Add(-24 * time.Hour)},
+	}))
+
+	snapshots, err = store.GetSourceSnapshots("marts.orders", "duckdb://prod.db/")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "data/refunds.csv", snapshots[0].Source)
+	assert.True(t, loaded.Equal(snapshots[1].ChangedAt))
+
+	// A later build replaces the snapshots of its target only
+	require.NoError(t, store.SaveSourceSnapshots("marts.orders", "duckdb://prod.db/", []core.SourceSnapshot{
+		{Source: "raw.orders", ChangedAt: loaded.Add(time.Hour)},
+	}))
+	snapshots, err = store.GetSourceSnapshots("marts.orders", "duckdb://prod.db/")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.True(t, loaded.Add(time.Hour).Equal(snapshots[0].ChangedAt))
+
+	snapshots, err = store.GetSourceSnapshots("marts.orders", "duckdb://dev.db/")
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+}
+
 // --- Column lineage tests ---
 
 func TestSQLiteStore_ColumnLineage(t *testing.T) {
@@ -7,6 +7,7 @@ type Position struct {
 	File   string
 	Line   int
 	Column int
+	Offset int // 0-based byte offset
 }
 
 // Node is the interface for all template AST nodes.
@@ -34,6 +35,7 @@ type TextNode struct {
 type ExprNode struct {
 	nodeBase
 	Expr string
+	End  Position // Position after the closing }}
 }
 
 // StmtKind identifies the type of control flow statement.
@@ -48,6 +48,7 @@ type Token struct {
 	Type  TokenType
 	Value string
 	Pos   Position
+	End   Position // Position after the token
 }
 
 // Lexer tokenizes a template string.
@@ -59,6 +60,7 @@ type Lexer struct {
 	col      int // current column number (1-based)
 	lastLine int // line at start of current token
 	lastCol  int // column at start of current token
+	lastPos  int // offset at start of current token
 }
 
 // NewLexer creates a new lexer for the given input.
@@ -165,6 +167,7 @@ func (l *Lexer) scanExpression() (Token, error) {
 				Type:  TokenExpr,
 				Value: expr,
 				Pos:   l.startPosition(),
+				End:   l.position(),
 			}, nil
 		}
 
@@ -267,14 +270,15 @@ func (l *Lexer) skipWhitespace() {
 func (l *Lexer) markStart() {
 	l.lastLine = l.line
 	l.lastCol = l.col
+	l.lastPos = l.pos
 }
 
 // position returns the current position.
 func (l *Lexer) position() Position {
-	return Position{File: l.file, Line: l.line, Column: l.col}
+	return Position{File: l.file, Line: l.line, Column: l.col, Offset: l.pos}
 }
 
 // startPosition returns the position where the current token started.
 func (l *Lexer) startPosition() Position {
-	return Position{File: l.file, Line: l.lastLine, Column: l.lastCol}
+	return Position{File: l.file, Line: l.lastLine, Column: l.lastCol, Offset: l.lastPos}
 }
@@ -53,6 +53,7 @@ func (p *Parser) parseNodes(stopOn []StmtKind) ([]Node, error) {
 			nodes = append(nodes, &ExprNode{
 				nodeBase: nodeBase{pos: tok.Pos},
 				Expr:     tok.Value,
+				End:      tok.End,
 			})
 			p.advance()
 
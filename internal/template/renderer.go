@@ -9,8 +9,9 @@ import (
 
 // Renderer executes a parsed template with a Starlark context.
 type Renderer struct {
-	ctx    *starctx.ExecutionContext
-	locals starlark.StringDict // Local variables (e.g., loop variables)
+	ctx       *starctx.ExecutionContext
+	locals    starlark.StringDict // Local variables (e.g., loop variables)
+	sourceMap *SourceMap          // Records where output comes from, if set
 }
 
 // NewRenderer creates a new renderer with the given execution context.
@@ -32,6 +33,19 @@ func (r *Renderer) Render(tmpl *Template) (string, error) {
 	return buf.String(), nil
 }
 
+// RenderWithSourceMap executes the template like Render, along with the map
+// of the rendered SQL back to the template.
+func (r *Renderer) RenderWithSourceMap(tmpl *Template) (string, *SourceMap, error) {
+	r.sourceMap = &SourceMap{}
+	defer func() { r.sourceMap = nil }()
+
+	rendered, err := r.Render(tmpl)
+	if err != nil {
+		return "", nil, err
+	}
+	return rendered, r.sourceMap, nil
+}
+
 // renderNodes renders a slice of nodes into the buffer.
 func (r *Renderer) renderNodes(nodes []Node, buf *strings.Builder, file string) error {
 	for _, node := range nodes {
@@ -46,6 +60,7 @@ func (r *Renderer) renderNodes(nodes []Node, buf *strings.Builder, file string)
 func (r *Renderer) renderNode(node Node, buf *strings.Builder, file string) error {
 	switch n := node.(type) {
 	case *TextNode:
+		r.record(buf.Len(), len(n.Text), n.Pos().Offset, len(n.Text), false)
 		buf.WriteString(n.Text)
 
 	case *ExprNode:
@@ -53,6 +68,7 @@ func (r *Renderer) renderNode(node Node, buf *strings.Builder, file string) erro
 		if err != nil {
 			return WrapRenderError(n.Pos(), "expression evaluation failed", err)
 		}
+		r.record(buf.Len(), len(result), n.Pos().Offset, n.End.Offset-n.Pos().Offset, true)
 		buf.WriteString(result)
 
 	case *ForBlock:
@@ -72,6 +88,13 @@ func (r *Renderer) renderNode(node Node, buf *strings.Builder, file string) erro
 	return nil
 }
 
+// record adds a segment of output to the source map, if one is recorded.
+func (r *Renderer) record(outStart, outLen, srcStart, srcLen int, expr bool) {
+	if r.sourceMap != nil {
+		r.sourceMap.add(outStart, outLen, srcStart, srcLen, expr)
+	}
+}
+
 // renderForBlock renders a for loop block.
 func (r *Renderer) renderForBlock(block *ForBlock, buf *strings.Builder, file string) error {
 	// Evaluate the iterator expression
@@ -95,8 +118,9 @@ func (r *Renderer) renderForBlock(block *ForBlock, buf *strings.Builder, file st
 
 		// Render body with loop context
 		loopRenderer := &Renderer{
-			ctx:    r.ctx,
-			locals: loopLocals,
+			ctx:       r.ctx,
+			locals:    loopLocals,
+			sourceMap: r.sourceMap,
 		}
 		if err := loopRenderer.renderNodes(block.Body, buf, file); err != nil {
 			return err
@@ -157,3 +181,14 @@ func RenderString(input, file string, ctx *starctx.ExecutionContext) (string, er
 	renderer := NewRenderer(ctx)
 	return renderer.Render(tmpl)
 }
+
+// RenderStringWithSourceMap renders a template string like RenderString,
+// along with the map of the rendered SQL back to the template string.
+func RenderStringWithSourceMap(input, file string, ctx *starctx.ExecutionContext) (string, *SourceMap, error) {
+	tmpl, err := ParseString(input, file)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return NewRenderer(ctx).RenderWithSourceMap(tmpl)
+}
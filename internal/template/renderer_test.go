@@ -1,6 +1,7 @@
 package template
 
 import (
+	"strings"
 	"testing"
 
 	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
@@ -210,3 +211,25 @@ FROM {{ target.schema }}.users`
 	// Should have correct table reference
 	assert.Contains(t, result, "analytics.users")
 }
+
+func TestRenderer_SourceMap(t *testing.T) {
+	input := "SELECT\n{* for col in ['id', 'name']: *}\n    {{ col }},\n{* endfor *}\nFROM {{ target.schema }}.users"
+	rendered, sm, err := RenderStringWithSourceMap(input, "test.sql", newTestContext())
+	require.NoError(t, err)
+	require.Equal(t, "SELECT\n\n    id,\n\n    name,\n\nFROM analytics.users", rendered)
+
+	source := func(text string) string {
+		i := strings.Index(rendered, text)
+		require.GreaterOrEqual(t, i, 0, text)
+		start, end := sm.Range(i, i+len(text))
+		return input[start:end]
+	}
+	// Text maps byte for byte, in each iteration of a loop
+	assert.Equal(t, "FROM", source("FROM"))
+	assert.Equal(t, ",", source("name,")[len("{{ col }}"):])
+	assert.Equal(t, ".users", source(".users"))
+	// Expression output maps to the whole expression
+	assert.Equal(t, "{{ col }}", source("name"))
+	assert.Equal(t, "{{ target.schema }}", source("lytic"))
+	assert.Equal(t, "FROM {{ target.schema }}.users", source("FROM analytics.users"))
+}
@@ -0,0 +1,59 @@
+package template
+
+import "sort"
+
+// SourceMap maps offsets of rendered output back to the template it was
+// rendered from, so errors found in the output point at what the user
+// wrote. Text maps byte for byte; the output of an expression maps to the
+// whole {{ ... }} it came from.
+type SourceMap struct {
+	segments []segment
+}
+
+// segment maps a run of output to the template text or expression it
+// was rendered from.
+type segment struct {
+	outStart, outLen int
+	srcStart, srcLen int
+	expr             bool
+}
+
+// add records that output at outStart was rendered from template source.
+func (m *SourceMap) add(outStart, outLen, srcStart, srcLen int, expr bool) {
+	if outLen > 0 {
+		m.segments = append(m.segments, segment{outStart: outStart, outLen: outLen, srcStart: srcStart, srcLen: srcLen, expr: expr})
+	}
+}
+
+// Range returns the template range of the output range from start to end.
+// A range reaching into the output of an expression covers the expression.
+func (m *SourceMap) Range(start, end int) (int, int) {
+	if len(m.segments) == 0 {
+		return start, end
+	}
+
+	// The segment holding an offset, or the last one past the output
+	at := func(offset int) segment {
+		i := sort.Search(len(m.segments), func(i int) bool {
+			return m.segments[i].outStart > offset
+		})
+		return m.segments[max(i-1, 0)]
+	}
+
+	src := func(seg segment, offset int) int {
+		return seg.srcStart + min(max(offset-seg.outStart, 0), seg.srcLen)
+	}
+	seg := at(start)
+	srcStart := src(seg, start)
+	if seg.expr {
+		srcStart = seg.srcStart
+	}
+
+	// Ends are exclusive: the end of a segment belongs to it
+	seg = at(max(end-1, start))
+	srcEnd := src(seg, end)
+	if seg.expr {
+		srcEnd = seg.srcStart + seg.srcLen
+	}
+	return srcStart, max(srcEnd, srcStart)
+}
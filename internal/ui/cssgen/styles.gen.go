@@ -156,6 +156,7 @@ var AllCSSClasses = map[string]bool{
 	"model-detail__actions": true,
 	"model-detail__badge": true,
 	"model-detail__content": true,
+	"model-detail__deprecated": true,
 	"model-detail__description": true,
 	"model-detail__header": true,
 	"model-detail__meta": true,
@@ -170,6 +171,10 @@ var AllCSSClasses = map[string]bool{
 	"model-detail__tag": true,
 	"model-detail__tags": true,
 	"model-detail__title": true,
+	"model-detail__version": true,
+	"model-detail__version--current": true,
+	"model-detail__version--deprecated": true,
+	"model-detail__versions": true,
 	"model-run-card": true,
 	"model-run-card--failed": true,
 	"model-run-card--running": true,
@@ -56,6 +56,21 @@ const ModelDetailBadge = "model-detail__badge"
 // - margin-top: `var(--ui-space-sm)` 🎨
 const ModelDetailContent = "model-detail__content"
 
+// **Base:** .model-detail
+// **Context:** Use with .model-detail for proper styling
+// **Overrides:** 6 properties (background, border-radius, color, font-size, margin-bottom, padding)
+//
+// **Visual:**
+// - background: `var(--ui-color-tertiary-container)` 🎨
+// - border-radius: `var(--ui-radius-md)` 🎨
+// - color: `var(--ui-color-tertiary-container-on)` 🎨
+// **Layout:**
+// - margin-bottom: `var(--ui-space-sm)` 🎨
+// - padding: `var(--ui-space-sm) var(--ui-space-md)` 🎨
+// **Typography:**
+// - font-size: `var(--ui-type-size-sm)` 🎨
+const ModelDetailDeprecated = "model-detail__deprecated"
+
 // **Base:** .model-detail
 // **Context:** Use with .model-detail for proper styling
 // **Overrides:** 3 properties (color, line-height, margin-bottom)
@@ -220,6 +235,54 @@ const ModelDetailTags = "model-detail__tags"
 // - font-weight: `var(--ui-weight-semibold)` 🎨
 const ModelDetailTitle = "model-detail__title"
 
+// **Base:** .model-detail
+// **Context:** Use with .model-detail for proper styling
+// **Overrides:** 6 properties (border, border-radius, color, font-size, padding, text-decoration)
+//
+// **Visual:**
+// - border: `var(--ui-border-sm) solid var(--ui-color-outline)` 🎨
+// - border-radius: `var(--ui-radius-md)` 🎨
+// - color: `inherit`
+// **Layout:**
+// - padding: `2px var(--ui-space-sm)` 🎨
+// **Typography:**
+// - font-size: `var(--ui-type-size-xs)` 🎨
+// - text-decoration: `none`
+const ModelDetailVersion = "model-detail__version"
+
+// **Base:** .model-detail__version
+// **Context:** Use with .model-detail__version for proper styling
+// **Overrides:** 2 properties (background, color)
+//
+// **Visual:**
+// - background: `var(--ui-color-primary-container)` 🎨
+// - color: `var(--ui-color-primary-container-on)` 🎨
+const ModelDetailVersionCurrent = "model-detail__version--current"
+
+// **Base:** .model-detail__version
+// **Context:** Use with .model-detail__version for proper styling
+// **Overrides:** 1 properties (text-decoration)
+//
+// **Typography:**
+// - text-decoration: `line-through`
+const ModelDetailVersionDeprecated = "model-detail__version--deprecated"
+
+// **Base:** .model-detail
+// **Context:** Use with .model-detail for proper styling
+// **Overrides:** 7 properties (align-items, color, display, flex-wrap, font-size, gap, margin-bottom)
+//
+// **Visual:**
+// - color: `var(--ui-color-surface-variant-on)` 🎨
+// **Layout:**
+// - align-items: `center`
+// - display: `flex`
+// - flex-wrap: `wrap`
+// - gap: `6px`
+// - margin-bottom: `var(--ui-space-sm)` 🎨
+// **Typography:**
+// - font-size: `var(--ui-type-size-sm)` 🎨
+const ModelDetailVersions = "model-detail__versions"
+
 // **Visual:**
 // - background: `var(--ui-color-surface-container)` 🎨
 // - border: `var(--ui-border-sm) solid var(--ui-color-outline)` 🎨
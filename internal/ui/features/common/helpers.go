@@ -31,6 +31,8 @@ func MaterializationLabel(mat string) string {
 		return "View"
 	case "incremental":
 		return "Incremental"
+	case "external":
+		return "External"
 	case "":
 		return "View"
 	default:
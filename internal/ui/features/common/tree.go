@@ -9,12 +9,19 @@ import (
 	"github.com/leapstack-labs/leapsql/pkg/core"
 )
 
-// BuildExplorerTree groups models into a tree structure by folder.
-func BuildExplorerTree(models []*core.PersistedModel) []TreeNode {
+// BuildExplorerTree groups models into a tree structure by folder. When the
+// project declares layers, models are grouped by layer instead, in declaration
+// order, and models in no layer by folder after them.
+func BuildExplorerTree(models []*core.PersistedModel, layers []core.LayerConfig) []TreeNode {
 	folders := make(map[string]*TreeNode)
 
 	for _, m := range models {
 		folder := ExtractFolder(m.Path)
+		if len(layers) > 0 {
+			if layer := core.InferLayer(layers, m.FilePath, m.Name, m.Meta); layer != core.ModelTypeOther {
+				folder = string(layer)
+			}
+		}
 
 		if _, ok := folders[folder]; !ok {
 			folders[folder] = &TreeNode{
@@ -32,6 +39,12 @@ func BuildExplorerTree(models []*core.PersistedModel) []TreeNode {
 		})
 	}
 
+	// Layers come first, in declaration order
+	rank := make(map[string]int, len(layers))
+	for i, l := range layers {
+		rank[l.Name] = i + 1
+	}
+
 	// Convert map to sorted slice
 	result := make([]TreeNode, 0, len(folders))
 	for _, node := range folders {
@@ -42,8 +55,12 @@ func BuildExplorerTree(models []*core.PersistedModel) []TreeNode {
 		result = append(result, *node)
 	}
 
-	// Sort folders by name
+	// Sort folders by layer, then name
 	sort.Slice(result, func(i, j int) bool {
+		ri, rj := rank[result[i].Name], rank[result[j].Name]
+		if ri != rj {
+			return ri != 0 && (rj == 0 || ri < rj)
+		}
 		return result[i].Name < result[j].Name
 	})
 
@@ -53,6 +70,7 @@ func BuildExplorerTree(models []*core.PersistedModel) []TreeNode {
 // ExtractFolder extracts the folder name from a model path.
 // e.g., "staging.customers" -> "staging"
 // e.g., "marts.finance.revenue" -> "marts/finance"
+// e.g., "billing/staging.invoices" -> "billing/staging" (workspace project)
 func ExtractFolder(modelPath string) string {
 	parts := strings.Split(modelPath, ".")
 	if len(parts) <= 1 {
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildExplorerTree(t *testing.T) {
+	models := []*core.PersistedModel{
+		{Model: &core.Model{Path: "marts.fct_orders", Name: "fct_orders", FilePath: "/p/models/marts/fct_orders.sql"}},
+		{Model: &core.Model{Path: "staging.stg_orders", Name: "stg_orders", FilePath: "/p/models/staging/stg_orders.sql"}},
+		{Model: &core.Model{Path: "bronze.orders", Name: "orders", FilePath: "/p/models/bronze/orders.sql"}},
+		{Model: &core.Model{Path: "adhoc.report", Name: "report", FilePath: "/p/models/adhoc/report.sql"}},
+	}
+
+	folders := func(tree []TreeNode) []string {
+		names := make([]string, len(tree))
+		for i, n := range tree {
+			names[i] = n.Name
+		}
+		return names
+	}
+
+	t.Run("by folder", func(t *testing.T) {
+		tree := BuildExplorerTree(models, nil)
+		assert.Equal(t, []string{"adhoc", "bronze", "marts", "staging"}, folders(tree))
+	})
+
+	t.Run("by layer", func(t *testing.T) {
+		layers := []core.LayerConfig{
+			{Name: "raw", Paths: []string{"bronze"}, Prefixes: []string{"stg_"}},
+			{Name: "gold", Paths: []string{"marts"}},
+		}
+		tree := BuildExplorerTree(models, layers)
+		assert.Equal(t, []string{"raw", "gold", "adhoc"}, folders(tree))
+		assert.Equal(t, "orders", tree[0].Children[0].Name)
+		assert.Equal(t, "stg_orders", tree[0].Children[1].Name)
+	})
+}
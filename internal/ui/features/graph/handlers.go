@@ -2,12 +2,14 @@
 package graph
 
 import (
+	"errors"
 	"net/http"
 	"sort"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/sessions"
 	"github.com/leapstack-labs/leapsql/internal/engine"
+	"github.com/leapstack-labs/leapsql/internal/selector"
 	"github.com/leapstack-labs/leapsql/internal/ui/features/common"
 	"github.com/leapstack-labs/leapsql/internal/ui/notifier"
 	"github.com/leapstack-labs/leapsql/pkg/core"
@@ -35,9 +37,16 @@ func NewHandlers(eng *engine.Engine, store core.Store, sessionStore sessions.Sto
 }
 
 // HandleGraphPage renders the graph visualization page with full content.
+// The optional ?select= query parameter scopes the graph to a selector
+// expression (e.g., "tag:pii AND NOT tag:deprecated").
 func (h *Handlers) HandleGraphPage(w http.ResponseWriter, r *http.Request) {
-	sidebar, graphData, err := h.buildGraphData()
+	sidebar, graphData, err := h.buildGraphData(r.URL.Query().Get("select"))
 	if err != nil {
+		var selErr *selectorError
+		if errors.As(err, &selErr) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -52,6 +61,7 @@ func (h *Handlers) HandleGraphPage(w http.ResponseWriter, r *http.Request) {
 // Unlike the old pattern, it does NOT send initial state - that's rendered by GraphPage.
 func (h *Handlers) GraphPageUpdates(w http.ResponseWriter, r *http.Request) {
 	sse := datastar.NewSSE(w, r)
+	selectExpr := r.URL.Query().Get("select")
 
 	// Subscribe to updates
 	updates := h.notifier.Subscribe()
@@ -64,7 +74,7 @@ func (h *Handlers) GraphPageUpdates(w http.ResponseWriter, r *http.Request) {
 		case <-ctx.Done():
 			return
 		case <-updates:
-			if err := h.sendGraphView(sse); err != nil {
+			if err := h.sendGraphView(sse, selectExpr); err != nil {
 				_ = sse.ConsoleError(err)
 			}
 		}
@@ -72,16 +82,25 @@ func (h *Handlers) GraphPageUpdates(w http.ResponseWriter, r *http.Request) {
 }
 
 // sendGraphView builds and sends the full app view for the graph page.
-func (h *Handlers) sendGraphView(sse *datastar.ServerSentEventGenerator) error {
-	sidebar, graphData, err := h.buildGraphData()
+func (h *Handlers) sendGraphView(sse *datastar.ServerSentEventGenerator, selectExpr string) error {
+	sidebar, graphData, err := h.buildGraphData(selectExpr)
 	if err != nil {
 		return err
 	}
 	return sse.PatchElementTempl(GraphAppShell(sidebar, graphData))
 }
 
+// selectorError reports an invalid ?select= expression.
+type selectorError struct {
+	err error
+}
+
+func (e *selectorError) Error() string { return e.err.Error() }
+func (e *selectorError) Unwrap() error { return e.err }
+
 // buildGraphData assembles all data needed for the graph view.
-func (h *Handlers) buildGraphData() (common.SidebarData, *GraphViewData, error) {
+// A non-empty selectExpr limits the graph to the matching models.
+func (h *Handlers) buildGraphData(selectExpr string) (common.SidebarData, *GraphViewData, error) {
 	sidebar := common.SidebarData{
 		CurrentPath: "/graph",
 		FullWidth:   true,
@@ -94,14 +113,67 @@ func (h *Handlers) buildGraphData() (common.SidebarData, *GraphViewData, error)
 	}
 
 	// Build explorer tree
-	sidebar.ExplorerTree = common.BuildExplorerTree(models)
+	sidebar.ExplorerTree = common.BuildExplorerTree(models, h.engine.GetLayers())
+
+	if selectExpr != "" {
+		models, err = h.selectModels(models, selectExpr)
+		if err != nil {
+			return sidebar, nil, &selectorError{err}
+		}
+	}
 
 	// Build graph data
 	graphData := h.buildFullGraphData(models)
+	graphData.Select = selectExpr
 
 	return sidebar, &graphData, nil
 }
 
+// selectModels filters models by a selector expression. Graph operators
+// follow the dependencies recorded in the state store.
+func (h *Handlers) selectModels(models []*core.PersistedModel, selectExpr string) ([]*core.PersistedModel, error) {
+	sel, err := selector.Parse(selectExpr)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]string, len(models))
+	coreModels := make([]*core.Model, 0, len(models))
+	for _, m := range models {
+		paths[m.ID] = m.Path
+		coreModels = append(coreModels, m.Model)
+	}
+	if err := selector.Validate(sel, coreModels); err != nil {
+		return nil, err
+	}
+
+	parents := make(map[string][]string, len(models))
+	for _, m := range models {
+		deps, err := h.store.GetDependencies(m.ID)
+		if err != nil {
+			continue
+		}
+		for _, depID := range deps {
+			if path, ok := paths[depID]; ok {
+				parents[m.Path] = append(parents[m.Path], path)
+			}
+		}
+	}
+
+	selected := make(map[string]bool)
+	for _, path := range selector.Select(sel, &selector.Context{Models: coreModels, Parents: parents}) {
+		selected[path] = true
+	}
+
+	result := make([]*core.PersistedModel, 0, len(models))
+	for _, m := range models {
+		if selected[m.Path] {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
 // buildFullGraphData creates graph view data from all models and their dependencies.
 func (h *Handlers) buildFullGraphData(models []*core.PersistedModel) GraphViewData {
 	// Create a map for quick lookup
@@ -5,6 +5,8 @@ package graph
 type GraphViewData struct {
 	Nodes []GraphNode
 	Edges []GraphEdge
+	// Select is the selector expression the graph is scoped to (empty for the full DAG)
+	Select string
 }
 
 // GraphNode represents a node in the graph.
@@ -90,7 +90,7 @@ func (h *Handlers) buildDashboardData() (common.SidebarData, *DashboardStats, er
 	if err != nil {
 		return sidebar, nil, err
 	}
-	sidebar.ExplorerTree = common.BuildExplorerTree(models)
+	sidebar.ExplorerTree = common.BuildExplorerTree(models, h.engine.GetLayers())
 
 	// Get stats for dashboard
 	stats := &DashboardStats{
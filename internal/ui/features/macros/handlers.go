@@ -114,7 +114,7 @@ func (h *Handlers) buildMacrosDataWithSelection(selectedNs, selectedFn string) (
 	if err != nil {
 		return sidebar, nil, err
 	}
-	sidebar.ExplorerTree = common.BuildExplorerTree(models)
+	sidebar.ExplorerTree = common.BuildExplorerTree(models, h.engine.GetLayers())
 
 	// Build macros view data
 	macrosData, err := h.buildMacrosViewData(selectedNs, selectedFn)
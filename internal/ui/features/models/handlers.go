@@ -4,8 +4,8 @@ package models
 import (
 	"fmt"
 	"net/http"
+	"sort"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/sessions"
 	"github.com/leapstack-labs/leapsql/internal/engine"
 	"github.com/leapstack-labs/leapsql/internal/ui/features/common"
@@ -36,7 +36,7 @@ func NewHandlers(eng *engine.Engine, store core.Store, sessionStore sessions.Sto
 
 // HandleModelPage renders the model detail page with full content.
 func (h *Handlers) HandleModelPage(w http.ResponseWriter, r *http.Request) {
-	modelPath := chi.URLParam(r, "path")
+	modelPath := modelPathParam(r)
 
 	sidebar, modelData, contextData, err := h.buildModelData(modelPath)
 	if err != nil {
@@ -60,7 +60,7 @@ func (h *Handlers) HandleModelPage(w http.ResponseWriter, r *http.Request) {
 // It subscribes to updates and pushes changes when the store changes.
 // Unlike the old pattern, it does NOT send initial state - that's rendered by ModelPage.
 func (h *Handlers) ModelPageUpdates(w http.ResponseWriter, r *http.Request) {
-	modelPath := chi.URLParam(r, "path")
+	modelPath := modelPathParam(r)
 	sse := datastar.NewSSE(w, r)
 
 	// Subscribe to updates
@@ -103,7 +103,7 @@ func (h *Handlers) buildModelData(modelPath string) (common.SidebarData, *ModelV
 	if err != nil {
 		return sidebar, nil, nil, err
 	}
-	sidebar.ExplorerTree = common.BuildExplorerTree(models)
+	sidebar.ExplorerTree = common.BuildExplorerTree(models, h.engine.GetLayers())
 
 	var modelData *ModelViewData
 	var contextData *ModelContext
@@ -141,9 +141,15 @@ func (h *Handlers) buildModelViewData(model *core.PersistedModel) ModelViewData
 		Description:  model.Description,
 		Owner:        model.Owner,
 		Tags:         model.Tags,
+		Version:      model.Version,
+		Deprecated:   model.Deprecated,
 		SourceSQL:    model.SQL,
 	}
 
+	if model.Version > 0 {
+		data.Versions = h.buildVersionHistory(model)
+	}
+
 	// Compile SQL
 	compiled, err := h.engine.RenderModel(model.Path)
 	if err != nil {
@@ -155,6 +161,37 @@ func (h *Handlers) buildModelViewData(model *core.PersistedModel) ModelViewData
 	return data
 }
 
+// buildVersionHistory lists all versions of a versioned model, oldest first.
+// Versions share the model name and, in a workspace, the project.
+func (h *Handlers) buildVersionHistory(model *core.PersistedModel) []ModelVersion {
+	models, err := h.store.ListModels()
+	if err != nil {
+		return nil
+	}
+
+	project, _ := core.SplitModelID(model.Path)
+	var versions []ModelVersion
+	for _, m := range models {
+		if m.Name != model.Name || m.Version == 0 {
+			continue
+		}
+		if p, _ := core.SplitModelID(m.Path); p != project {
+			continue
+		}
+		versions = append(versions, ModelVersion{
+			Version:    m.Version,
+			Path:       m.Path,
+			Current:    m.Path == model.Path,
+			Deprecated: m.Deprecated != nil,
+		})
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+	return versions
+}
+
 // buildModelContext builds the context panel data.
 func (h *Handlers) buildModelContext(model *core.PersistedModel) ModelContext {
 	deps, _ := h.store.GetDependencies(model.ID)
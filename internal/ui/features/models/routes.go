@@ -2,6 +2,8 @@
 package models
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/gorilla/sessions"
 	"github.com/leapstack-labs/leapsql/internal/engine"
@@ -22,9 +24,17 @@ func SetupRoutes(
 
 	// Page routes (full page render with content)
 	router.Get("/models/{path}", handlers.HandleModelPage)
+	router.Get("/models/{project}/{path}", handlers.HandleModelPage)
 
 	// SSE routes (live updates only)
 	router.Get("/models/{path}/updates", handlers.ModelPageUpdates)
+	router.Get("/models/{project}/{path}/updates", handlers.ModelPageUpdates)
 
 	return nil
 }
+
+// modelPathParam returns the model path from the request URL.
+// Workspace models are addressed as /models/{project}/{path}.
+func modelPathParam(r *http.Request) string {
+	return core.ModelID(chi.URLParam(r, "project"), chi.URLParam(r, "path"))
+}
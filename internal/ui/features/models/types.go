@@ -1,6 +1,8 @@
 // Package models provides model detail handlers for the UI.
 package models
 
+import "github.com/leapstack-labs/leapsql/pkg/core"
+
 // ModelViewData holds all data for the model view including all tab content.
 type ModelViewData struct {
 	// Model info
@@ -13,6 +15,11 @@ type ModelViewData struct {
 	Owner        string
 	Tags         []string
 
+	// Versioning
+	Version    int               // 0 if unversioned
+	Deprecated *core.Deprecation // nil if not deprecated
+	Versions   []ModelVersion    // All versions of this model, oldest first
+
 	// Tab content (all pre-rendered)
 	SourceSQL    string
 	CompiledSQL  string
@@ -23,6 +30,14 @@ type ModelViewData struct {
 	PreviewError string // If preview query failed
 }
 
+// ModelVersion is one entry in a model's version history.
+type ModelVersion struct {
+	Version    int
+	Path       string
+	Current    bool // The version being viewed
+	Deprecated bool
+}
+
 // PreviewData holds data preview results.
 type PreviewData struct {
 	Columns  []string
@@ -100,7 +100,7 @@ func (h *Handlers) buildRunsDataWithSelection(selectedRunID string) (common.Side
 	if err != nil {
 		return sidebar, nil, err
 	}
-	sidebar.ExplorerTree = common.BuildExplorerTree(models)
+	sidebar.ExplorerTree = common.BuildExplorerTree(models, h.engine.GetLayers())
 
 	// Get runs
 	runs, err := h.store.ListRuns(50)
@@ -32,15 +32,18 @@ type Handlers struct {
 	store        core.Store
 	sessionStore sessions.Store
 	notifier     *notifier.Notifier
+	layers       []core.LayerConfig
 	isDev        bool
 }
 
 // NewHandlers creates a new Handlers instance.
-func NewHandlers(store core.Store, sessionStore sessions.Store, notify *notifier.Notifier, isDev bool) *Handlers {
+// Layers group the explorer tree (nil groups it by folder).
+func NewHandlers(store core.Store, sessionStore sessions.Store, notify *notifier.Notifier, layers []core.LayerConfig, isDev bool) *Handlers {
 	return &Handlers{
 		store:        store,
 		sessionStore: sessionStore,
 		notifier:     notify,
+		layers:       layers,
 		isDev:        isDev,
 	}
 }
@@ -108,7 +111,7 @@ func (h *Handlers) buildQueryData(ctx context.Context) (common.SidebarData, *Que
 	if err != nil {
 		return sidebar, nil, err
 	}
-	sidebar.ExplorerTree = common.BuildExplorerTree(models)
+	sidebar.ExplorerTree = common.BuildExplorerTree(models, h.layers)
 
 	// Get tables and views
 	db, err := h.getDB()
@@ -14,9 +14,10 @@ func SetupRoutes(
 	store core.Store,
 	sessionStore sessions.Store,
 	notify *notifier.Notifier,
+	layers []core.LayerConfig,
 	isDev bool,
 ) error {
-	handlers := NewHandlers(store, sessionStore, notify, isDev)
+	handlers := NewHandlers(store, sessionStore, notify, layers, isDev)
 
 	// Page routes (full page render with content)
 	router.Get("/query", handlers.HandleQueryPage)
@@ -59,7 +59,7 @@ func SetupRoutes(
 		return err
 	}
 
-	if err := statequeryFeature.SetupRoutes(router, store, sessionStore, notify, isDev); err != nil {
+	if err := statequeryFeature.SetupRoutes(router, store, sessionStore, notify, eng.GetLayers(), isDev); err != nil {
 		return err
 	}
 
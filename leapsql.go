@@ -0,0 +1,432 @@
+// Package leapsql embeds LeapSQL in Go programs, so services can run, compile,
+// lint and trace a project's models without shelling out to the CLI.
+//
+// Open loads a project the way the leapsql command does, from the directory
+// holding its leapsql.yaml:
+//
+//	project, err := leapsql.Open(leapsql.Options{Dir: "analytics"})
+//	if err != nil {
+//		return err
+//	}
+//	defer project.Close()
+//
+//	run, err := project.Run(ctx, leapsql.RunOptions{Select: "tag:daily"})
+//
+// Database adapters are registered by importing them, like database/sql
+// drivers, so programs only link the adapters they use:
+//
+//	import _ "github.com/leapstack-labs/leapsql/pkg/adapters/duckdb"
+//
+// Models and runs are described with the types of pkg/core. The engine behind
+// a Project lives in internal packages, which programs cannot import.
+package leapsql
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/leapstack-labs/leapsql/internal/config"
+	"github.com/leapstack-labs/leapsql/internal/engine"
+	starctx "github.com/leapstack-labs/leapsql/internal/starlark"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	_ "github.com/leapstack-labs/leapsql/pkg/lint/sql/rules" // register SQL rules
+	"github.com/leapstack-labs/leapsql/pkg/redact"
+)
+
+// DefaultStatePath is the state database path, relative to the project
+// directory, used when Options.StatePath is empty.
+const DefaultStatePath = ".leapsql/state.db"
+
+// Options configures a project opened with Open.
+type Options struct {
+	// Dir is the project directory containing leapsql.yaml (default: the
+	// working directory)
+	Dir string
+	// Environment is the environment runs are recorded in (default "dev")
+	Environment string
+	// StatePath is the path to the state database (default: DefaultStatePath
+	// in Dir)
+	StatePath string
+	// Target overrides the target configured in leapsql.yaml, e.g. to supply
+	// credentials from a secret store
+	Target *core.TargetConfig
+	// QueryComment is the template of the comment prefixed to executed SQL
+	// (empty for the default, "off" to disable)
+	QueryComment string
+	// Logger receives the engine's logs (optional, discarded if nil)
+	Logger *slog.Logger
+	// Events receives run lifecycle events (optional)
+	Events Events
+}
+
+// Events receives notifications as runs progress. Methods are called
+// synchronously from the goroutine running the models, so they should return
+// quickly.
+type Events interface {
+	// OnRunStarted is called when a run has been recorded, before any model runs
+	OnRunStarted(run *core.Run)
+	// OnModelRunUpdated is called when a model starts running, succeeds, fails
+	// or is skipped
+	OnModelRunUpdated(runID string, modelRun *core.ModelRun)
+	// OnRunCompleted is called when a run finishes, whatever its status
+	OnRunCompleted(run *core.Run)
+}
+
+// Project is a LeapSQL project opened for embedding. Its methods are safe for
+// concurrent use, but run one at a time: a project builds models into one
+// database and records them in one state database.
+type Project struct {
+	mu     sync.Mutex
+	dir    string
+	env    string
+	config *core.ProjectConfig
+	engine *engine.Engine
+}
+
+// Open loads the project in opts.Dir and discovers its models. Close the
+// project to release its database connections.
+func Open(opts Options) (*Project, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project directory: %w", err)
+	}
+
+	cfg, err := config.LoadFromDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project config: %w", err)
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("no %s found in %s", config.ConfigFileName, dir)
+	}
+
+	target := cfg.Target
+	if opts.Target != nil {
+		override := *opts.Target
+		config.ApplyTargetDefaults(&override)
+		target = &override
+	}
+	if target == nil {
+		return nil, fmt.Errorf("target configuration required: set 'target' in %s or Options.Target", config.ConfigFileName)
+	}
+	target, err = resolveTarget(target, cfg.Secrets, dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target configuration: %w", err)
+	}
+
+	env := opts.Environment
+	if env == "" {
+		env = "dev"
+	}
+
+	statePath := opts.StatePath
+	if statePath == "" {
+		statePath = DefaultStatePath
+	}
+	statePath = resolvePath(statePath, dir)
+	if err := os.MkdirAll(filepath.Dir(statePath), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+
+	eng, err := engine.New(engine.Config{
+		ModelsDir:            resolvePath(cfg.ModelsDir, dir),
+		SeedsDir:             resolvePath(cfg.SeedsDir, dir),
+		MacrosDir:            resolvePath(cfg.MacrosDir, dir),
+		Groups:               cfg.Groups,
+		ProjectName:          filepath.Base(dir),
+		QueryComment:         opts.QueryComment,
+		Transaction:          target.Transaction,
+		Masking:              target.Masking,
+		MaxConcurrentQueries: target.MaxConcurrentQueries,
+		ResourceClasses:      target.ResourceClasses,
+		Dialect:              cfg.Dialect,
+		StatePath:            statePath,
+		Environment:          env,
+		Target:               starctx.TargetInfoFromConfig(target),
+		AdapterConfig: &core.AdapterConfig{
+			Type:     target.Type,
+			Path:     target.Database,
+			Database: target.Database,
+			Schema:   target.Schema,
+			Host:     target.Host,
+			Port:     target.Port,
+			Username: target.User,
+			Password: target.Password,
+			Options:  target.Options,
+			Params:   target.Params,
+		},
+		Logger: redactLogger(opts.Logger),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.Events != nil {
+		eng.SetRunObserver(opts.Events)
+	}
+
+	p := &Project{dir: dir, env: env, config: cfg, engine: eng}
+	if err := p.discover(); err != nil {
+		_ = eng.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Close releases the project's database connections.
+func (p *Project) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.engine.Close()
+}
+
+// Dir returns the absolute path of the project directory.
+func (p *Project) Dir() string {
+	return p.dir
+}
+
+// Models returns the project's enabled models, sorted by path. Models are
+// rediscovered first, so changes to model files are picked up.
+func (p *Project) Models() ([]*core.Model, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.discover(); err != nil {
+		return nil, err
+	}
+	models := make([]*core.Model, 0, len(p.engine.GetModels()))
+	for _, m := range p.engine.GetModels() {
+		models = append(models, m)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Path < models[j].Path })
+	return models, nil
+}
+
+// RunOptions configures a run.
+type RunOptions struct {
+	// Select limits the run to models matched by a selector expression, e.g.
+	// "staging.stg_orders,staging.stg_customers" or "tag:daily AND NOT
+	// tag:deprecated" (empty runs all models)
+	Select string
+	// Downstream also runs the models depending on the selected models
+	Downstream bool
+	// FullRefresh rebuilds models from scratch, replacing incremental tables
+	// and ignoring the build cache
+	FullRefresh bool
+	// Sample builds at most Sample rows per model, for quick development runs
+	// (0 builds models in full)
+	Sample int
+	// Explain captures the query plan of each model built, so plan changes
+	// between runs can be reported
+	Explain bool
+	// Threads is the number of models built at once, within the target's
+	// max_concurrent_queries and resource class limits (0 or 1 builds one
+	// model at a time)
+	Threads int
+	// LockTimeout is how long to wait for a concurrent run to release the
+	// state lock (0 fails immediately)
+	LockTimeout time.Duration
+}
+
+// Run loads seeds and runs the project's models in dependency order, like
+// leapsql run. Cancelling ctx stops the run before its next model; the run is
+// recorded as cancelled and the context's error returned.
+//
+// The returned run is non-nil once the run has been recorded, even if it
+// failed: check its Status and the returned error.
+func (p *Project) Run(ctx context.Context, opts RunOptions) (*core.Run, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := p.engine.LoadSeeds(ctx); err != nil {
+		return nil, fmt.Errorf("failed to load seeds: %w", err)
+	}
+	if err := p.discover(); err != nil {
+		return nil, err
+	}
+
+	p.engine.SetFullRefresh(opts.FullRefresh)
+	p.engine.SetSample(opts.Sample)
+	p.engine.SetExplain(opts.Explain)
+	p.engine.SetThreads(opts.Threads)
+	p.engine.SetLock(engine.LockConfig{Timeout: opts.LockTimeout})
+
+	if opts.Select == "" {
+		return p.engine.Run(ctx, p.env)
+	}
+	selected, err := p.selectModels(opts.Select)
+	if err != nil {
+		return nil, err
+	}
+	return p.engine.RunSelected(ctx, p.env, selected, opts.Downstream)
+}
+
+// Compile renders a model's SQL with its templates and macros expanded, as it
+// would be executed.
+func (p *Project) Compile(modelPath string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.discover(); err != nil {
+		return "", err
+	}
+	return p.engine.RenderModel(modelPath)
+}
+
+// LintResult holds the lint diagnostics of one model.
+type LintResult struct {
+	// Model is the model path (e.g., "staging.orders")
+	Model string
+	// FilePath is the path of the model's SQL file
+	FilePath string
+	// Diagnostics are the rule violations found in the model's rendered SQL,
+	// positioned in Source
+	Diagnostics []lint.Diagnostic
+	// SQL is the rendered SQL that fix edits refer to, for use with
+	// lint.ApplyFixes
+	SQL string
+	// Source is the content diagnostic positions refer to: the model's file,
+	// or SQL when the model's SQL can't be located in it
+	Source string
+}
+
+// Lint analyzes the rendered SQL of every model, including models disabled in
+// the current environment, with the SQL rules configured in the lint section
+// of leapsql.yaml. Only models with diagnostics are returned, sorted by file
+// path; models that fail to render or parse are skipped, as by leapsql lint.
+// It fails if the options of a rule are invalid.
+func (p *Project) Lint(ctx context.Context) ([]LintResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.discover(); err != nil {
+		return nil, err
+	}
+	models := make([]*core.Model, 0, len(p.engine.LintableModels()))
+	for _, m := range p.engine.LintableModels() {
+		models = append(models, m)
+	}
+
+	lintCfg := lint.NewConfig()
+	if err := lintCfg.ApplyProject(p.config.Lint); err != nil {
+		return nil, fmt.Errorf("invalid lint config: %w", err)
+	}
+	linted, err := p.engine.LintModels(ctx, models, lintCfg)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]LintResult, 0, len(linted))
+	for _, l := range linted {
+		results = append(results, LintResult(l))
+	}
+	return results, nil
+}
+
+// Lineage describes where a model's data comes from and where it goes.
+type Lineage struct {
+	// Model is the model path
+	Model string
+	// Upstream lists the models and sources the model depends on, directly
+	// or transitively, sorted
+	Upstream []string
+	// Downstream lists the models depending on the model, directly or
+	// transitively, sorted
+	Downstream []string
+	// Columns is the column-level lineage of the model's output columns
+	Columns []core.ColumnInfo
+}
+
+// Lineage returns the table and column lineage of a model.
+func (p *Project) Lineage(modelPath string) (*Lineage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.discover(); err != nil {
+		return nil, err
+	}
+	m, ok := p.engine.GetModels()[modelPath]
+	if !ok {
+		return nil, fmt.Errorf("model not found: %s", modelPath)
+	}
+
+	g := p.engine.GetGraph()
+	downstream := make([]string, 0)
+	for _, id := range g.GetAffectedNodes([]string{modelPath}) {
+		if id != modelPath {
+			downstream = append(downstream, id)
+		}
+	}
+	return &Lineage{
+		Model:      modelPath,
+		Upstream:   g.GetUpstreamNodes(modelPath),
+		Downstream: downstream,
+		Columns:    m.Columns,
+	}, nil
+}
+
+// discover rediscovers the project's models.
+func (p *Project) discover() error {
+	if _, err := p.engine.Discover(engine.DiscoveryOptions{}); err != nil {
+		return fmt.Errorf("failed to discover models: %w", err)
+	}
+	return nil
+}
+
+// selectModels resolves a selector expression to model paths. It is an error
+// for a selector to match no models.
+func (p *Project) selectModels(expr string) ([]string, error) {
+	selected, err := p.engine.SelectModels(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no models match %q", expr)
+	}
+	return selected, nil
+}
+
+// resolveTarget returns the target with env_var(), secret() and ${VAR}
+// references resolved, as the CLI does, and file database paths resolved
+// relative to the project directory.
+func resolveTarget(t *core.TargetConfig, secrets *core.SecretsConfig, dir string) (*core.TargetConfig, error) {
+	resolved := *t
+	resolved.Options = maps.Clone(t.Options)
+	resolved.Params = maps.Clone(t.Params)
+	if err := config.ResolveSecrets(&resolved, secrets); err != nil {
+		return nil, err
+	}
+	if resolved.Type == "duckdb" && resolved.Database != ":memory:" {
+		resolved.Database = resolvePath(resolved.Database, dir)
+	}
+	return &resolved, nil
+}
+
+// redactLogger wraps logger so that resolved credentials are masked in its
+// output.
+func redactLogger(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return nil
+	}
+	return slog.New(redact.NewHandler(logger.Handler()))
+}
+
+// resolvePath resolves a path relative to dir if it is not absolute.
+func resolvePath(path, dir string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,198 @@
+package leapsql_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql"
+	_ "github.com/leapstack-labs/leapsql/pkg/adapters/duckdb"
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createProject writes a project with a seed and two chained models.
+func createProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"leapsql.yaml":    "target:\n  type: duckdb\n  database: warehouse.duckdb\n",
+		"seeds/users.csv": "id,name,active\n1,alice,true\n2,bob,false\n",
+		"models/staging/active_users.sql": `/*---
+materialized: table
+---*/
+SELECT id, name FROM users WHERE active = true
+`,
+		"models/marts/user_names.sql": `/*---
+materialized: table
+---*/
+SELECT name FROM staging.active_users
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+	return dir
+}
+
+// recordingEvents records the events of runs.
+type recordingEvents struct {
+	mu        sync.Mutex
+	started   []*core.Run
+	updates   []core.ModelRun
+	completed []*core.Run
+}
+
+func (r *recordingEvents) OnRunStarted(run *core.Run) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = append(r.started, run)
+}
+
+func (r *recordingEvents) OnModelRunUpdated(_ string, modelRun *core.ModelRun) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates = append(r.updates, *modelRun)
+}
+
+func (r *recordingEvents) OnRunCompleted(run *core.Run) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.completed = append(r.completed, run)
+}
+
+func TestOpen(t *testing.T) {
+	t.Run("discovers models", func(t *testing.T) {
+		project, err := leapsql.Open(leapsql.Options{Dir: createProject(t)})
+		require.NoError(t, err)
+		defer func() { _ = project.Close() }()
+
+		models, err := project.Models()
+		require.NoError(t, err)
+		require.Len(t, models, 2)
+		assert.Equal(t, "marts.user_names", models[0].Path)
+		assert.Equal(t, "staging.active_users", models[1].Path)
+	})
+
+	t.Run("requires project config", func(t *testing.T) {
+		_, err := leapsql.Open(leapsql.Options{Dir: t.TempDir()})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no leapsql.yaml found")
+	})
+}
+
+func TestProject_Run(t *testing.T) {
+	dir := createProject(t)
+	events := &recordingEvents{}
+	project, err := leapsql.Open(leapsql.Options{Dir: dir, Events: events})
+	require.NoError(t, err)
+	defer func() { _ = project.Close() }()
+
+	run, err := project.Run(context.Background(), leapsql.RunOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, core.RunStatusCompleted, run.Status)
+	assert.Equal(t, "dev", run.Environment)
+	assert.FileExists(t, filepath.Join(dir, "warehouse.duckdb"))
+	assert.FileExists(t, filepath.Join(dir, leapsql.DefaultStatePath))
+
+	require.Len(t, events.started, 1)
+	require.Len(t, events.completed, 1)
+	assert.Equal(t, run.ID, events.completed[0].ID)
+	var succeeded []string
+	for _, u := range events.updates {
+		if u.Status == core.ModelRunStatusSuccess {
+			succeeded = append(succeeded, u.ModelID)
+		}
+	}
+	assert.Len(t, succeeded, 2)
+}
+
+func TestProject_Run_Select(t *testing.T) {
+	project, err := leapsql.Open(leapsql.Options{Dir: createProject(t)})
+	require.NoError(t, err)
+	defer func() { _ = project.Close() }()
+
+	run, err := project.Run(context.Background(), leapsql.RunOptions{Select: "staging.active_users"})
+	require.NoError(t, err)
+	assert.Equal(t, core.RunStatusCompleted, run.Status)
+
+	_, err = project.Run(context.Background(), leapsql.RunOptions{Select: "tag:missing"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid selector")
+}
+
+func TestProject_Run_Cancelled(t *testing.T) {
+	project, err := leapsql.Open(leapsql.Options{Dir: createProject(t)})
+	require.NoError(t, err)
+	defer func() { _ = project.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = project.Run(ctx, leapsql.RunOptions{})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestProject_Compile(t *testing.T) {
+	project, err := leapsql.Open(leapsql.Options{Dir: createProject(t)})
+	require.NoError(t, err)
+	defer func() { _ = project.Close() }()
+
+	sql, err := project.Compile("marts.user_names")
+	require.NoError(t, err)
+	assert.Contains(t, sql, "active_users")
+
+	_, err = project.Compile("marts.missing")
+	require.Error(t, err)
+}
+
+func TestProject_Lint(t *testing.T) {
+	dir := createProject(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "models", "marts", "unnamed_users.sql"),
+		[]byte("SELECT id FROM staging.active_users WHERE name = NULL\n"), 0600))
+
+	project, err := leapsql.Open(leapsql.Options{Dir: dir})
+	require.NoError(t, err)
+	defer func() { _ = project.Close() }()
+
+	results, err := project.Lint(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "marts.unnamed_users", results[0].Model)
+	var rules []string
+	for _, d := range results[0].Diagnostics {
+		rules = append(rules, d.RuleID)
+	}
+	assert.Contains(t, rules, "CV05")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = project.Lint(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestProject_Lineage(t *testing.T) {
+	project, err := leapsql.Open(leapsql.Options{Dir: createProject(t)})
+	require.NoError(t, err)
+	defer func() { _ = project.Close() }()
+
+	lineage, err := project.Lineage("staging.active_users")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"marts.user_names"}, lineage.Downstream)
+
+	lineage, err = project.Lineage("marts.user_names")
+	require.NoError(t, err)
+	assert.Contains(t, lineage.Upstream, "staging.active_users")
+	assert.Empty(t, lineage.Downstream)
+	require.NotEmpty(t, lineage.Columns)
+	assert.Equal(t, "name", lineage.Columns[0].Name)
+
+	_, err = project.Lineage("marts.missing")
+	require.Error(t, err)
+}
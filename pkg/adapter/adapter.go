@@ -7,6 +7,7 @@ package adapter
 
 import (
 	"context"
+	"strings"
 
 	"github.com/leapstack-labs/leapsql/pkg/core"
 )
@@ -41,3 +42,89 @@ type Adapter interface {
 	// Use dialect.Get(cfg.Name) to obtain the full dialect with parsing capabilities.
 	DialectConfig() *core.DialectConfig
 }
+
+// Cloner is an optional interface for adapters that can clone a table
+// without copying its data (e.g., zero-copy clones). Callers fall back to
+// CREATE TABLE AS SELECT for adapters that do not implement it.
+type Cloner interface {
+	Adapter
+
+	// CloneTable replaces target with a clone of the source table.
+	CloneTable(ctx context.Context, source, target string) error
+}
+
+// Constrainer is an optional interface for adapters that can add column
+// constraints to an existing table. Both methods must be idempotent, and must
+// fail if the table's rows violate the constraint.
+type Constrainer interface {
+	Adapter
+
+	// SetNotNull adds a NOT NULL constraint to a column.
+	SetNotNull(ctx context.Context, table, column string) error
+
+	// AddUnique adds a unique constraint over one or more columns.
+	AddUnique(ctx context.Context, table string, columns []string) error
+}
+
+// CostReporter is an optional interface for adapters that can report the
+// warehouse cost (bytes scanned, compute time) of the SQL they execute.
+type CostReporter interface {
+	Adapter
+
+	// ExecWithCost executes a SQL statement like Exec and returns its cost.
+	ExecWithCost(ctx context.Context, sql string) (core.QueryCost, error)
+}
+
+// Masker is an optional interface for adapters whose warehouse masks column
+// values with masking policies (e.g., Snowflake or Databricks), so PII
+// columns are stored unchanged and masked when read by unauthorized roles.
+type Masker interface {
+	Adapter
+
+	// SetMaskingPolicy attaches a masking policy to a column of a table or
+	// view. It must be idempotent.
+	SetMaskingPolicy(ctx context.Context, table, column, policy string) error
+}
+
+// Swapper is an optional interface for adapters that can replace a table or
+// view atomically, so readers see either the old relation or the new one and
+// never a missing or partially built one. Callers drop and recreate relations
+// in place for adapters that do not implement it.
+type Swapper interface {
+	Adapter
+
+	// SwapTable replaces target with staging, a fully built table in the same
+	// schema, which is renamed to target. target need not exist.
+	SwapTable(ctx context.Context, staging, target string) error
+
+	// ReplaceView creates or replaces a view defined by query.
+	ReplaceView(ctx context.Context, view, query string) error
+}
+
+// Transactor is an optional interface for adapters that can run a sequence of
+// statements in a single transaction. Callers run the statements one by one
+// for adapters that do not implement it.
+type Transactor interface {
+	Adapter
+
+	// Transaction calls fn in a transaction. Exec and Query calls made with
+	// the context passed to fn run in the transaction, on a single
+	// connection. The transaction commits if fn returns nil and rolls back
+	// otherwise.
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// TransactionalDDL reports whether DDL statements (CREATE, DROP, ALTER)
+	// take part in transactions. Where they commit implicitly instead, model
+	// builds run outside transactions unless configured otherwise.
+	TransactionalDDL() bool
+}
+
+// UniqueIndexName returns the name of the index enforcing a unique constraint
+// over columns of table, e.g. "orders_customer_id_order_date_unique".
+func UniqueIndexName(table string, columns []string) string {
+	name := table
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		name = table[i+1:]
+	}
+	return name + "_" + strings.Join(columns, "_") + "_unique"
+}
@@ -30,12 +30,73 @@ func (b *BaseSQLAdapter) Close() error {
 	return nil
 }
 
+// sqlConn is implemented by *sql.DB and *sql.Tx.
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txKey is the context key of the transaction started by Transaction.
+type txKey struct{}
+
+// txValue is a transaction together with the database it was started on, so
+// that another adapter given the same context does not use it.
+type txValue struct {
+	db *sql.DB
+	tx *sql.Tx
+}
+
+// Transaction calls fn in a transaction. Exec, Query and
+// GetTableMetadataCommon calls made with the context passed to fn run in the
+// transaction. The transaction commits if fn returns nil and rolls back
+// otherwise. A Transaction call inside fn joins the outer transaction.
+func (b *BaseSQLAdapter) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if b.DB == nil {
+		return fmt.Errorf("database connection not established")
+	}
+	if b.InTransaction(ctx) {
+		return fn(ctx)
+	}
+
+	tx, err := b.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(context.WithValue(ctx, txKey{}, txValue{db: b.DB, tx: tx})); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && b.Logger != nil {
+			b.Logger.Debug("failed to roll back transaction", "error", rbErr)
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// InTransaction reports whether ctx carries a transaction started by
+// Transaction on this adapter.
+func (b *BaseSQLAdapter) InTransaction(ctx context.Context) bool {
+	v, ok := ctx.Value(txKey{}).(txValue)
+	return ok && v.db == b.DB
+}
+
+// conn returns the transaction carried by ctx, or the database.
+func (b *BaseSQLAdapter) conn(ctx context.Context) sqlConn {
+	if v, ok := ctx.Value(txKey{}).(txValue); ok && v.db == b.DB {
+		return v.tx
+	}
+	return b.DB
+}
+
 // Exec executes a SQL statement that doesn't return rows.
+// The statement is prefixed with the query comment set on ctx, if any.
 func (b *BaseSQLAdapter) Exec(ctx context.Context, sqlStr string) error {
 	if b.DB == nil {
 		return fmt.Errorf("database connection not established")
 	}
-	_, err := b.DB.ExecContext(ctx, sqlStr)
+	_, err := b.conn(ctx).ExecContext(ctx, AnnotateSQL(ctx, sqlStr))
 	if err != nil {
 		return fmt.Errorf("failed to execute SQL: %w", err)
 	}
@@ -43,12 +104,13 @@ func (b *BaseSQLAdapter) Exec(ctx context.Context, sqlStr string) error {
 }
 
 // Query executes a SQL statement that returns rows.
+// The statement is prefixed with the query comment set on ctx, if any.
 func (b *BaseSQLAdapter) Query(ctx context.Context, sqlStr string) (*core.Rows, error) {
 	if b.DB == nil {
 		return nil, fmt.Errorf("database connection not established")
 	}
 	//nolint:rowserrcheck // rows.Err() must be checked by caller after iteration completes
-	rows, err := b.DB.QueryContext(ctx, sqlStr)
+	rows, err := b.conn(ctx).QueryContext(ctx, AnnotateSQL(ctx, sqlStr))
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -61,12 +123,24 @@ func (b *BaseSQLAdapter) IsConnected() bool {
 }
 
 // ParseQualifiedName splits a table reference into schema and name.
-// Uses the dialect's default schema if not specified.
+// Uses the dialect's default schema if not specified. The catalog of a
+// three-part name is dropped.
 func ParseQualifiedName(table string, cfg *core.DialectConfig) (schema, name string) {
-	if parts := strings.Split(table, "."); len(parts) == 2 {
-		return parts[0], parts[1]
+	_, schema, name = ParseCatalogQualifiedName(table, cfg)
+	return schema, name
+}
+
+// ParseCatalogQualifiedName splits a table reference into catalog, schema
+// and name. The catalog is empty unless the reference has three parts
+// (catalog.schema.table); the dialect's default schema is used if not specified.
+func ParseCatalogQualifiedName(table string, cfg *core.DialectConfig) (catalog, schema, name string) {
+	switch parts := strings.Split(table, "."); len(parts) {
+	case 3:
+		return parts[0], parts[1], parts[2]
+	case 2:
+		return "", parts[0], parts[1]
 	}
-	return cfg.DefaultSchema, table
+	return "", cfg.DefaultSchema, table
 }
 
 // GetTableMetadataCommon provides a shared implementation of GetTableMetadata.
@@ -77,7 +151,16 @@ func (b *BaseSQLAdapter) GetTableMetadataCommon(ctx context.Context, table strin
 		return nil, fmt.Errorf("database connection not established")
 	}
 
-	schema, tableName := ParseQualifiedName(table, cfg)
+	catalog, schema, tableName := ParseCatalogQualifiedName(table, cfg)
+
+	// Tables of other catalogs (e.g., attached databases) are only found by
+	// three-part names
+	args := []any{schema, tableName}
+	catalogFilter := "current_database()"
+	if catalog != "" {
+		args = append(args, catalog)
+		catalogFilter = cfg.Placeholder.FormatPlaceholder(3)
+	}
 
 	// Build query with appropriate placeholders
 	// The placeholders come from the dialect and are safe (? or $N)
@@ -89,11 +172,11 @@ func (b *BaseSQLAdapter) GetTableMetadataCommon(ctx context.Context, table strin
 			is_nullable,
 			ordinal_position
 		FROM information_schema.columns 
-		WHERE table_schema = %s AND table_name = %s
+		WHERE table_schema = %s AND table_name = %s AND table_catalog = %s
 		ORDER BY ordinal_position
-	`, cfg.Placeholder.FormatPlaceholder(1), cfg.Placeholder.FormatPlaceholder(2))
+	`, cfg.Placeholder.FormatPlaceholder(1), cfg.Placeholder.FormatPlaceholder(2), catalogFilter)
 
-	rows, err := b.DB.QueryContext(ctx, query, schema, tableName)
+	rows, err := b.conn(ctx).QueryContext(ctx, AnnotateSQL(ctx, query), args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query column metadata: %w", err)
 	}
@@ -119,9 +202,9 @@ func (b *BaseSQLAdapter) GetTableMetadataCommon(ctx context.Context, table strin
 	}
 
 	// Get row count
-	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s.%s", schema, tableName) //nolint:gosec // Table names are from metadata
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s", table) //nolint:gosec // Table names are from metadata
 	var rowCount int64
-	if err := b.DB.QueryRowContext(ctx, countQuery).Scan(&rowCount); err != nil {
+	if err := b.conn(ctx).QueryRowContext(ctx, AnnotateSQL(ctx, countQuery)).Scan(&rowCount); err != nil {
 		// Non-fatal error, just set to 0
 		rowCount = 0
 	}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -219,3 +220,28 @@ func TestBaseSQLAdapter_IsConnected(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCatalogQualifiedName(t *testing.T) {
+	cfg := &core.DialectConfig{DefaultSchema: "main"}
+	tests := []struct {
+		table                 string
+		catalog, schema, name string
+	}{
+		{table: "orders", schema: "main", name: "orders"},
+		{table: "staging.orders", schema: "staging", name: "orders"},
+		{table: "lake.staging.orders", catalog: "lake", schema: "staging", name: "orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			catalog, schema, name := ParseCatalogQualifiedName(tt.table, cfg)
+			assert.Equal(t, tt.catalog, catalog)
+			assert.Equal(t, tt.schema, schema)
+			assert.Equal(t, tt.name, name)
+
+			schema, name = ParseQualifiedName(tt.table, cfg)
+			assert.Equal(t, tt.schema, schema)
+			assert.Equal(t, tt.name, name)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package adapter
+
+import (
+	"context"
+	"strings"
+)
+
+type queryCommentKey struct{}
+
+// WithQueryComment returns a context whose statements are prefixed with a SQL
+// comment by the adapters, so warehouse query logs can attribute them back to
+// the run and model that issued them. An empty comment removes the prefix.
+func WithQueryComment(ctx context.Context, comment string) context.Context {
+	// A comment must not be able to close itself early
+	comment = strings.ReplaceAll(comment, "*/", "* /")
+	return context.WithValue(ctx, queryCommentKey{}, comment)
+}
+
+// QueryComment returns the query comment set on ctx, if any.
+func QueryComment(ctx context.Context) string {
+	comment, _ := ctx.Value(queryCommentKey{}).(string)
+	return comment
+}
+
+// AnnotateSQL prefixes a statement with the query comment set on ctx.
+// Adapters call it on every statement they execute.
+func AnnotateSQL(ctx context.Context, sql string) string {
+	comment := QueryComment(ctx)
+	if comment == "" {
+		return sql
+	}
+	return "/* " + comment + " */\n" + sql
+}
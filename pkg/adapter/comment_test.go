@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotateSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		want    string
+	}{
+		{
+			name: "no comment",
+			want: "SELECT 1",
+		},
+		{
+			name:    "comment",
+			comment: `{"model":"staging.orders"}`,
+			want:    "/* {\"model\":\"staging.orders\"} */\nSELECT 1",
+		},
+		{
+			name:    "comment closing itself",
+			comment: "model */ DROP TABLE users; /*",
+			want:    "/* model * / DROP TABLE users; /* */\nSELECT 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.comment != "" {
+				ctx = WithQueryComment(ctx, tt.comment)
+			}
+			assert.Equal(t, tt.want, AnnotateSQL(ctx, "SELECT 1"))
+		})
+	}
+}
+
+func TestBaseSQLAdapter_QueryComment(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	mock.ExpectExec("/* run 1 */\nCREATE TABLE users (id INT)").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("/* run 1 */\nSELECT id FROM users").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	base := &BaseSQLAdapter{DB: db}
+	ctx := WithQueryComment(context.Background(), "run 1")
+
+	require.NoError(t, base.Exec(ctx, "CREATE TABLE users (id INT)"))
+	rows, err := base.Query(ctx, "SELECT id FROM users")
+	require.NoError(t, err)
+	_ = rows.Close()
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
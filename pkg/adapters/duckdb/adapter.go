@@ -6,7 +6,10 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/go-viper/mapstructure/v2"
@@ -14,12 +17,19 @@ import (
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	duckdbdialect "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
 
-	_ "github.com/marcboeker/go-duckdb" // duckdb driver
+	goduckdb "github.com/marcboeker/go-duckdb" // duckdb driver
 )
 
 // Adapter implements the adapter.Adapter interface for DuckDB.
 type Adapter struct {
 	adapter.BaseSQLAdapter
+
+	// attached holds the aliases of databases attached from params, detached on Close
+	attached []string
+
+	// open opens the database for a DSN (replaced in tests to connect
+	// without DuckDB or MotherDuck)
+	open func(dsn string) (*sql.DB, error)
 }
 
 // New creates a new DuckDB adapter instance.
@@ -30,25 +40,51 @@ func New(logger *slog.Logger) *Adapter {
 	}
 	return &Adapter{
 		BaseSQLAdapter: adapter.BaseSQLAdapter{Logger: logger},
+		open:           openDuckDB,
 	}
 }
 
+// openDuckDB opens a DuckDB database with the go-duckdb driver.
+func openDuckDB(dsn string) (*sql.DB, error) {
+	return sql.Open("duckdb", dsn)
+}
+
 // DialectConfig returns the SQL dialect configuration for this adapter.
 func (a *Adapter) DialectConfig() *core.DialectConfig {
 	return duckdbdialect.DuckDB.Config()
 }
 
 // Connect establishes a connection to DuckDB.
-// Use ":memory:" as the path for an in-memory database.
+// Use ":memory:" as the path for an in-memory database, or an "md:" path
+// such as "md:analytics" for a MotherDuck database.
 func (a *Adapter) Connect(ctx context.Context, cfg core.AdapterConfig) error {
 	path := cfg.Path
 	if path == "" {
 		path = ":memory:"
 	}
 
+	// Parse adapter-specific params
+	params, err := parseParams(cfg.Params)
+	if err != nil {
+		return fmt.Errorf("failed to parse duckdb params: %w", err)
+	}
+
+	dsn := path
+	if IsMotherDuck(path) {
+		token := motherDuckToken(params)
+		if token == "" && !strings.Contains(path, "motherduck_token=") {
+			return fmt.Errorf("MotherDuck database %s requires a token: set params.motherduck_token or the MOTHERDUCK_TOKEN environment variable", path)
+		}
+		dsn = motherDuckDSN(path, token)
+	}
+
 	a.Logger.Debug("connecting to duckdb", slog.String("path", path))
 
-	db, err := sql.Open("duckdb", path)
+	open := a.open
+	if open == nil {
+		open = openDuckDB
+	}
+	db, err := open(dsn)
 	if err != nil {
 		return fmt.Errorf("failed to open duckdb connection: %w", err)
 	}
@@ -62,13 +98,7 @@ func (a *Adapter) Connect(ctx context.Context, cfg core.AdapterConfig) error {
 	a.DB = db
 	a.Cfg = cfg
 
-	// Parse and apply adapter-specific params
-	params, err := parseParams(cfg.Params)
-	if err != nil {
-		_ = db.Close()
-		return fmt.Errorf("failed to parse duckdb params: %w", err)
-	}
-
+	// Apply adapter-specific params
 	if err := a.applyParams(ctx, params); err != nil {
 		_ = db.Close()
 		return fmt.Errorf("failed to apply duckdb params: %w", err)
@@ -77,6 +107,19 @@ func (a *Adapter) Connect(ctx context.Context, cfg core.AdapterConfig) error {
 	return nil
 }
 
+// Close detaches the databases attached from params and closes the connection.
+func (a *Adapter) Close() error {
+	if a.DB != nil {
+		for _, alias := range a.attached {
+			if _, err := a.DB.ExecContext(context.Background(), fmt.Sprintf("DETACH DATABASE IF EXISTS %s", alias)); err != nil {
+				a.Logger.Debug("failed to detach database", slog.String("alias", alias), slog.String("error", err.Error()))
+			}
+		}
+	}
+	a.attached = nil
+	return a.BaseSQLAdapter.Close()
+}
+
 // GetTableMetadata retrieves metadata for a specified table.
 func (a *Adapter) GetTableMetadata(ctx context.Context, table string) (*core.TableMetadata, error) {
 	return a.GetTableMetadataCommon(ctx, table, a.DialectConfig())
@@ -109,6 +152,100 @@ func (a *Adapter) LoadCSV(ctx context.Context, tableName string, filePath string
 	return nil
 }
 
+// SetNotNull adds a NOT NULL constraint to a column.
+func (a *Adapter) SetNotNull(ctx context.Context, table, column string) error {
+	return a.Exec(ctx, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", table, column))
+}
+
+// AddUnique adds a unique constraint over columns, enforced by a unique index.
+func (a *Adapter) AddUnique(ctx context.Context, table string, columns []string) error {
+	return a.Exec(ctx, fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (%s)",
+		adapter.UniqueIndexName(table, columns), table, strings.Join(columns, ", ")))
+}
+
+// TransactionalDDL reports true: DuckDB DDL statements are transactional.
+func (a *Adapter) TransactionalDDL() bool {
+	return true
+}
+
+// SwapTable drops target and renames staging to target in a transaction.
+func (a *Adapter) SwapTable(ctx context.Context, staging, target string) error {
+	_, name := adapter.ParseQualifiedName(target, a.DialectConfig())
+	return a.Transaction(ctx, func(ctx context.Context) error {
+		if err := a.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", target)); err != nil {
+			return err
+		}
+		return a.Exec(ctx, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", staging, name))
+	})
+}
+
+// ReplaceView creates or replaces a view with CREATE OR REPLACE VIEW.
+func (a *Adapter) ReplaceView(ctx context.Context, view, query string) error {
+	return a.Exec(ctx, fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", view, query))
+}
+
+// ExecWithCost executes a SQL statement with DuckDB's profiler enabled and
+// returns its cost: the bytes produced by table scans and the CPU time.
+func (a *Adapter) ExecWithCost(ctx context.Context, sqlStr string) (core.QueryCost, error) {
+	if a.DB == nil {
+		return core.QueryCost{}, fmt.Errorf("database connection not established")
+	}
+
+	// The transaction's connection is not available for profiling, so the
+	// cost of statements run in a transaction is unknown
+	if a.InTransaction(ctx) {
+		return core.QueryCost{}, a.Exec(ctx, sqlStr)
+	}
+
+	// Profiling is a connection setting, so the statement runs on a dedicated connection
+	conn, err := a.DB.Conn(ctx)
+	if err != nil {
+		return core.QueryCost{}, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA enable_profiling = 'no_output'"); err != nil {
+		return core.QueryCost{}, fmt.Errorf("failed to enable profiling: %w", err)
+	}
+	defer func() { _, _ = conn.ExecContext(ctx, "PRAGMA disable_profiling") }()
+
+	if _, err := conn.ExecContext(ctx, adapter.AnnotateSQL(ctx, sqlStr)); err != nil {
+		return core.QueryCost{}, fmt.Errorf("failed to execute SQL: %w", err)
+	}
+
+	info, err := goduckdb.GetProfilingInfo(conn)
+	if err != nil {
+		// The statement succeeded; its cost is unknown
+		a.Logger.Debug("no profiling info", slog.String("error", err.Error()))
+		return core.QueryCost{}, nil
+	}
+	return profilingCost(info), nil
+}
+
+// profilingCost sums the cost of a profiled query: the CPU time of the query
+// and the result size of every scan operator.
+func profilingCost(info goduckdb.ProfilingInfo) core.QueryCost {
+	var cost core.QueryCost
+	if cpu, err := strconv.ParseFloat(info.Metrics["CPU_TIME"], 64); err == nil {
+		cost.SlotMS = int64(cpu * 1000)
+	}
+
+	var walk func(node goduckdb.ProfilingInfo)
+	walk = func(node goduckdb.ProfilingInfo) {
+		if strings.HasSuffix(node.Metrics["OPERATOR_TYPE"], "_SCAN") {
+			if size, err := strconv.ParseInt(node.Metrics["RESULT_SET_SIZE"], 10, 64); err == nil {
+				cost.BytesScanned += size
+			}
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(info)
+
+	return cost
+}
+
 // parseParams decodes core.AdapterConfig.Params into Params.
 func parseParams(raw map[string]any) (*Params, error) {
 	if raw == nil {
@@ -155,6 +292,21 @@ func (a *Adapter) applyParams(ctx context.Context, params *Params) error {
 		}
 	}
 
+	// 4. Attach databases (after extensions and secrets, which they may need)
+	if params.MotherDuckToken != "" && !IsMotherDuck(a.Cfg.Path) {
+		// MotherDuck databases attached to a local database authenticate with the
+		// token (set directly: applySetting logs values)
+		sql := fmt.Sprintf("SET motherduck_token = '%s'", strings.ReplaceAll(params.MotherDuckToken, "'", "''"))
+		if _, err := a.DB.ExecContext(ctx, sql); err != nil {
+			return fmt.Errorf("failed to set motherduck_token: %w", err)
+		}
+	}
+	for _, db := range params.Attach {
+		if err := a.attachDatabase(ctx, db); err != nil {
+			return fmt.Errorf("failed to attach database %q: %w", db.Path, err)
+		}
+	}
+
 	return nil
 }
 
@@ -257,6 +409,79 @@ func buildCreateSecretSQL(cfg SecretConfig) string {
 	return b.String()
 }
 
+// attachDatabase attaches a database under its alias.
+func (a *Adapter) attachDatabase(ctx context.Context, cfg AttachConfig) error {
+	alias := attachAlias(cfg)
+	a.Logger.Debug("attaching database",
+		slog.String("path", cfg.Path),
+		slog.String("alias", alias),
+	)
+
+	if _, err := a.DB.ExecContext(ctx, buildAttachSQL(cfg)); err != nil {
+		return err
+	}
+	a.attached = append(a.attached, alias)
+	return nil
+}
+
+// attachAlias returns the catalog name of an attached database: its alias,
+// or like DuckDB, the file name without extension.
+func attachAlias(cfg AttachConfig) string {
+	if cfg.Alias != "" {
+		return cfg.Alias
+	}
+	name := filepath.Base(strings.TrimPrefix(cfg.Path, "md:"))
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// buildAttachSQL constructs the ATTACH statement.
+func buildAttachSQL(cfg AttachConfig) string {
+	var opts []string
+	if cfg.Type != "" && cfg.Type != "duckdb" {
+		opts = append(opts, "TYPE "+cfg.Type)
+	}
+	if cfg.ReadOnly {
+		opts = append(opts, "READ_ONLY")
+	}
+
+	sql := fmt.Sprintf("ATTACH IF NOT EXISTS '%s' AS %s",
+		strings.ReplaceAll(cfg.Path, "'", "''"), attachAlias(cfg))
+	if len(opts) > 0 {
+		sql += " (" + strings.Join(opts, ", ") + ")"
+	}
+	return sql
+}
+
+// IsMotherDuck reports whether a DuckDB path is a MotherDuck connection
+// string: "md:" for the default database or "md:<database>".
+func IsMotherDuck(path string) bool {
+	return strings.HasPrefix(path, "md:")
+}
+
+// motherDuckToken returns the token to connect to MotherDuck with: the
+// motherduck_token param, or the MOTHERDUCK_TOKEN environment variable.
+func motherDuckToken(params *Params) string {
+	if params.MotherDuckToken != "" {
+		return params.MotherDuckToken
+	}
+	if token := os.Getenv("MOTHERDUCK_TOKEN"); token != "" {
+		return token
+	}
+	return os.Getenv("motherduck_token")
+}
+
+// motherDuckDSN adds the token to a MotherDuck connection string.
+func motherDuckDSN(path, token string) string {
+	if token == "" {
+		return path
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + "motherduck_token=" + url.QueryEscape(token)
+}
+
 // applySetting applies a DuckDB session setting.
 func (a *Adapter) applySetting(ctx context.Context, key, value string) error {
 	a.Logger.Debug("applying setting",
@@ -269,5 +494,11 @@ func (a *Adapter) applySetting(ctx context.Context, key, value string) error {
 	return err
 }
 
-// Ensure Adapter implements adapter.Adapter interface
-var _ adapter.Adapter = (*Adapter)(nil)
+// Ensure Adapter implements adapter.Adapter and its optional interfaces
+var (
+	_ adapter.Adapter      = (*Adapter)(nil)
+	_ adapter.Constrainer  = (*Adapter)(nil)
+	_ adapter.CostReporter = (*Adapter)(nil)
+	_ adapter.Swapper      = (*Adapter)(nil)
+	_ adapter.Transactor   = (*Adapter)(nil)
+)
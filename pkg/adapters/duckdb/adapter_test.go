@@ -2,10 +2,12 @@ package duckdb
 
 import (
 	"context"
+	"database/sql"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -344,6 +346,114 @@ func TestAdapter_LoadCSV(t *testing.T) {
 	assert.Len(t, metadata.Columns, 3)
 }
 
+func TestAdapter_Constraints(t *testing.T) {
+	ctx := context.Background()
+	adp := New(nil)
+	require.NoError(t, adp.Connect(ctx, core.AdapterConfig{Path: ":memory:"}))
+	defer func() { _ = adp.Close() }()
+
+	require.NoError(t, adp.Exec(ctx, "CREATE TABLE users AS SELECT * FROM (VALUES (1, 'a'), (2, NULL)) AS t(id, email)"))
+
+	require.NoError(t, adp.SetNotNull(ctx, "users", "id"))
+	require.NoError(t, adp.SetNotNull(ctx, "users", "id"), "SetNotNull should be idempotent")
+	assert.Error(t, adp.SetNotNull(ctx, "users", "email"), "existing nulls should violate NOT NULL")
+
+	require.NoError(t, adp.AddUnique(ctx, "users", []string{"id"}))
+	require.NoError(t, adp.AddUnique(ctx, "users", []string{"id"}), "AddUnique should be idempotent")
+
+	assert.Error(t, adp.Exec(ctx, "INSERT INTO users VALUES (NULL, 'c')"))
+	assert.Error(t, adp.Exec(ctx, "INSERT INTO users VALUES (1, 'c')"))
+	assert.NoError(t, adp.Exec(ctx, "INSERT INTO users VALUES (3, 'c')"))
+}
+
+func TestAdapter_ExecWithCost(t *testing.T) {
+	ctx := context.Background()
+	adp := New(nil)
+	require.NoError(t, adp.Connect(ctx, core.AdapterConfig{Path: ":memory:"}))
+	defer func() { _ = adp.Close() }()
+
+	require.NoError(t, adp.Exec(ctx, "CREATE TABLE numbers AS SELECT range AS n FROM range(10000)"))
+
+	cost, err := adp.ExecWithCost(ctx, "CREATE TABLE evens AS SELECT n FROM numbers WHERE n % 2 = 0")
+	require.NoError(t, err)
+	assert.Positive(t, cost.BytesScanned)
+
+	_, err = adp.ExecWithCost(ctx, "SELECT * FROM missing")
+	assert.Error(t, err)
+
+	rows, err := adp.Query(ctx, "SELECT COUNT(*) FROM evens")
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+	require.True(t, rows.Next())
+	var count int64
+	require.NoError(t, rows.Scan(&count))
+	assert.Equal(t, int64(5000), count)
+}
+
+func TestAdapter_Transaction(t *testing.T) {
+	ctx := context.Background()
+	adp := New(nil)
+	require.NoError(t, adp.Connect(ctx, core.AdapterConfig{Path: ":memory:"}))
+	defer func() { _ = adp.Close() }()
+
+	// Temporary macros are visible to the later statements of the transaction
+	err := adp.Transaction(ctx, func(ctx context.Context) error {
+		if err := adp.Exec(ctx, "CREATE TEMP MACRO double_it(x) AS x * 2"); err != nil {
+			return err
+		}
+		cost, err := adp.ExecWithCost(ctx, "CREATE TABLE doubled AS SELECT double_it(21) AS n")
+		assert.Zero(t, cost)
+		return err
+	})
+	require.NoError(t, err)
+	meta, err := adp.GetTableMetadata(ctx, "doubled")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), meta.RowCount)
+
+	// A failing statement rolls back the whole transaction
+	err = adp.Transaction(ctx, func(ctx context.Context) error {
+		if err := adp.Exec(ctx, "CREATE TABLE partial AS SELECT 1 AS n"); err != nil {
+			return err
+		}
+		return adp.Exec(ctx, "SELECT * FROM missing")
+	})
+	require.Error(t, err)
+	_, err = adp.GetTableMetadata(ctx, "partial")
+	assert.Error(t, err)
+}
+
+func TestAdapter_SwapTable(t *testing.T) {
+	ctx := context.Background()
+	adp := New(nil)
+	require.NoError(t, adp.Connect(ctx, core.AdapterConfig{Path: ":memory:"}))
+	defer func() { _ = adp.Close() }()
+
+	require.NoError(t, adp.Exec(ctx, "CREATE SCHEMA marts"))
+	require.NoError(t, adp.Exec(ctx, "CREATE TABLE marts.orders AS SELECT 1 AS id"))
+	require.NoError(t, adp.AddUnique(ctx, "marts.orders", []string{"id"}))
+	require.NoError(t, adp.Exec(ctx, "CREATE TABLE marts.orders__staging AS SELECT * FROM range(3) t(id)"))
+
+	require.NoError(t, adp.SwapTable(ctx, "marts.orders__staging", "marts.orders"))
+	meta, err := adp.GetTableMetadata(ctx, "marts.orders")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), meta.RowCount)
+	_, err = adp.GetTableMetadata(ctx, "marts.orders__staging")
+	assert.Error(t, err)
+
+	// The target need not exist
+	require.NoError(t, adp.Exec(ctx, "CREATE TABLE marts.customers__staging AS SELECT 1 AS id"))
+	require.NoError(t, adp.SwapTable(ctx, "marts.customers__staging", "marts.customers"))
+	_, err = adp.GetTableMetadata(ctx, "marts.customers")
+	require.NoError(t, err)
+
+	// A view can be replaced with one with different columns
+	require.NoError(t, adp.ReplaceView(ctx, "marts.order_ids", "SELECT id FROM marts.orders"))
+	require.NoError(t, adp.ReplaceView(ctx, "marts.order_ids", "SELECT id, id * 2 AS doubled FROM marts.orders"))
+	meta, err = adp.GetTableMetadata(ctx, "marts.order_ids")
+	require.NoError(t, err)
+	assert.Len(t, meta.Columns, 2)
+}
+
 func TestBuildCreateSecretSQL(t *testing.T) {
 	tests := []struct {
 		name string
@@ -533,6 +643,176 @@ func TestConnect_WithSettings(t *testing.T) {
 	assert.Equal(t, "2", threadsSetting)
 }
 
+func TestBuildAttachSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  AttachConfig
+		want string
+	}{
+		{
+			name: "alias from file name",
+			cfg:  AttachConfig{Path: "data/lake.duckdb"},
+			want: "ATTACH IF NOT EXISTS 'data/lake.duckdb' AS lake",
+		},
+		{
+			name: "explicit alias",
+			cfg:  AttachConfig{Path: "data/lake.duckdb", Alias: "raw", Type: "duckdb"},
+			want: "ATTACH IF NOT EXISTS 'data/lake.duckdb' AS raw",
+		},
+		{
+			name: "motherduck",
+			cfg:  AttachConfig{Path: "md:analytics"},
+			want: "ATTACH IF NOT EXISTS 'md:analytics' AS analytics",
+		},
+		{
+			name: "sqlite read-only",
+			cfg:  AttachConfig{Path: "crm.db", Type: "sqlite", ReadOnly: true},
+			want: "ATTACH IF NOT EXISTS 'crm.db' AS crm (TYPE sqlite, READ_ONLY)",
+		},
+		{
+			name: "quoted path",
+			cfg:  AttachConfig{Path: "it's.duckdb", Alias: "its"},
+			want: "ATTACH IF NOT EXISTS 'it''s.duckdb' AS its",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, buildAttachSQL(tt.cfg))
+		})
+	}
+}
+
+func TestConnect_WithAttach(t *testing.T) {
+	ctx := context.Background()
+	lake := filepath.Join(t.TempDir(), "lake.duckdb")
+
+	adp := New(nil)
+	cfg := core.AdapterConfig{
+		Path: ":memory:",
+		Params: map[string]any{
+			"attach": []any{map[string]any{"path": lake}},
+		},
+	}
+	require.NoError(t, adp.Connect(ctx, cfg))
+
+	// Three-part names reach the attached database
+	require.NoError(t, adp.Exec(ctx, "CREATE SCHEMA lake.staging"))
+	require.NoError(t, adp.Exec(ctx, "CREATE TABLE lake.staging.orders AS SELECT 1 AS id"))
+	meta, err := adp.GetTableMetadata(ctx, "lake.staging.orders")
+	require.NoError(t, err)
+	assert.Len(t, meta.Columns, 1)
+	require.NoError(t, adp.Close())
+
+	// The table was written to the attached file
+	adp = New(nil)
+	require.NoError(t, adp.Connect(ctx, core.AdapterConfig{Path: lake}))
+	defer func() { _ = adp.Close() }()
+	rows, err := adp.Query(ctx, "SELECT id FROM staging.orders")
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+	require.True(t, rows.Next())
+}
+
+func TestConnect_MotherDuck(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		params   map[string]any
+		envToken string
+		wantDSN  string
+		wantErr  string
+	}{
+		{
+			name:    "token from params",
+			path:    "md:analytics",
+			params:  map[string]any{"motherduck_token": "tok"},
+			wantDSN: "md:analytics?motherduck_token=tok",
+		},
+		{
+			name:     "token from environment",
+			path:     "md:",
+			envToken: "env-tok",
+			wantDSN:  "md:?motherduck_token=env-tok",
+		},
+		{
+			name:     "params token wins over environment",
+			path:     "md:analytics",
+			params:   map[string]any{"motherduck_token": "tok"},
+			envToken: "env-tok",
+			wantDSN:  "md:analytics?motherduck_token=tok",
+		},
+		{
+			name:    "connection string options are kept",
+			path:    "md:analytics?saas_mode=true",
+			params:  map[string]any{"motherduck_token": "a/b+c"},
+			wantDSN: "md:analytics?saas_mode=true&motherduck_token=a%2Fb%2Bc",
+		},
+		{
+			name:    "token in connection string",
+			path:    "md:analytics?motherduck_token=tok",
+			wantDSN: "md:analytics?motherduck_token=tok",
+		},
+		{
+			name:    "missing token",
+			path:    "md:analytics",
+			wantErr: "requires a token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MOTHERDUCK_TOKEN", tt.envToken)
+			t.Setenv("motherduck_token", "")
+
+			var gotDSN string
+			adp := New(nil)
+			adp.open = func(dsn string) (*sql.DB, error) {
+				gotDSN = dsn
+				db, _, err := sqlmock.New()
+				return db, err
+			}
+
+			err := adp.Connect(context.Background(), core.AdapterConfig{Path: tt.path, Params: tt.params})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Empty(t, gotDSN, "must not connect without a token")
+				return
+			}
+			require.NoError(t, err)
+			defer func() { _ = adp.Close() }()
+			assert.Equal(t, tt.wantDSN, gotDSN)
+		})
+	}
+}
+
+func TestConnect_AttachMotherDuck(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	adp := New(nil)
+	adp.open = func(dsn string) (*sql.DB, error) {
+		assert.Equal(t, "local.duckdb", dsn, "local databases get no token")
+		return db, nil
+	}
+
+	mock.ExpectExec(`SET motherduck_token = 'tok'`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`ATTACH IF NOT EXISTS 'md:analytics' AS analytics`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DETACH DATABASE IF EXISTS analytics`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectClose()
+
+	require.NoError(t, adp.Connect(context.Background(), core.AdapterConfig{
+		Path: "local.duckdb",
+		Params: map[string]any{
+			"motherduck_token": "tok",
+			"attach":           []any{map[string]any{"path": "md:analytics"}},
+		},
+	}))
+	require.NoError(t, adp.Close())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestConnect_WithNilParams(t *testing.T) {
 	ctx := context.Background()
 	adp := New(nil)
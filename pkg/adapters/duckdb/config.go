@@ -11,6 +11,30 @@ type Params struct {
 
 	// Settings to apply at session level (e.g., memory_limit, threads)
 	Settings map[string]string `mapstructure:"settings"`
+
+	// Attach lists databases to attach alongside the main database
+	Attach []AttachConfig `mapstructure:"attach"`
+
+	// MotherDuckToken authenticates MotherDuck databases ("md:" paths), as the
+	// target database or attached (default: MOTHERDUCK_TOKEN environment variable)
+	MotherDuckToken string `mapstructure:"motherduck_token"`
+}
+
+// AttachConfig defines a database attached with ATTACH. Models and sources
+// in it are referenced by three-part names: alias.schema.table.
+type AttachConfig struct {
+	// Path of the database file or connection string (e.g., "lake.duckdb", "md:analytics")
+	Path string `mapstructure:"path"`
+
+	// Alias is the catalog name the database is attached as
+	// (default: the file name without extension)
+	Alias string `mapstructure:"alias,omitempty"`
+
+	// Type: "duckdb" (default), "sqlite", "postgres", "mysql"
+	Type string `mapstructure:"type,omitempty"`
+
+	// ReadOnly attaches the database in read-only mode
+	ReadOnly bool `mapstructure:"read_only,omitempty"`
 }
 
 // SecretConfig defines a DuckDB secret for cloud storage.
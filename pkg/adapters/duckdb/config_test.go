@@ -157,6 +157,28 @@ func TestParseParams(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "attached databases",
+			input: map[string]any{
+				"attach": []any{
+					map[string]any{"path": "lake.duckdb"},
+					map[string]any{"path": "crm.db", "alias": "crm", "type": "sqlite", "read_only": true},
+				},
+			},
+			want: &Params{
+				Attach: []AttachConfig{
+					{Path: "lake.duckdb"},
+					{Path: "crm.db", Alias: "crm", Type: "sqlite", ReadOnly: true},
+				},
+			},
+		},
+		{
+			name: "motherduck token",
+			input: map[string]any{
+				"motherduck_token": "tok",
+			},
+			want: &Params{MotherDuckToken: "tok"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -171,6 +193,8 @@ func TestParseParams(t *testing.T) {
 			require.NoError(t, err)
 			assert.Equal(t, tt.want.Extensions, got.Extensions)
 			assert.Equal(t, tt.want.Settings, got.Settings)
+			assert.Equal(t, tt.want.Attach, got.Attach)
+			assert.Equal(t, tt.want.MotherDuckToken, got.MotherDuckToken)
 			assert.Len(t, got.Secrets, len(tt.want.Secrets))
 
 			for i, wantSecret := range tt.want.Secrets {
@@ -146,11 +146,49 @@ func (a *Adapter) LoadCSV(ctx context.Context, tableName string, filePath string
 	return nil
 }
 
+// SetNotNull adds a NOT NULL constraint to a column.
+func (a *Adapter) SetNotNull(ctx context.Context, table, column string) error {
+	return a.Exec(ctx, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", table, column))
+}
+
+// AddUnique adds a unique constraint over columns, enforced by a unique index.
+func (a *Adapter) AddUnique(ctx context.Context, table string, columns []string) error {
+	return a.Exec(ctx, fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (%s)",
+		adapter.UniqueIndexName(table, columns), table, strings.Join(columns, ", ")))
+}
+
+// TransactionalDDL reports true: PostgreSQL DDL statements are transactional.
+func (a *Adapter) TransactionalDDL() bool {
+	return true
+}
+
+// SwapTable drops target and renames staging to target in a transaction.
+func (a *Adapter) SwapTable(ctx context.Context, staging, target string) error {
+	_, name := adapter.ParseQualifiedName(target, a.DialectConfig())
+	return a.Transaction(ctx, func(ctx context.Context) error {
+		if err := a.Exec(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %s", target)); err != nil {
+			return err
+		}
+		return a.Exec(ctx, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", staging, name))
+	})
+}
+
+// ReplaceView drops and recreates a view in a transaction. CREATE OR REPLACE
+// VIEW is not used, as PostgreSQL rejects it when the view's columns change.
+func (a *Adapter) ReplaceView(ctx context.Context, view, query string) error {
+	return a.Transaction(ctx, func(ctx context.Context) error {
+		if err := a.Exec(ctx, fmt.Sprintf("DROP VIEW IF EXISTS %s", view)); err != nil {
+			return err
+		}
+		return a.Exec(ctx, fmt.Sprintf("CREATE VIEW %s AS %s", view, query))
+	})
+}
+
 // createTextTable creates or replaces a table with all TEXT columns.
 func (a *Adapter) createTextTable(ctx context.Context, tableName string, columns []string) error {
 	// Drop existing table
 	dropSQL := fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)
-	if _, err := a.DB.ExecContext(ctx, dropSQL); err != nil {
+	if _, err := a.DB.ExecContext(ctx, adapter.AnnotateSQL(ctx, dropSQL)); err != nil {
 		return err
 	}
 
@@ -163,7 +201,7 @@ func (a *Adapter) createTextTable(ctx context.Context, tableName string, columns
 	}
 
 	createSQL := fmt.Sprintf("CREATE TABLE %s (%s)", tableName, strings.Join(colDefs, ", "))
-	_, err := a.DB.ExecContext(ctx, createSQL)
+	_, err := a.DB.ExecContext(ctx, adapter.AnnotateSQL(ctx, createSQL))
 	return err
 }
 
@@ -205,5 +243,10 @@ func sanitizeIdentifier(name string, d *core.Dialect) string {
 	return safe
 }
 
-// Ensure Adapter implements adapter.Adapter interface
-var _ adapter.Adapter = (*Adapter)(nil)
+// Ensure Adapter implements adapter.Adapter and its optional interfaces
+var (
+	_ adapter.Adapter     = (*Adapter)(nil)
+	_ adapter.Constrainer = (*Adapter)(nil)
+	_ adapter.Swapper     = (*Adapter)(nil)
+	_ adapter.Transactor  = (*Adapter)(nil)
+)
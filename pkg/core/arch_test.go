@@ -278,7 +278,6 @@ func isSharedUtility(path string) bool {
 	shared := map[string]bool{
 		"internal/config":        true, // Shared config loading/defaults
 		"internal/loader":        true, // Model/seed loading
-		"internal/lineage":       true, // SQL lineage extraction
 		"internal/state":         true, // State persistence
 		"internal/state/sqlcgen": true, // Generated SQL code
 		"internal/dag":           true, // Dependency graph
@@ -286,6 +285,7 @@ func isSharedUtility(path string) bool {
 		"internal/starlark":      true, // Starlark execution
 		"internal/macro":         true, // Macro definitions
 		"internal/registry":      true, // Generic registry
+		"internal/selector":      true, // Model selection expressions
 		"internal/testutil":      true, // Test utilities
 	}
 	return shared[path]
@@ -318,6 +318,24 @@ func isAllowedUtilityException(from, to string) bool {
 	return false
 }
 
+// isAllowedComponentException returns true for explicitly allowed
+// component-to-component imports.
+func isAllowedComponentException(from, to string) bool {
+	exceptions := map[string]map[string]bool{
+		// lint -> dialect, parser: AnalyzeSource parses SQL text in the
+		// analyzer's dialect
+		// lint -> transpile: CV10 maps functions to the dialects of the
+		// portability profile
+		"pkg/lint": {"pkg/dialect": true, "pkg/parser": true, "pkg/transpile": true},
+		// lineage -> parser: lineage is extracted from parsed statements
+		"pkg/lineage": {"pkg/parser": true},
+	}
+	if allowed, exists := exceptions[getTopLevelComponent(from)]; exists {
+		return allowed[to]
+	}
+	return false
+}
+
 // =============================================================================
 // STAR TOPOLOGY TEST - pkg/* Components
 // =============================================================================
@@ -421,6 +439,10 @@ func checkPkgComponentImports(t *testing.T, pkg *packages.Package, pkgPath, base
 			continue
 		}
 
+		if isAllowedComponentException(pkgPath, depPath) {
+			continue
+		}
+
 		// Internal packages NOT OK
 		if strings.HasPrefix(depPath, "internal/") {
 			t.Errorf("BOUNDARY VIOLATION: Component '%s' imports internal '%s'.\n"+
@@ -8,6 +8,7 @@ import "github.com/leapstack-labs/leapsql/pkg/token"
 type ColumnRef struct {
 	Table  string // optional table/alias qualifier
 	Column string
+	Fields []string // struct field path after the column (t.s.a.b -> ["a", "b"])
 }
 
 func (*ColumnRef) exprNode() {}
@@ -168,20 +169,22 @@ type CaseExpr struct {
 	Operand Expr // CASE operand WHEN... (optional)
 	Whens   []WhenClause
 	Else    Expr
+	Span    token.Span // From CASE through END
 }
 
 func (*CaseExpr) exprNode() {}
 
 // Pos implements Node.
-func (c *CaseExpr) Pos() token.Position { return token.Position{} }
+func (c *CaseExpr) Pos() token.Position { return c.Span.Start }
 
 // End implements Node.
-func (c *CaseExpr) End() token.Position { return token.Position{} }
+func (c *CaseExpr) End() token.Position { return c.Span.End }
 
 // WhenClause represents a WHEN clause in CASE expression.
 type WhenClause struct {
-	Condition Expr
-	Result    Expr
+	Condition     Expr
+	Result        Expr
+	ConditionSpan token.Span // Source range of Condition
 }
 
 // CastExpr represents a CAST expression.
@@ -204,15 +207,16 @@ type InExpr struct {
 	Not    bool
 	Values []Expr      // IN (1, 2, 3)
 	Query  *SelectStmt // IN (SELECT ...)
+	Span   token.Span  // From the operand through the closing parenthesis
 }
 
 func (*InExpr) exprNode() {}
 
 // Pos implements Node.
-func (i *InExpr) Pos() token.Position { return token.Position{} }
+func (i *InExpr) Pos() token.Position { return i.Span.Start }
 
 // End implements Node.
-func (i *InExpr) End() token.Position { return token.Position{} }
+func (i *InExpr) End() token.Position { return i.Span.End }
 
 // BetweenExpr represents a BETWEEN expression.
 type BetweenExpr struct {
@@ -316,7 +320,8 @@ func (s *StarExpr) End() token.Position { return token.Position{} }
 
 // SubqueryExpr represents a subquery used as an expression (e.g., in EXISTS).
 type SubqueryExpr struct {
-	Select *SelectStmt
+	Select     *SelectStmt
+	Quantifier string // ALL, ANY or SOME in a quantified comparison, e.g. x > ALL (SELECT ...); empty otherwise
 }
 
 func (*SubqueryExpr) exprNode() {}
@@ -0,0 +1,448 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+// ---------- AST JSON Encoding ----------
+//
+// The JSON encoding of the AST lets tools outside Go consume parse results.
+// Each node is an object whose "type" is the name of its Go type, followed
+// by its non-zero fields in declaration order, named in snake_case:
+//
+//	{"type": "ColumnRef", "table": "o", "column": "amount"}
+//
+// Fields named Type are named after their own type instead, e.g. the
+// "literal_type" of a Literal and the "join_type" of a Join.
+//
+// Nodes embedding NodeInfo carry their "span" and comments. Spans are
+// {"start": pos, "end": pos} with pos {"line", "column", "offset"}; operators
+// are token names (e.g. "=", "AND"); literal types are "number", "string",
+// "bool" or "null"; comments are {"kind": "line"|"block", "text", "span"}.
+// Renaming a field or node type changes the encoding.
+
+var (
+	astTypes   = make(map[string]reflect.Type)
+	astTypesMu sync.RWMutex
+
+	spanType     = reflect.TypeOf(token.Span{})
+	positionType = reflect.TypeOf(token.Position{})
+	commentType  = reflect.TypeOf(token.Comment{})
+	tokenType    = reflect.TypeOf(token.TokenType(0))
+	literalType  = reflect.TypeOf(LiteralType(0))
+)
+
+var literalTypeNames = map[LiteralType]string{
+	LiteralNumber: "number",
+	LiteralString: "string",
+	LiteralBool:   "bool",
+	LiteralNull:   "null",
+}
+
+var commentKindNames = map[token.CommentKind]string{
+	token.LineComment:  "line",
+	token.BlockComment: "block",
+}
+
+func init() {
+	for _, v := range []any{
+		// Statements and clauses
+		SelectStmt{}, WithClause{}, CTE{}, SelectBody{}, SelectCore{}, FetchClause{},
+		WindowDef{}, SelectItem{}, FromClause{}, Join{}, OrderByItem{},
+		// Expressions
+		ColumnRef{}, Literal{}, BinaryExpr{}, UnaryExpr{}, FuncCall{}, WindowSpec{},
+		FrameSpec{}, FrameBound{}, CaseExpr{}, WhenClause{}, CastExpr{}, InExpr{},
+		BetweenExpr{}, IsNullExpr{}, IsBoolExpr{}, LikeExpr{}, ParenExpr{}, StarExpr{},
+		SubqueryExpr{}, ExistsExpr{}, MacroExpr{}, LambdaExpr{}, StructLiteral{},
+		StructField{}, ListLiteral{}, IndexExpr{},
+		// Table references
+		TableName{}, DerivedTable{}, LateralTable{}, TableFunction{}, MacroTable{},
+		PivotTable{}, PivotAggregate{}, PivotInValue{}, UnpivotTable{}, UnpivotInGroup{},
+		// Star modifiers
+		ExcludeModifier{}, ReplaceModifier{}, ReplaceItem{}, RenameModifier{}, RenameItem{},
+	} {
+		t := reflect.TypeOf(v)
+		astTypes[t.Name()] = t
+	}
+}
+
+// RecordNodeType registers the type of a dialect-specific node, such as one
+// stored in SelectCore.Extensions, so UnmarshalAST can decode it. The node
+// is encoded under the name of its type, which must not clash with another.
+func RecordNodeType(node Node) {
+	t := reflect.TypeOf(node)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	astTypesMu.Lock()
+	defer astTypesMu.Unlock()
+	astTypes[t.Name()] = t
+}
+
+func lookupNodeType(name string) (reflect.Type, bool) {
+	astTypesMu.RLock()
+	defer astTypesMu.RUnlock()
+	t, ok := astTypes[name]
+	return t, ok
+}
+
+// MarshalAST encodes node and its children as JSON.
+func MarshalAST(node Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeAST(&buf, reflect.ValueOf(node)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalAST decodes a node encoded by MarshalAST. The node types of the
+// data must be AST types of this package or recorded with RecordNodeType.
+func UnmarshalAST(data []byte) (Node, error) {
+	nodeIface := reflect.TypeOf((*Node)(nil)).Elem()
+	v := reflect.New(nodeIface).Elem()
+	if err := decodeAST(json.RawMessage(data), v); err != nil {
+		return nil, err
+	}
+	if v.IsNil() {
+		return nil, nil
+	}
+	return v.Interface().(Node), nil
+}
+
+// encodeAST writes the JSON encoding of v to buf.
+func encodeAST(buf *bytes.Buffer, v reflect.Value) error {
+	for !v.IsValid() || v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if !v.IsValid() || v.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Type() {
+	case positionType:
+		pos := v.Interface().(token.Position)
+		fmt.Fprintf(buf, `{"line":%d,"column":%d,"offset":%d}`, pos.Line, pos.Column, pos.Offset)
+		return nil
+	case spanType:
+		span := v.Interface().(token.Span)
+		buf.WriteString(`{"start":`)
+		_ = encodeAST(buf, reflect.ValueOf(span.Start))
+		buf.WriteString(`,"end":`)
+		_ = encodeAST(buf, reflect.ValueOf(span.End))
+		buf.WriteString("}")
+		return nil
+	case commentType:
+		c := v.Interface().(token.Comment)
+		kind, ok := commentKindNames[c.Kind]
+		if !ok {
+			return fmt.Errorf("unknown comment kind %d", c.Kind)
+		}
+		fmt.Fprintf(buf, `{"kind":%q,"text":`, kind)
+		writeJSONString(buf, c.Text)
+		buf.WriteString(`,"span":`)
+		_ = encodeAST(buf, reflect.ValueOf(c.Span))
+		buf.WriteString("}")
+		return nil
+	case tokenType:
+		writeJSONString(buf, v.Interface().(token.TokenType).String())
+		return nil
+	case literalType:
+		name, ok := literalTypeNames[v.Interface().(LiteralType)]
+		if !ok {
+			return fmt.Errorf("unknown literal type %d", v.Int())
+		}
+		writeJSONString(buf, name)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return encodeStruct(buf, v)
+	case reflect.Slice:
+		buf.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			if err := encodeAST(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("]")
+		return nil
+	case reflect.String:
+		writeJSONString(buf, v.String())
+		return nil
+	case reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64:
+		fmt.Fprintf(buf, "%v", v.Interface())
+		return nil
+	}
+	return fmt.Errorf("cannot encode %s in the AST", v.Type())
+}
+
+// encodeStruct writes a node as an object tagged with its type.
+func encodeStruct(buf *bytes.Buffer, v reflect.Value) error {
+	t := v.Type()
+	if _, ok := lookupNodeType(t.Name()); !ok {
+		return fmt.Errorf("cannot encode %s: not an AST node type", t)
+	}
+	buf.WriteString(`{"type":`)
+	writeJSONString(buf, t.Name())
+
+	var err error
+	eachASTField(v, func(name string, fv reflect.Value) {
+		if err != nil || fv.IsZero() {
+			return
+		}
+		buf.WriteString(",")
+		writeJSONString(buf, name)
+		buf.WriteString(":")
+		err = encodeAST(buf, fv)
+	})
+	if err != nil {
+		return err
+	}
+	buf.WriteString("}")
+	return nil
+}
+
+// eachASTField calls fn with the JSON name and value of each exported field
+// of a node, including those of embedded structs such as NodeInfo.
+func eachASTField(v reflect.Value, fn func(name string, fv reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			eachASTField(v.Field(i), fn)
+			continue
+		}
+		name := f.Name
+		if name == "Type" {
+			// Keep clear of the node's type tag
+			name = f.Type.Name()
+		}
+		fn(snakeCase(name), v.Field(i))
+	}
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
+
+// snakeCase converts a Go field name such as "ColumnsSpan" to "columns_span".
+func snakeCase(name string) string {
+	if name == "CTEs" {
+		return "ctes"
+	}
+	var sb strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// decodeAST decodes data into v, which must be settable.
+func decodeAST(data json.RawMessage, v reflect.Value) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Type() {
+	case positionType, spanType:
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		if v.Type() == spanType {
+			var span token.Span
+			if err := decodeAST(raw["start"], reflect.ValueOf(&span.Start).Elem()); err != nil {
+				return err
+			}
+			if err := decodeAST(raw["end"], reflect.ValueOf(&span.End).Elem()); err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(span))
+			return nil
+		}
+		var pos struct{ Line, Column, Offset int }
+		if err := json.Unmarshal(data, &pos); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(token.Position{Line: pos.Line, Column: pos.Column, Offset: pos.Offset}))
+		return nil
+	case commentType:
+		var raw struct {
+			Kind string
+			Text string
+			Span json.RawMessage
+		}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		c := token.Comment{Text: raw.Text}
+		kind, ok := lookupName(commentKindNames, raw.Kind)
+		if !ok {
+			return fmt.Errorf("unknown comment kind %q", raw.Kind)
+		}
+		c.Kind = kind
+		if raw.Span != nil {
+			if err := decodeAST(raw.Span, reflect.ValueOf(&c.Span).Elem()); err != nil {
+				return err
+			}
+		}
+		v.Set(reflect.ValueOf(c))
+		return nil
+	case tokenType:
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return err
+		}
+		tt, ok := token.LookupName(name)
+		if !ok {
+			return fmt.Errorf("unknown token %q", name)
+		}
+		v.Set(reflect.ValueOf(tt))
+		return nil
+	case literalType:
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return err
+		}
+		lt, ok := lookupName(literalTypeNames, name)
+		if !ok {
+			return fmt.Errorf("unknown literal type %q", name)
+		}
+		v.Set(reflect.ValueOf(lt))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		var tagged struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(data, &tagged); err != nil {
+			return err
+		}
+		t, ok := lookupNodeType(tagged.Type)
+		if !ok {
+			return fmt.Errorf("unknown node type %q", tagged.Type)
+		}
+		node := reflect.New(t)
+		if !node.Type().Implements(v.Type()) {
+			return fmt.Errorf("node type %s is not a %s", tagged.Type, v.Type().Name())
+		}
+		if err := decodeAST(data, node.Elem()); err != nil {
+			return err
+		}
+		v.Set(node)
+		return nil
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := decodeAST(data, elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	case reflect.Struct:
+		return decodeStruct(data, v)
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeAST(item, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64:
+		p := reflect.New(v.Type())
+		if err := json.Unmarshal(data, p.Interface()); err != nil {
+			return err
+		}
+		v.Set(p.Elem())
+		return nil
+	}
+	return fmt.Errorf("cannot decode %s in the AST", v.Type())
+}
+
+// decodeStruct decodes a node object into v, rejecting fields the node
+// type doesn't have.
+func decodeStruct(data json.RawMessage, v reflect.Value) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	t := v.Type()
+	if typ, ok := raw["type"]; ok {
+		var name string
+		if err := json.Unmarshal(typ, &name); err != nil {
+			return err
+		}
+		if name != t.Name() {
+			return fmt.Errorf("expected a %s node, got %q", t.Name(), name)
+		}
+		delete(raw, "type")
+	}
+
+	var err error
+	eachASTField(v, func(name string, fv reflect.Value) {
+		field, ok := raw[name]
+		if err != nil || !ok {
+			return
+		}
+		delete(raw, name)
+		if e := decodeAST(field, fv); e != nil {
+			err = fmt.Errorf("%s.%s: %w", t.Name(), name, e)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if len(raw) > 0 {
+		names := make([]string, 0, len(raw))
+		for name := range raw {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown field %q in %s node", names[0], t.Name())
+	}
+	return nil
+}
+
+// lookupName returns the key of names with the given value.
+func lookupName[K comparable](names map[K]string, name string) (K, bool) {
+	for k, n := range names {
+		if n == name {
+			return k, true
+		}
+	}
+	var zero K
+	return zero, false
+}
@@ -0,0 +1,125 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalAST(t *testing.T) {
+	expr := &core.BinaryExpr{
+		Left:  &core.ColumnRef{Table: "o", Column: "status"},
+		Op:    token.EQ,
+		Right: &core.Literal{Type: core.LiteralString, Value: "paid"},
+	}
+
+	data, err := core.MarshalAST(expr)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "BinaryExpr",
+		"left": {"type": "ColumnRef", "table": "o", "column": "status"},
+		"op": "=",
+		"right": {"type": "Literal", "literal_type": "string", "value": "paid"}
+	}`, string(data))
+}
+
+func TestMarshalAST_SpansAndComments(t *testing.T) {
+	span := token.Span{
+		Start: token.Position{Line: 1, Column: 15, Offset: 14},
+		End:   token.Position{Line: 1, Column: 26, Offset: 25},
+	}
+	table := &core.TableName{
+		NodeInfo: core.NodeInfo{
+			Span:             span,
+			TrailingComments: []*token.Comment{{Kind: token.LineComment, Text: "-- raw", Span: span}},
+		},
+		Schema: "staging",
+		Name:   "orders",
+	}
+
+	data, err := core.MarshalAST(table)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "TableName",
+		"span": {"start": {"line": 1, "column": 15, "offset": 14}, "end": {"line": 1, "column": 26, "offset": 25}},
+		"trailing_comments": [{
+			"kind": "line",
+			"text": "-- raw",
+			"span": {"start": {"line": 1, "column": 15, "offset": 14}, "end": {"line": 1, "column": 26, "offset": 25}}
+		}],
+		"schema": "staging",
+		"name": "orders"
+	}`, string(data))
+}
+
+func TestUnmarshalAST_RoundTrip(t *testing.T) {
+	nullsFirst := false
+	stmt := &core.SelectStmt{
+		With: &core.WithClause{CTEs: []*core.CTE{{
+			Name: "recent",
+			Select: &core.SelectStmt{Body: &core.SelectBody{Left: &core.SelectCore{
+				Columns: []core.SelectItem{{Star: true, Modifiers: []core.StarModifier{&core.ExcludeModifier{Columns: []string{"raw"}}}}},
+				From:    &core.FromClause{Source: &core.TableName{Name: "orders"}},
+			}}},
+		}}},
+		Body: &core.SelectBody{
+			Left: &core.SelectCore{
+				Distinct: true,
+				Columns: []core.SelectItem{
+					{Expr: &core.ColumnRef{Table: "r", Column: "id"}},
+					{Expr: &core.FuncCall{Name: "SUM", Args: []core.Expr{&core.ColumnRef{Column: "amount"}}}, Alias: "total"},
+					{Expr: &core.CaseExpr{Whens: []core.WhenClause{{
+						Condition: &core.IsNullExpr{Expr: &core.ColumnRef{Column: "status"}, Not: true},
+						Result:    &core.Literal{Type: core.LiteralBool, Value: "true"},
+					}}}},
+				},
+				From: &core.FromClause{
+					Source: &core.TableName{Name: "recent", Alias: "r"},
+					Joins: []*core.Join{{
+						Type:  "LEFT",
+						Right: &core.DerivedTable{Alias: "c", Select: &core.SelectStmt{Body: &core.SelectBody{Left: &core.SelectCore{}}}},
+						Using: []string{"id"},
+					}},
+				},
+				Where:   &core.InExpr{Expr: &core.ColumnRef{Column: "region"}, Values: []core.Expr{&core.Literal{Type: core.LiteralString, Value: "EU"}}},
+				GroupBy: []core.Expr{&core.ColumnRef{Table: "r", Column: "id"}},
+				OrderBy: []core.OrderByItem{{Expr: &core.ColumnRef{Column: "total"}, Desc: true, NullsFirst: &nullsFirst}},
+			},
+			Op:    core.SetOpUnionAll,
+			All:   true,
+			Right: &core.SelectBody{Left: &core.SelectCore{Columns: []core.SelectItem{{Expr: &core.Literal{Type: core.LiteralNull, Value: "NULL"}}}}},
+		},
+	}
+
+	data, err := core.MarshalAST(stmt)
+	require.NoError(t, err)
+
+	node, err := core.UnmarshalAST(data)
+	require.NoError(t, err)
+	assert.Equal(t, stmt, node)
+}
+
+func TestUnmarshalAST_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"unknown node type", `{"type": "DropTable"}`, `unknown node type "DropTable"`},
+		{"unknown field", `{"type": "ColumnRef", "column": "id", "collation": "C"}`, `unknown field "collation"`},
+		{"wrong interface", `{"type": "SelectStmt", "body": {"type": "SelectBody", "left": {"type": "SelectCore", "where": {"type": "TableName"}}}}`, "not a Expr"},
+		{"unknown operator", `{"type": "BinaryExpr", "op": "<=>>"}`, `unknown token "<=>>"`},
+		{"invalid JSON", `{"type": `, "unexpected end"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := core.UnmarshalAST([]byte(tt.data))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -60,6 +60,7 @@ type SelectCore struct {
 	NodeInfo
 	Distinct       bool
 	Columns        []SelectItem
+	ColumnsSpan    token.Span // Span of the select list
 	From           *FromClause
 	Where          Expr
 	GroupBy        []Expr
@@ -101,6 +102,7 @@ type SelectItem struct {
 	Expr      Expr           // Expression
 	Alias     string         // AS alias
 	Modifiers []StarModifier // DuckDB: EXCLUDE, REPLACE, RENAME modifiers
+	Span      token.Span     // Span of the item, alias included
 }
 
 // FromClause represents the FROM clause.
@@ -118,6 +120,8 @@ type Join struct {
 	Right     TableRef
 	Condition Expr     // ON clause (mutually exclusive with Using)
 	Using     []string // USING (col1, col2) columns
+
+	ConditionSpan token.Span // Source range of the ON clause, including the ON keyword
 }
 
 // JoinType represents the type of join.
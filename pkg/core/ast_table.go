@@ -51,6 +51,24 @@ func (l *LateralTable) Pos() token.Position { return l.NodeInfo.Pos() }
 // End implements Node.
 func (l *LateralTable) End() token.Position { return l.NodeInfo.End() }
 
+// TableFunction represents a table-valued function call in FROM clause.
+// SELECT * FROM generate_series(1, 10) AS t(i), UNNEST(items) AS item
+type TableFunction struct {
+	NodeInfo
+	Name          string   // Function name (uppercased, like FuncCall)
+	Args          []Expr   // Function arguments
+	Alias         string   // Optional table alias
+	ColumnAliases []string // Optional column aliases: AS t(a, b)
+}
+
+func (*TableFunction) tableRefNode() {}
+
+// Pos implements Node.
+func (f *TableFunction) Pos() token.Position { return f.NodeInfo.Pos() }
+
+// End implements Node.
+func (f *TableFunction) End() token.Position { return f.NodeInfo.End() }
+
 // MacroTable represents a macro used as a table reference (e.g., {{ ref('table') }}).
 type MacroTable struct {
 	NodeInfo
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Casing is a casing policy for keywords, function names or identifiers,
+// checked by lint and applied by the formatter.
+type Casing string
+
+// Casing policies. Snake case applies to identifiers only.
+const (
+	CasingAny   Casing = "any" // Any casing, words are kept as written
+	CasingUpper Casing = "upper"
+	CasingLower Casing = "lower"
+	CasingSnake Casing = "snake_case" // Lowercase words separated by underscores
+)
+
+var snakeCaseRe = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Apply returns word in the casing. Words are kept as written in any
+// casing, and in snake case unless they are all upper case: splitting
+// camelCase into words is a rename, not a recasing.
+func (c Casing) Apply(word string) string {
+	switch c {
+	case CasingUpper:
+		return strings.ToUpper(word)
+	case CasingLower:
+		return strings.ToLower(word)
+	case CasingSnake:
+		if strings.ToUpper(word) == word {
+			return strings.ToLower(word)
+		}
+	}
+	return word
+}
+
+// Matches reports whether word is in the casing.
+func (c Casing) Matches(word string) bool {
+	switch c {
+	case CasingUpper:
+		return strings.ToUpper(word) == word
+	case CasingLower:
+		return strings.ToLower(word) == word
+	case CasingSnake:
+		return snakeCaseRe.MatchString(word)
+	}
+	return true
+}
@@ -76,6 +76,7 @@ type DialectConfig struct {
 	Generators     []string // NOW, UUID, RANDOM, etc.
 	Windows        []string // ROW_NUMBER, LAG, LEAD, etc.
 	TableFunctions []string // read_csv, generate_series, etc.
+	FileFunctions  []string // Table functions reading the files named by their first argument: read_parquet, read_csv, etc.
 
 	// Keywords for autocomplete/highlighting
 	Keywords  []string
@@ -90,8 +91,9 @@ type DialectConfig struct {
 	SupportsOrderByAll bool // ORDER BY ALL
 
 	// Operator extensions
-	SupportsIlike        bool // ILIKE case-insensitive LIKE
-	SupportsCastOperator bool // :: cast operator
+	SupportsIlike         bool // ILIKE case-insensitive LIKE
+	SupportsCastOperator  bool // :: cast operator
+	SupportsJSONOperators bool // -> and ->> JSON extraction operators
 
 	// Join extensions
 	SupportsSemiAntiJoins bool // SEMI/ANTI join types
@@ -172,6 +174,7 @@ type Dialect struct {
 	Generators     map[string]struct{}
 	Windows        map[string]struct{}
 	TableFunctions map[string]struct{}
+	FileFunctions  map[string]struct{} // Table functions reading files (subset of TableFunctions)
 
 	// Documentation for LSP
 	Docs map[string]FunctionDoc
@@ -328,6 +331,13 @@ func (d *Dialect) IsTableFunction(name string) bool {
 	return d.FunctionLineageTypeOf(name) == LineageTable
 }
 
+// IsFileFunction returns true if the table function reads the files named by
+// its first argument, e.g. read_parquet('s3://bucket/orders/*.parquet').
+func (d *Dialect) IsFileFunction(name string) bool {
+	_, ok := d.FileFunctions[d.NormalizeName(name)]
+	return ok
+}
+
 // GetDoc returns documentation for a function.
 func (d *Dialect) GetDoc(name string) (FunctionDoc, bool) {
 	normalized := d.NormalizeName(name)
@@ -379,6 +389,10 @@ func (d *Dialect) Config() *DialectConfig {
 	for f := range d.TableFunctions {
 		tableFunctions = append(tableFunctions, f)
 	}
+	fileFunctions := make([]string, 0, len(d.FileFunctions))
+	for f := range d.FileFunctions {
+		fileFunctions = append(fileFunctions, f)
+	}
 	keywords := make([]string, 0, len(d.Keywords))
 	for kw := range d.Keywords {
 		keywords = append(keywords, kw)
@@ -392,6 +406,7 @@ func (d *Dialect) Config() *DialectConfig {
 		Generators:     generators,
 		Windows:        windows,
 		TableFunctions: tableFunctions,
+		FileFunctions:  fileFunctions,
 		Keywords:       keywords,
 		DataTypes:      d.DataTypes,
 	}
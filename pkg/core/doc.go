@@ -5,7 +5,7 @@
 //   - Service interfaces (Adapter, Store)
 //   - Configuration types (ProjectConfig, TargetConfig)
 //   - Lint types (Severity, RuleInfo)
-//   - Base AST interface (Node)
+//   - Base AST interface (Node) and its JSON encoding (MarshalAST)
 //
 // The Golden Rule: pkg/core imports ONLY pkg/token and stdlib.
 // All other packages depend on core, not the reverse.
@@ -0,0 +1,51 @@
+package core
+
+// FunctionSpelling is how a dialect spells a function. Args lists, for each
+// argument the dialect's function takes, the position of the matching
+// argument in the order shared by the equivalence's other spellings (nil for
+// that order).
+type FunctionSpelling struct {
+	Name string
+	Args []int
+}
+
+// FunctionEquivalence maps a dialect name to its spelling of a function.
+// Dialects missing from an equivalence have no equivalent function.
+type FunctionEquivalence map[string]FunctionSpelling
+
+// FunctionCompat describes how a dialect supports another dialect's function.
+type FunctionCompat int
+
+const (
+	// FunctionUnknown indicates nothing is known about the function in the
+	// target dialect, e.g. a user-defined function or a dialect without
+	// documented functions.
+	FunctionUnknown FunctionCompat = iota
+	// FunctionSame indicates the target dialect has the same function.
+	FunctionSame
+	// FunctionMapped indicates the target dialect spells the function
+	// differently or takes its arguments in a different order.
+	FunctionMapped
+	// FunctionMissing indicates the target dialect has no equivalent.
+	FunctionMissing
+)
+
+// String returns the string representation of a FunctionCompat.
+func (c FunctionCompat) String() string {
+	switch c {
+	case FunctionSame:
+		return "same"
+	case FunctionMapped:
+		return "mapped"
+	case FunctionMissing:
+		return "missing"
+	default:
+		return "unknown"
+	}
+}
+
+// FunctionMapping is a dialect's equivalent of another dialect's function.
+type FunctionMapping struct {
+	Name string // Lowercase name of the function in the target dialect
+	Args []int  // For each argument, the index of the source call's argument (nil for the same order)
+}
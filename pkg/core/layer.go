@@ -0,0 +1,136 @@
+package core
+
+import (
+	"path"
+	"strings"
+)
+
+// SourceLayer is the name DependsOn uses for raw tables that are not models.
+const SourceLayer = "source"
+
+// DefaultLayers returns the staging, intermediate and marts layers used when
+// a project declares none.
+func DefaultLayers() []LayerConfig {
+	return []LayerConfig{
+		{
+			Name:      string(ModelTypeStaging),
+			Paths:     []string{"staging"},
+			Prefixes:  []string{"stg_"},
+			DependsOn: []string{SourceLayer},
+		},
+		{
+			Name:      string(ModelTypeIntermediate),
+			Paths:     []string{"intermediate"},
+			Prefixes:  []string{"int_"},
+			DependsOn: []string{string(ModelTypeStaging), string(ModelTypeIntermediate), string(ModelTypeMarts)},
+		},
+		{
+			Name:      string(ModelTypeMarts),
+			Paths:     []string{"marts"},
+			Prefixes:  []string{"fct_", "dim_"},
+			DependsOn: []string{string(ModelTypeStaging), string(ModelTypeIntermediate), string(ModelTypeMarts)},
+		},
+	}
+}
+
+// InferLayer determines the layer of a model:
+//  1. The `type` frontmatter field, if it names a layer
+//  2. The first layer with a path pattern matching the model's directory
+//  3. The first layer with a prefix of the model's name
+//
+// Models matching no layer are ModelTypeOther.
+func InferLayer(layers []LayerConfig, filePath, name string, meta map[string]any) ModelType {
+	if typeVal, ok := meta["type"].(string); ok {
+		for _, l := range layers {
+			if strings.EqualFold(l.Name, typeVal) {
+				return ModelType(l.Name)
+			}
+		}
+	}
+	for _, l := range layers {
+		if l.MatchesPath(filePath) {
+			return ModelType(l.Name)
+		}
+	}
+	for _, l := range layers {
+		if l.MatchesName(name) {
+			return ModelType(l.Name)
+		}
+	}
+	return ModelTypeOther
+}
+
+// FindLayer returns the layer with the given name.
+func FindLayer(layers []LayerConfig, name ModelType) (LayerConfig, bool) {
+	for _, l := range layers {
+		if l.Name == string(name) {
+			return l, true
+		}
+	}
+	return LayerConfig{}, false
+}
+
+// MatchesPath reports whether the directory of filePath contains one of the
+// layer's path patterns. A pattern is matched, case-insensitively, against
+// consecutive directory names, and each of its segments may use path.Match
+// wildcards: "marts/*" matches models/marts/finance/fct_revenue.sql.
+func (l LayerConfig) MatchesPath(filePath string) bool {
+	dir := path.Dir(strings.ReplaceAll(strings.ToLower(filePath), "\\", "/"))
+	dirs := strings.Split(strings.Trim(dir, "/"), "/")
+	for _, pattern := range l.Paths {
+		segments := strings.Split(strings.Trim(strings.ToLower(pattern), "/"), "/")
+		for i := 0; i+len(segments) <= len(dirs); i++ {
+			if matchSegments(segments, dirs[i:i+len(segments)]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchesName reports whether name starts with one of the layer's prefixes,
+// ignoring case.
+func (l LayerConfig) MatchesName(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range l.Prefixes {
+		if strings.HasPrefix(name, strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether the layer's models may reference the named layer,
+// or raw tables for SourceLayer.
+func (l LayerConfig) Allows(layer string) bool {
+	if len(l.DependsOn) == 0 {
+		return true
+	}
+	for _, d := range l.DependsOn {
+		if strings.EqualFold(d, layer) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadsSources reports whether the layer's DependsOn lists SourceLayer: its
+// models are the entry point for raw tables.
+func (l LayerConfig) ReadsSources() bool {
+	for _, d := range l.DependsOn {
+		if strings.EqualFold(d, SourceLayer) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments reports whether each directory name matches its pattern segment.
+func matchSegments(patterns, dirs []string) bool {
+	for i, p := range patterns {
+		if ok, _ := path.Match(p, dirs[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -5,4 +5,5 @@ const (
 	MaterializationTable       = "table"
 	MaterializationView        = "view"
 	MaterializationIncremental = "incremental"
+	MaterializationExternal    = "external"
 )
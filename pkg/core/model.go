@@ -1,5 +1,12 @@
 package core
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // ModelType represents the semantic type of a model.
 type ModelType string
 
@@ -11,6 +18,81 @@ const (
 	ModelTypeOther        ModelType = "other"
 )
 
+// Access controls which models may reference a model.
+type Access string
+
+// Access level constants.
+const (
+	// AccessPublic models may be referenced from any model, including other workspace projects.
+	AccessPublic Access = "public"
+	// AccessProtected models may be referenced from any model in the same project (the default).
+	AccessProtected Access = "protected"
+	// AccessPrivate models may only be referenced from models in the same directory group.
+	AccessPrivate Access = "private"
+)
+
+// IsValid reports whether a is a known access level. Empty is valid and means protected.
+func (a Access) IsValid() bool {
+	switch a {
+	case "", AccessPublic, AccessProtected, AccessPrivate:
+		return true
+	}
+	return false
+}
+
+// TransactionPolicy controls whether a model's build runs in a database
+// transaction.
+type TransactionPolicy string
+
+// Transaction policy constants.
+const (
+	// TransactionAuto runs a build in a transaction when it has pre or post
+	// statements, so they are rolled back with a failed build.
+	TransactionAuto TransactionPolicy = "auto"
+	// TransactionAlways runs every build in a transaction, including the
+	// delete and insert of an incremental merge.
+	TransactionAlways TransactionPolicy = "always"
+	// TransactionNever runs every statement of a build on its own.
+	TransactionNever TransactionPolicy = "never"
+)
+
+// IsValid reports whether p is a known transaction policy. Empty is valid and
+// means the target's or adapter's default.
+func (p TransactionPolicy) IsValid() bool {
+	switch p {
+	case "", TransactionAuto, TransactionAlways, TransactionNever:
+		return true
+	}
+	return false
+}
+
+// MaskingMode controls how a target masks the columns models tag as PII.
+type MaskingMode string
+
+// Masking mode constants.
+const (
+	// MaskingNone builds PII columns unchanged.
+	MaskingNone MaskingMode = "none"
+	// MaskingHash replaces PII values with their MD5 hash, so they can still
+	// be joined and counted but not read.
+	MaskingHash MaskingMode = "hash"
+	// MaskingNull replaces PII values with NULL.
+	MaskingNull MaskingMode = "null"
+	// MaskingPolicy builds PII columns unchanged and attaches the target's
+	// warehouse masking policy to them.
+	MaskingPolicy MaskingMode = "policy"
+)
+
+// IsValid reports whether m is a known masking mode. Empty is valid and means
+// none.
+func (m MaskingMode) IsValid() bool {
+	switch m {
+	case "", MaskingNone, MaskingHash, MaskingNull, MaskingPolicy:
+		return true
+	}
+	return false
+}
+
 // TransformType describes how source columns are transformed.
 type TransformType string
 
@@ -19,14 +101,196 @@ const (
 	TransformDirect TransformType = ""
 	// TransformExpression means the column is derived from an expression.
 	TransformExpression TransformType = "EXPR"
+	// TransformGenerated means the column is added by LeapSQL, not the model's SQL.
+	TransformGenerated TransformType = "GENERATED"
 )
 
+// Audit columns appended to the tables of models with audit_columns enabled.
+const (
+	// AuditColumnLoadedAt is the time the table was built.
+	AuditColumnLoadedAt = "_leapsql_loaded_at"
+	// AuditColumnRunID is the ID of the run that built the table.
+	AuditColumnRunID = "_leapsql_run_id"
+	// AuditColumnModelHash is the content hash of the model that built the table.
+	AuditColumnModelHash = "_leapsql_model_hash"
+)
+
+// AuditColumnNames lists the audit columns in the order they are appended.
+var AuditColumnNames = []string{AuditColumnLoadedAt, AuditColumnRunID, AuditColumnModelHash}
+
+// ModelIDSeparator separates the project from the model path in workspace model IDs.
+const ModelIDSeparator = "/"
+
+// ModelID returns the namespaced ID of a model in a workspace project.
+// e.g., ModelID("marketing", "marts.campaigns") -> "marketing/marts.campaigns"
+// Models outside a workspace have no project and their ID is the path itself.
+func ModelID(project, path string) string {
+	if project == "" {
+		return path
+	}
+	return project + ModelIDSeparator + path
+}
+
+// SplitModelID splits a model ID into its project and model path.
+// The project is empty for models outside a workspace.
+func SplitModelID(id string) (project, path string) {
+	if i := strings.Index(id, ModelIDSeparator); i >= 0 {
+		return id[:i], id[i+len(ModelIDSeparator):]
+	}
+	return "", id
+}
+
+// ModelVersionSeparator separates a model name from a pinned version in refs.
+const ModelVersionSeparator = "@v"
+
+// VersionedName returns the name that references one version of a model.
+// e.g., VersionedName("dim_users", 2) -> "dim_users@v2"
+// Version 0 means the latest version and returns the name unchanged.
+func VersionedName(name string, version int) string {
+	if version <= 0 {
+		return name
+	}
+	return name + ModelVersionSeparator + strconv.Itoa(version)
+}
+
+// SplitVersionedName splits a versioned name into the model name and version.
+// The version is 0 if the name is not pinned to a version.
+func SplitVersionedName(name string) (string, int) {
+	i := strings.LastIndex(name, ModelVersionSeparator)
+	if i < 0 {
+		return name, 0
+	}
+	version, err := strconv.Atoi(name[i+len(ModelVersionSeparator):])
+	if err != nil || version <= 0 {
+		return name, 0
+	}
+	return name[:i], version
+}
+
+// ExternalConfig configures an external model: a model whose table is built
+// by a command, such as a Python script, instead of SQL.
+type ExternalConfig struct {
+	// Command is the program to run and its arguments. It runs in the model
+	// file's directory.
+	Command []string
+	// Env holds additional environment variables for the command
+	Env map[string]string
+	// Inputs are the models and tables the command reads. They become the
+	// model's dependencies and are exported to CSV files for the command.
+	Inputs []string
+	// Columns are the columns of the table the command produces
+	Columns []string
+}
+
+// PartitionGrain is the time unit a model's table is partitioned by.
+type PartitionGrain string
+
+// Partition grain constants.
+const (
+	PartitionGrainHour  PartitionGrain = "hour"
+	PartitionGrainDay   PartitionGrain = "day"
+	PartitionGrainMonth PartitionGrain = "month"
+	PartitionGrainYear  PartitionGrain = "year"
+)
+
+// PartitionSpec declares how a model's table is partitioned: by a date or
+// timestamp column, truncated to a grain.
+type PartitionSpec struct {
+	// Column is the date or timestamp column rows are partitioned by
+	Column string
+	// Grain is the time unit of a partition
+	Grain PartitionGrain
+}
+
+// Expr returns the SQL expression of the partition a row is in, such as
+// date_trunc('day', order_date). date_trunc takes the same arguments in the
+// dialects of the supported adapters.
+func (p PartitionSpec) Expr() string {
+	return "date_trunc('" + string(p.Grain) + "', " + p.Column + ")"
+}
+
+// String returns the spec as it reads in docs, e.g. "order_date (day)".
+func (p PartitionSpec) String() string {
+	return p.Column + " (" + string(p.Grain) + ")"
+}
+
+// RetentionUnit is the time unit of a retention period.
+type RetentionUnit string
+
+// Retention unit constants, with the suffixes they are written with.
+const (
+	RetentionHour  RetentionUnit = "h"
+	RetentionDay   RetentionUnit = "d"
+	RetentionWeek  RetentionUnit = "w"
+	RetentionMonth RetentionUnit = "mo"
+	RetentionYear  RetentionUnit = "y"
+)
+
+// RetentionPeriod is how long the rows of a model are kept, written as a
+// number of units such as 90d.
+type RetentionPeriod struct {
+	// Count is the number of units
+	Count int
+	// Unit is the time unit
+	Unit RetentionUnit
+}
+
+// ParseRetentionPeriod parses a retention period such as 24h, 90d, 12w, 6mo
+// or 2y.
+func ParseRetentionPeriod(s string) (RetentionPeriod, error) {
+	s = strings.TrimSpace(s)
+	digits := len(s) - len(strings.TrimLeft(s, "0123456789"))
+	count, err := strconv.Atoi(s[:digits])
+	unit := RetentionUnit(s[digits:])
+	switch {
+	case err != nil || count <= 0:
+		return RetentionPeriod{}, fmt.Errorf("invalid retention period %q: must be a positive number of units, e.g. 90d", s)
+	case unit != RetentionHour && unit != RetentionDay && unit != RetentionWeek && unit != RetentionMonth && unit != RetentionYear:
+		return RetentionPeriod{}, fmt.Errorf("invalid retention period %q: unit must be one of h, d, w, mo, y", s)
+	}
+	return RetentionPeriod{Count: count, Unit: unit}, nil
+}
+
+// Cutoff returns the time rows older than the period are deleted before,
+// counting back from now.
+func (p RetentionPeriod) Cutoff(now time.Time) time.Time {
+	switch p.Unit {
+	case RetentionHour:
+		return now.Add(-time.Duration(p.Count) * time.Hour)
+	case RetentionWeek:
+		return now.AddDate(0, 0, -7*p.Count)
+	case RetentionMonth:
+		return now.AddDate(0, -p.Count, 0)
+	case RetentionYear:
+		return now.AddDate(-p.Count, 0, 0)
+	default:
+		return now.AddDate(0, 0, -p.Count)
+	}
+}
+
+// String returns the period as it is written, e.g. "90d".
+func (p RetentionPeriod) String() string {
+	return strconv.Itoa(p.Count) + string(p.Unit)
+}
+
+// RetentionPolicy deletes the rows of a model's table that are older than a
+// period after each build.
+type RetentionPolicy struct {
+	// Column is the date or timestamp column the age of rows is measured by
+	Column string
+	// Keep is how long rows are kept
+	Keep RetentionPeriod
+}
+
 // Model represents a SQL model (transformation unit).
 // This contains the core identity fields only.
 // Persistence-specific fields (ID, ContentHash, timestamps) belong in state.PersistedModel.
 type Model struct {
-	// Path is the model path (e.g., "staging.customers")
+	// Path is the model path (e.g., "staging.customers").
+	// In a workspace it is namespaced by project (e.g., "core/staging.customers").
 	Path string
+	// Project is the workspace project the model belongs to (empty outside a workspace)
+	Project string
 	// Name is the model name (filename without extension)
 	Name string
 	// FilePath is the absolute path to the SQL file
@@ -37,10 +301,54 @@ type Model struct {
 	UniqueKey string
 	// Owner is the team/person responsible for this model
 	Owner string
+	// Group is the name of the group that owns this model (see GroupConfig)
+	Group string
 	// Schema is the database schema for this model
 	Schema string
+	// Database is the attached database (catalog) the model is built in;
+	// its table is referenced by a three-part name (empty for the target database)
+	Database string
 	// Description is a human-readable description of the model
 	Description string
+	// Version is the model version; versions of a model share its Name (0 if unversioned)
+	Version int
+	// Deprecated marks the model as deprecated (nil if not deprecated)
+	Deprecated *Deprecation
+	// Access controls which models may reference this model (empty means protected)
+	Access Access
+	// Disabled excludes the model from the DAG (frontmatter enabled: false)
+	Disabled bool
+	// AuditColumns appends the audit columns (AuditColumnNames) to the model's table
+	AuditColumns bool
+	// FullRefresh overrides run --full-refresh for the model (frontmatter
+	// full_refresh): false never rebuilds it from scratch, true always does.
+	// Nil follows the flag.
+	FullRefresh *bool
+	// Transaction overrides whether the model's build runs in a transaction
+	// (frontmatter transaction). Empty follows the target's transaction
+	// setting.
+	Transaction TransactionPolicy
+	// Sample overrides the number of rows the model is limited to in sampled
+	// runs (frontmatter sample); 0 builds it in full. Nil follows the run.
+	Sample *int
+	// ResourceClass is the target resource class limiting how many of the
+	// model's builds run at once (frontmatter resource_class). Empty is
+	// limited only by the target's max_concurrent_queries.
+	ResourceClass string
+	// PII lists the model's output columns that hold personal data
+	// (frontmatter pii); targets mask them according to their masking config
+	PII []string
+	// Where is the row filter applied to the model's query (frontmatter
+	// where), usually set per environment to build development targets on
+	// a subset of the data. Empty builds every row.
+	Where string
+	// PartitionBy partitions the model's table (frontmatter partition_by).
+	// Incremental runs rebuild the latest partition onwards and overwrite
+	// the partitions they build. Nil if the table is not partitioned.
+	PartitionBy *PartitionSpec
+	// Retention deletes rows older than a period from the model's table
+	// after each build (frontmatter retention). Nil keeps every row.
+	Retention *RetentionPolicy
 	// Tags are metadata labels for filtering/organizing models
 	Tags []string
 	// Meta contains custom extension fields
@@ -51,12 +359,27 @@ type Model struct {
 	Imports []string
 	// Sources are all table names referenced in the SQL
 	Sources []string
+	// Files are the sources that are files read by table functions, e.g.
+	// read_parquet('s3://bucket/orders/*.parquet')
+	Files []string
+	// Refs are model IDs referenced with ref(), e.g. "dim_users", "core/dim_users@v2"
+	Refs []string
 	// Columns contains column-level lineage information
 	Columns []ColumnInfo
 	// UsesSelectStar is true if model uses SELECT * or t.*
 	UsesSelectStar bool
-	// SQL is the raw SQL content (excluding frontmatter)
+	// SQL is the raw SQL content (excluding frontmatter). For a model file with
+	// several statements, it is the model's query only.
 	SQL string
+	// PreStatements are the raw statements before the model's query, run
+	// before building the model (e.g., SET or CREATE TEMP FUNCTION)
+	PreStatements []string
+	// PostStatements are the raw statements after the model's query, run
+	// after building the model
+	PostStatements []string
+	// External configures the command that builds an external model
+	// (materialized: external); nil for SQL models
+	External *ExternalConfig
 	// RawContent is the full file content including frontmatter
 	RawContent string
 	// Conditionals are #if directives for environment-specific SQL
@@ -65,11 +388,44 @@ type Model struct {
 	HasFrontmatter bool
 }
 
+// Deprecation describes why and since when a model is deprecated.
+type Deprecation struct {
+	// Since is the date or release the model was deprecated in
+	Since string
+	// Replacement is the model to use instead (optional)
+	Replacement string
+}
+
 // TestConfig represents test configuration for a model.
 type TestConfig struct {
 	Unique         []string
 	NotNull        []string
 	AcceptedValues *AcceptedValuesConfig
+	RowCountDrift  *DriftConfig
+	NullRateDrift  *DriftConfig
+}
+
+// DefaultDriftWindow is the number of previous runs anomaly tests compare a
+// model's output with by default.
+const DefaultDriftWindow = 10
+
+// DriftConfig configures an anomaly test comparing a metric of a model's
+// output with its values in previous runs. The latest run is anomalous when
+// the metric changed by more than MaxChange since the previous run, or lies
+// more than ZScore standard deviations from its mean over the previous runs.
+type DriftConfig struct {
+	// Columns whose null rates are checked (null rate tests; empty for all columns)
+	Columns []string
+	// MaxChange is the largest change since the previous run: a fraction of
+	// the row count, or a difference in null rate (0 disables the check)
+	MaxChange float64
+	// ZScore is the largest deviation from the mean of the previous runs, in
+	// standard deviations (0 disables the check)
+	ZScore float64
+	// Window is the number of previous runs compared with (0 for DefaultDriftWindow)
+	Window int
+	// Fail fails the model on anomalies instead of reporting them as warnings
+	Fail bool
 }
 
 // AcceptedValuesConfig represents accepted values test configuration.
@@ -79,9 +435,12 @@ type AcceptedValuesConfig struct {
 }
 
 // SourceRef represents a source column reference in lineage.
+// Field is the nested path inside Column for struct field access (s.a.b)
+// and JSON extraction (payload->'a'->>'b'), e.g. "a.b". Empty for whole-column references.
 type SourceRef struct {
 	Table  string
 	Column string
+	Field  string
 }
 
 // ColumnInfo represents column lineage information.
@@ -80,3 +80,9 @@ type OperatorDef struct {
 	Precedence int
 	Handler    any // spi.InfixHandler - cast at call site
 }
+
+// SyntaxError is a syntax error found while parsing SQL.
+type SyntaxError struct {
+	Pos     token.Position
+	Message string
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ModelPlan is the query plan of a model's build, captured with EXPLAIN after
+// the model was built.
+type ModelPlan struct {
+	RunID      string
+	ModelPath  string
+	Plan       string // EXPLAIN output, one line per row with columns separated by tabs
+	CapturedAt time.Time
+}
+
+// PlanOperator is a kind of query plan operator whose count is compared
+// between runs to detect plan regressions.
+type PlanOperator struct {
+	Name     string   // e.g. "full scans"
+	Patterns []string // Lowercase operator names as they appear in EXPLAIN output
+}
+
+// PlanOperators lists the operators tracked for plan regressions, spelled as
+// DuckDB, PostgreSQL, Snowflake and Databricks print them.
+var PlanOperators = []PlanOperator{
+	{Name: "full scans", Patterns: []string{"seq_scan", "seq scan", "tablescan", "filescan"}},
+	{Name: "broadcast joins", Patterns: []string{"broadcasthashjoin", "broadcastnestedloopjoin"}},
+	{Name: "nested loop joins", Patterns: []string{"nested_loop_join", "nested loop", "cross_product", "cartesianproduct"}},
+}
+
+// PlanChange is an increase in the number of operators of one kind between
+// two plans of the same model.
+type PlanChange struct {
+	Operator string // Name of the PlanOperator
+	Before   int
+	After    int
+}
+
+// String returns a description of the change, e.g. "full scans: 1 -> 2".
+func (c PlanChange) String() string {
+	return fmt.Sprintf("%s: %d -> %d", c.Operator, c.Before, c.After)
+}
+
+// ComparePlans returns the operators the current plan of a model has more of
+// than its previous plan: new full scans, broadcast joins or nested loop
+// joins. Plans are compared as text, so it returns nil if either is empty.
+func ComparePlans(previous, current string) []PlanChange {
+	if previous == "" || current == "" {
+		return nil
+	}
+	previous, current = strings.ToLower(previous), strings.ToLower(current)
+
+	var changes []PlanChange
+	for _, op := range PlanOperators {
+		before, after := countPatterns(previous, op.Patterns), countPatterns(current, op.Patterns)
+		if after > before {
+			changes = append(changes, PlanChange{Operator: op.Name, Before: before, After: after})
+		}
+	}
+	return changes
+}
+
+// countPatterns counts the occurrences of patterns in a lowercase plan.
+func countPatterns(plan string, patterns []string) int {
+	n := 0
+	for _, p := range patterns {
+		n += strings.Count(plan, p)
+	}
+	return n
+}
@@ -1,12 +1,97 @@
 package core
 
+import "time"
+
 // ProjectConfig holds project-level configuration.
 type ProjectConfig struct {
-	ModelsDir string        `koanf:"models_dir"`
-	SeedsDir  string        `koanf:"seeds_dir"`
-	MacrosDir string        `koanf:"macros_dir"`
-	Target    *TargetConfig `koanf:"target"`
-	Lint      *LintConfig   `koanf:"lint"`
+	ModelsDir string          `koanf:"models_dir"`
+	SeedsDir  string          `koanf:"seeds_dir"`
+	MacrosDir string          `koanf:"macros_dir"`
+	Dialect   string          `koanf:"dialect"` // SQL dialect models are written in (default: the target's)
+	Target    *TargetConfig   `koanf:"target"`
+	Lint      *LintConfig     `koanf:"lint"`
+	Groups    []GroupConfig   `koanf:"groups"`
+	Layers    []LayerConfig   `koanf:"layers"`
+	Secrets   *SecretsConfig  `koanf:"secrets"`
+	Metadata  *MetadataConfig `koanf:"metadata"`
+}
+
+// SecretsConfig configures the external secret manager that resolves
+// secret('name') references in the target config.
+type SecretsConfig struct {
+	// Command fetches a secret, e.g. ["vault", "kv", "get", "-field=value", "secret/{name}"].
+	// "{name}" in an argument is replaced by the secret name, which is also
+	// passed in the LEAPSQL_SECRET_NAME environment variable. The secret is
+	// the command's standard output, without the trailing newline.
+	Command []string `koanf:"command"`
+}
+
+// MetadataConfig configures the data catalogs model metadata is pushed to
+// with `leapsql metadata push`.
+type MetadataConfig struct {
+	// Sinks are the catalogs to push to
+	Sinks []MetadataSinkConfig `koanf:"sinks"`
+	// PushAfterRun pushes the metadata of the models built by each
+	// successful run to every sink
+	PushAfterRun bool `koanf:"push_after_run"`
+}
+
+// MetadataSinkConfig configures one data catalog that model metadata is
+// pushed to. URL and Token accept env_var(), secret() and ${VAR} references.
+type MetadataSinkConfig struct {
+	Type     string `koanf:"type"`     // Catalog type, e.g. datahub
+	URL      string `koanf:"url"`      // Catalog endpoint, e.g. the DataHub GMS URL
+	Token    string `koanf:"token"`    // Access token (optional)
+	Platform string `koanf:"platform"` // Data platform of the datasets (default: the target type)
+	Env      string `koanf:"env"`      // Catalog environment of the datasets (default: PROD)
+}
+
+// GroupConfig declares a group of models owned by one team.
+// Models join a group with the `group` frontmatter field.
+type GroupConfig struct {
+	Name         string        `koanf:"name"`          // Referenced from model frontmatter
+	Owner        string        `koanf:"owner"`         // Team or person responsible for the group's models
+	SlackChannel string        `koanf:"slack_channel"` // Channel to notify about the group's models (optional)
+	MaxRuntime   time.Duration `koanf:"max_runtime"`   // Time the group's models may spend building in a run (0 for no limit)
+}
+
+// LayerConfig declares a model layer, such as staging or marts. Models are
+// assigned the first layer whose paths or prefixes match them (see InferLayer),
+// or a layer named by the `type` frontmatter field.
+type LayerConfig struct {
+	Name      string   `koanf:"name"`       // Layer name, used as the model type
+	Paths     []string `koanf:"paths"`      // Directory patterns, e.g. "staging" or "marts/*"
+	Prefixes  []string `koanf:"prefixes"`   // Model name prefixes, e.g. "stg_"
+	DependsOn []string `koanf:"depends_on"` // Layers its models may reference, "source" for raw tables (empty allows any)
+}
+
+// FileSourceConfig sets how fresh a file source must be: a file, URL or glob
+// that models read with a table function such as read_parquet. Freshness is
+// the time since the most recently modified matching file.
+type FileSourceConfig struct {
+	Path       string        `koanf:"path"`        // As written in the models, e.g. "s3://bucket/orders/*.parquet"
+	WarnAfter  time.Duration `koanf:"warn_after"`  // Age after which the check warns (0 for none)
+	ErrorAfter time.Duration `koanf:"error_after"` // Age after which the check fails (0 for none)
+}
+
+// TableSourceConfig sets how to tell when a raw table, loaded outside
+// LeapSQL, last changed: the latest value of its loaded_at column.
+type TableSourceConfig struct {
+	Table    string `koanf:"table"`     // As models reference it, e.g. "raw.orders"
+	LoadedAt string `koanf:"loaded_at"` // Timestamp column set when rows are loaded
+}
+
+// WorkspaceConfig lists the LeapSQL projects that make up a workspace (monorepo).
+// Models from every project share one DAG and state store, and are identified
+// by namespaced model IDs (see ModelID).
+type WorkspaceConfig struct {
+	Projects []WorkspaceProject `koanf:"projects"`
+}
+
+// WorkspaceProject is a single project within a workspace.
+type WorkspaceProject struct {
+	Name string `koanf:"name"` // Namespace for the project's model IDs
+	Path string `koanf:"path"` // Project directory, relative to the workspace file
 }
 
 // TargetConfig holds database target configuration.
@@ -30,6 +115,22 @@ type TargetConfig struct {
 	Warehouse string `koanf:"warehouse"`
 	Role      string `koanf:"role"`
 
+	// Transaction is the default transaction policy of model builds (empty
+	// for the adapter's default)
+	Transaction TransactionPolicy `koanf:"transaction"`
+
+	// Masking controls how the columns models tag as PII are masked when
+	// built on this target (nil builds them unchanged)
+	Masking *MaskingConfig `koanf:"masking"`
+
+	// MaxConcurrentQueries caps the model builds running against this target
+	// at once (0 for no limit)
+	MaxConcurrentQueries int `koanf:"max_concurrent_queries"`
+
+	// ResourceClasses caps the builds running at once of the models of each
+	// resource class, keyed by class name (frontmatter resource_class)
+	ResourceClasses map[string]int `koanf:"resource_classes"`
+
 	// Additional driver-specific options
 	Options map[string]string `koanf:"options"`
 
@@ -37,21 +138,59 @@ type TargetConfig struct {
 	Params map[string]any `koanf:"params"`
 }
 
+// MaskingConfig configures how a target masks PII columns.
+type MaskingConfig struct {
+	// Mode is none (the default), hash, null or policy
+	Mode MaskingMode `koanf:"mode"`
+	// Policy is the warehouse masking policy attached to PII columns in
+	// policy mode (e.g., governance.mask_pii)
+	Policy string `koanf:"policy"`
+}
+
 // LintConfig holds lint rule configuration.
 type LintConfig struct {
+	// Profile names a bundled preset (strict, dbt-style, sqlfluff-default,
+	// minimal) the rest of the lint config is applied on top of
+	Profile string `koanf:"profile"`
+
 	// Disabled contains rule IDs to disable
 	Disabled []string `koanf:"disabled"`
 
+	// Enabled contains rule IDs to enable that the profile disables
+	Enabled []string `koanf:"enabled"`
+
 	// Severity maps rule ID to severity override (error, warning, info, hint)
 	Severity map[string]string `koanf:"severity"`
 
 	// Rules contains rule-specific options
 	Rules map[string]RuleOptions `koanf:"rules"`
 
+	// Overrides change rule severities for selected models, applied in order
+	Overrides []LintOverride `koanf:"overrides"`
+
+	// Dialects lists the dialects models must also be valid in besides the
+	// one they are written in, e.g. the production warehouse's when models
+	// are developed on DuckDB
+	Dialects []string `koanf:"dialects"`
+
 	// ProjectHealth holds project-level linting configuration
 	ProjectHealth *ProjectHealthConfig `koanf:"project_health"`
 }
 
+// LintOverride changes the severity of SQL and project lint rules for the
+// models it selects, e.g. to treat warnings as errors on critical models.
+type LintOverride struct {
+	// Models selects models by tag:<tag>, owner:<owner>, group:<group> or
+	// path:<dir>; a model matching any selector is selected
+	Models []string `koanf:"models"`
+
+	// WarningsAsErrors raises the warnings of all rules to errors
+	WarningsAsErrors bool `koanf:"warnings_as_errors"`
+
+	// Severity maps rule ID to severity override (error, warning, info, hint)
+	Severity map[string]string `koanf:"severity"`
+}
+
 // RuleOptions holds rule-specific configuration options.
 type RuleOptions map[string]any
 
@@ -63,6 +202,9 @@ type ProjectHealthConfig struct {
 	// Thresholds for various rules
 	Thresholds ProjectHealthThresholds `koanf:"thresholds"`
 
+	// PIIApproved lists the models allowed to expose PII columns (PL06)
+	PIIApproved []string `koanf:"pii_approved"`
+
 	// Rules maps rule IDs to severity overrides (off, info, warning, error)
 	Rules map[string]string `koanf:"rules"`
 }
@@ -61,14 +61,15 @@ func ParseSeverity(s string) (Severity, bool) {
 // RuleInfo provides metadata about a lint rule for documentation/tooling.
 // This is a DTO (Data Transfer Object) - it carries data without behavior.
 type RuleInfo struct {
-	ID              string   `json:"id"`
-	Name            string   `json:"name"`
-	Group           string   `json:"group"`
-	Description     string   `json:"description"`
-	DefaultSeverity Severity `json:"default_severity"`
-	ConfigKeys      []string `json:"config_keys,omitempty"`
-	Dialects        []string `json:"dialects,omitempty"` // Only for SQL rules
-	Type            string   `json:"type"`               // "sql" or "project"
+	ID              string       `json:"id"`
+	Name            string       `json:"name"`
+	Group           string       `json:"group"`
+	Description     string       `json:"description"`
+	DefaultSeverity Severity     `json:"default_severity"`
+	ConfigKeys      []string     `json:"config_keys,omitempty"`
+	Options         []RuleOption `json:"options,omitempty"`
+	Dialects        []string     `json:"dialects,omitempty"` // Only for SQL rules
+	Type            string       `json:"type"`               // "sql" or "project"
 
 	// Documentation fields
 	Rationale   string `json:"rationale,omitempty"`
@@ -76,3 +77,26 @@ type RuleInfo struct {
 	GoodExample string `json:"good_example,omitempty"`
 	Fix         string `json:"fix,omitempty"`
 }
+
+// OptionType is the type of a lint rule option's value.
+type OptionType string
+
+// Lint rule option types.
+const (
+	OptionInt        OptionType = "int"
+	OptionString     OptionType = "string"
+	OptionBool       OptionType = "bool"
+	OptionStringList OptionType = "string_list"
+)
+
+// RuleOption declares an option a lint rule accepts, so options set in the
+// project config can be validated and documented.
+type RuleOption struct {
+	Name        string     `json:"name"`
+	Type        OptionType `json:"type"`
+	Default     any        `json:"default,omitempty"` // Value used when the option is not set
+	Description string     `json:"description,omitempty"`
+	Min         int        `json:"min,omitempty"`  // Smallest int value allowed (0 for no bound)
+	Max         int        `json:"max,omitempty"`  // Largest int value allowed (0 for no bound)
+	Enum        []string   `json:"enum,omitempty"` // Allowed string values, or list items
+}
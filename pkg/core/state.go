@@ -1,7 +1,9 @@
 package core
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"time"
 )
 
@@ -30,9 +32,14 @@ type Store interface {
 	// Model run operations
 	RecordModelRun(modelRun *ModelRun) error
 	UpdateModelRun(id string, status ModelRunStatus, rowsAffected int64, errMsg string, renderMS int64, executionMS int64) error
+	UpdateModelRunCost(id string, cost QueryCost) error
+	UpdateModelRunBuildMode(id string, mode BuildMode) error
+	UpdateModelRunSkip(id string, reason SkipReason, skippedBy string) error
+	DeleteOrphanedModelRuns() (int64, error)
 	GetModelRunsForRun(runID string) ([]*ModelRun, error)
 	GetModelRunsWithModelInfo(runID string) ([]*ModelRunWithInfo, error)
 	GetLatestModelRun(modelID string) (*ModelRun, error)
+	GetLatestSuccessfulModelRun(modelID string) (*ModelRun, error)
 
 	// Dependency operations
 	SetDependencies(modelID string, parentIDs []string) error
@@ -72,11 +79,38 @@ type Store interface {
 	ListModelFilePaths() ([]string, error)
 	ListMacroFilePaths() ([]string, error)
 
+	// Build cache operations
+	GetModelBuild(modelID, target string) (*ModelBuild, error)
+	SaveModelBuild(build *ModelBuild) error
+	DeleteModelBuild(modelID, target string) error
+
 	// Column snapshot operations
 	SaveColumnSnapshot(runID, modelPath, sourceTable string, columns []string) error
 	GetColumnSnapshot(modelPath, sourceTable string) (columns []string, runID string, err error)
 	DeleteOldSnapshots(keepRuns int) error
 
+	// Query plan operations
+	SaveModelPlan(plan *ModelPlan) error
+	GetModelPlans(modelPath string, limit int) ([]*ModelPlan, error)
+	GetModelPlansForRun(runID string) ([]*ModelPlan, error)
+
+	// Model metric operations
+	SaveModelMetrics(metrics []*ModelMetric) error
+	GetModelMetrics(modelPath, metric, column string, limit int) ([]*ModelMetric, error)
+	GetModelMetricsForRun(runID string) ([]*ModelMetric, error)
+
+	// Backfill operations
+	CreateBackfill(backfill *Backfill) error
+	GetBackfill(id string) (*Backfill, error)
+	GetUnfinishedBackfill(modelPath, column string, start, end time.Time, chunk BackfillChunkSize) (*Backfill, error)
+	CompleteBackfill(id string, status BackfillStatus, errMsg string) error
+	RecordBackfillChunk(chunk *BackfillChunk) error
+	GetBackfillChunks(backfillID string) ([]*BackfillChunk, error)
+
+	// Source snapshot operations
+	SaveSourceSnapshots(modelPath, target string, snapshots []SourceSnapshot) error
+	GetSourceSnapshots(modelPath, target string) ([]SourceSnapshot, error)
+
 	// Batch operations
 	BatchGetAllColumns() (map[string][]ColumnInfo, error)
 	BatchGetAllDependencies() (map[string][]string, error)
@@ -91,6 +125,19 @@ type QueryableStore interface {
 	DB() *sql.DB
 }
 
+// ErrStateLocked is returned when the run lock is held by another run.
+var ErrStateLocked = errors.New("state is locked by another run")
+
+// Locker is an optional interface for stores that can serialize runs of
+// several processes sharing the store.
+type Locker interface {
+	Store
+	// LockRuns acquires the run lock, waiting up to timeout for another run to
+	// release it. It fails with ErrStateLocked if the lock is still held.
+	// The returned function releases the lock.
+	LockRuns(ctx context.Context, timeout time.Duration) (func() error, error)
+}
+
 // RunStatus represents the status of a pipeline run.
 type RunStatus string
 
@@ -144,8 +191,177 @@ type ModelRun struct {
 	StartedAt    time.Time
 	CompletedAt  *time.Time
 	Error        string
-	RenderMS     int64 // Time spent rendering template
-	ExecutionMS  int64 // Time spent executing SQL
+	RenderMS     int64      // Time spent rendering template
+	ExecutionMS  int64      // Time spent executing SQL
+	BytesScanned int64      // Bytes read by the model's queries (0 if the adapter does not report costs)
+	SlotMS       int64      // Compute time used by the model's queries (0 if the adapter does not report costs)
+	BuildMode    BuildMode  // How the model was built (empty if it was not built)
+	RowFilter    string     // Row filter applied to the model's query (empty if none)
+	ContentHash  string     // Content hash of the model when it ran (empty for runs recorded before it was tracked)
+	SkipReason   SkipReason // Why the model was skipped (empty if it was not)
+	SkippedBy    string     // Path of the model whose failure caused the skip (empty if none)
+}
+
+// Model metrics recorded by anomaly tests.
+const (
+	// MetricRowCount is the number of rows of a model's relation
+	MetricRowCount = "row_count"
+	// MetricNullRate is the fraction of null values in a column (0 to 1)
+	MetricNullRate = "null_rate"
+)
+
+// ModelMetric is a measure of a model's output recorded in a run, compared
+// between runs by anomaly tests.
+type ModelMetric struct {
+	RunID      string
+	ModelPath  string
+	Metric     string // MetricRowCount or MetricNullRate
+	Column     string // Column of a null rate (empty for row counts)
+	Value      float64
+	RecordedAt time.Time
+}
+
+// BuildMode describes how a model run built the model's relation.
+type BuildMode string
+
+// Build mode constants.
+const (
+	// BuildModeFull rebuilt the relation from the model's full query: tables,
+	// views and the first build of incremental models
+	BuildModeFull BuildMode = "full"
+	// BuildModeIncremental merged new rows into an existing incremental table
+	BuildModeIncremental BuildMode = "incremental"
+	// BuildModeFullRefresh rebuilt the model from scratch under a full refresh,
+	// ignoring the build cache and discarding an existing incremental table
+	BuildModeFullRefresh BuildMode = "full_refresh"
+	// BuildModeBackfill rebuilt the rows of an incremental model in a range of
+	// dates, one chunk at a time (see Backfill)
+	BuildModeBackfill BuildMode = "backfill"
+)
+
+// BackfillStatus represents the status of a backfill.
+type BackfillStatus string
+
+// Backfill status constants.
+const (
+	BackfillStatusRunning   BackfillStatus = "running"
+	BackfillStatusCompleted BackfillStatus = "completed"
+	BackfillStatusFailed    BackfillStatus = "failed"
+)
+
+// BackfillChunkSize is the length of the date ranges a backfill builds one
+// at a time.
+type BackfillChunkSize string
+
+// Backfill chunk size constants.
+const (
+	BackfillChunkDay   BackfillChunkSize = "day"
+	BackfillChunkWeek  BackfillChunkSize = "week"
+	BackfillChunkMonth BackfillChunkSize = "month"
+)
+
+// Next returns the start of the chunk after the one starting at t.
+func (c BackfillChunkSize) Next(t time.Time) time.Time {
+	switch c {
+	case BackfillChunkWeek:
+		return t.AddDate(0, 0, 7)
+	case BackfillChunkMonth:
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+// Backfill rebuilds the rows of an incremental model in a range of dates,
+// one chunk of the range at a time. Each chunk is committed and recorded as
+// it completes, so an interrupted backfill resumes after its completed
+// chunks.
+type Backfill struct {
+	ID          string
+	ModelPath   string
+	Column      string            // Date column the range applies to
+	Start       time.Time         // First date of the range
+	End         time.Time         // Date the range ends before
+	Chunk       BackfillChunkSize // Length of the chunks
+	Status      BackfillStatus
+	Error       string
+	StartedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// BackfillChunk is a completed chunk of a backfill.
+type BackfillChunk struct {
+	BackfillID   string
+	Start        time.Time // First date of the chunk
+	End          time.Time // Date the chunk ends before
+	RunID        string    // Run that built the chunk
+	RowsAffected int64
+	CompletedAt  time.Time
+}
+
+// SkipReason describes why a model run was skipped.
+type SkipReason string
+
+// Skip reason constants.
+const (
+	// SkipReasonCacheHit skipped a model whose build inputs are unchanged
+	// since its last build
+	SkipReasonCacheHit SkipReason = "cache_hit"
+	// SkipReasonFresh skipped a model, under --skip-fresh, whose build inputs
+	// and sources are unchanged since its last build
+	SkipReasonFresh SkipReason = "fresh"
+	// SkipReasonUpstreamFailed skipped a model downstream of a model that
+	// failed (the model run's SkippedBy)
+	SkipReasonUpstreamFailed SkipReason = "upstream_failed"
+	// SkipReasonRunStopped skipped a model that does not depend on the failed
+	// model (the model run's SkippedBy), since the run stops at the first failure
+	SkipReasonRunStopped SkipReason = "run_stopped"
+	// SkipReasonRenderFailed skipped every model of a run in which other
+	// models failed to render
+	SkipReasonRenderFailed SkipReason = "render_failed"
+	// SkipReasonGroupTimeout skipped a model in, or downstream of, a group
+	// that exceeded its max_runtime; SkippedBy names the model that timed out
+	SkipReasonGroupTimeout SkipReason = "group_timeout"
+	// SkipReasonCancelled skipped the models left when the run was cancelled
+	SkipReasonCancelled SkipReason = "cancelled"
+	// SkipReasonInterrupted skipped the models left when the run's process
+	// exited before the run completed
+	SkipReasonInterrupted SkipReason = "interrupted"
+)
+
+// ModelBuild records the last successful build of a model in a target.
+// The build hash covers everything the build depends on, so a model whose
+// hash is unchanged does not need to be rebuilt.
+type ModelBuild struct {
+	ModelID   string
+	Target    string
+	BuildHash string
+	RunID     string
+	BuiltAt   time.Time
+}
+
+// SourceSnapshot records when a source a model reads last changed, as seen
+// before the model's last build: the newest modification time of a file
+// source, or the latest loaded_at value of a table source.
+type SourceSnapshot struct {
+	Source    string
+	ChangedAt time.Time
+}
+
+// QueryCost is the warehouse cost of executed SQL, as reported by the adapter.
+type QueryCost struct {
+	// BytesScanned is the number of bytes read from tables
+	BytesScanned int64
+	// SlotMS is the compute time used across all workers, in milliseconds
+	// (BigQuery slot-ms; CPU time for DuckDB)
+	SlotMS int64
+}
+
+// Add returns the sum of two costs.
+func (c QueryCost) Add(other QueryCost) QueryCost {
+	return QueryCost{
+		BytesScanned: c.BytesScanned + other.BytesScanned,
+		SlotMS:       c.SlotMS + other.SlotMS,
+	}
 }
 
 // Dependency represents an edge in the model dependency graph.
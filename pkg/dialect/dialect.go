@@ -55,6 +55,7 @@ func NewDialect(name string) *Builder {
 			Generators:     make(map[string]struct{}),
 			Windows:        make(map[string]struct{}),
 			TableFunctions: make(map[string]struct{}),
+			FileFunctions:  make(map[string]struct{}),
 			Docs:           make(map[string]core.FunctionDoc),
 			Keywords:       make(map[string]struct{}),
 			ReservedWords:  make(map[string]struct{}),
@@ -87,6 +88,7 @@ func New(cfg *core.DialectConfig) *Builder {
 			Generators:     make(map[string]struct{}),
 			Windows:        make(map[string]struct{}),
 			TableFunctions: make(map[string]struct{}),
+			FileFunctions:  make(map[string]struct{}),
 			Docs:           make(map[string]core.FunctionDoc),
 			Keywords:       make(map[string]struct{}),
 			ReservedWords:  make(map[string]struct{}),
@@ -149,6 +151,16 @@ func (b *Builder) TableFunctions(funcs ...string) *Builder {
 	return b
 }
 
+// FileFunctions adds table functions that read the files named by their first
+// argument to the dialect.
+func (b *Builder) FileFunctions(funcs ...string) *Builder {
+	for _, f := range funcs {
+		b.dialect.TableFunctions[b.dialect.NormalizeName(f)] = struct{}{}
+		b.dialect.FileFunctions[b.dialect.NormalizeName(f)] = struct{}{}
+	}
+	return b
+}
+
 // WithDocs registers documentation for functions.
 func (b *Builder) WithDocs(docs map[string]core.FunctionDoc) *Builder {
 	for name, doc := range docs {
@@ -352,6 +364,10 @@ func (b *Builder) Build() *core.Dialect {
 	for _, f := range cfg.TableFunctions {
 		b.dialect.TableFunctions[b.dialect.NormalizeName(f)] = struct{}{}
 	}
+	for _, f := range cfg.FileFunctions {
+		b.dialect.TableFunctions[b.dialect.NormalizeName(f)] = struct{}{}
+		b.dialect.FileFunctions[b.dialect.NormalizeName(f)] = struct{}{}
+	}
 	for _, kw := range cfg.Keywords {
 		b.dialect.Keywords[b.dialect.NormalizeName(kw)] = struct{}{}
 	}
@@ -386,6 +402,16 @@ func (b *Builder) Build() *core.Dialect {
 		b.dialect.Precedences[token.DCOLON] = core.PrecedencePostfix
 	}
 
+	if cfg.SupportsJSONOperators {
+		b.AddOperator("->>", token.DARROW)
+		b.dialect.Precedences[token.DARROW] = core.PrecedencePostfix
+		// -> is lexed natively; dialects that reuse it (e.g. DuckDB lambdas)
+		// keep their own precedence and handler
+		if _, ok := b.dialect.Precedences[token.ARROW]; !ok {
+			b.dialect.Precedences[token.ARROW] = core.PrecedencePostfix
+		}
+	}
+
 	// Auto-wire join extensions
 	if cfg.SupportsSemiAntiJoins {
 		b.AddKeyword("SEMI", token.SEMI)
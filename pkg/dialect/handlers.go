@@ -38,9 +38,25 @@ func ParseHaving(p spi.ParserOps) (spi.Node, error) {
 
 // ParseWindow handles named window definitions.
 // The WINDOW keyword has already been consumed.
-func ParseWindow(_ spi.ParserOps) (spi.Node, error) {
-	// Window definitions are complex - the parser handles this specially
-	return nil, nil
+func ParseWindow(p spi.ParserOps) (spi.Node, error) {
+	var windows []core.WindowDef
+	for {
+		name, err := p.ParseIdentifier()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Expect(token.AS); err != nil {
+			return nil, err
+		}
+		spec, err := p.ParseWindowSpec()
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, core.WindowDef{Name: name, Spec: spec})
+		if !p.Match(token.COMMA) {
+			return windows, nil
+		}
+	}
 }
 
 // ParseOrderBy handles the standard ORDER BY clause.
@@ -22,6 +22,7 @@ var Config = &core.DialectConfig{
 	SupportsQualify:       true,
 	SupportsIlike:         true,
 	SupportsCastOperator:  true,
+	SupportsJSONOperators: true,
 	SupportsSemiAntiJoins: true,
 	SupportsGroupByAll:    true,
 	SupportsOrderByAll:    true,
@@ -32,6 +33,7 @@ var Config = &core.DialectConfig{
 	Generators:     duckDBGenerators,
 	Windows:        duckDBWindows,
 	TableFunctions: duckDBTableFunctions,
+	FileFunctions:  duckDBFileFunctions,
 	Keywords:       duckDBCompletionKeywords,
 	DataTypes:      duckDBTypes,
 }
@@ -37,6 +37,7 @@ var (
 // - QUALIFY clause (SupportsQualify)
 // - ILIKE operator (SupportsIlike)
 // - :: cast operator (SupportsCastOperator)
+// - ->> JSON operator (SupportsJSONOperators; -> is shared with lambdas)
 // - SEMI/ANTI joins (SupportsSemiAntiJoins)
 // - GROUP BY ALL (SupportsGroupByAll)
 // - ORDER BY ALL (SupportsOrderByAll)
@@ -137,7 +137,20 @@ func parseIndexOrSlice(p spi.ParserOps, left core.Expr) (core.Expr, error) {
 // parseLambdaBody handles -> expr after lambda params.
 // The -> has already been consumed.
 // left is the parameter(s) - either ColumnRef or ParenExpr containing params.
+//
+// DuckDB also uses -> for JSON extraction (payload->'$.a', items->0). A string
+// or number literal on the right can never be a lambda body, so that form is
+// parsed as a binary JSON operator instead.
 func parseLambdaBody(p spi.ParserOps, left core.Expr) (core.Expr, error) {
+	if tok := p.Token(); tok.Type == token.STRING || tok.Type == token.NUMBER {
+		p.NextToken()
+		lit := &core.Literal{Type: core.LiteralString, Value: tok.Literal}
+		if tok.Type == token.NUMBER {
+			lit.Type = core.LiteralNumber
+		}
+		return &core.BinaryExpr{Left: left, Op: token.ARROW, Right: lit}, nil
+	}
+
 	lambda := &core.LambdaExpr{}
 
 	// Extract parameter names from left
@@ -23,6 +23,23 @@ var duckDBWindows = []string{
 	"row_number",
 }
 
+// duckDBFileFunctions contains the table functions that read the files, URLs
+// or globs named by their first argument. Models reading files through them
+// have the files as external sources.
+var duckDBFileFunctions = []string{
+	"delta_scan",
+	"iceberg_scan",
+	"parquet_scan",
+	"read_csv",
+	"read_csv_auto",
+	"read_json",
+	"read_json_auto",
+	"read_ndjson",
+	"read_ndjson_auto",
+	"read_parquet",
+	"read_xlsx",
+}
+
 // duckDBWindowDocs provides documentation for window functions.
 var duckDBWindowDocs = map[string]core.FunctionDoc{
 	"row_number": {
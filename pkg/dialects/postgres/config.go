@@ -19,9 +19,10 @@ var Config = &core.DialectConfig{
 	},
 
 	// Framework Features (auto-wired by Builder)
-	SupportsIlike:        true,
-	SupportsCastOperator: true,
-	SupportsReturning:    true,
+	SupportsIlike:         true,
+	SupportsCastOperator:  true,
+	SupportsJSONOperators: true,
+	SupportsReturning:     true,
 	// PostgreSQL does NOT support these:
 	// - QUALIFY (window filtering clause)
 	// - GROUP BY ALL
@@ -36,6 +36,7 @@ var postgresReservedWords = []string{
 // Builder reads Config flags and auto-wires standard features:
 // - ILIKE operator (SupportsIlike)
 // - :: cast operator (SupportsCastOperator)
+// - -> and ->> JSON operators (SupportsJSONOperators)
 // - RETURNING clause (SupportsReturning)
 var Postgres = dialect.New(Config).
 	// Clause Sequence - standard ANSI clauses (no QUALIFY)
@@ -0,0 +1,18 @@
+package format
+
+import "github.com/leapstack-labs/leapsql/pkg/core"
+
+// Options configures the casing the formatter applies. Policies left unset
+// are core.CasingAny: keywords and function names are printed upper case,
+// data types and identifiers as written. Unquoted identifiers are only
+// recased in dialects that fold them to one case, where recasing them
+// doesn't change what they name.
+type Options struct {
+	Keywords    core.Casing // Keywords and data types: any, upper or lower
+	Functions   core.Casing // Function names: any, upper or lower
+	Identifiers core.Casing // Unquoted identifiers: any, upper, lower or snake_case
+
+	// Quoted holds the identifiers quoted in the source. They are printed
+	// quoted and as written, whatever the identifier casing.
+	Quoted map[string]bool
+}
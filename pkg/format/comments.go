@@ -0,0 +1,93 @@
+package format
+
+import (
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+// Comments are placed around anchors: the nodes the printer starts on a
+// line of their own (WITH clauses, CTEs, SELECT cores, select items and
+// joins) and the first tables of FROM clauses. A comment on the line an
+// anchor ends on, after it, trails the anchor and is printed at the end of
+// its last line. Any other comment leads the first anchor after it and is
+// printed on the lines before it. Comments after the last anchor close the
+// statement. Formatting moves comments to the nearest anchor, once:
+// formatted SQL keeps its comments where they are.
+
+// anchor is a node comments are placed around.
+type anchor struct {
+	node any
+	span token.Span
+}
+
+// placeComments formats stmt without comments to collect its anchors in
+// printing order, and places comments around them. It returns the comments
+// after the last anchor.
+func (p *Printer) placeComments(stmt *core.SelectStmt, comments []*token.Comment) []*token.Comment {
+	dry := newPrinter(p.dialect, p.opts)
+	dry.anchors = []anchor{}
+	dry.formatSelectStmt(stmt)
+
+	p.leading = make(map[any][]*token.Comment)
+	p.trailing = make(map[any][]*token.Comment)
+	var rest []*token.Comment
+	for _, c := range comments {
+		// The last anchor ending before the comment on its line, the
+		// innermost of the ones ending together
+		var prev *anchor
+		for i, a := range dry.anchors {
+			if a.span.End.Offset <= c.Span.Start.Offset && endLine(a.span.End) == c.Span.Start.Line &&
+				(prev == nil || a.span.End.Offset >= prev.span.End.Offset) {
+				prev = &dry.anchors[i]
+			}
+		}
+		if prev != nil {
+			p.trailing[prev.node] = append(p.trailing[prev.node], c)
+			continue
+		}
+
+		// The first anchor starting after the comment, the outermost of
+		// the ones starting together
+		var next *anchor
+		for i, a := range dry.anchors {
+			if a.span.Start.Offset >= c.Span.End.Offset && (next == nil || a.span.Start.Offset < next.span.Start.Offset) {
+				next = &dry.anchors[i]
+			}
+		}
+		if next != nil {
+			p.leading[next.node] = append(p.leading[next.node], c)
+			continue
+		}
+		rest = append(rest, c)
+	}
+	return rest
+}
+
+// endLine returns the line of the last character before an end position.
+// The lexer reports the position right after the last character of a line
+// as the start of the next one.
+func endLine(pos token.Position) int {
+	if pos.Column == 0 && pos.Line > 1 {
+		return pos.Line - 1
+	}
+	return pos.Line
+}
+
+// beginAnchor prints the comments leading node, on lines of their own. When
+// collecting anchors, it records node.
+func (p *Printer) beginAnchor(node any, span token.Span) {
+	if p.anchors != nil && span.IsValid() {
+		p.anchors = append(p.anchors, anchor{node: node, span: span})
+	}
+	if comments := p.leading[node]; len(comments) > 0 {
+		if !p.atLineStart {
+			p.writeln()
+		}
+		p.formatComments(comments)
+	}
+}
+
+// endAnchor prints the comments trailing node at the end of its last line.
+func (p *Printer) endAnchor(node any) {
+	p.formatTrailingComments(p.trailing[node])
+}
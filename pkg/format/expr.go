@@ -1,6 +1,8 @@
 package format
 
 import (
+	"strings"
+
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/token"
 )
@@ -123,9 +125,7 @@ func isLogicalOp(op token.TokenType) bool {
 func (p *Printer) formatLiteral(lit *core.Literal) {
 	switch lit.Type {
 	case core.LiteralString:
-		p.write("'")
-		p.write(lit.Value)
-		p.write("'")
+		p.write("'" + strings.ReplaceAll(lit.Value, "'", "''") + "'")
 	case core.LiteralBool:
 		if lit.Value == "TRUE" || lit.Value == "true" {
 			p.kw(token.TRUE)
@@ -141,10 +141,14 @@ func (p *Printer) formatLiteral(lit *core.Literal) {
 
 func (p *Printer) formatColumnRef(col *core.ColumnRef) {
 	if col.Table != "" {
-		p.write(col.Table)
+		p.ident(col.Table)
+		p.write(".")
+	}
+	p.ident(col.Column)
+	for _, field := range col.Fields {
 		p.write(".")
+		p.ident(field)
 	}
-	p.write(col.Column)
 }
 
 func (p *Printer) formatBinaryExpr(expr *core.BinaryExpr) {
@@ -174,7 +178,7 @@ func (p *Printer) formatUnaryExpr(expr *core.UnaryExpr) {
 }
 
 func (p *Printer) formatFuncCall(fn *core.FuncCall) {
-	p.write(fn.Name)
+	p.write(p.opts.Functions.Apply(fn.Name))
 	p.write("(")
 
 	if fn.Distinct {
@@ -210,10 +214,20 @@ func (p *Printer) formatFuncCall(fn *core.FuncCall) {
 
 func (p *Printer) formatWindowSpec(w *core.WindowSpec) {
 	p.kw(token.OVER)
-	p.write(" (")
+	p.space()
+	if w.Name != "" && len(w.PartitionBy) == 0 && len(w.OrderBy) == 0 && w.Frame == nil {
+		// OVER w reads the named window as is
+		p.ident(w.Name)
+		return
+	}
+	p.formatWindowBody(w)
+}
 
+// formatWindowBody prints the parenthesized body of a window specification.
+func (p *Printer) formatWindowBody(w *core.WindowSpec) {
+	p.write("(")
 	if w.Name != "" {
-		p.write(w.Name)
+		p.ident(w.Name)
 	}
 
 	if len(w.PartitionBy) > 0 {
@@ -328,7 +342,7 @@ func (p *Printer) formatCastExpr(c *core.CastExpr) {
 	p.space()
 	p.kw(token.AS)
 	p.space()
-	p.write(c.TypeName)
+	p.write(p.opts.Keywords.Apply(c.TypeName))
 	p.write(")")
 }
 
@@ -417,6 +431,10 @@ func (p *Printer) formatParenExpr(paren *core.ParenExpr) {
 }
 
 func (p *Printer) formatSubqueryExpr(sq *core.SubqueryExpr) {
+	if sq.Quantifier != "" {
+		p.keyword(sq.Quantifier)
+		p.space()
+	}
 	p.write("(")
 	p.writeln()
 	p.indent()
@@ -441,7 +459,7 @@ func (p *Printer) formatExistsExpr(ex *core.ExistsExpr) {
 
 func (p *Printer) formatStarExpr(star *core.StarExpr) {
 	if star.Table != "" {
-		p.write(star.Table)
+		p.ident(star.Table)
 		p.write(".")
 	}
 	p.write("*")
@@ -456,14 +474,14 @@ func (p *Printer) formatMacroExpr(m *core.MacroExpr) {
 
 func (p *Printer) formatLambdaExpr(lambda *core.LambdaExpr) {
 	if len(lambda.Params) == 1 {
-		p.write(lambda.Params[0])
+		p.ident(lambda.Params[0])
 	} else {
 		p.write("(")
 		for i, param := range lambda.Params {
 			if i > 0 {
 				p.write(", ")
 			}
-			p.write(param)
+			p.ident(param)
 		}
 		p.write(")")
 	}
@@ -478,9 +496,7 @@ func (p *Printer) formatStructLiteral(s *core.StructLiteral) {
 			p.write(", ")
 		}
 		// In DuckDB struct literals, keys are always single-quoted strings
-		p.write("'")
-		p.write(field.Key)
-		p.write("'")
+		p.write("'" + strings.ReplaceAll(field.Key, "'", "''") + "'")
 		p.write(": ")
 		p.formatExpr(field.Value)
 	}
@@ -7,16 +7,28 @@ import (
 
 // Format formats a parsed SQL statement according to the dialect.
 func Format(stmt *core.SelectStmt, d *core.Dialect) string {
-	p := newPrinter(d)
+	p := newPrinter(d, Options{})
 	p.formatSelectStmt(stmt)
 	return p.String()
 }
 
-// WithComments formats a statement with comment preservation.
+// WithComments formats a statement with comment preservation. Comments
+// move to the nearest line the formatter keeps, see comments.go.
 func WithComments(stmt *core.SelectStmt, comments []*token.Comment, d *core.Dialect) string {
-	decorated := Decorate(stmt, comments)
-	p := newPrinter(d)
-	p.formatSelectStmt(decorated)
+	return WithOptions(stmt, comments, d, Options{})
+}
+
+// WithOptions formats a statement with comment preservation in the casing
+// of opts.
+func WithOptions(stmt *core.SelectStmt, comments []*token.Comment, d *core.Dialect, opts Options) string {
+	p := newPrinter(d, opts)
+	rest := p.placeComments(stmt, comments)
+	p.formatSelectStmt(stmt)
+	p.flushTrailing()
+	if len(rest) > 0 && !p.atLineStart {
+		p.writeln()
+	}
+	p.formatComments(rest)
 	return p.String()
 }
 
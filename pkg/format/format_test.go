@@ -1,10 +1,17 @@
 package format
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/leapstack-labs/leapsql/pkg/core"
+	databricksdialect "github.com/leapstack-labs/leapsql/pkg/dialects/databricks"
 	duckdbdialect "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	postgresdialect "github.com/leapstack-labs/leapsql/pkg/dialects/postgres"
+	snowflakedialect "github.com/leapstack-labs/leapsql/pkg/dialects/snowflake"
 	"github.com/leapstack-labs/leapsql/pkg/parser"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -69,6 +76,43 @@ FROM t
 			expected: `SELECT
   t.*
 FROM t
+`,
+		},
+		{
+			name:  "quoted identifiers",
+			input: `SELECT "Order Total" AS "total ""net""", "select" FROM "My Table" m`,
+			expected: `SELECT
+  "Order Total" AS "total ""net""",
+  "select"
+FROM "My Table" m
+`,
+		},
+		{
+			name:  "escaped string",
+			input: "SELECT 'it''s' FROM t",
+			expected: `SELECT
+  'it''s'
+FROM t
+`,
+		},
+		{
+			name:  "named windows",
+			input: "SELECT rank() OVER w FROM t WINDOW w AS (PARTITION BY a ORDER BY b)",
+			expected: `SELECT
+  RANK() OVER w
+FROM t
+WINDOW
+  w AS (
+    PARTITION BY a
+    ORDER BY b)
+`,
+		},
+		{
+			name:  "select catalog-qualified table star",
+			input: "SELECT lake.main.orders.* FROM lake.main.orders",
+			expected: `SELECT
+  lake.main.orders.*
+FROM lake.main.orders
 `,
 		},
 	}
@@ -410,6 +454,43 @@ SELECT id FROM users`
 	assert.Contains(t, result, "-- Leading comment")
 }
 
+func TestFormat_CommentPlacement(t *testing.T) {
+	d := duckdbdialect.DuckDB
+
+	input := `-- header
+WITH paid AS (SELECT id FROM orders WHERE amount > 0) -- paid only
+SELECT id, -- the key
+  name
+FROM paid -- source
+/* joined */ JOIN users u ON u.id = paid.id
+WHERE id > 0 /* moved */
+-- footer`
+	expected := `-- header
+WITH
+  paid AS (
+    SELECT
+      id
+    FROM orders
+    WHERE
+      amount > 0
+  ) -- paid only
+SELECT
+  id, -- the key
+  name
+FROM paid -- source
+/* joined */
+JOIN users u
+  ON u.id = paid.id
+WHERE
+  id > 0 /* moved */
+-- footer
+`
+
+	result, err := formatSQL(input, d)
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+}
+
 func TestFormat_Cast(t *testing.T) {
 	d := duckdbdialect.DuckDB
 
@@ -426,6 +507,22 @@ FROM t
 	assert.Equal(t, expected, result)
 }
 
+func TestFormat_Casing(t *testing.T) {
+	d := duckdbdialect.DuckDB
+	input := `SELECT Count(*) AS n, CAST(amount AS Decimal) AS "Total" FROM Orders`
+	stmt, comments, err := parser.ParseWithDialectAndComments(input, d)
+	require.NoError(t, err)
+
+	opts := Options{Keywords: core.CasingLower, Functions: core.CasingLower, Identifiers: core.CasingUpper, Quoted: map[string]bool{"Total": true}}
+	assert.Equal(t, "select\n  count(*) as N,\n  cast(AMOUNT as decimal) as \"Total\"\nfrom ORDERS\n", WithOptions(stmt, comments, d, opts))
+
+	// Identifiers keep their case where it is part of their name
+	caseSensitive := *d
+	caseSensitive.Identifiers.Normalization = core.NormCaseSensitive
+	assert.Equal(t, "SELECT\n  COUNT(*) AS n,\n  CAST(amount AS Decimal) AS \"Total\"\nFROM Orders\n",
+		WithOptions(stmt, comments, &caseSensitive, Options{Identifiers: core.CasingUpper, Quoted: opts.Quoted}))
+}
+
 func TestFormat_Exists(t *testing.T) {
 	d := duckdbdialect.DuckDB
 
@@ -447,3 +544,73 @@ WHERE
 	result := Format(stmt, d)
 	assert.Equal(t, expected, result)
 }
+
+func TestFormat_QuantifiedSubquery(t *testing.T) {
+	d := duckdbdialect.DuckDB
+
+	input := "SELECT * FROM t WHERE a > all (SELECT b FROM other)"
+	expected := `SELECT
+  *
+FROM t
+WHERE
+  a > ALL (
+    SELECT
+      b
+    FROM other
+  )
+`
+
+	stmt, err := parser.ParseWithDialect(input, d)
+	require.NoError(t, err)
+
+	result := Format(stmt, d)
+	assert.Equal(t, expected, result)
+}
+
+// TestFormat_Idempotent formats the corpus of each dialect in
+// testdata/corpus twice: formatted SQL must format to itself, so checking
+// formatting (leapsql fmt --check) only fails on files that need it.
+func TestFormat_Idempotent(t *testing.T) {
+	dialects := map[string]*core.Dialect{
+		"duckdb":     duckdbdialect.DuckDB,
+		"postgres":   postgresdialect.Postgres,
+		"snowflake":  snowflakedialect.Snowflake,
+		"databricks": databricksdialect.Databricks,
+	}
+	files, err := filepath.Glob(filepath.Join("testdata", "corpus", "*.sql"))
+	require.NoError(t, err)
+	require.Len(t, files, len(dialects))
+
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".sql")
+		d, ok := dialects[name]
+		require.True(t, ok, "no dialect for %s", file)
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		// Statements are separated by semicolons ending a line
+		for i, sql := range strings.Split(string(content), ";\n") {
+			if strings.TrimSpace(sql) == "" {
+				continue
+			}
+			t.Run(fmt.Sprintf("%s/%d", name, i+1), func(t *testing.T) {
+				once, err := formatSQL(sql, d)
+				require.NoError(t, err, "input:\n%s", sql)
+				twice, err := formatSQL(once, d)
+				require.NoError(t, err, "formatted:\n%s", once)
+				assert.Equal(t, once, twice, "input:\n%s", sql)
+				assert.ElementsMatch(t, commentTexts(t, sql, d), commentTexts(t, once, d), "comments lost:\n%s", once)
+			})
+		}
+	}
+}
+
+func commentTexts(t *testing.T, sql string, d *core.Dialect) []string {
+	_, comments, err := parser.ParseWithDialectAndComments(sql, d)
+	require.NoError(t, err)
+	texts := make([]string, len(comments))
+	for i, c := range comments {
+		texts[i] = c.Text
+	}
+	return texts
+}
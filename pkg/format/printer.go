@@ -14,14 +14,22 @@ const indentSize = 2
 // Printer handles SQL formatting with proper indentation and style.
 type Printer struct {
 	dialect     *core.Dialect
+	opts        Options
 	output      *bytes.Buffer
 	depth       int
 	atLineStart bool
+
+	// Comment placement, see comments.go
+	anchors  []anchor
+	leading  map[any][]*token.Comment
+	trailing map[any][]*token.Comment
+	pending  []*token.Comment
 }
 
-func newPrinter(d *core.Dialect) *Printer {
+func newPrinter(d *core.Dialect, opts Options) *Printer {
 	return &Printer{
 		dialect:     d,
+		opts:        opts,
 		output:      &bytes.Buffer{},
 		atLineStart: true,
 	}
@@ -41,6 +49,7 @@ func (p *Printer) write(s string) {
 }
 
 func (p *Printer) writeln() {
+	p.flushTrailing()
 	p.output.WriteByte('\n')
 	p.atLineStart = true
 }
@@ -52,7 +61,13 @@ func (p *Printer) writeIndent() {
 	p.atLineStart = false
 }
 
+// keyword prints a keyword upper case, or lower case in the lower keyword
+// casing.
 func (p *Printer) keyword(s string) {
+	if p.opts.Keywords == core.CasingLower {
+		p.write(strings.ToLower(s))
+		return
+	}
 	p.write(strings.ToUpper(s))
 }
 
@@ -70,14 +85,13 @@ func (p *Printer) space() {
 	p.output.WriteByte(' ')
 }
 
-// kw prints a keyword based on the token type.
-// It automatically handles capitalization or dialect specifics if we add them later.
+// kw prints a keyword based on the token type, in the keyword casing.
 func (p *Printer) kw(tokens ...token.TokenType) {
 	for i, t := range tokens {
 		if i > 0 {
 			p.space()
 		}
-		p.write(t.String())
+		p.keyword(t.String())
 	}
 }
 
@@ -88,11 +102,55 @@ func (p *Printer) formatComments(comments []*token.Comment) {
 	}
 }
 
+// formatTrailingComments prints comments at the end of the current line.
 func (p *Printer) formatTrailingComments(comments []*token.Comment) {
-	for _, c := range comments {
-		p.space()
-		p.write(c.Text)
+	p.pending = append(p.pending, comments...)
+	if p.atLineStart {
+		p.flushTrailing()
+	}
+}
+
+// flushTrailing prints the pending trailing comments at the end of the
+// current line, or of the previous one at the start of a line.
+func (p *Printer) flushTrailing() {
+	if len(p.pending) == 0 {
+		return
+	}
+	reopened := p.atLineStart && bytes.HasSuffix(p.output.Bytes(), []byte("\n"))
+	if reopened {
+		p.output.Truncate(p.output.Len() - 1)
+	}
+	for _, c := range p.pending {
+		p.output.WriteString(" " + c.Text)
+	}
+	p.pending = nil
+	if reopened {
+		p.output.WriteByte('\n')
+	}
+}
+
+// ident prints an identifier, double-quoted if it was quoted in the source
+// or is not a plain one: letters, digits and underscores, not starting with
+// a digit, and not a keyword of the lexer. Double quotes are what the lexer
+// reads, whatever the dialect's own quotes. Plain identifiers are printed in
+// the identifier casing, unless the dialect is case-sensitive.
+func (p *Printer) ident(name string) {
+	plain := name != "" && !p.opts.Quoted[name]
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			plain = false
+		}
+	}
+	switch {
+	case !plain || token.LookupIdent(strings.ToLower(name)) != token.IDENT:
+		name = `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+	case p.dialect == nil || p.dialect.Identifiers.Normalization != core.NormCaseSensitive:
+		name = p.opts.Identifiers.Apply(name)
 	}
+	p.write(name)
 }
 
 // formatList prints a list of items with separators.
@@ -28,6 +28,7 @@ func (p *Printer) formatSelectStmt(stmt *core.SelectStmt) {
 }
 
 func (p *Printer) formatWithClause(with *core.WithClause) {
+	p.beginAnchor(with, with.Span)
 	p.kw(token.WITH)
 	if with.Recursive {
 		p.space()
@@ -38,7 +39,8 @@ func (p *Printer) formatWithClause(with *core.WithClause) {
 	p.indent()
 	p.formatList(len(with.CTEs), func(i int) {
 		cte := with.CTEs[i]
-		p.write(cte.Name)
+		p.beginAnchor(cte, cte.Span)
+		p.ident(cte.Name)
 		p.space()
 		p.kw(token.AS)
 		p.write(" (")
@@ -49,9 +51,11 @@ func (p *Printer) formatWithClause(with *core.WithClause) {
 		p.dedent()
 
 		p.write(")")
+		p.endAnchor(cte)
 	}, ",", true)
 	p.writeln()
 	p.dedent()
+	p.endAnchor(with)
 }
 
 func (p *Printer) formatSelectBody(body *core.SelectBody) {
@@ -95,6 +99,7 @@ func (p *Printer) formatSelectCore(sc *core.SelectCore) {
 	}
 
 	// SELECT [DISTINCT]
+	p.beginAnchor(sc, sc.Span)
 	p.kw(token.SELECT)
 	if sc.Distinct {
 		p.space()
@@ -104,7 +109,11 @@ func (p *Printer) formatSelectCore(sc *core.SelectCore) {
 
 	// Columns
 	p.indent()
-	p.formatList(len(sc.Columns), func(i int) { p.formatSelectItem(sc.Columns[i]) }, ",", true)
+	p.formatList(len(sc.Columns), func(i int) {
+		p.beginAnchor(&sc.Columns[i], sc.Columns[i].Span)
+		p.formatSelectItem(sc.Columns[i])
+		p.endAnchor(&sc.Columns[i])
+	}, ",", true)
 	p.writeln()
 	p.dedent()
 
@@ -126,6 +135,7 @@ func (p *Printer) formatSelectCore(sc *core.SelectCore) {
 
 		p.formatClause(clauseType, def, sc)
 	}
+	p.endAnchor(sc)
 }
 
 func (p *Printer) formatClause(t token.TokenType, def core.ClauseDef, sc *core.SelectCore) {
@@ -193,6 +203,8 @@ func hasValue(val any) bool {
 		return len(v) > 0
 	case []core.OrderByItem:
 		return len(v) > 0
+	case []core.WindowDef:
+		return len(v) > 0
 	case *core.FetchClause:
 		return v != nil
 	}
@@ -208,7 +220,7 @@ func (p *Printer) getClauseValue(sc *core.SelectCore, slot core.ClauseSlot) any
 	case core.SlotHaving:
 		return sc.Having
 	case core.SlotWindow:
-		return nil // TODO
+		return sc.Windows
 	case core.SlotOrderBy:
 		return sc.OrderBy
 	case core.SlotLimit:
@@ -237,6 +249,16 @@ func (p *Printer) formatSlotValue(slot core.ClauseSlot, val any) {
 		if items, ok := val.([]core.OrderByItem); ok {
 			p.formatList(len(items), func(i int) { p.formatOrderByItem(items[i]) }, ",", true)
 		}
+	case core.SlotWindow:
+		if windows, ok := val.([]core.WindowDef); ok {
+			p.formatList(len(windows), func(i int) {
+				p.ident(windows[i].Name)
+				p.space()
+				p.kw(token.AS)
+				p.space()
+				p.formatWindowBody(windows[i].Spec)
+			}, ",", true)
+		}
 	case core.SlotFetch:
 		if fetch, ok := val.(*core.FetchClause); ok {
 			p.formatFetchClause(fetch)
@@ -262,7 +284,7 @@ func (p *Printer) formatSelectItem(item core.SelectItem) {
 		p.space()
 		p.kw(token.AS)
 		p.space()
-		p.write(item.Alias)
+		p.ident(item.Alias)
 	}
 }
 
@@ -278,7 +300,7 @@ func (p *Printer) formatStarModifiers(mods []core.StarModifier) {
 				if i > 0 {
 					p.write(", ")
 				}
-				p.write(col)
+				p.ident(col)
 			}
 			p.write(")")
 
@@ -294,7 +316,7 @@ func (p *Printer) formatStarModifiers(mods []core.StarModifier) {
 				p.space()
 				p.kw(token.AS)
 				p.space()
-				p.write(item.Alias)
+				p.ident(item.Alias)
 			}
 			p.write(")")
 
@@ -305,11 +327,11 @@ func (p *Printer) formatStarModifiers(mods []core.StarModifier) {
 				if i > 0 {
 					p.write(", ")
 				}
-				p.write(item.OldName)
+				p.ident(item.OldName)
 				p.space()
 				p.kw(token.AS)
 				p.space()
-				p.write(item.NewName)
+				p.ident(item.NewName)
 			}
 			p.write(")")
 		}
@@ -321,11 +343,17 @@ func (p *Printer) formatFromClause(from *core.FromClause) {
 		return
 	}
 
-	p.formatTableRef(from.Source)
+	if from.Source != nil {
+		p.beginAnchor(from.Source, token.Span{Start: from.Source.Pos(), End: from.Source.End()})
+		p.formatTableRef(from.Source)
+		p.endAnchor(from.Source)
+	}
 
 	for _, join := range from.Joins {
 		p.writeln()
+		p.beginAnchor(join, join.Span)
 		p.formatJoin(join)
+		p.endAnchor(join)
 	}
 }
 
@@ -343,6 +371,8 @@ func (p *Printer) formatTableRef(ref core.TableRef) {
 		p.formatLateralTable(t)
 	case *core.MacroTable:
 		p.formatMacroTable(t)
+	case *core.TableFunction:
+		p.formatTableFunction(t)
 	case *core.PivotTable:
 		p.formatPivotTable(t)
 	case *core.UnpivotTable:
@@ -352,17 +382,33 @@ func (p *Printer) formatTableRef(ref core.TableRef) {
 
 func (p *Printer) formatTableName(t *core.TableName) {
 	if t.Catalog != "" {
-		p.write(t.Catalog)
+		p.ident(t.Catalog)
 		p.write(".")
 	}
 	if t.Schema != "" {
-		p.write(t.Schema)
+		p.ident(t.Schema)
 		p.write(".")
 	}
-	p.write(t.Name)
+	p.ident(t.Name)
+	if t.Alias != "" {
+		p.space()
+		p.ident(t.Alias)
+	}
+}
+
+func (p *Printer) formatTableFunction(t *core.TableFunction) {
+	p.write(p.opts.Functions.Apply(t.Name))
+	p.write("(")
+	p.formatList(len(t.Args), func(i int) { p.formatExpr(t.Args[i]) }, ", ", false)
+	p.write(")")
 	if t.Alias != "" {
 		p.space()
-		p.write(t.Alias)
+		p.ident(t.Alias)
+		if len(t.ColumnAliases) > 0 {
+			p.write("(")
+			p.formatList(len(t.ColumnAliases), func(i int) { p.ident(t.ColumnAliases[i]) }, ", ", false)
+			p.write(")")
+		}
 	}
 }
 
@@ -375,7 +421,7 @@ func (p *Printer) formatDerivedTable(t *core.DerivedTable) {
 	p.write(")")
 	if t.Alias != "" {
 		p.space()
-		p.write(t.Alias)
+		p.ident(t.Alias)
 	}
 }
 
@@ -389,7 +435,7 @@ func (p *Printer) formatLateralTable(t *core.LateralTable) {
 	p.write(")")
 	if t.Alias != "" {
 		p.space()
-		p.write(t.Alias)
+		p.ident(t.Alias)
 	}
 }
 
@@ -398,7 +444,7 @@ func (p *Printer) formatMacroTable(t *core.MacroTable) {
 	p.write(t.Content)
 	if t.Alias != "" {
 		p.space()
-		p.write(t.Alias)
+		p.ident(t.Alias)
 	}
 }
 
@@ -422,7 +468,7 @@ func (p *Printer) formatPivotTable(t *core.PivotTable) {
 			p.space()
 			p.kw(token.AS)
 			p.space()
-			p.write(agg.Alias)
+			p.ident(agg.Alias)
 		}
 	}
 
@@ -430,7 +476,7 @@ func (p *Printer) formatPivotTable(t *core.PivotTable) {
 	p.writeln()
 	p.keyword("FOR")
 	p.space()
-	p.write(t.ForColumn)
+	p.ident(t.ForColumn)
 	p.space()
 	p.kw(token.IN)
 	p.space()
@@ -448,7 +494,7 @@ func (p *Printer) formatPivotTable(t *core.PivotTable) {
 				p.space()
 				p.kw(token.AS)
 				p.space()
-				p.write(val.Alias)
+				p.ident(val.Alias)
 			}
 		}
 		p.write(")")
@@ -460,7 +506,7 @@ func (p *Printer) formatPivotTable(t *core.PivotTable) {
 
 	if t.Alias != "" {
 		p.space()
-		p.write(t.Alias)
+		p.ident(t.Alias)
 	}
 }
 
@@ -481,18 +527,18 @@ func (p *Printer) formatUnpivotTable(t *core.UnpivotTable) {
 			if i > 0 {
 				p.write(", ")
 			}
-			p.write(col)
+			p.ident(col)
 		}
 		p.write(")")
 	} else if len(t.ValueColumns) == 1 {
-		p.write(t.ValueColumns[0])
+		p.ident(t.ValueColumns[0])
 	}
 
 	// FOR name_column
 	p.space()
 	p.keyword("FOR")
 	p.space()
-	p.write(t.NameColumn)
+	p.ident(t.NameColumn)
 	p.space()
 	p.kw(token.IN)
 	p.write(" (")
@@ -507,17 +553,17 @@ func (p *Printer) formatUnpivotTable(t *core.UnpivotTable) {
 				if j > 0 {
 					p.write(", ")
 				}
-				p.write(col)
+				p.ident(col)
 			}
 			p.write(")")
 		} else if len(group.Columns) == 1 {
-			p.write(group.Columns[0])
+			p.ident(group.Columns[0])
 		}
 		if group.Alias != "" {
 			p.space()
 			p.kw(token.AS)
 			p.space()
-			p.write(group.Alias)
+			p.ident(group.Alias)
 		}
 	}
 	p.write(")")
@@ -528,7 +574,7 @@ func (p *Printer) formatUnpivotTable(t *core.UnpivotTable) {
 
 	if t.Alias != "" {
 		p.space()
-		p.write(t.Alias)
+		p.ident(t.Alias)
 	}
 }
 
@@ -570,7 +616,7 @@ func (p *Printer) formatJoin(join *core.Join) {
 			if i > 0 {
 				p.write(", ")
 			}
-			p.write(col)
+			p.ident(col)
 		}
 		p.write(")")
 		p.dedent()
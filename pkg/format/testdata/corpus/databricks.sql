@@ -0,0 +1,6 @@
+select id, name from main.default.users where id > 10;
+SELECT a, b, SUM(c) FROM t GROUP BY a, b HAVING SUM(c) > 0 ORDER BY a;
+WITH x AS (SELECT * FROM events WHERE dt = '2024-01-01') SELECT COUNT(*) FROM x;
+SELECT CASE WHEN a THEN 1 ELSE 0 END, COALESCE(b, 0), CAST(c AS STRING) FROM t;
+SELECT * FROM a LEFT ANTI JOIN b ON a.id = b.id;
+SELECT * FROM a LEFT SEMI JOIN b ON a.id = b.id
@@ -0,0 +1,31 @@
+select id,   sum(val) from   my_table where active=true;
+SELECT a AS col1, b col2, t.* FROM t;
+SELECT DISTINCT region FROM sales ORDER BY region DESC NULLS LAST LIMIT 10 OFFSET 5;
+-- Leading comment
+SELECT id, -- the key
+  name /* inline */ FROM users -- trailing
+WHERE id > 0;
+WITH recent AS (SELECT * FROM orders WHERE created_at > '2024-01-01'), totals AS (SELECT customer_id, SUM(amount) AS total FROM recent GROUP BY customer_id) SELECT c.name, t.total FROM customers c LEFT JOIN totals t ON c.id = t.customer_id AND t.total > 0;
+SELECT category, region, SUM(sales) FROM orders GROUP BY ALL HAVING COUNT(*) > 10 ORDER BY ALL;
+SELECT category, SUM(sales), ROW_NUMBER() OVER (PARTITION BY region ORDER BY SUM(sales) DESC) AS rn FROM orders GROUP BY ALL QUALIFY rn <= 3;
+SELECT CASE WHEN x > 0 THEN 'pos' WHEN x < 0 THEN 'neg' ELSE 'zero' END AS sign, CASE status WHEN 1 THEN 'a' ELSE 'b' END FROM t;
+SELECT CAST(a AS INTEGER), b::VARCHAR, CAST(c AS DATE) FROM t;
+SELECT * FROM a UNION ALL SELECT * FROM b EXCEPT SELECT * FROM c;
+SELECT * FROM ( -- outer
+  SELECT id FROM (SELECT id FROM t -- innermost
+ WHERE id IN (1, 2, 3)) inner_q) outer_q;
+SELECT id FROM t WHERE EXISTS (SELECT 1 FROM u WHERE u.id = t.id) AND name NOT LIKE 'a%' AND x BETWEEN 1 AND 10 AND y IS NOT NULL;
+SELECT * EXCLUDE (secret) FROM users;
+SELECT * REPLACE (lower(email) AS email) FROM users;
+SELECT * FROM t1 CROSS JOIN t2 FULL OUTER JOIN t3 USING (id) INNER JOIN t4 ON t4.id = t3.id;
+SELECT COUNT(DISTINCT user_id) FILTER (WHERE active) AS active_users, list(x) FROM events;
+SELECT a, SUM(b) OVER (ORDER BY a ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) FROM t;
+SELECT "Quoted Column", "select" FROM "My Table";
+SELECT 'it''s', -x, NOT y, a || b, 1 + 2 * 3, (1 + 2) * 3, -1.5e3 FROM t;
+SELECT * FROM {{ ref('stg_users') }} u JOIN {{ ref('stg_orders') }} o ON u.id = o.user_id;
+SELECT * FROM t WHERE x > ANY (SELECT y FROM u);
+SELECT [1, 2, 3] AS arr, {'a': 1} AS st, arr[1] FROM t;
+SELECT * FROM read_csv('data.csv', header = true);
+SELECT * FROM sales PIVOT (SUM(amount) FOR quarter IN ('Q1', 'Q2'));
+SELECT DATE '2024-01-01', TIMESTAMP '2024-01-01 00:00:00', NULL, TRUE, FALSE;
+SELECT x FROM t ORDER BY x LIMIT 5
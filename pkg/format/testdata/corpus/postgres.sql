@@ -0,0 +1,11 @@
+select id, name from users where email ilike '%@example.com' order by id;
+SELECT a::int, b::text[], CAST(c AS numeric(10, 2)) FROM t;
+WITH RECURSIVE tree AS ( -- roots first
+SELECT id, parent_id FROM nodes WHERE parent_id IS NULL UNION ALL SELECT n.id, n.parent_id FROM nodes n JOIN tree ON n.parent_id = tree.id) SELECT * FROM tree;
+SELECT DISTINCT customer_id, created_at FROM orders ORDER BY customer_id, created_at DESC;
+SELECT count(*) FILTER (WHERE status = 'open'), string_agg(name, ', ') FROM tickets GROUP BY team_id HAVING count(*) > 1;
+SELECT * FROM a LEFT JOIN LATERAL (SELECT * FROM b WHERE b.a_id = a.id LIMIT 1) b ON TRUE;
+SELECT x FROM t WHERE x <> y AND z NOT IN (SELECT z FROM u);
+SELECT rank() OVER w FROM t WINDOW w AS (PARTITION BY a ORDER BY b);
+-- comment before
+SELECT 1 AS one
@@ -0,0 +1,6 @@
+select id, name from analytics.public.users where created_at >= dateadd(day, -7, current_date());
+SELECT a, b FROM t QUALIFY ROW_NUMBER() OVER (PARTITION BY a ORDER BY b DESC) = 1;
+SELECT IFF(x > 0, 'pos', 'neg'), ZEROIFNULL(y), TRY_TO_NUMBER(z) FROM t;
+WITH base AS (SELECT * FROM raw.events) SELECT event_type, COUNT(*) AS n FROM base GROUP BY event_type ORDER BY n DESC LIMIT 100;
+SELECT t.value FROM src, LATERAL (SELECT value FROM items WHERE items.src_id = src.id) t;
+SELECT * FROM a JOIN b ON a.id = b.id WHERE a.x ILIKE 'foo%'
@@ -1,11 +1,20 @@
-// Package lineage provides domain-specific SQL lineage extraction.
+// Package lineage provides SQL lineage extraction on top of pkg/parser.
 //
-// This package uses the sql parsing toolkit from pkg/sql to analyze SQL
-// statements and extract data lineage information, including table-level
-// and column-level dependencies.
+// It analyzes a single SELECT statement and reports the tables it reads
+// from and, for each output column, the source columns it derives from.
 //
-// This is an internal package designed for use within the LeapSQL project.
-// External consumers should use pkg/sql directly for SQL parsing needs.
+// # Stability
+//
+// This package is part of the public API and follows semantic versioning.
+// The stable surface is:
+//
+//   - ExtractLineageWithOptions, ExtractLineageOptions and Schema
+//   - ModelLineage and ColumnLineage
+//   - core.TransformType values (core.TransformDirect, core.TransformExpression)
+//     reported in ColumnLineage.Transform
+//
+// Scope resolution and name inference are internal to the package and may
+// change between minor releases.
 //
 // # Features
 //
@@ -18,7 +27,7 @@
 //	import _ "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
 //
 //	duckdb, _ := dialect.Get("duckdb")
-//	schema := parser.Schema{
+//	schema := lineage.Schema{
 //	    "users": []string{"id", "name", "email"},
 //	}
 //
@@ -1,6 +1,9 @@
-package parser
+package resolve
 
 import (
+	"fmt"
+	"slices"
+
 	"github.com/leapstack-labs/leapsql/pkg/core"
 )
 
@@ -13,6 +16,9 @@ type Resolver struct {
 	dialect *core.Dialect
 	schema  Schema
 	errors  []error //nolint:unused // Reserved for future error collection
+
+	warnings []string // Non-fatal diagnostics collected during resolution
+	files    []string // Files read by table functions, in order of appearance
 }
 
 // NewResolver creates a new resolver with the given dialect and schema.
@@ -53,10 +59,30 @@ func (r *Resolver) Resolve(stmt *core.SelectStmt) (*Scope, error) {
 	return scope, nil
 }
 
+// Warnings returns non-fatal diagnostics collected by Resolve, such as
+// recursive CTEs without an anchor member.
+func (r *Resolver) Warnings() []string {
+	return r.warnings
+}
+
+// Files returns the paths of the files read by table functions in the
+// statement resolved by Resolve, e.g. read_parquet('orders.parquet'),
+// deduplicated.
+func (r *Resolver) Files() []string {
+	return r.files
+}
+
 // resolveCTEs resolves all CTEs in a WITH clause.
 // CTEs can reference previously defined CTEs (forward references not allowed).
 func (r *Resolver) resolveCTEs(scope *Scope, with *core.WithClause) error {
 	for _, cte := range with.CTEs {
+		if with.Recursive && cte.Select != nil && r.isSelfReferencing(cte) {
+			if err := r.resolveRecursiveCTE(scope, cte); err != nil {
+				return err
+			}
+			continue
+		}
+
 		// Create a child scope for the CTE that can see previously defined CTEs
 		cteScope := scope.Child()
 
@@ -87,6 +113,132 @@ func (r *Resolver) resolveCTEs(scope *Scope, with *core.WithClause) error {
 	return nil
 }
 
+// resolveRecursiveCTE resolves a WITH RECURSIVE CTE that references itself.
+//
+// The anchor members are resolved first and the CTE is registered with their
+// columns, so self-references in the recursive members resolve to the CTE
+// rather than to a physical table. Each member keeps its own scope for column
+// lineage; the CTE's underlying sources are the union over all members.
+// Self-references contribute the anchor's sources and are never re-expanded.
+func (r *Resolver) resolveRecursiveCTE(scope *Scope, cte *core.CTE) error {
+	rec := &RecursiveCTE{Name: cte.Name}
+
+	// Nested WITH clauses are visible to every member
+	withScope := scope.Child()
+	if cte.Select.With != nil {
+		if err := r.resolveCTEs(withScope, cte.Select.With); err != nil {
+			return err
+		}
+	}
+
+	var anchors, recursive []*core.SelectCore
+	for body := cte.Select.Body; body != nil; body = body.Right {
+		if body.Left == nil {
+			continue
+		}
+		if r.referencesTable(body.Left, cte.Name) {
+			recursive = append(recursive, body.Left)
+		} else {
+			anchors = append(anchors, body.Left)
+		}
+	}
+	if len(anchors) == 0 {
+		r.warnings = append(r.warnings, fmt.Sprintf("recursive CTE %q has no anchor member; its columns are unknown", cte.Name))
+	}
+
+	var columns, sources []string
+	for i, sc := range anchors {
+		memberScope := withScope.Child()
+		if err := r.resolveSelectCore(memberScope, sc); err != nil {
+			return err
+		}
+		if i == 0 {
+			columns = r.extractSelectColumns(memberScope, &core.SelectBody{Left: sc})
+		}
+		sources = mergeNames(sources, r.collectUnderlyingSources(memberScope))
+		rec.Members = append(rec.Members, CTEMember{Select: sc, Scope: memberScope})
+	}
+
+	// Register the anchor so the recursive members can see the CTE
+	withScope.RegisterRecursiveCTE(rec, columns, sources)
+
+	for _, sc := range recursive {
+		memberScope := withScope.Child()
+		if err := r.resolveSelectCore(memberScope, sc); err != nil {
+			return err
+		}
+		sources = mergeNames(sources, r.collectUnderlyingSources(memberScope))
+		rec.Members = append(rec.Members, CTEMember{Select: sc, Scope: memberScope, Recursive: true})
+	}
+
+	scope.RegisterRecursiveCTE(rec, columns, sources)
+	return nil
+}
+
+// isSelfReferencing reports whether any member of a CTE reads from the CTE itself.
+func (r *Resolver) isSelfReferencing(cte *core.CTE) bool {
+	for body := cte.Select.Body; body != nil; body = body.Right {
+		if body.Left != nil && r.referencesTable(body.Left, cte.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesTable reports whether a SELECT core reads from the named table
+// in its FROM clause, including through joins, derived tables, and PIVOT sources.
+func (r *Resolver) referencesTable(sc *core.SelectCore, name string) bool {
+	if sc == nil || sc.From == nil {
+		return false
+	}
+	if r.tableRefReferences(sc.From.Source, name) {
+		return true
+	}
+	for _, join := range sc.From.Joins {
+		if r.tableRefReferences(join.Right, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// tableRefReferences reports whether a table reference reads from the named table.
+func (r *Resolver) tableRefReferences(ref core.TableRef, name string) bool {
+	switch t := ref.(type) {
+	case *core.TableName:
+		return t.Schema == "" && r.dialect.NormalizeName(t.Name) == r.dialect.NormalizeName(name)
+	case *core.DerivedTable:
+		return t.Select != nil && r.bodyReferences(t.Select.Body, name)
+	case *core.LateralTable:
+		return t.Select != nil && r.bodyReferences(t.Select.Body, name)
+	case *core.PivotTable:
+		return r.tableRefReferences(t.Source, name)
+	case *core.UnpivotTable:
+		return r.tableRefReferences(t.Source, name)
+	}
+	return false
+}
+
+// bodyReferences reports whether any member of a SELECT body reads from the named table.
+func (r *Resolver) bodyReferences(body *core.SelectBody, name string) bool {
+	for ; body != nil; body = body.Right {
+		if r.referencesTable(body.Left, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeNames appends names not already present in dst.
+func mergeNames(dst, names []string) []string {
+	for _, name := range names {
+		if !slices.Contains(dst, name) {
+			dst = append(dst, name)
+		}
+	}
+	return dst
+}
+
 // collectUnderlyingSources collects all physical table sources from a scope.
 // It traces through CTEs and derived tables to find the underlying physical tables.
 func (r *Resolver) collectUnderlyingSources(scope *Scope) []string {
@@ -105,8 +257,17 @@ func (r *Resolver) collectUnderlyingSources(scope *Scope) []string {
 				seen[tableName] = struct{}{}
 				sources = append(sources, tableName)
 			}
-		case ScopeCTE, ScopeDerived:
-			// CTE or derived table - trace through to underlying sources
+		case ScopeTableFunction:
+			// File read by a table function (read_parquet('orders.parquet'))
+			if entry.SourceTable == "" {
+				continue
+			}
+			if _, ok := seen[entry.SourceTable]; !ok {
+				seen[entry.SourceTable] = struct{}{}
+				sources = append(sources, entry.SourceTable)
+			}
+		case ScopeCTE, ScopeDerived, ScopePivot:
+			// CTE, derived table, or PIVOT/UNPIVOT - trace through to underlying sources
 			for _, underlying := range entry.UnderlyingSources {
 				if _, ok := seen[underlying]; !ok {
 					seen[underlying] = struct{}{}
@@ -197,6 +358,7 @@ func (r *Resolver) resolveTableRef(scope *Scope, ref core.TableRef) error {
 				Alias:             t.Alias,
 				Columns:           cte.Columns,
 				UnderlyingSources: cte.UnderlyingSources,
+				Recursive:         cte.Recursive,
 			}
 			normalized := scope.normalize(entry.EffectiveName())
 			scope.entries[normalized] = entry
@@ -205,6 +367,28 @@ func (r *Resolver) resolveTableRef(scope *Scope, ref core.TableRef) error {
 			scope.RegisterTable(t)
 		}
 
+	case *core.TableFunction:
+		// Table-valued function - its arguments reference tables already in scope
+		scope.RegisterTableFunction(t)
+		if path := scope.filePath(t); path != "" && !slices.Contains(r.files, path) {
+			r.files = append(r.files, path)
+		}
+
+	case *core.PivotTable:
+		// PIVOT/UNPIVOT - resolve the transformed source in its own scope
+		inner := scope.Child()
+		if err := r.resolveTableRef(inner, t.Source); err != nil {
+			return err
+		}
+		scope.RegisterPivot(t, inner)
+
+	case *core.UnpivotTable:
+		inner := scope.Child()
+		if err := r.resolveTableRef(inner, t.Source); err != nil {
+			return err
+		}
+		scope.RegisterPivot(t, inner)
+
 	case *core.DerivedTable:
 		// Derived table (subquery in FROM)
 		subScope := scope.Child()
@@ -307,6 +491,10 @@ func (r *Resolver) extractColumnName(_ *Scope, item core.SelectItem, index int)
 func (r *Resolver) inferColumnName(expr core.Expr, index int) string {
 	switch e := expr.(type) {
 	case *core.ColumnRef:
+		// Struct field access is named after the innermost field
+		if len(e.Fields) > 0 {
+			return e.Fields[len(e.Fields)-1]
+		}
 		return e.Column
 
 	case *core.FuncCall:
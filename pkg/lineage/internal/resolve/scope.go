@@ -0,0 +1,616 @@
+// Package resolve resolves the tables, CTEs and columns a SELECT statement
+// references through nested scopes, for lineage extraction.
+package resolve
+
+import (
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"strings"
+)
+
+// Schema maps table names to their columns.
+// Used for SELECT * expansion when schema information is available.
+type Schema map[string][]string
+
+// ScopeType indicates the type of scope entry.
+type ScopeType int
+
+const (
+	// ScopeTable represents a physical table.
+	ScopeTable ScopeType = iota
+	// ScopeCTE represents a Common Table Expression.
+	ScopeCTE
+	// ScopeDerived represents a derived table (subquery in FROM).
+	ScopeDerived
+	// ScopeTableFunction represents a table-valued function in FROM (UNNEST, generate_series).
+	ScopeTableFunction
+	// ScopePivot represents a PIVOT or UNPIVOT applied to a FROM item.
+	ScopePivot
+)
+
+// ScopeEntry represents a table/CTE/derived table in scope.
+type ScopeEntry struct {
+	Type              ScopeType
+	Name              string              // Original table/CTE name
+	Alias             string              // Alias (if any)
+	Columns           []string            // Known columns (from schema or derived query)
+	SourceTable       string              // For physical tables: fully qualified name (schema.table); for file functions: the file path
+	UnderlyingSources []string            // For CTEs/derived tables: underlying physical tables
+	Function          *core.TableFunction // For table functions: the call, whose arguments feed its columns
+	Pivot             []PivotColumn       // For PIVOT/UNPIVOT: generated output columns
+	Inner             *Scope              // For PIVOT/UNPIVOT: scope holding the transformed FROM item
+	Recursive         *RecursiveCTE       // For WITH RECURSIVE CTEs that reference themselves
+}
+
+// RecursiveCTE describes a WITH RECURSIVE CTE that references itself.
+// Every scope entry for the CTE, including the self-references inside its
+// recursive members, shares the same RecursiveCTE.
+type RecursiveCTE struct {
+	Name    string
+	Members []CTEMember // Anchor members first, then recursive members
+}
+
+// CTEMember is one SELECT of a recursive CTE's set operation.
+type CTEMember struct {
+	Select    *core.SelectCore
+	Scope     *Scope // Scope the member's FROM clause was resolved into
+	Recursive bool   // true if the member references the CTE itself
+}
+
+// EffectiveName returns the name used to reference this entry (alias if present, else name).
+func (e *ScopeEntry) EffectiveName() string {
+	if e.Alias != "" {
+		return e.Alias
+	}
+	return e.Name
+}
+
+// Scope tracks all available tables, CTEs, and their columns within a query context.
+type Scope struct {
+	parent  *Scope                 // Parent scope (for nested queries)
+	entries map[string]*ScopeEntry // Name/alias -> entry (normalized to lowercase)
+	dialect *core.Dialect          // For name normalization
+	schema  Schema                 // External schema information
+}
+
+// NewScope creates a new root scope.
+// Returns an error if dialect is nil.
+func NewScope(d *core.Dialect, schema Schema) (*Scope, error) {
+	if d == nil {
+		return nil, core.ErrDialectRequired
+	}
+	return &Scope{
+		entries: make(map[string]*ScopeEntry),
+		dialect: d,
+		schema:  schema,
+	}, nil
+}
+
+// Child creates a child scope for nested queries (subqueries, derived tables).
+func (s *Scope) Child() *Scope {
+	return &Scope{
+		parent:  s,
+		entries: make(map[string]*ScopeEntry),
+		dialect: s.dialect,
+		schema:  s.schema,
+	}
+}
+
+// normalize normalizes an identifier according to dialect rules.
+func (s *Scope) normalize(name string) string {
+	return s.dialect.NormalizeName(name)
+}
+
+// RegisterCTE registers a CTE with its resolved columns.
+func (s *Scope) RegisterCTE(name string, columns []string) {
+	normalized := s.normalize(name)
+	s.entries[normalized] = &ScopeEntry{
+		Type:    ScopeCTE,
+		Name:    name,
+		Columns: columns,
+	}
+}
+
+// RegisterCTEWithSources registers a CTE with its resolved columns and underlying sources.
+func (s *Scope) RegisterCTEWithSources(name string, columns []string, underlyingSources []string) {
+	normalized := s.normalize(name)
+	s.entries[normalized] = &ScopeEntry{
+		Type:              ScopeCTE,
+		Name:              name,
+		Columns:           columns,
+		UnderlyingSources: underlyingSources,
+	}
+}
+
+// RegisterRecursiveCTE registers a recursive CTE with its anchor columns and underlying sources.
+func (s *Scope) RegisterRecursiveCTE(rec *RecursiveCTE, columns []string, underlyingSources []string) {
+	normalized := s.normalize(rec.Name)
+	s.entries[normalized] = &ScopeEntry{
+		Type:              ScopeCTE,
+		Name:              rec.Name,
+		Columns:           columns,
+		UnderlyingSources: underlyingSources,
+		Recursive:         rec,
+	}
+}
+
+// RegisterTable registers a physical table from a FROM clause.
+func (s *Scope) RegisterTable(table *core.TableName) {
+	entry := &ScopeEntry{
+		Type: ScopeTable,
+		Name: table.Name,
+	}
+
+	// Build fully qualified source name
+	var parts []string
+	if table.Catalog != "" {
+		parts = append(parts, table.Catalog)
+	}
+	if table.Schema != "" {
+		parts = append(parts, table.Schema)
+	}
+	parts = append(parts, table.Name)
+	entry.SourceTable = strings.Join(parts, ".")
+
+	if table.Alias != "" {
+		entry.Alias = table.Alias
+	}
+
+	// Try to get columns from schema
+	if s.schema != nil {
+		// Try various forms of the table name
+		for _, key := range []string{
+			entry.SourceTable,
+			table.Name,
+			s.normalize(entry.SourceTable),
+			s.normalize(table.Name),
+		} {
+			if cols, ok := s.schema[key]; ok {
+				entry.Columns = cols
+				break
+			}
+		}
+	}
+
+	// Register by effective name (alias or table name)
+	normalized := s.normalize(entry.EffectiveName())
+	s.entries[normalized] = entry
+}
+
+// RegisterDerived registers a derived table (subquery in FROM).
+func (s *Scope) RegisterDerived(alias string, columns []string) {
+	normalized := s.normalize(alias)
+	s.entries[normalized] = &ScopeEntry{
+		Type:    ScopeDerived,
+		Name:    alias,
+		Alias:   alias,
+		Columns: columns,
+	}
+}
+
+// RegisterDerivedWithSources registers a derived table with its underlying sources.
+func (s *Scope) RegisterDerivedWithSources(alias string, columns []string, underlyingSources []string) {
+	normalized := s.normalize(alias)
+	s.entries[normalized] = &ScopeEntry{
+		Type:              ScopeDerived,
+		Name:              alias,
+		Alias:             alias,
+		Columns:           columns,
+		UnderlyingSources: underlyingSources,
+	}
+}
+
+// RegisterTableFunction registers a table-valued function from a FROM clause.
+// Columns come from the column alias list when present; otherwise the
+// function produces a single column named after the function. Functions
+// reading files, like read_parquet('orders.parquet'), record the file path
+// as their source table.
+func (s *Scope) RegisterTableFunction(fn *core.TableFunction) {
+	name := s.normalize(fn.Name)
+	entry := &ScopeEntry{
+		Type:        ScopeTableFunction,
+		Name:        name,
+		Alias:       fn.Alias,
+		Columns:     fn.ColumnAliases,
+		SourceTable: s.filePath(fn),
+		Function:    fn,
+	}
+	if len(entry.Columns) == 0 {
+		entry.Columns = []string{name}
+	}
+	s.entries[s.normalize(entry.EffectiveName())] = entry
+}
+
+// filePath returns the file path a table function reads, or "" if it does
+// not read files or its path is not a string literal.
+func (s *Scope) filePath(fn *core.TableFunction) string {
+	if !s.dialect.IsFileFunction(fn.Name) || len(fn.Args) == 0 {
+		return ""
+	}
+	lit, ok := fn.Args[0].(*core.Literal)
+	if !ok || lit.Type != core.LiteralString {
+		return ""
+	}
+	return lit.Value
+}
+
+// Lookup finds a scope entry by name (table name or alias).
+// Searches current scope first, then parent scopes.
+func (s *Scope) Lookup(name string) (*ScopeEntry, bool) {
+	normalized := s.normalize(name)
+
+	if entry, ok := s.entries[normalized]; ok {
+		return entry, true
+	}
+
+	// Check parent scope (for correlated subqueries, LATERAL, etc.)
+	if s.parent != nil {
+		return s.parent.Lookup(name)
+	}
+
+	return nil, false
+}
+
+// LookupCTE looks up a CTE by name.
+// CTEs are only looked up in parent scopes (they're defined before the main query).
+func (s *Scope) LookupCTE(name string) (*ScopeEntry, bool) {
+	normalized := s.normalize(name)
+
+	if entry, ok := s.entries[normalized]; ok && entry.Type == ScopeCTE {
+		return entry, true
+	}
+
+	if s.parent != nil {
+		return s.parent.LookupCTE(name)
+	}
+
+	return nil, false
+}
+
+// AllEntries returns all scope entries in the current scope (not including parent).
+func (s *Scope) AllEntries() []*ScopeEntry {
+	entries := make([]*ScopeEntry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// ResolveColumn attempts to resolve a column reference to its source table.
+// Returns the scope entry and true if found, nil and false otherwise.
+//
+// For unqualified columns, it searches all entries in scope.
+// For qualified columns (table.column), it looks up by qualifier.
+func (s *Scope) ResolveColumn(ref *core.ColumnRef) (*ScopeEntry, bool) {
+	if ref.Table != "" {
+		// Qualified reference - lookup by table name/alias
+		return s.Lookup(ref.Table)
+	}
+
+	// Unqualified - search all entries by column name
+	// Return first match (ambiguous references would need more complex handling)
+	for _, entry := range s.entries {
+		for _, col := range entry.Columns {
+			if s.normalize(col) == s.normalize(ref.Column) {
+				return entry, true
+			}
+		}
+	}
+
+	// No column match found - try single-table inference
+	// If there's exactly one physical table (or PIVOT/UNPIVOT over one, or a
+	// file read by a table function) in scope with no schema info, assume
+	// unqualified columns belong to it (common for raw/seed tables)
+	var singleTable *ScopeEntry
+	tableCount := 0
+	for _, entry := range s.entries {
+		if entry.Type == ScopeTable || entry.Type == ScopePivot || (entry.Type == ScopeTableFunction && entry.SourceTable != "") {
+			tableCount++
+			singleTable = entry
+		}
+	}
+	if tableCount == 1 && singleTable != nil {
+		return singleTable, true
+	}
+
+	// Check parent scope
+	if s.parent != nil {
+		return s.parent.ResolveColumn(ref)
+	}
+
+	// Column not found in any known schema - this is OK for tables without schema info
+	return nil, false
+}
+
+// ExpandStar expands a SELECT * to column references.
+// If tableName is empty, expands * for all tables in scope.
+// If tableName is provided, expands only for that table.
+//
+// Returns nil if the table is not found or has no known columns.
+func (s *Scope) ExpandStar(tableName string) []*core.ColumnRef {
+	if tableName != "" {
+		// Expand table.*; schema.table.* and catalog.schema.table.* name the table last
+		if i := strings.LastIndex(tableName, "."); i >= 0 {
+			tableName = tableName[i+1:]
+		}
+		entry, ok := s.Lookup(tableName)
+		if !ok || len(entry.Columns) == 0 {
+			return nil
+		}
+
+		refs := make([]*core.ColumnRef, len(entry.Columns))
+		for i, col := range entry.Columns {
+			refs[i] = &core.ColumnRef{
+				Table:  entry.EffectiveName(),
+				Column: col,
+			}
+		}
+		return refs
+	}
+
+	// Expand * for all tables
+	var refs []*core.ColumnRef
+	for _, entry := range s.entries {
+		for _, col := range entry.Columns {
+			refs = append(refs, &core.ColumnRef{
+				Table:  entry.EffectiveName(),
+				Column: col,
+			})
+		}
+	}
+	return refs
+}
+
+// HasSchemaInfo returns true if the scope has column information for any table.
+func (s *Scope) HasSchemaInfo() bool {
+	for _, entry := range s.entries {
+		if len(entry.Columns) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ColumnSource represents a resolved source for a column reference.
+type ColumnSource struct {
+	Table       string // Source table name
+	SourceTable string // Fully qualified source (e.g., schema.table)
+	Column      string // Column name
+	FromCTE     bool   // True if from a CTE
+	FromDerived bool   // True if from a derived table
+}
+
+// ResolveColumnFull resolves a column reference and returns full source information.
+func (s *Scope) ResolveColumnFull(ref *core.ColumnRef) (*ColumnSource, bool) {
+	entry, ok := s.ResolveColumn(ref)
+	if !ok {
+		// No schema info - return a best-effort resolution
+		if ref.Table != "" {
+			return &ColumnSource{
+				Table:  ref.Table,
+				Column: ref.Column,
+			}, true
+		}
+		// Unqualified column with no schema - can't resolve
+		return nil, false
+	}
+
+	source := &ColumnSource{
+		Table:       entry.EffectiveName(),
+		Column:      ref.Column,
+		FromCTE:     entry.Type == ScopeCTE,
+		FromDerived: entry.Type == ScopeDerived,
+	}
+
+	if entry.SourceTable != "" {
+		source.SourceTable = entry.SourceTable
+	} else {
+		source.SourceTable = entry.Name
+	}
+
+	return source, true
+}
+
+// PivotColumnKind classifies an output column of a PIVOT or UNPIVOT.
+type PivotColumnKind int
+
+const (
+	// PivotGroup is a source column passed through unchanged (PIVOT grouping
+	// columns, UNPIVOT columns not listed in IN).
+	PivotGroup PivotColumnKind = iota
+	// PivotValue is a column produced by a PIVOT aggregate for one IN value.
+	PivotValue
+	// UnpivotName is the UNPIVOT column holding the unpivoted column names.
+	UnpivotName
+	// UnpivotValue is an UNPIVOT column holding values from the IN columns.
+	UnpivotValue
+)
+
+// PivotColumn describes a column produced by PIVOT or UNPIVOT.
+type PivotColumn struct {
+	Name      string
+	Kind      PivotColumnKind
+	Aggregate *core.FuncCall // PivotValue: the aggregate computing the column
+	Inputs    []string       // Source columns feeding this column
+}
+
+// RegisterPivot registers a PIVOT or UNPIVOT FROM item. inner holds the
+// transformed source; its known columns determine the passthrough columns.
+func (s *Scope) RegisterPivot(ref core.TableRef, inner *Scope) {
+	var (
+		alias   string
+		source  core.TableRef
+		columns []PivotColumn
+	)
+
+	var sourceCols []string
+	for _, entry := range inner.AllEntries() {
+		sourceCols = append(sourceCols, entry.Columns...)
+	}
+
+	switch t := ref.(type) {
+	case *core.PivotTable:
+		alias, source = t.Alias, t.Source
+		columns = s.pivotColumns(t, sourceCols)
+	case *core.UnpivotTable:
+		alias, source = t.Alias, t.Source
+		columns = s.unpivotColumns(t, sourceCols)
+	default:
+		return
+	}
+
+	entry := &ScopeEntry{
+		Type:  ScopePivot,
+		Name:  tableRefName(source),
+		Alias: alias,
+		Pivot: columns,
+		Inner: inner,
+	}
+	for _, col := range columns {
+		entry.Columns = append(entry.Columns, col.Name)
+	}
+	for _, underlying := range inner.AllEntries() {
+		switch {
+		case underlying.Type == ScopeTable:
+			entry.UnderlyingSources = append(entry.UnderlyingSources, underlying.SourceTable)
+		case underlying.Type == ScopeTableFunction && underlying.SourceTable != "":
+			entry.UnderlyingSources = append(entry.UnderlyingSources, underlying.SourceTable)
+		default:
+			entry.UnderlyingSources = append(entry.UnderlyingSources, underlying.UnderlyingSources...)
+		}
+	}
+
+	s.entries[s.normalize(entry.EffectiveName())] = entry
+}
+
+// pivotColumns computes PIVOT output columns: source columns not consumed by
+// the pivot, then one column per IN value and aggregate. Naming follows DuckDB:
+// the IN value, suffixed with _alias for aliased or multiple aggregates.
+func (s *Scope) pivotColumns(t *core.PivotTable, sourceCols []string) []PivotColumn {
+	consumed := map[string]struct{}{s.normalize(t.ForColumn): {}}
+	aggInputs := make([][]string, len(t.Aggregates))
+	for i, agg := range t.Aggregates {
+		for _, arg := range agg.Func.Args {
+			for _, ref := range collectColumnRefs(arg) {
+				aggInputs[i] = append(aggInputs[i], ref.Column)
+				consumed[s.normalize(ref.Column)] = struct{}{}
+			}
+		}
+	}
+
+	var columns []PivotColumn
+	for _, col := range sourceCols {
+		if _, ok := consumed[s.normalize(col)]; ok {
+			continue
+		}
+		columns = append(columns, PivotColumn{Name: col, Kind: PivotGroup, Inputs: []string{col}})
+	}
+
+	for _, val := range t.InValues {
+		valueName := pivotValueName(val)
+		for i, agg := range t.Aggregates {
+			name := valueName
+			switch {
+			case agg.Alias != "":
+				name += "_" + agg.Alias
+			case len(t.Aggregates) > 1:
+				name += "_" + strings.ToLower(agg.Func.Name)
+			}
+			inputs := append(append([]string{}, aggInputs[i]...), t.ForColumn)
+			columns = append(columns, PivotColumn{Name: name, Kind: PivotValue, Aggregate: agg.Func, Inputs: inputs})
+		}
+	}
+
+	return columns
+}
+
+// unpivotColumns computes UNPIVOT output columns: source columns not listed in
+// IN, the name column, then the value columns.
+func (s *Scope) unpivotColumns(t *core.UnpivotTable, sourceCols []string) []PivotColumn {
+	consumed := make(map[string]struct{})
+	valueInputs := make([][]string, len(t.ValueColumns))
+	for _, group := range t.InColumns {
+		for i, col := range group.Columns {
+			consumed[s.normalize(col)] = struct{}{}
+			if i < len(valueInputs) {
+				valueInputs[i] = append(valueInputs[i], col)
+			}
+		}
+	}
+
+	var columns []PivotColumn
+	for _, col := range sourceCols {
+		if _, ok := consumed[s.normalize(col)]; ok {
+			continue
+		}
+		columns = append(columns, PivotColumn{Name: col, Kind: PivotGroup, Inputs: []string{col}})
+	}
+
+	columns = append(columns, PivotColumn{Name: t.NameColumn, Kind: UnpivotName})
+	for i, name := range t.ValueColumns {
+		columns = append(columns, PivotColumn{Name: name, Kind: UnpivotValue, Inputs: valueInputs[i]})
+	}
+
+	return columns
+}
+
+// LookupPivotColumn finds a generated PIVOT/UNPIVOT column by name.
+// Columns not generated by the pivot pass through from the source unchanged.
+func (s *Scope) LookupPivotColumn(entry *ScopeEntry, name string) PivotColumn {
+	for _, col := range entry.Pivot {
+		if s.normalize(col.Name) == s.normalize(name) {
+			return col
+		}
+	}
+	return PivotColumn{Name: name, Kind: PivotGroup, Inputs: []string{name}}
+}
+
+// pivotValueName returns the column name generated for a PIVOT IN value.
+func pivotValueName(val core.PivotInValue) string {
+	if val.Alias != "" {
+		return val.Alias
+	}
+	switch v := val.Value.(type) {
+	case *core.Literal:
+		return v.Value
+	case *core.ColumnRef:
+		return v.Column
+	}
+	return "value"
+}
+
+// tableRefName returns the name a FROM item is referenced by.
+func tableRefName(ref core.TableRef) string {
+	switch t := ref.(type) {
+	case *core.TableName:
+		if t.Alias != "" {
+			return t.Alias
+		}
+		return t.Name
+	case *core.DerivedTable:
+		return t.Alias
+	case *core.LateralTable:
+		return t.Alias
+	case *core.TableFunction:
+		if t.Alias != "" {
+			return t.Alias
+		}
+		return t.Name
+	case *core.PivotTable:
+		if t.Alias != "" {
+			return t.Alias
+		}
+		return tableRefName(t.Source)
+	case *core.UnpivotTable:
+		if t.Alias != "" {
+			return t.Alias
+		}
+		return tableRefName(t.Source)
+	}
+	return ""
+}
+
+// collectColumnRefs returns the column references in an expression.
+func collectColumnRefs(expr core.Expr) []*core.ColumnRef {
+	cr := &ColumnResolver{}
+	return cr.CollectColumns(expr)
+}
@@ -1,11 +1,15 @@
 package lineage
 
 import (
+	"fmt"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lineage/internal/resolve"
 	"github.com/leapstack-labs/leapsql/pkg/parser"
+	"github.com/leapstack-labs/leapsql/pkg/token"
 )
 
 // ColumnLineage describes the lineage of a single output column.
@@ -19,14 +23,29 @@ type ColumnLineage struct {
 // ModelLineage describes the complete lineage of a SQL model.
 type ModelLineage struct {
 	Sources        []string         // All source tables (deduplicated, sorted)
+	Files          []string         // Sources that are files read by table functions (sorted)
 	Columns        []*ColumnLineage // Lineage for each output column
 	UsesSelectStar bool             // true if SELECT * or t.* detected
+	Warnings       []string         // Non-fatal diagnostics (e.g., recursion limit reached)
 }
 
+// Schema maps table names to their columns.
+// Used for SELECT * expansion when schema information is available.
+type Schema map[string][]string
+
+// defaultMaxRecursionDepth is the number of recursive CTE iterations
+// evaluated when ExtractLineageOptions.MaxRecursionDepth is not set.
+const defaultMaxRecursionDepth = 16
+
 // ExtractLineageOptions configures the lineage extraction.
 type ExtractLineageOptions struct {
 	Dialect *core.Dialect // SQL dialect (required)
-	Schema  parser.Schema    // Schema information for star expansion
+	Schema  Schema        // Schema information for star expansion
+
+	// MaxRecursionDepth bounds how many times the recursive members of a
+	// WITH RECURSIVE CTE are evaluated while column lineage converges.
+	// Zero or negative evaluates them 16 times.
+	MaxRecursionDepth int
 }
 
 // ExtractLineageWithOptions extracts lineage with full configuration options.
@@ -43,11 +62,18 @@ func ExtractLineageWithOptions(sqlStr string, opts ExtractLineageOptions) (*Mode
 		return nil, err
 	}
 
+	maxDepth := opts.MaxRecursionDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxRecursionDepth
+	}
+
 	// Create extractor
 	extractor := &lineageExtractor{
-		dialect: d,
-		schema:  opts.Schema,
-		sources: make(map[string]struct{}),
+		dialect:           d,
+		schema:            resolve.Schema(opts.Schema),
+		sources:           make(map[string]struct{}),
+		maxRecursionDepth: maxDepth,
+		recursive:         make(map[*resolve.RecursiveCTE][]*ColumnLineage),
 	}
 
 	// Extract lineage
@@ -57,9 +83,13 @@ func ExtractLineageWithOptions(sqlStr string, opts ExtractLineageOptions) (*Mode
 // lineageExtractor walks the AST to extract lineage information.
 type lineageExtractor struct {
 	dialect        *core.Dialect
-	schema         parser.Schema
+	schema         resolve.Schema
 	sources        map[string]struct{} // Collected source tables
 	usesSelectStar bool                // Track star usage during extraction
+	warnings       []string            // Diagnostics reported in the result
+
+	maxRecursionDepth int                                        // Iteration limit for recursive CTEs
+	recursive         map[*resolve.RecursiveCTE][]*ColumnLineage // Recursive CTE column lineage (in progress or final)
 }
 
 // extract extracts lineage from a parsed statement.
@@ -69,7 +99,7 @@ func (e *lineageExtractor) extract(stmt *core.SelectStmt) (*ModelLineage, error)
 	}
 
 	// Resolve scopes
-	resolver, err := parser.NewResolver(e.dialect, e.schema)
+	resolver, err := resolve.NewResolver(e.dialect, e.schema)
 	if err != nil {
 		return nil, err
 	}
@@ -90,15 +120,17 @@ func (e *lineageExtractor) extract(stmt *core.SelectStmt) (*ModelLineage, error)
 	// Build result
 	result := &ModelLineage{
 		Sources:        e.getSortedSources(),
+		Files:          slices.Sorted(slices.Values(resolver.Files())),
 		Columns:        columns,
 		UsesSelectStar: e.usesSelectStar,
+		Warnings:       append(resolver.Warnings(), e.warnings...),
 	}
 
 	return result, nil
 }
 
 // extractBodyLineage extracts lineage from a SELECT body.
-func (e *lineageExtractor) extractBodyLineage(scope *parser.Scope, body *core.SelectBody) ([]*ColumnLineage, error) {
+func (e *lineageExtractor) extractBodyLineage(scope *resolve.Scope, body *core.SelectBody) ([]*ColumnLineage, error) {
 	if body == nil || body.Left == nil {
 		return nil, nil
 	}
@@ -134,7 +166,7 @@ func (e *lineageExtractor) extractBodyLineage(scope *parser.Scope, body *core.Se
 }
 
 // extractCoreLineage extracts lineage from a SELECT core.
-func (e *lineageExtractor) extractCoreLineage(scope *parser.Scope, core *core.SelectCore) ([]*ColumnLineage, error) {
+func (e *lineageExtractor) extractCoreLineage(scope *resolve.Scope, core *core.SelectCore) ([]*ColumnLineage, error) {
 	if core == nil {
 		return nil, nil
 	}
@@ -144,7 +176,7 @@ func (e *lineageExtractor) extractCoreLineage(scope *parser.Scope, core *core.Se
 		e.registerFromClause(scope, core.From)
 	}
 
-	colResolver, err := parser.NewColumnResolver(scope, e.dialect)
+	colResolver, err := resolve.NewColumnResolver(scope, e.dialect)
 	if err != nil {
 		return nil, err
 	}
@@ -159,7 +191,7 @@ func (e *lineageExtractor) extractCoreLineage(scope *parser.Scope, core *core.Se
 }
 
 // extractSelectItemLineage extracts lineage from a single SELECT item.
-func (e *lineageExtractor) extractSelectItemLineage(scope *parser.Scope, colResolver *parser.ColumnResolver, item core.SelectItem, index int) []*ColumnLineage {
+func (e *lineageExtractor) extractSelectItemLineage(scope *resolve.Scope, colResolver *resolve.ColumnResolver, item core.SelectItem, index int) []*ColumnLineage {
 	// Handle SELECT *
 	if item.Star {
 		e.usesSelectStar = true
@@ -188,7 +220,7 @@ func (e *lineageExtractor) extractSelectItemLineage(scope *parser.Scope, colReso
 }
 
 // applyStarModifiers applies EXCLUDE, REPLACE, and RENAME modifiers to star-expanded columns.
-func (e *lineageExtractor) applyStarModifiers(scope *parser.Scope, colResolver *parser.ColumnResolver, lineages []*ColumnLineage, modifiers []core.StarModifier) []*ColumnLineage {
+func (e *lineageExtractor) applyStarModifiers(scope *resolve.Scope, colResolver *resolve.ColumnResolver, lineages []*ColumnLineage, modifiers []core.StarModifier) []*ColumnLineage {
 	if len(modifiers) == 0 {
 		return lineages
 	}
@@ -244,7 +276,7 @@ func (e *lineageExtractor) applyStarModifiers(scope *parser.Scope, colResolver *
 }
 
 // expandStar expands a SELECT * or table.* into individual column lineages.
-func (e *lineageExtractor) expandStar(scope *parser.Scope, tableName string, _ int) []*ColumnLineage {
+func (e *lineageExtractor) expandStar(scope *resolve.Scope, tableName string, _ int) []*ColumnLineage {
 	refs := scope.ExpandStar(tableName)
 	if len(refs) == 0 {
 		// No schema info available - return a single "unknown" lineage
@@ -260,6 +292,29 @@ func (e *lineageExtractor) expandStar(scope *parser.Scope, tableName string, _ i
 
 	var lineages []*ColumnLineage
 	for _, ref := range refs {
+		if entry, ok := lookupPivot(scope, ref); ok {
+			lineage := e.pivotColumnLineage(scope, entry, ref.Column)
+			lineage.Name = ref.Column
+			lineages = append(lineages, lineage)
+			continue
+		}
+
+		if entry, ok := lookupRecursiveCTE(scope, ref); ok {
+			lineage := e.recursiveColumnLineage(entry, ref.Column)
+			lineage.Name = ref.Column
+			lineages = append(lineages, lineage)
+			continue
+		}
+
+		if entry, ok := lookupTableFunction(scope, ref); ok {
+			lineages = append(lineages, &ColumnLineage{
+				Name:      ref.Column,
+				Sources:   e.tableFunctionSources(scope, entry, ref.Column),
+				Transform: core.TransformDirect,
+			})
+			continue
+		}
+
 		source := core.SourceRef{
 			Table:  ref.Table,
 			Column: ref.Column,
@@ -268,13 +323,14 @@ func (e *lineageExtractor) expandStar(scope *parser.Scope, tableName string, _ i
 		// Record the source table (avoiding CTE/derived names)
 		if entry, ok := scope.Lookup(ref.Table); ok {
 			switch entry.Type {
-			case parser.ScopeTable:
+			case resolve.ScopeTable:
 				if entry.SourceTable != "" {
 					e.sources[entry.SourceTable] = struct{}{}
+					source.Table = entry.SourceTable
 				} else {
 					e.sources[entry.Name] = struct{}{}
 				}
-			case parser.ScopeCTE, parser.ScopeDerived:
+			case resolve.ScopeCTE, resolve.ScopeDerived:
 				// For CTEs and derived tables, use underlying sources
 				for _, underlying := range entry.UnderlyingSources {
 					e.sources[underlying] = struct{}{}
@@ -293,7 +349,7 @@ func (e *lineageExtractor) expandStar(scope *parser.Scope, tableName string, _ i
 }
 
 // extractExprLineage extracts lineage from an expression.
-func (e *lineageExtractor) extractExprLineage(scope *parser.Scope, colResolver *parser.ColumnResolver, expr core.Expr) *ColumnLineage {
+func (e *lineageExtractor) extractExprLineage(scope *resolve.Scope, colResolver *resolve.ColumnResolver, expr core.Expr) *ColumnLineage {
 	lineage := &ColumnLineage{}
 
 	if expr == nil {
@@ -302,11 +358,18 @@ func (e *lineageExtractor) extractExprLineage(scope *parser.Scope, colResolver *
 
 	switch ex := expr.(type) {
 	case *core.ColumnRef:
-		// Direct column reference
-		source := e.resolveColumnRef(scope, ex)
-		if source != nil {
-			lineage.Sources = []core.SourceRef{*source}
+		// PIVOT/UNPIVOT output columns carry the pivot's transform
+		if entry, ok := lookupPivot(scope, ex); ok {
+			return e.pivotColumnLineage(scope, entry, ex.Column)
 		}
+
+		// Recursive CTE columns merge the anchor and recursive members
+		if entry, ok := lookupRecursiveCTE(scope, ex); ok && len(ex.Fields) == 0 {
+			return e.recursiveColumnLineage(entry, ex.Column)
+		}
+
+		// Direct column reference (including struct field access)
+		lineage.Sources = e.resolveColumnSources(scope, ex)
 		lineage.Transform = core.TransformDirect
 
 	case *core.Literal:
@@ -323,9 +386,8 @@ func (e *lineageExtractor) extractExprLineage(scope *parser.Scope, colResolver *
 		funcName := e.dialect.NormalizeName(ex.Name)
 		switch funcType {
 		case core.LineageTable:
-			// Table function acts as a data source, not a transformation
-			// SELECT * FROM read_csv('file.csv') - the CSV is the source, not upstream columns
-			lineage.Sources = nil
+			// Table functions only carry column lineage through their arguments:
+			// unnest(tags) derives from tags, read_csv('file.csv') has no upstream columns
 			lineage.Transform = core.TransformExpression
 			lineage.Function = funcName
 		case core.LineageAggregate:
@@ -361,6 +423,15 @@ func (e *lineageExtractor) extractExprLineage(scope *parser.Scope, colResolver *
 		lineage.Transform = core.TransformExpression
 
 	case *core.BinaryExpr:
+		// JSON extraction with a literal path narrows the source to a field
+		// e.g., payload->'customer'->>'id'
+		if path, ok := jsonPathOf(ex); ok {
+			leftLineage := e.extractExprLineage(scope, colResolver, ex.Left)
+			lineage.Sources = withField(leftLineage.Sources, path)
+			lineage.Transform = leftLineage.Transform
+			break
+		}
+
 		// Recursively extract lineage from both sides (handles subqueries)
 		leftLineage := e.extractExprLineage(scope, colResolver, ex.Left)
 		rightLineage := e.extractExprLineage(scope, colResolver, ex.Right)
@@ -390,7 +461,7 @@ func (e *lineageExtractor) extractExprLineage(scope *parser.Scope, colResolver *
 			}
 
 			// Create column resolver for the subquery scope
-			subColResolver, err := parser.NewColumnResolver(subScope, e.dialect)
+			subColResolver, err := resolve.NewColumnResolver(subScope, e.dialect)
 			if err == nil {
 				// Extract lineage from the subquery's SELECT columns only
 				for _, item := range core.Columns {
@@ -468,27 +539,378 @@ func (e *lineageExtractor) extractExprLineage(scope *parser.Scope, colResolver *
 }
 
 // collectExprSources collects all source columns from an expression.
-func (e *lineageExtractor) collectExprSources(scope *parser.Scope, colResolver *parser.ColumnResolver, expr core.Expr) []core.SourceRef {
+func (e *lineageExtractor) collectExprSources(scope *resolve.Scope, colResolver *resolve.ColumnResolver, expr core.Expr) []core.SourceRef {
 	refs := colResolver.CollectColumns(expr)
 	var sources []core.SourceRef
 	seen := make(map[string]struct{})
 
 	for _, ref := range refs {
-		source := e.resolveColumnRef(scope, ref)
-		if source != nil {
-			key := source.Table + "." + source.Column
+		for _, source := range e.resolveColumnSources(scope, ref) {
+			key := sourceKey(source)
 			if _, ok := seen[key]; !ok {
 				seen[key] = struct{}{}
-				sources = append(sources, *source)
+				sources = append(sources, source)
+			}
+		}
+	}
+
+	return sources
+}
+
+// resolveColumnSources resolves a column reference, including struct field
+// paths and columns produced by table functions, to its source columns.
+func (e *lineageExtractor) resolveColumnSources(scope *resolve.Scope, ref *core.ColumnRef) []core.SourceRef {
+	if ref == nil {
+		return nil
+	}
+
+	ref, field := splitFieldPath(scope, ref)
+
+	if entry, ok := lookupPivot(scope, ref); ok {
+		return withField(e.pivotColumnLineage(scope, entry, ref.Column).Sources, field)
+	}
+
+	if entry, ok := lookupRecursiveCTE(scope, ref); ok {
+		return withField(e.recursiveColumnLineage(entry, ref.Column).Sources, field)
+	}
+
+	if entry, ok := lookupTableFunction(scope, ref); ok {
+		if entry.SourceTable != "" {
+			// Columns of a file read by a table function come from the file
+			return withField([]core.SourceRef{{Table: entry.SourceTable, Column: ref.Column}}, field)
+		}
+		if ref.Table != "" && len(entry.Function.ColumnAliases) == 0 && !e.hasColumn(entry, ref.Column) {
+			// alias.field on an unnested struct (UNNEST(o.items) AS item -> item.sku)
+			field = joinFieldPath(ref.Column, field)
+		}
+		return withField(e.tableFunctionSources(scope, entry, ref.Column), field)
+	}
+
+	source := e.resolveColumnRef(scope, ref)
+	if source == nil {
+		return nil
+	}
+	return withField([]core.SourceRef{*source}, field)
+}
+
+// splitFieldPath separates struct field access from a dotted column reference.
+// The parser reads a.b.c as table a, column b, field c; which part is the
+// column depends on what is in scope:
+//   - a is a table or alias: column b, field c
+//   - b is a table (a is its schema): column c
+//   - c is a table (a.b is its catalog and schema, as in a.b.c.d): column d
+//   - a is a column of a table in scope: column a, field b.c
+//
+// Returns the reference to resolve and the dotted field path.
+func splitFieldPath(scope *resolve.Scope, ref *core.ColumnRef) (*core.ColumnRef, string) {
+	if ref.Table == "" {
+		return ref, strings.Join(ref.Fields, ".")
+	}
+	if _, ok := scope.Lookup(ref.Table); ok {
+		return &core.ColumnRef{Table: ref.Table, Column: ref.Column}, strings.Join(ref.Fields, ".")
+	}
+	if len(ref.Fields) > 0 {
+		if _, ok := scope.Lookup(ref.Column); ok {
+			return &core.ColumnRef{Table: ref.Column, Column: ref.Fields[0]}, strings.Join(ref.Fields[1:], ".")
+		}
+	}
+	if len(ref.Fields) > 1 {
+		if _, ok := scope.Lookup(ref.Fields[0]); ok {
+			return &core.ColumnRef{Table: ref.Fields[0], Column: ref.Fields[1]}, strings.Join(ref.Fields[2:], ".")
+		}
+	}
+	structRef := &core.ColumnRef{Column: ref.Table}
+	if _, ok := scope.ResolveColumn(structRef); ok {
+		return structRef, strings.Join(append([]string{ref.Column}, ref.Fields...), ".")
+	}
+	return ref, ""
+}
+
+// lookupTableFunction returns the table function entry a column reference reads from.
+// Besides qualified and listed columns, a bare alias refers to the function's
+// single output column (BigQuery style: FROM t, UNNEST(t.tags) AS tag).
+func lookupTableFunction(scope *resolve.Scope, ref *core.ColumnRef) (*resolve.ScopeEntry, bool) {
+	entry, ok := scope.ResolveColumn(ref)
+	if !ok && ref.Table == "" {
+		entry, ok = scope.Lookup(ref.Column)
+	}
+	if !ok || entry.Type != resolve.ScopeTableFunction {
+		return nil, false
+	}
+	return entry, true
+}
+
+// lookupPivot returns the PIVOT/UNPIVOT entry a column reference reads from.
+func lookupPivot(scope *resolve.Scope, ref *core.ColumnRef) (*resolve.ScopeEntry, bool) {
+	entry, ok := scope.ResolveColumn(ref)
+	if !ok || entry.Type != resolve.ScopePivot {
+		return nil, false
+	}
+	return entry, true
+}
+
+// pivotColumnLineage returns the lineage of a PIVOT/UNPIVOT output column.
+// Pivoted value columns derive from the aggregated column(s) and the pivot
+// column; unpivoted value columns from every IN column in their position.
+func (e *lineageExtractor) pivotColumnLineage(scope *resolve.Scope, entry *resolve.ScopeEntry, column string) *ColumnLineage {
+	col := scope.LookupPivotColumn(entry, column)
+
+	lineage := &ColumnLineage{}
+	for _, input := range col.Inputs {
+		sources := e.resolveColumnSources(entry.Inner, &core.ColumnRef{Column: input})
+		lineage.Sources = e.mergeSources(lineage.Sources, sources)
+	}
+
+	switch col.Kind {
+	case resolve.PivotValue:
+		lineage.Transform = core.TransformExpression
+		lineage.Function = e.dialect.NormalizeName(col.Aggregate.Name)
+	case resolve.UnpivotName, resolve.UnpivotValue:
+		lineage.Transform = core.TransformExpression
+	default:
+		lineage.Transform = core.TransformDirect
+	}
+
+	return lineage
+}
+
+// tableFunctionSources returns the sources of a table function output column.
+// When the function has one argument per output column (unnest(a, b) AS t(x, y))
+// the column maps to its positional argument; otherwise every argument contributes.
+func (e *lineageExtractor) tableFunctionSources(scope *resolve.Scope, entry *resolve.ScopeEntry, column string) []core.SourceRef {
+	colResolver, err := resolve.NewColumnResolver(scope, e.dialect)
+	if err != nil {
+		return nil
+	}
+
+	args := entry.Function.Args
+	if len(args) == len(entry.Columns) && len(args) > 1 {
+		for i, col := range entry.Columns {
+			if e.dialect.NormalizeName(col) == e.dialect.NormalizeName(column) {
+				args = args[i : i+1]
+				break
 			}
 		}
 	}
 
+	var sources []core.SourceRef
+	for _, arg := range args {
+		sources = e.mergeSources(sources, e.extractExprLineage(scope, colResolver, arg).Sources)
+	}
 	return sources
 }
 
+// hasColumn reports whether a scope entry lists the given column.
+func (e *lineageExtractor) hasColumn(entry *resolve.ScopeEntry, column string) bool {
+	for _, col := range entry.Columns {
+		if e.dialect.NormalizeName(col) == e.dialect.NormalizeName(column) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonPathOf returns the field path for a JSON extraction operator with a
+// literal right-hand side (col->'a', col->>'$.a.b', col->0).
+func jsonPathOf(ex *core.BinaryExpr) (string, bool) {
+	if ex.Op != token.ARROW && ex.Op != token.DARROW {
+		return "", false
+	}
+	lit, ok := ex.Right.(*core.Literal)
+	if !ok {
+		return "", false
+	}
+	if lit.Type == core.LiteralNumber {
+		return "[" + lit.Value + "]", true
+	}
+	path := strings.TrimPrefix(strings.TrimPrefix(lit.Value, "$"), ".")
+	return path, path != ""
+}
+
+// withField appends a field path to each source's existing field.
+func withField(sources []core.SourceRef, field string) []core.SourceRef {
+	if field == "" {
+		return sources
+	}
+	result := make([]core.SourceRef, len(sources))
+	for i, s := range sources {
+		s.Field = joinFieldPath(s.Field, field)
+		result[i] = s
+	}
+	return result
+}
+
+// lookupRecursiveCTE returns the recursive CTE entry a column reference resolves to.
+func lookupRecursiveCTE(scope *resolve.Scope, ref *core.ColumnRef) (*resolve.ScopeEntry, bool) {
+	entry, ok := scope.ResolveColumn(ref)
+	if !ok || entry.Type != resolve.ScopeCTE || entry.Recursive == nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+// recursiveColumnLineage returns the lineage of a recursive CTE output column.
+func (e *lineageExtractor) recursiveColumnLineage(entry *resolve.ScopeEntry, column string) *ColumnLineage {
+	columns := e.recursiveCTELineage(entry)
+
+	normalized := e.dialect.NormalizeName(column)
+	for i, name := range entry.Columns {
+		if e.dialect.NormalizeName(name) == normalized && i < len(columns) {
+			col := columns[i]
+			return &ColumnLineage{
+				Sources:   append([]core.SourceRef(nil), col.Sources...),
+				Transform: col.Transform,
+				Function:  col.Function,
+			}
+		}
+	}
+
+	// Unknown column (e.g., no anchor member): keep the reference unresolved
+	return &ColumnLineage{
+		Sources:   []core.SourceRef{{Column: column}},
+		Transform: core.TransformDirect,
+	}
+}
+
+// recursiveCTELineage computes the positional column lineage of a recursive CTE.
+//
+// The anchor members seed the lineage. Each iteration evaluates the recursive
+// members with self-references bound to the previous iteration's lineage and
+// merges the result with the anchor, stopping once nothing changes. Since
+// sources only accumulate this converges, but chains of columns feeding each
+// other take one iteration per hop, so the iteration count is capped at
+// maxRecursionDepth and a warning is recorded when the cap is reached.
+func (e *lineageExtractor) recursiveCTELineage(entry *resolve.ScopeEntry) []*ColumnLineage {
+	rec := entry.Recursive
+	if columns, ok := e.recursive[rec]; ok {
+		// Final lineage, or the previous iteration while a recursive member is evaluated
+		return columns
+	}
+
+	// Star usage inside the CTE body does not make the outer query a SELECT *
+	usesSelectStar := e.usesSelectStar
+	defer func() { e.usesSelectStar = usesSelectStar }()
+
+	var anchor []*ColumnLineage
+	for _, member := range rec.Members {
+		if !member.Recursive {
+			anchor = e.mergeMemberLineage(anchor, e.memberLineage(member))
+		}
+	}
+	current := anchor
+	e.recursive[rec] = current
+
+	for depth := 0; ; depth++ {
+		next := cloneLineage(anchor)
+		for _, member := range rec.Members {
+			if member.Recursive {
+				next = e.mergeMemberLineage(next, e.memberLineage(member))
+			}
+		}
+		if lineageEqual(current, next) {
+			break
+		}
+		if depth >= e.maxRecursionDepth {
+			e.warnings = append(e.warnings, fmt.Sprintf(
+				"recursive CTE %q did not converge within %d iterations; column lineage may be incomplete",
+				rec.Name, e.maxRecursionDepth))
+			break
+		}
+		current = next
+		e.recursive[rec] = current
+	}
+
+	return current
+}
+
+// memberLineage extracts the column lineage of one recursive CTE member.
+func (e *lineageExtractor) memberLineage(member resolve.CTEMember) []*ColumnLineage {
+	columns, err := e.extractCoreLineage(member.Scope, member.Select)
+	if err != nil {
+		return nil
+	}
+	return columns
+}
+
+// mergeMemberLineage merges a member's columns positionally into the CTE lineage.
+// A column stays direct only if every member passes it through unchanged.
+func (e *lineageExtractor) mergeMemberLineage(dst, member []*ColumnLineage) []*ColumnLineage {
+	if dst == nil {
+		return cloneLineage(member)
+	}
+	for i, col := range dst {
+		if i >= len(member) {
+			break
+		}
+		col.Sources = e.mergeSources(col.Sources, member[i].Sources)
+		if member[i].Transform != core.TransformDirect {
+			col.Transform = core.TransformExpression
+		}
+		if col.Function == "" {
+			col.Function = member[i].Function
+		}
+	}
+	return dst
+}
+
+// cloneLineage returns a copy of a column list that can be merged into safely.
+func cloneLineage(columns []*ColumnLineage) []*ColumnLineage {
+	out := make([]*ColumnLineage, len(columns))
+	for i, col := range columns {
+		c := *col
+		c.Sources = append([]core.SourceRef(nil), col.Sources...)
+		out[i] = &c
+	}
+	return out
+}
+
+// lineageEqual reports whether two column lists carry the same lineage.
+func lineageEqual(a, b []*ColumnLineage) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Transform != b[i].Transform || a[i].Function != b[i].Function || len(a[i].Sources) != len(b[i].Sources) {
+			return false
+		}
+		keys := make(map[string]struct{}, len(a[i].Sources))
+		for _, s := range a[i].Sources {
+			keys[sourceKey(s)] = struct{}{}
+		}
+		for _, s := range b[i].Sources {
+			if _, ok := keys[sourceKey(s)]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// joinFieldPath joins two field paths; array subscripts attach without a dot.
+func joinFieldPath(base, field string) string {
+	switch {
+	case base == "":
+		return field
+	case field == "":
+		return base
+	case strings.HasPrefix(field, "["):
+		return base + field
+	default:
+		return base + "." + field
+	}
+}
+
+// sourceKey returns the deduplication key for a source reference.
+func sourceKey(s core.SourceRef) string {
+	key := s.Table + "." + s.Column
+	if s.Field != "" {
+		key += "." + s.Field
+	}
+	return key
+}
+
 // resolveColumnRef resolves a column reference to its source.
-func (e *lineageExtractor) resolveColumnRef(scope *parser.Scope, ref *core.ColumnRef) *core.SourceRef {
+func (e *lineageExtractor) resolveColumnRef(scope *resolve.Scope, ref *core.ColumnRef) *core.SourceRef {
 	if ref == nil {
 		return nil
 	}
@@ -533,7 +955,7 @@ func (e *lineageExtractor) resolveColumnRef(scope *parser.Scope, ref *core.Colum
 	// Fallback: use the reference as-is, but check if it's a CTE/derived first
 	if ref.Table != "" {
 		if entry, entryOk := scope.Lookup(ref.Table); entryOk {
-			if entry.Type == parser.ScopeCTE || entry.Type == parser.ScopeDerived {
+			if entry.Type == resolve.ScopeCTE || entry.Type == resolve.ScopeDerived {
 				// Don't add CTE/derived names to sources
 				for _, underlying := range entry.UnderlyingSources {
 					e.sources[underlying] = struct{}{}
@@ -558,7 +980,7 @@ func (e *lineageExtractor) resolveColumnRef(scope *parser.Scope, ref *core.Colum
 }
 
 // registerFromClause registers tables from a FROM clause.
-func (e *lineageExtractor) registerFromClause(scope *parser.Scope, from *core.FromClause) {
+func (e *lineageExtractor) registerFromClause(scope *resolve.Scope, from *core.FromClause) {
 	if from == nil {
 		return
 	}
@@ -579,7 +1001,7 @@ func (e *lineageExtractor) registerFromClause(scope *parser.Scope, from *core.Fr
 }
 
 // registerTableRef registers a table reference as a source.
-func (e *lineageExtractor) registerTableRef(scope *parser.Scope, ref core.TableRef) {
+func (e *lineageExtractor) registerTableRef(scope *resolve.Scope, ref core.TableRef) {
 	if ref == nil {
 		return
 	}
@@ -603,6 +1025,10 @@ func (e *lineageExtractor) registerTableRef(scope *parser.Scope, ref core.TableR
 		parts = append(parts, t.Name)
 		e.sources[strings.Join(parts, ".")] = struct{}{}
 
+	case *core.TableFunction:
+		// Table functions are not sources themselves; columns they produce
+		// are traced through their arguments (UNNEST(t.tags) -> t.tags)
+
 	case *core.DerivedTable:
 		// Derived tables don't add sources directly
 		// Their inner queries' sources are collected when we process them
@@ -623,17 +1049,22 @@ func (e *lineageExtractor) registerTableRef(scope *parser.Scope, ref core.TableR
 }
 
 // collectSources collects sources from scope entries.
-func (e *lineageExtractor) collectSources(scope *parser.Scope) {
+func (e *lineageExtractor) collectSources(scope *resolve.Scope) {
 	for _, entry := range scope.AllEntries() {
 		switch entry.Type {
-		case parser.ScopeTable:
+		case resolve.ScopeTable:
 			if entry.SourceTable != "" {
 				e.sources[entry.SourceTable] = struct{}{}
 			} else {
 				e.sources[entry.Name] = struct{}{}
 			}
-		case parser.ScopeCTE, parser.ScopeDerived:
-			// For CTEs and derived tables, use ONLY underlying sources
+		case resolve.ScopeTableFunction:
+			// Files read by table functions are sources; other table functions are not
+			if entry.SourceTable != "" {
+				e.sources[entry.SourceTable] = struct{}{}
+			}
+		case resolve.ScopeCTE, resolve.ScopeDerived, resolve.ScopePivot:
+			// For CTEs, derived tables, and pivots, use ONLY underlying sources
 			// Do NOT add the CTE/derived name itself
 			for _, underlying := range entry.UnderlyingSources {
 				e.sources[underlying] = struct{}{}
@@ -660,7 +1091,7 @@ func (e *lineageExtractor) mergeSources(a, b []core.SourceRef) []core.SourceRef
 	var result []core.SourceRef
 
 	for _, s := range a {
-		key := s.Table + "." + s.Column
+		key := sourceKey(s)
 		if _, ok := seen[key]; !ok {
 			seen[key] = struct{}{}
 			result = append(result, s)
@@ -668,7 +1099,7 @@ func (e *lineageExtractor) mergeSources(a, b []core.SourceRef) []core.SourceRef
 	}
 
 	for _, s := range b {
-		key := s.Table + "." + s.Column
+		key := sourceKey(s)
 		if _, ok := seen[key]; !ok {
 			seen[key] = struct{}{}
 			result = append(result, s)
@@ -686,6 +1117,10 @@ func (e *lineageExtractor) inferColumnName(expr core.Expr, index int) string {
 
 	switch ex := expr.(type) {
 	case *core.ColumnRef:
+		// Struct field access is named after the innermost field
+		if len(ex.Fields) > 0 {
+			return ex.Fields[len(ex.Fields)-1]
+		}
 		return ex.Column
 
 	case *core.FuncCall:
@@ -713,7 +1148,7 @@ func (e *lineageExtractor) generateColumnName(index int) string {
 // - CTEs (from the subquery's WITH clause)
 // - Derived tables (recursively)
 // It does NOT resolve WHERE/HAVING to avoid issues with correlated subqueries.
-func (e *lineageExtractor) resolveSubqueryFrom(scope *parser.Scope, stmt *core.SelectStmt, from *core.FromClause) {
+func (e *lineageExtractor) resolveSubqueryFrom(scope *resolve.Scope, stmt *core.SelectStmt, from *core.FromClause) {
 	// First, handle any CTEs in the subquery
 	if stmt.With != nil {
 		for _, cte := range stmt.With.CTEs {
@@ -752,7 +1187,7 @@ func (e *lineageExtractor) resolveSubqueryFrom(scope *parser.Scope, stmt *core.S
 }
 
 // resolveTableRefForSubquery registers a table reference in the subquery scope.
-func (e *lineageExtractor) resolveTableRefForSubquery(scope *parser.Scope, ref core.TableRef) {
+func (e *lineageExtractor) resolveTableRefForSubquery(scope *resolve.Scope, ref core.TableRef) {
 	if ref == nil {
 		return
 	}
@@ -778,6 +1213,9 @@ func (e *lineageExtractor) resolveTableRefForSubquery(scope *parser.Scope, ref c
 		parts = append(parts, t.Name)
 		e.sources[strings.Join(parts, ".")] = struct{}{}
 
+	case *core.TableFunction:
+		scope.RegisterTableFunction(t)
+
 	case *core.DerivedTable:
 		// Nested derived table - extract columns and register
 		if t.Select != nil && t.Select.Body != nil && t.Select.Body.Left != nil {
@@ -825,11 +1263,15 @@ func (e *lineageExtractor) resolveTableRefForSubquery(scope *parser.Scope, ref c
 		}
 
 	case *core.PivotTable:
-		// PIVOT: resolve the source table recursively
-		e.resolveTableRefForSubquery(scope, t.Source)
+		// PIVOT: resolve the source table recursively, then expose the pivoted columns
+		inner := scope.Child()
+		e.resolveTableRefForSubquery(inner, t.Source)
+		scope.RegisterPivot(t, inner)
 
 	case *core.UnpivotTable:
-		// UNPIVOT: resolve the source table recursively
-		e.resolveTableRefForSubquery(scope, t.Source)
+		// UNPIVOT: resolve the source table recursively, then expose the unpivoted columns
+		inner := scope.Child()
+		e.resolveTableRefForSubquery(inner, t.Source)
+		scope.RegisterPivot(t, inner)
 	}
 }
@@ -2,11 +2,12 @@ package lineage
 
 import (
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/dialect"
-	"github.com/leapstack-labs/leapsql/pkg/parser"
 
 	// Import duckdb dialect so it registers itself
 	_ "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
@@ -41,6 +42,8 @@ type colSpec struct {
 	function  string // expected function name (empty = don't check)
 	srcCount  *int   // expected source count (nil = don't check)
 	srcTable  string // expected first source table (empty = don't check)
+	srcColumn string // expected first source column (empty = don't check)
+	srcField  string // expected first source field path (empty = don't check)
 }
 
 // srcN is a helper to create a pointer to an int for srcCount
@@ -50,7 +53,7 @@ func srcN(n int) *int { return &n }
 type testCase struct {
 	name    string
 	sql     string
-	schema  parser.Schema
+	schema  Schema
 	sources []string  // expected source tables
 	cols    []colSpec // expected columns
 }
@@ -106,6 +109,12 @@ func runLineageTests(t *testing.T, tests []testCase) {
 				if spec.srcTable != "" && len(col.Sources) > 0 && col.Sources[0].Table != spec.srcTable {
 					t.Errorf("column %q: expected source table %q, got %q", spec.name, spec.srcTable, col.Sources[0].Table)
 				}
+				if spec.srcColumn != "" && len(col.Sources) > 0 && col.Sources[0].Column != spec.srcColumn {
+					t.Errorf("column %q: expected source column %q, got %q", spec.name, spec.srcColumn, col.Sources[0].Column)
+				}
+				if spec.srcField != "" && len(col.Sources) > 0 && col.Sources[0].Field != spec.srcField {
+					t.Errorf("column %q: expected source field %q, got %q", spec.name, spec.srcField, col.Sources[0].Field)
+				}
 			}
 		})
 	}
@@ -290,6 +299,255 @@ func TestExtractLineage_TableFunctions(t *testing.T) {
 				{name: "series_val", transform: core.TransformExpression, function: "generate_series", srcCount: srcN(0)},
 			},
 		},
+		{
+			name:    "read_parquet file as source",
+			sql:     `SELECT id, amount FROM read_parquet('s3://bucket/orders/*.parquet')`,
+			sources: []string{"s3://bucket/orders/*.parquet"},
+			cols: []colSpec{
+				{name: "id", transform: core.TransformDirect, srcTable: "s3://bucket/orders/*.parquet", srcColumn: "id"},
+				{name: "amount", transform: core.TransformDirect, srcTable: "s3://bucket/orders/*.parquet", srcColumn: "amount"},
+			},
+		},
+		{
+			name:    "read_csv file joined with a table",
+			sql:     `SELECT o.id, c.name FROM read_csv('data/orders.csv', header = true) AS o JOIN customers c ON c.id = o.customer_id`,
+			sources: []string{"customers", "data/orders.csv"},
+			cols: []colSpec{
+				{name: "id", transform: core.TransformDirect, srcTable: "data/orders.csv", srcColumn: "id"},
+				{name: "name", transform: core.TransformDirect, srcTable: "customers", srcColumn: "name"},
+			},
+		},
+		{
+			name:    "file read in a CTE",
+			sql:     `WITH orders AS (SELECT id FROM read_json('events.json')) SELECT id FROM orders`,
+			sources: []string{"events.json"},
+			cols: []colSpec{
+				{name: "id", transform: core.TransformDirect, srcTable: "events.json", srcColumn: "id"},
+			},
+		},
+	})
+}
+
+func TestExtractLineage_Files(t *testing.T) {
+	duckdb, ok := dialect.Get("duckdb")
+	if !ok {
+		t.Fatal("DuckDB dialect not found - ensure duckdb/dialect package is imported")
+	}
+
+	tests := []struct {
+		name  string
+		sql   string
+		files []string
+	}{
+		{
+			name:  "no files",
+			sql:   `SELECT i FROM generate_series(1, 10) AS t(i) JOIN orders ON orders.id = t.i`,
+			files: nil,
+		},
+		{
+			name:  "files in CTEs and joins",
+			sql:   `WITH refunds AS (SELECT * FROM read_csv_auto('refunds.csv')) SELECT * FROM read_parquet('s3://bucket/orders.parquet') o JOIN refunds r ON r.id = o.id JOIN customers c ON c.id = o.customer_id`,
+			files: []string{"refunds.csv", "s3://bucket/orders.parquet"},
+		},
+		{
+			name:  "same file read twice",
+			sql:   `SELECT * FROM read_parquet('a.parquet') UNION ALL SELECT * FROM read_parquet('a.parquet')`,
+			files: []string{"a.parquet"},
+		},
+		{
+			name:  "computed path",
+			sql:   `SELECT * FROM read_parquet(getenv('ORDERS_PATH'))`,
+			files: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lineage, err := ExtractLineageWithOptions(tt.sql, ExtractLineageOptions{Dialect: duckdb})
+			if err != nil {
+				t.Fatalf("ExtractLineageWithOptions failed: %v", err)
+			}
+			if len(lineage.Files) != len(tt.files) || (len(tt.files) > 0 && !reflect.DeepEqual(lineage.Files, tt.files)) {
+				t.Errorf("expected files %v, got %v", tt.files, lineage.Files)
+			}
+			for _, f := range tt.files {
+				if !contains(lineage.Sources, f) {
+					t.Errorf("file %q missing from sources %v", f, lineage.Sources)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractLineage_NestedData(t *testing.T) {
+	runLineageTests(t, []testCase{
+		{
+			name:    "struct field access",
+			sql:     `SELECT address.city FROM users`,
+			sources: []string{"users"},
+			cols: []colSpec{
+				{name: "city", transform: core.TransformDirect, srcCount: srcN(1), srcTable: "users", srcColumn: "address", srcField: "city"},
+			},
+		},
+		{
+			name:    "qualified nested struct field access",
+			sql:     `SELECT u.address.geo.lat AS lat FROM users u`,
+			sources: []string{"users"},
+			cols: []colSpec{
+				{name: "lat", transform: core.TransformDirect, srcCount: srcN(1), srcTable: "users", srcColumn: "address", srcField: "geo.lat"},
+			},
+		},
+		{
+			name:    "schema qualified column is not a struct",
+			sql:     `SELECT raw.users.id FROM raw.users`,
+			sources: []string{"raw.users"},
+			cols: []colSpec{
+				{name: "id", transform: core.TransformDirect, srcCount: srcN(1), srcTable: "raw.users", srcColumn: "id"},
+			},
+		},
+		{
+			name:    "struct fields of the same column stay distinct",
+			sql:     `SELECT address.city || address.zip AS location FROM users`,
+			sources: []string{"users"},
+			cols: []colSpec{
+				{name: "location", transform: core.TransformExpression, srcCount: srcN(2), srcColumn: "address", srcField: "city"},
+			},
+		},
+		{
+			name:    "json text extraction",
+			sql:     `SELECT payload->>'customer_id' AS customer_id FROM events`,
+			sources: []string{"events"},
+			cols: []colSpec{
+				{name: "customer_id", transform: core.TransformDirect, srcCount: srcN(1), srcTable: "events", srcColumn: "payload", srcField: "customer_id"},
+			},
+		},
+		{
+			name:    "chained json extraction with path",
+			sql:     `SELECT payload->'$.customer'->>'id' AS id, payload->'items'->0 AS first_item FROM events`,
+			sources: []string{"events"},
+			cols: []colSpec{
+				{name: "id", transform: core.TransformDirect, srcCount: srcN(1), srcColumn: "payload", srcField: "customer.id"},
+				{name: "first_item", transform: core.TransformDirect, srcCount: srcN(1), srcColumn: "payload", srcField: "items[0]"},
+			},
+		},
+		{
+			name:    "json extraction in expression",
+			sql:     `SELECT CAST(payload->>'amount' AS DECIMAL) * 100 AS cents FROM events`,
+			sources: []string{"events"},
+			cols: []colSpec{
+				{name: "cents", transform: core.TransformExpression, srcCount: srcN(1), srcColumn: "payload", srcField: "amount"},
+			},
+		},
+		{
+			name:    "unnest in select list",
+			sql:     `SELECT id, unnest(tags) AS tag FROM posts`,
+			sources: []string{"posts"},
+			cols: []colSpec{
+				{name: "id", transform: core.TransformDirect, srcCount: srcN(1)},
+				{name: "tag", transform: core.TransformExpression, function: "unnest", srcCount: srcN(1), srcTable: "posts", srcColumn: "tags"},
+			},
+		},
+		{
+			name:    "unnest in FROM with column alias",
+			sql:     `SELECT p.id, t.tag FROM posts p, UNNEST(p.tags) AS t(tag)`,
+			sources: []string{"posts"},
+			cols: []colSpec{
+				{name: "id", transform: core.TransformDirect, srcCount: srcN(1), srcTable: "posts"},
+				{name: "tag", transform: core.TransformDirect, srcCount: srcN(1), srcTable: "posts", srcColumn: "tags"},
+			},
+		},
+		{
+			name:    "unnest alias as element column",
+			sql:     `SELECT item.sku FROM orders o, UNNEST(o.line_items) AS item`,
+			sources: []string{"orders"},
+			cols: []colSpec{
+				{name: "sku", transform: core.TransformDirect, srcCount: srcN(1), srcTable: "orders", srcColumn: "line_items", srcField: "sku"},
+			},
+		},
+	})
+}
+
+func TestExtractLineage_PivotUnpivot(t *testing.T) {
+	salesSchema := Schema{
+		"sales": {"region", "month", "amount", "quantity"},
+	}
+	wideSchema := Schema{
+		"monthly": {"region", "jan", "feb", "jan_qty", "feb_qty"},
+	}
+
+	runLineageTests(t, []testCase{
+		{
+			name:    "pivot value columns from aggregate and pivot column",
+			sql:     `SELECT region, jan, feb FROM sales PIVOT (SUM(amount) FOR month IN ('jan', 'feb'))`,
+			sources: []string{"sales"},
+			cols: []colSpec{
+				{name: "region", transform: core.TransformDirect, srcCount: srcN(1), srcTable: "sales", srcColumn: "region"},
+				{name: "jan", transform: core.TransformExpression, function: "sum", srcCount: srcN(2), srcTable: "sales", srcColumn: "amount"},
+				{name: "feb", transform: core.TransformExpression, function: "sum", srcCount: srcN(2), srcTable: "sales", srcColumn: "amount"},
+			},
+		},
+		{
+			name:    "pivot star expansion with schema",
+			sql:     `SELECT * FROM sales PIVOT (SUM(amount) FOR month IN ('jan' AS january, 'feb'))`,
+			schema:  salesSchema,
+			sources: []string{"sales"},
+			cols: []colSpec{
+				{name: "region", transform: core.TransformDirect, srcCount: srcN(1), srcColumn: "region"},
+				{name: "quantity", transform: core.TransformDirect, srcCount: srcN(1), srcColumn: "quantity"},
+				{name: "january", transform: core.TransformExpression, function: "sum", srcCount: srcN(2)},
+				{name: "feb", transform: core.TransformExpression, function: "sum", srcCount: srcN(2)},
+			},
+		},
+		{
+			name:    "pivot with aliased aggregates",
+			sql:     `SELECT p.jan_total, p.jan_cnt FROM sales PIVOT (SUM(amount) AS total, COUNT(quantity) AS cnt FOR month IN ('jan')) AS p`,
+			sources: []string{"sales"},
+			cols: []colSpec{
+				{name: "jan_total", transform: core.TransformExpression, function: "sum", srcCount: srcN(2), srcColumn: "amount"},
+				{name: "jan_cnt", transform: core.TransformExpression, function: "count", srcCount: srcN(2), srcColumn: "quantity"},
+			},
+		},
+		{
+			name:    "pivot over CTE",
+			sql:     `WITH s AS (SELECT region, month, amount FROM raw.sales) SELECT region, jan FROM s PIVOT (SUM(amount) FOR month IN ('jan'))`,
+			sources: []string{"raw.sales"},
+			cols: []colSpec{
+				{name: "region", transform: core.TransformDirect, srcCount: srcN(1), srcTable: "raw.sales"},
+				{name: "jan", transform: core.TransformExpression, function: "sum", srcCount: srcN(2), srcTable: "raw.sales", srcColumn: "amount"},
+			},
+		},
+		{
+			name:    "unpivot name and value columns",
+			sql:     `SELECT region, month, amount FROM monthly UNPIVOT (amount FOR month IN (jan, feb))`,
+			sources: []string{"monthly"},
+			cols: []colSpec{
+				{name: "region", transform: core.TransformDirect, srcCount: srcN(1), srcColumn: "region"},
+				{name: "month", transform: core.TransformExpression, srcCount: srcN(0)},
+				{name: "amount", transform: core.TransformExpression, srcCount: srcN(2), srcColumn: "jan"},
+			},
+		},
+		{
+			name:    "unpivot star expansion with multiple value columns",
+			sql:     `SELECT * FROM monthly UNPIVOT ((amount, qty) FOR month IN ((jan, jan_qty), (feb, feb_qty)))`,
+			schema:  wideSchema,
+			sources: []string{"monthly"},
+			cols: []colSpec{
+				{name: "region", transform: core.TransformDirect, srcCount: srcN(1)},
+				{name: "month", transform: core.TransformExpression, srcCount: srcN(0)},
+				{name: "amount", transform: core.TransformExpression, srcCount: srcN(2), srcColumn: "jan"},
+				{name: "qty", transform: core.TransformExpression, srcCount: srcN(2), srcColumn: "jan_qty"},
+			},
+		},
+		{
+			name:    "pivot with QUALIFY",
+			sql:     `SELECT region, jan, ROW_NUMBER() OVER (ORDER BY jan DESC) AS rnk FROM sales PIVOT (SUM(amount) FOR month IN ('jan')) QUALIFY rnk <= 3`,
+			sources: []string{"sales"},
+			cols: []colSpec{
+				{name: "region", transform: core.TransformDirect, srcCount: srcN(1)},
+				{name: "jan", transform: core.TransformExpression, function: "sum", srcCount: srcN(2)},
+				{name: "rnk", transform: core.TransformExpression, function: "row_number", srcCount: srcN(2), srcColumn: "amount"},
+			},
+		},
 	})
 }
 
@@ -637,7 +895,7 @@ func TestExtractLineage_StarExpansion(t *testing.T) {
 		{
 			name:    "star with schema",
 			sql:     `SELECT * FROM users`,
-			schema:  parser.Schema{"users": {"id", "name", "email", "created_at"}},
+			schema:  Schema{"users": {"id", "name", "email", "created_at"}},
 			sources: []string{"users"},
 			cols: []colSpec{
 				{name: "id", transform: core.TransformDirect},
@@ -649,7 +907,7 @@ func TestExtractLineage_StarExpansion(t *testing.T) {
 		{
 			name: "table.star with schema",
 			sql:  `SELECT u.*, o.amount FROM users u JOIN orders o ON u.id = o.user_id`,
-			schema: parser.Schema{
+			schema: Schema{
 				"users":  {"id", "name"},
 				"orders": {"id", "user_id", "amount"},
 			},
@@ -663,6 +921,73 @@ func TestExtractLineage_StarExpansion(t *testing.T) {
 	})
 }
 
+func TestExtractLineage_CatalogQualified(t *testing.T) {
+	schema := Schema{
+		"lake.main.orders":        {"id", "customer_id", "amount"},
+		"warehouse.crm.customers": {"id", "name"},
+	}
+	runLineageTests(t, []testCase{
+		{
+			name:    "catalog-qualified tables",
+			sql:     `SELECT o.id, c.name FROM lake.main.orders o JOIN warehouse.crm.customers c ON c.id = o.customer_id`,
+			sources: []string{"lake.main.orders", "warehouse.crm.customers"},
+			cols: []colSpec{
+				{name: "id", transform: core.TransformDirect, srcTable: "lake.main.orders", srcColumn: "id"},
+				{name: "name", transform: core.TransformDirect, srcTable: "warehouse.crm.customers", srcColumn: "name"},
+			},
+		},
+		{
+			name:    "fully qualified column",
+			sql:     `SELECT lake.main.orders.amount FROM lake.main.orders`,
+			sources: []string{"lake.main.orders"},
+			cols: []colSpec{
+				{name: "amount", transform: core.TransformDirect, srcTable: "lake.main.orders", srcColumn: "amount"},
+			},
+		},
+		{
+			name:    "schema-qualified column",
+			sql:     `SELECT main.orders.amount FROM lake.main.orders`,
+			sources: []string{"lake.main.orders"},
+			cols: []colSpec{
+				{name: "amount", transform: core.TransformDirect, srcTable: "lake.main.orders", srcColumn: "amount"},
+			},
+		},
+		{
+			name:    "star",
+			sql:     `SELECT * FROM lake.main.orders`,
+			schema:  schema,
+			sources: []string{"lake.main.orders"},
+			cols: []colSpec{
+				{name: "id", transform: core.TransformDirect, srcTable: "lake.main.orders"},
+				{name: "customer_id", transform: core.TransformDirect, srcTable: "lake.main.orders"},
+				{name: "amount", transform: core.TransformDirect, srcTable: "lake.main.orders"},
+			},
+		},
+		{
+			name:    "qualified table star",
+			sql:     `SELECT lake.main.orders.*, c.name FROM lake.main.orders JOIN warehouse.crm.customers c ON c.id = orders.customer_id`,
+			schema:  schema,
+			sources: []string{"lake.main.orders", "warehouse.crm.customers"},
+			cols: []colSpec{
+				{name: "id", transform: core.TransformDirect, srcTable: "lake.main.orders"},
+				{name: "customer_id", transform: core.TransformDirect, srcTable: "lake.main.orders"},
+				{name: "amount", transform: core.TransformDirect, srcTable: "lake.main.orders"},
+				{name: "name", transform: core.TransformDirect, srcTable: "warehouse.crm.customers"},
+			},
+		},
+	})
+
+	// Catalog and schema qualifiers of columns are not tables
+	duckdb, _ := dialect.Get("duckdb")
+	lineage, err := ExtractLineageWithOptions(`SELECT lake.main.orders.id FROM lake.main.orders`, ExtractLineageOptions{Dialect: duckdb})
+	if err != nil {
+		t.Fatalf("ExtractLineageWithOptions failed: %v", err)
+	}
+	if !reflect.DeepEqual(lineage.Sources, []string{"lake.main.orders"}) {
+		t.Errorf("expected sources [lake.main.orders], got %v", lineage.Sources)
+	}
+}
+
 func TestExtractLineage_SelectModifiers(t *testing.T) {
 	runLineageTests(t, []testCase{
 		{
@@ -1220,9 +1545,9 @@ func TestExtractLineage_CTEAdvanced(t *testing.T) {
 			SELECT id, name, level FROM subordinates`,
 			sources: []string{"employees"},
 			cols: []colSpec{
-				{name: "id", transform: core.TransformDirect},
-				{name: "name", transform: core.TransformDirect},
-				{name: "level", transform: core.TransformDirect},
+				{name: "id", transform: core.TransformDirect, srcTable: "employees", srcColumn: "id"},
+				{name: "name", transform: core.TransformDirect, srcTable: "employees", srcColumn: "name"},
+				{name: "level", transform: core.TransformExpression, srcCount: srcN(0)},
 			},
 		},
 		{
@@ -1252,6 +1577,146 @@ func TestExtractLineage_CTEAdvanced(t *testing.T) {
 	})
 }
 
+func TestExtractLineage_RecursiveCTEs(t *testing.T) {
+	runLineageTests(t, []testCase{
+		{
+			name: "recursive member adds sources",
+			sql: `WITH RECURSIVE tree AS (
+				SELECT id, parent_id, name AS path FROM nodes WHERE parent_id IS NULL
+				UNION ALL
+				SELECT n.id, n.parent_id, tree.path || '/' || l.label
+				FROM nodes n
+				JOIN tree ON n.parent_id = tree.id
+				JOIN labels l ON l.node_id = n.id
+			)
+			SELECT id, path FROM tree`,
+			sources: []string{"nodes", "labels"},
+			cols: []colSpec{
+				{name: "id", transform: core.TransformDirect, srcCount: srcN(1), srcTable: "nodes", srcColumn: "id"},
+				{name: "path", transform: core.TransformExpression, srcCount: srcN(2), srcTable: "nodes", srcColumn: "name"},
+			},
+		},
+		{
+			name: "self-reference passes anchor lineage through",
+			sql: `WITH RECURSIVE chain AS (
+				SELECT id, root_id FROM links WHERE root_id IS NULL
+				UNION ALL
+				SELECT l.id, chain.root_id FROM links l JOIN chain ON l.parent_id = chain.id
+			)
+			SELECT root_id FROM chain`,
+			sources: []string{"links"},
+			cols: []colSpec{
+				{name: "root_id", transform: core.TransformDirect, srcCount: srcN(1), srcTable: "links", srcColumn: "root_id"},
+			},
+		},
+		{
+			name: "generated series has no sources",
+			sql: `WITH RECURSIVE nums AS (
+				SELECT 1 AS n
+				UNION ALL
+				SELECT n + 1 FROM nums WHERE n < 10
+			)
+			SELECT nums.n, o.amount FROM nums JOIN orders o ON o.id = nums.n`,
+			sources: []string{"orders"},
+			cols: []colSpec{
+				{name: "n", transform: core.TransformExpression, srcCount: srcN(0)},
+				{name: "amount", transform: core.TransformDirect, srcTable: "orders"},
+			},
+		},
+		{
+			name: "lineage shifts across columns until it converges",
+			sql: `WITH RECURSIVE r AS (
+				SELECT x AS a, 0 AS b, 0 AS c FROM t
+				UNION ALL
+				SELECT 0, a, b FROM r
+			)
+			SELECT a, b, c FROM r`,
+			sources: []string{"t"},
+			cols: []colSpec{
+				{name: "a", transform: core.TransformExpression, srcCount: srcN(1), srcColumn: "x"},
+				{name: "b", transform: core.TransformExpression, srcCount: srcN(1), srcColumn: "x"},
+				{name: "c", transform: core.TransformExpression, srcCount: srcN(1), srcColumn: "x"},
+			},
+		},
+		{
+			name: "star over recursive CTE",
+			sql: `WITH RECURSIVE walk AS (
+				SELECT id, 0 AS depth FROM nodes
+				UNION ALL
+				SELECT n.id, walk.depth + 1 FROM nodes n JOIN walk ON n.parent_id = walk.id
+			)
+			SELECT * FROM walk`,
+			sources: []string{"nodes"},
+			cols: []colSpec{
+				{name: "id", transform: core.TransformDirect, srcTable: "nodes"},
+				{name: "depth", transform: core.TransformExpression, srcCount: srcN(0)},
+			},
+		},
+	})
+}
+
+func TestExtractLineage_RecursiveCTEDiagnostics(t *testing.T) {
+	duckdb, ok := dialect.Get("duckdb")
+	if !ok {
+		t.Fatal("DuckDB dialect not found - ensure duckdb/dialect package is imported")
+	}
+
+	tests := []struct {
+		name     string
+		sql      string
+		maxDepth int
+		warning  string // expected warning substring (empty = no warnings)
+		sources  []string
+	}{
+		{
+			name: "converges within limit",
+			sql: `WITH RECURSIVE r AS (SELECT x AS a, 0 AS b FROM t UNION ALL SELECT 0, a FROM r)
+			      SELECT a, b FROM r`,
+			maxDepth: 2,
+		},
+		{
+			name: "depth limit reached",
+			sql: `WITH RECURSIVE r AS (SELECT x AS a, 0 AS b, 0 AS c FROM t UNION ALL SELECT 0, a, b FROM r)
+			      SELECT a, b, c FROM r`,
+			maxDepth: 1,
+			warning:  `recursive CTE "r" did not converge within 1 iterations`,
+		},
+		{
+			name:    "no anchor member",
+			sql:     `WITH RECURSIVE r AS (SELECT a FROM r) SELECT a FROM r`,
+			warning: `recursive CTE "r" has no anchor member`,
+		},
+		{
+			name: "self-reference is not a source",
+			sql: `WITH RECURSIVE r AS (SELECT id FROM t UNION ALL SELECT id FROM r)
+			      SELECT id FROM r`,
+			sources: []string{"t"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lineage, err := ExtractLineageWithOptions(tt.sql, ExtractLineageOptions{
+				Dialect:           duckdb,
+				MaxRecursionDepth: tt.maxDepth,
+			})
+			if err != nil {
+				t.Fatalf("ExtractLineageWithOptions failed: %v", err)
+			}
+
+			if tt.warning == "" && len(lineage.Warnings) > 0 {
+				t.Errorf("expected no warnings, got %v", lineage.Warnings)
+			}
+			if tt.warning != "" && (len(lineage.Warnings) != 1 || !strings.Contains(lineage.Warnings[0], tt.warning)) {
+				t.Errorf("expected warning containing %q, got %v", tt.warning, lineage.Warnings)
+			}
+			if tt.sources != nil && !reflect.DeepEqual(lineage.Sources, tt.sources) {
+				t.Errorf("expected sources %v, got %v", tt.sources, lineage.Sources)
+			}
+		})
+	}
+}
+
 func TestExtractLineage_RealWorldPatterns(t *testing.T) {
 	runLineageTests(t, []testCase{
 		{
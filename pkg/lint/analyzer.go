@@ -29,6 +29,12 @@ func NewAnalyzerWithRegistry(config *Config, dialect string) *Analyzer {
 // Analyze runs all rules from the dialect against the statement.
 // The stmt parameter should be *core.SelectStmt.
 func (a *Analyzer) Analyze(stmt any, dialect DialectInfo) []Diagnostic {
+	return a.AnalyzeModel(stmt, dialect, nil)
+}
+
+// AnalyzeModel runs all rules from the dialect against the statement of a
+// model, applying the config's model overrides that match it.
+func (a *Analyzer) AnalyzeModel(stmt any, dialect DialectInfo, model *ModelInfo) []Diagnostic {
 	if stmt == nil {
 		return nil
 	}
@@ -66,12 +72,13 @@ func (a *Analyzer) Analyze(stmt any, dialect DialectInfo) []Diagnostic {
 
 		// Apply severity overrides
 		for i := range diags {
-			diags[i].Severity = a.config.GetSeverity(rule.ID(), diags[i].Severity)
+			diags[i].Severity = a.config.GetModelSeverity(rule.ID(), diags[i].Severity, model)
 		}
 
 		diagnostics = append(diagnostics, diags...)
 	}
 
+	SortDiagnostics(diagnostics)
 	return diagnostics
 }
 
@@ -1,6 +1,7 @@
 package lint_test
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,6 +12,7 @@ import (
 	"github.com/leapstack-labs/leapsql/pkg/lint"
 	"github.com/leapstack-labs/leapsql/pkg/lint/sql"
 	"github.com/leapstack-labs/leapsql/pkg/parser"
+	"github.com/leapstack-labs/leapsql/pkg/token"
 )
 
 // registerTestRule registers a test rule and returns a cleanup function.
@@ -233,3 +235,38 @@ func TestAnalyzer_DialectFilter(t *testing.T) {
 	assert.Contains(t, ids, "UNI01", "universal rule should trigger")
 	assert.NotContains(t, ids, "PG01", "postgres-only rule should not trigger for ANSI")
 }
+
+func TestAnalyzer_DiagnosticsOrdered(t *testing.T) {
+	lint.Clear()
+	t.Cleanup(func() {
+		lint.Clear()
+	})
+
+	// Rules run in registry order; diagnostics come out by position, then rule
+	for _, id := range []string{"ORD02", "ORD01", "ORD03"} {
+		sql.Register(sql.RuleDef{
+			ID:       id,
+			Name:     "order-" + id,
+			Group:    "test",
+			Severity: core.SeverityWarning,
+			Check: func(_ any, _ lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
+				return []lint.Diagnostic{
+					{RuleID: id, Message: "second line", Pos: token.Position{Line: 2, Column: 1}},
+					{RuleID: id, Message: "first line", Pos: token.Position{Line: 1, Column: 5}},
+				}
+			},
+		})
+	}
+
+	stmt, err := parser.ParseWithDialect("SELECT a FROM t", duckdbdialect.DuckDB)
+	require.NoError(t, err)
+
+	for range 5 {
+		diags := lint.NewAnalyzer(lint.NewConfig()).Analyze(stmt, duckdbdialect.DuckDB)
+		var got []string
+		for _, d := range diags {
+			got = append(got, fmt.Sprintf("%d:%d %s", d.Pos.Line, d.Pos.Column, d.RuleID))
+		}
+		assert.Equal(t, []string{"1:5 ORD01", "1:5 ORD02", "1:5 ORD03", "2:1 ORD01", "2:1 ORD02", "2:1 ORD03"}, got)
+	}
+}
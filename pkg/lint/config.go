@@ -1,6 +1,14 @@
 package lint
 
-import "github.com/leapstack-labs/leapsql/pkg/core"
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
 
 // Config controls which rules are enabled and their severity.
 type Config struct {
@@ -12,6 +20,14 @@ type Config struct {
 
 	// RuleOptions contains rule-specific configuration
 	RuleOptions map[string]map[string]any
+
+	// ModelOverrides change severities for selected models, applied in order
+	// after SeverityOverrides
+	ModelOverrides []ModelOverride
+
+	// Dialects are the dialects analyzed SQL must also be valid in besides
+	// the one it is written in
+	Dialects []string
 }
 
 // NewConfig creates a default configuration with all rules enabled.
@@ -41,6 +57,17 @@ func (c *Config) GetSeverity(ruleID string, defaultSeverity core.Severity) core.
 	return defaultSeverity
 }
 
+// GetModelSeverity returns the severity for a rule's diagnostic in model,
+// applying any override and then the model overrides matching the model.
+// A nil model only gets rule overrides.
+func (c *Config) GetModelSeverity(ruleID string, defaultSeverity core.Severity, model *ModelInfo) core.Severity {
+	severity := c.GetSeverity(ruleID, defaultSeverity)
+	if c != nil {
+		severity = ResolveModelSeverity(c.ModelOverrides, ruleID, severity, model)
+	}
+	return severity
+}
+
 // Disable disables a rule by ID.
 func (c *Config) Disable(ruleID string) *Config {
 	c.DisabledRules[ruleID] = true
@@ -61,7 +88,8 @@ func (c *Config) GetRuleOptions(ruleID string) map[string]any {
 	return c.RuleOptions[ruleID]
 }
 
-// SetRuleOptions sets options for a specific rule.
+// SetRuleOptions sets options for a specific rule, as given. Use
+// TrySetRuleOptions to validate them against the rule's option schema.
 func (c *Config) SetRuleOptions(ruleID string, opts map[string]any) *Config {
 	if c.RuleOptions == nil {
 		c.RuleOptions = make(map[string]map[string]any)
@@ -69,3 +97,148 @@ func (c *Config) SetRuleOptions(ruleID string, opts map[string]any) *Config {
 	c.RuleOptions[ruleID] = opts
 	return c
 }
+
+// TrySetRuleOptions sets options for a specific rule once they are validated
+// against the rule's option schema. The options are stored converted to their
+// declared types, with the defaults of the options not set. It fails for
+// unknown rules, unknown options and invalid values, leaving the rule's
+// options unchanged.
+func (c *Config) TrySetRuleOptions(ruleID string, opts map[string]any) error {
+	rule, ok := GetRuleByID(ruleID)
+	if !ok {
+		return fmt.Errorf("lint.rules.%s: unknown rule", ruleID)
+	}
+	validated, err := ValidateOptions(rule.Options(), untypedKeys(rule), opts)
+	if err != nil {
+		return fmt.Errorf("lint.rules.%s: %w", ruleID, err)
+	}
+
+	if c.RuleOptions == nil {
+		c.RuleOptions = make(map[string]map[string]any)
+	}
+	c.RuleOptions[ruleID] = validated
+	return nil
+}
+
+// untypedKeys returns the configuration keys of a rule that have no option
+// schema.
+func untypedKeys(rule Rule) []string {
+	var keys []string
+	for _, key := range rule.ConfigKeys() {
+		if !hasOption(rule.Options(), key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func hasOption(options []core.RuleOption, name string) bool {
+	for _, o := range options {
+		if o.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyProject applies the lint section of a project config: the profile,
+// then enabled and disabled rules, severity overrides, model overrides, rule
+// options and secondary dialects, which CV10 checks functions against unless
+// its dialects option is set. A nil config leaves c unchanged. It fails if the profile is
+// unknown or rule options or model overrides are invalid, reporting every
+// problem.
+func (c *Config) ApplyProject(project *core.LintConfig) error {
+	if project == nil {
+		return nil
+	}
+	if project.Profile != "" {
+		if err := c.ApplyProfile(project.Profile); err != nil {
+			return err
+		}
+	}
+	for _, id := range project.Enabled {
+		delete(c.DisabledRules, strings.TrimSpace(id))
+	}
+	for _, id := range project.Disabled {
+		c.Disable(strings.TrimSpace(id))
+	}
+	for id, sev := range project.Severity {
+		if s, ok := core.ParseSeverity(sev); ok {
+			c.SetSeverity(id, s)
+		}
+	}
+
+	ids := make([]string, 0, len(project.Rules))
+	for id := range project.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var errs []error
+	if overrides, err := ParseModelOverrides(project.Overrides); err != nil {
+		errs = append(errs, err)
+	} else {
+		c.ModelOverrides = append(c.ModelOverrides, overrides...)
+	}
+	profile, _ := GetProfile(project.Profile)
+	for _, id := range ids {
+		if err := c.TrySetRuleOptions(id, mergeRuleOptions(profile.Rules[id], project.Rules[id])); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, name := range project.Dialects {
+		c.Dialects = append(c.Dialects, strings.ToLower(strings.TrimSpace(name)))
+	}
+	if len(c.Dialects) > 0 && len(GetStringSliceOption(c.GetRuleOptions(portableFunctionsRuleID), "dialects", nil)) == 0 {
+		opts := make(map[string]any, len(c.GetRuleOptions(portableFunctionsRuleID))+1)
+		maps.Copy(opts, c.GetRuleOptions(portableFunctionsRuleID))
+		opts["dialects"] = c.Dialects
+		if c.RuleOptions == nil {
+			c.RuleOptions = make(map[string]map[string]any)
+		}
+		c.RuleOptions[portableFunctionsRuleID] = opts
+	}
+	return errors.Join(errs...)
+}
+
+// portableFunctionsRuleID is the rule checking functions have an equivalent
+// in the dialects of its dialects option, CV10.
+const portableFunctionsRuleID = "CV10"
+
+// ApplyProfile applies a bundled profile's disabled rules, severities and
+// rule options.
+func (c *Config) ApplyProfile(name string) error {
+	profile, ok := GetProfile(name)
+	if !ok {
+		return fmt.Errorf("lint.profile: unknown profile %q: must be one of %v", name, ListProfiles())
+	}
+	for _, id := range profile.Disabled {
+		c.Disable(id)
+	}
+	for id, sev := range profile.Severity {
+		c.SetSeverity(id, sev)
+	}
+	for id, opts := range profile.Rules {
+		if err := c.TrySetRuleOptions(id, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeRuleOptions returns the profile's options for a rule overridden by the
+// project's.
+func mergeRuleOptions(profile, project core.RuleOptions) map[string]any {
+	if len(profile) == 0 {
+		return project
+	}
+	merged := make(map[string]any, len(profile)+len(project))
+	for k, v := range profile {
+		merged[k] = v
+	}
+	for k, v := range project {
+		merged[k] = v
+	}
+	return merged
+}
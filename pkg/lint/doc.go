@@ -55,6 +55,13 @@
 //	config.SetSeverity("CV05", core.SeverityError)
 //	config.SetRuleOptions("AL06", map[string]any{"min_length": 3})
 //
+// SetRuleOptions sets options as given; TrySetRuleOptions validates them
+// against the rule's option schema first:
+//
+//	if err := config.TrySetRuleOptions("AL06", map[string]any{"min_length": 3}); err != nil {
+//		return err
+//	}
+//
 // # Creating Custom Rules
 //
 // For SQL rules, implement the SQLRule interface or use RuleDef:
@@ -0,0 +1,48 @@
+package lint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// Fingerprinter assigns stable fingerprints to lint diagnostics, so external
+// dashboards can deduplicate findings across runs. A fingerprint hashes the
+// rule ID, the model, the message and the source line the diagnostic points
+// at, but not its position: it is unchanged when unrelated lines are added
+// or removed. Identical diagnostics are numbered in the order they are
+// fingerprinted, so each gets a distinct fingerprint.
+type Fingerprinter struct {
+	seen map[string]int
+}
+
+// NewFingerprinter creates a Fingerprinter.
+func NewFingerprinter() *Fingerprinter {
+	return &Fingerprinter{seen: make(map[string]int)}
+}
+
+// Fingerprint returns the fingerprint of a diagnostic reported on a model.
+// line is the source line the diagnostic points at (see SourceLine), or ""
+// for diagnostics without a position.
+func (f *Fingerprinter) Fingerprint(ruleID, model, message, line string) string {
+	key := strings.Join([]string{ruleID, model, message, line}, "\x00")
+	occurrence := f.seen[key]
+	f.seen[key]++
+
+	sum := sha256.Sum256([]byte(key + "\x00" + strconv.Itoa(occurrence)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// SourceLine returns line n (1-based) of src with runs of whitespace
+// collapsed to one space, or "" if src has no such line.
+func SourceLine(src string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	lines := strings.Split(src, "\n")
+	if n > len(lines) {
+		return ""
+	}
+	return strings.Join(strings.Fields(lines[n-1]), " ")
+}
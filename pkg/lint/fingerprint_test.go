@@ -0,0 +1,33 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprinter(t *testing.T) {
+	fp := NewFingerprinter()
+	first := fp.Fingerprint("AM01", "staging.orders", "Ambiguous DISTINCT", "SELECT DISTINCT id")
+	second := fp.Fingerprint("AM01", "staging.orders", "Ambiguous DISTINCT", "SELECT DISTINCT id")
+	other := fp.Fingerprint("AM01", "staging.users", "Ambiguous DISTINCT", "SELECT DISTINCT id")
+
+	assert.Len(t, first, 32)
+	assert.NotEqual(t, first, second, "identical diagnostics should be numbered")
+	assert.NotEqual(t, first, other)
+
+	again := NewFingerprinter()
+	assert.Equal(t, first, again.Fingerprint("AM01", "staging.orders", "Ambiguous DISTINCT", "SELECT DISTINCT id"),
+		"fingerprints should be stable across runs")
+	assert.Equal(t, second, again.Fingerprint("AM01", "staging.orders", "Ambiguous DISTINCT", "SELECT DISTINCT id"))
+}
+
+func TestSourceLine(t *testing.T) {
+	const src = "SELECT id,\n    name   AS  n\nFROM users"
+
+	assert.Equal(t, "name AS n", SourceLine(src, 2))
+	assert.Equal(t, "SELECT id,", SourceLine(src, 1))
+	assert.Empty(t, SourceLine(src, 0))
+	assert.Empty(t, SourceLine(src, 4))
+	assert.Equal(t, SourceLine(src, 2), SourceLine("\n  name AS n  \n", 2), "indentation should not change the line")
+}
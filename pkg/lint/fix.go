@@ -0,0 +1,87 @@
+package lint
+
+import (
+	"sort"
+	"strings"
+)
+
+// ApplyFixes applies the first fix of each diagnostic to src and returns the
+// rewritten source along with the diagnostics left unfixed. Suggestions are
+// only applied when unsafe is true. Fixes whose edits overlap an edit that was
+// already accepted are skipped so a single pass never produces garbled SQL.
+func ApplyFixes(src string, diagnostics []Diagnostic, unsafe bool) (string, []Diagnostic) {
+	var edits []TextEdit
+	var unfixed []Diagnostic
+	for _, d := range diagnostics {
+		fix, ok := applicableFix(d, unsafe)
+		if !ok || !validEdits(fix.TextEdits, len(src)) || overlapsAny(fix.TextEdits, edits) {
+			unfixed = append(unfixed, d)
+			continue
+		}
+		edits = append(edits, fix.TextEdits...)
+	}
+	if len(edits) == 0 {
+		return src, unfixed
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].Pos.Offset < edits[j].Pos.Offset
+	})
+
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		b.WriteString(src[last:e.Pos.Offset])
+		b.WriteString(e.NewText)
+		last = e.EndPos.Offset
+	}
+	b.WriteString(src[last:])
+	return b.String(), unfixed
+}
+
+// IsFixable reports whether ApplyFixes would consider d.
+func IsFixable(d Diagnostic, unsafe bool) bool {
+	_, ok := applicableFix(d, unsafe)
+	return ok
+}
+
+func applicableFix(d Diagnostic, unsafe bool) (Fix, bool) {
+	if len(d.Fixes) == 0 || len(d.Fixes[0].TextEdits) == 0 {
+		return Fix{}, false
+	}
+	fix := d.Fixes[0]
+	if fix.Kind == FixSuggestion && !unsafe {
+		return Fix{}, false
+	}
+	return fix, true
+}
+
+// validEdits reports whether every edit lies within the source and no two
+// edits of the same fix overlap.
+func validEdits(edits []TextEdit, size int) bool {
+	for i, e := range edits {
+		if e.Pos.Offset < 0 || e.EndPos.Offset < e.Pos.Offset || e.EndPos.Offset > size {
+			return false
+		}
+		if overlapsAny(edits[i+1:], edits[i:i+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// overlapsAny reports whether any of edits touches a range in accepted.
+func overlapsAny(edits, accepted []TextEdit) bool {
+	for _, e := range edits {
+		for _, a := range accepted {
+			if e.Pos.Offset < a.EndPos.Offset && a.Pos.Offset < e.EndPos.Offset {
+				return true
+			}
+			// Two insertions at the same point have no defined order
+			if e.Pos.Offset == a.Pos.Offset && (e.Pos.Offset == e.EndPos.Offset || a.Pos.Offset == a.EndPos.Offset) {
+				return true
+			}
+		}
+	}
+	return false
+}
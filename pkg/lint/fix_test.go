@@ -0,0 +1,72 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func replace(start, end int, text string) TextEdit {
+	return TextEdit{Pos: token.Position{Offset: start}, EndPos: token.Position{Offset: end}, NewText: text}
+}
+
+func TestApplyFixes(t *testing.T) {
+	const src = "SELECT COUNT(1) FROM t"
+	safe := Diagnostic{Fixes: []Fix{{TextEdits: []TextEdit{replace(13, 14, "*")}}}}
+	suggestion := Diagnostic{Fixes: []Fix{{Kind: FixSuggestion, TextEdits: []TextEdit{replace(21, 22, "u")}}}}
+	overlapping := Diagnostic{Fixes: []Fix{{TextEdits: []TextEdit{replace(7, 15, "1")}}}}
+	outOfRange := Diagnostic{Fixes: []Fix{{TextEdits: []TextEdit{replace(20, 40, "")}}}}
+
+	tests := []struct {
+		name      string
+		diags     []Diagnostic
+		unsafe    bool
+		want      string
+		wantFixed int
+	}{
+		{name: "no fixes", diags: []Diagnostic{{}}, want: src},
+		{name: "safe fix", diags: []Diagnostic{safe}, want: "SELECT COUNT(*) FROM t", wantFixed: 1},
+		{name: "suggestion skipped", diags: []Diagnostic{safe, suggestion}, want: "SELECT COUNT(*) FROM t", wantFixed: 1},
+		{name: "suggestion applied when unsafe", diags: []Diagnostic{suggestion, safe}, unsafe: true, want: "SELECT COUNT(*) FROM u", wantFixed: 2},
+		{name: "overlapping fix skipped", diags: []Diagnostic{safe, overlapping}, want: "SELECT COUNT(*) FROM t", wantFixed: 1},
+		{name: "out of range fix skipped", diags: []Diagnostic{outOfRange}, want: src},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, unfixed := ApplyFixes(src, tt.diags, tt.unsafe)
+			assert.Equal(t, tt.want, got)
+			assert.Len(t, unfixed, len(tt.diags)-tt.wantFixed)
+		})
+	}
+}
+
+func TestMapRanges(t *testing.T) {
+	at := func(offset int) token.Position { return token.Position{Line: 1, Column: offset + 1, Offset: offset} }
+	diags := []Diagnostic{
+		{Pos: at(2), EndPos: at(4), RelatedInfo: []RelatedInfo{
+			{Pos: at(6)},
+			{FilePath: "other.sql", Pos: at(6)},
+		}},
+		{Pos: at(10)},
+		{},
+	}
+
+	// Shift by 100 and widen offsets from 10 to a range, as an expression would
+	MapRanges(diags, func(pos, end token.Position) (token.Position, token.Position) {
+		if pos.Offset == 10 {
+			return at(110), at(118)
+		}
+		return at(pos.Offset + 100), at(end.Offset + 100)
+	})
+
+	assert.Equal(t, at(102), diags[0].Pos)
+	assert.Equal(t, at(104), diags[0].EndPos)
+	assert.Equal(t, at(106), diags[0].RelatedInfo[0].Pos)
+	assert.Equal(t, token.Position{}, diags[0].RelatedInfo[0].EndPos, "an empty range keeps no end")
+	assert.Equal(t, at(6), diags[0].RelatedInfo[1].Pos, "other files are left alone")
+	assert.Equal(t, at(110), diags[1].Pos)
+	assert.Equal(t, at(118), diags[1].EndPos)
+	assert.Equal(t, Diagnostic{}, diags[2], "diagnostics without a position are left alone")
+}
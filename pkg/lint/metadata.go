@@ -5,8 +5,9 @@ import (
 	"strings"
 )
 
-// DefaultDocsBaseURL is the hosted documentation site.
-const DefaultDocsBaseURL = "https://leapsql.dev/docs/rules"
+// DefaultDocsBaseURL is where the hosted documentation site serves the rule
+// pages generated by scripts/gendocs.
+const DefaultDocsBaseURL = "https://leapstack-labs.github.io/leapsql/linting/rules"
 
 // DocsBaseURL can be overridden via config for local/offline mode.
 var DocsBaseURL = DefaultDocsBaseURL
@@ -1,5 +1,15 @@
 package lint
 
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
 // GetOption extracts a typed option with a default value.
 func GetOption[T any](opts map[string]any, key string, defaultVal T) T {
 	if opts == nil {
@@ -91,3 +101,176 @@ func GetStringSliceOption(opts map[string]any, key string, defaultVal []string)
 		return defaultVal
 	}
 }
+
+// OptionKeys returns the names of a rule's options, followed by the names of
+// untyped configuration keys.
+func OptionKeys(options []core.RuleOption, configKeys []string) []string {
+	if len(options) == 0 {
+		return configKeys
+	}
+	keys := make([]string, 0, len(options)+len(configKeys))
+	for _, o := range options {
+		keys = append(keys, o.Name)
+	}
+	return append(keys, configKeys...)
+}
+
+// ValidateOptions checks options set in the config against a rule's option
+// schema. It returns the options converted to their declared types (int,
+// string, bool or []string), with the defaults of the options not set.
+// Untyped configuration keys are accepted with any value. All problems are
+// reported in a single error.
+func ValidateOptions(schema []core.RuleOption, configKeys []string, opts map[string]any) (map[string]any, error) {
+	declared := make(map[string]core.RuleOption, len(schema)+len(configKeys))
+	for _, key := range configKeys {
+		declared[key] = core.RuleOption{Name: key}
+	}
+	for _, o := range schema {
+		declared[o.Name] = o
+	}
+
+	keys := make([]string, 0, len(opts))
+	for key := range opts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make(map[string]any, len(schema)+len(opts))
+	var problems []string
+	for _, key := range keys {
+		o, ok := declared[key]
+		if !ok {
+			problems = append(problems, unknownOptionProblem(key, schema, configKeys))
+			continue
+		}
+		v, err := convertOption(o, opts[key])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		result[key] = v
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+
+	for _, o := range schema {
+		if _, ok := result[o.Name]; !ok && o.Default != nil {
+			result[o.Name] = o.Default
+		}
+	}
+	return result, nil
+}
+
+// unknownOptionProblem describes an option a rule does not accept.
+func unknownOptionProblem(key string, schema []core.RuleOption, configKeys []string) string {
+	keys := OptionKeys(schema, configKeys)
+	if len(keys) == 0 {
+		return fmt.Sprintf("unknown option %q (the rule has no options)", key)
+	}
+	return fmt.Sprintf("unknown option %q (options: %s)", key, strings.Join(keys, ", "))
+}
+
+// convertOption converts an option's value to its declared type and checks
+// its constraints. Numbers decoded from YAML or JSON may be any numeric type.
+func convertOption(o core.RuleOption, v any) (any, error) {
+	switch o.Type {
+	case core.OptionInt:
+		n, ok := toInt(v)
+		if !ok {
+			return nil, fmt.Errorf("must be an integer, got %s", describeValue(v))
+		}
+		if o.Min != 0 && n < o.Min {
+			return nil, fmt.Errorf("must be at least %d, got %d", o.Min, n)
+		}
+		if o.Max != 0 && n > o.Max {
+			return nil, fmt.Errorf("must be at most %d, got %d", o.Max, n)
+		}
+		return n, nil
+
+	case core.OptionString:
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a string, got %s", describeValue(v))
+		}
+		if err := checkEnum(o, s); err != nil {
+			return nil, err
+		}
+		return s, nil
+
+	case core.OptionBool:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("must be true or false, got %s", describeValue(v))
+		}
+		return b, nil
+
+	case core.OptionStringList:
+		var items []any
+		switch list := v.(type) {
+		case []string:
+			for _, s := range list {
+				items = append(items, s)
+			}
+		case []any:
+			items = list
+		default:
+			return nil, fmt.Errorf("must be a list of strings, got %s", describeValue(v))
+		}
+		result := make([]string, 0, len(items))
+		for i, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("item %d must be a string, got %s", i+1, describeValue(item))
+			}
+			if err := checkEnum(o, s); err != nil {
+				return nil, fmt.Errorf("item %d %w", i+1, err)
+			}
+			result = append(result, s)
+		}
+		return result, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// toInt converts a whole number of any numeric type to an int.
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case uint64:
+		return int(n), n <= math.MaxInt
+	case float64:
+		return int(n), n == math.Trunc(n) && math.Abs(n) <= math.MaxInt32
+	default:
+		return 0, false
+	}
+}
+
+// checkEnum checks a string against an option's allowed values.
+func checkEnum(o core.RuleOption, s string) error {
+	if len(o.Enum) == 0 || slices.Contains(o.Enum, s) {
+		return nil
+	}
+	return fmt.Errorf("must be one of %s, got %q", strings.Join(o.Enum, ", "), s)
+}
+
+// describeValue describes an invalid option value for error messages.
+func describeValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "nothing"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []any, []string:
+		return "a list"
+	case map[string]any:
+		return "a map"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
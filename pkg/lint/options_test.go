@@ -0,0 +1,165 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOptionSchema = []core.RuleOption{
+	{Name: "max_length", Type: core.OptionInt, Default: 30, Min: 1, Max: 100},
+	{Name: "style", Type: core.OptionString, Default: "upper", Enum: []string{"upper", "lower"}},
+	{Name: "strict", Type: core.OptionBool},
+	{Name: "words", Type: core.OptionStringList, Default: []string{"DROP"}},
+}
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    map[string]any
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name: "defaults",
+			opts: nil,
+			want: map[string]any{"max_length": 30, "style": "upper", "words": []string{"DROP"}},
+		},
+		{
+			name: "values converted to their types",
+			opts: map[string]any{"max_length": float64(10), "strict": true, "words": []any{"DELETE", "TRUNCATE"}},
+			want: map[string]any{"max_length": 10, "style": "upper", "strict": true, "words": []string{"DELETE", "TRUNCATE"}},
+		},
+		{
+			name:    "wrong type",
+			opts:    map[string]any{"max_length": "ten"},
+			wantErr: `max_length: must be an integer, got "ten"`,
+		},
+		{
+			name:    "fractional number",
+			opts:    map[string]any{"max_length": 2.5},
+			wantErr: "max_length: must be an integer, got 2.5",
+		},
+		{
+			name:    "below minimum",
+			opts:    map[string]any{"max_length": 0},
+			wantErr: "max_length: must be at least 1, got 0",
+		},
+		{
+			name:    "above maximum",
+			opts:    map[string]any{"max_length": 200},
+			wantErr: "max_length: must be at most 100, got 200",
+		},
+		{
+			name:    "value not allowed",
+			opts:    map[string]any{"style": "camel"},
+			wantErr: `style: must be one of upper, lower, got "camel"`,
+		},
+		{
+			name:    "list item of the wrong type",
+			opts:    map[string]any{"words": []any{"DROP", 1}},
+			wantErr: "words: item 2 must be a string, got 1",
+		},
+		{
+			name:    "list expected",
+			opts:    map[string]any{"words": "DROP"},
+			wantErr: `words: must be a list of strings, got "DROP"`,
+		},
+		{
+			name:    "unknown option",
+			opts:    map[string]any{"max_len": 10},
+			wantErr: `unknown option "max_len" (options: max_length, style, strict, words)`,
+		},
+		{
+			name:    "all problems reported",
+			opts:    map[string]any{"strict": "yes", "max_length": "ten"},
+			wantErr: `max_length: must be an integer, got "ten"; strict: must be true or false, got "yes"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateOptions(testOptionSchema, nil, tt.opts)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateOptions_ConfigKeys(t *testing.T) {
+	got, err := ValidateOptions(nil, []string{"legacy"}, map[string]any{"legacy": []any{1, "a"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"legacy": []any{1, "a"}}, got)
+
+	_, err = ValidateOptions(nil, nil, map[string]any{"legacy": true})
+	require.Error(t, err)
+	assert.Equal(t, `unknown option "legacy" (the rule has no options)`, err.Error())
+}
+
+func TestConfig_SetRuleOptions(t *testing.T) {
+	cfg := NewConfig().SetRuleOptions("OPT01", map[string]any{"max_length": -1})
+	assert.Equal(t, map[string]any{"max_length": -1}, cfg.GetRuleOptions("OPT01"), "options are set as given")
+}
+
+func TestConfig_TrySetRuleOptions(t *testing.T) {
+	Clear()
+	RegisterSQLRule(&mockSQLRule{id: "OPT01", options: testOptionSchema})
+
+	cfg := NewConfig()
+	require.NoError(t, cfg.TrySetRuleOptions("OPT01", map[string]any{"max_length": 12}))
+	assert.Equal(t, 12, cfg.GetRuleOptions("OPT01")["max_length"])
+	assert.Equal(t, "upper", cfg.GetRuleOptions("OPT01")["style"])
+
+	err := cfg.TrySetRuleOptions("OPT01", map[string]any{"max_length": -1})
+	require.Error(t, err)
+	assert.Equal(t, "lint.rules.OPT01: max_length: must be at least 1, got -1", err.Error())
+	assert.Equal(t, 12, cfg.GetRuleOptions("OPT01")["max_length"], "invalid options are not applied")
+
+	err = cfg.TrySetRuleOptions("NOPE01", map[string]any{"max_length": 1})
+	require.Error(t, err)
+	assert.Equal(t, "lint.rules.NOPE01: unknown rule", err.Error())
+}
+
+func TestConfig_ApplyProject(t *testing.T) {
+	Clear()
+	RegisterSQLRule(&mockSQLRule{id: "OPT01", options: testOptionSchema})
+
+	cfg := NewConfig()
+	err := cfg.ApplyProject(&core.LintConfig{
+		Disabled: []string{"AM01"},
+		Rules: map[string]core.RuleOptions{
+			"OPT01":  {"style": "snake"},
+			"NOPE01": {"max_length": 1},
+		},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "lint.rules.NOPE01: unknown rule\nlint.rules.OPT01: style: must be one of upper, lower, got \"snake\"", err.Error())
+	assert.True(t, cfg.IsDisabled("AM01"))
+
+	cfg = NewConfig()
+	require.NoError(t, cfg.ApplyProject(&core.LintConfig{
+		Overrides: []core.LintOverride{{Models: []string{"tag:tier1"}, WarningsAsErrors: true}},
+	}))
+	require.Len(t, cfg.ModelOverrides, 1)
+	assert.Equal(t, []string{"tag:tier1"}, cfg.ModelOverrides[0].Selectors)
+
+	// Secondary dialects are CV10's dialects unless the project sets them
+	cfg = NewConfig()
+	require.NoError(t, cfg.ApplyProject(&core.LintConfig{Dialects: []string{" Snowflake"}}))
+	assert.Equal(t, []string{"snowflake"}, cfg.Dialects)
+	assert.Equal(t, []string{"snowflake"}, cfg.GetRuleOptions("CV10")["dialects"])
+
+	cfg = NewConfig()
+	cfg.RuleOptions["CV10"] = map[string]any{"dialects": []string{"postgres"}}
+	require.NoError(t, cfg.ApplyProject(&core.LintConfig{Dialects: []string{"snowflake"}}))
+	assert.Equal(t, []string{"postgres"}, cfg.GetRuleOptions("CV10")["dialects"])
+
+	require.NoError(t, NewConfig().ApplyProject(nil))
+}
@@ -0,0 +1,128 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// ModelOverride changes rule severities for the models matching any of its
+// selectors, e.g. to treat warnings as errors on models tagged tier1.
+type ModelOverride struct {
+	// Selectors are tag:<tag>, owner:<owner>, group:<group> or path:<dir>
+	Selectors []string
+
+	// WarningsAsErrors raises the warnings of all rules to errors
+	WarningsAsErrors bool
+
+	// Severity overrides the severity of individual rules
+	Severity map[string]core.Severity
+}
+
+// ParseModelOverrides converts the overrides of a project's lint config,
+// reporting every invalid selector and severity.
+func ParseModelOverrides(overrides []core.LintOverride) ([]ModelOverride, error) {
+	var errs []error
+	result := make([]ModelOverride, 0, len(overrides))
+	for i, o := range overrides {
+		prefix := fmt.Sprintf("lint.overrides[%d]", i)
+		if len(o.Models) == 0 {
+			errs = append(errs, fmt.Errorf("%s: models is required", prefix))
+		}
+		for _, sel := range o.Models {
+			if err := validateSelector(sel); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", prefix, err))
+			}
+		}
+
+		override := ModelOverride{
+			Selectors:        o.Models,
+			WarningsAsErrors: o.WarningsAsErrors,
+			Severity:         make(map[string]core.Severity, len(o.Severity)),
+		}
+		ids := make([]string, 0, len(o.Severity))
+		for id := range o.Severity {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			sev, ok := core.ParseSeverity(o.Severity[id])
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s.severity.%s: must be one of error, warning, info, hint, got %q", prefix, id, o.Severity[id]))
+				continue
+			}
+			override.Severity[id] = sev
+		}
+		result = append(result, override)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return result, nil
+}
+
+// validateSelector checks that a selector has a known kind and a value.
+func validateSelector(sel string) error {
+	kind, value, ok := strings.Cut(sel, ":")
+	if !ok || value == "" {
+		return fmt.Errorf("invalid model selector %q (expected tag:, owner:, group: or path:)", sel)
+	}
+	switch kind {
+	case "tag", "owner", "group", "path":
+		return nil
+	default:
+		return fmt.Errorf("unknown model selector %q (expected tag:, owner:, group: or path:)", sel)
+	}
+}
+
+// Matches reports whether the model matches any of the override's selectors.
+// path: selectors match consecutive directories of the model's file, like
+// layer paths.
+func (o ModelOverride) Matches(model *ModelInfo) bool {
+	if model == nil {
+		return false
+	}
+	for _, sel := range o.Selectors {
+		kind, value, _ := strings.Cut(sel, ":")
+		switch kind {
+		case "tag":
+			if slices.Contains(model.Tags, value) {
+				return true
+			}
+		case "owner":
+			if model.Owner == value {
+				return true
+			}
+		case "group":
+			if model.Group == value {
+				return true
+			}
+		case "path":
+			if (core.LayerConfig{Paths: []string{value}}).MatchesPath(model.FilePath) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ResolveModelSeverity applies the overrides matching model to the severity
+// of a diagnostic, in order: a later override wins. A rule's severity in an
+// override takes precedence over its WarningsAsErrors.
+func ResolveModelSeverity(overrides []ModelOverride, ruleID string, severity core.Severity, model *ModelInfo) core.Severity {
+	for _, o := range overrides {
+		if !o.Matches(model) {
+			continue
+		}
+		if sev, ok := o.Severity[ruleID]; ok {
+			severity = sev
+		} else if o.WarningsAsErrors && severity == core.SeverityWarning {
+			severity = core.SeverityError
+		}
+	}
+	return severity
+}
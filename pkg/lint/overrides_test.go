@@ -0,0 +1,77 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModelOverrides(t *testing.T) {
+	overrides, err := ParseModelOverrides([]core.LintOverride{
+		{Models: []string{"tag:tier1", "path:marts/finance"}, WarningsAsErrors: true, Severity: map[string]string{"PM04": "info"}},
+	})
+	require.NoError(t, err)
+	require.Len(t, overrides, 1)
+	assert.Equal(t, map[string]core.Severity{"PM04": core.SeverityInfo}, overrides[0].Severity)
+
+	_, err = ParseModelOverrides([]core.LintOverride{
+		{Models: []string{"tier1", "team:data"}},
+		{Severity: map[string]string{"AM01": "fatal"}},
+	})
+	require.Error(t, err)
+	assert.Equal(t, `lint.overrides[0]: invalid model selector "tier1" (expected tag:, owner:, group: or path:)
+lint.overrides[0]: unknown model selector "team:data" (expected tag:, owner:, group: or path:)
+lint.overrides[1]: models is required
+lint.overrides[1].severity.AM01: must be one of error, warning, info, hint, got "fatal"`, err.Error())
+}
+
+func TestModelOverride_Matches(t *testing.T) {
+	model := &ModelInfo{
+		Path:     "marts.fct_revenue",
+		FilePath: "/project/models/marts/finance/fct_revenue.sql",
+		Tags:     []string{"tier1"},
+		Owner:    "alice",
+		Group:    "finance",
+	}
+
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"tag:tier1", true},
+		{"tag:tier2", false},
+		{"owner:alice", true},
+		{"owner:bob", false},
+		{"group:finance", true},
+		{"path:marts/finance", true},
+		{"path:marts/*", true},
+		{"path:staging", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			assert.Equal(t, tt.want, ModelOverride{Selectors: []string{tt.selector}}.Matches(model))
+		})
+	}
+	assert.False(t, ModelOverride{Selectors: []string{"tag:tier1"}}.Matches(nil))
+}
+
+func TestResolveModelSeverity(t *testing.T) {
+	critical := &ModelInfo{Tags: []string{"tier1"}}
+	other := &ModelInfo{Tags: []string{"tier3"}}
+	overrides := []ModelOverride{
+		{Selectors: []string{"tag:tier1"}, WarningsAsErrors: true, Severity: map[string]core.Severity{"PM04": core.SeverityInfo}},
+	}
+
+	assert.Equal(t, core.SeverityError, ResolveModelSeverity(overrides, "AM01", core.SeverityWarning, critical))
+	assert.Equal(t, core.SeverityInfo, ResolveModelSeverity(overrides, "AM02", core.SeverityInfo, critical), "only warnings are raised")
+	assert.Equal(t, core.SeverityInfo, ResolveModelSeverity(overrides, "PM04", core.SeverityWarning, critical), "rule severity wins")
+	assert.Equal(t, core.SeverityWarning, ResolveModelSeverity(overrides, "AM01", core.SeverityWarning, other))
+
+	cfg := NewConfig()
+	cfg.SetSeverity("AM01", core.SeverityWarning)
+	cfg.ModelOverrides = overrides
+	assert.Equal(t, core.SeverityError, cfg.GetModelSeverity("AM01", core.SeverityHint, critical), "applied after rule overrides")
+	assert.Equal(t, core.SeverityWarning, cfg.GetModelSeverity("AM01", core.SeverityHint, nil))
+}
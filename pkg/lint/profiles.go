@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"sort"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// Profile is a named preset of lint settings, selected with `profile` in the
+// lint config. A profile is applied before the rest of the lint config, so
+// projects start from its baseline and override individual rules.
+type Profile struct {
+	Name        string
+	Description string
+
+	// Disabled contains the rule IDs the profile turns off
+	Disabled []string
+
+	// Severity maps rule ID to the profile's severity for the rule
+	Severity map[string]core.Severity
+
+	// Rules contains rule-specific options
+	Rules map[string]core.RuleOptions
+}
+
+// Bundled profile names.
+const (
+	ProfileMinimal         = "minimal"
+	ProfileStrict          = "strict"
+	ProfileDbtStyle        = "dbt-style"
+	ProfileSQLFluffDefault = "sqlfluff-default"
+)
+
+// styleRules are the rules that only enforce a preferred way of writing SQL,
+// as opposed to flagging queries that are likely wrong.
+var styleRules = []string{
+	"AL03", "AL06", "AL07", "AL09",
+	"AM02", "AM05",
+	"CV01", "CV02", "CV04", "CV08",
+	"RF03",
+	"ST01", "ST02", "ST04", "ST06", "ST07", "ST08", "ST09", "ST10",
+}
+
+var profiles = map[string]Profile{
+	ProfileMinimal: {
+		Name:        ProfileMinimal,
+		Description: "Only rules that catch likely bugs; style rules are off",
+		Disabled:    styleRules,
+	},
+	ProfileStrict: {
+		Name:        ProfileStrict,
+		Description: "Every rule on, warnings are errors and informational rules are warnings",
+		Severity: map[string]core.Severity{
+			// Warnings become errors
+			"AL05": core.SeverityError, "AL07": core.SeverityError, "AL08": core.SeverityError,
+			"AM01": core.SeverityError, "AM03": core.SeverityError, "AM06": core.SeverityError,
+			"AM08": core.SeverityError, "AM09": core.SeverityError,
+			"CV05": core.SeverityError, "CV09": core.SeverityError, "CV10": core.SeverityError,
+			"RF02": core.SeverityError,
+			"ST03": core.SeverityError,
+			// Informational rules become warnings
+			"AL03": core.SeverityWarning, "AL06": core.SeverityWarning,
+			"AM02": core.SeverityWarning, "AM05": core.SeverityWarning,
+			"RF03": core.SeverityWarning,
+			"ST04": core.SeverityWarning, "ST08": core.SeverityWarning, "ST10": core.SeverityWarning,
+		},
+	},
+	ProfileDbtStyle: {
+		Name:        ProfileDbtStyle,
+		Description: "The conventions of the dbt Labs SQL style guide",
+		Severity: map[string]core.Severity{
+			"AM02": core.SeverityWarning, // union all over union
+			"AM05": core.SeverityWarning, // explicit joins over comma-separated tables
+			"CV01": core.SeverityWarning, // != over <>
+			"CV02": core.SeverityWarning, // coalesce over ifnull/nvl
+			"CV04": core.SeverityWarning, // count(*) over count(1)
+			"CV08": core.SeverityWarning, // left join over right join
+			"RF02": core.SeverityError,   // qualify columns when joining
+			"AL06": core.SeverityWarning, // no initialisms as aliases
+		},
+		Rules: map[string]core.RuleOptions{
+			"AL06": {"min_length": 3},
+		},
+	},
+	ProfileSQLFluffDefault: {
+		Name:        ProfileSQLFluffDefault,
+		Description: "Like SQLFluff's defaults: every rule on, and every violation at least a warning",
+		Severity: map[string]core.Severity{
+			"AL03": core.SeverityWarning, "AL06": core.SeverityWarning, "AL09": core.SeverityWarning,
+			"AM02": core.SeverityWarning, "AM05": core.SeverityWarning,
+			"CV01": core.SeverityWarning, "CV02": core.SeverityWarning, "CV04": core.SeverityWarning,
+			"CV08": core.SeverityWarning,
+			"RF03": core.SeverityWarning,
+			"ST01": core.SeverityWarning, "ST02": core.SeverityWarning, "ST04": core.SeverityWarning,
+			"ST06": core.SeverityWarning, "ST07": core.SeverityWarning, "ST08": core.SeverityWarning,
+			"ST09": core.SeverityWarning, "ST10": core.SeverityWarning,
+		},
+	},
+}
+
+// GetProfile returns the bundled profile with the given name.
+func GetProfile(name string) (Profile, bool) {
+	p, ok := profiles[name]
+	return p, ok
+}
+
+// ListProfiles returns the names of the bundled profiles, sorted.
+func ListProfiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
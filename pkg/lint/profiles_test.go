@@ -0,0 +1,82 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListProfiles(t *testing.T) {
+	assert.Equal(t, []string{"dbt-style", "minimal", "sqlfluff-default", "strict"}, ListProfiles())
+
+	for _, name := range ListProfiles() {
+		p, ok := GetProfile(name)
+		require.True(t, ok)
+		assert.Equal(t, name, p.Name)
+		assert.NotEmpty(t, p.Description)
+	}
+
+	_, ok := GetProfile("lenient")
+	assert.False(t, ok)
+}
+
+func TestConfig_ApplyProfile(t *testing.T) {
+	Clear()
+	RegisterSQLRule(&mockSQLRule{id: "AL06", options: []core.RuleOption{
+		{Name: "min_length", Type: core.OptionInt, Default: 1, Min: 1},
+		{Name: "max_length", Type: core.OptionInt, Default: 30, Min: 1},
+	}})
+
+	cfg := NewConfig()
+	require.NoError(t, cfg.ApplyProfile(ProfileMinimal))
+	assert.True(t, cfg.IsDisabled("ST06"))
+	assert.False(t, cfg.IsDisabled("AM04"))
+
+	cfg = NewConfig()
+	require.NoError(t, cfg.ApplyProfile(ProfileStrict))
+	assert.Equal(t, core.SeverityError, cfg.GetSeverity("AM01", core.SeverityWarning))
+	assert.Equal(t, core.SeverityWarning, cfg.GetSeverity("AL03", core.SeverityInfo))
+
+	cfg = NewConfig()
+	require.NoError(t, cfg.ApplyProfile(ProfileDbtStyle))
+	assert.Equal(t, 3, cfg.GetRuleOptions("AL06")["min_length"])
+
+	err := NewConfig().ApplyProfile("lenient")
+	require.Error(t, err)
+	assert.Equal(t, `lint.profile: unknown profile "lenient": must be one of [dbt-style minimal sqlfluff-default strict]`, err.Error())
+}
+
+func TestConfig_ApplyProject_Profile(t *testing.T) {
+	Clear()
+	RegisterSQLRule(&mockSQLRule{id: "AL06", options: []core.RuleOption{
+		{Name: "min_length", Type: core.OptionInt, Default: 1, Min: 1},
+		{Name: "max_length", Type: core.OptionInt, Default: 30, Min: 1},
+	}})
+
+	cfg := NewConfig()
+	require.NoError(t, cfg.ApplyProject(&core.LintConfig{
+		Profile:  ProfileMinimal,
+		Enabled:  []string{"ST06"},
+		Severity: map[string]string{"ST06": "warning"},
+	}))
+	assert.False(t, cfg.IsDisabled("ST06"), "enabled re-enables a rule the profile disables")
+	assert.True(t, cfg.IsDisabled("ST07"))
+	assert.Equal(t, core.SeverityWarning, cfg.GetSeverity("ST06", core.SeverityHint))
+
+	// Project rule options are layered over the profile's
+	cfg = NewConfig()
+	require.NoError(t, cfg.ApplyProject(&core.LintConfig{
+		Profile:  ProfileDbtStyle,
+		Severity: map[string]string{"RF02": "warning"},
+		Rules:    map[string]core.RuleOptions{"AL06": {"max_length": 20}},
+	}))
+	assert.Equal(t, 3, cfg.GetRuleOptions("AL06")["min_length"])
+	assert.Equal(t, 20, cfg.GetRuleOptions("AL06")["max_length"])
+	assert.Equal(t, core.SeverityWarning, cfg.GetSeverity("RF02", core.SeverityWarning))
+
+	err := NewConfig().ApplyProject(&core.LintConfig{Profile: "lenient"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown profile "lenient"`)
+}
@@ -1,6 +1,9 @@
 package project
 
 import (
+	"sort"
+	"sync"
+
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/lint"
 )
@@ -9,6 +12,11 @@ import (
 type Analyzer struct {
 	config        *AnalyzerConfig
 	disabledRules map[string]bool
+
+	// State of the last analysis, reused by AnalyzeChanged
+	mu       sync.Mutex
+	last     *Context
+	lastDiag map[string][]Diagnostic // rule ID -> diagnostics
 }
 
 // AnalyzerConfig holds configuration for the project analyzer.
@@ -19,6 +27,10 @@ type AnalyzerConfig struct {
 	// SeverityOverrides changes the default severity of rules
 	SeverityOverrides map[string]core.Severity
 
+	// ModelOverrides change severities for the models diagnostics are
+	// reported on, applied in order after SeverityOverrides
+	ModelOverrides []lint.ModelOverride
+
 	// ProjectHealth contains thresholds and settings
 	ProjectHealth lint.ProjectHealthConfig
 }
@@ -49,26 +61,123 @@ func (a *Analyzer) Analyze(ctx *Context) []Diagnostic {
 		return nil
 	}
 
-	var diagnostics []Diagnostic
-	rules := GetAll()
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-	for _, rule := range rules {
-		// Skip disabled rules
-		if a.isDisabled(rule.ID) {
+	results := make(map[string][]Diagnostic)
+	for _, rule := range a.enabledRules() {
+		results[rule.ID] = a.check(rule, ctx)
+	}
+	return a.record(ctx, results)
+}
+
+// AnalyzeChanged re-runs the project rules after the given models were
+// added, edited or removed since the previous Analyze or AnalyzeChanged call,
+// and returns the diagnostics of the whole project. Rules scoped to a model
+// or its neighbors are only re-evaluated for the models a change may affect,
+// in the previous graph or in ctx; the diagnostics of other models are
+// reused. ScopeProject rules always run in full. Without a previous analysis,
+// it analyzes the whole project.
+//
+// Configuration changes are not tracked: call Analyze after them.
+func (a *Analyzer) AnalyzeChanged(ctx *Context, changed []string) []Diagnostic {
+	if ctx == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	affectedModels := make(map[string]bool, len(changed))
+	for _, path := range changed {
+		affectedModels[path] = true
+	}
+	affectedNeighbors := make(map[string]bool, len(changed))
+	for _, path := range changed {
+		affectedNeighbors[path] = true
+		for _, graph := range []*Context{a.last, ctx} {
+			if graph == nil {
+				continue
+			}
+			for _, p := range graph.GetParents(path) {
+				affectedNeighbors[p] = true
+			}
+			for _, c := range graph.GetChildren(path) {
+				affectedNeighbors[c] = true
+			}
+		}
+	}
+
+	results := make(map[string][]Diagnostic)
+	for _, rule := range a.enabledRules() {
+		previous, ok := a.lastDiag[rule.ID]
+		var affected map[string]bool
+		switch rule.Scope {
+		case ScopeModel:
+			affected = affectedModels
+		case ScopeNeighbors:
+			affected = affectedNeighbors
+		}
+		if !ok || affected == nil {
+			results[rule.ID] = a.check(rule, ctx)
 			continue
 		}
 
-		// Run the rule
-		diags := rule.Check(ctx)
+		var diags []Diagnostic
+		for _, d := range previous {
+			if !affected[d.Model] {
+				diags = append(diags, d)
+			}
+		}
+		results[rule.ID] = append(diags, a.check(rule, ctx.focus(affected))...)
+	}
+	return a.record(ctx, results)
+}
 
-		// Apply severity overrides
-		for i := range diags {
-			diags[i].Severity = a.getSeverity(rule.ID, diags[i].Severity)
+// enabledRules returns the registered rules that are not disabled, by ID.
+func (a *Analyzer) enabledRules() []RuleDef {
+	var rules []RuleDef
+	for _, rule := range GetAll() {
+		if !a.isDisabled(rule.ID) {
+			rules = append(rules, rule)
 		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
 
-		diagnostics = append(diagnostics, diags...)
+// check runs a rule and applies severity overrides to its diagnostics.
+func (a *Analyzer) check(rule RuleDef, ctx *Context) []Diagnostic {
+	diags := rule.Check(ctx)
+	for i := range diags {
+		diags[i].Severity = a.getSeverity(rule.ID, diags[i].Severity)
+		if a.config != nil && len(a.config.ModelOverrides) > 0 {
+			diags[i].Severity = lint.ResolveModelSeverity(a.config.ModelOverrides, rule.ID, diags[i].Severity, overrideTarget(ctx, diags[i].Model))
+		}
 	}
+	return diags
+}
 
+// overrideTarget returns the model a diagnostic is reported on, for matching
+// model overrides; nil if it is not reported on a model.
+func overrideTarget(ctx *Context, path string) *lint.ModelInfo {
+	m, ok := ctx.GetModel(path)
+	if !ok {
+		return nil
+	}
+	return &lint.ModelInfo{Path: m.Path, FilePath: m.FilePath, Tags: m.Tags, Owner: m.Owner, Group: m.Group}
+}
+
+// record keeps the results of an analysis for AnalyzeChanged and returns
+// them as a single list, ordered by SortDiagnostics.
+func (a *Analyzer) record(ctx *Context, results map[string][]Diagnostic) []Diagnostic {
+	a.last, a.lastDiag = ctx, results
+
+	var diagnostics []Diagnostic
+	for _, diags := range results {
+		diagnostics = append(diagnostics, diags...)
+	}
+	SortDiagnostics(diagnostics)
 	return diagnostics
 }
 
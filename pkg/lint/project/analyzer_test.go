@@ -1,6 +1,7 @@
 package project
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/leapstack-labs/leapsql/pkg/core"
@@ -110,3 +111,158 @@ func TestAnalyzer_SeverityOverride(t *testing.T) {
 	require.Len(t, diags, 1)
 	assert.Equal(t, core.SeverityError, diags[0].Severity)
 }
+
+func TestAnalyzer_ModelOverrides(t *testing.T) {
+	Clear()
+
+	Register(RuleDef{
+		ID:       "TEST03",
+		Name:     "test-rule-3",
+		Group:    "test",
+		Severity: core.SeverityWarning,
+		Check: func(ctx *Context) []Diagnostic {
+			var diags []Diagnostic
+			for path := range ctx.Models() {
+				diags = append(diags, Diagnostic{RuleID: "TEST03", Severity: core.SeverityWarning, Model: path})
+			}
+			return diags
+		},
+	})
+
+	models := map[string]*ModelInfo{
+		"marts.fct_revenue": {Path: "marts.fct_revenue", Tags: []string{"tier1"}},
+		"marts.fct_visits":  {Path: "marts.fct_visits"},
+	}
+	ctx := NewContext(models, nil, nil, lint.DefaultProjectHealthConfig())
+
+	cfg := NewAnalyzerConfig()
+	cfg.ModelOverrides = []lint.ModelOverride{{Selectors: []string{"tag:tier1"}, WarningsAsErrors: true}}
+	diags := NewAnalyzer(cfg).Analyze(ctx)
+
+	severities := make(map[string]core.Severity)
+	for _, d := range diags {
+		severities[d.Model] = d.Severity
+	}
+	assert.Equal(t, map[string]core.Severity{
+		"marts.fct_revenue": core.SeverityError,
+		"marts.fct_visits":  core.SeverityWarning,
+	}, severities)
+}
+
+func TestAnalyzer_AnalyzeChanged(t *testing.T) {
+	Clear()
+
+	// Each rule records the models it evaluated
+	evaluated := map[string][]string{}
+	flagged := func(m *ModelInfo) bool { return m.Meta["flagged"] == true }
+	Register(RuleDef{
+		ID:    "INC01",
+		Scope: ScopeModel,
+		Check: func(ctx *Context) []Diagnostic {
+			var diags []Diagnostic
+			for path, m := range ctx.CheckedModels() {
+				evaluated["INC01"] = append(evaluated["INC01"], path)
+				if flagged(m) {
+					diags = append(diags, Diagnostic{RuleID: "INC01", Model: path, Message: "flagged"})
+				}
+			}
+			return diags
+		},
+	})
+	Register(RuleDef{
+		ID:    "INC02",
+		Scope: ScopeNeighbors,
+		Check: func(ctx *Context) []Diagnostic {
+			var diags []Diagnostic
+			for path := range ctx.CheckedModels() {
+				evaluated["INC02"] = append(evaluated["INC02"], path)
+				for _, parent := range ctx.GetParents(path) {
+					if p, ok := ctx.GetModel(parent); ok && flagged(p) {
+						diags = append(diags, Diagnostic{RuleID: "INC02", Model: path, Message: "depends on " + parent})
+					}
+				}
+			}
+			return diags
+		},
+	})
+	Register(RuleDef{
+		ID: "INC03",
+		Check: func(ctx *Context) []Diagnostic {
+			evaluated["INC03"] = append(evaluated["INC03"], "*")
+			return []Diagnostic{{RuleID: "INC03", Message: "models: " + fmt.Sprint(len(ctx.Models()))}}
+		},
+	})
+
+	// a -> b -> c, d is unrelated
+	newContext := func(flaggedA bool, withC bool) *Context {
+		models := map[string]*ModelInfo{
+			"a": {Path: "a", Meta: map[string]any{"flagged": flaggedA}},
+			"b": {Path: "b"},
+			"d": {Path: "d", Meta: map[string]any{"flagged": true}},
+		}
+		parents := map[string][]string{"b": {"a"}}
+		children := map[string][]string{"a": {"b"}}
+		if withC {
+			models["c"] = &ModelInfo{Path: "c", Meta: map[string]any{"flagged": true}}
+			parents["c"] = []string{"b"}
+			children["b"] = []string{"c"}
+		}
+		return NewContext(models, parents, children, lint.DefaultProjectHealthConfig())
+	}
+
+	analyzer := NewAnalyzer(nil)
+	analyzer.Analyze(newContext(false, true))
+
+	t.Run("edited model", func(t *testing.T) {
+		clear(evaluated)
+		ctx := newContext(true, true)
+		diags := analyzer.AnalyzeChanged(ctx, []string{"a"})
+
+		assert.ElementsMatch(t, []string{"a"}, evaluated["INC01"])
+		assert.ElementsMatch(t, []string{"a", "b"}, evaluated["INC02"])
+		assert.Equal(t, []string{"*"}, evaluated["INC03"])
+		assert.ElementsMatch(t, NewAnalyzer(nil).Analyze(ctx), diags, "same diagnostics as a full analysis")
+	})
+
+	t.Run("removed model", func(t *testing.T) {
+		clear(evaluated)
+		ctx := newContext(true, false)
+		diags := analyzer.AnalyzeChanged(ctx, []string{"c"})
+
+		assert.Empty(t, evaluated["INC01"], "c no longer exists")
+		assert.ElementsMatch(t, []string{"b"}, evaluated["INC02"], "b was c's parent")
+		assert.ElementsMatch(t, NewAnalyzer(nil).Analyze(ctx), diags)
+	})
+
+	t.Run("without previous analysis", func(t *testing.T) {
+		clear(evaluated)
+		ctx := newContext(false, true)
+		diags := NewAnalyzer(nil).AnalyzeChanged(ctx, []string{"a"})
+
+		assert.ElementsMatch(t, []string{"a", "b", "c", "d"}, evaluated["INC01"])
+		assert.ElementsMatch(t, NewAnalyzer(nil).Analyze(ctx), diags)
+	})
+}
+
+func TestSortDiagnostics(t *testing.T) {
+	diags := []Diagnostic{
+		{RuleID: "PM02", Model: "marts.orders", FilePath: "/models/marts/orders.sql", Message: "b"},
+		{RuleID: "PM01", Model: "staging.orders", FilePath: "/models/staging/orders.sql", Message: "a"},
+		{RuleID: "PM02", Model: "marts.orders", FilePath: "/models/marts/orders.sql", Message: "a"},
+		{RuleID: "PM01", Model: "marts.orders", FilePath: "/models/marts/orders.sql", Message: "z"},
+		{RuleID: "PS01", Message: "project-wide"},
+	}
+	SortDiagnostics(diags)
+
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.Model+" "+d.RuleID+" "+d.Message)
+	}
+	assert.Equal(t, []string{
+		" PS01 project-wide",
+		"marts.orders PM01 z",
+		"marts.orders PM02 a",
+		"marts.orders PM02 b",
+		"staging.orders PM01 a",
+	}, got)
+}
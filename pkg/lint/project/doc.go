@@ -24,6 +24,15 @@
 // This allows flexibility for teams using either folder-based or prefix-based
 // conventions.
 //
+// # Sources and Seeds
+//
+// Tables models read that are not models are sources. Context.Sources lists
+// them with the models reading them, telling seeds apart from external
+// sources once the project's seeds are registered with SetSeeds:
+//
+//	ctx.SetSeeds(map[string]string{"countries": "seeds/countries.csv"})
+//	src, ok := ctx.Source("countries") // src.Kind == SourceKindSeed
+//
 // # Usage
 //
 // Create a Context from your discovered models and run the analyzer:
@@ -31,4 +40,21 @@
 //	ctx := project.NewContext(models, graph)
 //	analyzer := project.NewAnalyzer(config)
 //	diagnostics := analyzer.Analyze(ctx)
+//
+// NewContextFromStore builds the Context from the state store instead, using
+// what the last discovery recorded, so project rules can run without parsing
+// any model files:
+//
+//	ctx, err := project.NewContextFromStore(store, config)
+//
+// # Incremental Analysis
+//
+// Each rule declares a Scope: the models it reads to compute a model's
+// diagnostics. After an edit, AnalyzeChanged re-evaluates ScopeModel rules on
+// the changed models and ScopeNeighbors rules on those models and their
+// direct parents and children, reusing the previous diagnostics elsewhere.
+// ScopeProject rules, such as lineage rules that follow columns across the
+// DAG, always run in full:
+//
+//	diagnostics = analyzer.AnalyzeChanged(newCtx, []string{"staging.orders"})
 package project
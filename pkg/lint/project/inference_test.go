@@ -162,3 +162,48 @@ func TestInferModelType_Priority(t *testing.T) {
 	}
 	assert.Equal(t, core.ModelTypeStaging, InferModelType(model))
 }
+
+func TestInferModelTypeWithLayers(t *testing.T) {
+	layers := []core.LayerConfig{
+		{Name: "bronze", Paths: []string{"raw/*"}, Prefixes: []string{"brz_"}},
+		{Name: "gold", Paths: []string{"gold"}, Prefixes: []string{"gld_", "rpt_"}},
+	}
+
+	tests := []struct {
+		name     string
+		model    *ModelInfo
+		expected core.ModelType
+	}{
+		{
+			name:     "path pattern with wildcard",
+			model:    &ModelInfo{Name: "orders", FilePath: "/models/raw/shop/orders.sql"},
+			expected: "bronze",
+		},
+		{
+			name:     "pattern needs a directory below raw",
+			model:    &ModelInfo{Name: "orders", FilePath: "/models/raw/orders.sql"},
+			expected: core.ModelTypeOther,
+		},
+		{
+			name:     "second prefix",
+			model:    &ModelInfo{Name: "RPT_Revenue", FilePath: "/models/reports/RPT_Revenue.sql"},
+			expected: "gold",
+		},
+		{
+			name:     "frontmatter names a declared layer",
+			model:    &ModelInfo{Name: "orders", FilePath: "/models/gold/orders.sql", Meta: map[string]any{"type": "Bronze"}},
+			expected: "bronze",
+		},
+		{
+			name:     "default layers no longer apply",
+			model:    &ModelInfo{Name: "stg_orders", FilePath: "/models/staging/stg_orders.sql"},
+			expected: core.ModelTypeOther,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, InferModelTypeWithLayers(tt.model, layers))
+		})
+	}
+}
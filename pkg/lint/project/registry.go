@@ -1,6 +1,7 @@
 package project
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/leapstack-labs/leapsql/pkg/core"
@@ -20,13 +21,15 @@ type Registry struct {
 
 // RuleDef is a project-level rule definition.
 type RuleDef struct {
-	ID          string        // Unique identifier, e.g., "PM01"
-	Name        string        // Human-readable name, e.g., "root-models"
-	Group       string        // Category: "modeling", "structure", "lineage"
-	Description string        // Human-readable description
-	Severity    core.Severity // Default severity (uses unified core.Severity)
-	Check       Check         // The check function
-	ConfigKeys  []string      // Configuration keys this rule accepts
+	ID          string            // Unique identifier, e.g., "PM01"
+	Name        string            // Human-readable name, e.g., "root-models"
+	Group       string            // Category: "modeling", "structure", "lineage"
+	Description string            // Human-readable description
+	Severity    core.Severity     // Default severity (uses unified core.Severity)
+	Check       Check             // The check function
+	Scope       Scope             // Models the rule's diagnostics depend on; ScopeProject if unset
+	Options     []core.RuleOption // Options this rule accepts, validated when set in the config
+	ConfigKeys  []string          // Deprecated: use Options. Option names accepted without validation
 
 	// Documentation fields for richer rule documentation
 	Rationale   string // Why this rule exists, what problems it prevents
@@ -38,6 +41,21 @@ type RuleDef struct {
 // Check is the function signature for project-level rule checks.
 type Check func(ctx *Context) []Diagnostic
 
+// Scope declares which models a rule reads to compute the diagnostics of a
+// model, so incremental analysis knows which rules a change can affect.
+type Scope int
+
+const (
+	// ScopeProject rules may read any part of the project. They are
+	// re-evaluated in full on every change.
+	ScopeProject Scope = iota
+	// ScopeModel rules read only the model itself.
+	ScopeModel
+	// ScopeNeighbors rules read the model and its direct parents and
+	// children.
+	ScopeNeighbors
+)
+
 // Diagnostic represents a project-level lint finding.
 type Diagnostic struct {
 	RuleID   string
@@ -52,6 +70,24 @@ type Diagnostic struct {
 	AutoFixable      bool   // true if fixes can be auto-applied
 }
 
+// SortDiagnostics orders diagnostics by file, model, rule ID and message, so
+// lint output doesn't depend on the order rules or models are checked in.
+func SortDiagnostics(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		if a.Model != b.Model {
+			return a.Model < b.Model
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		return a.Message < b.Message
+	})
+}
+
 // Register adds a rule to the global registry.
 // Call this from init() functions in rule packages.
 func Register(rule RuleDef) {
@@ -126,7 +162,12 @@ func (w *wrappedProjectRule) Name() string                   { return w.def.Name
 func (w *wrappedProjectRule) Group() string                  { return w.def.Group }
 func (w *wrappedProjectRule) Description() string            { return w.def.Description }
 func (w *wrappedProjectRule) DefaultSeverity() core.Severity { return w.def.Severity }
-func (w *wrappedProjectRule) ConfigKeys() []string           { return w.def.ConfigKeys }
+func (w *wrappedProjectRule) Options() []core.RuleOption     { return w.def.Options }
+
+// ConfigKeys returns the names of the rule's options.
+func (w *wrappedProjectRule) ConfigKeys() []string {
+	return lint.OptionKeys(w.def.Options, w.def.ConfigKeys)
+}
 
 // Documentation methods
 func (w *wrappedProjectRule) Rationale() string   { return w.def.Rationale }
@@ -8,17 +8,25 @@
 //   - PL02: Orphaned Columns - Columns never used by downstream models
 //   - PL04: Implicit Cross-Join - JOINs with no visible join keys
 //   - PL05: Schema Drift - SELECT * from source with changed schema
+//   - PL06: PII Exposure - PII columns reach a mart not approved to expose them
+//   - PL07: Plan Regression - Query plan has new full scans or costly joins since the previous run
 //
 // PM (Modeling): Rules about model structure and organization
 //   - PM01: Root Models - Models with no sources (broken DAG lineage)
-//   - PM02: Source Fanout - Source referenced by multiple non-staging models
-//   - PM03: Staging Depends Staging - Staging model references another staging
+//   - PM02: Source Fanout - Source or seed referenced by multiple non-staging models
+//   - PM03: Staging Depends Staging - Model references a layer outside its depends_on
 //   - PM04: Model Fanout - Model has too many direct downstream consumers
 //   - PM05: Too Many Joins - Model references too many upstream models
-//   - PM06: Downstream on Source - Marts/intermediate depends directly on source
+//   - PM06: Downstream on Source - Marts/intermediate depends directly on a source or seed
 //   - PM07: Rejoining Upstream - Unnecessary intermediate model pattern
+//   - PM08: Deprecated Dependency - Model depends on a deprecated model
+//   - PM09: Model Access - Model references a model outside its access level
+//   - PM10: Unknown Group - Model belongs to a group not declared in config
+//   - PM11: Missing Owner - Model has no owning group or owner
 //
 // PS (Structure): Rules about project structure and naming
 //   - PS01: Model Naming - Model naming convention mismatch
 //   - PS02: Model Directory - Model directory mismatch
+//   - PS03: Seed Naming - Seed name is not lowercase snake_case
+//   - PS04: Relation Collision - Models are built as the same table
 package projectrules
@@ -0,0 +1,85 @@
+package projectrules
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIncrementalMatchesFull checks the scopes the rules declare: analyzing
+// only the changed models must give the same diagnostics as a full analysis.
+func TestIncrementalMatchesFull(t *testing.T) {
+	newContext := func(edit func(models map[string]*project.ModelInfo)) *project.Context {
+		models := map[string]*project.ModelInfo{
+			"staging.orders": {
+				Path: "staging.orders", Name: "stg_orders", FilePath: "/models/staging/stg_orders.sql",
+				Type: core.ModelTypeStaging, Sources: []string{"raw.orders"},
+			},
+			"marts.orders": {
+				Path: "marts.orders", Name: "fct_orders", FilePath: "/models/marts/fct_orders.sql",
+				Type: core.ModelTypeMarts, Sources: []string{"staging.orders"},
+			},
+			"marts.revenue": {
+				Path: "marts.revenue", Name: "fct_revenue", FilePath: "/models/marts/fct_revenue.sql",
+				Type: core.ModelTypeMarts, Sources: []string{"marts.orders"},
+			},
+		}
+		if edit != nil {
+			edit(models)
+		}
+		parents := map[string][]string{}
+		children := map[string][]string{}
+		for path, m := range models {
+			for _, src := range m.Sources {
+				if _, ok := models[src]; ok {
+					parents[path] = append(parents[path], src)
+					children[src] = append(children[src], path)
+				}
+			}
+		}
+		return project.NewContext(models, parents, children, lint.DefaultProjectHealthConfig())
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(models map[string]*project.ModelInfo)
+		changed []string
+	}{
+		{
+			name: "parent deprecated and renamed",
+			edit: func(models map[string]*project.ModelInfo) {
+				models["marts.orders"].Deprecated = &core.Deprecation{Since: "2024-01-01"}
+				models["marts.orders"].Name = "orders"
+			},
+			changed: []string{"marts.orders"},
+		},
+		{
+			name: "model reads a raw source",
+			edit: func(models map[string]*project.ModelInfo) {
+				models["marts.revenue"].Sources = []string{"raw.payments"}
+			},
+			changed: []string{"marts.revenue"},
+		},
+		{
+			name: "staging model removed",
+			edit: func(models map[string]*project.ModelInfo) {
+				delete(models, "staging.orders")
+			},
+			changed: []string{"staging.orders"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := project.NewAnalyzer(nil)
+			analyzer.Analyze(newContext(nil))
+
+			ctx := newContext(tt.edit)
+			got := analyzer.AnalyzeChanged(ctx, tt.changed)
+			assert.ElementsMatch(t, project.NewAnalyzer(nil).Analyze(ctx), got)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package projectrules
+
+import (
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+)
+
+// readsSources reports whether the model belongs to a layer whose models are
+// the entry point for raw tables (staging by default).
+func readsSources(ctx *project.Context, model *project.ModelInfo) bool {
+	layer, ok := ctx.Layer(model)
+	return ok && layer.ReadsSources()
+}
+
+// sourceLayerNames lists the layers whose models read raw tables.
+func sourceLayerNames(ctx *project.Context) []string {
+	var names []string
+	for _, l := range ctx.Layers() {
+		if l.ReadsSources() {
+			names = append(names, l.Name)
+		}
+	}
+	return names
+}
+
+// sourceKind names a table that is not a model for messages: "seed" or
+// "source".
+func sourceKind(ctx *project.Context, name string) string {
+	if ctx.IsSeed(name) {
+		return string(project.SourceKindSeed)
+	}
+	return string(project.SourceKindExternal)
+}
+
+// quotedPrefixes formats prefixes as 'stg_' or 'fct_' or 'dim_'.
+func quotedPrefixes(prefixes []string) string {
+	quoted := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		quoted[i] = "'" + p + "'"
+	}
+	return strings.Join(quoted, " or ")
+}
+
+// describeDependsOn describes what a layer's models may reference, e.g.
+// "raw sources and staging models".
+func describeDependsOn(layer core.LayerConfig) string {
+	var parts, layers []string
+	for _, d := range layer.DependsOn {
+		if strings.EqualFold(d, core.SourceLayer) {
+			parts = append(parts, "raw sources")
+		} else {
+			layers = append(layers, d)
+		}
+	}
+	if len(layers) > 0 {
+		parts = append(parts, strings.Join(layers, ", ")+" models")
+	}
+	return strings.Join(parts, " and ")
+}
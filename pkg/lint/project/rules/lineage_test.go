@@ -348,6 +348,122 @@ func TestPL04_ImplicitCrossJoin(t *testing.T) {
 	}
 }
 
+func TestPL06_PIIExposure(t *testing.T) {
+	staging := func() *project.ModelInfo {
+		return &project.ModelInfo{
+			Path: "staging.customers",
+			Name: "stg_customers",
+			Type: core.ModelTypeStaging,
+			PII:  []string{"email"},
+			Columns: []core.ColumnInfo{
+				{Name: "id", Sources: []core.SourceRef{{Table: "raw.customers", Column: "id"}}},
+				{Name: "email", Sources: []core.SourceRef{{Table: "raw.customers", Column: "email"}}},
+			},
+		}
+	}
+	mart := func(columns ...core.ColumnInfo) *project.ModelInfo {
+		return &project.ModelInfo{
+			Path:    "marts.customers",
+			Name:    "dim_customers",
+			Type:    core.ModelTypeMarts,
+			Columns: columns,
+		}
+	}
+	parents := map[string][]string{
+		"marts.customers":        {"staging.customers"},
+		"intermediate.customers": {"staging.customers"},
+	}
+
+	tests := []struct {
+		name        string
+		models      []*project.ModelInfo
+		approved    []string
+		wantMessage string
+	}{
+		{
+			name: "PII column reaches a mart",
+			models: []*project.ModelInfo{staging(), mart(
+				core.ColumnInfo{Name: "id", Sources: []core.SourceRef{{Table: "staging.customers", Column: "id"}}},
+				core.ColumnInfo{Name: "contact", Sources: []core.SourceRef{{Table: "staging.customers", Column: "email"}}},
+			)},
+			wantMessage: "mart 'dim_customers' exposes PII columns: contact (from staging.customers.email)",
+		},
+		{
+			name: "lineage source referenced by model name",
+			models: []*project.ModelInfo{staging(), mart(
+				core.ColumnInfo{Name: "email", Sources: []core.SourceRef{{Table: "stg_customers", Column: "EMAIL"}}},
+			)},
+			wantMessage: "mart 'dim_customers' exposes PII columns: email (from staging.customers.email)",
+		},
+		{
+			name: "PII traced through an intermediate model",
+			models: []*project.ModelInfo{
+				staging(),
+				{
+					Path: "intermediate.customers",
+					Name: "int_customers",
+					Type: core.ModelTypeIntermediate,
+					Columns: []core.ColumnInfo{
+						{Name: "email_domain", Sources: []core.SourceRef{{Table: "staging.customers", Column: "email"}}},
+					},
+				},
+				mart(core.ColumnInfo{Name: "domain", Sources: []core.SourceRef{{Table: "intermediate.customers", Column: "email_domain"}}}),
+			},
+			wantMessage: "mart 'dim_customers' exposes PII columns: domain (from staging.customers.email)",
+		},
+		{
+			name: "PII tagged in the mart itself",
+			models: []*project.ModelInfo{{
+				Path:    "marts.customers",
+				Name:    "dim_customers",
+				Type:    core.ModelTypeMarts,
+				PII:     []string{"phone"},
+				Columns: []core.ColumnInfo{{Name: "phone"}},
+			}},
+			wantMessage: "mart 'dim_customers' exposes PII columns: phone (from marts.customers.phone)",
+		},
+		{
+			name: "approved mart - should not flag",
+			models: []*project.ModelInfo{staging(), mart(
+				core.ColumnInfo{Name: "email", Sources: []core.SourceRef{{Table: "staging.customers", Column: "email"}}},
+			)},
+			approved: []string{"dim_customers"},
+		},
+		{
+			name: "mart without PII columns - should not flag",
+			models: []*project.ModelInfo{staging(), mart(
+				core.ColumnInfo{Name: "id", Sources: []core.SourceRef{{Table: "staging.customers", Column: "id"}}},
+			)},
+		},
+		{
+			name:   "PII outside marts - should not flag",
+			models: []*project.ModelInfo{staging()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			models := make(map[string]*project.ModelInfo)
+			for _, m := range tt.models {
+				models[m.Path] = m
+			}
+			cfg := lint.DefaultProjectHealthConfig()
+			cfg.PIIApproved = tt.approved
+			ctx := project.NewContext(models, parents, nil, cfg)
+			diags := checkPIIExposure(ctx)
+
+			if tt.wantMessage == "" {
+				assert.Empty(t, diags)
+				return
+			}
+			if assert.Len(t, diags, 1) {
+				assert.Equal(t, "PL06", diags[0].RuleID)
+				assert.Equal(t, tt.wantMessage, diags[0].Message)
+			}
+		})
+	}
+}
+
 func TestGetSourceTablesFromColumns(t *testing.T) {
 	cols := []core.ColumnInfo{
 		{Name: "a", Sources: []core.SourceRef{{Table: "t1", Column: "a"}}},
@@ -206,6 +206,34 @@ func TestPM03_StagingDependsStaging(t *testing.T) {
 	}
 }
 
+func TestPM03_PM06_CustomLayers(t *testing.T) {
+	layers := []core.LayerConfig{
+		{Name: "bronze", DependsOn: []string{"source"}},
+		{Name: "silver", DependsOn: []string{"bronze", "silver"}},
+		{Name: "gold", DependsOn: []string{"silver"}},
+	}
+	models := map[string]*project.ModelInfo{
+		"bronze.orders":  {Path: "bronze.orders", Name: "orders", Type: "bronze", Sources: []string{"raw.orders"}},
+		"silver.orders":  {Path: "silver.orders", Name: "orders_clean", Type: "silver", Sources: []string{"bronze.orders"}},
+		"gold.revenue":   {Path: "gold.revenue", Name: "revenue", Type: "gold", Sources: []string{"silver.orders", "bronze.orders"}},
+		"gold.forecast":  {Path: "gold.forecast", Name: "forecast", Type: "gold", Sources: []string{"raw.targets"}},
+		"adhoc.analysis": {Path: "adhoc.analysis", Name: "analysis", Type: core.ModelTypeOther, Sources: []string{"raw.orders", "gold.revenue"}},
+	}
+	cfg := lint.DefaultProjectHealthConfig()
+	cfg.Layers = layers
+	ctx := project.NewContext(models, nil, nil, cfg)
+
+	diags := checkStagingDependsStaging(ctx)
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, "gold model 'revenue' depends on bronze model 'orders'; gold models should only reference silver models", diags[0].Message)
+	}
+
+	diags = checkDownstreamOnSource(ctx)
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, "gold model 'forecast' depends directly on source 'raw.targets'; use a bronze model instead", diags[0].Message)
+	}
+}
+
 func TestPM04_ModelFanout(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -463,6 +491,45 @@ func TestPM06_DownstreamOnSource(t *testing.T) {
 	}
 }
 
+func TestPM02_PM06_Seeds(t *testing.T) {
+	models := map[string]*project.ModelInfo{
+		"staging.stg_countries": {
+			Path: "staging.stg_countries", Name: "stg_countries", Type: core.ModelTypeStaging,
+			Sources: []string{"countries"},
+		},
+		"marts.dim_customers": {
+			Path: "marts.dim_customers", Name: "dim_customers", Type: core.ModelTypeMarts,
+			Sources: []string{"countries", "raw_customers"},
+		},
+		"marts.fct_orders": {
+			Path: "marts.fct_orders", Name: "fct_orders", Type: core.ModelTypeMarts,
+			Sources: []string{"countries", "raw_customers"},
+		},
+	}
+	ctx := project.NewContext(models, nil, nil, lint.DefaultProjectHealthConfig())
+	ctx.SetSeeds(map[string]string{"countries": "/seeds/countries.csv"})
+
+	var messages []string
+	for _, d := range checkDownstreamOnSource(ctx) {
+		messages = append(messages, d.Message)
+	}
+	assert.ElementsMatch(t, []string{
+		"marts model 'dim_customers' depends directly on seed 'countries'; use a staging model instead",
+		"marts model 'dim_customers' depends directly on source 'raw_customers'; use a staging model instead",
+		"marts model 'fct_orders' depends directly on seed 'countries'; use a staging model instead",
+		"marts model 'fct_orders' depends directly on source 'raw_customers'; use a staging model instead",
+	}, messages)
+
+	messages = nil
+	for _, d := range checkSourceFanout(ctx) {
+		messages = append(messages, d.Message)
+	}
+	assert.ElementsMatch(t, []string{
+		"Seed 'countries' is referenced by 2 non-staging models (marts.dim_customers, marts.fct_orders); reference 'stg_countries' instead",
+		"Source 'raw_customers' is referenced by 2 non-staging models (marts.dim_customers, marts.fct_orders); no staging model reads it yet, consider creating one",
+	}, messages)
+}
+
 func TestPM07_RejoiningUpstream(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -580,3 +647,297 @@ func TestPM07_RejoiningUpstream(t *testing.T) {
 		})
 	}
 }
+
+func TestPM08_DeprecatedDependency(t *testing.T) {
+	deprecatedV1 := &project.ModelInfo{
+		Path:       "marts.dim_users_v1",
+		Name:       "dim_users",
+		FilePath:   "/models/marts/dim_users_v1.sql",
+		Type:       core.ModelTypeMarts,
+		Version:    1,
+		Deprecated: &core.Deprecation{Since: "2024-06-01", Replacement: "dim_users@v2"},
+	}
+	currentV2 := &project.ModelInfo{
+		Path:     "marts.dim_users_v2",
+		Name:     "dim_users",
+		FilePath: "/models/marts/dim_users_v2.sql",
+		Type:     core.ModelTypeMarts,
+		Version:  2,
+	}
+
+	tests := []struct {
+		name        string
+		models      map[string]*project.ModelInfo
+		parents     map[string][]string
+		wantDiags   int
+		wantMessage string
+	}{
+		{
+			name: "depends on deprecated version",
+			models: map[string]*project.ModelInfo{
+				"marts.dim_users_v1": deprecatedV1,
+				"marts.fct_orders": {
+					Path:     "marts.fct_orders",
+					Name:     "fct_orders",
+					FilePath: "/models/marts/fct_orders.sql",
+					Type:     core.ModelTypeMarts,
+				},
+			},
+			parents: map[string][]string{
+				"marts.fct_orders": {"marts.dim_users_v1"},
+			},
+			wantDiags:   1,
+			wantMessage: "model 'fct_orders' depends on deprecated model 'dim_users@v1' (deprecated since 2024-06-01); use 'dim_users@v2' instead",
+		},
+		{
+			name: "depends on current version - should not flag",
+			models: map[string]*project.ModelInfo{
+				"marts.dim_users_v1": deprecatedV1,
+				"marts.dim_users_v2": currentV2,
+				"marts.fct_orders": {
+					Path:     "marts.fct_orders",
+					Name:     "fct_orders",
+					FilePath: "/models/marts/fct_orders.sql",
+					Type:     core.ModelTypeMarts,
+				},
+			},
+			parents: map[string][]string{
+				"marts.fct_orders": {"marts.dim_users_v2"},
+			},
+			wantDiags: 0,
+		},
+		{
+			name: "deprecated model depending on deprecated model - should not flag",
+			models: map[string]*project.ModelInfo{
+				"marts.dim_users_v1": deprecatedV1,
+				"marts.legacy_report": {
+					Path:       "marts.legacy_report",
+					Name:       "legacy_report",
+					FilePath:   "/models/marts/legacy_report.sql",
+					Type:       core.ModelTypeMarts,
+					Deprecated: &core.Deprecation{},
+				},
+			},
+			parents: map[string][]string{
+				"marts.legacy_report": {"marts.dim_users_v1"},
+			},
+			wantDiags: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := project.NewContext(tt.models, tt.parents, nil, lint.DefaultProjectHealthConfig())
+			diags := checkDeprecatedDependency(ctx)
+
+			assert.Len(t, diags, tt.wantDiags)
+			if tt.wantDiags > 0 {
+				assert.Equal(t, "PM08", diags[0].RuleID)
+				assert.Equal(t, tt.wantMessage, diags[0].Message)
+			}
+		})
+	}
+}
+
+func TestPM09_ModelAccess(t *testing.T) {
+	model := func(path, proj string, access core.Access) *project.ModelInfo {
+		return &project.ModelInfo{Path: path, Project: proj, Name: path, Access: access}
+	}
+
+	tests := []struct {
+		name        string
+		models      map[string]*project.ModelInfo
+		parents     map[string][]string
+		wantDiags   int
+		wantMessage string
+	}{
+		{
+			name: "private model referenced from same directory",
+			models: map[string]*project.ModelInfo{
+				"finance._ledger":   model("finance._ledger", "", core.AccessPrivate),
+				"finance.fct_books": model("finance.fct_books", "", ""),
+			},
+			parents:   map[string][]string{"finance.fct_books": {"finance._ledger"}},
+			wantDiags: 0,
+		},
+		{
+			name: "private model referenced from subdirectory",
+			models: map[string]*project.ModelInfo{
+				"finance._ledger":          model("finance._ledger", "", core.AccessPrivate),
+				"finance.reports.fct_book": model("finance.reports.fct_book", "", ""),
+			},
+			parents:   map[string][]string{"finance.reports.fct_book": {"finance._ledger"}},
+			wantDiags: 0,
+		},
+		{
+			name: "private model referenced from another directory",
+			models: map[string]*project.ModelInfo{
+				"finance._ledger":     model("finance._ledger", "", core.AccessPrivate),
+				"marketing.fct_spend": model("marketing.fct_spend", "", ""),
+			},
+			parents:     map[string][]string{"marketing.fct_spend": {"finance._ledger"}},
+			wantDiags:   1,
+			wantMessage: "model 'marketing.fct_spend' references 'finance._ledger', which is private to 'finance'",
+		},
+		{
+			name: "private model referenced from similarly named directory",
+			models: map[string]*project.ModelInfo{
+				"finance._ledger":       model("finance._ledger", "", core.AccessPrivate),
+				"finance_ops.fct_costs": model("finance_ops.fct_costs", "", ""),
+			},
+			parents:     map[string][]string{"finance_ops.fct_costs": {"finance._ledger"}},
+			wantDiags:   1,
+			wantMessage: "model 'finance_ops.fct_costs' references 'finance._ledger', which is private to 'finance'",
+		},
+		{
+			name: "root-level private model referenced from subdirectory",
+			models: map[string]*project.ModelInfo{
+				"_ledger":           model("_ledger", "", core.AccessPrivate),
+				"finance.fct_books": model("finance.fct_books", "", ""),
+			},
+			parents:   map[string][]string{"finance.fct_books": {"_ledger"}},
+			wantDiags: 0,
+		},
+		{
+			name: "private model referenced from same directory of another project",
+			models: map[string]*project.ModelInfo{
+				"core/finance._ledger":      model("core/finance._ledger", "core", core.AccessPrivate),
+				"billing/finance.fct_books": model("billing/finance.fct_books", "billing", ""),
+			},
+			parents:     map[string][]string{"billing/finance.fct_books": {"core/finance._ledger"}},
+			wantDiags:   1,
+			wantMessage: "model 'billing/finance.fct_books' references 'core/finance._ledger', which is private to 'core/finance'",
+		},
+		{
+			name: "root-level private model referenced from its project",
+			models: map[string]*project.ModelInfo{
+				"core/_ledger":           model("core/_ledger", "core", core.AccessPrivate),
+				"core/finance.fct_books": model("core/finance.fct_books", "core", ""),
+			},
+			parents:   map[string][]string{"core/finance.fct_books": {"core/_ledger"}},
+			wantDiags: 0,
+		},
+		{
+			name: "root-level private model referenced from another project's root",
+			models: map[string]*project.ModelInfo{
+				"core/_ledger":    model("core/_ledger", "core", core.AccessPrivate),
+				"billing/_totals": model("billing/_totals", "billing", ""),
+			},
+			parents:     map[string][]string{"billing/_totals": {"core/_ledger"}},
+			wantDiags:   1,
+			wantMessage: "model 'billing/_totals' references 'core/_ledger', which is private to project 'core'",
+		},
+		{
+			name: "protected model referenced from another project",
+			models: map[string]*project.ModelInfo{
+				"core/staging.stg_users": model("core/staging.stg_users", "core", ""),
+				"billing/invoices":       model("billing/invoices", "billing", ""),
+			},
+			parents:     map[string][]string{"billing/invoices": {"core/staging.stg_users"}},
+			wantDiags:   1,
+			wantMessage: "model 'billing/invoices' references 'core/staging.stg_users', which is protected to project 'core'",
+		},
+		{
+			name: "public model referenced from another project",
+			models: map[string]*project.ModelInfo{
+				"core/staging.stg_users": model("core/staging.stg_users", "core", core.AccessPublic),
+				"billing/invoices":       model("billing/invoices", "billing", ""),
+			},
+			parents:   map[string][]string{"billing/invoices": {"core/staging.stg_users"}},
+			wantDiags: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := project.NewContext(tt.models, tt.parents, nil, lint.DefaultProjectHealthConfig())
+			diags := checkModelAccess(ctx)
+
+			assert.Len(t, diags, tt.wantDiags)
+			if tt.wantDiags > 0 {
+				assert.Equal(t, "PM09", diags[0].RuleID)
+				assert.Equal(t, tt.wantMessage, diags[0].Message)
+			}
+		})
+	}
+}
+
+func TestPM10_UnknownGroup(t *testing.T) {
+	groups := lint.GroupsByName([]core.GroupConfig{{Name: "finance", Owner: "finance-data"}})
+
+	tests := []struct {
+		name        string
+		group       string
+		groups      map[string]core.GroupConfig
+		wantDiags   int
+		wantMessage string
+	}{
+		{name: "declared group", group: "finance", groups: groups, wantDiags: 0},
+		{name: "no group", group: "", groups: groups, wantDiags: 0},
+		{
+			name:        "undeclared group",
+			group:       "fnance",
+			groups:      groups,
+			wantDiags:   1,
+			wantMessage: "model 'fct_revenue' belongs to undeclared group 'fnance'",
+		},
+		{
+			name:        "no groups declared",
+			group:       "finance",
+			wantDiags:   1,
+			wantMessage: "model 'fct_revenue' belongs to undeclared group 'finance'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			models := map[string]*project.ModelInfo{
+				"marts.fct_revenue": {Path: "marts.fct_revenue", Name: "fct_revenue", Group: tt.group},
+			}
+			cfg := lint.DefaultProjectHealthConfig()
+			cfg.Groups = tt.groups
+			ctx := project.NewContext(models, nil, nil, cfg)
+			diags := checkUnknownGroup(ctx)
+
+			assert.Len(t, diags, tt.wantDiags)
+			if tt.wantDiags > 0 {
+				assert.Equal(t, "PM10", diags[0].RuleID)
+				assert.Equal(t, tt.wantMessage, diags[0].Message)
+			}
+		})
+	}
+}
+
+func TestPM11_MissingOwner(t *testing.T) {
+	groups := lint.GroupsByName([]core.GroupConfig{{Name: "finance"}})
+
+	tests := []struct {
+		name      string
+		model     *project.ModelInfo
+		groups    map[string]core.GroupConfig
+		wantDiags int
+	}{
+		{name: "has group", model: &project.ModelInfo{Group: "finance"}, groups: groups, wantDiags: 0},
+		{name: "has owner", model: &project.ModelInfo{Owner: "alice"}, groups: groups, wantDiags: 0},
+		{name: "unowned", model: &project.ModelInfo{}, groups: groups, wantDiags: 1},
+		{name: "unowned without declared groups", model: &project.ModelInfo{}, wantDiags: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.model.Path = "marts.fct_revenue"
+			tt.model.Name = "fct_revenue"
+			models := map[string]*project.ModelInfo{tt.model.Path: tt.model}
+			cfg := lint.DefaultProjectHealthConfig()
+			cfg.Groups = tt.groups
+			ctx := project.NewContext(models, nil, nil, cfg)
+			diags := checkMissingOwner(ctx)
+
+			assert.Len(t, diags, tt.wantDiags)
+			if tt.wantDiags > 0 {
+				assert.Equal(t, "PM11", diags[0].RuleID)
+				assert.Equal(t, "model 'fct_revenue' has no owning group or owner", diags[0].Message)
+			}
+		})
+	}
+}
@@ -16,7 +16,10 @@ func init() {
 		Description: "Model has too many passthrough columns",
 		Severity:    core.SeverityWarning,
 		Check:       checkPassthroughBloat,
-		ConfigKeys:  []string{"threshold"},
+		Scope:       project.ScopeModel,
+		Options: []core.RuleOption{
+			{Name: "threshold", Type: core.OptionInt, Default: 20, Min: 1, Description: "Overrides lint.project_health.thresholds.passthrough_columns"},
+		},
 
 		Rationale: `Models with many passthrough columns (direct copies without transformation) indicate a "SELECT *" 
 style that doesn't add value and increases data movement. Explicit column selection ensures only 
@@ -56,7 +59,7 @@ func checkPassthroughBloat(ctx *project.Context) []project.Diagnostic {
 		threshold = 20 // default
 	}
 
-	for _, model := range ctx.Models() {
+	for _, model := range ctx.CheckedModels() {
 		if len(model.Columns) == 0 {
 			continue // No column info available
 		}
@@ -31,7 +31,7 @@ Removing them reduces costs and simplifies the data model.`,
 -- fct_revenue.sql uses: id, amount, tax
 -- All columns are consumed downstream`,
 
-		Fix: "Remove columns that are not consumed by any downstream model, or document why they should be retained.",
+		Fix: "Remove columns that are not consumed by any downstream model, or document why they should be retained. `leapsql prune` removes them from the model files.",
 	})
 }
 
@@ -50,6 +50,38 @@ Removing them reduces costs and simplifies the data model.`,
 func checkOrphanedColumns(ctx *project.Context) []project.Diagnostic {
 	var diagnostics []project.Diagnostic
 
+	for path, orphaned := range OrphanedColumns(ctx) {
+		model, _ := ctx.GetModel(path)
+
+		// Limit the displayed columns to avoid very long messages
+		displayColumns := orphaned
+		suffix := ""
+		if len(orphaned) > 5 {
+			displayColumns = orphaned[:5]
+			suffix = fmt.Sprintf(" and %d more", len(orphaned)-5)
+		}
+
+		diagnostics = append(diagnostics, project.Diagnostic{
+			RuleID:   "PL02",
+			Severity: core.SeverityInfo,
+			Message: fmt.Sprintf(
+				"Model '%s' has %d columns not used by downstream models: %s%s; consider removing them",
+				model.Name, len(orphaned), strings.Join(displayColumns, ", "), suffix),
+			Model:            model.Path,
+			FilePath:         model.FilePath,
+			DocumentationURL: lint.BuildDocURL("PL02"),
+			ImpactScore:      lint.ImpactLow.Int(),
+			AutoFixable:      false,
+		})
+	}
+
+	return diagnostics
+}
+
+// OrphanedColumns returns, by model path, the sorted columns of models with
+// downstream consumers that none of them reads. Leaf models and models
+// without column lineage are left out. leapsql prune removes these columns.
+func OrphanedColumns(ctx *project.Context) map[string][]string {
 	// Build a set of all consumed columns across the project
 	// Key: "model_path.column_name" (the source)
 	consumedColumns := make(map[string]bool)
@@ -64,6 +96,8 @@ func checkOrphanedColumns(ctx *project.Context) []project.Diagnostic {
 		}
 	}
 
+	result := make(map[string][]string)
+
 	// Check each model's columns to see if they're consumed downstream
 	for path, model := range ctx.Models() {
 		children := ctx.GetChildren(path)
@@ -87,32 +121,11 @@ func checkOrphanedColumns(ctx *project.Context) []project.Diagnostic {
 			}
 		}
 
-		// Report if there are orphaned columns
 		if len(orphaned) > 0 {
 			sort.Strings(orphaned)
-
-			// Limit the displayed columns to avoid very long messages
-			displayColumns := orphaned
-			suffix := ""
-			if len(orphaned) > 5 {
-				displayColumns = orphaned[:5]
-				suffix = fmt.Sprintf(" and %d more", len(orphaned)-5)
-			}
-
-			diagnostics = append(diagnostics, project.Diagnostic{
-				RuleID:   "PL02",
-				Severity: core.SeverityInfo,
-				Message: fmt.Sprintf(
-					"Model '%s' has %d columns not used by downstream models: %s%s; consider removing them",
-					model.Name, len(orphaned), strings.Join(displayColumns, ", "), suffix),
-				Model:            model.Path,
-				FilePath:         model.FilePath,
-				DocumentationURL: lint.BuildDocURL("PL02"),
-				ImpactScore:      lint.ImpactLow.Int(),
-				AutoFixable:      false,
-			})
+			result[path] = orphaned
 		}
 	}
 
-	return diagnostics
+	return result
 }
@@ -18,6 +18,7 @@ func init() {
 		Description: "JOINs with no visible join keys in column lineage",
 		Severity:    core.SeverityWarning,
 		Check:       checkImplicitCrossJoin,
+		Scope:       project.ScopeModel,
 
 		Rationale: `When a model references multiple tables but no column expression bridges them, it may indicate 
 a missing JOIN condition (Cartesian product). Cross joins are rarely intentional and can cause 
@@ -60,7 +61,7 @@ JOIN customers c ON o.customer_id = c.id  -- Explicit join condition`,
 func checkImplicitCrossJoin(ctx *project.Context) []project.Diagnostic {
 	var diagnostics []project.Diagnostic
 
-	for _, model := range ctx.Models() {
+	for _, model := range ctx.CheckedModels() {
 		// Need at least 2 sources and some column info
 		if len(model.Sources) < 2 {
 			continue
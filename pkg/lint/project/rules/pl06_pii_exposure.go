@@ -0,0 +1,154 @@
+package projectrules
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+)
+
+func init() {
+	project.Register(project.RuleDef{
+		ID:          "PL06",
+		Name:        "pii-exposure",
+		Group:       "lineage",
+		Description: "PII columns reach a mart that is not approved to expose them",
+		Severity:    core.SeverityWarning,
+		Check:       checkPIIExposure,
+
+		Rationale: `Marts are what analysts, dashboards and exports read. Personal data tagged as PII in 
+a staging model travels downstream through every column derived from it, and easily ends up in a mart 
+nobody reviewed for it. Column-level lineage traces each mart column back to the PII columns it is 
+computed from, so only the marts approved to hold personal data expose it.`,
+
+		BadExample: `-- models/staging/stg_customers.sql
+/*---
+pii: [email]
+---*/
+SELECT id, email FROM raw.customers
+
+-- models/marts/dim_customers.sql
+SELECT id, lower(email) AS contact FROM {{ ref('stg_customers') }}  -- contact is PII`,
+
+		GoodExample: `-- models/marts/dim_customers.sql
+SELECT id, md5(email) AS customer_key FROM {{ ref('stg_customers') }}
+
+# or approve the mart in leapsql.yaml
+lint:
+  project_health:
+    pii_approved: [marts.dim_customers]`,
+
+		Fix: "Drop or aggregate the PII columns before the mart, or list the mart under `lint.project_health.pii_approved` in leapsql.yaml.",
+	})
+}
+
+// checkPIIExposure flags marts with columns derived from columns tagged as
+// PII, unless the mart is approved in config. A column carries PII if its
+// model tags it in frontmatter or any column it is computed from carries PII.
+//
+// Note: hashing a PII column in SQL still derives from it; models that
+// anonymize PII should leave it out of the mart or be approved.
+func checkPIIExposure(ctx *project.Context) []project.Diagnostic {
+	var diagnostics []project.Diagnostic
+	approved := ctx.GetConfig().PIIApproved
+	tracer := &piiTracer{ctx: ctx, memo: make(map[string][]string)}
+
+	for path, model := range ctx.Models() {
+		if model.Type != core.ModelTypeMarts {
+			continue
+		}
+		if slices.Contains(approved, model.Path) || slices.Contains(approved, model.Name) {
+			continue
+		}
+
+		var exposed []string
+		for _, col := range model.Columns {
+			if origins := tracer.origins(path, col.Name); len(origins) > 0 {
+				exposed = append(exposed, fmt.Sprintf("%s (from %s)", col.Name, strings.Join(origins, ", ")))
+			}
+		}
+		if len(exposed) == 0 {
+			continue
+		}
+		sort.Strings(exposed)
+
+		diagnostics = append(diagnostics, project.Diagnostic{
+			RuleID:           "PL06",
+			Severity:         core.SeverityWarning,
+			Message:          fmt.Sprintf("mart '%s' exposes PII columns: %s", model.Name, strings.Join(exposed, "; ")),
+			Model:            model.Path,
+			FilePath:         model.FilePath,
+			DocumentationURL: lint.BuildDocURL("PL06"),
+			ImpactScore:      lint.ImpactHigh.Int(),
+			AutoFixable:      false,
+		})
+	}
+
+	return diagnostics
+}
+
+// piiTracer traces columns back to the PII columns they are computed from.
+type piiTracer struct {
+	ctx  *project.Context
+	memo map[string][]string // "model.column" to its PII origins
+}
+
+// origins returns the PII columns, as "model.column", that a column of a
+// model is computed from. A column tagged as PII is its own origin.
+func (t *piiTracer) origins(modelPath, column string) []string {
+	key := modelPath + "." + strings.ToLower(column)
+	if origins, ok := t.memo[key]; ok {
+		return origins
+	}
+	t.memo[key] = nil // Guards against cycles
+
+	model, ok := t.ctx.GetModel(modelPath)
+	if !ok {
+		return nil
+	}
+	for _, pii := range model.PII {
+		if strings.EqualFold(pii, column) {
+			t.memo[key] = []string{modelPath + "." + pii}
+			return t.memo[key]
+		}
+	}
+
+	var origins []string
+	for _, col := range model.Columns {
+		if !strings.EqualFold(col.Name, column) {
+			continue
+		}
+		for _, src := range col.Sources {
+			parent, ok := t.resolve(modelPath, src.Table)
+			if !ok {
+				continue
+			}
+			for _, origin := range t.origins(parent, src.Column) {
+				if !slices.Contains(origins, origin) {
+					origins = append(origins, origin)
+				}
+			}
+		}
+	}
+	sort.Strings(origins)
+	t.memo[key] = origins
+	return origins
+}
+
+// resolve returns the path of the model a lineage source table refers to:
+// a model with that path, or a parent of the model with that name.
+func (t *piiTracer) resolve(modelPath, table string) (string, bool) {
+	if t.ctx.IsModel(table) {
+		return table, true
+	}
+	for _, parent := range t.ctx.GetParents(modelPath) {
+		if m, ok := t.ctx.GetModel(parent); ok && (m.Name == table || strings.HasSuffix(parent, "."+table)) {
+			return parent, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,77 @@
+package projectrules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+)
+
+func init() {
+	project.Register(project.RuleDef{
+		ID:          "PL07",
+		Name:        "plan-regression",
+		Group:       "lineage",
+		Description: "Query plan has new full scans or costly joins since the previous run",
+		Severity:    core.SeverityWarning,
+		Check:       checkPlanRegression,
+
+		Rationale: `A model's query plan can change without any change to its SQL: an upstream table grows,
+statistics change, or an edit to a parent model removes the filter a join relied on. A new full
+scan or broadcast join often means a build that was fast becomes slow and expensive. This rule
+compares the plans captured with 'leapsql run --explain' in the last two runs that built each model.`,
+
+		BadExample: `-- Run 1: SEQ_SCAN orders, filtered by an index on order_date
+-- Run 2: the date filter was moved downstream, so every row of orders is scanned
+SELECT * FROM {{ ref('stg_orders') }}`,
+
+		GoodExample: `-- Keep selective filters in the model that reads the large table
+SELECT * FROM {{ ref('stg_orders') }}
+WHERE order_date >= DATE '2024-01-01'`,
+
+		Fix: "Review the model's latest plan in the state database (model_plans) and restore the filter, join key or clustering that the previous plan used, or accept the change if it is expected.",
+	})
+}
+
+// checkPlanRegression flags models whose latest captured query plan has more
+// full scans, broadcast joins or nested loop joins than the plan captured in
+// the run before. Models without two captured plans are skipped.
+func checkPlanRegression(ctx *project.Context) []project.Diagnostic {
+	store := ctx.PlanStore()
+	if store == nil {
+		return nil // No plans available, skip plan regression detection
+	}
+
+	var diagnostics []project.Diagnostic
+	for _, model := range ctx.Models() {
+		plans, err := store.GetModelPlans(model.Path, 2)
+		if err != nil || len(plans) < 2 {
+			continue
+		}
+
+		changes := core.ComparePlans(plans[1].Plan, plans[0].Plan)
+		if len(changes) == 0 {
+			continue
+		}
+
+		parts := make([]string, len(changes))
+		for i, c := range changes {
+			parts[i] = c.String()
+		}
+		diagnostics = append(diagnostics, project.Diagnostic{
+			RuleID:   "PL07",
+			Severity: core.SeverityWarning,
+			Message: fmt.Sprintf("Query plan of '%s' regressed since run %s (%s)",
+				model.Path, plans[1].RunID, strings.Join(parts, ", ")),
+			Model:            model.Path,
+			FilePath:         model.FilePath,
+			DocumentationURL: lint.BuildDocURL("PL07"),
+			ImpactScore:      lint.ImpactMedium.Int(),
+			AutoFixable:      false,
+		})
+	}
+
+	return diagnostics
+}
@@ -0,0 +1,99 @@
+package projectrules
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockPlanStore implements project.SnapshotStore and project.PlanStore for testing.
+type mockPlanStore struct {
+	mockSnapshotStore
+	plans map[string][]*core.ModelPlan // modelPath -> plans, newest first
+}
+
+func (m *mockPlanStore) GetModelPlans(modelPath string, limit int) ([]*core.ModelPlan, error) {
+	plans := m.plans[modelPath]
+	if limit > 0 && len(plans) > limit {
+		plans = plans[:limit]
+	}
+	return plans, nil
+}
+
+func TestPL07_PlanRegression(t *testing.T) {
+	models := map[string]*project.ModelInfo{
+		"marts.revenue": {
+			Path:     "marts.revenue",
+			Name:     "revenue",
+			FilePath: "/models/marts/revenue.sql",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		store   project.SnapshotStore
+		plans   []string // Plans of marts.revenue, newest first
+		wantMsg string
+	}{
+		{
+			name:  "store without plans - no diagnostic",
+			store: &mockSnapshotStore{},
+		},
+		{
+			name:  "single plan - no diagnostic",
+			plans: []string{"SEQ_SCAN orders"},
+		},
+		{
+			name:  "unchanged plan - no diagnostic",
+			plans: []string{"HASH_JOIN\nSEQ_SCAN orders\nSEQ_SCAN customers", "HASH_JOIN\nSEQ_SCAN orders\nSEQ_SCAN customers"},
+		},
+		{
+			name:  "fewer scans - no diagnostic",
+			plans: []string{"INDEX_SCAN orders", "SEQ_SCAN orders"},
+		},
+		{
+			name:    "new full scan",
+			plans:   []string{"SEQ_SCAN orders\nSEQ_SCAN customers", "SEQ_SCAN orders\nINDEX_SCAN customers"},
+			wantMsg: "Query plan of 'marts.revenue' regressed since run run-1 (full scans: 1 -> 2)",
+		},
+		{
+			name:    "new broadcast join",
+			plans:   []string{"BroadcastHashJoin [id]\nFileScan parquet orders", "SortMergeJoin [id]\nFileScan parquet orders"},
+			wantMsg: "Query plan of 'marts.revenue' regressed since run run-1 (broadcast joins: 0 -> 1)",
+		},
+		{
+			name:    "several changes",
+			plans:   []string{"Nested Loop\n  Seq Scan on orders\n  Seq Scan on customers", "Hash Join\n  Seq Scan on orders\n  Index Scan on customers"},
+			wantMsg: "Query plan of 'marts.revenue' regressed since run run-1 (full scans: 1 -> 2, nested loop joins: 0 -> 1)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.store
+			if store == nil {
+				plans := make([]*core.ModelPlan, len(tt.plans))
+				for i, p := range tt.plans {
+					plans[i] = &core.ModelPlan{RunID: []string{"run-2", "run-1"}[i], ModelPath: "marts.revenue", Plan: p}
+				}
+				store = &mockPlanStore{plans: map[string][]*core.ModelPlan{"marts.revenue": plans}}
+			}
+
+			ctx := project.NewContextWithStore(models, nil, nil, lint.DefaultProjectHealthConfig(), store)
+			diags := checkPlanRegression(ctx)
+
+			if tt.wantMsg == "" {
+				assert.Empty(t, diags)
+				return
+			}
+			if assert.Len(t, diags, 1) {
+				assert.Equal(t, "PL07", diags[0].RuleID)
+				assert.Equal(t, "marts.revenue", diags[0].Model)
+				assert.Equal(t, tt.wantMsg, diags[0].Message)
+			}
+		})
+	}
+}
@@ -16,6 +16,7 @@ func init() {
 		Description: "Models with no sources (broken DAG lineage)",
 		Severity:    core.SeverityWarning,
 		Check:       checkRootModels,
+		Scope:       project.ScopeModel,
 
 		Rationale: `Non-staging models without upstream dependencies indicate broken lineage. These "root" models 
 don't reference any tables, suggesting either a configuration error, a model that should be a seed, 
@@ -36,15 +37,16 @@ FROM {{ ref('stg_orders') }}`,
 // These are "root" models that don't reference any tables, which usually
 // indicates a broken lineage or a model that should be a seed.
 //
-// Staging models are expected to reference external sources (seeds/raw tables),
-// so only non-staging models without sources are flagged.
+// Models of layers that read raw tables (staging by default) are expected to
+// reference external sources (seeds/raw tables), so only other models without
+// sources are flagged.
 func checkRootModels(ctx *project.Context) []project.Diagnostic {
 	var diagnostics []project.Diagnostic
 
-	for _, model := range ctx.Models() {
+	for _, model := range ctx.CheckedModels() {
 		// Skip staging models - they're expected to reference external sources
 		// which may not be in our model list
-		if model.Type == core.ModelTypeStaging {
+		if readsSources(ctx, model) {
 			continue
 		}
 
@@ -15,7 +15,7 @@ func init() {
 		ID:          "PM02",
 		Name:        "source-fanout",
 		Group:       "modeling",
-		Description: "Source referenced by multiple non-staging models",
+		Description: "Source or seed referenced by multiple non-staging models",
 		Severity:    core.SeverityWarning,
 		Check:       checkSourceFanout,
 
@@ -45,6 +45,7 @@ SELECT * FROM {{ ref('stg_orders') }}  -- Reference staging model`,
 //
 // Best practice: Each raw source should be referenced by exactly one
 // staging model, which then provides a clean interface for downstream models.
+// Staging is any layer whose models read raw tables.
 func checkSourceFanout(ctx *project.Context) []project.Diagnostic {
 	var diagnostics []project.Diagnostic
 
@@ -53,7 +54,7 @@ func checkSourceFanout(ctx *project.Context) []project.Diagnostic {
 
 	for _, model := range ctx.Models() {
 		// Only look at non-staging models
-		if model.Type == core.ModelTypeStaging {
+		if readsSources(ctx, model) {
 			continue
 		}
 
@@ -66,6 +67,7 @@ func checkSourceFanout(ctx *project.Context) []project.Diagnostic {
 	}
 
 	// Find sources with multiple non-staging consumers
+	sources := ctx.Sources()
 	for source, consumers := range sourceRefs {
 		if len(consumers) > 1 {
 			sort.Strings(consumers)
@@ -73,8 +75,9 @@ func checkSourceFanout(ctx *project.Context) []project.Diagnostic {
 				RuleID:   "PM02",
 				Severity: core.SeverityWarning,
 				Message: fmt.Sprintf(
-					"Source '%s' is referenced by %d non-staging models (%s); consider creating a staging model",
-					source, len(consumers), strings.Join(consumers, ", ")),
+					"%s '%s' is referenced by %d non-staging models (%s); %s",
+					capitalize(sourceKind(ctx, source)), source, len(consumers), strings.Join(consumers, ", "),
+					stagingAdvice(ctx, sources[source])),
 				Model:            consumers[0], // Associate with first consumer
 				DocumentationURL: lint.BuildDocURL("PM02"),
 				ImpactScore:      lint.ImpactMedium.Int(),
@@ -85,3 +88,27 @@ func checkSourceFanout(ctx *project.Context) []project.Diagnostic {
 
 	return diagnostics
 }
+
+// stagingAdvice points at the staging models already reading src, if any.
+func stagingAdvice(ctx *project.Context, src *project.SourceInfo) string {
+	var staging []string
+	if src != nil {
+		for _, path := range src.Consumers {
+			if model, ok := ctx.GetModel(path); ok && readsSources(ctx, model) {
+				staging = append(staging, "'"+model.Name+"'")
+			}
+		}
+	}
+	if len(staging) == 0 {
+		return "no staging model reads it yet, consider creating one"
+	}
+	return "reference " + strings.Join(staging, " or ") + " instead"
+}
+
+// capitalize upper-cases the first letter of s.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
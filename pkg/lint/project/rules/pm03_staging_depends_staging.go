@@ -13,13 +13,15 @@ func init() {
 		ID:          "PM03",
 		Name:        "staging-depends-staging",
 		Group:       "modeling",
-		Description: "Staging model references another staging model",
+		Description: "Model references a model from a layer it may not depend on (by default, staging on staging)",
 		Severity:    core.SeverityWarning,
 		Check:       checkStagingDependsStaging,
+		Scope:       project.ScopeNeighbors,
 
 		Rationale: `Staging models should only reference raw sources, not other staging models. When staging models 
 depend on each other, it blurs the boundary between data cleaning (staging) and data transformation 
-(intermediate/marts). If you need to combine staging models, create an intermediate model instead.`,
+(intermediate/marts). If you need to combine staging models, create an intermediate model instead. Projects that declare 
+their own layers set which layers each may reference with depends_on.`,
 
 		BadExample: `-- models/staging/stg_orders_enhanced.sql
 SELECT o.*, c.name
@@ -35,42 +37,43 @@ JOIN {{ ref('stg_customers') }} c ON o.customer_id = c.id`,
 	})
 }
 
-// checkStagingDependsStaging flags staging models that depend on other
-// staging models. Staging models should only reference raw sources,
-// not other staging models.
+// checkStagingDependsStaging flags models that depend on a model from a layer
+// their layer may not reference, per the layer's depends_on. By default,
+// staging models may only reference raw sources, not other staging models.
 //
 // Best practice: Staging models clean and normalize raw data. If you need
 // to combine staging models, create an intermediate model instead.
 func checkStagingDependsStaging(ctx *project.Context) []project.Diagnostic {
 	var diagnostics []project.Diagnostic
 
-	for _, model := range ctx.Models() {
-		// Only check staging models
-		if model.Type != core.ModelTypeStaging {
+	for _, model := range ctx.CheckedModels() {
+		layer, ok := ctx.Layer(model)
+		if !ok || len(layer.DependsOn) == 0 {
 			continue
 		}
 
-		// Check if any of its sources are also staging models
+		// Check the layer of each source that is a model
 		for _, source := range model.Sources {
 			sourceModel, ok := ctx.GetModel(source)
 			if !ok {
 				continue // External source, not a model
 			}
-
-			if sourceModel.Type == core.ModelTypeStaging {
-				diagnostics = append(diagnostics, project.Diagnostic{
-					RuleID:   "PM03",
-					Severity: core.SeverityWarning,
-					Message: fmt.Sprintf(
-						"Staging model '%s' depends on staging model '%s'; staging should only reference raw sources",
-						model.Name, sourceModel.Name),
-					Model:            model.Path,
-					FilePath:         model.FilePath,
-					DocumentationURL: lint.BuildDocURL("PM03"),
-					ImpactScore:      lint.ImpactMedium.Int(),
-					AutoFixable:      false,
-				})
+			if _, ok := ctx.Layer(sourceModel); !ok || layer.Allows(string(sourceModel.Type)) {
+				continue
 			}
+
+			diagnostics = append(diagnostics, project.Diagnostic{
+				RuleID:   "PM03",
+				Severity: core.SeverityWarning,
+				Message: fmt.Sprintf(
+					"%s model '%s' depends on %s model '%s'; %s models should only reference %s",
+					layer.Name, model.Name, sourceModel.Type, sourceModel.Name, layer.Name, describeDependsOn(layer)),
+				Model:            model.Path,
+				FilePath:         model.FilePath,
+				DocumentationURL: lint.BuildDocURL("PM03"),
+				ImpactScore:      lint.ImpactMedium.Int(),
+				AutoFixable:      false,
+			})
 		}
 	}
 
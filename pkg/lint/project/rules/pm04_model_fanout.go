@@ -18,7 +18,10 @@ func init() {
 		Description: "Model has too many direct downstream consumers",
 		Severity:    core.SeverityWarning,
 		Check:       checkModelFanout,
-		ConfigKeys:  []string{"threshold"},
+		Scope:       project.ScopeNeighbors,
+		Options: []core.RuleOption{
+			{Name: "threshold", Type: core.OptionInt, Default: 3, Min: 1, Description: "Overrides lint.project_health.thresholds.model_fanout"},
+		},
 
 		Rationale: `Models with many downstream consumers become bottlenecks for changes. A "God Model" that many 
 models depend on makes refactoring risky since changes affect many downstream models. Consider whether 
@@ -50,7 +53,7 @@ func checkModelFanout(ctx *project.Context) []project.Diagnostic {
 		threshold = 3 // default
 	}
 
-	for path, model := range ctx.Models() {
+	for path, model := range ctx.CheckedModels() {
 		children := ctx.GetChildren(path)
 		if len(children) > threshold {
 			// Sort children for consistent output
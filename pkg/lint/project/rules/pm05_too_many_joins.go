@@ -18,7 +18,10 @@ func init() {
 		Description: "Model references too many upstream models",
 		Severity:    core.SeverityWarning,
 		Check:       checkTooManyJoins,
-		ConfigKeys:  []string{"threshold"},
+		Scope:       project.ScopeModel,
+		Options: []core.RuleOption{
+			{Name: "threshold", Type: core.OptionInt, Default: 7, Min: 1, Description: "Overrides lint.project_health.thresholds.too_many_joins"},
+		},
 
 		Rationale: `High join counts often indicate a "God Model" that tries to do too much in a single query. 
 These models are hard to understand, slow to execute, and difficult to maintain. Breaking complex 
@@ -59,7 +62,7 @@ func checkTooManyJoins(ctx *project.Context) []project.Diagnostic {
 		threshold = 7 // default
 	}
 
-	for _, model := range ctx.Models() {
+	for _, model := range ctx.CheckedModels() {
 		// Count unique upstream model references
 		if len(model.Sources) > threshold {
 			// Sort sources for consistent output
@@ -2,6 +2,7 @@ package projectrules
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/lint"
@@ -13,13 +14,15 @@ func init() {
 		ID:          "PM06",
 		Name:        "downstream-on-source",
 		Group:       "modeling",
-		Description: "Marts or intermediate model depends directly on source (not staging)",
+		Description: "Marts or intermediate model depends directly on a source or seed (not staging)",
 		Severity:    core.SeverityWarning,
 		Check:       checkDownstreamOnSource,
+		Scope:       project.ScopeNeighbors,
 
 		Rationale: `The recommended transformation pattern is Sources → Staging → Intermediate → Marts. When marts 
 or intermediate models reference sources directly, they bypass data cleaning in staging, leading to 
-duplicated transformation logic and making lineage harder to understand.`,
+duplicated transformation logic and making lineage harder to understand. Seeds are raw tables too: 
+reading them through a staging model gives their columns proper names and types in one place.`,
 
 		BadExample: `-- models/marts/fct_orders.sql
 SELECT * FROM raw.orders  -- Direct source reference in marts`,
@@ -45,13 +48,22 @@ SELECT * FROM {{ ref('stg_orders') }}  -- Reference staging`,
 //   - Leads to duplicated transformation logic
 //   - Makes lineage harder to understand
 //
-// Best practice: All raw sources should flow through staging models first.
+// Best practice: All raw sources, seeds included, should flow through
+// staging models first.
+// Declared layers that do not list "source" in depends_on are checked the
+// same way.
 func checkDownstreamOnSource(ctx *project.Context) []project.Diagnostic {
 	var diagnostics []project.Diagnostic
 
-	for _, model := range ctx.Models() {
-		// Only check marts and intermediate models
-		if model.Type != core.ModelTypeMarts && model.Type != core.ModelTypeIntermediate {
+	instead := "reference a model instead"
+	if names := sourceLayerNames(ctx); len(names) > 0 {
+		instead = "use a " + strings.Join(names, " or ") + " model instead"
+	}
+
+	for _, model := range ctx.CheckedModels() {
+		// Only check layers that may not read raw sources (marts and intermediate by default)
+		layer, ok := ctx.Layer(model)
+		if !ok || layer.Allows(core.SourceLayer) {
 			continue
 		}
 
@@ -63,8 +75,8 @@ func checkDownstreamOnSource(ctx *project.Context) []project.Diagnostic {
 					RuleID:   "PM06",
 					Severity: core.SeverityWarning,
 					Message: fmt.Sprintf(
-						"%s model '%s' depends directly on source '%s'; use a staging model instead",
-						string(model.Type), model.Name, source),
+						"%s model '%s' depends directly on %s '%s'; %s",
+						layer.Name, model.Name, sourceKind(ctx, source), source, instead),
 					Model:            model.Path,
 					FilePath:         model.FilePath,
 					DocumentationURL: lint.BuildDocURL("PM06"),
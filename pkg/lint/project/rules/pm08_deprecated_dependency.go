@@ -0,0 +1,89 @@
+package projectrules
+
+import (
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+)
+
+func init() {
+	project.Register(project.RuleDef{
+		ID:          "PM08",
+		Name:        "deprecated-dependency",
+		Group:       "modeling",
+		Description: "Model depends on a deprecated model",
+		Severity:    core.SeverityWarning,
+		Check:       checkDeprecatedDependency,
+		Scope:       project.ScopeNeighbors,
+
+		Rationale: `A deprecated model is scheduled for removal or has been superseded by a new version. 
+Models that still depend on it will break when it is removed, and keep reading data the owners no 
+longer maintain. Migrating consumers early keeps the removal a non-event.`,
+
+		BadExample: `-- models/marts/dim_users_v1.sql
+/*---
+name: dim_users
+version: 1
+deprecated:
+  since: "2024-06-01"
+  replacement: dim_users@v2
+---*/
+
+-- models/marts/fct_orders.sql
+SELECT * FROM {{ ref('dim_users', v=1) }}  -- Pinned to the deprecated version`,
+
+		GoodExample: `-- models/marts/fct_orders.sql
+SELECT * FROM {{ ref('dim_users', v=2) }}  -- Uses the replacement`,
+
+		Fix: "Point the model at the replacement named in the deprecation notice, then remove the deprecated model once it has no consumers.",
+	})
+}
+
+// checkDeprecatedDependency flags models whose direct parents are deprecated.
+// Deprecated models themselves are not flagged for depending on each other,
+// since they are on their way out together.
+func checkDeprecatedDependency(ctx *project.Context) []project.Diagnostic {
+	var diagnostics []project.Diagnostic
+
+	for _, model := range ctx.CheckedModels() {
+		if model.Deprecated != nil {
+			continue
+		}
+
+		for _, parentPath := range ctx.GetParents(model.Path) {
+			parent, ok := ctx.GetModel(parentPath)
+			if !ok || parent.Deprecated == nil {
+				continue
+			}
+
+			message := fmt.Sprintf("model '%s' depends on deprecated model '%s'", model.Name, deprecatedName(parent))
+			if parent.Deprecated.Since != "" {
+				message += fmt.Sprintf(" (deprecated since %s)", parent.Deprecated.Since)
+			}
+			if parent.Deprecated.Replacement != "" {
+				message += fmt.Sprintf("; use '%s' instead", parent.Deprecated.Replacement)
+			}
+
+			diagnostics = append(diagnostics, project.Diagnostic{
+				RuleID:           "PM08",
+				Severity:         core.SeverityWarning,
+				Message:          message,
+				Model:            model.Path,
+				FilePath:         model.FilePath,
+				DocumentationURL: lint.BuildDocURL("PM08"),
+				ImpactScore:      lint.ImpactMedium.Int(),
+				AutoFixable:      false,
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+// deprecatedName returns the name a deprecated model is referenced by,
+// including its version for versioned models (e.g., "dim_users@v1").
+func deprecatedName(m *project.ModelInfo) string {
+	return core.VersionedName(m.Name, m.Version)
+}
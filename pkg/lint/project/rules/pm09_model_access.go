@@ -0,0 +1,129 @@
+package projectrules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+)
+
+func init() {
+	project.Register(project.RuleDef{
+		ID:          "PM09",
+		Name:        "model-access",
+		Group:       "modeling",
+		Description: "Model references a model outside its access level",
+		Severity:    core.SeverityError,
+		Check:       checkModelAccess,
+		Scope:       project.ScopeNeighbors,
+
+		Rationale: `Access levels let a team declare which models are part of its interface and which are 
+implementation details. A private model may only be referenced from its own directory group, and a 
+protected model (the default) only from its own project. Referencing past these boundaries couples 
+teams to internals that can change without notice.`,
+
+		BadExample: `-- models/finance/_int_ledger.sql
+/*---
+access: private
+---*/
+
+-- models/marketing/fct_spend.sql
+SELECT * FROM finance._int_ledger  -- Private to models/finance/`,
+
+		GoodExample: `-- models/finance/fct_ledger.sql
+/*---
+access: public
+---*/
+SELECT * FROM finance._int_ledger
+
+-- models/marketing/fct_spend.sql
+SELECT * FROM finance.fct_ledger`,
+
+		Fix: "Reference a public or protected model that exposes the data, or widen the access level of the referenced model if it is meant to be shared.",
+	})
+}
+
+// checkModelAccess flags references to private models from outside their
+// directory group, and to protected models from other workspace projects.
+func checkModelAccess(ctx *project.Context) []project.Diagnostic {
+	var diagnostics []project.Diagnostic
+
+	for _, model := range ctx.CheckedModels() {
+		for _, parentPath := range ctx.GetParents(model.Path) {
+			parent, ok := ctx.GetModel(parentPath)
+			if !ok {
+				continue
+			}
+
+			var reason string
+			switch parent.Access {
+			case core.AccessPublic:
+				continue
+			case core.AccessPrivate:
+				if inDirectoryGroup(model.Path, parent.Path) {
+					continue
+				}
+				if group := directoryGroup(parent.Path); group != "" {
+					reason = fmt.Sprintf("private to '%s'", group)
+				} else {
+					reason = fmt.Sprintf("private to project '%s'", parent.Project)
+				}
+			default:
+				if model.Project == parent.Project {
+					continue
+				}
+				reason = fmt.Sprintf("protected to project '%s'", parent.Project)
+			}
+
+			diagnostics = append(diagnostics, project.Diagnostic{
+				RuleID:           "PM09",
+				Severity:         core.SeverityError,
+				Message:          fmt.Sprintf("model '%s' references '%s', which is %s", model.Path, parent.Path, reason),
+				Model:            model.Path,
+				FilePath:         model.FilePath,
+				DocumentationURL: lint.BuildDocURL("PM09"),
+				ImpactScore:      lint.ImpactHigh.Int(),
+				AutoFixable:      false,
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+// directoryGroup returns the directory a model path belongs to, prefixed
+// with its workspace project, e.g. "finance" for "finance._int_ledger" and
+// "core/marts" for "core/marts.dim_users". It is empty for models at the
+// root of their project.
+func directoryGroup(modelPath string) string {
+	project, path := core.SplitModelID(modelPath)
+	dir := modelDirectory(path)
+	if dir == "" {
+		return ""
+	}
+	return core.ModelID(project, dir)
+}
+
+// modelDirectory returns the directory of a model path within its project.
+func modelDirectory(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// inDirectoryGroup reports whether modelPath lives in the directory group of
+// privatePath or one of its subdirectories. The group of a model at the root
+// of its project is the whole project.
+func inDirectoryGroup(modelPath, privatePath string) bool {
+	project, path := core.SplitModelID(modelPath)
+	privateProject, private := core.SplitModelID(privatePath)
+	if project != privateProject {
+		return false
+	}
+	group := modelDirectory(private)
+	dir := modelDirectory(path)
+	return group == "" || dir == group || strings.HasPrefix(dir, group+".")
+}
@@ -0,0 +1,70 @@
+package projectrules
+
+import (
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+)
+
+func init() {
+	project.Register(project.RuleDef{
+		ID:          "PM10",
+		Name:        "unknown-group",
+		Group:       "modeling",
+		Description: "Model belongs to a group that is not declared in config",
+		Severity:    core.SeverityError,
+		Check:       checkUnknownGroup,
+		Scope:       project.ScopeModel,
+
+		Rationale: `Groups route ownership: impact analysis and run failures are reported to the owner 
+and channel declared for a model's group. A model assigned to an undeclared group, usually through a 
+typo, has no owner to route to.`,
+
+		BadExample: `# leapsql.yaml
+groups:
+  - name: finance
+    owner: finance-data
+
+-- models/marts/fct_revenue.sql
+/*---
+group: fnance  -- Typo, not a declared group
+---*/`,
+
+		GoodExample: `-- models/marts/fct_revenue.sql
+/*---
+group: finance
+---*/`,
+
+		Fix: "Fix the group name in the model's frontmatter, or declare the group under `groups` in leapsql.yaml.",
+	})
+}
+
+// checkUnknownGroup flags models whose group is not declared in config.
+func checkUnknownGroup(ctx *project.Context) []project.Diagnostic {
+	var diagnostics []project.Diagnostic
+	groups := ctx.GetConfig().Groups
+
+	for _, model := range ctx.CheckedModels() {
+		if model.Group == "" {
+			continue
+		}
+		if _, ok := groups[model.Group]; ok {
+			continue
+		}
+
+		diagnostics = append(diagnostics, project.Diagnostic{
+			RuleID:           "PM10",
+			Severity:         core.SeverityError,
+			Message:          fmt.Sprintf("model '%s' belongs to undeclared group '%s'", model.Name, model.Group),
+			Model:            model.Path,
+			FilePath:         model.FilePath,
+			DocumentationURL: lint.BuildDocURL("PM10"),
+			ImpactScore:      lint.ImpactMedium.Int(),
+			AutoFixable:      false,
+		})
+	}
+
+	return diagnostics
+}
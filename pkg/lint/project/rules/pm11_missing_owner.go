@@ -0,0 +1,65 @@
+package projectrules
+
+import (
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+)
+
+func init() {
+	project.Register(project.RuleDef{
+		ID:          "PM11",
+		Name:        "missing-owner",
+		Group:       "modeling",
+		Description: "Model has no owning group or owner",
+		Severity:    core.SeverityWarning,
+		Check:       checkMissingOwner,
+		Scope:       project.ScopeModel,
+
+		Rationale: `Once a project declares groups, every model should have someone responsible for it. 
+Unowned models are the ones nobody is told about when they break, and nobody feels safe changing. 
+This rule only runs when groups are declared in config.`,
+
+		BadExample: `-- models/marts/fct_revenue.sql
+SELECT * FROM {{ ref('stg_payments') }}  -- No group or owner`,
+
+		GoodExample: `-- models/marts/fct_revenue.sql
+/*---
+group: finance
+---*/
+SELECT * FROM {{ ref('stg_payments') }}`,
+
+		Fix: "Assign the model to a group with `group:` in its frontmatter, or set an `owner:`.",
+	})
+}
+
+// checkMissingOwner flags models with neither a group nor an owner.
+// Projects that declare no groups have not opted into ownership, so the rule is skipped.
+func checkMissingOwner(ctx *project.Context) []project.Diagnostic {
+	if len(ctx.GetConfig().Groups) == 0 {
+		return nil
+	}
+
+	var diagnostics []project.Diagnostic
+
+	for _, model := range ctx.CheckedModels() {
+		if model.Group != "" || model.Owner != "" {
+			continue
+		}
+
+		diagnostics = append(diagnostics, project.Diagnostic{
+			RuleID:           "PM11",
+			Severity:         core.SeverityWarning,
+			Message:          fmt.Sprintf("model '%s' has no owning group or owner", model.Name),
+			Model:            model.Path,
+			FilePath:         model.FilePath,
+			DocumentationURL: lint.BuildDocURL("PM11"),
+			ImpactScore:      lint.ImpactLow.Int(),
+			AutoFixable:      false,
+		})
+	}
+
+	return diagnostics
+}
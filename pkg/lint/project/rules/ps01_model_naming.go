@@ -2,7 +2,7 @@ package projectrules
 
 import (
 	"fmt"
-	"strings"
+	"slices"
 
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/lint"
@@ -17,10 +17,12 @@ func init() {
 		Description: "Model naming convention mismatch",
 		Severity:    core.SeverityWarning,
 		Check:       checkModelNaming,
+		Scope:       project.ScopeModel,
 
 		Rationale: `Consistent naming conventions make it easy to identify model types at a glance. Models in specific 
 directories should follow the expected prefix convention: staging models use 'stg_', intermediate 
-models use 'int_', and marts models use 'fct_' or 'dim_'.`,
+models use 'int_', and marts models use 'fct_' or 'dim_'. Projects that declare their own layers set 
+each layer's directories and prefixes with paths and prefixes.`,
 
 		BadExample: `-- models/staging/orders.sql (missing stg_ prefix)
 -- models/marts/order_metrics.sql (missing fct_ or dim_ prefix)`,
@@ -34,74 +36,38 @@ models use 'int_', and marts models use 'fct_' or 'dim_'.`,
 }
 
 // checkModelNaming flags models where the directory location and name prefix
-// don't match the expected convention.
+// don't match the expected convention of their layer.
 //
-// Expected patterns:
+// Expected patterns with the default layers:
 //   - Models in /staging/ should have names starting with stg_
 //   - Models in /intermediate/ should have names starting with int_
 //   - Models in /marts/ should have names starting with fct_ or dim_
+//
+// Models named with another layer's prefix are left to PS02.
 func checkModelNaming(ctx *project.Context) []project.Diagnostic {
 	var diagnostics []project.Diagnostic
 
-	for _, model := range ctx.Models() {
-		pathLower := strings.ToLower(model.FilePath)
-		nameLower := strings.ToLower(model.Name)
-
-		// Check staging directory convention
-		if strings.Contains(pathLower, "/staging/") {
-			if !strings.HasPrefix(nameLower, "stg_") {
-				diagnostics = append(diagnostics, project.Diagnostic{
-					RuleID:           "PS01",
-					Severity:         core.SeverityWarning,
-					Message:          fmt.Sprintf("Model '%s' is in staging directory but doesn't have 'stg_' prefix", model.Name),
-					Model:            model.Path,
-					FilePath:         model.FilePath,
-					DocumentationURL: lint.BuildDocURL("PS01"),
-					ImpactScore:      lint.ImpactLow.Int(),
-					AutoFixable:      false,
-				})
-			}
+	layers := ctx.Layers()
+	for _, model := range ctx.CheckedModels() {
+		layer, ok := ctx.Layer(model)
+		if !ok || len(layer.Prefixes) == 0 || !layer.MatchesPath(model.FilePath) {
+			continue
 		}
-
-		// Check intermediate directory convention
-		if strings.Contains(pathLower, "/intermediate/") {
-			if !strings.HasPrefix(nameLower, "int_") {
-				diagnostics = append(diagnostics, project.Diagnostic{
-					RuleID:           "PS01",
-					Severity:         core.SeverityWarning,
-					Message:          fmt.Sprintf("Model '%s' is in intermediate directory but doesn't have 'int_' prefix", model.Name),
-					Model:            model.Path,
-					FilePath:         model.FilePath,
-					DocumentationURL: lint.BuildDocURL("PS01"),
-					ImpactScore:      lint.ImpactLow.Int(),
-					AutoFixable:      false,
-				})
-			}
+		if slices.ContainsFunc(layers, func(l core.LayerConfig) bool { return l.MatchesName(model.Name) }) {
+			continue
 		}
 
-		// Check marts directory convention
-		if strings.Contains(pathLower, "/marts/") {
-			isFact := strings.HasPrefix(nameLower, "fct_")
-			isDim := strings.HasPrefix(nameLower, "dim_")
-			// Also allow intermediate models in marts (some teams structure this way)
-			isInt := strings.HasPrefix(nameLower, "int_")
-
-			if !isFact && !isDim && !isInt {
-				// Only warn if the model type is marts (inferred)
-				if model.Type == core.ModelTypeMarts {
-					diagnostics = append(diagnostics, project.Diagnostic{
-						RuleID:           "PS01",
-						Severity:         core.SeverityWarning,
-						Message:          fmt.Sprintf("Model '%s' is in marts directory but doesn't have 'fct_' or 'dim_' prefix", model.Name),
-						Model:            model.Path,
-						FilePath:         model.FilePath,
-						DocumentationURL: lint.BuildDocURL("PS01"),
-						ImpactScore:      lint.ImpactLow.Int(),
-						AutoFixable:      false,
-					})
-				}
-			}
-		}
+		diagnostics = append(diagnostics, project.Diagnostic{
+			RuleID:   "PS01",
+			Severity: core.SeverityWarning,
+			Message: fmt.Sprintf("Model '%s' is in %s directory but doesn't have %s prefix",
+				model.Name, layer.Name, quotedPrefixes(layer.Prefixes)),
+			Model:            model.Path,
+			FilePath:         model.FilePath,
+			DocumentationURL: lint.BuildDocURL("PS01"),
+			ImpactScore:      lint.ImpactLow.Int(),
+			AutoFixable:      false,
+		})
 	}
 
 	return diagnostics
@@ -2,6 +2,7 @@ package projectrules
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/leapstack-labs/leapsql/pkg/core"
@@ -17,10 +18,12 @@ func init() {
 		Description: "Model directory mismatch",
 		Severity:    core.SeverityWarning,
 		Check:       checkModelDirectory,
+		Scope:       project.ScopeModel,
 
 		Rationale: `A model's name prefix should match its directory location. When a model is named 'stg_orders' but 
 placed in 'marts/', it creates confusion about the model's purpose and breaks organizational 
-conventions that teams rely on for navigation.`,
+conventions that teams rely on for navigation. The prefixes and directories of each layer can be 
+declared with layers in leapsql.yaml.`,
 
 		BadExample: `-- models/marts/stg_orders.sql (stg_ model in marts directory)
 -- models/staging/fct_revenue.sql (fct_ model in staging directory)`,
@@ -32,67 +35,49 @@ conventions that teams rely on for navigation.`,
 	})
 }
 
-// checkModelDirectory flags models where the name prefix suggests a type
-// but the model is not in the expected directory.
+// checkModelDirectory flags models where the name prefix suggests a layer
+// but the model is not in one of the layer's directories.
 //
-// For example:
+// For example, with the default layers:
 //   - stg_customers.sql should be in staging/, not in marts/
 //   - fct_orders.sql should be in marts/, not in staging/
 func checkModelDirectory(ctx *project.Context) []project.Diagnostic {
 	var diagnostics []project.Diagnostic
 
-	for _, model := range ctx.Models() {
-		pathLower := strings.ToLower(model.FilePath)
-		nameLower := strings.ToLower(model.Name)
-
-		// Check if stg_ prefix model is in staging directory
-		if strings.HasPrefix(nameLower, "stg_") {
-			if !strings.Contains(pathLower, "/staging/") {
-				diagnostics = append(diagnostics, project.Diagnostic{
-					RuleID:           "PS02",
-					Severity:         core.SeverityWarning,
-					Message:          fmt.Sprintf("Model '%s' has 'stg_' prefix but is not in staging directory", model.Name),
-					Model:            model.Path,
-					FilePath:         model.FilePath,
-					DocumentationURL: lint.BuildDocURL("PS02"),
-					ImpactScore:      lint.ImpactLow.Int(),
-					AutoFixable:      false,
-				})
-			}
+	layers := ctx.Layers()
+	for _, model := range ctx.CheckedModels() {
+		i := slices.IndexFunc(layers, func(l core.LayerConfig) bool { return l.MatchesName(model.Name) })
+		if i < 0 {
+			continue
 		}
-
-		// Check if int_ prefix model is in intermediate directory
-		if strings.HasPrefix(nameLower, "int_") {
-			if !strings.Contains(pathLower, "/intermediate/") {
-				diagnostics = append(diagnostics, project.Diagnostic{
-					RuleID:           "PS02",
-					Severity:         core.SeverityWarning,
-					Message:          fmt.Sprintf("Model '%s' has 'int_' prefix but is not in intermediate directory", model.Name),
-					Model:            model.Path,
-					FilePath:         model.FilePath,
-					DocumentationURL: lint.BuildDocURL("PS02"),
-					ImpactScore:      lint.ImpactLow.Int(),
-					AutoFixable:      false,
-				})
-			}
+		layer := layers[i]
+		if len(layer.Paths) == 0 || layer.MatchesPath(model.FilePath) {
+			continue
 		}
 
-		// Check if fct_/dim_ prefix model is in marts directory
-		if strings.HasPrefix(nameLower, "fct_") || strings.HasPrefix(nameLower, "dim_") {
-			if !strings.Contains(pathLower, "/marts/") {
-				diagnostics = append(diagnostics, project.Diagnostic{
-					RuleID:           "PS02",
-					Severity:         core.SeverityWarning,
-					Message:          fmt.Sprintf("Model '%s' has 'fct_' or 'dim_' prefix but is not in marts directory", model.Name),
-					Model:            model.Path,
-					FilePath:         model.FilePath,
-					DocumentationURL: lint.BuildDocURL("PS02"),
-					ImpactScore:      lint.ImpactLow.Int(),
-					AutoFixable:      false,
-				})
-			}
-		}
+		diagnostics = append(diagnostics, project.Diagnostic{
+			RuleID:   "PS02",
+			Severity: core.SeverityWarning,
+			Message: fmt.Sprintf("Model '%s' has %s prefix but is not in %s directory",
+				model.Name, quotedPrefixes(matchingPrefixes(layer, model.Name)), layer.Name),
+			Model:            model.Path,
+			FilePath:         model.FilePath,
+			DocumentationURL: lint.BuildDocURL("PS02"),
+			ImpactScore:      lint.ImpactLow.Int(),
+			AutoFixable:      false,
+		})
 	}
 
 	return diagnostics
 }
+
+// matchingPrefixes returns the layer's prefixes that name starts with.
+func matchingPrefixes(layer core.LayerConfig, name string) []string {
+	var prefixes []string
+	for _, p := range layer.Prefixes {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(p)) {
+			prefixes = append(prefixes, p)
+		}
+	}
+	return prefixes
+}
@@ -0,0 +1,69 @@
+package projectrules
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+)
+
+func init() {
+	project.Register(project.RuleDef{
+		ID:          "PS03",
+		Name:        "seed-naming",
+		Group:       "structure",
+		Description: "Seed name is not lowercase snake_case",
+		Severity:    core.SeverityWarning,
+		Check:       checkSeedNaming,
+
+		Rationale: `A seed's file name becomes the name of its table, and models reference it by that name. Names 
+with capitals, spaces or dashes must be quoted in SQL and are easily mistyped, and depending on the 
+database their case may or may not be preserved.`,
+
+		BadExample: `-- seeds/Country Codes.csv
+-- seeds/payment-methods.csv`,
+
+		GoodExample: `-- seeds/country_codes.csv
+-- seeds/payment_methods.csv`,
+
+		Fix: "Rename the CSV file to a lowercase snake_case name and update the models that read it.",
+	})
+}
+
+// seedNamePattern matches lowercase snake_case table names.
+var seedNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// checkSeedNaming flags seeds whose table name is not lowercase snake_case.
+func checkSeedNaming(ctx *project.Context) []project.Diagnostic {
+	var diagnostics []project.Diagnostic
+
+	var seeds []*project.SourceInfo
+	for name, src := range ctx.Sources() {
+		// Seeds read with a schema are listed twice; report them once
+		if src.Kind == project.SourceKindSeed && name == src.Name {
+			seeds = append(seeds, src)
+		}
+	}
+	sort.Slice(seeds, func(i, j int) bool { return seeds[i].Name < seeds[j].Name })
+
+	for _, seed := range seeds {
+		if seedNamePattern.MatchString(seed.Name) {
+			continue
+		}
+		diagnostics = append(diagnostics, project.Diagnostic{
+			RuleID:           "PS03",
+			Severity:         core.SeverityWarning,
+			Message:          fmt.Sprintf("Seed '%s' is not named in lowercase snake_case", seed.Name),
+			Model:            seed.Name,
+			FilePath:         seed.FilePath,
+			DocumentationURL: lint.BuildDocURL("PS03"),
+			ImpactScore:      lint.ImpactLow.Int(),
+			AutoFixable:      false,
+		})
+	}
+
+	return diagnostics
+}
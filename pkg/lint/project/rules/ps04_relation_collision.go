@@ -0,0 +1,97 @@
+package projectrules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/project"
+)
+
+func init() {
+	project.Register(project.RuleDef{
+		ID:          "PS04",
+		Name:        "relation-collision",
+		Group:       "structure",
+		Description: "Models are built as the same table",
+		Severity:    core.SeverityError,
+		Check:       checkRelationCollision,
+
+		Rationale: `A model's table is named after its schema and name, which frontmatter schema: and a 
+generate_schema_name macro can change. Two models that end up with the same table overwrite each 
+other on every run, and references to the table read whichever model was built last.`,
+
+		BadExample: `-- models/staging/stg_orders.sql
+/*---
+schema: analytics
+---*/
+
+-- models/marts/stg_orders.sql
+/*---
+schema: analytics  -- Both are built as analytics.stg_orders
+---*/`,
+
+		GoodExample: `-- models/staging/stg_orders.sql
+/*---
+schema: analytics
+---*/
+
+-- models/marts/fct_orders.sql
+/*---
+schema: analytics
+---*/`,
+
+		Fix: "Rename one of the models, or give it a different `schema:`. If a `generate_schema_name` macro maps different schemas to one, make it keep them apart.",
+	})
+}
+
+// checkRelationCollision flags models whose tables have the same name.
+// Table names are compared case-insensitively, as most databases fold them.
+func checkRelationCollision(ctx *project.Context) []project.Diagnostic {
+	byRelation := make(map[string][]*project.ModelInfo)
+	for _, model := range ctx.Models() {
+		if model.Relation == "" {
+			continue
+		}
+		key := strings.ToLower(model.Relation)
+		byRelation[key] = append(byRelation[key], model)
+	}
+
+	relations := make([]string, 0, len(byRelation))
+	for relation := range byRelation {
+		relations = append(relations, relation)
+	}
+	sort.Strings(relations)
+
+	var diagnostics []project.Diagnostic
+	for _, relation := range relations {
+		models := byRelation[relation]
+		if len(models) < 2 {
+			continue
+		}
+		sort.Slice(models, func(i, j int) bool { return models[i].Path < models[j].Path })
+
+		for _, model := range models {
+			var others []string
+			for _, other := range models {
+				if other != model {
+					others = append(others, other.Path)
+				}
+			}
+			diagnostics = append(diagnostics, project.Diagnostic{
+				RuleID:           "PS04",
+				Severity:         core.SeverityError,
+				Message:          fmt.Sprintf("model '%s' is built as %s, the same table as %s", model.Path, model.Relation, strings.Join(others, ", ")),
+				Model:            model.Path,
+				FilePath:         model.FilePath,
+				DocumentationURL: lint.BuildDocURL("PS04"),
+				ImpactScore:      lint.ImpactHigh.Int(),
+				AutoFixable:      false,
+			})
+		}
+	}
+
+	return diagnostics
+}
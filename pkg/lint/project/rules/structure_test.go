@@ -116,3 +116,73 @@ func TestPS02_ModelDirectory(t *testing.T) {
 		})
 	}
 }
+
+func TestPS_CustomLayers(t *testing.T) {
+	layers := []core.LayerConfig{
+		{Name: "bronze", Paths: []string{"bronze"}, Prefixes: []string{"brz_"}},
+		{Name: "gold", Paths: []string{"gold"}, Prefixes: []string{"gld_", "rpt_"}},
+	}
+	models := map[string]*project.ModelInfo{
+		"gold.revenue":     {Path: "gold.revenue", Name: "revenue", FilePath: "/models/gold/revenue.sql"},
+		"gold.rpt_sales":   {Path: "gold.rpt_sales", Name: "rpt_sales", FilePath: "/models/gold/rpt_sales.sql"},
+		"gold.brz_orders":  {Path: "gold.brz_orders", Name: "brz_orders", FilePath: "/models/gold/brz_orders.sql"},
+		"staging.stg_user": {Path: "staging.stg_user", Name: "stg_user", FilePath: "/models/staging/stg_user.sql"},
+	}
+	project.InferAndSetTypes(models, layers)
+
+	cfg := lint.DefaultProjectHealthConfig()
+	cfg.Layers = layers
+	ctx := project.NewContext(models, nil, nil, cfg)
+
+	naming := checkModelNaming(ctx)
+	if assert.Len(t, naming, 1) {
+		assert.Equal(t, "Model 'revenue' is in gold directory but doesn't have 'gld_' or 'rpt_' prefix", naming[0].Message)
+	}
+
+	directory := checkModelDirectory(ctx)
+	if assert.Len(t, directory, 1) {
+		assert.Equal(t, "Model 'brz_orders' has 'brz_' prefix but is not in bronze directory", directory[0].Message)
+	}
+}
+
+func TestPS03_SeedNaming(t *testing.T) {
+	models := map[string]*project.ModelInfo{
+		"staging.stg_countries": {
+			Path:    "staging.stg_countries",
+			Name:    "stg_countries",
+			Type:    core.ModelTypeStaging,
+			Sources: []string{"main.Country Codes"},
+		},
+	}
+	ctx := project.NewContext(models, nil, nil, lint.DefaultProjectHealthConfig())
+	ctx.SetSeeds(map[string]string{
+		"Country Codes":   "/seeds/Country Codes.csv",
+		"payment-methods": "/seeds/payment-methods.csv",
+		"raw_orders":      "/seeds/raw_orders.csv",
+	})
+
+	diags := checkSeedNaming(ctx)
+	if assert.Len(t, diags, 2, "seeds read with a schema are reported once") {
+		assert.Equal(t, "Seed 'Country Codes' is not named in lowercase snake_case", diags[0].Message)
+		assert.Equal(t, "/seeds/Country Codes.csv", diags[0].FilePath)
+		assert.Equal(t, "payment-methods", diags[1].Model)
+	}
+}
+
+func TestPS04_RelationCollision(t *testing.T) {
+	models := map[string]*project.ModelInfo{
+		"staging.stg_orders": {Path: "staging.stg_orders", Name: "stg_orders", FilePath: "/models/staging/stg_orders.sql", Relation: "analytics.stg_orders"},
+		"marts.stg_orders":   {Path: "marts.stg_orders", Name: "stg_orders", FilePath: "/models/marts/stg_orders.sql", Relation: "Analytics.stg_orders"},
+		"marts.fct_orders":   {Path: "marts.fct_orders", Name: "fct_orders", FilePath: "/models/marts/fct_orders.sql", Relation: "analytics.fct_orders"},
+		"marts.unknown":      {Path: "marts.unknown", Name: "unknown", FilePath: "/models/marts/unknown.sql"},
+	}
+	ctx := project.NewContext(models, nil, nil, lint.DefaultProjectHealthConfig())
+
+	diags := checkRelationCollision(ctx)
+	if assert.Len(t, diags, 2) {
+		assert.Equal(t, "marts.stg_orders", diags[0].Model)
+		assert.Equal(t, "model 'marts.stg_orders' is built as Analytics.stg_orders, the same table as staging.stg_orders", diags[0].Message)
+		assert.Equal(t, "staging.stg_orders", diags[1].Model)
+		assert.Equal(t, core.SeverityError, diags[1].Severity)
+	}
+}
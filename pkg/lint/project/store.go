@@ -0,0 +1,99 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+)
+
+// ErrNoModels is returned by NewContextFromStore when the store holds no models.
+var ErrNoModels = errors.New("state store has no models")
+
+// NewContextFromStore builds a project context from the models, column lineage
+// and dependencies recorded in the state store by discovery, without parsing
+// any model files. The store also backs schema drift detection (PL05).
+// Uses batch queries, so building the context costs a handful of queries
+// regardless of project size.
+func NewContextFromStore(store core.Store, config lint.ProjectHealthConfig) (*Context, error) {
+	storeModels, err := store.ListModels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	if len(storeModels) == 0 {
+		return nil, ErrNoModels
+	}
+
+	// Dependencies are recorded by model ID
+	modelIDToPath := make(map[string]string, len(storeModels))
+	for _, m := range storeModels {
+		modelIDToPath[m.ID] = m.Path
+	}
+
+	// Fall back to per-model column queries if the batch query fails
+	allColumns, err := store.BatchGetAllColumns()
+	if err != nil {
+		allColumns = nil
+	}
+
+	allDeps, err := store.BatchGetAllDependencies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependencies: %w", err)
+	}
+
+	allDependents, err := store.BatchGetAllDependents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependents: %w", err)
+	}
+
+	models := make(map[string]*ModelInfo, len(storeModels))
+	parents := make(map[string][]string, len(storeModels))
+	children := make(map[string][]string, len(storeModels))
+
+	for _, m := range storeModels {
+		var columns []core.ColumnInfo
+		if allColumns != nil {
+			columns = allColumns[m.Path]
+		} else {
+			columns, _ = store.GetModelColumns(m.Path)
+		}
+
+		projectName, _ := core.SplitModelID(m.Path)
+		models[m.Path] = &ModelInfo{
+			Path:           m.Path,
+			Project:        projectName,
+			Name:           m.Name,
+			FilePath:       m.FilePath,
+			Sources:        m.Sources,
+			Columns:        columns,
+			Materialized:   m.Materialized,
+			Tags:           m.Tags,
+			Meta:           m.Meta,
+			Version:        m.Version,
+			Deprecated:     m.Deprecated,
+			Access:         m.Access,
+			Owner:          m.Owner,
+			Group:          m.Group,
+			PII:            m.PII,
+			UsesSelectStar: m.UsesSelectStar,
+		}
+		parents[m.Path] = resolveModelIDs(allDeps[m.ID], modelIDToPath)
+		children[m.Path] = resolveModelIDs(allDependents[m.ID], modelIDToPath)
+	}
+
+	InferAndSetTypes(models, config.Layers)
+
+	return NewContextWithStore(models, parents, children, config, store), nil
+}
+
+// resolveModelIDs converts model IDs to paths, dropping unknown IDs.
+func resolveModelIDs(ids []string, idToPath map[string]string) []string {
+	var paths []string
+	for _, id := range ids {
+		if path, ok := idToPath[id]; ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,91 @@
+package project
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore serves the queries NewContextFromStore makes; other methods of
+// core.Store are left unimplemented.
+type fakeStore struct {
+	core.Store
+	models     []*core.PersistedModel
+	columns    map[string][]core.ColumnInfo
+	columnsErr error
+	deps       map[string][]string
+	dependents map[string][]string
+}
+
+func (s *fakeStore) ListModels() ([]*core.PersistedModel, error) { return s.models, nil }
+
+func (s *fakeStore) BatchGetAllColumns() (map[string][]core.ColumnInfo, error) {
+	return s.columns, s.columnsErr
+}
+
+func (s *fakeStore) GetModelColumns(modelPath string) ([]core.ColumnInfo, error) {
+	return s.columns[modelPath], nil
+}
+
+func (s *fakeStore) BatchGetAllDependencies() (map[string][]string, error) { return s.deps, nil }
+
+func (s *fakeStore) BatchGetAllDependents() (map[string][]string, error) { return s.dependents, nil }
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		models: []*core.PersistedModel{
+			{ID: "m1", Model: &core.Model{
+				Path: "staging.stg_orders", Name: "stg_orders", Materialized: "view",
+				Sources: []string{"raw.orders"}, PII: []string{"email"},
+			}},
+			{ID: "m2", Model: &core.Model{
+				Path: "marts.fct_orders", Name: "fct_orders", Materialized: "table",
+				Sources: []string{"staging.stg_orders"}, Owner: "finance",
+			}},
+		},
+		columns: map[string][]core.ColumnInfo{
+			"staging.stg_orders": {{Name: "email", Index: 0}},
+		},
+		deps:       map[string][]string{"m2": {"m1", "unknown"}},
+		dependents: map[string][]string{"m1": {"m2"}},
+	}
+}
+
+func TestNewContextFromStore(t *testing.T) {
+	store := newFakeStore()
+	ctx, err := NewContextFromStore(store, lint.DefaultProjectHealthConfig())
+	require.NoError(t, err)
+
+	models := ctx.GetModels()
+	require.Len(t, models, 2)
+
+	stg := models["staging.stg_orders"]
+	assert.Equal(t, []string{"raw.orders"}, stg.Sources)
+	assert.Equal(t, []string{"email"}, stg.PII)
+	assert.Equal(t, core.ModelTypeStaging, stg.Type)
+	require.Len(t, stg.Columns, 1)
+	assert.Equal(t, "email", stg.Columns[0].Name)
+
+	assert.Equal(t, "finance", models["marts.fct_orders"].Owner)
+	assert.Equal(t, []string{"staging.stg_orders"}, ctx.GetParents("marts.fct_orders"), "unknown IDs are dropped")
+	assert.Equal(t, []string{"marts.fct_orders"}, ctx.GetChildren("staging.stg_orders"))
+	assert.Same(t, store, ctx.Store())
+}
+
+func TestNewContextFromStore_ColumnsFallback(t *testing.T) {
+	store := newFakeStore()
+	store.columnsErr = errors.New("batch query failed")
+
+	ctx, err := NewContextFromStore(store, lint.DefaultProjectHealthConfig())
+	require.NoError(t, err)
+	assert.Len(t, ctx.GetModels()["staging.stg_orders"].Columns, 1)
+}
+
+func TestNewContextFromStore_NoModels(t *testing.T) {
+	_, err := NewContextFromStore(&fakeStore{}, lint.DefaultProjectHealthConfig())
+	assert.ErrorIs(t, err, ErrNoModels)
+}
@@ -1,6 +1,9 @@
 package project
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/lint"
 )
@@ -13,7 +16,12 @@ type Context struct {
 	parents  map[string][]string   // model -> upstream models
 	children map[string][]string   // model -> downstream models
 	config   lint.ProjectHealthConfig
-	store    SnapshotStore // optional: for schema drift detection
+	store    SnapshotStore     // optional: for schema drift detection
+	seeds    map[string]string // seed table -> CSV file
+
+	// checked limits the models scoped rules report on during incremental
+	// analysis; nil means all models
+	checked map[string]*ModelInfo
 }
 
 // SnapshotStore provides access to column snapshots for schema drift detection.
@@ -22,19 +30,51 @@ type SnapshotStore interface {
 	GetColumnSnapshot(modelPath string, sourceTable string) (columns []string, runID string, err error)
 }
 
+// PlanStore provides access to the query plans captured for models, for plan
+// regression detection. Snapshot stores may implement it.
+type PlanStore interface {
+	GetModelPlans(modelPath string, limit int) ([]*core.ModelPlan, error)
+}
+
 // ModelInfo holds all metadata about a model for project-level analysis.
 // This is a richer representation than lint.ModelInfo, with computed fields.
 type ModelInfo struct {
 	Path           string            // e.g., "staging.customers"
+	Project        string            // Workspace project (empty outside a workspace)
 	Name           string            // e.g., "stg_customers"
 	FilePath       string            // Absolute path to .sql file
+	Relation       string            // Table the model is built as, e.g. "analytics.customers" (empty if unknown)
 	Type           core.ModelType    // Inferred or explicit model type
 	Sources        []string          // Table references (deps)
 	Columns        []core.ColumnInfo // Column-level lineage
 	Materialized   string            // table, view, incremental
 	Tags           []string
 	Meta           map[string]any
-	UsesSelectStar bool // true if model uses SELECT * or t.*
+	Version        int               // Model version (0 if unversioned)
+	Deprecated     *core.Deprecation // Deprecation notice (nil if not deprecated)
+	Access         core.Access       // Access level (empty means protected)
+	Owner          string            // Owner from frontmatter
+	Group          string            // Owning group from frontmatter
+	PII            []string          // PII columns from frontmatter
+	UsesSelectStar bool              // true if model uses SELECT * or t.*
+}
+
+// SourceKind tells apart the tables models read that are not models.
+type SourceKind string
+
+// Source kinds.
+const (
+	SourceKindSeed     SourceKind = "seed"   // Loaded from a CSV file in the seeds directory
+	SourceKindExternal SourceKind = "source" // Raw table loaded outside the project
+)
+
+// SourceInfo describes a table that models read but that is not a model:
+// a seed or an external source.
+type SourceInfo struct {
+	Name      string     // Table name as models reference it, e.g. "raw_orders"
+	Kind      SourceKind // Seed or external source
+	FilePath  string     // CSV file of a seed (empty for external sources)
+	Consumers []string   // Paths of the models that read it, sorted
 }
 
 // NewContext creates a new project context for analysis.
@@ -65,12 +105,20 @@ func (c *Context) Store() SnapshotStore {
 	return c.store
 }
 
+// PlanStore returns the store's query plans, if the store captures them.
+// This is used by PL07 for plan regression detection.
+func (c *Context) PlanStore() PlanStore {
+	plans, _ := c.store.(PlanStore)
+	return plans
+}
+
 // GetModels implements lint.ProjectContext.
 func (c *Context) GetModels() map[string]lint.ModelInfo {
 	result := make(map[string]lint.ModelInfo, len(c.models))
 	for path, m := range c.models {
 		result[path] = lint.ModelInfo{
 			Path:         m.Path,
+			Project:      m.Project,
 			Name:         m.Name,
 			FilePath:     m.FilePath,
 			Type:         m.Type,
@@ -79,11 +127,30 @@ func (c *Context) GetModels() map[string]lint.ModelInfo {
 			Materialized: m.Materialized,
 			Tags:         m.Tags,
 			Meta:         m.Meta,
+			Version:      m.Version,
+			Deprecated:   m.Deprecated,
+			Access:       m.Access,
+			Owner:        m.Owner,
+			Group:        m.Group,
+			PII:          m.PII,
 		}
 	}
 	return result
 }
 
+// Layers returns the model layers models are classified into.
+func (c *Context) Layers() []core.LayerConfig {
+	if c.config.Layers == nil {
+		return core.DefaultLayers()
+	}
+	return c.config.Layers
+}
+
+// Layer returns the layer of a model, and false for models in no layer.
+func (c *Context) Layer(model *ModelInfo) (core.LayerConfig, bool) {
+	return core.FindLayer(c.Layers(), model.Type)
+}
+
 // GetParents implements lint.ProjectContext.
 func (c *Context) GetParents(modelPath string) []string {
 	return c.parents[modelPath]
@@ -110,6 +177,101 @@ func (c *Context) Models() map[string]*ModelInfo {
 	return c.models
 }
 
+// CheckedModels returns the models whose diagnostics are being computed: all
+// models, or during incremental analysis the models a change may affect.
+// Rules with a ScopeModel or ScopeNeighbors scope iterate these instead of
+// Models, and use the other accessors to look at the rest of the project.
+func (c *Context) CheckedModels() map[string]*ModelInfo {
+	if c.checked == nil {
+		return c.models
+	}
+	return c.checked
+}
+
+// focus returns a copy of the context whose CheckedModels are the given
+// paths that are models of the project.
+func (c *Context) focus(paths map[string]bool) *Context {
+	focused := *c
+	focused.checked = make(map[string]*ModelInfo, len(paths))
+	for path := range paths {
+		if m, ok := c.models[path]; ok {
+			focused.checked[path] = m
+		}
+	}
+	return &focused
+}
+
+// SetSeeds registers the project's seeds, keyed by table name with their CSV
+// files, so Sources can tell seeds from external sources.
+func (c *Context) SetSeeds(seeds map[string]string) {
+	c.seeds = seeds
+}
+
+// Sources returns the seeds and external sources of the project, keyed by
+// table name: every table a model reads that is not a model, and every seed.
+func (c *Context) Sources() map[string]*SourceInfo {
+	sources := make(map[string]*SourceInfo)
+	for name, file := range c.seeds {
+		sources[name] = &SourceInfo{Name: name, Kind: SourceKindSeed, FilePath: file}
+	}
+
+	for _, m := range c.models {
+		for _, name := range m.Sources {
+			if c.IsModel(name) {
+				continue
+			}
+			src, ok := sources[name]
+			if !ok {
+				src = &SourceInfo{Name: name, Kind: SourceKindExternal}
+				// Seeds read with a schema are also listed under their qualified name
+				if seed, file, ok := c.seedFor(name); ok {
+					if src, ok = sources[seed]; !ok {
+						src = &SourceInfo{Name: seed, Kind: SourceKindSeed, FilePath: file}
+					}
+				}
+				sources[name] = src
+			}
+			src.Consumers = append(src.Consumers, m.Path)
+		}
+	}
+
+	for _, src := range sources {
+		slices.Sort(src.Consumers)
+		src.Consumers = slices.Compact(src.Consumers)
+	}
+	return sources
+}
+
+// Source returns the seed or external source a model reads as name. It
+// reports false if name is a model.
+func (c *Context) Source(name string) (*SourceInfo, bool) {
+	if c.IsModel(name) {
+		return nil, false
+	}
+	src, ok := c.Sources()[name]
+	return src, ok
+}
+
+// IsSeed reports whether a table name models read refers to a seed.
+func (c *Context) IsSeed(name string) bool {
+	_, _, ok := c.seedFor(name)
+	return ok && !c.IsModel(name)
+}
+
+// seedFor returns the seed a table name refers to, matching schema-qualified
+// names such as "main.raw_orders" on their last part.
+func (c *Context) seedFor(name string) (string, string, bool) {
+	if file, ok := c.seeds[name]; ok {
+		return name, file, true
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		if file, ok := c.seeds[name[i+1:]]; ok {
+			return name[i+1:], file, true
+		}
+	}
+	return "", "", false
+}
+
 // IsModel checks if a given table name is a known model.
 func (c *Context) IsModel(name string) bool {
 	_, ok := c.models[name]
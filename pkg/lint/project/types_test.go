@@ -0,0 +1,52 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext_Sources(t *testing.T) {
+	models := map[string]*ModelInfo{
+		"staging.stg_orders": {Path: "staging.stg_orders", Name: "stg_orders", Sources: []string{"raw.orders"}},
+		"staging.stg_countries": {
+			Path: "staging.stg_countries", Name: "stg_countries", Sources: []string{"main.countries"},
+		},
+		"marts.fct_orders": {
+			Path: "marts.fct_orders", Name: "fct_orders",
+			Sources: []string{"staging.stg_orders", "countries", "raw.orders"},
+		},
+	}
+	ctx := NewContext(models, nil, nil, lint.DefaultProjectHealthConfig())
+	ctx.SetSeeds(map[string]string{
+		"countries":  "/seeds/countries.csv",
+		"currencies": "/seeds/currencies.csv",
+	})
+
+	sources := ctx.Sources()
+	assert.Len(t, sources, 4, "raw.orders, countries under both names and currencies")
+
+	orders := sources["raw.orders"]
+	require.NotNil(t, orders)
+	assert.Equal(t, SourceKindExternal, orders.Kind)
+	assert.Equal(t, []string{"marts.fct_orders", "staging.stg_orders"}, orders.Consumers)
+
+	countries := sources["countries"]
+	require.NotNil(t, countries)
+	assert.Equal(t, SourceKindSeed, countries.Kind)
+	assert.Equal(t, "/seeds/countries.csv", countries.FilePath)
+	assert.Equal(t, []string{"marts.fct_orders", "staging.stg_countries"}, countries.Consumers)
+	assert.Same(t, countries, sources["main.countries"])
+
+	currencies := sources["currencies"]
+	require.NotNil(t, currencies)
+	assert.Empty(t, currencies.Consumers, "unreferenced seeds are listed")
+
+	assert.True(t, ctx.IsSeed("main.countries"))
+	assert.False(t, ctx.IsSeed("raw.orders"))
+
+	_, ok := ctx.Source("staging.stg_orders")
+	assert.False(t, ok, "models are not sources")
+}
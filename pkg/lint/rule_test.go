@@ -15,6 +15,7 @@ type mockSQLRule struct {
 	group       string
 	description string
 	severity    core.Severity
+	options     []core.RuleOption
 	configKeys  []string
 	dialects    []string
 }
@@ -24,6 +25,7 @@ func (m *mockSQLRule) Name() string                   { return m.name }
 func (m *mockSQLRule) Group() string                  { return m.group }
 func (m *mockSQLRule) Description() string            { return m.description }
 func (m *mockSQLRule) DefaultSeverity() core.Severity { return m.severity }
+func (m *mockSQLRule) Options() []core.RuleOption     { return m.options }
 func (m *mockSQLRule) ConfigKeys() []string           { return m.configKeys }
 func (m *mockSQLRule) Dialects() []string             { return m.dialects }
 
@@ -44,6 +46,7 @@ type mockProjectRule struct {
 	group       string
 	description string
 	severity    core.Severity
+	options     []core.RuleOption
 	configKeys  []string
 }
 
@@ -52,6 +55,7 @@ func (m *mockProjectRule) Name() string                   { return m.name }
 func (m *mockProjectRule) Group() string                  { return m.group }
 func (m *mockProjectRule) Description() string            { return m.description }
 func (m *mockProjectRule) DefaultSeverity() core.Severity { return m.severity }
+func (m *mockProjectRule) Options() []core.RuleOption     { return m.options }
 func (m *mockProjectRule) ConfigKeys() []string           { return m.configKeys }
 
 // Documentation methods (return empty for mocks)
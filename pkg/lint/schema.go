@@ -0,0 +1,101 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+// Schema maps table names to their columns, such as the output columns of
+// the upstream models a model reads. Keys are table names as statements
+// reference them, e.g. "staging.customers".
+type Schema map[string][]string
+
+// Columns returns the columns of the table with the given name. Names are
+// matched exactly first, then case-insensitively.
+func (s Schema) Columns(name string) ([]string, bool) {
+	if cols, ok := s[name]; ok {
+		return cols, true
+	}
+	for table, cols := range s {
+		if strings.EqualFold(table, name) {
+			return cols, true
+		}
+	}
+	return nil, false
+}
+
+// contextDialect is a DialectInfo that carries context to SQL rules: the
+// schema of the tables a statement reads and the source it was parsed from.
+type contextDialect struct {
+	DialectInfo
+	schema Schema
+	src    string
+	tokens []token.Token
+}
+
+// withContext returns a copy of the context attached to dialect, or a new
+// one wrapping it.
+func withContext(dialect DialectInfo) *contextDialect {
+	if cd, ok := dialect.(*contextDialect); ok {
+		copied := *cd
+		return &copied
+	}
+	return &contextDialect{DialectInfo: dialect}
+}
+
+// WithSchema returns dialect with schema attached. Rules receive it as their
+// dialect and read the schema with SchemaOf, so rules that resolve column
+// references can look up the columns of the tables a statement reads.
+func WithSchema(dialect DialectInfo, schema Schema) DialectInfo {
+	if len(schema) == 0 {
+		return dialect
+	}
+	cd := withContext(dialect)
+	cd.schema = schema
+	return cd
+}
+
+// SchemaOf returns the schema attached to dialect with WithSchema, or nil.
+func SchemaOf(dialect DialectInfo) Schema {
+	if cd, ok := dialect.(*contextDialect); ok {
+		return cd.schema
+	}
+	return nil
+}
+
+// WithSource returns dialect with the source of a statement and its tokens
+// attached. Rules receive it as their dialect and read them with SourceOf,
+// so rules can check what the statement does not keep, such as the casing
+// of keywords and which identifiers were quoted.
+func WithSource(dialect DialectInfo, src string, tokens []token.Token) DialectInfo {
+	cd := withContext(dialect)
+	cd.src, cd.tokens = src, tokens
+	return cd
+}
+
+// SourceOf returns the source and tokens attached to dialect with
+// WithSource. There are no tokens if none were attached.
+func SourceOf(dialect DialectInfo) (string, []token.Token) {
+	if cd, ok := dialect.(*contextDialect); ok {
+		return cd.src, cd.tokens
+	}
+	return "", nil
+}
+
+// FunctionDocOf returns the documentation dialect has for a function, such as
+// its return type, looking through the context attached with WithSchema and
+// WithSource.
+func FunctionDocOf(dialect DialectInfo, name string) (core.FunctionDoc, bool) {
+	if cd, ok := dialect.(*contextDialect); ok {
+		dialect = cd.DialectInfo
+	}
+	docs, ok := dialect.(interface {
+		GetDoc(name string) (core.FunctionDoc, bool)
+	})
+	if !ok {
+		return core.FunctionDoc{}, false
+	}
+	return docs.GetDoc(name)
+}
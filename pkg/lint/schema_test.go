@@ -0,0 +1,52 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	duckdbdialect "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+)
+
+func TestSchema_Columns(t *testing.T) {
+	schema := lint.Schema{"staging.Customers": {"id", "name"}}
+
+	cols, ok := schema.Columns("staging.Customers")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"id", "name"}, cols)
+
+	cols, ok = schema.Columns("STAGING.customers")
+	assert.True(t, ok, "names match case-insensitively")
+	assert.Equal(t, []string{"id", "name"}, cols)
+
+	_, ok = schema.Columns("staging.orders")
+	assert.False(t, ok)
+}
+
+func TestWithSchema(t *testing.T) {
+	d := duckdbdialect.DuckDB
+	schema := lint.Schema{"orders": {"id"}}
+
+	wrapped := lint.WithSchema(d, schema)
+	assert.Equal(t, "duckdb", wrapped.GetName(), "the dialect is still available")
+	assert.Equal(t, schema, lint.SchemaOf(wrapped))
+
+	assert.Nil(t, lint.SchemaOf(d))
+	assert.Same(t, d, lint.WithSchema(d, nil), "an empty schema leaves the dialect as is")
+}
+
+func TestFunctionDocOf(t *testing.T) {
+	d := duckdbdialect.DuckDB
+
+	doc, ok := lint.FunctionDocOf(d, "lower")
+	assert.True(t, ok)
+	assert.Equal(t, "VARCHAR", doc.ReturnType)
+
+	doc, ok = lint.FunctionDocOf(lint.WithSchema(d, lint.Schema{"orders": {"id"}}), "LOWER")
+	assert.True(t, ok, "the dialect is looked up through the schema")
+	assert.Equal(t, "VARCHAR", doc.ReturnType)
+
+	_, ok = lint.FunctionDocOf(d, "my_udf")
+	assert.False(t, ok)
+}
@@ -1,13 +1,26 @@
 package sql
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/dialect"
 	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/parser"
+	"github.com/leapstack-labs/leapsql/pkg/token"
 )
 
+// ParseErrorRuleID is the rule ID of the diagnostics AnalyzeSource reports
+// for syntax errors.
+const ParseErrorRuleID = "PRS"
+
 // Analyzer runs SQL lint rules against parsed statements.
 type Analyzer struct {
 	config  *lint.Config
-	dialect string // Filter rules by dialect (empty = all)
+	dialect string      // Filter rules by dialect (empty = all)
+	schema  lint.Schema // Columns of the tables statements read (optional)
 }
 
 // NewAnalyzer creates a new SQL analyzer with optional configuration.
@@ -21,14 +34,38 @@ func NewAnalyzer(config *lint.Config, dialect string) *Analyzer {
 	}
 }
 
+// SetSchema sets the columns of the tables analyzed statements read, such as
+// the output columns of upstream models. Rules that resolve column
+// references, like RF02 and AM06, use it to tell which table a column
+// comes from.
+func (a *Analyzer) SetSchema(schema lint.Schema) {
+	a.schema = schema
+}
+
 // Analyze runs all registered SQL rules against the statement.
 // The stmt parameter should be *core.SelectStmt.
 func (a *Analyzer) Analyze(stmt any, dialect lint.DialectInfo) []lint.Diagnostic {
+	return a.AnalyzeModel(stmt, dialect, nil)
+}
+
+// AnalyzeModel runs all registered SQL rules against the statement of a
+// model, applying the config's model overrides that match it.
+func (a *Analyzer) AnalyzeModel(stmt any, dialect lint.DialectInfo, model *lint.ModelInfo) []lint.Diagnostic {
 	if stmt == nil {
 		return nil
 	}
+	return a.run(a.enabledRules(dialect), stmt, dialect, model)
+}
 
-	var diagnostics []lint.Diagnostic
+// ruleRun is an enabled rule with its options, looked up once per analysis.
+type ruleRun struct {
+	rule lint.SQLRule
+	opts map[string]any
+}
+
+// enabledRules returns the registered rules that are enabled and apply to
+// the analyzer's dialect, or the given dialect if it has none.
+func (a *Analyzer) enabledRules(dialect lint.DialectInfo) []ruleRun {
 	dialectName := a.dialect
 	if dialectName == "" && dialect != nil {
 		dialectName = dialect.GetName()
@@ -41,6 +78,7 @@ func (a *Analyzer) Analyze(stmt any, dialect lint.DialectInfo) []lint.Diagnostic
 		rules = lint.GetAllSQLRules()
 	}
 
+	var runs []ruleRun
 	for _, rule := range rules {
 		// Skip disabled rules
 		if a.config.IsDisabled(rule.ID()) {
@@ -53,23 +91,145 @@ func (a *Analyzer) Analyze(stmt any, dialect lint.DialectInfo) []lint.Diagnostic
 			continue
 		}
 
-		// Get rule-specific options
-		opts := a.config.GetRuleOptions(rule.ID())
+		runs = append(runs, ruleRun{rule: rule, opts: a.config.GetRuleOptions(rule.ID())})
+	}
+	return runs
+}
 
-		// Run the rule with options
-		diags := rule.CheckSQL(stmt, dialect, opts)
+// run runs rules against a statement, applying severity overrides.
+func (a *Analyzer) run(rules []ruleRun, stmt any, dialect lint.DialectInfo, model *lint.ModelInfo) []lint.Diagnostic {
+	if a.schema != nil && dialect != nil {
+		dialect = lint.WithSchema(dialect, a.schema)
+	}
+
+	var diagnostics []lint.Diagnostic
+	for _, r := range rules {
+		diags := r.rule.CheckSQL(stmt, dialect, r.opts)
 
 		// Apply severity overrides
 		for i := range diags {
-			diags[i].Severity = a.config.GetSeverity(rule.ID(), diags[i].Severity)
+			diags[i].Severity = a.config.GetModelSeverity(r.rule.ID(), diags[i].Severity, model)
 		}
 
 		diagnostics = append(diagnostics, diags...)
 	}
 
+	lint.SortDiagnostics(diagnostics)
+	return diagnostics
+}
+
+// AnalyzeSource parses sql with the analyzer's dialect and runs all
+// registered SQL rules against it. Parsing is error-tolerant: syntax errors
+// are reported as PRS diagnostics, and the rules still check the parts of
+// the statement that parsed. Every diagnostic has a line and column. It fails
+// if the analyzer has no dialect or the dialect is not registered.
+func (a *Analyzer) AnalyzeSource(sql string) ([]lint.Diagnostic, error) {
+	if a.dialect == "" {
+		return nil, fmt.Errorf("analyzer has no dialect")
+	}
+	d, ok := dialect.Get(a.dialect)
+	if !ok {
+		return nil, fmt.Errorf("unknown dialect %q", a.dialect)
+	}
+
+	if strings.TrimSpace(sql) == "" {
+		return nil, nil
+	}
+
+	stmt, syntaxErrs := parseTolerant(sql, d)
+	return a.analyzeStatement(a.enabledRules(d), sql, stmt, syntaxErrs, d, nil), nil
+}
+
+// parseTolerant parses src in dialect d without stopping at syntax errors,
+// returning the statement parsed despite them along with every error.
+func parseTolerant(src string, d *core.Dialect) (*core.SelectStmt, []core.SyntaxError) {
+	stmt, errs := parser.ParseTolerant(src, d)
+	syntaxErrs := make([]core.SyntaxError, 0, len(errs))
+	for _, err := range errs {
+		var pe *parser.ParseError
+		if errors.As(err, &pe) {
+			syntaxErrs = append(syntaxErrs, core.SyntaxError{Pos: pe.Pos, Message: pe.Message})
+		} else {
+			syntaxErrs = append(syntaxErrs, core.SyntaxError{Message: err.Error()})
+		}
+	}
+	return stmt, syntaxErrs
+}
+
+// analyzeStatement runs rules against a statement parsed from src, reporting
+// its syntax errors as PRS diagnostics. Every diagnostic gets a line and
+// column.
+func (a *Analyzer) analyzeStatement(rules []ruleRun, src string, stmt *core.SelectStmt, syntaxErrs []core.SyntaxError, d *core.Dialect, model *lint.ModelInfo) []lint.Diagnostic {
+	var diagnostics []lint.Diagnostic
+	for _, e := range syntaxErrs {
+		diagnostics = append(diagnostics, lint.Diagnostic{
+			RuleID:   ParseErrorRuleID,
+			Severity: core.SeverityError,
+			Message:  e.Message,
+			Pos:      e.Pos,
+		})
+	}
+	diagnostics = append(diagnostics, a.analyzePartial(rules, stmt, withSource(d, src), model, len(syntaxErrs) > 0)...)
+
+	lines := lineOffsets(src)
+	for i := range diagnostics {
+		diagnostics[i].Pos = resolvePosition(diagnostics[i].Pos, lines)
+		diagnostics[i].EndPos = resolvePosition(diagnostics[i].EndPos, lines)
+	}
+
+	lint.SortDiagnostics(diagnostics)
 	return diagnostics
 }
 
+// withSource returns d with src and its tokens attached, for the rules that
+// check the source.
+func withSource(d *core.Dialect, src string) lint.DialectInfo {
+	return lint.WithSource(d, src, parser.TokenizeWithDialect(src, d))
+}
+
+// analyzePartial runs rules against a statement that may be incomplete.
+// Rules are written for complete statements, so if one fails on a statement
+// with syntax errors, only the syntax errors are reported.
+func (a *Analyzer) analyzePartial(rules []ruleRun, stmt *core.SelectStmt, d lint.DialectInfo, model *lint.ModelInfo, partial bool) (diags []lint.Diagnostic) {
+	if stmt == nil {
+		return nil
+	}
+	if partial {
+		defer func() {
+			if recover() != nil {
+				diags = nil
+			}
+		}()
+	}
+	return a.run(rules, stmt, d, model)
+}
+
+// lineOffsets returns the byte offset each line of src starts at.
+func lineOffsets(src string) []int {
+	offsets := []int{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+// resolvePosition fills in the line and column of a position that only has
+// a byte offset.
+func resolvePosition(pos token.Position, lines []int) token.Position {
+	if pos.Line > 0 || pos.Offset <= 0 {
+		return pos
+	}
+	line := 0
+	for line+1 < len(lines) && lines[line+1] <= pos.Offset {
+		line++
+	}
+	pos.Line = line + 1
+	pos.Column = pos.Offset - lines[line] + 1
+	return pos
+}
+
 // AnalyzeMultiple runs analysis on multiple statements.
 func (a *Analyzer) AnalyzeMultiple(stmts []any, dialect lint.DialectInfo) []lint.Diagnostic {
 	var diagnostics []lint.Diagnostic
@@ -0,0 +1,98 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	_ "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb" // register dialect
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerSourceRule registers a rule that reports at the given offset of
+// every statement with a FROM clause.
+func registerSourceRule(t *testing.T, check lintsql.CheckFunc) {
+	t.Helper()
+	lint.Clear()
+	lintsql.Register(lintsql.RuleDef{
+		ID:       "SRC01",
+		Name:     "test.source",
+		Group:    "test",
+		Severity: core.SeverityWarning,
+		Check:    check,
+	})
+	t.Cleanup(lint.Clear)
+}
+
+func TestAnalyzer_AnalyzeSource(t *testing.T) {
+	registerSourceRule(t, func(stmt any, _ lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
+		s, ok := stmt.(*core.SelectStmt)
+		if !ok || s.Body == nil || s.Body.Left == nil || s.Body.Left.From == nil {
+			return nil
+		}
+		// Only the offset is set; AnalyzeSource fills in line and column
+		return []lint.Diagnostic{{RuleID: "SRC01", Severity: core.SeverityWarning, Message: "from", Pos: token.Position{Offset: 12}}}
+	})
+
+	diags, err := lintsql.NewAnalyzer(nil, "duckdb").AnalyzeSource("SELECT id\nFROM orders")
+	require.NoError(t, err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, "SRC01", diags[0].RuleID)
+	assert.Equal(t, 2, diags[0].Pos.Line)
+	assert.Equal(t, 3, diags[0].Pos.Column)
+
+	diags, err = lintsql.NewAnalyzer(nil, "duckdb").AnalyzeSource("  \n")
+	require.NoError(t, err)
+	assert.Empty(t, diags)
+}
+
+func TestAnalyzer_AnalyzeSource_SyntaxErrors(t *testing.T) {
+	registerSourceRule(t, func(stmt any, _ lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
+		s := stmt.(*core.SelectStmt)
+		if s.Body.Left.From == nil {
+			return nil
+		}
+		return []lint.Diagnostic{{RuleID: "SRC01", Severity: core.SeverityWarning, Message: "from", Pos: token.Position{Line: 1, Column: 1}}}
+	})
+
+	diags, err := lintsql.NewAnalyzer(nil, "duckdb").AnalyzeSource("SELECT id,\nFROM orders WHERE")
+	require.NoError(t, err)
+	require.NotEmpty(t, diags)
+
+	var parseErrs int
+	for _, d := range diags {
+		if d.RuleID == lintsql.ParseErrorRuleID {
+			parseErrs++
+			assert.Equal(t, core.SeverityError, d.Severity)
+			assert.Positive(t, d.Pos.Line)
+			assert.NotContains(t, d.Message, "parse error at", "the position is not repeated in the message")
+		}
+	}
+	assert.Positive(t, parseErrs)
+}
+
+func TestAnalyzer_AnalyzeSource_RulePanicsOnPartialStatement(t *testing.T) {
+	registerSourceRule(t, func(_ any, _ lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
+		panic("incomplete statement")
+	})
+
+	diags, err := lintsql.NewAnalyzer(nil, "duckdb").AnalyzeSource("SELECT FROM")
+	require.NoError(t, err)
+	require.NotEmpty(t, diags)
+	for _, d := range diags {
+		assert.Equal(t, lintsql.ParseErrorRuleID, d.RuleID)
+	}
+}
+
+func TestAnalyzer_AnalyzeSource_Dialect(t *testing.T) {
+	_, err := lintsql.NewAnalyzer(nil, "").AnalyzeSource("SELECT 1")
+	require.Error(t, err)
+	assert.Equal(t, "analyzer has no dialect", err.Error())
+
+	_, err = lintsql.NewAnalyzer(nil, "oracle").AnalyzeSource("SELECT 1")
+	require.Error(t, err)
+	assert.Equal(t, `unknown dialect "oracle"`, err.Error())
+}
@@ -0,0 +1,78 @@
+package sql
+
+import (
+	"context"
+	"runtime"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+)
+
+// Source is one SQL text analyzed by AnalyzeProject, such as a model's
+// rendered SQL.
+type Source struct {
+	Path  string          // Identifies the source in the results, e.g. the model path
+	SQL   string          // SQL text to analyze
+	Model *lint.ModelInfo // Model the SQL belongs to, for model overrides (optional)
+}
+
+// SourceResult holds the diagnostics of one source.
+type SourceResult struct {
+	Path        string
+	Diagnostics []lint.Diagnostic
+
+	// SyntaxErrors reports whether the source failed to parse; its syntax
+	// errors are among the diagnostics as PRS diagnostics
+	SyntaxErrors bool
+}
+
+// AnalyzeProject analyzes many sources in dialect d, like AnalyzeSource on
+// each. The enabled rules and their options are looked up once for the
+// batch, and sources with the same SQL are parsed once. Sources are analyzed
+// in parallel; the results are in the order of sources. It fails if ctx is
+// canceled or d is nil.
+func (a *Analyzer) AnalyzeProject(ctx context.Context, sources []Source, d *core.Dialect) ([]SourceResult, error) {
+	if d == nil {
+		return nil, core.ErrDialectRequired
+	}
+	rules := a.enabledRules(d)
+	results := make([]SourceResult, len(sources))
+
+	// Sources with the same SQL share a parsed statement, so they are
+	// analyzed by the same worker: rules never see a statement concurrently
+	groups := make(map[string][]int)
+	var order []string
+	for i, src := range sources {
+		results[i].Path = src.Path
+		if strings.TrimSpace(src.SQL) == "" {
+			continue
+		}
+		if _, ok := groups[src.SQL]; !ok {
+			order = append(order, src.SQL)
+		}
+		groups[src.SQL] = append(groups[src.SQL], i)
+	}
+
+	eg, egctx := errgroup.WithContext(ctx)
+	eg.SetLimit(runtime.GOMAXPROCS(0))
+	for _, sql := range order {
+		eg.Go(func() error {
+			if err := egctx.Err(); err != nil {
+				return err
+			}
+			stmt, syntaxErrs := parseTolerant(sql, d)
+			for _, i := range groups[sql] {
+				results[i].Diagnostics = a.analyzeStatement(rules, sql, stmt, syntaxErrs, d, sources[i].Model)
+				results[i].SyntaxErrors = len(syntaxErrs) > 0
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,86 @@
+package sql_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	duckdbdialect "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzer_AnalyzeProject(t *testing.T) {
+	var checked atomic.Int32
+	registerSourceRule(t, func(stmt any, _ lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
+		checked.Add(1)
+		s := stmt.(*core.SelectStmt)
+		if !s.Body.Left.Distinct {
+			return nil
+		}
+		return []lint.Diagnostic{{RuleID: "SRC01", Severity: core.SeverityWarning, Message: "distinct", Pos: token.Position{Line: 1, Column: 1}}}
+	})
+
+	cfg := lint.NewConfig()
+	cfg.ModelOverrides = []lint.ModelOverride{{Selectors: []string{"tag:tier1"}, WarningsAsErrors: true}}
+
+	sources := []lintsql.Source{
+		{Path: "staging.orders", SQL: "SELECT DISTINCT id FROM orders"},
+		{Path: "staging.customers", SQL: "SELECT id FROM customers"},
+		{Path: "marts.orders", SQL: "SELECT DISTINCT id FROM orders", Model: &lint.ModelInfo{Path: "marts.orders", Tags: []string{"tier1"}}},
+		{Path: "broken", SQL: "SELECT FROM"},
+		{Path: "empty", SQL: ""},
+	}
+	results, err := lintsql.NewAnalyzer(cfg, "duckdb").AnalyzeProject(context.Background(), sources, duckdbdialect.DuckDB)
+	require.NoError(t, err)
+	require.Len(t, results, len(sources))
+
+	var paths []string
+	for _, res := range results {
+		paths = append(paths, res.Path)
+	}
+	assert.Equal(t, []string{"staging.orders", "staging.customers", "marts.orders", "broken", "empty"}, paths, "results are in source order")
+
+	require.Len(t, results[0].Diagnostics, 1)
+	assert.Equal(t, core.SeverityWarning, results[0].Diagnostics[0].Severity)
+	assert.Empty(t, results[1].Diagnostics)
+	require.Len(t, results[2].Diagnostics, 1)
+	assert.Equal(t, core.SeverityError, results[2].Diagnostics[0].Severity, "model overrides apply per source")
+
+	assert.True(t, results[3].SyntaxErrors)
+	assert.Equal(t, lintsql.ParseErrorRuleID, results[3].Diagnostics[0].RuleID)
+	assert.False(t, results[0].SyntaxErrors)
+	assert.Empty(t, results[4].Diagnostics)
+}
+
+func TestAnalyzer_AnalyzeProject_Parallel(t *testing.T) {
+	registerSourceRule(t, func(_ any, _ lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
+		return []lint.Diagnostic{{RuleID: "SRC01", Severity: core.SeverityWarning, Message: "checked"}}
+	})
+
+	sources := make([]lintsql.Source, 200)
+	for i := range sources {
+		sources[i] = lintsql.Source{Path: fmt.Sprintf("model_%03d", i), SQL: fmt.Sprintf("SELECT %d AS id", i%7)}
+	}
+	results, err := lintsql.NewAnalyzer(nil, "duckdb").AnalyzeProject(context.Background(), sources, duckdbdialect.DuckDB)
+	require.NoError(t, err)
+	for i, res := range results {
+		assert.Equal(t, sources[i].Path, res.Path)
+		assert.Len(t, res.Diagnostics, 1)
+	}
+}
+
+func TestAnalyzer_AnalyzeProject_Errors(t *testing.T) {
+	_, err := lintsql.NewAnalyzer(nil, "duckdb").AnalyzeProject(context.Background(), nil, nil)
+	require.ErrorIs(t, err, core.ErrDialectRequired)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = lintsql.NewAnalyzer(nil, "duckdb").AnalyzeProject(ctx, []lintsql.Source{{Path: "a", SQL: "SELECT 1"}}, duckdbdialect.DuckDB)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -18,4 +18,21 @@
 //
 //	analyzer := sql.NewAnalyzer(lint.NewConfig(), "duckdb")
 //	diagnostics := analyzer.Analyze(stmt, dialect)
+//
+// To lint SQL text without parsing it first, use AnalyzeSource. It parses with
+// the analyzer's dialect, which must be registered, and reports syntax errors
+// as PRS diagnostics alongside the rules' findings:
+//
+//	import _ "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb" // register the dialect
+//
+//	diagnostics, err := analyzer.AnalyzeSource("SELECT a FROM t")
+//
+// AnalyzeProject lints many sources, such as every model of a project, in
+// parallel. Rule lookups are shared across the batch and identical SQL is
+// parsed once; results are grouped per source in input order.
+//
+// Measure computes complexity metrics of a statement, such as its joins,
+// CTEs and nesting depth, for complexity reports and budgets:
+//
+//	metrics := sql.Measure(stmt, dialect)
 package sql
@@ -0,0 +1,188 @@
+package sql
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql/internal/ast"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+// Explanation describes a run of one rule against SQL text, for developing
+// rules and reporting rule bugs.
+type Explanation struct {
+	Rule         lint.SQLRule
+	Dialect      string
+	Options      map[string]any     // Options the rule ran with
+	Nodes        []ExplainNode      // Nodes of the parsed statement, depth-first
+	SyntaxErrors []core.SyntaxError // Syntax errors of the SQL
+	Ran          bool               // Whether the rule ran
+	Reason       string             // Why the rule did or didn't report diagnostics
+	Diagnostics  []lint.Diagnostic  // Diagnostics the rule reported
+}
+
+// ExplainNode is a node of a parsed statement.
+type ExplainNode struct {
+	Depth  int            // Depth below the statement, which is at depth 0
+	Type   string         // Node type, e.g. "SelectCore" or "ColumnRef"
+	Detail string         // What the node names, e.g. "o.amount" (optional)
+	Pos    token.Position // Start of the node, when the parser records it
+}
+
+// Explain parses src in dialect d and runs the SQL rule ruleID against it,
+// with the options the analyzer's config sets for it. Unlike analysis, the
+// rule runs even if the config disables it. The explanation lists the nodes
+// of the statement the rule checked and why it did or didn't fire.
+func (a *Analyzer) Explain(ruleID, src string, d *core.Dialect) (*Explanation, error) {
+	if d == nil {
+		return nil, core.ErrDialectRequired
+	}
+	rule, ok := lint.GetSQLRuleByID(ruleID)
+	if !ok {
+		if _, ok := lint.GetProjectRuleByID(ruleID); ok {
+			return nil, fmt.Errorf("rule %s is a project rule: only SQL rules can be explained", ruleID)
+		}
+		return nil, fmt.Errorf("rule %q not found", ruleID)
+	}
+	ex := &Explanation{
+		Rule:    rule,
+		Dialect: d.Name,
+		Options: a.config.GetRuleOptions(rule.ID()),
+	}
+
+	stmt, syntaxErrs := parseTolerant(src, d)
+	ex.SyntaxErrors = syntaxErrs
+	lines := lineOffsets(src)
+	if stmt != nil {
+		ex.Nodes = explainNodes(stmt, lines)
+	}
+
+	switch {
+	case len(rule.Dialects()) > 0 && !containsDialect(rule.Dialects(), d.Name):
+		ex.Reason = fmt.Sprintf("%s only runs on %s SQL, not %s", rule.ID(), strings.Join(rule.Dialects(), ", "), d.Name)
+		return ex, nil
+	case stmt == nil:
+		ex.Reason = "The SQL has no statement the rule can check"
+		return ex, nil
+	}
+
+	ex.Ran = true
+	diags, failed := a.explainRun(rule, ex.Options, stmt, src, d, len(syntaxErrs) > 0)
+	for i := range diags {
+		diags[i].Pos = resolvePosition(diags[i].Pos, lines)
+		diags[i].EndPos = resolvePosition(diags[i].EndPos, lines)
+	}
+	lint.SortDiagnostics(diags)
+	ex.Diagnostics = diags
+
+	switch {
+	case failed:
+		ex.Reason = fmt.Sprintf("%s failed on the statement, which is incomplete because of syntax errors", rule.ID())
+	case len(diags) == 0:
+		ex.Reason = fmt.Sprintf("%s checked the statement and found no violations", rule.ID())
+	default:
+		ex.Reason = fmt.Sprintf("%s reported %d diagnostic(s)", rule.ID(), len(diags))
+	}
+	if a.config.IsDisabled(rule.ID()) {
+		ex.Reason += "; the lint config disables the rule, so lint doesn't run it"
+	}
+	return ex, nil
+}
+
+// explainRun runs a rule against a statement parsed from src, applying
+// severity overrides. A rule that panics on a statement with syntax errors
+// reports failed.
+func (a *Analyzer) explainRun(rule lint.SQLRule, opts map[string]any, stmt *core.SelectStmt, src string, d *core.Dialect, partial bool) (diags []lint.Diagnostic, failed bool) {
+	if partial {
+		defer func() {
+			if recover() != nil {
+				diags, failed = nil, true
+			}
+		}()
+	}
+
+	dialect := withSource(d, src)
+	if a.schema != nil {
+		dialect = lint.WithSchema(dialect, a.schema)
+	}
+	diags = rule.CheckSQL(stmt, dialect, opts)
+	for i := range diags {
+		diags[i].Severity = a.config.GetSeverity(rule.ID(), diags[i].Severity)
+	}
+	return diags, false
+}
+
+// explainNodes lists the nodes of stmt depth-first.
+func explainNodes(stmt *core.SelectStmt, lines []int) []ExplainNode {
+	var nodes []ExplainNode
+	ast.WalkDepth(stmt, func(node any, depth int) bool {
+		if isNilNode(node) {
+			return false
+		}
+		n := ExplainNode{Depth: depth, Type: nodeType(node), Detail: nodeDetail(node)}
+		if pn, ok := node.(core.Node); ok {
+			n.Pos = resolvePosition(pn.Pos(), lines)
+		}
+		nodes = append(nodes, n)
+		return true
+	})
+	return nodes
+}
+
+// isNilNode reports whether node is a nil pointer, such as an absent WITH
+// clause, which the walk visits but isn't part of the statement.
+func isNilNode(node any) bool {
+	v := reflect.ValueOf(node)
+	return !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil())
+}
+
+// nodeType returns the name of a node's type without its package.
+func nodeType(node any) string {
+	t := reflect.TypeOf(node)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// nodeDetail returns what a node names, such as a column or function.
+func nodeDetail(node any) string {
+	switch n := node.(type) {
+	case *core.ColumnRef:
+		return ast.ColumnRefSQL(n)
+	case *core.TableName:
+		name := n.Name
+		if n.Schema != "" {
+			name = n.Schema + "." + name
+		}
+		if n.Alias != "" {
+			name += " AS " + n.Alias
+		}
+		return name
+	case *core.DerivedTable:
+		return n.Alias
+	case *core.CTE:
+		return n.Name
+	case *core.FuncCall:
+		return n.Name
+	case *core.Literal:
+		return ast.LiteralSQL(n)
+	case *core.BinaryExpr:
+		return n.Op.String()
+	case *core.UnaryExpr:
+		return n.Op.String()
+	case *core.CastExpr:
+		return n.TypeName
+	case *core.Join:
+		return string(n.Type)
+	case *core.StarExpr:
+		if n.Table != "" {
+			return n.Table + ".*"
+		}
+		return "*"
+	}
+	return ""
+}
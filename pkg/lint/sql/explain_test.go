@@ -0,0 +1,77 @@
+package sql_test
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	duckdbdialect "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzer_Explain(t *testing.T) {
+	registerSourceRule(t, func(stmt any, _ lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
+		s := stmt.(*core.SelectStmt)
+		if !s.Body.Left.Distinct {
+			return nil
+		}
+		return []lint.Diagnostic{{RuleID: "SRC01", Severity: core.SeverityWarning, Message: "distinct", Pos: token.Position{Offset: 7}}}
+	})
+
+	cfg := lint.NewConfig()
+	cfg.Disable("SRC01")
+	cfg.SetSeverity("SRC01", core.SeverityError)
+	analyzer := lintsql.NewAnalyzer(cfg, "duckdb")
+
+	ex, err := analyzer.Explain("SRC01", "SELECT DISTINCT o.id\nFROM orders o", duckdbdialect.DuckDB)
+	require.NoError(t, err)
+	assert.True(t, ex.Ran, "explain runs disabled rules")
+	assert.Equal(t, "duckdb", ex.Dialect)
+	assert.Contains(t, ex.Reason, "reported 1 diagnostic")
+	assert.Contains(t, ex.Reason, "disables the rule")
+	require.Len(t, ex.Diagnostics, 1)
+	assert.Equal(t, core.SeverityError, ex.Diagnostics[0].Severity)
+	assert.Equal(t, token.Position{Line: 1, Column: 8, Offset: 7}, ex.Diagnostics[0].Pos)
+
+	require.NotEmpty(t, ex.Nodes)
+	assert.Equal(t, lintsql.ExplainNode{Depth: 0, Type: "SelectStmt", Pos: ex.Nodes[0].Pos}, ex.Nodes[0])
+	var details []string
+	for _, n := range ex.Nodes {
+		if n.Type == "ColumnRef" || n.Type == "TableName" {
+			details = append(details, n.Detail)
+		}
+		assert.GreaterOrEqual(t, n.Depth, 0)
+	}
+	assert.Equal(t, []string{"o.id", "orders AS o"}, details)
+
+	ex, err = analyzer.Explain("SRC01", "SELECT id FROM orders", duckdbdialect.DuckDB)
+	require.NoError(t, err)
+	assert.True(t, ex.Ran)
+	assert.Empty(t, ex.Diagnostics)
+	assert.Contains(t, ex.Reason, "found no violations")
+}
+
+func TestAnalyzer_Explain_Errors(t *testing.T) {
+	registerSourceRule(t, func(_ any, _ lint.DialectInfo, _ map[string]any) []lint.Diagnostic { return nil })
+	analyzer := lintsql.NewAnalyzer(nil, "duckdb")
+
+	_, err := analyzer.Explain("NOPE01", "SELECT 1", duckdbdialect.DuckDB)
+	require.ErrorContains(t, err, `rule "NOPE01" not found`)
+
+	_, err = analyzer.Explain("SRC01", "SELECT 1", nil)
+	require.ErrorIs(t, err, core.ErrDialectRequired)
+
+	ex, err := analyzer.Explain("SRC01", "SELECT FROM", duckdbdialect.DuckDB)
+	require.NoError(t, err)
+	assert.NotEmpty(t, ex.SyntaxErrors)
+
+	lintsql.Register(lintsql.RuleDef{ID: "SNOW01", Name: "test.snowflake", Group: "test", Dialects: []string{"snowflake"},
+		Check: func(_ any, _ lint.DialectInfo, _ map[string]any) []lint.Diagnostic { return nil }})
+	ex, err = analyzer.Explain("SNOW01", "SELECT 1", duckdbdialect.DuckDB)
+	require.NoError(t, err)
+	assert.False(t, ex.Ran)
+	assert.Equal(t, "SNOW01 only runs on snowflake SQL, not duckdb", ex.Reason)
+}
@@ -0,0 +1,285 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql/internal/ast"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+// Subqueries returns the subqueries of the FROM clauses of stmt, outer
+// subqueries before the ones they hold.
+func Subqueries(stmt *core.SelectStmt) []*core.DerivedTable {
+	var subs []*core.DerivedTable
+	ast.Walk(stmt, func(node any) bool {
+		if d, ok := node.(*core.DerivedTable); ok {
+			subs = append(subs, d)
+		}
+		return true
+	})
+	return subs
+}
+
+// SubqueryAt returns the innermost subquery of a FROM clause of stmt whose
+// span holds offset, or nil if there is none.
+func SubqueryAt(stmt *core.SelectStmt, offset int) *core.DerivedTable {
+	var found *core.DerivedTable
+	for _, d := range Subqueries(stmt) {
+		if d.Span.Start.Offset <= offset && offset < d.Span.End.Offset {
+			found = d
+		}
+	}
+	return found
+}
+
+// CTEAt returns the CTE of the WITH clause of stmt whose span holds offset,
+// or nil if there is none.
+func CTEAt(stmt *core.SelectStmt, offset int) *core.CTE {
+	if stmt == nil || stmt.With == nil {
+		return nil
+	}
+	for _, c := range stmt.With.CTEs {
+		if c.Span.Start.Offset <= offset && offset < c.Span.End.Offset {
+			return c
+		}
+	}
+	return nil
+}
+
+// ExtractCTE builds a fix moving a subquery of stmt into a CTE named name,
+// read by the subquery's alias where the subquery was. The CTE is added
+// before the CTE holding the subquery, so it may read the CTEs before it,
+// after the last CTE otherwise, and in a new WITH clause if stmt has none.
+// The subquery's text is moved as is, reindented. src is the SQL stmt was
+// parsed from and tokens its tokens. It fails if name is already used by a
+// table of the query, or if the subquery reads a CTE of a nested WITH
+// clause, which it could not read from the top.
+func ExtractCTE(stmt *core.SelectStmt, src string, tokens []token.Token, sub *core.DerivedTable, name string) (lint.Fix, error) {
+	fix := lint.Fix{Description: fmt.Sprintf("Extract subquery into CTE %s", name)}
+	if sub.Alias != "" {
+		fix.Description = fmt.Sprintf("Extract subquery %s into CTE %s", sub.Alias, name)
+	}
+
+	if tableNames(stmt)[strings.ToLower(name)] {
+		return fix, fmt.Errorf("%s already names a table or CTE of the query", name)
+	}
+	if nested := nestedCTEs(stmt, sub); len(nested) > 0 {
+		for table := range tableNames(sub.Select) {
+			if nested[table] {
+				return fix, fmt.Errorf("the subquery reads %s, a CTE of a nested WITH clause", table)
+			}
+		}
+	}
+	open, closing, ok := parens(tokens, sub.Span.Start.Offset, sub.Span.End.Offset)
+	if !ok {
+		return fix, fmt.Errorf("subquery not found in the SQL")
+	}
+
+	ref := name
+	if sub.Alias != "" && !strings.EqualFold(sub.Alias, name) {
+		ref += " AS " + ast.IdentSQL(sub.Alias)
+	}
+	fix.TextEdits = append(fix.TextEdits, lint.TextEdit{Pos: sub.Span.Start, EndPos: sub.Span.End, NewText: ref})
+
+	cte := func(margin string) string {
+		return name + " AS (\n" + reindent(src, open+1, closing, margin+"    ") + "\n" + margin + ")"
+	}
+	switch outer := CTEAt(stmt, sub.Span.Start.Offset); {
+	case outer != nil:
+		margin := lineIndent(src, outer.Span.Start.Offset)
+		fix.TextEdits = append(fix.TextEdits, lint.TextEdit{Pos: outer.Span.Start, EndPos: outer.Span.Start, NewText: cte(margin) + ",\n" + margin})
+	case stmt.With != nil:
+		margin := lineIndent(src, stmt.With.CTEs[0].Span.Start.Offset)
+		last := stmt.With.CTEs[len(stmt.With.CTEs)-1]
+		fix.TextEdits = append(fix.TextEdits, lint.TextEdit{Pos: last.Span.End, EndPos: last.Span.End, NewText: ",\n" + margin + cte(margin)})
+	default:
+		first := tokenAt(tokens, 0)
+		fix.TextEdits = append(fix.TextEdits, lint.TextEdit{Pos: first.Pos, EndPos: first.Pos, NewText: "WITH " + cte("") + "\n"})
+	}
+	return fix, nil
+}
+
+// ExtractModel builds a fix moving the CTE of stmt named cte out of the
+// query, into a model of its own: the CTE is removed from the WITH clause,
+// which goes if it has no other CTE, and the tables reading it read ref
+// instead, e.g. {{ ref('orders') }}, aliased by the CTE's name unless they
+// have an alias. It returns the fix and the CTE's query, dedented. It fails
+// if the CTE reads another CTE of the query, which a model could not.
+func ExtractModel(stmt *core.SelectStmt, src string, tokens []token.Token, cte, ref string) (lint.Fix, string, error) {
+	fix := lint.Fix{Description: fmt.Sprintf("Extract CTE %s into a model", cte)}
+	if stmt == nil || stmt.With == nil {
+		return fix, "", fmt.Errorf("no CTE %s in the query", cte)
+	}
+
+	ctes := stmt.With.CTEs
+	index := -1
+	names := make(map[string]bool, len(ctes))
+	for i, c := range ctes {
+		names[strings.ToLower(c.Name)] = true
+		if strings.EqualFold(c.Name, cte) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return fix, "", fmt.Errorf("no CTE %s in the query", cte)
+	}
+	c := ctes[index]
+	for table := range tableNames(c.Select) {
+		if names[table] && !strings.EqualFold(table, cte) {
+			return fix, "", fmt.Errorf("CTE %s reads CTE %s: extract %s first", cte, table, table)
+		}
+	}
+	open, closing, ok := parens(tokens, c.Span.Start.Offset, c.Span.End.Offset)
+	if !ok {
+		return fix, "", fmt.Errorf("CTE %s not found in the SQL", cte)
+	}
+	query := reindent(src, open+1, closing, "")
+
+	// The CTE goes with the separator before or after it
+	switch {
+	case len(ctes) == 1:
+		end := stmt.With.Span.End
+		for _, tok := range tokens {
+			if tok.Pos.Offset >= end.Offset {
+				end = tok.Pos
+				break
+			}
+		}
+		fix.TextEdits = append(fix.TextEdits, lint.TextEdit{Pos: stmt.With.Span.Start, EndPos: end, NewText: ""})
+	case index < len(ctes)-1:
+		fix.TextEdits = append(fix.TextEdits, lint.TextEdit{Pos: c.Span.Start, EndPos: ctes[index+1].Span.Start, NewText: ""})
+	default:
+		fix.TextEdits = append(fix.TextEdits, lint.TextEdit{Pos: ctes[index-1].Span.End, EndPos: c.Span.End, NewText: ""})
+	}
+
+	ast.Walk(stmt, func(node any) bool {
+		t, ok := node.(*core.TableName)
+		if !ok || t.Catalog != "" || t.Schema != "" || !strings.EqualFold(t.Name, cte) ||
+			(t.Span.Start.Offset >= c.Span.Start.Offset && t.Span.Start.Offset < c.Span.End.Offset) {
+			return true
+		}
+		for _, tok := range tokens {
+			if tok.Pos.Offset != t.Span.Start.Offset {
+				continue
+			}
+			end := tokenEnd(src, tok)
+			text := ref
+			if t.Alias == "" {
+				text += " AS " + src[tok.Pos.Offset:end.Offset]
+			}
+			fix.TextEdits = append(fix.TextEdits, lint.TextEdit{Pos: tok.Pos, EndPos: end, NewText: text})
+			break
+		}
+		return true
+	})
+	return fix, query, nil
+}
+
+// tableNames returns the lowercase names of the CTEs of stmt and of the
+// tables it reads without a schema.
+func tableNames(stmt *core.SelectStmt) map[string]bool {
+	names := make(map[string]bool)
+	ast.Walk(stmt, func(node any) bool {
+		switch n := node.(type) {
+		case *core.CTE:
+			names[strings.ToLower(n.Name)] = true
+		case *core.TableName:
+			if n.Catalog == "" && n.Schema == "" {
+				names[strings.ToLower(n.Name)] = true
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// nestedCTEs returns the lowercase names of the CTEs of the WITH clauses of
+// stmt's subqueries, but those of sub.
+func nestedCTEs(stmt *core.SelectStmt, sub *core.DerivedTable) map[string]bool {
+	names := make(map[string]bool)
+	ast.Walk(stmt, func(node any) bool {
+		s, ok := node.(*core.SelectStmt)
+		if !ok || s == stmt || s.With == nil {
+			return true
+		}
+		for _, c := range s.With.CTEs {
+			if c.Span.Start.Offset < sub.Span.Start.Offset || c.Span.Start.Offset >= sub.Span.End.Offset {
+				names[strings.ToLower(c.Name)] = true
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// parens returns the offsets of the first opening parenthesis of tokens
+// between start and end, and of the parenthesis closing it.
+func parens(tokens []token.Token, start, end int) (int, int, bool) {
+	open, depth := -1, 0
+	for _, tok := range tokens {
+		if tok.Pos.Offset < start || tok.Pos.Offset >= end {
+			continue
+		}
+		switch tok.Type {
+		case token.LPAREN:
+			if open < 0 {
+				open = tok.Pos.Offset
+			}
+			depth++
+		case token.RPAREN:
+			depth--
+			if open >= 0 && depth == 0 {
+				return open, tok.Pos.Offset, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// reindent returns the text of src between start and end, trimmed, with its
+// lines indented by indent. Lines keep their indentation relative to each
+// other, and to the first one when it starts its line.
+func reindent(src string, start, end int, indent string) string {
+	text := src[start:end]
+	start += len(text) - len(strings.TrimLeft(text, " \t\r\n"))
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+
+	margin, first := -1, -1
+	if prefix := src[strings.LastIndexByte(src[:start], '\n')+1 : start]; strings.TrimSpace(prefix) == "" {
+		margin, first = len(prefix), len(prefix)
+	}
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if n := len(line) - len(strings.TrimLeft(line, " \t")); margin < 0 || n < margin {
+			margin = n
+		}
+	}
+
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		switch {
+		case line == "":
+		case i == 0:
+			line = indent + strings.Repeat(" ", max(first-margin, 0)) + line
+		default:
+			line = indent + line[margin:]
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lineIndent returns the indentation of the line of src holding offset, if
+// only whitespace precedes offset on it.
+func lineIndent(src string, offset int) string {
+	prefix := src[strings.LastIndexByte(src[:offset], '\n')+1 : offset]
+	if strings.TrimSpace(prefix) != "" {
+		return ""
+	}
+	return prefix
+}
@@ -0,0 +1,148 @@
+package sql_test
+
+import (
+	"strings"
+	"testing"
+
+	duckdbdialect "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractCTE(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		at      string // Text the cursor is on
+		cte     string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "new WITH clause",
+			sql:  "SELECT o.id\nFROM (\n    SELECT id\n    FROM orders\n    WHERE amount > 0\n) AS o",
+			at:   "amount",
+			cte:  "paid_orders",
+			want: "WITH paid_orders AS (\n    SELECT id\n    FROM orders\n    WHERE amount > 0\n)\nSELECT o.id\nFROM paid_orders AS o",
+		},
+		{
+			name: "after the last CTE",
+			sql:  "WITH c AS (SELECT id FROM customers)\nSELECT * FROM c JOIN (SELECT customer_id,\n                             SUM(amount) AS total\n                      FROM orders GROUP BY 1) totals ON c.id = totals.customer_id",
+			at:   "SUM",
+			cte:  "totals",
+			want: "WITH c AS (SELECT id FROM customers),\ntotals AS (\n    SELECT customer_id,\n           SUM(amount) AS total\n    FROM orders GROUP BY 1\n)\nSELECT * FROM c JOIN totals ON c.id = totals.customer_id",
+		},
+		{
+			name: "before the CTE holding it",
+			sql:  "WITH\n    a AS (SELECT 1 AS x),\n    b AS (SELECT s.x FROM (SELECT x FROM a) s)\nSELECT * FROM b",
+			at:   "FROM a",
+			cte:  "a_x",
+			want: "WITH\n    a AS (SELECT 1 AS x),\n    a_x AS (\n        SELECT x FROM a\n    ),\n    b AS (SELECT s.x FROM a_x AS s)\nSELECT * FROM b",
+		},
+		{
+			name: "innermost subquery",
+			sql:  "SELECT * FROM (SELECT * FROM (SELECT id FROM orders) inner_q) outer_q",
+			at:   "id",
+			cte:  "ids",
+			want: "WITH ids AS (\n    SELECT id FROM orders\n)\nSELECT * FROM (SELECT * FROM ids AS inner_q) outer_q",
+		},
+		{
+			name:    "name taken",
+			sql:     "SELECT * FROM (SELECT id FROM orders) o JOIN customers c ON c.id = o.id",
+			at:      "id",
+			cte:     "customers",
+			wantErr: "customers already names a table or CTE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(tt.sql, duckdbdialect.DuckDB)
+			require.NoError(t, err)
+			sub := lintsql.SubqueryAt(stmt, strings.Index(tt.sql, tt.at))
+			require.NotNil(t, sub)
+
+			fix, err := lintsql.ExtractCTE(stmt, tt.sql, parser.TokenizeWithDialect(tt.sql, duckdbdialect.DuckDB), sub, tt.cte)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			got, _ := lint.ApplyFixes(tt.sql, []lint.Diagnostic{{Fixes: []lint.Fix{fix}}}, false)
+			assert.Equal(t, tt.want, got)
+			_, err = parser.ParseWithDialect(got, duckdbdialect.DuckDB)
+			assert.NoError(t, err)
+		})
+	}
+
+	sql := "SELECT 1 FROM orders"
+	stmt, err := parser.ParseWithDialect(sql, duckdbdialect.DuckDB)
+	require.NoError(t, err)
+	assert.Nil(t, lintsql.SubqueryAt(stmt, 0))
+}
+
+func TestExtractModel(t *testing.T) {
+	const ref = "{{ ref('paid_orders') }}"
+	tests := []struct {
+		name      string
+		sql       string
+		cte       string
+		want      string
+		wantQuery string
+		wantErr   string
+	}{
+		{
+			name:      "only CTE",
+			sql:       "WITH paid_orders AS (\n    SELECT id, amount\n    FROM orders\n    WHERE amount > 0\n)\nSELECT paid_orders.id FROM paid_orders",
+			cte:       "paid_orders",
+			want:      "SELECT paid_orders.id FROM {{ ref('paid_orders') }} AS paid_orders",
+			wantQuery: "SELECT id, amount\nFROM orders\nWHERE amount > 0",
+		},
+		{
+			name:      "first CTE",
+			sql:       "WITH paid_orders AS (SELECT id FROM orders WHERE amount > 0),\nids AS (SELECT p.id FROM paid_orders p)\nSELECT * FROM ids",
+			cte:       "paid_orders",
+			want:      "WITH ids AS (SELECT p.id FROM {{ ref('paid_orders') }} p)\nSELECT * FROM ids",
+			wantQuery: "SELECT id FROM orders WHERE amount > 0",
+		},
+		{
+			name:      "last CTE",
+			sql:       "WITH a AS (SELECT 1 AS x), paid_orders AS (SELECT id FROM orders)\nSELECT * FROM a, paid_orders",
+			cte:       "paid_orders",
+			want:      "WITH a AS (SELECT 1 AS x)\nSELECT * FROM a, {{ ref('paid_orders') }} AS paid_orders",
+			wantQuery: "SELECT id FROM orders",
+		},
+		{
+			name:    "reads another CTE",
+			sql:     "WITH a AS (SELECT 1 AS x), paid_orders AS (SELECT x FROM a)\nSELECT * FROM paid_orders",
+			cte:     "paid_orders",
+			wantErr: "CTE paid_orders reads CTE a",
+		},
+		{
+			name:    "missing CTE",
+			sql:     "SELECT * FROM orders",
+			cte:     "paid_orders",
+			wantErr: "no CTE paid_orders",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(tt.sql, duckdbdialect.DuckDB)
+			require.NoError(t, err)
+
+			fix, query, err := lintsql.ExtractModel(stmt, tt.sql, parser.TokenizeWithDialect(tt.sql, duckdbdialect.DuckDB), tt.cte, ref)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantQuery, query)
+			got, _ := lint.ApplyFixes(tt.sql, []lint.Diagnostic{{Fixes: []lint.Fix{fix}}}, false)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
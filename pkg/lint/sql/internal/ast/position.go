@@ -21,6 +21,19 @@ func GetTableRefPosition(ref core.TableRef) token.Position {
 	return token.Position{}
 }
 
+// GetTableRefSpan returns the span of a table reference, including its alias.
+func GetTableRefSpan(ref core.TableRef) token.Span {
+	switch t := ref.(type) {
+	case *core.TableName:
+		return t.Span
+	case *core.DerivedTable:
+		return t.Span
+	case *core.LateralTable:
+		return t.Span
+	}
+	return token.Span{}
+}
+
 // GetJoinPosition returns the position of a join.
 func GetJoinPosition(join *core.Join) token.Position {
 	if join == nil {
@@ -37,6 +50,18 @@ func GetSelectCorePosition(core *core.SelectCore) token.Position {
 	return core.Span.Start
 }
 
+// GetSelectListSpan returns the span of a SelectCore's select list, or of the
+// SelectCore if the list has no position.
+func GetSelectListSpan(core *core.SelectCore) token.Span {
+	if core == nil {
+		return token.Span{}
+	}
+	if core.ColumnsSpan.Start.Line == 0 {
+		return core.Span
+	}
+	return core.ColumnsSpan
+}
+
 // GetCTEPosition returns the position of a CTE.
 func GetCTEPosition(cte *core.CTE) token.Position {
 	if cte == nil {
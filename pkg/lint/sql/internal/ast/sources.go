@@ -0,0 +1,204 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+)
+
+// Source is a table, CTE or derived table in a FROM clause.
+type Source struct {
+	Name    string   // Name column references qualify the source by: its alias, or its table name
+	Columns []string // Columns of the source; nil if unknown
+}
+
+// Known reports whether the columns of the source are known.
+func (s Source) Known() bool {
+	return s.Columns != nil
+}
+
+// HasColumn reports whether the source has the given column.
+func (s Source) HasColumn(column string, d lint.DialectInfo) bool {
+	for _, c := range s.Columns {
+		if SameName(c, column, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromSources returns the sources of the FROM clause of sc, a SELECT of
+// stmt. Columns are resolved from the output of stmt's CTEs and derived
+// tables, and for tables from the schema attached to d (see lint.WithSchema).
+func FromSources(stmt *core.SelectStmt, sc *core.SelectCore, d lint.DialectInfo) []Source {
+	if sc == nil || sc.From == nil {
+		return nil
+	}
+	r := newResolver(stmt, d)
+	sources := []Source{r.source(sc.From.Source)}
+	for _, j := range sc.From.Joins {
+		sources = append(sources, r.source(j.Right))
+	}
+	return sources
+}
+
+// ColumnSources returns the sources that have the given column, and whether
+// the columns of every source are known, in which case a column in no
+// source doesn't come from the FROM clause (e.g. a select list alias).
+func ColumnSources(sources []Source, column string, d lint.DialectInfo) (matches []Source, complete bool) {
+	complete = true
+	for _, s := range sources {
+		if !s.Known() {
+			complete = false
+			continue
+		}
+		if s.HasColumn(column, d) {
+			matches = append(matches, s)
+		}
+	}
+	return matches, complete
+}
+
+// resolver resolves the columns of FROM sources.
+type resolver struct {
+	dialect lint.DialectInfo
+	schema  lint.Schema
+	ctes    map[string][]string // CTE name -> columns; nil if unknown
+}
+
+func newResolver(stmt *core.SelectStmt, d lint.DialectInfo) *resolver {
+	r := &resolver{dialect: d, schema: lint.SchemaOf(d), ctes: make(map[string][]string)}
+	r.addCTEs(stmt)
+	return r
+}
+
+// addCTEs resolves the columns of stmt's CTEs in order, so each CTE sees
+// the ones before it. A CTE referencing itself has unknown columns.
+func (r *resolver) addCTEs(stmt *core.SelectStmt) {
+	if stmt == nil || stmt.With == nil {
+		return
+	}
+	for _, cte := range stmt.With.CTEs {
+		name := r.normalize(cte.Name)
+		r.ctes[name] = nil
+		r.ctes[name] = r.outputColumns(cte.Select)
+	}
+}
+
+// source resolves a FROM item.
+func (r *resolver) source(ref core.TableRef) Source {
+	switch t := ref.(type) {
+	case *core.TableName:
+		name := t.Name
+		if t.Alias != "" {
+			name = t.Alias
+		}
+		return Source{Name: name, Columns: r.tableColumns(t)}
+	case *core.DerivedTable:
+		return Source{Name: t.Alias, Columns: r.outputColumns(t.Select)}
+	case *core.LateralTable:
+		return Source{Name: t.Alias}
+	case *core.TableFunction:
+		return Source{Name: t.Alias}
+	}
+	return Source{}
+}
+
+// tableColumns returns the columns of a table: a CTE if it is an
+// unqualified name of one, otherwise the table in the schema.
+func (r *resolver) tableColumns(t *core.TableName) []string {
+	if t.Schema == "" && t.Catalog == "" {
+		if cols, ok := r.ctes[r.normalize(t.Name)]; ok {
+			return cols
+		}
+	}
+	if r.schema == nil {
+		return nil
+	}
+
+	parts := []string{t.Name}
+	if t.Schema != "" {
+		parts = append([]string{t.Schema}, parts...)
+	}
+	if t.Catalog != "" {
+		parts = append([]string{t.Catalog}, parts...)
+	}
+	for _, name := range []string{strings.Join(parts, "."), t.Name} {
+		if cols, ok := r.schema.Columns(name); ok {
+			return cols
+		}
+	}
+	return nil
+}
+
+// outputColumns returns the columns a query outputs, named after its first
+// SELECT, or nil if any of them can't be resolved.
+func (r *resolver) outputColumns(stmt *core.SelectStmt) []string {
+	sc := GetSelectCore(stmt)
+	if sc == nil {
+		return nil
+	}
+
+	// A nested WITH clause scopes its CTEs to the query
+	inner := r
+	if stmt.With != nil {
+		inner = &resolver{dialect: r.dialect, schema: r.schema, ctes: make(map[string][]string, len(r.ctes))}
+		for name, cols := range r.ctes {
+			inner.ctes[name] = cols
+		}
+		inner.addCTEs(stmt)
+	}
+
+	var sources []Source
+	if sc.From != nil {
+		sources = append(sources, inner.source(sc.From.Source))
+		for _, j := range sc.From.Joins {
+			sources = append(sources, inner.source(j.Right))
+		}
+	}
+
+	columns := make([]string, 0, len(sc.Columns))
+	for _, item := range sc.Columns {
+		switch {
+		case item.Star || item.TableStar != "":
+			if len(item.Modifiers) > 0 {
+				return nil
+			}
+			for _, s := range sources {
+				if item.TableStar != "" && !SameName(s.Name, item.TableStar, r.dialect) {
+					continue
+				}
+				if !s.Known() {
+					return nil
+				}
+				columns = append(columns, s.Columns...)
+			}
+		case item.Alias != "":
+			columns = append(columns, item.Alias)
+		default:
+			if col, ok := item.Expr.(*core.ColumnRef); ok {
+				columns = append(columns, col.Column)
+			}
+			// Other expressions get names generated by the database, which
+			// queries don't reference
+		}
+	}
+	return columns
+}
+
+func (r *resolver) normalize(name string) string {
+	return normalizeName(name, r.dialect)
+}
+
+// SameName reports whether two identifiers name the same object in dialect d.
+func SameName(a, b string, d lint.DialectInfo) bool {
+	return normalizeName(a, d) == normalizeName(b, d)
+}
+
+func normalizeName(name string, d lint.DialectInfo) string {
+	if d == nil {
+		return strings.ToLower(name)
+	}
+	return d.NormalizeName(name)
+}
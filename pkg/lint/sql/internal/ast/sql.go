@@ -0,0 +1,57 @@
+package ast
+
+import (
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// LiteralSQL renders a literal back to SQL source.
+func LiteralSQL(lit *core.Literal) string {
+	switch lit.Type {
+	case core.LiteralString:
+		return "'" + strings.ReplaceAll(lit.Value, "'", "''") + "'"
+	case core.LiteralNull:
+		return "NULL"
+	default:
+		return lit.Value
+	}
+}
+
+// ColumnRefSQL renders a column reference back to SQL source, quoting parts
+// that are not plain identifiers.
+func ColumnRefSQL(col *core.ColumnRef) string {
+	parts := make([]string, 0, 2+len(col.Fields))
+	if col.Table != "" {
+		parts = append(parts, IdentSQL(col.Table))
+	}
+	parts = append(parts, IdentSQL(col.Column))
+	for _, f := range col.Fields {
+		parts = append(parts, IdentSQL(f))
+	}
+	return strings.Join(parts, ".")
+}
+
+// IdentSQL renders an identifier, double-quoting it unless it is a plain
+// identifier.
+func IdentSQL(name string) string {
+	if isPlainIdent(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func isPlainIdent(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && r >= '0' && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -17,6 +17,25 @@ func Walk(node any, fn func(node any) bool) {
 	walkNode(node, fn)
 }
 
+// WalkDepth is like Walk, and also passes fn the depth of each node below
+// the root, which is at depth 0. If fn returns false, the children of the
+// node are skipped.
+func WalkDepth(node any, fn func(node any, depth int) bool) {
+	var visit func(node any, depth int)
+	visit = func(node any, depth int) {
+		root := true
+		Walk(node, func(n any) bool {
+			if root {
+				root = false
+				return fn(n, depth)
+			}
+			visit(n, depth+1)
+			return false
+		})
+	}
+	visit(node, 0)
+}
+
 func walkNode(node any, fn func(node any) bool) {
 	switch n := node.(type) {
 	case *core.SelectStmt:
@@ -98,6 +117,14 @@ func walkNode(node any, fn func(node any) bool) {
 		}
 		Walk(n.Select, fn)
 
+	case *core.TableFunction:
+		if n == nil {
+			return
+		}
+		for _, arg := range n.Args {
+			Walk(arg, fn)
+		}
+
 	case *core.BinaryExpr:
 		if n == nil {
 			return
@@ -353,6 +380,10 @@ func CollectReferencedTables(stmt *core.SelectStmt) map[string]bool {
 			if n.Alias != "" {
 				refs[n.Alias] = true
 			}
+		case *core.TableFunction:
+			if n.Alias != "" {
+				refs[n.Alias] = true
+			}
 		}
 		return true
 	})
@@ -0,0 +1,137 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql/internal/ast"
+)
+
+// Metrics measures the complexity of a statement. Counts include the
+// subqueries and CTEs of the statement.
+type Metrics struct {
+	Joins        int      // JOIN clauses, comma joins included
+	CTEs         int      // Common table expressions
+	Subqueries   int      // Queries nested in the statement, other than CTEs
+	NestingDepth int      // Deepest nesting of queries: 1 for a query without subqueries or CTEs
+	SetOps       int      // UNION, INTERSECT and EXCEPT operations
+	Expressions  int      // Expression nodes, e.g. 3 for a = 1
+	Tables       []string // Tables the statement reads, as written, excluding CTEs; sorted
+	Columns      []string // Columns the statement references, as written; sorted
+}
+
+// Measure computes the complexity metrics of stmt. Table names are matched
+// against CTE names with the identifier rules of dialect d.
+func Measure(stmt *core.SelectStmt, d lint.DialectInfo) Metrics {
+	m := &measurer{dialect: d, tables: make(map[string]bool), columns: make(map[string]bool)}
+	if stmt != nil {
+		m.query(stmt, 1, nil)
+	}
+
+	metrics := m.metrics
+	metrics.Tables = sortedKeys(m.tables)
+	metrics.Columns = sortedKeys(m.columns)
+	return metrics
+}
+
+// MeasureSource parses src in dialect d and computes the complexity metrics
+// of its statement. Syntax errors fail the measurement.
+func MeasureSource(src string, d *core.Dialect) (Metrics, error) {
+	if d == nil {
+		return Metrics{}, core.ErrDialectRequired
+	}
+	stmt, syntaxErrs := parseTolerant(src, d)
+	if len(syntaxErrs) > 0 {
+		e := syntaxErrs[0]
+		return Metrics{}, fmt.Errorf("syntax error at line %d, column %d: %s", e.Pos.Line, e.Pos.Column, e.Message)
+	}
+	return Measure(stmt, d), nil
+}
+
+// measurer accumulates the metrics of a statement.
+type measurer struct {
+	dialect lint.DialectInfo
+	metrics Metrics
+	tables  map[string]bool
+	columns map[string]bool
+}
+
+// query measures a query at the given nesting depth. ctes holds the names of
+// the CTEs in scope, which table references resolve to before tables.
+func (m *measurer) query(stmt *core.SelectStmt, depth int, ctes []string) {
+	m.metrics.NestingDepth = max(m.metrics.NestingDepth, depth)
+
+	if stmt.With != nil {
+		for _, cte := range stmt.With.CTEs {
+			m.metrics.CTEs++
+			// A CTE sees itself, for recursion, and the CTEs before it
+			ctes = append(ctes, cte.Name)
+			if cte.Select != nil {
+				m.query(cte.Select, depth+1, ctes)
+			}
+		}
+	}
+
+	ast.Walk(stmt.Body, func(node any) bool {
+		switch n := node.(type) {
+		case *core.SelectStmt:
+			if n != nil {
+				m.metrics.Subqueries++
+				m.query(n, depth+1, ctes)
+			}
+			return false
+		case *core.SelectBody:
+			if n != nil && n.Op != core.SetOpNone {
+				m.metrics.SetOps++
+			}
+		case *core.Join:
+			m.metrics.Joins++
+		case *core.TableName:
+			if !m.isCTE(n, ctes) {
+				m.tables[tableSQL(n)] = true
+			}
+		case *core.ColumnRef:
+			m.columns[ast.ColumnRefSQL(n)] = true
+		}
+		if _, ok := node.(core.Expr); ok && !isNilNode(node) {
+			m.metrics.Expressions++
+		}
+		return true
+	})
+}
+
+// isCTE reports whether a table reference names a CTE in scope.
+func (m *measurer) isCTE(t *core.TableName, ctes []string) bool {
+	if t.Schema != "" || t.Catalog != "" {
+		return false
+	}
+	for _, name := range ctes {
+		if ast.SameName(name, t.Name, m.dialect) {
+			return true
+		}
+	}
+	return false
+}
+
+// tableSQL returns the qualified name of a table reference, without alias.
+func tableSQL(t *core.TableName) string {
+	name := t.Name
+	if t.Schema != "" {
+		name = t.Schema + "." + name
+	}
+	if t.Catalog != "" {
+		name = t.Catalog + "." + name
+	}
+	return name
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package sql_test
+
+import (
+	"testing"
+
+	duckdbdialect "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasureSource(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want lintsql.Metrics
+	}{
+		{
+			name: "flat query",
+			sql:  "SELECT id FROM orders WHERE amount > 10",
+			want: lintsql.Metrics{
+				NestingDepth: 1,
+				Expressions:  4,
+				Tables:       []string{"orders"},
+				Columns:      []string{"amount", "id"},
+			},
+		},
+		{
+			name: "joins and set operation",
+			sql:  "SELECT o.id FROM raw.orders o JOIN customers c ON o.customer_id = c.id, regions r UNION ALL SELECT id FROM archive",
+			want: lintsql.Metrics{
+				Joins:        2,
+				NestingDepth: 1,
+				SetOps:       1,
+				Expressions:  5,
+				Tables:       []string{"archive", "customers", "raw.orders", "regions"},
+				Columns:      []string{"c.id", "id", "o.customer_id", "o.id"},
+			},
+		},
+		{
+			name: "CTEs and subqueries",
+			sql: `WITH recent AS (SELECT id FROM orders),
+			      big AS (SELECT id FROM recent WHERE id IN (SELECT id FROM refunds))
+			      SELECT b.id FROM big b JOIN (SELECT id FROM recent) r ON b.id = r.id`,
+			want: lintsql.Metrics{
+				Joins:        1,
+				CTEs:         2,
+				Subqueries:   2,
+				NestingDepth: 3,
+				Expressions:  10,
+				Tables:       []string{"orders", "refunds"},
+				Columns:      []string{"b.id", "id", "r.id"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := lintsql.MeasureSource(tt.sql, duckdbdialect.DuckDB)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMeasureSource_SchemaQualifiedTableIsNotCTE(t *testing.T) {
+	got, err := lintsql.MeasureSource("WITH orders AS (SELECT 1 AS id) SELECT id FROM orders JOIN staging.orders s USING (id)", duckdbdialect.DuckDB)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"staging.orders"}, got.Tables)
+}
+
+func TestMeasureSource_SyntaxError(t *testing.T) {
+	_, err := lintsql.MeasureSource("SELECT FROM WHERE", duckdbdialect.DuckDB)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "syntax error")
+}
+
+func TestMeasure_Nil(t *testing.T) {
+	got := lintsql.Measure(nil, duckdbdialect.DuckDB)
+	assert.Zero(t, got.NestingDepth)
+	assert.Empty(t, got.Tables)
+}
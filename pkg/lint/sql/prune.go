@@ -0,0 +1,138 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql/internal/ast"
+)
+
+// RemoveColumns builds a fix removing the output columns named in columns
+// from the final SELECT list of stmt, matched by alias or column name,
+// ignoring case. It returns the fix and the columns it removes; the other
+// columns cannot be removed from the SQL, e.g. because they come from a star
+// or are referenced elsewhere in the query.
+//
+// Nothing is removed from queries whose output depends on the select list as
+// a whole: set operations, SELECT DISTINCT, GROUP BY ALL and positional
+// GROUP BY or ORDER BY. The last column is never removed.
+func RemoveColumns(stmt *core.SelectStmt, columns []string) (lint.Fix, []string) {
+	fix := lint.Fix{Kind: lint.FixSuggestion}
+	if stmt == nil || stmt.Body == nil || stmt.Body.Right != nil || stmt.Body.Left == nil {
+		return fix, nil
+	}
+	sc := stmt.Body.Left
+	if sc.Distinct || sc.GroupByAll || sc.OrderByAll || hasPositionalRef(sc) {
+		return fix, nil
+	}
+
+	wanted := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		wanted[strings.ToLower(c)] = true
+	}
+
+	remove := make([]bool, len(sc.Columns))
+	for i, item := range sc.Columns {
+		name := strings.ToLower(outputName(item))
+		remove[i] = name != "" && wanted[name] && item.Span.End.Offset > item.Span.Start.Offset
+	}
+
+	// Keep columns the rest of the query refers to by name: ORDER BY and
+	// QUALIFY may use aliases, and DuckDB lets later items use earlier ones
+	referenced := referencedNames(sc, remove)
+	last := -1
+	for i := range sc.Columns {
+		if remove[i] && referenced[strings.ToLower(outputName(sc.Columns[i]))] {
+			remove[i] = false
+		}
+		if !remove[i] {
+			last = i
+		}
+	}
+	if last < 0 {
+		return fix, nil
+	}
+
+	var removed []string
+	for i, item := range sc.Columns {
+		if !remove[i] {
+			continue
+		}
+		removed = append(removed, outputName(item))
+		// Items before the last kept one go with the separator that follows
+		// them; items after it with the separator that precedes them
+		if i < last {
+			fix.TextEdits = append(fix.TextEdits, lint.TextEdit{
+				Pos:    item.Span.Start,
+				EndPos: sc.Columns[i+1].Span.Start,
+			})
+		}
+	}
+	if last < len(sc.Columns)-1 {
+		fix.TextEdits = append(fix.TextEdits, lint.TextEdit{
+			Pos:    sc.Columns[last].Span.End,
+			EndPos: sc.Columns[len(sc.Columns)-1].Span.End,
+		})
+	}
+	if len(removed) > 0 {
+		fix.Description = fmt.Sprintf("Remove unused columns %s", strings.Join(removed, ", "))
+	}
+	return fix, removed
+}
+
+// outputName returns the name of the column a select item outputs, or "" if
+// it has none a downstream model could refer to.
+func outputName(item core.SelectItem) string {
+	if item.Alias != "" {
+		return item.Alias
+	}
+	if col, ok := item.Expr.(*core.ColumnRef); ok && len(col.Fields) == 0 {
+		return col.Column
+	}
+	return ""
+}
+
+// hasPositionalRef reports whether GROUP BY or ORDER BY refers to select
+// items by position.
+func hasPositionalRef(sc *core.SelectCore) bool {
+	exprs := append([]core.Expr(nil), sc.GroupBy...)
+	for _, o := range sc.OrderBy {
+		exprs = append(exprs, o.Expr)
+	}
+	for _, e := range exprs {
+		if lit, ok := e.(*core.Literal); ok && lit.Type == core.LiteralNumber {
+			return true
+		}
+	}
+	return false
+}
+
+// referencedNames returns the unqualified names the clauses of sc and the
+// select items that are kept refer to, lower-cased.
+func referencedNames(sc *core.SelectCore, remove []bool) map[string]bool {
+	names := make(map[string]bool)
+	collect := func(node any) bool {
+		if col, ok := node.(*core.ColumnRef); ok && col.Table == "" {
+			names[strings.ToLower(col.Column)] = true
+		}
+		return true
+	}
+
+	for i, item := range sc.Columns {
+		if !remove[i] {
+			ast.Walk(item.Expr, collect)
+		}
+	}
+	ast.Walk(sc.Where, collect)
+	ast.Walk(sc.Having, collect)
+	ast.Walk(sc.Qualify, collect)
+	for _, e := range sc.GroupBy {
+		ast.Walk(e, collect)
+	}
+	for _, o := range sc.OrderBy {
+		ast.Walk(o.Expr, collect)
+	}
+	return names
+}
@@ -0,0 +1,96 @@
+package sql_test
+
+import (
+	"testing"
+
+	duckdbdialect "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveColumns(t *testing.T) {
+	tests := []struct {
+		name        string
+		sql         string
+		columns     []string
+		want        string
+		wantRemoved []string
+	}{
+		{
+			name:        "middle column",
+			sql:         "SELECT id, notes, amount FROM orders",
+			columns:     []string{"notes"},
+			want:        "SELECT id, amount FROM orders",
+			wantRemoved: []string{"notes"},
+		},
+		{
+			name:        "trailing columns",
+			sql:         "SELECT\n    id,\n    amount * 2 AS doubled,\n    o.notes\nFROM orders o",
+			columns:     []string{"DOUBLED", "notes"},
+			want:        "SELECT\n    id\nFROM orders o",
+			wantRemoved: []string{"doubled", "notes"},
+		},
+		{
+			name:        "leading and trailing columns",
+			sql:         "SELECT notes, id, amount FROM orders",
+			columns:     []string{"notes", "amount"},
+			want:        "SELECT id FROM orders",
+			wantRemoved: []string{"notes", "amount"},
+		},
+		{
+			name:        "star and expressions without a name are kept",
+			sql:         "SELECT *, amount + 1 FROM orders",
+			columns:     []string{"amount"},
+			want:        "SELECT *, amount + 1 FROM orders",
+			wantRemoved: nil,
+		},
+		{
+			name:        "columns referenced by other clauses are kept",
+			sql:         "SELECT id, amount * 2 AS doubled, notes FROM orders ORDER BY doubled",
+			columns:     []string{"doubled", "notes"},
+			want:        "SELECT id, amount * 2 AS doubled FROM orders ORDER BY doubled",
+			wantRemoved: []string{"notes"},
+		},
+		{
+			name:    "the last column is never removed",
+			sql:     "SELECT notes FROM orders",
+			columns: []string{"notes"},
+			want:    "SELECT notes FROM orders",
+		},
+		{
+			name:    "positional group by",
+			sql:     "SELECT customer_id, notes, SUM(amount) AS total FROM orders GROUP BY 1, 2",
+			columns: []string{"notes"},
+			want:    "SELECT customer_id, notes, SUM(amount) AS total FROM orders GROUP BY 1, 2",
+		},
+		{
+			name:    "distinct",
+			sql:     "SELECT DISTINCT id, notes FROM orders",
+			columns: []string{"notes"},
+			want:    "SELECT DISTINCT id, notes FROM orders",
+		},
+		{
+			name:    "set operation",
+			sql:     "SELECT id, notes FROM a UNION ALL SELECT id, notes FROM b",
+			columns: []string{"notes"},
+			want:    "SELECT id, notes FROM a UNION ALL SELECT id, notes FROM b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(tt.sql, duckdbdialect.DuckDB)
+			require.NoError(t, err)
+
+			fix, removed := lintsql.RemoveColumns(stmt, tt.columns)
+			assert.Equal(t, tt.wantRemoved, removed)
+			assert.Equal(t, lint.FixSuggestion, fix.Kind)
+
+			got, _ := lint.ApplyFixes(tt.sql, []lint.Diagnostic{{Fixes: []lint.Fix{fix}}}, true)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
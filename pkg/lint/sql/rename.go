@@ -0,0 +1,178 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql/internal/ast"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+// RenameOutputColumn builds a fix renaming the output column of stmt named
+// column (ignoring case) to name: the alias of the select item outputting
+// it is replaced, and a column selected without an alias is given one. The
+// first SELECT of set operations names the columns. src is the SQL stmt was
+// parsed from and tokens its tokens. It fails if no select item outputs the
+// column, e.g. because it comes from a star.
+func RenameOutputColumn(stmt *core.SelectStmt, src string, tokens []token.Token, column, name string) (lint.Fix, error) {
+	fix := lint.Fix{Kind: lint.FixSuggestion, Description: fmt.Sprintf("Rename column %s to %s", column, name)}
+	if stmt == nil || stmt.Body == nil || stmt.Body.Left == nil {
+		return fix, fmt.Errorf("no select list")
+	}
+
+	sc := stmt.Body.Left
+	for _, item := range sc.Columns {
+		if !strings.EqualFold(outputName(item), column) {
+			continue
+		}
+		if item.Alias == "" {
+			fix.TextEdits = append(fix.TextEdits, lint.TextEdit{Pos: item.Span.End, EndPos: item.Span.End, NewText: " AS " + name})
+			return fix, nil
+		}
+		// The alias is the item's last token
+		for i := len(tokens) - 1; i >= 0; i-- {
+			tok := tokens[i]
+			if tok.Pos.Offset < item.Span.Start.Offset || tok.Pos.Offset >= item.Span.End.Offset || !isName(tok, item.Alias) {
+				continue
+			}
+			fix.TextEdits = append(fix.TextEdits, lint.TextEdit{Pos: tok.Pos, EndPos: tokenEnd(src, tok), NewText: name})
+			return fix, nil
+		}
+		return fix, fmt.Errorf("alias %s not found in the SQL", item.Alias)
+	}
+
+	for _, item := range sc.Columns {
+		if item.Star || item.TableStar != "" {
+			return fix, fmt.Errorf("column %s comes from a star: select it by name first", column)
+		}
+	}
+	return fix, fmt.Errorf("no column %s in the select list", column)
+}
+
+// RenameColumnRefs builds a fix renaming the references of stmt to the
+// column of a table to name. isTable reports whether a table of the query
+// is the one whose column is renamed: references qualified by its alias or
+// name are renamed, and unqualified references too if unqualified is set,
+// i.e. no other table of the query has the column. Select items referring
+// to the column without an alias are given its old name as alias, so the
+// query's output columns don't change.
+//
+// It returns the fix and notes on the references it leaves in place, such
+// as JOIN ... USING (column), which no rename can keep working.
+func RenameColumnRefs(stmt *core.SelectStmt, src string, tokens []token.Token, isTable func(*core.TableName) bool, column, name string, unqualified bool) (lint.Fix, []string) {
+	fix := lint.Fix{Kind: lint.FixSuggestion, Description: fmt.Sprintf("Rename references to %s to %s", column, name)}
+
+	qualifiers := make(map[string]bool)
+	stars := false
+	keep := make(map[int]bool) // End offsets of select items keeping their name
+	ast.Walk(stmt, func(node any) bool {
+		switch n := node.(type) {
+		case *core.TableName:
+			if isTable(n) {
+				if n.Alias != "" {
+					qualifiers[strings.ToLower(n.Alias)] = true
+				} else {
+					qualifiers[strings.ToLower(n.Name)] = true
+				}
+			}
+		case *core.SelectCore:
+			for _, item := range n.Columns {
+				stars = stars || item.Star || item.TableStar != ""
+				if col, ok := item.Expr.(*core.ColumnRef); ok && item.Alias == "" && len(col.Fields) == 0 && strings.EqualFold(col.Column, column) {
+					keep[item.Span.End.Offset] = true
+				}
+			}
+		}
+		return true
+	})
+	if len(qualifiers) == 0 {
+		return fix, nil
+	}
+
+	var notes []string
+	skippedUnqualified := false
+	inUsing := false
+	for i, tok := range tokens {
+		switch {
+		case tok.Type == token.USING && i+1 < len(tokens) && tokens[i+1].Type == token.LPAREN:
+			inUsing = true
+			continue
+		case tok.Type == token.RPAREN:
+			inUsing = false
+			continue
+		case !isName(tok, column):
+			continue
+		}
+
+		prev, next := tokenAt(tokens, i-1), tokenAt(tokens, i+1)
+		switch {
+		case prev.Type == token.DOT:
+			if !qualifiers[strings.ToLower(tokenAt(tokens, i-2).Literal)] {
+				continue
+			}
+		case next.Type == token.DOT || next.Type == token.LPAREN || prev.Type == token.AS || prev.Type == token.FROM || prev.Type == token.JOIN:
+			// A qualifier, a function, an alias or a table
+			continue
+		case inUsing:
+			notes = append(notes, fmt.Sprintf("JOIN ... USING (%s) left in place: rewrite it with ON", column))
+			continue
+		case !unqualified:
+			skippedUnqualified = true
+			continue
+		}
+
+		end := tokenEnd(src, tok)
+		text := name
+		if keep[end.Offset] {
+			text += " AS " + src[tok.Pos.Offset:end.Offset]
+		}
+		fix.TextEdits = append(fix.TextEdits, lint.TextEdit{Pos: tok.Pos, EndPos: end, NewText: text})
+	}
+
+	if skippedUnqualified {
+		notes = append(notes, fmt.Sprintf("unqualified references to %s left in place: another table of the query has the column", column))
+	}
+	if stars && len(fix.TextEdits) > 0 {
+		notes = append(notes, fmt.Sprintf("the query selects *: references to %s through it are not renamed", column))
+	}
+	return fix, notes
+}
+
+// isName reports whether tok is an identifier, or a keyword used as one,
+// spelling name (ignoring case).
+func isName(tok token.Token, name string) bool {
+	return tok.Type != token.STRING && tok.Type != token.NUMBER && tok.Type != token.EOF && strings.EqualFold(tok.Literal, name)
+}
+
+// tokenAt returns the token at index i, or an EOF token if there is none.
+func tokenAt(tokens []token.Token, i int) token.Token {
+	if i < 0 || i >= len(tokens) {
+		return token.Token{Type: token.EOF}
+	}
+	return tokens[i]
+}
+
+// tokenEnd returns the position after an identifier token in src: quoted
+// identifiers are longer than their literal.
+func tokenEnd(src string, tok token.Token) token.Position {
+	end := tok.Pos.Offset + len(tok.Literal)
+	if tok.Pos.Offset < len(src) && src[tok.Pos.Offset] == '"' {
+		for end = tok.Pos.Offset + 1; end < len(src); end++ {
+			if src[end] != '"' {
+				continue
+			}
+			if end+1 < len(src) && src[end+1] == '"' {
+				end++
+				continue
+			}
+			end++
+			break
+		}
+	}
+	pos := tok.Pos
+	pos.Column += end - tok.Pos.Offset
+	pos.Offset = end
+	return pos
+}
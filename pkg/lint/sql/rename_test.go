@@ -0,0 +1,136 @@
+package sql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	duckdbdialect "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	lintsql "github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameOutputColumn(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "alias",
+			sql:  "SELECT id, amount * 100 AS amount_cents FROM orders",
+			want: "SELECT id, amount * 100 AS total_cents FROM orders",
+		},
+		{
+			name: "quoted alias",
+			sql:  `SELECT id, amount * 100 "amount_cents" FROM orders`,
+			want: `SELECT id, amount * 100 total_cents FROM orders`,
+		},
+		{
+			name: "column without alias",
+			sql:  "SELECT id, o.amount_cents FROM orders o",
+			want: "SELECT id, o.amount_cents AS total_cents FROM orders o",
+		},
+		{
+			name: "first select of a set operation",
+			sql:  "SELECT amount_cents FROM a UNION ALL SELECT amount_cents FROM b",
+			want: "SELECT amount_cents AS total_cents FROM a UNION ALL SELECT amount_cents FROM b",
+		},
+		{
+			name:    "star",
+			sql:     "SELECT * FROM orders",
+			wantErr: "comes from a star",
+		},
+		{
+			name:    "missing column",
+			sql:     "SELECT id FROM orders",
+			wantErr: "no column amount_cents",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(tt.sql, duckdbdialect.DuckDB)
+			require.NoError(t, err)
+
+			fix, err := lintsql.RenameOutputColumn(stmt, tt.sql, parser.TokenizeWithDialect(tt.sql, duckdbdialect.DuckDB), "amount_cents", "total_cents")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			got, _ := lint.ApplyFixes(tt.sql, []lint.Diagnostic{{Fixes: []lint.Fix{fix}}}, true)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRenameColumnRefs(t *testing.T) {
+	isOrders := func(t *core.TableName) bool {
+		return strings.EqualFold(t.Schema, "staging") && strings.EqualFold(t.Name, "orders")
+	}
+	tests := []struct {
+		name        string
+		sql         string
+		unqualified bool
+		want        string
+		wantNotes   []string
+	}{
+		{
+			name: "qualified references keep the output column",
+			sql:  "SELECT o.id, o.amount, SUM(o.amount) AS total FROM staging.orders o GROUP BY o.id, o.amount",
+			want: "SELECT o.id, o.new_amount AS amount, SUM(o.new_amount) AS total FROM staging.orders o GROUP BY o.id, o.new_amount",
+		},
+		{
+			name: "join keys",
+			sql:  "SELECT c.id FROM customers c JOIN staging.orders ON orders.amount = c.amount",
+			want: "SELECT c.id FROM customers c JOIN staging.orders ON orders.new_amount = c.amount",
+		},
+		{
+			name:        "unqualified references",
+			sql:         "SELECT amount, amount * 2 AS doubled FROM staging.orders WHERE amount > 0 ORDER BY amount",
+			unqualified: true,
+			want:        "SELECT new_amount AS amount, new_amount * 2 AS doubled FROM staging.orders WHERE new_amount > 0 ORDER BY new_amount",
+		},
+		{
+			name:      "unqualified references are left when ambiguous",
+			sql:       "SELECT o.amount, amount FROM staging.orders o",
+			want:      "SELECT o.new_amount AS amount, amount FROM staging.orders o",
+			wantNotes: []string{"unqualified references to amount left in place: another table of the query has the column"},
+		},
+		{
+			name:        "aliases, functions and strings are not references",
+			sql:         "SELECT o.id AS amount, amount(o.id), 'amount' FROM staging.orders o",
+			unqualified: true,
+			want:        "SELECT o.id AS amount, amount(o.id), 'amount' FROM staging.orders o",
+		},
+		{
+			name:        "using",
+			sql:         "SELECT id FROM staging.orders JOIN refunds USING (amount)",
+			unqualified: true,
+			want:        "SELECT id FROM staging.orders JOIN refunds USING (amount)",
+			wantNotes:   []string{"JOIN ... USING (amount) left in place: rewrite it with ON"},
+		},
+		{
+			name: "other tables",
+			sql:  "SELECT r.amount FROM refunds r",
+			want: "SELECT r.amount FROM refunds r",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(tt.sql, duckdbdialect.DuckDB)
+			require.NoError(t, err)
+
+			fix, notes := lintsql.RenameColumnRefs(stmt, tt.sql, parser.TokenizeWithDialect(tt.sql, duckdbdialect.DuckDB), isOrders, "amount", "new_amount", tt.unqualified)
+			assert.Equal(t, tt.wantNotes, notes)
+			got, _ := lint.ApplyFixes(tt.sql, []lint.Diagnostic{{Fixes: []lint.Fix{fix}}}, true)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package rules
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/leapstack-labs/leapsql/pkg/core"
@@ -43,42 +44,60 @@ func checkUniqueTableAlias(stmt any, _ lint.DialectInfo, _ map[string]any) []lin
 		return nil
 	}
 
-	// Collect all table aliases
-	aliases := make(map[string]int) // alias -> count
+	// Collect the table references of each alias, in order of appearance
+	var order []string
+	refs := make(map[string][]core.TableRef)
 	for _, ref := range ast.CollectTableRefs(selectStmt) {
 		var alias string
 		switch t := ref.(type) {
 		case *core.TableName:
-			if t.Alias != "" {
-				alias = strings.ToLower(t.Alias)
-			}
+			alias = t.Alias
 		case *core.DerivedTable:
-			if t.Alias != "" {
-				alias = strings.ToLower(t.Alias)
-			}
+			alias = t.Alias
 		case *core.LateralTable:
-			if t.Alias != "" {
-				alias = strings.ToLower(t.Alias)
-			}
+			alias = t.Alias
 		}
-		if alias != "" {
-			aliases[alias]++
+		if alias == "" {
+			continue
 		}
+		alias = strings.ToLower(alias)
+		if _, ok := refs[alias]; !ok {
+			order = append(order, alias)
+		}
+		refs[alias] = append(refs[alias], ref)
 	}
 
-	// Find duplicates
+	// Report duplicates at their second use, pointing at the others
 	var diagnostics []lint.Diagnostic
-	for alias, count := range aliases {
-		if count > 1 {
-			diagnostics = append(diagnostics, lint.Diagnostic{
-				RuleID:           "AL04",
-				Severity:         core.SeverityError,
-				Message:          "Table alias '" + alias + "' is used " + string(rune('0'+count)) + " times; aliases must be unique",
-				DocumentationURL: lint.BuildDocURL("AL04"),
-				ImpactScore:      lint.ImpactCritical.Int(),
-				AutoFixable:      false,
+	for _, alias := range order {
+		uses := refs[alias]
+		if len(uses) < 2 {
+			continue
+		}
+		span := ast.GetTableRefSpan(uses[1])
+		related := make([]lint.RelatedInfo, 0, len(uses)-1)
+		for i, ref := range uses {
+			if i == 1 {
+				continue
+			}
+			other := ast.GetTableRefSpan(ref)
+			related = append(related, lint.RelatedInfo{
+				Pos:     other.Start,
+				EndPos:  other.End,
+				Message: fmt.Sprintf("Alias '%s' is also used here", alias),
 			})
 		}
+		diagnostics = append(diagnostics, lint.Diagnostic{
+			RuleID:           "AL04",
+			Severity:         core.SeverityError,
+			Message:          fmt.Sprintf("Table alias '%s' is used %d times; aliases must be unique", alias, len(uses)),
+			Pos:              span.Start,
+			EndPos:           span.End,
+			RelatedInfo:      related,
+			DocumentationURL: lint.BuildDocURL("AL04"),
+			ImpactScore:      lint.ImpactCritical.Int(),
+			AutoFixable:      false,
+		})
 	}
 	return diagnostics
 }
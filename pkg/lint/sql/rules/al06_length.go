@@ -20,8 +20,11 @@ var AliasLength = sql.RuleDef{
 	Group:       "aliasing",
 	Description: "Alias length should be between min and max characters.",
 	Severity:    core.SeverityInfo,
-	ConfigKeys:  []string{"min_length", "max_length"},
-	Check:       checkAliasLength,
+	Options: []core.RuleOption{
+		{Name: "min_length", Type: core.OptionInt, Default: defaultMinLength, Min: 1, Description: "Shortest alias allowed"},
+		{Name: "max_length", Type: core.OptionInt, Default: defaultMaxLength, Min: 1, Description: "Longest alias allowed"},
+	},
+	Check: checkAliasLength,
 
 	Rationale: `Overly short aliases (single letters) lack meaning and make queries 
 harder to understand. Overly long aliases add verbosity without improving clarity 
@@ -22,8 +22,11 @@ var ForbidAlias = sql.RuleDef{
 	Group:       "aliasing",
 	Description: "Forbidden alias patterns (e.g., single letters, t1/t2).",
 	Severity:    core.SeverityWarning,
-	ConfigKeys:  []string{"forbidden_patterns", "forbidden_names"},
-	Check:       checkForbidAlias,
+	Options: []core.RuleOption{
+		{Name: "forbidden_patterns", Type: core.OptionStringList, Default: defaultForbiddenPatterns, Description: "Regular expressions matching forbidden aliases"},
+		{Name: "forbidden_names", Type: core.OptionStringList, Description: "Aliases forbidden by name"},
+	},
+	Check: checkForbidAlias,
 
 	Rationale: `Generic aliases like single letters (a, b, c) or numbered tables (t1, t2) 
 provide no semantic meaning. They make queries harder to understand and maintain, 
@@ -14,12 +14,18 @@ import (
 
 // Helper to run analysis and filter by rule ID
 func runRule(t *testing.T, sql string, ruleID string) []lint.Diagnostic {
+	t.Helper()
+	return runRuleWithSchema(t, sql, ruleID, nil)
+}
+
+// Helper to run analysis with the columns of upstream tables and filter by rule ID
+func runRuleWithSchema(t *testing.T, sql string, ruleID string, schema lint.Schema) []lint.Diagnostic {
 	t.Helper()
 	stmt, err := parser.ParseWithDialect(sql, duckdbdialect.DuckDB)
 	require.NoError(t, err)
 
 	analyzer := lint.NewAnalyzerWithRegistry(lint.NewConfig(), "duckdb")
-	diags := analyzer.AnalyzeWithRegistryRules(stmt, duckdbdialect.DuckDB)
+	diags := analyzer.AnalyzeWithRegistryRules(stmt, lint.WithSchema(duckdbdialect.DuckDB, schema))
 
 	var filtered []lint.Diagnostic
 	for _, d := range diags {
@@ -130,6 +136,20 @@ func TestAL04_UniqueTableAlias(t *testing.T) {
 	}
 }
 
+func TestAL04_RelatedLocations(t *testing.T) {
+	diags := runRule(t, "SELECT * FROM users u JOIN orders u ON u.id = u.user_id", "AL04")
+	require.Len(t, diags, 1)
+
+	d := diags[0]
+	assert.Equal(t, "Table alias 'u' is used 2 times; aliases must be unique", d.Message)
+	assert.Equal(t, 1, d.Pos.Line)
+	assert.Equal(t, 28, d.Pos.Column, "points at the second use")
+	require.Len(t, d.RelatedInfo, 1)
+	assert.Equal(t, 15, d.RelatedInfo[0].Pos.Column, "points at the first use")
+	assert.Less(t, d.RelatedInfo[0].Pos.Offset, d.RelatedInfo[0].EndPos.Offset)
+	assert.Empty(t, d.RelatedInfo[0].FilePath)
+}
+
 func TestAL05_UnusedAlias(t *testing.T) {
 	tests := []struct {
 		name     string
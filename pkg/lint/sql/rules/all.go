@@ -37,6 +37,9 @@ package rules
 //   - AM06: Column Refs - Ambiguous column references
 //   - AM08: Join Condition - Missing join condition
 //   - AM09: Order By Limit - ORDER BY without LIMIT
+//   - AM11: Implicit Coercion - Comparisons between mismatched types
+//   - AM12: Not In Null - NOT IN and != ALL against subqueries that may return NULL
+//   - AM13: Union Column Names - Set operation columns named differently by position
 //
 // Convention rules:
 //   - CV01: Not Equal - Prefer != over <>
@@ -45,6 +48,9 @@ package rules
 //   - CV05: Is Null - Use IS NULL instead of = NULL
 //   - CV08: Left Join - Prefer LEFT JOIN over RIGHT JOIN
 //   - CV09: Blocked Words - Block dangerous SQL keywords
+//   - CV10: Portable Functions - Functions must exist in the portability profile's dialects
+//   - CV15: Column Naming - Aliases are snake_case and carry date, boolean and ID affixes
+//   - CV16: Casing - Keywords, function names and identifiers follow the configured casing
 //
 // References rules:
 //   - RF02: Qualification - Qualify columns in multi-table queries
@@ -52,15 +52,23 @@ func checkColumnCountMismatch(stmt any, _ lint.DialectInfo, _ map[string]any) []
 	// Count columns in each core
 	var diagnostics []lint.Diagnostic
 	firstCount := countColumnsAM04(cores[0])
+	firstList := ast.GetSelectListSpan(cores[0])
 
 	for i := 1; i < len(cores); i++ {
 		count := countColumnsAM04(cores[i])
 		if count != firstCount {
+			list := ast.GetSelectListSpan(cores[i])
 			diagnostics = append(diagnostics, lint.Diagnostic{
-				RuleID:           "AM04",
-				Severity:         core.SeverityError,
-				Message:          fmt.Sprintf("Column count mismatch in set operation: first query has %d columns, query %d has %d columns", firstCount, i+1, count),
-				Pos:              cores[i].Span.Start,
+				RuleID:   "AM04",
+				Severity: core.SeverityError,
+				Message:  fmt.Sprintf("Column count mismatch in set operation: first query has %d columns, query %d has %d columns", firstCount, i+1, count),
+				Pos:      list.Start,
+				EndPos:   list.End,
+				RelatedInfo: []lint.RelatedInfo{{
+					Pos:     firstList.Start,
+					EndPos:  firstList.End,
+					Message: fmt.Sprintf("First query selects %d columns", firstCount),
+				}},
 				DocumentationURL: lint.BuildDocURL("AM04"),
 				ImpactScore:      lint.ImpactCritical.Int(),
 				AutoFixable:      false,
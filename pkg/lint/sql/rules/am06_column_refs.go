@@ -1,6 +1,8 @@
 package rules
 
 import (
+	"strings"
+
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/lint"
 	"github.com/leapstack-labs/leapsql/pkg/lint/sql"
@@ -22,7 +24,10 @@ var AmbiguousColumnRef = sql.RuleDef{
 
 	Rationale: `When multiple tables are joined, unqualified column names may exist in 
 more than one table. The database may pick an unexpected source, or error out. 
-Qualifying columns prevents ambiguity and makes the query self-documenting.`,
+Qualifying columns prevents ambiguity and makes the query self-documenting.
+
+When the columns of every joined table are known, from CTEs, subqueries and the
+output of upstream models, only columns that exist in more than one table are reported.`,
 
 	BadExample: `SELECT name, email, created_at
 FROM customers c
@@ -35,7 +40,7 @@ JOIN orders o ON o.customer_id = c.id`,
 	Fix: "Prefix column references with the table alias (e.g., c.name instead of name).",
 }
 
-func checkAmbiguousColumnRef(stmt any, _ lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
+func checkAmbiguousColumnRef(stmt any, dialect lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
 	selectStmt, ok := stmt.(*core.SelectStmt)
 	if !ok {
 		return nil
@@ -55,19 +60,59 @@ func checkAmbiguousColumnRef(stmt any, _ lint.DialectInfo, _ map[string]any) []l
 		return nil
 	}
 
+	// Columns of CTEs, derived tables and tables in the schema are known, so
+	// references to them can be resolved
+	sources := ast.FromSources(selectStmt, selectCore, dialect)
+
 	// Find unqualified column references
 	var diagnostics []lint.Diagnostic
 	for _, colRef := range ast.CollectColumnRefs(selectStmt) {
-		if colRef.Table == "" {
-			diagnostics = append(diagnostics, lint.Diagnostic{
-				RuleID:           "AM06",
-				Severity:         core.SeverityWarning,
-				Message:          "Column '" + colRef.Column + "' is unqualified and may be ambiguous with multiple tables; consider adding table qualifier",
-				DocumentationURL: lint.BuildDocURL("AM06"),
-				ImpactScore:      lint.ImpactMedium.Int(),
-				AutoFixable:      false,
-			})
+		if colRef.Table != "" {
+			continue
 		}
+
+		message := "Column '" + colRef.Column + "' is unqualified and may be ambiguous with multiple tables; consider adding table qualifier"
+		matches, complete := ast.ColumnSources(sources, colRef.Column, dialect)
+		switch {
+		case len(matches) > 1:
+			if isJoinedColumn(selectCore, colRef.Column, dialect) {
+				continue
+			}
+			names := make([]string, len(matches))
+			for i, m := range matches {
+				names[i] = "'" + m.Name + "'"
+			}
+			message = "Column '" + colRef.Column + "' is ambiguous: it exists in " + strings.Join(names, " and ")
+		case complete:
+			// The column resolves to a single source, or to none of them,
+			// such as a select list alias
+			continue
+		}
+
+		diagnostics = append(diagnostics, lint.Diagnostic{
+			RuleID:           "AM06",
+			Severity:         core.SeverityWarning,
+			Message:          message,
+			DocumentationURL: lint.BuildDocURL("AM06"),
+			ImpactScore:      lint.ImpactMedium.Int(),
+			AutoFixable:      false,
+		})
 	}
 	return diagnostics
 }
+
+// isJoinedColumn reports whether a column is joined with USING or a NATURAL
+// join, which merges the column of both tables into one.
+func isJoinedColumn(sc *core.SelectCore, column string, dialect lint.DialectInfo) bool {
+	for _, j := range sc.From.Joins {
+		if j.Natural {
+			return true
+		}
+		for _, col := range j.Using {
+			if ast.SameName(col, column, dialect) {
+				return true
+			}
+		}
+	}
+	return false
+}
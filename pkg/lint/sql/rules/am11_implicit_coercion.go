@@ -0,0 +1,264 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql/internal/ast"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+func init() {
+	sql.Register(ImplicitCoercion)
+}
+
+// ImplicitCoercion flags comparisons between values of mismatched types.
+var ImplicitCoercion = sql.RuleDef{
+	ID:          "AM11",
+	Name:        "ambiguous.implicit_coercion",
+	Group:       "ambiguous",
+	Description: "Comparisons and join conditions should not compare values of mismatched types.",
+	Severity:    core.SeverityWarning,
+	Options: []core.RuleOption{
+		{Name: "strictness", Type: core.OptionString, Default: "loose", Enum: []string{"loose", "strict"}, Description: "loose flags numbers, strings and booleans compared to other types; strict also flags dates compared to timestamps or strings"},
+		{Name: "strict_dialects", Type: core.OptionStringList, Description: "Dialects checked with strict strictness whatever the strictness option"},
+	},
+	Check: checkImplicitCoercion,
+
+	Rationale: `Comparing values of different types makes the database coerce one side to the
+type of the other. Which side depends on the dialect: a string key compared to an
+integer key may be cast to a number, failing on the first non-numeric value, or the
+integer cast to a string, matching '01' against 1 differently. Casting a column also
+keeps the database from using its indexes, partitions or clustering. The rule knows
+the types of literals, casts and the functions the dialect documents; comparisons
+involving columns of unknown type are not flagged.`,
+
+	BadExample: `SELECT *
+FROM orders o
+JOIN customers c ON CAST(o.customer_ref AS VARCHAR) = CAST(c.id AS INTEGER)
+WHERE CAST(o.ordered_at AS DATE) = CURRENT_TIMESTAMP`,
+
+	GoodExample: `SELECT *
+FROM orders o
+JOIN customers c ON CAST(o.customer_ref AS INTEGER) = CAST(c.id AS INTEGER)
+WHERE CAST(o.ordered_at AS DATE) = CURRENT_DATE`,
+
+	Fix: "Cast one side explicitly to the type of the other, preferably the side that is not an indexed or partitioned column.",
+}
+
+// typeFamily groups the types that compare without coercion.
+type typeFamily int
+
+const (
+	familyUnknown typeFamily = iota
+	familyNumber
+	familyString
+	familyDate
+	familyTimestamp
+	familyBoolean
+)
+
+func (f typeFamily) String() string {
+	switch f {
+	case familyNumber:
+		return "number"
+	case familyString:
+		return "string"
+	case familyDate:
+		return "date"
+	case familyTimestamp:
+		return "timestamp"
+	case familyBoolean:
+		return "boolean"
+	default:
+		return "unknown"
+	}
+}
+
+var typeFamilies = map[string]typeFamily{
+	"TINYINT": familyNumber, "SMALLINT": familyNumber, "INT": familyNumber, "INTEGER": familyNumber,
+	"BIGINT": familyNumber, "HUGEINT": familyNumber, "UTINYINT": familyNumber, "USMALLINT": familyNumber,
+	"UINTEGER": familyNumber, "UBIGINT": familyNumber, "UHUGEINT": familyNumber, "BYTEINT": familyNumber,
+	"INT1": familyNumber, "INT2": familyNumber, "INT4": familyNumber, "INT8": familyNumber,
+	"DECIMAL": familyNumber, "NUMERIC": familyNumber, "NUMBER": familyNumber, "REAL": familyNumber,
+	"FLOAT": familyNumber, "FLOAT4": familyNumber, "FLOAT8": familyNumber, "DOUBLE": familyNumber, "DOUBLE PRECISION": familyNumber,
+	"VARCHAR": familyString, "CHAR": familyString, "CHARACTER": familyString, "CHARACTER VARYING": familyString,
+	"TEXT": familyString, "STRING": familyString, "BPCHAR": familyString, "NVARCHAR": familyString, "NCHAR": familyString,
+	"DATE": familyDate, "BOOLEAN": familyBoolean, "BOOL": familyBoolean,
+}
+
+// familyOfType returns the family of a type name, e.g. VARCHAR(255).
+func familyOfType(typeName string) typeFamily {
+	name := strings.ToUpper(strings.TrimSpace(typeName))
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = strings.TrimSpace(name[:i])
+	}
+	if strings.HasPrefix(name, "TIMESTAMP") || name == "DATETIME" {
+		return familyTimestamp
+	}
+	return typeFamilies[name]
+}
+
+var signatureReturnRe = regexp.MustCompile(`->\s*(.+)$`)
+
+// familyOfFunction returns the family of the values a function returns, as
+// documented by the dialect. Functions whose signatures return different
+// families are unknown.
+func familyOfFunction(fn *core.FuncCall, d lint.DialectInfo) typeFamily {
+	doc, ok := lint.FunctionDocOf(d, fn.Name)
+	if !ok {
+		return familyUnknown
+	}
+	if len(doc.Signatures) == 0 {
+		return familyOfType(doc.ReturnType)
+	}
+	family := familyUnknown
+	for i, sig := range doc.Signatures {
+		m := signatureReturnRe.FindStringSubmatch(sig)
+		if m == nil {
+			return familyUnknown
+		}
+		f := familyOfType(m[1])
+		if i > 0 && f != family {
+			return familyUnknown
+		}
+		family = f
+	}
+	return family
+}
+
+// familyOf infers the family of the values expr evaluates to.
+func familyOf(expr core.Expr, d lint.DialectInfo) typeFamily {
+	switch e := expr.(type) {
+	case *core.ParenExpr:
+		return familyOf(e.Expr, d)
+	case *core.Literal:
+		switch e.Type {
+		case core.LiteralNumber:
+			return familyNumber
+		case core.LiteralString:
+			return familyString
+		case core.LiteralBool:
+			return familyBoolean
+		}
+	case *core.CastExpr:
+		return familyOfType(e.TypeName)
+	case *core.FuncCall:
+		return familyOfFunction(e, d)
+	case *core.BinaryExpr:
+		switch e.Op {
+		case token.PLUS, token.MINUS, token.STAR, token.SLASH, token.MOD:
+			if familyOf(e.Left, d) == familyNumber && familyOf(e.Right, d) == familyNumber {
+				return familyNumber
+			}
+		case token.DPIPE:
+			return familyString
+		case token.EQ, token.NE, token.LT, token.GT, token.LE, token.GE, token.AND, token.OR:
+			return familyBoolean
+		}
+	case *core.InExpr, *core.BetweenExpr, *core.IsNullExpr, *core.IsBoolExpr, *core.LikeExpr, *core.ExistsExpr:
+		return familyBoolean
+	}
+	return familyUnknown
+}
+
+// coerces reports whether comparing values of families a and b coerces one
+// of them. Outside strict mode, dates compare to timestamps and to strings,
+// which databases parse as date constants.
+func coerces(a, b typeFamily, strict bool) bool {
+	if a == familyUnknown || b == familyUnknown || a == b {
+		return false
+	}
+	if strict {
+		return true
+	}
+	temporal := func(f typeFamily) bool { return f == familyDate || f == familyTimestamp }
+	switch {
+	case temporal(a) && temporal(b):
+		return false
+	case temporal(a) && b == familyString, a == familyString && temporal(b):
+		return false
+	}
+	return true
+}
+
+func checkImplicitCoercion(stmt any, d lint.DialectInfo, opts map[string]any) []lint.Diagnostic {
+	selectStmt, ok := stmt.(*core.SelectStmt)
+	if !ok {
+		return nil
+	}
+
+	strict := lint.GetStringOption(opts, "strictness", "loose") == "strict"
+	if d != nil {
+		for _, name := range lint.GetStringSliceOption(opts, "strict_dialects", nil) {
+			if strings.EqualFold(name, d.GetName()) {
+				strict = true
+			}
+		}
+	}
+
+	var diagnostics []lint.Diagnostic
+	compare := func(left, right core.Expr, pos token.Position) {
+		l, r := familyOf(left, d), familyOf(right, d)
+		if !coerces(l, r, strict) {
+			return
+		}
+		diagnostics = append(diagnostics, lint.Diagnostic{
+			RuleID:           "AM11",
+			Severity:         core.SeverityWarning,
+			Message:          "Comparison of " + l.String() + " and " + r.String() + " values relies on implicit type coercion",
+			Pos:              pos,
+			DocumentationURL: lint.BuildDocURL("AM11"),
+			ImpactScore:      lint.ImpactMedium.Int(),
+			AutoFixable:      false,
+		})
+	}
+
+	// check inspects the comparisons of an expression, leaving out those of
+	// subqueries, which are checked with their own SELECT.
+	check := func(expr core.Expr, pos token.Position) {
+		ast.Walk(expr, func(node any) bool {
+			switch n := node.(type) {
+			case *core.SelectStmt:
+				return false
+			case *core.BinaryExpr:
+				switch n.Op {
+				case token.EQ, token.NE, token.LT, token.GT, token.LE, token.GE:
+					compare(n.Left, n.Right, pos)
+				}
+			case *core.InExpr:
+				for _, v := range n.Values {
+					before := len(diagnostics)
+					compare(n.Expr, v, pos)
+					if len(diagnostics) > before {
+						break
+					}
+				}
+			case *core.BetweenExpr:
+				compare(n.Expr, n.Low, pos)
+				compare(n.Expr, n.High, pos)
+			}
+			return true
+		})
+	}
+
+	for _, selectCore := range ast.CollectSelectCores(selectStmt) {
+		pos := ast.GetSelectCorePosition(selectCore)
+		for _, col := range selectCore.Columns {
+			check(col.Expr, pos)
+		}
+		if selectCore.From != nil {
+			for _, join := range selectCore.From.Joins {
+				check(join.Condition, join.Span.Start)
+			}
+		}
+		check(selectCore.Where, pos)
+		check(selectCore.Having, pos)
+		check(selectCore.Qualify, pos)
+	}
+
+	return diagnostics
+}
@@ -0,0 +1,238 @@
+package rules
+
+import (
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql/internal/ast"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+func init() {
+	sql.Register(NotInNull)
+}
+
+// NotInNull flags NOT IN and != ALL against subqueries that may return NULL.
+var NotInNull = sql.RuleDef{
+	ID:          "AM12",
+	Name:        "ambiguous.not_in_null",
+	Group:       "ambiguous",
+	Description: "NOT IN and != ALL should not compare to subqueries or lists that may contain NULL.",
+	Severity:    core.SeverityWarning,
+	Check:       checkNotInNull,
+
+	Rationale: `x NOT IN (a, b, NULL) means x != a AND x != b AND x != NULL, and x != NULL is
+never true. As soon as the subquery of a NOT IN or != ALL returns a single NULL, the
+condition filters out every row, and the query silently returns nothing. NOT EXISTS
+with a correlated subquery ignores NULLs and returns the rows that have no match, which
+is almost always what was meant. Subqueries whose column can't be NULL, because it is
+a literal, a COUNT or filtered with IS NOT NULL, are not flagged.`,
+
+	BadExample: `SELECT *
+FROM customers c
+WHERE c.id NOT IN (SELECT customer_id FROM orders)`,
+
+	GoodExample: `SELECT *
+FROM customers c
+WHERE NOT EXISTS (SELECT 1 FROM orders WHERE customer_id = c.id)`,
+
+	Fix: "Rewrite the condition with NOT EXISTS, or filter the subquery's column with IS NOT NULL.",
+}
+
+func checkNotInNull(stmt any, d lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
+	selectStmt, ok := stmt.(*core.SelectStmt)
+	if !ok {
+		return nil
+	}
+
+	var diagnostics []lint.Diagnostic
+	report := func(message string, pos, end token.Position) *lint.Diagnostic {
+		diagnostics = append(diagnostics, lint.Diagnostic{
+			RuleID:           "AM12",
+			Severity:         core.SeverityWarning,
+			Message:          message,
+			Pos:              pos,
+			EndPos:           end,
+			DocumentationURL: lint.BuildDocURL("AM12"),
+			ImpactScore:      lint.ImpactHigh.Int(),
+			AutoFixable:      false,
+		})
+		return &diagnostics[len(diagnostics)-1]
+	}
+
+	for _, selectCore := range ast.CollectSelectCores(selectStmt) {
+		outer := selectCore
+		exprs := []core.Expr{outer.Where, outer.Having, outer.Qualify}
+		for _, col := range outer.Columns {
+			exprs = append(exprs, col.Expr)
+		}
+		if outer.From != nil {
+			for _, join := range outer.From.Joins {
+				exprs = append(exprs, join.Condition)
+			}
+		}
+
+		for _, expr := range exprs {
+			// Subqueries are checked with their own SELECT
+			ast.Walk(expr, func(node any) bool {
+				switch n := node.(type) {
+				case *core.SelectStmt:
+					return false
+				case *core.InExpr:
+					if !n.Not {
+						break
+					}
+					if n.Query != nil {
+						if !nullableSubqueryAM12(n.Query) {
+							break
+						}
+						diag := report("NOT IN matches no rows when its subquery returns a NULL; use NOT EXISTS", n.Span.Start, n.Span.End)
+						// NOT EXISTS keeps the rows whose operand is NULL,
+						// which NOT IN drops, so the rewrite is a suggestion.
+						if fix, ok := notExistsFixAM12(n, selectStmt, outer, d); ok {
+							diag.Fixes = []lint.Fix{fix}
+						}
+					} else if containsNullAM12(n.Values) {
+						report("NOT IN matches no rows when its list contains NULL", n.Span.Start, n.Span.End)
+					}
+				case *core.BinaryExpr:
+					sub, ok := n.Right.(*core.SubqueryExpr)
+					if ok && n.Op == token.NE && strings.EqualFold(sub.Quantifier, "ALL") && nullableSubqueryAM12(sub.Select) {
+						report("!= ALL matches no rows when its subquery returns a NULL; use NOT EXISTS", ast.GetSelectCorePosition(outer), token.Position{})
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+// nullableSubqueryAM12 reports whether the column a subquery returns may be
+// NULL. Subqueries whose column can't be resolved may be.
+func nullableSubqueryAM12(stmt *core.SelectStmt) bool {
+	if stmt == nil || stmt.Body == nil || stmt.Body.Op != core.SetOpNone {
+		return true
+	}
+	sc := stmt.Body.Left
+	if sc == nil || len(sc.Columns) != 1 || sc.Columns[0].Expr == nil {
+		return true
+	}
+	expr := sc.Columns[0].Expr
+	if nonNullExprAM12(expr) {
+		return false
+	}
+	col, ok := expr.(*core.ColumnRef)
+	return !ok || !filtersNullAM12(sc.Where, col)
+}
+
+// nonNullExprAM12 reports whether expr never evaluates to NULL.
+func nonNullExprAM12(expr core.Expr) bool {
+	switch e := expr.(type) {
+	case *core.ParenExpr:
+		return nonNullExprAM12(e.Expr)
+	case *core.Literal:
+		return e.Type != core.LiteralNull
+	case *core.FuncCall:
+		switch strings.ToUpper(e.Name) {
+		case "COUNT", "COUNT_IF":
+			return true
+		case "COALESCE", "IFNULL", "NVL":
+			return len(e.Args) > 0 && nonNullExprAM12(e.Args[len(e.Args)-1])
+		}
+	}
+	return false
+}
+
+// filtersNullAM12 reports whether a WHERE condition requires col IS NOT NULL.
+func filtersNullAM12(where core.Expr, col *core.ColumnRef) bool {
+	switch e := where.(type) {
+	case *core.ParenExpr:
+		return filtersNullAM12(e.Expr, col)
+	case *core.BinaryExpr:
+		if e.Op == token.AND {
+			return filtersNullAM12(e.Left, col) || filtersNullAM12(e.Right, col)
+		}
+	case *core.IsNullExpr:
+		ref, ok := e.Expr.(*core.ColumnRef)
+		return ok && e.Not && strings.EqualFold(ref.Column, col.Column) &&
+			(ref.Table == "" || col.Table == "" || strings.EqualFold(ref.Table, col.Table))
+	}
+	return false
+}
+
+func containsNullAM12(values []core.Expr) bool {
+	for _, v := range values {
+		if lit, ok := v.(*core.Literal); ok && lit.Type == core.LiteralNull {
+			return true
+		}
+	}
+	return false
+}
+
+// notExistsFixAM12 rewrites x NOT IN (SELECT col FROM t WHERE w) as
+// NOT EXISTS (SELECT 1 FROM t WHERE w AND col = x). The fix is offered for
+// subqueries of a single SELECT with no clause after WHERE, and operands the
+// correlation can't confuse with the subquery's columns.
+func notExistsFixAM12(in *core.InExpr, stmt *core.SelectStmt, outer *core.SelectCore, d lint.DialectInfo) (lint.Fix, bool) {
+	q := in.Query
+	if !in.Span.Start.IsValid() || q.With != nil || q.Body == nil || q.Body.Op != core.SetOpNone {
+		return lint.Fix{}, false
+	}
+	sc := q.Body.Left
+	if sc == nil || sc.Distinct || sc.From == nil || len(sc.GroupBy) > 0 || sc.GroupByAll || sc.Having != nil ||
+		len(sc.Windows) > 0 || sc.Qualify != nil || len(sc.OrderBy) > 0 || sc.OrderByAll ||
+		sc.Limit != nil || sc.Offset != nil || sc.Fetch != nil || len(sc.Extensions) > 0 ||
+		!sc.Span.Start.IsValid() || !sc.ColumnsSpan.Start.IsValid() {
+		return lint.Fix{}, false
+	}
+	inner, ok := sc.Columns[0].Expr.(*core.ColumnRef)
+	if !ok {
+		return lint.Fix{}, false
+	}
+	operand, ok := in.Expr.(*core.ColumnRef)
+	if !ok {
+		return lint.Fix{}, false
+	}
+
+	// Qualify the operand, so it refers to the outer query inside the subquery
+	qualifier := operand.Table
+	if qualifier == "" {
+		sources := ast.FromSources(stmt, outer, d)
+		if len(sources) != 1 || sources[0].Name == "" {
+			return lint.Fix{}, false
+		}
+		qualifier = sources[0].Name
+		qualified := *operand
+		qualified.Table = qualifier
+		operand = &qualified
+	}
+	for _, source := range ast.FromSources(q, sc, d) {
+		if source.Name == "" || ast.SameName(source.Name, qualifier, d) {
+			return lint.Fix{}, false
+		}
+	}
+
+	correlation := ast.ColumnRefSQL(inner) + " = " + ast.ColumnRefSQL(operand)
+	if sc.Where == nil {
+		correlation = " WHERE " + correlation
+	} else if bin, ok := sc.Where.(*core.BinaryExpr); ok && bin.Op == token.OR {
+		// AND would bind to the last disjunct only
+		return lint.Fix{}, false
+	} else {
+		correlation = " AND " + correlation
+	}
+
+	return lint.Fix{
+		Description: "Rewrite as NOT EXISTS",
+		Kind:        lint.FixSuggestion,
+		TextEdits: []lint.TextEdit{
+			{Pos: in.Span.Start, EndPos: sc.Span.Start, NewText: "NOT EXISTS ("},
+			{Pos: sc.ColumnsSpan.Start, EndPos: sc.ColumnsSpan.End, NewText: "1"},
+			{Pos: sc.Span.End, EndPos: sc.Span.End, NewText: correlation},
+		},
+	}, true
+}
@@ -0,0 +1,131 @@
+package rules
+
+import (
+	"fmt"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql/internal/ast"
+)
+
+func init() {
+	sql.Register(UnionColumnNames)
+}
+
+// UnionColumnNames warns about set operation columns named differently in
+// each query.
+var UnionColumnNames = sql.RuleDef{
+	ID:          "AM13",
+	Name:        "ambiguous.union_column_names",
+	Group:       "ambiguous",
+	Description: "Columns combined by a set operation should have the same name in every query.",
+	Severity:    core.SeverityWarning,
+	Check:       checkUnionColumnNames,
+
+	Rationale: `UNION, INTERSECT and EXCEPT match columns by position, not by name, and name
+the result's columns after the first query. A column named differently in a later query
+(id in one, user_id in the other) is often a mapping mistake: columns listed in another
+order, or a column missing with another one taking its place. The query still runs, and
+the values land in the wrong column. Aliasing the column to the name of the first query
+confirms the mapping. Expressions without an alias are not checked, and DuckDB's
+UNION BY NAME, which matches columns by name, is skipped.`,
+
+	BadExample: `SELECT id, email, created_at FROM customers
+UNION ALL
+SELECT user_id, created_at, email FROM users`,
+
+	GoodExample: `SELECT id, email, created_at FROM customers
+UNION ALL
+SELECT user_id AS id, email, created_at FROM users`,
+
+	Fix: "Reorder the columns so they line up, or alias each column to the name the first query gives it.",
+}
+
+func checkUnionColumnNames(stmt any, d lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
+	selectStmt, ok := stmt.(*core.SelectStmt)
+	if !ok || selectStmt == nil {
+		return nil
+	}
+
+	// Chained set operations nest in the Right of their body; check each
+	// chain once, from its first query
+	bodies := ast.CollectSelectBodies(selectStmt)
+	chained := make(map[*core.SelectBody]bool)
+	for _, body := range bodies {
+		if body != nil && body.Right != nil {
+			chained[body.Right] = true
+		}
+	}
+
+	var diagnostics []lint.Diagnostic
+	for _, body := range bodies {
+		if body == nil || body.Op == core.SetOpNone || chained[body] {
+			continue
+		}
+		cores, ok := setOperationQueriesAM13(body)
+		if !ok {
+			continue
+		}
+
+		first := cores[0]
+		for i, sc := range cores[1:] {
+			for pos := 0; pos < len(first.Columns) && pos < len(sc.Columns); pos++ {
+				want, got := &first.Columns[pos], &sc.Columns[pos]
+				wantName, gotName := outputNameAM13(want), outputNameAM13(got)
+				if wantName == "" || gotName == "" || ast.SameName(wantName, gotName, d) {
+					continue
+				}
+				diagnostics = append(diagnostics, lint.Diagnostic{
+					RuleID:   "AM13",
+					Severity: core.SeverityWarning,
+					Message: fmt.Sprintf("Column %d of query %d is named '%s' but the set operation names it '%s'; alias it to confirm the mapping",
+						pos+1, i+2, gotName, wantName),
+					Pos:    got.Span.Start,
+					EndPos: got.Span.End,
+					RelatedInfo: []lint.RelatedInfo{{
+						Pos:     want.Span.Start,
+						EndPos:  want.Span.End,
+						Message: fmt.Sprintf("The first query names column %d '%s'", pos+1, wantName),
+					}},
+					DocumentationURL: lint.BuildDocURL("AM13"),
+					ImpactScore:      lint.ImpactMedium.Int(),
+					AutoFixable:      false,
+				})
+			}
+		}
+	}
+	return diagnostics
+}
+
+// setOperationQueriesAM13 returns the queries of a chain of set operations,
+// or false if columns can't be matched by position: the chain has a
+// BY NAME operation, or a query selects *.
+func setOperationQueriesAM13(body *core.SelectBody) ([]*core.SelectCore, bool) {
+	var cores []*core.SelectCore
+	for b := body; b != nil; b = b.Right {
+		if b.ByName || b.Left == nil {
+			return nil, false
+		}
+		for _, col := range b.Left.Columns {
+			if col.Star || col.TableStar != "" {
+				return nil, false
+			}
+		}
+		cores = append(cores, b.Left)
+	}
+	return cores, len(cores) > 1
+}
+
+// outputNameAM13 returns the name of the column a select item outputs: its
+// alias, or the name of the column it selects. Unaliased expressions have
+// none.
+func outputNameAM13(item *core.SelectItem) string {
+	if item.Alias != "" {
+		return item.Alias
+	}
+	if col, ok := item.Expr.(*core.ColumnRef); ok && len(col.Fields) == 0 {
+		return col.Column
+	}
+	return ""
+}
@@ -4,8 +4,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	duckdbdialect "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
 	_ "github.com/leapstack-labs/leapsql/pkg/lint/sql/rules" // register rules
+	"github.com/leapstack-labs/leapsql/pkg/parser"
 )
 
 func TestAM01_DistinctWithGroupBy(t *testing.T) {
@@ -118,6 +122,20 @@ func TestAM04_ColumnCountMismatch(t *testing.T) {
 	}
 }
 
+func TestAM04_RelatedLocations(t *testing.T) {
+	diags := runRule(t, "SELECT id, name FROM users\nUNION ALL\nSELECT id FROM customers", "AM04")
+	require.Len(t, diags, 1)
+
+	d := diags[0]
+	assert.Equal(t, 3, d.Pos.Line, "points at the second SELECT list")
+	assert.Equal(t, 8, d.Pos.Column)
+	require.Len(t, d.RelatedInfo, 1)
+	assert.Equal(t, "First query selects 2 columns", d.RelatedInfo[0].Message)
+	assert.Equal(t, 1, d.RelatedInfo[0].Pos.Line, "points at the first SELECT list")
+	assert.Equal(t, 8, d.RelatedInfo[0].Pos.Column)
+	assert.Equal(t, 16, d.RelatedInfo[0].EndPos.Column)
+}
+
 func TestAM05_ImplicitJoin(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -188,6 +206,63 @@ func TestAM06_AmbiguousColumnRef(t *testing.T) {
 	}
 }
 
+func TestAM06_AmbiguousColumnRef_ResolvedColumns(t *testing.T) {
+	schema := lint.Schema{
+		"staging.customers": {"id", "name"},
+		"staging.orders":    {"id", "customer_id", "amount"},
+	}
+
+	tests := []struct {
+		name   string
+		sql    string
+		schema lint.Schema
+		want   []string
+	}{
+		{
+			name:   "upstream columns resolve unique columns",
+			sql:    "SELECT id, name, amount FROM staging.customers c JOIN staging.orders o ON c.id = o.customer_id",
+			schema: schema,
+			want:   []string{"Column 'id' is ambiguous: it exists in 'c' and 'o'"},
+		},
+		{
+			name:   "USING merges the join column",
+			sql:    "SELECT id, name FROM staging.customers JOIN staging.orders USING (id)",
+			schema: schema,
+		},
+		{
+			name:   "select list alias",
+			sql:    "SELECT c.name AS customer, o.amount FROM staging.customers c JOIN staging.orders o ON c.id = o.customer_id ORDER BY customer",
+			schema: schema,
+		},
+		{
+			name: "CTE columns without a schema",
+			sql: `WITH c AS (SELECT id AS customer_id, name FROM customers),
+o AS (SELECT customer_id AS buyer, amount FROM orders)
+SELECT name, amount FROM c JOIN o ON c.customer_id = o.buyer`,
+		},
+		{
+			name: "derived table columns without a schema",
+			sql:  "SELECT name, total FROM (SELECT id, name FROM customers) c JOIN (SELECT customer_id, SUM(amount) AS total FROM orders GROUP BY customer_id) o ON c.id = o.customer_id",
+		},
+		{
+			name:   "unknown table keeps columns unresolved",
+			sql:    "SELECT name FROM staging.customers c JOIN payments p ON c.id = p.customer_id",
+			schema: schema,
+			want:   []string{"Column 'name' is unqualified and may be ambiguous with multiple tables; consider adding table qualifier"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range runRuleWithSchema(t, tt.sql, "AM06", tt.schema) {
+				got = append(got, d.Message)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestAM08_JoinConditionTables(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -227,3 +302,271 @@ func TestAM08_JoinConditionTables(t *testing.T) {
 		})
 	}
 }
+
+func TestAM11_ImplicitCoercion(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		config   map[string]any
+		wantDiag []string
+	}{
+		{
+			name:     "matching types",
+			sql:      "SELECT * FROM orders WHERE CAST(ref AS INTEGER) = 42 AND lower(status) = 'open'",
+			wantDiag: nil,
+		},
+		{
+			name:     "columns of unknown type",
+			sql:      "SELECT * FROM orders o JOIN customers c ON o.customer_id = c.id WHERE o.ref = '42'",
+			wantDiag: nil,
+		},
+		{
+			name:     "string and number keys in a join",
+			sql:      "SELECT * FROM orders o JOIN customers c ON CAST(o.customer_ref AS VARCHAR(20)) = CAST(c.id AS BIGINT)",
+			wantDiag: []string{"Comparison of string and number values relies on implicit type coercion"},
+		},
+		{
+			name: "function return types",
+			sql:  "SELECT length(name) = '3' AS is_short FROM customers WHERE upper(name) > 10",
+			wantDiag: []string{
+				"Comparison of number and string values relies on implicit type coercion",
+				"Comparison of string and number values relies on implicit type coercion",
+			},
+		},
+		{
+			name:     "IN list and BETWEEN",
+			sql:      "SELECT * FROM orders WHERE CAST(ref AS INT) IN ('1', '2') OR CAST(total AS DOUBLE) BETWEEN 1 AND 'x'",
+			wantDiag: []string{"Comparison of number and string values relies on implicit type coercion", "Comparison of number and string values relies on implicit type coercion"},
+		},
+		{
+			name:     "date and timestamp in loose mode",
+			sql:      "SELECT * FROM orders WHERE CAST(ordered_at AS DATE) = CURRENT_TIMESTAMP AND CAST(ordered_at AS DATE) > '2024-01-01'",
+			wantDiag: nil,
+		},
+		{
+			name:   "date and timestamp in strict mode",
+			sql:    "SELECT * FROM orders WHERE CAST(ordered_at AS DATE) = CAST(shipped_at AS TIMESTAMP) AND CAST(ordered_at AS DATE) > '2024-01-01'",
+			config: map[string]any{"strictness": "strict"},
+			wantDiag: []string{
+				"Comparison of date and timestamp values relies on implicit type coercion",
+				"Comparison of date and string values relies on implicit type coercion",
+			},
+		},
+		{
+			name:     "strict dialect",
+			sql:      "SELECT * FROM orders WHERE CAST(ordered_at AS DATE) > '2024-01-01'",
+			config:   map[string]any{"strict_dialects": []string{"DuckDB"}},
+			wantDiag: []string{"Comparison of date and string values relies on implicit type coercion"},
+		},
+		{
+			name:     "subqueries are checked once",
+			sql:      "SELECT * FROM orders WHERE id IN (SELECT id FROM refunds WHERE CAST(amount AS DECIMAL(10, 2)) > 'x')",
+			wantDiag: []string{"Comparison of number and string values relies on implicit type coercion"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(tt.sql, duckdbdialect.DuckDB)
+			require.NoError(t, err)
+
+			cfg := lint.NewConfig()
+			if tt.config != nil {
+				cfg = cfg.SetRuleOptions("AM11", tt.config)
+			}
+
+			analyzer := lint.NewAnalyzerWithRegistry(cfg, "duckdb")
+			diags := analyzer.AnalyzeWithRegistryRules(stmt, duckdbdialect.DuckDB)
+
+			var messages []string
+			for _, d := range diags {
+				if d.RuleID == "AM11" {
+					messages = append(messages, d.Message)
+				}
+			}
+			assert.ElementsMatch(t, tt.wantDiag, messages)
+		})
+	}
+}
+
+func TestAM12_NotInNull(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		wantDiag []string
+	}{
+		{
+			name:     "NOT IN with a nullable subquery",
+			sql:      "SELECT * FROM customers c WHERE c.id NOT IN (SELECT customer_id FROM orders)",
+			wantDiag: []string{"NOT IN matches no rows when its subquery returns a NULL; use NOT EXISTS"},
+		},
+		{
+			name:     "IN with a subquery",
+			sql:      "SELECT * FROM customers c WHERE c.id IN (SELECT customer_id FROM orders)",
+			wantDiag: nil,
+		},
+		{
+			name:     "subquery filtering NULLs",
+			sql:      "SELECT * FROM customers c WHERE c.id NOT IN (SELECT customer_id FROM orders WHERE status = 'paid' AND customer_id IS NOT NULL)",
+			wantDiag: nil,
+		},
+		{
+			name:     "subquery of non-null values",
+			sql:      "SELECT * FROM customers c WHERE c.id NOT IN (SELECT coalesce(customer_id, 0) FROM orders) AND c.tier NOT IN (SELECT count(*) FROM orders)",
+			wantDiag: nil,
+		},
+		{
+			name:     "NOT IN list with NULL",
+			sql:      "SELECT * FROM customers WHERE tier NOT IN ('gold', NULL)",
+			wantDiag: []string{"NOT IN matches no rows when its list contains NULL"},
+		},
+		{
+			name:     "!= ALL with a nullable subquery",
+			sql:      "SELECT * FROM customers c WHERE c.id <> ALL (SELECT customer_id FROM orders)",
+			wantDiag: []string{"!= ALL matches no rows when its subquery returns a NULL; use NOT EXISTS"},
+		},
+		{
+			name:     "> ALL",
+			sql:      "SELECT * FROM customers c WHERE c.id > ALL (SELECT customer_id FROM orders)",
+			wantDiag: nil,
+		},
+		{
+			name:     "nested subquery is checked once",
+			sql:      "SELECT * FROM (SELECT * FROM customers WHERE id NOT IN (SELECT customer_id FROM orders)) sub",
+			wantDiag: []string{"NOT IN matches no rows when its subquery returns a NULL; use NOT EXISTS"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages []string
+			for _, d := range runRule(t, tt.sql, "AM12") {
+				messages = append(messages, d.Message)
+			}
+			assert.Equal(t, tt.wantDiag, messages)
+		})
+	}
+}
+
+func TestAM12_NotExistsFix(t *testing.T) {
+	tests := []struct {
+		name  string
+		sql   string
+		fixed string // empty if no fix is offered
+	}{
+		{
+			name:  "qualified operand",
+			sql:   "SELECT * FROM customers c WHERE c.id NOT IN (SELECT customer_id FROM orders)",
+			fixed: "SELECT * FROM customers c WHERE NOT EXISTS (SELECT 1 FROM orders WHERE customer_id = c.id)",
+		},
+		{
+			name:  "operand qualified with the only table",
+			sql:   "SELECT * FROM customers WHERE id NOT IN (SELECT customer_id FROM orders WHERE status = 'paid')",
+			fixed: "SELECT * FROM customers WHERE NOT EXISTS (SELECT 1 FROM orders WHERE status = 'paid' AND customer_id = customers.id)",
+		},
+		{
+			name: "subquery with OR",
+			sql:  "SELECT * FROM customers c WHERE c.id NOT IN (SELECT customer_id FROM orders WHERE a = 1 OR b = 2)",
+		},
+		{
+			name: "unqualified operand with several tables",
+			sql:  "SELECT * FROM customers JOIN regions r ON r.id = region_id WHERE customer_id NOT IN (SELECT customer_id FROM orders)",
+		},
+		{
+			name: "subquery shadowing the operand's table",
+			sql:  "SELECT * FROM orders o WHERE o.id NOT IN (SELECT parent_id FROM orders o)",
+		},
+		{
+			name: "subquery with GROUP BY",
+			sql:  "SELECT * FROM customers c WHERE c.id NOT IN (SELECT customer_id FROM orders GROUP BY customer_id)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := runRule(t, tt.sql, "AM12")
+			require.Len(t, diags, 1)
+			if tt.fixed == "" {
+				assert.Empty(t, diags[0].Fixes)
+				return
+			}
+			require.Len(t, diags[0].Fixes, 1)
+			assert.Equal(t, lint.FixSuggestion, diags[0].Fixes[0].Kind)
+
+			fixed, unfixed := lint.ApplyFixes(tt.sql, diags, false)
+			assert.Equal(t, tt.sql, fixed, "suggestions need unsafe")
+			assert.Len(t, unfixed, 1)
+
+			fixed, unfixed = lint.ApplyFixes(tt.sql, diags, true)
+			assert.Equal(t, tt.fixed, fixed)
+			assert.Empty(t, unfixed)
+		})
+	}
+}
+
+func TestAM13_UnionColumnNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		wantDiag []string
+	}{
+		{
+			name:     "matching names",
+			sql:      "SELECT id, email FROM customers UNION ALL SELECT id, EMAIL FROM users",
+			wantDiag: nil,
+		},
+		{
+			name:     "renamed column",
+			sql:      "SELECT id, email FROM customers UNION ALL SELECT user_id, email FROM users",
+			wantDiag: []string{"Column 1 of query 2 is named 'user_id' but the set operation names it 'id'; alias it to confirm the mapping"},
+		},
+		{
+			name:     "explicit alias",
+			sql:      "SELECT c.id, email FROM customers c UNION ALL SELECT u.user_id AS id, email FROM users u",
+			wantDiag: nil,
+		},
+		{
+			name: "swapped columns",
+			sql:  "SELECT id, email, created_at FROM customers UNION SELECT id, created_at, email FROM users",
+			wantDiag: []string{
+				"Column 2 of query 2 is named 'created_at' but the set operation names it 'email'; alias it to confirm the mapping",
+				"Column 3 of query 2 is named 'email' but the set operation names it 'created_at'; alias it to confirm the mapping",
+			},
+		},
+		{
+			name:     "chained set operations",
+			sql:      "SELECT id FROM a UNION ALL SELECT id FROM b EXCEPT SELECT b_id FROM c",
+			wantDiag: []string{"Column 1 of query 3 is named 'b_id' but the set operation names it 'id'; alias it to confirm the mapping"},
+		},
+		{
+			name:     "unaliased expressions",
+			sql:      "SELECT id, 'customer' FROM customers UNION ALL SELECT id, upper(kind) FROM users",
+			wantDiag: nil,
+		},
+		{
+			name:     "star",
+			sql:      "SELECT * FROM customers UNION ALL SELECT user_id FROM users",
+			wantDiag: nil,
+		},
+		{
+			name:     "union by name",
+			sql:      "SELECT id, email FROM customers UNION ALL BY NAME SELECT email, id FROM users",
+			wantDiag: nil,
+		},
+		{
+			name:     "set operation in a subquery",
+			sql:      "SELECT * FROM (SELECT id FROM a UNION ALL SELECT a_id FROM b) ids",
+			wantDiag: []string{"Column 1 of query 2 is named 'a_id' but the set operation names it 'id'; alias it to confirm the mapping"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var messages []string
+			for _, d := range runRule(t, tt.sql, "AM13") {
+				messages = append(messages, d.Message)
+			}
+			assert.Equal(t, tt.wantDiag, messages)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	duckdbdialect "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	_ "github.com/leapstack-labs/leapsql/pkg/dialects/snowflake" // register the snowflake function catalog
 	"github.com/leapstack-labs/leapsql/pkg/lint"
 	_ "github.com/leapstack-labs/leapsql/pkg/lint/sql/rules" // register rules
 	"github.com/leapstack-labs/leapsql/pkg/parser"
@@ -217,3 +218,241 @@ func TestCV09_BlockedWords(t *testing.T) {
 		})
 	}
 }
+
+func TestCV10_PortableFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		config   map[string]any
+		wantDiag []string
+	}{
+		{
+			name:     "no portability profile",
+			sql:      "SELECT list_contains(tags, 'a') FROM users",
+			wantDiag: nil,
+		},
+		{
+			name:     "function without equivalent",
+			sql:      "SELECT list_contains(tags, 'a'), count_if(active) FROM users",
+			config:   map[string]any{"dialects": []string{"postgres"}},
+			wantDiag: []string{"Function 'LIST_CONTAINS' has no equivalent in postgres", "Function 'COUNT_IF' has no equivalent in postgres"},
+		},
+		{
+			name:     "function with equivalent",
+			sql:      "SELECT ifnull(name, 'x'), strpos(name, 'a') FROM users",
+			config:   map[string]any{"dialects": []string{"postgres", "snowflake"}},
+			wantDiag: nil,
+		},
+		{
+			name:     "function missing from the target catalog",
+			sql:      "SELECT list_sort(tags), epoch_ms(created_at) FROM users",
+			config:   map[string]any{"dialects": []any{"snowflake"}},
+			wantDiag: []string{"Function 'LIST_SORT' has no equivalent in snowflake", "Function 'EPOCH_MS' has no equivalent in snowflake"},
+		},
+		{
+			name:     "several dialects",
+			sql:      "SELECT count_if(active) FROM users",
+			config:   map[string]any{"dialects": []string{"snowflake", "postgres"}},
+			wantDiag: []string{"Function 'COUNT_IF' has no equivalent in postgres"},
+		},
+		{
+			name:     "unknown function",
+			sql:      "SELECT my_udf(id) FROM users",
+			config:   map[string]any{"dialects": []string{"snowflake", "postgres"}},
+			wantDiag: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(tt.sql, duckdbdialect.DuckDB)
+			require.NoError(t, err)
+
+			cfg := lint.NewConfig()
+			if tt.config != nil {
+				cfg = cfg.SetRuleOptions("CV10", tt.config)
+			}
+
+			analyzer := lint.NewAnalyzerWithRegistry(cfg, "duckdb")
+			diags := analyzer.AnalyzeWithRegistryRules(stmt, duckdbdialect.DuckDB)
+
+			var messages []string
+			for _, d := range diags {
+				if d.RuleID == "CV10" {
+					messages = append(messages, d.Message)
+				}
+			}
+			assert.Equal(t, tt.wantDiag, messages)
+		})
+	}
+}
+
+func TestCV15_ColumnNaming(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		config   map[string]any
+		wantDiag []string
+	}{
+		{
+			name:     "conventional names",
+			sql:      "SELECT id AS customer_id, CAST(created AS DATE) AS signup_date, status = 'active' AS is_active, name AS full_name FROM customers",
+			wantDiag: nil,
+		},
+		{
+			name:     "unaliased columns are not checked",
+			sql:      "SELECT FirstName, status = 'active' FROM customers",
+			wantDiag: nil,
+		},
+		{
+			name:     "not snake_case",
+			sql:      "SELECT first_name AS FirstName, last_name AS \"last-name\" FROM customers",
+			wantDiag: []string{"Column alias 'FirstName' is not snake_case", "Column alias 'last-name' is not snake_case"},
+		},
+		{
+			name: "dates without suffix",
+			sql:  "SELECT CAST(created AS TIMESTAMP) AS created, date_trunc('month', created_at) AS signup_month, max(updated_at) AS last_update FROM customers",
+			wantDiag: []string{
+				"Column alias 'created' holds a date and should end with _at or _date",
+				"Column alias 'signup_month' holds a date and should end with _at or _date",
+				"Column alias 'last_update' holds a date and should end with _at or _date",
+			},
+		},
+		{
+			name: "booleans without prefix",
+			sql:  "SELECT email IS NULL AS missing_email, status IN ('a', 'b') AS open, NOT is_deleted AS visible FROM customers",
+			wantDiag: []string{
+				"Column alias 'missing_email' holds a boolean and should start with is_ or has_",
+				"Column alias 'open' holds a boolean and should start with is_ or has_",
+				"Column alias 'visible' holds a boolean and should start with is_ or has_",
+			},
+		},
+		{
+			name:     "renamed IDs",
+			sql:      "SELECT c.id AS customer, account_id AS account, o.id AS id FROM customers c JOIN orders o ON c.id = o.customer_id",
+			wantDiag: []string{"Column alias 'customer' holds an ID and should end with _id", "Column alias 'account' holds an ID and should end with _id"},
+		},
+		{
+			name:     "custom affixes",
+			sql:      "SELECT CAST(created AS DATE) AS created_on, flag = 1 AS is_flagged, user_key AS customer FROM customers",
+			config:   map[string]any{"date_suffixes": []string{"_on"}, "boolean_prefixes": []string{"flag_"}, "id_suffixes": []string{"_key"}},
+			wantDiag: []string{"Column alias 'is_flagged' holds a boolean and should start with flag_", "Column alias 'customer' holds an ID and should end with _key"},
+		},
+		{
+			name:     "checks turned off",
+			sql:      "SELECT CAST(created AS DATE) AS Created, flag = 1 AS flagged FROM customers",
+			config:   map[string]any{"snake_case": false, "date_suffixes": []string{}, "boolean_prefixes": []string{}},
+			wantDiag: nil,
+		},
+		{
+			name:     "subqueries and unions",
+			sql:      "SELECT * FROM (SELECT a = b AS same FROM t) UNION ALL SELECT x AS SomeName FROM u",
+			wantDiag: []string{"Column alias 'same' holds a boolean and should start with is_ or has_", "Column alias 'SomeName' is not snake_case"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(tt.sql, duckdbdialect.DuckDB)
+			require.NoError(t, err)
+
+			cfg := lint.NewConfig()
+			if tt.config != nil {
+				cfg = cfg.SetRuleOptions("CV15", tt.config)
+			}
+
+			analyzer := lint.NewAnalyzerWithRegistry(cfg, "duckdb")
+			diags := analyzer.AnalyzeWithRegistryRules(stmt, duckdbdialect.DuckDB)
+
+			var messages []string
+			for _, d := range diags {
+				if d.RuleID == "CV15" {
+					messages = append(messages, d.Message)
+				}
+			}
+			assert.ElementsMatch(t, tt.wantDiag, messages)
+		})
+	}
+}
+
+func TestCV16_Casing(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		config   map[string]any
+		wantDiag []string
+	}{
+		{
+			name:     "any casing by default",
+			sql:      "select Id, Count(*) AS n FROM orders",
+			wantDiag: nil,
+		},
+		{
+			name:     "upper case keywords and functions",
+			sql:      "SELECT CustomerId, COUNT(*) AS n, CAST(total AS DECIMAL(10, 2)) AS total FROM orders o LEFT JOIN refunds r ON o.id = r.id",
+			config:   map[string]any{"keywords": "upper", "functions": "upper"},
+			wantDiag: nil,
+		},
+		{
+			name:   "lower case keywords, functions and types",
+			sql:    "select id, Count(*) as n, total::double precision FROM orders WHERE created_at > date '2024-01-01'",
+			config: map[string]any{"keywords": "upper", "functions": "upper"},
+			wantDiag: []string{
+				"Keyword 'select' should be upper case",
+				"Function name 'Count' should be upper case",
+				"Keyword 'as' should be upper case",
+				"Keyword 'double' should be upper case",
+				"Keyword 'precision' should be upper case",
+				"Keyword 'date' should be upper case",
+			},
+		},
+		{
+			name:     "lower case policies",
+			sql:      "select sum(x) as total, * exclude (y) from generate_series(1, 10) as t(x)",
+			config:   map[string]any{"keywords": "lower", "functions": "lower"},
+			wantDiag: nil,
+		},
+		{
+			name:   "snake_case identifiers skip quoted ones",
+			sql:    `WITH Totals AS (SELECT customer_id AS CustomerId, SUM(amount) FROM ORDERS GROUP BY customer_id) SELECT t.CustomerId AS "CustomerId", "Total" FROM Totals AS t`,
+			config: map[string]any{"identifiers": "snake_case"},
+			wantDiag: []string{
+				"Identifier 'Totals' should be snake_case",
+				"Identifier 'CustomerId' should be snake_case",
+				"Identifier 'ORDERS' should be snake_case",
+				"Identifier 'CustomerId' should be snake_case",
+				"Identifier 'Totals' should be snake_case",
+			},
+		},
+		{
+			name:     "templates are left out",
+			sql:      "SELECT id FROM {{ ref('orders') }}",
+			config:   map[string]any{"identifiers": "upper"},
+			wantDiag: []string{"Identifier 'id' should be upper case"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(tt.sql, duckdbdialect.DuckDB)
+			require.NoError(t, err)
+
+			cfg := lint.NewConfig()
+			if tt.config != nil {
+				cfg = cfg.SetRuleOptions("CV16", tt.config)
+			}
+
+			analyzer := lint.NewAnalyzerWithRegistry(cfg, "duckdb")
+			tokens := parser.TokenizeWithDialect(tt.sql, duckdbdialect.DuckDB)
+			diags := analyzer.AnalyzeWithRegistryRules(stmt, lint.WithSource(duckdbdialect.DuckDB, tt.sql, tokens))
+
+			var messages []string
+			for _, d := range diags {
+				if d.RuleID == "CV16" {
+					messages = append(messages, d.Message)
+				}
+			}
+			assert.ElementsMatch(t, tt.wantDiag, messages)
+		})
+	}
+}
@@ -20,8 +20,10 @@ var BlockedWords = sql.RuleDef{
 	Group:       "convention",
 	Description: "Block dangerous SQL keywords like DELETE, DROP, TRUNCATE.",
 	Severity:    core.SeverityWarning,
-	ConfigKeys:  []string{"blocked_words"},
-	Check:       checkBlockedWords,
+	Options: []core.RuleOption{
+		{Name: "blocked_words", Type: core.OptionStringList, Default: []string{"DELETE", "DROP", "TRUNCATE"}, Description: "Keywords to flag, case-insensitive"},
+	},
+	Check: checkBlockedWords,
 
 	Rationale: `In data transformation pipelines (dbt, LeapSQL), destructive operations 
 like DELETE, DROP, and TRUNCATE are usually mistakes. Models should be declarative 
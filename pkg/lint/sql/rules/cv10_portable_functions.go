@@ -0,0 +1,103 @@
+package rules
+
+import (
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/dialect"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql/internal/ast"
+	"github.com/leapstack-labs/leapsql/pkg/transpile"
+)
+
+func init() {
+	sql.Register(PortableFunctions)
+}
+
+// PortableFunctions flags functions with no equivalent in the dialects of the
+// portability profile.
+var PortableFunctions = sql.RuleDef{
+	ID:          "CV10",
+	Name:        "convention.portable_functions",
+	Group:       "convention",
+	Description: "Functions should have an equivalent in every dialect of the portability profile.",
+	Severity:    core.SeverityWarning,
+	Options: []core.RuleOption{
+		{Name: "dialects", Type: core.OptionStringList, Description: "Dialects every function must have an equivalent in, lint.dialects by default (the rule is off when empty)"},
+	},
+	Check: checkPortableFunctions,
+
+	Rationale: `Models developed against one database and deployed to another (DuckDB locally,
+Snowflake in production) can only be transpiled when every function they call has an
+equivalent in the target dialect. Functions spelled differently (IFNULL and NVL) or taking
+their arguments in another order (STRPOS and CHARINDEX) are translated, but dialect-specific
+functions fail only once the model runs on the target. List the target dialects in the
+'dialects' option, or the project's lint.dialects, to catch them while writing the model. The rule is off until one is set.`,
+
+	BadExample: `-- With dialects: [postgres]
+SELECT list_contains(tags, 'vip') AS is_vip
+FROM customers`,
+
+	GoodExample: `-- With dialects: [postgres]
+SELECT 'vip' IN (SELECT unnest(tags)) AS is_vip
+FROM customers`,
+
+	Fix: "Replace the function with one every dialect of the profile supports, or an equivalent expression.",
+}
+
+func checkPortableFunctions(stmt any, d lint.DialectInfo, opts map[string]any) []lint.Diagnostic {
+	selectStmt, ok := stmt.(*core.SelectStmt)
+	if !ok || d == nil {
+		return nil
+	}
+
+	profile := lint.GetStringSliceOption(opts, "dialects", nil)
+	if len(profile) == 0 {
+		return nil
+	}
+
+	from := profileDialect(d.GetName())
+	targets := make([]*core.Dialect, 0, len(profile))
+	for _, name := range profile {
+		targets = append(targets, profileDialect(name))
+	}
+
+	var diagnostics []lint.Diagnostic
+	seen := make(map[string]bool)
+	for _, fn := range ast.CollectFuncCalls(selectStmt) {
+		name := strings.ToUpper(fn.Name)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		var missing []string
+		for _, target := range targets {
+			if _, compat := transpile.MapFunction(from, target, fn.Name); compat == core.FunctionMissing {
+				missing = append(missing, strings.ToLower(target.Name))
+			}
+		}
+		if len(missing) > 0 {
+			diagnostics = append(diagnostics, lint.Diagnostic{
+				RuleID:           "CV10",
+				Severity:         core.SeverityWarning,
+				Message:          "Function '" + name + "' has no equivalent in " + strings.Join(missing, ", "),
+				DocumentationURL: lint.BuildDocURL("CV10"),
+				ImpactScore:      lint.ImpactMedium.Int(),
+				AutoFixable:      false,
+			})
+		}
+	}
+
+	return diagnostics
+}
+
+// profileDialect returns the registered dialect of a name. Dialects that are
+// not registered are only known through the table of equivalents.
+func profileDialect(name string) *core.Dialect {
+	if d, ok := dialect.Get(name); ok {
+		return d
+	}
+	return &core.Dialect{Name: name}
+}
@@ -0,0 +1,245 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql/internal/ast"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+func init() {
+	sql.Register(ColumnNaming)
+}
+
+// ColumnNaming checks column aliases against the naming convention: snake_case,
+// and affixes telling dates, booleans and IDs apart.
+var ColumnNaming = sql.RuleDef{
+	ID:          "CV15",
+	Name:        "convention.column_naming",
+	Group:       "convention",
+	Description: "Column aliases should be snake_case and mark dates, booleans and IDs with their affixes.",
+	Severity:    core.SeverityInfo,
+	Options: []core.RuleOption{
+		{Name: "snake_case", Type: core.OptionBool, Default: true, Description: "Require aliases to be snake_case"},
+		{Name: "date_suffixes", Type: core.OptionStringList, Default: []string{"_at", "_date"}, Description: "Suffixes of date and timestamp columns, one of which is required (the check is off when empty)"},
+		{Name: "boolean_prefixes", Type: core.OptionStringList, Default: []string{"is_", "has_"}, Description: "Prefixes of boolean columns, one of which is required (the check is off when empty)"},
+		{Name: "id_suffixes", Type: core.OptionStringList, Default: []string{"_id"}, Description: "Suffixes of ID columns, one of which is required (the check is off when empty)"},
+	},
+	Check: checkColumnNaming,
+
+	Rationale: `Consistent column names let consumers guess what a column holds without
+reading the model: created_at is a timestamp, is_active a boolean, customer_id a key
+to join on. The rule infers what an aliased column holds from its expression
+(comparisons and IS NULL are booleans, casts to DATE and DATE_TRUNC are dates) or,
+for a renamed column, from the affixes of the source column, and checks the alias
+carries the matching affix.`,
+
+	BadExample: `SELECT
+    id AS customer,
+    CAST(created AS DATE) AS signup,
+    status = 'active' AS active,
+    lifetimeValue AS LifetimeValue
+FROM customers`,
+
+	GoodExample: `SELECT
+    id AS customer_id,
+    CAST(created AS DATE) AS signup_date,
+    status = 'active' AS is_active,
+    lifetimeValue AS lifetime_value
+FROM customers`,
+
+	Fix: "Rename the alias to snake_case and give it the affix of what the column holds.",
+}
+
+var snakeCaseRe = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// columnKind is what a column holds, as far as naming is concerned.
+type columnKind int
+
+const (
+	kindOther columnKind = iota
+	kindDate
+	kindBoolean
+	kindID
+)
+
+// namingAffixes holds the affixes of each kind of column.
+type namingAffixes struct {
+	dateSuffixes    []string
+	booleanPrefixes []string
+	idSuffixes      []string
+}
+
+func checkColumnNaming(stmt any, _ lint.DialectInfo, opts map[string]any) []lint.Diagnostic {
+	selectStmt, ok := stmt.(*core.SelectStmt)
+	if !ok {
+		return nil
+	}
+
+	snakeCase := lint.GetBoolOption(opts, "snake_case", true)
+	affixes := namingAffixes{
+		dateSuffixes:    lint.GetStringSliceOption(opts, "date_suffixes", []string{"_at", "_date"}),
+		booleanPrefixes: lint.GetStringSliceOption(opts, "boolean_prefixes", []string{"is_", "has_"}),
+		idSuffixes:      lint.GetStringSliceOption(opts, "id_suffixes", []string{"_id"}),
+	}
+
+	var diagnostics []lint.Diagnostic
+	report := func(item *core.SelectItem, message string) {
+		diagnostics = append(diagnostics, lint.Diagnostic{
+			RuleID:           "CV15",
+			Severity:         core.SeverityInfo,
+			Message:          message,
+			Pos:              item.Span.Start,
+			EndPos:           item.Span.End,
+			DocumentationURL: lint.BuildDocURL("CV15"),
+			ImpactScore:      lint.ImpactLow.Int(),
+			AutoFixable:      false,
+		})
+	}
+
+	for _, selectCore := range ast.CollectSelectCores(selectStmt) {
+		for i := range selectCore.Columns {
+			item := &selectCore.Columns[i]
+			if item.Alias == "" || item.Expr == nil {
+				continue
+			}
+			alias := item.Alias
+
+			if snakeCase && !snakeCaseRe.MatchString(alias) {
+				report(item, "Column alias '"+alias+"' is not snake_case")
+			}
+
+			name := strings.ToLower(alias)
+			switch affixes.kindOf(item.Expr) {
+			case kindDate:
+				if len(affixes.dateSuffixes) > 0 && !hasSuffix(name, affixes.dateSuffixes) {
+					report(item, "Column alias '"+alias+"' holds a date and should end with "+orList(affixes.dateSuffixes))
+				}
+			case kindBoolean:
+				if len(affixes.booleanPrefixes) > 0 && !hasPrefix(name, affixes.booleanPrefixes) {
+					report(item, "Column alias '"+alias+"' holds a boolean and should start with "+orList(affixes.booleanPrefixes))
+				}
+			case kindID:
+				if len(affixes.idSuffixes) > 0 && !hasSuffix(name, affixes.idSuffixes) {
+					report(item, "Column alias '"+alias+"' holds an ID and should end with "+orList(affixes.idSuffixes))
+				}
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// Functions returning a date or timestamp.
+var dateFunctions = map[string]bool{
+	"CURRENT_DATE":      true,
+	"CURRENT_TIMESTAMP": true,
+	"NOW":               true,
+	"DATE":              true,
+	"DATE_TRUNC":        true,
+	"DATE_ADD":          true,
+	"DATE_SUB":          true,
+	"DATEADD":           true,
+	"LAST_DAY":          true,
+	"MAKE_DATE":         true,
+	"MAKE_TIMESTAMP":    true,
+	"TO_DATE":           true,
+	"TO_TIMESTAMP":      true,
+	"STRPTIME":          true,
+}
+
+// Functions returning a value of their first argument's kind.
+var passThroughFunctions = map[string]bool{
+	"MIN":       true,
+	"MAX":       true,
+	"ANY_VALUE": true,
+	"COALESCE":  true,
+}
+
+// kindOf infers what expr holds. Column references take the kind their name
+// marks, so that renaming a column keeps its affix.
+func (a namingAffixes) kindOf(expr core.Expr) columnKind {
+	switch e := expr.(type) {
+	case *core.ParenExpr:
+		return a.kindOf(e.Expr)
+	case *core.ColumnRef:
+		name := strings.ToLower(e.Column)
+		switch {
+		case isIDName(name, a.idSuffixes):
+			return kindID
+		case hasSuffix(name, a.dateSuffixes):
+			return kindDate
+		case hasPrefix(name, a.booleanPrefixes):
+			return kindBoolean
+		}
+	case *core.CastExpr:
+		typeName := strings.ToUpper(e.TypeName)
+		switch {
+		case typeName == "BOOLEAN" || typeName == "BOOL":
+			return kindBoolean
+		case typeName == "DATE" || strings.HasPrefix(typeName, "TIMESTAMP") || typeName == "DATETIME":
+			return kindDate
+		}
+	case *core.FuncCall:
+		name := strings.ToUpper(e.Name)
+		switch {
+		case dateFunctions[name]:
+			return kindDate
+		case passThroughFunctions[name] && len(e.Args) > 0 && e.Window == nil:
+			return a.kindOf(e.Args[0])
+		}
+	case *core.Literal:
+		if e.Type == core.LiteralBool {
+			return kindBoolean
+		}
+	case *core.BinaryExpr:
+		switch e.Op {
+		case token.EQ, token.NE, token.LT, token.GT, token.LE, token.GE, token.AND, token.OR:
+			return kindBoolean
+		}
+	case *core.UnaryExpr:
+		if e.Op == token.NOT {
+			return kindBoolean
+		}
+	case *core.InExpr, *core.BetweenExpr, *core.IsNullExpr, *core.IsBoolExpr, *core.LikeExpr, *core.ExistsExpr:
+		return kindBoolean
+	}
+	return kindOther
+}
+
+// isIDName reports whether a column name marks an ID: "id" itself or a name
+// with an ID suffix.
+func isIDName(name string, suffixes []string) bool {
+	return len(suffixes) > 0 && (name == "id" || hasSuffix(name, suffixes))
+}
+
+func hasSuffix(name string, suffixes []string) bool {
+	for _, s := range suffixes {
+		s = strings.ToLower(s)
+		if strings.HasSuffix(name, s) || name == strings.TrimPrefix(s, "_") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPrefix(name string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(name, strings.ToLower(p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// orList joins affixes for a message, e.g. "_at or _date".
+func orList(affixes []string) string {
+	if len(affixes) == 1 {
+		return affixes[0]
+	}
+	return strings.Join(affixes[:len(affixes)-1], ", ") + " or " + affixes[len(affixes)-1]
+}
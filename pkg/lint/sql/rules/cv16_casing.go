@@ -0,0 +1,190 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	"github.com/leapstack-labs/leapsql/pkg/lint/sql"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+func init() {
+	sql.Register(Casing)
+}
+
+// Casing checks keywords, function names and unquoted identifiers follow the
+// configured casing policies, the ones `leapsql fmt` applies.
+var Casing = sql.RuleDef{
+	ID:          "CV16",
+	Name:        "convention.casing",
+	Group:       "convention",
+	Description: "Keywords, function names and unquoted identifiers should follow the configured casing.",
+	Severity:    core.SeverityInfo,
+	Options: []core.RuleOption{
+		{Name: "keywords", Type: core.OptionString, Default: "any", Enum: []string{"any", "upper", "lower"}, Description: "Casing of keywords and data types (any to leave them unchecked)"},
+		{Name: "functions", Type: core.OptionString, Default: "any", Enum: []string{"any", "upper", "lower"}, Description: "Casing of function names (any to leave them unchecked)"},
+		{Name: "identifiers", Type: core.OptionString, Default: "any", Enum: []string{"any", "lower", "upper", "snake_case"}, Description: "Casing of unquoted identifiers (any to leave them unchecked)"},
+	},
+	Check: checkCasing,
+
+	Rationale: `SQL that mixes SELECT with select, or Count with COUNT, is harder to scan and
+makes diffs noisy when someone normalizes it. The rule checks the SQL as written:
+keywords and data types, function names and unquoted identifiers. Quoted
+identifiers are never checked, their case is part of their name, and neither are
+unquoted ones in dialects where identifiers are case-sensitive. Any casing is
+accepted until the policies are set, and leapsql fmt formats models in the
+casing they set.`,
+
+	BadExample: `select Customer_ID, count(*) AS Orders
+FROM Orders
+GROUP BY Customer_ID`,
+
+	GoodExample: `SELECT customer_id, COUNT(*) AS orders
+FROM orders
+GROUP BY customer_id`,
+
+	Fix: "Recase the word, or run leapsql fmt.",
+}
+
+// typeWords are the words continuing the name of a data type, such as
+// DOUBLE PRECISION and TIMESTAMP WITH TIME ZONE.
+var typeWords = map[string]bool{"PRECISION": true, "VARYING": true, "WITH": true, "WITHOUT": true, "TIME": true, "ZONE": true}
+
+// wordKind is what a word of a statement is, as far as casing is concerned.
+type wordKind int
+
+const (
+	wordNone wordKind = iota
+	wordKeyword
+	wordFunction
+	wordIdentifier
+)
+
+func checkCasing(_ any, d lint.DialectInfo, opts map[string]any) []lint.Diagnostic {
+	src, tokens := lint.SourceOf(d)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	policies := map[wordKind]core.Casing{
+		wordKeyword:    core.Casing(lint.GetStringOption(opts, "keywords", string(core.CasingAny))),
+		wordFunction:   core.Casing(lint.GetStringOption(opts, "functions", string(core.CasingAny))),
+		wordIdentifier: core.Casing(lint.GetStringOption(opts, "identifiers", string(core.CasingAny))),
+	}
+	// Recasing an identifier renames it in case-sensitive dialects
+	if d.NormalizeName("aB") == "aB" {
+		policies[wordIdentifier] = core.CasingAny
+	}
+	names := map[wordKind]string{wordKeyword: "Keyword", wordFunction: "Function name", wordIdentifier: "Identifier"}
+
+	var diagnostics []lint.Diagnostic
+	for i, kind := range classifyWords(src, tokens) {
+		policy := policies[kind]
+		tok := tokens[i]
+		if kind == wordNone || policy.Matches(tok.Literal) {
+			continue
+		}
+
+		end := token.Position{Offset: tok.Pos.Offset + len(tok.Literal)}
+		diag := lint.Diagnostic{
+			RuleID:           "CV16",
+			Severity:         core.SeverityInfo,
+			Message:          fmt.Sprintf("%s '%s' should be %s", names[kind], tok.Literal, casingName(policy)),
+			Pos:              tok.Pos,
+			EndPos:           end,
+			DocumentationURL: lint.BuildDocURL("CV16"),
+			ImpactScore:      lint.ImpactLow.Int(),
+		}
+		if recased := policy.Apply(tok.Literal); policy.Matches(recased) {
+			diag.Fixes = []lint.Fix{{
+				Description: fmt.Sprintf("Change to %s", recased),
+				TextEdits:   []lint.TextEdit{{Pos: tok.Pos, EndPos: end, NewText: recased}},
+			}}
+			diag.AutoFixable = true
+		}
+		diagnostics = append(diagnostics, diag)
+	}
+	return diagnostics
+}
+
+// casingName returns how a casing policy reads in messages.
+func casingName(c core.Casing) string {
+	switch c {
+	case core.CasingUpper:
+		return "upper case"
+	case core.CasingLower:
+		return "lower case"
+	}
+	return string(c)
+}
+
+// classifyWords returns what each token of a statement is: a keyword (data
+// types included), a function name, an unquoted identifier or none of them.
+// Template expressions are left out: their output is checked, not their
+// source.
+func classifyWords(src string, tokens []token.Token) []wordKind {
+	kinds := make([]wordKind, len(tokens))
+	typeName := false // The next words name a data type
+	var casts []int   // Paren depths of the CASTs being read
+	depth := 0
+	for i, tok := range tokens {
+		prev, next := token.Token{Type: token.EOF}, token.Token{Type: token.EOF}
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+
+		switch tok.Type {
+		case token.LPAREN:
+			depth++
+		case token.RPAREN:
+			if len(casts) > 0 && casts[len(casts)-1] == depth {
+				casts = casts[:len(casts)-1]
+			}
+			depth--
+		case token.DCOLON:
+			typeName = true
+			continue
+		case token.AS:
+			if len(casts) > 0 && casts[len(casts)-1] == depth {
+				typeName = true
+				continue
+			}
+		}
+
+		word := tok.Literal != "" && (tok.Literal[0] == '_' || isLetter(tok.Literal[0])) && src[tok.Pos.Offset] != '"'
+		if !word || tok.Type == token.MACRO {
+			typeName = false
+			continue
+		}
+		upper := strings.ToUpper(tok.Literal)
+		if next.Type == token.LPAREN && (upper == "CAST" || upper == "TRY_CAST") {
+			casts = append(casts, depth+1)
+		}
+
+		switch {
+		case typeName, tok.Type == token.IDENT && next.Type == token.STRING:
+			// Data types, as in CAST(x AS DATE) and DATE '2024-01-01'
+			kinds[i] = wordKeyword
+			typeName = typeWords[strings.ToUpper(next.Literal)]
+		case prev.Type == token.DOT || next.Type == token.DOT:
+			kinds[i] = wordIdentifier
+		case tok.Type == token.IDENT && next.Type == token.LPAREN && prev.Type != token.AS:
+			// Table aliases with column lists, as in AS t(a, b), name no function
+			kinds[i] = wordFunction
+		case tok.Type == token.IDENT:
+			kinds[i] = wordIdentifier
+		default:
+			kinds[i] = wordKeyword
+		}
+	}
+	return kinds
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,33 @@
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/leapstack-labs/leapsql/pkg/lint"
+	_ "github.com/leapstack-labs/leapsql/pkg/lint/sql/rules" // register rules
+)
+
+// TestProfiles_ReferenceRegisteredRules keeps the bundled profiles in sync
+// with the rules.
+func TestProfiles_ReferenceRegisteredRules(t *testing.T) {
+	for _, name := range lint.ListProfiles() {
+		profile, _ := lint.GetProfile(name)
+
+		ids := append([]string{}, profile.Disabled...)
+		for id := range profile.Severity {
+			ids = append(ids, id)
+		}
+		for id := range profile.Rules {
+			ids = append(ids, id)
+		}
+		for _, id := range ids {
+			_, ok := lint.GetRuleByID(id)
+			assert.True(t, ok, "profile %s references unknown rule %s", name, id)
+		}
+
+		require.NoError(t, lint.NewConfig().ApplyProfile(name), "profile %s", name)
+	}
+}
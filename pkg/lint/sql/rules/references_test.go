@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/leapstack-labs/leapsql/pkg/lint"
 	_ "github.com/leapstack-labs/leapsql/pkg/lint/sql/rules" // register rules
 )
 
@@ -43,6 +44,22 @@ func TestRF02_QualifyColumns(t *testing.T) {
 	}
 }
 
+func TestRF02_QualifyColumns_ResolvedColumns(t *testing.T) {
+	schema := lint.Schema{
+		"staging.customers": {"id", "name"},
+		"staging.orders":    {"id", "customer_id", "amount"},
+	}
+
+	// Select list aliases can't be qualified once every table's columns are known
+	sql := "SELECT c.name AS customer, SUM(o.amount) AS total FROM staging.customers c JOIN staging.orders o ON c.id = o.customer_id GROUP BY customer ORDER BY total"
+	assert.Empty(t, runRuleWithSchema(t, sql, "RF02", schema))
+	assert.Len(t, runRule(t, sql, "RF02"), 2, "without a schema, aliases can't be told from columns")
+
+	// Columns of upstream tables still need qualifying
+	sql = "SELECT name, o.amount FROM staging.customers c JOIN staging.orders o ON c.id = o.customer_id"
+	assert.Len(t, runRuleWithSchema(t, sql, "RF02", schema), 1)
+}
+
 func TestRF03_ConsistentQualification(t *testing.T) {
 	tests := []struct {
 		name     string
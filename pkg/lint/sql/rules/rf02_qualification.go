@@ -22,7 +22,10 @@ var QualifyColumns = sql.RuleDef{
 
 	Rationale: `In queries involving multiple tables, unqualified column names can be ambiguous. 
 If two tables have a column with the same name, the query may fail or return unexpected results. 
-Qualifying columns with table names or aliases makes the query explicit and prevents errors when schemas change.`,
+Qualifying columns with table names or aliases makes the query explicit and prevents errors when schemas change.
+
+When the columns of every table are known, from CTEs, subqueries and the output of upstream
+models, references that match none of them, such as select list aliases, are not reported.`,
 
 	BadExample: `SELECT name, amount
 FROM customers
@@ -35,7 +38,7 @@ JOIN orders ON customers.id = orders.customer_id`,
 	Fix: "Prefix each column reference with its table name or alias.",
 }
 
-func checkQualifyColumns(stmt any, _ lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
+func checkQualifyColumns(stmt any, dialect lint.DialectInfo, _ map[string]any) []lint.Diagnostic {
 	selectStmt, ok := stmt.(*core.SelectStmt)
 	if !ok {
 		return nil
@@ -55,10 +58,18 @@ func checkQualifyColumns(stmt any, _ lint.DialectInfo, _ map[string]any) []lint.
 		return nil
 	}
 
+	// Columns of CTEs, derived tables and tables in the schema are known, so
+	// references to none of them, such as select list aliases, can't be
+	// qualified
+	sources := ast.FromSources(selectStmt, selectCore, dialect)
+
 	// Find unqualified column references
 	var diagnostics []lint.Diagnostic
 	for _, colRef := range ast.CollectColumnRefs(selectStmt) {
 		if colRef.Table == "" {
+			if matches, complete := ast.ColumnSources(sources, colRef.Column, dialect); complete && len(matches) == 0 {
+				continue
+			}
 			diagnostics = append(diagnostics, lint.Diagnostic{
 				RuleID:           "RF02",
 				Severity:         core.SeverityWarning,
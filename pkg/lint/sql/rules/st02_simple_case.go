@@ -5,6 +5,7 @@ import (
 	"github.com/leapstack-labs/leapsql/pkg/lint"
 	"github.com/leapstack-labs/leapsql/pkg/lint/sql"
 	"github.com/leapstack-labs/leapsql/pkg/lint/sql/internal/ast"
+	"github.com/leapstack-labs/leapsql/pkg/token"
 )
 
 func init() {
@@ -57,53 +58,67 @@ func checkSimpleCaseConversion(stmt any, _ lint.DialectInfo, _ map[string]any) [
 		}
 
 		// Check if all WHEN conditions compare the same column to a literal
-		if canConvertToSimpleCaseST02(caseExpr) {
-			diagnostics = append(diagnostics, lint.Diagnostic{
-				RuleID:           "ST02",
-				Severity:         core.SeverityHint,
-				Message:          "Searched CASE expression can be converted to simple CASE for better readability",
-				DocumentationURL: lint.BuildDocURL("ST02"),
-				ImpactScore:      lint.ImpactLow.Int(),
-				AutoFixable:      false,
-			})
+		operand, literals, ok := simpleCaseOperandST02(caseExpr)
+		if !ok {
+			continue
+		}
+		diag := lint.Diagnostic{
+			RuleID:           "ST02",
+			Severity:         core.SeverityHint,
+			Message:          "Searched CASE expression can be converted to simple CASE for better readability",
+			Pos:              caseExpr.Span.Start,
+			EndPos:           caseExpr.Span.End,
+			DocumentationURL: lint.BuildDocURL("ST02"),
+			ImpactScore:      lint.ImpactLow.Int(),
+			AutoFixable:      false,
 		}
+		// A simple CASE evaluates the operand once and compares it using the
+		// operand's type, so the rewrite is only offered as a suggestion.
+		if fix, ok := simpleCaseFixST02(caseExpr, operand, literals); ok {
+			diag.Fixes = []lint.Fix{fix}
+		}
+		diagnostics = append(diagnostics, diag)
 	}
 	return diagnostics
 }
 
-func canConvertToSimpleCaseST02(caseExpr *core.CaseExpr) bool {
+// simpleCaseOperandST02 returns the column compared in every WHEN condition
+// and the literal each condition compares it to.
+func simpleCaseOperandST02(caseExpr *core.CaseExpr) (*core.ColumnRef, []*core.Literal, bool) {
 	if len(caseExpr.Whens) < 2 {
-		return false
+		return nil, nil, false
 	}
 
+	var operand *core.ColumnRef
 	var commonColumn string
+	literals := make([]*core.Literal, 0, len(caseExpr.Whens))
 
 	for _, when := range caseExpr.Whens {
 		// Check if condition is an equality comparison
 		binExpr, ok := when.Condition.(*core.BinaryExpr)
 		if !ok {
-			return false
+			return nil, nil, false
 		}
 
 		// Must be equality comparison (=)
 		if binExpr.Op.String() != "=" {
-			return false
+			return nil, nil, false
 		}
 
 		// One side should be a column ref, the other a literal
 		var colRef *core.ColumnRef
-		var hasLiteral bool
+		var lit *core.Literal
 
 		if cr, ok := binExpr.Left.(*core.ColumnRef); ok {
 			colRef = cr
-			_, hasLiteral = binExpr.Right.(*core.Literal)
+			lit, _ = binExpr.Right.(*core.Literal)
 		} else if cr, ok := binExpr.Right.(*core.ColumnRef); ok {
 			colRef = cr
-			_, hasLiteral = binExpr.Left.(*core.Literal)
+			lit, _ = binExpr.Left.(*core.Literal)
 		}
 
-		if colRef == nil || !hasLiteral {
-			return false
+		if colRef == nil || lit == nil {
+			return nil, nil, false
 		}
 
 		colName := colRef.Column
@@ -113,10 +128,42 @@ func canConvertToSimpleCaseST02(caseExpr *core.CaseExpr) bool {
 
 		if commonColumn == "" {
 			commonColumn = colName
+			operand = colRef
 		} else if commonColumn != colName {
-			return false // Different columns in different WHENs
+			return nil, nil, false // Different columns in different WHENs
+		}
+		literals = append(literals, lit)
+	}
+
+	return operand, literals, commonColumn != ""
+}
+
+// simpleCaseFixST02 builds the edits that move operand after CASE and reduce
+// each WHEN condition to its literal.
+func simpleCaseFixST02(caseExpr *core.CaseExpr, operand *core.ColumnRef, literals []*core.Literal) (lint.Fix, bool) {
+	start := caseExpr.Span.Start
+	if !start.IsValid() {
+		return lint.Fix{}, false
+	}
+
+	// Insert the operand right after the CASE keyword
+	afterCase := token.Position{Line: start.Line, Column: start.Column + len("CASE"), Offset: start.Offset + len("CASE")}
+	edits := []lint.TextEdit{{Pos: afterCase, EndPos: afterCase, NewText: " " + ast.ColumnRefSQL(operand)}}
+
+	for i, when := range caseExpr.Whens {
+		if !when.ConditionSpan.Start.IsValid() {
+			return lint.Fix{}, false
 		}
+		edits = append(edits, lint.TextEdit{
+			Pos:     when.ConditionSpan.Start,
+			EndPos:  when.ConditionSpan.End,
+			NewText: ast.LiteralSQL(literals[i]),
+		})
 	}
 
-	return commonColumn != ""
+	return lint.Fix{
+		Description: "Convert to simple CASE on " + ast.ColumnRefSQL(operand),
+		Kind:        lint.FixSuggestion,
+		TextEdits:   edits,
+	}, true
 }
@@ -50,35 +50,58 @@ func checkPreferUsing(stmt any, _ lint.DialectInfo, _ map[string]any) []lint.Dia
 		}
 
 		// Check if condition is a simple equality on same-named columns
-		if canUseUsingST07(join.Condition) {
-			diagnostics = append(diagnostics, lint.Diagnostic{
-				RuleID:           "ST07",
-				Severity:         core.SeverityHint,
-				Message:          "Consider using USING clause for join on same-named columns",
-				Pos:              join.Span.Start,
-				DocumentationURL: lint.BuildDocURL("ST07"),
-				ImpactScore:      lint.ImpactLow.Int(),
-				AutoFixable:      false,
-			})
+		column, ok := usingColumnST07(join.Condition)
+		if !ok {
+			continue
+		}
+		diag := lint.Diagnostic{
+			RuleID:           "ST07",
+			Severity:         core.SeverityHint,
+			Message:          "Consider using USING clause for join on same-named columns",
+			Pos:              join.Span.Start,
+			EndPos:           join.Span.End,
+			DocumentationURL: lint.BuildDocURL("ST07"),
+			ImpactScore:      lint.ImpactLow.Int(),
+			AutoFixable:      false,
 		}
+		// USING merges the join columns into one, which changes what
+		// SELECT * returns and how the column may be qualified, so the
+		// rewrite is only offered as a suggestion.
+		if join.ConditionSpan.Start.IsValid() {
+			using := "USING (" + ast.IdentSQL(column) + ")"
+			diag.Fixes = []lint.Fix{{
+				Description: "Replace ON with " + using,
+				Kind:        lint.FixSuggestion,
+				TextEdits: []lint.TextEdit{{
+					Pos:     join.ConditionSpan.Start,
+					EndPos:  join.ConditionSpan.End,
+					NewText: using,
+				}},
+			}}
+		}
+		diagnostics = append(diagnostics, diag)
 	}
 	return diagnostics
 }
 
-func canUseUsingST07(condition core.Expr) bool {
+// usingColumnST07 returns the column name when condition is an equality
+// between same-named, table-qualified columns.
+func usingColumnST07(condition core.Expr) (string, bool) {
 	binExpr, ok := condition.(*core.BinaryExpr)
 	if !ok || binExpr.Op != token.EQ {
-		return false
+		return "", false
 	}
 
 	leftCol, leftOk := binExpr.Left.(*core.ColumnRef)
 	rightCol, rightOk := binExpr.Right.(*core.ColumnRef)
 
-	if !leftOk || !rightOk {
-		return false
+	if !leftOk || !rightOk || len(leftCol.Fields) > 0 || len(rightCol.Fields) > 0 {
+		return "", false
 	}
 
 	// Both must have table qualifiers and same column name
-	return leftCol.Table != "" && rightCol.Table != "" &&
-		leftCol.Column == rightCol.Column
+	if leftCol.Table == "" || rightCol.Table == "" || leftCol.Column != rightCol.Column {
+		return "", false
+	}
+	return leftCol.Column, true
 }
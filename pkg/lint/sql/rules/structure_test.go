@@ -4,7 +4,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/leapstack-labs/leapsql/pkg/lint"
 	_ "github.com/leapstack-labs/leapsql/pkg/lint/sql/rules" // register rules
 )
 
@@ -83,6 +85,42 @@ func TestST02_SimpleCaseConversion(t *testing.T) {
 	}
 }
 
+func TestST02_Suggestion(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "column on the left",
+			sql:  "SELECT CASE WHEN o.status = 'a' THEN 1 WHEN o.status = 'it''s' THEN 2 END FROM orders o",
+			want: "SELECT CASE o.status WHEN 'a' THEN 1 WHEN 'it''s' THEN 2 END FROM orders o",
+		},
+		{
+			name: "column on the right",
+			sql:  "SELECT CASE WHEN 1 = code THEN 'x' WHEN 2 = code THEN 'y' ELSE 'z' END FROM t",
+			want: "SELECT CASE code WHEN 1 THEN 'x' WHEN 2 THEN 'y' ELSE 'z' END FROM t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := runRule(t, tt.sql, "ST02")
+			require.Len(t, diags, 1)
+			require.Len(t, diags[0].Fixes, 1)
+			assert.Equal(t, lint.FixSuggestion, diags[0].Fixes[0].Kind)
+
+			fixed, unfixed := lint.ApplyFixes(tt.sql, diags, false)
+			assert.Len(t, unfixed, 1, "suggestions are not applied by default")
+			assert.Equal(t, tt.sql, fixed)
+
+			fixed, unfixed = lint.ApplyFixes(tt.sql, diags, true)
+			assert.Empty(t, unfixed)
+			assert.Equal(t, tt.want, fixed)
+		})
+	}
+}
+
 func TestST03_UnusedCTE(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -243,6 +281,23 @@ func TestST07_PreferUsing(t *testing.T) {
 	}
 }
 
+func TestST07_Suggestion(t *testing.T) {
+	sql := "SELECT *\nFROM users usr\nLEFT JOIN orders ord ON usr.id = ord.id\nWHERE usr.active"
+	diags := runRule(t, sql, "ST07")
+	require.Len(t, diags, 1)
+	assert.Equal(t, 3, diags[0].Pos.Line)
+	assert.Equal(t, 1, diags[0].Pos.Column)
+
+	require.Len(t, diags[0].Fixes, 1)
+	fix := diags[0].Fixes[0]
+	assert.Equal(t, lint.FixSuggestion, fix.Kind)
+	assert.Equal(t, "Replace ON with USING (id)", fix.Description)
+
+	fixed, unfixed := lint.ApplyFixes(sql, diags, true)
+	assert.Empty(t, unfixed)
+	assert.Equal(t, "SELECT *\nFROM users usr\nLEFT JOIN orders ord USING (id)\nWHERE usr.active", fixed)
+}
+
 func TestST08_DistinctVsGroupBy(t *testing.T) {
 	tests := []struct {
 		name     string
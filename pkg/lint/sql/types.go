@@ -10,14 +10,15 @@ import (
 // The Check function receives an `any` type that should be *core.SelectStmt.
 // This avoids import cycles between lint -> parser -> dialect -> lint.
 type RuleDef struct {
-	ID          string        // Unique identifier, e.g., "AM01" or "ansi/select-star"
-	Name        string        // Human-readable name, e.g., "ambiguous.distinct"
-	Group       string        // Category, e.g., "ambiguous", "structure", "convention"
-	Description string        // Human-readable description
-	Severity    core.Severity // Default severity
-	Check       CheckFunc     // The check function
-	ConfigKeys  []string      // Configuration keys this rule accepts (for rule-specific options)
-	Dialects    []string      // Restrict to specific dialects; nil/empty means all dialects
+	ID          string            // Unique identifier, e.g., "AM01" or "ansi/select-star"
+	Name        string            // Human-readable name, e.g., "ambiguous.distinct"
+	Group       string            // Category, e.g., "ambiguous", "structure", "convention"
+	Description string            // Human-readable description
+	Severity    core.Severity     // Default severity
+	Check       CheckFunc         // The check function
+	Options     []core.RuleOption // Options this rule accepts, validated when set in the config
+	ConfigKeys  []string          // Deprecated: use Options. Option names accepted without validation
+	Dialects    []string          // Restrict to specific dialects; nil/empty means all dialects
 
 	// Documentation fields for richer rule documentation
 	Rationale   string // Why this rule exists, what problems it prevents
@@ -47,9 +48,14 @@ func (w *wrappedRuleDef) Name() string                   { return w.def.Name }
 func (w *wrappedRuleDef) Group() string                  { return w.def.Group }
 func (w *wrappedRuleDef) Description() string            { return w.def.Description }
 func (w *wrappedRuleDef) DefaultSeverity() core.Severity { return w.def.Severity }
-func (w *wrappedRuleDef) ConfigKeys() []string           { return w.def.ConfigKeys }
+func (w *wrappedRuleDef) Options() []core.RuleOption     { return w.def.Options }
 func (w *wrappedRuleDef) Dialects() []string             { return w.def.Dialects }
 
+// ConfigKeys returns the names of the rule's options.
+func (w *wrappedRuleDef) ConfigKeys() []string {
+	return lint.OptionKeys(w.def.Options, w.def.ConfigKeys)
+}
+
 // Documentation methods
 func (w *wrappedRuleDef) Rationale() string   { return w.def.Rationale }
 func (w *wrappedRuleDef) BadExample() string  { return w.def.BadExample }
@@ -1,6 +1,8 @@
 package lint
 
 import (
+	"sort"
+
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/token"
 )
@@ -27,14 +29,15 @@ type DialectInfo interface {
 // The Check function receives an `any` type that should be *core.SelectStmt.
 // This avoids import cycles between lint -> parser -> dialect -> lint.
 type RuleDef struct {
-	ID          string        // Unique identifier, e.g., "AM01" or "ansi/select-star"
-	Name        string        // Human-readable name, e.g., "ambiguous.distinct"
-	Group       string        // Category, e.g., "ambiguous", "structure", "convention"
-	Description string        // Human-readable description
-	Severity    core.Severity // Default severity
-	Check       CheckFunc     // The check function
-	ConfigKeys  []string      // Configuration keys this rule accepts (for rule-specific options)
-	Dialects    []string      // Restrict to specific dialects; nil/empty means all dialects
+	ID          string            // Unique identifier, e.g., "AM01" or "ansi/select-star"
+	Name        string            // Human-readable name, e.g., "ambiguous.distinct"
+	Group       string            // Category, e.g., "ambiguous", "structure", "convention"
+	Description string            // Human-readable description
+	Severity    core.Severity     // Default severity
+	Check       CheckFunc         // The check function
+	Options     []core.RuleOption // Options this rule accepts, validated when set in the config
+	ConfigKeys  []string          // Deprecated: use Options. Option names accepted without validation
+	Dialects    []string          // Restrict to specific dialects; nil/empty means all dialects
 
 	// Documentation fields for richer rule documentation
 	Rationale   string // Why this rule exists, what problems it prevents
@@ -62,16 +65,64 @@ type Diagnostic struct {
 	Fixes    []Fix          // Optional: suggested fixes (for LSP code actions)
 
 	// Remediation metadata
-	DocumentationURL string        // URL to rule documentation, e.g., "https://leapsql.dev/docs/rules/AM01"
+	DocumentationURL string        // URL to rule documentation, e.g., "https://leapstack-labs.github.io/leapsql/linting/rules/am01"
 	ImpactScore      int           // 0-100, used for health score weighting
 	AutoFixable      bool          // true if Fixes can be auto-applied
 	RelatedInfo      []RelatedInfo // Additional locations/context
 }
 
-// RelatedInfo provides additional context for a diagnostic.
+// SortDiagnostics orders diagnostics by line, column and rule ID, so lint
+// output doesn't depend on the order rules run in. Diagnostics of a rule at
+// the same position keep the order the rule reported them in.
+func SortDiagnostics(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		if a.Pos.Line != b.Pos.Line {
+			return a.Pos.Line < b.Pos.Line
+		}
+		if a.Pos.Column != b.Pos.Column {
+			return a.Pos.Column < b.Pos.Column
+		}
+		return a.RuleID < b.RuleID
+	})
+}
+
+// MapRanges moves the ranges of diagnostics, and of their related locations
+// in the same file, with mapRange: for instance from SQL rendered from a
+// template back to the template. Without an end, a diagnostic gets one if
+// its position maps to a range. Fixes are left alone, as their edits apply
+// to the text the diagnostics were found in.
+func MapRanges(diags []Diagnostic, mapRange func(pos, end token.Position) (token.Position, token.Position)) {
+	move := func(pos, end *token.Position) {
+		if !pos.IsValid() {
+			return
+		}
+		if !end.IsValid() {
+			if start, stop := mapRange(*pos, *pos); stop.Offset > start.Offset {
+				*pos, *end = start, stop
+			} else {
+				*pos = start
+			}
+			return
+		}
+		*pos, *end = mapRange(*pos, *end)
+	}
+	for i := range diags {
+		move(&diags[i].Pos, &diags[i].EndPos)
+		for j := range diags[i].RelatedInfo {
+			if rel := &diags[i].RelatedInfo[j]; rel.FilePath == "" {
+				move(&rel.Pos, &rel.EndPos)
+			}
+		}
+	}
+}
+
+// RelatedInfo is a secondary location of a diagnostic, such as the other
+// half of a conflict. An empty FilePath means the diagnostic's own file.
 type RelatedInfo struct {
 	FilePath string
 	Pos      token.Position
+	EndPos   token.Position // Optional: end of the related range
 	Message  string
 }
 
@@ -79,6 +130,26 @@ type RelatedInfo struct {
 type Fix struct {
 	Description string
 	TextEdits   []TextEdit
+	Kind        FixKind // FixSafe unless the rewrite may change semantics
+}
+
+// FixKind classifies how safely a fix can be applied.
+type FixKind int
+
+const (
+	// FixSafe fixes preserve semantics and are applied by --fix.
+	FixSafe FixKind = iota
+	// FixSuggestion fixes are rewrites that may subtly change semantics.
+	// They are offered as LSP code actions and applied only by --fix --unsafe.
+	FixSuggestion
+)
+
+// String returns the name of the fix kind.
+func (k FixKind) String() string {
+	if k == FixSuggestion {
+		return "suggestion"
+	}
+	return "fix"
 }
 
 // TextEdit represents a text replacement.
@@ -110,7 +181,11 @@ type Rule interface {
 	// DefaultSeverity returns the default severity for this rule
 	DefaultSeverity() core.Severity
 
-	// ConfigKeys returns configuration keys this rule accepts
+	// Options returns the options this rule accepts, with their types,
+	// defaults and constraints
+	Options() []core.RuleOption
+
+	// ConfigKeys returns the names of the options this rule accepts
 	ConfigKeys() []string
 
 	// Documentation methods for richer rule documentation
@@ -152,6 +227,7 @@ func GetRuleInfo(r Rule) core.RuleInfo {
 		Description:     r.Description(),
 		DefaultSeverity: r.DefaultSeverity(),
 		ConfigKeys:      r.ConfigKeys(),
+		Options:         r.Options(),
 		Rationale:       r.Rationale(),
 		BadExample:      r.BadExample(),
 		GoodExample:     r.GoodExample(),
@@ -188,9 +264,14 @@ func (w *wrappedRuleDef) Name() string                   { return w.def.Name }
 func (w *wrappedRuleDef) Group() string                  { return w.def.Group }
 func (w *wrappedRuleDef) Description() string            { return w.def.Description }
 func (w *wrappedRuleDef) DefaultSeverity() core.Severity { return w.def.Severity }
-func (w *wrappedRuleDef) ConfigKeys() []string           { return w.def.ConfigKeys }
+func (w *wrappedRuleDef) Options() []core.RuleOption     { return w.def.Options }
 func (w *wrappedRuleDef) Dialects() []string             { return w.def.Dialects }
 
+// ConfigKeys returns the names of the rule's options.
+func (w *wrappedRuleDef) ConfigKeys() []string {
+	return OptionKeys(w.def.Options, w.def.ConfigKeys)
+}
+
 // Documentation methods
 func (w *wrappedRuleDef) Rationale() string   { return w.def.Rationale }
 func (w *wrappedRuleDef) BadExample() string  { return w.def.BadExample }
@@ -255,6 +336,7 @@ type ProjectContext interface {
 // This mirrors the data needed from parser.ModelConfig without importing it.
 type ModelInfo struct {
 	Path         string            // Model path (e.g., "staging.customers")
+	Project      string            // Workspace project (empty outside a workspace)
 	Name         string            // Model name (e.g., "stg_customers")
 	FilePath     string            // Absolute path to .sql file
 	Type         core.ModelType    // Inferred or explicit model type
@@ -263,6 +345,12 @@ type ModelInfo struct {
 	Materialized string            // table, view, incremental
 	Tags         []string          // Metadata tags
 	Meta         map[string]any    // Custom metadata
+	Version      int               // Model version (0 if unversioned)
+	Deprecated   *core.Deprecation // Deprecation notice (nil if not deprecated)
+	Access       core.Access       // Access level (empty means protected)
+	Owner        string            // Owner from frontmatter
+	Group        string            // Owning group from frontmatter
+	PII          []string          // PII columns from frontmatter
 }
 
 // ProjectHealthConfig holds configurable thresholds for project health rules.
@@ -271,6 +359,16 @@ type ProjectHealthConfig struct {
 	TooManyJoinsThreshold       int // PM05: default 7
 	PassthroughColumnThreshold  int // PL01: default 20
 	StarlarkComplexityThreshold int // PT01: default 10
+
+	// Groups holds the model groups declared in config, keyed by name (PM10, PM11)
+	Groups map[string]core.GroupConfig
+
+	// PIIApproved lists the models, by path or name, allowed to expose PII columns (PL06)
+	PIIApproved []string
+
+	// Layers holds the model layers declared in config; nil means
+	// core.DefaultLayers (PM01, PM02, PM03, PM06, PS01, PS02)
+	Layers []core.LayerConfig
 }
 
 // DefaultProjectHealthConfig returns the default configuration.
@@ -282,3 +380,15 @@ func DefaultProjectHealthConfig() ProjectHealthConfig {
 		StarlarkComplexityThreshold: 10,
 	}
 }
+
+// GroupsByName indexes declared model groups by name for ProjectHealthConfig.Groups.
+func GroupsByName(groups []core.GroupConfig) map[string]core.GroupConfig {
+	if len(groups) == 0 {
+		return nil
+	}
+	result := make(map[string]core.GroupConfig, len(groups))
+	for _, g := range groups {
+		result[g.Name] = g
+	}
+	return result
+}
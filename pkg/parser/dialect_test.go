@@ -276,6 +276,30 @@ func TestDuckDBClauseSequenceWithQualify(t *testing.T) {
 	assert.NotNil(t, core.Limit, "LIMIT should be parsed")
 }
 
+func TestWindowClause(t *testing.T) {
+	sql := `SELECT rank() OVER w, sum(x) OVER (w ROWS UNBOUNDED PRECEDING)
+	FROM t
+	WINDOW w AS (PARTITION BY a ORDER BY b), v AS (w)
+	ORDER BY a`
+
+	stmt, err := parser.ParseWithDialect(sql, postgresDialect.Postgres)
+	require.NoError(t, err)
+
+	sc := stmt.Body.Left
+	require.Len(t, sc.Windows, 2)
+	assert.Equal(t, "w", sc.Windows[0].Name)
+	assert.Len(t, sc.Windows[0].Spec.PartitionBy, 1)
+	assert.Len(t, sc.Windows[0].Spec.OrderBy, 1)
+	assert.Equal(t, "v", sc.Windows[1].Name)
+	assert.Equal(t, "w", sc.Windows[1].Spec.Name)
+	assert.NotNil(t, sc.OrderBy, "ORDER BY should be parsed after WINDOW")
+
+	sum, ok := sc.Columns[1].Expr.(*core.FuncCall)
+	require.True(t, ok)
+	assert.Equal(t, "w", sum.Window.Name)
+	assert.NotNil(t, sum.Window.Frame)
+}
+
 // ---------- Dialect Registration Tests ----------
 
 func TestDialectRegistration(t *testing.T) {
@@ -827,3 +851,132 @@ func TestSelectNameAsIdentifier(t *testing.T) {
 		})
 	}
 }
+
+// ---------- Nested Data Access Tests ----------
+
+func TestStructFieldAccess(t *testing.T) {
+	stmt, err := parser.ParseWithDialect(`SELECT u.address.geo.lat FROM users u`, duckdbDialect.DuckDB)
+	require.NoError(t, err)
+
+	ref, ok := stmt.Body.Left.Columns[0].Expr.(*core.ColumnRef)
+	require.True(t, ok)
+	assert.Equal(t, "u", ref.Table)
+	assert.Equal(t, "address", ref.Column)
+	assert.Equal(t, []string{"geo", "lat"}, ref.Fields)
+
+	output := format.Format(stmt, duckdbDialect.DuckDB)
+	assert.Contains(t, output, "u.address.geo.lat")
+}
+
+func TestJSONOperators(t *testing.T) {
+	tests := []struct {
+		name    string
+		sql     string
+		dialect *core.Dialect
+		wantOp  token.TokenType
+	}{
+		{"duckdb arrow", `SELECT payload->'customer' FROM events`, duckdbDialect.DuckDB, token.ARROW},
+		{"duckdb double arrow", `SELECT payload->>'$.customer.id' FROM events`, duckdbDialect.DuckDB, token.DARROW},
+		{"duckdb arrow index", `SELECT payload->0 FROM events`, duckdbDialect.DuckDB, token.ARROW},
+		{"postgres arrow", `SELECT payload->'customer' FROM events`, postgresDialect.Postgres, token.ARROW},
+		{"postgres double arrow", `SELECT payload->>'id' FROM events`, postgresDialect.Postgres, token.DARROW},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(tt.sql, tt.dialect)
+			require.NoError(t, err)
+
+			bin, ok := stmt.Body.Left.Columns[0].Expr.(*core.BinaryExpr)
+			require.True(t, ok, "expected BinaryExpr, got %T", stmt.Body.Left.Columns[0].Expr)
+			assert.Equal(t, tt.wantOp, bin.Op)
+			assert.Equal(t, &core.ColumnRef{Column: "payload"}, bin.Left)
+		})
+	}
+}
+
+func TestJSONOperatorsChainAndCompare(t *testing.T) {
+	sql := `SELECT * FROM events WHERE payload->'customer'->>'id' = '42'`
+	stmt, err := parser.ParseWithDialect(sql, postgresDialect.Postgres)
+	require.NoError(t, err)
+
+	cmp, ok := stmt.Body.Left.Where.(*core.BinaryExpr)
+	require.True(t, ok)
+	assert.Equal(t, token.EQ, cmp.Op)
+
+	outer, ok := cmp.Left.(*core.BinaryExpr)
+	require.True(t, ok)
+	assert.Equal(t, token.DARROW, outer.Op)
+
+	inner, ok := outer.Left.(*core.BinaryExpr)
+	require.True(t, ok)
+	assert.Equal(t, token.ARROW, inner.Op)
+}
+
+func TestDuckDBArrowStillParsesLambdas(t *testing.T) {
+	stmt, err := parser.ParseWithDialect(`SELECT list_transform(xs, x -> x + 1) FROM t`, duckdbDialect.DuckDB)
+	require.NoError(t, err)
+
+	fn, ok := stmt.Body.Left.Columns[0].Expr.(*core.FuncCall)
+	require.True(t, ok)
+	require.Len(t, fn.Args, 2)
+	_, ok = fn.Args[1].(*core.LambdaExpr)
+	assert.True(t, ok, "expected LambdaExpr, got %T", fn.Args[1])
+}
+
+// ---------- Subquery Expression Tests ----------
+
+func TestQuantifiedSubquery(t *testing.T) {
+	tests := []struct {
+		sql        string
+		op         token.TokenType
+		quantifier string
+	}{
+		{"SELECT * FROM t WHERE a <> ALL (SELECT b FROM s)", token.NE, "ALL"},
+		{"SELECT * FROM t WHERE a > any (SELECT b FROM s)", token.GT, "ANY"},
+		{"SELECT * FROM t WHERE a = SOME (WITH x AS (SELECT 1 AS b) SELECT b FROM x)", token.EQ, "SOME"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(tt.sql, duckdbDialect.DuckDB)
+			require.NoError(t, err)
+
+			bin, ok := stmt.Body.Left.Where.(*core.BinaryExpr)
+			require.True(t, ok, "WHERE should contain a comparison")
+			assert.Equal(t, tt.op, bin.Op)
+
+			sub, ok := bin.Right.(*core.SubqueryExpr)
+			require.True(t, ok, "the comparison should be against a subquery")
+			assert.Equal(t, tt.quantifier, sub.Quantifier)
+			assert.NotNil(t, sub.Select)
+		})
+	}
+}
+
+func TestQuantifiedSubquery_AnyFunction(t *testing.T) {
+	stmt, err := parser.ParseWithDialect("SELECT any(flag) FROM t", duckdbDialect.DuckDB)
+	require.NoError(t, err)
+
+	fn, ok := stmt.Body.Left.Columns[0].Expr.(*core.FuncCall)
+	require.True(t, ok, "ANY without a subquery is a function call")
+	assert.Equal(t, "ANY", fn.Name)
+
+	_, err = parser.ParseWithDialect("SELECT * FROM t WHERE a = ALL (1, 2)", duckdbDialect.DuckDB)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected subquery after ALL")
+}
+
+func TestInExprSpan(t *testing.T) {
+	sql := "SELECT * FROM t WHERE x = 1 AND t.a + 1 NOT IN (SELECT b FROM s)"
+
+	stmt, err := parser.ParseWithDialect(sql, duckdbDialect.DuckDB)
+	require.NoError(t, err)
+
+	and, ok := stmt.Body.Left.Where.(*core.BinaryExpr)
+	require.True(t, ok)
+	in, ok := and.Right.(*core.InExpr)
+	require.True(t, ok)
+	assert.Equal(t, "t.a + 1 NOT IN (SELECT b FROM s)", sql[in.Span.Start.Offset:in.Span.End.Offset])
+	assert.Equal(t, in.Span.Start, in.Pos())
+}
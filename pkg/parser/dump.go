@@ -0,0 +1,144 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/token"
+)
+
+var (
+	spanType     = reflect.TypeOf(token.Span{})
+	positionType = reflect.TypeOf(token.Position{})
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// FprintAST writes a tree of node and its children to w, one field per line,
+// for debugging the parser. Fields with zero values are left out, and the
+// source span of a node follows its type, e.g.:
+//
+//	SelectStmt @1:1-1:21
+//	  Body: SelectBody @1:1-1:21
+//	    Left: SelectCore @1:1-1:21
+//	      Columns:
+//	        0: SelectItem @1:8-1:10
+//	          Expr: ColumnRef
+//	            Column: "id"
+func FprintAST(w io.Writer, node any) error {
+	p := &astPrinter{w: w}
+	p.value(reflect.ValueOf(node), 0, "")
+	p.newline()
+	return p.err
+}
+
+// astPrinter writes the tree of FprintAST.
+type astPrinter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *astPrinter) printf(format string, args ...any) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+func (p *astPrinter) newline() {
+	p.printf("\n")
+}
+
+// field writes the field's name on a new line at the given depth, then its
+// value.
+func (p *astPrinter) field(name string, v reflect.Value, depth int) {
+	p.newline()
+	p.printf("%s%s:", strings.Repeat("  ", depth), name)
+	p.value(v, depth, " ")
+}
+
+// value writes v after sep on the current line, and its children on the
+// lines below at depth+1.
+func (p *astPrinter) value(v reflect.Value, depth int, sep string) {
+	for !v.IsValid() || v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if !v.IsValid() || v.IsNil() {
+			p.printf("%snil", sep)
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch {
+	case v.Type() == positionType:
+		p.printf("%s%s", sep, formatPosition(v.Interface().(token.Position)))
+	case v.Type() == spanType:
+		p.printf("%s%s", sep, formatSpan(v.Interface().(token.Span)))
+	case v.Type().Implements(stringerType) && v.Kind() != reflect.Struct:
+		p.printf("%s%s", sep, v.Interface().(fmt.Stringer).String())
+	case v.Kind() == reflect.Struct:
+		p.printf("%s", sep)
+		p.structValue(v, depth)
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() == reflect.String:
+		p.printf("%s%q", sep, v.Interface())
+	case v.Kind() == reflect.Slice || v.Kind() == reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			p.field(fmt.Sprint(i), v.Index(i), depth+1)
+		}
+	case v.Kind() == reflect.String:
+		p.printf("%s%q", sep, v.String())
+	default:
+		p.printf("%s%v", sep, v.Interface())
+	}
+}
+
+// structValue writes a struct's type and span, then its non-zero exported
+// fields. The span of a node comes from its embedded NodeInfo or Span field;
+// comments are left out.
+func (p *astPrinter) structValue(v reflect.Value, depth int) {
+	t := v.Type()
+	p.printf("%s", t.Name())
+	if span, ok := nodeSpan(v); ok {
+		p.printf(" @%s", formatSpan(span))
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+		if !f.IsExported() || fv.IsZero() || f.Name == "NodeInfo" || (f.Name == "Span" && f.Type == spanType) {
+			continue
+		}
+		p.field(f.Name, fv, depth+1)
+	}
+}
+
+// nodeSpan returns the source span a node records, if any.
+func nodeSpan(v reflect.Value) (token.Span, bool) {
+	for _, name := range []string{"NodeInfo", "Span"} {
+		f := v.FieldByName(name)
+		if !f.IsValid() {
+			continue
+		}
+		if name == "NodeInfo" {
+			f = f.FieldByName("Span")
+		}
+		if f.Type() != spanType {
+			continue
+		}
+		span := f.Interface().(token.Span)
+		if span.Start.IsValid() {
+			return span, true
+		}
+	}
+	return token.Span{}, false
+}
+
+func formatPosition(pos token.Position) string {
+	return fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+}
+
+func formatSpan(span token.Span) string {
+	if !span.End.IsValid() {
+		return formatPosition(span.Start)
+	}
+	return formatPosition(span.Start) + "-" + formatPosition(span.End)
+}
@@ -0,0 +1,94 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/parser"
+	"github.com/leapstack-labs/leapsql/pkg/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	duckdbDialect "github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+)
+
+func TestFprintAST(t *testing.T) {
+	stmt, err := parser.ParseWithDialect("SELECT id, SUM(x) AS s\nFROM orders o", duckdbDialect.DuckDB)
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, parser.FprintAST(&sb, stmt))
+	out := sb.String()
+
+	assert.True(t, strings.HasPrefix(out, "SelectStmt"), "output starts with the root node: %s", out)
+	assert.Contains(t, out, "        0: SelectItem @1:8-1:10\n")
+	assert.Contains(t, out, "          Expr: ColumnRef\n            Column: \"id\"\n")
+	assert.Contains(t, out, "Name: \"SUM\"")
+	assert.Contains(t, out, "Alias: \"s\"")
+	assert.Contains(t, out, "Source: TableName @2:6")
+	assert.NotContains(t, out, "NodeInfo", "embedded node info is printed as the span")
+	assert.NotContains(t, out, "Where:", "zero fields are left out")
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		assert.Equal(t, strings.TrimRight(line, " "), line, "line has trailing spaces")
+	}
+}
+
+func TestFprintAST_Nil(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, parser.FprintAST(&sb, nil))
+	assert.Equal(t, "nil\n", sb.String())
+}
+
+func TestTokenizeWithDialect(t *testing.T) {
+	tokens := parser.TokenizeWithDialect("SELECT a\nFROM t QUALIFY a = 1", duckdbDialect.DuckDB)
+	require.NotEmpty(t, tokens)
+
+	last := tokens[len(tokens)-1]
+	assert.Equal(t, token.EOF, last.Type)
+
+	assert.Equal(t, "SELECT", tokens[0].Literal)
+	assert.Equal(t, 1, tokens[0].Pos.Line)
+	assert.Equal(t, 1, tokens[0].Pos.Column)
+
+	var from token.Token
+	for _, tok := range tokens {
+		if tok.Literal == "FROM" {
+			from = tok
+		}
+	}
+	assert.Equal(t, 2, from.Pos.Line)
+	assert.Equal(t, 1, from.Pos.Column)
+}
+
+func TestMarshalAST_RoundTripParsed(t *testing.T) {
+	queries := []string{
+		"SELECT id, SUM(amount) AS total FROM orders o WHERE status = 'paid' GROUP BY id HAVING SUM(amount) > 10 ORDER BY total DESC NULLS LAST LIMIT 5",
+		"WITH recent AS (SELECT * EXCLUDE (raw) FROM events) SELECT r.id FROM recent r LEFT JOIN users u USING (id)",
+		"SELECT CASE WHEN a IS NOT NULL THEN 1 ELSE 0 END, CAST(b AS VARCHAR), c BETWEEN 1 AND 2 FROM t",
+		"SELECT name FROM t WHERE name ILIKE 'a%' AND id IN (SELECT id FROM s) AND EXISTS (SELECT 1 FROM u)",
+		"SELECT ROW_NUMBER() OVER (PARTITION BY dept ORDER BY salary DESC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS rn FROM emp QUALIFY rn = 1",
+		"SELECT list_transform([1, 2, 3], x -> x * 2), {'a': 1}, arr[1:2] FROM t",
+		"SELECT a FROM t UNION ALL SELECT b FROM s",
+		"SELECT a FROM t WHERE a <> ALL (SELECT b FROM s) AND a NOT IN (1, 2)",
+		"-- leading\nSELECT a /* inline */ FROM {{ ref('orders') }}",
+	}
+
+	for _, sql := range queries {
+		t.Run(sql, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(sql, duckdbDialect.DuckDB)
+			require.NoError(t, err)
+
+			data, err := core.MarshalAST(stmt)
+			require.NoError(t, err)
+
+			node, err := core.UnmarshalAST(data)
+			require.NoError(t, err)
+			require.IsType(t, &core.SelectStmt{}, node)
+
+			again, err := core.MarshalAST(node)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(data), string(again))
+		})
+	}
+}
@@ -453,6 +453,21 @@ func Tokenize(input string) []Token {
 	return tokens
 }
 
+// TokenizeWithDialect returns all tokens from the input, lexed with the
+// keywords and operators of dialect d. The last token is EOF.
+func TokenizeWithDialect(input string, d *core.Dialect) []Token {
+	l := NewLexerWithDialect(input, d)
+	var tokens []Token
+	for {
+		tok := l.NextToken()
+		tokens = append(tokens, tok)
+		if tok.Type == TOKEN_EOF {
+			break
+		}
+	}
+	return tokens
+}
+
 // readMacro scans a {{ ... }} macro token.
 // Handles nested braces and skips over quoted strings to avoid
 // miscounting braces inside string literals.
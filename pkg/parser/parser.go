@@ -27,6 +27,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -42,6 +43,9 @@ type Parser struct {
 	peek2   Token // second lookahead token
 	errors  []error
 	dialect *core.Dialect // required
+
+	// End positions of the previous, current and lookahead tokens
+	prevEnd, tokenEndPos, peekEnd, peek2End token.Position
 }
 
 // NewParser creates a new parser for the given SQL input with dialect support.
@@ -67,6 +71,15 @@ func ParseWithDialect(sql string, d *core.Dialect) (*core.SelectStmt, error) {
 	return stmt, nil
 }
 
+// ParseTolerant parses the SQL like ParseWithDialect, but doesn't stop at
+// errors: it returns the statement parsed despite them, along with every
+// error. The statement may be incomplete where errors occurred.
+func ParseTolerant(sql string, d *core.Dialect) (*core.SelectStmt, []error) {
+	p := NewParser(sql, d)
+	stmt := p.parseStatement()
+	return stmt, p.errors
+}
+
 // Dialect returns the parser's dialect, if any.
 func (p *Parser) Dialect() *core.Dialect {
 	return p.dialect
@@ -76,9 +89,11 @@ func (p *Parser) Dialect() *core.Dialect {
 
 // nextToken advances to the next token.
 func (p *Parser) nextToken() {
-	p.token = p.peek
-	p.peek = p.peek2
+	p.prevEnd = p.tokenEndPos
+	p.token, p.tokenEndPos = p.peek, p.peekEnd
+	p.peek, p.peekEnd = p.peek2, p.peek2End
 	p.peek2 = p.lexer.NextToken()
+	p.peek2End = p.lexer.currentPos()
 }
 
 // check returns true if the current token is of the given type.
@@ -144,6 +159,23 @@ func (p *Parser) addError(msg string) {
 	})
 }
 
+// addErr adds an error returned while parsing a nested construct. Parse
+// errors are kept with their own position, and not added again if the
+// parser already recorded them.
+func (p *Parser) addErr(err error) {
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		p.addError(err.Error())
+		return
+	}
+	for _, e := range p.errors {
+		if existing, ok := e.(*ParseError); ok && *existing == *pe {
+			return
+		}
+	}
+	p.errors = append(p.errors, pe)
+}
+
 // ---------- Keyword Helpers ----------
 
 // isKeyword returns true if the token is a reserved keyword that can't be used as alias.
@@ -280,6 +312,15 @@ func (p *Parser) ParseIdentifier() (string, error) {
 	}
 }
 
+// ParseWindowSpec parses a window specification (implements spi.ParserOps).
+func (p *Parser) ParseWindowSpec() (*core.WindowSpec, error) {
+	spec := p.parseWindowSpec()
+	if len(p.errors) > 0 {
+		return nil, p.errors[len(p.errors)-1]
+	}
+	return spec, nil
+}
+
 // AddError adds a parse error (implements spi.ParserOps).
 func (p *Parser) AddError(msg string) {
 	p.addError(msg)
@@ -304,6 +345,12 @@ func (p *Parser) makeSpan(start token.Position) token.Span {
 	return token.Span{Start: start, End: p.tokenEnd()}
 }
 
+// spanFrom creates a span from start position to the end of the last
+// consumed token, for nodes whose parsing has just finished.
+func (p *Parser) spanFrom(start token.Position) token.Span {
+	return token.Span{Start: start, End: p.prevEnd}
+}
+
 // Comments returns the comments collected during lexing.
 // Call this after parsing to get all comments for the formatter.
 func (p *Parser) Comments() []*token.Comment {
@@ -31,6 +31,8 @@ func (p *Parser) parseExpression() core.Expr {
 
 // parseExpressionWithPrecedence implements Pratt parsing with dialect-aware precedence.
 func (p *Parser) parseExpressionWithPrecedence(minPrecedence int) core.Expr {
+	start := p.token.Pos
+
 	// Parse prefix (unary operators and primary expressions)
 	left := p.parsePrefixExpr()
 	if left == nil {
@@ -48,6 +50,10 @@ func (p *Parser) parseExpressionWithPrecedence(minPrecedence int) core.Expr {
 		if left == nil {
 			break
 		}
+		// IN spans its operand, which was parsed before the IN keyword
+		if in, ok := left.(*core.InExpr); ok && !in.Span.Start.IsValid() {
+			in.Span = p.spanFrom(start)
+		}
 	}
 
 	return left
@@ -158,7 +164,7 @@ func (p *Parser) parseInfixExpr(left core.Expr, prec int) core.Expr {
 			p.nextToken()
 			result, err := handler(p, left)
 			if err != nil {
-				p.addError(err.Error())
+				p.addErr(err)
 				return left
 			}
 			if result != nil {
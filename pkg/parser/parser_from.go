@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/spi"
 )
@@ -10,8 +12,9 @@ import (
 // Grammar:
 //
 //	from_clause   → table_ref (join)*
-//	table_ref     → table_name | derived_table | lateral_table
+//	table_ref     → table_name | table_func | derived_table | lateral_table
 //	table_name    → [catalog "."] [schema "."] identifier [AS identifier]
+//	table_func    → identifier "(" [expr ("," expr)*] ")" [[AS] identifier ["(" identifier ("," identifier)* ")"]]
 //	derived_table → "(" statement ")" [AS] identifier
 //	lateral_table → LATERAL "(" statement ")" [AS] identifier
 //	join          → join_type JOIN table_ref [ON expr] | "," table_ref
@@ -59,7 +62,7 @@ func (p *Parser) parseFromItemExtensions(source core.TableRef) core.TableRef {
 
 		result, err := handler(p, source)
 		if err != nil {
-			p.addError(err.Error())
+			p.addErr(err)
 			break
 		}
 
@@ -72,8 +75,11 @@ func (p *Parser) parseFromItemExtensions(source core.TableRef) core.TableRef {
 // parseTableRef parses a table reference.
 func (p *Parser) parseTableRef() core.TableRef {
 	// LATERAL subquery
+	start := p.token.Pos
 	if p.match(TOKEN_LATERAL) {
-		return p.parseLateralTable()
+		lateral := p.parseLateralTable()
+		lateral.Span = p.spanFrom(start)
+		return lateral
 	}
 
 	// Derived table (subquery)
@@ -86,13 +92,67 @@ func (p *Parser) parseTableRef() core.TableRef {
 		return p.parseMacroTable()
 	}
 
+	// Table-valued function (e.g., generate_series(1, 10), UNNEST(arr))
+	if p.check(TOKEN_IDENT) && p.checkPeek(TOKEN_LPAREN) {
+		return p.parseTableFunction()
+	}
+
 	// Simple table name
 	return p.parseTableName()
 }
 
+// parseTableFunction parses a table-valued function call with optional
+// table alias and column alias list.
+func (p *Parser) parseTableFunction() *core.TableFunction {
+	fn := &core.TableFunction{Name: strings.ToUpper(p.token.Literal)}
+	p.nextToken()
+
+	p.expect(TOKEN_LPAREN)
+	if !p.check(TOKEN_RPAREN) {
+		for {
+			fn.Args = append(fn.Args, p.parseExpression())
+			if !p.match(TOKEN_COMMA) {
+				break
+			}
+		}
+	}
+	p.expect(TOKEN_RPAREN)
+
+	// Optional alias
+	if p.match(TOKEN_AS) {
+		if p.check(TOKEN_IDENT) {
+			fn.Alias = p.token.Literal
+			p.nextToken()
+		}
+	} else if p.check(TOKEN_IDENT) && !p.isJoinKeyword(p.token) && !p.isClauseKeyword(p.token) {
+		fn.Alias = p.token.Literal
+		p.nextToken()
+	}
+
+	// Optional column alias list: AS t(a, b)
+	if fn.Alias != "" && p.match(TOKEN_LPAREN) {
+		for {
+			if !p.check(TOKEN_IDENT) {
+				p.addError("expected column alias")
+				break
+			}
+			fn.ColumnAliases = append(fn.ColumnAliases, p.token.Literal)
+			p.nextToken()
+			if !p.match(TOKEN_COMMA) {
+				break
+			}
+		}
+		p.expect(TOKEN_RPAREN)
+	}
+
+	return fn
+}
+
 // parseTableName parses a table name with optional schema/catalog.
 func (p *Parser) parseTableName() *core.TableName {
 	table := &core.TableName{}
+	start := p.token.Pos
+	defer func() { table.Span = p.spanFrom(start) }()
 
 	if !p.check(TOKEN_IDENT) {
 		p.addError("expected table name")
@@ -138,6 +198,7 @@ func (p *Parser) parseTableName() *core.TableName {
 
 // parseDerivedTable parses a derived table (subquery in FROM).
 func (p *Parser) parseDerivedTable() *core.DerivedTable {
+	start := p.token.Pos
 	p.expect(TOKEN_LPAREN)
 	derived := &core.DerivedTable{}
 	derived.Select = p.parseStatement()
@@ -154,6 +215,7 @@ func (p *Parser) parseDerivedTable() *core.DerivedTable {
 		p.nextToken()
 	}
 
+	derived.Span = p.spanFrom(start)
 	return derived
 }
 
@@ -202,12 +264,14 @@ func (p *Parser) parseMacroTable() *core.MacroTable {
 
 // parseJoin parses a JOIN clause.
 func (p *Parser) parseJoin() *core.Join {
+	start := p.token.Pos
 	join := &core.Join{}
 
 	// Comma join (implicit cross join) - hardcoded special case
 	if p.match(TOKEN_COMMA) {
 		join.Type = core.JoinComma
 		join.Right = p.parseTableRef()
+		join.Span = p.spanFrom(start)
 		return join
 	}
 
@@ -239,6 +303,7 @@ func (p *Parser) parseJoin() *core.Join {
 
 			join.Right = p.parseTableRef()
 			p.parseJoinCondition(join)
+			join.Span = p.spanFrom(start)
 			return join
 		}
 	}
@@ -260,6 +325,7 @@ func (p *Parser) parseJoin() *core.Join {
 
 	join.Right = p.parseTableRef()
 	p.parseJoinCondition(join)
+	join.Span = p.spanFrom(start)
 	return join
 }
 
@@ -274,8 +340,11 @@ func (p *Parser) parseJoinCondition(join *core.Join) {
 		if p.check(TOKEN_USING) {
 			p.addError("NATURAL JOIN cannot have USING clause")
 		}
-	case p.match(TOKEN_ON):
+	case p.check(TOKEN_ON):
+		start := p.token.Pos
+		p.nextToken()
 		join.Condition = p.parseExpression()
+		join.ConditionSpan = p.spanFrom(start)
 	case p.match(TOKEN_USING):
 		join.Using = p.parseUsingColumns()
 	}
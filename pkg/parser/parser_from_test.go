@@ -144,6 +144,101 @@ func TestJoinUsing(t *testing.T) {
 	}
 }
 
+// ---------- Table Function Tests ----------
+
+func TestTableFunction(t *testing.T) {
+	tests := []struct {
+		name        string
+		sql         string
+		wantName    string
+		wantArgs    int
+		wantAlias   string
+		wantColumns []string
+	}{
+		{
+			name:     "no alias",
+			sql:      "SELECT * FROM generate_series(1, 10)",
+			wantName: "GENERATE_SERIES",
+			wantArgs: 2,
+		},
+		{
+			name:        "alias with column list",
+			sql:         "SELECT i FROM generate_series(1, 10) AS t(i)",
+			wantName:    "GENERATE_SERIES",
+			wantArgs:    2,
+			wantAlias:   "t",
+			wantColumns: []string{"i"},
+		},
+		{
+			name:      "implicit alias",
+			sql:       "SELECT * FROM read_csv('data.csv') src",
+			wantName:  "READ_CSV",
+			wantArgs:  1,
+			wantAlias: "src",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := parser.ParseWithDialect(tt.sql, duckdbdialect.DuckDB)
+			require.NoError(t, err)
+
+			fn, ok := stmt.Body.Left.From.Source.(*core.TableFunction)
+			require.True(t, ok, "expected TableFunction, got %T", stmt.Body.Left.From.Source)
+			assert.Equal(t, tt.wantName, fn.Name)
+			assert.Len(t, fn.Args, tt.wantArgs)
+			assert.Equal(t, tt.wantAlias, fn.Alias)
+			assert.Equal(t, tt.wantColumns, fn.ColumnAliases)
+		})
+	}
+}
+
+func TestTableFunctionInCommaJoin(t *testing.T) {
+	sql := "SELECT p.id, t.tag FROM posts p, UNNEST(p.tags) AS t(tag) WHERE t.tag <> ''"
+	stmt, err := parser.ParseWithDialect(sql, duckdbdialect.DuckDB)
+	require.NoError(t, err)
+
+	require.Len(t, stmt.Body.Left.From.Joins, 1)
+	fn, ok := stmt.Body.Left.From.Joins[0].Right.(*core.TableFunction)
+	require.True(t, ok)
+	assert.Equal(t, "UNNEST", fn.Name)
+	require.Len(t, fn.Args, 1)
+	assert.Equal(t, &core.ColumnRef{Table: "p", Column: "tags"}, fn.Args[0])
+	assert.NotNil(t, stmt.Body.Left.Where)
+}
+
+func TestTableRefSpans(t *testing.T) {
+	sql := "SELECT id, name\nFROM users AS u\nJOIN (SELECT 1 AS x) d ON true"
+	stmt, err := parser.ParseWithDialect(sql, duckdbdialect.DuckDB)
+	require.NoError(t, err)
+
+	sc := stmt.Body.Left
+	assert.Equal(t, 0, sc.Span.Start.Offset)
+	assert.Equal(t, len(sql), sc.Span.End.Offset)
+	assert.Equal(t, "id, name", sql[sc.ColumnsSpan.Start.Offset:sc.ColumnsSpan.End.Offset])
+
+	table, ok := sc.From.Source.(*core.TableName)
+	require.True(t, ok)
+	assert.Equal(t, "users AS u", sql[table.Span.Start.Offset:table.Span.End.Offset])
+	assert.Equal(t, 2, table.Span.Start.Line)
+
+	derived, ok := sc.From.Joins[0].Right.(*core.DerivedTable)
+	require.True(t, ok)
+	assert.Equal(t, "(SELECT 1 AS x) d", sql[derived.Span.Start.Offset:derived.Span.End.Offset])
+}
+
+func TestSelectItemSpans(t *testing.T) {
+	sql := "SELECT id, amount * 2 AS doubled, u.*, name n FROM users u"
+	stmt, err := parser.ParseWithDialect(sql, duckdbdialect.DuckDB)
+	require.NoError(t, err)
+
+	var items []string
+	for _, item := range stmt.Body.Left.Columns {
+		items = append(items, sql[item.Span.Start.Offset:item.Span.End.Offset])
+	}
+	assert.Equal(t, []string{"id", "amount * 2 AS doubled", "u.*", "name n"}, items)
+}
+
 // ---------- FETCH Clause Tests ----------
 
 func TestFetchClause(t *testing.T) {
@@ -305,6 +400,15 @@ func TestFormatFetch(t *testing.T) {
 	}
 }
 
+func TestFormatTableFunction(t *testing.T) {
+	sql := "SELECT t.i FROM generate_series(1, 10) AS t(i)"
+	stmt, err := parser.ParseWithDialect(sql, duckdbdialect.DuckDB)
+	require.NoError(t, err)
+
+	output := format.Format(stmt, duckdbdialect.DuckDB)
+	assert.Contains(t, output, "GENERATE_SERIES(1, 10) t(i)")
+}
+
 // ---------- Multiple Join Tests ----------
 
 func TestMultipleJoinsWithDifferentStyles(t *testing.T) {
@@ -533,3 +637,15 @@ func getDuckDBDialect(t *testing.T) *core.Dialect {
 	require.True(t, ok, "DuckDB dialect should be registered")
 	return d
 }
+
+func TestCTESpans(t *testing.T) {
+	sql := "WITH a AS (SELECT 1 AS x), b AS (\n  SELECT x FROM a\n)\nSELECT * FROM b"
+	stmt, err := parser.ParseWithDialect(sql, duckdbdialect.DuckDB)
+	require.NoError(t, err)
+
+	with := stmt.With
+	assert.Equal(t, "WITH a AS (SELECT 1 AS x), b AS (\n  SELECT x FROM a\n)", sql[with.Span.Start.Offset:with.Span.End.Offset])
+	require.Len(t, with.CTEs, 2)
+	assert.Equal(t, "a AS (SELECT 1 AS x)", sql[with.CTEs[0].Span.Start.Offset:with.CTEs[0].Span.End.Offset])
+	assert.Equal(t, "b AS (\n  SELECT x FROM a\n)", sql[with.CTEs[1].Span.Start.Offset:with.CTEs[1].Span.End.Offset])
+}
@@ -13,7 +13,7 @@ import (
 //
 // Grammar:
 //
-//	primary       → literal | column_ref | func_call | paren_expr | case_expr | cast_expr | exists_expr
+//	primary       → literal | column_ref | func_call | paren_expr | case_expr | cast_expr | exists_expr | quantified
 //	literal       → NUMBER | STRING | TRUE | FALSE | NULL
 //	column_ref    → [table "."] column | [schema "." table "."] column
 //	func_call     → identifier "(" [DISTINCT] [expr_list | "*"] ")" [FILTER "(" WHERE expr ")"] [OVER window_spec]
@@ -27,7 +27,7 @@ func (p *Parser) parsePrimary() core.Expr {
 			p.nextToken() // consume the prefix token
 			expr, err := handler(p)
 			if err != nil {
-				p.addError(err.Error())
+				p.addErr(err)
 				return nil
 			}
 			if expr != nil {
@@ -79,6 +79,10 @@ func (p *Parser) parsePrimary() core.Expr {
 	case TOKEN_EXISTS:
 		return p.parseExistsExpr(false)
 
+	case TOKEN_ALL:
+		p.nextToken()
+		return p.parseQuantifiedSubquery("ALL")
+
 	case TOKEN_IDENT:
 		return p.parseIdentifierExpr()
 
@@ -113,6 +117,12 @@ func (p *Parser) parseIdentifierExpr() core.Expr {
 	name := p.token.Literal
 	p.nextToken()
 
+	// ANY and SOME quantify a subquery; otherwise they name functions
+	if upper := strings.ToUpper(name); (upper == "ANY" || upper == "SOME") && p.check(TOKEN_LPAREN) &&
+		(p.checkPeek(TOKEN_SELECT) || p.checkPeek(TOKEN_WITH)) {
+		return p.parseQuantifiedSubquery(upper)
+	}
+
 	// Check if it's a function call
 	if p.check(TOKEN_LPAREN) {
 		return p.parseFuncCall(name)
@@ -132,10 +142,10 @@ func (p *Parser) parseQualifiedColumnRef(firstPart string) core.Expr {
 	parts := []string{firstPart}
 
 	for p.match(TOKEN_DOT) {
-		// Check for table.*
+		// Check for table.*, schema.table.* or catalog.schema.table.*
 		if p.check(TOKEN_STAR) {
 			p.nextToken()
-			return &core.StarExpr{Table: firstPart}
+			return &core.StarExpr{Table: strings.Join(parts, ".")}
 		}
 
 		if p.check(TOKEN_IDENT) {
@@ -147,15 +157,17 @@ func (p *Parser) parseQualifiedColumnRef(firstPart string) core.Expr {
 	// Build column reference
 	ref := &core.ColumnRef{}
 	switch len(parts) {
-	case 2:
+	case 1:
+		ref.Column = parts[0]
+	default:
+		// table.column.field... - trailing parts are struct field access.
+		// Whether the first part is a table, schema, or struct column depends
+		// on scope and is decided during resolution.
 		ref.Table = parts[0]
 		ref.Column = parts[1]
-	case 3:
-		// schema.table.column - we'll use table.column for now
-		ref.Table = parts[1]
-		ref.Column = parts[2]
-	default:
-		ref.Column = parts[len(parts)-1]
+		if len(parts) > 2 {
+			ref.Fields = parts[2:]
+		}
 	}
 
 	return ref
@@ -12,10 +12,12 @@ import (
 //	cast_expr     → CAST "(" expr AS type_name ")"
 //	exists_expr   → [NOT] EXISTS "(" statement ")"
 //	paren_expr    → "(" expression ")" | "(" statement ")"  -- subquery if SELECT/WITH
+//	quantified    → (ALL | ANY | SOME) "(" statement ")"
 //	type_name     → identifier ["(" number ["," number] ")"]
 
 // parseCaseExpr parses a CASE expression.
 func (p *Parser) parseCaseExpr() core.Expr {
+	start := p.token.Pos
 	p.expect(TOKEN_CASE)
 	caseExpr := &core.CaseExpr{}
 
@@ -27,7 +29,9 @@ func (p *Parser) parseCaseExpr() core.Expr {
 	// WHEN clauses
 	for p.match(TOKEN_WHEN) {
 		when := core.WhenClause{}
+		condStart := p.token.Pos
 		when.Condition = p.parseExpression()
+		when.ConditionSpan = p.spanFrom(condStart)
 		p.expect(TOKEN_THEN)
 		when.Result = p.parseExpression()
 		caseExpr.Whens = append(caseExpr.Whens, when)
@@ -39,6 +43,7 @@ func (p *Parser) parseCaseExpr() core.Expr {
 	}
 
 	p.expect(TOKEN_END)
+	caseExpr.Span = p.spanFrom(start)
 	return caseExpr
 }
 
@@ -93,6 +98,19 @@ func (p *Parser) parseTypeName() string {
 	return typeName
 }
 
+// parseQuantifiedSubquery parses the subquery of a quantified comparison,
+// e.g. x > ALL (SELECT ...), after the quantifier.
+func (p *Parser) parseQuantifiedSubquery(quantifier string) core.Expr {
+	p.expect(TOKEN_LPAREN)
+	if !p.check(TOKEN_SELECT) && !p.check(TOKEN_WITH) {
+		p.addError("expected subquery after " + quantifier)
+		return nil
+	}
+	subquery := &core.SubqueryExpr{Select: p.parseStatement(), Quantifier: quantifier}
+	p.expect(TOKEN_RPAREN)
+	return subquery
+}
+
 // parseParenExpr parses a parenthesized expression, subquery, or lambda parameter list.
 func (p *Parser) parseParenExpr() core.Expr {
 	p.expect(TOKEN_LPAREN)
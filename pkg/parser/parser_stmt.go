@@ -44,6 +44,7 @@ func (p *Parser) parseStatement() *core.SelectStmt {
 
 // parseWithClause parses a WITH clause with CTEs.
 func (p *Parser) parseWithClause() *core.WithClause {
+	start := p.token.Pos
 	p.expect(TOKEN_WITH)
 	with := &core.WithClause{}
 
@@ -62,11 +63,13 @@ func (p *Parser) parseWithClause() *core.WithClause {
 		}
 	}
 
+	with.Span = p.spanFrom(start)
 	return with
 }
 
 // parseCTE parses a single CTE.
 func (p *Parser) parseCTE() *core.CTE {
+	start := p.token.Pos
 	cte := &core.CTE{}
 
 	// CTE name
@@ -85,6 +88,7 @@ func (p *Parser) parseCTE() *core.CTE {
 	cte.Select = p.parseStatement()
 	p.expect(TOKEN_RPAREN)
 
+	cte.Span = p.spanFrom(start)
 	return cte
 }
 
@@ -134,6 +138,7 @@ func (p *Parser) parseSelectBody() *core.SelectBody {
 
 // parseSelectCore parses a single SELECT clause.
 func (p *Parser) parseSelectCore() *core.SelectCore {
+	start := p.token.Pos
 	p.expect(TOKEN_SELECT)
 	sc := &core.SelectCore{}
 
@@ -145,7 +150,9 @@ func (p *Parser) parseSelectCore() *core.SelectCore {
 	}
 
 	// SELECT list
+	listStart := p.token.Pos
 	sc.Columns = p.parseSelectList()
+	sc.ColumnsSpan = p.spanFrom(listStart)
 
 	// FROM clause (required for our use case)
 	if p.match(TOKEN_FROM) {
@@ -155,6 +162,7 @@ func (p *Parser) parseSelectCore() *core.SelectCore {
 	// Parse optional clauses using dialect-driven approach
 	p.parseClauses(sc)
 
+	sc.Span = p.spanFrom(start)
 	return sc
 }
 
@@ -191,7 +199,7 @@ func (p *Parser) parseClausesWithDialect(sc *core.SelectCore) {
 				handler := def.Handler.(spi.ClauseHandler)
 				result, err := handler(p)
 				if err != nil {
-					p.addError(err.Error())
+					p.addErr(err)
 				}
 
 				// Use slot-based assignment (declarative)
@@ -264,8 +272,9 @@ func (p *Parser) assignToSlot(sc *core.SelectCore, slot core.ClauseSlot, result
 		}
 
 	case core.SlotWindow:
-		// Window definitions are complex - for now skip
-		// TODO: Add window definitions support
+		if windows, ok := result.([]core.WindowDef); ok {
+			sc.Windows = windows
+		}
 
 	case core.SlotOrderBy:
 		// Check for ORDER BY ALL marker (DuckDB extension)
@@ -345,7 +354,9 @@ func (p *Parser) parseSelectList() []core.SelectItem {
 	var items []core.SelectItem
 
 	for {
+		start := p.token.Pos
 		item := p.parseSelectItem()
+		item.Span = p.spanFrom(start)
 		items = append(items, item)
 
 		if !p.match(TOKEN_COMMA) {
@@ -384,6 +395,14 @@ func (p *Parser) parseSelectItem() core.SelectItem {
 	// Regular expression
 	item.Expr = p.parseExpression()
 
+	// Qualified star: schema.table.* or catalog.schema.table.*
+	if star, ok := item.Expr.(*core.StarExpr); ok && star.Table != "" {
+		item.Expr = nil
+		item.TableStar = star.Table
+		item.Modifiers = p.parseStarModifiers()
+		return item
+	}
+
 	// Optional alias
 	if p.match(TOKEN_AS) {
 		if p.check(TOKEN_IDENT) {
@@ -416,7 +435,7 @@ func (p *Parser) parseStarModifiers() []core.StarModifier {
 
 		mod, err := handler(p)
 		if err != nil {
-			p.addError(err.Error())
+			p.addErr(err)
 			break
 		}
 
@@ -8,7 +8,7 @@ import (
 //
 // Grammar:
 //
-//	window_spec   → identifier | "(" [PARTITION BY expr_list] [ORDER BY order_list] [frame_spec] ")"
+//	window_spec   → identifier | "(" [identifier] [PARTITION BY expr_list] [ORDER BY order_list] [frame_spec] ")"
 //	frame_spec    → (ROWS|RANGE|GROUPS) frame_extent
 //	frame_extent  → BETWEEN frame_bound AND frame_bound | frame_bound
 //	frame_bound   → UNBOUNDED PRECEDING | UNBOUNDED FOLLOWING | CURRENT ROW | expr PRECEDING | expr FOLLOWING
@@ -26,6 +26,12 @@ func (p *Parser) parseWindowSpec() *core.WindowSpec {
 
 	p.expect(TOKEN_LPAREN)
 
+	// Named window the specification builds on
+	if p.check(TOKEN_IDENT) {
+		spec.Name = p.token.Literal
+		p.nextToken()
+	}
+
 	// PARTITION BY
 	if p.match(TOKEN_PARTITION) {
 		p.expect(TOKEN_BY)
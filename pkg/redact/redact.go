@@ -0,0 +1,144 @@
+// Package redact masks secret values, such as resolved database passwords, in
+// logs and artifacts.
+//
+// Secrets are registered process-wide when the configuration resolves them.
+// Log handlers and artifact writers then replace every occurrence of a
+// registered secret with Mask.
+package redact
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces secret values.
+const Mask = "****"
+
+// minLength is the length below which values are not registered: masking
+// every "a" or "42" would mangle unrelated text.
+const minLength = 4
+
+var (
+	mu       sync.RWMutex
+	secrets  []string // Sorted longest first, so overlapping secrets mask fully
+	replacer *strings.Replacer
+)
+
+// Add registers secret values to mask. Empty and very short values are
+// ignored.
+func Add(values ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	changed := false
+	for _, v := range values {
+		if len(v) < minLength || contains(v) {
+			continue
+		}
+		secrets = append(secrets, v)
+		changed = true
+	}
+	if !changed {
+		return
+	}
+
+	sort.SliceStable(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, s := range secrets {
+		pairs = append(pairs, s, Mask)
+	}
+	replacer = strings.NewReplacer(pairs...)
+}
+
+func contains(v string) bool {
+	for _, s := range secrets {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset forgets all registered secrets. Used for testing.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	secrets = nil
+	replacer = nil
+}
+
+// String returns s with every registered secret replaced by Mask.
+func String(s string) string {
+	mu.RLock()
+	r := replacer
+	mu.RUnlock()
+	if r == nil {
+		return s
+	}
+	return r.Replace(s)
+}
+
+// handler masks secrets in the records passed to the next handler.
+type handler struct {
+	next slog.Handler
+}
+
+// NewHandler returns a slog handler that masks registered secrets in record
+// messages and string, error and stringer attributes before passing records
+// to next.
+func NewHandler(next slog.Handler) slog.Handler {
+	return &handler{next: next}
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, String(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = attr(a)
+	}
+	return &handler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{next: h.next.WithGroup(name)}
+}
+
+// attr masks secrets in an attribute value.
+func attr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, String(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, g := range group {
+			redacted[i] = attr(g)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return slog.String(a.Key, String(x.Error()))
+		case fmt.Stringer:
+			return slog.String(a.Key, String(x.String()))
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,48 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestString(t *testing.T) {
+	t.Cleanup(Reset)
+
+	assert.Equal(t, "password=hunter22", String("password=hunter22"))
+
+	Add("hunter22", "", "abc", "hunter2")
+	assert.Equal(t, "password=**** user=abc", String("password=hunter22 user=abc"))
+	assert.Equal(t, "****/****", String("hunter2/hunter22"))
+
+	Reset()
+	assert.Equal(t, "hunter22", String("hunter22"))
+}
+
+func TestHandler(t *testing.T) {
+	t.Cleanup(Reset)
+	Add("s3cr3t-token")
+
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+
+	logger.With("dsn", "token=s3cr3t-token").WithGroup("run").Info("connecting with s3cr3t-token",
+		"error", errors.New("auth failed for s3cr3t-token"),
+		"rows", 3,
+		slog.Group("target", "password", "s3cr3t-token"),
+	)
+
+	assert.Equal(t,
+		`level=INFO msg="connecting with ****" dsn="token=****" run.error="auth failed for ****" run.rows=3 run.target.password=****`+"\n",
+		buf.String())
+}
@@ -26,6 +26,7 @@ type ParserOps interface {
 	ParseExpressionList() ([]core.Expr, error)
 	ParseOrderByList() ([]core.OrderByItem, error)
 	ParseIdentifier() (string, error)
+	ParseWindowSpec() (*core.WindowSpec, error)
 
 	// Error handling
 	AddError(msg string)
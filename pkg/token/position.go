@@ -1,5 +1,7 @@
 package token
 
+import "strings"
+
 // Position represents a location in the source code.
 type Position struct {
 	Line   int // 1-based line number
@@ -27,3 +29,30 @@ func (s Span) Contains(offset int) bool {
 func (s Span) IsValid() bool {
 	return s.Start.IsValid() && s.End.IsValid()
 }
+
+// OffsetIn returns the byte offset of the position's line and column in src,
+// clamped to the end of its line. Columns count bytes.
+func (p Position) OffsetIn(src string) int {
+	offset := 0
+	for line := 1; line < p.Line; line++ {
+		i := strings.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			return len(src)
+		}
+		offset += i + 1
+	}
+	lineEnd := len(src)
+	if i := strings.IndexByte(src[offset:], '\n'); i >= 0 {
+		lineEnd = offset + i
+	}
+	return min(offset+max(p.Column-1, 0), lineEnd)
+}
+
+// PositionAt returns the position of a byte offset of src, clamped to its
+// length.
+func PositionAt(src string, offset int) Position {
+	offset = min(max(offset, 0), len(src))
+	line := 1 + strings.Count(src[:offset], "\n")
+	column := offset - strings.LastIndexByte(src[:offset], '\n')
+	return Position{Line: line, Column: column, Offset: offset}
+}
@@ -0,0 +1,32 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPositionOffsets(t *testing.T) {
+	src := "SELECT a\nFROM t\n\nWHERE b"
+
+	tests := []struct {
+		pos    Position
+		offset int
+	}{
+		{Position{Line: 1, Column: 1}, 0},
+		{Position{Line: 1, Column: 8}, 7},
+		{Position{Line: 2, Column: 6}, 14},
+		{Position{Line: 3, Column: 1}, 16},
+		{Position{Line: 4, Column: 7}, 23},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.offset, tt.pos.OffsetIn(src), "OffsetIn(%d:%d)", tt.pos.Line, tt.pos.Column)
+		tt.pos.Offset = tt.offset
+		assert.Equal(t, tt.pos, PositionAt(src, tt.offset))
+	}
+
+	// Out of range positions are clamped
+	assert.Equal(t, 8, Position{Line: 1, Column: 40}.OffsetIn(src))
+	assert.Equal(t, len(src), Position{Line: 9, Column: 1}.OffsetIn(src))
+	assert.Equal(t, Position{Line: 4, Column: 8, Offset: len(src)}, PositionAt(src, 100))
+}
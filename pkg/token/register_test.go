@@ -92,3 +92,20 @@ func TestGetDynamicName(t *testing.T) {
 	_, ok = getDynamicName(TokenType(99999))
 	assert.False(t, ok)
 }
+
+func TestLookupName(t *testing.T) {
+	// Every builtin type round-trips through its name
+	for tt, name := range tokenNames {
+		got, ok := LookupName(name)
+		require.True(t, ok, "builtin %q should be found", name)
+		assert.Equal(t, tt, got, "builtin %q", name)
+	}
+
+	dyn := Register("TEST_LOOKUP_NAME")
+	got, ok := LookupName(dyn.String())
+	require.True(t, ok, "registered token should be found")
+	assert.Equal(t, dyn, got)
+
+	_, ok = LookupName("NONEXISTENT_TOKEN_12345")
+	assert.False(t, ok, "unknown name should not be found")
+}
@@ -127,6 +127,7 @@ const (
 
 	// Extended operators
 	DCOLON // :: cast operator (Postgres, DuckDB, Databricks)
+	DARROW // ->> JSON text extraction operator (Postgres, DuckDB)
 
 	// Template tokens
 	MACRO // {{ ... }} content
@@ -148,6 +149,27 @@ func (t TokenType) String() string {
 	return fmt.Sprintf("TOKEN(%d)", t)
 }
 
+// tokenTypes maps the names of builtin token types back to the types.
+var tokenTypes = func() map[string]TokenType {
+	m := make(map[string]TokenType, len(tokenNames))
+	for t, name := range tokenNames {
+		m[name] = t
+	}
+	return m
+}()
+
+// LookupName returns the token type whose String is name, builtin or
+// registered by a dialect.
+func LookupName(name string) (TokenType, bool) {
+	if t, ok := tokenTypes[name]; ok {
+		return t, true
+	}
+	if t, ok := LookupDynamicKeyword(name); ok {
+		return t, true
+	}
+	return ILLEGAL, false
+}
+
 // tokenNames maps builtin token types to their string representations.
 var tokenNames = map[TokenType]string{
 	EOF:     "EOF",
@@ -256,6 +278,7 @@ var tokenNames = map[TokenType]string{
 	SEMI:      "SEMI",
 	ANTI:      "ANTI",
 	DCOLON:    "::",
+	DARROW:    "->>",
 
 	MACRO: "MACRO",
 }
@@ -0,0 +1,178 @@
+package transpile
+
+import (
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// fn spells a function with its arguments in the given order.
+func fn(name string, args ...int) core.FunctionSpelling {
+	return core.FunctionSpelling{Name: name, Args: args}
+}
+
+// equivalences lists the functions spelled differently across dialects.
+// Functions spelled the same in every dialect (COALESCE, SUM, ...) need no
+// entry. When a dialect spells several entries' functions the same way, the
+// first entry is used to translate from it.
+var equivalences = []core.FunctionEquivalence{
+	{"duckdb": fn("ifnull"), "snowflake": fn("ifnull"), "databricks": fn("ifnull"), "postgres": fn("coalesce")},
+	{"snowflake": fn("nvl"), "databricks": fn("nvl"), "duckdb": fn("ifnull"), "postgres": fn("coalesce")},
+	{"duckdb": fn("strpos"), "postgres": fn("strpos"), "databricks": fn("instr"), "snowflake": fn("charindex", 1, 0)},
+	{"duckdb": fn("starts_with"), "postgres": fn("starts_with"), "snowflake": fn("startswith"), "databricks": fn("startswith")},
+	{"duckdb": fn("ends_with"), "snowflake": fn("endswith"), "databricks": fn("endswith")},
+	{"duckdb": fn("regexp_matches"), "snowflake": fn("regexp_like"), "databricks": fn("regexp_like")},
+	{"duckdb": fn("string_split"), "postgres": fn("string_to_array"), "snowflake": fn("split"), "databricks": fn("split")},
+	{"duckdb": fn("string_agg"), "postgres": fn("string_agg"), "databricks": fn("string_agg"), "snowflake": fn("listagg")},
+	{"duckdb": fn("array_length"), "postgres": fn("cardinality"), "snowflake": fn("array_size"), "databricks": fn("size")},
+	{"duckdb": fn("list_contains"), "databricks": fn("array_contains"), "snowflake": fn("array_contains", 1, 0)},
+	{"duckdb": fn("list_distinct"), "snowflake": fn("array_distinct"), "databricks": fn("array_distinct")},
+	{"duckdb": fn("bool_and"), "postgres": fn("bool_and"), "databricks": fn("bool_and"), "snowflake": fn("booland_agg")},
+	{"duckdb": fn("bool_or"), "postgres": fn("bool_or"), "databricks": fn("bool_or"), "snowflake": fn("boolor_agg")},
+	{"duckdb": fn("count_if"), "snowflake": fn("count_if"), "databricks": fn("count_if")},
+	{"duckdb": fn("uuid"), "postgres": fn("gen_random_uuid"), "snowflake": fn("uuid_string"), "databricks": fn("uuid")},
+	{"duckdb": fn("date_diff"), "snowflake": fn("datediff"), "databricks": fn("timestampdiff")},
+	{"duckdb": fn("datediff"), "snowflake": fn("datediff"), "databricks": fn("timestampdiff")},
+}
+
+// standardFunctions lists the SQL standard functions every dialect has, some
+// of which dialect catalogs leave out because the parser handles them.
+var standardFunctions = map[string]struct{}{
+	"coalesce": {}, "nullif": {}, "count": {}, "sum": {}, "avg": {}, "min": {}, "max": {},
+	"row_number": {}, "rank": {}, "dense_rank": {}, "percent_rank": {}, "cume_dist": {}, "ntile": {},
+	"lag": {}, "lead": {}, "first_value": {}, "last_value": {}, "nth_value": {},
+	"lower": {}, "upper": {}, "substring": {}, "trim": {}, "abs": {}, "round": {}, "floor": {}, "ceil": {},
+}
+
+// MapFunction returns dialect to's equivalent of function name of dialect
+// from, looked up in the table of cross-dialect equivalents and then in the
+// dialects' function catalogs. The mapping is only meaningful when the
+// returned FunctionCompat is FunctionSame or FunctionMapped.
+func MapFunction(from, to *core.Dialect, name string) (core.FunctionMapping, core.FunctionCompat) {
+	name = strings.ToLower(name)
+	fromName, toName := strings.ToLower(from.Name), strings.ToLower(to.Name)
+	if fromName == toName {
+		return core.FunctionMapping{Name: name}, core.FunctionSame
+	}
+	if eq, ok := LookupFunctionEquivalence(fromName, name); ok {
+		target, ok := eq[toName]
+		if !ok {
+			return core.FunctionMapping{}, core.FunctionMissing
+		}
+		args, ok := mapArgs(eq[fromName], target)
+		if !ok {
+			return core.FunctionMapping{}, core.FunctionMissing
+		}
+		if target.Name == name && args == nil {
+			return core.FunctionMapping{Name: name}, core.FunctionSame
+		}
+		return core.FunctionMapping{Name: target.Name, Args: args}, core.FunctionMapped
+	}
+	if _, ok := standardFunctions[name]; ok {
+		return core.FunctionMapping{Name: name}, core.FunctionSame
+	}
+
+	if !hasCatalog(to) {
+		return core.FunctionMapping{}, core.FunctionUnknown
+	}
+	if inCatalog(to, name) {
+		return core.FunctionMapping{Name: name}, core.FunctionSame
+	}
+	// Only report a function missing from the target when the source's
+	// catalog knows it, so user-defined functions and catalog gaps are not
+	// mistaken for dialect-specific functions.
+	if hasCatalog(from) && inCatalog(from, name) {
+		return core.FunctionMapping{}, core.FunctionMissing
+	}
+	return core.FunctionMapping{}, core.FunctionUnknown
+}
+
+// LookupFunctionEquivalence returns the equivalence a dialect's function
+// belongs to.
+func LookupFunctionEquivalence(dialect, name string) (core.FunctionEquivalence, bool) {
+	dialect, name = strings.ToLower(dialect), strings.ToLower(name)
+	for _, eq := range equivalences {
+		if s, ok := eq[dialect]; ok && s.Name == name {
+			return eq, true
+		}
+	}
+	return nil, false
+}
+
+// FunctionEquivalences returns the table of cross-dialect equivalents.
+func FunctionEquivalences() []core.FunctionEquivalence {
+	result := make([]core.FunctionEquivalence, len(equivalences))
+	copy(result, equivalences)
+	return result
+}
+
+// hasCatalog reports whether a dialect documents its functions. Catalogs of
+// dialects without documented functions are too incomplete to tell a missing
+// function from an undocumented one.
+func hasCatalog(d *core.Dialect) bool {
+	return len(d.Docs) > 0
+}
+
+// inCatalog reports whether a dialect has a function: a documented,
+// aggregate, generator, window or table function.
+func inCatalog(d *core.Dialect, name string) bool {
+	if _, ok := d.GetDoc(name); ok {
+		return true
+	}
+	return d.IsAggregate(name) || d.IsGenerator(name) || d.IsWindow(name) || d.IsTableFunction(name)
+}
+
+// applyMapping returns the arguments of a call to the source function in the
+// order of the target function. It reports false if the call's number of
+// arguments does not match.
+func applyMapping(m core.FunctionMapping, args []core.Expr) ([]core.Expr, bool) {
+	if m.Args == nil {
+		return args, true
+	}
+	if len(args) != len(m.Args) {
+		return nil, false
+	}
+	result := make([]core.Expr, len(m.Args))
+	for i, idx := range m.Args {
+		result[i] = args[idx]
+	}
+	return result, true
+}
+
+// mapArgs returns, for each argument of spelling to, the index of the
+// matching argument of spelling from (nil for the same order). It reports
+// false if the spellings take a different number of arguments.
+func mapArgs(from, to core.FunctionSpelling) ([]int, bool) {
+	if from.Args == nil && to.Args == nil {
+		return nil, true
+	}
+	if from.Args != nil && to.Args != nil && len(from.Args) != len(to.Args) {
+		return nil, false
+	}
+	n := len(from.Args)
+	if to.Args != nil {
+		n = len(to.Args)
+	}
+	// index[pos] is the index of from's argument at shared position pos
+	index := make([]int, n)
+	for i := range index {
+		index[i] = i
+	}
+	for i, pos := range from.Args {
+		index[pos] = i
+	}
+	args := make([]int, n)
+	for i := range args {
+		pos := i
+		if to.Args != nil {
+			pos = to.Args[i]
+		}
+		args[i] = index[pos]
+	}
+	for i, idx := range args {
+		if idx != i {
+			return args, true
+		}
+	}
+	return nil, true
+}
@@ -0,0 +1,287 @@
+// Package transpile rewrites queries parsed in one SQL dialect for another.
+//
+// Functions are translated with MapFunction, through the table of
+// cross-dialect equivalents (IFNULL in DuckDB is NVL's equivalent in
+// Snowflake and COALESCE in Postgres, CHARINDEX takes its arguments in the
+// opposite order of STRPOS, ...), so the rewritten query can be formatted
+// for the target dialect.
+// Constructs the target dialect has no equivalent for are reported in an
+// *UnsupportedError rather than formatted into SQL the target would reject
+// or, worse, silently dropped.
+//
+// Use engine.TranspileSQL to parse, rewrite and format SQL in one call.
+package transpile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+)
+
+// byNameDialects lists the dialects supporting set operations BY NAME, which
+// every dialect parses.
+var byNameDialects = map[string]bool{"duckdb": true}
+
+// UnsupportedError is returned when a query uses constructs that cannot be
+// rendered for the target dialect.
+type UnsupportedError struct {
+	Dialect    string   // Target dialect
+	Constructs []string // Descriptions of the unsupported constructs
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("not supported by %s: %s", e.Dialect, strings.Join(e.Constructs, "; "))
+}
+
+// Rewrite rewrites a query parsed in dialect from for dialect to, in place.
+// It returns an *UnsupportedError listing the constructs the target dialect
+// has no equivalent for.
+func Rewrite(stmt *core.SelectStmt, from, to *core.Dialect) error {
+	if from == nil || to == nil {
+		return core.ErrDialectRequired
+	}
+	t := &transpiler{from: from, to: to}
+	t.stmt(stmt)
+	if len(t.unsupported) > 0 {
+		return &UnsupportedError{Dialect: to.Name, Constructs: t.unsupported}
+	}
+	return nil
+}
+
+// transpiler rewrites a query's AST for the target dialect in place,
+// collecting the constructs it cannot rewrite.
+type transpiler struct {
+	from, to    *core.Dialect
+	unsupported []string
+}
+
+func (t *transpiler) unsupportedf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	for _, u := range t.unsupported {
+		if u == msg {
+			return
+		}
+	}
+	t.unsupported = append(t.unsupported, msg)
+}
+
+// hasSlot reports whether the target dialect has a clause filling a slot.
+func (t *transpiler) hasSlot(slot core.ClauseSlot) bool {
+	for _, tok := range t.to.ClauseSequence() {
+		if def, ok := t.to.ClauseDefFor(tok); ok && def.Slot == slot {
+			return true
+		}
+	}
+	return false
+}
+
+// hasJoinType reports whether the target dialect has a join type.
+func (t *transpiler) hasJoinType(typ core.JoinType) bool {
+	if typ == "" || typ == core.JoinComma {
+		return true
+	}
+	for _, def := range t.to.JoinTypes {
+		if core.JoinType(def.Type) == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *transpiler) stmt(s *core.SelectStmt) {
+	if s == nil {
+		return
+	}
+	if s.With != nil {
+		for _, cte := range s.With.CTEs {
+			t.stmt(cte.Select)
+		}
+	}
+	for body := s.Body; body != nil; body = body.Right {
+		if body.ByName && !byNameDialects[t.to.Name] {
+			t.unsupportedf("%s BY NAME", body.Op)
+		}
+		t.selectCore(body.Left)
+	}
+}
+
+func (t *transpiler) selectCore(sc *core.SelectCore) {
+	if sc == nil {
+		return
+	}
+	for _, item := range sc.Columns {
+		t.expr(item.Expr)
+		for _, mod := range item.Modifiers {
+			if replace, ok := mod.(*core.ReplaceModifier); ok {
+				for _, r := range replace.Items {
+					t.expr(r.Expr)
+				}
+			}
+		}
+	}
+	if sc.From != nil {
+		t.tableRef(sc.From.Source)
+		for _, join := range sc.From.Joins {
+			if !t.hasJoinType(join.Type) {
+				t.unsupportedf("%s JOIN", join.Type)
+			}
+			t.tableRef(join.Right)
+			t.expr(join.Condition)
+		}
+	}
+	t.expr(sc.Where)
+	t.exprs(sc.GroupBy)
+	t.expr(sc.Having)
+	if len(sc.Windows) > 0 {
+		t.unsupportedf("named WINDOW clause")
+	}
+	if sc.Qualify != nil {
+		if !t.hasSlot(core.SlotQualify) {
+			t.unsupportedf("QUALIFY")
+		}
+		t.expr(sc.Qualify)
+	}
+	t.orderBy(sc.OrderBy)
+	t.expr(sc.Limit)
+	t.expr(sc.Offset)
+	if sc.Fetch != nil {
+		if !t.hasSlot(core.SlotFetch) {
+			t.unsupportedf("FETCH")
+		}
+		t.expr(sc.Fetch.Count)
+	}
+}
+
+func (t *transpiler) tableRef(ref core.TableRef) {
+	switch r := ref.(type) {
+	case *core.DerivedTable:
+		t.stmt(r.Select)
+	case *core.LateralTable:
+		t.stmt(r.Select)
+	case *core.TableFunction:
+		t.exprs(r.Args)
+	case *core.PivotTable:
+		t.tableRef(r.Source)
+		for _, agg := range r.Aggregates {
+			t.expr(agg.Func)
+		}
+		for _, v := range r.InValues {
+			t.expr(v.Value)
+		}
+	case *core.UnpivotTable:
+		t.tableRef(r.Source)
+	}
+}
+
+func (t *transpiler) orderBy(items []core.OrderByItem) {
+	for _, item := range items {
+		t.expr(item.Expr)
+	}
+}
+
+func (t *transpiler) exprs(exprs []core.Expr) {
+	for _, e := range exprs {
+		t.expr(e)
+	}
+}
+
+func (t *transpiler) expr(e core.Expr) {
+	switch x := e.(type) {
+	case *core.FuncCall:
+		if x == nil {
+			return
+		}
+		t.funcCall(x)
+		t.exprs(x.Args)
+		t.expr(x.Filter)
+		if x.Window != nil {
+			t.exprs(x.Window.PartitionBy)
+			t.orderBy(x.Window.OrderBy)
+			if f := x.Window.Frame; f != nil {
+				if f.Start != nil {
+					t.expr(f.Start.Offset)
+				}
+				if f.End != nil {
+					t.expr(f.End.Offset)
+				}
+			}
+		}
+	case *core.BinaryExpr:
+		if _, ok := t.to.Precedences[x.Op]; !ok {
+			t.unsupportedf("operator %s", x.Op)
+		}
+		t.expr(x.Left)
+		t.expr(x.Right)
+	case *core.LikeExpr:
+		if _, ok := t.to.Precedences[x.Op]; !ok {
+			t.unsupportedf("operator %s", x.Op)
+		}
+		t.expr(x.Expr)
+		t.expr(x.Pattern)
+	case *core.UnaryExpr:
+		t.expr(x.Expr)
+	case *core.CaseExpr:
+		t.expr(x.Operand)
+		for _, w := range x.Whens {
+			t.expr(w.Condition)
+			t.expr(w.Result)
+		}
+		t.expr(x.Else)
+	case *core.CastExpr:
+		t.expr(x.Expr)
+	case *core.InExpr:
+		t.expr(x.Expr)
+		t.exprs(x.Values)
+		t.stmt(x.Query)
+	case *core.BetweenExpr:
+		t.expr(x.Expr)
+		t.expr(x.Low)
+		t.expr(x.High)
+	case *core.IsNullExpr:
+		t.expr(x.Expr)
+	case *core.IsBoolExpr:
+		t.expr(x.Expr)
+	case *core.ParenExpr:
+		t.expr(x.Expr)
+	case *core.SubqueryExpr:
+		t.stmt(x.Select)
+	case *core.ExistsExpr:
+		t.stmt(x.Select)
+	case *core.LambdaExpr:
+		t.expr(x.Body)
+	case *core.StructLiteral:
+		for _, f := range x.Fields {
+			t.expr(f.Value)
+		}
+	case *core.ListLiteral:
+		t.exprs(x.Elements)
+	case *core.IndexExpr:
+		t.expr(x.Expr)
+		t.expr(x.Index)
+		t.expr(x.Start)
+		t.expr(x.Stop)
+	}
+}
+
+// funcCall renames a function to the target dialect's equivalent, reordering
+// its arguments to match.
+func (t *transpiler) funcCall(f *core.FuncCall) {
+	mapping, compat := MapFunction(t.from, t.to, f.Name)
+	switch compat {
+	case core.FunctionMissing:
+		// Functions only missing from the target's catalog are kept: catalogs
+		// have gaps, and the target's database reports truly missing ones.
+		if _, ok := LookupFunctionEquivalence(t.from.Name, f.Name); ok {
+			t.unsupportedf("function %s", f.Name)
+		}
+	case core.FunctionMapped:
+		args, ok := applyMapping(mapping, f.Args)
+		if !ok {
+			t.unsupportedf("function %s with %d arguments", f.Name, len(f.Args))
+			return
+		}
+		f.Name = strings.ToUpper(mapping.Name)
+		f.Args = args
+	}
+}
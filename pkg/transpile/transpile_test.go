@@ -0,0 +1,175 @@
+package transpile
+
+import (
+	"testing"
+
+	"github.com/leapstack-labs/leapsql/pkg/core"
+	"github.com/leapstack-labs/leapsql/pkg/dialects/duckdb"
+	"github.com/leapstack-labs/leapsql/pkg/dialects/postgres"
+	"github.com/leapstack-labs/leapsql/pkg/dialects/snowflake"
+	"github.com/leapstack-labs/leapsql/pkg/format"
+	"github.com/leapstack-labs/leapsql/pkg/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// transpileSQL is a test helper that parses, rewrites and formats SQL.
+// The public TranspileSQL() function lives in internal/engine.
+func transpileSQL(sql string, from, to *core.Dialect) (string, error) {
+	stmt, err := parser.ParseWithDialect(sql, from)
+	if err != nil {
+		return "", err
+	}
+	if err := Rewrite(stmt, from, to); err != nil {
+		return "", err
+	}
+	return format.Format(stmt, to), nil
+}
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		from, to *core.Dialect
+		expected string
+	}{
+		{
+			name: "renamed function",
+			sql:  "SELECT ifnull(a, 0) AS a FROM t",
+			from: duckdb.DuckDB,
+			to:   postgres.Postgres,
+			expected: `SELECT
+  COALESCE(a, 0) AS a
+FROM t
+`,
+		},
+		{
+			name: "reordered arguments",
+			sql:  "SELECT strpos(name, 'x') AS pos, list_contains(tags, 'a') AS tagged FROM t",
+			from: duckdb.DuckDB,
+			to:   snowflake.Snowflake,
+			expected: `SELECT
+  CHARINDEX('x', name) AS pos,
+  ARRAY_CONTAINS('a', tags) AS tagged
+FROM t
+`,
+		},
+		{
+			name: "reordered back",
+			sql:  "SELECT charindex('x', name) AS pos, nvl(a, 0) AS a FROM t",
+			from: snowflake.Snowflake,
+			to:   duckdb.DuckDB,
+			expected: `SELECT
+  STRPOS(name, 'x') AS pos,
+  IFNULL(a, 0) AS a
+FROM t
+`,
+		},
+		{
+			name: "nested functions and subqueries",
+			sql:  "SELECT id FROM (SELECT id, bool_and(ok) AS ok FROM t GROUP BY id) AS s WHERE starts_with(id, 'a')",
+			from: duckdb.DuckDB,
+			to:   snowflake.Snowflake,
+			expected: `SELECT
+  id
+FROM (
+  SELECT
+    id,
+    BOOLAND_AGG(ok) AS ok
+  FROM t
+  GROUP BY
+    id
+) s
+WHERE
+  STARTSWITH(id, 'a')
+`,
+		},
+		{
+			name: "qualify kept",
+			sql:  "SELECT id FROM t QUALIFY row_number() OVER (PARTITION BY id) = 1",
+			from: duckdb.DuckDB,
+			to:   snowflake.Snowflake,
+			expected: `SELECT
+  id
+FROM t
+QUALIFY
+  ROW_NUMBER() OVER (
+    PARTITION BY id) = 1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := transpileSQL(tt.sql, tt.from, tt.to)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestRewrite_Unsupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		to       *core.Dialect
+		contains []string
+	}{
+		{
+			name:     "function without equivalent",
+			sql:      "SELECT list_contains(tags, 'a') AS tagged, count_if(ok) AS n FROM t",
+			to:       postgres.Postgres,
+			contains: []string{"function LIST_CONTAINS", "function COUNT_IF"},
+		},
+		{
+			name:     "clause",
+			sql:      "SELECT id FROM t QUALIFY row_number() OVER (PARTITION BY id) = 1",
+			to:       postgres.Postgres,
+			contains: []string{"QUALIFY"},
+		},
+		{
+			name:     "set operation by name",
+			sql:      "SELECT a FROM t UNION BY NAME SELECT a FROM u",
+			to:       snowflake.Snowflake,
+			contains: []string{"UNION BY NAME"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := transpileSQL(tt.sql, duckdb.DuckDB, tt.to)
+			var unsupported *UnsupportedError
+			require.ErrorAs(t, err, &unsupported)
+			assert.Equal(t, tt.to.Name, unsupported.Dialect)
+			for _, s := range tt.contains {
+				assert.Contains(t, err.Error(), s)
+			}
+		})
+	}
+}
+
+func TestMapFunction(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      string
+		to      *core.Dialect
+		mapping core.FunctionMapping
+		compat  core.FunctionCompat
+	}{
+		{name: "same dialect", fn: "LIST_CONTAINS", to: duckdb.DuckDB, mapping: core.FunctionMapping{Name: "list_contains"}, compat: core.FunctionSame},
+		{name: "renamed", fn: "ifnull", to: postgres.Postgres, mapping: core.FunctionMapping{Name: "coalesce"}, compat: core.FunctionMapped},
+		{name: "reordered arguments", fn: "strpos", to: snowflake.Snowflake, mapping: core.FunctionMapping{Name: "charindex", Args: []int{1, 0}}, compat: core.FunctionMapped},
+		{name: "no equivalent", fn: "list_contains", to: postgres.Postgres, compat: core.FunctionMissing},
+		{name: "standard function", fn: "nullif", to: snowflake.Snowflake, mapping: core.FunctionMapping{Name: "nullif"}, compat: core.FunctionSame},
+		{name: "missing from the target catalog", fn: "epoch_ms", to: snowflake.Snowflake, compat: core.FunctionMissing},
+		{name: "user-defined function", fn: "my_udf", to: snowflake.Snowflake, compat: core.FunctionUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mapping, compat := MapFunction(duckdb.DuckDB, tt.to, tt.fn)
+			assert.Equal(t, tt.compat, compat, compat.String())
+			assert.Equal(t, tt.mapping, mapping)
+		})
+	}
+}
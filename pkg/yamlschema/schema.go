@@ -0,0 +1,385 @@
+// Package yamlschema validates YAML documents against schemas derived from Go
+// struct types.
+//
+// Unlike decoding, validation does not stop at the first problem: it reports
+// every unknown field, value of the wrong type and invalid enum value, each
+// with the line and column of the offending YAML node.
+package yamlschema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kind is the shape of YAML value a schema accepts.
+type kind int
+
+const (
+	kindAny kind = iota
+	kindString
+	kindInt
+	kindFloat
+	kindBool
+	kindDuration // time.Duration: "30m" or a number of nanoseconds
+	kindList
+	kindMap    // Mapping with arbitrary keys
+	kindObject // Mapping with known fields
+)
+
+// Schema describes the YAML accepted for a Go type.
+type Schema struct {
+	kind    kind
+	fields  map[string]*Schema // kindObject
+	elem    *Schema            // kindList items, kindMap values
+	enum    []string           // Allowed scalar values, if any
+	ignored map[string]bool    // kindObject fields accepted with any value, but not listed
+	weak    bool               // Accept weakly typed values (root only)
+}
+
+// For derives the schema of a Go value's type. Struct fields are named by
+// the given tag ("yaml", "koanf", ...); fields without the tag or tagged "-"
+// are not accepted.
+func For(v any, tag string) *Schema {
+	return fromType(reflect.TypeOf(v), tag)
+}
+
+func fromType(t reflect.Type, tag string) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return &Schema{kind: kindDuration}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{kind: kindString}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{kind: kindInt}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{kind: kindFloat}
+	case reflect.Bool:
+		return &Schema{kind: kindBool}
+	case reflect.Slice, reflect.Array:
+		return &Schema{kind: kindList, elem: fromType(t.Elem(), tag)}
+	case reflect.Map:
+		return &Schema{kind: kindMap, elem: fromType(t.Elem(), tag)}
+	case reflect.Struct:
+		s := &Schema{kind: kindObject, fields: make(map[string]*Schema)}
+		for i := range t.NumField() {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+			if name == "" || name == "-" || !f.IsExported() {
+				continue
+			}
+			s.fields[name] = fromType(f.Type, tag)
+		}
+		return s
+	default:
+		return &Schema{kind: kindAny}
+	}
+}
+
+// Enum restricts the field at path to the given values and returns the
+// schema. The path is dotted; "*" stands for any map key or list item, as in
+// "config.*.materialized". Empty values are always allowed. Enum panics if
+// the path does not name a field of the schema.
+func (s *Schema) Enum(path string, values ...string) *Schema {
+	target := s
+	for _, part := range strings.Split(path, ".") {
+		var next *Schema
+		switch {
+		case part == "*" && (target.kind == kindMap || target.kind == kindList):
+			next = target.elem
+		case target.kind == kindObject:
+			next = target.fields[part]
+		}
+		if next == nil {
+			panic(fmt.Sprintf("yamlschema: no field %q in schema", path))
+		}
+		target = next
+	}
+	target.enum = values
+	return s
+}
+
+// WeaklyTyped makes the schema accept the values weakly typed decoders, such
+// as koanf's, convert: scalars holding a value of the expected type, like
+// "8080" for an integer, and a single value in place of a list. It returns
+// the schema.
+func (s *Schema) WeaklyTyped() *Schema {
+	s.weak = true
+	return s
+}
+
+// Ignore makes the schema accept top-level fields that its type does not
+// have, with any value, such as fields of an older format that are still
+// found in files but no longer read. Ignored fields are not listed among the
+// expected fields of unknown field errors. It returns the schema.
+func (s *Schema) Ignore(fields ...string) *Schema {
+	if s.ignored == nil {
+		s.ignored = make(map[string]bool, len(fields))
+	}
+	for _, field := range fields {
+		s.ignored[field] = true
+	}
+	return s
+}
+
+// Error is a schema violation.
+type Error struct {
+	Line    int    // 1-based line of the offending node (0 if unknown)
+	Column  int    // 1-based column of the offending node (0 if unknown)
+	Path    string // Dotted path of the value, e.g. "tests[0].unique"; empty for the document
+	Field   string // Name of the field not in the schema, for unknown fields
+	Message string
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+	case e.Line > 0:
+		return fmt.Sprintf("%d: %s", e.Line, e.Message)
+	default:
+		return e.Message
+	}
+}
+
+// Validate checks a YAML document against the schema and returns every
+// violation in document order. Invalid YAML is reported as a single error.
+// An empty document is valid.
+func (s *Schema) Validate(data []byte) []*Error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []*Error{syntaxError(err)}
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+
+	var errs []*Error
+	s.validate(doc.Content[0], "", s.weak, &errs)
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line != errs[j].Line {
+			return errs[i].Line < errs[j].Line
+		}
+		return errs[i].Column < errs[j].Column
+	})
+	return errs
+}
+
+// yamlLinePattern matches the position prefix of yaml.v3 syntax errors.
+var yamlLinePattern = regexp.MustCompile(`^yaml: line (\d+): `)
+
+func syntaxError(err error) *Error {
+	msg := err.Error()
+	e := &Error{Message: "invalid YAML: " + strings.TrimPrefix(msg, "yaml: ")}
+	if m := yamlLinePattern.FindStringSubmatch(msg); m != nil {
+		e.Line, _ = strconv.Atoi(m[1])
+		e.Message = "invalid YAML: " + strings.TrimPrefix(msg, m[0])
+	}
+	return e
+}
+
+func (s *Schema) validate(n *yaml.Node, path string, weak bool, errs *[]*Error) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if s.kind == kindAny || isNull(n) {
+		return
+	}
+
+	report := func(node *yaml.Node, format string, args ...any) {
+		msg := fmt.Sprintf(format, args...)
+		if path != "" {
+			msg = path + ": " + msg
+		}
+		*errs = append(*errs, &Error{Line: node.Line, Column: node.Column, Path: path, Message: msg})
+	}
+
+	switch s.kind {
+	case kindString, kindInt, kindFloat, kindBool, kindDuration:
+		if n.Kind != yaml.ScalarNode || !s.acceptsScalar(n, weak) {
+			report(n, "expected %s, got %s", s.describe(), describeNode(n))
+			return
+		}
+		if len(s.enum) > 0 && n.Value != "" && !slices.Contains(s.enum, n.Value) {
+			*errs = append(*errs, &Error{
+				Line: n.Line, Column: n.Column, Path: path,
+				Message: fmt.Sprintf("invalid %s value: %q, must be one of: %s", path, n.Value, strings.Join(s.enum, ", ")),
+			})
+		}
+
+	case kindList:
+		if n.Kind != yaml.SequenceNode {
+			if weak && n.Kind == yaml.ScalarNode {
+				s.elem.validate(n, path, weak, errs)
+				return
+			}
+			report(n, "expected %s, got %s", s.describe(), describeNode(n))
+			return
+		}
+		for i, item := range n.Content {
+			s.elem.validate(item, fmt.Sprintf("%s[%d]", path, i), weak, errs)
+		}
+
+	case kindMap, kindObject:
+		if n.Kind != yaml.MappingNode {
+			report(n, "expected %s, got %s", s.describe(), describeNode(n))
+			return
+		}
+		seen := make(map[string]bool)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.Value == "<<" {
+				continue // Merge key
+			}
+			if seen[key.Value] {
+				*errs = append(*errs, &Error{
+					Line: key.Line, Column: key.Column, Path: path,
+					Message: fmt.Sprintf("duplicate field %q%s", key.Value, in(path)),
+				})
+				continue
+			}
+			seen[key.Value] = true
+
+			child := s.elem
+			if s.kind == kindObject {
+				if s.ignored[key.Value] {
+					continue
+				}
+				child = s.fields[key.Value]
+				if child == nil {
+					*errs = append(*errs, &Error{
+						Line: key.Line, Column: key.Column, Path: path, Field: key.Value,
+						Message: fmt.Sprintf("unknown field %q%s, expected one of: %s", key.Value, in(path), strings.Join(s.fieldNames(), ", ")),
+					})
+					continue
+				}
+			}
+			child.validate(value, join(path, key.Value), weak, errs)
+		}
+	}
+}
+
+// acceptsScalar reports whether a scalar node decodes into the schema's type,
+// following yaml.v3: any scalar decodes into a string, integral floats into
+// integers, and unquoted YAML 1.1 booleans such as "yes" and "off" into
+// booleans. Weakly typed, scalars are accepted if their value parses as the
+// type.
+func (s *Schema) acceptsScalar(n *yaml.Node, weak bool) bool {
+	tag := n.ShortTag()
+	if weak {
+		var err error
+		switch s.kind {
+		case kindInt:
+			_, err = strconv.ParseInt(n.Value, 0, 64)
+		case kindFloat:
+			_, err = strconv.ParseFloat(n.Value, 64)
+		case kindBool:
+			_, err = strconv.ParseBool(n.Value)
+		}
+		if err == nil {
+			return true
+		}
+	}
+
+	switch s.kind {
+	case kindInt:
+		if tag == "!!float" {
+			f, err := strconv.ParseFloat(n.Value, 64)
+			return err == nil && f == math.Trunc(f)
+		}
+		return tag == "!!int"
+	case kindFloat:
+		return tag == "!!int" || tag == "!!float"
+	case kindBool:
+		return tag == "!!bool" || (n.Style == 0 && yaml11Bools[strings.ToLower(n.Value)])
+	case kindDuration:
+		_, err := time.ParseDuration(n.Value)
+		return err == nil || tag == "!!int"
+	default:
+		return true
+	}
+}
+
+// yaml11Bools are the YAML 1.1 booleans yaml.v3 still decodes into booleans.
+var yaml11Bools = map[string]bool{
+	"y": true, "yes": true, "on": true, "n": true, "no": true, "off": true,
+}
+
+func (s *Schema) describe() string {
+	switch s.kind {
+	case kindString:
+		return "a string"
+	case kindInt:
+		return "an integer"
+	case kindFloat:
+		return "a number"
+	case kindBool:
+		return "a boolean"
+	case kindDuration:
+		return "a duration such as 30m"
+	case kindList:
+		return "a list"
+	default:
+		return "a mapping"
+	}
+}
+
+func (s *Schema) fieldNames() []string {
+	names := make([]string, 0, len(s.fields))
+	for name := range s.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func describeNode(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.MappingNode:
+		return "a mapping"
+	}
+	switch n.ShortTag() {
+	case "!!int":
+		return "an integer"
+	case "!!float":
+		return "a number"
+	case "!!bool":
+		return "a boolean"
+	default:
+		return "a string"
+	}
+}
+
+func isNull(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null"
+}
+
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func in(path string) string {
+	if path == "" {
+		return ""
+	}
+	return " in " + path
+}
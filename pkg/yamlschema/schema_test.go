@@ -0,0 +1,192 @@
+package yamlschema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testDeployment struct {
+	Name     string                     `yaml:"name"`
+	Replicas int                        `yaml:"replicas"`
+	Ratio    float64                    `yaml:"ratio"`
+	Enabled  *bool                      `yaml:"enabled"`
+	Tags     []string                   `yaml:"tags"`
+	Labels   map[string]string          `yaml:"labels"`
+	Extra    map[string]any             `yaml:"extra"`
+	Stages   map[string]testStage       `yaml:"stages"`
+	Ports    []testPort                 `yaml:"ports"`
+	Internal string                     `yaml:"-"`
+	Ignored  string                     // No tag
+	Nested   map[string]map[string]bool `yaml:"nested"`
+}
+
+type testStage struct {
+	Mode string `yaml:"mode"`
+}
+
+type testPort struct {
+	Number int    `yaml:"number"`
+	Proto  string `yaml:"proto"`
+}
+
+func testSchema() *Schema {
+	return For(testDeployment{}, "yaml").
+		Enum("stages.*.mode", "fast", "safe").
+		Enum("ports.*.proto", "tcp", "udp")
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string // Error() of each violation
+	}{
+		{
+			name: "valid",
+			yaml: `name: web
+replicas: 3
+ratio: 1
+enabled: true
+tags: [a, b]
+labels: {team: data}
+extra: {anything: [1, {x: y}]}
+stages:
+  prod: {mode: safe}
+ports:
+  - {number: 80, proto: tcp}
+nested: {a: {b: true}}
+`,
+		},
+		{
+			name: "empty document",
+			yaml: "",
+		},
+		{
+			name: "nulls are accepted",
+			yaml: "name:\ntags: ~\nstages:\n  prod:\n",
+		},
+		{
+			name: "YAML 1.1 booleans and integral floats",
+			yaml: "enabled: yes\nreplicas: 2.0\n",
+		},
+		{
+			name: "unknown fields",
+			yaml: "name: web\ncolour: red\nstages:\n  prod:\n    speed: 1\nInternal: x\nIgnored: y\n",
+			want: []string{
+				`2:1: unknown field "colour", expected one of: enabled, extra, labels, name, nested, ports, ratio, replicas, stages, tags`,
+				`5:5: unknown field "speed" in stages.prod, expected one of: mode`,
+				`6:1: unknown field "Internal", expected one of: enabled, extra, labels, name, nested, ports, ratio, replicas, stages, tags`,
+				`7:1: unknown field "Ignored", expected one of: enabled, extra, labels, name, nested, ports, ratio, replicas, stages, tags`,
+			},
+		},
+		{
+			name: "wrong types",
+			yaml: "name: [web]\nreplicas: three\nratio: x\nenabled: 'true'\ntags: a\nlabels: [a]\nports:\n  - number: 1.5\nnested: {a: {b: 1}}\n",
+			want: []string{
+				"1:7: name: expected a string, got a list",
+				"2:11: replicas: expected an integer, got a string",
+				"3:8: ratio: expected a number, got a string",
+				"4:10: enabled: expected a boolean, got a string",
+				"5:7: tags: expected a list, got a string",
+				"6:9: labels: expected a mapping, got a list",
+				"8:13: ports[0].number: expected an integer, got a number",
+				"9:17: nested.a.b: expected a boolean, got an integer",
+			},
+		},
+		{
+			name: "enum values",
+			yaml: "stages:\n  prod: {mode: slow}\n  dev: {mode: ''}\nports:\n  - proto: sctp\n",
+			want: []string{
+				`2:16: invalid stages.prod.mode value: "slow", must be one of: fast, safe`,
+				`5:12: invalid ports[0].proto value: "sctp", must be one of: tcp, udp`,
+			},
+		},
+		{
+			name: "duplicate fields",
+			yaml: "name: a\nname: b\n",
+			want: []string{`2:1: duplicate field "name"`},
+		},
+		{
+			name: "anchors and merge keys",
+			yaml: "stages:\n  base: &base {mode: fast}\n  prod:\n    <<: *base\n  dev: *base\n",
+		},
+		{
+			name: "syntax error",
+			yaml: "name: web\n  tags: [a]\n",
+			want: []string{"2: invalid YAML: mapping values are not allowed in this context"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range testSchema().Validate([]byte(tt.yaml)) {
+				got = append(got, err.Error())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidate_ErrorFields(t *testing.T) {
+	errs := testSchema().Validate([]byte("stages:\n  prod:\n    speed: 1\n"))
+	require.Len(t, errs, 1)
+	assert.Equal(t, &Error{
+		Line:    3,
+		Column:  5,
+		Path:    "stages.prod",
+		Field:   "speed",
+		Message: `unknown field "speed" in stages.prod, expected one of: mode`,
+	}, errs[0])
+}
+
+func TestWeaklyTyped(t *testing.T) {
+	schema := For(testDeployment{}, "yaml").WeaklyTyped()
+
+	assert.Empty(t, schema.Validate([]byte("replicas: '3'\nratio: '0.5'\nenabled: 'true'\ntags: a\nname: 12\n")))
+
+	var got []string
+	for _, err := range schema.Validate([]byte("replicas: three\ntags: {a: b}\n")) {
+		got = append(got, err.Error())
+	}
+	assert.Equal(t, []string{
+		"1:11: replicas: expected an integer, got a string",
+		"2:7: tags: expected a list, got a mapping",
+	}, got)
+}
+
+func TestDuration(t *testing.T) {
+	type limits struct {
+		Timeout time.Duration `yaml:"timeout"`
+	}
+	schema := For(limits{}, "yaml")
+
+	assert.Empty(t, schema.Validate([]byte("timeout: 1h30m\n")))
+	assert.Empty(t, schema.Validate([]byte("timeout: 1000\n")))
+
+	var got []string
+	for _, err := range schema.Validate([]byte("timeout: soon\n")) {
+		got = append(got, err.Error())
+	}
+	assert.Equal(t, []string{"1:10: timeout: expected a duration such as 30m, got a string"}, got)
+}
+
+func TestIgnore(t *testing.T) {
+	schema := For(testStage{}, "yaml").Ignore("version")
+
+	assert.Empty(t, schema.Validate([]byte("mode: fast\nversion: {major: 1}\n")))
+
+	var got []string
+	for _, err := range schema.Validate([]byte("speed: 3\n")) {
+		got = append(got, err.Error())
+	}
+	assert.Equal(t, []string{`1:1: unknown field "speed", expected one of: mode`}, got)
+}
+
+func TestEnum_UnknownPath(t *testing.T) {
+	assert.Panics(t, func() { For(testDeployment{}, "yaml").Enum("stages.mode", "fast") })
+	assert.Panics(t, func() { For(testDeployment{}, "yaml").Enum("missing", "x") })
+}
@@ -70,7 +70,7 @@ func getGlobalsSchema() []GlobalObject {
 			Description: "Information about the current model being rendered.",
 			Properties: []GlobalProperty{
 				{Name: "this.name", Type: "string", Description: "Model name"},
-				{Name: "this.schema", Type: "string", Description: "Model schema"},
+				{Name: "this.schema", Type: "string", Description: "Schema the model is built in"},
 			},
 		},
 		{
@@ -83,6 +83,11 @@ func getGlobalsSchema() []GlobalObject {
 			Description: "Parsed frontmatter as a dictionary. Access any field defined in the model's frontmatter.",
 			Properties:  nil, // config is dynamic based on frontmatter
 		},
+		{
+			Name:        "ref()",
+			Description: "Function returning the table name of a model: ref('model'), ref('project', 'model'), or ref('model', v=2) to pin a version.",
+			Properties:  nil, // ref is a function, not an object
+		},
 	}
 }
 
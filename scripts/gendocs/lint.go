@@ -6,8 +6,10 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/leapstack-labs/leapsql/pkg/core"
 	"github.com/leapstack-labs/leapsql/pkg/lint"
 	_ "github.com/leapstack-labs/leapsql/pkg/lint/project/rules"
 	_ "github.com/leapstack-labs/leapsql/pkg/lint/sql/rules"
@@ -59,6 +61,19 @@ func generateLintDocs(outDir string) error {
 	}
 	log.Printf("  Generated project-rules.md")
 
+	// Generate a page per rule, linked from diagnostics
+	rules := make([]lint.Rule, 0, len(sqlRules)+len(projectRules))
+	for _, r := range sqlRules {
+		rules = append(rules, r)
+	}
+	for _, r := range projectRules {
+		rules = append(rules, r)
+	}
+	if err := generateRulePages(filepath.Join(outDir, "rules"), rules); err != nil {
+		return err
+	}
+	log.Printf("  Generated %d rule pages in rules/", len(rules))
+
 	return nil
 }
 
@@ -92,11 +107,32 @@ func generateLintIndex(outDir string, sqlCount, projectCount int) error {
 	w.Header(2, "Configuration")
 	w.Paragraph("Rules can be configured in `leapsql.yaml`:")
 	w.CodeBlock("yaml", `lint:
+  disabled: [AM01]         # disable rules
+  severity:
+    AL06: error            # override severity
   rules:
-    AM01: off              # disable rule
     AL06:
-      severity: error      # override severity
       max_length: 30       # rule-specific option`)
+	w.Paragraph("Rule options are checked against the options each rule declares: an unknown rule or option, " +
+		"or a value of the wrong type or out of range, fails `leapsql lint` with an error naming it. " +
+		"Options not set take their defaults. `leapsql rules <ID> --format json` lists a rule's options.")
+
+	w.Header(3, "Model Overrides")
+	w.Paragraph("Overrides change severities for the models they select, for SQL and project rules alike. " +
+		"A model is selected by `tag:`, `owner:`, `group:` or `path:`, where a path names directories " +
+		"of the model's file. Overrides apply in order after `severity`, so a later one wins:")
+	w.CodeBlock("yaml", `lint:
+  overrides:
+    - models: ["tag:tier1", "path:marts/finance"]
+      warnings_as_errors: true   # every warning is an error
+      severity:
+        PM04: info               # except model fanout`)
+
+	w.Header(2, "Rule Pages")
+	w.Paragraph("Every rule has a page under `/linting/rules/`, such as [AM01](/linting/rules/am01), " +
+		"with examples, options and how to fix violations. Editors link diagnostics to these pages, " +
+		"`leapsql lint --verbose` prints the link of each violation and `leapsql rules <ID> --format markdown` " +
+		"prints the same documentation.")
 
 	w.Header(2, "Rule Categories")
 
@@ -243,7 +279,8 @@ func capitalizeFirst(s string) string {
 	return strings.ToUpper(s[:1]) + s[1:]
 }
 
-// writeRuleDoc writes detailed documentation for a single rule.
+// writeRuleDoc writes the summary of a single rule on its group page, linking
+// to the rule's own page.
 func writeRuleDoc(w *MarkdownWriter, rule lint.Rule) {
 	// Rule header with anchor: ### AM01 - ambiguous.distinct {#AM01}
 	w.Line(fmt.Sprintf("### %s - %s {#%s}", rule.ID(), rule.Name(), rule.ID()))
@@ -253,35 +290,95 @@ func writeRuleDoc(w *MarkdownWriter, rule lint.Rule) {
 	w.Line(fmt.Sprintf("**Severity:** %s", InlineCode(rule.DefaultSeverity().String())))
 	w.Newline()
 
+	w.Paragraph(cleanDescription(rule.Description()))
+	w.Paragraph(fmt.Sprintf("[Examples, options and how to fix](%s)", rulePageLink(rule.ID())))
+
+	// Horizontal rule between rules for readability
+	w.Line("---")
+	w.Newline()
+}
+
+// generateRulePages writes a page per rule to outDir, replacing pages of
+// rules that no longer exist.
+func generateRulePages(outDir string, rules []lint.Rule) error {
+	if err := os.RemoveAll(outDir); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", outDir, err)
+	}
+	if err := os.MkdirAll(outDir, 0750); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, rule := range rules {
+		w := NewMarkdownWriter()
+		writeRulePage(w, rule)
+		name := strings.ToLower(rule.ID()) + ".md"
+		if err := os.WriteFile(filepath.Join(outDir, name), w.Bytes(), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRulePage writes the full documentation page of a single rule. Section
+// anchors are stable so diagnostics and other pages can link to them.
+func writeRulePage(w *MarkdownWriter, rule lint.Rule) {
+	w.Frontmatter(fmt.Sprintf("%s - %s", rule.ID(), rule.Name()), strconv.Quote(cleanDescription(rule.Description())))
+	w.GeneratedMarker()
+
+	w.Line(fmt.Sprintf("# %s - %s {#%s}", rule.ID(), rule.Name(), rule.ID()))
+	w.Newline()
+
+	kind, groupPage := "SQL", "sql-rules"
+	if _, ok := rule.(lint.ProjectRule); ok {
+		kind, groupPage = "Project", "project-rules"
+	}
+	w.Line(fmt.Sprintf("**Type:** %s | **Group:** [%s](/linting/%s#%s) | **Severity:** %s",
+		kind, rule.Group(), groupPage, rule.Group(), InlineCode(rule.DefaultSeverity().String())))
+	w.Newline()
+
 	w.Paragraph(cleanDescription(rule.Description()))
 
 	// Rationale (if available)
 	if rationale := rule.Rationale(); rationale != "" {
-		w.Header(4, "Why This Matters")
+		w.Line("## Why This Matters {#rationale}")
+		w.Newline()
 		w.Paragraph(strings.TrimSpace(rationale))
 	}
 
 	// Bad example (if available)
 	if badExample := rule.BadExample(); badExample != "" {
-		w.Header(4, "Bad")
+		w.Line("## Bad {#bad}")
+		w.Newline()
 		w.CodeBlock("sql", badExample)
 	}
 
 	// Good example (if available)
 	if goodExample := rule.GoodExample(); goodExample != "" {
-		w.Header(4, "Good")
+		w.Line("## Good {#good}")
+		w.Newline()
 		w.CodeBlock("sql", goodExample)
 	}
 
 	// Fix (if available)
 	if fix := rule.Fix(); fix != "" {
-		w.Header(4, "How to Fix")
+		w.Line("## How to Fix {#fix}")
+		w.Newline()
 		w.Paragraph(strings.TrimSpace(fix))
 	}
 
-	// Config keys (if available)
-	if configKeys := rule.ConfigKeys(); len(configKeys) > 0 {
-		w.Header(4, "Configuration")
+	// Options (if available)
+	if options := rule.Options(); len(options) > 0 {
+		w.Line("## Options {#options}")
+		w.Newline()
+		rows := make([][]string, 0, len(options))
+		for _, o := range options {
+			rows = append(rows, []string{InlineCode(o.Name), string(o.Type), formatOptionDefault(o.Default), o.Description})
+		}
+		w.Table([]string{"Option", "Type", "Default", "Description"}, rows)
+		w.CodeBlock("yaml", ruleOptionsExample(rule.ID(), options))
+	} else if configKeys := rule.ConfigKeys(); len(configKeys) > 0 {
+		w.Line("## Configuration {#options}")
+		w.Newline()
 		w.Paragraph(fmt.Sprintf("This rule accepts the following configuration options: %s",
 			InlineCode(strings.Join(configKeys, ", "))))
 	}
@@ -293,8 +390,50 @@ func writeRuleDoc(w *MarkdownWriter, rule lint.Rule) {
 			w.Newline()
 		}
 	}
+}
 
-	// Horizontal rule between rules for readability
-	w.Line("---")
-	w.Newline()
+// rulePageLink returns the site path of a rule's page. It matches the path
+// of lint.BuildDocURL, which diagnostics link to.
+func rulePageLink(ruleID string) string {
+	return "/linting/rules/" + strings.ToLower(ruleID)
+}
+
+// ruleOptionsExample renders leapsql.yaml setting each option to its default.
+func ruleOptionsExample(ruleID string, options []core.RuleOption) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "lint:\n  rules:\n    %s:", ruleID)
+	for _, o := range options {
+		fmt.Fprintf(&b, "\n      %s: %s", o.Name, formatYAMLValue(o.Default))
+	}
+	return b.String()
+}
+
+// formatYAMLValue formats an option default as a YAML flow value.
+func formatYAMLValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case []string:
+		quoted := make([]string, len(v))
+		for i, s := range v {
+			quoted[i] = strconv.Quote(s)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// formatOptionDefault formats an option's default value for the options table.
+func formatOptionDefault(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "-"
+	case []string:
+		return InlineCode("[" + strings.Join(v, ", ") + "]")
+	default:
+		return InlineCode(fmt.Sprint(v))
+	}
 }
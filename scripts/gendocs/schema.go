@@ -32,7 +32,7 @@ type ConfigField struct {
 	Required    bool
 	Default     string
 	Description string
-	Category    string // "project", "common", "duckdb", "postgres", "snowflake"
+	Category    string // "project", "common", "duckdb", "postgres", "snowflake", "group"
 }
 
 // getConfigSchema returns the configuration schema definition.
@@ -43,10 +43,15 @@ func getConfigSchema() []ConfigField {
 		{Name: "models_dir", Type: "string", Default: "models", Description: "Path to models directory", Category: "project"},
 		{Name: "seeds_dir", Type: "string", Default: "seeds", Description: "Path to seeds directory", Category: "project"},
 		{Name: "macros_dir", Type: "string", Default: "macros", Description: "Path to macros directory", Category: "project"},
+		{Name: "dialect", Type: "string", Description: "SQL dialect models are written in, transpiled to the target's (default: the target type)", Category: "project"},
 
 		// Common target options
 		{Name: "type", Type: "string", Required: true, Description: "Database type: duckdb, postgres, snowflake, bigquery", Category: "common"},
 		{Name: "schema", Type: "string", Required: false, Description: "Default schema for models", Category: "common"},
+		{Name: "transaction", Type: "string", Required: false, Description: "Whether model builds run in a transaction: auto, always, never (default: the adapter's)", Category: "common"},
+		{Name: "masking", Type: "object", Required: false, Description: "How columns tagged as PII are masked: `mode` (none, hash, null, policy) and `policy`", Category: "common"},
+		{Name: "max_concurrent_queries", Type: "int", Required: false, Description: "Most model builds running against the target at once (default: no limit)", Category: "common"},
+		{Name: "resource_classes", Type: "object", Required: false, Description: "Most builds running at once per model resource class, keyed by class name", Category: "common"},
 
 		// File-based databases (DuckDB)
 		{Name: "database", Type: "string", Required: false, Description: "File path (DuckDB) or database name", Category: "duckdb"},
@@ -65,6 +70,11 @@ func getConfigSchema() []ConfigField {
 		// Advanced options
 		{Name: "options", Type: "map[string]string", Required: false, Description: "Additional driver-specific options", Category: "advanced"},
 		{Name: "params", Type: "map[string]any", Required: false, Description: "Adapter-specific configuration (extensions, secrets, settings)", Category: "advanced"},
+
+		// Model groups
+		{Name: "name", Type: "string", Required: true, Description: "Group name, referenced by the `group` frontmatter field", Category: "group"},
+		{Name: "owner", Type: "string", Required: false, Description: "Team or person responsible for the group's models", Category: "group"},
+		{Name: "slack_channel", Type: "string", Required: false, Description: "Channel to notify about the group's models", Category: "group"},
 	}
 }
 
@@ -218,6 +228,31 @@ func generateConfigurationDoc(outDir string) error {
     role: ANALYTICS_ROLE
     schema: PUBLIC`)
 
+	// Groups
+	w.Header(2, "Groups")
+	w.Paragraph("Groups declare which team owns which models. Models join a group with the `group` frontmatter field. " +
+		"Run failures and `leapsql lineage` impact reports list the owner and channel of every affected group, " +
+		"and `leapsql list --group` filters by group.")
+	groupHeaders := []string{"Field", "Type", "Required", "Description"}
+	var groupRows [][]string
+	for _, f := range fields {
+		if f.Category == "group" {
+			req := "No"
+			if f.Required {
+				req = "Yes"
+			}
+			groupRows = append(groupRows, []string{
+				InlineCode(f.Name),
+				f.Type,
+				req,
+				f.Description,
+			})
+		}
+	}
+	w.Table(groupHeaders, groupRows)
+	w.Paragraph("The [PM10](/linting/project-rules#PM10) lint rule reports models assigned to undeclared groups. " +
+		"Once groups are declared, [PM11](/linting/project-rules#PM11) also reports models with neither a group nor an owner.")
+
 	// Full example
 	w.Header(2, "Full Configuration Example")
 	w.CodeBlock("yaml", `# LeapSQL Configuration
@@ -258,7 +293,15 @@ targets:
     user: leapsql
     password: ${PROD_DB_PASSWORD}
     database: analytics
-    schema: public`)
+    schema: public
+
+# Model ownership
+groups:
+  - name: finance
+    owner: finance-data
+    slack_channel: "#finance-alerts"
+  - name: growth
+    owner: growth-team`)
 
 	// Environment variables
 	w.Header(2, "Environment Variables")